@@ -14,6 +14,7 @@ import (
 	"k8s.io/kubectl/pkg/scheme"
 
 	"github.com/openshift/oc/pkg/cli"
+	imagemanifest "github.com/openshift/oc/pkg/cli/image/manifest"
 	schemehelper "github.com/openshift/oc/pkg/helpers/scheme"
 	"github.com/openshift/oc/pkg/version"
 
@@ -48,7 +49,12 @@ func main() {
 	command := cli.CommandFor(basename)
 	logs.AddFlags(command.PersistentFlags())
 	injectLoglevelFlag(command.PersistentFlags())
-	if err := kcli.RunNoErrOutput(command); err != nil {
+	err := kcli.RunNoErrOutput(command)
+	// the commands talking to registries share the files their requests are logged to
+	if closeErr := imagemanifest.CloseRequestLogs(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
 		// Pretty-print the error and exit with an error.
 		kcmdutil.CheckErr(err)
 	}
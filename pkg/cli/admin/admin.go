@@ -6,7 +6,6 @@ import (
 	"github.com/spf13/cobra"
 
 	"k8s.io/cli-runtime/pkg/genericiooptions"
-	"k8s.io/kubectl/pkg/cmd/certificates"
 	"k8s.io/kubectl/pkg/cmd/drain"
 	"k8s.io/kubectl/pkg/cmd/taint"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
@@ -14,12 +13,15 @@ import (
 
 	"github.com/openshift/oc/pkg/cli/admin/buildchain"
 	"github.com/openshift/oc/pkg/cli/admin/catalog"
+	"github.com/openshift/oc/pkg/cli/admin/certificate"
 	"github.com/openshift/oc/pkg/cli/admin/copytonode"
 	"github.com/openshift/oc/pkg/cli/admin/createbootstrapprojecttemplate"
 	"github.com/openshift/oc/pkg/cli/admin/createerrortemplate"
 	"github.com/openshift/oc/pkg/cli/admin/createlogintemplate"
 	"github.com/openshift/oc/pkg/cli/admin/createproviderselectiontemplate"
+	"github.com/openshift/oc/pkg/cli/admin/etcd"
 	"github.com/openshift/oc/pkg/cli/admin/groups"
+	"github.com/openshift/oc/pkg/cli/admin/imagestreams"
 	"github.com/openshift/oc/pkg/cli/admin/inspect"
 	"github.com/openshift/oc/pkg/cli/admin/inspectalerts"
 	"github.com/openshift/oc/pkg/cli/admin/migrate"
@@ -33,6 +35,7 @@ import (
 	"github.com/openshift/oc/pkg/cli/admin/policy"
 	"github.com/openshift/oc/pkg/cli/admin/project"
 	"github.com/openshift/oc/pkg/cli/admin/prune"
+	"github.com/openshift/oc/pkg/cli/admin/pullsecret"
 	"github.com/openshift/oc/pkg/cli/admin/rebootmachineconfigpool"
 	"github.com/openshift/oc/pkg/cli/admin/release"
 	"github.com/openshift/oc/pkg/cli/admin/restartkubelet"
@@ -68,6 +71,7 @@ func NewCommandAdmin(f kcmdutil.Factory, streams genericiooptions.IOStreams) *co
 		ocpcertificates.NewCommandOCPCertificates(f, streams),
 		waitforstable.NewCmdWaitForStableClusterOperators(f, streams),
 		inspect.NewCmdInspect(streams),
+		etcd.NewCommandEtcd(f, streams),
 	}
 
 	if kcmdutil.FeatureGate(inspectAlertsFeatureGate).IsEnabled() {
@@ -88,6 +92,7 @@ func NewCommandAdmin(f kcmdutil.Factory, streams genericiooptions.IOStreams) *co
 				cmdutil.ReplaceCommandName("kubectl", "oc adm", ktemplates.Normalize(taint.NewCmdTaint(f, streams))),
 				node.NewCmdLogs(f, streams),
 				restartkubelet.NewCmdRestartKubelet(f, streams),
+				restartkubelet.NewCmdRestartCRIO(f, streams),
 				copytonode.NewCmdCopyToNode(f, streams),
 				rebootmachineconfigpool.NewCmdRebootMachineConfigPool(f, streams),
 				waitfornodereboot.NewCmdWaitForNodeReboot(f, streams),
@@ -100,14 +105,16 @@ func NewCommandAdmin(f kcmdutil.Factory, streams genericiooptions.IOStreams) *co
 				project.NewCmdNewProject(f, streams),
 				policy.NewCmdPolicy(f, streams),
 				groups.NewCmdGroups(f, streams),
-				withShortDescription(cmdutil.ReplaceCommandName("kubectl", "oc adm", ktemplates.Normalize(certificates.NewCmdCertificate(f, streams))), "Approve or reject certificate requests"),
+				withShortDescription(certificate.NewCmdCertificate(f, streams), "Approve or reject certificate requests"),
 				network.NewCmdPodNetwork(f, streams),
+				pullsecret.NewCommandPullSecret(f, streams),
 			},
 		},
 		{
 			Message: "Maintenance:",
 			Commands: []*cobra.Command{
 				prune.NewCommandPrune(f, streams),
+				imagestreams.NewCommandImageStreams(f, streams),
 				migrate.NewCommandMigrate(f, streams,
 					// Migration commands
 					migratetemplateinstances.NewCmdMigrateTemplateInstances(f, streams),
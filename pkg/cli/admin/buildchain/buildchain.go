@@ -34,9 +34,15 @@ var (
 	buildChainLong = templates.LongDesc(`
 		Output the inputs and dependencies of your builds.
 
-		Supported formats for the generated graph are dot and a human-readable output.
+		Supported formats for the generated graph are dot, json and a human-readable output.
+		The dot and json formats include the strategy of each build configuration.
 		Tag and namespace are optional and if they are not specified, 'latest' and the
 		default namespace will be used respectively.
+
+		The build configurations of the namespaces the image streams of the loaded build
+		configurations are in are loaded as well, so that chains spanning several namespaces
+		are complete. Namespaces you are not allowed to read are skipped. Pass
+		--follow-namespaces=false to only look in the current namespace.
 	`)
 
 	buildChainExample = templates.Examples(`
@@ -46,6 +52,9 @@ var (
 		# Build the dependency tree for the 'v2' tag in dot format and visualize it via the dot utility
 		oc adm build-chain <image-stream>:v2 -o dot | dot -T svg -o deps.svg
 
+		# List the build configurations triggered by the 'latest' tag in <image-stream> along with their strategy
+		oc adm build-chain <image-stream> -o json | jq -r '.nodes[] | select(.kind == "BuildConfig") | "\(.id) \(.strategy)"'
+
 		# Build the dependency tree across all namespaces for the specified image stream tag found in the 'test' namespace
 		oc adm build-chain <image-stream> -n test --all
 	`)
@@ -58,6 +67,7 @@ type BuildChainOptions struct {
 	defaultNamespace string
 	namespaces       sets.String
 	allNamespaces    bool
+	followNamespaces bool
 	triggerOnly      bool
 	reverse          bool
 
@@ -87,9 +97,10 @@ func NewCmdBuildChain(f kcmdutil.Factory, streams genericiooptions.IOStreams) *c
 	}
 
 	cmd.Flags().BoolVar(&options.allNamespaces, "all", false, "If true, build dependency tree for the specified image stream tag across all namespaces")
+	cmd.Flags().BoolVar(&options.followNamespaces, "follow-namespaces", true, "If true, also look for dependencies in the namespaces of the image streams the build configurations refer to, skipping those you are not allowed to read.")
 	cmd.Flags().BoolVar(&options.triggerOnly, "trigger-only", true, "If true, only include dependencies based on build triggers. If false, include all dependencies.")
 	cmd.Flags().BoolVar(&options.reverse, "reverse", false, "If true, show the istags dependencies instead of its dependants.")
-	cmd.Flags().StringVarP(&options.output, "output", "o", "", "Output format of dependency tree")
+	cmd.Flags().StringVarP(&options.output, "output", "o", "", "Output format of dependency tree. One of: dot|json")
 	return cmd
 }
 
@@ -177,8 +188,8 @@ func (o *BuildChainOptions) Validate() error {
 	if len(o.defaultNamespace) == 0 {
 		return fmt.Errorf("default namespace cannot be empty")
 	}
-	if o.output != "" && o.output != "dot" {
-		return fmt.Errorf("output must be either empty or 'dot'")
+	if o.output != "" && o.output != "dot" && o.output != "json" {
+		return fmt.Errorf("output must be either empty, 'dot' or 'json'")
 	}
 	if o.buildClient == nil {
 		return fmt.Errorf("buildConfig client must not be nil")
@@ -197,7 +208,11 @@ func (o *BuildChainOptions) Validate() error {
 func (o *BuildChainOptions) RunBuildChain() error {
 	ist := imagegraph.MakeImageStreamTagObjectMeta2(o.defaultNamespace, o.name)
 
-	desc, err := describe.NewChainDescriber(o.buildClient, o.namespaces, o.output).Describe(ist, !o.triggerOnly, o.reverse)
+	describer := describe.NewChainDescriber(o.buildClient, o.namespaces, o.output)
+	if o.followNamespaces {
+		describer.FollowNamespaces()
+	}
+	desc, err := describer.Describe(ist, !o.triggerOnly, o.reverse)
 	if err != nil {
 		if _, isNotFoundErr := err.(describe.NotFoundErr); isNotFoundErr {
 			// Try to get the imageStreamTag via a direct GET
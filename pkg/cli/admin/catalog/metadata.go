@@ -0,0 +1,109 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+)
+
+// mirrorMetadataFilename is written to the manifest directory to record what a run mirrored,
+// which --incremental reads to mirror only the images added since.
+const mirrorMetadataFilename = "mirror-metadata.json"
+
+// mirrorMetadata describes the images a run of catalog mirror copied to the destination.
+type mirrorMetadata struct {
+	// Catalog is the catalog image mirrored, and CatalogDigest the digest of its version.
+	Catalog       string        `json:"catalog"`
+	CatalogDigest digest.Digest `json:"catalogDigest,omitempty"`
+	// Destination is where the images were mirrored to.
+	Destination string    `json:"destination"`
+	MirrorTime  time.Time `json:"mirrorTime"`
+	// Images are the bundle and related images referenced by digest that are in the
+	// destination, sorted by source.
+	Images []mirroredImage `json:"images"`
+}
+
+type mirroredImage struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+}
+
+// readMirrorMetadata reads the metadata written to dir by a previous run, or returns nil if
+// there is none.
+func readMirrorMetadata(dir string) (*mirrorMetadata, error) {
+	data, err := os.ReadFile(filepath.Join(dir, mirrorMetadataFilename))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	metadata := &mirrorMetadata{}
+	if err := json.Unmarshal(data, metadata); err != nil {
+		return nil, fmt.Errorf("unable to read the metadata of the previous run in %s: %v", dir, err)
+	}
+	return metadata, nil
+}
+
+func writeMirrorMetadata(dir string, metadata *mirrorMetadata) error {
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, mirrorMetadataFilename), append(data, '\n'), 0640); err != nil {
+		return fmt.Errorf("error writing the mirror metadata: %v", err)
+	}
+	return nil
+}
+
+// mirrored returns the destination of every image mirrored by the run, by source.
+func (m *mirrorMetadata) mirrored() map[string]string {
+	if m == nil {
+		return nil
+	}
+	mirrored := make(map[string]string, len(m.Images))
+	for _, image := range m.Images {
+		mirrored[image.Source] = image.Destination
+	}
+	return mirrored
+}
+
+// alreadyMirrored returns whether a previous run mirrored the image to the same destination.
+// Only images referenced by digest are immutable, images referenced by tag, such as the
+// catalog itself, are always mirrored again.
+func alreadyMirrored(from, to imagesource.TypedImageReference, previous map[string]string) bool {
+	if len(from.Ref.ID) == 0 {
+		return false
+	}
+	dest, ok := previous[from.String()]
+	return ok && dest == to.String()
+}
+
+// newMirrorMetadata records the images of the mapping that are in the destination: all of
+// them when mirroring succeeded, otherwise only those a previous run mirrored.
+func newMirrorMetadata(catalog imagesource.TypedImageReference, catalogDigest digest.Digest, dest imagesource.TypedImageReference, mapping map[imagesource.TypedImageReference]imagesource.TypedImageReference, previous map[string]string, succeeded bool) *mirrorMetadata {
+	metadata := &mirrorMetadata{
+		Catalog:       catalog.String(),
+		CatalogDigest: catalogDigest,
+		Destination:   dest.String(),
+		MirrorTime:    time.Now().UTC().Truncate(time.Second),
+		Images:        []mirroredImage{},
+	}
+	for from, to := range mapping {
+		if len(from.Ref.ID) == 0 || !succeeded && !alreadyMirrored(from, to, previous) {
+			continue
+		}
+		metadata.Images = append(metadata.Images, mirroredImage{Source: from.String(), Destination: to.String()})
+	}
+	sort.Slice(metadata.Images, func(i, j int) bool {
+		return metadata.Images[i].Source < metadata.Images[j].Source
+	})
+	return metadata
+}
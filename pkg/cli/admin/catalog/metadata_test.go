@@ -0,0 +1,99 @@
+package catalog
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+)
+
+func TestIncrementalMirror(t *testing.T) {
+	source := mustParse(t, "quay.io/example/image:tag")
+	dest := mustParse(t, "localhost:5000")
+	newMirrorer := func(previous map[string]string, mirrored *map[imagesource.TypedImageReference]imagesource.TypedImageReference) *IndexImageMirrorer {
+		mirrorer, err := NewIndexImageMirror(
+			WithMirrorer(ImageMirrorerFunc(func(mapping map[imagesource.TypedImageReference]imagesource.TypedImageReference) error {
+				*mirrored = mapping
+				return nil
+			})),
+			WithExtractor(existingExtractor("testdata/test.db")),
+			WithRelatedImagesParser(&sqliteRelatedImagesParser{}),
+			WithSource(source),
+			WithDest(dest),
+			WithPreviouslyMirrored(previous),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return mirrorer
+	}
+
+	var firstMirrored map[imagesource.TypedImageReference]imagesource.TypedImageReference
+	mapping, err := newMirrorer(nil, &firstMirrored).Mirror()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(firstMirrored, mapping) {
+		t.Fatalf("expected the first run to mirror all the images")
+	}
+
+	dir := t.TempDir()
+	// images referenced by tag, such as the catalog, are not recorded and always mirrored
+	expected := []string{}
+	for from := range mapping {
+		if len(from.Ref.ID) == 0 {
+			expected = append(expected, from.String())
+		}
+	}
+	metadata := newMirrorMetadata(source, digest.FromString("catalog"), dest, mapping, nil, true)
+	if len(metadata.Images) != len(mapping)-len(expected) {
+		t.Errorf("expected the %d images referenced by digest to be recorded, got %d", len(mapping)-len(expected), len(metadata.Images))
+	}
+	// an image missing from the metadata is mirrored again
+	removed := metadata.Images[0]
+	metadata.Images = metadata.Images[1:]
+	if err := writeMirrorMetadata(dir, metadata); err != nil {
+		t.Fatal(err)
+	}
+	previous, err := readMirrorMetadata(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(previous, metadata) {
+		t.Fatalf("expected the metadata read to be\n%#v\ngot\n%#v", metadata, previous)
+	}
+
+	var secondMirrored map[imagesource.TypedImageReference]imagesource.TypedImageReference
+	secondMapping, err := newMirrorer(previous.mirrored(), &secondMirrored).Mirror()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(secondMapping, mapping) {
+		t.Errorf("expected the incremental run to return the mapping of all the images")
+	}
+	var got []string
+	for from := range secondMirrored {
+		got = append(got, from.String())
+	}
+	expected = append(expected, removed.Source)
+	if !reflect.DeepEqual(sorted(got), sorted(expected)) {
+		t.Errorf("expected the incremental run to mirror %v, got %v", expected, got)
+	}
+
+	if failed := newMirrorMetadata(source, "", dest, mapping, previous.mirrored(), false); !reflect.DeepEqual(failed.Images, previous.Images) {
+		t.Errorf("expected a failed run to only record the images mirrored before, got %v", failed.Images)
+	}
+
+	if empty, err := readMirrorMetadata(t.TempDir()); empty != nil || err != nil {
+		t.Errorf("expected no metadata without a previous run, got %v: %v", empty, err)
+	}
+}
+
+func sorted(values []string) []string {
+	values = append([]string{}, values...)
+	sort.Strings(values)
+	return values
+}
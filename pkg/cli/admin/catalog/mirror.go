@@ -66,6 +66,12 @@ var (
 		A mapping.txt file is also created that is compatible with "oc image mirror". This may be used to further
 		customize the mirroring configuration, but should not be needed in normal circumstances.
 
+		The bundle and related images mirrored are recorded with their digests in a mirror-metadata.json file of the
+		manifests directory. To refresh a mirror when a new version of the catalog is published, pass --incremental
+		with the --to-manifests directory of the previous run: only the images added since the previous catalog
+		version are copied, the manifests still cover all the images of the catalog, and the metadata is updated.
+		Images referenced by tag, such as the catalog itself, are always mirrored again.
+
 	` + prefixLines(sqliteDeprecationNotice, "\t\t\t"))
 	mirrorExample = templates.Examples(`
 		# Mirror an operator-registry image and its contents to a registry
@@ -85,6 +91,10 @@ var (
 		oc adm catalog mirror --manifests-only quay.io/my/image:latest myregistry.com
 		oc image mirror -f manifests/mapping.txt
 
+		# Mirror a new version of a catalog, copying only the images added since the previous run
+		oc adm catalog mirror quay.io/my/image:latest myregistry.com --to-manifests=manifests-my-image
+		oc adm catalog mirror quay.io/my/image:latest myregistry.com --to-manifests=manifests-my-image --incremental
+
 		# Delete all ImageDigestMirrorSets generated by oc adm catalog mirror
 		oc delete imagedigestmirrorset -l operators.openshift.org/catalog=true
 	`)
@@ -115,6 +125,9 @@ type MirrorCatalogOptions struct {
 	IndexPath       string
 	TempDir         bool
 	ContinueOnError bool
+	// Incremental only mirrors the images that the run recorded in the manifest directory
+	// did not mirror
+	Incremental bool
 
 	FromFileDir string
 	FileDir     string
@@ -130,6 +143,8 @@ type MirrorCatalogOptions struct {
 
 	SourceRef imagesource.TypedImageReference
 	DestRef   imagesource.TypedImageReference
+
+	catalogDigest digest.Digest
 }
 
 func NewMirrorCatalogOptions(streams genericiooptions.IOStreams) *MirrorCatalogOptions {
@@ -184,6 +199,7 @@ func NewMirrorCatalog(f kcmdutil.Factory, streams genericiooptions.IOStreams) *c
 	flags.IntVar(&o.MaxICSPSize, "max-icsp-size", maxICSPSize, "The maximum number of bytes for the generated ICSP yaml(s). Defaults to 250000")
 	flags.IntVar(&o.MaxIDMSSize, "max-idms-size", maxIDMSSize, "The maximum number of bytes for the generated IDMS yaml(s). Defaults to 250000")
 	flags.BoolVar(&o.ContinueOnError, "continue-on-error", true, "If an error occurs while mirroring, keep going and attempt to mirror as much as possible.")
+	flags.BoolVar(&o.Incremental, "incremental", o.Incremental, "Only mirror the images added since the previous run, whose metadata is read from the --to-manifests directory.")
 	flags.MarkDeprecated("icsp-scope", "support for it will be removed in a future release.Use --idms-scope instead.")
 	flags.MarkDeprecated("max-icsp-size", "support for it will be removed in a future release. Use --max-idms-size instead.")
 	return cmd
@@ -227,6 +243,9 @@ func (o *MirrorCatalogOptions) Complete(cmd *cobra.Command, args []string) error
 	}
 
 	if o.ManifestDir == "" {
+		if o.Incremental {
+			return fmt.Errorf("--incremental requires --to-manifests with the directory of the previous run")
+		}
 		o.ManifestDir = fmt.Sprintf("manifests-%s-%d", o.SourceRef.Ref.Name, time.Now().Unix())
 	}
 
@@ -278,6 +297,23 @@ func (o *MirrorCatalogOptions) Complete(cmd *cobra.Command, args []string) error
 	if _, err := retriever.Image(context.TODO(), srcRef); err != nil {
 		return err
 	}
+	o.catalogDigest = image.Digest
+
+	if o.Incremental {
+		previous, err := readMirrorMetadata(o.ManifestDir)
+		if err != nil {
+			return err
+		}
+		switch {
+		case previous == nil:
+			fmt.Fprintf(o.IOStreams.Out, "no metadata of a previous run in %s, mirroring all the images\n", o.ManifestDir)
+		case previous.Destination != o.DestRef.String():
+			fmt.Fprintf(o.IOStreams.ErrOut, "warning: the previous run mirrored to %s, mirroring all the images to %s\n", previous.Destination, o.DestRef.String())
+		default:
+			fmt.Fprintf(o.IOStreams.Out, "mirroring the images added since %s@%s was mirrored at %s\n", previous.Catalog, previous.CatalogDigest, previous.MirrorTime.Format(time.RFC3339))
+			o.PreviouslyMirrored = previous.mirrored()
+		}
+	}
 
 	indexLocation := "/"
 	if dcLocation, ok := image.Config.Config.Labels[ConfigsLocationLabelKey]; ok {
@@ -579,6 +615,21 @@ func (o *MirrorCatalogOptions) Run() error {
 		return err
 	}
 	mapping, err := indexMirrorer.Mirror()
+	if len(o.PreviouslyMirrored) > 0 {
+		skipped := 0
+		for from, to := range mapping {
+			if alreadyMirrored(from, to, o.PreviouslyMirrored) {
+				skipped++
+			}
+		}
+		fmt.Fprintf(o.IOStreams.Out, "skipped %d images already mirrored by the previous run\n", skipped)
+	}
+	if !o.DryRun && !o.ManifestOnly {
+		metadata := newMirrorMetadata(o.SourceRef, o.catalogDigest, o.DestRef, mapping, o.PreviouslyMirrored, err == nil)
+		if err := writeMirrorMetadata(o.ManifestDir, metadata); err != nil {
+			fmt.Fprintln(o.IOStreams.ErrOut, err.Error())
+		}
+	}
 	if err != nil {
 		err = fmt.Errorf("errors during mirroring. the full contents of the catalog may not have been mirrored: %v", err)
 		if !o.ContinueOnError {
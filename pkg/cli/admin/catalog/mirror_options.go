@@ -15,6 +15,9 @@ type IndexImageMirrorerOptions struct {
 	Source, Dest      imagesource.TypedImageReference
 	ManifestDir       string
 	MaxPathComponents int
+	// PreviouslyMirrored are the destinations of the images mirrored by a previous run, by
+	// source, which are not mirrored again
+	PreviouslyMirrored map[string]string
 }
 
 func (o *IndexImageMirrorerOptions) Validate() error {
@@ -83,6 +86,9 @@ func (c *IndexImageMirrorerOptions) ToOption() ImageIndexMirrorOption {
 		if c.MaxPathComponents > 0 {
 			o.MaxPathComponents = c.MaxPathComponents
 		}
+		if c.PreviouslyMirrored != nil {
+			o.PreviouslyMirrored = c.PreviouslyMirrored
+		}
 	}
 }
 
@@ -135,3 +141,9 @@ func WithMaxPathComponents(i int) ImageIndexMirrorOption {
 		o.MaxPathComponents = i
 	}
 }
+
+func WithPreviouslyMirrored(m map[string]string) ImageIndexMirrorOption {
+	return func(o *IndexImageMirrorerOptions) {
+		o.PreviouslyMirrored = m
+	}
+}
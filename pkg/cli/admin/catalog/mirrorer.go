@@ -54,6 +54,9 @@ type IndexImageMirrorer struct {
 	// options
 	Source, Dest      imagesource.TypedImageReference
 	MaxPathComponents int
+	// PreviouslyMirrored are the destinations of the images mirrored by a previous run, by
+	// source, which are not mirrored again
+	PreviouslyMirrored map[string]string
 }
 
 var _ Mirrorer = &IndexImageMirrorer{}
@@ -74,6 +77,7 @@ func NewIndexImageMirror(options ...ImageIndexMirrorOption) (*IndexImageMirrorer
 		Source:              config.Source,
 		Dest:                config.Dest,
 		MaxPathComponents:   config.MaxPathComponents,
+		PreviouslyMirrored:  config.PreviouslyMirrored,
 	}, nil
 }
 
@@ -100,7 +104,16 @@ func (b *IndexImageMirrorer) Mirror() (map[imagesource.TypedImageReference]image
 	}
 	mapping[b.Source] = mappedIndex
 
-	if err := b.ImageMirrorer.Mirror(mapping); err != nil {
+	toMirror := mapping
+	if len(b.PreviouslyMirrored) > 0 {
+		toMirror = make(map[imagesource.TypedImageReference]imagesource.TypedImageReference, len(mapping))
+		for from, to := range mapping {
+			if !alreadyMirrored(from, to, b.PreviouslyMirrored) {
+				toMirror[from] = to
+			}
+		}
+	}
+	if err := b.ImageMirrorer.Mirror(toMirror); err != nil {
 		errs = append(errs, fmt.Errorf("mirroring failed: %s", err.Error()))
 	}
 
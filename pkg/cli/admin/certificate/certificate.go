@@ -0,0 +1,228 @@
+package certificate
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"path"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	certificatesv1client "k8s.io/client-go/kubernetes/typed/certificates/v1"
+	"k8s.io/kubectl/pkg/cmd/certificates"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+var (
+	approveAllLong = templates.LongDesc(`
+		Approve certificate signing requests.
+
+		With --all, every pending certificate signing request is approved instead of those
+		passed by name or with -f, and a summary of the approved requests is printed. The
+		requests can be narrowed down to those whose subject common name matches the
+		--csr-filter glob pattern, like the system:node:* requests nodes create when they
+		join the cluster, and to those older than --min-age.
+
+		SECURITY NOTICE: Depending on the requested attributes, the issued certificate
+		can potentially grant a requester access to cluster resources or to authenticate
+		as a requested identity. Before approving a CSR, ensure you understand what the
+		signed certificate can do.
+	`)
+
+	approveAllExample = templates.Examples(`
+		# Approve CSR 'csr-sqgzp'
+		oc adm certificate approve csr-sqgzp
+
+		# Approve the pending CSRs of the nodes that are at least 5 minutes old
+		oc adm certificate approve --all --csr-filter='system:node:*' --min-age=5m
+	`)
+)
+
+// NewCmdCertificate is a wrapper for the Kubernetes cli certificate command, whose approve
+// subcommand can also approve all the pending certificate signing requests.
+func NewCmdCertificate(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	cmd := certificates.NewCmdCertificate(f, streams)
+	for _, subCmd := range cmd.Commands() {
+		if subCmd.Name() == "approve" {
+			addApproveAll(f, subCmd, streams)
+		}
+	}
+	return cmdutil.ReplaceCommandName("kubectl", "oc adm", templates.Normalize(cmd))
+}
+
+// ApproveAllOptions holds the options of approve --all.
+type ApproveAllOptions struct {
+	All       bool
+	CSRFilter string
+	MinAge    time.Duration
+
+	Client certificatesv1client.CertificateSigningRequestsGetter
+
+	// now returns the time the age of the requests is computed from
+	now func() time.Time
+
+	genericiooptions.IOStreams
+}
+
+// addApproveAll adds the --all, --csr-filter and --min-age flags to the approve command,
+// which runs as before unless --all is set.
+func addApproveAll(f kcmdutil.Factory, cmd *cobra.Command, streams genericiooptions.IOStreams) {
+	o := &ApproveAllOptions{now: time.Now, IOStreams: streams}
+
+	approve := cmd.Run
+	cmd.Use = "approve (-f FILENAME | NAME | --all)"
+	cmd.Long = approveAllLong
+	cmd.Example = approveAllExample
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		if !o.All {
+			if len(o.CSRFilter) > 0 || o.MinAge > 0 {
+				kcmdutil.CheckErr(kcmdutil.UsageErrorf(cmd, "--csr-filter and --min-age can only be used with --all"))
+			}
+			approve(cmd, args)
+			return
+		}
+		kcmdutil.CheckErr(o.Complete(f, cmd, args))
+		kcmdutil.CheckErr(o.Validate())
+		kcmdutil.CheckErr(o.Run())
+	}
+
+	cmd.Flags().BoolVar(&o.All, "all", o.All, "If true, approve all the pending certificate signing requests and print a summary.")
+	cmd.Flags().StringVar(&o.CSRFilter, "csr-filter", o.CSRFilter, "With --all, only approve the requests whose subject common name matches this glob pattern, e.g. 'system:node:*'.")
+	cmd.Flags().DurationVar(&o.MinAge, "min-age", o.MinAge, "With --all, only approve the requests created at least this long ago, e.g. 5m.")
+}
+
+func (o *ApproveAllOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "certificate signing requests cannot be passed by name with --all")
+	}
+	if cmd.Flags().Changed("filename") || cmd.Flags().Changed("kustomize") {
+		return kcmdutil.UsageErrorf(cmd, "certificate signing requests cannot be passed with -f or -k with --all")
+	}
+	if cmd.Flags().Changed("output") {
+		return kcmdutil.UsageErrorf(cmd, "--output cannot be used with --all")
+	}
+	kubeClient, err := f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+	o.Client = kubeClient.CertificatesV1()
+	return nil
+}
+
+func (o *ApproveAllOptions) Validate() error {
+	if _, err := path.Match(o.CSRFilter, ""); err != nil {
+		return fmt.Errorf("invalid --csr-filter %q: %v", o.CSRFilter, err)
+	}
+	if o.MinAge < 0 {
+		return fmt.Errorf("--min-age must not be negative")
+	}
+	return nil
+}
+
+// Run approves the pending certificate signing requests matching the filters, oldest first.
+func (o *ApproveAllOptions) Run() error {
+	list, err := o.Client.CertificateSigningRequests().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	csrs := list.Items
+	sort.Slice(csrs, func(i, j int) bool {
+		if !csrs[i].CreationTimestamp.Equal(&csrs[j].CreationTimestamp) {
+			return csrs[i].CreationTimestamp.Before(&csrs[j].CreationTimestamp)
+		}
+		return csrs[i].Name < csrs[j].Name
+	})
+
+	now := o.now()
+	var approved []certificatesv1.CertificateSigningRequest
+	var errs []error
+	pending, unmatched, recent := 0, 0, 0
+	for i := range csrs {
+		csr := &csrs[i]
+		if !isPending(csr) {
+			continue
+		}
+		pending++
+		if len(o.CSRFilter) > 0 {
+			if matched, _ := path.Match(o.CSRFilter, subjectCommonName(csr)); !matched {
+				unmatched++
+				continue
+			}
+		}
+		if now.Sub(csr.CreationTimestamp.Time) < o.MinAge {
+			recent++
+			continue
+		}
+
+		csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+			Type:           certificatesv1.CertificateApproved,
+			Status:         corev1.ConditionTrue,
+			Reason:         "OCAdmApprove",
+			Message:        "This CSR was approved by oc adm certificate approve --all.",
+			LastUpdateTime: metav1.NewTime(now),
+		})
+		if _, err := o.Client.CertificateSigningRequests().UpdateApproval(context.TODO(), csr.Name, csr, metav1.UpdateOptions{}); err != nil {
+			fmt.Fprintf(o.ErrOut, "error: unable to approve %s: %v\n", csr.Name, err)
+			errs = append(errs, err)
+			continue
+		}
+		approved = append(approved, *csr)
+	}
+
+	if len(approved) > 0 {
+		w := tabwriter.NewWriter(o.Out, 0, 4, 3, ' ', 0)
+		fmt.Fprintln(w, "NAME\tAGE\tSUBJECT\tREQUESTOR\tSIGNERNAME")
+		for _, csr := range approved {
+			age := duration.HumanDuration(now.Sub(csr.CreationTimestamp.Time))
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", csr.Name, age, subjectCommonName(&csr), csr.Spec.Username, csr.Spec.SignerName)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintln(o.Out)
+	}
+	fmt.Fprintf(o.Out, "Approved %d of %d pending certificate signing requests", len(approved), pending)
+	if unmatched > 0 || recent > 0 {
+		fmt.Fprintf(o.Out, " (%d not matching --csr-filter, %d younger than --min-age)", unmatched, recent)
+	}
+	fmt.Fprintln(o.Out)
+	return utilerrors.NewAggregate(errs)
+}
+
+// isPending returns true if the request is neither approved, denied nor failed.
+func isPending(csr *certificatesv1.CertificateSigningRequest) bool {
+	for _, c := range csr.Status.Conditions {
+		switch c.Type {
+		case certificatesv1.CertificateApproved, certificatesv1.CertificateDenied, certificatesv1.CertificateFailed:
+			return false
+		}
+	}
+	return true
+}
+
+// subjectCommonName returns the common name of the subject of the request, or an empty
+// string if the request cannot be parsed.
+func subjectCommonName(csr *certificatesv1.CertificateSigningRequest) string {
+	block, _ := pem.Decode(csr.Spec.Request)
+	if block == nil {
+		return ""
+	}
+	req, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return ""
+	}
+	return req.Subject.CommonName
+}
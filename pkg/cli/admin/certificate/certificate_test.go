@@ -0,0 +1,152 @@
+package certificate
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestApproveAll(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newCSR := func(name, commonName string, age time.Duration, conditions ...certificatesv1.RequestConditionType) *certificatesv1.CertificateSigningRequest {
+		der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: commonName}}, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		csr := &certificatesv1.CertificateSigningRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(now.Add(-age))},
+			Spec: certificatesv1.CertificateSigningRequestSpec{
+				Request:    pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}),
+				Username:   "system:serviceaccount:openshift-machine-config-operator:node-bootstrapper",
+				SignerName: certificatesv1.KubeAPIServerClientKubeletSignerName,
+			},
+		}
+		for _, c := range conditions {
+			csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{Type: c, Status: corev1.ConditionTrue})
+		}
+		return csr
+	}
+	objects := []runtime.Object{
+		newCSR("csr-worker-1", "system:node:worker-1", 10*time.Minute),
+		newCSR("csr-worker-2", "system:node:worker-2", 2*time.Minute),
+		newCSR("csr-worker-0", "system:node:worker-0", 20*time.Minute, certificatesv1.CertificateApproved),
+		newCSR("csr-denied", "system:node:worker-3", 20*time.Minute, certificatesv1.CertificateDenied),
+		newCSR("csr-user", "alice", 30*time.Minute),
+	}
+
+	tests := []struct {
+		name      string
+		csrFilter string
+		minAge    time.Duration
+		approved  []string
+		output    string
+	}{
+		{
+			name:     "all pending",
+			approved: []string{"csr-user", "csr-worker-1", "csr-worker-2"},
+			output: `NAME           AGE   SUBJECT                REQUESTOR                                                                   SIGNERNAME
+csr-user       30m   alice                  system:serviceaccount:openshift-machine-config-operator:node-bootstrapper   kubernetes.io/kube-apiserver-client-kubelet
+csr-worker-1   10m   system:node:worker-1   system:serviceaccount:openshift-machine-config-operator:node-bootstrapper   kubernetes.io/kube-apiserver-client-kubelet
+csr-worker-2   2m    system:node:worker-2   system:serviceaccount:openshift-machine-config-operator:node-bootstrapper   kubernetes.io/kube-apiserver-client-kubelet
+
+Approved 3 of 3 pending certificate signing requests
+`,
+		},
+		{
+			name:      "nodes older than 5m",
+			csrFilter: "system:node:*",
+			minAge:    5 * time.Minute,
+			approved:  []string{"csr-worker-1"},
+			output: `NAME           AGE   SUBJECT                REQUESTOR                                                                   SIGNERNAME
+csr-worker-1   10m   system:node:worker-1   system:serviceaccount:openshift-machine-config-operator:node-bootstrapper   kubernetes.io/kube-apiserver-client-kubelet
+
+Approved 1 of 3 pending certificate signing requests (1 not matching --csr-filter, 1 younger than --min-age)
+`,
+		},
+		{
+			name:      "no match",
+			csrFilter: "system:node:master-*",
+			output: `Approved 0 of 3 pending certificate signing requests (3 not matching --csr-filter, 0 younger than --min-age)
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(objects...)
+			out := &bytes.Buffer{}
+			o := &ApproveAllOptions{
+				All:       true,
+				CSRFilter: test.csrFilter,
+				MinAge:    test.minAge,
+				Client:    client.CertificatesV1(),
+				now:       func() time.Time { return now },
+				IOStreams: genericiooptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}},
+			}
+			if err := o.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Run(); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != test.output {
+				t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), test.output)
+			}
+
+			list, err := client.CertificatesV1().CertificateSigningRequests().List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			approved := map[string]bool{}
+			for _, name := range test.approved {
+				approved[name] = true
+			}
+			for _, csr := range list.Items {
+				wasApproved := csr.Name == "csr-worker-0"
+				for _, c := range csr.Status.Conditions {
+					if c.Type == certificatesv1.CertificateApproved && c.Reason == "OCAdmApprove" {
+						wasApproved = false
+						if !approved[csr.Name] {
+							t.Errorf("%s should not have been approved", csr.Name)
+						}
+						delete(approved, csr.Name)
+					}
+				}
+				if wasApproved && len(csr.Status.Conditions) != 1 {
+					t.Errorf("%s was approved again", csr.Name)
+				}
+			}
+			for name := range approved {
+				t.Errorf("%s was not approved", name)
+			}
+		})
+	}
+}
+
+func TestApproveAllValidate(t *testing.T) {
+	o := &ApproveAllOptions{CSRFilter: "system:node:["}
+	if err := o.Validate(); err == nil {
+		t.Errorf("expected an invalid --csr-filter to be rejected")
+	}
+	o = &ApproveAllOptions{MinAge: -time.Minute}
+	if err := o.Validate(); err == nil {
+		t.Errorf("expected a negative --min-age to be rejected")
+	}
+}
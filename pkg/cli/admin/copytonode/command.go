@@ -1,6 +1,8 @@
 package copytonode
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -16,12 +18,23 @@ var (
 	copyToNodeLong = templates.LongDesc(`
 		Copies file from the host to the specified nodes.
 
+		The files are staged in a secret and copied by a short-lived privileged pod on each node,
+		which verifies the sha256 checksum of each copied file and fails if it does not match the
+		local file. Nodes are selected by name with --node, as node/NAME arguments, or with a label
+		selector.
+
 		Experimental: This command is under active development and may change without notice.
 	`)
 
 	copyToNodeExample = templates.Examples(`
 		# Copy a new bootstrap kubeconfig file to node-0
-		oc adm copy-to-node --copy=new-bootstrap-kubeconfig=/etc/kubernetes/kubeconfig node/node-0`)
+		oc adm copy-to-node --copy=new-bootstrap-kubeconfig=/etc/kubernetes/kubeconfig node/node-0
+
+		# Copy a debugging tool to node-0 and node-1
+		oc adm copy-to-node --node=node-0 --node=node-1 --from=./tcpdump --to=/usr/local/bin/tcpdump
+
+		# Copy a CA certificate to all the worker nodes
+		oc adm copy-to-node --from=ca.crt --to=/etc/pki/ca-trust/source/anchors/ca.crt -l node-role.kubernetes.io/worker`)
 )
 
 type CopyToNodeOptions struct {
@@ -29,6 +42,11 @@ type CopyToNodeOptions struct {
 
 	// FileSources to derive the secret from (optional)
 	FileSources []string
+	// From and To are a single file source set with --from and --to.
+	From string
+	To   string
+	// Nodes are the names of nodes to copy to, in addition to the node arguments.
+	Nodes []string
 
 	genericiooptions.IOStreams
 }
@@ -75,16 +93,43 @@ func (o *CopyToNodeOptions) AddFlags(cmd *cobra.Command) {
 	o.PerNodePodOptions.AddFlags(cmd)
 
 	cmd.Flags().StringSliceVar(&o.FileSources, "copy", o.FileSources, "<source-path>=<node-destination>.  Specifying a directory will iterate each named file in the directory, non-recursive (PR welcome) that is a valid secret key.")
-
+	cmd.Flags().StringVar(&o.From, "from", o.From, "Local file or directory to copy, to the path of the node set by --to.")
+	cmd.Flags().StringVar(&o.To, "to", o.To, "Path on the node to copy the file or directory set by --from to.")
+	cmd.Flags().StringSliceVar(&o.Nodes, "node", o.Nodes, "Name of a node to copy to. May be specified multiple times.")
 }
 
 func (o *CopyToNodeOptions) ToRuntime(args []string) (*CopyToNodeRuntime, error) {
+	fileSources, args, err := o.parseArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
 	perNodePodRuntime, err := o.PerNodePodOptions.ToRuntime(args)
 	if err != nil {
 		return nil, err
 	}
 	return &CopyToNodeRuntime{
 		PerNodePodRuntime: perNodePodRuntime,
-		FileSources:       o.FileSources,
+		FileSources:       fileSources,
 	}, nil
 }
+
+// parseArgs returns the file sources of --copy, --from and --to, and the node arguments with
+// the nodes of --node.
+func (o *CopyToNodeOptions) parseArgs(args []string) ([]string, []string, error) {
+	fileSources := append([]string{}, o.FileSources...)
+	switch {
+	case len(o.From) > 0 && len(o.To) > 0:
+		fileSources = append(fileSources, o.From+"="+o.To)
+	case len(o.From) > 0 || len(o.To) > 0:
+		return nil, nil, fmt.Errorf("--from and --to must be specified together")
+	}
+	if len(fileSources) == 0 {
+		return nil, nil, fmt.Errorf("--copy or --from and --to must be specified")
+	}
+	nodeArgs := append([]string{}, args...)
+	for _, node := range o.Nodes {
+		nodeArgs = append(nodeArgs, "node/"+node)
+	}
+	return fileSources, nodeArgs, nil
+}
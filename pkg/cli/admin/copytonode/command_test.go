@@ -0,0 +1,79 @@
+package copytonode
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name                string
+		options             CopyToNodeOptions
+		args                []string
+		expectedFileSources []string
+		expectedArgs        []string
+		expectedError       string
+	}{
+		{
+			name:                "copy with node arguments",
+			options:             CopyToNodeOptions{FileSources: []string{"kubeconfig=/etc/kubernetes/kubeconfig"}},
+			args:                []string{"node/node-0"},
+			expectedFileSources: []string{"kubeconfig=/etc/kubernetes/kubeconfig"},
+			expectedArgs:        []string{"node/node-0"},
+		},
+		{
+			name:                "from and to with nodes",
+			options:             CopyToNodeOptions{From: "./tcpdump", To: "/usr/local/bin/tcpdump", Nodes: []string{"node-0", "node-1"}},
+			expectedFileSources: []string{"./tcpdump=/usr/local/bin/tcpdump"},
+			expectedArgs:        []string{"node/node-0", "node/node-1"},
+		},
+		{
+			name:                "copy, from and to",
+			options:             CopyToNodeOptions{FileSources: []string{"a=/etc/a"}, From: "b", To: "/etc/b", Nodes: []string{"node-1"}},
+			args:                []string{"node/node-0"},
+			expectedFileSources: []string{"a=/etc/a", "b=/etc/b"},
+			expectedArgs:        []string{"node/node-0", "node/node-1"},
+		},
+		{
+			name:                "label selector",
+			options:             CopyToNodeOptions{From: "ca.crt", To: "/etc/pki/ca-trust/source/anchors/ca.crt"},
+			expectedFileSources: []string{"ca.crt=/etc/pki/ca-trust/source/anchors/ca.crt"},
+		},
+		{
+			name:          "from without to",
+			options:       CopyToNodeOptions{From: "ca.crt", Nodes: []string{"node-0"}},
+			expectedError: "--from and --to must be specified together",
+		},
+		{
+			name:          "to without from",
+			options:       CopyToNodeOptions{FileSources: []string{"a=/etc/a"}, To: "/etc/b"},
+			expectedError: "--from and --to must be specified together",
+		},
+		{
+			name:          "nothing to copy",
+			options:       CopyToNodeOptions{Nodes: []string{"node-0"}},
+			expectedError: "--copy or --from and --to must be specified",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fileSources, args, err := test.options.parseArgs(test.args)
+			if len(test.expectedError) > 0 {
+				if err == nil || err.Error() != test.expectedError {
+					t.Fatalf("expected error %q, got %v", test.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(fileSources, test.expectedFileSources) {
+				t.Errorf("expected the file sources %v, got %v", test.expectedFileSources, fileSources)
+			}
+			if strings.Join(args, ",") != strings.Join(test.expectedArgs, ",") {
+				t.Errorf("expected the arguments %v, got %v", test.expectedArgs, args)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package copytonode
 
 import (
 	"context"
+	"crypto/sha256"
 	_ "embed"
 	"errors"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/alessio/shellescape"
@@ -55,17 +57,7 @@ func (r *CopyToNodeRuntime) Run(ctx context.Context) error {
 		return cleanupFn, nil
 	}
 
-	copyCommands := []string{
-		"#/bin/bash",
-		"set -uo pipefail",
-	}
-	for source, destination := range secretDataKeyToFilename {
-		sourcePath := filepath.Join("/source-data", source)
-		destPath := filepath.Join("/host-root", destination)
-		parentDir := filepath.Dir(destPath)
-		copyCommands = append(copyCommands, fmt.Sprintf("mkdir -p %s", shellescape.Quote(parentDir)))
-		copyCommands = append(copyCommands, fmt.Sprintf("cp --dereference -fr %s %s", shellescape.Quote(sourcePath), shellescape.Quote(destPath)))
-	}
+	script := copyScript("/source-data", "/host-root", secret.Data, secretDataKeyToFilename)
 
 	createPodFn := func(ctx context.Context, namespaceName, nodeName, imagePullSpec string) (*corev1.Pod, error) {
 		restartObj := pod.DeepCopy()
@@ -74,13 +66,42 @@ func (r *CopyToNodeRuntime) Run(ctx context.Context) error {
 		restartObj.Spec.Containers[0].Image = imagePullSpec
 		restartObj.Spec.Containers[0].Command = append(
 			restartObj.Spec.Containers[0].Command,
-			strings.Join(copyCommands, "\n"))
+			script)
 		return restartObj, nil
 	}
 
 	return r.PerNodePodRuntime.Run(ctx, prePodHookFn, createPodFn)
 }
 
+// copyScript returns the script copying the content of each secret data key mounted in sourceRoot
+// to its file on the node mounted in hostRoot, and verifying the sha256 checksum of the copy.
+func copyScript(sourceRoot, hostRoot string, data map[string][]byte, secretDataKeyToFilename map[string]string) string {
+	copyCommands := []string{
+		"#/bin/bash",
+		"set -uo pipefail",
+	}
+	secretDataKeys := make([]string, 0, len(secretDataKeyToFilename))
+	for source := range secretDataKeyToFilename {
+		secretDataKeys = append(secretDataKeys, source)
+	}
+	sort.Strings(secretDataKeys)
+	for _, source := range secretDataKeys {
+		destination := secretDataKeyToFilename[source]
+		sourcePath := filepath.Join(sourceRoot, source)
+		destPath := filepath.Join(hostRoot, destination)
+		parentDir := filepath.Dir(destPath)
+		copyCommands = append(copyCommands, fmt.Sprintf("mkdir -p %s || exit 1", shellescape.Quote(parentDir)))
+		copyCommands = append(copyCommands, fmt.Sprintf("cp --dereference -fr %s %s || exit 1", shellescape.Quote(sourcePath), shellescape.Quote(destPath)))
+		// verify the copy against the local file rather than trusting the write to the host
+		checksum := fmt.Sprintf("%x", sha256.Sum256(data[source]))
+		copyCommands = append(copyCommands, fmt.Sprintf("if ! echo %s | sha256sum --check --status; then echo %s >&2; exit 1; fi",
+			shellescape.Quote(checksum+"  "+destPath),
+			shellescape.Quote(fmt.Sprintf("the checksum of %s does not match sha256:%s", destination, checksum)),
+		))
+	}
+	return strings.Join(copyCommands, "\n")
+}
+
 // lifted from create secret command
 // returns a map of secret key data to the node file location
 func (r *CopyToNodeRuntime) secretFromFileSources() (*corev1.Secret, map[string]string, error) {
@@ -0,0 +1,111 @@
+package copytonode
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCopyScript(t *testing.T) {
+	for _, tool := range []string{"bash", "sha256sum"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s is required to run the copy script: %v", tool, err)
+		}
+	}
+	tests := []struct {
+		name string
+		// data is the content of the secret, and files the content of the mounted secret
+		data          map[string]string
+		files         map[string]string
+		expectedError string
+	}{
+		{
+			name:  "copied files",
+			data:  map[string]string{"copy-to-node-0": "kubeconfig", "copy-to-node-1-0": "ca"},
+			files: map[string]string{"copy-to-node-0": "kubeconfig", "copy-to-node-1-0": "ca"},
+		},
+		{
+			name:          "copy not matching the local file",
+			data:          map[string]string{"copy-to-node-0": "kubeconfig", "copy-to-node-1-0": "ca"},
+			files:         map[string]string{"copy-to-node-0": "kubeconfig", "copy-to-node-1-0": "corrupted"},
+			expectedError: "the checksum of /etc/pki/ca-trust/source/anchors/ca.crt does not match sha256:",
+		},
+	}
+	destinations := map[string]string{
+		"copy-to-node-0":   "/etc/kubernetes/kubeconfig",
+		"copy-to-node-1-0": "/etc/pki/ca-trust/source/anchors/ca.crt",
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sourceRoot, hostRoot := t.TempDir(), t.TempDir()
+			data := map[string][]byte{}
+			for key, content := range test.data {
+				data[key] = []byte(content)
+			}
+			for key, content := range test.files {
+				if err := os.WriteFile(filepath.Join(sourceRoot, key), []byte(content), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			script := copyScript(sourceRoot, hostRoot, data, destinations)
+			stderr := &bytes.Buffer{}
+			cmd := exec.Command("bash", "-c", script)
+			cmd.Stderr = stderr
+			err := cmd.Run()
+			if len(test.expectedError) > 0 {
+				if err == nil || !strings.Contains(stderr.String(), test.expectedError) {
+					t.Fatalf("expected the script to fail with %q, got %v: %s", test.expectedError, err, stderr.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v: %s\n%s", err, stderr.String(), script)
+			}
+			for key, destination := range destinations {
+				content, err := os.ReadFile(filepath.Join(hostRoot, destination))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(content) != test.data[key] {
+					t.Errorf("expected %s to hold %q, got %q", destination, test.data[key], content)
+				}
+			}
+		})
+	}
+}
+
+func TestParseFileSource(t *testing.T) {
+	tests := []struct {
+		source              string
+		expectedSource      string
+		expectedDestination string
+		expectedError       string
+	}{
+		{source: "ca.crt=/etc/pki/ca.crt", expectedSource: "ca.crt", expectedDestination: "/etc/pki/ca.crt"},
+		{source: "=/etc/pki/ca.crt", expectedError: "source-path is /etc/pki/ca.crt missing"},
+		{source: "ca.crt=", expectedError: "node-destination is ca.crt missing"},
+		{source: "ca.crt", expectedError: "format is <source-path>=<node-destination>"},
+		{source: "a=b=c", expectedError: "format is <source-path>=<node-destination>"},
+	}
+	for _, test := range tests {
+		t.Run(test.source, func(t *testing.T) {
+			source, destination, err := parseFileSource(test.source)
+			if len(test.expectedError) > 0 {
+				if err == nil || err.Error() != test.expectedError {
+					t.Fatalf("expected error %q, got %v", test.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if source != test.expectedSource || destination != test.expectedDestination {
+				t.Errorf("expected %s to %s, got %s to %s", test.expectedSource, test.expectedDestination, source, destination)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
+	operatorclient "github.com/openshift/client-go/operator/clientset/versioned"
+)
+
+var (
+	backupLong = templates.LongDesc(`
+		Trigger a backup of etcd by the etcd operator.
+
+		An EtcdBackup is created for the etcd operator to save a snapshot of etcd and the
+		static pod resources of the control plane to the persistent volume claim --pvc-name,
+		in the openshift-etcd namespace. Pass --wait to wait for the backup to complete.
+
+		The backup is refused when the claim does not exist or another backup is still
+		pending. EtcdBackups are only served with the AutomatedEtcdBackup feature gate, in the
+		TechPreviewNoUpgrade feature set. Otherwise, follow the documented procedure running
+		cluster-backup.sh on a control plane node.`)
+
+	backupExample = templates.Examples(`
+		# Back up etcd to the claim etcd-backup-pvc and wait for the backup to complete
+		oc adm etcd backup --pvc-name=etcd-backup-pvc --wait`)
+)
+
+// backupStates are the reasons of the conditions of an EtcdBackup, the last ones being final.
+var backupStates = []operatorv1alpha1.BackupConditionReason{
+	operatorv1alpha1.BackupPending,
+	operatorv1alpha1.BackupCompleted,
+	operatorv1alpha1.BackupFailed,
+	operatorv1alpha1.BackupSkipped,
+}
+
+type BackupOptions struct {
+	PVCName string
+	Wait    bool
+	Timeout time.Duration
+
+	KubeClient     kubernetes.Interface
+	OperatorClient operatorclient.Interface
+
+	genericiooptions.IOStreams
+}
+
+func NewCmdBackup(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := &BackupOptions{Timeout: 15 * time.Minute, IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:                   "backup --pvc-name=NAME",
+		DisableFlagsInUseLine: true,
+		Short:                 "Trigger a backup of etcd by the etcd operator",
+		Long:                  backupLong,
+		Example:               backupExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run(context.TODO()))
+		},
+	}
+	cmd.Flags().StringVar(&o.PVCName, "pvc-name", o.PVCName, "The persistent volume claim in the openshift-etcd namespace to save the backup to.")
+	cmd.Flags().BoolVar(&o.Wait, "wait", o.Wait, "If true, wait for the backup to complete.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The time to wait for the backup to complete with --wait.")
+	return cmd
+}
+
+func (o *BackupOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.KubeClient, err = kubernetes.NewForConfig(config); err != nil {
+		return err
+	}
+	o.OperatorClient, err = operatorclient.NewForConfig(config)
+	return err
+}
+
+func (o *BackupOptions) Validate() error {
+	if len(o.PVCName) == 0 {
+		return fmt.Errorf("--pvc-name is required")
+	}
+	if o.Timeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+	return nil
+}
+
+func (o *BackupOptions) Run(ctx context.Context) error {
+	if _, err := o.KubeClient.CoreV1().PersistentVolumeClaims(etcdNamespace).Get(ctx, o.PVCName, metav1.GetOptions{}); err != nil {
+		if kapierrors.IsNotFound(err) {
+			return fmt.Errorf("the persistent volume claim %s does not exist in the %s namespace", o.PVCName, etcdNamespace)
+		}
+		return err
+	}
+
+	backups, err := o.OperatorClient.OperatorV1alpha1().EtcdBackups().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if kapierrors.IsNotFound(err) {
+			return fmt.Errorf("EtcdBackups are not served by the cluster, they require the AutomatedEtcdBackup feature gate")
+		}
+		return err
+	}
+	for _, backup := range backups.Items {
+		if backupState(&backup) == operatorv1alpha1.BackupPending {
+			return fmt.Errorf("the backup %s is still pending, wait for it to complete before triggering another one", backup.Name)
+		}
+	}
+
+	backup, err := o.OperatorClient.OperatorV1alpha1().EtcdBackups().Create(ctx, &operatorv1alpha1.EtcdBackup{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "backup-"},
+		Spec:       operatorv1alpha1.EtcdBackupSpec{PVCName: o.PVCName},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "etcdbackup.operator.openshift.io/%s created\n", backup.Name)
+	if !o.Wait {
+		return nil
+	}
+
+	var state operatorv1alpha1.BackupConditionReason
+	err = wait.PollUntilContextTimeout(ctx, 5*time.Second, o.Timeout, true, func(ctx context.Context) (bool, error) {
+		current, err := o.OperatorClient.OperatorV1alpha1().EtcdBackups().Get(ctx, backup.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		state = backupState(current)
+		return state != "" && state != operatorv1alpha1.BackupPending, nil
+	})
+	if err != nil {
+		return fmt.Errorf("the backup %s did not complete: %v", backup.Name, err)
+	}
+	if state != operatorv1alpha1.BackupCompleted {
+		return fmt.Errorf("the backup %s did not complete: %s, see 'oc describe etcdbackup %s'", backup.Name, state, backup.Name)
+	}
+	fmt.Fprintf(o.Out, "etcdbackup.operator.openshift.io/%s completed\n", backup.Name)
+	return nil
+}
+
+// backupState returns the most advanced state reported by the conditions of the backup.
+func backupState(backup *operatorv1alpha1.EtcdBackup) operatorv1alpha1.BackupConditionReason {
+	var state operatorv1alpha1.BackupConditionReason
+	for i, s := range backupStates {
+		for _, condition := range backup.Status.Conditions {
+			if condition.Status == metav1.ConditionTrue && (condition.Type == string(s) || condition.Reason == string(s)) {
+				state = backupStates[i]
+			}
+		}
+	}
+	return state
+}
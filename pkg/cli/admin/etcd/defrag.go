@@ -0,0 +1,194 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	operatorclient "github.com/openshift/client-go/operator/clientset/versioned"
+)
+
+const (
+	// minDefragBytes and maxFragmentedPercentage are the thresholds above which the etcd
+	// operator defragments a member.
+	minDefragBytes          = 100 * 1024 * 1024
+	maxFragmentedPercentage = 45
+	// defaultBackendQuotaGiB is the backend quota of etcd when the etcd operator does not set one.
+	defaultBackendQuotaGiB = 8
+	// quotaWarningPercentage is the share of the backend quota from which the database size is
+	// reported as close to the quota.
+	quotaWarningPercentage = 80
+)
+
+var (
+	defragRecommendationLong = templates.LongDesc(`
+		Recommend whether to defragment the members of the etcd cluster.
+
+		A member is worth defragmenting when its database is larger than 100MiB and more than
+		45% of it is free space left by compacted revisions, the thresholds from which the
+		etcd operator defragments the members itself. The database size is also compared to
+		the backend quota, etcd refusing writes once it is reached.
+
+		This command only reads the status of the members and never defragments them. When
+		defragmentation is recommended, it prints the procedure to follow: the members are
+		defragmented one at a time, the followers first and the leader last, since a member
+		does not serve requests while it is defragmented.`)
+
+	defragRecommendationExample = templates.Examples(`
+		# Recommend whether to defragment the members of the etcd cluster
+		oc adm etcd defrag-recommendation`)
+)
+
+type DefragRecommendationOptions struct {
+	OperatorClient operatorclient.Interface
+	Etcdctl        *etcdctl
+
+	genericiooptions.IOStreams
+}
+
+func NewCmdDefragRecommendation(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := &DefragRecommendationOptions{IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:                   "defrag-recommendation",
+		DisableFlagsInUseLine: true,
+		Short:                 "Recommend whether to defragment the members of the etcd cluster",
+		Long:                  defragRecommendationLong,
+		Example:               defragRecommendationExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run(context.TODO()))
+		},
+	}
+	return cmd
+}
+
+func (o *DefragRecommendationOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+	var err error
+	if o.Etcdctl, err = newEtcdctl(f); err != nil {
+		return err
+	}
+	o.OperatorClient, err = operatorclient.NewForConfig(o.Etcdctl.Config)
+	return err
+}
+
+// defragRecommendation is the recommendation for a member.
+type defragRecommendation struct {
+	Status endpointStatus
+	// Pod is the etcd pod of the member
+	Pod                  string
+	FragmentedPercentage float64
+	Recommended          bool
+}
+
+func recommendDefrag(statuses []endpointStatus, podsByIP map[string]string) []defragRecommendation {
+	var recommendations []defragRecommendation
+	for _, s := range statuses {
+		r := defragRecommendation{Status: s, Pod: podsByIP[endpointHost(s.Endpoint)]}
+		if s.Status.DBSize > 0 {
+			r.FragmentedPercentage = float64(s.Status.DBSize-s.Status.DBSizeInUse) / float64(s.Status.DBSize) * 100
+		}
+		r.Recommended = s.Status.DBSize >= minDefragBytes && r.FragmentedPercentage >= maxFragmentedPercentage
+		recommendations = append(recommendations, r)
+	}
+	return recommendations
+}
+
+func endpointHost(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return endpoint
+	}
+	host, _, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		return u.Host
+	}
+	return host
+}
+
+func (o *DefragRecommendationOptions) Run(ctx context.Context) error {
+	etcd, err := o.OperatorClient.OperatorV1().Etcds().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	quotaGiB := int64(etcd.Spec.BackendQuotaGiB)
+	if quotaGiB == 0 {
+		quotaGiB = defaultBackendQuotaGiB
+	}
+
+	statuses, err := o.Etcdctl.endpointStatus(ctx)
+	if err != nil {
+		return err
+	}
+	// the etcd pods run on the host network of the control plane nodes, their IP is the one of
+	// the client URL of their member
+	pods, err := o.Etcdctl.Client.CoreV1().Pods(etcdNamespace).List(ctx, metav1.ListOptions{LabelSelector: etcdPodSelector})
+	if err != nil {
+		return err
+	}
+	podsByIP := map[string]string{}
+	for _, pod := range pods.Items {
+		podsByIP[pod.Status.PodIP] = pod.Name
+	}
+
+	printDefragRecommendations(o.Out, recommendDefrag(statuses, podsByIP), quotaGiB)
+	return nil
+}
+
+func printDefragRecommendations(out io.Writer, recommendations []defragRecommendation, quotaGiB int64) {
+	quota := quotaGiB * 1024 * 1024 * 1024
+	w := printers.GetNewTabWriter(out)
+	fmt.Fprintln(w, "ENDPOINT\tPOD\tLEADER\tDB SIZE\tIN USE\tFRAGMENTED\tQUOTA USED\tDEFRAG RECOMMENDED")
+	var followers, leaders []defragRecommendation
+	closeToQuota := false
+	for _, r := range recommendations {
+		quotaUsed := float64(r.Status.Status.DBSize) / float64(quota) * 100
+		closeToQuota = closeToQuota || quotaUsed >= quotaWarningPercentage
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%s\t%.1f%%\t%.1f%%\t%t\n", r.Status.Endpoint, valueOrUnknown(r.Pod), r.Status.isLeader(),
+			units.BytesSize(float64(r.Status.Status.DBSize)), units.BytesSize(float64(r.Status.Status.DBSizeInUse)), r.FragmentedPercentage, quotaUsed, r.Recommended)
+		switch {
+		case !r.Recommended:
+		case r.Status.isLeader():
+			leaders = append(leaders, r)
+		default:
+			followers = append(followers, r)
+		}
+	}
+	w.Flush()
+
+	if closeToQuota {
+		fmt.Fprintf(out, "\nwarning: the database of a member uses more than %d%% of the backend quota of %dGiB, etcd refuses writes once the quota is reached.\n", quotaWarningPercentage, quotaGiB)
+	}
+	if len(followers)+len(leaders) == 0 {
+		fmt.Fprintln(out, "\nNo member needs to be defragmented.")
+		return
+	}
+	fmt.Fprintln(out, "\nDefragment the members one at a time, in this order, checking that 'oc adm etcd status' reports the member healthy before the next one:")
+	for i, r := range append(followers, leaders...) {
+		pod := r.Pod
+		if len(pod) == 0 {
+			pod = "<the etcd pod of " + r.Status.Endpoint + ">"
+		}
+		fmt.Fprintf(out, "  %d. oc rsh -n %s -c %s %s etcdctl --command-timeout=%s --endpoints=https://localhost:2379 defrag\n", i+1, etcdNamespace, etcdctlContainer, pod, etcdctlTimeout)
+	}
+}
+
+func valueOrUnknown(s string) string {
+	if len(s) == 0 {
+		return "<unknown>"
+	}
+	return s
+}
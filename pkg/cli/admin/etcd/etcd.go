@@ -0,0 +1,227 @@
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/cmd/exec"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/podutils"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// etcdNamespace is the namespace of the etcd pods run by the etcd operator.
+	etcdNamespace = "openshift-etcd"
+	// etcdPodSelector selects the etcd pods, one per control plane node.
+	etcdPodSelector = "app=etcd"
+	// etcdctlContainer is the container of the etcd pods set up to run etcdctl against the
+	// members of the cluster.
+	etcdctlContainer = "etcdctl"
+	// etcdctlTimeout bounds every etcdctl command, so that an unresponsive member does not
+	// hang the command.
+	etcdctlTimeout = 30 * time.Second
+)
+
+var etcdLong = templates.LongDesc(`
+	Inspect and back up the etcd cluster of the control plane.
+
+	These commands read the state of the etcd operator and run read-only etcdctl commands in
+	the etcdctl container of a ready etcd pod, instead of running etcdctl by hand in a
+	debug shell on a control plane node. They never write to etcd: defragmentation is only
+	recommended, and backups are taken by the etcd operator.`)
+
+// NewCommandEtcd implements the etcd command and its subcommands.
+func NewCommandEtcd(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	cmds := &cobra.Command{
+		Use:   "etcd",
+		Short: "Inspect and back up the etcd cluster of the control plane",
+		Long:  etcdLong,
+		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+	cmds.AddCommand(
+		NewCmdStatus(f, streams),
+		NewCmdMember(f, streams),
+		NewCmdDefragRecommendation(f, streams),
+		NewCmdBackup(f, streams),
+	)
+	return cmds
+}
+
+// readOnlyCommands are the etcdctl commands that may be run in the etcd pods.
+var readOnlyCommands = map[string]bool{
+	"endpoint status": true,
+	"endpoint health": true,
+	"member list":     true,
+}
+
+// etcdctl runs read-only etcdctl commands in the etcdctl container of a ready etcd pod.
+type etcdctl struct {
+	Client   kubernetes.Interface
+	Config   *restclient.Config
+	Executor exec.RemoteExecutor
+}
+
+func newEtcdctl(f kcmdutil.Factory) (*etcdctl, error) {
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &etcdctl{Client: client, Config: config, Executor: &exec.DefaultRemoteExecutor{}}, nil
+}
+
+// pod returns a ready etcd pod to run etcdctl in.
+func (e *etcdctl) pod(ctx context.Context) (*corev1.Pod, error) {
+	pods, err := e.Client.CoreV1().Pods(etcdNamespace).List(ctx, metav1.ListOptions{LabelSelector: etcdPodSelector})
+	if err != nil {
+		return nil, err
+	}
+	var ready []*corev1.Pod
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp == nil && podutils.IsPodReady(pod) {
+			ready = append(ready, pod)
+		}
+	}
+	if len(ready) == 0 {
+		return nil, fmt.Errorf("no ready etcd pod found in namespace %s", etcdNamespace)
+	}
+	sort.Slice(ready, func(i, j int) bool { return ready[i].Name < ready[j].Name })
+	return ready[0], nil
+}
+
+// run runs the read-only etcdctl command with JSON output and decodes it into out.
+func (e *etcdctl) run(ctx context.Context, out interface{}, command ...string) error {
+	if len(command) < 2 || !readOnlyCommands[strings.Join(command[:2], " ")] {
+		return fmt.Errorf("refusing to run etcdctl %s, only %s may be run", strings.Join(command, " "), strings.Join(sortedCommands(), ", "))
+	}
+	pod, err := e.pod(ctx)
+	if err != nil {
+		return err
+	}
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	execOptions := &exec.ExecOptions{
+		StreamOptions: exec.StreamOptions{
+			Namespace:     pod.Namespace,
+			PodName:       pod.Name,
+			ContainerName: etcdctlContainer,
+			IOStreams:     genericiooptions.IOStreams{Out: stdout, ErrOut: stderr},
+		},
+		Executor:  e.Executor,
+		PodClient: e.Client.CoreV1(),
+		Config:    e.Config,
+		Command:   append([]string{"etcdctl"}, append(command, "--write-out=json", fmt.Sprintf("--command-timeout=%s", etcdctlTimeout))...),
+	}
+	klog.V(2).Infof("Running %v in pod %s/%s", execOptions.Command, pod.Namespace, pod.Name)
+	if err := execOptions.Run(); err != nil {
+		// etcdctl reports the members it could not reach on stderr, but their status is still
+		// printed for the others
+		if stdout.Len() == 0 {
+			return fmt.Errorf("etcdctl %s failed in pod %s: %v: %s", strings.Join(command, " "), pod.Name, err, strings.TrimSpace(stderr.String()))
+		}
+		klog.V(2).Infof("etcdctl %s failed in pod %s: %v: %s", strings.Join(command, " "), pod.Name, err, stderr.String())
+	}
+	if err := json.Unmarshal(stdout.Bytes(), out); err != nil {
+		return fmt.Errorf("unable to decode the output of etcdctl %s: %v", strings.Join(command, " "), err)
+	}
+	return nil
+}
+
+func sortedCommands() []string {
+	var commands []string
+	for command := range readOnlyCommands {
+		commands = append(commands, command)
+	}
+	sort.Strings(commands)
+	return commands
+}
+
+// endpointStatus is an item of the output of 'etcdctl endpoint status --write-out=json'.
+type endpointStatus struct {
+	Endpoint string `json:"Endpoint"`
+	Status   struct {
+		Header struct {
+			MemberID uint64 `json:"member_id"`
+		} `json:"header"`
+		Version     string   `json:"version"`
+		DBSize      int64    `json:"dbSize"`
+		DBSizeInUse int64    `json:"dbSizeInUse"`
+		Leader      uint64   `json:"leader"`
+		RaftIndex   uint64   `json:"raftIndex"`
+		RaftTerm    uint64   `json:"raftTerm"`
+		IsLearner   bool     `json:"isLearner"`
+		Errors      []string `json:"errors"`
+	} `json:"Status"`
+}
+
+func (s endpointStatus) isLeader() bool {
+	return s.Status.Header.MemberID == s.Status.Leader
+}
+
+func (e *etcdctl) endpointStatus(ctx context.Context) ([]endpointStatus, error) {
+	var statuses []endpointStatus
+	if err := e.run(ctx, &statuses, "endpoint", "status", "--cluster"); err != nil {
+		return nil, err
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Endpoint < statuses[j].Endpoint })
+	return statuses, nil
+}
+
+// endpointHealth is an item of the output of 'etcdctl endpoint health --write-out=json'.
+type endpointHealth struct {
+	Endpoint string `json:"endpoint"`
+	Health   bool   `json:"health"`
+	Took     string `json:"took"`
+	Error    string `json:"error,omitempty"`
+}
+
+func (e *etcdctl) endpointHealth(ctx context.Context) ([]endpointHealth, error) {
+	var health []endpointHealth
+	if err := e.run(ctx, &health, "endpoint", "health", "--cluster"); err != nil {
+		return nil, err
+	}
+	sort.Slice(health, func(i, j int) bool { return health[i].Endpoint < health[j].Endpoint })
+	return health, nil
+}
+
+// member is a member in the output of 'etcdctl member list --write-out=json'.
+type member struct {
+	ID         uint64   `json:"ID"`
+	Name       string   `json:"name"`
+	PeerURLs   []string `json:"peerURLs"`
+	ClientURLs []string `json:"clientURLs"`
+	IsLearner  bool     `json:"isLearner"`
+}
+
+func (e *etcdctl) memberList(ctx context.Context) ([]member, error) {
+	var list struct {
+		Members []member `json:"members"`
+	}
+	if err := e.run(ctx, &list, "member", "list"); err != nil {
+		return nil, err
+	}
+	sort.Slice(list.Members, func(i, j int) bool { return list.Members[i].Name < list.Members[j].Name })
+	return list.Members, nil
+}
+
+// memberID formats the ID of a member like etcdctl does.
+func memberID(id uint64) string {
+	return fmt.Sprintf("%x", id)
+}
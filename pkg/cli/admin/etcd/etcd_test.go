@@ -0,0 +1,116 @@
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	kubernetesscheme "k8s.io/client-go/kubernetes/scheme"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
+)
+
+const testEndpointStatus = `[
+	{"Endpoint":"https://10.0.0.4:2379","Status":{"header":{"member_id":2},"version":"3.5.21","dbSize":314572800,"dbSizeInUse":104857600,"leader":1,"raftIndex":100,"raftTerm":3}},
+	{"Endpoint":"https://10.0.0.3:2379","Status":{"header":{"member_id":1},"version":"3.5.21","dbSize":314572800,"dbSizeInUse":104857600,"leader":1,"raftIndex":100,"raftTerm":3}},
+	{"Endpoint":"https://10.0.0.5:2379","Status":{"header":{"member_id":3},"version":"3.5.21","dbSize":52428800,"dbSizeInUse":10485760,"leader":1,"raftIndex":100,"raftTerm":3}}
+]`
+
+type fakeRemoteExecutor struct {
+	commands [][]string
+}
+
+func (f *fakeRemoteExecutor) Execute(url *url.URL, config *restclient.Config, stdin io.Reader, stdout, stderr io.Writer, tty bool, terminalSizeQueue remotecommand.TerminalSizeQueue) error {
+	command := url.Query()["command"]
+	f.commands = append(f.commands, command)
+	stdout.Write([]byte(testEndpointStatus))
+	return nil
+}
+
+func etcdPod(name, ip string, ready bool) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: etcdNamespace, Labels: map[string]string{"app": "etcd"}},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			PodIP:      ip,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+}
+
+func TestEtcdctl(t *testing.T) {
+	executor := &fakeRemoteExecutor{}
+	e := &etcdctl{
+		Client: fake.NewSimpleClientset(etcdPod("etcd-master-0", "10.0.0.3", false), etcdPod("etcd-master-1", "10.0.0.4", true)),
+		Config: &restclient.Config{
+			Host: "https://api.example.com:6443",
+			ContentConfig: restclient.ContentConfig{
+				GroupVersion:         &schema.GroupVersion{Version: "v1"},
+				NegotiatedSerializer: kubernetesscheme.Codecs,
+			},
+		},
+		Executor: executor,
+	}
+
+	if err := e.run(context.TODO(), nil, "defrag", "--cluster"); err == nil || !strings.Contains(err.Error(), "refusing to run etcdctl defrag") {
+		t.Errorf("expected defrag to be refused, got %v", err)
+	}
+	if len(executor.commands) != 0 {
+		t.Fatalf("expected no command to be run, got %v", executor.commands)
+	}
+
+	statuses, err := e.endpointStatus(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "etcdctl endpoint status --cluster --write-out=json --command-timeout=30s"; len(executor.commands) != 1 || strings.Join(executor.commands[0], " ") != expected {
+		t.Errorf("expected %q to be run, got %v", expected, executor.commands)
+	}
+	if len(statuses) != 3 || statuses[0].Endpoint != "https://10.0.0.3:2379" || !statuses[0].isLeader() || statuses[1].isLeader() {
+		t.Errorf("unexpected statuses %#v", statuses)
+	}
+
+	recommendations := recommendDefrag(statuses, map[string]string{"10.0.0.3": "etcd-master-0", "10.0.0.4": "etcd-master-1"})
+	out := &bytes.Buffer{}
+	printDefragRecommendations(out, recommendations, 8)
+	expected := `ENDPOINT                POD             LEADER   DB SIZE   IN USE   FRAGMENTED   QUOTA USED   DEFRAG RECOMMENDED
+https://10.0.0.3:2379   etcd-master-0   true     300MiB    100MiB   66.7%        3.7%         true
+https://10.0.0.4:2379   etcd-master-1   false    300MiB    100MiB   66.7%        3.7%         true
+https://10.0.0.5:2379   <unknown>       false    50MiB     10MiB    80.0%        0.6%         false
+
+Defragment the members one at a time, in this order, checking that 'oc adm etcd status' reports the member healthy before the next one:
+  1. oc rsh -n openshift-etcd -c etcdctl etcd-master-1 etcdctl --command-timeout=30s --endpoints=https://localhost:2379 defrag
+  2. oc rsh -n openshift-etcd -c etcdctl etcd-master-0 etcdctl --command-timeout=30s --endpoints=https://localhost:2379 defrag
+`
+	if out.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, out.String())
+	}
+}
+
+func TestBackupState(t *testing.T) {
+	backup := &operatorv1alpha1.EtcdBackup{}
+	if state := backupState(backup); state != "" {
+		t.Errorf("expected no state, got %q", state)
+	}
+	backup.Status.Conditions = []metav1.Condition{{Type: "BackupPending", Status: metav1.ConditionTrue, Reason: "BackupPending"}}
+	if state := backupState(backup); state != operatorv1alpha1.BackupPending {
+		t.Errorf("expected a pending backup, got %q", state)
+	}
+	backup.Status.Conditions = append(backup.Status.Conditions, metav1.Condition{Type: "BackupCompleted", Status: metav1.ConditionTrue, Reason: "BackupCompleted"})
+	if state := backupState(backup); state != operatorv1alpha1.BackupCompleted {
+		t.Errorf("expected a completed backup, got %q", state)
+	}
+}
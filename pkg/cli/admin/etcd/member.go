@@ -0,0 +1,82 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	memberListLong = templates.LongDesc(`
+		List the members of the etcd cluster.
+
+		The members are listed by 'etcdctl member list' in an etcd pod, with their peer and
+		client URLs and whether they are learners, which are members still catching up with
+		the leader before they vote.`)
+
+	memberListExample = templates.Examples(`
+		# List the members of the etcd cluster
+		oc adm etcd member list`)
+)
+
+// NewCmdMember implements the member command and its subcommands.
+func NewCmdMember(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "member",
+		Short: "Inspect the members of the etcd cluster",
+		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+	cmd.AddCommand(NewCmdMemberList(f, streams))
+	return cmd
+}
+
+type MemberListOptions struct {
+	Etcdctl *etcdctl
+
+	genericiooptions.IOStreams
+}
+
+func NewCmdMemberList(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := &MemberListOptions{IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:                   "list",
+		DisableFlagsInUseLine: true,
+		Short:                 "List the members of the etcd cluster",
+		Long:                  memberListLong,
+		Example:               memberListExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run(context.TODO()))
+		},
+	}
+	return cmd
+}
+
+func (o *MemberListOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+	var err error
+	o.Etcdctl, err = newEtcdctl(f)
+	return err
+}
+
+func (o *MemberListOptions) Run(ctx context.Context) error {
+	members, err := o.Etcdctl.memberList(ctx)
+	if err != nil {
+		return err
+	}
+	w := printers.GetNewTabWriter(o.Out)
+	fmt.Fprintln(w, "ID\tNAME\tPEER URLS\tCLIENT URLS\tLEARNER")
+	for _, m := range members {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\n", memberID(m.ID), m.Name, strings.Join(m.PeerURLs, ","), strings.Join(m.ClientURLs, ","), m.IsLearner)
+	}
+	return w.Flush()
+}
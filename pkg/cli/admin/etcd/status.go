@@ -0,0 +1,154 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	operatorclient "github.com/openshift/client-go/operator/clientset/versioned"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+var (
+	statusLong = templates.LongDesc(`
+		Show the status of the etcd cluster.
+
+		The conditions of the etcd operator and the revisions of the etcd pods on the control
+		plane nodes are followed by the health of every member, its database size and whether
+		it is the leader, as reported by 'etcdctl endpoint status' and 'etcdctl endpoint
+		health' in an etcd pod.`)
+
+	statusExample = templates.Examples(`
+		# Show the status of the etcd cluster
+		oc adm etcd status`)
+)
+
+// memberConditions are the conditions of the etcd operator on the members, always shown by
+// the status, which also shows the degraded conditions of the other controllers of the operator.
+var memberConditions = []string{"EtcdMembersAvailable", "EtcdMembersProgressing", "EtcdMembersDegraded"}
+
+type StatusOptions struct {
+	OperatorClient operatorclient.Interface
+	Etcdctl        *etcdctl
+
+	genericiooptions.IOStreams
+}
+
+func NewCmdStatus(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := &StatusOptions{IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:                   "status",
+		DisableFlagsInUseLine: true,
+		Short:                 "Show the status of the etcd cluster",
+		Long:                  statusLong,
+		Example:               statusExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run(context.TODO()))
+		},
+	}
+	return cmd
+}
+
+func (o *StatusOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+	var err error
+	if o.Etcdctl, err = newEtcdctl(f); err != nil {
+		return err
+	}
+	o.OperatorClient, err = operatorclient.NewForConfig(o.Etcdctl.Config)
+	return err
+}
+
+func (o *StatusOptions) Run(ctx context.Context) error {
+	etcd, err := o.OperatorClient.OperatorV1().Etcds().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	printOperatorStatus(o.Out, etcd)
+
+	statuses, err := o.Etcdctl.endpointStatus(ctx)
+	if err != nil {
+		return err
+	}
+	health, err := o.Etcdctl.endpointHealth(ctx)
+	if err != nil {
+		return err
+	}
+	healthByEndpoint := map[string]endpointHealth{}
+	for _, h := range health {
+		healthByEndpoint[h.Endpoint] = h
+	}
+
+	fmt.Fprintln(o.Out)
+	w := printers.GetNewTabWriter(o.Out)
+	fmt.Fprintln(w, "ENDPOINT\tMEMBER\tHEALTH\tLEADER\tVERSION\tDB SIZE\tIN USE\tRAFT TERM\tRAFT INDEX\tERRORS")
+	for _, s := range statuses {
+		memberHealth := "unknown"
+		if h, ok := healthByEndpoint[s.Endpoint]; ok {
+			memberHealth = fmt.Sprintf("unhealthy: %s", h.Error)
+			if h.Health {
+				memberHealth = fmt.Sprintf("healthy (%s)", h.Took)
+			}
+		}
+		errors := "<none>"
+		if len(s.Status.Errors) > 0 {
+			errors = strings.Join(s.Status.Errors, ", ")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\t%s\t%s\t%d\t%d\t%s\n", s.Endpoint, memberID(s.Status.Header.MemberID), memberHealth, s.isLeader(), s.Status.Version,
+			units.HumanSize(float64(s.Status.DBSize)), units.HumanSize(float64(s.Status.DBSizeInUse)), s.Status.RaftTerm, s.Status.RaftIndex, errors)
+	}
+	return w.Flush()
+}
+
+func printOperatorStatus(out io.Writer, etcd *operatorv1.Etcd) {
+	w := printers.GetNewTabWriter(out)
+	defer w.Flush()
+	fmt.Fprintln(w, "CONDITION\tSTATUS\tMESSAGE")
+	for _, condition := range statusConditions(etcd.Status.Conditions) {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", condition.Type, condition.Status, strings.ReplaceAll(condition.Message, "\n", " "))
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "NODE\tCURRENT REVISION\tTARGET REVISION\tLAST FAILED REVISION")
+	for _, node := range etcd.Status.NodeStatuses {
+		target := "-"
+		if node.TargetRevision > 0 {
+			target = fmt.Sprintf("%d", node.TargetRevision)
+		}
+		failed := "-"
+		if node.LastFailedRevision > 0 {
+			failed = fmt.Sprintf("%d", node.LastFailedRevision)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", node.NodeName, node.CurrentRevision, target, failed)
+	}
+}
+
+// statusConditions returns the conditions on the members, followed by the degraded
+// conditions of the other controllers.
+func statusConditions(conditions []operatorv1.OperatorCondition) []operatorv1.OperatorCondition {
+	var shown []operatorv1.OperatorCondition
+	for _, conditionType := range memberConditions {
+		if condition := v1helpers.FindOperatorCondition(conditions, conditionType); condition != nil {
+			shown = append(shown, *condition)
+		}
+	}
+	for _, condition := range conditions {
+		if strings.HasSuffix(condition.Type, operatorv1.OperatorStatusTypeDegraded) && condition.Type != "EtcdMembersDegraded" && condition.Status == operatorv1.ConditionTrue {
+			shown = append(shown, condition)
+		}
+	}
+	return shown
+}
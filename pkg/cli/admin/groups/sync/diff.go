@@ -0,0 +1,48 @@
+package sync
+
+import (
+	"fmt"
+	"io"
+
+	syncgroups "github.com/openshift/oc/pkg/helpers/groupsync"
+)
+
+// printGroupChanges writes the changes as a diff against the current OpenShift groups.
+// Groups and members that are added start with '+' and the ones removed with '-'.
+func printGroupChanges(out io.Writer, changes []syncgroups.GroupChange) error {
+	var added, updated, pruned, addedUsers, removedUsers int
+	for _, change := range changes {
+		var err error
+		switch change.Type {
+		case syncgroups.GroupAdded:
+			added++
+			_, err = fmt.Fprintf(out, "+ group/%s (%s)\n", change.Name, change.LDAPGroupUID)
+		case syncgroups.GroupPruned:
+			pruned++
+			_, err = fmt.Fprintf(out, "- group/%s (%s)\n", change.Name, change.LDAPGroupUID)
+		case syncgroups.GroupUpdated:
+			updated++
+			_, err = fmt.Fprintf(out, "  group/%s (%s)\n", change.Name, change.LDAPGroupUID)
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, user := range change.AddedUsers {
+			addedUsers++
+			if _, err := fmt.Fprintf(out, "+     %s\n", user); err != nil {
+				return err
+			}
+		}
+		for _, user := range change.RemovedUsers {
+			removedUsers++
+			if _, err := fmt.Fprintf(out, "-     %s\n", user); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintf(out, "# %d groups added, %d updated, %d pruned, %d unchanged; %d members added, %d removed\n",
+		added, updated, pruned, len(changes)-added-updated-pruned, addedUsers, removedUsers)
+	return err
+}
@@ -9,6 +9,7 @@ import (
 	"k8s.io/client-go/discovery"
 
 	kerrs "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
@@ -33,6 +34,10 @@ var (
 		describe how data is requested from the external record store. Default behavior is to indicate all OpenShift groups
 		for which the external record does not exist, to run the pruning process and commit the results, use the --confirm
 		flag.
+
+		Use -o json or -o yaml to report the groups that are pruned because their external record is missing, and the
+		groups that are kept because they are blacklisted, along with the members of the pruned groups. Use --backup-file
+		to write the groups to a file before they are pruned, so that they can be restored with 'oc apply -f'.
 	`)
 
 	pruneExamples = templates.Examples(`
@@ -47,6 +52,12 @@ var (
 
 		# Prune all orphaned groups from a list of specific groups specified in a list
 		oc adm %[1]s groups/group_name groups/other_name --sync-config=/path/to/ldap-sync-config.yaml --confirm
+
+		# Report the groups that would be pruned and why as JSON
+		oc adm %[1]s --blacklist=/path/to/denylist.txt --sync-config=/path/to/ldap-sync-config.yaml -o json
+
+		# Prune all orphaned groups after saving them to a file they can be restored from
+		oc adm %[1]s --sync-config=/path/to/ldap-sync-config.yaml --backup-file=pruned-groups.yaml --confirm
 	`)
 )
 
@@ -66,6 +77,11 @@ type PruneOptions struct {
 	// Confirm determines whether or not to write to OpenShift
 	Confirm bool
 
+	// Output is the format of the report of the pruned groups, json or yaml
+	Output string
+	// BackupFile is the file the pruned groups are written to before they are deleted
+	BackupFile string
+
 	// GroupClient is the interface used to interact with OpenShift Group objects
 	GroupClient     userv1typedclient.GroupsGetter
 	DiscoveryClient discovery.DiscoveryInterface
@@ -103,6 +119,9 @@ func NewCmdPruneGroups(name, fullName string, f kcmdutil.Factory, streams generi
 	cmd.Flags().StringVar(&o.ConfigFile, "sync-config", o.ConfigFile, "path to the sync config")
 	cmd.MarkFlagFilename("sync-config", "yaml", "yml")
 	cmd.Flags().BoolVar(&o.Confirm, "confirm", o.Confirm, "if true, modify OpenShift groups; if false, display groups")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format of the report of the pruned and blacklisted groups. One of: json|yaml.")
+	cmd.Flags().StringVar(&o.BackupFile, "backup-file", o.BackupFile, "path to write the groups to before they are pruned, to restore them with 'oc apply -f'")
+	cmd.MarkFlagFilename("backup-file", "yaml", "yml")
 
 	return cmd
 }
@@ -145,6 +164,11 @@ func (o *PruneOptions) Validate() error {
 	if o.GroupClient == nil {
 		results.Errors = append(results.Errors, field.Required(field.NewPath("groupInterface"), ""))
 	}
+	switch o.Output {
+	case "", "json", "yaml":
+	default:
+		return fmt.Errorf("--output must be one of json or yaml")
+	}
 	// TODO(skuznets): pretty-print validation results
 	if len(results.Errors) > 0 {
 		return fmt.Errorf("validation of LDAP sync config failed: %v", results.Errors.ToAggregate())
@@ -197,10 +221,58 @@ func (o *PruneOptions) Run() error {
 		return err
 	}
 
-	// Now we run the pruner and report any errors
-	pruneErrors := pruner.Prune()
+	if len(o.Output) == 0 && len(o.BackupFile) == 0 {
+		// Now we run the pruner and report any errors
+		pruneErrors := pruner.Prune()
+		return kerrs.NewAggregate(pruneErrors)
+	}
+
+	// determine the groups first, so that exactly the groups backed up and reported are pruned
+	orphans, pruneErrors := pruner.Orphans()
+	if len(o.BackupFile) > 0 {
+		if err := writeGroupBackup(o.BackupFile, pruner.GroupClient, orphans); err != nil {
+			return kerrs.NewAggregate(append(pruneErrors, err))
+		}
+	}
+	deleteErrs := map[string]error{}
+	for _, orphan := range orphans {
+		if err := pruner.DeleteGroup(orphan.Name); err != nil {
+			deleteErrs[orphan.Name] = err
+			pruneErrors = append(pruneErrors, err)
+			continue
+		}
+		if len(o.Output) == 0 {
+			fmt.Fprintf(o.Out, "group/%s\n", orphan.Name)
+		}
+	}
+	if len(o.Output) == 0 {
+		return kerrs.NewAggregate(pruneErrors)
+	}
+
+	excluded, err := syncgroups.ExcludedGroups(pruner.GroupClient, o.blacklistedCandidates(), clientConfig.Host())
+	if err != nil {
+		pruneErrors = append(pruneErrors, err)
+	}
+	if err := printPruneReport(o.Out, o.Output, newPruneReport(!o.Confirm, orphans, excluded, deleteErrs)); err != nil {
+		pruneErrors = append(pruneErrors, err)
+	}
 	return kerrs.NewAggregate(pruneErrors)
+}
 
+// blacklistedCandidates returns the blacklisted groups that would otherwise be considered for
+// pruning: all of them, or those of the whitelist when there is one.
+func (o *PruneOptions) blacklistedCandidates() []string {
+	if len(o.Whitelist) == 0 {
+		return o.Blacklist
+	}
+	whitelist := sets.NewString(o.Whitelist...)
+	candidates := []string{}
+	for _, name := range o.Blacklist {
+		if whitelist.Has(name) {
+			candidates = append(candidates, name)
+		}
+	}
+	return candidates
 }
 
 func buildPruneBuilder(ldapClient ldap.Client, pruneConfig *legacyconfigv1.LDAPSyncConfig) (PruneBuilder, error) {
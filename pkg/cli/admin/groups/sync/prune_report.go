@@ -0,0 +1,111 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	userv1 "github.com/openshift/api/user/v1"
+	userv1typedclient "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
+	syncgroups "github.com/openshift/oc/pkg/helpers/groupsync"
+)
+
+const (
+	// pruneReasonMissing is the reason of a group whose LDAP record no longer exists
+	pruneReasonMissing = "MissingFromLDAP"
+	// pruneReasonBlacklisted is the reason of a group kept because it is blacklisted
+	pruneReasonBlacklisted = "Blacklisted"
+)
+
+// pruneReport lists the groups a prune job deletes or keeps, printed with -o json or -o yaml.
+type pruneReport struct {
+	// DryRun is true when the groups were not deleted, without --confirm.
+	DryRun bool          `json:"dryRun"`
+	Groups []prunedGroup `json:"groups"`
+}
+
+type prunedGroup struct {
+	Name         string `json:"name"`
+	LDAPGroupUID string `json:"ldapGroupUID,omitempty"`
+	// Pruned is whether the group is deleted, or would be with --confirm.
+	Pruned bool `json:"pruned"`
+	// Reason is MissingFromLDAP for a pruned group, and Blacklisted for a group kept by the
+	// blacklist.
+	Reason string `json:"reason"`
+	// Users are the members of a pruned group.
+	Users []string `json:"users,omitempty"`
+	// Error is set when the group could not be deleted.
+	Error string `json:"error,omitempty"`
+}
+
+func newPruneReport(dryRun bool, orphans, excluded []syncgroups.GroupChange, deleteErrs map[string]error) *pruneReport {
+	report := &pruneReport{DryRun: dryRun, Groups: []prunedGroup{}}
+	for _, change := range orphans {
+		group := prunedGroup{
+			Name:         change.Name,
+			LDAPGroupUID: change.LDAPGroupUID,
+			Pruned:       true,
+			Reason:       pruneReasonMissing,
+			Users:        change.RemovedUsers,
+		}
+		if err := deleteErrs[change.Name]; err != nil {
+			group.Error = err.Error()
+		}
+		report.Groups = append(report.Groups, group)
+	}
+	for _, change := range excluded {
+		report.Groups = append(report.Groups, prunedGroup{
+			Name:         change.Name,
+			LDAPGroupUID: change.LDAPGroupUID,
+			Reason:       pruneReasonBlacklisted,
+		})
+	}
+	return report
+}
+
+func printPruneReport(out io.Writer, format string, report *pruneReport) error {
+	var data []byte
+	var err error
+	if format == "yaml" {
+		data, err = yaml.Marshal(report)
+	} else {
+		data, err = json.MarshalIndent(report, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+// writeGroupBackup writes the groups about to be pruned to a file as they are stored, so that
+// they can be restored with oc apply -f if the prune was a mistake.
+func writeGroupBackup(path string, client userv1typedclient.GroupInterface, orphans []syncgroups.GroupChange) error {
+	buf := &bytes.Buffer{}
+	for _, change := range orphans {
+		group, err := client.Get(context.TODO(), change.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("unable to back up group %q: %v", change.Name, err)
+		}
+		group = group.DeepCopy()
+		group.TypeMeta = metav1.TypeMeta{APIVersion: userv1.GroupVersion.String(), Kind: "Group"}
+		group.ResourceVersion = ""
+		group.UID = ""
+		group.CreationTimestamp = metav1.Time{}
+		group.ManagedFields = nil
+		data, err := yaml.Marshal(group)
+		if err != nil {
+			return err
+		}
+		buf.WriteString("---\n")
+		buf.Write(data)
+	}
+	return os.WriteFile(path, buf.Bytes(), 0600)
+}
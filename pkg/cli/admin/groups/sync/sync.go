@@ -25,6 +25,7 @@ import (
 	"k8s.io/kubectl/pkg/util/templates"
 
 	legacyconfigv1 "github.com/openshift/api/legacyconfig/v1"
+	userv1 "github.com/openshift/api/user/v1"
 	userv1typedclient "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
 	"github.com/openshift/library-go/pkg/config/helpers"
 	"github.com/openshift/library-go/pkg/security/ldapclient"
@@ -47,12 +48,19 @@ var (
 		requested from the external record store and migrated to OpenShift records. Default behavior is to do a dry-run
 		without changing OpenShift records. Passing '--confirm' will sync all groups from the LDAP server returned by the
 		LDAP query templates.
+
+		Passing '--dry-run -o diff' compares the results of the sync with the current OpenShift groups and shows which
+		groups and members would be added or removed, as well as the previously synced groups that would be pruned
+		because their LDAP records no longer exist, without changing any OpenShift records.
 	`)
 
 	syncExamples = templates.Examples(`
 		# Sync all groups with an LDAP server
 		oc adm groups sync --sync-config=/path/to/ldap-sync-config.yaml --confirm
 
+		# Show how syncing all groups with an LDAP server would change the OpenShift groups
+		oc adm groups sync --sync-config=/path/to/ldap-sync-config.yaml --dry-run -o diff
+
 		# Sync all groups except the ones from the blacklist file with an LDAP server
 		oc adm groups sync --blacklist=/path/to/blacklist.txt --sync-config=/path/to/ldap-sync-config.yaml --confirm
 
@@ -102,6 +110,10 @@ type SyncOptions struct {
 
 	// Confirm determines whether or not to write to OpenShift
 	Confirm bool
+	// DryRun explicitly requests the default behavior of not writing to OpenShift
+	DryRun bool
+	// Diff prints the changes the sync and a prune would make to OpenShift instead of the groups
+	Diff bool
 
 	// GroupClient is the interface used to interact with OpenShift Group objects
 	GroupClient     userv1typedclient.GroupsGetter
@@ -143,6 +155,7 @@ func NewCmdSync(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.C
 	cmd.MarkFlagFilename("sync-config", "yaml", "yml")
 	cmd.Flags().StringVar(&o.Type, "type", o.Type, "which groups white- and blacklist entries refer to: "+strings.Join(AllowedSourceTypes, ","))
 	cmd.Flags().BoolVar(&o.Confirm, "confirm", o.Confirm, "if true, modify OpenShift groups; if false, display results of a dry-run")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun, "if true, display results of a dry-run; may not be combined with --confirm")
 
 	o.PrintFlags.AddFlags(cmd)
 
@@ -197,6 +210,10 @@ func (o *SyncOptions) Complete(f kcmdutil.Factory, args []string) error {
 	if err != nil {
 		return err
 	}
+	if o.PrintFlags.OutputFormat != nil && *o.PrintFlags.OutputFormat == "diff" {
+		o.Diff = true
+		return nil
+	}
 	if !o.Confirm {
 		o.PrintFlags.Complete("%s (dry run)")
 	}
@@ -323,6 +340,12 @@ func (o *SyncOptions) Validate() error {
 	if !ValidateSource(o.Source) {
 		return fmt.Errorf("sync source must be one of the following: %v", strings.Join(AllowedSourceTypes, ","))
 	}
+	if o.Confirm && o.DryRun {
+		return fmt.Errorf("--confirm and --dry-run may not both be specified")
+	}
+	if o.Confirm && o.Diff {
+		return fmt.Errorf("--output=diff may only be used with a dry-run")
+	}
 
 	results := ldapsync.ValidateLDAPSyncConfig(o.Config)
 	if o.GroupClient == nil {
@@ -422,7 +445,17 @@ func (o *SyncOptions) Run() error {
 
 	// Now we run the Syncer and report any errors
 	openshiftGroups, syncErrors := syncer.Sync()
-	if !o.Confirm {
+	if o.Diff {
+		changes, err := syncgroups.DiffGroups(o.GroupClient.Groups(), openshiftGroups)
+		if err != nil {
+			return err
+		}
+		orphans, pruneErrors := o.orphanedGroups(ldapClient, clientConfig.Host(), openshiftGroups)
+		syncErrors = append(syncErrors, pruneErrors...)
+		if err := printGroupChanges(o.Out, append(changes, orphans...)); err != nil {
+			return err
+		}
+	} else if !o.Confirm {
 		list := &unstructured.UnstructuredList{
 			Object: map[string]interface{}{
 				"kind":       "List",
@@ -449,6 +482,57 @@ func (o *SyncOptions) Run() error {
 	return kerrs.NewAggregate(syncErrors)
 }
 
+// orphanedGroups returns the previously synced OpenShift groups selected by the sync that
+// a prune would delete, because their LDAP records no longer exist.
+func (o *SyncOptions) orphanedGroups(ldapClient ldap.Client, host string, synced []*userv1.Group) ([]syncgroups.GroupChange, []error) {
+	pruneBuilder, err := buildPruneBuilder(ldapClient, o.Config)
+	if err != nil {
+		return nil, []error{err}
+	}
+	pruner := &syncgroups.LDAPGroupPruner{
+		Host:        host,
+		GroupClient: o.GroupClient.Groups(),
+		DryRun:      true,
+
+		Out: o.Out,
+		Err: o.ErrOut,
+	}
+	pruner.GroupDetector, err = pruneBuilder.GetGroupDetector()
+	if err != nil {
+		return nil, []error{err}
+	}
+	var listerMapper interfaces.LDAPGroupListerNameMapper
+	if o.Source == GroupSyncSourceOpenShift {
+		listerMapper, err = getOpenShiftGroupListerMapper(host, o)
+		if err != nil {
+			return nil, []error{err}
+		}
+	} else {
+		// the white- and blacklist hold LDAP group UIDs and are applied to the orphans below
+		listerMapper = syncgroups.NewAllOpenShiftGroupLister(nil, host, o.GroupClient.Groups())
+	}
+	pruner.GroupLister = listerMapper
+	pruner.GroupNameMapper = listerMapper
+
+	orphans, errs := pruner.Orphans()
+	if o.Source != GroupSyncSourceLDAP {
+		return orphans, errs
+	}
+	whitelist, blacklist := sets.NewString(o.Whitelist...), sets.NewString(o.Blacklist...)
+	syncedNames := sets.NewString()
+	for _, group := range synced {
+		syncedNames.Insert(group.Name)
+	}
+	var selected []syncgroups.GroupChange
+	for _, orphan := range orphans {
+		if (whitelist.Len() > 0 && !whitelist.Has(orphan.LDAPGroupUID)) || blacklist.Has(orphan.LDAPGroupUID) || syncedNames.Has(orphan.Name) {
+			continue
+		}
+		selected = append(selected, orphan)
+	}
+	return selected, errs
+}
+
 func buildSyncBuilder(ldapClient ldap.Client, syncConfig *legacyconfigv1.LDAPSyncConfig, errorHandler syncerror.Handler) (SyncBuilder, error) {
 	switch {
 	case syncConfig.RFC2307Config != nil:
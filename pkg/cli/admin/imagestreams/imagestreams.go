@@ -0,0 +1,29 @@
+package imagestreams
+
+import (
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var imageStreamsLong = templates.LongDesc(`
+	Manage the image streams of the cluster.
+
+	These commands help administrators understand how the tags of the image streams are
+	used before cleaning them up.`)
+
+// NewCommandImageStreams implements the imagestreams command and its subcommands.
+func NewCommandImageStreams(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	cmds := &cobra.Command{
+		Use:   "imagestreams",
+		Short: "Manage the image streams of the cluster",
+		Long:  imageStreamsLong,
+		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+	cmds.AddCommand(
+		NewCmdUsage(f, streams),
+	)
+	return cmds
+}
@@ -0,0 +1,499 @@
+package imagestreams
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	appsv1client "github.com/openshift/client-go/apps/clientset/versioned/typed/apps/v1"
+	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+	"github.com/openshift/library-go/pkg/build/buildutil"
+	triggerutil "github.com/openshift/library-go/pkg/image/trigger"
+)
+
+var (
+	usageLong = templates.LongDesc(`
+		Show which workloads reference each tag of the image streams and when it was last pulled.
+
+		For every tag of the image streams of the namespace, or of all the namespaces with
+		--all-namespaces, the deployments, replica sets, stateful sets, daemon sets, jobs, cron
+		jobs, pods, deployment configs and build configs referencing it are listed. A workload
+		references a tag when one of its containers uses the pull spec of the tag or of an image
+		of its history, or when one of its image triggers, or the input or output of a build
+		config, is the image stream tag. A pod also references the images its containers run,
+		by digest. Workloads of other namespaces are only found with --all-namespaces.
+
+		The image registry does not record when images are pulled, so the last pull shown is
+		the last Pulled event of the kubelet for one of these pull specs. Events are only kept
+		for a few hours: a tag that was not pulled recently shows <unknown>.
+
+		Pass --unused to only list the tags that nothing references and that were not pulled
+		recently, which are candidates for deletion with 'oc tag -d'.`)
+
+	usageExample = templates.Examples(`
+		# Show the usage of the tags of the image streams of the current namespace
+		oc adm imagestreams usage
+
+		# Show the usage of the tags of the image stream ruby
+		oc adm imagestreams usage ruby
+
+		# List the tags of all the image streams that nothing references
+		oc adm imagestreams usage --all-namespaces --unused`)
+)
+
+type UsageOptions struct {
+	Namespace     string
+	AllNamespaces bool
+	Names         []string
+	Unused        bool
+
+	ImageClient imagev1client.ImageV1Interface
+	KubeClient  kubernetes.Interface
+	AppsClient  appsv1client.AppsV1Interface
+	BuildClient buildv1client.BuildV1Interface
+
+	genericiooptions.IOStreams
+}
+
+func NewCmdUsage(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := &UsageOptions{IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:     "usage [IMAGESTREAM...]",
+		Short:   "Show the workloads referencing the tags of image streams and their last pulls",
+		Long:    usageLong,
+		Example: usageExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run(context.TODO()))
+		},
+	}
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "Show the image streams of all the namespaces, and find the workloads referencing them in all the namespaces.")
+	cmd.Flags().BoolVar(&o.Unused, "unused", o.Unused, "Only show the tags that no workload references and that were not pulled recently.")
+	return cmd
+}
+
+func (o *UsageOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if o.AllNamespaces && len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "image streams may not be named with --all-namespaces")
+	}
+	o.Names = args
+
+	var err error
+	if o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace(); err != nil {
+		return err
+	}
+	if o.AllNamespaces {
+		o.Namespace = metav1.NamespaceAll
+	}
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.ImageClient, err = imagev1client.NewForConfig(config); err != nil {
+		return err
+	}
+	if o.KubeClient, err = kubernetes.NewForConfig(config); err != nil {
+		return err
+	}
+	if o.AppsClient, err = appsv1client.NewForConfig(config); err != nil {
+		return err
+	}
+	o.BuildClient, err = buildv1client.NewForConfig(config)
+	return err
+}
+
+func (o *UsageOptions) Run(ctx context.Context) error {
+	var streams []imagev1.ImageStream
+	if len(o.Names) > 0 {
+		for _, name := range o.Names {
+			stream, err := o.ImageClient.ImageStreams(o.Namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			streams = append(streams, *stream)
+		}
+	} else {
+		list, err := o.ImageClient.ImageStreams(o.Namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		streams = list.Items
+	}
+
+	referrers, err := o.referrers(ctx)
+	if err != nil {
+		return err
+	}
+	events, err := o.KubeClient.CoreV1().Events(o.Namespace).List(ctx, metav1.ListOptions{FieldSelector: "reason=Pulled"})
+	if err != nil {
+		return fmt.Errorf("unable to list the Pulled events: %v", err)
+	}
+
+	now := time.Now()
+	w := printers.GetNewTabWriter(o.Out)
+	printed := 0
+	for _, usage := range tagUsages(streams, referrers, pulledImages(events.Items)) {
+		if o.Unused && usage.used() {
+			continue
+		}
+		if printed == 0 {
+			fmt.Fprintln(w, "IMAGESTREAM\tTAG\tUPDATED\tREFERENCED BY\tLAST PULLED")
+		}
+		printed++
+		stream := usage.ImageStream
+		if o.AllNamespaces {
+			stream = usage.Namespace + "/" + stream
+		}
+		referencedBy := "<none>"
+		if len(usage.ReferencedBy) > 0 {
+			referencedBy = strings.Join(usage.ReferencedBy, ",")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", stream, usage.Tag, ago(now, usage.Updated), referencedBy, ago(now, usage.LastPulled))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if printed == 0 {
+		if o.Unused {
+			fmt.Fprintln(o.ErrOut, "No unused image stream tags found.")
+		} else {
+			fmt.Fprintln(o.ErrOut, "No image stream tags found.")
+		}
+	}
+	return nil
+}
+
+func ago(now, t time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(now.Sub(t)) + " ago"
+}
+
+// referrers lists the workloads, pods and build configs that may reference image stream tags.
+// The pods, jobs and replica sets are listed as well as their owners, since they may still run
+// an image their owner no longer references. Deployment configs and build configs are skipped
+// when their API is not enabled.
+func (o *UsageOptions) referrers(ctx context.Context) ([]*referrer, error) {
+	var referrers []*referrer
+	deployments, err := o.KubeClient.AppsV1().Deployments(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		referrers = append(referrers, podTemplateReferrer(d, "deployment", &d.Spec.Template.Spec))
+	}
+	replicaSets, err := o.KubeClient.AppsV1().ReplicaSets(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range replicaSets.Items {
+		r := &replicaSets.Items[i]
+		referrers = append(referrers, podTemplateReferrer(r, "replicaset", &r.Spec.Template.Spec))
+	}
+	statefulSets, err := o.KubeClient.AppsV1().StatefulSets(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range statefulSets.Items {
+		s := &statefulSets.Items[i]
+		referrers = append(referrers, podTemplateReferrer(s, "statefulset", &s.Spec.Template.Spec))
+	}
+	daemonSets, err := o.KubeClient.AppsV1().DaemonSets(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range daemonSets.Items {
+		d := &daemonSets.Items[i]
+		referrers = append(referrers, podTemplateReferrer(d, "daemonset", &d.Spec.Template.Spec))
+	}
+	jobs, err := o.KubeClient.BatchV1().Jobs(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range jobs.Items {
+		j := &jobs.Items[i]
+		referrers = append(referrers, podTemplateReferrer(j, "job", &j.Spec.Template.Spec))
+	}
+	cronJobs, err := o.KubeClient.BatchV1().CronJobs(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range cronJobs.Items {
+		c := &cronJobs.Items[i]
+		referrers = append(referrers, podTemplateReferrer(c, "cronjob", &c.Spec.JobTemplate.Spec.Template.Spec))
+	}
+	pods, err := o.KubeClient.CoreV1().Pods(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range pods.Items {
+		referrers = append(referrers, podReferrer(&pods.Items[i]))
+	}
+
+	deploymentConfigs, err := o.AppsClient.DeploymentConfigs(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		for i := range deploymentConfigs.Items {
+			referrers = append(referrers, deploymentConfigReferrer(&deploymentConfigs.Items[i]))
+		}
+	}
+	buildConfigs, err := o.BuildClient.BuildConfigs(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		for i := range buildConfigs.Items {
+			referrers = append(referrers, buildConfigReferrer(&buildConfigs.Items[i]))
+		}
+	}
+	return referrers, nil
+}
+
+// referrer is an object referencing images, by pull spec or through image stream tags.
+type referrer struct {
+	Namespace, Kind, Name string
+	// ImageStreamTags are the image stream tags referenced, as namespace/name:tag.
+	ImageStreamTags sets.String
+	// Images are the pull specs of the images referenced.
+	Images sets.String
+}
+
+func newReferrer(namespace, kind, name string) *referrer {
+	return &referrer{
+		Namespace:       namespace,
+		Kind:            kind,
+		Name:            name,
+		ImageStreamTags: sets.NewString(),
+		Images:          sets.NewString(),
+	}
+}
+
+// String returns the kind and name of the referrer, prefixed with its namespace when it is
+// not in the namespace of the image stream.
+func (r *referrer) String(namespace string) string {
+	if r.Namespace != namespace {
+		return fmt.Sprintf("%s/%s/%s", r.Namespace, r.Kind, r.Name)
+	}
+	return fmt.Sprintf("%s/%s", r.Kind, r.Name)
+}
+
+func (r *referrer) addObjectReference(ref *corev1.ObjectReference) {
+	if ref == nil {
+		return
+	}
+	switch ref.Kind {
+	case "ImageStreamTag":
+		namespace := ref.Namespace
+		if len(namespace) == 0 {
+			namespace = r.Namespace
+		}
+		r.ImageStreamTags.Insert(namespace + "/" + ref.Name)
+	case "DockerImage":
+		r.Images.Insert(ref.Name)
+	}
+}
+
+func (r *referrer) addPodSpec(spec *corev1.PodSpec) {
+	for _, container := range spec.InitContainers {
+		r.Images.Insert(container.Image)
+	}
+	for _, container := range spec.Containers {
+		r.Images.Insert(container.Image)
+	}
+}
+
+// podTemplateReferrer returns the images of the pod template and the image stream tags of
+// the image trigger annotation of the object.
+func podTemplateReferrer(obj metav1.Object, kind string, spec *corev1.PodSpec) *referrer {
+	r := newReferrer(obj.GetNamespace(), kind, obj.GetName())
+	r.addPodSpec(spec)
+	if annotation, ok := obj.GetAnnotations()[triggerutil.TriggerAnnotationKey]; ok {
+		var triggers []triggerutil.ObjectFieldTrigger
+		if err := json.Unmarshal([]byte(annotation), &triggers); err == nil {
+			for _, trigger := range triggers {
+				kind := trigger.From.Kind
+				if len(kind) == 0 {
+					kind = "ImageStreamTag"
+				}
+				r.addObjectReference(&corev1.ObjectReference{Kind: kind, Namespace: trigger.From.Namespace, Name: trigger.From.Name})
+			}
+		}
+	}
+	return r
+}
+
+// podReferrer returns the images of the containers of the pod and the images they run, which
+// the kubelet reports by digest.
+func podReferrer(pod *corev1.Pod) *referrer {
+	r := newReferrer(pod.Namespace, "pod", pod.Name)
+	r.addPodSpec(&pod.Spec)
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, status := range statuses {
+			if len(status.Image) > 0 {
+				r.Images.Insert(status.Image)
+			}
+			if imageID := strings.TrimPrefix(status.ImageID, "docker-pullable://"); len(imageID) > 0 {
+				r.Images.Insert(imageID)
+			}
+		}
+	}
+	return r
+}
+
+func deploymentConfigReferrer(dc *appsv1.DeploymentConfig) *referrer {
+	r := newReferrer(dc.Namespace, "deploymentconfig", dc.Name)
+	if dc.Spec.Template != nil {
+		r.addPodSpec(&dc.Spec.Template.Spec)
+	}
+	for _, trigger := range dc.Spec.Triggers {
+		if trigger.Type == appsv1.DeploymentTriggerOnImageChange && trigger.ImageChangeParams != nil {
+			from := trigger.ImageChangeParams.From
+			r.addObjectReference(&from)
+		}
+	}
+	return r
+}
+
+// buildConfigReferrer returns the images the build config builds from, pushes to, takes
+// sources from and is triggered by.
+func buildConfigReferrer(bc *buildv1.BuildConfig) *referrer {
+	r := newReferrer(bc.Namespace, "buildconfig", bc.Name)
+	r.addObjectReference(buildutil.GetInputReference(bc.Spec.Strategy))
+	r.addObjectReference(bc.Spec.Output.To)
+	for i := range bc.Spec.Source.Images {
+		r.addObjectReference(&bc.Spec.Source.Images[i].From)
+	}
+	for _, trigger := range bc.Spec.Triggers {
+		if trigger.Type == buildv1.ImageChangeBuildTriggerType && trigger.ImageChange != nil {
+			r.addObjectReference(trigger.ImageChange.From)
+		}
+	}
+	return r
+}
+
+// pulledImages returns the last time each image was pulled according to the Pulled events of
+// the kubelet, such as `Successfully pulled image "IMAGE" in 1.2s` or `Container image "IMAGE"
+// already present on machine`.
+func pulledImages(events []corev1.Event) map[string]time.Time {
+	pulls := make(map[string]time.Time)
+	for _, event := range events {
+		if event.Reason != "Pulled" {
+			continue
+		}
+		parts := strings.SplitN(event.Message, `"`, 3)
+		if len(parts) < 3 || len(parts[1]) == 0 {
+			continue
+		}
+		last := event.LastTimestamp.Time
+		switch {
+		case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+			last = event.Series.LastObservedTime.Time
+		case last.IsZero() && !event.EventTime.IsZero():
+			last = event.EventTime.Time
+		case last.IsZero():
+			last = event.CreationTimestamp.Time
+		}
+		if last.After(pulls[parts[1]]) {
+			pulls[parts[1]] = last
+		}
+	}
+	return pulls
+}
+
+// tagUsage is how a tag of an image stream is used.
+type tagUsage struct {
+	Namespace, ImageStream, Tag string
+	// Updated is when the tag last pointed to a new image.
+	Updated time.Time
+	// ReferencedBy are the referrers of the tag, sorted.
+	ReferencedBy []string
+	// LastPulled is the last pull of the tag found, if any.
+	LastPulled time.Time
+}
+
+func (u tagUsage) used() bool {
+	return len(u.ReferencedBy) > 0 || !u.LastPulled.IsZero()
+}
+
+// tagUsages returns the usage of every tag of the image streams, sorted by image stream and tag.
+func tagUsages(streams []imagev1.ImageStream, referrers []*referrer, pulls map[string]time.Time) []tagUsage {
+	var usages []tagUsage
+	for _, stream := range streams {
+		for _, tag := range stream.Status.Tags {
+			usage := tagUsage{Namespace: stream.Namespace, ImageStream: stream.Name, Tag: tag.Tag}
+			if len(tag.Items) > 0 {
+				usage.Updated = tag.Items[0].Created.Time
+			}
+			specs := pullSpecs(&stream, tag)
+			key := fmt.Sprintf("%s/%s:%s", stream.Namespace, stream.Name, tag.Tag)
+			for _, r := range referrers {
+				if r.ImageStreamTags.Has(key) || r.Images.HasAny(specs.UnsortedList()...) {
+					usage.ReferencedBy = append(usage.ReferencedBy, r.String(stream.Namespace))
+				}
+			}
+			sort.Strings(usage.ReferencedBy)
+			for spec := range specs {
+				if pulled := pulls[spec]; pulled.After(usage.LastPulled) {
+					usage.LastPulled = pulled
+				}
+			}
+			usages = append(usages, usage)
+		}
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		a, b := usages[i], usages[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.ImageStream != b.ImageStream {
+			return a.ImageStream < b.ImageStream
+		}
+		return a.Tag < b.Tag
+	})
+	return usages
+}
+
+// pullSpecs returns the pull specs of the tag, through the internal and public repositories
+// of the image stream, and of the images of its history by digest.
+func pullSpecs(stream *imagev1.ImageStream, tag imagev1.NamedTagEventList) sets.String {
+	specs := sets.NewString()
+	for _, repository := range []string{stream.Status.DockerImageRepository, stream.Status.PublicDockerImageRepository} {
+		if len(repository) == 0 {
+			continue
+		}
+		specs.Insert(repository + ":" + tag.Tag)
+		for _, item := range tag.Items {
+			specs.Insert(repository + "@" + item.Image)
+		}
+	}
+	for _, item := range tag.Items {
+		if len(item.DockerImageReference) > 0 {
+			specs.Insert(item.DockerImageReference)
+		}
+	}
+	return specs
+}
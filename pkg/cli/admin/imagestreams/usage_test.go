@@ -0,0 +1,114 @@
+package imagestreams
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	fakeappsclient "github.com/openshift/client-go/apps/clientset/versioned/fake"
+	fakebuildclient "github.com/openshift/client-go/build/clientset/versioned/fake"
+	triggerutil "github.com/openshift/library-go/pkg/image/trigger"
+)
+
+const (
+	rubyDigest = "sha256:0000000000000000000000000000000000000000000000000000000000000001"
+	oldDigest  = "sha256:0000000000000000000000000000000000000000000000000000000000000002"
+	repository = "image-registry.openshift-image-registry.svc:5000/app/ruby"
+)
+
+func TestTagUsages(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	stream := imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "ruby"},
+		Status: imagev1.ImageStreamStatus{
+			DockerImageRepository:       repository,
+			PublicDockerImageRepository: "registry.example.com/app/ruby",
+			Tags: []imagev1.NamedTagEventList{
+				{Tag: "latest", Items: []imagev1.TagEvent{
+					{Created: metav1.NewTime(now.Add(-time.Hour)), Image: rubyDigest, DockerImageReference: repository + "@" + rubyDigest},
+					{Created: metav1.NewTime(now.Add(-48 * time.Hour)), Image: oldDigest, DockerImageReference: repository + "@" + oldDigest},
+				}},
+				{Tag: "3.1", Items: []imagev1.TagEvent{{Created: metav1.NewTime(now.Add(-24 * time.Hour)), Image: oldDigest}}},
+				{Tag: "unused", Items: []imagev1.TagEvent{{Created: metav1.NewTime(now.Add(-24 * time.Hour)), Image: "sha256:0000000000000000000000000000000000000000000000000000000000000003"}}},
+			},
+		},
+	}
+
+	byPullSpec := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "web"}}
+	byPullSpec.Spec.Template.Spec.Containers = []corev1.Container{{Name: "web", Image: repository + ":latest"}}
+	byTrigger := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "other",
+		Name:        "worker",
+		Annotations: map[string]string{triggerutil.TriggerAnnotationKey: `[{"from":{"kind":"ImageStreamTag","name":"ruby:3.1","namespace":"app"},"fieldPath":"spec.template.spec.containers[?(@.name==\"worker\")].image"}]`},
+	}}
+	byTrigger.Spec.Template.Spec.Containers = []corev1.Container{{Name: "worker", Image: " "}}
+	build := &buildv1.BuildConfig{ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "site"}}
+	build.Spec.Strategy.SourceStrategy = &buildv1.SourceBuildStrategy{From: corev1.ObjectReference{Kind: "ImageStreamTag", Name: "ruby:latest"}}
+	build.Spec.Output.To = &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "site:latest"}
+
+	referrers := []*referrer{
+		podTemplateReferrer(byPullSpec, "deployment", &byPullSpec.Spec.Template.Spec),
+		podTemplateReferrer(byTrigger, "deployment", &byTrigger.Spec.Template.Spec),
+		buildConfigReferrer(build),
+	}
+	pulls := pulledImages([]corev1.Event{
+		{Reason: "Pulled", Message: `Successfully pulled image "` + repository + "@" + oldDigest + `" in 1.2s`, LastTimestamp: metav1.NewTime(now.Add(-10 * time.Minute))},
+		{Reason: "Pulled", Message: `Container image "` + repository + "@" + oldDigest + `" already present on machine`, LastTimestamp: metav1.NewTime(now.Add(-20 * time.Minute))},
+		{Reason: "Pulling", Message: `Pulling image "` + repository + `:unused"`, LastTimestamp: metav1.NewTime(now)},
+	})
+
+	expected := []tagUsage{
+		{Namespace: "app", ImageStream: "ruby", Tag: "3.1", Updated: now.Add(-24 * time.Hour), ReferencedBy: []string{"other/deployment/worker"}, LastPulled: now.Add(-10 * time.Minute)},
+		{Namespace: "app", ImageStream: "ruby", Tag: "latest", Updated: now.Add(-time.Hour), ReferencedBy: []string{"buildconfig/site", "deployment/web"}, LastPulled: now.Add(-10 * time.Minute)},
+		{Namespace: "app", ImageStream: "ruby", Tag: "unused", Updated: now.Add(-24 * time.Hour)},
+	}
+	usages := tagUsages([]imagev1.ImageStream{stream}, referrers, pulls)
+	if !reflect.DeepEqual(usages, expected) {
+		t.Errorf("expected usages\n%#v\ngot\n%#v", expected, usages)
+	}
+	if usages[2].used() {
+		t.Errorf("expected the tag %s to be unused", usages[2].Tag)
+	}
+}
+
+func TestReferrers(t *testing.T) {
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "migrate"}}
+	job.Spec.Template.Spec.Containers = []corev1.Container{{Name: "migrate", Image: repository + ":3.1"}}
+	replicaSet := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "web-1"}}
+	replicaSet.Spec.Template.Spec.Containers = []corev1.Container{{Name: "web", Image: repository + "@" + oldDigest}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "web-1-abcde"}}
+	pod.Spec.Containers = []corev1.Container{{Name: "web", Image: "registry.example.com/app/web:v1"}}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "web", Image: "registry.example.com/app/web:v1", ImageID: "docker-pullable://" + repository + "@" + rubyDigest}}
+
+	o := &UsageOptions{
+		Namespace:   "app",
+		KubeClient:  fake.NewSimpleClientset(job, replicaSet, pod),
+		AppsClient:  fakeappsclient.NewSimpleClientset().AppsV1(),
+		BuildClient: fakebuildclient.NewSimpleClientset().BuildV1(),
+	}
+	referrers, err := o.referrers(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	images := make(map[string][]string)
+	for _, r := range referrers {
+		images[r.String("app")] = r.Images.List()
+	}
+	expected := map[string][]string{
+		"job/migrate":      {repository + ":3.1"},
+		"replicaset/web-1": {repository + "@" + oldDigest},
+		"pod/web-1-abcde":  {repository + "@" + rubyDigest, "registry.example.com/app/web:v1"},
+	}
+	if !reflect.DeepEqual(images, expected) {
+		t.Errorf("expected the images\n%v\ngot\n%v", expected, images)
+	}
+}
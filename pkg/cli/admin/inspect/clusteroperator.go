@@ -0,0 +1,187 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/klog/v2"
+)
+
+// relatedObjectsMaxDepth bounds how far the related objects of a cluster operator are followed.
+const relatedObjectsMaxDepth = 5
+
+// clusterOperatorEventsDirname holds the events of the namespaces of every cluster operator
+// inspected. It is not named events.yaml, so that the events are not listed twice in events.html.
+const clusterOperatorEventsDirname = "clusteroperator-events"
+
+// gatherClusterOperatorClosure gathers the objects the cluster operator relates to, the objects
+// those relate to in turn, and the namespaces of all of them with the logs of their pods. The
+// namespace of the operator, openshift-<name>-operator by convention, is gathered with them
+// when it exists. The events of these namespaces are then written in chronological order in a
+// single file for the operator, from --since or --since-time when one is given.
+func gatherClusterOperatorClosure(ctx *resourceContext, co *unstructured.Unstructured, o *InspectOptions) error {
+	errs := []error{}
+	infos, namespaces, err := relatedObjectsClosure(co, func(ref *configv1.ObjectReference) ([]*resource.Info, error) {
+		return objectReferenceToResourceInfos(o.configFlags, ref)
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	operatorNamespace := fmt.Sprintf("openshift-%s-operator", co.GetName())
+	if _, err := o.kubeClient.CoreV1().Namespaces().Get(context.TODO(), operatorNamespace, metav1.GetOptions{}); err == nil {
+		namespaces.Insert(operatorNamespace)
+	} else if !apierrors.IsNotFound(err) {
+		errs = append(errs, err)
+	}
+
+	// the deepest objects are inspected first, so that inspecting an object finds the objects
+	// it relates to already gathered instead of reading them again
+	for i := len(infos) - 1; i >= 0; i-- {
+		if err := InspectResource(infos[i], ctx, o); err != nil {
+			errs = append(errs, fmt.Errorf("skipping gathering %s due to error: %v", infoToContextKey(infos[i]), err))
+		}
+	}
+	if err := gatherNamespaces(ctx, o, namespaces.List()...); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := o.gatherClusterOperatorEvents(co.GetName(), namespaces.List()); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.NewAggregate(errs)
+}
+
+// relatedObjectsClosure follows the related objects of the object, and of the objects it relates
+// to, up to relatedObjectsMaxDepth. It returns the objects reached, closest first, and the
+// namespaces they are in or are.
+func relatedObjectsClosure(obj *unstructured.Unstructured, getInfos func(ref *configv1.ObjectReference) ([]*resource.Info, error)) ([]*resource.Info, sets.String, error) {
+	errs := []error{}
+	namespaces := sets.NewString()
+	seenRefs, seenInfos := sets.NewString(), sets.NewString()
+	var infos []*resource.Info
+
+	refs, err := obtainRelatedObjects(obj)
+	if err != nil {
+		return nil, namespaces, err
+	}
+	for depth := 0; len(refs) > 0 && depth < relatedObjectsMaxDepth; depth++ {
+		var next []*configv1.ObjectReference
+		for _, ref := range refs {
+			if seenRefs.Has(objectRefToContextKey(ref)) {
+				continue
+			}
+			seenRefs.Insert(objectRefToContextKey(ref))
+
+			refInfos, err := getInfos(ref)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("skipping gathering %s due to error: %v", objectReferenceToString(ref), err))
+				continue
+			}
+			for _, info := range refInfos {
+				if seenInfos.Has(infoToContextKey(info)) {
+					continue
+				}
+				seenInfos.Insert(infoToContextKey(info))
+				infos = append(infos, info)
+
+				if len(info.Namespace) > 0 {
+					namespaces.Insert(info.Namespace)
+				} else if info.ResourceMapping().Resource.GroupResource() == corev1.SchemeGroupVersion.WithResource("namespaces").GroupResource() {
+					namespaces.Insert(info.Name)
+				}
+				if unstr, ok := info.Object.(*unstructured.Unstructured); ok {
+					related, err := obtainRelatedObjects(unstr)
+					if err != nil {
+						errs = append(errs, err)
+						continue
+					}
+					next = append(next, related...)
+				}
+			}
+		}
+		refs = next
+	}
+	if len(refs) > 0 {
+		klog.V(1).Infof("Not following the related objects of %q deeper than %d objects", unstructuredToString(obj), relatedObjectsMaxDepth)
+	}
+	return infos, namespaces, errors.NewAggregate(errs)
+}
+
+// gatherClusterOperatorEvents writes the events of the namespaces of the cluster operator since
+// --since or --since-time, oldest first.
+func (o *InspectOptions) gatherClusterOperatorEvents(name string, namespaces []string) error {
+	errs := []error{}
+	events := &corev1.EventList{}
+	for _, namespace := range namespaces {
+		list, err := o.kubeClient.CoreV1().Events(namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		events.Items = append(events.Items, list.Items...)
+	}
+	events.Items = eventsSince(events.Items, o.sinceCutoff())
+
+	destDir := path.Join(o.DestDir, clusterOperatorEventsDirname)
+	if err := os.MkdirAll(destDir, os.ModePerm); err != nil {
+		errs = append(errs, err)
+		return errors.NewAggregate(errs)
+	}
+	if err := o.fileWriter.WriteFromResource(path.Join(destDir, name+".yaml"), events); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.NewAggregate(errs)
+}
+
+// sinceCutoff returns the time from which the logs and events are gathered, or the zero time
+// when neither --since nor --since-time is given.
+func (o *InspectOptions) sinceCutoff() time.Time {
+	if len(o.sinceTime) > 0 {
+		return o.sinceTimestamp.Time
+	}
+	if o.since != 0 {
+		return time.Now().Add(-o.since)
+	}
+	return time.Time{}
+}
+
+// eventsSince returns the events last seen at or after since, oldest first.
+func eventsSince(events []corev1.Event, since time.Time) []corev1.Event {
+	var filtered []corev1.Event
+	for _, event := range events {
+		if !eventTime(event).Before(since) {
+			filtered = append(filtered, event)
+		}
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return eventTime(filtered[i]).Before(eventTime(filtered[j]))
+	})
+	return filtered
+}
+
+// eventTime returns when the event was last seen.
+func eventTime(event corev1.Event) time.Time {
+	switch {
+	case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+		return event.Series.LastObservedTime.Time
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
@@ -0,0 +1,140 @@
+package inspect
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+func withRelatedObjects(obj *unstructured.Unstructured, refs ...configv1.ObjectReference) *unstructured.Unstructured {
+	var related []interface{}
+	for _, ref := range refs {
+		related = append(related, map[string]interface{}{
+			"group":     ref.Group,
+			"resource":  ref.Resource,
+			"namespace": ref.Namespace,
+			"name":      ref.Name,
+		})
+	}
+	unstructured.SetNestedSlice(obj.Object, related, "status", "relatedObjects")
+	return obj
+}
+
+func newInfo(group, resourceName, namespace, name string) *resource.Info {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	return &resource.Info{
+		Namespace: namespace,
+		Name:      name,
+		Object:    obj,
+		Mapping: &meta.RESTMapping{
+			Resource:         schema.GroupVersionResource{Group: group, Version: "v1", Resource: resourceName},
+			GroupVersionKind: schema.GroupVersionKind{Group: group, Version: "v1", Kind: resourceName},
+		},
+	}
+}
+
+func TestRelatedObjectsClosure(t *testing.T) {
+	operator := newInfo("operator.openshift.io", "kubeapiservers", "", "cluster")
+	withRelatedObjects(operator.Object.(*unstructured.Unstructured),
+		configv1.ObjectReference{Resource: "namespaces", Name: "openshift-kube-apiserver"},
+		configv1.ObjectReference{Group: "example.com", Resource: "widgets", Namespace: "openshift-widgets", Name: "widget"},
+		// the operator relates back to the cluster operator
+		configv1.ObjectReference{Group: "config.openshift.io", Resource: "clusteroperators", Name: "kube-apiserver"},
+	)
+	widget := newInfo("example.com", "widgets", "openshift-widgets", "widget")
+	withRelatedObjects(widget.Object.(*unstructured.Unstructured),
+		configv1.ObjectReference{Group: "example.com", Resource: "gadgets"},
+	)
+	objects := map[string][]*resource.Info{
+		"operator.openshift.io/kubeapiservers/cluster": {operator},
+		"/namespaces/openshift-kube-apiserver":         {newInfo("", "namespaces", "", "openshift-kube-apiserver")},
+		"example.com/widgets/widget":                   {widget},
+		"example.com/gadgets/":                         {newInfo("example.com", "gadgets", "openshift-gadgets", "a"), newInfo("example.com", "gadgets", "openshift-gadgets", "b")},
+		"config.openshift.io/clusteroperators/kube-apiserver": {
+			newInfo("config.openshift.io", "clusteroperators", "", "kube-apiserver"),
+		},
+	}
+
+	co := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	co.SetName("kube-apiserver")
+	withRelatedObjects(co,
+		configv1.ObjectReference{Group: "operator.openshift.io", Resource: "kubeapiservers", Name: "cluster"},
+		configv1.ObjectReference{Resource: "namespaces", Name: "openshift-kube-apiserver"},
+		configv1.ObjectReference{Group: "example.com", Resource: "missing", Name: "missing"},
+	)
+	infos, namespaces, err := relatedObjectsClosure(co, func(ref *configv1.ObjectReference) ([]*resource.Info, error) {
+		infos, ok := objects[fmt.Sprintf("%s/%s/%s", ref.Group, ref.Resource, ref.Name)]
+		if !ok {
+			return nil, fmt.Errorf("not found")
+		}
+		return infos, nil
+	})
+	if err == nil {
+		t.Errorf("expected an error for the missing object")
+	}
+
+	var keys []string
+	for _, info := range infos {
+		keys = append(keys, infoToContextKey(info))
+	}
+	expectedKeys := []string{
+		"/operator.openshift.io/kubeapiservers/cluster",
+		"//namespaces/openshift-kube-apiserver",
+		"openshift-widgets/example.com/widgets/widget",
+		"/config.openshift.io/clusteroperators/kube-apiserver",
+		"openshift-gadgets/example.com/gadgets/a",
+		"openshift-gadgets/example.com/gadgets/b",
+	}
+	if !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("expected objects %v, got %v", expectedKeys, keys)
+	}
+	if expected := []string{"openshift-gadgets", "openshift-kube-apiserver", "openshift-widgets"}; !reflect.DeepEqual(namespaces.List(), expected) {
+		t.Errorf("expected namespaces %v, got %v", expected, namespaces.List())
+	}
+}
+
+func TestEventsSince(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	newEvent := func(name string, event corev1.Event) corev1.Event {
+		event.Name = name
+		return event
+	}
+	events := []corev1.Event{
+		newEvent("old", corev1.Event{LastTimestamp: metav1.NewTime(now.Add(-2 * time.Hour))}),
+		newEvent("recent", corev1.Event{LastTimestamp: metav1.NewTime(now.Add(-10 * time.Minute))}),
+		newEvent("series", corev1.Event{
+			EventTime: metav1.NewMicroTime(now.Add(-3 * time.Hour)),
+			Series:    &corev1.EventSeries{LastObservedTime: metav1.NewMicroTime(now.Add(-20 * time.Minute))},
+		}),
+		newEvent("created", corev1.Event{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(now.Add(-5 * time.Minute))}}),
+	}
+
+	tests := []struct {
+		since    time.Time
+		expected []string
+	}{
+		{since: time.Time{}, expected: []string{"old", "series", "recent", "created"}},
+		{since: now.Add(-time.Hour), expected: []string{"series", "recent", "created"}},
+		{since: now, expected: nil},
+	}
+	for _, test := range tests {
+		var names []string
+		for _, event := range eventsSince(events, test.since) {
+			names = append(names, event.Name)
+		}
+		if !reflect.DeepEqual(names, test.expected) {
+			t.Errorf("since %s: expected %v, got %v", test.since, test.expected, names)
+		}
+	}
+}
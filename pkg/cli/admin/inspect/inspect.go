@@ -31,6 +31,7 @@ import (
 	"k8s.io/kubectl/pkg/util/templates"
 
 	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/oc/pkg/cli/image/workqueue"
 )
 
 var (
@@ -39,6 +40,16 @@ var (
 
 		This command downloads the specified resource and any related
 		resources for the purpose of gathering debugging information.
+
+		For a clusteroperator, the objects it relates to are followed recursively, and
+		the namespaces of all of them are gathered with the logs of their pods, along
+		with the operator namespace. The events of those namespaces are also written,
+		oldest first, to clusteroperator-events/NAME.yaml, limited to the events seen
+		since --since or --since-time when one is given.
+
+		The resources, and the logs of the pods of the namespaces inspected, are
+		gathered in parallel. Pass --parallelism to bound the number of them gathered
+		at the same time.
 	`)
 
 	inspectExample = templates.Examples(`
@@ -76,6 +87,14 @@ type InspectOptions struct {
 	sinceInt       int64
 	sinceTimestamp metav1.Time
 
+	// parallelism is the number of resources, and separately of pods, gathered concurrently
+	parallelism int
+	// resourceQueue inspects the requested resources and podQueue gathers the logs of the pods of
+	// the namespaces inspected. They are separate so that a namespace waiting for its pods never
+	// holds the workers they need.
+	resourceQueue workqueue.Interface
+	podQueue      workqueue.Interface
+
 	// directory where all gathered data will be stored
 	DestDir string
 	// whether or not to allow writes to an existing and populated base directory
@@ -94,6 +113,7 @@ func NewInspectOptions(streams genericiooptions.IOStreams) *InspectOptions {
 		printFlags:  printFlags,
 		configFlags: genericclioptions.NewConfigFlags(true),
 		overwrite:   true,
+		parallelism: 10,
 		IOStreams:   streams,
 	}
 }
@@ -117,6 +137,7 @@ func NewCmdInspect(streams genericiooptions.IOStreams) *cobra.Command {
 	cmd.Flags().BoolVarP(&o.allNamespaces, "all-namespaces", "A", o.allNamespaces, "If present, list the requested object(s) across all namespaces. Namespace in current context is ignored even if specified with --namespace.")
 	cmd.Flags().StringVar(&o.sinceTime, "since-time", o.sinceTime, "Only return logs after a specific date (RFC3339). Defaults to all logs. Only one of since-time / since may be used.")
 	cmd.Flags().DurationVar(&o.since, "since", o.since, "Only return logs newer than a relative duration like 5s, 2m, or 3h. Defaults to all logs. Only one of since-time / since may be used.")
+	cmd.Flags().IntVar(&o.parallelism, "parallelism", o.parallelism, "The number of resources, and of pods whose logs are downloaded, gathered concurrently.")
 	cmd.Flags().BoolVar(&o.rotatedPodLogs, "rotated-pod-logs", o.rotatedPodLogs, "Experimental: If present, retrieve rotated log files that are available for selected pods. This can significantly increase the collected logs size. since/since-time will be matched against the date in the log file name.")
 
 	// The rotated-pod-logs option should be removed once support for retrieving rotated logs is added to kubelet
@@ -194,6 +215,9 @@ func (o *InspectOptions) Validate() error {
 	if len(o.sinceTime) > 0 && o.since != 0 {
 		return fmt.Errorf("at most one of `sinceTime` or `since` may be specified")
 	}
+	if o.parallelism < 1 {
+		return fmt.Errorf("--parallelism must be greater than zero")
+	}
 	return nil
 }
 
@@ -258,14 +282,16 @@ func (o *InspectOptions) Run() error {
 		}
 	}
 
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	o.resourceQueue = workqueue.New(o.parallelism, stopCh)
+	o.podQueue = workqueue.New(o.parallelism, stopCh)
+
 	// finally, gather polymorphic resources specified by the user
 	ctx := NewResourceContext(serverResources)
-	for _, info := range infos {
-		err := InspectResource(info, ctx, o)
-		if err != nil {
-			allErrs = append(allErrs, err)
-		}
-	}
+	allErrs = append(allErrs, forEach(o.resourceQueue, len(infos), func(i int) error {
+		return InspectResource(infos[i], ctx, o)
+	})...)
 
 	// now gather all the events into a single file and produce a unified file
 	if err := CreateEventFilterPage(o.DestDir); err != nil {
@@ -283,11 +309,10 @@ func (o *InspectOptions) Run() error {
 // gatherConfigResourceData gathers all config.openshift.io resources
 func (o *InspectOptions) gatherConfigResourceData(destDir string, ctx *resourceContext) error {
 	// determine if we've already collected configResourceData
-	if ctx.visited.Has(configResourceDataKey) {
+	if !ctx.visit(configResourceDataKey) {
 		klog.V(1).Infof("Skipping previously-collected config.openshift.io resource data")
 		return nil
 	}
-	ctx.visited.Insert(configResourceDataKey)
 
 	klog.V(1).Infof("Gathering config.openshift.io resource data...\n")
 
@@ -326,11 +351,10 @@ func (o *InspectOptions) gatherConfigResourceData(destDir string, ctx *resourceC
 // gatherOperatorResourceData gathers all kubeapiserver.operator.openshift.io resources
 func (o *InspectOptions) gatherOperatorResourceData(destDir string, ctx *resourceContext) error {
 	// determine if we've already collected operatorResourceData
-	if ctx.visited.Has(operatorResourceDataKey) {
+	if !ctx.visit(operatorResourceDataKey) {
 		klog.V(1).Infof("Skipping previously-collected operator.openshift.io resource data")
 		return nil
 	}
-	ctx.visited.Insert(operatorResourceDataKey)
 
 	// ensure destination path exists
 	if err := os.MkdirAll(destDir, os.ModePerm); err != nil {
@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openshift/oc/pkg/cli/image/workqueue"
 )
 
 func TestDirectoryViable(t *testing.T) {
@@ -235,3 +238,28 @@ func errorFuzzyEquals(a, b error) bool {
 	}
 	return strings.Contains(a.Error(), b.Error())
 }
+
+func TestForEach(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	ctx := NewResourceContext(nil)
+	for name, q := range map[string]workqueue.Interface{"serial": nil, "parallel": workqueue.New(4, stopCh)} {
+		t.Run(name, func(t *testing.T) {
+			keys := []string{"a", "b", "a", "c", "b"}
+			var lock sync.Mutex
+			visited := 0
+			errs := forEach(q, len(keys), func(i int) error {
+				if !ctx.visit(name + "/" + keys[i]) {
+					return fmt.Errorf("%d: %s already visited", i, keys[i])
+				}
+				lock.Lock()
+				defer lock.Unlock()
+				visited++
+				return nil
+			})
+			if visited != 3 || len(errs) != 2 {
+				t.Errorf("expected 3 objects visited once and 2 errors, got %d and %v", visited, errs)
+			}
+		})
+	}
+}
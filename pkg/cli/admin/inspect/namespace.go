@@ -76,15 +76,14 @@ func (o *InspectOptions) gatherNamespaceData(baseDir, namespace string) error {
 	klog.V(1).Infof("    Gathering pod data for namespace %q...\n", namespace)
 	// gather specific pod data
 	if pods := resourcesToStore[corev1.SchemeGroupVersion.WithResource("pods")]; pods != nil {
-		for _, pod := range pods.(*unstructured.UnstructuredList).Items {
+		items := pods.(*unstructured.UnstructuredList).Items
+		errs = append(errs, forEach(o.podQueue, len(items), func(i int) error {
+			pod := items[i]
 			klog.V(1).Infof("        Gathering data for pod %q\n", pod.GetName())
 			structuredPod := &corev1.Pod{}
 			runtime.DefaultUnstructuredConverter.FromUnstructured(pod.Object, structuredPod)
-			if err := o.gatherPodData(path.Join(destDir, "/pods/"+pod.GetName()), namespace, structuredPod); err != nil {
-				errs = append(errs, err)
-				continue
-			}
-		}
+			return o.gatherPodData(path.Join(destDir, "/pods/"+pod.GetName()), namespace, structuredPod)
+		})...)
 	}
 
 	if len(errs) > 0 {
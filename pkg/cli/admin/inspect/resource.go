@@ -28,11 +28,10 @@ const (
 // InspectResource receives an object to gather debugging data for, and a context to keep track of
 // already-seen objects when following related-object reference chains.
 func InspectResource(info *resource.Info, context *resourceContext, o *InspectOptions) error {
-	if context.visited.Has(infoToContextKey(info)) {
+	if !context.visit(infoToContextKey(info)) {
 		klog.V(1).Infof("Skipping previously-inspected resource: %q ...", infoToContextKey(info))
 		return nil
 	}
-	context.visited.Insert(infoToContextKey(info))
 
 	switch info.ResourceMapping().Resource.GroupResource() {
 	case configv1.GroupVersion.WithResource("clusteroperators").GroupResource():
@@ -57,8 +56,8 @@ func InspectResource(info *resource.Info, context *resourceContext, o *InspectOp
 			errs = append(errs, err)
 		}
 
-		// obtain associated objects for the current resource
-		if err := gatherRelatedObjects(context, unstr, o); err != nil {
+		// obtain the objects the operator relates to, recursively, with their namespaces
+		if err := gatherClusterOperatorClosure(context, unstr, o); err != nil {
 			errs = append(errs, err)
 		}
 
@@ -71,7 +70,7 @@ func InspectResource(info *resource.Info, context *resourceContext, o *InspectOp
 		}
 		resourcesToCollect := namespaceResourcesToCollect()
 		for _, resource := range resourcesToCollect {
-			if context.visited.Has(resourceToContextKey(resource, info.Name)) {
+			if context.isVisited(resourceToContextKey(resource, info.Name)) {
 				continue
 			}
 			resourceInfos, err := groupResourceToInfos(o.configFlags, resource, info.Name, context.serverResources)
@@ -232,7 +231,7 @@ func gatherRelatedObjects(context *resourceContext, unstr *unstructured.Unstruct
 func gatherMoreObjects(context *resourceContext, o *InspectOptions, relatedObjReferences ...*configv1.ObjectReference) error {
 	errs := []error{}
 	for _, relatedRef := range relatedObjReferences {
-		if context.visited.Has(objectRefToContextKey(relatedRef)) {
+		if context.isVisited(objectRefToContextKey(relatedRef)) {
 			continue
 		}
 
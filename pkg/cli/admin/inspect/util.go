@@ -13,6 +13,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 
 	configv1 "github.com/openshift/api/config/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -26,14 +27,62 @@ import (
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc/pkg/cli/image/workqueue"
 )
 
-// resourceContext is used to keep track of previously seen objects
+// resourceContext is used to keep track of previously seen objects. It is shared by the resources
+// inspected in parallel.
 type resourceContext struct {
+	lock            sync.Mutex
 	visited         sets.String
 	serverResources sets.String
 }
 
+// visit records that the object with the key is inspected, and returns false if it already was.
+func (c *resourceContext) visit(key string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.visited.Has(key) {
+		return false
+	}
+	c.visited.Insert(key)
+	return true
+}
+
+func (c *resourceContext) isVisited(key string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.visited.Has(key)
+}
+
+// forEach calls fn for each index up to n, in parallel on the queue if there is one, and returns
+// the errors in the order of the indices.
+func forEach(q workqueue.Interface, n int, fn func(i int) error) []error {
+	results := make([]error, n)
+	if q == nil {
+		for i := 0; i < n; i++ {
+			results[i] = fn(i)
+		}
+	} else {
+		q.Batch(func(w workqueue.Work) {
+			for i := 0; i < n; i++ {
+				i := i
+				w.Parallel(func() {
+					results[i] = fn(i)
+				})
+			}
+		})
+	}
+	var errs []error
+	for _, err := range results {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
 func NewResourceContext(serverResources sets.String) *resourceContext {
 	return &resourceContext{
 		visited:         sets.NewString(),
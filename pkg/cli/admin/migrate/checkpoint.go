@@ -0,0 +1,174 @@
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// checkpointInterval is the minimum time between two writes of the checkpoint file, so that
+// migrating many small objects is not slowed down by writing it.
+const checkpointInterval = 5 * time.Second
+
+// migrateCheckpoint is the progress of a migration, saved to the file of --checkpoint-file so that
+// an interrupted migration resumes where it stopped. It relies on objects being listed in the order
+// of their keys, as --from-key and --to-key do.
+type migrateCheckpoint struct {
+	// Completed are the resources all the objects of which were processed.
+	Completed []string `json:"completed,omitempty"`
+	// Resource is the resource being migrated.
+	Resource string `json:"resource,omitempty"`
+	// Key is the key (namespace/name or name) of the last object of Resource processed along with
+	// all the objects listed before it.
+	Key string `json:"key,omitempty"`
+}
+
+func loadCheckpoint(path string) (*migrateCheckpoint, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &migrateCheckpoint{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	checkpoint := &migrateCheckpoint{}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("unable to read the checkpoint %s: %v", path, err)
+	}
+	return checkpoint, nil
+}
+
+// save replaces the checkpoint file at once, so that an interrupted write does not lose it.
+func (c *migrateCheckpoint) save(path string) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// skip returns whether the object was processed by the migration the checkpoint was saved by.
+func (c *migrateCheckpoint) skip(info *resource.Info) bool {
+	resourceName := checkpointResource(info)
+	if sets.NewString(c.Completed...).Has(resourceName) {
+		return true
+	}
+	return resourceName == c.Resource && checkpointKey(info) <= c.Key
+}
+
+func checkpointResource(info *resource.Info) string {
+	groupResource := info.Mapping.Resource.GroupResource()
+	return groupResource.String()
+}
+
+func checkpointKey(info *resource.Info) string {
+	if len(info.Namespace) > 0 {
+		return info.Namespace + "/" + info.Name
+	}
+	return info.Name
+}
+
+// checkpointer advances the checkpoint as the objects are processed. Objects are processed by
+// several workers, so the checkpoint only moves past an object once all the objects sent to the
+// workers before it are processed. It never moves past an object that failed to migrate, so that
+// a resumed migration retries it.
+type checkpointer struct {
+	path string
+	out  io.Writer
+
+	checkpoint *migrateCheckpoint
+	// next is the sequence number of the first object not processed yet
+	next int
+	// processed are the objects processed after an object not processed yet, by sequence number
+	processed map[int]processedObject
+	// failed is set once the checkpoint reached an object that failed to migrate
+	failed   bool
+	lastSave time.Time
+	saveErr  error
+}
+
+type processedObject struct {
+	info   *resource.Info
+	failed bool
+}
+
+func newCheckpointer(path string, checkpoint *migrateCheckpoint, out io.Writer) *checkpointer {
+	return &checkpointer{
+		path:       path,
+		out:        out,
+		checkpoint: checkpoint,
+		processed:  map[int]processedObject{},
+		lastSave:   time.Now(),
+	}
+}
+
+// done records that the object with the sequence number was processed, and whether it failed to
+// migrate. The info of an object that could not be retrieved is nil.
+func (c *checkpointer) done(seq int, info *resource.Info, failed bool) {
+	c.processed[seq] = processedObject{info: info, failed: failed}
+	advanced := false
+	for {
+		processed, ok := c.processed[c.next]
+		if !ok {
+			break
+		}
+		delete(c.processed, c.next)
+		c.next++
+		if processed.failed {
+			c.failed = true
+		}
+		info := processed.info
+		if c.failed || info == nil || info.Mapping == nil {
+			continue
+		}
+		resourceName := checkpointResource(info)
+		if len(c.checkpoint.Resource) > 0 && c.checkpoint.Resource != resourceName {
+			c.checkpoint.Completed = sets.NewString(append(c.checkpoint.Completed, c.checkpoint.Resource)...).List()
+		}
+		c.checkpoint.Resource = resourceName
+		c.checkpoint.Key = checkpointKey(info)
+		advanced = true
+	}
+	if advanced && time.Since(c.lastSave) >= checkpointInterval {
+		c.save()
+	}
+}
+
+func (c *checkpointer) save() {
+	c.lastSave = time.Now()
+	if err := c.checkpoint.save(c.path); err != nil && c.saveErr == nil {
+		// only report the first failure, the next saves are likely to fail the same way
+		c.saveErr = err
+		fmt.Fprintf(c.out, "warning: unable to save the checkpoint %s: %v\n", c.path, err)
+	}
+}
+
+// finish removes the checkpoint file once all the objects were migrated, since there is nothing
+// left to resume, and saves it otherwise.
+func (c *checkpointer) finish(migrated bool) {
+	if !migrated {
+		c.save()
+		return
+	}
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(c.out, "warning: unable to remove the checkpoint %s: %v\n", c.path, err)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/util/flowcontrol"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/scheme"
 )
@@ -114,6 +115,14 @@ type ResourceOptions struct {
 	FromKey       string
 	ToKey         string
 
+	// CheckpointFile is the file the progress of the migration is saved to and resumed from.
+	CheckpointFile string
+	// QPS and Burst limit the rate objects are saved at, QPS is unlimited if zero.
+	QPS   float32
+	Burst int
+	// ProgressInterval is the interval the progress is reported at on ErrOut, never if zero.
+	ProgressInterval time.Duration
+
 	OverlappingResources []sets.String
 	DefaultExcludes      []schema.GroupResource
 
@@ -136,14 +145,17 @@ type ResourceOptions struct {
 	// If true, Out and ErrOut will be wrapped to make them goroutine safe.
 	SyncOut bool
 
+	checkpoint *migrateCheckpoint
+
 	genericiooptions.IOStreams
 }
 
 func NewResourceOptions(streams genericiooptions.IOStreams) *ResourceOptions {
 	return &ResourceOptions{
-		PrintFlags:    genericclioptions.NewPrintFlags("migrated").WithTypeSetter(scheme.Scheme),
-		IOStreams:     streams,
-		AllNamespaces: true,
+		PrintFlags:       genericclioptions.NewPrintFlags("migrated").WithTypeSetter(scheme.Scheme),
+		IOStreams:        streams,
+		AllNamespaces:    true,
+		ProgressInterval: time.Minute,
 	}
 }
 
@@ -179,6 +191,10 @@ func (o *ResourceOptions) Bind(c *cobra.Command) {
 
 	c.Flags().StringVar(&o.FromKey, "from-key", o.FromKey, "If specified, only migrate items with a key (namespace/name or name) greater than or equal to this value")
 	c.Flags().StringVar(&o.ToKey, "to-key", o.ToKey, "If specified, only migrate items with a key (namespace/name or name) less than this value")
+	c.Flags().StringVar(&o.CheckpointFile, "checkpoint-file", o.CheckpointFile, "If specified, the progress of the migration is saved to this file, and a migration interrupted before it completed resumes from it. The file is removed once all the items are migrated.")
+	c.Flags().Float32Var(&o.QPS, "qps", o.QPS, "The maximum number of items migrated per second. Defaults to no limit.")
+	c.Flags().IntVar(&o.Burst, "burst", o.Burst, "The maximum number of items migrated at once above --qps. Defaults to --qps.")
+	c.Flags().DurationVar(&o.ProgressInterval, "progress-interval", o.ProgressInterval, "The interval the number of items processed so far is reported at on standard error. Set to 0 to disable.")
 
 	o.PrintFlags.AddFlags(c)
 
@@ -245,6 +261,23 @@ func (o *ResourceOptions) Complete(f kcmdutil.Factory, c *cobra.Command) error {
 		}
 	}
 
+	if len(o.CheckpointFile) > 0 {
+		o.checkpoint, err = loadCheckpoint(o.CheckpointFile)
+		if err != nil {
+			return err
+		}
+		keyFilterFn := o.FilterFn
+		o.FilterFn = func(info *resource.Info) (bool, error) {
+			if o.checkpoint.skip(info) {
+				return false, nil
+			}
+			if keyFilterFn == nil {
+				return true, nil
+			}
+			return keyFilterFn(info)
+		}
+	}
+
 	// use the factory's caching discovery client
 	discoveryClient, err := f.ToDiscoveryClient()
 	if err != nil {
@@ -325,6 +358,21 @@ func (o *ResourceOptions) Complete(f kcmdutil.Factory, c *cobra.Command) error {
 		break
 	}
 
+	// do not list again the resources a resumed migration already completed
+	if o.checkpoint != nil && len(o.checkpoint.Completed) > 0 {
+		completed := sets.NewString(o.checkpoint.Completed...)
+		include := []string{}
+		for _, s := range o.Include {
+			gvr, err := mapper.ResourceFor(schema.ParseGroupResource(s).WithVersion(""))
+			if err == nil && completed.Has(gvr.GroupResource().String()) {
+				klog.V(2).Infof("Skipping %s, completed according to the checkpoint %s", s, o.CheckpointFile)
+				continue
+			}
+			include = append(include, s)
+		}
+		o.Include = include
+	}
+
 	// we need at least one worker
 	if o.Workers == 0 {
 		o.Workers = 1
@@ -374,18 +422,44 @@ func (o *ResourceOptions) Validate() error {
 	if o.Workers < 1 {
 		return fmt.Errorf("invalid value %d for workers, must be at least 1", o.Workers)
 	}
+	if o.QPS < 0 {
+		return fmt.Errorf("--qps must not be negative")
+	}
+	if o.Burst < 0 {
+		return fmt.Errorf("--burst must not be negative")
+	}
+	if o.Burst > 0 && o.QPS == 0 {
+		return fmt.Errorf("--burst requires --qps")
+	}
 	return nil
 }
 
 func (o *ResourceOptions) Visitor() *ResourceVisitor {
+	var rateLimiter flowcontrol.RateLimiter
+	if o.QPS > 0 {
+		burst := o.Burst
+		if burst == 0 {
+			burst = int(o.QPS)
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		rateLimiter = flowcontrol.NewTokenBucketRateLimiter(o.QPS, burst)
+	}
 	return &ResourceVisitor{
 		Out:      o.Out,
+		ErrOut:   o.ErrOut,
 		Builder:  &resourceBuilder{builder: o.Builder},
 		SaveFn:   o.SaveFn,
 		PrintFn:  o.PrintFn,
 		FilterFn: o.FilterFn,
 		DryRun:   o.DryRun,
 		Workers:  o.Workers,
+
+		ProgressInterval: o.ProgressInterval,
+		RateLimiter:      rateLimiter,
+		CheckpointFile:   o.CheckpointFile,
+		checkpoint:       o.checkpoint,
 	}
 }
 
@@ -406,6 +480,8 @@ func (r *resourceBuilder) Visitor(fns ...resource.ErrMatchFunc) (resource.Visito
 
 type ResourceVisitor struct {
 	Out io.Writer
+	// ErrOut receives the progress reports, may be nil if ProgressInterval is zero.
+	ErrOut io.Writer
 
 	Builder Builder
 
@@ -416,6 +492,15 @@ type ResourceVisitor struct {
 	DryRun bool
 
 	Workers int
+
+	// ProgressInterval is the interval the progress is reported at, never if zero.
+	ProgressInterval time.Duration
+	// RateLimiter, if set, limits the rate objects are saved at.
+	RateLimiter flowcontrol.RateLimiter
+	// CheckpointFile, if set, is the file the progress of a migration that is not a dry run is
+	// saved to.
+	CheckpointFile string
+	checkpoint     *migrateCheckpoint
 }
 
 func (o *ResourceVisitor) Visit(fn MigrateVisitFunc) error {
@@ -446,10 +531,21 @@ func (o *ResourceVisitor) Visit(fn MigrateVisitFunc) error {
 	// migrateTracker tracks stats for this migrate run
 	t := &migrateTracker{
 		out:                 out,
+		errOut:              o.ErrOut,
 		dryRun:              dryRun,
+		progressInterval:    o.ProgressInterval,
 		resourcesWithErrors: sets.NewString(),
 		results:             results,
 	}
+	if len(o.CheckpointFile) > 0 && !dryRun {
+		// the loaded checkpoint filters the objects while the workers run, so advance a copy
+		checkpoint := &migrateCheckpoint{}
+		if o.checkpoint != nil {
+			*checkpoint = *o.checkpoint
+			checkpoint.Completed = append([]string(nil), o.checkpoint.Completed...)
+		}
+		t.checkpointer = newCheckpointer(o.CheckpointFile, checkpoint, out)
+	}
 
 	// use a wait group to track when workers have finished processing
 	workersWG := sync.WaitGroup{}
@@ -459,12 +555,13 @@ func (o *ResourceVisitor) Visit(fn MigrateVisitFunc) error {
 		go func() {
 			defer workersWG.Done()
 			worker := &migrateWorker{
-				retries:   10, // how many times should this worker retry per resource
-				work:      work,
-				results:   results,
-				migrateFn: fn,
-				actionFn:  actionFn,
-				filterFn:  o.FilterFn,
+				retries:     10, // how many times should this worker retry per resource
+				work:        work,
+				results:     results,
+				migrateFn:   fn,
+				actionFn:    actionFn,
+				filterFn:    o.FilterFn,
+				rateLimiter: o.RateLimiter,
 			}
 			worker.run()
 		}()
@@ -478,9 +575,11 @@ func (o *ResourceVisitor) Visit(fn MigrateVisitFunc) error {
 		t.run()
 	}()
 
+	seq := 0
 	err = visitor.Visit(func(info *resource.Info, err error) error {
 		// send data from producer visitor to workers
-		work <- workData{info: info, err: err}
+		work <- workData{seq: seq, info: info, err: err}
+		seq++
 		return nil
 	})
 
@@ -493,11 +592,15 @@ func (o *ResourceVisitor) Visit(fn MigrateVisitFunc) error {
 	// wait for the consumer to finish recording the results from processing
 	consumerWG.Wait()
 
+	if t.checkpointer != nil {
+		t.checkpointer.finish(err == nil && t.errors == 0)
+	}
+
 	if summarize {
 		if dryRun {
-			fmt.Fprintf(out, "summary (dry run): total=%d errors=%d ignored=%d unchanged=%d migrated=%d\n", t.found, t.errors, t.ignored, t.unchanged, t.found-t.errors-t.unchanged-t.ignored)
+			fmt.Fprintf(out, "summary (dry run): total=%d errors=%d ignored=%d unchanged=%d migrated=%d\n", t.found, t.errors, t.ignored, t.unchanged, t.migrated())
 		} else {
-			fmt.Fprintf(out, "summary: total=%d errors=%d ignored=%d unchanged=%d migrated=%d\n", t.found, t.errors, t.ignored, t.unchanged, t.found-t.errors-t.unchanged-t.ignored)
+			fmt.Fprintf(out, "summary: total=%d errors=%d ignored=%d unchanged=%d migrated=%d\n", t.found, t.errors, t.ignored, t.unchanged, t.migrated())
 		}
 	}
 
@@ -564,6 +667,8 @@ const (
 
 // workData stores a single item of work that needs to be processed by a worker
 type workData struct {
+	// seq is the order the item was listed in
+	seq  int
 	info *resource.Info
 	err  error
 }
@@ -580,7 +685,8 @@ type resultData struct {
 // migrateTracker abstracts transforming and saving resources and can be used to keep track
 // of how many total resources have been updated.
 type migrateTracker struct {
-	out io.Writer
+	out    io.Writer
+	errOut io.Writer
 
 	dryRun bool
 
@@ -588,6 +694,9 @@ type migrateTracker struct {
 
 	resourcesWithErrors sets.String
 
+	progressInterval time.Duration
+	checkpointer     *checkpointer
+
 	results <-chan resultData
 }
 
@@ -610,38 +719,67 @@ func (t *migrateTracker) report(prefix string, info *resource.Info, err error) {
 // run executes until t.results is closed
 // it processes each result and updates its stats as appropriate
 func (t *migrateTracker) run() {
-	for r := range t.results {
-		if r.found {
-			t.found++
-		}
-		if r.retry {
-			t.report("retry:", r.data.info, r.data.err)
-			continue // retry attempts do not have results to process
+	var progress <-chan time.Time
+	if t.progressInterval > 0 && t.errOut != nil {
+		ticker := time.NewTicker(t.progressInterval)
+		defer ticker.Stop()
+		progress = ticker.C
+	}
+	start := time.Now()
+	for {
+		select {
+		case r, ok := <-t.results:
+			if !ok {
+				return
+			}
+			t.record(r)
+		case <-progress:
+			fmt.Fprintf(t.errOut, "I%s %-10s total=%d errors=%d ignored=%d unchanged=%d migrated=%d rate=%.1f/s\n",
+				timeStampNow(), "progress:", t.found, t.errors, t.ignored, t.unchanged, t.migrated(), float64(t.found)/time.Since(start).Seconds())
 		}
+	}
+}
 
-		switch r.result {
-		case attemptResultError:
-			t.report("error:", r.data.info, r.data.err)
-			t.errors++
-			groupResource := r.data.info.Mapping.Resource.GroupResource()
-			t.resourcesWithErrors.Insert((&groupResource).String())
-		case attemptResultIgnore:
-			t.ignored++
-			if klog.V(2).Enabled() {
-				t.report("ignored:", r.data.info, nil)
-			}
-		case attemptResultUnchanged:
-			t.unchanged++
-			if klog.V(2).Enabled() {
-				t.report("unchanged:", r.data.info, nil)
-			}
-		case attemptResultSuccess:
-			if klog.V(1).Enabled() {
-				if t.dryRun {
-					t.report("migrated (dry run):", r.data.info, nil)
-				} else {
-					t.report("migrated:", r.data.info, nil)
-				}
+// migrated returns the number of resources migrated so far.
+func (t *migrateTracker) migrated() int {
+	return t.found - t.errors - t.unchanged - t.ignored
+}
+
+// record updates the stats with a single result
+func (t *migrateTracker) record(r resultData) {
+	if r.found {
+		t.found++
+	}
+	if r.retry {
+		t.report("retry:", r.data.info, r.data.err)
+		return // retry attempts do not have results to process
+	}
+	if t.checkpointer != nil {
+		t.checkpointer.done(r.data.seq, r.data.info, r.result == attemptResultError)
+	}
+
+	switch r.result {
+	case attemptResultError:
+		t.report("error:", r.data.info, r.data.err)
+		t.errors++
+		groupResource := r.data.info.Mapping.Resource.GroupResource()
+		t.resourcesWithErrors.Insert((&groupResource).String())
+	case attemptResultIgnore:
+		t.ignored++
+		if klog.V(2).Enabled() {
+			t.report("ignored:", r.data.info, nil)
+		}
+	case attemptResultUnchanged:
+		t.unchanged++
+		if klog.V(2).Enabled() {
+			t.report("unchanged:", r.data.info, nil)
+		}
+	case attemptResultSuccess:
+		if klog.V(1).Enabled() {
+			if t.dryRun {
+				t.report("migrated (dry run):", r.data.info, nil)
+			} else {
+				t.report("migrated:", r.data.info, nil)
 			}
 		}
 	}
@@ -655,6 +793,8 @@ type migrateWorker struct {
 	migrateFn MigrateVisitFunc
 	actionFn  MigrateActionFunc
 	filterFn  MigrateFilterFunc
+	// rateLimiter, if set, limits the rate actionFn is called at
+	rateLimiter flowcontrol.RateLimiter
 }
 
 // run processes data until t.work is closed
@@ -665,7 +805,7 @@ func (t *migrateWorker) run() {
 			ok, err := t.filterFn(data.info)
 			// error if we cannot figure out how to filter this resource
 			if err != nil {
-				t.results <- resultData{found: true, result: attemptResultError, data: workData{info: data.info, err: err}}
+				t.results <- resultData{found: true, result: attemptResultError, data: workData{seq: data.seq, info: data.info, err: err}}
 				continue
 			}
 			// we want to ignore this resource
@@ -684,7 +824,7 @@ func (t *migrateWorker) run() {
 		// we have no error and the resource was not ignored, so attempt to process it
 		// try to invoke the migrateFn and saveFn on info, retrying any recalculation requests up to t.retries times
 		result, err := t.try(data.info, t.retries)
-		t.results <- resultData{found: true, result: result, data: workData{info: data.info, err: err}}
+		t.results <- resultData{found: true, result: result, data: workData{seq: data.seq, info: data.info, err: err}}
 	}
 }
 
@@ -702,6 +842,9 @@ func (t *migrateWorker) try(info *resource.Info, retries int) (attemptResult, er
 		return attemptResultUnchanged, nil
 	}
 	if t.actionFn != nil {
+		if t.rateLimiter != nil {
+			t.rateLimiter.Accept()
+		}
 		if err := t.actionFn(info, reporter); err != nil {
 			if err == ErrUnchanged {
 				return attemptResultUnchanged, nil
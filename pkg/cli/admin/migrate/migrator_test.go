@@ -2,14 +2,21 @@ package migrate
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
 	"runtime"
+	"sort"
 	"sync"
 	"testing"
 
 	"k8s.io/klog/v2"
 
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/resource"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 )
 
 // TestResourceVisitor_Visit is used to check for race conditions
@@ -129,3 +136,108 @@ func (t testBuilder) Visitor(_ ...resource.ErrMatchFunc) (resource.Visitor, erro
 	}
 	return infos, nil
 }
+
+// infosBuilder emits a resource.Visitor that visits the given infos in order
+type infosBuilder []*resource.Info
+
+func (b infosBuilder) Visitor(_ ...resource.ErrMatchFunc) (resource.Visitor, error) {
+	return resource.InfoListVisitor(b), nil
+}
+
+func newCheckpointInfo(resourceName, name string) *resource.Info {
+	return &resource.Info{
+		Mapping:   &meta.RESTMapping{Resource: schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: resourceName}},
+		Namespace: "ns",
+		Name:      name,
+	}
+}
+
+func TestResourceVisitorCheckpoint(t *testing.T) {
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+	infos := infosBuilder{
+		newCheckpointInfo("as", "a1"),
+		newCheckpointInfo("as", "a2"),
+		newCheckpointInfo("as", "a3"),
+		newCheckpointInfo("bs", "b1"),
+		newCheckpointInfo("bs", "b2"),
+		newCheckpointInfo("bs", "b3"),
+	}
+	failing := "b2"
+	var lock sync.Mutex
+	var saved []string
+	save := func(info *resource.Info, _ Reporter) error {
+		if info.Name == failing {
+			return fmt.Errorf("failed to save %s", info.Name)
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		saved = append(saved, info.Name)
+		return nil
+	}
+	o := &ResourceVisitor{
+		Out:            io.Discard,
+		Builder:        infos,
+		SaveFn:         save,
+		Workers:        4,
+		CheckpointFile: checkpointFile,
+	}
+	if err := o.Visit(AlwaysRequiresMigration); err != kcmdutil.ErrExit {
+		t.Fatalf("expected the migration to fail, got %v", err)
+	}
+
+	checkpoint, err := loadCheckpoint(checkpointFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the checkpoint stops before the item which failed to migrate
+	expected := &migrateCheckpoint{Completed: []string{"as.test.io"}, Resource: "bs.test.io", Key: "ns/b1"}
+	if !reflect.DeepEqual(expected, checkpoint) {
+		t.Errorf("expected checkpoint %#v, got %#v", expected, checkpoint)
+	}
+	for _, info := range infos[:4] {
+		if !checkpoint.skip(info) {
+			t.Errorf("expected %s to be skipped when resuming", info.Name)
+		}
+	}
+	for _, info := range append(infos[4:], newCheckpointInfo("cs", "c1")) {
+		if checkpoint.skip(info) {
+			t.Errorf("expected %s not to be skipped when resuming", info.Name)
+		}
+	}
+
+	// resuming retries the item which failed, as Complete sets up the visitor
+	failing, saved = "", nil
+	o.checkpoint = checkpoint
+	o.FilterFn = func(info *resource.Info) (bool, error) { return !checkpoint.skip(info), nil }
+	if err := o.Visit(AlwaysRequiresMigration); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sort.Strings(saved)
+	if !reflect.DeepEqual(saved, []string{"b2", "b3"}) {
+		t.Errorf("expected the items after the checkpoint to be migrated when resuming, got %v", saved)
+	}
+	if _, err := os.Stat(checkpointFile); !os.IsNotExist(err) {
+		t.Errorf("expected the checkpoint to be removed once the migration completed, got %v", err)
+	}
+}
+
+func TestCheckpointerOutOfOrder(t *testing.T) {
+	c := newCheckpointer(filepath.Join(t.TempDir(), "checkpoint.json"), &migrateCheckpoint{}, io.Discard)
+	c.done(1, newCheckpointInfo("as", "a2"), false)
+	if len(c.checkpoint.Key) != 0 {
+		t.Errorf("expected the checkpoint not to move past an item not processed, got %#v", c.checkpoint)
+	}
+	c.done(0, newCheckpointInfo("as", "a1"), false)
+	c.done(2, nil, false)
+	if c.checkpoint.Key != "ns/a2" || c.next != 3 {
+		t.Errorf("expected the checkpoint to move past the processed items, got %#v next=%d", c.checkpoint, c.next)
+	}
+
+	// the checkpoint stops at an item which failed, even once the items after it are processed
+	c.done(4, newCheckpointInfo("as", "a5"), false)
+	c.done(3, newCheckpointInfo("as", "a4"), true)
+	c.done(5, newCheckpointInfo("bs", "b1"), false)
+	if c.checkpoint.Key != "ns/a2" || len(c.checkpoint.Completed) != 0 || c.next != 6 {
+		t.Errorf("expected the checkpoint not to move past the failed item, got %#v next=%d", c.checkpoint, c.next)
+	}
+}
@@ -46,6 +46,10 @@ var (
 		This command locates and updates every template instance which refers to a particular
 		group-version-kind to refer to some other, equivalent group-version-kind.
 
+		The progress is reported on standard error every --progress-interval. Use --qps and --burst to
+		limit the rate template instances are updated at, and --checkpoint-file to resume an
+		interrupted migration where it stopped instead of processing all the template instances again.
+
 		The following transformations will occur:
 
 %s`, prettyPrintMigrations(transforms)))
@@ -56,6 +60,9 @@ var (
 
 		# To actually perform the update, the confirm flag must be appended
 		oc adm migrate template-instances --confirm
+
+		# Update at most 20 template instances per second, resuming from the checkpoint if the command is interrupted
+		oc adm migrate template-instances --confirm --qps=20 --checkpoint-file=migrate-template-instances.json
 	`)
 )
 
@@ -0,0 +1,117 @@
+package mustgather
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// gatherManifestFile is the name of the inventory of the gathered files written at the top of
+// the destination directory.
+const gatherManifestFile = "gather-manifest.json"
+
+// gatherManifest enumerates the files of a must-gather, so that a truncated or altered copy of it
+// can be detected and tooling can index it without walking the archive.
+type gatherManifest struct {
+	// Created is the time the manifest was written, once the gather completed.
+	Created time.Time `json:"created"`
+	// Images are the plug-in images that gathered the files.
+	Images []gatherImage `json:"images"`
+	// Files are the regular files gathered, sorted by path.
+	Files []gatheredFile `json:"files"`
+}
+
+type gatherImage struct {
+	// Image is the pull spec of the plug-in image.
+	Image string `json:"image"`
+	// ImageID is the image the plug-in pods ran, by digest.
+	ImageID string `json:"imageID"`
+	// Directory is the path of the directory the image gathered into, relative to the
+	// destination directory.
+	Directory string `json:"directory"`
+}
+
+type gatheredFile struct {
+	// Path is relative to the destination directory, with forward slashes.
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// recordGatherImage remembers the image a plug-in pod ran, for the gather manifest.
+func (o *MustGatherOptions) recordGatherImage(image, imageID, dir string) {
+	o.gatherImagesLock.Lock()
+	defer o.gatherImagesLock.Unlock()
+	directory, err := filepath.Rel(o.DestDir, dir)
+	if err != nil {
+		directory = dir
+	}
+	gathered := gatherImage{Image: image, ImageID: imageID, Directory: filepath.ToSlash(directory)}
+	for _, existing := range o.gatherImages {
+		if existing == gathered {
+			return
+		}
+	}
+	o.gatherImages = append(o.gatherImages, gathered)
+}
+
+// writeGatherManifest enumerates the files of the destination directory with their size and
+// sha256 digest.
+func (o *MustGatherOptions) writeGatherManifest() error {
+	o.gatherImagesLock.Lock()
+	images := append([]gatherImage{}, o.gatherImages...)
+	o.gatherImagesLock.Unlock()
+	sort.Slice(images, func(i, j int) bool { return images[i].Directory < images[j].Directory })
+
+	manifest := gatherManifest{Created: time.Now().UTC(), Images: images, Files: []gatheredFile{}}
+	err := filepath.WalkDir(o.DestDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(o.DestDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == gatherManifestFile {
+			return nil
+		}
+		size, sum, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, gatheredFile{Path: filepath.ToSlash(rel), Size: size, SHA256: sum})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(o.DestDir, gatherManifestFile), append(data, '\n'), 0644)
+}
+
+func hashFile(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
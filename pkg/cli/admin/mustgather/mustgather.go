@@ -9,6 +9,7 @@ import (
 	"os"
 	"path"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -47,6 +48,7 @@ import (
 	"github.com/openshift/oc/pkg/cli/admin/inspect"
 	"github.com/openshift/oc/pkg/cli/rsync"
 	ocmdhelpers "github.com/openshift/oc/pkg/helpers/cmd"
+	ocompletion "github.com/openshift/oc/pkg/helpers/completion"
 )
 
 const (
@@ -58,7 +60,14 @@ var (
 		Launch a pod to gather debugging information.
 
 		This command will launch a pod in a temporary namespace on your cluster that gathers
-		debugging information and then downloads the gathered information.
+		debugging information and then downloads the gathered information. The gathered files
+		are listed with their size and sha256 digest in gather-manifest.json, together with the
+		digests of the plug-in images, to detect an incomplete or altered copy of the output.
+
+		Recurring gathers of a long running investigation may pass --since-last to only collect
+		the logs and events newer than the start of the previous successful gather of the
+		cluster that used it. The start of each such gather is recorded in the cache directory
+		of oc, $KUBECACHEDIR or ~/.kube/cache.
 	`)
 
 	mustGatherExample = templates.Examples(`
@@ -79,6 +88,9 @@ var (
 
 		# Gather information using a specific image, command, and pod directory
 		  oc adm must-gather --image=my/image:tag --source-dir=/pod/directory -- myspecial-command.sh
+
+		# Gather only the logs newer than the last successful gather of the cluster, which are all of them the first time
+		  oc adm must-gather --since-last
 	`)
 
 	volumeUsageCheckerScript = `
@@ -134,6 +146,15 @@ func NewMustGatherCommand(f kcmdutil.Factory, streams genericiooptions.IOStreams
 	cmd.Flags().MarkHidden("keep")
 	cmd.Flags().StringVar(&o.SinceTime, "since-time", o.SinceTime, "Only return logs after a specific date (RFC3339). Defaults to all logs. Plugins are encouraged but not required to support this. Only one of since-time / since may be used.")
 	cmd.Flags().DurationVar(&o.Since, "since", o.Since, "Only return logs newer than a relative duration like 5s, 2m, or 3h. Defaults to all logs. Plugins are encouraged but not required to support this. Only one of since-time / since may be used.")
+	cmd.Flags().BoolVar(&o.SinceLast, "since-last", o.SinceLast, "Only return logs newer than the start of the previous successful gather of the cluster that used --since-last, and record the start of this one. Defaults to all logs on the first gather. Plugins are encouraged but not required to support this. May not be used with since-time or since.")
+
+	kcmdutil.CheckErr(cmd.RegisterFlagCompletionFunc("image", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeImages(f, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}))
+	kcmdutil.CheckErr(cmd.RegisterFlagCompletionFunc("image-stream", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return ocompletion.CompGetImageStreamTags(f, toComplete, true)
+	}))
+	kcmdutil.CheckErr(cmd.RegisterFlagCompletionFunc("node-name", ocompletion.NodeCompletionFunc(f)))
 
 	return cmd
 }
@@ -144,6 +165,7 @@ func NewMustGatherOptions(streams genericiooptions.IOStreams) *MustGatherOptions
 		IOStreams:        streams,
 		Timeout:          10 * time.Minute,
 		VolumePercentage: 30,
+		LastGathersDir:   defaultLastGathersDir(),
 	}
 	opts.LogOut = opts.newPrefixWriter(streams.Out, "[must-gather      ] OUT", false, true)
 	opts.RawOut = opts.newPrefixWriter(streams.Out, "", false, false)
@@ -213,6 +235,17 @@ func (o *MustGatherOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, arg
 	if err := o.completeImages(); err != nil {
 		return err
 	}
+	if o.SinceLast {
+		if len(o.SinceTime) > 0 || o.Since != 0 {
+			return fmt.Errorf("--since-last may not be used with `--since-time` or `--since`")
+		}
+		if last, ok := lastGather(o.LastGathersDir, o.Config.Host); ok {
+			o.SinceTime = last.UTC().Format(time.RFC3339)
+			o.log("Gathering logs since the last gather of %s at %s", o.Config.Host, o.SinceTime)
+		} else {
+			o.log("No previous gather of %s recorded, gathering all logs", o.Config.Host)
+		}
+	}
 	o.PrinterCreated, err = printers.NewTypeSetter(scheme.Scheme).WrapToPrinter(&printers.NamePrinter{Operation: "created"}, nil)
 	if err != nil {
 		return err
@@ -251,17 +284,9 @@ func (o *MustGatherOptions) completeImages() error {
 		if err != nil {
 			return err
 		}
-
-		cos, err := o.ConfigClient.ConfigV1().ClusterOperators().List(context.TODO(), metav1.ListOptions{})
-		if err != nil {
+		if err := o.annotatedClusterOperators(pluginImages); err != nil {
 			return err
 		}
-		for _, item := range cos.Items {
-			ann := item.GetAnnotations()
-			if v, ok := ann[mgAnnotation]; ok {
-				pluginImages[v] = struct{}{}
-			}
-		}
 		// delete the default image to avoid duplication in case an Operator had it in its annotation
 		delete(pluginImages, o.Images[0])
 		for i := range pluginImages {
@@ -295,6 +320,60 @@ func (o *MustGatherOptions) annotatedCSVs() (map[string]struct{}, error) {
 	return pluginImages, nil
 }
 
+func (o *MustGatherOptions) annotatedClusterOperators(pluginImages map[string]struct{}) error {
+	cos, err := o.ConfigClient.ConfigV1().ClusterOperators().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, item := range cos.Items {
+		ann := item.GetAnnotations()
+		if v, ok := ann[mgAnnotation]; ok {
+			pluginImages[v] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// completeImages returns the default must-gather image and the plugin images of the
+// operators annotated with mgAnnotation which begin with toComplete, for the completion
+// of --image.
+func completeImages(f kcmdutil.Factory, toComplete string) []string {
+	o := &MustGatherOptions{}
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return nil
+	}
+	if o.ConfigClient, err = configclient.NewForConfig(config); err != nil {
+		return nil
+	}
+	if o.DynamicClient, err = dynamic.NewForConfig(config); err != nil {
+		return nil
+	}
+	if o.ImageClient, err = imagev1client.NewForConfig(config); err != nil {
+		return nil
+	}
+
+	images := map[string]struct{}{}
+	if image, err := o.resolveImageStreamTag("openshift", "must-gather", "latest"); err == nil {
+		images[image] = struct{}{}
+	}
+	if csvImages, err := o.annotatedCSVs(); err == nil {
+		for image := range csvImages {
+			images[image] = struct{}{}
+		}
+	}
+	o.annotatedClusterOperators(images)
+
+	var comps []string
+	for image := range images {
+		if strings.HasPrefix(image, toComplete) {
+			comps = append(comps, image)
+		}
+	}
+	sort.Strings(comps)
+	return comps
+}
+
 func (o *MustGatherOptions) resolveImageStreamTagString(s string) (string, error) {
 	namespace, name, tag := parseImageStreamTagString(s)
 	if len(namespace) == 0 {
@@ -355,6 +434,10 @@ type MustGatherOptions struct {
 	Keep             bool
 	Since            time.Duration
 	SinceTime        string
+	SinceLast        bool
+	// LastGathersDir is the directory the start of the last successful gather of each server is
+	// recorded in for SinceLast.
+	LastGathersDir string
 
 	RsyncRshCmd string
 
@@ -366,6 +449,10 @@ type MustGatherOptions struct {
 
 	LogWriter    *os.File
 	LogWriterMux sync.Mutex
+
+	// gatherImages are the images the plug-in pods ran, listed in the gather manifest.
+	gatherImages     []gatherImage
+	gatherImagesLock sync.Mutex
 }
 
 func (o *MustGatherOptions) Validate() error {
@@ -406,6 +493,7 @@ func (o *MustGatherOptions) Validate() error {
 // Run creates and runs a must-gather pod
 func (o *MustGatherOptions) Run() error {
 	var errs []error
+	start := time.Now()
 
 	if err := os.MkdirAll(o.DestDir, os.ModePerm); err != nil {
 		// ensure the errors bubble up to BackupGathering method for display
@@ -413,6 +501,14 @@ func (o *MustGatherOptions) Run() error {
 		return err
 	}
 
+	// enumerate everything that was gathered once the logs are closed, including the fallback
+	// collection of a failed gather
+	defer func() {
+		if err := o.writeGatherManifest(); err != nil {
+			fmt.Fprintf(o.ErrOut, "Unable to write %s: %v\n", gatherManifestFile, err)
+		}
+	}()
+
 	f, err := os.Create(path.Join(o.DestDir, "must-gather.logs"))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Unable to write must-gather logs: %v. It is possible the destination directory has not been created yet due to early termination\n", err)
@@ -553,6 +649,11 @@ func (o *MustGatherOptions) Run() error {
 	if len(errs) == 0 {
 		// If we didn't have an error during collection, then we don't need to do our backup collection.
 		runBackCollection = false
+		if o.SinceLast {
+			if err := recordLastGather(o.LastGathersDir, o.Config.Host, start); err != nil {
+				o.log("Unable to record the start of this gather for --since-last: %v", err)
+			}
+		}
 	} else if len(o.Command) > 0 {
 		// If we had errors, but the user specified a command, he probably just typoed the command.
 		// If the command was specified, don't run the backup collection.
@@ -662,6 +763,7 @@ func (o *MustGatherOptions) copyFilesFromPod(pod *corev1.Pod) error {
 	if err := os.MkdirAll(destDir, os.ModePerm); err != nil {
 		return err
 	}
+	o.recordGatherImage(pod.Spec.Containers[0].Image, pod.Status.ContainerStatuses[0].ImageID, destDir)
 
 	var errs []error
 
@@ -2,8 +2,12 @@ package mustgather
 
 import (
 	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -239,3 +243,80 @@ func TestGetNamespace(t *testing.T) {
 		})
 	}
 }
+
+func TestLastGather(t *testing.T) {
+	dir := t.TempDir()
+	if _, ok := lastGather(dir, "https://api.a.example.com:6443"); ok {
+		t.Fatalf("expected no last gather before one is recorded")
+	}
+
+	start := time.Date(2024, 3, 1, 10, 30, 15, 500, time.UTC)
+	if err := recordLastGather(dir, "https://api.a.example.com:6443", start); err != nil {
+		t.Fatal(err)
+	}
+	if err := recordLastGather(dir, "https://api.b.example.com:6443", start.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	last, ok := lastGather(dir, "https://api.a.example.com:6443")
+	if !ok || !last.Equal(start.Truncate(time.Second)) {
+		t.Errorf("expected the last gather to start at %s, got %s", start.Truncate(time.Second), last)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, lastGathersFile), []byte("not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := lastGather(dir, "https://api.a.example.com:6443"); ok {
+		t.Errorf("expected a corrupted file to be treated as no last gather")
+	}
+	if err := recordLastGather(dir, "https://api.a.example.com:6443", start); err != nil {
+		t.Errorf("expected a corrupted file to be replaced: %v", err)
+	}
+}
+
+func TestWriteGatherManifest(t *testing.T) {
+	dir := t.TempDir()
+	imageDir := filepath.Join(dir, "quay-io-openshift-must-gather-sha256-abc")
+	if err := os.MkdirAll(filepath.Join(imageDir, "namespaces"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"timestamp": "start\n",
+		"quay-io-openshift-must-gather-sha256-abc/namespaces/pods.yaml": "pods",
+		"quay-io-openshift-must-gather-sha256-abc/gather.logs":          "",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o := &MustGatherOptions{DestDir: dir}
+	o.recordGatherImage("quay.io/openshift/must-gather:latest", "quay.io/openshift/must-gather@sha256:abc", imageDir)
+	o.recordGatherImage("quay.io/openshift/must-gather:latest", "quay.io/openshift/must-gather@sha256:abc", imageDir)
+	for i := 0; i < 2; i++ {
+		// the manifest of a previous run is not listed in the next one
+		if err := o.writeGatherManifest(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, gatherManifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := gatherManifest{}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	expectedImages := []gatherImage{{Image: "quay.io/openshift/must-gather:latest", ImageID: "quay.io/openshift/must-gather@sha256:abc", Directory: "quay-io-openshift-must-gather-sha256-abc"}}
+	if !reflect.DeepEqual(manifest.Images, expectedImages) {
+		t.Errorf("unexpected images: %s", diff.ObjectReflectDiff(expectedImages, manifest.Images))
+	}
+	expectedFiles := []gatheredFile{
+		{Path: "quay-io-openshift-must-gather-sha256-abc/gather.logs", Size: 0, SHA256: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{Path: "quay-io-openshift-must-gather-sha256-abc/namespaces/pods.yaml", Size: 4, SHA256: "049c287ed3e2d554fabbbf4055dc3621a8bf44852f372b29a1be7570653fe789"},
+		{Path: "timestamp", Size: 6, SHA256: "46210dddc66714c3d8d226711510cf8421774214016c508c72a833a05370f6b5"},
+	}
+	if !reflect.DeepEqual(manifest.Files, expectedFiles) {
+		t.Errorf("unexpected files: %s", diff.ObjectReflectDiff(expectedFiles, manifest.Files))
+	}
+}
@@ -0,0 +1,74 @@
+package mustgather
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"k8s.io/client-go/util/homedir"
+	"k8s.io/klog/v2"
+)
+
+// lastGathersFile is the name of the file the time of the last successful gather of each server
+// is stored in, for --since-last.
+const lastGathersFile = "must-gather-last.json"
+
+// defaultLastGathersDir returns the directory the last gathers are stored in, next to the other
+// files oc caches under $KUBECACHEDIR.
+func defaultLastGathersDir() string {
+	if kcd := os.Getenv("KUBECACHEDIR"); kcd != "" {
+		return filepath.Join(kcd, "oc")
+	}
+	return filepath.Join(homedir.HomeDir(), ".kube", "cache", "oc")
+}
+
+// lastGathers maps a server URL to the time its last successful gather started.
+type lastGathers map[string]time.Time
+
+func loadLastGathers(dir string) (lastGathers, error) {
+	data, err := os.ReadFile(filepath.Join(dir, lastGathersFile))
+	if os.IsNotExist(err) {
+		return lastGathers{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	last := lastGathers{}
+	if err := json.Unmarshal(data, &last); err != nil {
+		return nil, err
+	}
+	return last, nil
+}
+
+// lastGather returns the time the last successful gather of the server started. An unreadable
+// file is treated as if the server had never been gathered.
+func lastGather(dir, server string) (time.Time, bool) {
+	last, err := loadLastGathers(dir)
+	if err != nil {
+		klog.V(4).Infof("Unable to read the last gathers: %v", err)
+		return time.Time{}, false
+	}
+	t, ok := last[server]
+	return t, ok
+}
+
+// recordLastGather stores the time a successful gather of the server started, so that the next
+// gather with --since-last only collects what is newer.
+func recordLastGather(dir, server string, start time.Time) error {
+	last, err := loadLastGathers(dir)
+	if err != nil {
+		// start over rather than failing the gather because of a corrupted file
+		last = lastGathers{}
+	}
+	last[server] = start.UTC().Truncate(time.Second)
+
+	data, err := json.Marshal(last)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, lastGathersFile), data, 0600)
+}
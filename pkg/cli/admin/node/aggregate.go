@@ -0,0 +1,161 @@
+package node
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+)
+
+// maxBufferedPipeMemory is the size of the logs a bufferedPipe holds in memory
+// before spilling them to a temporary file.
+const maxBufferedPipeMemory = 1024 * 1024
+
+// bufferedPipe is a pipe whose writes never block. It allows a node to finish
+// returning its logs, and make room for the next node, while the merge reader
+// is still waiting for lines from the other nodes. Up to limit bytes are held
+// in memory, the rest is spilled to a temporary file until the reader catches
+// up, so that many nodes returning large logs do not exhaust the memory.
+type bufferedPipe struct {
+	lock   sync.Mutex
+	cond   *sync.Cond
+	limit  int
+	buf    bytes.Buffer
+	closed bool
+	err    error
+
+	// file holds the data written past limit from offset read to offset written.
+	file    *os.File
+	read    int64
+	written int64
+}
+
+func newBufferedPipe(limit int) *bufferedPipe {
+	p := &bufferedPipe{limit: limit}
+	p.cond = sync.NewCond(&p.lock)
+	return p
+}
+
+func (p *bufferedPipe) Write(data []byte) (int, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.closed {
+		return 0, io.ErrClosedPipe
+	}
+	defer p.cond.Broadcast()
+	// once data is spilled, the following writes go to the file until the
+	// reader drains it, to keep the data in order
+	if p.read == p.written && p.buf.Len()+len(data) <= p.limit {
+		return p.buf.Write(data)
+	}
+	if p.file == nil {
+		f, err := os.CreateTemp("", "node-logs-")
+		if err != nil {
+			return 0, err
+		}
+		p.file = f
+	}
+	n, err := p.file.WriteAt(data, p.written)
+	p.written += int64(n)
+	return n, err
+}
+
+// Read blocks until data is available or the pipe is closed, and then returns
+// the error the pipe was closed with, or io.EOF.
+func (p *bufferedPipe) Read(data []byte) (int, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for p.buf.Len() == 0 && p.read == p.written && !p.closed {
+		p.cond.Wait()
+	}
+	if p.buf.Len() > 0 {
+		return p.buf.Read(data)
+	}
+	if p.read < p.written {
+		if remaining := p.written - p.read; int64(len(data)) > remaining {
+			data = data[:remaining]
+		}
+		n, err := p.file.ReadAt(data, p.read)
+		p.read += int64(n)
+		if err == io.EOF && n > 0 {
+			err = nil
+		}
+		if p.read == p.written {
+			// the file is drained, reuse it from the start
+			p.read, p.written = 0, 0
+		}
+		return n, err
+	}
+	if p.err != nil {
+		return 0, p.err
+	}
+	return 0, io.EOF
+}
+
+func (p *bufferedPipe) CloseWithError(err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.closed {
+		return
+	}
+	p.closed = true
+	p.err = err
+	p.cond.Broadcast()
+}
+
+// Close closes the pipe if its writer did not, and removes the temporary file.
+func (p *bufferedPipe) Close() error {
+	p.CloseWithError(io.ErrClosedPipe)
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.file == nil {
+		return nil
+	}
+	p.file.Close()
+	err := os.Remove(p.file.Name())
+	p.file = nil
+	p.read, p.written = 0, 0
+	return err
+}
+
+// prefixWriter writes each complete line to out with the prefix, holding lock
+// while doing so, so that many nodes may share out without mixing their lines.
+type prefixWriter struct {
+	lock   *sync.Mutex
+	out    io.Writer
+	prefix []byte
+	line   []byte
+}
+
+func (w *prefixWriter) Write(data []byte) (int, error) {
+	w.line = append(w.line, data...)
+	i := bytes.LastIndexByte(w.line, '\n')
+	if i == -1 {
+		return len(data), nil
+	}
+	lines := w.line[:i+1]
+	var b bytes.Buffer
+	for len(lines) > 0 {
+		j := bytes.IndexByte(lines, '\n')
+		b.Write(w.prefix)
+		b.Write(lines[:j+1])
+		lines = lines[j+1:]
+	}
+	w.line = append(w.line[:0], w.line[i+1:]...)
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if _, err := w.out.Write(b.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// Flush writes the last line if it did not end with a newline.
+func (w *prefixWriter) Flush() error {
+	if len(w.line) == 0 {
+		return nil
+	}
+	_, err := w.Write([]byte("\n"))
+	return err
+}
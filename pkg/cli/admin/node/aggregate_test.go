@@ -0,0 +1,130 @@
+package node
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func Test_prefixWriter(t *testing.T) {
+	var lock sync.Mutex
+	out := &bytes.Buffer{}
+	var wg sync.WaitGroup
+	for _, node := range []string{"node-1", "node-2", "node-3"} {
+		wg.Add(1)
+		go func(node string) {
+			defer wg.Done()
+			w := &prefixWriter{lock: &lock, out: out, prefix: []byte(node + " ")}
+			for i := 0; i < 100; i++ {
+				// split lines across writes
+				fmt.Fprintf(w, "line %d", i)
+				fmt.Fprintf(w, " of %s\nrest", node)
+				fmt.Fprint(w, " of line\n")
+			}
+			fmt.Fprint(w, "last")
+			if err := w.Flush(); err != nil {
+				t.Error(err)
+			}
+		}(node)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 3*201 {
+		t.Fatalf("unexpected number of lines: %d", len(lines))
+	}
+	for _, line := range lines {
+		node, rest, _ := strings.Cut(line, " ")
+		if rest != "rest of line" && rest != "last" && !strings.HasSuffix(rest, " of "+node) {
+			t.Errorf("mixed line: %q", line)
+		}
+	}
+}
+
+func Test_bufferedPipe(t *testing.T) {
+	pipes := []*bufferedPipe{newBufferedPipe(maxBufferedPipeMemory), newBufferedPipe(maxBufferedPipeMemory)}
+	// writes complete without a reader
+	fmt.Fprint(pipes[0], "A\nC\n")
+	pipes[0].CloseWithError(nil)
+	fmt.Fprint(pipes[1], "B\nD\n")
+	pipes[1].CloseWithError(nil)
+	if _, err := pipes[1].Write([]byte("E\n")); err == nil {
+		t.Errorf("expected a write to a closed pipe to fail")
+	}
+
+	out := &bytes.Buffer{}
+	if _, err := NewMergeReader(Reader{R: pipes[0], Prefix: []byte("node-2 ")}, Reader{R: pipes[1], Prefix: []byte("node-1 ")}).WriteTo(out); err != nil {
+		t.Fatal(err)
+	}
+	if want := "node-2 A\nnode-1 B\nnode-2 C\nnode-1 D\n"; out.String() != want {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+
+	failed := newBufferedPipe(maxBufferedPipeMemory)
+	failed.CloseWithError(fmt.Errorf("node is gone"))
+	_, err := NewMergeReader(Reader{R: failed}).WriteTo(&bytes.Buffer{})
+	if err == nil || err.Error() != "node is gone" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func Test_bufferedPipeSpill(t *testing.T) {
+	pipe := newBufferedPipe(8)
+	defer pipe.Close()
+	write := func(from, to int) string {
+		var written strings.Builder
+		for i := from; i < to; i++ {
+			line := fmt.Sprintf("line %d\n", i)
+			written.WriteString(line)
+			if _, err := pipe.Write([]byte(line)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return written.String()
+	}
+	read := func(size int) string {
+		data := make([]byte, size)
+		var read strings.Builder
+		for read.Len() < size {
+			n, err := pipe.Read(data[:size-read.Len()])
+			if err != nil {
+				t.Fatal(err)
+			}
+			read.Write(data[:n])
+		}
+		return read.String()
+	}
+
+	expected := write(0, 50)
+	if pipe.buf.Len() > 8 || pipe.file == nil {
+		t.Fatalf("expected at most 8 bytes in memory and the rest in a file, got %d bytes in memory", pipe.buf.Len())
+	}
+	if got := read(len(expected)); got != expected {
+		t.Errorf("unexpected data: %q", got)
+	}
+	// the reader catching up lets the writes go back to memory
+	expected = write(50, 51)
+	if pipe.buf.Len() != len(expected) {
+		t.Errorf("expected the write to go to memory, got %d bytes in memory", pipe.buf.Len())
+	}
+	expected += write(51, 100)
+	name := pipe.file.Name()
+	pipe.CloseWithError(nil)
+
+	out := &bytes.Buffer{}
+	if _, err := out.ReadFrom(pipe); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != expected {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+	if err := pipe.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("expected the temporary file to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,253 @@
+package node
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// journalPriorities are the syslog priority names accepted by --priority, in order.
+var journalPriorities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// parsePriority parses a --priority value like journalctl does: either a single
+// priority, which matches it and every more important one, or a FROM..TO range.
+func parsePriority(value string) (int, int, error) {
+	parse := func(s string) (int, error) {
+		if n, err := strconv.Atoi(s); err == nil && n >= 0 && n < len(journalPriorities) {
+			return n, nil
+		}
+		for i, name := range journalPriorities {
+			if s == name {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("unknown priority %q, must be one of %s or 0-7", s, strings.Join(journalPriorities, ", "))
+	}
+	from, to, isRange := strings.Cut(value, "..")
+	if !isRange {
+		max, err := parse(value)
+		return 0, max, err
+	}
+	min, err := parse(from)
+	if err != nil {
+		return 0, 0, err
+	}
+	max, err := parse(to)
+	if err != nil {
+		return 0, 0, err
+	}
+	if min > max {
+		min, max = max, min
+	}
+	return min, max, nil
+}
+
+// parseJournalTime converts a --since or --until value to an absolute time, if it
+// is a timestamp or a duration relative to now, such as -1h. Other values, like
+// "yesterday", are only understood by journalctl on the node.
+func parseJournalTime(value string, now time.Time) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, true
+		}
+	}
+	if d, err := time.ParseDuration(strings.TrimPrefix(value, "-")); err == nil {
+		return now.Add(-d), true
+	}
+	return time.Time{}, false
+}
+
+// journalFilter applies the --grep, --priority, --since and --until filters to
+// journal entries returned as JSON, for nodes that ignore them, and formats the
+// matching entries in the requested output format.
+type journalFilter struct {
+	grep         *regexp.Regexp
+	priority     bool
+	minPriority  int
+	maxPriority  int
+	since, until time.Time
+	// output is one of short, short-unix, cat or json.
+	output string
+	errOut io.Writer
+}
+
+// journalEntry is a single entry of `journalctl --output=json`.
+type journalEntry map[string]json.RawMessage
+
+func (e journalEntry) field(name string) (string, bool) {
+	raw, ok := e[name]
+	if !ok {
+		return "", false
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, true
+	}
+	// fields that are not valid UTF-8 are encoded as an array of bytes
+	var b []byte
+	var ints []int
+	if err := json.Unmarshal(raw, &ints); err != nil {
+		return "", false
+	}
+	for _, i := range ints {
+		b = append(b, byte(i))
+	}
+	return string(b), true
+}
+
+func (e journalEntry) time() (time.Time, bool) {
+	s, ok := e.field("__REALTIME_TIMESTAMP")
+	if !ok {
+		return time.Time{}, false
+	}
+	usec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMicro(usec), true
+}
+
+// matches returns true if the entry passes all filters. Entries missing a field
+// that is filtered on are kept.
+func (f *journalFilter) matches(e journalEntry) bool {
+	if f.grep != nil {
+		if message, ok := e.field("MESSAGE"); ok && !f.grep.MatchString(message) {
+			return false
+		}
+	}
+	if f.priority {
+		if s, ok := e.field("PRIORITY"); ok {
+			if p, err := strconv.Atoi(s); err == nil && (p < f.minPriority || p > f.maxPriority) {
+				return false
+			}
+		}
+	}
+	if !f.since.IsZero() || !f.until.IsZero() {
+		if t, ok := e.time(); ok {
+			if !f.since.IsZero() && t.Before(f.since) {
+				return false
+			}
+			if !f.until.IsZero() && t.After(f.until) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// format renders the entry like journalctl does for the output format.
+func (f *journalFilter) format(e journalEntry) ([]byte, error) {
+	message, _ := e.field("MESSAGE")
+	switch f.output {
+	case "json":
+		return marshalJournalEntry(e)
+	case "cat":
+		return []byte(message), nil
+	}
+
+	var b bytes.Buffer
+	t, _ := e.time()
+	if f.output == "short-unix" {
+		fmt.Fprintf(&b, "%d.%06d", t.Unix(), t.Nanosecond()/1000)
+	} else {
+		b.WriteString(t.Local().Format("Jan 02 15:04:05"))
+	}
+	if host, ok := e.field("_HOSTNAME"); ok {
+		b.WriteString(" " + host)
+	}
+	identifier, ok := e.field("SYSLOG_IDENTIFIER")
+	if !ok {
+		identifier, _ = e.field("_COMM")
+	}
+	b.WriteString(" " + identifier)
+	if pid, ok := e.field("_PID"); ok {
+		b.WriteString("[" + pid + "]")
+	}
+	b.WriteString(": " + message)
+	return b.Bytes(), nil
+}
+
+// marshalJournalEntry encodes the entry with the timestamp first and the other
+// fields sorted, so that entries from many nodes interleave in time order when
+// sorted as lines.
+func marshalJournalEntry(e journalEntry) ([]byte, error) {
+	var keys []string
+	for key := range e {
+		if key != "__REALTIME_TIMESTAMP" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if _, ok := e["__REALTIME_TIMESTAMP"]; ok {
+		keys = append([]string{"__REALTIME_TIMESTAMP"}, keys...)
+	}
+
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(name)
+		b.WriteByte(':')
+		if err := json.Compact(&b, e[key]); err != nil {
+			return nil, err
+		}
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// copy filters and formats the journal entries read from in. A node that does
+// not return JSON entries has applied only the filters it supports, so its
+// lines are passed through unfiltered, wrapped in an entry for --output=json.
+func (f *journalFilter) copy(out io.Writer, in io.Reader, node string) error {
+	warned := false
+	s := bufio.NewScanner(in)
+	s.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for s.Scan() {
+		line := s.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		entry := journalEntry{}
+		if err := json.Unmarshal(line, &entry); err != nil {
+			if !warned && f.errOut != nil {
+				warned = true
+				fmt.Fprintf(f.errOut, "warning: node %s did not return structured journal entries, only the filters supported by the node are applied\n", node)
+			}
+			if f.output != "json" {
+				if _, err := fmt.Fprintf(out, "%s\n", line); err != nil {
+					return err
+				}
+				continue
+			}
+			message, _ := json.Marshal(string(line))
+			hostname, _ := json.Marshal(node)
+			entry = journalEntry{"MESSAGE": message, "_HOSTNAME": hostname}
+		} else if !f.matches(entry) {
+			continue
+		}
+
+		formatted, err := f.format(entry)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(out, "%s\n", formatted); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
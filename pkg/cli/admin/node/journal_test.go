@@ -0,0 +1,105 @@
+package node
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_parsePriority(t *testing.T) {
+	tests := []struct {
+		value    string
+		min, max int
+		wantErr  bool
+	}{
+		{value: "err", min: 0, max: 3},
+		{value: "4", min: 0, max: 4},
+		{value: "err..warning", min: 3, max: 4},
+		{value: "6..notice", min: 5, max: 6},
+		{value: "8", wantErr: true},
+		{value: "error", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			min, max, err := parsePriority(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePriority() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (min != tt.min || max != tt.max) {
+				t.Errorf("parsePriority() = %d..%d, want %d..%d", min, max, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func Test_parseJournalTime(t *testing.T) {
+	now := time.Date(2023, 10, 14, 12, 0, 0, 0, time.UTC)
+	if got, ok := parseJournalTime("-1h", now); !ok || !got.Equal(now.Add(-time.Hour)) {
+		t.Errorf("unexpected relative time: %v %t", got, ok)
+	}
+	if got, ok := parseJournalTime("2023-10-14T10:00:00Z", now); !ok || !got.Equal(now.Add(-2*time.Hour)) {
+		t.Errorf("unexpected absolute time: %v %t", got, ok)
+	}
+	if _, ok := parseJournalTime("yesterday", now); ok {
+		t.Errorf("expected yesterday to be left to the node")
+	}
+}
+
+func Test_journalFilter_copy(t *testing.T) {
+	in := strings.Join([]string{
+		`{"__CURSOR":"a","__REALTIME_TIMESTAMP":"1697277600000000","PRIORITY":"3","_HOSTNAME":"node-1","SYSLOG_IDENTIFIER":"kubelet","_PID":"42","MESSAGE":"failed to sync pod"}`,
+		`{"__CURSOR":"b","__REALTIME_TIMESTAMP":"1697277601000000","PRIORITY":"6","_HOSTNAME":"node-1","SYSLOG_IDENTIFIER":"kubelet","_PID":"42","MESSAGE":"synced pod"}`,
+		`{"__CURSOR":"c","__REALTIME_TIMESTAMP":"1697270400000000","PRIORITY":"2","_HOSTNAME":"node-1","SYSLOG_IDENTIFIER":"kubelet","_PID":"42","MESSAGE":"failed early"}`,
+		`{"__CURSOR":"d","__REALTIME_TIMESTAMP":"1697277602000000","PRIORITY":"4","_HOSTNAME":"node-1","SYSLOG_IDENTIFIER":"crio","MESSAGE":[102,97,105,108,101,100]}`,
+	}, "\n")
+
+	tests := []struct {
+		name   string
+		filter journalFilter
+		want   string
+	}{
+		{
+			name:   "priority and since",
+			filter: journalFilter{priority: true, minPriority: 0, maxPriority: 4, since: time.Unix(1697277000, 0), output: "cat"},
+			want:   "failed to sync pod\nfailed\n",
+		},
+		{
+			name:   "grep",
+			filter: journalFilter{grep: regexp.MustCompile("^synced"), output: "short-unix"},
+			want:   "1697277601.000000 node-1 kubelet[42]: synced pod\n",
+		},
+		{
+			name:   "json",
+			filter: journalFilter{priority: true, minPriority: 3, maxPriority: 3, output: "json"},
+			want:   `{"__REALTIME_TIMESTAMP":"1697277600000000","MESSAGE":"failed to sync pod","PRIORITY":"3","SYSLOG_IDENTIFIER":"kubelet","_HOSTNAME":"node-1","_PID":"42","__CURSOR":"a"}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			if err := tt.filter.copy(out, strings.NewReader(in), "node-1"); err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("copy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_journalFilter_copyUnstructured(t *testing.T) {
+	errOut := &bytes.Buffer{}
+	out := &bytes.Buffer{}
+	f := journalFilter{priority: true, maxPriority: 3, output: "json", errOut: errOut}
+	if err := f.copy(out, strings.NewReader("Oct 14 12:00:00 node-1 kubelet[42]: synced pod\n"), "node-1"); err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"MESSAGE":"Oct 14 12:00:00 node-1 kubelet[42]: synced pod","_HOSTNAME":"node-1"}` + "\n"; out.String() != want {
+		t.Errorf("copy() = %q, want %q", out.String(), want)
+	}
+	if !strings.Contains(errOut.String(), "did not return structured journal entries") {
+		t.Errorf("expected a warning, got %q", errOut.String())
+	}
+}
@@ -7,10 +7,14 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -37,6 +41,21 @@ var (
 		to see a list of log files available under /var/log/ and view those contents
 		directly.
 
+		The --grep, --since and --until filters are applied by the node. The --priority filter,
+		and the other filters for nodes that do not support them, are applied to the journal
+		entries returned as JSON, which happens when either --priority or --output=json is
+		given. Only the short, short-unix, cat and json output formats may be used with
+		--priority. With --output=json, each journal entry is printed as a single line of
+		JSON starting with its timestamp.
+
+		Logs are retrieved from up to --max-concurrency nodes at a time. Pass --prefix to
+		start each line with the name of the node it came from, which also streams the
+		logs of a file or, with --unify=false, of the journal from many nodes at once.
+
+		Pass --dir to write the logs of each node to a file compressed with gzip under
+		DIR/NODE/ instead, such as DIR/NODE/journal-kubelet.log.gz for the kubelet
+		service, which keeps the logs of many nodes apart when collecting them for support.
+
 		Node logs may contain sensitive output and so are limited to privileged node
 		administrators. The system:node-admins role grants this permission by default.
 		You check who has that permission via:
@@ -53,6 +72,15 @@ var (
 
 		# Display cron log file from all control plane nodes
 		oc adm node-logs --role master --path=cron
+
+		# Show the kubelet logs from all worker nodes, 20 nodes at a time, starting each line with the node name
+		oc adm node-logs --role worker -u kubelet --prefix --max-concurrency=20
+
+		# Write the logs of the current boot of all nodes to a directory per node under ./boot-logs
+		oc adm node-logs -l kubernetes.io/os=linux --boot=0 --dir=./boot-logs
+
+		# Show the kubelet errors and warnings of the last hour from all worker nodes as JSON
+		oc adm node-logs --role worker -u kubelet --priority=warning --since=-1h -o json
 	`)
 )
 
@@ -75,10 +103,24 @@ type LogsOptions struct {
 	Until             string
 	Tail              int
 	Output            string
+	Priority          string
 
 	// output format arguments
-	Raw   bool
-	Unify bool
+	Raw    bool
+	Unify  bool
+	Prefix bool
+
+	// MaxConcurrency is the number of nodes to retrieve logs from at any one time
+	MaxConcurrency int
+
+	// Dir, if set, is the directory the logs of each node are written to, compressed, rather
+	// than to the output
+	Dir string
+
+	// since and until are the absolute times of --since and --until, if known
+	since, until time.Time
+	// journal filters and formats journal entries returned as JSON
+	journal *journalFilter
 
 	RESTClientGetter func(mapping *meta.RESTMapping) (resource.RESTClient, error)
 	Builder          *resource.Builder
@@ -91,6 +133,7 @@ func NewLogsOptions(streams genericiooptions.IOStreams) *LogsOptions {
 		Path:              "journal",
 		IOStreams:         streams,
 		GrepCaseSensitive: true,
+		MaxConcurrency:    10,
 	}
 }
 
@@ -119,11 +162,15 @@ func NewCmdLogs(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.C
 	cmd.Flags().StringVar(&o.Until, "until", o.Until, "Return logs before a specific ISO timestamp or relative date. Only applies to node service logs.")
 	cmd.Flags().IntVar(&o.Boot, "boot", o.Boot, " Show messages from a specific boot. Use negative numbers, allowed [-100, 0], passing invalid boot offset will fail retrieving logs. Only applies to node service logs.")
 	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Display service logs in an alternate format (short, cat, json, short-unix). Only applies to node service logs.")
+	cmd.Flags().StringVar(&o.Priority, "priority", o.Priority, "Return log entries of the specified priority, and all more important ones, or within a range of priorities such as err..warning. Priorities are emerg, alert, crit, err, warning, notice, info, debug, or 0-7. Only applies to node service logs.")
 	cmd.Flags().IntVar(&o.Tail, "tail", o.Tail, "Return up to this many lines (not more than 100k) from the end of the log. Only applies to node service logs.")
 	cmd.Flags().StringVar(&o.Role, "role", o.Role, "Set a label selector by node role.")
 	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) to filter on.")
 	cmd.Flags().BoolVar(&o.Raw, "raw", o.Raw, "Perform no transformation of the returned data.")
 	cmd.Flags().BoolVar(&o.Unify, "unify", o.Unify, "Interleave logs by sorting the output. Defaults on when viewing node service logs.")
+	cmd.Flags().BoolVar(&o.Prefix, "prefix", o.Prefix, "Prefix each line with the name of the node it came from.")
+	cmd.Flags().IntVar(&o.MaxConcurrency, "max-concurrency", o.MaxConcurrency, "Number of nodes to retrieve logs from at any one time.")
+	cmd.Flags().StringVar(&o.Dir, "dir", o.Dir, "Write the logs of each node, compressed with gzip, to a file under DIR/NODE/ instead of the output.")
 
 	return cmd
 }
@@ -157,6 +204,41 @@ func (o *LogsOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []st
 	o.Builder = builder
 	o.BootChanaged = cmd.Flag("boot").Changed
 
+	now := time.Now()
+	if len(o.Since) > 0 {
+		o.since, _ = parseJournalTime(o.Since, now)
+	}
+	if len(o.Until) > 0 {
+		o.until, _ = parseJournalTime(o.Until, now)
+	}
+	if o.Path == "journal" && (len(o.Priority) > 0 || o.Output == "json") {
+		o.journal = &journalFilter{
+			since:  o.since,
+			until:  o.until,
+			output: o.Output,
+			errOut: o.ErrOut,
+		}
+		if len(o.journal.output) == 0 {
+			o.journal.output = "short"
+		}
+		if len(o.Priority) > 0 {
+			var err error
+			o.journal.priority = true
+			o.journal.minPriority, o.journal.maxPriority, err = parsePriority(o.Priority)
+			if err != nil {
+				return fmt.Errorf("invalid --priority: %v", err)
+			}
+		}
+		if len(o.Grep) > 0 {
+			pattern := o.Grep
+			if !o.GrepCaseSensitive {
+				pattern = "(?i)" + pattern
+			}
+			// patterns that are only valid for the node are applied by the node alone
+			o.journal.grep, _ = regexp.Compile(pattern)
+		}
+	}
+
 	return nil
 }
 
@@ -170,6 +252,22 @@ func (o LogsOptions) Validate() error {
 	if o.BootChanaged && (o.Boot < -100 || o.Boot > 0) {
 		return fmt.Errorf("--boot accepts values [-100, 0]")
 	}
+	if o.MaxConcurrency < 1 {
+		return fmt.Errorf("--max-concurrency must be greater than zero")
+	}
+	if len(o.Dir) > 0 && o.Prefix {
+		return fmt.Errorf("--prefix may not be used with --dir, the logs of each node are written to their own file")
+	}
+	if len(o.Priority) > 0 {
+		if o.Path != "journal" {
+			return fmt.Errorf("--priority only applies to node service logs")
+		}
+		switch o.Output {
+		case "", "short", "short-unix", "cat", "json":
+		default:
+			return fmt.Errorf("--priority may only be used with --output of short, short-unix, cat or json")
+		}
+	}
 	return nil
 }
 
@@ -184,6 +282,8 @@ type logRequest struct {
 
 	// raw is set to true when we are viewing the journal and wish to skip prefixing
 	raw bool
+	// journal, if set, filters and formats the journal entries returned as JSON
+	journal *journalFilter
 	// skipPrefix bypasses prefixing if the user knows that a unique identifier is already
 	// in the file
 	skipPrefix bool
@@ -215,6 +315,14 @@ func (req *logRequest) writeTo(out io.Writer) error {
 		// the content-encoding of the response, but we perform optional
 		// decompression here in case the content of the logs on the server
 		// is also gzipped.
+		if req.journal != nil {
+			pr, pw := io.Pipe()
+			defer pr.Close()
+			go func() {
+				pw.CloseWithError(optionallyDecompress(pw, in))
+			}()
+			return req.journal.copy(out, pr, req.node)
+		}
 		return optionallyDecompress(out, in)
 	}
 
@@ -262,9 +370,22 @@ func (o LogsOptions) RunLogs() error {
 			if len(o.Since) > 0 {
 				req.Param("since", o.Since)
 			}
-			if len(o.Output) > 0 {
+			// Needed for kubelet that only supports absolute times
+			if !o.until.IsZero() {
+				req.Param("untilTime", o.until.UTC().Format(time.RFC3339))
+			}
+			if !o.since.IsZero() {
+				req.Param("sinceTime", o.since.UTC().Format(time.RFC3339))
+			}
+			if o.journal != nil {
+				// entries are filtered and formatted as requested on the client
+				req.Param("output", "json")
+			} else if len(o.Output) > 0 {
 				req.Param("output", o.Output)
 			}
+			if len(o.Priority) > 0 {
+				req.Param("priority", o.Priority)
+			}
 			if o.BootChanaged {
 				req.Param("boot", fmt.Sprintf("%d", o.Boot))
 			}
@@ -289,9 +410,10 @@ func (o LogsOptions) RunLogs() error {
 		}
 
 		requests = append(requests, &logRequest{
-			node: info.Name,
-			req:  req,
-			raw:  o.Raw || o.Path == "journal",
+			node:    info.Name,
+			req:     req,
+			raw:     o.Raw || o.Path == "journal",
+			journal: o.journal,
 		})
 		return nil
 	})
@@ -311,20 +433,35 @@ func (o LogsOptions) RunLogs() error {
 	out := bufio.NewWriterSize(o.Out, 1024*16)
 	defer out.Flush()
 
-	if o.Unify {
+	// limits the number of nodes logs are retrieved from at the same time
+	active := make(chan struct{}, o.MaxConcurrency)
+
+	if len(o.Dir) > 0 {
+		errs = append(errs, o.writeRequestsToDir(requests, active)...)
+
+	} else if o.Unify {
 		// unified output is each source, interleaved in lexographic order (assumes
 		// the source input is sorted by time)
 		var readers []Reader
 		for i := range requests {
 			req := requests[i]
 			req.skipPrefix = true
-			pr, pw := io.Pipe()
-			readers = append(readers, Reader{
-				R: pr,
-			})
+			// the merge reader waits for a line from every node, so the nodes
+			// beyond --max-concurrency are only reached if the ones before them
+			// never block writing their logs, spilling them to a temporary file
+			// past maxBufferedPipeMemory
+			pipe := newBufferedPipe(maxBufferedPipeMemory)
+			defer pipe.Close()
+			reader := Reader{R: pipe}
+			if o.Prefix {
+				reader.Prefix = []byte(fmt.Sprintf("%s ", req.node))
+			}
+			readers = append(readers, reader)
 			go func() {
-				err := req.WriteRequest(pw)
-				pw.CloseWithError(err)
+				active <- struct{}{}
+				defer func() { <-active }()
+				err := req.WriteRequest(pipe)
+				pipe.CloseWithError(err)
 			}()
 		}
 		_, err := NewMergeReader(readers...).WriteTo(out)
@@ -332,6 +469,33 @@ func (o LogsOptions) RunLogs() error {
 			errs = append(errs, agg.Errors()...)
 		}
 
+	} else if o.Prefix {
+		// stream from many nodes at once, writing whole lines so that the lines
+		// of the nodes are interleaved as they arrive
+		var lock sync.Mutex
+		var wg sync.WaitGroup
+		for i := range requests {
+			req := requests[i]
+			req.skipPrefix = true
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				active <- struct{}{}
+				defer func() { <-active }()
+				w := &prefixWriter{lock: &lock, out: out, prefix: []byte(fmt.Sprintf("%s ", req.node))}
+				err := req.WriteRequest(w)
+				if flushErr := w.Flush(); err == nil {
+					err = flushErr
+				}
+				if err != nil {
+					lock.Lock()
+					defer lock.Unlock()
+					errs = append(errs, err)
+				}
+			}()
+		}
+		wg.Wait()
+
 	} else {
 		// display files sequentially
 		for _, req := range requests {
@@ -357,6 +521,78 @@ func (o LogsOptions) RunLogs() error {
 	return nil
 }
 
+// writeRequestsToDir writes the logs of each node to its own compressed file under Dir, from up
+// to MaxConcurrency nodes at a time.
+func (o LogsOptions) writeRequestsToDir(requests []*logRequest, active chan struct{}) []error {
+	name := logFileName(o.Path, o.Units, o.Output)
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	var errs []error
+	for i := range requests {
+		req := requests[i]
+		req.skipPrefix = true
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			active <- struct{}{}
+			defer func() { <-active }()
+			file := filepath.Join(o.Dir, req.node, name)
+			err := writeRequestToFile(req, file)
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			fmt.Fprintf(o.Out, "%s\n", file)
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+func writeRequestToFile(req *logRequest, file string) error {
+	if req.err != nil {
+		return req.err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gw := gzip.NewWriter(f)
+	if err := req.WriteRequest(gw); err != nil {
+		return fmt.Errorf("%s: %v", req.node, err)
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// logFileName returns the name, relative to the directory of a node, of the file its logs are
+// written to with --dir. The journal is named after the units retrieved, and the listing of a
+// directory of /var/log is written to an index file in it.
+func logFileName(path string, units []string, output string) string {
+	if path == "journal" {
+		name := "journal"
+		for _, unit := range units {
+			name += "-" + strings.TrimSuffix(filepath.Base(unit), ".service")
+		}
+		if output == "json" {
+			return name + ".json.gz"
+		}
+		return name + ".log.gz"
+	}
+	if len(path) == 0 || strings.HasSuffix(path, "/") {
+		return filepath.Join(filepath.Clean("/"+path), "index.txt.gz")[1:]
+	}
+	return strings.TrimSuffix(filepath.Clean("/"+path), ".gz")[1:] + ".gz"
+}
+
 func optionallyDecompress(out io.Writer, in io.Reader) error {
 	bufferSize := 4096
 	buf := bufio.NewReaderSize(in, bufferSize)
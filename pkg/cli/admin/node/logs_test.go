@@ -123,3 +123,29 @@ func readers(all ...string) []Reader {
 	}
 	return out
 }
+
+func Test_logFileName(t *testing.T) {
+	tests := []struct {
+		path   string
+		units  []string
+		output string
+		want   string
+	}{
+		{path: "journal", want: "journal.log.gz"},
+		{path: "journal", units: []string{"kubelet", "crio.service"}, want: "journal-kubelet-crio.log.gz"},
+		{path: "journal", units: []string{"kubelet"}, output: "json", want: "journal-kubelet.json.gz"},
+		{path: "/", want: "index.txt.gz"},
+		{path: "openshift-apiserver/", want: "openshift-apiserver/index.txt.gz"},
+		{path: "cron", want: "cron.gz"},
+		{path: "openshift-apiserver/audit.log", want: "openshift-apiserver/audit.log.gz"},
+		{path: "messages-20240101.gz", want: "messages-20240101.gz"},
+		{path: "../../etc/passwd", want: "etc/passwd.gz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := logFileName(tt.path, tt.units, tt.output); got != tt.want {
+				t.Errorf("logFileName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
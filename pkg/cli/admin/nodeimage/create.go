@@ -24,6 +24,7 @@ import (
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	kutils "k8s.io/client-go/util/exec"
 	"k8s.io/kubectl/pkg/cmd/exec"
+	"k8s.io/kubectl/pkg/cmd/logs"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/templates"
 	"sigs.k8s.io/yaml"
@@ -68,6 +69,17 @@ var (
 		others will be optional (note: any eventual configuration file present
 		will be ignored).
 
+		Additional manifests to be applied when the nodes are added can be embedded
+		in the image using '--manifests-dir', while '--network-configs-dir' allows to
+		provide the static network configuration of each host, using a NMState file
+		named after the MAC address of one of its interfaces.
+
+		When '--monitor' is set, once the image has been created the command keeps
+		monitoring the nodes booted with it until they have joined the cluster, as
+		"oc adm node-image monitor" does. The nodes are identified by the first
+		static IP address configured for each host, unless '--monitor-ip-addresses'
+		is specified.
+
 		In case of a command failure a report.json file is automatically created
 		with the error details, and additional troubleshooting information.
 	`)
@@ -91,6 +103,12 @@ var (
 		# Create an ISO to add a single node with a root device hint and without
 		# using the configuration file
 		  oc adm node-image create --mac-address=00:d8:e7:c7:4b:bb --root-device-hint=deviceName:/dev/sda
+
+		# Create an ISO embedding the manifests of the manifests folder, with the
+		# static network configuration of each host read from the network folder
+		# (for example network/00-d8-e7-c7-4b-bb.yaml), and monitor the nodes
+		# booted with it until they have joined the cluster
+		  oc adm node-image create --manifests-dir=manifests --network-configs-dir=network --monitor
 	`)
 
 	createCommand = "oc adm node-image create"
@@ -137,6 +155,16 @@ type CreateOptions struct {
 	GeneratePXEFiles bool
 	// GenerateReport allows to save the report in the asset folder
 	GenerateReport bool
+	// ManifestsDir is the folder, relative to the assets folder, containing
+	// additional manifests to be embedded in the image.
+	ManifestsDir string
+	// NetworkConfigsDir is the folder, relative to the assets folder, containing
+	// the static network configuration of each host, named after its MAC address.
+	NetworkConfigsDir string
+	// Monitor allows to monitor the nodes booted with the generated image.
+	Monitor bool
+	// MonitorIPAddresses are the IP addresses of the nodes to monitor.
+	MonitorIPAddresses string
 
 	// Simpler interface for creating a single node
 	SingleNodeOpts *singleNodeCreateOptions
@@ -144,6 +172,8 @@ type CreateOptions struct {
 	report      *report
 	rsyncRshCmd string
 	fileWriter  fileWriter
+	manifests   map[string]string
+	monitorFn   func(*MonitorOptions) error
 }
 
 type singleNodeCreateOptions struct {
@@ -163,6 +193,10 @@ func (o *CreateOptions) AddFlags(cmd *cobra.Command) {
 	flags.StringVarP(&o.OutputName, "output-name", "o", "", "The name of the output image.")
 	flags.BoolVarP(&o.GeneratePXEFiles, "pxe", "p", false, "Instead of an ISO, create files that can be used for PXE boot")
 	flags.BoolVarP(&o.GenerateReport, "report", "r", false, "When set, the report.json is always generated in the asset folder")
+	flags.StringVar(&o.ManifestsDir, "manifests-dir", o.ManifestsDir, "The path, relative to the assets folder, containing additional manifests to be embedded in the image.")
+	flags.StringVar(&o.NetworkConfigsDir, "network-configs-dir", o.NetworkConfigsDir, "The path, relative to the assets folder, containing the NMState configuration of each host, in a file named after the MAC address of one of its interfaces.")
+	flags.BoolVar(&o.Monitor, "monitor", o.Monitor, "When set, monitor the nodes booted with the generated image until they have joined the cluster.")
+	flags.StringVar(&o.MonitorIPAddresses, "monitor-ip-addresses", o.MonitorIPAddresses, "IP addresses of the nodes to monitor. Defaults to the first static IP address of each host. Valid only when `monitor` is set.")
 
 	flags.StringP(snFlagMacAddress, "m", "", "Single node flag. MAC address used to identify the host to apply the configuration. If specified, the nodes-config.yaml config file will not be used.")
 	usageFmt := "Single node flag. %s. Valid only when `mac-address` is defined."
@@ -194,6 +228,9 @@ func (o *CreateOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []
 		return rsync.NewDefaultCopyStrategy(o)
 	}
 	o.fileWriter = o
+	o.monitorFn = func(m *MonitorOptions) error {
+		return m.Run()
+	}
 	return o.completeSingleNodeOptions(cmd)
 }
 
@@ -253,6 +290,33 @@ func (o *CreateOptions) Validate() error {
 		}
 	}
 
+	if o.MonitorIPAddresses != "" && !o.Monitor {
+		return fmt.Errorf("--monitor-ip-addresses requires --monitor to be set")
+	}
+	if o.ManifestsDir != "" {
+		manifests, err := o.readManifests()
+		if err != nil {
+			return err
+		}
+		o.manifests = manifests
+	}
+	if o.NetworkConfigsDir != "" || (o.Monitor && o.MonitorIPAddresses == "") {
+		data, err := o.nodesConfig()
+		if err != nil {
+			return err
+		}
+		if o.Monitor && o.MonitorIPAddresses == "" {
+			ips, err := nodesIPAddresses(data)
+			if err != nil {
+				return err
+			}
+			o.MonitorIPAddresses = strings.Join(ips, ",")
+		}
+	}
+	if o.Monitor {
+		return validateIPAddresses(o.MonitorIPAddresses)
+	}
+
 	return nil
 }
 
@@ -277,8 +341,18 @@ func (o *CreateOptions) validateConfigFile() error {
 
 // Run creates a temporary namespace to kick-off a pod for running the node-joiner
 // cli tool. If the command is successfull, it will download the generated image
-// from the pod.
+// from the pod, and monitor the nodes booted with it when requested.
 func (o *CreateOptions) Run() error {
+	if err := o.createImage(); err != nil {
+		return err
+	}
+	if !o.Monitor {
+		return nil
+	}
+	return o.monitorNodes()
+}
+
+func (o *CreateOptions) createImage() error {
 	ctx := context.Background()
 	defer o.cleanup(ctx)
 
@@ -289,6 +363,7 @@ func (o *CreateOptions) Run() error {
 		o.createServiceAccount,
 		o.createRolesAndBindings,
 		o.createInputConfigMap,
+		o.createManifestsConfigMap,
 		o.createPod,
 	}
 	err := o.runNodeJoinerPod(ctx, tasks)
@@ -328,6 +403,25 @@ func (o *CreateOptions) Run() error {
 	return nil
 }
 
+// monitorNodes runs the monitor command for the nodes booted with the generated
+// image, sharing the connection to the cluster of the create command.
+func (o *CreateOptions) monitorNodes() error {
+	m := NewMonitorOptions(o.IOStreams)
+	m.SecurityOptions = o.SecurityOptions
+	m.Config = o.Config
+	m.Client = o.Client
+	m.ConfigClient = o.ConfigClient
+	m.RESTClientGetter = o.RESTClientGetter
+	m.remoteExecutor = o.remoteExecutor
+	m.IPAddressesToMonitor = o.MonitorIPAddresses
+	m.updateLogsFn = func(opts *logs.LogsOptions) error {
+		return opts.RunLogs()
+	}
+
+	o.log("Monitoring the nodes %s, boot them using the generated image", o.MonitorIPAddresses)
+	return o.monitorFn(m)
+}
+
 func (o *CreateOptions) attachPodLogsToReport(ctx context.Context, err error) {
 	o.log("unexpected error caught while running the command, storing pod logs in report")
 
@@ -661,15 +755,10 @@ func (o *CreateOptions) createConfigFileFromFlags() ([]byte, error) {
 }
 
 func (o *CreateOptions) createInputConfigMap(ctx context.Context) error {
-	var data []byte
-	var err error
-
 	if o.SingleNodeOpts != nil {
 		klog.V(2).Info("Single node flags found, ignoring configuration file.")
-		data, err = o.createConfigFileFromFlags()
-	} else {
-		data, err = fs.ReadFile(o.FSys, nodeJoinerConfigurationFile)
 	}
+	data, err := o.nodesConfig()
 	if err != nil {
 		return err
 	}
@@ -692,6 +781,27 @@ func (o *CreateOptions) createInputConfigMap(ctx context.Context) error {
 	return nil
 }
 
+func (o *CreateOptions) createManifestsConfigMap(ctx context.Context) error {
+	if len(o.manifests) == 0 {
+		return nil
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      nodeJoinerManifestsConfigMap,
+			Namespace: o.nodeJoinerNamespace.GetName(),
+		},
+		Data: o.manifests,
+	}
+
+	_, err := o.Client.CoreV1().ConfigMaps(o.nodeJoinerNamespace.GetName()).Create(ctx, cm, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("cannot create manifests configmap: %w", err)
+	}
+
+	return nil
+}
+
 func (o *CreateOptions) nodeJoinerCommand() string {
 	if o.GeneratePXEFiles {
 		return "node-joiner add-nodes --pxe"
@@ -699,6 +809,15 @@ func (o *CreateOptions) nodeJoinerCommand() string {
 	return "node-joiner add-nodes"
 }
 
+// copyManifestsCommand returns the command copying the additional manifests in
+// the openshift folder of the assets, from where node-joiner embeds them.
+func (o *CreateOptions) copyManifestsCommand() string {
+	if len(o.manifests) == 0 {
+		return ""
+	}
+	return "mkdir -p /assets/openshift; cp -L /manifests/* /assets/openshift; "
+}
+
 func (o *CreateOptions) createPod(ctx context.Context) error {
 	assetsVolSize := resource.MustParse("4Gi")
 	nodeJoinerPod := &corev1.Pod{
@@ -753,12 +872,28 @@ func (o *CreateOptions) createPod(ctx context.Context) error {
 					},
 					Command: []string{
 						"/bin/bash", "-c",
-						fmt.Sprintf("cp /config/%s /assets; HOME=/assets %s --dir=/assets --log-level=debug; sleep 600", nodeJoinerConfigurationFile, o.nodeJoinerCommand()),
+						fmt.Sprintf("cp /config/%s /assets; %sHOME=/assets %s --dir=/assets --log-level=debug; sleep 600", nodeJoinerConfigurationFile, o.copyManifestsCommand(), o.nodeJoinerCommand()),
 					},
 				},
 			},
 		},
 	}
+	if len(o.manifests) > 0 {
+		nodeJoinerPod.Spec.Volumes = append(nodeJoinerPod.Spec.Volumes, corev1.Volume{
+			Name: nodeJoinerManifestsConfigMap,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: nodeJoinerManifestsConfigMap,
+					},
+				},
+			},
+		})
+		nodeJoinerPod.Spec.Containers[0].VolumeMounts = append(nodeJoinerPod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      nodeJoinerManifestsConfigMap,
+			MountPath: "/manifests",
+		})
+	}
 
 	err := o.configurePodProxySetting(ctx, nodeJoinerPod)
 	if err != nil {
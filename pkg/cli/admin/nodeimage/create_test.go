@@ -51,14 +51,32 @@ var (
   interfaces:
   - name: eth0
     macAddress: 00:b9:9b:c8:ac:f4`
+
+	staticNetworkConfigYaml = `interfaces:
+- name: eth0
+  type: ethernet
+  state: up
+  ipv4:
+    enabled: true
+    dhcp: false
+    address:
+    - ip: 192.168.111.90
+      prefix-length: 24`
 )
 
 func TestValidate(t *testing.T) {
 	testCases := []struct {
-		name          string
-		nodesConfig   *string
-		outputName    *string
-		expectedError string
+		name               string
+		nodesConfig        *string
+		outputName         *string
+		files              map[string]string
+		manifestsDir       string
+		networkConfigsDir  string
+		monitor            bool
+		monitorIPAddresses string
+
+		expectedError              string
+		expectedMonitorIPAddresses string
 	}{
 		{
 			name:        "default",
@@ -73,6 +91,84 @@ func TestValidate(t *testing.T) {
 			nodesConfig:   strPtr("invalid: yaml\n\tfile"),
 			expectedError: "config file nodes-config.yaml is not valid",
 		},
+		{
+			name:        "monitor nodes with static network configuration",
+			nodesConfig: &defaultNodesConfigYaml,
+			files: map[string]string{
+				"network/00-B9-9B-C8-AC-F4.yaml": staticNetworkConfigYaml,
+			},
+			networkConfigsDir:          "network",
+			monitor:                    true,
+			expectedMonitorIPAddresses: "192.168.111.90",
+		},
+		{
+			name:                       "monitor nodes with explicit IP addresses",
+			nodesConfig:                &defaultNodesConfigYaml,
+			monitor:                    true,
+			monitorIPAddresses:         "192.168.111.91,192.168.111.92",
+			expectedMonitorIPAddresses: "192.168.111.91,192.168.111.92",
+		},
+		{
+			name:          "monitor nodes without static IP address",
+			nodesConfig:   &defaultNodesConfigYaml,
+			monitor:       true,
+			expectedError: "host extra-worker-0 has no static IP address configured",
+		},
+		{
+			name:               "monitor IP addresses without monitor",
+			nodesConfig:        &defaultNodesConfigYaml,
+			monitorIPAddresses: "192.168.111.91",
+			expectedError:      "--monitor-ip-addresses requires --monitor to be set",
+
+			expectedMonitorIPAddresses: "192.168.111.91",
+		},
+		{
+			name:        "network configuration not matching any host",
+			nodesConfig: &defaultNodesConfigYaml,
+			files: map[string]string{
+				"network/00-b9-9b-c8-ac-f5.yaml": staticNetworkConfigYaml,
+			},
+			networkConfigsDir: "network",
+			expectedError:     "the network configuration found for 00:b9:9b:c8:ac:f5 in network does not match any host interface",
+		},
+		{
+			name:        "network configuration not named after a MAC address",
+			nodesConfig: &defaultNodesConfigYaml,
+			files: map[string]string{
+				"network/extra-worker-0.yaml": staticNetworkConfigYaml,
+			},
+			networkConfigsDir: "network",
+			expectedError:     "the network configuration extra-worker-0.yaml must be named after the MAC address of the host interface",
+		},
+		{
+			name: "network configuration for a host already configured",
+			nodesConfig: strPtr(defaultNodesConfigYaml + `
+  networkConfig:
+    interfaces: []`),
+			files: map[string]string{
+				"network/00:b9:9b:c8:ac:f4.yml": staticNetworkConfigYaml,
+			},
+			networkConfigsDir: "network",
+			expectedError:     "host extra-worker-0 already has a network configuration",
+		},
+		{
+			name:        "invalid manifest",
+			nodesConfig: &defaultNodesConfigYaml,
+			files: map[string]string{
+				"manifests/chrony.yaml": "invalid: yaml\n\tfile",
+			},
+			manifestsDir:  "manifests",
+			expectedError: "manifest chrony.yaml is not valid",
+		},
+		{
+			name:        "empty manifests folder",
+			nodesConfig: &defaultNodesConfigYaml,
+			files: map[string]string{
+				"manifests/README.md": "",
+			},
+			manifestsDir:  "manifests",
+			expectedError: "no manifest found in manifests",
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -82,11 +178,23 @@ func TestValidate(t *testing.T) {
 					Data: []byte(*tc.nodesConfig),
 				}
 			}
+			for name, data := range tc.files {
+				fakeFileSystem[name] = &fstest.MapFile{
+					Data: []byte(data),
+				}
+			}
 			o := &CreateOptions{
-				FSys: fakeFileSystem,
+				FSys:               fakeFileSystem,
+				ManifestsDir:       tc.manifestsDir,
+				NetworkConfigsDir:  tc.networkConfigsDir,
+				Monitor:            tc.monitor,
+				MonitorIPAddresses: tc.monitorIPAddresses,
 			}
 
 			err := o.Validate()
+			if o.MonitorIPAddresses != tc.expectedMonitorIPAddresses {
+				t.Errorf("expected monitor IP addresses %q, actual %q", tc.expectedMonitorIPAddresses, o.MonitorIPAddresses)
+			}
 
 			if tc.expectedError == "" {
 				if err != nil {
@@ -136,6 +244,9 @@ func TestRun(t *testing.T) {
 		nodesConfig      string
 		assetsDir        string
 		generatePXEFiles bool
+		files            map[string]string
+		manifestsDir     string
+		monitor          bool
 
 		objects          func(string, string) []runtime.Object
 		remoteExecOutput string
@@ -143,7 +254,9 @@ func TestRun(t *testing.T) {
 		expectedErrorCode    int
 		expectedError        string
 		expectedPod          func(t *testing.T, pod *corev1.Pod)
+		expectedConfigMaps   func(t *testing.T, configMaps []corev1.ConfigMap)
 		expectedRsyncInclude []string
+		expectedMonitor      string
 	}{
 		{
 			name:                 "default",
@@ -235,6 +348,61 @@ func TestRun(t *testing.T) {
 			objects:          ClusterVersion_4_17_ObjectFn,
 			remoteExecOutput: "0",
 		},
+		{
+			name:        "embed additional manifests",
+			nodesConfig: defaultNodesConfigYaml,
+			objects:     defaultClusterVersionObjectFn,
+			files: map[string]string{
+				"manifests/chrony.yaml": "kind: MachineConfig",
+			},
+			manifestsDir: "manifests",
+			expectedPod: func(t *testing.T, pod *corev1.Pod) {
+				if !strings.Contains(pod.Spec.Containers[0].Command[2], "cp -L /manifests/* /assets/openshift") {
+					t.Errorf("expected the manifests to be copied in the assets, but found command %q", pod.Spec.Containers[0].Command[2])
+				}
+				mounted := false
+				for _, m := range pod.Spec.Containers[0].VolumeMounts {
+					if m.Name == nodeJoinerManifestsConfigMap && m.MountPath == "/manifests" {
+						mounted = true
+					}
+				}
+				if !mounted {
+					t.Errorf("expected the manifests configmap to be mounted, found %v", pod.Spec.Containers[0].VolumeMounts)
+				}
+			},
+			expectedConfigMaps: func(t *testing.T, configMaps []corev1.ConfigMap) {
+				for _, cm := range configMaps {
+					if cm.Name == nodeJoinerManifestsConfigMap {
+						if cm.Data["chrony.yaml"] != "kind: MachineConfig" {
+							t.Errorf("unexpected manifests configmap data: %v", cm.Data)
+						}
+						return
+					}
+				}
+				t.Errorf("manifests configmap not found")
+			},
+		},
+		{
+			name:        "monitor the nodes once the image is created",
+			nodesConfig: defaultNodesConfigYaml,
+			objects:     defaultClusterVersionObjectFn,
+			files: map[string]string{
+				"network/00-b9-9b-c8-ac-f4.yaml": staticNetworkConfigYaml,
+			},
+			monitor:         true,
+			expectedMonitor: "192.168.111.90",
+			expectedConfigMaps: func(t *testing.T, configMaps []corev1.ConfigMap) {
+				for _, cm := range configMaps {
+					if cm.Name == "nodes-config" {
+						if !strings.Contains(cm.Data[nodeJoinerConfigurationFile], "ip: 192.168.111.90") {
+							t.Errorf("expected the static network configuration in the nodes config, found %s", cm.Data[nodeJoinerConfigurationFile])
+						}
+						return
+					}
+				}
+				t.Errorf("nodes-config configmap not found")
+			},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -248,6 +416,11 @@ func TestRun(t *testing.T) {
 					Data: []byte(tc.nodesConfig),
 				}
 			}
+			for name, data := range tc.files {
+				fakeFileSystem[name] = &fstest.MapFile{
+					Data: []byte(data),
+				}
+			}
 			// Allow the test case to use the right digest created by the fake registry.
 			objs := []runtime.Object{}
 			if tc.objects != nil {
@@ -290,12 +463,25 @@ func TestRun(t *testing.T) {
 
 				AssetsDir:        tc.assetsDir,
 				GeneratePXEFiles: tc.generatePXEFiles,
+				ManifestsDir:     tc.manifestsDir,
+				Monitor:          tc.monitor,
 				fileWriter:       mockFileWriter{},
 			}
+			if tc.monitor {
+				o.NetworkConfigsDir = "network"
+			}
+			var monitoredIPAddresses string
+			o.monitorFn = func(m *MonitorOptions) error {
+				monitoredIPAddresses = m.IPAddressesToMonitor
+				return nil
+			}
 			// Since the fake registry creates a self-signed cert, let's configure
 			// the command options accordingly
 			o.SecurityOptions.Insecure = true
 
+			if err := o.Validate(); err != nil {
+				t.Fatalf("unexpected validation error: %v", err)
+			}
 			err := o.Run()
 			assertContainerImageAndErrors(t, err, fakeReg, fakeClient, tc.expectedErrorCode, tc.expectedError, nodeJoinerContainer)
 
@@ -305,6 +491,18 @@ func TestRun(t *testing.T) {
 				tc.expectedPod(t, pod)
 			}
 
+			if tc.expectedConfigMaps != nil {
+				configMaps, err := fakeClient.CoreV1().ConfigMaps("").List(context.Background(), metav1.ListOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				tc.expectedConfigMaps(t, configMaps.Items)
+			}
+
+			if monitoredIPAddresses != tc.expectedMonitor {
+				t.Errorf("expected the nodes %q to be monitored, actual %q", tc.expectedMonitor, monitoredIPAddresses)
+			}
+
 			if tc.expectedError == "" {
 				if fakeCp.options.Destination.Path != tc.assetsDir {
 					t.Errorf("expected %v, actual %v", fakeCp.options.Destination.Path, tc.assetsDir)
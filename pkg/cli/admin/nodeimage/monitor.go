@@ -126,8 +126,11 @@ func (o *MonitorOptions) Validate() error {
 	if o.IPAddressesToMonitor == "" {
 		return fmt.Errorf("--ip-addresses cannot be empty")
 	}
+	return validateIPAddresses(o.IPAddressesToMonitor)
+}
 
-	for _, ip := range strings.Split(o.IPAddressesToMonitor, ",") {
+func validateIPAddresses(ipAddresses string) error {
+	for _, ip := range strings.Split(ipAddresses, ",") {
 		parsedIPAddress := net.ParseIP(ip)
 		if parsedIPAddress == nil {
 			return fmt.Errorf("%s is not valid IP address", ip)
@@ -0,0 +1,218 @@
+package nodeimage
+
+import (
+	"fmt"
+	"io/fs"
+	"net"
+	"path"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	nodeJoinerManifestsConfigMap = "nodes-manifests"
+	// maxConfigMapSize is the maximum size of the data stored in a ConfigMap
+	maxConfigMapSize = 1024 * 1024
+)
+
+var manifestExtensions = regexp.MustCompile(`\.(yaml|yml|json)$`)
+
+// nodesConfig returns the content of the nodes-config.yaml file used to create
+// the image, either read from the assets folder or generated from the single
+// node flags, with the static network configurations of --network-configs-dir
+// applied to its hosts.
+func (o *CreateOptions) nodesConfig() ([]byte, error) {
+	var data []byte
+	var err error
+	if o.SingleNodeOpts != nil {
+		data, err = o.createConfigFileFromFlags()
+	} else {
+		data, err = fs.ReadFile(o.FSys, nodeJoinerConfigurationFile)
+	}
+	if err != nil || o.NetworkConfigsDir == "" {
+		return data, err
+	}
+
+	networkConfigs, err := o.readNetworkConfigs()
+	if err != nil {
+		return nil, err
+	}
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("config file %s is not valid: %w", nodeJoinerConfigurationFile, err)
+	}
+	applied := map[string]bool{}
+	for _, host := range hosts(config) {
+		for _, mac := range hostMacAddresses(host) {
+			networkConfig, ok := networkConfigs[mac]
+			if !ok {
+				continue
+			}
+			if _, found := host["networkConfig"]; found {
+				return nil, fmt.Errorf("host %s already has a network configuration, cannot apply the one found for %s in %s", hostName(host), mac, o.NetworkConfigsDir)
+			}
+			host["networkConfig"] = networkConfig
+			applied[mac] = true
+		}
+	}
+	for mac := range networkConfigs {
+		if !applied[mac] {
+			return nil, fmt.Errorf("the network configuration found for %s in %s does not match any host interface", mac, o.NetworkConfigsDir)
+		}
+	}
+	return yaml.Marshal(config)
+}
+
+// readNetworkConfigs reads the NMState configurations of --network-configs-dir,
+// keyed by the MAC address naming each file, for example 00-d8-e7-c7-4b-bb.yaml.
+func (o *CreateOptions) readNetworkConfigs() (map[string]map[string]interface{}, error) {
+	entries, err := fs.ReadDir(o.FSys, o.NetworkConfigsDir)
+	if err != nil {
+		return nil, err
+	}
+	networkConfigs := map[string]map[string]interface{}{}
+	for _, entry := range entries {
+		if entry.IsDir() || !manifestExtensions.MatchString(entry.Name()) {
+			continue
+		}
+		name := manifestExtensions.ReplaceAllString(entry.Name(), "")
+		mac, err := net.ParseMAC(strings.ReplaceAll(name, "-", ":"))
+		if err != nil {
+			return nil, fmt.Errorf("the network configuration %s must be named after the MAC address of the host interface: %w", entry.Name(), err)
+		}
+		if _, found := networkConfigs[mac.String()]; found {
+			return nil, fmt.Errorf("found more than one network configuration for %s in %s", mac, o.NetworkConfigsDir)
+		}
+		data, err := fs.ReadFile(o.FSys, path.Join(o.NetworkConfigsDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var networkConfig map[string]interface{}
+		if err := yaml.Unmarshal(data, &networkConfig); err != nil {
+			return nil, fmt.Errorf("network configuration %s is not valid: %w", entry.Name(), err)
+		}
+		networkConfigs[mac.String()] = networkConfig
+	}
+	if len(networkConfigs) == 0 {
+		return nil, fmt.Errorf("no network configuration found in %s", o.NetworkConfigsDir)
+	}
+	return networkConfigs, nil
+}
+
+// readManifests reads the additional manifests of --manifests-dir, keyed by
+// their file name.
+func (o *CreateOptions) readManifests() (map[string]string, error) {
+	entries, err := fs.ReadDir(o.FSys, o.ManifestsDir)
+	if err != nil {
+		return nil, err
+	}
+	manifests := map[string]string{}
+	size := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !manifestExtensions.MatchString(entry.Name()) {
+			continue
+		}
+		if errs := validation.IsConfigMapKey(entry.Name()); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid manifest file name %s: %s", entry.Name(), strings.Join(errs, ", "))
+		}
+		data, err := fs.ReadFile(o.FSys, path.Join(o.ManifestsDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var manifest interface{}
+		if err := yaml.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("manifest %s is not valid: %w", entry.Name(), err)
+		}
+		size += len(data)
+		manifests[entry.Name()] = string(data)
+	}
+	if len(manifests) == 0 {
+		return nil, fmt.Errorf("no manifest found in %s", o.ManifestsDir)
+	}
+	if size > maxConfigMapSize {
+		return nil, fmt.Errorf("the manifests in %s exceed the maximum size of %d bytes", o.ManifestsDir, maxConfigMapSize)
+	}
+	return manifests, nil
+}
+
+// nodesIPAddresses returns the first static IP address configured for each
+// host, which identifies the nodes to monitor once booted.
+func nodesIPAddresses(data []byte) ([]string, error) {
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("config file %s is not valid: %w", nodeJoinerConfigurationFile, err)
+	}
+	var ips []string
+	for _, host := range hosts(config) {
+		ip := hostIPAddress(host)
+		if ip == "" {
+			return nil, fmt.Errorf("host %s has no static IP address configured, use --monitor-ip-addresses to specify the nodes to monitor", hostName(host))
+		}
+		ips = append(ips, ip)
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no host found in %s", nodeJoinerConfigurationFile)
+	}
+	return ips, nil
+}
+
+func hosts(config map[string]interface{}) []map[string]interface{} {
+	var hosts []map[string]interface{}
+	list, _ := config["hosts"].([]interface{})
+	for _, item := range list {
+		if host, ok := item.(map[string]interface{}); ok {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+func hostMacAddresses(host map[string]interface{}) []string {
+	var macs []string
+	interfaces, _ := host["interfaces"].([]interface{})
+	for _, item := range interfaces {
+		iface, _ := item.(map[string]interface{})
+		value, _ := iface["macAddress"].(string)
+		if mac, err := net.ParseMAC(value); err == nil {
+			macs = append(macs, mac.String())
+		}
+	}
+	return macs
+}
+
+func hostName(host map[string]interface{}) string {
+	if hostname, ok := host["hostname"].(string); ok && hostname != "" {
+		return hostname
+	}
+	if macs := hostMacAddresses(host); len(macs) > 0 {
+		return macs[0]
+	}
+	return "<unknown>"
+}
+
+// hostIPAddress returns the first IPv4, or else IPv6, address of the NMState
+// network configuration of the host.
+func hostIPAddress(host map[string]interface{}) string {
+	networkConfig, _ := host["networkConfig"].(map[string]interface{})
+	interfaces, _ := networkConfig["interfaces"].([]interface{})
+	for _, family := range []string{"ipv4", "ipv6"} {
+		for _, item := range interfaces {
+			iface, _ := item.(map[string]interface{})
+			ipConfig, _ := iface[family].(map[string]interface{})
+			if enabled, ok := ipConfig["enabled"].(bool); ok && !enabled {
+				continue
+			}
+			addresses, _ := ipConfig["address"].([]interface{})
+			for _, address := range addresses {
+				entry, _ := address.(map[string]interface{})
+				if ip, _ := entry["ip"].(string); net.ParseIP(ip) != nil {
+					return ip
+				}
+			}
+		}
+	}
+	return ""
+}
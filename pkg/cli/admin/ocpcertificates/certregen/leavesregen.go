@@ -68,7 +68,8 @@ func (o *LeavesRegen) forceRegenerationOnSecret(objPrinter *objectPrinter, kubeC
 	return err
 }
 
-func isRevisioned(meta metav1.ObjectMeta) bool {
+// IsRevisioned returns true for the copies of the objects made for the revisions of static pods.
+func IsRevisioned(meta metav1.ObjectMeta) bool {
 	for _, ref := range meta.OwnerReferences {
 		if ref.Kind == "ConfigMap" && strings.HasPrefix(ref.Name, "revision-status-") {
 			return true
@@ -81,7 +82,7 @@ func isRevisioned(meta metav1.ObjectMeta) bool {
 func IsPlatformCertSecret(s *corev1.Secret) bool {
 	return len(s.Annotations[certrotation.CertificateIssuer]) != 0 &&
 		len(s.Annotations[certrotation.CertificateNotBeforeAnnotation]) != 0 &&
-		!isRevisioned(s.ObjectMeta)
+		!IsRevisioned(s.ObjectMeta)
 }
 
 func IsLeafCertSecret(s *corev1.Secret) (bool, error) {
@@ -0,0 +1,139 @@
+package certreport
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	reportLong = templates.LongDesc(`
+		Report the expiry of the platform certificates in the cluster.
+
+		The certificates are those of the secrets managed by the certificate rotation of the
+		platform operators, and of the CA bundles stored in config maps. Each certificate is
+		listed with its expiry date, the issuer that rotates it and the component owning it,
+		and the certificates expiring within --within, or already expired, are flagged.
+
+		Experimental: This command is under active development and may change without notice.
+	`)
+
+	reportExample = templates.Examples(`
+		# Report the expiry of the platform certificates
+		oc adm ocp-certificates report
+
+		# Only report the certificates expiring in the next 10 days, as JSON
+		oc adm ocp-certificates report --within=10d --expiring-only -o json
+	`)
+)
+
+type ReportOptions struct {
+	RESTClientGetter genericclioptions.RESTClientGetter
+
+	Within       string
+	ExpiringOnly bool
+	Output       string
+
+	genericiooptions.IOStreams
+}
+
+func NewReportOptions(restClientGetter genericclioptions.RESTClientGetter, streams genericiooptions.IOStreams) *ReportOptions {
+	return &ReportOptions{
+		RESTClientGetter: restClientGetter,
+		Within:           "30d",
+
+		IOStreams: streams,
+	}
+}
+
+func NewCmdReport(restClientGetter genericclioptions.RESTClientGetter, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewReportOptions(restClientGetter, streams)
+
+	cmd := &cobra.Command{
+		Use:                   "report",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Report the expiry of the platform certificates"),
+		Long:                  reportLong,
+		Example:               reportExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			r, err := o.ToRuntime(args)
+			cmdutil.CheckErr(err)
+			cmdutil.CheckErr(r.Run(context.Background()))
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
+// AddFlags registers flags for a cli
+func (o *ReportOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Within, "within", o.Within, "Flag the certificates expiring within this duration, such as 30d or 72h.")
+	cmd.Flags().BoolVar(&o.ExpiringOnly, "expiring-only", o.ExpiringOnly, "Only report the certificates expired or expiring within --within.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json.")
+}
+
+func (o *ReportOptions) ToRuntime(args []string) (*ReportRuntime, error) {
+	if len(args) > 0 {
+		return nil, fmt.Errorf("no arguments are allowed")
+	}
+	if len(o.Output) > 0 && o.Output != "json" {
+		return nil, fmt.Errorf("--output must be json")
+	}
+	within, err := parseWithin(o.Within)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --within: %v", err)
+	}
+
+	clientConfig, err := o.RESTClientGetter.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	kubeClient, err := kubernetes.NewForConfig(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReportRuntime{
+		KubeClient: kubeClient,
+
+		within:       within,
+		withinName:   o.Within,
+		expiringOnly: o.ExpiringOnly,
+		json:         o.Output == "json",
+		now:          time.Now,
+
+		IOStreams: o.IOStreams,
+	}, nil
+}
+
+// parseWithin parses a duration, which may also be a number of days such as 30d.
+func parseWithin(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("%q must be a number of days or a duration", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("%q must not be negative", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,211 @@
+package certreport
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/kubernetes"
+	certutil "k8s.io/client-go/util/cert"
+
+	"github.com/openshift/api/annotations"
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+	"github.com/openshift/oc/pkg/cli/admin/ocpcertificates/certregen"
+)
+
+const (
+	StatusExpired  = "Expired"
+	StatusExpiring = "Expiring"
+	StatusValid    = "Valid"
+)
+
+type ReportRuntime struct {
+	KubeClient kubernetes.Interface
+
+	within       time.Duration
+	withinName   string
+	expiringOnly bool
+	json         bool
+	now          func() time.Time
+
+	genericiooptions.IOStreams
+}
+
+// CertificateReport is the JSON output of the report.
+type CertificateReport struct {
+	// Within is the duration the certificates are flagged as expiring within.
+	Within       string        `json:"within"`
+	Certificates []Certificate `json:"certificates"`
+}
+
+// Certificate is a certificate stored in a secret or in the CA bundle of a config map.
+type Certificate struct {
+	Namespace string `json:"namespace"`
+	// Name is the resource and name of the object storing the certificate, such as secrets/name.
+	Name string `json:"name"`
+	// Type is the managed certificate type of the object: signer, target or ca-bundle.
+	Type    string `json:"type"`
+	Subject string `json:"subject"`
+	Issuer  string `json:"issuer"`
+	// RotatedBy is the signer that issued the certificate and rotates it.
+	RotatedBy string `json:"rotatedBy,omitempty"`
+	// Owner is the component owning the certificate.
+	Owner     string    `json:"owner,omitempty"`
+	NotBefore time.Time `json:"notBefore"`
+	NotAfter  time.Time `json:"notAfter"`
+	// Status is Expired, Expiring if the certificate expires within the duration, or Valid.
+	Status string `json:"status"`
+}
+
+func (r *ReportRuntime) Run(ctx context.Context) error {
+	secrets, err := r.KubeClient.CoreV1().Secrets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	configMaps, err := r.KubeClient.CoreV1().ConfigMaps(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", certrotation.ManagedCertificateTypeLabelName, certrotation.CertificateTypeCABundle),
+	})
+	if err != nil {
+		return err
+	}
+
+	report := r.report(secrets.Items, configMaps.Items)
+	if r.json {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = r.Out.Write(append(data, '\n'))
+		return err
+	}
+	return r.print(report)
+}
+
+// report returns the certificates of the platform secrets and CA bundles, the first to expire
+// first.
+func (r *ReportRuntime) report(secrets []corev1.Secret, configMaps []corev1.ConfigMap) *CertificateReport {
+	now := r.now()
+	report := &CertificateReport{Within: r.withinName, Certificates: []Certificate{}}
+	add := func(meta metav1.ObjectMeta, name, certificateType string, cert *x509.Certificate) {
+		c := Certificate{
+			Namespace: meta.Namespace,
+			Name:      name,
+			Type:      certificateType,
+			Subject:   cert.Subject.CommonName,
+			Issuer:    cert.Issuer.CommonName,
+			RotatedBy: meta.Annotations[certrotation.CertificateIssuer],
+			Owner:     meta.Annotations[annotations.OpenShiftComponent],
+			NotBefore: cert.NotBefore,
+			NotAfter:  cert.NotAfter,
+			Status:    StatusValid,
+		}
+		switch {
+		case !now.Before(cert.NotAfter):
+			c.Status = StatusExpired
+		case now.Add(r.within).After(cert.NotAfter):
+			c.Status = StatusExpiring
+		}
+		if r.expiringOnly && c.Status == StatusValid {
+			return
+		}
+		report.Certificates = append(report.Certificates, c)
+	}
+
+	for i := range secrets {
+		s := &secrets[i]
+		_, labeled := s.Labels[certrotation.ManagedCertificateTypeLabelName]
+		if (!labeled && !certregen.IsPlatformCertSecret(s)) || certregen.IsRevisioned(s.ObjectMeta) {
+			continue
+		}
+		certPEM := s.Data[corev1.TLSCertKey]
+		if len(certPEM) == 0 {
+			continue
+		}
+		certs, err := certutil.ParseCertsPEM(certPEM)
+		if err != nil {
+			fmt.Fprintf(r.ErrOut, "warning: Unable to parse the certificate of secrets/%s[%s]: %v\n", s.Name, s.Namespace, err)
+			continue
+		}
+		// the first certificate is the one the secret holds the key of
+		certificateType := s.Labels[certrotation.ManagedCertificateTypeLabelName]
+		if len(certificateType) == 0 {
+			certificateType = string(certrotation.CertificateTypeTarget)
+			if certs[0].IsCA {
+				certificateType = string(certrotation.CertificateTypeSigner)
+			}
+		}
+		add(s.ObjectMeta, "secrets/"+s.Name, certificateType, certs[0])
+	}
+
+	for i := range configMaps {
+		cm := &configMaps[i]
+		if certregen.IsRevisioned(cm.ObjectMeta) {
+			continue
+		}
+		bundlePEM := cm.Data["ca-bundle.crt"]
+		if len(bundlePEM) == 0 {
+			continue
+		}
+		certs, err := certutil.ParseCertsPEM([]byte(bundlePEM))
+		if err != nil {
+			fmt.Fprintf(r.ErrOut, "warning: Unable to parse the CA bundle of configmaps/%s[%s]: %v\n", cm.Name, cm.Namespace, err)
+			continue
+		}
+		for _, cert := range certs {
+			add(cm.ObjectMeta, "configmaps/"+cm.Name, string(certrotation.CertificateTypeCABundle), cert)
+		}
+	}
+
+	sort.SliceStable(report.Certificates, func(i, j int) bool {
+		a, b := report.Certificates[i], report.Certificates[j]
+		if !a.NotAfter.Equal(b.NotAfter) {
+			return a.NotAfter.Before(b.NotAfter)
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return report
+}
+
+func (r *ReportRuntime) print(report *CertificateReport) error {
+	now := r.now()
+	w := printers.GetNewTabWriter(r.Out)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tTYPE\tSUBJECT\tNOT AFTER\tEXPIRES\tSTATUS\tROTATED BY\tOWNER")
+	expired, expiring := 0, 0
+	for _, c := range report.Certificates {
+		var expires string
+		switch c.Status {
+		case StatusExpired:
+			expired++
+			expires = duration.HumanDuration(now.Sub(c.NotAfter)) + " ago"
+		case StatusExpiring:
+			expiring++
+			fallthrough
+		default:
+			expires = "in " + duration.HumanDuration(c.NotAfter.Sub(now))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", c.Namespace, c.Name, c.Type, c.Subject, c.NotAfter.UTC().Format(time.RFC3339), expires, c.Status, valueOrNone(c.RotatedBy), valueOrNone(c.Owner))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(r.Out, "\n%d certificates expired, %d expiring within %s\n", expired, expiring, report.Within)
+	return nil
+}
+
+func valueOrNone(s string) string {
+	if len(s) == 0 {
+		return "<none>"
+	}
+	return s
+}
@@ -0,0 +1,148 @@
+package certreport
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	certutil "k8s.io/client-go/util/cert"
+
+	"github.com/openshift/api/annotations"
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+)
+
+func TestReport(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	signer := makeCert(t, "signer", true, now.Add(300*24*time.Hour))
+	serving := makeCert(t, "serving", false, now.Add(10*24*time.Hour))
+	expired := makeCert(t, "expired", false, now.Add(-time.Hour))
+
+	platformSecret := func(name string, cert []byte) corev1.Secret {
+		return corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "openshift-kube-apiserver",
+				Name:      name,
+				Annotations: map[string]string{
+					certrotation.CertificateIssuer:              "kube-apiserver-signer",
+					certrotation.CertificateNotBeforeAnnotation: now.Format(time.RFC3339),
+					annotations.OpenShiftComponent:              "kube-apiserver",
+				},
+			},
+			Data: map[string][]byte{corev1.TLSCertKey: cert},
+		}
+	}
+	revisioned := platformSecret("serving-1", serving)
+	revisioned.OwnerReferences = []metav1.OwnerReference{{Kind: "ConfigMap", Name: "revision-status-1"}}
+	secrets := []corev1.Secret{
+		platformSecret("signer", signer),
+		platformSecret("serving", serving),
+		platformSecret("expired", expired),
+		revisioned,
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "user"},
+			Data:       map[string][]byte{corev1.TLSCertKey: expired},
+		},
+	}
+	configMaps := []corev1.ConfigMap{{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-config-managed",
+			Name:      "kube-apiserver-server-ca",
+			Labels:    map[string]string{certrotation.ManagedCertificateTypeLabelName: string(certrotation.CertificateTypeCABundle)},
+		},
+		Data: map[string]string{"ca-bundle.crt": string(signer)},
+	}}
+
+	tests := []struct {
+		name         string
+		expiringOnly bool
+		want         []string
+	}{
+		{
+			name: "all",
+			want: []string{"secrets/expired Expired target", "secrets/serving Expiring target", "configmaps/kube-apiserver-server-ca Valid ca-bundle", "secrets/signer Valid signer"},
+		},
+		{
+			name:         "expiring only",
+			expiringOnly: true,
+			want:         []string{"secrets/expired Expired target", "secrets/serving Expiring target"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &ReportRuntime{
+				within:       30 * 24 * time.Hour,
+				withinName:   "30d",
+				expiringOnly: test.expiringOnly,
+				now:          func() time.Time { return now },
+				IOStreams:    genericiooptions.IOStreams{Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}},
+			}
+			report := r.report(secrets, configMaps)
+			var got []string
+			for _, c := range report.Certificates {
+				got = append(got, c.Name+" "+c.Status+" "+c.Type)
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("expected %v, got %v", test.want, got)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Errorf("expected %v, got %v", test.want, got)
+					break
+				}
+			}
+			if c := report.Certificates[0]; c.RotatedBy != "kube-apiserver-signer" || c.Owner != "kube-apiserver" {
+				t.Errorf("unexpected rotation owner of %s: %#v", c.Name, c)
+			}
+		})
+	}
+}
+
+func TestParseWithin(t *testing.T) {
+	for s, want := range map[string]time.Duration{"30d": 30 * 24 * time.Hour, "72h": 72 * time.Hour, "0d": 0} {
+		if got, err := parseWithin(s); err != nil || got != want {
+			t.Errorf("%s: expected %s, got %s, %v", s, want, got, err)
+		}
+	}
+	for _, s := range []string{"d", "-1d", "-1h", "month"} {
+		if _, err := parseWithin(s); err == nil {
+			t.Errorf("%s: expected an error", s)
+		}
+	}
+}
+
+func makeCert(t *testing.T, commonName string, isCA bool, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := certutil.EncodeCertificates(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
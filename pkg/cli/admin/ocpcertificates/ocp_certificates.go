@@ -10,6 +10,7 @@ import (
 	ktemplates "k8s.io/kubectl/pkg/util/templates"
 
 	"github.com/openshift/oc/pkg/cli/admin/ocpcertificates/certregen"
+	"github.com/openshift/oc/pkg/cli/admin/ocpcertificates/certreport"
 	"github.com/openshift/oc/pkg/cli/admin/ocpcertificates/monitorregeneration"
 	"github.com/openshift/oc/pkg/cli/admin/ocpcertificates/regeneratemco"
 	"github.com/openshift/oc/pkg/cli/admin/ocpcertificates/trustpurge"
@@ -36,6 +37,7 @@ func NewCommandOCPCertificates(f kcmdutil.Factory, streams genericiooptions.IOSt
 		regeneratemco.NewCmdUpdateUserData(f, streams),
 		monitorregeneration.NewCmdMonitorCertificates(f, streams),
 		trustpurge.NewCmdRemoveOldTrust(f, streams),
+		certreport.NewCmdReport(f, streams),
 	)
 
 	return cmds
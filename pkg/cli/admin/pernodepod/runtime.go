@@ -4,6 +4,8 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -48,6 +50,15 @@ type PerNodePodRuntime struct {
 	NumberOfNodesInParallel  int
 	PercentOfNodesInParallel int
 
+	// ConfirmFn, if set, is called with the nodes before any change is made and cancels the run
+	// when it returns an error.
+	ConfirmFn func(nodes []*corev1.Node) error
+	// VerifyNodeFn, if set, is called with the succeeded pod of a node, and the node is only
+	// reported as done and counted out of the nodes in parallel once it returns.
+	VerifyNodeFn func(ctx context.Context, nodeName string, pod *corev1.Pod) error
+	// StopOnFailure stops starting the pods of the remaining nodes once a node failed.
+	StopOnFailure bool
+
 	Printer printers.ResourcePrinter
 	genericiooptions.IOStreams
 }
@@ -69,6 +80,12 @@ func (r *PerNodePodRuntime) Run(ctx context.Context, prePodHookFn PrePodHookFunc
 		numberOfNodesInParallel = 1
 	}
 
+	if r.ConfirmFn != nil && !r.DryRun {
+		if err := r.ConfirmFn(interestingNodes); err != nil {
+			return err
+		}
+	}
+
 	// create a namespace to work in
 	nsName := "!!-dry-run"
 	if !r.DryRun {
@@ -115,6 +132,10 @@ func (r *PerNodePodRuntime) Run(ctx context.Context, prePodHookFn PrePodHookFunc
 	// consumer
 	wg := sync.WaitGroup{}
 	errCh := make(chan error, len(interestingNodes))
+	stopped := make(chan struct{})
+	stopOnce := sync.Once{}
+	skippedLock := sync.Mutex{}
+	skipped := []string{}
 	for i := 0; i < numberOfNodesInParallel; i++ {
 		wg.Add(1)
 		go func(ctx context.Context) {
@@ -125,8 +146,19 @@ func (r *PerNodePodRuntime) Run(ctx context.Context, prePodHookFn PrePodHookFunc
 					if !stillReady {
 						return
 					}
+					select {
+					case <-stopped:
+						skippedLock.Lock()
+						skipped = append(skipped, node.Name)
+						skippedLock.Unlock()
+						continue
+					default:
+					}
 					if restartErr := r.HandleNode(ctx, createPodFn, nsName, node); restartErr != nil {
 						errCh <- restartErr
+						if r.StopOnFailure {
+							stopOnce.Do(func() { close(stopped) })
+						}
 					}
 				case <-ctx.Done():
 					return
@@ -140,6 +172,10 @@ func (r *PerNodePodRuntime) Run(ctx context.Context, prePodHookFn PrePodHookFunc
 	for err := range errCh {
 		errs = append(errs, err)
 	}
+	if len(skipped) > 0 {
+		sort.Strings(skipped)
+		errs = append(errs, fmt.Errorf("stopped after a failure, %d nodes were left untouched: %s", len(skipped), strings.Join(skipped, ", ")))
+	}
 
 	return utilerrors.NewAggregate(errs)
 }
@@ -200,8 +236,15 @@ func (r *PerNodePodRuntime) HandleNode(ctx context.Context, createPodFn CreatePo
 
 	switch {
 	case finalPodState.Status.Phase == corev1.PodSucceeded:
-		r.Printer.PrintObj(node, r.Out)
 		_ = r.KubeClient.CoreV1().Pods(namespaceName).Delete(timeLimitedCtx, createdPod.Name, metav1.DeleteOptions{})
+		if r.VerifyNodeFn != nil {
+			if err := r.VerifyNodeFn(ctx, node.Name, finalPodState); err != nil {
+				retErr := fmt.Errorf("node/%v failed verification after --namespace=%v pod/%v: %w", node.Name, namespaceName, createdPod.Name, err)
+				fmt.Fprintln(r.ErrOut, retErr.Error())
+				return retErr
+			}
+		}
+		r.Printer.PrintObj(node, r.Out)
 
 	case finalPodState.Status.Phase == corev1.PodFailed:
 		terminationInfo := finalPodState.Status.ContainerStatuses[0].LastTerminationState.Terminated
@@ -37,6 +37,12 @@ var (
 		If no service account is provided the one specified in podTemplateSpec.spec.serviceAccountName is used,
 		unless it is empty, in which case "default" is used.
 		If service accounts are provided, the podTemplateSpec.spec.serviceAccountName is ignored.
+
+		A directory of manifests (with -R to recurse into its subdirectories) or a stream of several
+		objects can be reviewed at once, in which case the objects without a pod template are skipped
+		with a warning. Use -o summary-json or -o summary-yaml to print a summary of the result of each
+		object, including the objects that could not be reviewed or were skipped, so that admission
+		problems can be caught before deploying.
 	`)
 	reviewExamples = templates.Examples(`# Check whether service accounts sa1 and sa2 can admit a pod with a template pod spec specified in my_resource.yaml
 		# Service Account specified in myresource.yaml file is ignored
@@ -50,6 +56,9 @@ var (
 
 		# Check whether the default service account can admit the pod; default is taken since no service account is defined in myresource_with_no_sa.yaml
 		oc policy scc-review -f myresource_with_no_sa.yaml
+
+		# Check whether the workloads in the manifests directory and its subdirectories can be admitted, and print a summary in JSON
+		oc policy scc-review -f ./manifests -R -o summary-json
 	`)
 )
 
@@ -108,6 +117,7 @@ func NewCmdSccReview(f kcmdutil.Factory, streams genericiooptions.IOStreams, adm
 	cmd.Flags().BoolVar(&o.noHeaders, "no-headers", o.noHeaders, "When using the default output format, don't print headers (default print headers).")
 
 	o.PrintFlags.AddFlags(cmd)
+	addSummaryOutputFlags(cmd)
 	return cmd
 }
 
@@ -188,20 +198,7 @@ func (o *SCCReviewOptions) Run(args []string) error {
 	if err != nil {
 		return err
 	}
-	allErrs := []error{}
-	err = r.Visit(func(info *resource.Info, err error) error {
-		if err != nil {
-			return err
-		}
-		objectName := info.Name
-		podTemplateSpec, err := GetPodTemplateForObject(info.Object)
-		if err != nil {
-			return fmt.Errorf(" %q cannot create pod: %v", objectName, err)
-		}
-		err = CheckStatefulSetWithWolumeClaimTemplates(info.Object)
-		if err != nil {
-			return err
-		}
+	reviews, err := reviewObjects(r, func(info *resource.Info, podTemplateSpec *corev1.PodTemplateSpec) (runtime.Object, error) {
 		review := &securityv1.PodSecurityPolicyReview{
 			Spec: securityv1.PodSecurityPolicyReviewSpec{
 				Template:            *podTemplateSpec,
@@ -210,13 +207,11 @@ func (o *SCCReviewOptions) Run(args []string) error {
 		}
 		unversionedObj, err := o.client.PodSecurityPolicyReviews(o.namespace).Create(context.TODO(), review, metav1.CreateOptions{})
 		if err != nil {
-			return fmt.Errorf("unable to compute Pod Security Policy Review for %q: %v", objectName, err)
-		}
-		if err = o.Printer.WithInfo(info).PrintObj(unversionedObj, o.Out); err != nil {
-			allErrs = append(allErrs, err)
+			return nil, fmt.Errorf("unable to compute Pod Security Policy Review for %q: %v", info.Name, err)
 		}
-		return nil
+		return unversionedObj, nil
 	})
+	allErrs := printReviews(reviews, o.PrintFlags, o.Printer, o.Out, o.ErrOut)
 	allErrs = append(allErrs, err)
 	return utilerrors.NewAggregate(allErrs)
 }
@@ -0,0 +1,249 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/yaml"
+
+	securityv1 "github.com/openshift/api/security/v1"
+)
+
+// objectReview is the review of the pod template of an object read from the arguments.
+type objectReview struct {
+	info     *resource.Info
+	response runtime.Object
+	err      error
+	// skipped is why the object was not reviewed, without failing the command.
+	skipped string
+}
+
+// reviewObjects reviews the pod template of each object of the result. A failure to review an
+// object does not prevent the others from being reviewed, so that a directory or a stream of
+// objects is reviewed in a single pass. Objects without a pod template are reported as skipped
+// when more than one object is reviewed, since a directory of manifests usually holds more than
+// workloads.
+func reviewObjects(r *resource.Result, review func(info *resource.Info, podTemplateSpec *corev1.PodTemplateSpec) (runtime.Object, error)) ([]objectReview, error) {
+	infos, err := r.Infos()
+	reviews := []objectReview{}
+	for _, info := range infos {
+		podTemplateSpec, podErr := GetPodTemplateForObject(info.Object)
+		if podErr != nil {
+			if len(infos) > 1 {
+				reviews = append(reviews, objectReview{info: info, skipped: "no pod template"})
+				continue
+			}
+			reviews = append(reviews, objectReview{info: info, err: fmt.Errorf(" %q cannot create pod: %v", info.Name, podErr)})
+			continue
+		}
+		if err := CheckStatefulSetWithWolumeClaimTemplates(info.Object); err != nil {
+			reviews = append(reviews, objectReview{info: info, err: err})
+			continue
+		}
+		response, err := review(info, podTemplateSpec)
+		reviews = append(reviews, objectReview{info: info, response: response, err: err})
+	}
+	return reviews, err
+}
+
+const (
+	summaryJSONOutput = "summary-json"
+	summaryYAMLOutput = "summary-yaml"
+)
+
+// addSummaryOutputFlags adds the summary output formats to the help of the --output flag.
+func addSummaryOutputFlags(cmd *cobra.Command) {
+	if flag := cmd.Flags().Lookup("output"); flag != nil {
+		flag.Usage = strings.TrimSuffix(flag.Usage, ".") + ", " + summaryJSONOutput + ", " + summaryYAMLOutput + "."
+	}
+}
+
+// reviewedObject is the result of the review of an object printed with -o summary-json or
+// -o summary-yaml.
+type reviewedObject struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Source is the file or URL the object was read from, if any.
+	Source string `json:"source,omitempty"`
+	// Allowed is whether a security context constraint admits the pod of the object.
+	Allowed bool `json:"allowed"`
+	// AllowedBy is the security context constraint admitting the pod for the user, group or
+	// service account of scc-subject-review.
+	AllowedBy string `json:"allowedBy,omitempty"`
+	// ServiceAccounts are the service accounts admitted to create the pod by scc-review.
+	ServiceAccounts []reviewedServiceAccount `json:"serviceAccounts,omitempty"`
+	// Error is set when the object could not be reviewed.
+	Error string `json:"error,omitempty"`
+	// Skipped is why the object was not reviewed, such as an object without a pod template.
+	Skipped string `json:"skipped,omitempty"`
+}
+
+type reviewedServiceAccount struct {
+	Name      string `json:"name"`
+	AllowedBy string `json:"allowedBy,omitempty"`
+}
+
+type reviewedObjectList struct {
+	Items []reviewedObject `json:"items"`
+}
+
+// outputFormat returns the output format of the print flags.
+func outputFormat(printFlags *genericclioptions.PrintFlags) string {
+	if printFlags.OutputFormat == nil {
+		return ""
+	}
+	return *printFlags.OutputFormat
+}
+
+// isSummaryOutput returns whether the results are printed as a list of reviewedObject rather
+// than as the review objects.
+func isSummaryOutput(printFlags *genericclioptions.PrintFlags) bool {
+	switch outputFormat(printFlags) {
+	case summaryJSONOutput, summaryYAMLOutput:
+		return true
+	}
+	return false
+}
+
+// isListOutput returns whether the review objects are printed together in a v1 List.
+func isListOutput(printFlags *genericclioptions.PrintFlags) bool {
+	switch outputFormat(printFlags) {
+	case "json", "yaml":
+		return true
+	}
+	return false
+}
+
+// reviewKind returns the kind of a review object, which the responses of the server do not carry.
+func reviewKind(obj runtime.Object) schema.GroupVersionKind {
+	switch obj.(type) {
+	case *securityv1.PodSecurityPolicyReview:
+		return securityv1.GroupVersion.WithKind("PodSecurityPolicyReview")
+	case *securityv1.PodSecurityPolicySubjectReview:
+		return securityv1.GroupVersion.WithKind("PodSecurityPolicySubjectReview")
+	case *securityv1.PodSecurityPolicySelfSubjectReview:
+		return securityv1.GroupVersion.WithKind("PodSecurityPolicySelfSubjectReview")
+	}
+	return obj.GetObjectKind().GroupVersionKind()
+}
+
+func newReviewedObject(review objectReview) reviewedObject {
+	result := reviewedObject{
+		Kind:      printers.GetObjectGroupKind(review.info.Object).Kind,
+		Namespace: review.info.Namespace,
+		Name:      review.info.Name,
+		Source:    review.info.Source,
+	}
+	if review.err != nil {
+		result.Error = review.err.Error()
+		return result
+	}
+	if len(review.skipped) > 0 {
+		result.Skipped = review.skipped
+		return result
+	}
+	switch t := review.response.(type) {
+	case *securityv1.PodSecurityPolicyReview:
+		for _, sa := range t.Status.AllowedServiceAccounts {
+			account := reviewedServiceAccount{Name: sa.Name}
+			if sa.AllowedBy != nil {
+				account.AllowedBy = sa.AllowedBy.Name
+			}
+			result.ServiceAccounts = append(result.ServiceAccounts, account)
+		}
+		result.Allowed = len(result.ServiceAccounts) > 0
+	case *securityv1.PodSecurityPolicySubjectReview:
+		if t.Status.AllowedBy != nil {
+			result.AllowedBy = t.Status.AllowedBy.Name
+		}
+		result.Allowed = len(result.AllowedBy) > 0
+	case *securityv1.PodSecurityPolicySelfSubjectReview:
+		if t.Status.AllowedBy != nil {
+			result.AllowedBy = t.Status.AllowedBy.Name
+		}
+		result.Allowed = len(result.AllowedBy) > 0
+	}
+	return result
+}
+
+// printReviews prints the reviews, and returns the errors of the objects that could not be
+// reviewed. The skipped objects are reported on errOut, except with the summary output which
+// lists them.
+func printReviews(reviews []objectReview, printFlags *genericclioptions.PrintFlags, printer *policyPrinter, out, errOut io.Writer) []error {
+	errs := []error{}
+	if isSummaryOutput(printFlags) {
+		return printSummary(reviews, outputFormat(printFlags) == summaryYAMLOutput, out)
+	}
+
+	list := &corev1.List{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "List"}}
+	for _, review := range reviews {
+		if review.err != nil {
+			errs = append(errs, review.err)
+			continue
+		}
+		if len(review.skipped) > 0 {
+			fmt.Fprintf(errOut, "warning: skipping %s/%s: %s\n", printers.GetObjectGroupKind(review.info.Object).Kind, review.info.Name, review.skipped)
+			continue
+		}
+		if isListOutput(printFlags) {
+			review.response.GetObjectKind().SetGroupVersionKind(reviewKind(review.response))
+			list.Items = append(list.Items, runtime.RawExtension{Object: review.response})
+			continue
+		}
+		if err := printer.WithInfo(review.info).PrintObj(review.response, out); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if !isListOutput(printFlags) {
+		return errs
+	}
+	listPrinter, err := printFlags.ToPrinter()
+	if err != nil {
+		return append(errs, err)
+	}
+	if err := listPrinter.PrintObj(list, out); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// printSummary prints the reviews as a list of reviewedObject in JSON or YAML, and returns the
+// errors of the objects that could not be reviewed.
+func printSummary(reviews []objectReview, asYAML bool, out io.Writer) []error {
+	errs := []error{}
+
+	list := reviewedObjectList{Items: []reviewedObject{}}
+	for _, review := range reviews {
+		if review.err != nil {
+			errs = append(errs, review.err)
+		}
+		list.Items = append(list.Items, newReviewedObject(review))
+	}
+	var data []byte
+	var err error
+	if asYAML {
+		data, err = yaml.Marshal(list)
+	} else {
+		data, err = json.MarshalIndent(list, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return append(errs, err)
+	}
+	if _, err := out.Write(data); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
@@ -0,0 +1,151 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/kubectl/pkg/scheme"
+
+	securityv1 "github.com/openshift/api/security/v1"
+)
+
+const reviewedManifests = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+  namespace: test
+spec:
+  selector:
+    matchLabels:
+      app: frontend
+  template:
+    metadata:
+      labels:
+        app: frontend
+    spec:
+      serviceAccountName: frontend
+      containers:
+      - name: frontend
+        image: frontend
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: test
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: database
+  namespace: test
+spec:
+  selector:
+    matchLabels:
+      app: database
+  template:
+    metadata:
+      labels:
+        app: database
+    spec:
+      containers:
+      - name: database
+        image: database
+  volumeClaimTemplates:
+  - metadata:
+      name: data
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: privileged
+  namespace: test
+spec:
+  containers:
+  - name: privileged
+    image: privileged
+`
+
+func TestReviewObjects(t *testing.T) {
+	r := resource.NewLocalBuilder().
+		WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...).
+		Stream(strings.NewReader(reviewedManifests), "manifests.yaml").
+		ContinueOnError().
+		Flatten().
+		Do()
+
+	reviews, err := reviewObjects(r, func(info *resource.Info, podTemplateSpec *corev1.PodTemplateSpec) (runtime.Object, error) {
+		review := &securityv1.PodSecurityPolicyReview{}
+		if podTemplateSpec.Spec.ServiceAccountName == "frontend" {
+			review.Status.AllowedServiceAccounts = []securityv1.ServiceAccountPodSecurityPolicyReviewStatus{
+				{Name: "frontend", PodSecurityPolicySubjectReviewStatus: securityv1.PodSecurityPolicySubjectReviewStatus{AllowedBy: &corev1.ObjectReference{Name: "restricted-v2"}}},
+			}
+		}
+		return review, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := summaryJSONOutput
+	printFlags := genericclioptions.NewPrintFlags("")
+	printFlags.OutputFormat = &output
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	errs := printReviews(reviews, printFlags, nil, out, errOut)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `StatefulSet "database" with spec.volumeClaimTemplates`) {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if errOut.Len() > 0 {
+		t.Errorf("unexpected warnings: %s", errOut.String())
+	}
+
+	list := reviewedObjectList{}
+	if err := json.Unmarshal(out.Bytes(), &list); err != nil {
+		t.Fatalf("unable to parse the results: %v\n%s", err, out.String())
+	}
+	expected := []reviewedObject{
+		{Kind: "Deployment", Namespace: "test", Name: "frontend", Source: "manifests.yaml", Allowed: true, ServiceAccounts: []reviewedServiceAccount{{Name: "frontend", AllowedBy: "restricted-v2"}}},
+		{Kind: "ConfigMap", Namespace: "test", Name: "config", Source: "manifests.yaml", Skipped: "no pod template"},
+		{Kind: "StatefulSet", Namespace: "test", Name: "database", Source: "manifests.yaml", Error: `StatefulSet "database" with spec.volumeClaimTemplates currently not supported.`},
+		{Kind: "Pod", Namespace: "test", Name: "privileged", Source: "manifests.yaml"},
+	}
+	if !reflect.DeepEqual(list.Items, expected) {
+		t.Errorf("unexpected results:\n%s", out.String())
+	}
+
+	// -o json prints the review objects in a v1 List, and warns about the skipped objects
+	output = "json"
+	out.Reset()
+	errs = printReviews(reviews, printFlags, nil, out, errOut)
+	if len(errs) != 1 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if expected := "warning: skipping ConfigMap/config: no pod template\n"; errOut.String() != expected {
+		t.Errorf("expected the warning %q, got %q", expected, errOut.String())
+	}
+	reviewList := struct {
+		Kind  string `json:"kind"`
+		Items []securityv1.PodSecurityPolicyReview
+	}{}
+	if err := json.Unmarshal(out.Bytes(), &reviewList); err != nil {
+		t.Fatalf("unable to parse the results: %v\n%s", err, out.String())
+	}
+	if reviewList.Kind != "List" || len(reviewList.Items) != 2 {
+		t.Fatalf("expected a List of 2 reviews, got:\n%s", out.String())
+	}
+	for _, item := range reviewList.Items {
+		if item.Kind != "PodSecurityPolicyReview" || item.APIVersion != "security.openshift.io/v1" {
+			t.Errorf("unexpected kind %s of %s", item.Kind, item.APIVersion)
+		}
+	}
+	if allowed := reviewList.Items[0].Status.AllowedServiceAccounts; len(allowed) != 1 || allowed[0].Name != "frontend" {
+		t.Errorf("unexpected review of the frontend: %#v", allowed)
+	}
+}
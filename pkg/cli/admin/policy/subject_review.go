@@ -32,6 +32,11 @@ var (
 		It returns a list of security context constraints that will admit the resource.
 		If user is specified but not groups, it is interpreted as "what if the user is not a member of any groups".
 		If user and groups are empty, then the check is performed using the current user.
+
+		A directory of manifests (with -R to recurse into its subdirectories) or a stream of several
+		objects can be reviewed at once, in which case the objects without a pod template are skipped
+		with a warning. Use -o summary-json or -o summary-yaml to print a summary of the result of each
+		object, including the objects that could not be reviewed or were skipped.
 	`)
 	subjectReviewExamples = templates.Examples(`# Check whether user bob can create a pod specified in myresource.yaml
 		oc policy scc-subject-review -u bob -f myresource.yaml
@@ -41,6 +46,9 @@ var (
 
 		# Check whether a service account specified in the pod template spec in myresourcewithsa.yaml can create the pod
 		oc policy scc-subject-review -f myresourcewithsa.yaml
+
+		# Check whether user bob can create the workloads of all the manifests read from stdin, and print a summary in YAML
+		cat *.yaml | oc policy scc-subject-review -u bob -f - -o summary-yaml
 	`)
 )
 
@@ -96,6 +104,7 @@ func NewCmdSccSubjectReview(f kcmdutil.Factory, streams genericiooptions.IOStrea
 	kcmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, "Filename, directory, or URL to a file identifying the resource to get from a server.")
 
 	o.PrintFlags.AddFlags(cmd)
+	addSummaryOutputFlags(cmd)
 	return cmd
 }
 
@@ -162,39 +171,21 @@ func (o *SCCSubjectReviewOptions) Run(args []string) error {
 		return err
 	}
 
-	allErrs := []error{}
-	err = r.Visit(func(info *resource.Info, err error) error {
-		if err != nil {
-			return err
-		}
-		var response runtime.Object
-		objectName := info.Name
-		podTemplateSpec, err := GetPodTemplateForObject(info.Object)
-		if err != nil {
-			return fmt.Errorf(" %q cannot create pod: %v", objectName, err)
-		}
-		err = CheckStatefulSetWithWolumeClaimTemplates(info.Object)
-		if err != nil {
-			return err
-		}
+	reviews, err := reviewObjects(r, func(info *resource.Info, podTemplateSpec *corev1.PodTemplateSpec) (runtime.Object, error) {
 		if len(userOrSA) > 0 || len(o.Groups) > 0 {
 			versionedObj, err := o.pspSubjectReview(userOrSA, podTemplateSpec)
 			if err != nil {
-				return fmt.Errorf("unable to compute Pod Security Policy Subject Review for %q: %v", objectName, err)
+				return nil, fmt.Errorf("unable to compute Pod Security Policy Subject Review for %q: %v", info.Name, err)
 			}
-			response = versionedObj
-		} else {
-			versionedObj, err := o.pspSelfSubjectReview(podTemplateSpec)
-			if err != nil {
-				return fmt.Errorf("unable to compute Pod Security Policy Subject Review for %q: %v", objectName, err)
-			}
-			response = versionedObj
+			return versionedObj, nil
 		}
-		if err := o.Printer.WithInfo(info).PrintObj(response, o.Out); err != nil {
-			allErrs = append(allErrs, err)
+		versionedObj, err := o.pspSelfSubjectReview(podTemplateSpec)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compute Pod Security Policy Subject Review for %q: %v", info.Name, err)
 		}
-		return nil
+		return versionedObj, nil
 	})
+	allErrs := printReviews(reviews, o.PrintFlags, o.Printer, o.Out, o.ErrOut)
 	allErrs = append(allErrs, err)
 	return utilerrors.NewAggregate(allErrs)
 }
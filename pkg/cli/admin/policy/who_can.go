@@ -2,7 +2,6 @@ package policy
 
 import (
 	"bytes"
-	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -20,13 +19,37 @@ import (
 	"k8s.io/client-go/discovery"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/templates"
 
-	authorizationv1 "github.com/openshift/api/authorization/v1"
 	authorizationv1typedclient "github.com/openshift/client-go/authorization/clientset/versioned/typed/authorization/v1"
 )
 
 const WhoCanRecommendedName = "who-can"
 
+var (
+	whoCanLong = templates.LongDesc(`
+		List who can perform the specified action on a resource.
+
+		Pass --matrix with a file of verbs and resources to review many actions at once, such
+		as for a periodic access review. The file contains a list of entries, each of which
+		is expanded to every combination of its verbs, resources and optional resource names:
+
+		    - {verbs: [get, list, watch], resources: [secrets, configmaps]}
+		    - {verbs: [create], resources: [pods/exec, deployments.apps/scale], resourceNames: [frontend]}
+
+		The users and groups allowed to perform each action are printed as a table, or as a
+		list of results with --output of json or yaml.
+	`)
+
+	whoCanExample = templates.Examples(`
+		# List who can get secrets in the current namespace
+		oc adm policy who-can get secrets
+
+		# List who can perform the actions in the file in all namespaces, as JSON
+		oc adm policy who-can --matrix -f verbs-resources.yaml --all-namespaces -o json
+	`)
+)
+
 type WhoCanOptions struct {
 	PrintFlags *genericclioptions.PrintFlags
 
@@ -41,6 +64,11 @@ type WhoCanOptions struct {
 	subresource  string
 	resourceName string
 
+	useMatrix    bool
+	matrixFile   string
+	matrix       []matrixCheck
+	matrixOutput string
+
 	genericiooptions.IOStreams
 }
 
@@ -56,17 +84,25 @@ func NewWhoCanOptions(streams genericiooptions.IOStreams) *WhoCanOptions {
 func NewCmdWhoCan(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
 	o := NewWhoCanOptions(streams)
 	cmd := &cobra.Command{
-		Use:   "who-can VERB RESOURCE [NAME]",
-		Short: "List who can perform the specified action on a resource",
-		Long:  "List who can perform the specified action on a resource",
+		Use:     "who-can VERB RESOURCE [NAME] | --matrix -f FILENAME",
+		Short:   "List who can perform the specified action on a resource",
+		Long:    whoCanLong,
+		Example: whoCanExample,
 		Run: func(cmd *cobra.Command, args []string) {
 			kcmdutil.CheckErr(o.complete(f, cmd, args))
+			if o.useMatrix {
+				kcmdutil.CheckErr(o.runMatrix())
+				return
+			}
 			kcmdutil.CheckErr(o.run())
 		},
 	}
 
 	cmd.Flags().BoolVarP(&o.allNamespaces, "all-namespaces", "A", o.allNamespaces, "If true, list who can perform the specified action in all namespaces.")
 	cmd.Flags().StringVar(&o.subresource, "subresource", o.subresource, "SubResource such as log or scale")
+	cmd.Flags().BoolVar(&o.useMatrix, "matrix", o.useMatrix, "If true, list who can perform each of the actions in the file passed with -f.")
+	cmd.Flags().StringVarP(&o.matrixFile, "filename", "f", o.matrixFile, "File with the verbs and resources to review with --matrix.")
+	cmd.MarkFlagFilename("filename", "yaml", "yml", "json")
 
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
@@ -82,11 +118,33 @@ func (o *WhoCanOptions) complete(f kcmdutil.Factory, cmd *cobra.Command, args []
 		return err
 	}
 
-	switch len(args) {
-	case 3:
+	switch {
+	case o.useMatrix:
+		if len(args) > 0 {
+			return errors.New("no arguments may be specified with --matrix")
+		}
+		if len(o.matrixFile) == 0 {
+			return errors.New("--matrix requires a file of verbs and resources passed with -f")
+		}
+		if len(o.subresource) > 0 {
+			return errors.New("--subresource may not be used with --matrix, specify resources such as pods/log in the file instead")
+		}
+		o.matrixOutput = *o.PrintFlags.OutputFormat
+		switch o.matrixOutput {
+		case "", "json", "yaml":
+		default:
+			return fmt.Errorf("--output may only be json or yaml with --matrix")
+		}
+		o.matrix, err = readMatrix(o.matrixFile, mapper, discoveryClient, o.ErrOut)
+		if err != nil {
+			return err
+		}
+	case len(o.matrixFile) > 0:
+		return errors.New("-f may only be used with --matrix")
+	case len(args) == 3:
 		o.resourceName = args[2]
 		fallthrough
-	case 2:
+	case len(args) == 2:
 		o.verb = args[0]
 		o.resource = ResourceFor(mapper, discoveryClient, args[1], o.subresource, o.ErrOut)
 	default:
@@ -176,24 +234,12 @@ func hasSubresource(discoveryClient discovery.DiscoveryInterface, gvr schema.Gro
 }
 
 func (o *WhoCanOptions) run() error {
-	authorizationAttributes := authorizationv1.Action{
-		Verb:         o.verb,
-		Group:        o.resource.Group,
-		Resource:     o.resource.Resource,
-		ResourceName: o.resourceName,
-	}
-	if len(o.subresource) > 0 {
-		authorizationAttributes.Resource += "/" + o.subresource
-	}
-
-	resourceAccessReviewResponse := &authorizationv1.ResourceAccessReviewResponse{}
-	var err error
-	if o.allNamespaces {
-		resourceAccessReviewResponse, err = o.client.ResourceAccessReviews().Create(context.TODO(), &authorizationv1.ResourceAccessReview{Action: authorizationAttributes}, metav1.CreateOptions{})
-	} else {
-		resourceAccessReviewResponse, err = o.client.LocalResourceAccessReviews(o.bindingNamespace).Create(context.TODO(), &authorizationv1.LocalResourceAccessReview{Action: authorizationAttributes}, metav1.CreateOptions{})
-	}
-
+	resourceAccessReviewResponse, err := o.review(matrixCheck{
+		verb:         o.verb,
+		resource:     o.resource,
+		subresource:  o.subresource,
+		resourceName: o.resourceName,
+	})
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,191 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/yaml"
+
+	authorizationv1 "github.com/openshift/api/authorization/v1"
+)
+
+// matrixEntry is an entry of the --matrix file. Each entry is expanded to every
+// combination of its verbs and resources.
+type matrixEntry struct {
+	Verbs         []string `json:"verbs"`
+	Resources     []string `json:"resources"`
+	ResourceNames []string `json:"resourceNames,omitempty"`
+}
+
+// matrixCheck is a single verb and resource pair to review.
+type matrixCheck struct {
+	verb         string
+	resource     schema.GroupVersionResource
+	subresource  string
+	resourceName string
+}
+
+func (c matrixCheck) resourceDisplay() string {
+	display := c.resource.Resource
+	if len(c.resource.Group) > 0 {
+		display += "." + c.resource.Group
+	}
+	if len(c.subresource) > 0 {
+		display += "/" + c.subresource
+	}
+	if len(c.resourceName) > 0 {
+		display += "/" + c.resourceName
+	}
+	return display
+}
+
+// matrixResult is printed for each check with --output=json or yaml.
+type matrixResult struct {
+	Namespace       string   `json:"namespace"`
+	Verb            string   `json:"verb"`
+	Resource        string   `json:"resource"`
+	Group           string   `json:"group,omitempty"`
+	Subresource     string   `json:"subresource,omitempty"`
+	ResourceName    string   `json:"resourceName,omitempty"`
+	Users           []string `json:"users"`
+	Groups          []string `json:"groups"`
+	EvaluationError string   `json:"evaluationError,omitempty"`
+}
+
+// readMatrix reads the verb and resource pairs of a --matrix file, which holds a
+// list of entries such as:
+//
+//   - verbs: [get, list]
+//     resources: [secrets, pods/log, deployments.apps]
+func readMatrix(filename string, mapper meta.RESTMapper, discoveryClient discovery.DiscoveryInterface, errOut io.Writer) ([]matrixCheck, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var entries []matrixEntry
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", filename, err)
+	}
+
+	var checks []matrixCheck
+	for i, entry := range entries {
+		if len(entry.Verbs) == 0 || len(entry.Resources) == 0 {
+			return nil, fmt.Errorf("entry %d of %s must have at least one verb and one resource", i+1, filename)
+		}
+		resourceNames := entry.ResourceNames
+		if len(resourceNames) == 0 {
+			resourceNames = []string{""}
+		}
+		for _, resourceArg := range entry.Resources {
+			resourceArg, subresource, _ := strings.Cut(resourceArg, "/")
+			resource := ResourceFor(mapper, discoveryClient, resourceArg, subresource, errOut)
+			for _, verb := range entry.Verbs {
+				for _, resourceName := range resourceNames {
+					checks = append(checks, matrixCheck{
+						verb:         verb,
+						resource:     resource,
+						subresource:  subresource,
+						resourceName: resourceName,
+					})
+				}
+			}
+		}
+	}
+	if len(checks) == 0 {
+		return nil, fmt.Errorf("%s must contain at least one entry", filename)
+	}
+	return checks, nil
+}
+
+// runMatrix reviews every check of the matrix and prints who can perform each of them.
+func (o *WhoCanOptions) runMatrix() error {
+	var results []matrixResult
+	for _, check := range o.matrix {
+		response, err := o.review(check)
+		if err != nil {
+			return fmt.Errorf("unable to review %s %s: %v", check.verb, check.resourceDisplay(), err)
+		}
+		namespace := response.Namespace
+		if namespace == metav1.NamespaceAll {
+			namespace = "<all>"
+		}
+		results = append(results, matrixResult{
+			Namespace:       namespace,
+			Verb:            check.verb,
+			Resource:        check.resource.Resource,
+			Group:           check.resource.Group,
+			Subresource:     check.subresource,
+			ResourceName:    check.resourceName,
+			Users:           sets.NewString(response.UsersSlice...).List(),
+			Groups:          sets.NewString(response.GroupsSlice...).List(),
+			EvaluationError: response.EvaluationError,
+		})
+	}
+
+	switch o.matrixOutput {
+	case "json":
+		data, err := json.MarshalIndent(results, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(o.Out, "%s\n", data)
+		return err
+	case "yaml":
+		data, err := yaml.Marshal(results)
+		if err != nil {
+			return err
+		}
+		_, err = o.Out.Write(data)
+		return err
+	}
+
+	w := tabwriter.NewWriter(o.Out, tabWriterMinWidth, tabWriterWidth, tabWriterPadding, tabWriterPadChar, tabWriterFlags)
+	defer w.Flush()
+	fmt.Fprintf(w, "NAMESPACE\tVERB\tRESOURCE\tUSERS\tGROUPS\t\n")
+	for i, result := range results {
+		users, groups := "<none>", "<none>"
+		if len(result.Users) > 0 {
+			users = strings.Join(result.Users, ",")
+		}
+		if len(result.Groups) > 0 {
+			groups = strings.Join(result.Groups, ",")
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t\n", result.Namespace, result.Verb, o.matrix[i].resourceDisplay(), users, groups); err != nil {
+			return err
+		}
+	}
+	for i, result := range results {
+		if len(result.EvaluationError) > 0 {
+			fmt.Fprintf(o.ErrOut, "warning: error during evaluation of %s %s, results may not be complete: %s\n", result.Verb, o.matrix[i].resourceDisplay(), result.EvaluationError)
+		}
+	}
+	return nil
+}
+
+// review returns the subjects that may perform the check in the binding namespace,
+// or in all namespaces.
+func (o *WhoCanOptions) review(check matrixCheck) (*authorizationv1.ResourceAccessReviewResponse, error) {
+	action := authorizationv1.Action{
+		Verb:         check.verb,
+		Group:        check.resource.Group,
+		Resource:     check.resource.Resource,
+		ResourceName: check.resourceName,
+	}
+	if len(check.subresource) > 0 {
+		action.Resource += "/" + check.subresource
+	}
+	if o.allNamespaces {
+		return o.client.ResourceAccessReviews().Create(context.TODO(), &authorizationv1.ResourceAccessReview{Action: action}, metav1.CreateOptions{})
+	}
+	return o.client.LocalResourceAccessReviews(o.bindingNamespace).Create(context.TODO(), &authorizationv1.LocalResourceAccessReview{Action: action}, metav1.CreateOptions{})
+}
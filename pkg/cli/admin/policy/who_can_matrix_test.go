@@ -0,0 +1,100 @@
+package policy
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	authorizationv1 "github.com/openshift/api/authorization/v1"
+	fakeauthorizationclient "github.com/openshift/client-go/authorization/clientset/versioned/fake"
+)
+
+func TestWhoCanMatrix(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "matrix.yaml")
+	if err := os.WriteFile(filename, []byte(`
+- verbs: [get, list]
+  resources: [secrets]
+- verbs: [get]
+  resources: [pods/log, deployments.apps]
+  resourceNames: [frontend]
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Secret"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	discoveryClient := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{
+		Resources: []*metav1.APIResourceList{{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "pods/log"}}}},
+	}}
+
+	streams, _, out, errOut := genericiooptions.NewTestIOStreams()
+	checks, err := readMatrix(filename, mapper, discoveryClient, errOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var displayed []string
+	for _, check := range checks {
+		displayed = append(displayed, check.verb+" "+check.resourceDisplay())
+	}
+	if expected := []string{"get secrets", "list secrets", "get pods/log/frontend", "get deployments.apps/frontend"}; !reflect.DeepEqual(expected, displayed) {
+		t.Fatalf("unexpected checks: %v", displayed)
+	}
+
+	fakeClient := fakeauthorizationclient.NewSimpleClientset()
+	fakeClient.PrependReactor("create", "localresourceaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.LocalResourceAccessReview)
+		response := &authorizationv1.ResourceAccessReviewResponse{Namespace: "test", GroupsSlice: []string{"system:masters"}}
+		if review.Action.Verb == "get" && review.Action.Resource == "secrets" {
+			response.UsersSlice = []string{"bob", "alice"}
+		}
+		if review.Action.Resource == "pods/log" && review.Action.ResourceName == "frontend" {
+			response.EvaluationError = "partial"
+		}
+		return true, response, nil
+	})
+
+	o := &WhoCanOptions{
+		bindingNamespace: "test",
+		client:           fakeClient.AuthorizationV1(),
+		matrix:           checks,
+		IOStreams:        streams,
+	}
+	if err := o.runMatrix(); err != nil {
+		t.Fatal(err)
+	}
+	expected := `NAMESPACE   VERB   RESOURCE                    USERS       GROUPS           
+test        get    secrets                     alice,bob   system:masters   
+test        list   secrets                     <none>      system:masters   
+test        get    pods/log/frontend           <none>      system:masters   
+test        get    deployments.apps/frontend   <none>      system:masters   
+`
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+	if !bytes.Contains(errOut.Bytes(), []byte("error during evaluation of get pods/log/frontend")) {
+		t.Errorf("expected an evaluation warning, got %q", errOut.String())
+	}
+
+	out.Reset()
+	o.matrixOutput = "json"
+	if err := o.runMatrix(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(out.Bytes(), []byte(`"resource": "deployments",
+        "group": "apps",`)) {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
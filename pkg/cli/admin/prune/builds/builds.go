@@ -11,6 +11,7 @@ import (
 	"github.com/spf13/cobra"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/templates"
@@ -25,6 +26,13 @@ var (
 
 		By default, the prune operation performs a dry run making no changes to internal registry. A
 		--confirm flag is needed for changes to be effective.
+
+		Builds younger than --keep-younger-than are never pruned. By default, they are preserved in
+		addition to the --keep-complete and --keep-failed most recent older builds of each build config.
+		With --keep-policy=combined, they count towards --keep-complete and --keep-failed instead, so
+		that only the most recent builds and any builds younger than --keep-younger-than are preserved.
+
+		Use --selector to only prune the builds matching a label query.
 	`)
 
 	buildsExample = templates.Examples(`
@@ -34,6 +42,10 @@ var (
 
 		# To actually perform the prune operation, the confirm flag must be appended
 		oc adm prune builds --orphans --confirm
+
+		# Dry run deleting the builds labeled app=frontend, except for the last 3 complete builds
+		# of each build config and any build from the last week
+		oc adm prune builds -l app=frontend --keep-complete=3 --keep-younger-than=168h --keep-policy=combined
 	`)
 )
 
@@ -44,6 +56,8 @@ type PruneBuildsOptions struct {
 	KeepYoungerThan time.Duration
 	KeepComplete    int
 	KeepFailed      int
+	KeepPolicy      string
+	Selector        string
 	Namespace       string
 
 	BuildClient buildv1client.BuildV1Interface
@@ -58,6 +72,7 @@ func NewPruneBuildsOptions(streams genericiooptions.IOStreams) *PruneBuildsOptio
 		KeepYoungerThan: 60 * time.Minute,
 		KeepComplete:    5,
 		KeepFailed:      1,
+		KeepPolicy:      string(KeepPolicyAdditive),
 		IOStreams:       streams,
 	}
 }
@@ -82,6 +97,8 @@ func NewCmdPruneBuilds(f kcmdutil.Factory, streams genericiooptions.IOStreams) *
 	cmd.Flags().DurationVar(&o.KeepYoungerThan, "keep-younger-than", o.KeepYoungerThan, "Specify the minimum age of a Build for it to be considered a candidate for pruning.")
 	cmd.Flags().IntVar(&o.KeepComplete, "keep-complete", o.KeepComplete, "Per BuildConfig, specify the number of builds whose status is complete that will be preserved.")
 	cmd.Flags().IntVar(&o.KeepFailed, "keep-failed", o.KeepFailed, "Per BuildConfig, specify the number of builds whose status is failed, error, or cancelled that will be preserved.")
+	cmd.Flags().StringVar(&o.KeepPolicy, "keep-policy", o.KeepPolicy, "How --keep-younger-than combines with --keep-complete and --keep-failed. One of: additive, to preserve the recent builds in addition to the given number of older builds, or combined, to preserve the given number of most recent builds and any other build younger than --keep-younger-than.")
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) to filter the builds to prune on.")
 
 	return cmd
 }
@@ -125,6 +142,14 @@ func (o PruneBuildsOptions) Validate() error {
 	if o.KeepFailed < 0 {
 		return fmt.Errorf("--keep-failed must be greater than or equal to 0")
 	}
+	switch KeepPolicy(o.KeepPolicy) {
+	case KeepPolicyAdditive, KeepPolicyCombined:
+	default:
+		return fmt.Errorf("--keep-policy must be one of: %s, %s", KeepPolicyAdditive, KeepPolicyCombined)
+	}
+	if _, err := labels.Parse(o.Selector); err != nil {
+		return fmt.Errorf("invalid --selector: %v", err)
+	}
 	return nil
 }
 
@@ -139,7 +164,7 @@ func (o PruneBuildsOptions) Run() error {
 		buildConfigs = append(buildConfigs, &buildConfigList.Items[i])
 	}
 
-	buildList, err := o.BuildClient.Builds(o.Namespace).List(context.TODO(), metav1.ListOptions{})
+	buildList, err := o.BuildClient.Builds(o.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: o.Selector})
 	if err != nil {
 		return err
 	}
@@ -153,6 +178,7 @@ func (o PruneBuildsOptions) Run() error {
 		Orphans:         o.Orphans,
 		KeepComplete:    o.KeepComplete,
 		KeepFailed:      o.KeepFailed,
+		KeepPolicy:      KeepPolicy(o.KeepPolicy),
 		BuildConfigs:    buildConfigs,
 		Builds:          builds,
 	}
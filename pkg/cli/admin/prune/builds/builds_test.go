@@ -31,3 +31,29 @@ func TestBuildPruneNamespaced(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildPruneSelector(t *testing.T) {
+	osFake := &fakebuildv1client.FakeBuildV1{Fake: &clienttesting.Fake{}}
+	opts := NewPruneBuildsOptions(genericiooptions.NewTestIOStreamsDiscard())
+	opts.Selector = "app=frontend"
+	opts.BuildClient = osFake
+
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := opts.Run(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, a := range osFake.Actions() {
+		list, ok := a.(clienttesting.ListAction)
+		if !ok || a.GetResource().Resource != "builds" {
+			continue
+		}
+		if selector := list.GetListRestrictions().Labels.String(); selector != "app=frontend" {
+			t.Errorf("Unexpected selector while listing builds: %q", selector)
+		}
+		return
+	}
+	t.Errorf("Missing list builds action")
+}
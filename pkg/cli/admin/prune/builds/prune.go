@@ -21,6 +21,18 @@ type BuildDeleter interface {
 	DeleteBuild(build *buildv1.Build) error
 }
 
+// KeepPolicy controls how KeepYoungerThan combines with KeepComplete and KeepFailed.
+type KeepPolicy string
+
+const (
+	// KeepPolicyAdditive preserves the builds younger than KeepYoungerThan in
+	// addition to the most recent older builds given by KeepComplete and KeepFailed.
+	KeepPolicyAdditive KeepPolicy = "additive"
+	// KeepPolicyCombined preserves the most recent builds given by KeepComplete
+	// and KeepFailed, and any other build younger than KeepYoungerThan.
+	KeepPolicyCombined KeepPolicy = "combined"
+)
+
 // pruner is an object that knows how to prune a data set
 type pruner struct {
 	resolver Resolver
@@ -39,6 +51,9 @@ type PrunerOptions struct {
 	KeepComplete int
 	// KeepFailed is per BuildConfig how many of the most recent failed builds should be preserved
 	KeepFailed int
+	// KeepPolicy controls whether builds younger than KeepYoungerThan count towards
+	// KeepComplete and KeepFailed. Defaults to KeepPolicyAdditive.
+	KeepPolicy KeepPolicy
 	// BuildConfigs is the entire list of buildconfigs across all namespaces in the cluster.
 	BuildConfigs []*buildv1.BuildConfig
 	// Builds is the entire list of builds across all namespaces in the cluster.
@@ -47,13 +62,16 @@ type PrunerOptions struct {
 
 // NewPruner returns a Pruner over specified data using specified options.
 func NewPruner(options PrunerOptions) Pruner {
-	klog.V(1).Infof("Creating build pruner with keepYoungerThan=%v, orphans=%v, keepComplete=%v, keepFailed=%v",
-		options.KeepYoungerThan, options.Orphans, options.KeepComplete, options.KeepFailed)
+	klog.V(1).Infof("Creating build pruner with keepYoungerThan=%v, orphans=%v, keepComplete=%v, keepFailed=%v, keepPolicy=%v",
+		options.KeepYoungerThan, options.Orphans, options.KeepComplete, options.KeepFailed, options.KeepPolicy)
 
 	filter := &andFilter{
 		filterPredicates: []FilterPredicate{NewFilterBeforePredicate(options.KeepYoungerThan)},
 	}
-	builds := filter.Filter(options.Builds)
+	builds := options.Builds
+	if options.KeepPolicy != KeepPolicyCombined {
+		builds = filter.Filter(builds)
+	}
 	dataSet := NewDataSet(options.BuildConfigs, builds)
 
 	resolvers := []Resolver{}
@@ -68,8 +86,15 @@ func NewPruner(options PrunerOptions) Pruner {
 	}
 	resolvers = append(resolvers, NewPerBuildConfigResolver(dataSet, options.KeepComplete, options.KeepFailed))
 
+	var resolver Resolver = &mergeResolver{resolvers: resolvers}
+	if options.KeepPolicy == KeepPolicyCombined {
+		// the most recent builds were counted regardless of their age, so only
+		// the old enough ones of the rest can be pruned
+		resolver = &filterResolver{resolver: resolver, filter: filter}
+	}
+
 	return &pruner{
-		resolver: &mergeResolver{resolvers: resolvers},
+		resolver: resolver,
 	}
 }
 
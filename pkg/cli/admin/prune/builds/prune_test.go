@@ -112,3 +112,39 @@ func TestPruneTask(t *testing.T) {
 	}
 
 }
+
+func TestPruneKeepPolicy(t *testing.T) {
+	now := metav1.Now()
+	buildConfig := mockBuildConfig("a", "build-config")
+	builds := []*buildv1.Build{
+		withCreated(withStatus(mockBuild("a", "build-1", buildConfig), buildv1.BuildPhaseComplete), metav1.NewTime(now.Add(-10*time.Minute))),
+		withCreated(withStatus(mockBuild("a", "build-2", buildConfig), buildv1.BuildPhaseComplete), metav1.NewTime(now.Add(-30*time.Minute))),
+		withCreated(withStatus(mockBuild("a", "build-3", buildConfig), buildv1.BuildPhaseComplete), metav1.NewTime(now.Add(-2*time.Hour))),
+		withCreated(withStatus(mockBuild("a", "build-4", buildConfig), buildv1.BuildPhaseComplete), metav1.NewTime(now.Add(-3*time.Hour))),
+		withCreated(withStatus(mockBuild("a", "build-5", buildConfig), buildv1.BuildPhaseComplete), metav1.NewTime(now.Add(-4*time.Hour))),
+	}
+
+	tests := []struct {
+		policy   KeepPolicy
+		expected sets.String
+	}{
+		{policy: KeepPolicyAdditive, expected: sets.NewString("build-5")},
+		{policy: KeepPolicyCombined, expected: sets.NewString("build-3", "build-4", "build-5")},
+	}
+	for _, test := range tests {
+		t.Run(string(test.policy), func(t *testing.T) {
+			recorder := &mockDeleteRecorder{set: sets.String{}}
+			pruner := NewPruner(PrunerOptions{
+				KeepYoungerThan: time.Hour,
+				KeepComplete:    2,
+				KeepPolicy:      test.policy,
+				BuildConfigs:    []*buildv1.BuildConfig{buildConfig},
+				Builds:          builds,
+			})
+			if err := pruner.Prune(recorder); err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+			recorder.Verify(t, test.expected)
+		})
+	}
+}
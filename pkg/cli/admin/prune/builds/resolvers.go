@@ -31,6 +31,20 @@ func (m *mergeResolver) Resolve() ([]*buildv1.Build, error) {
 	return results, nil
 }
 
+// filterResolver only returns the results of another resolver matching the filter
+type filterResolver struct {
+	resolver Resolver
+	filter   Filter
+}
+
+func (f *filterResolver) Resolve() ([]*buildv1.Build, error) {
+	builds, err := f.resolver.Resolve()
+	if err != nil {
+		return nil, err
+	}
+	return f.filter.Filter(builds), nil
+}
+
 // NewOrphanBuildResolver returns a Resolver that matches Build objects with no associated BuildConfig and has a BuildPhase in filter
 func NewOrphanBuildResolver(dataSet DataSet, BuildPhaseFilter []buildv1.BuildPhase) Resolver {
 	filter := sets.NewString()
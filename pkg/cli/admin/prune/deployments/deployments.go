@@ -11,6 +11,7 @@ import (
 	"github.com/spf13/cobra"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	kappsv1client "k8s.io/client-go/kubernetes/typed/apps/v1"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -26,6 +27,15 @@ var (
 
 		By default, the prune operation performs a dry run making no changes to the deployment configs.
 		A --confirm flag is needed for changes to be effective.
+
+		Deployments younger than --keep-younger-than are never pruned. By default, they are preserved
+		in addition to the --keep-complete and --keep-failed most recent older deployments of each
+		deployment config. With --keep-policy=combined, they count towards --keep-complete and
+		--keep-failed instead, so that only the most recent deployments and any deployments younger
+		than --keep-younger-than are preserved.
+
+		Use --selector to only prune the replication controllers, and replica sets with
+		--replica-sets, matching a label query.
 	`)
 
 	deploymentsExample = templates.Examples(`
@@ -34,6 +44,10 @@ var (
 
 		 # To actually perform the prune operation, the confirm flag must be appended
 		oc adm prune deployments --keep-complete=1 --confirm
+
+		# Dry run deleting the deployments labeled app=frontend, except for the last 3 complete
+		# deployments of each deployment config and any deployment from the last day
+		oc adm prune deployments -l app=frontend --keep-complete=3 --keep-younger-than=24h --keep-policy=combined
 	`)
 )
 
@@ -45,6 +59,8 @@ type PruneDeploymentsOptions struct {
 	KeepYoungerThan time.Duration
 	KeepComplete    int
 	KeepFailed      int
+	KeepPolicy      string
+	Selector        string
 	Namespace       string
 
 	AppsClient  appsv1client.DeploymentConfigsGetter
@@ -60,6 +76,7 @@ func NewPruneDeploymentsOptions(streams genericiooptions.IOStreams) *PruneDeploy
 		KeepYoungerThan: 60 * time.Minute,
 		KeepComplete:    5,
 		KeepFailed:      1,
+		KeepPolicy:      string(KeepPolicyAdditive),
 		IOStreams:       streams,
 	}
 }
@@ -85,6 +102,8 @@ func NewCmdPruneDeployments(f kcmdutil.Factory, streams genericiooptions.IOStrea
 	cmd.Flags().DurationVar(&o.KeepYoungerThan, "keep-younger-than", o.KeepYoungerThan, "Specify the minimum age of a deployment for it to be considered a candidate for pruning.")
 	cmd.Flags().IntVar(&o.KeepComplete, "keep-complete", o.KeepComplete, "Per DeploymentConfig, specify the number of deployments whose status is complete that will be preserved whose replica size is 0.")
 	cmd.Flags().IntVar(&o.KeepFailed, "keep-failed", o.KeepFailed, "Per DeploymentConfig, specify the number of deployments whose status is failed that will be preserved whose replica size is 0.")
+	cmd.Flags().StringVar(&o.KeepPolicy, "keep-policy", o.KeepPolicy, "How --keep-younger-than combines with --keep-complete and --keep-failed. One of: additive, to preserve the recent deployments in addition to the given number of older deployments, or combined, to preserve the given number of most recent deployments and any other deployment younger than --keep-younger-than.")
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) to filter the deployments to prune on.")
 
 	return cmd
 }
@@ -136,6 +155,14 @@ func (o PruneDeploymentsOptions) Validate() error {
 	if o.KeepFailed < 0 {
 		return fmt.Errorf("--keep-failed must be greater than or equal to 0")
 	}
+	switch KeepPolicy(o.KeepPolicy) {
+	case KeepPolicyAdditive, KeepPolicyCombined:
+	default:
+		return fmt.Errorf("--keep-policy must be one of: %s, %s", KeepPolicyAdditive, KeepPolicyCombined)
+	}
+	if _, err := labels.Parse(o.Selector); err != nil {
+		return fmt.Errorf("invalid --selector: %v", err)
+	}
 	return nil
 }
 
@@ -161,7 +188,7 @@ func (o PruneDeploymentsOptions) Run() error {
 		}
 	}
 
-	replicationControllerList, err := o.KubeClient.ReplicationControllers(o.Namespace).List(context.TODO(), metav1.ListOptions{})
+	replicationControllerList, err := o.KubeClient.ReplicationControllers(o.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: o.Selector})
 	if err != nil {
 		return err
 	}
@@ -171,7 +198,7 @@ func (o PruneDeploymentsOptions) Run() error {
 	}
 
 	if o.ReplicaSets {
-		replicaSetList, err := o.KAppsClient.ReplicaSets(o.Namespace).List(context.TODO(), metav1.ListOptions{})
+		replicaSetList, err := o.KAppsClient.ReplicaSets(o.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: o.Selector})
 		if err != nil {
 			return err
 		}
@@ -187,6 +214,7 @@ func (o PruneDeploymentsOptions) Run() error {
 		ReplicaSets:     o.ReplicaSets,
 		KeepComplete:    o.KeepComplete,
 		KeepFailed:      o.KeepFailed,
+		KeepPolicy:      KeepPolicy(o.KeepPolicy),
 		Deployments:     deployments,
 		Replicas:        replicas,
 	}
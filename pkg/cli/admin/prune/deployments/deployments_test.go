@@ -39,3 +39,31 @@ func TestDeploymentPruneNamespaced(t *testing.T) {
 		}
 	}
 }
+
+func TestDeploymentPruneSelector(t *testing.T) {
+	osFake := &fakeappsv1client.FakeAppsV1{Fake: &clienttesting.Fake{}}
+	coreFake := &fakecorev1client.FakeCoreV1{Fake: &clienttesting.Fake{}}
+	opts := NewPruneDeploymentsOptions(genericiooptions.NewTestIOStreamsDiscard())
+	opts.Selector = "app=frontend"
+	opts.AppsClient = osFake
+	opts.KubeClient = coreFake
+
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := opts.Run(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, a := range coreFake.Actions() {
+		list, ok := a.(clienttesting.ListAction)
+		if !ok || a.GetResource().Resource != "replicationcontrollers" {
+			continue
+		}
+		if selector := list.GetListRestrictions().Labels.String(); selector != "app=frontend" {
+			t.Errorf("Unexpected selector while listing replication controllers: %q", selector)
+		}
+		return
+	}
+	t.Errorf("Missing list replication controllers action")
+}
@@ -27,6 +27,18 @@ type ReplicaDeleter interface {
 	DeleteReplica(replica metav1.Object) error
 }
 
+// KeepPolicy controls how KeepYoungerThan combines with KeepComplete and KeepFailed.
+type KeepPolicy string
+
+const (
+	// KeepPolicyAdditive preserves the deployments younger than KeepYoungerThan in
+	// addition to the most recent older deployments given by KeepComplete and KeepFailed.
+	KeepPolicyAdditive KeepPolicy = "additive"
+	// KeepPolicyCombined preserves the most recent deployments given by KeepComplete
+	// and KeepFailed, and any other deployment younger than KeepYoungerThan.
+	KeepPolicyCombined KeepPolicy = "combined"
+)
+
 // pruner is an object that knows how to prune a data set
 type pruner struct {
 	resolver Resolver
@@ -46,6 +58,9 @@ type PrunerOptions struct {
 	KeepComplete int
 	// KeepFailed is per DeploymentConfig how many of the most recent failed deployments should be preserved.
 	KeepFailed int
+	// KeepPolicy controls whether deployments younger than KeepYoungerThan count towards
+	// KeepComplete and KeepFailed. Defaults to KeepPolicyAdditive.
+	KeepPolicy KeepPolicy
 	// Deployments is the entire list of deployments and deploymentconfigs across all namespaces in the cluster.
 	Deployments []metav1.Object
 	// Replicas is the entire list of replication controllers and replicasets across all namespaces in the cluster.
@@ -55,14 +70,17 @@ type PrunerOptions struct {
 // NewPruner returns a Pruner over specified data using specified options.
 // deploymentConfigs, deployments, opts.KeepYoungerThan, opts.Orphans, opts.KeepComplete, opts.KeepFailed, deploymentPruneFunc
 func NewPruner(options PrunerOptions) Pruner {
-	klog.V(1).Infof("Creating deployment pruner with keepYoungerThan=%v, orphans=%v, replicaSets=%v, keepComplete=%v, keepFailed=%v",
-		options.KeepYoungerThan, options.Orphans, options.ReplicaSets, options.KeepComplete, options.KeepFailed)
+	klog.V(1).Infof("Creating deployment pruner with keepYoungerThan=%v, orphans=%v, replicaSets=%v, keepComplete=%v, keepFailed=%v, keepPolicy=%v",
+		options.KeepYoungerThan, options.Orphans, options.ReplicaSets, options.KeepComplete, options.KeepFailed, options.KeepPolicy)
 
+	ageFilter := &andFilter{
+		filterPredicates: []FilterPredicate{NewFilterBeforePredicate(options.KeepYoungerThan)},
+	}
 	filter := &andFilter{
-		filterPredicates: []FilterPredicate{
-			FilterZeroReplicaSize,
-			NewFilterBeforePredicate(options.KeepYoungerThan),
-		},
+		filterPredicates: []FilterPredicate{FilterZeroReplicaSize},
+	}
+	if options.KeepPolicy != KeepPolicyCombined {
+		filter.filterPredicates = append(filter.filterPredicates, ageFilter.filterPredicates...)
 	}
 
 	if !options.Orphans {
@@ -86,8 +104,15 @@ func NewPruner(options PrunerOptions) Pruner {
 	}
 	resolvers = append(resolvers, NewPerDeploymentResolver(dataSet, options.KeepComplete, options.KeepFailed))
 
+	var resolver Resolver = &mergeResolver{resolvers: resolvers}
+	if options.KeepPolicy == KeepPolicyCombined {
+		// the most recent deployments were counted regardless of their age, so
+		// only the old enough ones of the rest can be pruned
+		resolver = &filterResolver{resolver: resolver, filter: ageFilter}
+	}
+
 	return &pruner{
-		resolver: &mergeResolver{resolvers: resolvers},
+		resolver: resolver,
 	}
 }
 
@@ -65,6 +65,7 @@ func TestPruneTask(t *testing.T) {
 		KeepToungerThan    time.Duration
 		KeepComplete       int
 		KeepFailed         int
+		KeepPolicy         KeepPolicy
 		ExpectedPruneNames []string
 	}{
 		"prune nothing": {
@@ -158,6 +159,32 @@ func TestPruneTask(t *testing.T) {
 				"rs1",
 			},
 		},
+		"prune keep 1 old non-orphaned, completed replicas in addition to younger replicas": {
+			Orphans:         false,
+			ReplicaSets:     true,
+			KeepToungerThan: time.Hour,
+			KeepComplete:    1,
+			KeepFailed:      0,
+			KeepPolicy:      KeepPolicyAdditive,
+			ExpectedPruneNames: []string{
+				"build-3",
+				"rs1",
+			},
+		},
+		"prune keep 1 non-orphaned, completed replicas and any younger replicas": {
+			Orphans:         false,
+			ReplicaSets:     true,
+			KeepToungerThan: time.Hour,
+			KeepComplete:    1,
+			KeepFailed:      0,
+			KeepPolicy:      KeepPolicyCombined,
+			ExpectedPruneNames: []string{
+				"build-2",
+				"build-3",
+				"rs1",
+				"rs3",
+			},
+		},
 		"prune everything not running": {
 			Orphans:         true,
 			ReplicaSets:     true,
@@ -187,6 +214,7 @@ func TestPruneTask(t *testing.T) {
 			ReplicaSets:     test.ReplicaSets,
 			KeepComplete:    test.KeepComplete,
 			KeepFailed:      test.KeepFailed,
+			KeepPolicy:      test.KeepPolicy,
 			Deployments:     deployments,
 			Replicas:        replicas,
 		}
@@ -34,6 +34,20 @@ func (m *mergeResolver) Resolve() ([]metav1.Object, error) {
 	return results, nil
 }
 
+// filterResolver only returns the results of another resolver matching the filter
+type filterResolver struct {
+	resolver Resolver
+	filter   Filter
+}
+
+func (f *filterResolver) Resolve() ([]metav1.Object, error) {
+	items, err := f.resolver.Resolve()
+	if err != nil {
+		return nil, err
+	}
+	return f.filter.Filter(items), nil
+}
+
 // NewOrphanReplicaResolver returns a Resolver that matches objects with no associated Deployment or DeploymentConfig and has a DeploymentStatus in filter
 func NewOrphanReplicaResolver(dataSet DataSet, replicaStatusFilter []appsv1.DeploymentStatus) Resolver {
 	filter := sets.NewString()
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -38,6 +40,21 @@ import (
 
 const defaultPruneImageWorkerCount = 5
 
+const (
+	// KeepTagRevisionsAnnotation on an image stream overrides the number of
+	// tag revisions preserved for the tags of that image stream.
+	KeepTagRevisionsAnnotation = "prune.openshift.io/keep-tag-revisions"
+	// KeepYoungerThanAnnotation on an image stream overrides the minimum age,
+	// as a duration such as 168h, of the image stream and its tag revisions
+	// for them to be candidates for pruning. It does not protect the images
+	// themselves.
+	KeepYoungerThanAnnotation = "prune.openshift.io/keep-younger-than"
+	// KeepTagRegexAnnotation on an image stream preserves all the revisions of
+	// its tags matching the regular expression, in addition to the tags
+	// matching the global expression.
+	KeepTagRegexAnnotation = "prune.openshift.io/keep-tag-regex"
+)
+
 type imageStreamTagReference struct {
 	Namespace string
 	Name      string
@@ -163,12 +180,51 @@ func (s *PruneStats) String() string {
 type pruneAlgorithm struct {
 	keepYoungerThan    time.Time
 	keepTagRevisions   int
+	keepTagRegexps     []*regexp.Regexp
 	pruneOverSizeLimit bool
 	namespace          string
 	allImages          bool
 	pruneRegistry      bool
 }
 
+// forImageStream returns the algorithm to use when pruning the history of the
+// image stream, taking the retention policy from its annotations into account.
+// Invalid annotations are reported and ignored.
+func (a pruneAlgorithm) forImageStream(is *imagev1.ImageStream) pruneAlgorithm {
+	if value, ok := is.Annotations[KeepTagRevisionsAnnotation]; ok {
+		if n, err := strconv.Atoi(value); err != nil || n < 0 {
+			klog.Warningf("imagestream %s/%s: ignoring invalid %s annotation %q", is.Namespace, is.Name, KeepTagRevisionsAnnotation, value)
+		} else {
+			a.keepTagRevisions = n
+		}
+	}
+	if value, ok := is.Annotations[KeepYoungerThanAnnotation]; ok {
+		if d, err := time.ParseDuration(value); err != nil || d < 0 {
+			klog.Warningf("imagestream %s/%s: ignoring invalid %s annotation %q", is.Namespace, is.Name, KeepYoungerThanAnnotation, value)
+		} else {
+			a.keepYoungerThan = metav1.Now().Add(-d)
+		}
+	}
+	if value, ok := is.Annotations[KeepTagRegexAnnotation]; ok {
+		if re, err := regexp.Compile(value); err != nil {
+			klog.Warningf("imagestream %s/%s: ignoring invalid %s annotation %q: %v", is.Namespace, is.Name, KeepTagRegexAnnotation, value, err)
+		} else {
+			a.keepTagRegexps = append(append([]*regexp.Regexp{}, a.keepTagRegexps...), re)
+		}
+	}
+	return a
+}
+
+// keepsTag returns true if all the revisions of the tag are preserved.
+func (a pruneAlgorithm) keepsTag(tag string) bool {
+	for _, re := range a.keepTagRegexps {
+		if re.MatchString(tag) {
+			return true
+		}
+	}
+	return false
+}
+
 // ImageDeleter knows how to remove images from OpenShift.
 type ImageDeleter interface {
 	// DeleteImage removes the image from OpenShift's storage.
@@ -214,6 +270,9 @@ type PrunerOptions struct {
 	// KeepTagRevisions is the minimum number of tag revisions to preserve;
 	// revisions older than this value are candidates for pruning.
 	KeepTagRevisions *int
+	// KeepTagRegexp preserves all the revisions of the image stream tags whose
+	// name matches the regular expression.
+	KeepTagRegexp *regexp.Regexp
 	// PruneOverSizeLimit indicates that images exceeding defined limits (openshift.io/Image)
 	// will be considered as candidates for pruning.
 	PruneOverSizeLimit *bool
@@ -313,6 +372,13 @@ var _ Pruner = &pruner{}
 // defined in their namespace will be considered for pruning. Important to note is
 // the fact that this flag does not work in any combination with the keep* flags.
 //
+// keepTagRegexp preserves all revisions of the tags matching it, regardless of
+// their age and of keepTagRevisions.
+//
+// Image streams may override keepTagRevisions and keepYoungerThan for their own
+// history, and add tags to preserve, using the KeepTagRevisionsAnnotation,
+// KeepYoungerThanAnnotation and KeepTagRegexAnnotation annotations.
+//
 // images, streams, pods, rcs, bcs, builds, daemonsets and dcs are the resources used to run
 // the pruning algorithm. These should be the full list for each type from the
 // cluster; otherwise, the pruning algorithm might result in incorrect
@@ -355,6 +421,9 @@ func NewPruner(options PrunerOptions) (Pruner, kerrors.Aggregate) {
 	if options.KeepTagRevisions != nil {
 		algorithm.keepTagRevisions = *options.KeepTagRevisions
 	}
+	if options.KeepTagRegexp != nil {
+		algorithm.keepTagRegexps = []*regexp.Regexp{options.KeepTagRegexp}
+	}
 	if options.PruneOverSizeLimit != nil {
 		algorithm.pruneOverSizeLimit = *options.PruneOverSizeLimit
 	}
@@ -890,12 +959,17 @@ func (p *pruner) getGlobalReferenceCounts(images map[string]*imagev1.Image) (ref
 	return counts, nil
 }
 
-func (p *pruner) pruneImageStreamTag(is *imagev1.ImageStream, tagEventList imagev1.NamedTagEventList, counts referenceCounts, layerLinkDeleter LayerLinkDeleter) (imagev1.NamedTagEventList, int, []string, []error) {
+func (p *pruner) pruneImageStreamTag(is *imagev1.ImageStream, algorithm pruneAlgorithm, tagEventList imagev1.NamedTagEventList, counts referenceCounts, layerLinkDeleter LayerLinkDeleter) (imagev1.NamedTagEventList, int, []string, []error) {
+	if algorithm.keepsTag(tagEventList.Tag) {
+		klog.V(4).Infof("imagestream %s/%s: tag %s: keeping all revisions because of --keep-tag-regex", is.Namespace, is.Name, tagEventList.Tag)
+		return tagEventList, 0, nil, nil
+	}
+
 	filteredItems := tagEventList.Items[:0]
 	var manifestsToDelete []string
 	var errs []error
 	for rev, item := range tagEventList.Items {
-		if !p.algorithm.pruneOverSizeLimit && item.Created.After(p.algorithm.keepYoungerThan) {
+		if !algorithm.pruneOverSizeLimit && item.Created.After(algorithm.keepYoungerThan) {
 			klog.V(4).Infof("imagestream %s/%s: tag %s: revision %d: keeping %s because of --keep-younger-than", is.Namespace, is.Name, tagEventList.Tag, rev+1, item.Image)
 			filteredItems = append(filteredItems, item)
 			continue
@@ -925,14 +999,14 @@ func (p *pruner) pruneImageStreamTag(is *imagev1.ImageStream, tagEventList image
 			continue
 		}
 
-		if p.algorithm.pruneOverSizeLimit {
+		if algorithm.pruneOverSizeLimit {
 			if !exceedsLimits(is, image, p.imageStreamLimits) {
 				klog.V(4).Infof("imagestream %s/%s: tag %s: revision %d: keeping %s because --prune-over-size-limit is used and image does not exceed limits", is.Namespace, is.Name, tagEventList.Tag, rev+1, item.Image)
 				filteredItems = append(filteredItems, item)
 				continue
 			}
 		} else {
-			if rev < p.algorithm.keepTagRevisions {
+			if rev < algorithm.keepTagRevisions {
 				klog.V(4).Infof("imagestream %s/%s: tag %s: revision %d: keeping %s because of --keep-tag-revisions", is.Namespace, is.Name, tagEventList.Tag, rev+1, item.Image)
 				filteredItems = append(filteredItems, item)
 				continue
@@ -952,7 +1026,7 @@ func (p *pruner) pruneImageStreamTag(is *imagev1.ImageStream, tagEventList image
 
 		klog.V(4).Infof("imagestream %s/%s: tag %s: revision %d: deleting repository links for %s...", is.Namespace, is.Name, tagEventList.Tag, rev+1, item.Image)
 
-		if p.algorithm.pruneRegistry {
+		if algorithm.pruneRegistry {
 			if counts.Manifests.Add(image.Name, -1) == 0 {
 				manifestsToDelete = append(manifestsToDelete, image.Name)
 			}
@@ -982,7 +1056,7 @@ func (p *pruner) pruneImageStreamTag(is *imagev1.ImageStream, tagEventList image
 func (p *pruner) pruneImageStream(stream *imagev1.ImageStream, imageStreamDeleter ImageStreamDeleter, layerLinkDeleter LayerLinkDeleter, manifestDeleter ManifestDeleter) (*imagev1.ImageStream, *PruneStats, []error) {
 	klog.V(4).Infof("Examining ImageStream %s/%s", stream.Namespace, stream.Name)
 
-	if !p.algorithm.pruneOverSizeLimit && stream.CreationTimestamp.Time.After(p.algorithm.keepYoungerThan) {
+	if algorithm := p.algorithm.forImageStream(stream); !algorithm.pruneOverSizeLimit && stream.CreationTimestamp.Time.After(algorithm.keepYoungerThan) {
 		klog.V(4).Infof("imagestream %s/%s: keeping all images because of --keep-younger-than", stream.Namespace, stream.Name)
 		return stream, &PruneStats{}, nil
 	}
@@ -1012,9 +1086,10 @@ func (p *pruner) pruneImageStream(stream *imagev1.ImageStream, imageStreamDelete
 			return nil
 		}
 
+		algorithm := p.algorithm.forImageStream(is)
 		deletedItems = 0
 		for i, tagEventList := range is.Status.Tags {
-			updatedTagEventList, deletedTagItems, tagManifestsToDelete, tagErrs := p.pruneImageStreamTag(is, tagEventList, counts, collectingLayerLinkDeleter)
+			updatedTagEventList, deletedTagItems, tagManifestsToDelete, tagErrs := p.pruneImageStreamTag(is, algorithm, tagEventList, counts, collectingLayerLinkDeleter)
 			is.Status.Tags[i] = updatedTagEventList
 			deletedItems += deletedTagItems
 			manifestsToDelete = append(manifestsToDelete, tagManifestsToDelete...)
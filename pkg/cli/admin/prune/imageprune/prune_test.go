@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
+	"regexp"
 	"strings"
 	"sync"
 	"testing"
@@ -67,6 +68,7 @@ func TestImagePruning(t *testing.T) {
 		pruneRegistry                        *bool
 		ignoreInvalidRefs                    *bool
 		keepTagRevisions                     *int
+		keepTagRegex                         string
 		namespace                            string
 		images                               map[string]*imagev1.Image
 		pods                                 corev1.PodList
@@ -1684,6 +1686,57 @@ func TestImagePruning(t *testing.T) {
 			},
 		},
 
+		{
+			name:             "tags matching --keep-tag-regex keep all revisions",
+			keepTagRevisions: keepTagRevisions(1),
+			keepTagRegex:     "^v[0-9]+$",
+			images: Images(
+				imagetest.Image("sha256:0000000000000000000000000000000000000000000000000000000000000000", registryHost+"/foo/bar@sha256:0000000000000000000000000000000000000000000000000000000000000000"),
+				imagetest.Image("sha256:0000000000000000000000000000000000000000000000000000000000000001", registryHost+"/foo/bar@sha256:0000000000000000000000000000000000000000000000000000000000000001"),
+				imagetest.Image("sha256:0000000000000000000000000000000000000000000000000000000000000002", registryHost+"/foo/bar@sha256:0000000000000000000000000000000000000000000000000000000000000002"),
+				imagetest.Image("sha256:0000000000000000000000000000000000000000000000000000000000000003", registryHost+"/foo/bar@sha256:0000000000000000000000000000000000000000000000000000000000000003"),
+			),
+			streams: Streams(
+				imagetest.Stream(registryHost, "foo", "bar", []imagev1.NamedTagEventList{
+					imagetest.Tag("v1",
+						imagetest.TagEvent("sha256:0000000000000000000000000000000000000000000000000000000000000000", registryHost+"/foo/bar@sha256:0000000000000000000000000000000000000000000000000000000000000000"),
+						imagetest.TagEvent("sha256:0000000000000000000000000000000000000000000000000000000000000001", registryHost+"/foo/bar@sha256:0000000000000000000000000000000000000000000000000000000000000001"),
+					),
+					imagetest.Tag("latest",
+						imagetest.TagEvent("sha256:0000000000000000000000000000000000000000000000000000000000000002", registryHost+"/foo/bar@sha256:0000000000000000000000000000000000000000000000000000000000000002"),
+						imagetest.TagEvent("sha256:0000000000000000000000000000000000000000000000000000000000000003", registryHost+"/foo/bar@sha256:0000000000000000000000000000000000000000000000000000000000000003"),
+					),
+				}),
+			),
+			expectedImageDeletions:        []string{"sha256:0000000000000000000000000000000000000000000000000000000000000003"},
+			expectedStreamUpdates:         []string{"foo/bar|latest|1|sha256:0000000000000000000000000000000000000000000000000000000000000003"},
+			expectedManifestLinkDeletions: []string{"foo/bar|sha256:0000000000000000000000000000000000000000000000000000000000000003"},
+			expectedBlobDeletions:         []string{"sha256:0000000000000000000000000000000000000000000000000000000000000003"},
+		},
+
+		{
+			name:             "image stream annotations override the retention policy",
+			keepTagRevisions: keepTagRevisions(0),
+			images: Images(
+				imagetest.Image("sha256:0000000000000000000000000000000000000000000000000000000000000000", registryHost+"/foo/bar@sha256:0000000000000000000000000000000000000000000000000000000000000000"),
+				imagetest.Image("sha256:0000000000000000000000000000000000000000000000000000000000000001", registryHost+"/foo/bar@sha256:0000000000000000000000000000000000000000000000000000000000000001"),
+				imagetest.Image("sha256:0000000000000000000000000000000000000000000000000000000000000002", registryHost+"/foo/bar@sha256:0000000000000000000000000000000000000000000000000000000000000002"),
+			),
+			streams: Streams(
+				withAnnotations(imagetest.Stream(registryHost, "foo", "bar", []imagev1.NamedTagEventList{
+					imagetest.Tag("latest",
+						imagetest.TagEvent("sha256:0000000000000000000000000000000000000000000000000000000000000000", registryHost+"/foo/bar@sha256:0000000000000000000000000000000000000000000000000000000000000000"),
+						imagetest.TagEvent("sha256:0000000000000000000000000000000000000000000000000000000000000001", registryHost+"/foo/bar@sha256:0000000000000000000000000000000000000000000000000000000000000001"),
+						imagetest.TagEvent("sha256:0000000000000000000000000000000000000000000000000000000000000002", registryHost+"/foo/bar@sha256:0000000000000000000000000000000000000000000000000000000000000002"),
+					),
+				}), map[string]string{KeepTagRevisionsAnnotation: "2"}),
+			),
+			expectedImageDeletions:        []string{"sha256:0000000000000000000000000000000000000000000000000000000000000002"},
+			expectedStreamUpdates:         []string{"foo/bar|latest|2|sha256:0000000000000000000000000000000000000000000000000000000000000002"},
+			expectedManifestLinkDeletions: []string{"foo/bar|sha256:0000000000000000000000000000000000000000000000000000000000000002"},
+			expectedBlobDeletions:         []string{"sha256:0000000000000000000000000000000000000000000000000000000000000002"},
+		},
+
 		{
 			name:             "referenced by statefulset - don't prune",
 			keepTagRevisions: keepTagRevisions(0),
@@ -1896,6 +1949,9 @@ func TestImagePruning(t *testing.T) {
 				options.KeepYoungerThan = &youngerThan
 				options.KeepTagRevisions = &tagRevisions
 			}
+			if len(test.keepTagRegex) > 0 {
+				options.KeepTagRegexp = regexp.MustCompile(test.keepTagRegex)
+			}
 			if test.pruneRegistry != nil {
 				options.PruneRegistry = test.pruneRegistry
 			}
@@ -2371,6 +2427,11 @@ func TestImageWithStrongAndWeakRefsIsNotPruned(t *testing.T) {
 	}
 }
 
+func withAnnotations(stream imagev1.ImageStream, annotations map[string]string) imagev1.ImageStream {
+	stream.Annotations = annotations
+	return stream
+}
+
 func keepTagRevisions(n int) *int {
 	return &n
 }
@@ -64,7 +64,7 @@ var (
 		integrated container image registry. If this command is run outside of the cluster network, the route
 		needs to be provided using --registry-url.
 
-		Only a user with a cluster role %s or higher who is logged-in will be able to actually
+		Only a user with a cluster role %[1]s or higher who is logged-in will be able to actually
 		delete the images.
 
 		If the registry is secured with a certificate signed by a self-signed root certificate
@@ -78,7 +78,19 @@ var (
 		 2. provided registry-url is prefixed with http://
 		 3. registry url is a private or link-local address
 		 4. user's config allows for insecure connection (the user logged in to the cluster with
-			--insecure-skip-tls-verify or allowed for insecure connection)`)
+			--insecure-skip-tls-verify or allowed for insecure connection)
+
+		All revisions of the image stream tags matching --keep-tag-regex are preserved. An image
+		stream may set its own retention policy for its history with the following annotations:
+
+		 * %[2]s: the number of revisions to preserve per tag, instead of --keep-tag-revisions
+		 * %[3]s: the minimum age of the image stream and its tag revisions, instead of
+		   --keep-younger-than
+		 * %[4]s: a regular expression matching more tags to preserve
+
+		With --output=json, a report of the image stream items, images, layer links, manifest
+		links and blobs that would be or were pruned, including the number of bytes reclaimed
+		from the registry, is printed instead.`)
 
 	imagesExample = templates.Examples(`
 	  # See what the prune command would delete if only images and their referrers were more than an hour old
@@ -95,6 +107,12 @@ var (
 	  # To actually perform the prune operation, the confirm flag must be appended
 	  oc adm prune images --prune-over-size-limit --confirm
 
+	  # Report what would be pruned as JSON, always preserving the release tags
+	  oc adm prune images --keep-tag-revisions=3 --keep-tag-regex='^v[0-9]+\.[0-9]+$' -o json
+
+	  # Preserve 10 revisions per tag of a particular image stream
+	  oc annotate imagestream/ruby -n myproject prune.openshift.io/keep-tag-revisions=10
+
 	  # Force the insecure HTTP protocol with the particular registry host name
 	  oc adm prune images --registry-url=http://registry.example.org --confirm
 
@@ -115,6 +133,7 @@ type PruneImagesOptions struct {
 	Confirm             bool
 	KeepYoungerThan     *time.Duration
 	KeepTagRevisions    *int
+	KeepTagRegex        string
 	PruneOverSizeLimit  *bool
 	AllImages           *bool
 	CABundle            string
@@ -124,7 +143,9 @@ type PruneImagesOptions struct {
 	PruneRegistry       *bool
 	IgnoreInvalidRefs   bool
 	NumWorkers          *int
+	Output              string
 
+	KeepTagRegexp      *regexp.Regexp
 	ClientConfig       *restclient.Config
 	AppsClient         appsv1client.AppsV1Interface
 	BuildClient        buildv1client.BuildV1Interface
@@ -153,7 +174,7 @@ func NewCmdPruneImages(f kcmdutil.Factory, streams genericiooptions.IOStreams) *
 	cmd := &cobra.Command{
 		Use:     "images",
 		Short:   "Remove unreferenced images",
-		Long:    fmt.Sprintf(imagesLongDesc, "system:image-pruner"),
+		Long:    fmt.Sprintf(imagesLongDesc, "system:image-pruner", imageprune.KeepTagRevisionsAnnotation, imageprune.KeepYoungerThanAnnotation, imageprune.KeepTagRegexAnnotation),
 		Example: imagesExample,
 		Run: func(cmd *cobra.Command, args []string) {
 			kcmdutil.CheckErr(opts.Complete(f, cmd, args, streams.Out))
@@ -166,6 +187,7 @@ func NewCmdPruneImages(f kcmdutil.Factory, streams genericiooptions.IOStreams) *
 	cmd.Flags().BoolVar(opts.AllImages, "all", *opts.AllImages, "Include images that were imported from external registries as candidates for pruning.  If pruned, all the mirrored objects associated with them will also be removed from the integrated registry.")
 	cmd.Flags().DurationVar(opts.KeepYoungerThan, "keep-younger-than", *opts.KeepYoungerThan, "Specify the minimum age of an image and its referrers for it to be considered a candidate for pruning.")
 	cmd.Flags().IntVar(opts.KeepTagRevisions, "keep-tag-revisions", *opts.KeepTagRevisions, "Specify the number of image revisions for a tag in an image stream that will be preserved.")
+	cmd.Flags().StringVar(&opts.KeepTagRegex, "keep-tag-regex", opts.KeepTagRegex, "A regular expression matching the image stream tags whose revisions will all be preserved.")
 	cmd.Flags().BoolVar(opts.PruneOverSizeLimit, "prune-over-size-limit", *opts.PruneOverSizeLimit, "Specify if images which are exceeding LimitRanges (see 'openshift.io/Image'), specified in the same namespace, should be considered for pruning. This flag cannot be combined with --keep-younger-than nor --keep-tag-revisions.")
 	cmd.Flags().StringVar(&opts.CABundle, "certificate-authority", opts.CABundle, "The path to a certificate authority bundle to use when communicating with the managed container image registries. Defaults to the certificate authority data from the current user's config file. It cannot be used together with --force-insecure.")
 	cmd.Flags().StringVar(&opts.RegistryUrlOverride, "registry-url", opts.RegistryUrlOverride, "The address to use when contacting the registry, instead of using the default value. This is useful if you can't resolve or reach the registry (e.g.; the default is a cluster-internal URL) but you do have an alternative route that works. Particular transport protocol can be enforced using '<scheme>://' prefix.")
@@ -173,6 +195,7 @@ func NewCmdPruneImages(f kcmdutil.Factory, streams genericiooptions.IOStreams) *
 	cmd.Flags().BoolVar(opts.PruneRegistry, "prune-registry", *opts.PruneRegistry, "If false, the prune operation will clean up image API objects, but the none of the associated content in the registry is removed.  Note, if only image API objects are cleaned up through use of this flag, the only means for subsequently cleaning up registry data corresponding to those image API objects is to employ the 'hard prune' administrative task.")
 	cmd.Flags().BoolVar(&opts.IgnoreInvalidRefs, "ignore-invalid-refs", opts.IgnoreInvalidRefs, "If true, the pruning process will ignore all errors while parsing image references. This means that the pruning process will ignore the intended connection between the object and the referenced image. As a result an image may be incorrectly deleted as unused.")
 	cmd.Flags().IntVar(opts.NumWorkers, "num-workers", *opts.NumWorkers, "Specify the number of parallel workers to use when running prune operations.")
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", opts.Output, "Print a report of what would be or was pruned in an alternative format: json")
 
 	return cmd
 }
@@ -206,6 +229,14 @@ func (o *PruneImagesOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, ar
 	o.Out = out
 	o.ErrOut = os.Stderr
 
+	if len(o.KeepTagRegex) > 0 {
+		var err error
+		o.KeepTagRegexp, err = regexp.Compile(o.KeepTagRegex)
+		if err != nil {
+			return fmt.Errorf("invalid --keep-tag-regex: %v", err)
+		}
+	}
+
 	var err error
 	o.ClientConfig, err = f.ToRESTConfig()
 	if err != nil {
@@ -261,6 +292,9 @@ func (o PruneImagesOptions) Validate() error {
 	if err := validateRegistryURL(o.RegistryUrlOverride); len(o.RegistryUrlOverride) > 0 && err != nil {
 		return fmt.Errorf("invalid --registry-url flag: %v", err)
 	}
+	if len(o.Output) > 0 && o.Output != "json" {
+		return fmt.Errorf("--output only supports 'json'")
+	}
 	if o.ForceInsecure && len(o.CABundle) > 0 {
 		return fmt.Errorf("--certificate-authority cannot be specified with --force-insecure")
 	}
@@ -468,6 +502,7 @@ func (o PruneImagesOptions) Run() error {
 	options := imageprune.PrunerOptions{
 		KeepYoungerThan:    o.KeepYoungerThan,
 		KeepTagRevisions:   o.KeepTagRevisions,
+		KeepTagRegexp:      o.KeepTagRegexp,
 		PruneOverSizeLimit: o.PruneOverSizeLimit,
 		AllImages:          o.AllImages,
 		Images:             allImages,
@@ -500,11 +535,16 @@ func (o PruneImagesOptions) Run() error {
 		return fmt.Errorf("failed to build graph - no changes made")
 	}
 
-	imageStreamDeleter := &describingImageStreamDeleter{w: o.Out, errOut: o.ErrOut}
-	layerLinkDeleter := &describingLayerLinkDeleter{w: o.Out, errOut: o.ErrOut}
-	manifestDeleter := &describingManifestDeleter{w: o.Out, errOut: o.ErrOut}
-	blobDeleter := &describingBlobDeleter{w: o.Out, errOut: o.ErrOut}
-	imageDeleter := &describingImageDeleter{w: o.Out, errOut: o.ErrOut}
+	var report *pruneReport
+	if o.Output == "json" {
+		report = newPruneReport(allImages, !o.Confirm)
+	}
+
+	imageStreamDeleter := &describingImageStreamDeleter{w: o.Out, errOut: o.ErrOut, report: report}
+	layerLinkDeleter := &describingLayerLinkDeleter{w: o.Out, errOut: o.ErrOut, report: report}
+	manifestDeleter := &describingManifestDeleter{w: o.Out, errOut: o.ErrOut, report: report}
+	blobDeleter := &describingBlobDeleter{w: o.Out, errOut: o.ErrOut, report: report}
+	imageDeleter := &describingImageDeleter{w: o.Out, errOut: o.ErrOut, report: report}
 
 	if o.Confirm {
 		imageStreamDeleter.delegate = imageprune.NewImageStreamDeleter(o.ImageClient)
@@ -517,7 +557,11 @@ func (o PruneImagesOptions) Run() error {
 	}
 
 	if o.PruneRegistry != nil && !*o.PruneRegistry {
-		fmt.Fprintln(o.Out, "Only API objects will be removed.  No modifications to the image registry will be made.")
+		out := o.Out
+		if report != nil {
+			out = o.ErrOut
+		}
+		fmt.Fprintln(out, "Only API objects will be removed.  No modifications to the image registry will be made.")
 	}
 
 	stats, errs := pruner.Prune(
@@ -527,6 +571,12 @@ func (o PruneImagesOptions) Run() error {
 		blobDeleter,
 		imageDeleter,
 	)
+	if report != nil {
+		if err := report.print(o.Out, stats.String()); err != nil {
+			return err
+		}
+		return errs
+	}
 	fmt.Fprintf(o.Out, "Summary: %s\n", stats)
 	return errs
 }
@@ -561,137 +611,162 @@ func (o *PruneImagesOptions) printGraphBuildErrors(errs kutilerrors.Aggregate) {
 	}
 }
 
-// describingImageStreamDeleter prints information about each image stream update.
-// If a delegate exists, its DeleteImageStream function is invoked prior to returning.
+// describingImageStreamDeleter prints information about each image stream update,
+// or records it in the report if one is set. If a delegate exists, its
+// DeleteImageStream function is invoked prior to returning.
 type describingImageStreamDeleter struct {
 	w        io.Writer
 	delegate imageprune.ImageStreamDeleter
 	errOut   io.Writer
+	report   *pruneReport
 }
 
 var _ imageprune.ImageStreamDeleter = &describingImageStreamDeleter{}
 
 func (p *describingImageStreamDeleter) GetImageStream(stream *imagev1.ImageStream) (*imagev1.ImageStream, error) {
-	if p.delegate == nil {
-		return stream, nil
+	if p.delegate != nil {
+		var err error
+		stream, err = p.delegate.GetImageStream(stream)
+		if err != nil {
+			return stream, err
+		}
 	}
 
-	return p.delegate.GetImageStream(stream)
+	p.report.observeImageStream(stream)
+	return stream, nil
 }
 
 func (p *describingImageStreamDeleter) UpdateImageStream(stream *imagev1.ImageStream, deletedItems int) (*imagev1.ImageStream, error) {
-	fmt.Fprintf(p.w, "Deleting %d items from image stream %s/%s\n", deletedItems, stream.Namespace, stream.Name)
-
-	if p.delegate == nil {
-		return stream, nil
+	if p.report == nil {
+		fmt.Fprintf(p.w, "Deleting %d items from image stream %s/%s\n", deletedItems, stream.Namespace, stream.Name)
 	}
 
-	updatedStream, err := p.delegate.UpdateImageStream(stream, deletedItems)
-	if err != nil {
-		fmt.Fprintf(p.errOut, "error updating image stream %s/%s to remove image references: %v\n", stream.Namespace, stream.Name, err)
+	updatedStream := stream
+	var err error
+	if p.delegate != nil {
+		updatedStream, err = p.delegate.UpdateImageStream(stream, deletedItems)
+		if err != nil {
+			fmt.Fprintf(p.errOut, "error updating image stream %s/%s to remove image references: %v\n", stream.Namespace, stream.Name, err)
+		}
 	}
 
+	p.report.addImageStream(stream, err)
 	return updatedStream, err
 }
 
-// describingImageDeleter prints information about each image being deleted.
-// If a delegate exists, its DeleteImage function is invoked prior to returning.
+// describingImageDeleter prints information about each image being deleted,
+// or records it in the report if one is set. If a delegate exists, its
+// DeleteImage function is invoked prior to returning.
 type describingImageDeleter struct {
 	w        io.Writer
 	delegate imageprune.ImageDeleter
 	errOut   io.Writer
+	report   *pruneReport
 }
 
 var _ imageprune.ImageDeleter = &describingImageDeleter{}
 
 func (p *describingImageDeleter) DeleteImage(image *imagev1.Image) error {
-	fmt.Fprintf(p.w, "Deleting image %s\n", image.Name)
-
-	if p.delegate == nil {
-		return nil
+	if p.report == nil {
+		fmt.Fprintf(p.w, "Deleting image %s\n", image.Name)
 	}
 
-	err := p.delegate.DeleteImage(image)
-	if err != nil {
-		fmt.Fprintf(p.errOut, "error deleting image %s from server: %v\n", image.Name, err)
+	var err error
+	if p.delegate != nil {
+		err = p.delegate.DeleteImage(image)
+		if err != nil {
+			fmt.Fprintf(p.errOut, "error deleting image %s from server: %v\n", image.Name, err)
+		}
 	}
 
+	p.report.addImage(image, err)
 	return err
 }
 
-// describingLayerLinkDeleter prints information about each repo layer link being deleted. If a delegate
-// exists, its DeleteLayerLink function is invoked prior to returning.
+// describingLayerLinkDeleter prints information about each repo layer link being deleted, or records
+// it in the report if one is set. If a delegate exists, its DeleteLayerLink function is invoked prior
+// to returning.
 type describingLayerLinkDeleter struct {
 	w        io.Writer
 	delegate imageprune.LayerLinkDeleter
 	errOut   io.Writer
+	report   *pruneReport
 }
 
 var _ imageprune.LayerLinkDeleter = &describingLayerLinkDeleter{}
 
 func (p *describingLayerLinkDeleter) DeleteLayerLink(repo, name string) error {
-	fmt.Fprintf(p.w, "Deleting layer link %s in repository %s\n", name, repo)
-
-	if p.delegate == nil {
-		return nil
+	if p.report == nil {
+		fmt.Fprintf(p.w, "Deleting layer link %s in repository %s\n", name, repo)
 	}
 
-	err := p.delegate.DeleteLayerLink(repo, name)
-	if err != nil {
-		fmt.Fprintf(p.errOut, "error deleting repository %s layer link %s from the registry: %v\n", repo, name, err)
+	var err error
+	if p.delegate != nil {
+		err = p.delegate.DeleteLayerLink(repo, name)
+		if err != nil {
+			fmt.Fprintf(p.errOut, "error deleting repository %s layer link %s from the registry: %v\n", repo, name, err)
+		}
 	}
 
+	p.report.addLayerLink(repo, name, err)
 	return err
 }
 
-// describingBlobDeleter prints information about each blob being deleted. If a
-// delegate exists, its DeleteBlob function is invoked prior to returning.
+// describingBlobDeleter prints information about each blob being deleted, or
+// records it in the report if one is set. If a delegate exists, its DeleteBlob
+// function is invoked prior to returning.
 type describingBlobDeleter struct {
 	w        io.Writer
 	delegate imageprune.BlobDeleter
 	errOut   io.Writer
+	report   *pruneReport
 }
 
 var _ imageprune.BlobDeleter = &describingBlobDeleter{}
 
 func (p *describingBlobDeleter) DeleteBlob(layer string) error {
-	fmt.Fprintf(p.w, "Deleting blob %s\n", layer)
-
-	if p.delegate == nil {
-		return nil
+	if p.report == nil {
+		fmt.Fprintf(p.w, "Deleting blob %s\n", layer)
 	}
 
-	err := p.delegate.DeleteBlob(layer)
-	if err != nil {
-		fmt.Fprintf(p.errOut, "error deleting blob %s from the registry: %v\n", layer, err)
+	var err error
+	if p.delegate != nil {
+		err = p.delegate.DeleteBlob(layer)
+		if err != nil {
+			fmt.Fprintf(p.errOut, "error deleting blob %s from the registry: %v\n", layer, err)
+		}
 	}
 
+	p.report.addBlob(layer, err)
 	return err
 }
 
 // describingManifestDeleter prints information about each repo manifest being
-// deleted. If a delegate exists, its DeleteManifest function is invoked prior
-// to returning.
+// deleted, or records it in the report if one is set. If a delegate exists, its
+// DeleteManifest function is invoked prior to returning.
 type describingManifestDeleter struct {
 	w        io.Writer
 	delegate imageprune.ManifestDeleter
 	errOut   io.Writer
+	report   *pruneReport
 }
 
 var _ imageprune.ManifestDeleter = &describingManifestDeleter{}
 
 func (p *describingManifestDeleter) DeleteManifest(repo, manifest string) error {
-	fmt.Fprintf(p.w, "Deleting manifest link %s in repository %s\n", manifest, repo)
-
-	if p.delegate == nil {
-		return nil
+	if p.report == nil {
+		fmt.Fprintf(p.w, "Deleting manifest link %s in repository %s\n", manifest, repo)
 	}
 
-	err := p.delegate.DeleteManifest(repo, manifest)
-	if err != nil {
-		fmt.Fprintf(p.errOut, "error deleting manifest link %s from repository %s: %v\n", manifest, repo, err)
+	var err error
+	if p.delegate != nil {
+		err = p.delegate.DeleteManifest(repo, manifest)
+		if err != nil {
+			fmt.Fprintf(p.errOut, "error deleting manifest link %s from repository %s: %v\n", manifest, repo, err)
+		}
 	}
 
+	p.report.addManifestLink(repo, manifest, err)
 	return err
 }
 
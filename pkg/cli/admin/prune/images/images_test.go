@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -27,6 +28,7 @@ import (
 	"k8s.io/kubectl/pkg/scheme"
 
 	"github.com/openshift/api"
+	imagev1 "github.com/openshift/api/image/v1"
 	fakeappsclient "github.com/openshift/client-go/apps/clientset/versioned/fake"
 	fakeappsv1client "github.com/openshift/client-go/apps/clientset/versioned/typed/apps/v1/fake"
 	fakebuildclient "github.com/openshift/client-go/build/clientset/versioned/fake"
@@ -202,6 +204,60 @@ func TestImagePruneErrOnBadReference(t *testing.T) {
 	verifyOutput(errBuf.String(), true)
 }
 
+func TestImagePruneJSONReport(t *testing.T) {
+	registryHost := "registry.io"
+	oldImage := imagetest.ImageWithLayers("sha256:0000000000000000000000000000000000000000000000000000000000000000", registryHost+"/foo/bar@sha256:0000000000000000000000000000000000000000000000000000000000000000", nil, "layer1", "layer2")
+	oldImage.DockerImageLayers[0].LayerSize = 100
+	oldImage.DockerImageLayers[1].LayerSize = 200
+	newImage := imagetest.ImageWithLayers("sha256:0000000000000000000000000000000000000000000000000000000000000001", registryHost+"/foo/bar@sha256:0000000000000000000000000000000000000000000000000000000000000001", nil, "layer1")
+	newImage.DockerImageLayers[0].LayerSize = 100
+	stream := imagetest.Stream(registryHost, "foo", "bar", []imagev1.NamedTagEventList{
+		imagetest.Tag("latest",
+			imagetest.TagEvent(newImage.Name, newImage.DockerImageReference),
+			imagetest.TagEvent(oldImage.Name, oldImage.DockerImageReference),
+		),
+	})
+
+	keepYoungerThan := time.Hour
+	keepTagRevisions := 1
+	out := &bytes.Buffer{}
+	opts := &PruneImagesOptions{
+		KeepYoungerThan:  &keepYoungerThan,
+		KeepTagRevisions: &keepTagRevisions,
+		Output:           "json",
+		AppsClient:       &fakeappsv1client.FakeAppsV1{Fake: &(fakeappsclient.NewSimpleClientset().Fake)},
+		BuildClient:      &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset().Fake)},
+		ImageClient:      &fakeimagev1client.FakeImageV1{Fake: &(fakeimageclient.NewSimpleClientset(&oldImage, &newImage, &stream).Fake)},
+		KubeClient:       fakekubernetes.NewSimpleClientset(),
+		Out:              out,
+		ErrOut:           io.Discard,
+	}
+	if err := opts.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	report := &pruneReport{}
+	if err := json.Unmarshal(out.Bytes(), report); err != nil {
+		t.Fatalf("unable to decode report %q: %v", out.String(), err)
+	}
+	if !report.DryRun {
+		t.Errorf("expected a dry run report")
+	}
+	if len(report.ImageStreams) != 1 || len(report.ImageStreams[0].Items) != 1 || report.ImageStreams[0].Items[0].Image != oldImage.Name {
+		t.Errorf("unexpected image streams: %#v", report.ImageStreams)
+	}
+	if len(report.Images) != 1 || report.Images[0].Name != oldImage.Name || report.Images[0].Size != 300 {
+		t.Errorf("unexpected images: %#v", report.Images)
+	}
+	expectedBlobs := []prunedBlob{{Digest: "layer2", Size: 200}, {Digest: oldImage.Name}}
+	if !reflect.DeepEqual(report.Blobs, expectedBlobs) {
+		t.Errorf("unexpected blobs: %s", diff.ObjectDiff(report.Blobs, expectedBlobs))
+	}
+	if report.ReclaimedBytes != 200 {
+		t.Errorf("expected 200 reclaimed bytes, got %d", report.ReclaimedBytes)
+	}
+}
+
 type fakeVersionDiscovery struct {
 	*fakediscovery.FakeDiscovery
 	masterVersion apimachineryversion.Info
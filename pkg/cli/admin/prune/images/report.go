@@ -0,0 +1,229 @@
+package images
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dockerv10 "github.com/openshift/api/image/docker10"
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+// pruneReport collects what the describing deleters prune, or would prune
+// during a dry run, for --output=json. All of its methods may be called on a
+// nil report, in which case they do nothing.
+type pruneReport struct {
+	mutex sync.Mutex
+	// blobSizes are the known sizes of the blobs in bytes.
+	blobSizes map[string]int64
+	// tags are the status tags of each image stream, by namespace/name, as
+	// fetched before pruning.
+	tags map[string][]imagev1.NamedTagEventList
+
+	DryRun         bool                `json:"dryRun"`
+	ImageStreams   []prunedImageStream `json:"imageStreams,omitempty"`
+	Images         []prunedImage       `json:"images,omitempty"`
+	LayerLinks     []prunedLink        `json:"layerLinks,omitempty"`
+	ManifestLinks  []prunedLink        `json:"manifestLinks,omitempty"`
+	Blobs          []prunedBlob        `json:"blobs,omitempty"`
+	ReclaimedBytes int64               `json:"reclaimedBytes"`
+	Summary        string              `json:"summary"`
+}
+
+type prunedImageStream struct {
+	Namespace string          `json:"namespace"`
+	Name      string          `json:"name"`
+	Items     []prunedTagItem `json:"items"`
+	Error     string          `json:"error,omitempty"`
+}
+
+type prunedTagItem struct {
+	Tag     string       `json:"tag"`
+	Image   string       `json:"image"`
+	Created *metav1.Time `json:"created,omitempty"`
+}
+
+type prunedImage struct {
+	Name  string `json:"name"`
+	Size  int64  `json:"size,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type prunedLink struct {
+	Repository string `json:"repository"`
+	Digest     string `json:"digest"`
+	Error      string `json:"error,omitempty"`
+}
+
+type prunedBlob struct {
+	Digest string `json:"digest"`
+	Size   int64  `json:"size,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func newPruneReport(images map[string]*imagev1.Image, dryRun bool) *pruneReport {
+	r := &pruneReport{
+		blobSizes: map[string]int64{},
+		tags:      map[string][]imagev1.NamedTagEventList{},
+		DryRun:    dryRun,
+	}
+	for _, image := range images {
+		for _, layer := range image.DockerImageLayers {
+			if layer.LayerSize > 0 {
+				r.blobSizes[layer.Name] = layer.LayerSize
+			}
+		}
+		if len(image.DockerImageManifest) > 0 {
+			r.blobSizes[image.Name] = int64(len(image.DockerImageManifest))
+		}
+	}
+	return r
+}
+
+// imageSize returns the size of the image in bytes, or 0 if it is unknown.
+func imageSize(image *imagev1.Image) int64 {
+	if metadata, ok := image.DockerImageMetadata.Object.(*dockerv10.DockerImage); ok && metadata.Size > 0 {
+		return metadata.Size
+	}
+	var size int64
+	for _, layer := range image.DockerImageLayers {
+		size += layer.LayerSize
+	}
+	return size
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// observeImageStream remembers the tags of the image stream before any of its
+// items are pruned.
+func (r *pruneReport) observeImageStream(stream *imagev1.ImageStream) {
+	if r == nil || stream == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.tags[fmt.Sprintf("%s/%s", stream.Namespace, stream.Name)] = stream.DeepCopy().Status.Tags
+}
+
+// addImageStream records the items removed from the image stream since it was
+// observed.
+func (r *pruneReport) addImageStream(stream *imagev1.ImageStream, err error) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	remaining := map[string][]imagev1.TagEvent{}
+	for _, tag := range stream.Status.Tags {
+		remaining[tag.Tag] = tag.Items
+	}
+	prunedStream := prunedImageStream{Namespace: stream.Namespace, Name: stream.Name, Error: errorString(err)}
+	for _, tag := range r.tags[fmt.Sprintf("%s/%s", stream.Namespace, stream.Name)] {
+		// the pruner only removes items, so the remaining items of a tag are
+		// in the same order as before
+		items := remaining[tag.Tag]
+		for _, item := range tag.Items {
+			if len(items) > 0 && items[0].Image == item.Image {
+				items = items[1:]
+				continue
+			}
+			prunedItem := prunedTagItem{Tag: tag.Tag, Image: item.Image}
+			if !item.Created.IsZero() {
+				prunedItem.Created = item.Created.DeepCopy()
+			}
+			prunedStream.Items = append(prunedStream.Items, prunedItem)
+		}
+	}
+	// an update retried after a conflict replaces the previous attempt
+	for i := range r.ImageStreams {
+		if r.ImageStreams[i].Namespace == stream.Namespace && r.ImageStreams[i].Name == stream.Name {
+			r.ImageStreams[i] = prunedStream
+			return
+		}
+	}
+	r.ImageStreams = append(r.ImageStreams, prunedStream)
+}
+
+func (r *pruneReport) addImage(image *imagev1.Image, err error) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Images = append(r.Images, prunedImage{Name: image.Name, Size: imageSize(image), Error: errorString(err)})
+}
+
+func (r *pruneReport) addLayerLink(repo, digest string, err error) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.LayerLinks = append(r.LayerLinks, prunedLink{Repository: repo, Digest: digest, Error: errorString(err)})
+}
+
+func (r *pruneReport) addManifestLink(repo, digest string, err error) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.ManifestLinks = append(r.ManifestLinks, prunedLink{Repository: repo, Digest: digest, Error: errorString(err)})
+}
+
+// addBlob records the blob and, if it was deleted, counts its size as reclaimed.
+func (r *pruneReport) addBlob(digest string, err error) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	size := r.blobSizes[digest]
+	r.Blobs = append(r.Blobs, prunedBlob{Digest: digest, Size: size, Error: errorString(err)})
+	if err == nil {
+		r.ReclaimedBytes += size
+	}
+}
+
+// print writes the report as JSON, sorted to be independent of the order in
+// which the workers pruned the objects.
+func (r *pruneReport) print(w io.Writer, summary string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.Summary = summary
+	sort.Slice(r.ImageStreams, func(i, j int) bool {
+		a, b := r.ImageStreams[i], r.ImageStreams[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	sort.Slice(r.Images, func(i, j int) bool { return r.Images[i].Name < r.Images[j].Name })
+	for _, links := range [][]prunedLink{r.LayerLinks, r.ManifestLinks} {
+		sort.Slice(links, func(i, j int) bool {
+			if links[i].Repository != links[j].Repository {
+				return links[i].Repository < links[j].Repository
+			}
+			return links[i].Digest < links[j].Digest
+		})
+	}
+	sort.Slice(r.Blobs, func(i, j int) bool { return r.Blobs[i].Digest < r.Blobs[j].Digest })
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
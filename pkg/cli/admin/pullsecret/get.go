@@ -0,0 +1,94 @@
+package pullsecret
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	getLong = templates.LongDesc(`
+		Show the registries of the global pull secret of the cluster.
+
+		The registries the pull secret has credentials for are listed with their user, without
+		their passwords. Pass --to to write the content of the pull secret to a file, or to
+		the standard output with --to=-, for instance to merge it in the auth.json of podman.`)
+
+	getExample = templates.Examples(`
+		# List the registries of the pull secret
+		oc adm pull-secret get
+
+		# Write the content of the pull secret to a file
+		oc adm pull-secret get --to=pull-secret.json`)
+)
+
+type GetOptions struct {
+	To string
+
+	Client kubernetes.Interface
+	genericiooptions.IOStreams
+}
+
+func NewCmdGet(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := &GetOptions{IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:     "get",
+		Short:   "Show the registries of the global pull secret",
+		Long:    getLong,
+		Example: getExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run(context.TODO()))
+		},
+	}
+	cmd.Flags().StringVar(&o.To, "to", o.To, "Write the content of the pull secret to this file, or to the standard output if '-'.")
+	return cmd
+}
+
+func (o *GetOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.Client, err = kubernetes.NewForConfig(config)
+	return err
+}
+
+func (o *GetOptions) Run(ctx context.Context) error {
+	secret, config, err := getPullSecret(ctx, o.Client)
+	if err != nil {
+		return err
+	}
+	switch o.To {
+	case "":
+	case "-":
+		_, err := o.Out.Write(secret.Data[corev1.DockerConfigJsonKey])
+		return err
+	default:
+		if err := os.WriteFile(o.To, secret.Data[corev1.DockerConfigJsonKey], 0600); err != nil {
+			return err
+		}
+		fmt.Fprintf(o.Out, "Wrote the pull secret %s/%s to %s\n", pullSecretNamespace, pullSecretName, o.To)
+		return nil
+	}
+
+	w := printers.GetNewTabWriter(o.Out)
+	fmt.Fprintln(w, "REGISTRY\tUSER\tEMAIL")
+	for _, registry := range config.registries() {
+		entry := config.entry(registry)
+		fmt.Fprintf(w, "%s\t%s\t%s\n", registry, entry.username(), entry.Email)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,132 @@
+package pullsecret
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	mergeLong = templates.LongDesc(`
+		Add registry credentials to the global pull secret of the cluster.
+
+		The credentials of the registries of --from-file, or of --registry with --username and
+		the password read from the standard input with --password-stdin, are added to the pull
+		secret, replacing those it has for the same registries. The credentials of the other
+		registries are kept. The credentials are validated and the registries added or updated
+		are printed before the pull secret is updated, and its previous value is backed up.
+		Pass --dry-run to only print the changes.`)
+
+	mergeExample = templates.Examples(`
+		# Add the credentials of the registries of a file to the pull secret
+		oc adm pull-secret merge --from-file=auth.json
+
+		# Add the credentials of a registry to the pull secret
+		echo "$PASSWORD" | oc adm pull-secret merge --registry=registry.example.com --username=admin --password-stdin
+
+		# Print the changes merging the credentials of a file would make
+		oc adm pull-secret merge --from-file=auth.json --dry-run`)
+)
+
+type MergeOptions struct {
+	updateOptions
+
+	FromFile      string
+	Registry      string
+	Username      string
+	PasswordStdin bool
+}
+
+func NewCmdMerge(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := &MergeOptions{updateOptions: updateOptions{IOStreams: streams}}
+	cmd := &cobra.Command{
+		Use:     "merge --from-file=FILE | --registry=HOST --username=USER --password-stdin",
+		Short:   "Add registry credentials to the global pull secret",
+		Long:    mergeLong,
+		Example: mergeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run(context.TODO()))
+		},
+	}
+	cmd.Flags().StringVar(&o.FromFile, "from-file", o.FromFile, "The docker config file with the credentials to add, or '-' to read it from the standard input.")
+	cmd.Flags().StringVar(&o.Registry, "registry", o.Registry, "The registry to add the credentials of.")
+	cmd.Flags().StringVar(&o.Username, "username", o.Username, "The user of the registry.")
+	cmd.Flags().BoolVar(&o.PasswordStdin, "password-stdin", o.PasswordStdin, "Read the password of the registry from the standard input.")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun, "Print the changes without updating the pull secret.")
+	return cmd
+}
+
+func (o *MergeOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+	switch {
+	case len(o.FromFile) > 0 && (len(o.Registry) > 0 || len(o.Username) > 0 || o.PasswordStdin):
+		return kcmdutil.UsageErrorf(cmd, "--from-file may not be used with --registry, --username or --password-stdin")
+	case len(o.FromFile) == 0 && (len(o.Registry) == 0 || len(o.Username) == 0 || !o.PasswordStdin):
+		return kcmdutil.UsageErrorf(cmd, "either --from-file or --registry, --username and --password-stdin are required")
+	}
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.Client, err = kubernetes.NewForConfig(config)
+	return err
+}
+
+func (o *MergeOptions) Run(ctx context.Context) error {
+	var data []byte
+	var err error
+	if len(o.FromFile) > 0 {
+		data, err = readInput(o.In, o.FromFile)
+	} else {
+		data, err = o.registryConfig()
+	}
+	if err != nil {
+		return err
+	}
+	additions, err := parseDockerConfig(data)
+	if err != nil {
+		return err
+	}
+
+	secret, previous, err := getPullSecret(ctx, o.Client)
+	if err != nil {
+		return err
+	}
+	// the pull secret is parsed again, so that merging does not change the previous config
+	config, err := parseDockerConfig(secret.Data[corev1.DockerConfigJsonKey])
+	if err != nil {
+		return err
+	}
+	config.merge(additions)
+	return o.update(ctx, secret, previous, config)
+}
+
+// registryConfig returns a docker config with the credentials of --registry, whose password is
+// read from the standard input.
+func (o *MergeOptions) registryConfig() ([]byte, error) {
+	password, err := io.ReadAll(o.In)
+	if err != nil {
+		return nil, err
+	}
+	passwordString := strings.TrimRight(string(password), "\r\n")
+	if len(passwordString) == 0 {
+		return nil, fmt.Errorf("no password was read from the standard input")
+	}
+	return json.Marshal(map[string]map[string]authEntry{
+		"auths": {o.Registry: {Auth: base64.StdEncoding.EncodeToString([]byte(o.Username + ":" + passwordString))}},
+	})
+}
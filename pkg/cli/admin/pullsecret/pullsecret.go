@@ -0,0 +1,262 @@
+package pullsecret
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// pullSecretNamespace and pullSecretName locate the global pull secret of the cluster,
+	// which the nodes pull all the images with.
+	pullSecretNamespace = "openshift-config"
+	pullSecretName      = "pull-secret"
+	// backupPrefix prefixes the name of the secrets the previous values of the pull secret are
+	// backed up in, which are labelled with backupLabel.
+	backupPrefix = "pull-secret-backup-"
+	backupLabel  = "oc.openshift.io/pull-secret-backup"
+)
+
+var pullSecretLong = templates.LongDesc(`
+	Manage the global pull secret of the cluster.
+
+	The pull secret openshift-config/pull-secret holds the credentials the nodes pull the
+	images of the cluster with, and is rolled out to the nodes by the Machine Config Operator.
+	These commands validate the credentials before updating the secret, instead of editing it
+	with 'oc set data' and jq, and back up its previous value in a secret of the
+	openshift-config namespace that 'oc adm pull-secret set --from-backup' restores.`)
+
+// NewCommandPullSecret implements the pull-secret command and its subcommands.
+func NewCommandPullSecret(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	cmds := &cobra.Command{
+		Use:   "pull-secret",
+		Short: "Manage the global pull secret of the cluster",
+		Long:  pullSecretLong,
+		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+	cmds.AddCommand(
+		NewCmdGet(f, streams),
+		NewCmdSet(f, streams),
+		NewCmdMerge(f, streams),
+	)
+	return cmds
+}
+
+// dockerConfig is the content of a kubernetes.io/dockerconfigjson secret. The fields other than
+// the auths, and the fields of the auths other than the credentials, are preserved.
+type dockerConfig struct {
+	raw   map[string]json.RawMessage
+	auths map[string]json.RawMessage
+}
+
+// authEntry holds the credentials of a registry.
+type authEntry struct {
+	Auth     string `json:"auth,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Email    string `json:"email,omitempty"`
+}
+
+// username returns the user of the credentials.
+func (e authEntry) username() string {
+	if len(e.Auth) == 0 {
+		return e.Username
+	}
+	decoded, err := base64.StdEncoding.DecodeString(e.Auth)
+	if err != nil {
+		return ""
+	}
+	username, _, _ := strings.Cut(string(decoded), ":")
+	return username
+}
+
+// parseDockerConfig parses and validates a docker config: it must have auths, the credentials of
+// every registry must be a base64 encoded USER:PASSWORD auth or a username and a password, and
+// it may not use credential helpers, which the nodes cannot run.
+func parseDockerConfig(data []byte) (*dockerConfig, error) {
+	config := &dockerConfig{}
+	if err := json.Unmarshal(data, &config.raw); err != nil {
+		return nil, fmt.Errorf("the pull secret is not valid JSON: %v", err)
+	}
+	for _, key := range []string{"credsStore", "credHelpers"} {
+		if _, ok := config.raw[key]; ok {
+			return nil, fmt.Errorf("the pull secret may not use credential helpers (%s), which the nodes cannot run", key)
+		}
+	}
+	auths, ok := config.raw["auths"]
+	if !ok {
+		return nil, fmt.Errorf("the pull secret has no auths")
+	}
+	if err := json.Unmarshal(auths, &config.auths); err != nil {
+		return nil, fmt.Errorf("the auths of the pull secret are not valid: %v", err)
+	}
+	for registry, raw := range config.auths {
+		if len(registry) == 0 || strings.ContainsAny(registry, " \t\n") {
+			return nil, fmt.Errorf("the pull secret has credentials for the invalid registry %q", registry)
+		}
+		var entry authEntry
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			return nil, fmt.Errorf("the credentials of %s are not valid: %v", registry, err)
+		}
+		switch {
+		case len(entry.Auth) > 0:
+			decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("the auth of %s is not base64 encoded: %v", registry, err)
+			}
+			if username, _, ok := strings.Cut(string(decoded), ":"); !ok || len(username) == 0 {
+				return nil, fmt.Errorf("the auth of %s is not of the form USER:PASSWORD", registry)
+			}
+		case len(entry.Username) == 0 || len(entry.Password) == 0:
+			return nil, fmt.Errorf("the credentials of %s have neither an auth nor a username and a password", registry)
+		}
+	}
+	return config, nil
+}
+
+// Bytes returns the docker config as JSON.
+func (c *dockerConfig) Bytes() ([]byte, error) {
+	auths, err := json.Marshal(c.auths)
+	if err != nil {
+		return nil, err
+	}
+	c.raw["auths"] = auths
+	return json.Marshal(c.raw)
+}
+
+func (c *dockerConfig) registries() []string {
+	var registries []string
+	for registry := range c.auths {
+		registries = append(registries, registry)
+	}
+	sort.Strings(registries)
+	return registries
+}
+
+func (c *dockerConfig) entry(registry string) authEntry {
+	var entry authEntry
+	json.Unmarshal(c.auths[registry], &entry)
+	return entry
+}
+
+// merge adds the credentials of the registries of other, replacing those of the registries
+// already in the config.
+func (c *dockerConfig) merge(other *dockerConfig) {
+	for registry, raw := range other.auths {
+		c.auths[registry] = raw
+	}
+}
+
+// changes describes how the credentials of the registries of updated differ from those of c.
+func (c *dockerConfig) changes(updated *dockerConfig) []string {
+	var changes []string
+	for _, registry := range updated.registries() {
+		raw, ok := c.auths[registry]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("added the credentials of %s", registry))
+		case !jsonEqual(raw, updated.auths[registry]):
+			changes = append(changes, fmt.Sprintf("updated the credentials of %s", registry))
+		}
+	}
+	for _, registry := range c.registries() {
+		if _, ok := updated.auths[registry]; !ok {
+			changes = append(changes, fmt.Sprintf("removed the credentials of %s", registry))
+		}
+	}
+	return changes
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	var compactA, compactB bytes.Buffer
+	if json.Compact(&compactA, a) != nil || json.Compact(&compactB, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(compactA.Bytes(), compactB.Bytes())
+}
+
+func getPullSecret(ctx context.Context, client kubernetes.Interface) (*corev1.Secret, *dockerConfig, error) {
+	secret, err := client.CoreV1().Secrets(pullSecretNamespace).Get(ctx, pullSecretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	config, err := parseDockerConfig(secret.Data[corev1.DockerConfigJsonKey])
+	if err != nil {
+		return nil, nil, fmt.Errorf("the pull secret %s/%s of the cluster is not valid: %v", pullSecretNamespace, pullSecretName, err)
+	}
+	return secret, config, nil
+}
+
+// updateOptions update the pull secret of the cluster after backing it up.
+type updateOptions struct {
+	DryRun bool
+
+	Client kubernetes.Interface
+	genericiooptions.IOStreams
+}
+
+// update replaces the content of the pull secret with the config, after printing the changes
+// and backing up its previous value. The update fails if the pull secret changed since it was
+// read.
+func (o *updateOptions) update(ctx context.Context, secret *corev1.Secret, previous, config *dockerConfig) error {
+	changes := previous.changes(config)
+	if len(changes) == 0 {
+		fmt.Fprintf(o.Out, "The pull secret %s/%s is unchanged\n", pullSecretNamespace, pullSecretName)
+		return nil
+	}
+	for _, change := range changes {
+		fmt.Fprintf(o.Out, "%s\n", change)
+	}
+	if o.DryRun {
+		fmt.Fprintf(o.Out, "The pull secret %s/%s was not updated (dry run)\n", pullSecretNamespace, pullSecretName)
+		return nil
+	}
+	data, err := config.Bytes()
+	if err != nil {
+		return err
+	}
+
+	backup, err := o.Client.CoreV1().Secrets(pullSecretNamespace).Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   backupPrefix + time.Now().UTC().Format("20060102-150405"),
+			Labels: map[string]string{backupLabel: "true"},
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: secret.Data[corev1.DockerConfigJsonKey]},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to back up the pull secret, it was not updated: %v", err)
+	}
+
+	secret = secret.DeepCopy()
+	secret.Data[corev1.DockerConfigJsonKey] = data
+	if _, err := o.Client.CoreV1().Secrets(pullSecretNamespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("unable to update the pull secret: %v", err)
+	}
+	fmt.Fprintf(o.Out, "Updated the pull secret %s/%s, its previous value is backed up in the secret %s/%s\n", pullSecretNamespace, pullSecretName, pullSecretNamespace, backup.Name)
+	fmt.Fprintf(o.Out, "To restore it, run: oc adm pull-secret set --from-backup=%s\n", backup.Name)
+	return nil
+}
+
+func readInput(in io.Reader, path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(in)
+	}
+	return os.ReadFile(path)
+}
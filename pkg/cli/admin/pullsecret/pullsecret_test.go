@@ -0,0 +1,100 @@
+package pullsecret
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func auth(credentials string) string {
+	return base64.StdEncoding.EncodeToString([]byte(credentials))
+}
+
+func TestParseDockerConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		expectedErr string
+	}{
+		{name: "auth", config: `{"auths":{"quay.io":{"auth":"` + auth("user:password") + `","email":"user@example.com"}}}`},
+		{name: "username and password", config: `{"auths":{"registry.example.com":{"username":"user","password":"password"}}}`},
+		{name: "not json", config: `auths`, expectedErr: "not valid JSON"},
+		{name: "no auths", config: `{}`, expectedErr: "has no auths"},
+		{name: "credential helper", config: `{"auths":{},"credHelpers":{"quay.io":"secretservice"}}`, expectedErr: "credential helpers"},
+		{name: "invalid registry", config: `{"auths":{"quay .io":{"auth":"` + auth("user:password") + `"}}}`, expectedErr: "invalid registry"},
+		{name: "auth not base64", config: `{"auths":{"quay.io":{"auth":"user:password"}}}`, expectedErr: "not base64"},
+		{name: "auth without password", config: `{"auths":{"quay.io":{"auth":"` + auth("user") + `"}}}`, expectedErr: "USER:PASSWORD"},
+		{name: "no credentials", config: `{"auths":{"quay.io":{"email":"user@example.com"}}}`, expectedErr: "neither an auth nor"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseDockerConfig([]byte(test.config))
+			switch {
+			case len(test.expectedErr) == 0 && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case len(test.expectedErr) > 0 && (err == nil || !strings.Contains(err.Error(), test.expectedErr)):
+				t.Fatalf("expected an error containing %q, got %v", test.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestMergeDockerConfig(t *testing.T) {
+	previous, err := parseDockerConfig([]byte(`{
+  "auths": {
+    "quay.io": {"auth": "` + auth("quay:password") + `", "email": "user@example.com"},
+    "registry.redhat.io": {"auth": "` + auth("redhat:password") + `"}
+  },
+  "other": {"kept": true}
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := parseDockerConfig([]byte(`{"auths": {"quay.io": {"auth": "` + auth("quay:password") + `", "email": "user@example.com"}, "registry.redhat.io": {"auth": "` + auth("redhat:password") + `"}}, "other": {"kept": true}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := previous.changes(config); len(changes) != 0 {
+		t.Errorf("expected no changes for the same config formatted differently, got %v", changes)
+	}
+
+	additions, err := parseDockerConfig([]byte(`{"auths":{"registry.redhat.io":{"auth":"` + auth("redhat:rotated") + `"},"registry.example.com":{"username":"admin","password":"password"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.merge(additions)
+	expectedChanges := []string{
+		"added the credentials of registry.example.com",
+		"updated the credentials of registry.redhat.io",
+	}
+	if changes := previous.changes(config); !reflect.DeepEqual(changes, expectedChanges) {
+		t.Errorf("expected changes %v, got %v", expectedChanges, changes)
+	}
+	if changes := config.changes(previous); !reflect.DeepEqual(changes, []string{"updated the credentials of registry.redhat.io", "removed the credentials of registry.example.com"}) {
+		t.Errorf("unexpected changes restoring the previous config: %v", changes)
+	}
+
+	data, err := config.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged, err := parseDockerConfig(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if registries := merged.registries(); !reflect.DeepEqual(registries, []string{"quay.io", "registry.example.com", "registry.redhat.io"}) {
+		t.Errorf("unexpected registries %v", registries)
+	}
+	if string(merged.raw["other"]) != `{"kept":true}` {
+		t.Errorf("expected the other fields to be kept, got %s", merged.raw["other"])
+	}
+	for registry, expected := range map[string]string{"quay.io": "quay", "registry.example.com": "admin", "registry.redhat.io": "redhat"} {
+		if username := merged.entry(registry).username(); username != expected {
+			t.Errorf("expected the user of %s to be %s, got %s", registry, expected, username)
+		}
+	}
+	if email := merged.entry("quay.io").Email; email != "user@example.com" {
+		t.Errorf("expected the email of quay.io to be kept, got %q", email)
+	}
+}
@@ -0,0 +1,107 @@
+package pullsecret
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	setLong = templates.LongDesc(`
+		Replace the global pull secret of the cluster.
+
+		The content of the pull secret is replaced with the credentials of --from-file, or
+		restored from a backup with --from-backup. The credentials are validated and the
+		registries added, updated or removed are printed before the pull secret is updated,
+		and its previous value is backed up. Pass --dry-run to only print the changes.
+
+		Use 'oc adm pull-secret merge' to add the credentials of registries while keeping
+		the others.`)
+
+	setExample = templates.Examples(`
+		# Replace the pull secret with the content of a file
+		oc adm pull-secret set --from-file=pull-secret.json
+
+		# Restore the pull secret from a backup
+		oc adm pull-secret set --from-backup=pull-secret-backup-20240101-120000
+
+		# List the backups of the pull secret
+		oc get secrets -n openshift-config -l oc.openshift.io/pull-secret-backup`)
+)
+
+type SetOptions struct {
+	updateOptions
+
+	FromFile   string
+	FromBackup string
+}
+
+func NewCmdSet(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := &SetOptions{updateOptions: updateOptions{IOStreams: streams}}
+	cmd := &cobra.Command{
+		Use:     "set --from-file=FILE | --from-backup=NAME",
+		Short:   "Replace the global pull secret",
+		Long:    setLong,
+		Example: setExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run(context.TODO()))
+		},
+	}
+	cmd.Flags().StringVar(&o.FromFile, "from-file", o.FromFile, "The docker config file to replace the pull secret with, or '-' to read it from the standard input.")
+	cmd.Flags().StringVar(&o.FromBackup, "from-backup", o.FromBackup, "The backup secret of openshift-config to restore the pull secret from.")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun, "Print the changes without updating the pull secret.")
+	return cmd
+}
+
+func (o *SetOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+	if (len(o.FromFile) > 0) == (len(o.FromBackup) > 0) {
+		return kcmdutil.UsageErrorf(cmd, "exactly one of --from-file or --from-backup is required")
+	}
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.Client, err = kubernetes.NewForConfig(config)
+	return err
+}
+
+func (o *SetOptions) Run(ctx context.Context) error {
+	var data []byte
+	if len(o.FromBackup) > 0 {
+		backup, err := o.Client.CoreV1().Secrets(pullSecretNamespace).Get(ctx, o.FromBackup, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if backup.Labels[backupLabel] != "true" {
+			return fmt.Errorf("the secret %s/%s is not a backup of the pull secret", pullSecretNamespace, o.FromBackup)
+		}
+		data = backup.Data[corev1.DockerConfigJsonKey]
+	} else {
+		var err error
+		if data, err = readInput(o.In, o.FromFile); err != nil {
+			return err
+		}
+	}
+	config, err := parseDockerConfig(data)
+	if err != nil {
+		return err
+	}
+
+	secret, previous, err := getPullSecret(ctx, o.Client)
+	if err != nil {
+		return err
+	}
+	return o.update(ctx, secret, previous, config)
+}
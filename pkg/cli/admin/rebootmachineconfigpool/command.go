@@ -2,12 +2,15 @@ package rebootmachineconfigpool
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/templates"
@@ -20,6 +23,10 @@ var (
 		Does not wait for the reboot to complete, only initiates it.  This command will honor paused pools.
 		Degraded, failed, or otherwise not healthy nodes will not restart.
 
+		With --watch, the nodes of the pools are watched until they have rebooted.  The time each node
+		is seen draining, rebooting and ready again is printed, followed by a report of the time each
+		pool took to reboot.  The command fails if nodes are degraded or --timeout is reached.
+
 		Experimental: This command is under active development and may change without notice.
 	`)
 
@@ -31,7 +38,10 @@ var (
 		oc adm reboot-machine-config-pool mcp/worker
 
 		# Reboot masters
-		oc adm reboot-machine-config-pool mcp/master`)
+		oc adm reboot-machine-config-pool mcp/master
+
+		# Reboot workers and watch the nodes drain, reboot and become ready, for up to 2 hours
+		oc adm reboot-machine-config-pool mcp/worker --watch --timeout=2h`)
 )
 
 type RebootMachineConfigPoolOptions struct {
@@ -42,6 +52,9 @@ type RebootMachineConfigPoolOptions struct {
 	// TODO push this into genericclioptions
 	DryRun bool
 
+	Watch   bool
+	Timeout time.Duration
+
 	genericiooptions.IOStreams
 }
 
@@ -84,9 +97,21 @@ func (o *RebootMachineConfigPoolOptions) AddFlags(cmd *cobra.Command) {
 	o.ResourceBuilderFlags.AddFlags(cmd.Flags())
 
 	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun, "Set to true to use server-side dry run.")
+	cmd.Flags().BoolVar(&o.Watch, "watch", o.Watch, "Watch the nodes of the pools until they have rebooted, then report the time each took.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The length of time to watch the nodes before giving up. Zero means no timeout.")
 }
 
 func (o *RebootMachineConfigPoolOptions) ToRuntime(args []string) (*RebootMachineConfigPoolRuntime, error) {
+	if o.Watch && o.DryRun {
+		return nil, fmt.Errorf("--watch cannot be used with --dry-run")
+	}
+	if o.Timeout != 0 && !o.Watch {
+		return nil, fmt.Errorf("--timeout requires --watch")
+	}
+	if o.Timeout < 0 {
+		return nil, fmt.Errorf("--timeout must not be negative")
+	}
+
 	printer, err := o.PrintFlags.ToPrinter()
 	if err != nil {
 		return nil, err
@@ -101,12 +126,20 @@ func (o *RebootMachineConfigPoolOptions) ToRuntime(args []string) (*RebootMachin
 	if err != nil {
 		return nil, err
 	}
+	kubeClient, err := kubernetes.NewForConfig(clientConfig)
+	if err != nil {
+		return nil, err
+	}
 
 	ret := &RebootMachineConfigPoolRuntime{
 		ResourceFinder: builder,
 		DynamicClient:  dynamicClient,
+		KubeClient:     kubeClient,
 
-		dryRun: o.DryRun,
+		dryRun:  o.DryRun,
+		watch:   o.Watch,
+		timeout: o.Timeout,
+		now:     time.Now,
 
 		Printer:   printer,
 		IOStreams: o.IOStreams,
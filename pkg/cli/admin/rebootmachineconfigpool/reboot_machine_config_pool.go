@@ -5,6 +5,7 @@ import (
 	_ "embed"
 	"fmt"
 	"strconv"
+	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -16,6 +17,7 @@ import (
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 )
 
 const (
@@ -60,8 +62,14 @@ const (
 type RebootMachineConfigPoolRuntime struct {
 	ResourceFinder genericclioptions.ResourceFinder
 	DynamicClient  dynamic.Interface
+	KubeClient     kubernetes.Interface
 
-	dryRun bool
+	dryRun  bool
+	watch   bool
+	timeout time.Duration
+	now     func() time.Time
+
+	watchedPools []*poolProgress
 
 	Printer printers.ResourcePrinter
 
@@ -76,6 +84,9 @@ func (r *RebootMachineConfigPoolRuntime) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if r.watch {
+		return r.watchPools(ctx)
+	}
 	return nil
 }
 
@@ -218,6 +229,31 @@ func (r *RebootMachineConfigPoolRuntime) rebootMachineConfigPool(ctx context.Con
 		return err
 	}
 
+	if r.watch {
+		if machineConfigPool.Spec.Paused {
+			fmt.Fprintf(r.ErrOut, "warning: machineconfigpools/%v is paused, its nodes will not reboot until it is unpaused and are not watched\n", machineConfigPool.Name)
+			return nil
+		}
+		if machineConfigPool.Spec.NodeSelector == nil {
+			return fmt.Errorf("machineconfigpools/%v cannot be watched due to missing .spec.nodeSelector", machineConfigPool.Name)
+		}
+		finalUnstructured, err := runtime.DefaultUnstructuredConverter.ToUnstructured(finalObject)
+		if err != nil {
+			return err
+		}
+		rebootNumber, err := GetRebootNumber(&unstructured.Unstructured{Object: finalUnstructured})
+		if err != nil {
+			return fmt.Errorf("unable to parse the reboot number of machineconfig/%v: %w", finalObject.Name, err)
+		}
+		r.watchedPools = append(r.watchedPools, &poolProgress{
+			name:         machineConfigPool.Name,
+			nodeSelector: machineConfigPool.Spec.NodeSelector,
+			rebootNumber: rebootNumber,
+			started:      r.now(),
+			nodes:        map[string]*nodeProgress{},
+		})
+	}
+
 	return nil
 }
 
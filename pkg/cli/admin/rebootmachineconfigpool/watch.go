@@ -0,0 +1,312 @@
+package rebootmachineconfigpool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+const (
+	currentConfigAnnotation = "machineconfiguration.openshift.io/currentConfig"
+	desiredConfigAnnotation = "machineconfiguration.openshift.io/desiredConfig"
+	stateAnnotation         = "machineconfiguration.openshift.io/state"
+	reasonAnnotation        = "machineconfiguration.openshift.io/reason"
+
+	watchInterval = 10 * time.Second
+)
+
+// The phases a node goes through to reach the reboot number of its pool.
+const (
+	PhaseWaiting   = "Waiting"
+	PhaseDraining  = "Draining"
+	PhaseRebooting = "Rebooting"
+	PhaseReady     = "Ready"
+	PhaseDegraded  = "Degraded"
+)
+
+// poolProgress tracks the nodes of a machine config pool rebooting to the reboot number set by
+// the command.
+type poolProgress struct {
+	name         string
+	nodeSelector *metav1.LabelSelector
+	rebootNumber int
+	started      time.Time
+
+	nodes map[string]*nodeProgress
+}
+
+// nodeProgress records when a node was first seen draining, rebooting and ready again.
+type nodeProgress struct {
+	name   string
+	phase  string
+	reason string
+
+	draining  time.Time
+	rebooting time.Time
+	ready     time.Time
+}
+
+// nodePhase returns the phase of a node given the reboot numbers of its current and desired
+// machine configs and the reboot number it must reach.
+func nodePhase(node *corev1.Node, currentRebootNumber, desiredRebootNumber, target int) string {
+	switch node.Annotations[stateAnnotation] {
+	case "Degraded", "Unreconcilable":
+		return PhaseDegraded
+	}
+	if currentRebootNumber >= target {
+		if nodeIsReady(node) && !node.Spec.Unschedulable {
+			return PhaseReady
+		}
+		return PhaseRebooting
+	}
+	if desiredRebootNumber < target {
+		return PhaseWaiting
+	}
+	if node.Annotations[stateAnnotation] == "Rebooting" || !nodeIsReady(node) {
+		return PhaseRebooting
+	}
+	return PhaseDraining
+}
+
+func nodeIsReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// observe records the phase of the node at now, and returns whether it changed.
+func (p *nodeProgress) observe(phase, reason string, now time.Time) bool {
+	if phase == p.phase && reason == p.reason {
+		return false
+	}
+	p.phase = phase
+	p.reason = reason
+	switch phase {
+	case PhaseDraining:
+		if p.draining.IsZero() {
+			p.draining = now
+		}
+	case PhaseRebooting:
+		if p.rebooting.IsZero() {
+			p.rebooting = now
+		}
+	case PhaseReady:
+		if p.ready.IsZero() {
+			p.ready = now
+		}
+	}
+	return true
+}
+
+// done returns whether no node of the pool is still progressing, and the number of nodes ready
+// and degraded.
+func (p *poolProgress) done() (bool, int, int) {
+	ready, degraded := 0, 0
+	for _, node := range p.nodes {
+		switch node.phase {
+		case PhaseReady:
+			ready++
+		case PhaseDegraded:
+			degraded++
+		}
+	}
+	return ready+degraded == len(p.nodes), ready, degraded
+}
+
+// watchPools polls the nodes of the pools until they all rebooted or are degraded, printing the
+// progression of each node, then prints a final report.
+func (r *RebootMachineConfigPoolRuntime) watchPools(ctx context.Context) error {
+	if len(r.watchedPools) == 0 {
+		return nil
+	}
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	// rendered machine configs are immutable, so their reboot numbers are only read once
+	rebootNumbers := map[string]int{}
+	for {
+		if err := r.observePools(ctx, rebootNumbers); err != nil {
+			return err
+		}
+
+		finished := true
+		degraded := 0
+		for _, pool := range r.watchedPools {
+			poolDone, _, poolDegraded := pool.done()
+			finished = finished && poolDone
+			degraded += poolDegraded
+		}
+		if finished {
+			if err := r.printReport(); err != nil {
+				return err
+			}
+			if degraded > 0 {
+				return fmt.Errorf("%d nodes are degraded and did not reboot", degraded)
+			}
+			return nil
+		}
+
+		select {
+		case <-time.After(watchInterval):
+		case <-ctx.Done():
+			if err := r.printReport(); err != nil {
+				return err
+			}
+			return fmt.Errorf("timed out waiting for the nodes to reboot: %w", ctx.Err())
+		}
+	}
+}
+
+func (r *RebootMachineConfigPoolRuntime) observePools(ctx context.Context, rebootNumbers map[string]int) error {
+	for _, pool := range r.watchedPools {
+		selector, err := metav1.LabelSelectorAsSelector(pool.nodeSelector)
+		if err != nil {
+			return fmt.Errorf("machineconfigpools/%v has an invalid .spec.nodeSelector: %w", pool.name, err)
+		}
+		nodes, err := r.KubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			fmt.Fprintf(r.ErrOut, "machineconfigpools/%v failed listing nodes: %v\n", pool.name, err)
+			continue
+		}
+
+		changed := false
+		for i := range nodes.Items {
+			node := &nodes.Items[i]
+			// nodes of both the master and worker pools are in the master pool
+			if _, ok := node.Labels["node-role.kubernetes.io/master"]; ok && pool.name != "master" {
+				continue
+			}
+			current, err := r.rebootNumberOf(ctx, rebootNumbers, node.Annotations[currentConfigAnnotation])
+			if err != nil {
+				fmt.Fprintf(r.ErrOut, "nodes/%v: %v\n", node.Name, err)
+				continue
+			}
+			desired, err := r.rebootNumberOf(ctx, rebootNumbers, node.Annotations[desiredConfigAnnotation])
+			if err != nil {
+				fmt.Fprintf(r.ErrOut, "nodes/%v: %v\n", node.Name, err)
+				continue
+			}
+
+			progress, ok := pool.nodes[node.Name]
+			if !ok {
+				progress = &nodeProgress{name: node.Name}
+				pool.nodes[node.Name] = progress
+			}
+			phase := nodePhase(node, current, desired, pool.rebootNumber)
+			reason := ""
+			if phase == PhaseDegraded {
+				reason = node.Annotations[reasonAnnotation]
+			}
+			now := r.now()
+			if !progress.observe(phase, reason, now) {
+				continue
+			}
+			changed = true
+			if len(reason) > 0 {
+				fmt.Fprintf(r.Out, "%s nodes/%v (machineconfigpools/%v) %s: %s\n", now.Format(time.RFC3339), node.Name, pool.name, phase, reason)
+			} else {
+				fmt.Fprintf(r.Out, "%s nodes/%v (machineconfigpools/%v) %s\n", now.Format(time.RFC3339), node.Name, pool.name, phase)
+			}
+		}
+		if changed {
+			_, ready, degraded := pool.done()
+			fmt.Fprintf(r.Out, "machineconfigpools/%v: %d of %d nodes rebooted, %d degraded\n", pool.name, ready, len(pool.nodes), degraded)
+		}
+	}
+	return nil
+}
+
+// rebootNumberOf returns the reboot number of the machine config, which is 0 if it was never
+// rebooted by this command.
+func (r *RebootMachineConfigPoolRuntime) rebootNumberOf(ctx context.Context, rebootNumbers map[string]int, machineConfigName string) (int, error) {
+	if len(machineConfigName) == 0 {
+		return 0, fmt.Errorf("missing the current or desired machineconfig annotation")
+	}
+	if n, ok := rebootNumbers[machineConfigName]; ok {
+		return n, nil
+	}
+	machineConfig, err := r.DynamicClient.Resource(MachineConfigResource).Get(ctx, machineConfigName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return 0, fmt.Errorf("machineconfig/%v is missing", machineConfigName)
+	case err != nil:
+		return 0, fmt.Errorf("failed getting machineconfig/%v: %w", machineConfigName, err)
+	}
+	n, err := GetRebootNumber(machineConfig)
+	if err != nil {
+		n = 0
+	}
+	rebootNumbers[machineConfigName] = n
+	return n, nil
+}
+
+// printReport prints the timestamps each node was seen draining, rebooting and ready, and the time
+// each pool took to reboot.
+func (r *RebootMachineConfigPoolRuntime) printReport() error {
+	fmt.Fprintln(r.Out)
+	w := printers.GetNewTabWriter(r.Out)
+	fmt.Fprintln(w, "POOL\tNODE\tDRAINING\tREBOOTING\tREADY\tDURATION\tPHASE")
+	for _, pool := range r.watchedPools {
+		names := make([]string, 0, len(pool.nodes))
+		for name := range pool.nodes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			node := pool.nodes[name]
+			took := "-"
+			if started := node.started(); !started.IsZero() && !node.ready.IsZero() {
+				took = duration.HumanDuration(node.ready.Sub(started))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", pool.name, name, formatTimestamp(node.draining), formatTimestamp(node.rebooting), formatTimestamp(node.ready), took, node.phase)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(r.Out)
+	for _, pool := range r.watchedPools {
+		_, ready, degraded := pool.done()
+		var last time.Time
+		for _, node := range pool.nodes {
+			if node.ready.After(last) {
+				last = node.ready
+			}
+		}
+		if ready == len(pool.nodes) && !last.IsZero() {
+			fmt.Fprintf(r.Out, "machineconfigpools/%v: %d of %d nodes rebooted in %s\n", pool.name, ready, len(pool.nodes), duration.HumanDuration(last.Sub(pool.started)))
+			continue
+		}
+		fmt.Fprintf(r.Out, "machineconfigpools/%v: %d of %d nodes rebooted, %d degraded\n", pool.name, ready, len(pool.nodes), degraded)
+	}
+	return nil
+}
+
+// started returns when the node was first seen draining or rebooting.
+func (p *nodeProgress) started() time.Time {
+	if !p.draining.IsZero() {
+		return p.draining
+	}
+	return p.rebooting
+}
+
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,249 @@
+package rebootmachineconfigpool
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodePhase(t *testing.T) {
+	tests := []struct {
+		name          string
+		state         string
+		ready         bool
+		unschedulable bool
+		current       int
+		desired       int
+		expected      string
+	}{
+		{
+			name:     "desired config not rendered yet",
+			state:    "Done",
+			ready:    true,
+			current:  1,
+			desired:  1,
+			expected: PhaseWaiting,
+		},
+		{
+			name:     "draining to the desired config",
+			state:    "Working",
+			ready:    true,
+			current:  1,
+			desired:  2,
+			expected: PhaseDraining,
+		},
+		{
+			name:          "cordoned while draining",
+			state:         "Working",
+			ready:         true,
+			unschedulable: true,
+			current:       1,
+			desired:       2,
+			expected:      PhaseDraining,
+		},
+		{
+			name:     "rebooting",
+			state:    "Rebooting",
+			ready:    true,
+			current:  1,
+			desired:  2,
+			expected: PhaseRebooting,
+		},
+		{
+			name:     "not ready before reaching the desired config",
+			state:    "Working",
+			current:  1,
+			desired:  2,
+			expected: PhaseRebooting,
+		},
+		{
+			name:     "back on the current config but not ready",
+			state:    "Done",
+			current:  2,
+			desired:  2,
+			expected: PhaseRebooting,
+		},
+		{
+			name:          "back on the current config but still cordoned",
+			state:         "Done",
+			ready:         true,
+			unschedulable: true,
+			current:       2,
+			desired:       2,
+			expected:      PhaseRebooting,
+		},
+		{
+			name:     "ready on the current config",
+			state:    "Done",
+			ready:    true,
+			current:  2,
+			desired:  2,
+			expected: PhaseReady,
+		},
+		{
+			name:     "past the target",
+			state:    "Done",
+			ready:    true,
+			current:  3,
+			desired:  3,
+			expected: PhaseReady,
+		},
+		{
+			name:     "degraded",
+			state:    "Degraded",
+			ready:    true,
+			current:  1,
+			desired:  2,
+			expected: PhaseDegraded,
+		},
+		{
+			name:     "unreconcilable after reaching the target",
+			state:    "Unreconcilable",
+			ready:    true,
+			current:  2,
+			desired:  2,
+			expected: PhaseDegraded,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status := corev1.ConditionFalse
+			if test.ready {
+				status = corev1.ConditionTrue
+			}
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-0", Annotations: map[string]string{stateAnnotation: test.state}},
+				Spec:       corev1.NodeSpec{Unschedulable: test.unschedulable},
+				Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}}},
+			}
+			if phase := nodePhase(node, test.current, test.desired, 2); phase != test.expected {
+				t.Errorf("expected phase %s, got %s", test.expected, phase)
+			}
+		})
+	}
+
+	// a node without a ready condition is not ready
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0"}}
+	if phase := nodePhase(node, 2, 2, 2); phase != PhaseRebooting {
+		t.Errorf("expected phase %s without a ready condition, got %s", PhaseRebooting, phase)
+	}
+}
+
+func TestNodeProgressObserve(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	type observation struct {
+		phase, reason string
+		changed       bool
+	}
+	tests := []struct {
+		name         string
+		observations []observation
+		expected     nodeProgress
+	}{
+		{
+			name: "full reboot",
+			observations: []observation{
+				{phase: PhaseWaiting, changed: true},
+				{phase: PhaseDraining, changed: true},
+				{phase: PhaseDraining},
+				{phase: PhaseRebooting, changed: true},
+				{phase: PhaseReady, changed: true},
+			},
+			expected: nodeProgress{phase: PhaseReady, draining: start.Add(time.Minute), rebooting: start.Add(3 * time.Minute), ready: start.Add(4 * time.Minute)},
+		},
+		{
+			name: "missed the drain",
+			observations: []observation{
+				{phase: PhaseWaiting, changed: true},
+				{phase: PhaseRebooting, changed: true},
+				{phase: PhaseReady, changed: true},
+			},
+			expected: nodeProgress{phase: PhaseReady, rebooting: start.Add(time.Minute), ready: start.Add(2 * time.Minute)},
+		},
+		{
+			name: "flapping keeps the first times",
+			observations: []observation{
+				{phase: PhaseRebooting, changed: true},
+				{phase: PhaseReady, changed: true},
+				{phase: PhaseRebooting, changed: true},
+				{phase: PhaseReady, changed: true},
+			},
+			expected: nodeProgress{phase: PhaseReady, rebooting: start, ready: start.Add(time.Minute)},
+		},
+		{
+			name: "new degraded reason",
+			observations: []observation{
+				{phase: PhaseDegraded, reason: "unable to drain", changed: true},
+				{phase: PhaseDegraded, reason: "unable to drain"},
+				{phase: PhaseDegraded, reason: "unable to apply", changed: true},
+			},
+			expected: nodeProgress{phase: PhaseDegraded, reason: "unable to apply"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := &nodeProgress{}
+			for i, o := range test.observations {
+				if changed := p.observe(o.phase, o.reason, start.Add(time.Duration(i)*time.Minute)); changed != o.changed {
+					t.Errorf("observation %d: expected changed %t, got %t", i, o.changed, changed)
+				}
+			}
+			if *p != test.expected {
+				t.Errorf("expected %#v, got %#v", test.expected, *p)
+			}
+		})
+	}
+}
+
+func TestPoolProgressDone(t *testing.T) {
+	tests := []struct {
+		name             string
+		phases           []string
+		expectedDone     bool
+		expectedReady    int
+		expectedDegraded int
+	}{
+		{
+			name:         "no nodes",
+			expectedDone: true,
+		},
+		{
+			name:          "all ready",
+			phases:        []string{PhaseReady, PhaseReady},
+			expectedDone:  true,
+			expectedReady: 2,
+		},
+		{
+			name:             "ready or degraded",
+			phases:           []string{PhaseReady, PhaseDegraded},
+			expectedDone:     true,
+			expectedReady:    1,
+			expectedDegraded: 1,
+		},
+		{
+			name:          "one node rebooting",
+			phases:        []string{PhaseReady, PhaseRebooting},
+			expectedReady: 1,
+		},
+		{
+			name:             "nodes waiting and draining",
+			phases:           []string{PhaseWaiting, PhaseDraining, PhaseDegraded},
+			expectedDegraded: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := &poolProgress{name: "worker", nodes: map[string]*nodeProgress{}}
+			for i, phase := range test.phases {
+				name := string(rune('a' + i))
+				p.nodes[name] = &nodeProgress{name: name, phase: phase}
+			}
+			done, ready, degraded := p.done()
+			if done != test.expectedDone || ready != test.expectedReady || degraded != test.expectedDegraded {
+				t.Errorf("expected done %t with %d ready and %d degraded, got done %t with %d ready and %d degraded", test.expectedDone, test.expectedReady, test.expectedDegraded, done, ready, degraded)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+)
+
+// clusterProxyCAKey is the key of the CA bundle in the config map the cluster proxy trusts.
+const clusterProxyCAKey = "ca-bundle.crt"
+
+// clusterProxyTransport returns the proxy and the additional CA bundle the cluster reaches
+// registries with, from the status of the cluster proxy and the config map of its trusted
+// CA, usually user-ca-bundle in openshift-config. Nothing is returned if the cluster has no
+// proxy configuration.
+func clusterProxyTransport(ctx context.Context, configClient configv1client.Interface, kubeClient kubernetes.Interface) (func(*http.Request) (*url.URL, error), []byte, error) {
+	proxy, err := configClient.ConfigV1().Proxies().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("unable to read the cluster proxy: %v", err)
+	}
+	proxyFunc, err := clusterProxyFunc(proxy.Status)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(proxy.Spec.TrustedCA.Name) == 0 {
+		return proxyFunc, nil, nil
+	}
+	cm, err := kubeClient.CoreV1().ConfigMaps("openshift-config").Get(ctx, proxy.Spec.TrustedCA.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read the trusted CA of the cluster proxy: %v", err)
+	}
+	return proxyFunc, []byte(cm.Data[clusterProxyCAKey]), nil
+}
+
+// clusterProxyFunc returns the proxy of a request according to the status of the cluster
+// proxy, or nil if the cluster does not use a proxy.
+func clusterProxyFunc(status configv1.ProxyStatus) (func(*http.Request) (*url.URL, error), error) {
+	if len(status.HTTPProxy) == 0 && len(status.HTTPSProxy) == 0 {
+		return nil, nil
+	}
+	var httpProxy, httpsProxy *url.URL
+	for _, p := range []struct {
+		value string
+		url   **url.URL
+	}{{status.HTTPProxy, &httpProxy}, {status.HTTPSProxy, &httpsProxy}} {
+		if len(p.value) == 0 {
+			continue
+		}
+		u, err := url.Parse(p.value)
+		if err != nil || len(u.Host) == 0 {
+			return nil, fmt.Errorf("the cluster proxy %q is not a valid URL", p.value)
+		}
+		*p.url = u
+	}
+	noProxy := strings.Split(status.NoProxy, ",")
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassClusterProxy(req.URL.Hostname(), noProxy) {
+			return nil, nil
+		}
+		if req.URL.Scheme == "https" {
+			return httpsProxy, nil
+		}
+		return httpProxy, nil
+	}, nil
+}
+
+// bypassClusterProxy returns true if host matches an entry of the noProxy list of the cluster,
+// which are domains, optionally with a leading dot, IP addresses, CIDRs, or * for all hosts.
+func bypassClusterProxy(host string, noProxy []string) bool {
+	ip := net.ParseIP(host)
+	for _, entry := range noProxy {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case len(entry) == 0:
+		case entry == "*":
+			return true
+		case ip != nil:
+			if _, cidr, err := net.ParseCIDR(entry); err == nil && cidr.Contains(ip) {
+				return true
+			}
+			if ip.Equal(net.ParseIP(entry)) {
+				return true
+			}
+		default:
+			if h, _, err := net.SplitHostPort(entry); err == nil {
+				entry = h
+			}
+			domain := strings.TrimPrefix(strings.TrimPrefix(entry, "*"), ".")
+			host = strings.ToLower(host)
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return true
+			}
+		}
+	}
+	return false
+}
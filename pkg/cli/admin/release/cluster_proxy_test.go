@@ -0,0 +1,66 @@
+package release
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configfake "github.com/openshift/client-go/config/clientset/versioned/fake"
+)
+
+func TestClusterProxyTransport(t *testing.T) {
+	proxy := &configv1.Proxy{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec:       configv1.ProxySpec{TrustedCA: configv1.ConfigMapNameReference{Name: "user-ca-bundle"}},
+		Status: configv1.ProxyStatus{
+			HTTPProxy:  "http://proxy.example.com:3128",
+			HTTPSProxy: "http://secure-proxy.example.com:3128",
+			NoProxy:    ".cluster.local,.svc,10.0.0.0/16,127.0.0.1,localhost,registry.example.com:5000",
+		},
+	}
+	ca := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "user-ca-bundle"},
+		Data:       map[string]string{"ca-bundle.crt": "-----BEGIN CERTIFICATE-----\n"},
+	}
+
+	proxyFunc, caData, err := clusterProxyTransport(context.TODO(), configfake.NewSimpleClientset(proxy), kubefake.NewSimpleClientset(ca))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(caData) != ca.Data["ca-bundle.crt"] {
+		t.Errorf("unexpected CA bundle %q", caData)
+	}
+	for _, test := range []struct {
+		url      string
+		expected string
+	}{
+		{url: "https://quay.io/v2/", expected: "http://secure-proxy.example.com:3128"},
+		{url: "http://quay.io/v2/", expected: "http://proxy.example.com:3128"},
+		{url: "https://image-registry.openshift-image-registry.svc:5000/v2/"},
+		{url: "https://registry.example.com/v2/"},
+		{url: "https://mirror.registry.example.com/v2/"},
+		{url: "https://10.0.12.4/v2/"},
+		{url: "https://10.1.12.4/v2/", expected: "http://secure-proxy.example.com:3128"},
+		{url: "https://localhost:5000/v2/"},
+	} {
+		u, _ := url.Parse(test.url)
+		got, err := proxyFunc(&http.Request{URL: u})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (got == nil && len(test.expected) > 0) || (got != nil && got.String() != test.expected) {
+			t.Errorf("%s: expected proxy %q, got %v", test.url, test.expected, got)
+		}
+	}
+
+	proxyFunc, caData, err = clusterProxyTransport(context.TODO(), configfake.NewSimpleClientset(), kubefake.NewSimpleClientset())
+	if err != nil || proxyFunc != nil || caData != nil {
+		t.Errorf("expected no proxy configuration without a cluster proxy: %v", err)
+	}
+}
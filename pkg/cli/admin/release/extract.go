@@ -26,7 +26,10 @@ import (
 	"k8s.io/kubectl/pkg/util/templates"
 
 	imagev1 "github.com/openshift/api/image/v1"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned"
 	"github.com/openshift/library-go/pkg/image/dockerv1client"
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/library-go/pkg/image/registryclient"
 	"github.com/openshift/library-go/pkg/manifest"
 	"github.com/openshift/oc/pkg/cli/image/extract"
 	"github.com/openshift/oc/pkg/cli/image/imagesource"
@@ -60,6 +63,7 @@ func NewExtractOptions(streams genericiooptions.IOStreams, extractManifests bool
 		IOStreams:        streams,
 		Directory:        ".",
 		ExtractManifests: extractManifests,
+		UseClusterProxy:  true,
 	}
 }
 
@@ -82,7 +86,9 @@ func NewExtract(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.C
 			You may pass a PGP private key file with --signing-key which will create an ASCII
 			armored sha256sum.txt.asc file describing the content that was extracted that is
 			signed by the key. For more advanced signing, use the generated sha256sum.txt and an
-			external tool like gpg.
+			external tool like gpg. Each archive also contains a <command>.sha256 file with the
+			checksum of its binary, and a <command>.sha256.asc signature of it with --signing-key,
+			so that the binary remains verifiable once extracted from the archive.
 
 			The --credentials-requests flag filters extracted manifests to only cloud credential
 			requests. The --cloud flag further filters credential requests to a specific cloud.
@@ -105,6 +111,39 @@ func NewExtract(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.C
 			If the specified image supports multiple operating systems, the image that matches the
 			current operating system will be chosen. Otherwise you must pass --filter-by-os to
 			select the desired image.
+
+			When the manifests are extracted to a directory, an extraction-manifest.json file is
+			written next to them recording the source pull spec, the digest it resolved to, the
+			layers the files came from, the sha256 sum of every extracted file, the version of the
+			client and when the extraction started and completed.
+
+			When no release image is specified, the release of the connected cluster is extracted
+			through the proxy of the cluster, trusting the CA bundle of its proxy configuration, so
+			that the registries the cluster pulls from are reachable from within restricted networks
+			without setting HTTPS_PROXY or --certificate-authority. Pass --use-cluster-proxy=false to
+			connect to the registries as configured locally instead.
+
+			When a request for content addressed by digest fails, it is retried against the
+			mirrors of --icsp-file or --idms-file or, when the release of the connected cluster is
+			extracted, of the ImageDigestMirrorSets and ImageContentSourcePolicies of the cluster,
+			and then against the repositories of --alternate-from, in order. Tags are always
+			resolved against the repository of the release image. When alternate sources are
+			known, the extraction-manifest.json file records the repository each layer was
+			served from, and the number of blobs served by each alternate source is printed.
+
+			Instead of extracting the manifests, you can pass --output=sbom to print a CycloneDX
+			software bill of materials of the release in JSON. It lists each image of the
+			image-references file of the payload with its digest, the repository it is pulled
+			from, and the source repository and commit it was built from, so that security
+			scanners can ingest the composition of the release.
+
+			Along with the manifests, --export-signatures=DIR writes the signatures of the release
+			and of each of its component images to DIR, to serve them to disconnected clusters
+			along with the mirrored images. The atomic container signatures found in the signature
+			stores of the release are written as DIR/ALGO=HASH/signature-NUMBER, so that DIR can be
+			served over HTTP as a signature store. The sigstore signatures are written under
+			DIR/sigstore in the layout of the file:// images of 'oc image mirror', to be mirrored
+			along with the images with --from-dir=DIR/sigstore.
 		`),
 		Example: templates.Examples(`
 			# Use git to check out the source code for the current cluster release to DIR
@@ -113,6 +152,15 @@ func NewExtract(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.C
 			# Extract cloud credential requests for AWS
 			oc adm release extract --credentials-requests --cloud=aws
 
+			# Extract the manifests of a release along with the signatures of its images
+			oc adm release extract --to=manifests --export-signatures=signatures quay.io/openshift-release-dev/ocp-release:4.11.2
+
+			# Extract the manifests of a release, falling back to a mirror registry on failures
+			oc adm release extract --to=manifests --alternate-from=mirror.example.com/ocp/release:4.11.2 quay.io/openshift-release-dev/ocp-release:4.11.2
+
+			# Write a CycloneDX SBOM of the component images of a release to a file
+			oc adm release extract --output=sbom quay.io/openshift-release-dev/ocp-release:4.11.2 > sbom.json
+
 			# Use git to check out the source code for the current cluster release to DIR from linux/s390x image
 			# Note: Wildcard filter is not supported; pass a single os/arch to extract
 			oc adm release extract --git=DIR quay.io/openshift-release-dev/ocp-release:4.11.2 --filter-by-os=linux/s390x
@@ -133,12 +181,14 @@ func NewExtract(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.C
 	flags.StringVar(&o.IDMSFile, "idms-file", o.IDMSFile, "Path to an ImageDigestMirrorSet file. If set, data from this file will be used to find alternative locations for images.")
 
 	flags.StringVar(&o.From, "from", o.From, "Image containing the release payload.")
+	flags.StringArrayVar(&o.AlternateFrom, "alternate-from", o.AlternateFrom, "Another location of the release image, such as a mirror registry, whose repository serves the content addressed by digest of the release and of its images when a request to their repository fails. Tried in order after the mirrors of --icsp-file, --idms-file or of the connected cluster. May be repeated.")
+	flags.BoolVar(&o.UseClusterProxy, "use-cluster-proxy", o.UseClusterProxy, "When the release image of the connected cluster is extracted, connect to the registries through the proxy of the cluster and trust its CA bundle.")
 	flags.StringVar(&o.File, "file", o.File, "Extract a single file from the payload to standard output.")
 	flags.StringVar(&o.Directory, "to", o.Directory, "Directory to write release contents to, defaults to the current directory.")
 
 	flags.StringVar(&o.GitExtractDir, "git", o.GitExtractDir, "Check out the sources that created this release into the provided dir. Repos will be created at <dir>/<host>/<path>. Requires 'git' on your path.")
 	flags.BoolVar(&o.Tools, "tools", o.Tools, "Extract the tools archives from the release image. Implies --command=*")
-	flags.StringVar(&o.SigningKey, "signing-key", o.SigningKey, "Sign the sha256sum.txt generated by --tools with this GPG key. A sha256sum.txt.asc file signed by this key will be created. The key is assumed to be encrypted.")
+	flags.StringVar(&o.SigningKey, "signing-key", o.SigningKey, "Sign the sha256sum.txt generated by --tools with this GPG key. A sha256sum.txt.asc file signed by this key will be created, and the checksum of each binary will be signed in its archive. The key is assumed to be encrypted.")
 
 	flags.StringVar(&o.Command, "command", o.Command, "Specify 'oc' or 'openshift-install' to extract the client for your operating system.")
 	flags.StringVar(&o.CommandOperatingSystem, "command-os", o.CommandOperatingSystem, "Override which operating system command is extracted (mac, windows, linux) or can be specified with arch(linux/arm64, mac/amd64). You map specify '*' to extract all tool archives.")
@@ -150,7 +200,8 @@ func NewExtract(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.C
 	flags.BoolVar(&o.CredentialsRequests, "credentials-requests", o.CredentialsRequests, "Exclude manifests which are not credential requests.")
 	flags.StringVar(&o.Cloud, "cloud", o.Cloud, "Exclude credential requests which are not relevant to the given cloud provider.  Works only in combination with --credentials-requests.")
 
-	flags.StringVarP(&o.Output, "output", "o", o.Output, "Output format. Supports 'commit' when used with '--git'.")
+	flags.StringVar(&o.ExportSignaturesDir, "export-signatures", o.ExportSignaturesDir, "Write the signatures of the release and of its component images to this directory, to serve them to disconnected clusters.")
+	flags.StringVarP(&o.Output, "output", "o", o.Output, "Output format. Supports 'commit' when used with '--git', and 'sbom' to print a CycloneDX SBOM of the component images instead of extracting the manifests.")
 	return cmd
 }
 
@@ -167,11 +218,24 @@ type ExtractOptions struct {
 	ICSPFile string
 	IDMSFile string
 
+	// AlternateFrom are other locations of the release image whose repositories are fallen
+	// back to after the mirrors when a request for content addressed by digest fails.
+	AlternateFrom []string
+	alternateFrom []reference.DockerImageReference
+	// clusterMirrors are the mirrors of the connected cluster, when its release is extracted
+	clusterMirrors []registryclient.AlternateBlobSourceStrategy
+	// sources records the repository each blob was served from when alternate sources are known
+	sources *blobSources
+
 	Output string
 
 	FromDir string
 	From    string
 
+	// UseClusterProxy, if true, applies the proxy and trusted CA of the cluster to the
+	// registry client when the release image is that of the connected cluster.
+	UseClusterProxy bool
+
 	Tools                  bool
 	Command                string
 	CommandOperatingSystem string
@@ -198,6 +262,10 @@ type ExtractOptions struct {
 	File      string
 	FileDir   string
 
+	// ExportSignaturesDir is the directory the signatures of the release and of its component
+	// images are written to after the manifests are extracted.
+	ExportSignaturesDir string
+
 	ExtractManifests bool
 	Manifests        []manifest.Manifest
 
@@ -215,6 +283,7 @@ func (o *ExtractOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args [
 	if len(o.From) > 0 {
 		args = []string{o.From}
 	}
+	fromCluster := len(args) == 0
 	args, err := findArgumentsFromCluster(f, args)
 	if err != nil {
 		return err
@@ -223,6 +292,16 @@ func (o *ExtractOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args [
 		return fmt.Errorf("you may only specify a single image via --from or argument")
 	}
 	o.From = args[0]
+	if fromCluster && o.UseClusterProxy {
+		if err := o.useClusterProxy(f); err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: Unable to use the proxy configuration of the cluster, pass --use-cluster-proxy=false to silence this warning: %v\n", err)
+		}
+	}
+	if fromCluster && len(o.ICSPFile) == 0 && len(o.IDMSFile) == 0 {
+		if err := o.loadClusterMirrors(f); err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: Unable to fall back to the mirrors of the cluster: %v\n", err)
+		}
+	}
 	if o.Included && o.InstallConfig == "" {
 		if o.RESTConfig, err = f.ToRESTConfig(); err != nil {
 			return err
@@ -232,7 +311,41 @@ func (o *ExtractOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args [
 	return o.FilterOptions.Complete(cmd.Flags())
 }
 
+// useClusterProxy configures the registry client with the proxy and trusted CA of the cluster.
+func (o *ExtractOptions) useClusterProxy(f kcmdutil.Factory) error {
+	cfg, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	configClient, err := configv1client.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	kubeClient, err := f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+	proxy, caData, err := clusterProxyTransport(context.TODO(), configClient, kubeClient)
+	if err != nil {
+		return err
+	}
+	if proxy != nil {
+		klog.V(2).Infof("Using the proxy of the cluster to connect to the registries")
+		o.SecurityOptions.Proxy = proxy
+	}
+	o.SecurityOptions.AdditionalCAData = caData
+	return nil
+}
+
 func (o *ExtractOptions) Validate() error {
+	o.alternateFrom = nil
+	for _, alternate := range o.AlternateFrom {
+		ref, err := reference.Parse(alternate)
+		if err != nil {
+			return fmt.Errorf("--alternate-from %q is not a valid image reference: %v", alternate, err)
+		}
+		o.alternateFrom = append(o.alternateFrom, ref)
+	}
 	return o.FilterOptions.Validate()
 }
 
@@ -254,8 +367,8 @@ func (o *ExtractOptions) Run(ctx context.Context) error {
 		sources++
 	}
 
-	if len(o.Output) > 0 && len(o.GitExtractDir) == 0 {
-		return fmt.Errorf("--output is only supported with --git")
+	if len(o.Output) > 0 && len(o.GitExtractDir) == 0 && o.Output != "sbom" {
+		return fmt.Errorf("--output is only supported with --git, or set to 'sbom'")
 	}
 
 	if len(o.InstallConfig) > 0 && !o.Included {
@@ -279,6 +392,11 @@ func (o *ExtractOptions) Run(ctx context.Context) error {
 		fmt.Fprintln(o.ErrOut, "warning: if you intend to pass CredentialsRequests to ccoctl, you should use --included to filter out requests that your cluster is not expected to need.")
 	}
 
+	if err := o.useAlternateSources(); err != nil {
+		return err
+	}
+	defer o.sources.report(o.ErrOut)
+
 	switch {
 	case sources > 1:
 		return fmt.Errorf("only one of --tools, --command, --credentials-requests, --file, or --git may be specified")
@@ -287,6 +405,13 @@ func (o *ExtractOptions) Run(ctx context.Context) error {
 	case o.Directory != "" && o.Directory != "." && len(o.File) > 0:
 		return fmt.Errorf("only one of --to and --file may be set")
 
+	case len(o.ExportSignaturesDir) > 0 && (sources > 0 || o.Output == "sbom" || len(o.File) > 0 || o.Directory == ""):
+		return fmt.Errorf("--export-signatures is only supported when extracting the manifests")
+	case o.Output == "sbom" && (sources > 0 || o.Included):
+		return fmt.Errorf("--output=sbom may not be used with --tools, --command, --credentials-requests, --file, --git, or --included")
+	case o.Output == "sbom":
+		return o.extractSBOM()
+
 	case len(o.GitExtractDir) > 0:
 		return o.extractGit(o.GitExtractDir)
 	case o.Tools:
@@ -305,6 +430,12 @@ func (o *ExtractOptions) Run(ctx context.Context) error {
 		}
 	}
 
+	var audit *extractionAudit
+	if o.ExtractManifests && o.Directory != "" && o.File == "" {
+		audit = newExtractionAudit(o.From, time.Now())
+		audit.sources = o.sources
+	}
+
 	src := o.From
 	ref, err := imagesource.ParseReference(src)
 	if err != nil {
@@ -332,10 +463,18 @@ func (o *ExtractOptions) Run(ctx context.Context) error {
 	}
 
 	var metadataVerifyMsg string
+	var signedRelease []signedImage
 
 	verifier := imagemanifest.NewVerifier()
 	imageMetadataCallbacks = append(imageMetadataCallbacks, func(m *extract.Mapping, dgst, contentDigest digest.Digest, config *dockerv1client.DockerImageConfig, manifestListDigest digest.Digest) {
 		verifier.Verify(dgst, contentDigest)
+		signedRelease = []signedImage{{ref: ref, digest: dgst}}
+		if len(manifestListDigest) > 0 {
+			signedRelease = append(signedRelease, signedImage{ref: ref, digest: manifestListDigest})
+		}
+		if audit != nil {
+			audit.image(dgst, contentDigest, config, manifestListDigest)
+		}
 		if len(ref.Ref.ID) > 0 {
 			metadataVerifyMsg = fmt.Sprintf("Extracted release payload created at %s", config.Created.Format(time.RFC3339))
 		} else {
@@ -380,7 +519,7 @@ func (o *ExtractOptions) Run(ctx context.Context) error {
 			include = newIncluder(inclusionConfig)
 		}
 
-		tarEntryCallbacks = append(tarEntryCallbacks, func(hdr *tar.Header, _ extract.LayerInfo, r io.Reader) (bool, error) {
+		tarEntryCallbacks = append(tarEntryCallbacks, func(hdr *tar.Header, layer extract.LayerInfo, r io.Reader) (bool, error) {
 			if hdr.Name == "image-references" && !o.CredentialsRequests {
 				buf := &bytes.Buffer{}
 				if _, err := io.Copy(buf, r); err != nil {
@@ -403,6 +542,9 @@ func (o *ExtractOptions) Run(ctx context.Context) error {
 					if err != nil {
 						return false, err
 					}
+					if audit != nil {
+						audit.file(hdr.Name, layer)
+					}
 				}
 				if out != nil {
 					_, err := buf.WriteTo(out)
@@ -416,6 +558,9 @@ func (o *ExtractOptions) Run(ctx context.Context) error {
 					if err != nil {
 						return false, err
 					}
+					if audit != nil {
+						audit.file(hdr.Name, layer)
+					}
 				}
 				if out != nil {
 					_, err := io.Copy(out, r)
@@ -474,6 +619,9 @@ func (o *ExtractOptions) Run(ctx context.Context) error {
 				if err != nil {
 					return false, errors.Wrapf(err, "error creating manifest in %s", hdr.Name)
 				}
+				if audit != nil {
+					audit.file(hdr.Name, layer)
+				}
 			}
 			if out != nil {
 				for _, m := range manifestsToWrite {
@@ -551,16 +699,44 @@ func (o *ExtractOptions) Run(ctx context.Context) error {
 		return fmt.Errorf("image did not contain %s", o.File)
 	}
 
+	if audit != nil {
+		if err := audit.write(o.Directory, verifier.Verified(), time.Now()); err != nil {
+			return err
+		}
+	}
+
 	// Only output manifest errors if manifests were being extracted.
 	// Do not return an error so current operation, e.g. mirroring, continues.
 	if o.ExtractManifests && len(manifestErrs) > 0 {
 		fmt.Fprintf(o.ErrOut, "Errors: %s\n", errorList(manifestErrs))
 	}
 
+	if len(o.ExportSignaturesDir) > 0 {
+		if err := o.exportSignatures(ctx, o.ExportSignaturesDir, signedRelease); err != nil {
+			return err
+		}
+	}
+
 	return nil
 
 }
 
+// extractSBOM prints a CycloneDX SBOM of the component images of the release instead of extracting
+// its manifests.
+func (o *ExtractOptions) extractSBOM() error {
+	opts := NewInfoOptions(o.IOStreams)
+	opts.SecurityOptions = o.SecurityOptions
+	opts.FilterOptions = o.FilterOptions
+	opts.FileDir = o.FileDir
+	opts.ICSPFile = o.ICSPFile
+	opts.IDMSFile = o.IDMSFile
+	release, err := opts.LoadReleaseInfo(o.From, false)
+	if err != nil {
+		return err
+	}
+	return writeReleaseSBOM(o.Out, release, time.Now())
+}
+
 func (o *ExtractOptions) extractGit(dir string) error {
 	switch o.Output {
 	case "commit", "":
@@ -0,0 +1,169 @@
+package release
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/distribution/distribution/v3"
+	digest "github.com/opencontainers/go-digest"
+
+	apimachineryversion "k8s.io/apimachinery/pkg/version"
+
+	"github.com/openshift/library-go/pkg/image/dockerv1client"
+	"github.com/openshift/oc/pkg/cli/image/extract"
+	"github.com/openshift/oc/pkg/version"
+)
+
+// extractionManifestName is the file recording what was extracted from a release image.
+const extractionManifestName = "extraction-manifest.json"
+
+// extractionManifest is a verifiable record of the content extracted from a release image
+// and of where it was pulled from.
+type extractionManifest struct {
+	// Source is the pull spec the release image was extracted from.
+	Source string `json:"source"`
+	// Digest is the digest of the manifest the source resolved to.
+	Digest digest.Digest `json:"digest"`
+	// ContentDigest is the digest of the manifest content, which verifies the image.
+	ContentDigest digest.Digest `json:"contentDigest"`
+	// ManifestListDigest is set when the image was selected from a manifest list.
+	ManifestListDigest digest.Digest `json:"manifestListDigest,omitempty"`
+	// ManifestSource is the repository the manifest was served from, when the release
+	// image has alternate sources.
+	ManifestSource string `json:"manifestSource,omitempty"`
+	// Verified is true if the content of the image matched its digest.
+	Verified bool `json:"verified"`
+	// Created is when the release image was built.
+	Created time.Time `json:"created"`
+
+	// Layers are the layers of the image the files were extracted from.
+	Layers []extractionLayer `json:"layers"`
+	// Files are the files written to the extraction directory.
+	Files []extractionFile `json:"files"`
+
+	// Tool is the version of the client which extracted the files.
+	Tool extractionTool `json:"tool"`
+
+	StartTime      time.Time `json:"startTime"`
+	CompletionTime time.Time `json:"completionTime"`
+}
+
+type extractionLayer struct {
+	Index     int           `json:"index"`
+	Digest    digest.Digest `json:"digest"`
+	MediaType string        `json:"mediaType,omitempty"`
+	Size      int64         `json:"size"`
+	// DiffID is the digest of the uncompressed content of the layer.
+	DiffID string `json:"diffID,omitempty"`
+	// Source is the repository the layer was served from, when the release image has
+	// alternate sources.
+	Source string `json:"source,omitempty"`
+}
+
+type extractionFile struct {
+	Name   string        `json:"name"`
+	SHA256 string        `json:"sha256"`
+	Size   int64         `json:"size"`
+	Layer  digest.Digest `json:"layer"`
+}
+
+type extractionTool struct {
+	Name string `json:"name"`
+	apimachineryversion.Info
+}
+
+// extractionAudit collects the extraction manifest while a release image is extracted.
+type extractionAudit struct {
+	manifest extractionManifest
+	diffIDs  []string
+	layers   map[int]distribution.Descriptor
+	files    map[string]digest.Digest
+	// sources records the repository each blob was served from, if the image has alternate sources
+	sources *blobSources
+}
+
+func newExtractionAudit(source string, now time.Time) *extractionAudit {
+	return &extractionAudit{
+		manifest: extractionManifest{
+			Source:    source,
+			Tool:      extractionTool{Name: "oc", Info: version.Get()},
+			StartTime: now.UTC(),
+		},
+		layers: make(map[int]distribution.Descriptor),
+		files:  make(map[string]digest.Digest),
+	}
+}
+
+// image records the image the files were extracted from.
+func (a *extractionAudit) image(dgst, contentDigest digest.Digest, config *dockerv1client.DockerImageConfig, manifestListDigest digest.Digest) {
+	a.manifest.Digest = dgst
+	a.manifest.ContentDigest = contentDigest
+	a.manifest.ManifestListDigest = manifestListDigest
+	if config != nil {
+		a.manifest.Created = config.Created.UTC()
+		if config.RootFS != nil {
+			a.diffIDs = config.RootFS.DiffIDs
+		}
+	}
+}
+
+// file records a file written from a layer of the image.
+func (a *extractionAudit) file(name string, layer extract.LayerInfo) {
+	a.layers[layer.Index] = layer.Descriptor
+	a.files[name] = layer.Descriptor.Digest
+}
+
+// write hashes the files extracted to dir and writes the extraction manifest next to them.
+func (a *extractionAudit) write(dir string, verified bool, now time.Time) error {
+	m := a.manifest
+	m.Verified = verified
+	m.CompletionTime = now.UTC()
+	m.ManifestSource = a.sources.source(m.Digest)
+
+	m.Layers = make([]extractionLayer, 0, len(a.layers))
+	for index, desc := range a.layers {
+		layer := extractionLayer{Index: index, Digest: desc.Digest, MediaType: desc.MediaType, Size: desc.Size, Source: a.sources.source(desc.Digest)}
+		if index < len(a.diffIDs) {
+			layer.DiffID = a.diffIDs[index]
+		}
+		m.Layers = append(m.Layers, layer)
+	}
+	sort.Slice(m.Layers, func(i, j int) bool { return m.Layers[i].Index < m.Layers[j].Index })
+
+	m.Files = make([]extractionFile, 0, len(a.files))
+	for name, layer := range a.files {
+		sum, size, err := sha256File(filepath.Join(dir, name))
+		if err != nil {
+			return fmt.Errorf("unable to record %s in the %s: %v", name, extractionManifestName, err)
+		}
+		m.Files = append(m.Files, extractionFile{Name: name, SHA256: sum, Size: size, Layer: layer})
+	}
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Name < m.Files[j].Name })
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, extractionManifestName), append(data, '\n'), 0644)
+}
+
+func sha256File(name string) (string, int64, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
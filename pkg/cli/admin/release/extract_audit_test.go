@@ -0,0 +1,63 @@
+package release
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/distribution/distribution/v3"
+	digest "github.com/opencontainers/go-digest"
+
+	"github.com/openshift/library-go/pkg/image/dockerv1client"
+	"github.com/openshift/oc/pkg/cli/image/extract"
+)
+
+func TestExtractionAudit(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"image-references":     `{"kind":"ImageStream"}`,
+		"0000_00_cvo_crd.yaml": "---\nkind: CustomResourceDefinition\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	audit := newExtractionAudit("quay.io/openshift-release-dev/ocp-release:4.16.0-x86_64", start)
+	first := distribution.Descriptor{MediaType: "application/vnd.docker.image.rootfs.diff.tar.gzip", Digest: digest.FromString("first"), Size: 10}
+	last := distribution.Descriptor{MediaType: "application/vnd.docker.image.rootfs.diff.tar.gzip", Digest: digest.FromString("last"), Size: 20}
+	audit.file("image-references", extract.LayerInfo{Index: 3, Descriptor: last})
+	audit.file("0000_00_cvo_crd.yaml", extract.LayerInfo{Index: 1, Descriptor: first})
+	audit.image(digest.FromString("manifest"), digest.FromString("manifest"), &dockerv1client.DockerImageConfig{
+		Created: start.Add(-time.Hour),
+		RootFS:  &dockerv1client.DockerConfigRootFS{DiffIDs: []string{"sha256:0", "sha256:1", "sha256:2", "sha256:3"}},
+	}, "")
+	if err := audit.write(dir, true, start.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, extractionManifestName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m extractionManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Source != "quay.io/openshift-release-dev/ocp-release:4.16.0-x86_64" || m.Digest != digest.FromString("manifest") || !m.Verified || m.Tool.Name != "oc" {
+		t.Errorf("unexpected extraction manifest:\n%s", data)
+	}
+	if !m.StartTime.Equal(start) || !m.CompletionTime.Equal(start.Add(time.Minute)) || !m.Created.Equal(start.Add(-time.Hour)) {
+		t.Errorf("unexpected timestamps:\n%s", data)
+	}
+	if len(m.Layers) != 2 || m.Layers[0].Digest != first.Digest || m.Layers[0].DiffID != "sha256:1" || m.Layers[1].Index != 3 || m.Layers[1].DiffID != "sha256:3" {
+		t.Errorf("unexpected layers %#v", m.Layers)
+	}
+	crd := digest.FromString("---\nkind: CustomResourceDefinition\n")
+	if len(m.Files) != 2 || m.Files[0].Name != "0000_00_cvo_crd.yaml" || m.Files[0].SHA256 != crd.Encoded() || m.Files[0].Layer != first.Digest || m.Files[1].Layer != last.Digest {
+		t.Errorf("unexpected files %#v", m.Files)
+	}
+}
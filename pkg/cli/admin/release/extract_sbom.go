@@ -0,0 +1,156 @@
+package release
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	digest "github.com/opencontainers/go-digest"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+
+	imagereference "github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/oc/pkg/version"
+)
+
+// cycloneDXSpecVersion is the version of the CycloneDX specification the SBOM follows.
+const cycloneDXSpecVersion = "1.5"
+
+// cycloneDXDocument is the CycloneDX software bill of materials of a release, listing the
+// component images of the payload, so that security scanners can ingest the composition of a
+// release without pulling every image.
+type cycloneDXDocument struct {
+	BOMFormat    string               `json:"bomFormat"`
+	SpecVersion  string               `json:"specVersion"`
+	SerialNumber string               `json:"serialNumber"`
+	Version      int                  `json:"version"`
+	Metadata     cycloneDXMetadata    `json:"metadata"`
+	Components   []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     cycloneDXTools     `json:"tools"`
+	Component cycloneDXComponent `json:"component"`
+}
+
+type cycloneDXTools struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	Type               string                       `json:"type"`
+	BOMRef             string                       `json:"bom-ref,omitempty"`
+	Name               string                       `json:"name"`
+	Version            string                       `json:"version,omitempty"`
+	PURL               string                       `json:"purl,omitempty"`
+	Hashes             []cycloneDXHash              `json:"hashes,omitempty"`
+	ExternalReferences []cycloneDXExternalReference `json:"externalReferences,omitempty"`
+	Properties         []cycloneDXProperty          `json:"properties,omitempty"`
+}
+
+type cycloneDXHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+type cycloneDXExternalReference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// newReleaseSBOM returns the SBOM of the release, with a component for each tag of its image
+// references carrying the digest of the image, the repository it is pulled from, and the source
+// repository and commit it was built from.
+func newReleaseSBOM(release *ReleaseInfo, now time.Time) (*cycloneDXDocument, error) {
+	if release.References == nil {
+		return nil, fmt.Errorf("the release image has no image references")
+	}
+	ocVersion := version.Get()
+	doc := &cycloneDXDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  cycloneDXSpecVersion,
+		SerialNumber: "urn:uuid:" + string(uuid.NewUUID()),
+		Version:      1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: now.UTC().Format(time.RFC3339),
+			Tools: cycloneDXTools{
+				Components: []cycloneDXComponent{{Type: "application", Name: "oc", Version: ocVersion.GitVersion}},
+			},
+			Component: cycloneDXComponent{
+				Type:    "container",
+				BOMRef:  release.Image,
+				Name:    release.ImageRef.Ref.Name,
+				Version: release.PreferredName(),
+				PURL:    ociPURL(release.ImageRef.Ref, release.Digest),
+				Hashes:  digestHashes(release.Digest),
+			},
+		},
+		Components: []cycloneDXComponent{},
+	}
+
+	for _, tag := range release.References.Spec.Tags {
+		if tag.From == nil || tag.From.Kind != "DockerImage" {
+			continue
+		}
+		ref, err := imagereference.Parse(tag.From.Name)
+		if err != nil {
+			return nil, fmt.Errorf("the image of %s is not a valid image reference: %v", tag.Name, err)
+		}
+		component := cycloneDXComponent{
+			Type:   "container",
+			BOMRef: tag.From.Name,
+			Name:   tag.Name,
+			PURL:   ociPURL(ref, digest.Digest(ref.ID)),
+			Hashes: digestHashes(digest.Digest(ref.ID)),
+		}
+		if location := tag.Annotations[annotationBuildSourceLocation]; len(location) > 0 {
+			component.ExternalReferences = append(component.ExternalReferences, cycloneDXExternalReference{Type: "vcs", URL: location})
+		}
+		for _, annotation := range []string{annotationBuildSourceLocation, annotationBuildSourceCommit, annotationBuildSourceRef} {
+			if value := tag.Annotations[annotation]; len(value) > 0 {
+				component.Properties = append(component.Properties, cycloneDXProperty{Name: annotation, Value: value})
+			}
+		}
+		doc.Components = append(doc.Components, component)
+	}
+	return doc, nil
+}
+
+// writeReleaseSBOM prints the SBOM of the release as JSON.
+func writeReleaseSBOM(out io.Writer, release *ReleaseInfo, now time.Time) error {
+	doc, err := newReleaseSBOM(release, now)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(append(data, '\n'))
+	return err
+}
+
+// ociPURL returns the package URL of an image, identified by its digest and its repository.
+func ociPURL(ref imagereference.DockerImageReference, dgst digest.Digest) string {
+	if len(dgst) == 0 {
+		return ""
+	}
+	// the digest is percent-encoded as the version of the package, the repository is not since it
+	// is made of characters valid in a qualifier
+	return fmt.Sprintf("pkg:oci/%s@%s?repository_url=%s", ref.Name, url.QueryEscape(dgst.String()), ref.AsRepository().String())
+}
+
+func digestHashes(dgst digest.Digest) []cycloneDXHash {
+	if dgst.Validate() != nil || dgst.Algorithm() != digest.SHA256 {
+		return nil
+	}
+	return []cycloneDXHash{{Algorithm: "SHA-256", Content: dgst.Encoded()}}
+}
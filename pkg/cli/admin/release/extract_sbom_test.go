@@ -0,0 +1,73 @@
+package release
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	digest "github.com/opencontainers/go-digest"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+)
+
+func TestWriteReleaseSBOM(t *testing.T) {
+	releaseDigest := digest.FromString("release")
+	cvoDigest := digest.FromString("cvo")
+	ref, err := imagesource.ParseReference("quay.io/openshift-release-dev/ocp-release@" + releaseDigest.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release := &ReleaseInfo{
+		Image:    "quay.io/openshift-release-dev/ocp-release@" + releaseDigest.String(),
+		ImageRef: ref,
+		Digest:   releaseDigest,
+		References: &imagev1.ImageStream{
+			ObjectMeta: metav1.ObjectMeta{Name: "4.16.0"},
+			Spec: imagev1.ImageStreamSpec{Tags: []imagev1.TagReference{
+				{
+					Name: "cluster-version-operator",
+					Annotations: map[string]string{
+						annotationBuildSourceLocation: "https://github.com/openshift/cluster-version-operator",
+						annotationBuildSourceCommit:   "0123456789abcdef",
+					},
+					From: &corev1.ObjectReference{Kind: "DockerImage", Name: "quay.io/openshift-release-dev/ocp-v4.0-art-dev@" + cvoDigest.String()},
+				},
+				{Name: "no-image"},
+			}},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	if err := writeReleaseSBOM(out, release, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	doc := &cycloneDXDocument{}
+	if err := json.Unmarshal(out.Bytes(), doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.BOMFormat != "CycloneDX" || doc.SpecVersion != cycloneDXSpecVersion || doc.Metadata.Timestamp != "2024-05-01T10:00:00Z" {
+		t.Errorf("unexpected document: %s", out.String())
+	}
+	if doc.Metadata.Component.Version != "4.16.0" || doc.Metadata.Component.Hashes[0].Content != releaseDigest.Encoded() {
+		t.Errorf("unexpected release component: %#v", doc.Metadata.Component)
+	}
+	if len(doc.Components) != 1 {
+		t.Fatalf("expected a single component, got %#v", doc.Components)
+	}
+	component := doc.Components[0]
+	expectedPURL := "pkg:oci/ocp-v4.0-art-dev@sha256%3A" + cvoDigest.Encoded() + "?repository_url=quay.io/openshift-release-dev/ocp-v4.0-art-dev"
+	if component.Name != "cluster-version-operator" || component.PURL != expectedPURL {
+		t.Errorf("unexpected component: %#v", component)
+	}
+	if len(component.ExternalReferences) != 1 || component.ExternalReferences[0].URL != "https://github.com/openshift/cluster-version-operator" {
+		t.Errorf("unexpected external references: %#v", component.ExternalReferences)
+	}
+	if len(component.Properties) != 2 || component.Properties[1] != (cycloneDXProperty{Name: annotationBuildSourceCommit, Value: "0123456789abcdef"}) {
+		t.Errorf("unexpected properties: %#v", component.Properties)
+	}
+}
@@ -0,0 +1,265 @@
+package release
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/distribution/distribution/v3"
+	digest "github.com/opencontainers/go-digest"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/manifest"
+	"github.com/openshift/library-go/pkg/verify"
+	"github.com/openshift/library-go/pkg/verify/store"
+	"github.com/openshift/library-go/pkg/verify/store/sigstore"
+	"github.com/openshift/library-go/pkg/verify/util"
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	imagemanifest "github.com/openshift/oc/pkg/cli/image/manifest"
+	"github.com/openshift/oc/pkg/cli/image/workqueue"
+)
+
+// sigstoreSignaturesDir is the directory of the signature export holding the sigstore signatures,
+// in the layout of the file:// images of oc image mirror.
+const sigstoreSignaturesDir = "sigstore"
+
+// sigstoreSignatureMediaType is the media type of the layers of cosign signatures.
+const sigstoreSignatureMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+
+// signedImage is an image of the release whose signatures are exported.
+type signedImage struct {
+	// name is the name of the image in the release, empty for the release itself
+	name   string
+	ref    imagesource.TypedImageReference
+	digest digest.Digest
+}
+
+// signatureExport counts the signatures exported.
+type signatureExport struct {
+	lock     sync.Mutex
+	atomic   int
+	sigstore int
+	errs     []error
+}
+
+func (e *signatureExport) add(atomic, sigstore int, err error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.atomic += atomic
+	e.sigstore += sigstore
+	if err != nil {
+		e.errs = append(e.errs, err)
+	}
+}
+
+// exportSignatures writes the signatures of the release and of its component images to dir, so
+// that they can be served to disconnected clusters along with the mirrored images. The atomic
+// container signatures of the stores of the release verification config map are written in the
+// layout of a signature store, as <dir>/<algo>=<hash>/signature-<number>, so that dir can be
+// served over HTTP as a store. The sigstore signatures, pushed as the sha256-<hash>.sig tags of the
+// repositories of the images, are written under <dir>/sigstore as file:// images to be mirrored
+// with oc image mirror --from-dir.
+func (o *ExtractOptions) exportSignatures(ctx context.Context, dir string, release []signedImage) error {
+	images := append([]signedImage{}, release...)
+	if o.ImageReferences != nil {
+		for _, tag := range o.ImageReferences.Spec.Tags {
+			if tag.From == nil || tag.From.Kind != "DockerImage" {
+				continue
+			}
+			ref, err := imagesource.ParseReference(tag.From.Name)
+			if err != nil {
+				return fmt.Errorf("the image of %s is not a valid image reference: %v", tag.Name, err)
+			}
+			if len(ref.Ref.ID) == 0 {
+				continue
+			}
+			images = append(images, signedImage{name: tag.Name, ref: ref, digest: digest.Digest(ref.Ref.ID)})
+		}
+	}
+
+	stores, err := releaseSignatureStores(o.Manifests, sigstore.NewCachedHTTPClientConstructor(o.SecurityOptions.ReferentialHTTPClient, nil).HTTPClient)
+	if err != nil {
+		return err
+	}
+	if len(stores) == 0 {
+		fmt.Fprintf(o.ErrOut, "warning: The release does not define signature stores, only the sigstore signatures are exported\n")
+	}
+	registryContext, err := o.SecurityOptions.Context()
+	if err != nil {
+		return err
+	}
+	fromOptions := &imagesource.Options{FileDir: o.FileDir, Insecure: o.SecurityOptions.Insecure, RegistryContext: registryContext}
+	toOptions := &imagesource.Options{FileDir: filepath.Join(dir, sigstoreSignaturesDir)}
+
+	export := &signatureExport{}
+	q := workqueue.New(8, ctx.Done())
+	q.Batch(func(w workqueue.Work) {
+		for _, image := range images {
+			image := image
+			w.Parallel(func() {
+				name := image.name
+				if len(name) == 0 {
+					name = "the release"
+				}
+				atomic, err := writeAtomicSignatures(ctx, dir, stores, image.digest)
+				if err != nil {
+					export.add(0, 0, fmt.Errorf("unable to export the signatures of %s: %v", name, err))
+					return
+				}
+				sigstoreCount, err := copySigstoreSignatures(ctx, fromOptions, toOptions, image.ref, image.digest)
+				if err != nil {
+					export.add(atomic, 0, fmt.Errorf("unable to export the sigstore signatures of %s: %v", name, err))
+					return
+				}
+				klog.V(2).Infof("Exported %d atomic and %d sigstore signatures of %s", atomic, sigstoreCount, name)
+				export.add(atomic, sigstoreCount, nil)
+			})
+		}
+	})
+
+	fmt.Fprintf(o.Out, "Exported %d atomic container signatures and %d sigstore signatures of %d images to %s\n", export.atomic, export.sigstore, len(images), dir)
+	return utilerrors.NewAggregate(export.errs)
+}
+
+// releaseSignatureStores returns the HTTP signature stores of the release verification config map
+// of the manifests, in the order of their keys.
+func releaseSignatureStores(manifests []manifest.Manifest, clientBuilder sigstore.HTTPClient) ([]store.Store, error) {
+	for _, m := range manifests {
+		configMap, err := util.ReadConfigMap(m.Raw)
+		if err != nil || configMap == nil {
+			continue
+		}
+		if _, ok := configMap.Annotations[verify.ReleaseAnnotationConfigMapVerifier]; !ok {
+			continue
+		}
+		data := configMap.Data
+		keys := make([]string, 0, len(data))
+		for key := range data {
+			if strings.HasPrefix(key, "store-") {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		var stores []store.Store
+		for _, key := range keys {
+			u, err := url.Parse(strings.TrimSpace(data[key]))
+			if err != nil {
+				return nil, fmt.Errorf("the config map %s/%s has an invalid key %q: %v", configMap.Namespace, configMap.Name, key, err)
+			}
+			if u.Scheme != "http" && u.Scheme != "https" {
+				klog.V(2).Infof("Ignoring the signature store %s which is not served over HTTP", u)
+				continue
+			}
+			stores = append(stores, &sigstore.Store{URI: u, HTTPClient: clientBuilder})
+		}
+		return stores, nil
+	}
+	return nil, nil
+}
+
+// writeAtomicSignatures writes the signatures of the digest found in the stores to dir, in the
+// layout of a signature store, and returns the number of signatures written.
+func writeAtomicSignatures(ctx context.Context, dir string, stores []store.Store, dgst digest.Digest) (int, error) {
+	var signatures [][]byte
+	// the stores usually mirror each other
+	seen := sets.NewString()
+	for _, s := range stores {
+		err := s.Signatures(ctx, "", dgst.String(), func(ctx context.Context, signature []byte, errIn error) (bool, error) {
+			if errIn != nil {
+				if !errors.Is(errIn, store.ErrNotFound) {
+					klog.V(2).Infof("Unable to retrieve a signature of %s from %s: %v", dgst, s, errIn)
+				}
+				return false, nil
+			}
+			if !seen.Has(string(signature)) {
+				seen.Insert(string(signature))
+				signatures = append(signatures, signature)
+			}
+			return false, nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	if len(signatures) == 0 {
+		return 0, nil
+	}
+
+	prefix, err := util.DigestToKeyPrefix(dgst.String(), "=")
+	if err != nil {
+		return 0, err
+	}
+	signatureDir := filepath.Join(dir, prefix)
+	if err := os.MkdirAll(signatureDir, 0755); err != nil {
+		return 0, err
+	}
+	for i, signature := range signatures {
+		if err := os.WriteFile(filepath.Join(signatureDir, "signature-"+strconv.Itoa(i+1)), signature, 0644); err != nil {
+			return 0, err
+		}
+	}
+	return len(signatures), nil
+}
+
+// copySigstoreSignatures copies the sha256-<hash>.sig tag of the repository of the image to the
+// file:// repository of the same name, and returns the number of signatures it holds.
+func copySigstoreSignatures(ctx context.Context, fromOptions, toOptions *imagesource.Options, ref imagesource.TypedImageReference, dgst digest.Digest) (int, error) {
+	from, err := fromOptions.Repository(ctx, ref)
+	if err != nil {
+		return 0, err
+	}
+	tag := strings.Replace(dgst.String(), ":", "-", 1) + ".sig"
+	desc, err := from.Tags(ctx).Get(ctx, tag)
+	if err != nil {
+		if errors.As(err, &distribution.ErrTagUnknown{}) || imagemanifest.IsImageNotFound(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("unable to get the signatures tag %s: %v", tag, err)
+	}
+	fromManifests, err := from.Manifests(ctx)
+	if err != nil {
+		return 0, err
+	}
+	signatures, err := fromManifests.Get(ctx, desc.Digest)
+	if err != nil {
+		return 0, err
+	}
+
+	toRef := ref
+	toRef.Type = imagesource.DestinationFile
+	to, err := toOptions.Repository(ctx, toRef)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, blob := range signatures.References() {
+		data, err := from.Blobs(ctx).Get(ctx, blob.Digest)
+		if err != nil {
+			return 0, fmt.Errorf("unable to get %s of the signatures tag %s: %v", blob.Digest, tag, err)
+		}
+		if _, err := to.Blobs(ctx).Put(ctx, blob.MediaType, data); err != nil {
+			return 0, err
+		}
+		if blob.MediaType == sigstoreSignatureMediaType {
+			count++
+		}
+	}
+	toManifests, err := to.Manifests(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := toManifests.Put(ctx, signatures, distribution.WithTag(tag)); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,77 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+
+	"github.com/openshift/library-go/pkg/manifest"
+)
+
+func TestExportAtomicSignatures(t *testing.T) {
+	dgst := digest.FromString("release")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sha256=" + dgst.Encoded() + "/signature-1":
+			fmt.Fprint(w, "first")
+		case "/sha256=" + dgst.Encoded() + "/signature-2":
+			fmt.Fprint(w, "second")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	manifests, err := manifest.ParseManifests(strings.NewReader(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: release-verification
+  namespace: openshift-config-managed
+  annotations:
+    release.openshift.io/verification-config-map: ""
+data:
+  store-openshift-mirror: ` + server.URL + `
+  store-local: file:///signatures
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores, err := releaseSignatureStores(manifests, func() (*http.Client, error) { return server.Client(), nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stores) != 1 {
+		t.Fatalf("expected the HTTP store only, got %v", stores)
+	}
+
+	dir := t.TempDir()
+	// the same store twice, the signatures must not be repeated
+	count, err := writeAtomicSignatures(context.Background(), dir, append(stores, stores...), dgst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 signatures, got %d", count)
+	}
+	for name, expected := range map[string]string{"signature-1": "first", "signature-2": "second"} {
+		data, err := os.ReadFile(filepath.Join(dir, "sha256="+dgst.Encoded(), name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, data)
+		}
+	}
+
+	count, err = writeAtomicSignatures(context.Background(), dir, stores, digest.FromString("unsigned"))
+	if err != nil || count != 0 {
+		t.Fatalf("expected no signature, got %d: %v", count, err)
+	}
+}
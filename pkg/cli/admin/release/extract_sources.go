@@ -0,0 +1,206 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	digest "github.com/opencontainers/go-digest"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+
+	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+	operatorclient "github.com/openshift/client-go/operator/clientset/versioned"
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/library-go/pkg/image/registryclient"
+	"github.com/openshift/oc/pkg/cli/image/strategy"
+)
+
+// loadClusterMirrors reads the ImageDigestMirrorSets and ImageContentSourcePolicies of the
+// connected cluster, whose mirrors are fallen back to when the release image of the cluster
+// cannot be fetched from its repository.
+func (o *ExtractOptions) loadClusterMirrors(f kcmdutil.Factory) error {
+	cfg, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	configClient, err := configv1client.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	operatorClient, err := operatorclient.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	idmsList, err := configClient.ConfigV1().ImageDigestMirrorSets().List(context.TODO(), metav1.ListOptions{})
+	if err != nil && !meta.IsNoMatchError(err) {
+		return fmt.Errorf("unable to read the ImageDigestMirrorSets of the cluster: %v", err)
+	}
+	if err == nil && len(idmsList.Items) > 0 {
+		o.clusterMirrors = append(o.clusterMirrors, strategy.NewIDMSListOnErrorStrategy("the ImageDigestMirrorSets of the cluster", idmsList.Items))
+	}
+	icspList, err := operatorClient.OperatorV1alpha1().ImageContentSourcePolicies().List(context.TODO(), metav1.ListOptions{})
+	if err != nil && !meta.IsNoMatchError(err) {
+		return fmt.Errorf("unable to read the ImageContentSourcePolicies of the cluster: %v", err)
+	}
+	if err == nil && len(icspList.Items) > 0 {
+		o.clusterMirrors = append(o.clusterMirrors, strategy.NewICSPListOnErrorStrategy("the ImageContentSourcePolicies of the cluster", icspList.Items))
+	}
+	return nil
+}
+
+// useAlternateSources falls back, when a request for content addressed by digest fails, to
+// the mirrors of --icsp-file, --idms-file or of the connected cluster and then to the
+// repositories of --alternate-from, and records which source served each blob.
+func (o *ExtractOptions) useAlternateSources() error {
+	var strategies []registryclient.AlternateBlobSourceStrategy
+	if len(o.ICSPFile) > 0 {
+		strategies = append(strategies, strategy.NewICSPOnErrorStrategy(o.ICSPFile))
+	}
+	if len(o.IDMSFile) > 0 {
+		strategies = append(strategies, strategy.NewIDMSOnErrorStrategy(o.IDMSFile))
+	}
+	strategies = append(strategies, o.clusterMirrors...)
+	if len(strategies) == 0 && len(o.alternateFrom) == 0 {
+		return nil
+	}
+
+	ctx, err := o.SecurityOptions.Context()
+	if err != nil {
+		return err
+	}
+	o.sources = newBlobSources()
+	ctx.Transport = o.sources.wrap(ctx.Transport)
+	ctx.InsecureTransport = o.sources.wrap(ctx.InsecureTransport)
+	if len(o.alternateFrom) > 0 || len(o.clusterMirrors) > 0 {
+		ctx.WithAlternateBlobSourceStrategy(o.sources.recordAlternates(strategy.NewOnErrorFallbackStrategy(o.alternateFrom, strategies...)))
+		// the mirrors of the files are now fallen back to by the registry context, whose
+		// strategy extracting with the files would replace
+		o.ICSPFile, o.IDMSFile = "", ""
+	}
+	return nil
+}
+
+// blobSources records the repository that served each blob and manifest fetched by digest.
+type blobSources struct {
+	lock    sync.Mutex
+	sources map[digest.Digest]string
+	// alternates are the repositories fallen back to when a repository failed
+	alternates sets.String
+}
+
+func newBlobSources() *blobSources {
+	return &blobSources{
+		sources:    make(map[digest.Digest]string),
+		alternates: sets.NewString(),
+	}
+}
+
+// source returns the repository that served the blob or manifest, if it was fetched.
+func (s *blobSources) source(dgst digest.Digest) string {
+	if s == nil {
+		return ""
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.sources[dgst]
+}
+
+// record records that the repository served the content of the registry API request path,
+// /v2/<repository>/blobs/<digest> or /v2/<repository>/manifests/<digest>.
+func (s *blobSources) record(registry, path string) {
+	path = strings.TrimPrefix(path, "/v2/")
+	var repository, ref string
+	for _, kind := range []string{"/blobs/", "/manifests/"} {
+		if i := strings.LastIndex(path, kind); i > 0 {
+			repository, ref = path[:i], path[i+len(kind):]
+			break
+		}
+	}
+	dgst, err := digest.Parse(ref)
+	if len(repository) == 0 || err != nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.sources[dgst]; !ok {
+		s.sources[dgst] = registry + "/" + repository
+	}
+}
+
+func (s *blobSources) wrap(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		return nil
+	}
+	return &blobSourcesRoundTripper{sources: s, delegate: rt}
+}
+
+type blobSourcesRoundTripper struct {
+	sources  *blobSources
+	delegate http.RoundTripper
+}
+
+func (rt *blobSourcesRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.delegate.RoundTrip(req)
+	// a redirect to the storage of the registry is served by the repository requested
+	if err == nil && req.Method == http.MethodGet && resp.StatusCode < http.StatusBadRequest {
+		rt.sources.record(req.URL.Host, req.URL.Path)
+	}
+	return resp, err
+}
+
+// recordAlternates records the alternate repositories returned by the strategy.
+func (s *blobSources) recordAlternates(delegate registryclient.AlternateBlobSourceStrategy) registryclient.AlternateBlobSourceStrategy {
+	return &alternatesRecorder{sources: s, delegate: delegate}
+}
+
+type alternatesRecorder struct {
+	sources  *blobSources
+	delegate registryclient.AlternateBlobSourceStrategy
+}
+
+func (r *alternatesRecorder) FirstRequest(ctx context.Context, locator reference.DockerImageReference) ([]reference.DockerImageReference, error) {
+	return r.delegate.FirstRequest(ctx, locator)
+}
+
+func (r *alternatesRecorder) OnFailure(ctx context.Context, locator reference.DockerImageReference) ([]reference.DockerImageReference, error) {
+	alternates, err := r.delegate.OnFailure(ctx, locator)
+	r.sources.lock.Lock()
+	defer r.sources.lock.Unlock()
+	for _, alternate := range alternates {
+		if alternate != locator.AsRepository().AsV2() {
+			r.sources.alternates.Insert(alternate.Exact())
+		}
+	}
+	return alternates, err
+}
+
+// report prints how many blobs and manifests each alternate repository served.
+func (s *blobSources) report(out io.Writer) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	served := make(map[string]int)
+	for _, source := range s.sources {
+		if s.alternates.Has(source) {
+			served[source]++
+		}
+	}
+	var repositories []string
+	for repository := range served {
+		repositories = append(repositories, repository)
+	}
+	sort.Strings(repositories)
+	for _, repository := range repositories {
+		fmt.Fprintf(out, "info: %d blobs and manifests were served by the alternate source %s\n", served[repository], repository)
+	}
+}
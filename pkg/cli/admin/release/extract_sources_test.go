@@ -0,0 +1,91 @@
+package release
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+)
+
+type statusRoundTripper map[string]int
+
+func (rt statusRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: rt[req.URL.Host], Request: req}, nil
+}
+
+type fixedAlternates []reference.DockerImageReference
+
+func (a fixedAlternates) FirstRequest(ctx context.Context, locator reference.DockerImageReference) ([]reference.DockerImageReference, error) {
+	return nil, nil
+}
+
+func (a fixedAlternates) OnFailure(ctx context.Context, locator reference.DockerImageReference) ([]reference.DockerImageReference, error) {
+	return a, nil
+}
+
+func TestBlobSources(t *testing.T) {
+	layer := digest.FromString("layer")
+	config := digest.FromString("config")
+	manifest := digest.FromString("manifest")
+	sources := newBlobSources()
+	rt := sources.wrap(statusRoundTripper{
+		"quay.io":            http.StatusServiceUnavailable,
+		"mirror.example.com": http.StatusTemporaryRedirect,
+		"backup.example.com": http.StatusOK,
+	})
+	for _, request := range []struct {
+		method string
+		url    string
+	}{
+		{http.MethodGet, "https://quay.io/v2/openshift-release-dev/ocp-release/blobs/" + layer.String()},
+		{http.MethodGet, "https://mirror.example.com/v2/ocp/release/blobs/" + layer.String()},
+		{http.MethodGet, "https://backup.example.com/v2/ocp/release/blobs/" + layer.String()},
+		{http.MethodHead, "https://backup.example.com/v2/ocp/release/blobs/" + config.String()},
+		{http.MethodGet, "https://backup.example.com/v2/ocp/release/manifests/" + manifest.String()},
+		{http.MethodGet, "https://backup.example.com/v2/ocp/release/manifests/4.16"},
+		{http.MethodGet, "https://backup.example.com/v2/"},
+	} {
+		req, err := http.NewRequest(request.method, request.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := rt.RoundTrip(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for dgst, expected := range map[digest.Digest]string{
+		layer:    "mirror.example.com/ocp/release",
+		config:   "",
+		manifest: "backup.example.com/ocp/release",
+	} {
+		if source := sources.source(dgst); source != expected {
+			t.Errorf("expected %s to be served by %q, got %q", dgst, expected, source)
+		}
+	}
+
+	alternates := sources.recordAlternates(fixedAlternates{
+		{Registry: "quay.io", Namespace: "openshift-release-dev", Name: "ocp-release"},
+		{Registry: "mirror.example.com", Namespace: "ocp", Name: "release"},
+		{Registry: "backup.example.com", Namespace: "ocp", Name: "release"},
+	})
+	if _, err := alternates.OnFailure(context.Background(), reference.DockerImageReference{Registry: "quay.io", Namespace: "openshift-release-dev", Name: "ocp-release"}); err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	sources.report(out)
+	expected := "info: 1 blobs and manifests were served by the alternate source backup.example.com/ocp/release\n" +
+		"info: 1 blobs and manifests were served by the alternate source mirror.example.com/ocp/release\n"
+	if out.String() != expected {
+		t.Errorf("expected report\n%s\ngot\n%s", expected, out.String())
+	}
+
+	var nilSources *blobSources
+	if source := nilSources.source(layer); source != "" {
+		t.Errorf("expected no source without alternate sources, got %q", source)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"k8s.io/utils/ptr"
 
@@ -596,38 +597,10 @@ func (o *ExtractOptions) extractCommand(command string) error {
 	var hashFn = sha256.New
 	var signer *openpgp.Entity
 	if willArchive && len(o.SigningKey) > 0 {
-		key, err := os.ReadFile(o.SigningKey)
-		if err != nil {
-			return err
-		}
-		keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewBuffer(key))
-		if err != nil {
+		var err error
+		if signer, err = loadSigningKey(o.SigningKey, o.Out); err != nil {
 			return err
 		}
-		for _, key := range keyring {
-			if !key.PrivateKey.CanSign() {
-				continue
-			}
-			fmt.Fprintf(o.Out, "Enter password for private key: ")
-			password, err := terminal.ReadPassword(int(syscall.Stdin))
-			fmt.Fprintln(o.Out)
-			if err != nil {
-				return err
-			}
-			if err := key.PrivateKey.Decrypt(password); err != nil {
-				return fmt.Errorf("unable to decrypt signing key: %v", err)
-			}
-			for i, subkey := range key.Subkeys {
-				if err := subkey.PrivateKey.Decrypt(password); err != nil {
-					return fmt.Errorf("unable to decrypt signing subkey %d: %v", i, err)
-				}
-			}
-			signer = key
-			break
-		}
-		if signer == nil {
-			return fmt.Errorf("no private key exists in %s capable of signing the output", o.SigningKey)
-		}
 	}
 
 	// load the release image
@@ -780,7 +753,11 @@ func (o *ExtractOptions) extractCommand(command string) error {
 		bw := bufio.NewWriterSize(w, 16*1024)
 		w = bw
 
+		// binaries which are signed again once archived have their checksum archived afterwards
+		resign := (target.InjectReleaseVersion || target.InjectReleaseImage || target.InjectReleaseArchitecture) && target.SignMachOBinary
+
 		var hash hash.Hash
+		binaryHash := hashFn()
 		closeFn := func() error { return nil }
 		if target.AsArchive {
 			text := strings.Replace(target.Readme, `\u0060`, "`", -1)
@@ -823,7 +800,29 @@ func (o *ExtractOptions) extractCommand(command string) error {
 				}
 
 				w = fw
-				closeFn = func() error { return zw.Close() }
+				closeFn = func() error {
+					files, err := binaryChecksumFiles(target.Command+".exe", binaryHash.Sum(nil), signer)
+					if err != nil {
+						return err
+					}
+					for _, file := range files {
+						zh := &zip.FileHeader{
+							Method:             zip.Deflate,
+							Name:               file.name,
+							UncompressedSize64: uint64(len(file.data)),
+							Modified:           hdr.ModTime,
+						}
+						zh.SetMode(os.FileMode(0644))
+						fw, err := zw.CreateHeader(zh)
+						if err != nil {
+							return err
+						}
+						if _, err := fw.Write(file.data); err != nil {
+							return err
+						}
+					}
+					return zw.Close()
+				}
 
 			} else {
 				klog.V(2).Infof("Writing %s as a tar.gz archive %s", hdr.Name, layer.Mapping.To)
@@ -872,12 +871,24 @@ func (o *ExtractOptions) extractCommand(command string) error {
 							return err
 						}
 					}
+					if !resign {
+						files, err := binaryChecksumFiles(target.Command, binaryHash.Sum(nil), signer)
+						if err != nil {
+							return err
+						}
+						for _, file := range files {
+							if err := writeTarFile(tw, file, hdr.ModTime); err != nil {
+								return err
+							}
+						}
+					}
 					if err := tw.Close(); err != nil {
 						return err
 					}
 					return gw.Close()
 				}
 			}
+			w = io.MultiWriter(w, binaryHash)
 		}
 
 		// copy the input to disk
@@ -925,25 +936,27 @@ func (o *ExtractOptions) extractCommand(command string) error {
 			klog.V(2).Infof("Unable to set extracted file modification time: %v", err)
 		}
 
-		if (target.InjectReleaseVersion || target.InjectReleaseImage || target.InjectReleaseArchitecture) && target.SignMachOBinary {
+		if resign {
 			if err = codesign.ResignMacho(layer.Mapping.To, target.AsArchive, target.Command, target.LinkTo); err != nil {
 				klog.Infof("Unable to resign macho binaries:  %v", err)
-			} else {
-				if target.AsArchive {
-					// Since we rewrite tarball after signing mach-o files in darwin/arm64,
-					// we should reflect the modified hash sum of this tarball to prevent mismatches.
-					h := hashFn()
-					archived, err := os.Open(layer.Mapping.To)
-					if err != nil {
-						return false, err
-					}
-					if _, err = io.Copy(h, archived); err != nil {
-						archived.Close()
-						return false, err
-					}
-					hash = h
+			}
+			if target.AsArchive {
+				if err := archiveBinaryChecksum(layer.Mapping.To, target.Command, signer); err != nil {
+					return false, fmt.Errorf("unable to archive the checksum of %s: %v", target.Command, err)
+				}
+				// Since we rewrite tarball after signing mach-o files in darwin/arm64,
+				// we should reflect the modified hash sum of this tarball to prevent mismatches.
+				h := hashFn()
+				archived, err := os.Open(layer.Mapping.To)
+				if err != nil {
+					return false, err
+				}
+				if _, err = io.Copy(h, archived); err != nil {
 					archived.Close()
+					return false, err
 				}
+				hash = h
+				archived.Close()
 			}
 		}
 
@@ -1052,6 +1065,176 @@ func (o *ExtractOptions) extractCommand(command string) error {
 	return nil
 }
 
+// loadSigningKey returns the first private key of the armored keyring at path capable of
+// signing, prompting on out for the password it is encrypted with.
+func loadSigningKey(path string, out io.Writer) (*openpgp.Entity, error) {
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewBuffer(key))
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keyring {
+		if !key.PrivateKey.CanSign() {
+			continue
+		}
+		fmt.Fprintf(out, "Enter password for private key: ")
+		password, err := terminal.ReadPassword(int(syscall.Stdin))
+		fmt.Fprintln(out)
+		if err != nil {
+			return nil, err
+		}
+		if err := key.PrivateKey.Decrypt(password); err != nil {
+			return nil, fmt.Errorf("unable to decrypt signing key: %v", err)
+		}
+		for i, subkey := range key.Subkeys {
+			if err := subkey.PrivateKey.Decrypt(password); err != nil {
+				return nil, fmt.Errorf("unable to decrypt signing subkey %d: %v", i, err)
+			}
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("no private key exists in %s capable of signing the output", path)
+}
+
+// archivedFile is a file added to the archive of a binary.
+type archivedFile struct {
+	name string
+	data []byte
+}
+
+// binaryChecksumFiles returns the checksum of an archived binary in the format of sha256sum
+// and, if signer is set, its detached signature, which are archived next to the binary so that
+// it remains verifiable once extracted from the archive.
+func binaryChecksumFiles(name string, sum []byte, signer *openpgp.Entity) ([]archivedFile, error) {
+	data := []byte(fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum), name))
+	files := []archivedFile{{name: name + ".sha256", data: data}}
+	if signer != nil {
+		buf := &bytes.Buffer{}
+		if err := openpgp.ArmoredDetachSign(buf, signer, bytes.NewReader(data), nil); err != nil {
+			return nil, fmt.Errorf("unable to sign the checksum of %s: %v", name, err)
+		}
+		files = append(files, archivedFile{name: name + ".sha256.asc", data: buf.Bytes()})
+	}
+	return files, nil
+}
+
+func writeTarFile(tw *tar.Writer, file archivedFile, modTime time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     file.name,
+		Mode:     int64(os.FileMode(0644).Perm()),
+		Size:     int64(len(file.data)),
+		Typeflag: tar.TypeReg,
+		ModTime:  modTime,
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(file.data)
+	return err
+}
+
+// archiveBinaryChecksum rewrites the tar.gz archive at path with the checksum of the binary
+// command it contains, once the binary has been modified in the archive.
+func archiveBinaryChecksum(path, command string, signer *openpgp.Entity) error {
+	readArchive := func(fn func(hdr *tar.Header, r io.Reader) error) error {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gr.Close()
+		tr := tar.NewReader(gr)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := fn(hdr, tr); err != nil {
+				return err
+			}
+		}
+	}
+
+	hash := sha256.New()
+	var modTime time.Time
+	found := false
+	if err := readArchive(func(hdr *tar.Header, r io.Reader) error {
+		if hdr.Name != command || hdr.Typeflag != tar.TypeReg {
+			return nil
+		}
+		found = true
+		modTime = hdr.ModTime
+		_, err := io.Copy(hash, r)
+		return err
+	}); err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("the archive does not contain %s", command)
+	}
+	files, err := binaryChecksumFiles(command, hash.Sum(nil), signer)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	out, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	gw, err := gzip.NewWriterLevel(out, 3)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(gw)
+	if err := readArchive(func(hdr *tar.Header, r io.Reader) error {
+		for _, file := range files {
+			if hdr.Name == file.name {
+				return nil
+			}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := io.Copy(tw, r)
+		return err
+	}); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := writeTarFile(tw, file, modTime); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+	if err := out.Chmod(info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(out.Name(), path)
+}
+
 const (
 	// releaseImageMarker is the placeholder within a binary for the release image pullspec.
 	releaseImageMarker = "!_RELEASE_IMAGE_LOCATION_\x00XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX\x00"
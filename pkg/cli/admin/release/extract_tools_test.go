@@ -1,8 +1,17 @@
 package release
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func Test_copyAndReplace(t *testing.T) {
@@ -233,3 +242,74 @@ func Test_copyAndReplace(t *testing.T) {
 		})
 	}
 }
+
+func Test_archiveBinaryChecksum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "openshift-client-mac.tar.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for _, file := range []archivedFile{
+		{name: "README.md", data: []byte("readme")},
+		{name: "oc", data: []byte("signed binary")},
+		// a stale checksum of the binary before it was signed
+		{name: "oc.sha256", data: []byte("0000  oc\n")},
+	} {
+		if err := writeTarFile(tw, file, time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: "kubectl", Typeflag: tar.TypeLink, Linkname: "oc"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := archiveBinaryChecksum(path, "oc", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err = os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+	var names []string
+	var checksum []byte
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+		if hdr.Name == "oc.sha256" {
+			if checksum, err = io.ReadAll(tr); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if expected := []string{"README.md", "oc", "kubectl", "oc.sha256"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected entries %v, got %v", expected, names)
+	}
+	sum := sha256.Sum256([]byte("signed binary"))
+	if expected := hex.EncodeToString(sum[:]) + "  oc\n"; string(checksum) != expected {
+		t.Errorf("expected checksum %q, got %q", expected, checksum)
+	}
+}
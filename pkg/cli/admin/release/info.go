@@ -108,6 +108,10 @@ func NewInfo(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Comm
 			it is a slow operation that requires sufficient disk space. By default, the image containing the
 			machine-os component is targeted for RPM queries. Other images can be targeted with --rpmdb-image.
 
+			The --manifest-diff flag lists the manifests of the payload that are added, removed or
+			changed between the two release arguments, grouped by API group and kind, to review what
+			the cluster version operator will change during an update between them.
+
 			If the specified image supports multiple operating systems, the image that matches the
 			current operating system will be chosen. Otherwise you must pass --filter-by-os to
 			select the desired image.
@@ -122,6 +126,9 @@ func NewInfo(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Comm
 			# Show the source code difference between two releases
 			oc adm release info 4.11.0 4.11.2 --commits
 
+			# Show the manifests an update between two releases adds, removes or changes
+			oc adm release info 4.11.0 4.11.2 --manifest-diff
+
 			# Show where the images referenced by the release are located
 			oc adm release info quay.io/openshift-release-dev/ocp-release:4.11.2 --pullspecs
 
@@ -161,6 +168,7 @@ func NewInfo(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Comm
 	flags.StringVar(&o.RpmdbCacheDir, "rpmdb-cache", o.RpmdbCacheDir, "Cache rpmdb content in this directory.")
 	flags.BoolVar(&o.RpmdbList, "rpmdb", o.RpmdbList, "List RPM packages in image.")
 	flags.BoolVar(&o.RpmdbDiff, "rpmdb-diff", o.RpmdbDiff, "Generate RPM package diff.")
+	flags.BoolVar(&o.ManifestDiff, "manifest-diff", o.ManifestDiff, "List the payload manifests added, removed or changed between the two releases.")
 	flags.StringVar(&o.RpmdbImage, "rpmdb-image", "", "The image to use for RPM queries.")
 	flags.StringVar(&o.BugsDir, "bugs", o.BugsDir, "Generate bug listings from the changelogs in the git repositories extracted to this path.")
 	flags.BoolVar(&o.IncludeImages, "include-images", o.IncludeImages, "When displaying JSON output of a release output the images the release references.")
@@ -196,6 +204,7 @@ type InfoOptions struct {
 	RpmdbImage    string
 	BugsDir       string
 	SkipBugCheck  bool
+	ManifestDiff  bool
 
 	ParallelOptions imagemanifest.ParallelOptions
 	SecurityOptions imagemanifest.SecurityOptions
@@ -445,9 +454,9 @@ func (o *InfoOptions) Validate() error {
 	if o.SkipBugCheck && o.Output != "name" && o.Output != "json" {
 		return fmt.Errorf("--skip-bug-check requires --output to be set to 'name' or 'json'")
 	}
-	if len(o.ChangelogDir) > 0 || len(o.BugsDir) > 0 || o.RpmdbDiff {
+	if len(o.ChangelogDir) > 0 || len(o.BugsDir) > 0 || o.RpmdbDiff || o.ManifestDiff {
 		if len(o.From) == 0 {
-			return fmt.Errorf("--changelog/--bugs/--rpmdb-diff require --changes-from")
+			return fmt.Errorf("--changelog/--bugs/--rpmdb-diff/--manifest-diff require --changes-from")
 		}
 	}
 	if o.RpmdbList && o.RpmdbDiff {
@@ -458,9 +467,9 @@ func (o *InfoOptions) Validate() error {
 			return fmt.Errorf("--rpmdb/--rpmdb-diff require --rpmdb-cache")
 		}
 	}
-	exclusiveOps := boolToInt(len(o.ChangelogDir) > 0) + boolToInt(len(o.BugsDir) > 0) + boolToInt(o.RpmdbDiff)
+	exclusiveOps := boolToInt(len(o.ChangelogDir) > 0) + boolToInt(len(o.BugsDir) > 0) + boolToInt(o.RpmdbDiff) + boolToInt(o.ManifestDiff)
 	if exclusiveOps > 1 {
-		return fmt.Errorf("--changelog/--bugs/--rpmdb-diff are mutually exclusive")
+		return fmt.Errorf("--changelog/--bugs/--rpmdb-diff/--manifest-diff are mutually exclusive")
 	}
 	switch {
 	case len(o.BugsDir) > 0:
@@ -481,6 +490,12 @@ func (o *InfoOptions) Validate() error {
 		default:
 			return fmt.Errorf("--output only supports 'json' for --rpmdb/--rpmdb-diff")
 		}
+	case o.ManifestDiff:
+		switch o.Output {
+		case "", "json":
+		default:
+			return fmt.Errorf("--output only supports 'json' for --manifest-diff")
+		}
 	default:
 		output := strings.SplitN(o.Output, "=", 2)[0]
 		if len(output) > 0 && !stringArrContains(o.allowedFormats(), output) {
@@ -549,6 +564,9 @@ func (o *InfoOptions) Run() error {
 		if o.RpmdbDiff {
 			return o.describeRpmDiff(release, diff, o.RpmdbCacheDir, o.Output, o.RpmdbImage)
 		}
+		if o.ManifestDiff {
+			return describeManifestDiff(o.Out, calculateManifestDiff(baseRelease, release), o.Output)
+		}
 		return describeReleaseDiff(o.Out, diff, o.ShowCommit, o.Output)
 	}
 
@@ -0,0 +1,206 @@
+package release
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/openshift/library-go/pkg/manifest"
+)
+
+// ManifestDiff categorizes the manifests of the payload that differ between two releases.
+type ManifestDiff struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+
+	Added   []ManifestChange `json:"added"`
+	Removed []ManifestChange `json:"removed"`
+	Changed []ManifestChange `json:"changed"`
+
+	// Warnings are the payload files that could not be parsed and were left out of the diff.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ManifestChange identifies a manifest of the payload.
+type ManifestChange struct {
+	Group     string `json:"group"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Include are the cluster profiles of the include.release.openshift.io annotations of the
+	// manifest, since the payload carries a manifest of the same object for each profile.
+	Include string `json:"include,omitempty"`
+	// File is the payload file the manifest is in, in the newest release that has it.
+	File string `json:"file"`
+}
+
+// GroupKind returns the API group and kind of the manifest, or only the kind for the core group.
+func (c ManifestChange) GroupKind() string {
+	if len(c.Group) == 0 {
+		return c.Kind
+	}
+	return c.Group + "/" + c.Kind
+}
+
+type payloadManifest struct {
+	ManifestChange
+	manifest manifest.Manifest
+}
+
+const includeAnnotationPrefix = "include.release.openshift.io/"
+
+// manifestInclude returns the sorted cluster profiles the manifest is included in.
+func manifestInclude(m manifest.Manifest) string {
+	var profiles []string
+	for annotation := range m.Obj.GetAnnotations() {
+		if strings.HasPrefix(annotation, includeAnnotationPrefix) {
+			profiles = append(profiles, strings.TrimPrefix(annotation, includeAnnotationPrefix))
+		}
+	}
+	sort.Strings(profiles)
+	return strings.Join(profiles, ",")
+}
+
+// payloadManifests indexes the manifests of a release by group, kind, namespace, name and
+// cluster profiles. The API version and the file are left out so that a manifest moving to a
+// new version or file is reported as changed rather than removed and added, unless the release
+// has several manifests of the same object for the same profiles, which are told apart by their
+// file. The files are read in order so that the index does not depend on the order of the map,
+// and the files that cannot be parsed are returned as warnings.
+func payloadManifests(release *ReleaseInfo) (map[ManifestChange]payloadManifest, []string) {
+	files := make([]string, 0, len(release.ManifestFiles))
+	for file := range release.ManifestFiles {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var warnings []string
+	manifests := make(map[ManifestChange]payloadManifest)
+	for _, file := range files {
+		ms, err := manifest.ParseManifests(bytes.NewReader(release.ManifestFiles[file]))
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("unable to parse manifest %s of %s: %v", file, release.PreferredName(), err))
+			continue
+		}
+		for _, m := range ms {
+			key := ManifestChange{Group: m.GVK.Group, Kind: m.GVK.Kind, Namespace: m.Obj.GetNamespace(), Name: m.Obj.GetName(), Include: manifestInclude(m)}
+			if _, ok := manifests[key]; ok {
+				key.File = file
+			}
+			change := key
+			change.File = file
+			manifests[key] = payloadManifest{ManifestChange: change, manifest: m}
+		}
+	}
+	return manifests, warnings
+}
+
+// calculateManifestDiff returns the manifests the cluster version operator adds, removes
+// or changes when updating from one release to the other.
+func calculateManifestDiff(from, to *ReleaseInfo) *ManifestDiff {
+	fromManifests, fromWarnings := payloadManifests(from)
+	toManifests, toWarnings := payloadManifests(to)
+	diff := &ManifestDiff{From: from.PreferredName(), To: to.PreferredName(), Warnings: append(fromWarnings, toWarnings...)}
+	for key, m := range toManifests {
+		old, ok := fromManifests[key]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, m.ManifestChange)
+		case !reflect.DeepEqual(old.manifest.Obj.Object, m.manifest.Obj.Object):
+			diff.Changed = append(diff.Changed, m.ManifestChange)
+		}
+	}
+	for key, m := range fromManifests {
+		if _, ok := toManifests[key]; !ok {
+			diff.Removed = append(diff.Removed, m.ManifestChange)
+		}
+	}
+	for _, changes := range [][]ManifestChange{diff.Added, diff.Removed, diff.Changed} {
+		sortManifestChanges(changes)
+	}
+	return diff
+}
+
+func sortManifestChanges(changes []ManifestChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Include != b.Include {
+			return a.Include < b.Include
+		}
+		return a.File < b.File
+	})
+}
+
+func describeManifestDiff(out io.Writer, diff *ManifestDiff, outputMode string) error {
+	switch outputMode {
+	case "json":
+		data, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	case "":
+		// print human readable output
+	default:
+		return fmt.Errorf("unrecognized output mode: %s", outputMode)
+	}
+	for _, warning := range diff.Warnings {
+		fmt.Fprintf(out, "warning: %s\n", warning)
+	}
+	if len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Changed) == 0 {
+		fmt.Fprintf(out, "The manifests of %s and %s are identical\n", diff.From, diff.To)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintf(w, "Manifests changed from %s to %s:\n", diff.From, diff.To)
+	for _, section := range []struct {
+		title   string
+		changes []ManifestChange
+	}{
+		{title: "Added", changes: diff.Added},
+		{title: "Removed", changes: diff.Removed},
+		{title: "Changed", changes: diff.Changed},
+	} {
+		if len(section.changes) == 0 {
+			continue
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "%s (%d):\n", section.title, len(section.changes))
+		groupKind := ""
+		for _, change := range section.changes {
+			if gk := change.GroupKind(); gk != groupKind {
+				groupKind = gk
+				fmt.Fprintf(w, "  %s\n", groupKind)
+			}
+			name := change.Name
+			if len(change.Namespace) > 0 {
+				name = change.Namespace + "/" + change.Name
+			}
+			if len(change.Include) > 0 {
+				name += " (" + change.Include + ")"
+			}
+			fmt.Fprintf(w, "    %s\t%s\n", name, change.File)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package release
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCalculateManifestDiff(t *testing.T) {
+	from := &ReleaseInfo{
+		Image:    "quay.io/openshift-release-dev/ocp-release:4.16.0",
+		Metadata: &CincinnatiMetadata{Version: "4.16.0"},
+		ManifestFiles: map[string][]byte{
+			"0000_50_operator_deployment.yaml": []byte("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: operator\n  namespace: openshift-operator\nspec:\n  replicas: 1\n"),
+			"0000_50_operator_config.yaml":     []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  namespace: openshift-operator\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: legacy\n  namespace: openshift-operator\n"),
+		},
+	}
+	to := &ReleaseInfo{
+		Image:    "quay.io/openshift-release-dev/ocp-release:4.16.1",
+		Metadata: &CincinnatiMetadata{Version: "4.16.1"},
+		ManifestFiles: map[string][]byte{
+			"0000_50_operator_deployment.yaml": []byte("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: operator\n  namespace: openshift-operator\nspec:\n  replicas: 2\n"),
+			// moving a manifest to another file does not change it
+			"0000_50_operator_configmap.yaml": []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  namespace: openshift-operator\n"),
+			"0000_50_operator_crd.yaml":       []byte("apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: operators.example.com\n"),
+		},
+	}
+
+	diff := calculateManifestDiff(from, to)
+	if expected := []ManifestChange{{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition", Name: "operators.example.com", File: "0000_50_operator_crd.yaml"}}; !reflect.DeepEqual(diff.Added, expected) {
+		t.Errorf("expected added %v, got %v", expected, diff.Added)
+	}
+	if expected := []ManifestChange{{Kind: "ConfigMap", Namespace: "openshift-operator", Name: "legacy", File: "0000_50_operator_config.yaml"}}; !reflect.DeepEqual(diff.Removed, expected) {
+		t.Errorf("expected removed %v, got %v", expected, diff.Removed)
+	}
+	if expected := []ManifestChange{{Group: "apps", Kind: "Deployment", Namespace: "openshift-operator", Name: "operator", File: "0000_50_operator_deployment.yaml"}}; !reflect.DeepEqual(diff.Changed, expected) {
+		t.Errorf("expected changed %v, got %v", expected, diff.Changed)
+	}
+
+	out := &bytes.Buffer{}
+	if err := describeManifestDiff(out, diff, ""); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"Added (1):\n  apiextensions.k8s.io/CustomResourceDefinition\n", "Removed (1):\n  ConfigMap\n    openshift-operator/legacy", "Changed (1):\n  apps/Deployment\n"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected the output to contain %q:\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestCalculateManifestDiffProfiles(t *testing.T) {
+	configMap := func(profile, data string) string {
+		return "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  namespace: openshift-operator\n  annotations:\n    include.release.openshift.io/" + profile + ": \"true\"\ndata:\n  key: " + data + "\n"
+	}
+	from := &ReleaseInfo{
+		Image:    "quay.io/openshift-release-dev/ocp-release:4.16.0",
+		Metadata: &CincinnatiMetadata{Version: "4.16.0"},
+		ManifestFiles: map[string][]byte{
+			"0000_50_operator_config.yaml":            []byte(configMap("self-managed-high-availability", "a")),
+			"0000_50_operator_config-hypershift.yaml": []byte(configMap("ibm-cloud-managed", "a")),
+			"0000_50_operator_broken.yaml":            []byte("kind: [ConfigMap\n"),
+		},
+	}
+	to := &ReleaseInfo{
+		Image:    "quay.io/openshift-release-dev/ocp-release:4.16.1",
+		Metadata: &CincinnatiMetadata{Version: "4.16.1"},
+		ManifestFiles: map[string][]byte{
+			"0000_50_operator_config.yaml":            []byte(configMap("self-managed-high-availability", "a")),
+			"0000_50_operator_config-hypershift.yaml": []byte(configMap("ibm-cloud-managed", "b")),
+		},
+	}
+
+	for i := 0; i < 10; i++ {
+		diff := calculateManifestDiff(from, to)
+		if len(diff.Added) > 0 || len(diff.Removed) > 0 {
+			t.Fatalf("expected no added or removed manifests, got %v and %v", diff.Added, diff.Removed)
+		}
+		expected := []ManifestChange{{Kind: "ConfigMap", Namespace: "openshift-operator", Name: "config", Include: "ibm-cloud-managed", File: "0000_50_operator_config-hypershift.yaml"}}
+		if !reflect.DeepEqual(diff.Changed, expected) {
+			t.Fatalf("expected changed %v, got %v", expected, diff.Changed)
+		}
+		if len(diff.Warnings) != 1 || !strings.Contains(diff.Warnings[0], "unable to parse manifest 0000_50_operator_broken.yaml of 4.16.0") {
+			t.Fatalf("expected a warning about the broken manifest, got %v", diff.Warnings)
+		}
+	}
+}
@@ -17,6 +17,7 @@ import (
 	digest "github.com/opencontainers/go-digest"
 	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
 	"github.com/spf13/cobra"
+	"golang.org/x/crypto/openpgp"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -127,6 +128,14 @@ func NewMirror(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Co
 			The --overwrite option only applies when --apply-release-image-signature is specified
 			and indicates to update an exisiting config map if one is found. A config map written to a
 			directory will always replace onethat already exists.
+
+			When mirroring to disk, --transfer-manifest writes a transfer-manifest.json file next to
+			the mirrored content listing the source of every image and the sha256 sum and size of
+			every file written, and --transfer-manifest-signing-key signs it with a GPG key into
+			transfer-manifest.json.asc. The manifest can be reviewed and approved before the content
+			is moved into another environment, where --verify-transfer-manifest checks that the content
+			of --from-dir is exactly the approved one before uploading it, and that the manifest is
+			signed by a key of --transfer-manifest-keyring if it is set.
 		`),
 		Example: templates.Examples(`
 			# Perform a dry run showing what would be mirrored, including the mirror objects
@@ -144,6 +153,14 @@ func NewMirror(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Co
 			oc adm release mirror --from file://openshift/release --to myregistry.com/openshift/release \
 				--release-image-signature-to-dir /tmp/releases
 
+			# Mirror a release to a directory with a signed transfer manifest for review
+			oc adm release mirror 4.11.0 --to-dir /tmp/releases --transfer-manifest-signing-key key.asc
+
+			# Upload the reviewed release only if it matches the transfer manifest signed by a trusted key
+			oc adm release mirror --from file://openshift/release --from-dir /tmp/releases \
+				--to myregistry.com/openshift/release \
+				--verify-transfer-manifest /tmp/releases/transfer-manifest.json --transfer-manifest-keyring trusted.gpg
+
 			# Mirror the 4.11.0 release to repository registry.example.com and apply signatures to connected cluster
 			oc adm release mirror --from=quay.io/openshift-release-dev/ocp-release:4.11.0-x86_64 \
 				--to=registry.example.com/your/repository --apply-release-image-signature
@@ -173,6 +190,10 @@ func NewMirror(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Co
 	flags.BoolVar(&o.SkipRelease, "skip-release-image", o.SkipRelease, "Do not push the release image.")
 	flags.StringVar(&o.ToRelease, "to-release-image", o.ToRelease, "Specify an alternate locations for the release image instead as tag 'release' in --to.")
 	flags.BoolVar(&o.Overwrite, "overwrite", o.Overwrite, "Used with --apply-release-image-signature to update an existing signature configmap.")
+	flags.BoolVar(&o.TransferManifest, "transfer-manifest", o.TransferManifest, "When mirroring to disk, write a transfer-manifest.json describing the mirrored content.")
+	flags.StringVar(&o.TransferManifestSigningKey, "transfer-manifest-signing-key", o.TransferManifestSigningKey, "Sign the transfer manifest with this GPG key into transfer-manifest.json.asc. Implies --transfer-manifest. The key is assumed to be encrypted.")
+	flags.StringVar(&o.VerifyTransferManifest, "verify-transfer-manifest", o.VerifyTransferManifest, "Before mirroring from --from-dir, verify that its content is the one described by this transfer manifest.")
+	flags.StringVar(&o.TransferManifestKeyring, "transfer-manifest-keyring", o.TransferManifestKeyring, "Require the transfer manifest of --verify-transfer-manifest to be signed by a key of this armored GPG keyring.")
 	return cmd
 }
 
@@ -201,6 +222,15 @@ type MirrorOptions struct {
 	ReleaseImageSignatureToDir string
 	Overwrite                  bool
 
+	// TransferManifest writes a description of the content mirrored to disk, signed by
+	// TransferManifestSigningKey if it is set.
+	TransferManifest           bool
+	TransferManifestSigningKey string
+	// VerifyTransferManifest is the transfer manifest the content of FromDir must match,
+	// which must be signed by a key of TransferManifestKeyring if it is set.
+	VerifyTransferManifest  string
+	TransferManifestKeyring string
+
 	DryRun                       bool
 	PrintImageSourceInstructions string
 
@@ -312,6 +342,13 @@ func (o *MirrorOptions) Validate() error {
 	if o.Overwrite && !o.ApplyReleaseImageSignature {
 		return fmt.Errorf("--overwite is only valid when --apply-release-image-signature is specified")
 	}
+
+	if len(o.TransferManifestSigningKey) > 0 {
+		o.TransferManifest = true
+	}
+	if len(o.TransferManifestKeyring) > 0 && len(o.VerifyTransferManifest) == 0 {
+		return fmt.Errorf("--transfer-manifest-keyring is only valid when --verify-transfer-manifest is specified")
+	}
 	return nil
 }
 
@@ -488,6 +525,31 @@ func (o *MirrorOptions) Run(ctx context.Context) error {
 		return fmt.Errorf("when mirroring to multiple repositories, use the new release command with --from-release and --mirror")
 	}
 
+	if o.TransferManifest && !toDisk {
+		return fmt.Errorf("--transfer-manifest is only valid when mirroring to disk")
+	}
+	var transferSigner *openpgp.Entity
+	if len(o.TransferManifestSigningKey) > 0 {
+		var err error
+		if transferSigner, err = loadSigningKey(o.TransferManifestSigningKey, o.Out); err != nil {
+			return err
+		}
+	}
+	if len(o.VerifyTransferManifest) > 0 {
+		dir := o.FromDir
+		if len(dir) == 0 {
+			dir = "."
+		}
+		m, err := verifyTransferManifest(o.VerifyTransferManifest, o.TransferManifestKeyring, dir)
+		if err != nil {
+			return err
+		}
+		if len(o.TransferManifestKeyring) == 0 {
+			fmt.Fprintf(o.ErrOut, "warning: The signature of the transfer manifest was not verified, use --transfer-manifest-keyring to require it\n")
+		}
+		fmt.Fprintf(o.ErrOut, "info: Verified %d files of %s against the transfer manifest\n", len(m.Files), m.Release)
+	}
+
 	var releaseDigest string
 	var manifests []manifest.Manifest
 	is := o.ImageStream
@@ -812,6 +874,21 @@ func (o *MirrorOptions) Run(ctx context.Context) error {
 		return err
 	}
 
+	if o.TransferManifest && !o.DryRun {
+		dir := o.ToDir
+		if len(dir) == 0 {
+			dir = "."
+		}
+		m, err := newTransferManifest(dir, o.From, releaseDigest, mappings, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := writeTransferManifest(dir, m, transferSigner); err != nil {
+			return err
+		}
+		fmt.Fprintf(o.ErrOut, "info: Wrote the transfer manifest of %d files to %s\n", len(m.Files), filepath.Join(dir, transferManifestName))
+	}
+
 	to := o.ToRelease
 	if len(to) == 0 {
 		to = targetFn("").Ref.Exact()
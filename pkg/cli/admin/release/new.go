@@ -25,6 +25,7 @@ import (
 	"github.com/ghodss/yaml"
 	digest "github.com/opencontainers/go-digest"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"k8s.io/klog/v2"
 
 	corev1 "k8s.io/api/core/v1"
@@ -94,6 +95,21 @@ func NewRelease(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.C
 
 			will override the default cluster-version-operator image with one pulled from
 			registry.example.com.
+
+			With --lint, the manifests of the release are checked before the release is created,
+			so that mistakes fail the build of the release rather than the update of a cluster:
+			manifest names must set a valid run level and objects must be applied after their
+			namespace and custom resource definition, capabilities and feature sets must be known
+			and be set on the manifests depending on gated ones, and an object must not be defined
+			twice for the same cluster profile and feature set. A rule is disabled with
+			--lint-skip=NAME.
+
+			With --provenance, a SLSA provenance attestation of the release is pushed along with
+			the release image, as an OCI artifact whose subject is the release image. It records the
+			digests of the input images, the builder set by --provenance-builder-id, and the
+			arguments and flags of the command, so that custom releases can satisfy supply chain
+			policies. Registries implementing the OCI referrers API list it among the referrers of
+			the release image.
 		`),
 		Example: templates.Examples(`
 			# Create a release from the latest origin images and push to a DockerHub repository
@@ -107,6 +123,15 @@ func NewRelease(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.C
 			oc adm release new --from-release registry.ci.openshift.org/origin/release:v4.11 \
 				cli=docker.io/mycompany/cli:latest --to-image docker.io/mycompany/myrepo:latest
 
+			# Check the manifests of a new release before pushing it, except for duplicate objects
+			oc adm release new --from-release registry.ci.openshift.org/origin/release:v4.11 \
+				--lint --lint-skip=duplicates --to-image docker.io/mycompany/myrepo:latest
+
+			# Create a new release and attach its provenance, built by a CI job
+			oc adm release new --from-release registry.ci.openshift.org/origin/release:v4.11 \
+				--provenance --provenance-builder-id=https://ci.example.com/jobs/release \
+				--to-image docker.io/mycompany/myrepo:latest
+
 			# Run a verification pass to ensure the release can be reproduced
 			oc adm release new --from-release registry.ci.openshift.org/origin/release:v4.11
 		`),
@@ -141,6 +166,8 @@ func NewRelease(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.C
 	// validation
 	flags.BoolVar(&o.AllowMissingImages, "allow-missing-images", o.AllowMissingImages, "Ignore errors when an operator references a release image that is not included.")
 	flags.BoolVar(&o.SkipManifestCheck, "skip-manifest-check", o.SkipManifestCheck, "Ignore errors when an operator includes a yaml/yml/json file that is not parseable.")
+	flags.BoolVar(&o.Lint, "lint", o.Lint, "Check the manifests of the release for mistakes the cluster version operator would fail on, and fail before the release is created.")
+	flags.StringSliceVar(&o.LintSkip, "lint-skip", o.LintSkip, "A list of rules --lint should not check: runlevels, capabilities, duplicates, or feature-sets.")
 
 	flags.StringSliceVar(&o.Exclude, "exclude", o.Exclude, "A list of image names or tags to exclude. It is applied after all inputs. Comma separated or individual arguments.")
 	flags.StringSliceVar(&o.AlwaysInclude, "include", o.AlwaysInclude, "A list of image tags that should not be pruned. Excluding a tag takes precedence. Comma separated or individual arguments.")
@@ -154,6 +181,8 @@ func NewRelease(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.C
 	flags.StringVar(&o.ToImageBase, "to-image-base", o.ToImageBase, "If specified, the image to add the release layer on top of.")
 	flags.StringVar(&o.ToImageBaseTag, "to-image-base-tag", o.ToImageBaseTag, "If specified, the image tag in the input to add the release layer on top of. Defaults to cluster-version-operator.")
 	flags.StringVar(&o.ToSignature, "to-signature", o.ToSignature, "If specified, output a message that can be signed that describes this release. Requires --to-image.")
+	flags.BoolVar(&o.Provenance, "provenance", o.Provenance, "Attach a SLSA provenance attestation of the release to the pushed release image, as an OCI referrer. Requires --to-image.")
+	flags.StringVar(&o.ProvenanceBuilderID, "provenance-builder-id", o.ProvenanceBuilderID, "The identity of the builder recorded in the provenance of the release, such as the URI of the CI job. Defaults to "+defaultProvenanceBuilderID+".")
 
 	// misc
 	flags.StringVarP(&o.Output, "output", "o", o.Output, "Output the mapping definition in this format.")
@@ -203,21 +232,34 @@ type NewOptions struct {
 	ToImageBaseTag string
 	ToSignature    string
 
+	Provenance          bool
+	ProvenanceBuilderID string
+
 	Mirror string
 
 	AllowMissingImages bool
 	SkipManifestCheck  bool
 
+	Lint     bool
+	LintSkip []string
+	// LintRules are the rules the manifests of the release are checked with, the default rules but
+	// the skipped ones when --lint is set.
+	LintRules []PayloadLintRule
+
 	Mappings []Mapping
 
 	ImageClient imageclient.Interface
 
 	VerifyOutputFn func(dgst digest.Digest) error
 
+	// provenanceParameters are the arguments and flags of the invocation recorded in the provenance
+	provenanceParameters releaseBuildParameters
+	startedOn            time.Time
+
 	cleanupFns []func()
 }
 
-func (o *NewOptions) Complete(f kcmdutil.Factory, _ *cobra.Command, args []string) error {
+func (o *NewOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
 	overlap := make(map[string]string)
 	var mappings []Mapping
 	for _, filename := range o.MappingFilenames {
@@ -252,6 +294,22 @@ func (o *NewOptions) Complete(f kcmdutil.Factory, _ *cobra.Command, args []strin
 			o.Namespace = namespace
 		}
 	}
+
+	if o.Provenance {
+		flags := map[string]string{}
+		cmd.Flags().Visit(func(flag *pflag.Flag) {
+			flags[flag.Name] = flag.Value.String()
+		})
+		o.provenanceParameters = newReleaseBuildParameters(args, flags)
+	}
+
+	if o.Lint {
+		rules, err := payloadLintRules(o.LintSkip)
+		if err != nil {
+			return err
+		}
+		o.LintRules = rules
+	}
 	return nil
 }
 
@@ -277,9 +335,18 @@ func (o *NewOptions) Validate() error {
 			return fmt.Errorf("must specify image mappings when no other source is defined")
 		}
 	}
+	if len(o.LintSkip) > 0 && !o.Lint {
+		return fmt.Errorf("--lint-skip requires --lint")
+	}
 	if len(o.ToSignature) > 0 && len(o.ToImage) == 0 {
 		return fmt.Errorf("--to-signature requires --to-image")
 	}
+	if o.Provenance && len(o.ToImage) == 0 {
+		return fmt.Errorf("--provenance requires --to-image")
+	}
+	if len(o.ProvenanceBuilderID) > 0 && !o.Provenance {
+		return fmt.Errorf("--provenance-builder-id requires --provenance")
+	}
 	if len(o.Mirror) > 0 && o.ReferenceMode != "" && o.ReferenceMode != "public" {
 		return fmt.Errorf("--reference-mode must be public or empty when using --mirror")
 	}
@@ -352,6 +419,7 @@ func (o *NewOptions) Run(ctx context.Context) error {
 	var ordered []string
 	var is *imageapi.ImageStream
 	now := time.Now().UTC().Truncate(time.Second)
+	o.startedOn = now
 
 	switch {
 	case len(o.FromReleaseImage) > 0:
@@ -765,6 +833,12 @@ func (o *NewOptions) Run(ctx context.Context) error {
 	// use a stable ordering for operators
 	sort.Strings(ordered)
 
+	if len(o.LintRules) > 0 {
+		if err := lintPayload(ordered, metadata, o.LintRules, o.SkipManifestCheck); err != nil {
+			return err
+		}
+	}
+
 	var operators []string
 	pr, pw := io.Pipe()
 	go func() {
@@ -778,7 +852,7 @@ func (o *NewOptions) Run(ctx context.Context) error {
 		return fmt.Errorf("unable to create a release: %v", err)
 	}
 
-	if err := o.write(br, is, now); err != nil {
+	if err := o.write(ctx, br, is, now); err != nil {
 		return err
 	}
 
@@ -1120,7 +1194,7 @@ func (o *NewOptions) mirrorImages(ctx context.Context, is *imageapi.ImageStream)
 	return nil
 }
 
-func (o *NewOptions) write(r io.Reader, is *imageapi.ImageStream, now time.Time) error {
+func (o *NewOptions) write(ctx context.Context, r io.Reader, is *imageapi.ImageStream, now time.Time) error {
 	var exitErr error
 	switch {
 	case len(o.ToDir) > 0:
@@ -1216,6 +1290,7 @@ func (o *NewOptions) write(r io.Reader, is *imageapi.ImageStream, now time.Time)
 			}
 		}
 
+		var baseDigest digest.Digest
 		verifier := imagemanifest.NewVerifier()
 		options := imageappend.NewAppendImageOptions(genericiooptions.IOStreams{Out: io.Discard, ErrOut: o.ErrOut})
 		options.ParallelOptions = o.ParallelOptions
@@ -1225,6 +1300,7 @@ func (o *NewOptions) write(r io.Reader, is *imageapi.ImageStream, now time.Time)
 		options.KeepManifestList = o.KeepManifestList
 		options.ConfigurationCallback = func(dgst, contentDigest digest.Digest, config *dockerv1client.DockerImageConfig) error {
 			verifier.Verify(dgst, contentDigest)
+			baseDigest = dgst
 			// reset any base image info
 			if len(config.OS) == 0 {
 				config.OS = "linux"
@@ -1289,6 +1365,23 @@ func (o *NewOptions) write(r io.Reader, is *imageapi.ImageStream, now time.Time)
 			klog.V(2).Infof("Signature for output:\n%s", string(msg))
 		}
 
+		if o.Provenance {
+			statement := newReleaseProvenance(toRef, options.ToDigest, is, toImageBase, baseDigest, o.ProvenanceBuilderID, o.provenanceParameters, o.startedOn, time.Now())
+			if o.DryRun {
+				data, err := json.MarshalIndent(statement, "", "  ")
+				if err != nil {
+					return err
+				}
+				klog.V(2).Infof("Provenance for output:\n%s", string(data))
+			} else {
+				provenanceDigest, err := o.pushProvenance(ctx, toRef, options.ToDigest, statement)
+				if err != nil {
+					return fmt.Errorf("unable to attach the provenance to %s: %v", toRefWithDigest.Exact(), err)
+				}
+				fmt.Fprintf(o.ErrOut, "info: Attached the provenance %s to %s\n", provenanceDigest, toRefWithDigest.Exact())
+			}
+		}
+
 		fmt.Fprintf(o.Out, "%s %s %s\n", options.ToDigest.String(), is.Name, is.CreationTimestamp.Format(time.RFC3339))
 	}
 	return exitErr
@@ -0,0 +1,355 @@
+package release
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/manifest"
+)
+
+const (
+	featureSetAnnotation     = "release.openshift.io/feature-set"
+	profileAnnotationPrefix  = "include.release.openshift.io/"
+	defaultFeatureSetValue   = "Default"
+	defaultPayloadRunLevel   = "50"
+	payloadManifestPrefix    = "0000_"
+	customResourceDefinition = "CustomResourceDefinition"
+)
+
+// payloadTaskPattern matches the run level and the component of the name of a manifest of the
+// payload, which the cluster version operator orders the manifests by.
+var payloadTaskPattern = regexp.MustCompile(`^0000_(\d{2})_([a-zA-Z0-9-]+)_`)
+
+// PayloadManifest is an object of the manifests of a release payload.
+type PayloadManifest struct {
+	manifest.Manifest
+
+	// Filename is the operator and the name of the file the object is read from.
+	Filename string
+	// PayloadFilename is the name of the file in the payload, which orders it.
+	PayloadFilename string
+	// Index is the position of the object in its file.
+	Index int
+	// RunLevel and Component are the run level and the component of the payload file name, empty
+	// when the name is not valid.
+	RunLevel  string
+	Component string
+}
+
+func (m *PayloadManifest) String() string {
+	name := m.Obj.GetName()
+	if ns := m.Obj.GetNamespace(); len(ns) > 0 {
+		name = ns + "/" + name
+	}
+	return fmt.Sprintf("%s %s in %s", m.GVK.Kind, name, m.Filename)
+}
+
+// appliedBefore returns whether the cluster version operator applies the manifest before the other
+// one. Run levels are applied in order, but the components of a run level are applied in parallel,
+// each one in the order of its file names.
+func (m *PayloadManifest) appliedBefore(other *PayloadManifest) bool {
+	if m.RunLevel != other.RunLevel {
+		return m.RunLevel < other.RunLevel
+	}
+	if m.Component != other.Component {
+		return false
+	}
+	if m.PayloadFilename != other.PayloadFilename {
+		return m.PayloadFilename < other.PayloadFilename
+	}
+	return m.Index < other.Index
+}
+
+// PayloadLintRule checks the manifests of a release payload for mistakes which would otherwise only
+// surface once the cluster version operator applies them.
+type PayloadLintRule struct {
+	// Name identifies the rule to --lint-skip.
+	Name        string
+	Description string
+	// Lint returns an error for each mistake found in the manifests of the payload.
+	Lint func(manifests []*PayloadManifest) []error
+}
+
+// DefaultPayloadLintRules are the rules --lint checks the payload with.
+var DefaultPayloadLintRules = []PayloadLintRule{
+	{Name: "runlevels", Description: "manifest names are ordered by run level and are applied after the namespaces and custom resource definitions they depend on", Lint: lintRunLevels},
+	{Name: "capabilities", Description: "capabilities are known, and manifests are enabled by the capabilities of the manifests they depend on", Lint: lintCapabilities},
+	{Name: "duplicates", Description: "an object is defined by a single manifest of each cluster profile and feature set", Lint: lintDuplicates},
+	{Name: "feature-sets", Description: "feature sets are known, and manifests are only applied in the feature sets of the manifests they depend on", Lint: lintFeatureSets},
+}
+
+// payloadLintRules returns the default rules but the skipped ones.
+func payloadLintRules(skip []string) ([]PayloadLintRule, error) {
+	skipped := sets.NewString(skip...)
+	var rules []PayloadLintRule
+	for _, rule := range DefaultPayloadLintRules {
+		if skipped.Has(rule.Name) {
+			skipped.Delete(rule.Name)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	if skipped.Len() > 0 {
+		var names []string
+		for _, rule := range DefaultPayloadLintRules {
+			names = append(names, rule.Name)
+		}
+		return nil, fmt.Errorf("--lint-skip: unknown rules %s, the rules are %s", strings.Join(skipped.List(), ", "), strings.Join(names, ", "))
+	}
+	return rules, nil
+}
+
+// lintPayload checks the manifests of the operators with the rules, and returns an error listing
+// the mistakes found.
+func lintPayload(ordered []string, metadata map[string]imageData, rules []PayloadLintRule, skipManifestCheck bool) error {
+	var manifests []*PayloadManifest
+	var errs []error
+	if err := iterateExtractedManifests(ordered, metadata, func(directory string, contents []os.FileInfo, operator string) error {
+		for _, fi := range contents {
+			if fi.IsDir() || fi.Name() == imageReferencesImageStreamFilename {
+				continue
+			}
+			switch strings.ToLower(filepath.Ext(fi.Name())) {
+			case ".yaml", ".yml", ".json":
+			default:
+				continue
+			}
+			filename := filepath.Join(filepath.Base(directory), fi.Name())
+			data, err := os.ReadFile(filepath.Join(directory, fi.Name()))
+			if err != nil {
+				return err
+			}
+			parsed, err := manifest.ParseManifests(bytes.NewReader(data))
+			if err != nil {
+				if !skipManifestCheck {
+					errs = append(errs, fmt.Errorf("%s: %v", filename, err))
+				}
+				continue
+			}
+			// the manifests that do not set their run level are renamed into the default one, as
+			// writePayload does
+			payloadFilename, runLevel, component := fi.Name(), defaultPayloadRunLevel, operator
+			if strings.HasPrefix(payloadFilename, payloadManifestPrefix) {
+				runLevel, component = "", ""
+				if m := payloadTaskPattern.FindStringSubmatch(payloadFilename); m != nil {
+					runLevel, component = m[1], m[2]
+				}
+			} else {
+				payloadFilename = fmt.Sprintf("%s%s_%s_%s", payloadManifestPrefix, defaultPayloadRunLevel, operator, payloadFilename)
+			}
+			for i := range parsed {
+				manifests = append(manifests, &PayloadManifest{
+					Manifest:        parsed[i],
+					Filename:        filename,
+					PayloadFilename: payloadFilename,
+					Index:           i,
+					RunLevel:        runLevel,
+					Component:       component,
+				})
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		for _, err := range rule.Lint(manifests) {
+			errs = append(errs, fmt.Errorf("%s: %v", rule.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("the release payload has %d lint errors: %s", len(errs), errorList(errs))
+	}
+	return nil
+}
+
+// payloadDependencies returns, for each manifest, the manifests of the namespace and of the custom
+// resource definition of its object the payload defines.
+func payloadDependencies(manifests []*PayloadManifest) map[*PayloadManifest][]*PayloadManifest {
+	namespaces := map[string][]*PayloadManifest{}
+	crds := map[string][]*PayloadManifest{}
+	for _, m := range manifests {
+		switch {
+		case m.GVK.Group == "" && m.GVK.Kind == "Namespace":
+			namespaces[m.Obj.GetName()] = append(namespaces[m.Obj.GetName()], m)
+		case m.GVK.Group == "apiextensions.k8s.io" && m.GVK.Kind == customResourceDefinition:
+			group, _, _ := unstructured.NestedString(m.Obj.Object, "spec", "group")
+			kind, _, _ := unstructured.NestedString(m.Obj.Object, "spec", "names", "kind")
+			if len(kind) > 0 {
+				key := kind + "." + group
+				crds[key] = append(crds[key], m)
+			}
+		}
+	}
+	dependencies := map[*PayloadManifest][]*PayloadManifest{}
+	for _, m := range manifests {
+		if ns := m.Obj.GetNamespace(); len(ns) > 0 {
+			dependencies[m] = append(dependencies[m], namespaces[ns]...)
+		}
+		dependencies[m] = append(dependencies[m], crds[m.GVK.Kind+"."+m.GVK.Group]...)
+	}
+	return dependencies
+}
+
+// lintRunLevels checks that the manifests whose name sets the run level follow the
+// 0000_<runlevel>_<component>_<name> pattern, and that the namespace and the custom resource
+// definition of an object are applied before it.
+func lintRunLevels(manifests []*PayloadManifest) []error {
+	var errs []error
+	reported := sets.NewString()
+	for _, m := range manifests {
+		if len(m.RunLevel) == 0 && !reported.Has(m.Filename) {
+			reported.Insert(m.Filename)
+			errs = append(errs, fmt.Errorf("%s: the name starts with %s but does not follow %sNN_<component>_<name>, rename it so that the cluster version operator can order it", m.Filename, payloadManifestPrefix, payloadManifestPrefix))
+		}
+	}
+	dependencies := payloadDependencies(manifests)
+	for _, m := range manifests {
+		if len(m.RunLevel) == 0 {
+			continue
+		}
+		for _, dependency := range dependencies[m] {
+			if len(dependency.RunLevel) == 0 || dependency.appliedBefore(m) {
+				continue
+			}
+			errs = append(errs, fmt.Errorf("%s: the %s is not applied before it, rename the manifests so that the %s has a lower run level, or the same run level and component and a name that sorts first", m, dependency, dependency.GVK.Kind))
+		}
+	}
+	return errs
+}
+
+// lintCapabilities checks that the capabilities of the capability.openshift.io/name annotations are
+// known, and that the manifests depending on a manifest enabled by capabilities are enabled by them
+// too, so that they are not applied without what they depend on.
+func lintCapabilities(manifests []*PayloadManifest) []error {
+	known := sets.NewString()
+	for _, capability := range configv1.KnownClusterVersionCapabilities {
+		known.Insert(string(capability))
+	}
+	var errs []error
+	for _, m := range manifests {
+		for _, capability := range m.GetManifestCapabilities() {
+			if !known.Has(string(capability)) {
+				errs = append(errs, fmt.Errorf("%s: unknown capability %q in the %s annotation, the known capabilities are %s", m, capability, manifest.CapabilityAnnotation, strings.Join(known.List(), ", ")))
+			}
+		}
+	}
+	dependencies := payloadDependencies(manifests)
+	for _, m := range manifests {
+		capabilities := sets.NewString()
+		for _, capability := range m.GetManifestCapabilities() {
+			capabilities.Insert(string(capability))
+		}
+		for _, dependency := range dependencies[m] {
+			required := sets.NewString()
+			for _, capability := range dependency.GetManifestCapabilities() {
+				required.Insert(string(capability))
+			}
+			if missing := required.Difference(capabilities); missing.Len() > 0 {
+				errs = append(errs, fmt.Errorf("%s: the %s it depends on is only applied with the %s capabilities, annotate it with %s=%s", m, dependency, strings.Join(missing.List(), ", "), manifest.CapabilityAnnotation, strings.Join(required.Union(capabilities).List(), "+")))
+			}
+		}
+	}
+	return errs
+}
+
+// lintDuplicates checks that no two manifests define the same object for the same cluster profile
+// and feature set, since the cluster version operator would apply both.
+func lintDuplicates(manifests []*PayloadManifest) []error {
+	var errs []error
+	for i, m := range manifests {
+		for _, other := range manifests[:i] {
+			if !m.SameResourceID(other.Manifest) {
+				continue
+			}
+			profiles := manifestProfiles(m).Intersection(manifestProfiles(other))
+			if profiles.Len() == 0 {
+				continue
+			}
+			featureSets := manifestFeatureSets(m).Intersection(manifestFeatureSets(other))
+			if featureSets.Len() == 0 {
+				continue
+			}
+			errs = append(errs, fmt.Errorf("%s: the object is also defined in %s for the %s cluster profiles and the %s feature sets, remove one of the manifests or set %s<profile> or %s annotations so that only one of them is applied", m, other.Filename, strings.Join(profiles.List(), ", "), strings.Join(featureSets.List(), ", "), profileAnnotationPrefix, featureSetAnnotation))
+		}
+	}
+	return errs
+}
+
+// lintFeatureSets checks that the feature sets of the release.openshift.io/feature-set annotations
+// are known, and that the manifests depending on a manifest only applied in some feature sets are
+// only applied in those feature sets too.
+func lintFeatureSets(manifests []*PayloadManifest) []error {
+	known := knownFeatureSets()
+	var errs []error
+	for _, m := range manifests {
+		value, ok := m.Obj.GetAnnotations()[featureSetAnnotation]
+		if !ok {
+			continue
+		}
+		for _, featureSet := range strings.Split(value, ",") {
+			if !known.Has(featureSet) {
+				errs = append(errs, fmt.Errorf("%s: unknown feature set %q in %s=%s, the known feature sets are %s", m, featureSet, featureSetAnnotation, value, strings.Join(known.List(), ", ")))
+			}
+		}
+	}
+	dependencies := payloadDependencies(manifests)
+	for _, m := range manifests {
+		// the unknown feature sets are reported above
+		featureSets := manifestFeatureSets(m).Intersection(known)
+		for _, dependency := range dependencies[m] {
+			if extra := featureSets.Difference(manifestFeatureSets(dependency)); extra.Len() > 0 {
+				errs = append(errs, fmt.Errorf("%s: the %s it depends on is not applied in the %s feature sets, set %s to the feature sets of the %s", m, dependency, strings.Join(extra.List(), ", "), featureSetAnnotation, dependency.GVK.Kind))
+			}
+		}
+	}
+	return errs
+}
+
+// knownFeatureSets returns the values of the release.openshift.io/feature-set annotation, where the
+// default feature set is Default.
+func knownFeatureSets() sets.String {
+	known := sets.NewString(string(configv1.CustomNoUpgrade))
+	for _, featureSet := range configv1.AllFixedFeatureSets {
+		if featureSet == configv1.Default {
+			known.Insert(defaultFeatureSetValue)
+			continue
+		}
+		known.Insert(string(featureSet))
+	}
+	return known
+}
+
+// manifestFeatureSets returns the feature sets the manifest is applied in, all of them when it has
+// no release.openshift.io/feature-set annotation.
+func manifestFeatureSets(m *PayloadManifest) sets.String {
+	value, ok := m.Obj.GetAnnotations()[featureSetAnnotation]
+	if !ok {
+		return knownFeatureSets()
+	}
+	return sets.NewString(strings.Split(value, ",")...)
+}
+
+// manifestProfiles returns the cluster profiles the manifest is included in. A manifest that is not
+// included in any profile counts as a profile of its own, so that duplicates are still reported.
+func manifestProfiles(m *PayloadManifest) sets.String {
+	profiles := sets.NewString()
+	for key, value := range m.Obj.GetAnnotations() {
+		if strings.HasPrefix(key, profileAnnotationPrefix) && value == "true" {
+			profiles.Insert(strings.TrimPrefix(key, profileAnnotationPrefix))
+		}
+	}
+	if profiles.Len() == 0 {
+		profiles.Insert("<none>")
+	}
+	return profiles
+}
@@ -0,0 +1,130 @@
+package release
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLintPayload(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]map[string]string{
+		"cluster-foo-operator": {
+			// the namespace is applied after the deployment in it
+			"0000_50_foo_01_deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  namespace: openshift-foo
+  annotations:
+    include.release.openshift.io/self-managed-high-availability: "true"
+`,
+			"0000_50_foo_02_namespace.yaml": `apiVersion: v1
+kind: Namespace
+metadata:
+  name: openshift-foo
+  annotations:
+    include.release.openshift.io/self-managed-high-availability: "true"
+    capability.openshift.io/name: Console
+`,
+			"0000_5_foo_bad.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: bad
+  namespace: openshift-foo
+  annotations:
+    release.openshift.io/feature-set: TechPreview
+    capability.openshift.io/name: Console+Unknown
+`,
+			"README.md": "not a manifest",
+		},
+		"cluster-bar-operator": {
+			"0000_10_bar_crd.yaml": `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: bars.example.com
+  annotations:
+    include.release.openshift.io/self-managed-high-availability: "true"
+    release.openshift.io/feature-set: TechPreviewNoUpgrade
+spec:
+  group: example.com
+  names:
+    kind: Bar
+`,
+			// unprefixed manifests are applied in run level 50, after the CRD
+			"bar.yaml": `apiVersion: example.com/v1
+kind: Bar
+metadata:
+  name: cluster
+  annotations:
+    include.release.openshift.io/self-managed-high-availability: "true"
+`,
+			"bar-copy.yaml": `apiVersion: example.com/v1
+kind: Bar
+metadata:
+  name: cluster
+  annotations:
+    include.release.openshift.io/self-managed-high-availability: "true"
+    include.release.openshift.io/single-node-developer: "true"
+`,
+			// a different profile is not a duplicate
+			"bar-hypershift.yaml": `apiVersion: example.com/v1
+kind: Bar
+metadata:
+  name: cluster
+  annotations:
+    include.release.openshift.io/ibm-cloud-managed: "true"
+    release.openshift.io/feature-set: TechPreviewNoUpgrade
+`,
+		},
+	}
+	metadata := map[string]imageData{}
+	var ordered []string
+	for operator, contents := range files {
+		operatorDir := filepath.Join(dir, operator)
+		if err := os.MkdirAll(operatorDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for name, content := range contents {
+			if err := os.WriteFile(filepath.Join(operatorDir, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		metadata[operator] = imageData{Directory: operatorDir}
+		ordered = append(ordered, operator)
+	}
+
+	err := lintPayload(ordered, metadata, DefaultPayloadLintRules, false)
+	if err == nil {
+		t.Fatal("expected lint errors")
+	}
+	expected := []string{
+		"runlevels: cluster-foo-operator/0000_5_foo_bad.yaml: the name starts with 0000_ but does not follow 0000_NN_<component>_<name>",
+		"runlevels: Deployment openshift-foo/foo in cluster-foo-operator/0000_50_foo_01_deployment.yaml: the Namespace openshift-foo in cluster-foo-operator/0000_50_foo_02_namespace.yaml is not applied before it",
+		`capabilities: ConfigMap openshift-foo/bad in cluster-foo-operator/0000_5_foo_bad.yaml: unknown capability "Unknown"`,
+		"capabilities: Deployment openshift-foo/foo in cluster-foo-operator/0000_50_foo_01_deployment.yaml: the Namespace openshift-foo in cluster-foo-operator/0000_50_foo_02_namespace.yaml it depends on is only applied with the Console capabilities, annotate it with capability.openshift.io/name=Console",
+		"duplicates: Bar cluster in cluster-bar-operator/bar.yaml: the object is also defined in cluster-bar-operator/bar-copy.yaml for the self-managed-high-availability cluster profiles",
+		`feature-sets: ConfigMap openshift-foo/bad in cluster-foo-operator/0000_5_foo_bad.yaml: unknown feature set "TechPreview"`,
+		"feature-sets: Bar cluster in cluster-bar-operator/bar.yaml: the CustomResourceDefinition bars.example.com in cluster-bar-operator/0000_10_bar_crd.yaml it depends on is not applied in the CustomNoUpgrade, Default, DevPreviewNoUpgrade feature sets",
+	}
+	for _, s := range expected {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("expected the error %q, got:\n%v", s, err)
+		}
+	}
+	if !strings.HasPrefix(err.Error(), "the release payload has 8 lint errors") {
+		t.Errorf("unexpected errors:\n%v", err)
+	}
+
+	rules, err := payloadLintRules([]string{"runlevels", "capabilities", "duplicates", "feature-sets"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lintPayload(ordered, metadata, rules, false); err != nil {
+		t.Errorf("expected no error with all the rules skipped, got %v", err)
+	}
+	if _, err := payloadLintRules([]string{"unknown"}); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+}
@@ -0,0 +1,217 @@
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/distribution/distribution/v3"
+	digest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	imagespecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+
+	imageapi "github.com/openshift/api/image/v1"
+	imagereference "github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	ocversion "github.com/openshift/oc/pkg/version"
+)
+
+const (
+	inTotoStatementType     = "https://in-toto.io/Statement/v1"
+	inTotoMediaType         = "application/vnd.in-toto+json"
+	inTotoPredicateTypeKey  = "in-toto.io/predicate-type"
+	slsaProvenancePredicate = "https://slsa.dev/provenance/v1"
+	// releaseBuildType identifies how a release payload is built, to interpret the parameters of
+	// its provenance.
+	releaseBuildType = "https://github.com/openshift/oc/adm-release-new@v1"
+	// defaultProvenanceBuilderID is the builder of the provenance when --provenance-builder-id is
+	// not set.
+	defaultProvenanceBuilderID = "https://github.com/openshift/oc"
+)
+
+// inTotoStatement is an in-toto attestation of the release image, carrying its SLSA provenance.
+type inTotoStatement struct {
+	Type          string                   `json:"_type"`
+	Subject       []slsaResourceDescriptor `json:"subject"`
+	PredicateType string                   `json:"predicateType"`
+	Predicate     slsaProvenance           `json:"predicate"`
+}
+
+type slsaProvenance struct {
+	BuildDefinition slsaBuildDefinition `json:"buildDefinition"`
+	RunDetails      slsaRunDetails      `json:"runDetails"`
+}
+
+type slsaBuildDefinition struct {
+	BuildType            string                   `json:"buildType"`
+	ExternalParameters   releaseBuildParameters   `json:"externalParameters"`
+	ResolvedDependencies []slsaResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+// releaseBuildParameters are the arguments and the flags oc adm release new was invoked with.
+type releaseBuildParameters struct {
+	Arguments []string          `json:"arguments,omitempty"`
+	Flags     map[string]string `json:"flags,omitempty"`
+}
+
+type slsaResourceDescriptor struct {
+	Name   string            `json:"name,omitempty"`
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+type slsaRunDetails struct {
+	Builder  slsaBuilder       `json:"builder"`
+	Metadata slsaBuildMetadata `json:"metadata"`
+}
+
+type slsaBuilder struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version,omitempty"`
+}
+
+type slsaBuildMetadata struct {
+	InvocationID string `json:"invocationId"`
+	StartedOn    string `json:"startedOn"`
+	FinishedOn   string `json:"finishedOn"`
+}
+
+// newReleaseProvenance returns the provenance of the release image pushed to ref with the digest,
+// built from the images of the image stream on top of the base image.
+func newReleaseProvenance(ref imagereference.DockerImageReference, dgst digest.Digest, is *imageapi.ImageStream, base string, baseDigest digest.Digest, builderID string, parameters releaseBuildParameters, startedOn, finishedOn time.Time) *inTotoStatement {
+	var dependencies []slsaResourceDescriptor
+	if len(base) > 0 && len(baseDigest) > 0 {
+		dependencies = append(dependencies, slsaResourceDescriptor{Name: "base", URI: "docker://" + base, Digest: digestSet(baseDigest)})
+	}
+	for _, tag := range is.Spec.Tags {
+		if tag.From == nil || tag.From.Kind != "DockerImage" {
+			continue
+		}
+		tagRef, err := imagereference.Parse(tag.From.Name)
+		if err != nil || len(tagRef.ID) == 0 {
+			continue
+		}
+		dependencies = append(dependencies, slsaResourceDescriptor{Name: tag.Name, URI: "docker://" + tag.From.Name, Digest: digestSet(digest.Digest(tagRef.ID))})
+	}
+	if len(builderID) == 0 {
+		builderID = defaultProvenanceBuilderID
+	}
+	ocVersion := ocversion.Get()
+	return &inTotoStatement{
+		Type:          inTotoStatementType,
+		Subject:       []slsaResourceDescriptor{{Name: ref.AsRepository().Exact(), Digest: digestSet(dgst)}},
+		PredicateType: slsaProvenancePredicate,
+		Predicate: slsaProvenance{
+			BuildDefinition: slsaBuildDefinition{
+				BuildType:            releaseBuildType,
+				ExternalParameters:   parameters,
+				ResolvedDependencies: dependencies,
+			},
+			RunDetails: slsaRunDetails{
+				Builder: slsaBuilder{
+					ID:      builderID,
+					Version: map[string]string{"oc": ocVersion.GitVersion, "oc-commit": ocVersion.GitCommit},
+				},
+				Metadata: slsaBuildMetadata{
+					InvocationID: string(uuid.NewUUID()),
+					StartedOn:    startedOn.UTC().Format(time.RFC3339),
+					FinishedOn:   finishedOn.UTC().Format(time.RFC3339),
+				},
+			},
+		},
+	}
+}
+
+// newReleaseBuildParameters returns the parameters of the provenance from the arguments and the
+// flags set on the command line.
+func newReleaseBuildParameters(args []string, flags map[string]string) releaseBuildParameters {
+	parameters := releaseBuildParameters{Arguments: append([]string{}, args...)}
+	if len(flags) > 0 {
+		parameters.Flags = make(map[string]string, len(flags))
+		for name, value := range flags {
+			parameters.Flags[name] = value
+		}
+	}
+	return parameters
+}
+
+func digestSet(dgst digest.Digest) map[string]string {
+	if dgst.Validate() != nil {
+		return nil
+	}
+	return map[string]string{dgst.Algorithm().String(): dgst.Encoded()}
+}
+
+// pushProvenance pushes the provenance to the repository of the release image as an OCI artifact
+// whose subject is the release image, so that registries implementing the OCI referrers API list it
+// among the referrers of the release image. It returns the digest of the artifact.
+func (o *NewOptions) pushProvenance(ctx context.Context, ref imagereference.DockerImageReference, dgst digest.Digest, statement *inTotoStatement) (digest.Digest, error) {
+	data, err := json.Marshal(statement)
+	if err != nil {
+		return "", err
+	}
+	registryContext, err := o.SecurityOptions.Context()
+	if err != nil {
+		return "", err
+	}
+	opts := &imagesource.Options{Insecure: o.SecurityOptions.Insecure, RegistryContext: registryContext}
+	repo, err := opts.Repository(ctx, imagesource.TypedImageReference{Type: imagesource.DestinationRegistry, Ref: ref.AsRepository()})
+	if err != nil {
+		return "", err
+	}
+	manifests, err := repo.Manifests(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// the subject must be described by the media type and size of the release manifest
+	release, err := manifests.Get(ctx, dgst)
+	if err != nil {
+		return "", fmt.Errorf("unable to get the release image manifest: %v", err)
+	}
+	mediaType, payload, err := release.Payload()
+	if err != nil {
+		return "", err
+	}
+
+	config := imagespecv1.DescriptorEmptyJSON
+	config.Data = nil
+	if _, err := repo.Blobs(ctx).Put(ctx, config.MediaType, []byte("{}")); err != nil {
+		return "", fmt.Errorf("unable to push the configuration of the provenance: %v", err)
+	}
+	layer, err := repo.Blobs(ctx).Put(ctx, inTotoMediaType, data)
+	if err != nil {
+		return "", fmt.Errorf("unable to push the provenance: %v", err)
+	}
+
+	artifact := imagespecv1.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    imagespecv1.MediaTypeImageManifest,
+		ArtifactType: inTotoMediaType,
+		Config:       config,
+		Layers: []imagespecv1.Descriptor{{
+			MediaType:   inTotoMediaType,
+			Digest:      layer.Digest,
+			Size:        layer.Size,
+			Annotations: map[string]string{inTotoPredicateTypeKey: statement.PredicateType},
+		}},
+		Subject:     &imagespecv1.Descriptor{MediaType: mediaType, Digest: dgst, Size: int64(len(payload))},
+		Annotations: map[string]string{imagespecv1.AnnotationCreated: statement.Predicate.RunDetails.Metadata.FinishedOn},
+	}
+	artifactData, err := json.Marshal(artifact)
+	if err != nil {
+		return "", err
+	}
+	m, _, err := distribution.UnmarshalManifest(imagespecv1.MediaTypeImageManifest, artifactData)
+	if err != nil {
+		return "", err
+	}
+	artifactDigest, err := manifests.Put(ctx, m)
+	if err != nil {
+		return "", fmt.Errorf("unable to push the provenance manifest: %v", err)
+	}
+	return artifactDigest, nil
+}
@@ -0,0 +1,71 @@
+package release
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	digest "github.com/opencontainers/go-digest"
+
+	corev1 "k8s.io/api/core/v1"
+
+	imageapi "github.com/openshift/api/image/v1"
+	imagereference "github.com/openshift/library-go/pkg/image/reference"
+)
+
+func TestNewReleaseProvenance(t *testing.T) {
+	releaseDigest := digest.FromString("release")
+	baseDigest := digest.FromString("base")
+	cvoDigest := digest.FromString("cvo")
+	ref, err := imagereference.Parse("quay.io/example/release:4.16.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	is := &imageapi.ImageStream{
+		Spec: imageapi.ImageStreamSpec{Tags: []imageapi.TagReference{
+			{Name: "cluster-version-operator", From: &corev1.ObjectReference{Kind: "DockerImage", Name: "quay.io/example/cvo@" + cvoDigest.String()}},
+			{Name: "by-tag", From: &corev1.ObjectReference{Kind: "DockerImage", Name: "quay.io/example/cvo:latest"}},
+			{Name: "no-image"},
+		}},
+	}
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	parameters := newReleaseBuildParameters([]string{"cli=quay.io/example/cli@" + cvoDigest.String()}, map[string]string{"name": "4.16.0", "provenance": "true"})
+	statement := newReleaseProvenance(ref, releaseDigest, is, "quay.io/example/base:latest", baseDigest, "", parameters, started, started.Add(time.Minute))
+
+	if statement.Type != inTotoStatementType || statement.PredicateType != slsaProvenancePredicate {
+		t.Errorf("unexpected statement types %q and %q", statement.Type, statement.PredicateType)
+	}
+	expectedSubject := []slsaResourceDescriptor{{Name: "quay.io/example/release", Digest: map[string]string{"sha256": releaseDigest.Encoded()}}}
+	if !reflect.DeepEqual(statement.Subject, expectedSubject) {
+		t.Errorf("unexpected subject %#v", statement.Subject)
+	}
+	expectedDependencies := []slsaResourceDescriptor{
+		{Name: "base", URI: "docker://quay.io/example/base:latest", Digest: map[string]string{"sha256": baseDigest.Encoded()}},
+		{Name: "cluster-version-operator", URI: "docker://quay.io/example/cvo@" + cvoDigest.String(), Digest: map[string]string{"sha256": cvoDigest.Encoded()}},
+	}
+	if !reflect.DeepEqual(statement.Predicate.BuildDefinition.ResolvedDependencies, expectedDependencies) {
+		t.Errorf("unexpected dependencies %#v", statement.Predicate.BuildDefinition.ResolvedDependencies)
+	}
+	if !reflect.DeepEqual(statement.Predicate.BuildDefinition.ExternalParameters, parameters) {
+		t.Errorf("unexpected parameters %#v", statement.Predicate.BuildDefinition.ExternalParameters)
+	}
+	metadata := statement.Predicate.RunDetails.Metadata
+	if statement.Predicate.RunDetails.Builder.ID != defaultProvenanceBuilderID || metadata.StartedOn != "2024-01-02T03:04:05Z" || metadata.FinishedOn != "2024-01-02T03:05:05Z" || len(metadata.InvocationID) == 0 {
+		t.Errorf("unexpected run details %#v", statement.Predicate.RunDetails)
+	}
+
+	data, err := json.Marshal(statement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"_type", "subject", "predicateType", "predicate"} {
+		if _, ok := fields[field]; !ok {
+			t.Errorf("the statement has no %s field: %s", field, data)
+		}
+	}
+}
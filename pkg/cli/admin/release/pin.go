@@ -0,0 +1,321 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+	"github.com/openshift/library-go/pkg/image/reference"
+	imagemanifest "github.com/openshift/oc/pkg/cli/image/manifest"
+)
+
+func NewPinOptions(streams genericiooptions.IOStreams) *PinOptions {
+	return &PinOptions{
+		IOStreams: streams,
+	}
+}
+
+func NewPin(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewPinOptions(streams)
+	cmd := &cobra.Command{
+		Use:   "pin --file=FILE [--verify]",
+		Short: "Record the release a cluster runs and verify that it still runs it",
+		Long: templates.LongDesc(`
+			Record the release a cluster runs and verify that it still runs it.
+
+			Regulated environments must prove that the software of a cluster did not change
+			between two audits. This command records, in a pin file, the digest of the release
+			the connected cluster runs and the digest of every image of its payload. The release
+			image is read from its registry, or from the mirrors of --idms-file in a disconnected
+			environment. The cluster must not be updating.
+
+			Pass --verify to check, at a later time, that the cluster still runs exactly what the
+			pin file records: that its desired release is the pinned one and no update is in
+			progress, and that every container of the running pods that uses an image of the
+			repositories of the release uses one of the pinned digests. The command exits with an
+			error listing the differences found.
+		`),
+		Example: templates.Examples(`
+			# Record the release and the component images the cluster runs
+			oc adm release pin --file=cluster-pin.yaml
+
+			# Verify that the cluster still runs the release and the component images recorded
+			oc adm release pin --file=cluster-pin.yaml --verify
+		`),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run(cmd.Context()))
+		},
+	}
+	flags := cmd.Flags()
+	o.SecurityOptions.Bind(flags)
+	o.FilterOptions.Bind(flags)
+
+	flags.StringVar(&o.File, "file", o.File, "The pin file to write, or to verify the cluster against with --verify.")
+	flags.BoolVar(&o.Verify, "verify", o.Verify, "Verify that the cluster runs the release and the component images of the pin file instead of writing it.")
+	flags.StringVar(&o.ICSPFile, "icsp-file", o.ICSPFile, "Path to an ImageContentSourcePolicy file. If set, data from this file will be used to find alternative locations for the release image.")
+	flags.MarkDeprecated("icsp-file", "support for it will be removed in a future release. Use --idms-file instead.")
+	flags.StringVar(&o.IDMSFile, "idms-file", o.IDMSFile, "Path to an ImageDigestMirrorSet file. If set, data from this file will be used to find alternative locations for the release image.")
+	return cmd
+}
+
+type PinOptions struct {
+	genericiooptions.IOStreams
+
+	SecurityOptions imagemanifest.SecurityOptions
+	FilterOptions   imagemanifest.FilterOptions
+
+	File     string
+	Verify   bool
+	ICSPFile string
+	IDMSFile string
+
+	configClient configv1client.Interface
+	kubeClient   kubernetes.Interface
+}
+
+// releasePin is the content of a pin file.
+type releasePin struct {
+	ClusterID string    `json:"clusterID"`
+	Version   string    `json:"version"`
+	Release   string    `json:"release"`
+	PinnedAt  time.Time `json:"pinnedAt"`
+	// Components are the images of the payload of the release, sorted by name.
+	Components []pinnedComponent `json:"components"`
+}
+
+type pinnedComponent struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+}
+
+// unpinnedContainer is a container running an image of the repositories of the release that
+// is not one of the pinned digests.
+type unpinnedContainer struct {
+	Namespace, Pod, Container, Image string
+}
+
+func (o *PinOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "pin expects no arguments")
+	}
+	cfg, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.configClient, err = configv1client.NewForConfig(cfg); err != nil {
+		return err
+	}
+	if o.kubeClient, err = kubernetes.NewForConfig(cfg); err != nil {
+		return err
+	}
+	return o.FilterOptions.Complete(cmd.Flags())
+}
+
+func (o *PinOptions) Validate() error {
+	if len(o.File) == 0 {
+		return fmt.Errorf("--file is required")
+	}
+	if len(o.ICSPFile) > 0 && len(o.IDMSFile) > 0 {
+		return fmt.Errorf("icsp-file and idms-file are mutually exclusive")
+	}
+	return o.FilterOptions.Validate()
+}
+
+func (o *PinOptions) Run(ctx context.Context) error {
+	cv, err := o.configClient.ConfigV1().ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to read the cluster version: %v", err)
+	}
+	if o.Verify {
+		return o.verify(ctx, cv)
+	}
+	return o.pin(cv)
+}
+
+func (o *PinOptions) pin(cv *configv1.ClusterVersion) error {
+	if len(cv.Status.Desired.Image) == 0 {
+		return fmt.Errorf("the cluster is not reporting the release image it runs")
+	}
+	if updating(cv) {
+		return fmt.Errorf("the cluster is updating to %s, pin its release once the update completes", cv.Status.Desired.Image)
+	}
+
+	info := NewInfoOptions(o.IOStreams)
+	info.SecurityOptions = o.SecurityOptions
+	info.FilterOptions = o.FilterOptions
+	info.ICSPFile = o.ICSPFile
+	info.IDMSFile = o.IDMSFile
+	release, err := info.LoadReleaseInfo(cv.Status.Desired.Image, false)
+	if err != nil {
+		return fmt.Errorf("unable to read the release image %s: %v", cv.Status.Desired.Image, err)
+	}
+	pin, err := newReleasePin(cv, release)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(pin)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(o.File, data, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "Pinned the release %s and its %d component images of cluster %s to %s\n", pin.Release, len(pin.Components), pin.ClusterID, o.File)
+	return nil
+}
+
+// newReleasePin records the release the cluster runs and the images of its payload, which must
+// all be referenced by digest.
+func newReleasePin(cv *configv1.ClusterVersion, release *ReleaseInfo) (*releasePin, error) {
+	if ref, err := reference.Parse(cv.Status.Desired.Image); err != nil || len(ref.ID) == 0 {
+		return nil, fmt.Errorf("the release image of the cluster %s is not referenced by digest", cv.Status.Desired.Image)
+	}
+	if release.References == nil {
+		return nil, fmt.Errorf("the release image %s does not reference any image", cv.Status.Desired.Image)
+	}
+	pin := &releasePin{
+		ClusterID:  string(cv.Spec.ClusterID),
+		Version:    cv.Status.Desired.Version,
+		Release:    cv.Status.Desired.Image,
+		PinnedAt:   time.Now().UTC().Truncate(time.Second),
+		Components: []pinnedComponent{},
+	}
+	for _, tag := range release.References.Spec.Tags {
+		if tag.From == nil || tag.From.Kind != "DockerImage" {
+			continue
+		}
+		if ref, err := reference.Parse(tag.From.Name); err != nil || len(ref.ID) == 0 {
+			return nil, fmt.Errorf("the image %s of the release is not referenced by digest: %s", tag.Name, tag.From.Name)
+		}
+		pin.Components = append(pin.Components, pinnedComponent{Name: tag.Name, Image: tag.From.Name})
+	}
+	sort.Slice(pin.Components, func(i, j int) bool { return pin.Components[i].Name < pin.Components[j].Name })
+	return pin, nil
+}
+
+func (o *PinOptions) verify(ctx context.Context, cv *configv1.ClusterVersion) error {
+	data, err := os.ReadFile(o.File)
+	if err != nil {
+		return err
+	}
+	pin := &releasePin{}
+	if err := yaml.Unmarshal(data, pin); err != nil {
+		return fmt.Errorf("unable to read the pin file %s: %v", o.File, err)
+	}
+	if clusterID := string(cv.Spec.ClusterID); clusterID != pin.ClusterID {
+		return fmt.Errorf("the pin file %s records the cluster %s, not the connected cluster %s", o.File, pin.ClusterID, clusterID)
+	}
+
+	var errs []error
+	if !sameDigest(cv.Status.Desired.Image, pin.Release) {
+		errs = append(errs, fmt.Errorf("the cluster runs the release %s instead of the pinned %s", cv.Status.Desired.Image, pin.Release))
+	}
+	if updating(cv) {
+		errs = append(errs, fmt.Errorf("the cluster is updating to %s", cv.Status.Desired.Image))
+	}
+
+	pods, err := o.kubeClient.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list the pods of the cluster: %v", err)
+	}
+	checked, unpinned := unpinnedContainers(pin, pods.Items)
+	if len(unpinned) > 0 {
+		w := tabwriter.NewWriter(o.Out, 0, 4, 1, ' ', 0)
+		fmt.Fprintf(w, "NAMESPACE\tPOD\tCONTAINER\tIMAGE\n")
+		for _, c := range unpinned {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.Namespace, c.Pod, c.Container, c.Image)
+		}
+		w.Flush()
+		errs = append(errs, fmt.Errorf("%d of %d containers of the release components do not run a pinned image", len(unpinned), checked))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("the cluster %s does not run what %s pinned at %s: %s", pin.ClusterID, o.File, pin.PinnedAt.Format(time.RFC3339), errorList(errs))
+	}
+	fmt.Fprintf(o.Out, "The cluster %s runs the pinned release %s, and its %d containers of the release components run pinned images\n", pin.ClusterID, pin.Release, checked)
+	return nil
+}
+
+// updating returns whether the last update of the cluster is not completed.
+func updating(cv *configv1.ClusterVersion) bool {
+	return len(cv.Status.History) > 0 && cv.Status.History[0].State != configv1.CompletedUpdate
+}
+
+// sameDigest returns whether both images are referenced by the same digest, whatever the
+// repositories they are pulled from.
+func sameDigest(a, b string) bool {
+	refA, errA := reference.Parse(a)
+	refB, errB := reference.Parse(b)
+	return errA == nil && errB == nil && len(refA.ID) > 0 && refA.ID == refB.ID
+}
+
+// unpinnedContainers returns how many containers of the running pods use an image of the
+// repositories of the release or of its components, and those of them whose image is not one
+// of the pinned digests.
+func unpinnedContainers(pin *releasePin, pods []corev1.Pod) (int, []unpinnedContainer) {
+	repositories, digests := sets.NewString(), sets.NewString()
+	for _, image := range append([]string{pin.Release}, pinnedImages(pin)...) {
+		ref, err := reference.Parse(image)
+		if err != nil {
+			continue
+		}
+		repositories.Insert(ref.AsRepository().Exact())
+		if _, err := digest.Parse(ref.ID); err == nil {
+			digests.Insert(ref.ID)
+		}
+	}
+
+	checked := 0
+	var unpinned []unpinnedContainer
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, container := range append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+			ref, err := reference.Parse(container.Image)
+			if err != nil || !repositories.Has(ref.AsRepository().Exact()) {
+				continue
+			}
+			checked++
+			if !digests.Has(ref.ID) {
+				unpinned = append(unpinned, unpinnedContainer{Namespace: pod.Namespace, Pod: pod.Name, Container: container.Name, Image: container.Image})
+			}
+		}
+	}
+	sort.Slice(unpinned, func(i, j int) bool {
+		if unpinned[i].Namespace != unpinned[j].Namespace {
+			return unpinned[i].Namespace < unpinned[j].Namespace
+		}
+		if unpinned[i].Pod != unpinned[j].Pod {
+			return unpinned[i].Pod < unpinned[j].Pod
+		}
+		return unpinned[i].Container < unpinned[j].Container
+	})
+	return checked, unpinned
+}
+
+func pinnedImages(pin *releasePin) []string {
+	var images []string
+	for _, component := range pin.Components {
+		images = append(images, component.Image)
+	}
+	return images
+}
@@ -0,0 +1,99 @@
+package release
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	imageapi "github.com/openshift/api/image/v1"
+)
+
+const (
+	pinRelease   = "quay.io/openshift-release-dev/ocp-release@sha256:0000000000000000000000000000000000000000000000000000000000000001"
+	pinEtcd      = "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:0000000000000000000000000000000000000000000000000000000000000002"
+	pinConsole   = "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:0000000000000000000000000000000000000000000000000000000000000003"
+	unpinnedEtcd = "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:0000000000000000000000000000000000000000000000000000000000000004"
+)
+
+func TestNewReleasePin(t *testing.T) {
+	cv := &configv1.ClusterVersion{
+		Spec: configv1.ClusterVersionSpec{ClusterID: "cluster-id"},
+		Status: configv1.ClusterVersionStatus{
+			Desired: configv1.Release{Version: "4.16.3", Image: pinRelease},
+		},
+	}
+	release := &ReleaseInfo{References: &imageapi.ImageStream{
+		Spec: imageapi.ImageStreamSpec{Tags: []imageapi.TagReference{
+			{Name: "etcd", From: &corev1.ObjectReference{Kind: "DockerImage", Name: pinEtcd}},
+			{Name: "console", From: &corev1.ObjectReference{Kind: "DockerImage", Name: pinConsole}},
+			{Name: "other", From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "other:latest"}},
+		}},
+	}}
+	pin, err := newReleasePin(cv, release)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pin.ClusterID != "cluster-id" || pin.Version != "4.16.3" || pin.Release != pinRelease {
+		t.Errorf("unexpected pin of the release %#v", pin)
+	}
+	expected := []pinnedComponent{{Name: "console", Image: pinConsole}, {Name: "etcd", Image: pinEtcd}}
+	if !reflect.DeepEqual(pin.Components, expected) {
+		t.Errorf("expected components %v, got %v", expected, pin.Components)
+	}
+
+	release.References.Spec.Tags[0].From.Name = "quay.io/openshift-release-dev/ocp-v4.0-art-dev:etcd"
+	if _, err := newReleasePin(cv, release); err == nil {
+		t.Errorf("expected an error for a component referenced by tag")
+	}
+}
+
+func TestUnpinnedContainers(t *testing.T) {
+	pin := &releasePin{
+		Release:    pinRelease,
+		Components: []pinnedComponent{{Name: "console", Image: pinConsole}, {Name: "etcd", Image: pinEtcd}},
+	}
+	newPod := func(namespace, name string, phase corev1.PodPhase, images ...string) corev1.Pod {
+		pod := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+		for i, image := range images {
+			pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: string(rune('a' + i)), Image: image})
+		}
+		return pod
+	}
+	pods := []corev1.Pod{
+		newPod("openshift-cluster-version", "cluster-version-operator", corev1.PodRunning, pinRelease),
+		newPod("openshift-etcd", "etcd-master-0", corev1.PodRunning, pinEtcd, unpinnedEtcd),
+		newPod("openshift-etcd", "installer-1-master-0", corev1.PodSucceeded, unpinnedEtcd),
+		newPod("openshift-console", "console", corev1.PodRunning, "quay.io/openshift-release-dev/ocp-v4.0-art-dev:console"),
+		newPod("default", "app", corev1.PodRunning, "registry.example.com/app@sha256:0000000000000000000000000000000000000000000000000000000000000005"),
+	}
+	checked, unpinned := unpinnedContainers(pin, pods)
+	if checked != 4 {
+		t.Errorf("expected 4 containers of the release components to be checked, got %d", checked)
+	}
+	expected := []unpinnedContainer{
+		{Namespace: "openshift-console", Pod: "console", Container: "a", Image: "quay.io/openshift-release-dev/ocp-v4.0-art-dev:console"},
+		{Namespace: "openshift-etcd", Pod: "etcd-master-0", Container: "b", Image: unpinnedEtcd},
+	}
+	if !reflect.DeepEqual(unpinned, expected) {
+		t.Errorf("expected unpinned containers %v, got %v", expected, unpinned)
+	}
+}
+
+func TestSameDigest(t *testing.T) {
+	mirrored := "mirror.example.com/ocp/release@sha256:0000000000000000000000000000000000000000000000000000000000000001"
+	if !sameDigest(pinRelease, mirrored) {
+		t.Errorf("expected a mirror of the release to have the same digest")
+	}
+	if sameDigest(pinRelease, pinEtcd) {
+		t.Errorf("expected different digests")
+	}
+	if sameDigest("quay.io/openshift-release-dev/ocp-release:4.16.3", "quay.io/openshift-release-dev/ocp-release:4.16.3") {
+		t.Errorf("expected images referenced by tag not to be the same digest")
+	}
+}
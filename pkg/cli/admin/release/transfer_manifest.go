@@ -0,0 +1,195 @@
+package release
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/openpgp"
+
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	"github.com/openshift/oc/pkg/cli/image/mirror"
+	"github.com/openshift/oc/pkg/version"
+)
+
+// transferManifestName is the file describing the content of a release mirrored to disk.
+const transferManifestName = "transfer-manifest.json"
+
+// TransferManifest describes the content of a release mirrored to disk, so that it can be
+// reviewed and approved before being imported into another environment.
+type TransferManifest struct {
+	// Release is the release image that was mirrored.
+	Release string `json:"release"`
+	// ReleaseDigest is the digest the release image was verified with.
+	ReleaseDigest string `json:"releaseDigest"`
+	// Images are the images of the release and where they were mirrored from.
+	Images []TransferImage `json:"images"`
+	// Files are the files written to disk, relative to the mirror directory.
+	Files []TransferFile `json:"files"`
+
+	Created   time.Time `json:"created"`
+	CreatedBy string    `json:"createdBy"`
+}
+
+type TransferImage struct {
+	Name        string `json:"name"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+}
+
+type TransferFile struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// newTransferManifest describes the files of the repositories the mappings mirrored under dir.
+func newTransferManifest(dir, release, releaseDigest string, mappings []mirror.Mapping, now time.Time) (*TransferManifest, error) {
+	m := &TransferManifest{
+		Release:       release,
+		ReleaseDigest: releaseDigest,
+		Created:       now.UTC(),
+		CreatedBy:     fmt.Sprintf("oc/%s", version.Get().GitVersion),
+	}
+	repositories := make(map[string]struct{})
+	for _, mapping := range mappings {
+		m.Images = append(m.Images, TransferImage{Name: mapping.Name, Source: mapping.Source.String(), Destination: mapping.Destination.String()})
+		if mapping.Destination.Type == imagesource.DestinationFile {
+			repositories[mapping.Destination.Ref.RepositoryName()] = struct{}{}
+		}
+	}
+	sort.Slice(m.Images, func(i, j int) bool { return m.Images[i].Name < m.Images[j].Name })
+
+	err := walkTransferRepositories(dir, repositories, func(path string) error {
+		sum, size, err := sha256File(filepath.Join(dir, filepath.FromSlash(path)))
+		if err != nil {
+			return err
+		}
+		m.Files = append(m.Files, TransferFile{Path: path, SHA256: sum, Size: size})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to describe the mirrored content: %v", err)
+	}
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Path < m.Files[j].Path })
+	return m, nil
+}
+
+// walkTransferRepositories calls fn with the path relative to dir of each file of the
+// repositories mirrored to dir.
+func walkTransferRepositories(dir string, repositories map[string]struct{}, fn func(path string) error) error {
+	for repository := range repositories {
+		root := filepath.Join(dir, "v2", strings.ReplaceAll(repository, "/", string(filepath.Separator)))
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			return fn(filepath.ToSlash(rel))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTransferManifest writes the transfer manifest to dir, with an armored detached
+// signature next to it if signer is set.
+func writeTransferManifest(dir string, m *TransferManifest, signer *openpgp.Entity) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	path := filepath.Join(dir, transferManifestName)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("unable to write the transfer manifest: %v", err)
+	}
+	if signer == nil {
+		return nil
+	}
+	buf := &bytes.Buffer{}
+	if err := openpgp.ArmoredDetachSign(buf, signer, bytes.NewReader(data), nil); err != nil {
+		return fmt.Errorf("unable to sign the transfer manifest: %v", err)
+	}
+	if err := os.WriteFile(path+".asc", buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("unable to write the signature of the transfer manifest: %v", err)
+	}
+	return nil
+}
+
+// verifyTransferManifest checks that the files under dir are those the transfer manifest at
+// path describes. If keyringPath is set, the manifest must have been signed by one of its
+// keys in path.asc.
+func verifyTransferManifest(path, keyringPath, dir string) (*TransferManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyringPath) > 0 {
+		keyringData, err := os.ReadFile(keyringPath)
+		if err != nil {
+			return nil, err
+		}
+		keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(keyringData))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the keyring %s: %v", keyringPath, err)
+		}
+		signature, err := os.Open(path + ".asc")
+		if err != nil {
+			return nil, fmt.Errorf("the transfer manifest is not signed: %v", err)
+		}
+		defer signature.Close()
+		if _, err := openpgp.CheckArmoredDetachedSignature(keyring, bytes.NewReader(data), signature); err != nil {
+			return nil, fmt.Errorf("the signature of the transfer manifest is invalid: %v", err)
+		}
+	}
+
+	m := &TransferManifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("unable to read the transfer manifest %s: %v", path, err)
+	}
+	var errs []error
+	approved := make(map[string]struct{}, len(m.Files))
+	for _, file := range m.Files {
+		approved[file.Path] = struct{}{}
+		sum, size, err := sha256File(filepath.Join(dir, filepath.FromSlash(file.Path)))
+		switch {
+		case err != nil:
+			errs = append(errs, err)
+		case size != file.Size || sum != file.SHA256:
+			errs = append(errs, fmt.Errorf("%s does not match the transfer manifest", file.Path))
+		}
+	}
+	// files added to the repositories after the review could be imported along with the release
+	repositories := make(map[string]struct{})
+	for _, image := range m.Images {
+		if ref, err := imagesource.ParseReference(image.Destination); err == nil && ref.Type == imagesource.DestinationFile {
+			repositories[ref.Ref.RepositoryName()] = struct{}{}
+		}
+	}
+	if err := walkTransferRepositories(dir, repositories, func(path string) error {
+		if _, ok := approved[path]; !ok {
+			errs = append(errs, fmt.Errorf("%s is not in the transfer manifest", path))
+		}
+		return nil
+	}); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("the content of %s was not approved in the transfer manifest: %s", dir, errorList(errs))
+	}
+	return m, nil
+}
@@ -0,0 +1,108 @@
+package release
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	"github.com/openshift/oc/pkg/cli/image/mirror"
+)
+
+func TestTransferManifest(t *testing.T) {
+	dir := t.TempDir()
+	repository := filepath.Join(dir, "v2", "openshift", "release")
+	for name, content := range map[string]string{
+		"blobs/sha256:1":       "layer",
+		"manifests/sha256:2":   "manifest",
+		"manifests/4.16.0-cli": "manifest",
+	} {
+		path := filepath.Join(repository, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	source, err := imagesource.ParseReference("quay.io/openshift-release-dev/ocp-v4.0-art-dev:cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	destination, err := imagesource.ParseReference("file://openshift/release:4.16.0-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := newTransferManifest(dir, "quay.io/openshift-release-dev/ocp-release:4.16.0", "sha256:0", []mirror.Mapping{{Name: "cli", Source: source, Destination: destination}}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Files) != 3 || m.Files[0].Path != "v2/openshift/release/blobs/sha256:1" || m.Files[0].Size != 5 {
+		t.Fatalf("unexpected files %#v", m.Files)
+	}
+
+	signer, err := openpgp.NewEntity("test", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeTransferManifest(dir, m, signer); err != nil {
+		t.Fatal(err)
+	}
+	keyring := &bytes.Buffer{}
+	w, err := armor.Encode(keyring, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := signer.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	keyringPath := filepath.Join(t.TempDir(), "keyring.gpg")
+	if err := os.WriteFile(keyringPath, keyring.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, transferManifestName)
+	if _, err := verifyTransferManifest(path, keyringPath, dir); err != nil {
+		t.Fatalf("expected the content to be approved: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(repository, "manifests", "latest"), []byte("manifest"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifyTransferManifest(path, keyringPath, dir); err == nil || !strings.Contains(err.Error(), "v2/openshift/release/manifests/latest is not in the transfer manifest") {
+		t.Errorf("expected added files to be rejected, got %v", err)
+	}
+	if err := os.Remove(filepath.Join(repository, "manifests", "latest")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(repository, "blobs", "sha256:1"), []byte("other"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifyTransferManifest(path, keyringPath, dir); err == nil || !strings.Contains(err.Error(), "v2/openshift/release/blobs/sha256:1 does not match") {
+		t.Errorf("expected modified files to be rejected, got %v", err)
+	}
+
+	if err := os.WriteFile(path, bytes.Replace(mustReadFile(t, path), []byte("sha256:0"), []byte("sha256:9"), 1), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifyTransferManifest(path, keyringPath, dir); err == nil || !strings.Contains(err.Error(), "signature of the transfer manifest is invalid") {
+		t.Errorf("expected a modified manifest to be rejected, got %v", err)
+	}
+}
+
+func mustReadFile(t *testing.T, path string) []byte {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
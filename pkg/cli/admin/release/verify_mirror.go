@@ -0,0 +1,311 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/manifestlist"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/library-go/pkg/image/registryclient"
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	imagemanifest "github.com/openshift/oc/pkg/cli/image/manifest"
+	"github.com/openshift/oc/pkg/cli/image/strategy"
+	"github.com/openshift/oc/pkg/cli/image/workqueue"
+)
+
+func NewVerifyMirrorOptions(streams genericiooptions.IOStreams) *VerifyMirrorOptions {
+	return &VerifyMirrorOptions{
+		IOStreams:       streams,
+		ParallelOptions: imagemanifest.ParallelOptions{MaxPerRegistry: 4},
+	}
+}
+
+func NewVerifyMirror(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewVerifyMirrorOptions(streams)
+	cmd := &cobra.Command{
+		Use:   "verify-mirror --idms-file=FILE RELEASE@DIGEST",
+		Short: "Verify that the images of a release can be pulled from their mirrors",
+		Long: templates.LongDesc(`
+			Verify that the images of a release can be pulled from their mirrors.
+
+			A disconnected cluster pulls the release image and every image it references through
+			the mirrors of its ImageDigestMirrorSet or ImageContentSourcePolicy objects. An image
+			missing from the mirrors is only noticed once the cluster is updating, when the
+			operator in need of that image fails to roll out. This command checks, before the
+			update is requested, that the release and each image of its payload are found by
+			digest in one of the mirrors the file configures for them, that the content served
+			matches the digest, and that the mirror has the config and the layers of each image.
+
+			The source registries are never contacted, and the release must be referenced by
+			digest. The command exits with an error if an image cannot be resolved through the
+			mirrors.
+		`),
+		Example: templates.Examples(`
+			# Verify that the images of a release have been mirrored to the locations of an ImageDigestMirrorSet
+			oc adm release verify-mirror --idms-file=idms.yaml \
+				quay.io/openshift-release-dev/ocp-release@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef
+		`),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run(cmd.Context()))
+		},
+	}
+	flags := cmd.Flags()
+	o.SecurityOptions.Bind(flags)
+	o.FilterOptions.Bind(flags)
+	o.ParallelOptions.Bind(flags)
+
+	flags.StringVar(&o.From, "from", o.From, "The release image to verify, by digest.")
+	flags.StringVar(&o.ICSPFile, "icsp-file", o.ICSPFile, "Path to an ImageContentSourcePolicy file configuring the mirrors of the release images.")
+	flags.MarkDeprecated("icsp-file", "support for it will be removed in a future release. Use --idms-file instead.")
+	flags.StringVar(&o.IDMSFile, "idms-file", o.IDMSFile, "Path to an ImageDigestMirrorSet file configuring the mirrors of the release images.")
+	return cmd
+}
+
+type VerifyMirrorOptions struct {
+	genericiooptions.IOStreams
+
+	SecurityOptions imagemanifest.SecurityOptions
+	FilterOptions   imagemanifest.FilterOptions
+	ParallelOptions imagemanifest.ParallelOptions
+
+	From     string
+	ICSPFile string
+	IDMSFile string
+
+	release reference.DockerImageReference
+	// mirrors returns the mirrors the file configures for the repository of an image.
+	mirrors strategy.Mirrors
+}
+
+// mirroredImage is the outcome of resolving an image through its mirrors.
+type mirroredImage struct {
+	Name   string
+	Source reference.DockerImageReference
+	// Mirror is the location the image was found at, if Err is not set.
+	Mirror reference.DockerImageReference
+	Err    error
+}
+
+func (o *VerifyMirrorOptions) Complete(cmd *cobra.Command, args []string) error {
+	switch {
+	case len(args) == 1 && len(o.From) > 0, len(args) > 1:
+		return fmt.Errorf("you may only specify a single release image via --from or argument")
+	case len(args) == 1:
+		o.From = args[0]
+	}
+	if len(o.From) == 0 {
+		return kcmdutil.UsageErrorf(cmd, "a release image is required")
+	}
+	ref, err := reference.Parse(o.From)
+	if err != nil {
+		return err
+	}
+	o.release = ref
+	return o.FilterOptions.Complete(cmd.Flags())
+}
+
+func (o *VerifyMirrorOptions) Validate() error {
+	switch {
+	case len(o.ICSPFile) > 0 && len(o.IDMSFile) > 0:
+		return fmt.Errorf("icsp-file and idms-file are mutually exclusive")
+	case len(o.ICSPFile) == 0 && len(o.IDMSFile) == 0:
+		return fmt.Errorf("--idms-file is required to locate the mirrors of the release")
+	}
+	if _, err := digest.Parse(o.release.ID); err != nil {
+		return fmt.Errorf("the release image must be referenced by digest, got %s", o.From)
+	}
+	return o.FilterOptions.Validate()
+}
+
+// loadMirrors reads the mirrors of the ICSP or IDMS file.
+func (o *VerifyMirrorOptions) loadMirrors() error {
+	var err error
+	if len(o.ICSPFile) > 0 {
+		o.mirrors, err = strategy.ICSPMirrors(o.ICSPFile)
+	} else {
+		o.mirrors, err = strategy.IDMSMirrors(o.IDMSFile)
+	}
+	return err
+}
+
+func (o *VerifyMirrorOptions) Run(ctx context.Context) error {
+	ctx, cancelFn := context.WithCancel(ctx)
+	defer cancelFn()
+	if err := o.loadMirrors(); err != nil {
+		return err
+	}
+	registryContext, err := o.SecurityOptions.Context()
+	if err != nil {
+		return err
+	}
+	opts := &imagesource.Options{RegistryContext: registryContext, Insecure: o.SecurityOptions.Insecure}
+
+	release := o.resolve(ctx, opts, "release", o.release)
+	if release.Err != nil {
+		return fmt.Errorf("the release image %s cannot be pulled from its mirrors: %v", o.release.Exact(), release.Err)
+	}
+
+	info := NewInfoOptions(o.IOStreams)
+	info.SecurityOptions = o.SecurityOptions
+	info.FilterOptions = o.FilterOptions
+	payload, err := info.LoadReleaseInfo(release.Mirror.Exact(), false)
+	if err != nil {
+		return fmt.Errorf("unable to read the release image from the mirror %s: %v", release.Mirror.Exact(), err)
+	}
+	if payload.References == nil {
+		return fmt.Errorf("the release image %s does not reference any image", o.release.Exact())
+	}
+
+	images := []*mirroredImage{&release}
+	var errs []error
+	for _, tag := range payload.References.Spec.Tags {
+		if tag.From == nil || tag.From.Kind != "DockerImage" {
+			continue
+		}
+		ref, err := reference.Parse(tag.From.Name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("the release image %s references an invalid image for %s: %v", o.release.Exact(), tag.Name, err))
+			continue
+		}
+		images = append(images, &mirroredImage{Name: tag.Name, Source: ref})
+	}
+
+	references := images[1:]
+	q := workqueue.New(o.ParallelOptions.MaxPerRegistry, ctx.Done())
+	q.Batch(func(w workqueue.Work) {
+		for _, image := range references {
+			image := image
+			w.Parallel(func() {
+				*image = o.resolve(ctx, opts, image.Name, image.Source)
+			})
+		}
+	})
+	sort.Slice(references, func(i, j int) bool { return references[i].Name < references[j].Name })
+
+	w := tabwriter.NewWriter(o.Out, 0, 4, 1, ' ', 0)
+	fmt.Fprintf(w, "NAME\tSTATUS\tMIRROR\n")
+	failed := 0
+	for _, image := range images {
+		if image.Err != nil {
+			failed++
+			fmt.Fprintf(w, "%s\tMissing\t%v\n", image.Name, image.Err)
+			continue
+		}
+		fmt.Fprintf(w, "%s\tOK\t%s\n", image.Name, image.Mirror.Exact())
+	}
+	w.Flush()
+
+	if failed > 0 {
+		errs = append(errs, fmt.Errorf("%d of %d images of %s cannot be pulled from their mirrors", failed, len(images), o.release.Exact()))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", errorList(errs))
+	}
+	fmt.Fprintf(o.Out, "\nAll %d images of %s can be pulled from their mirrors\n", len(images), o.release.Exact())
+	return nil
+}
+
+// resolve looks up the image by digest in the mirrors of its repository, in order of preference,
+// and returns the first mirror serving content matching the digest.
+func (o *VerifyMirrorOptions) resolve(ctx context.Context, opts *imagesource.Options, name string, source reference.DockerImageReference) mirroredImage {
+	image := mirroredImage{Name: name, Source: source}
+	dgst, err := digest.Parse(source.ID)
+	if err != nil {
+		image.Err = fmt.Errorf("%s is not referenced by digest", source.Exact())
+		return image
+	}
+	mirrors, err := o.mirrors(source)
+	if err != nil {
+		image.Err = err
+		return image
+	}
+	if len(mirrors) == 0 {
+		image.Err = fmt.Errorf("no mirror is configured for %s", source.AsRepository().Exact())
+		return image
+	}
+	var errs []error
+	for _, mirror := range mirrors {
+		mirror.Tag, mirror.ID = "", source.ID
+		if err := verifyMirroredManifest(ctx, opts, mirror, dgst); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", mirror.Exact(), err))
+			continue
+		}
+		image.Mirror = mirror
+		return image
+	}
+	image.Err = fmt.Errorf("%s", errorList(errs))
+	return image
+}
+
+// verifyMirroredManifest checks that the mirror serves the manifest with the digest, and the
+// manifests of all the images of a manifest list, and that it has the config and the layers of
+// each image.
+func verifyMirroredManifest(ctx context.Context, opts *imagesource.Options, mirror reference.DockerImageReference, dgst digest.Digest) error {
+	repo, err := opts.Repository(ctx, imagesource.TypedImageReference{Type: imagesource.DestinationRegistry, Ref: mirror})
+	if err != nil {
+		return err
+	}
+	manifests, err := repo.Manifests(ctx)
+	if err != nil {
+		return err
+	}
+	blobs := repo.Blobs(ctx)
+	m, err := getVerifiedManifest(ctx, manifests, dgst)
+	if err != nil {
+		return err
+	}
+	list, ok := m.(*manifestlist.DeserializedManifestList)
+	if !ok {
+		return verifyMirroredBlobs(ctx, blobs, m)
+	}
+	for _, child := range list.Manifests {
+		m, err := getVerifiedManifest(ctx, manifests, child.Digest)
+		if err == nil {
+			err = verifyMirroredBlobs(ctx, blobs, m)
+		}
+		if err != nil {
+			return fmt.Errorf("image %s of the manifest list: %v", child.Digest, err)
+		}
+	}
+	return nil
+}
+
+// verifyMirroredBlobs checks that the mirror has the blobs the image manifest references.
+func verifyMirroredBlobs(ctx context.Context, blobs distribution.BlobStatter, m distribution.Manifest) error {
+	for _, blob := range m.References() {
+		if _, err := blobs.Stat(ctx, blob.Digest); err != nil {
+			if err == distribution.ErrBlobUnknown {
+				return fmt.Errorf("the blob %s is missing", blob.Digest)
+			}
+			return fmt.Errorf("unable to check the blob %s: %v", blob.Digest, err)
+		}
+	}
+	return nil
+}
+
+func getVerifiedManifest(ctx context.Context, manifests distribution.ManifestService, dgst digest.Digest) (distribution.Manifest, error) {
+	m, err := manifests.Get(ctx, dgst, imagemanifest.PreferManifestList)
+	if err != nil {
+		return nil, err
+	}
+	contentDigest, err := registryclient.ContentDigestForManifest(m, dgst.Algorithm())
+	if err != nil {
+		return nil, err
+	}
+	if contentDigest != dgst {
+		return nil, fmt.Errorf("the content of the manifest has digest %s", contentDigest)
+	}
+	return m, nil
+}
@@ -0,0 +1,118 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/ocischema"
+	digest "github.com/opencontainers/go-digest"
+	imagespecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	imagemanifest "github.com/openshift/oc/pkg/cli/image/manifest"
+)
+
+func TestVerifyMirrorResolve(t *testing.T) {
+	m, err := ocischema.FromStruct(ocischema.Manifest{
+		Versioned: ocischema.SchemaVersion,
+		Config:    distribution.Descriptor{MediaType: imagespecv1.MediaTypeImageConfig, Digest: digest.FromString("config"), Size: 6},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, payload, err := m.Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dgst := digest.FromBytes(payload)
+	tampered := append([]byte(" "), payload...)
+
+	// the registry serves the manifest and its config in mirror/good, the manifest without its
+	// config in mirror/noblobs, and a different content in mirror/tampered
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/v2/":
+		case fmt.Sprintf("/v2/mirror/good/manifests/%s", dgst), fmt.Sprintf("/v2/mirror/noblobs/manifests/%s", dgst):
+			w.Header().Set("Content-Type", imagespecv1.MediaTypeImageManifest)
+			w.Header().Set("Docker-Content-Digest", dgst.String())
+			w.Write(payload)
+		case fmt.Sprintf("/v2/mirror/good/blobs/%s", digest.FromString("config")):
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Length", "6")
+			w.Header().Set("Docker-Content-Digest", digest.FromString("config").String())
+		case fmt.Sprintf("/v2/mirror/tampered/manifests/%s", dgst):
+			w.Header().Set("Content-Type", imagespecv1.MediaTypeImageManifest)
+			w.Write(tampered)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[{"code":"MANIFEST_UNKNOWN","message":"manifest unknown"}]}`))
+		}
+	}))
+	defer registry.Close()
+	u, _ := url.Parse(registry.URL)
+
+	dir := t.TempDir()
+	idms := func(mirrors ...string) string {
+		file := filepath.Join(dir, fmt.Sprintf("idms-%d.yaml", len(mirrors)))
+		content := "apiVersion: config.openshift.io/v1\nkind: ImageDigestMirrorSet\nmetadata:\n  name: release\nspec:\n  imageDigestMirrors:\n  - source: quay.io/openshift/release\n    mirrors:\n"
+		for _, mirror := range mirrors {
+			content += fmt.Sprintf("    - %s/mirror/%s\n", u.Host, mirror)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+
+	o := NewVerifyMirrorOptions(genericiooptions.NewTestIOStreamsDiscard())
+	o.SecurityOptions = imagemanifest.SecurityOptions{Insecure: true}
+	registryContext, err := o.SecurityOptions.Context()
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := &imagesource.Options{RegistryContext: registryContext, Insecure: true}
+	source, err := reference.Parse("quay.io/openshift/release@" + dgst.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o.IDMSFile = idms("missing", "tampered", "noblobs", "good")
+	if err := o.loadMirrors(); err != nil {
+		t.Fatal(err)
+	}
+	image := o.resolve(context.TODO(), opts, "release", source)
+	if image.Err != nil {
+		t.Fatal(image.Err)
+	}
+	if expected := fmt.Sprintf("%s/mirror/good@%s", u.Host, dgst); image.Mirror.Exact() != expected {
+		t.Errorf("expected the image to be found at %s, got %s", expected, image.Mirror.Exact())
+	}
+
+	o.IDMSFile = idms("missing", "tampered", "noblobs")
+	if err := o.loadMirrors(); err != nil {
+		t.Fatal(err)
+	}
+	image = o.resolve(context.TODO(), opts, "release", source)
+	if image.Err == nil || !strings.Contains(image.Err.Error(), "mirror/tampered@"+dgst.String()+":") {
+		t.Errorf("expected the tampered mirror to be reported, got %v", image.Err)
+	}
+	if image.Err == nil || !strings.Contains(image.Err.Error(), "mirror/noblobs@"+dgst.String()+": the blob "+digest.FromString("config").String()+" is missing") {
+		t.Errorf("expected the missing blob to be reported, got %v", image.Err)
+	}
+
+	other, _ := reference.Parse("quay.io/other/image@" + dgst.String())
+	if image := o.resolve(context.TODO(), opts, "other", other); image.Err == nil || !strings.Contains(image.Err.Error(), "no mirror is configured") {
+		t.Errorf("expected an error without mirrors, got %v", image.Err)
+	}
+}
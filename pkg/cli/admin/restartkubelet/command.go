@@ -15,30 +15,34 @@ import (
 
 var (
 	regenerateSignersLong = templates.LongDesc(`
-		Regenerate certificates provided by an OCP v4 cluster.
+		Restart kubelet on the specified nodes through a privileged pod on each node.
 
-		This command does not wait for changes to be acknowledged by the cluster.
-		Some may take a very long time to roll out into a cluster, with different operators and operands involved for each.
+		Kubelet is restarted on one node at a time by default, use --max-unavailable to restart more
+		nodes at the same time. The nodes to restart are listed and a confirmation is asked for before
+		any of them is restarted, unless --confirm is set. A node is only done once kubelet is active
+		again and the node reports it is ready, and the remaining nodes are left untouched after the
+		first node that fails to restart or to be ready within --ready-timeout.
 
 		Experimental: This command is under active development and may change without notice.
 	`)
 
 	regenerateSignersExample = templates.Examples(`
-		# Restart all the nodes, 10% at a time
+		# Restart all the nodes, one at a time
 		oc adm restart-kubelet nodes --all --directive=RemoveKubeletKubeconfig
 
-		# Restart all the nodes, 20 nodes at a time
-		oc adm restart-kubelet nodes --all --parallelism=20 --directive=RemoveKubeletKubeconfig
+		# Restart all the nodes, 20 nodes at a time, without asking for confirmation
+		oc adm restart-kubelet nodes --all --max-unavailable=20 --confirm --directive=RemoveKubeletKubeconfig
 
 		# Restart all the nodes, 15% at a time
-		oc adm restart-kubelet nodes --all --parallelism=15% --directive=RemoveKubeletKubeconfig
+		oc adm restart-kubelet nodes --all --max-unavailable=15% --directive=RemoveKubeletKubeconfig
 
 		# Restart all the masters at the same time
-		oc adm restart-kubelet nodes -l node-role.kubernetes.io/master --parallelism=100% --directive=RemoveKubeletKubeconfig`)
+		oc adm restart-kubelet nodes -l node-role.kubernetes.io/master --max-unavailable=100% --directive=RemoveKubeletKubeconfig`)
 )
 
 type RestartKubeletOptions struct {
 	PerNodePodOptions *pernodepod.PerNodePodOptions
+	GuardOptions      *RestartGuardOptions
 
 	CommandWhileKubeletIsOff string
 	Directive                string
@@ -54,6 +58,7 @@ func NewRestartKubelet(restClientGetter genericclioptions.RESTClientGetter, stre
 			restClientGetter,
 			streams,
 		),
+		GuardOptions: NewRestartGuardOptions("kubelet", streams),
 
 		IOStreams: streams,
 	}
@@ -72,6 +77,7 @@ func NewCmdRestartKubelet(restClientGetter genericclioptions.RESTClientGetter, s
 			ctx, cancel := pernodepod.SignalContext()
 			defer cancel()
 
+			cmdutil.CheckErr(o.GuardOptions.Complete(cmd, o.PerNodePodOptions))
 			r, err := o.ToRuntime(args)
 			cmdutil.CheckErr(err)
 			cmdutil.CheckErr(r.Run(ctx))
@@ -86,6 +92,7 @@ func NewCmdRestartKubelet(restClientGetter genericclioptions.RESTClientGetter, s
 // AddFlags registers flags for a cli
 func (o *RestartKubeletOptions) AddFlags(cmd *cobra.Command) {
 	o.PerNodePodOptions.AddFlags(cmd)
+	o.GuardOptions.AddFlags(cmd)
 
 	cmd.Flags().StringVar(&o.CommandWhileKubeletIsOff, "command", o.CommandWhileKubeletIsOff, "command to run after the kubelet stops, before the kubelet starts.")
 	cmd.Flags().StringVar(&o.Directive, "directive", o.Directive, "run a well-known command while restarting kubelets: RemoveKubeletKubeconfig")
@@ -97,6 +104,7 @@ func (o *RestartKubeletOptions) ToRuntime(args []string) (*RestartKubeletRuntime
 	}
 	commandWhileKubeletIsOff := o.CommandWhileKubeletIsOff
 	switch o.Directive {
+	case "":
 	case "RemoveKubeletKubeconfig":
 		commandWhileKubeletIsOff = "rm -f /host-root/var/lib/kubelet/kubeconfig"
 	default:
@@ -107,6 +115,7 @@ func (o *RestartKubeletOptions) ToRuntime(args []string) (*RestartKubeletRuntime
 	if err != nil {
 		return nil, err
 	}
+	o.GuardOptions.Guard(perNodePodRuntime)
 	return &RestartKubeletRuntime{
 		PerNodePodRuntime:        perNodePodRuntime,
 		CommandWhileKubeletIsOff: commandWhileKubeletIsOff,
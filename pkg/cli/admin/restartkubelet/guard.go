@@ -0,0 +1,139 @@
+package restartkubelet
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+
+	"github.com/openshift/oc/pkg/cli/admin/pernodepod"
+	"github.com/openshift/oc/pkg/helpers/term"
+)
+
+// RestartGuardOptions guard the restart of a service of the nodes: the nodes are restarted a few at
+// a time, only once confirmed, and a node is only done once it is ready again.
+type RestartGuardOptions struct {
+	// Service is the systemd unit restarted, used in the prompt.
+	Service string
+
+	MaxUnavailable string
+	Confirm        bool
+	ReadyTimeout   time.Duration
+
+	genericiooptions.IOStreams
+}
+
+func NewRestartGuardOptions(service string, streams genericiooptions.IOStreams) *RestartGuardOptions {
+	return &RestartGuardOptions{
+		Service:        service,
+		MaxUnavailable: "1",
+		ReadyTimeout:   5 * time.Minute,
+
+		IOStreams: streams,
+	}
+}
+
+// AddFlags registers flags for a cli, after the flags of the per-node pods, whose --parallelism is
+// superseded by --max-unavailable.
+func (o *RestartGuardOptions) AddFlags(cmd *cobra.Command) {
+	_ = cmd.Flags().MarkDeprecated("parallelism", "use --max-unavailable instead")
+	cmd.Flags().StringVar(&o.MaxUnavailable, "max-unavailable", o.MaxUnavailable, fmt.Sprintf("The number or percentage (N or N%%) of nodes %s is restarted on at the same time. Cannot be used with --parallelism.", o.Service))
+	cmd.Flags().BoolVar(&o.Confirm, "confirm", o.Confirm, "Restart without asking for confirmation. Required when the input is not a terminal.")
+	cmd.Flags().DurationVar(&o.ReadyTimeout, "ready-timeout", o.ReadyTimeout, "The time to wait for a node to be ready again once restarted. The remaining nodes are not restarted if a node is not ready in time.")
+}
+
+// Complete sets the parallelism of the per-node pods from --max-unavailable, unless the deprecated
+// --parallelism was set.
+func (o *RestartGuardOptions) Complete(cmd *cobra.Command, perNodePodOptions *pernodepod.PerNodePodOptions) error {
+	parallelismSet := cmd.Flags().Changed("parallelism")
+	if parallelismSet && cmd.Flags().Changed("max-unavailable") {
+		return fmt.Errorf("only one of --max-unavailable and --parallelism can be set")
+	}
+	if !parallelismSet {
+		perNodePodOptions.Parallelism = o.MaxUnavailable
+	}
+	if o.ReadyTimeout <= 0 {
+		return fmt.Errorf("--ready-timeout must be positive")
+	}
+	return nil
+}
+
+// Guard makes the per-node pods stop on the first failure, ask for confirmation before any node is
+// restarted, and wait for each node to be ready once restarted.
+func (o *RestartGuardOptions) Guard(r *pernodepod.PerNodePodRuntime) {
+	r.StopOnFailure = true
+	r.ConfirmFn = o.confirm
+	r.VerifyNodeFn = func(ctx context.Context, nodeName string, pod *corev1.Pod) error {
+		return o.waitForNodeReady(ctx, r, nodeName, restartTime(pod))
+	}
+}
+
+func (o *RestartGuardOptions) confirm(nodes []*corev1.Node) error {
+	if len(nodes) == 0 || o.Confirm {
+		return nil
+	}
+	if !term.IsTerminalReader(o.In) {
+		return fmt.Errorf("refusing to restart %s on %d nodes without --confirm", o.Service, len(nodes))
+	}
+	names := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	fmt.Fprintf(o.Out, "%s will be restarted on the following nodes, %s at a time:\n  %s\n", o.Service, o.MaxUnavailable, strings.Join(names, "\n  "))
+	if !term.PromptForBool(o.In, o.Out, "Continue? (y/n): ") {
+		return fmt.Errorf("restart of %s cancelled", o.Service)
+	}
+	return nil
+}
+
+// restartTime returns when the restart pod finished, which it only does once the service is
+// active again, as reported by the node. It falls back to the creation of the pod.
+func restartTime(pod *corev1.Pod) time.Time {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated != nil && !status.State.Terminated.FinishedAt.IsZero() {
+			return status.State.Terminated.FinishedAt.Time
+		}
+	}
+	return pod.CreationTimestamp.Time
+}
+
+// waitForNodeReady waits for the node to report it is ready after restartedAt, so that the next
+// nodes are only restarted once a restarted node serves its workloads again. A Ready condition
+// neither updated nor changed since the restart is stale, reported before the service stopped.
+func (o *RestartGuardOptions) waitForNodeReady(ctx context.Context, r *pernodepod.PerNodePodRuntime, nodeName string, restartedAt time.Time) error {
+	var lastCondition *corev1.NodeCondition
+	err := wait.PollUntilContextTimeout(ctx, 5*time.Second, o.ReadyTimeout, true, func(ctx context.Context) (bool, error) {
+		node, err := r.KubeClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err != nil {
+			// the API may be unavailable while the service restarts
+			return false, nil
+		}
+		for i := range node.Status.Conditions {
+			if node.Status.Conditions[i].Type == corev1.NodeReady {
+				lastCondition = &node.Status.Conditions[i]
+				return lastCondition.Status == corev1.ConditionTrue && reportedAfter(lastCondition, restartedAt), nil
+			}
+		}
+		return false, nil
+	})
+	if err == nil {
+		return nil
+	}
+	if lastCondition != nil {
+		if lastCondition.Status == corev1.ConditionTrue {
+			return fmt.Errorf("node has not reported it is ready since the restart at %s after %v", restartedAt.Format(time.RFC3339), o.ReadyTimeout)
+		}
+		return fmt.Errorf("node is not ready after %v: %s: %s", o.ReadyTimeout, lastCondition.Reason, lastCondition.Message)
+	}
+	return fmt.Errorf("node is not ready after %v: %w", o.ReadyTimeout, err)
+}
+
+func reportedAfter(condition *corev1.NodeCondition, t time.Time) bool {
+	return condition.LastHeartbeatTime.After(t) || condition.LastTransitionTime.After(t)
+}
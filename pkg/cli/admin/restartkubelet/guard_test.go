@@ -0,0 +1,80 @@
+package restartkubelet
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/oc/pkg/cli/admin/pernodepod"
+)
+
+func TestWaitForNodeReady(t *testing.T) {
+	restartedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	before, after := metav1.NewTime(restartedAt.Add(-time.Minute)), metav1.NewTime(restartedAt.Add(time.Second))
+	tests := []struct {
+		name          string
+		condition     *corev1.NodeCondition
+		expectedError string
+	}{
+		{
+			name:      "heartbeat after the restart",
+			condition: &corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionTrue, LastHeartbeatTime: after, LastTransitionTime: before},
+		},
+		{
+			name:      "ready again after the restart",
+			condition: &corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionTrue, LastHeartbeatTime: before, LastTransitionTime: after},
+		},
+		{
+			name:          "ready before the restart",
+			condition:     &corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionTrue, LastHeartbeatTime: before, LastTransitionTime: before},
+			expectedError: "node has not reported it is ready since the restart at 2024-01-01T12:00:00Z",
+		},
+		{
+			name:          "not ready",
+			condition:     &corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionFalse, LastHeartbeatTime: after, Reason: "KubeletNotReady", Message: "PLEG is not healthy"},
+			expectedError: "node is not ready after 1s: KubeletNotReady: PLEG is not healthy",
+		},
+		{
+			name:          "no ready condition",
+			expectedError: "node is not ready after 1s",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+			if test.condition != nil {
+				node.Status.Conditions = []corev1.NodeCondition{*test.condition}
+			}
+			r := &pernodepod.PerNodePodRuntime{KubeClient: fake.NewSimpleClientset(node)}
+			o := &RestartGuardOptions{ReadyTimeout: time.Second}
+			err := o.waitForNodeReady(context.Background(), r, "node", restartedAt)
+			if len(test.expectedError) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+				t.Fatalf("expected error %q, got %v", test.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestRestartTime(t *testing.T) {
+	created := metav1.NewTime(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	finished := metav1.NewTime(created.Add(time.Minute))
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created}}
+	if restarted := restartTime(pod); !restarted.Equal(created.Time) {
+		t.Errorf("expected the creation time without a terminated container, got %s", restarted)
+	}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{FinishedAt: finished}}}}
+	if restarted := restartTime(pod); !restarted.Equal(finished.Time) {
+		t.Errorf("expected the time the container finished, got %s", restarted)
+	}
+}
@@ -0,0 +1,124 @@
+package restartkubelet
+
+import (
+	"context"
+	_ "embed"
+
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+	"github.com/openshift/oc/pkg/cli/admin/pernodepod"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	//go:embed restart-crio-pod-template.yaml
+	crioPodYaml []byte
+	crioPod     = resourceread.ReadPodV1OrDie(crioPodYaml)
+
+	restartCRIOLong = templates.LongDesc(`
+		Restart CRI-O on the specified nodes through a privileged pod on each node.
+
+		CRI-O is restarted on one node at a time by default, use --max-unavailable to restart more
+		nodes at the same time. The running containers are kept while CRI-O restarts. The nodes to
+		restart are listed and a confirmation is asked for before any of them is restarted, unless
+		--confirm is set. A node is only done once CRI-O is active again and the node reports it is
+		ready, and the remaining nodes are left untouched after the first node that fails to restart
+		or to be ready within --ready-timeout.
+
+		Experimental: This command is under active development and may change without notice.
+	`)
+
+	restartCRIOExample = templates.Examples(`
+		# Restart CRI-O on all the worker nodes, one at a time
+		oc adm restart-crio nodes -l node-role.kubernetes.io/worker
+
+		# Restart CRI-O on all the nodes, 10% at a time, without asking for confirmation
+		oc adm restart-crio nodes --all --max-unavailable=10% --confirm
+
+		# List the nodes CRI-O would be restarted on
+		oc adm restart-crio nodes --all --dry-run`)
+)
+
+type RestartCRIOOptions struct {
+	PerNodePodOptions *pernodepod.PerNodePodOptions
+	GuardOptions      *RestartGuardOptions
+
+	genericiooptions.IOStreams
+}
+
+func NewRestartCRIO(restClientGetter genericclioptions.RESTClientGetter, streams genericiooptions.IOStreams) *RestartCRIOOptions {
+	return &RestartCRIOOptions{
+		PerNodePodOptions: pernodepod.NewPerNodePodOptions(
+			"openshift-restart-crio-",
+			"restarted crio",
+			restClientGetter,
+			streams,
+		),
+		GuardOptions: NewRestartGuardOptions("crio", streams),
+
+		IOStreams: streams,
+	}
+}
+
+func NewCmdRestartCRIO(restClientGetter genericclioptions.RESTClientGetter, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewRestartCRIO(restClientGetter, streams)
+
+	cmd := &cobra.Command{
+		Use:                   "restart-crio",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Restart CRI-O on the specified nodes"),
+		Long:                  restartCRIOLong,
+		Example:               restartCRIOExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := pernodepod.SignalContext()
+			defer cancel()
+
+			cmdutil.CheckErr(o.GuardOptions.Complete(cmd, o.PerNodePodOptions))
+			r, err := o.ToRuntime(args)
+			cmdutil.CheckErr(err)
+			cmdutil.CheckErr(r.Run(ctx))
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
+// AddFlags registers flags for a cli
+func (o *RestartCRIOOptions) AddFlags(cmd *cobra.Command) {
+	o.PerNodePodOptions.AddFlags(cmd)
+	o.GuardOptions.AddFlags(cmd)
+}
+
+func (o *RestartCRIOOptions) ToRuntime(args []string) (*RestartCRIORuntime, error) {
+	perNodePodRuntime, err := o.PerNodePodOptions.ToRuntime(args)
+	if err != nil {
+		return nil, err
+	}
+	o.GuardOptions.Guard(perNodePodRuntime)
+	return &RestartCRIORuntime{
+		PerNodePodRuntime: perNodePodRuntime,
+	}, nil
+}
+
+type RestartCRIORuntime struct {
+	PerNodePodRuntime *pernodepod.PerNodePodRuntime
+}
+
+func (r *RestartCRIORuntime) Run(ctx context.Context) error {
+	return r.PerNodePodRuntime.Run(ctx, nil, r.createPod)
+}
+
+func (r *RestartCRIORuntime) createPod(ctx context.Context, namespaceName, nodeName, imagePullSpec string) (*corev1.Pod, error) {
+	restartObj := crioPod.DeepCopy()
+	restartObj.Namespace = namespaceName
+	restartObj.Spec.NodeName = nodeName
+	restartObj.Spec.Containers[0].Image = imagePullSpec
+	return restartObj, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/docker/go-units"
@@ -39,11 +40,18 @@ var (
 
 		This command analyzes all the images managed by the platform and presents current
 		usage statistics.
+
+		Pass --sum-by to sum the storage of the images by the namespaces or the image streams
+		referring to them, an image referred to by several of them being accounted to each, and
+		--output=json or csv to generate reports, in which the storage is in bytes.
 	`)
 
 	topImagesExample = templates.Examples(`
 		# Show usage statistics for images
 		oc adm top images
+
+		# Generate a CSV report of the storage used by the images of each namespace
+		oc adm top images --sum-by=namespace -o csv
 	`)
 )
 
@@ -53,6 +61,9 @@ type TopImagesOptions struct {
 	Streams *imagev1.ImageStreamList
 	Pods    *corev1.PodList
 
+	Output string
+	SumBy  string
+
 	genericiooptions.IOStreams
 }
 
@@ -77,6 +88,9 @@ func NewCmdTopImages(f kcmdutil.Factory, streams genericiooptions.IOStreams) *co
 		},
 	}
 
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json, csv.")
+	cmd.Flags().StringVar(&o.SumBy, "sum-by", o.SumBy, "Sum the usage of the images by namespace or imagestream.")
+
 	return cmd
 }
 
@@ -124,26 +138,31 @@ func (o *TopImagesOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args
 
 // Validate ensures that a TopImagesOptions is valid and can be used to execute command.
 func (o TopImagesOptions) Validate(cmd *cobra.Command) error {
-	return nil
+	if err := validateOutput(o.Output); err != nil {
+		return err
+	}
+	return validateSumBy(o.SumBy)
 }
 
 // Run contains all the necessary functionality to show current image references.
 func (o TopImagesOptions) Run() error {
+	if len(o.SumBy) > 0 {
+		return PrintOutput(o.Out, o.Output, sumColumns(o.SumBy), o.imagesSum())
+	}
 	infos := o.imagesTop()
-	Print(o.Out, ImageColumns, infos)
-	return nil
+	return PrintOutput(o.Out, o.Output, ImageColumns, infos)
 }
 
 var ImageColumns = []string{"NAME", "IMAGESTREAMTAG", "PARENTS", "USAGE", "METADATA", "STORAGE"}
 
 // imageInfo contains statistic information about Image usage.
 type imageInfo struct {
-	Image           string
-	ImageStreamTags []string
-	Parents         []string
-	Usage           []string
-	Metadata        bool
-	Storage         int64
+	Image           string   `json:"image"`
+	ImageStreamTags []string `json:"imageStreamTags"`
+	Parents         []string `json:"parents"`
+	Usage           []string `json:"usage"`
+	Metadata        bool     `json:"metadata"`
+	Storage         int64    `json:"storage"`
 }
 
 var _ Info = &imageInfo{}
@@ -165,6 +184,25 @@ func (i imageInfo) PrintLine(out io.Writer) {
 	printValue(out, units.BytesSize(float64(i.Storage)))
 }
 
+func (i imageInfo) Values() []string {
+	return []string{
+		i.Image,
+		strings.Join(i.ImageStreamTags, ";"),
+		strings.Join(i.Parents, ";"),
+		strings.Join(i.Usage, ";"),
+		strconv.FormatBool(i.Metadata),
+		strconv.FormatInt(i.Storage, 10),
+	}
+}
+
+// imagesSum sums the image usage by namespace or image stream.
+func (o TopImagesOptions) imagesSum() []Info {
+	g := genericgraph.New()
+	addImagesToGraph(g, o.Images)
+	addImageStreamsToGraph(g, o.Streams)
+	return sumImages(g, o.SumBy)
+}
+
 // imagesTop generates Image information from a graph and returns this as a list
 // of imageInfo array.
 func (o TopImagesOptions) imagesTop() []Info {
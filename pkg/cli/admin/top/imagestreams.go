@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strconv"
+	"strings"
 
 	units "github.com/docker/go-units"
 	gonum "github.com/gonum/graph"
@@ -32,11 +34,17 @@ var (
 
 		This command analyzes all the image streams managed by the platform and presents current
 		usage statistics.
+
+		Pass --sum-by=namespace to sum the usage of the image streams of each namespace, and
+		--output=json or csv to generate reports, in which the storage is in bytes.
 	`)
 
 	topImageStreamsExample = templates.Examples(`
 		# Show usage statistics for image streams
 		oc adm top imagestreams
+
+		# Generate a JSON report of the storage used by the image streams of each namespace
+		oc adm top imagestreams --sum-by=namespace -o json
 	`)
 )
 
@@ -45,6 +53,9 @@ type TopImageStreamsOptions struct {
 	Images  *imagev1.ImageList
 	Streams *imagev1.ImageStreamList
 
+	Output string
+	SumBy  string
+
 	genericiooptions.IOStreams
 }
 
@@ -70,6 +81,9 @@ func NewCmdTopImageStreams(f kcmdutil.Factory, streams genericiooptions.IOStream
 		Aliases: []string{"imagestreams", "is"},
 	}
 
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json, csv.")
+	cmd.Flags().StringVar(&o.SumBy, "sum-by", o.SumBy, "Sum the usage of the image streams by namespace or imagestream, the default.")
+
 	return cmd
 }
 
@@ -106,24 +120,29 @@ func (o *TopImageStreamsOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command
 
 // Validate ensures that a TopImageStreamsOptions is valid and can be used to execute command.
 func (o TopImageStreamsOptions) Validate(cmd *cobra.Command) error {
-	return nil
+	if err := validateOutput(o.Output); err != nil {
+		return err
+	}
+	return validateSumBy(o.SumBy)
 }
 
 // Run contains all the necessary functionality to show current image references.
 func (o TopImageStreamsOptions) Run() error {
 	infos := o.imageStreamsTop()
-	Print(o.Out, ImageStreamColumns, infos)
-	return nil
+	if o.SumBy == sumByNamespace {
+		return PrintOutput(o.Out, o.Output, sumColumns(o.SumBy), sumImageStreams(infos))
+	}
+	return PrintOutput(o.Out, o.Output, ImageStreamColumns, infos)
 }
 
 var ImageStreamColumns = []string{"NAME", "STORAGE", "IMAGES", "LAYERS"}
 
 // imageStreamInfo contains contains statistic information about ImageStream usage.
 type imageStreamInfo struct {
-	ImageStream string
-	Storage     int64
-	Images      int
-	Layers      int
+	ImageStream string `json:"imageStream"`
+	Storage     int64  `json:"storage"`
+	Images      int    `json:"images"`
+	Layers      int    `json:"layers"`
 }
 
 var _ Info = &imageStreamInfo{}
@@ -135,6 +154,28 @@ func (i imageStreamInfo) PrintLine(out io.Writer) {
 	printValue(out, i.Layers)
 }
 
+func (i imageStreamInfo) Values() []string {
+	return []string{i.ImageStream, strconv.FormatInt(i.Storage, 10), strconv.Itoa(i.Images), strconv.Itoa(i.Layers)}
+}
+
+// sumImageStreams sums the usage of the image streams of each namespace. The
+// layers shared by image streams are accounted to each of them.
+func sumImageStreams(infos []Info) []Info {
+	sums := map[string]*sumInfo{}
+	for _, info := range infos {
+		stream := info.(imageStreamInfo)
+		namespace, _, _ := strings.Cut(stream.ImageStream, "/")
+		sum, ok := sums[namespace]
+		if !ok {
+			sum = &sumInfo{Name: namespace}
+			sums[namespace] = sum
+		}
+		sum.Images += stream.Images
+		sum.Storage += stream.Storage
+	}
+	return sortSums(sums)
+}
+
 // imageStreamsTop generates ImageStream information from a graph and
 // returns this as a list of imageStreamInfo array.
 func (o TopImageStreamsOptions) imageStreamsTop() []Info {
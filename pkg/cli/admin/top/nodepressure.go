@@ -0,0 +1,300 @@
+package top
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubectl/pkg/cmd/top"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+
+	"github.com/openshift/oc/pkg/helpers/parallel"
+)
+
+// maxConcurrentSummaries is the number of nodes whose summary is read at the same time.
+const maxConcurrentSummaries = 10
+
+// nodeSummary is the part of the summary API of the kubelet, /stats/summary, read to show the
+// pressure of the nodes.
+type nodeSummary struct {
+	Node struct {
+		CPU    *resourceStats `json:"cpu,omitempty"`
+		Memory *resourceStats `json:"memory,omitempty"`
+		IO     *resourceStats `json:"io,omitempty"`
+	} `json:"node"`
+	Pods []podSummary `json:"pods,omitempty"`
+}
+
+type podSummary struct {
+	PodRef struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"podRef"`
+	CPU              *resourceStats `json:"cpu,omitempty"`
+	Memory           *resourceStats `json:"memory,omitempty"`
+	IO               *resourceStats `json:"io,omitempty"`
+	EphemeralStorage *struct {
+		UsedBytes *uint64 `json:"usedBytes,omitempty"`
+	} `json:"ephemeral-storage,omitempty"`
+}
+
+// resourceStats holds the pressure stall information, PSI, reported by kubelets with the
+// KubeletPSI feature enabled, and the usage of the resource.
+type resourceStats struct {
+	PSI *struct {
+		Some struct {
+			Avg10 float64 `json:"avg10"`
+		} `json:"some"`
+	} `json:"psi,omitempty"`
+	WorkingSetBytes *uint64 `json:"workingSetBytes,omitempty"`
+}
+
+// someAvg10 returns the share of the last 10 seconds in which some tasks stalled on the
+// resource, in percent, and whether the kubelet reports it.
+func (s *resourceStats) someAvg10() (float64, bool) {
+	if s == nil || s.PSI == nil {
+		return 0, false
+	}
+	return s.PSI.Some.Avg10, true
+}
+
+// pressureConditions are the node conditions reported when they are true.
+var pressureConditions = []corev1.NodeConditionType{corev1.NodeMemoryPressure, corev1.NodeDiskPressure, corev1.NodePIDPressure}
+
+var NodePressureColumns = []string{"NAME", "CPU-PSI", "MEMORY-PSI", "IO-PSI", "CONDITIONS", "TOP PODS"}
+
+// nodePressureInfo is the pressure on a node and the pods under the most pressure on it.
+type nodePressureInfo struct {
+	Node string
+	// CPU, Memory and IO are the pressure stall information of the node, when reported
+	CPU        *float64
+	Memory     *float64
+	IO         *float64
+	Conditions []string
+	TopPods    []string
+}
+
+func (i nodePressureInfo) PrintLine(out io.Writer) {
+	printValue(out, i.Node)
+	printValue(out, formatPSI(i.CPU))
+	printValue(out, formatPSI(i.Memory))
+	printValue(out, formatPSI(i.IO))
+	printArray(out, i.Conditions)
+	printArray(out, i.TopPods)
+}
+
+func formatPSI(value *float64) string {
+	if value == nil {
+		return "<unknown>"
+	}
+	return fmt.Sprintf("%.2f%%", *value)
+}
+
+// maxPressure returns the highest pressure of the info, to sort the nodes by.
+func (i nodePressureInfo) maxPressure() float64 {
+	max := 0.0
+	for _, value := range []*float64{i.CPU, i.Memory, i.IO} {
+		if value != nil && *value > max {
+			max = *value
+		}
+	}
+	return max
+}
+
+// TopNodePressureOptions shows the pressure on the nodes instead of their usage.
+type TopNodePressureOptions struct {
+	Pressure bool
+	TopPods  int
+
+	*top.TopNodeOptions
+}
+
+// AddPressureFlags adds the flags to show the pressure on the nodes to the top node command,
+// which shows it instead of the usage of the nodes with --pressure.
+func AddPressureFlags(f kcmdutil.Factory, cmd *cobra.Command, topNodeOptions *top.TopNodeOptions) {
+	o := &TopNodePressureOptions{TopPods: 3, TopNodeOptions: topNodeOptions}
+	cmd.Flags().BoolVar(&o.Pressure, "pressure", o.Pressure, "If true, show the pressure stall information of the CPU, memory and IO of the nodes, their pressure conditions and the pods under the most pressure, read from the summary API of the kubelets.")
+	cmd.Flags().IntVar(&o.TopPods, "top-pods", o.TopPods, "The number of pods under the most pressure to show per node with --pressure.")
+
+	run := cmd.Run
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		if !o.Pressure {
+			run(cmd, args)
+			return
+		}
+		kcmdutil.CheckErr(o.Complete(f, cmd, args))
+		kcmdutil.CheckErr(o.Validate())
+		kcmdutil.CheckErr(o.Run(context.TODO()))
+	}
+}
+
+func (o *TopNodePressureOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) == 1 {
+		o.ResourceName = args[0]
+	} else if len(args) > 1 {
+		return kcmdutil.UsageErrorf(cmd, "%s", cmd.Use)
+	}
+	clientset, err := f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+	o.NodeClient = clientset.CoreV1()
+	return nil
+}
+
+func (o *TopNodePressureOptions) Validate() error {
+	if len(o.SortBy) > 0 {
+		return fmt.Errorf("--sort-by is not supported with --pressure, the nodes are sorted by their highest pressure")
+	}
+	if o.TopPods < 0 {
+		return fmt.Errorf("--top-pods must be positive")
+	}
+	return nil
+}
+
+func (o *TopNodePressureOptions) Run(ctx context.Context) error {
+	var nodes []corev1.Node
+	if len(o.ResourceName) > 0 {
+		node, err := o.NodeClient.Nodes().Get(ctx, o.ResourceName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		nodes = append(nodes, *node)
+	} else {
+		nodeList, err := o.NodeClient.Nodes().List(ctx, metav1.ListOptions{LabelSelector: o.Selector})
+		if err != nil {
+			return err
+		}
+		nodes = nodeList.Items
+	}
+	if len(nodes) == 0 {
+		fmt.Fprintln(o.ErrOut, "No resources found")
+		return nil
+	}
+
+	infos := make([]nodePressureInfo, len(nodes))
+	lock := sync.Mutex{}
+	limit := make(chan struct{}, maxConcurrentSummaries)
+	var fns []func() error
+	for i := range nodes {
+		fns = append(fns, func() error {
+			limit <- struct{}{}
+			defer func() { <-limit }()
+			summary, err := o.summary(ctx, nodes[i].Name)
+			if err != nil {
+				lock.Lock()
+				defer lock.Unlock()
+				fmt.Fprintf(o.ErrOut, "warning: unable to read the summary of node %s: %v\n", nodes[i].Name, err)
+			}
+			infos[i] = newNodePressureInfo(&nodes[i], summary, o.TopPods)
+			return nil
+		})
+	}
+	parallel.Run(fns...)
+
+	sort.SliceStable(infos, func(i, j int) bool {
+		if infos[i].maxPressure() != infos[j].maxPressure() {
+			return infos[i].maxPressure() > infos[j].maxPressure()
+		}
+		return infos[i].Node < infos[j].Node
+	})
+	s := tabbedString(func(out *tabwriter.Writer) {
+		if !o.NoHeaders {
+			printHeader(out, NodePressureColumns)
+		}
+		for _, info := range infos {
+			info.PrintLine(out)
+			fmt.Fprintf(out, "\n")
+		}
+	})
+	fmt.Fprintf(o.Out, "%s", s)
+	return nil
+}
+
+func (o *TopNodePressureOptions) summary(ctx context.Context, node string) (*nodeSummary, error) {
+	data, err := o.NodeClient.RESTClient().Get().
+		Resource("nodes").
+		Name(node).
+		SubResource("proxy").
+		Suffix("stats/summary").
+		DoRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	summary := &nodeSummary{}
+	if err := json.Unmarshal(data, summary); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// newNodePressureInfo returns the pressure on the node, with the topPods pods under the most
+// pressure: the pods stalling the most on any resource or, when the kubelet does not report
+// their PSI, the pods with the largest working set and ephemeral storage.
+func newNodePressureInfo(node *corev1.Node, summary *nodeSummary, topPods int) nodePressureInfo {
+	info := nodePressureInfo{Node: node.Name}
+	for _, conditionType := range pressureConditions {
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+				info.Conditions = append(info.Conditions, string(condition.Type))
+			}
+		}
+	}
+	if summary == nil {
+		return info
+	}
+	info.CPU = psiOrNil(summary.Node.CPU)
+	info.Memory = psiOrNil(summary.Node.Memory)
+	info.IO = psiOrNil(summary.Node.IO)
+
+	type podPressure struct {
+		name     string
+		pressure float64
+		usage    uint64
+	}
+	var pods []podPressure
+	for _, pod := range summary.Pods {
+		p := podPressure{name: pod.PodRef.Namespace + "/" + pod.PodRef.Name}
+		for _, stats := range []*resourceStats{pod.CPU, pod.Memory, pod.IO} {
+			if value, ok := stats.someAvg10(); ok && value > p.pressure {
+				p.pressure = value
+			}
+		}
+		if pod.Memory != nil && pod.Memory.WorkingSetBytes != nil {
+			p.usage += *pod.Memory.WorkingSetBytes
+		}
+		if pod.EphemeralStorage != nil && pod.EphemeralStorage.UsedBytes != nil {
+			p.usage += *pod.EphemeralStorage.UsedBytes
+		}
+		pods = append(pods, p)
+	}
+	sort.SliceStable(pods, func(i, j int) bool {
+		if pods[i].pressure != pods[j].pressure {
+			return pods[i].pressure > pods[j].pressure
+		}
+		if pods[i].usage != pods[j].usage {
+			return pods[i].usage > pods[j].usage
+		}
+		return pods[i].name < pods[j].name
+	})
+	for i := 0; i < len(pods) && i < topPods; i++ {
+		info.TopPods = append(info.TopPods, pods[i].name)
+	}
+	return info
+}
+
+func psiOrNil(stats *resourceStats) *float64 {
+	value, ok := stats.someAvg10()
+	if !ok {
+		return nil
+	}
+	return &value
+}
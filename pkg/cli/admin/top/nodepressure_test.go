@@ -0,0 +1,62 @@
+package top
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testSummary = `{
+	"node": {
+		"nodeName": "worker-0",
+		"cpu": {"usageNanoCores": 1500000000, "psi": {"full": {"avg10": 0}, "some": {"avg10": 12.5, "avg60": 8, "avg300": 3, "total": 1000}}},
+		"memory": {"workingSetBytes": 8000000000, "psi": {"full": {"avg10": 1}, "some": {"avg10": 3.25}}}
+	},
+	"pods": [
+		{"podRef": {"name": "idle", "namespace": "ns1"}, "memory": {"workingSetBytes": 100}},
+		{"podRef": {"name": "hungry", "namespace": "ns1"}, "memory": {"workingSetBytes": 1000, "psi": {"some": {"avg10": 9.5}}}},
+		{"podRef": {"name": "writer", "namespace": "ns2"}, "memory": {"workingSetBytes": 50}, "ephemeral-storage": {"usedBytes": 5000}},
+		{"podRef": {"name": "throttled", "namespace": "ns2"}, "cpu": {"psi": {"some": {"avg10": 20}}}}
+	]
+}`
+
+func TestNewNodePressureInfo(t *testing.T) {
+	summary := &nodeSummary{}
+	if err := json.Unmarshal([]byte(testSummary), summary); err != nil {
+		t.Fatal(err)
+	}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-0"},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue},
+			{Type: corev1.NodeDiskPressure, Status: corev1.ConditionFalse},
+		}},
+	}
+
+	info := newNodePressureInfo(node, summary, 3)
+	if expected := []string{"ns2/throttled", "ns1/hungry", "ns2/writer"}; !reflect.DeepEqual(expected, info.TopPods) {
+		t.Errorf("expected top pods %v, got %v", expected, info.TopPods)
+	}
+	if expected := []string{"MemoryPressure"}; !reflect.DeepEqual(expected, info.Conditions) {
+		t.Errorf("expected conditions %v, got %v", expected, info.Conditions)
+	}
+	if info.maxPressure() != 12.5 {
+		t.Errorf("expected a pressure of 12.5, got %v", info.maxPressure())
+	}
+
+	out := &bytes.Buffer{}
+	info.PrintLine(out)
+	if expected := "worker-0\t12.50%\t3.25%\t<unknown>\tMemoryPressure\tns2/throttled, ns1/hungry, ns2/writer\t"; out.String() != expected {
+		t.Errorf("expected\n%q\ngot\n%q", expected, out.String())
+	}
+
+	unreachable := newNodePressureInfo(node, nil, 3)
+	if unreachable.CPU != nil || len(unreachable.TopPods) != 0 || len(unreachable.Conditions) != 1 {
+		t.Errorf("unexpected info without a summary: %#v", unreachable)
+	}
+}
@@ -2,6 +2,8 @@ package top
 
 import (
 	"bytes"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
@@ -10,6 +12,48 @@ import (
 
 type Info interface {
 	PrintLine(out io.Writer)
+	// Values returns the columns of the CSV output, with sizes in bytes.
+	Values() []string
+}
+
+// outputFormats are the values of --output, the table being the default.
+var outputFormats = []string{"json", "csv"}
+
+func validateOutput(output string) error {
+	switch output {
+	case "", "json", "csv":
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q, must be one of %s", output, strings.Join(outputFormats, ", "))
+	}
+}
+
+// PrintOutput prints the infos as a table, or as JSON or CSV for reports.
+func PrintOutput(out io.Writer, output string, headers []string, infos []Info) error {
+	switch output {
+	case "json":
+		data, err := json.MarshalIndent(infos, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case "csv":
+		w := csv.NewWriter(out)
+		if err := w.Write(headers); err != nil {
+			return err
+		}
+		for _, info := range infos {
+			if err := w.Write(info.Values()); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		return w.Error()
+	default:
+		Print(out, headers, infos)
+		return nil
+	}
 }
 
 func Print(out io.Writer, headers []string, infos []Info) {
@@ -0,0 +1,120 @@
+package top
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	units "github.com/docker/go-units"
+
+	"github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+)
+
+const (
+	sumByNamespace   = "namespace"
+	sumByImageStream = "imagestream"
+)
+
+var sumByValues = []string{sumByNamespace, sumByImageStream}
+
+func validateSumBy(sumBy string) error {
+	switch sumBy {
+	case "", sumByNamespace, sumByImageStream:
+		return nil
+	default:
+		return fmt.Errorf("invalid --sum-by %q, must be one of %s", sumBy, strings.Join(sumByValues, ", "))
+	}
+}
+
+// sumColumns returns the columns of the usage summed by namespace or image stream.
+func sumColumns(sumBy string) []string {
+	return []string{strings.ToUpper(sumBy), "IMAGES", "STORAGE"}
+}
+
+// noneSum is the name of the usage of the images no image stream refers to.
+const noneSum = "<none>"
+
+// sumInfo contains the usage statistics of the images of a namespace or an
+// image stream.
+type sumInfo struct {
+	Name    string `json:"name"`
+	Images  int    `json:"images"`
+	Storage int64  `json:"storage"`
+}
+
+var _ Info = &sumInfo{}
+
+func (i sumInfo) PrintLine(out io.Writer) {
+	printValue(out, i.Name)
+	printValue(out, i.Images)
+	printValue(out, units.BytesSize(float64(i.Storage)))
+}
+
+func (i sumInfo) Values() []string {
+	return []string{i.Name, strconv.Itoa(i.Images), strconv.FormatInt(i.Storage, 10)}
+}
+
+// sumKey returns the namespace or namespace/name of the stream to sum by.
+func sumKey(sumBy, namespace, name string) string {
+	if sumBy == sumByNamespace {
+		return namespace
+	}
+	return namespace + "/" + name
+}
+
+// sortSums orders the sums by decreasing storage, with the images no image
+// stream refers to last.
+func sortSums(sums map[string]*sumInfo) []Info {
+	infos := []Info{}
+	for _, sum := range sums {
+		infos = append(infos, *sum)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		a, b := infos[i].(sumInfo), infos[j].(sumInfo)
+		if (a.Name == noneSum) != (b.Name == noneSum) {
+			return b.Name == noneSum
+		}
+		if a.Storage != b.Storage {
+			return a.Storage > b.Storage
+		}
+		return a.Name < b.Name
+	})
+	return infos
+}
+
+// sumImages sums the storage of the images by the namespaces or the image
+// streams referring to them. An image referred to from several namespaces or
+// image streams is accounted to each of them.
+func sumImages(g genericgraph.Graph, sumBy string) []Info {
+	sums := map[string]*sumInfo{}
+	add := func(key string, storage int64) {
+		sum, ok := sums[key]
+		if !ok {
+			sum = &sumInfo{Name: key}
+			sums[key] = sum
+		}
+		sum.Images++
+		sum.Storage += storage
+	}
+	for _, in := range getImageNodes(g.Nodes()) {
+		storage := getStorage(in.Image)
+		keys := map[string]bool{}
+		for _, e := range g.InboundEdges(in, ImageStreamImageEdgeKind) {
+			streamNode, ok := e.From().(*imagegraph.ImageStreamNode)
+			if !ok {
+				continue
+			}
+			keys[sumKey(sumBy, streamNode.ImageStream.Namespace, streamNode.ImageStream.Name)] = true
+		}
+		if len(keys) == 0 {
+			add(noneSum, storage)
+		}
+		for key := range keys {
+			add(key, storage)
+		}
+	}
+	return sortSums(sums)
+}
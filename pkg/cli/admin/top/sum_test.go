@@ -0,0 +1,111 @@
+package top
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+func sumTestData() (*imagev1.ImageList, *imagev1.ImageStreamList) {
+	image := func(name string, size int64) imagev1.Image {
+		return imagev1.Image{
+			ObjectMeta:        metav1.ObjectMeta{Name: name},
+			DockerImageLayers: []imagev1.ImageLayer{{Name: name + "-layer", LayerSize: size}},
+		}
+	}
+	stream := func(namespace, name string, images ...string) imagev1.ImageStream {
+		is := imagev1.ImageStream{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		for _, image := range images {
+			is.Status.Tags = append(is.Status.Tags, imagev1.NamedTagEventList{Tag: image, Items: []imagev1.TagEvent{{Image: image}}})
+		}
+		return is
+	}
+	images := &imagev1.ImageList{Items: []imagev1.Image{
+		image("image1", 1024),
+		image("image2", 2048),
+		image("image3", 512),
+	}}
+	streams := &imagev1.ImageStreamList{Items: []imagev1.ImageStream{
+		stream("ns1", "frontend", "image1", "image2"),
+		stream("ns1", "backend", "image2"),
+		stream("ns2", "frontend", "image1"),
+	}}
+	return images, streams
+}
+
+func TestImagesSum(t *testing.T) {
+	images, streams := sumTestData()
+	o := TopImagesOptions{Images: images, Streams: streams, SumBy: sumByNamespace}
+	expected := []Info{
+		sumInfo{Name: "ns1", Images: 2, Storage: 3072},
+		sumInfo{Name: "ns2", Images: 1, Storage: 1024},
+		sumInfo{Name: "<none>", Images: 1, Storage: 512},
+	}
+	if infos := o.imagesSum(); !reflect.DeepEqual(infos, expected) {
+		t.Errorf("unexpected sum by namespace, expected %#v, got %#v", expected, infos)
+	}
+
+	o.SumBy = sumByImageStream
+	expected = []Info{
+		sumInfo{Name: "ns1/frontend", Images: 2, Storage: 3072},
+		sumInfo{Name: "ns1/backend", Images: 1, Storage: 2048},
+		sumInfo{Name: "ns2/frontend", Images: 1, Storage: 1024},
+		sumInfo{Name: "<none>", Images: 1, Storage: 512},
+	}
+	if infos := o.imagesSum(); !reflect.DeepEqual(infos, expected) {
+		t.Errorf("unexpected sum by image stream, expected %#v, got %#v", expected, infos)
+	}
+}
+
+func TestImageStreamsSum(t *testing.T) {
+	images, streams := sumTestData()
+	o := TopImageStreamsOptions{Images: images, Streams: streams}
+	expected := []Info{
+		sumInfo{Name: "ns1", Images: 3, Storage: 5120},
+		sumInfo{Name: "ns2", Images: 1, Storage: 1024},
+	}
+	if infos := sumImageStreams(o.imageStreamsTop()); !reflect.DeepEqual(infos, expected) {
+		t.Errorf("unexpected sum by namespace, expected %#v, got %#v", expected, infos)
+	}
+}
+
+func TestPrintOutput(t *testing.T) {
+	infos := []Info{
+		imageInfo{Image: "image1", ImageStreamTags: []string{"ns1/frontend (latest)", "ns2/frontend (v1)"}, Parents: []string{}, Usage: []string{}, Storage: 1024},
+	}
+	out := &bytes.Buffer{}
+	if err := PrintOutput(out, "csv", ImageColumns, infos); err != nil {
+		t.Fatal(err)
+	}
+	want := "NAME,IMAGESTREAMTAG,PARENTS,USAGE,METADATA,STORAGE\nimage1,ns1/frontend (latest);ns2/frontend (v1),,,false,1024\n"
+	if out.String() != want {
+		t.Errorf("unexpected CSV:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if err := PrintOutput(out, "json", sumColumns(sumByNamespace), []Info{sumInfo{Name: "ns1", Images: 2, Storage: 3072}}); err != nil {
+		t.Fatal(err)
+	}
+	want = `[
+    {
+        "name": "ns1",
+        "images": 2,
+        "storage": 3072
+    }
+]
+`
+	if out.String() != want {
+		t.Errorf("unexpected JSON:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	if err := validateOutput("yaml"); err == nil {
+		t.Errorf("expected an error for an unsupported output")
+	}
+	if err := validateSumBy("pod"); err == nil {
+		t.Errorf("expected an error for an unsupported sum")
+	}
+}
@@ -22,6 +22,19 @@ var topLong = templates.LongDesc(`
 	This command analyzes resources managed by the platform and presents current
 	usage statistics.`)
 
+var (
+	topNodePressureLong = templates.LongDesc(`
+		Pass --pressure to show instead the pressure stall information, PSI, of the nodes: the
+		share of the last 10 seconds in which some tasks stalled on the CPU, memory or IO,
+		reported by the kubelets with the KubeletPSI feature. The nodes under the most pressure
+		are listed first, with their memory, disk and PID pressure conditions and the pods under
+		the most pressure on them.`)
+
+	topNodePressureExample = templates.Examples(`
+		# Show the pressure on the nodes and the 5 pods under the most pressure on each
+		oc adm top node --pressure --top-pods=5`)
+)
+
 func NewCommandTop(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
 	// Parent command to which all subcommands are added.
 	cmds := &cobra.Command{
@@ -31,14 +44,16 @@ func NewCommandTop(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobr
 		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
 	}
 
-	cmdTopNode := cmdutil.ReplaceCommandName("kubectl", "oc adm", top.NewCmdTopNode(f, nil, streams))
+	topNodeOptions := &top.TopNodeOptions{IOStreams: streams, UseProtocolBuffers: true}
+	cmdTopNode := cmdutil.ReplaceCommandName("kubectl", "oc adm", top.NewCmdTopNode(f, topNodeOptions, streams))
 	cmdTopPod := cmdutil.ReplaceCommandName("kubectl", "oc adm", top.NewCmdTopPod(f, nil, streams))
 
 	cmds.AddCommand(NewCmdTopImages(f, streams))
 	cmds.AddCommand(NewCmdTopImageStreams(f, streams))
 	cmds.AddCommand(toppvc.NewCmdTopPersistentVolumeClaims(f, streams))
-	cmdTopNode.Long = templates.LongDesc(cmdTopNode.Long)
-	cmdTopNode.Example = templates.Examples(cmdTopNode.Example)
+	AddPressureFlags(f, cmdTopNode, topNodeOptions)
+	cmdTopNode.Long = templates.LongDesc(cmdTopNode.Long) + "\n\n" + topNodePressureLong
+	cmdTopNode.Example = templates.Examples(cmdTopNode.Example) + "\n\n" + topNodePressureExample
 	cmdTopPod.Long = templates.LongDesc(cmdTopPod.Long)
 	cmdTopPod.Example = templates.Examples(cmdTopPod.Example)
 	cmds.AddCommand(cmdTopNode)
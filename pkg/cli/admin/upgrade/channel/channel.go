@@ -4,6 +4,8 @@ package channel
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -15,9 +17,27 @@ import (
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/templates"
 
+	configv1 "github.com/openshift/api/config/v1"
 	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+
+	ocompletion "github.com/openshift/oc/pkg/helpers/completion"
 )
 
+// channelPattern matches the channel naming scheme used by the OpenShift
+// update service, e.g. stable-4.16 or eus-4.14.
+var channelPattern = regexp.MustCompile(`^(stable|fast|candidate|eus)-[0-9]+[.][0-9]+$`)
+
+var channelExample = templates.Examples(`
+	# List the channels available to the cluster's current version
+	oc adm upgrade channel list
+
+	# Set the update channel
+	oc adm upgrade channel set stable-4.16
+
+	# Clear the update channel, e.g. for disconnected clusters without an update service
+	oc adm upgrade channel set --clear
+`)
+
 func NewOptions(streams genericiooptions.IOStreams) *Options {
 	return &Options{
 		IOStreams: streams,
@@ -27,8 +47,9 @@ func NewOptions(streams genericiooptions.IOStreams) *Options {
 func New(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
 	o := NewOptions(streams)
 	cmd := &cobra.Command{
-		Use:   "channel CHANNEL",
-		Short: "Set or clear the update channel",
+		Use:     "channel CHANNEL",
+		Short:   "Set or clear the update channel",
+		Example: channelExample,
 		Long: templates.LongDesc(`
 			Set or clear the update channel.
 
@@ -42,21 +63,93 @@ func New(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command
 			If desired channel is not empty, the command will set the update channel to it. If there is a list of
 			acceptable channels and the desired channel is not in that list, you must pass --allow-explicit-channel
 			to allow channel change to proceed.
+
+			If there is no list of acceptable channels, the command warns about a desired channel which
+			does not match the <stable|fast|candidate|eus>-<major>.<minor> naming scheme.
+
+			Use the 'list' subcommand to show the channels available to the current version.
 		`),
+		ValidArgsFunction: channelCompletionFunc(f),
 		Run: func(cmd *cobra.Command, args []string) {
 			kcmdutil.CheckErr(o.Complete(f, cmd, args))
 			kcmdutil.CheckErr(o.Run())
 		},
 	}
+	o.AddFlags(cmd)
+	cmd.AddCommand(newSetCommand(f, streams), newListCommand(f, streams))
+	return cmd
+}
+
+func newSetCommand(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "set CHANNEL",
+		Short:   "Set or clear the update channel",
+		Example: channelExample,
+		Long: templates.LongDesc(`
+			Set or clear the update channel.
+
+			If there is a list of acceptable channels and the channel is not in that list, you must pass
+			--allow-explicit-channel to set it. If there is no such list, the command warns about a
+			channel which does not match the <stable|fast|candidate|eus>-<major>.<minor> naming scheme
+			used by the update service.
+
+			Pass --clear instead of a channel to clear the update channel.  This is useful for
+			disconnected clusters which cannot reach an update service.
+		`),
+		ValidArgsFunction: channelCompletionFunc(f),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	o.AddFlags(cmd)
+	cmd.Flags().BoolVar(&o.Clear, "clear", o.Clear, "Clear the update channel.")
+	return cmd
+}
+
+func newListCommand(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the update channels available to the current version",
+		Example: channelExample,
+		Long: templates.LongDesc(`
+			List the update channels available to the current version.
+
+			The current channel is marked with an asterisk.  Channels are reported by the
+			update service, so the list is empty when the cluster cannot reach it.
+		`),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.RequireNoArguments(cmd, args)
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.RunList())
+		},
+	}
+	return cmd
+}
+
+// channelCompletionFunc completes the channel argument with the channels available to the
+// current version, which the update service reports.
+func channelCompletionFunc(f kcmdutil.Factory) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return ocompletion.ChannelCompletions(ocompletion.ClusterVersion(f), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func (o *Options) AddFlags(cmd *cobra.Command) {
 	flags := cmd.Flags()
 	flags.BoolVar(&o.AllowExplicitChannel, "allow-explicit-channel", o.AllowExplicitChannel, "Change the channel, even if there is a list of acceptable channels and the desired channel is not in that list.")
-	return cmd
 }
 
 type Options struct {
 	genericiooptions.IOStreams
 
 	Channel string
+	Clear   bool
 
 	AllowExplicitChannel bool
 
@@ -69,6 +162,12 @@ func (o *Options) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string
 	} else if len(args) == 1 {
 		o.Channel = args[0]
 	}
+	if o.Clear && len(o.Channel) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "--clear may not be specified with a channel")
+	}
+	if cmd.Name() == "set" && !o.Clear && len(o.Channel) == 0 {
+		return kcmdutil.UsageErrorf(cmd, "a channel or --clear is required")
+	}
 
 	cfg, err := f.ToRESTConfig()
 	if err != nil {
@@ -82,13 +181,63 @@ func (o *Options) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string
 	return nil
 }
 
-func (o *Options) Run() error {
-	ctx := context.TODO()
+// validateChannel warns when the requested channel does not match the naming scheme used by
+// the update service. The channels available to the current version are authoritative, so the
+// naming scheme is only checked when the update service reported none.
+func (o *Options) validateChannel(available []string) {
+	if len(o.Channel) == 0 || len(available) > 0 || channelPattern.MatchString(o.Channel) {
+		return
+	}
+	fmt.Fprintf(o.ErrOut, "warning: The requested channel %q does not match the expected <stable|fast|candidate|eus>-<major>.<minor> naming scheme.\n", o.Channel)
+}
+
+func (o *Options) getClusterVersion(ctx context.Context) (*configv1.ClusterVersion, error) {
 	cv, err := o.Client.ConfigV1().ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
 	if err != nil {
 		if errors.IsNotFound(err) {
-			return fmt.Errorf("no cluster version information available - you must be connected to an OpenShift version 4 server to fetch the current version")
+			return nil, fmt.Errorf("no cluster version information available - you must be connected to an OpenShift version 4 server to fetch the current version")
+		}
+		return nil, err
+	}
+	return cv, nil
+}
+
+// RunList prints the channels known to be compatible with the current version.
+func (o *Options) RunList() error {
+	cv, err := o.getClusterVersion(context.TODO())
+	if err != nil {
+		return err
+	}
+
+	if cv.Spec.Channel == "" {
+		fmt.Fprint(o.Out, "Channel: <none>\n")
+	} else {
+		fmt.Fprintf(o.Out, "Channel: %s\n", cv.Spec.Channel)
+	}
+
+	if len(cv.Status.Desired.Channels) == 0 {
+		fmt.Fprintf(o.Out, "No channels known to be compatible with the current version %q.\n", cv.Status.Desired.Version)
+		return nil
+	}
+
+	channels := make([]string, len(cv.Status.Desired.Channels))
+	copy(channels, cv.Status.Desired.Channels)
+	sort.Strings(channels)
+	fmt.Fprintf(o.Out, "Available channels for %s:\n", cv.Status.Desired.Version)
+	for _, channel := range channels {
+		marker := " "
+		if channel == cv.Spec.Channel {
+			marker = "*"
 		}
+		fmt.Fprintf(o.Out, "%s %s\n", marker, channel)
+	}
+	return nil
+}
+
+func (o *Options) Run() error {
+	ctx := context.TODO()
+	cv, err := o.getClusterVersion(ctx)
+	if err != nil {
 		return err
 	}
 
@@ -101,6 +250,7 @@ func (o *Options) Run() error {
 		return nil
 	}
 
+	o.validateChannel(cv.Status.Desired.Channels)
 	if len(cv.Status.Desired.Channels) > 0 {
 		found, known := false, false
 		for _, channel := range cv.Status.Desired.Channels {
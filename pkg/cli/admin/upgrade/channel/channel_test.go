@@ -0,0 +1,144 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+
+	configv1 "github.com/openshift/api/config/v1"
+	fakeconfigv1client "github.com/openshift/client-go/config/clientset/versioned/fake"
+)
+
+func TestValidateChannel(t *testing.T) {
+	for _, testCase := range []struct {
+		name            string
+		channel         string
+		available       []string
+		allowExplicit   bool
+		expectedError   string
+		expectedWarning string
+		expectedChannel string
+	}{
+		{
+			name:            "available channel",
+			channel:         "stable-4.16",
+			available:       []string{"stable-4.16", "fast-4.16"},
+			expectedChannel: "stable-4.16",
+		},
+		{
+			name:            "available channel outside of the naming scheme",
+			channel:         "okd-scos-4.16",
+			available:       []string{"okd-scos-4.16"},
+			expectedChannel: "okd-scos-4.16",
+		},
+		{
+			name:          "unavailable channel",
+			channel:       "fast-4.17",
+			available:     []string{"stable-4.16", "fast-4.16"},
+			expectedError: `the requested channel "fast-4.17" is not one of the available channels (stable-4.16, fast-4.16), you must pass --allow-explicit-channel to continue`,
+		},
+		{
+			name:            "unavailable channel allowed",
+			channel:         "my-custom-channel",
+			available:       []string{"stable-4.16"},
+			allowExplicit:   true,
+			expectedWarning: `warning: The requested channel "my-custom-channel" is not one of the available channels (stable-4.16). You have used --allow-explicit-channel to proceed anyway.`,
+			expectedChannel: "my-custom-channel",
+		},
+		{
+			name:            "no available channels",
+			channel:         "eus-4.14",
+			expectedWarning: `warning: No channels known to be compatible with the current version "4.16.1"; unable to validate "eus-4.14".`,
+			expectedChannel: "eus-4.14",
+		},
+		{
+			name:            "no available channels outside of the naming scheme",
+			channel:         "stable-4.16.1",
+			expectedWarning: `warning: The requested channel "stable-4.16.1" does not match the expected <stable|fast|candidate|eus>-<major>.<minor> naming scheme.`,
+			expectedChannel: "stable-4.16.1",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			client := fakeconfigv1client.NewSimpleClientset(&configv1.ClusterVersion{
+				ObjectMeta: metav1.ObjectMeta{Name: "version"},
+				Status:     configv1.ClusterVersionStatus{Desired: configv1.Release{Version: "4.16.1", Channels: testCase.available}},
+			})
+			errOut := &bytes.Buffer{}
+			o := NewOptions(genericiooptions.IOStreams{Out: &bytes.Buffer{}, ErrOut: errOut})
+			o.Client = client
+			o.Channel = testCase.channel
+			o.AllowExplicitChannel = testCase.allowExplicit
+
+			err := o.Run()
+			var actualError string
+			if err != nil {
+				actualError = strings.TrimSpace(err.Error())
+			}
+			if actualError != testCase.expectedError {
+				t.Errorf("expected error %q, got %q", testCase.expectedError, actualError)
+			}
+			if len(testCase.expectedWarning) > 0 && !strings.Contains(errOut.String(), testCase.expectedWarning) {
+				t.Errorf("expected warning %q, got %q", testCase.expectedWarning, errOut.String())
+			}
+			if len(testCase.expectedWarning) == 0 && errOut.Len() > 0 {
+				t.Errorf("expected no warning, got %q", errOut.String())
+			}
+
+			cv, err := client.ConfigV1().ClusterVersions().Get(context.TODO(), "version", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cv.Spec.Channel != testCase.expectedChannel {
+				t.Errorf("expected channel %q, got %q", testCase.expectedChannel, cv.Spec.Channel)
+			}
+		})
+	}
+}
+
+func TestRunList(t *testing.T) {
+	for _, testCase := range []struct {
+		name        string
+		spec        configv1.ClusterVersionSpec
+		desired     configv1.Release
+		expectedOut string
+	}{
+		{
+			name:        "no channels",
+			desired:     configv1.Release{Version: "4.16.1"},
+			expectedOut: "Channel: <none>\nNo channels known to be compatible with the current version \"4.16.1\".\n",
+		},
+		{
+			name:    "current channel marked",
+			spec:    configv1.ClusterVersionSpec{Channel: "stable-4.16"},
+			desired: configv1.Release{Version: "4.16.1", Channels: []string{"stable-4.16", "fast-4.16", "candidate-4.17"}},
+			expectedOut: `Channel: stable-4.16
+Available channels for 4.16.1:
+  candidate-4.17
+  fast-4.16
+* stable-4.16
+`,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			cv := &configv1.ClusterVersion{
+				ObjectMeta: metav1.ObjectMeta{Name: "version"},
+				Spec:       testCase.spec,
+				Status:     configv1.ClusterVersionStatus{Desired: testCase.desired},
+			}
+			out := &bytes.Buffer{}
+			o := NewOptions(genericiooptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}})
+			o.Client = fakeconfigv1client.NewSimpleClientset(cv)
+
+			if err := o.RunList(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.String() != testCase.expectedOut {
+				t.Errorf("expected output:\n%s\ngot:\n%s", testCase.expectedOut, out.String())
+			}
+		})
+	}
+}
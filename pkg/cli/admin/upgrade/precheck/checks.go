@@ -0,0 +1,332 @@
+package precheck
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	apiserverv1 "github.com/openshift/api/apiserver/v1"
+	configv1 "github.com/openshift/api/config/v1"
+	machineconfigv1 "github.com/openshift/api/machineconfiguration/v1"
+)
+
+const (
+	checkUpdateTarget               = "Update target"
+	checkOperators                  = "Cluster operators"
+	checkUpgradeable                = "Upgradeable"
+	checkCertificateSigningRequests = "Certificate signing requests"
+	checkMachineConfigPools         = "Machine config pools"
+	checkDeprecatedAPIs             = "Deprecated APIs"
+	checkUnsupportedConfigurations  = "Unsupported configurations"
+	checkCapacity                   = "Capacity headroom"
+)
+
+// updateTargetResult checks that the target is newer than the current version and one the
+// update service recommends.
+func updateTargetResult(cv *configv1.ClusterVersion, current, to semver.Version) checkResult {
+	r := checkResult{Check: checkUpdateTarget}
+	if to.LTE(current) {
+		r.Result, r.Message = resultFail, fmt.Sprintf("%s is not newer than the current version %s", to, current)
+		return r
+	}
+	for _, update := range cv.Status.AvailableUpdates {
+		if update.Version == to.String() {
+			r.Result, r.Message = resultPass, fmt.Sprintf("%s is a recommended update", to)
+			return r
+		}
+	}
+	for _, update := range cv.Status.ConditionalUpdates {
+		if update.Release.Version != to.String() {
+			continue
+		}
+		if c := findCondition(update.Conditions, "Recommended"); c != nil && c.Status != metav1.ConditionTrue {
+			r.Result, r.Message = resultWarn, fmt.Sprintf("%s is supported but not recommended: %s: %s", to, c.Reason, c.Message)
+			return r
+		}
+		r.Result, r.Message = resultPass, fmt.Sprintf("%s is a recommended update", to)
+		return r
+	}
+	r.Result, r.Message = resultWarn, fmt.Sprintf("%s is not in the available updates of channel %q and can only be requested with --allow-explicit-upgrade", to, cv.Spec.Channel)
+	return r
+}
+
+func operatorsResult(operators []configv1.ClusterOperator) checkResult {
+	var unavailable, degraded []string
+	for _, co := range operators {
+		if c := findClusterOperatorStatusCondition(co.Status.Conditions, configv1.OperatorAvailable); c == nil || c.Status != configv1.ConditionTrue {
+			unavailable = append(unavailable, co.Name)
+		}
+		if c := findClusterOperatorStatusCondition(co.Status.Conditions, configv1.OperatorDegraded); c != nil && c.Status == configv1.ConditionTrue {
+			degraded = append(degraded, co.Name)
+		}
+	}
+	var problems []string
+	if len(unavailable) > 0 {
+		problems = append(problems, fmt.Sprintf("Unavailable: %s", joinNames(unavailable)))
+	}
+	if len(degraded) > 0 {
+		problems = append(problems, fmt.Sprintf("Degraded: %s", joinNames(degraded)))
+	}
+	if len(problems) > 0 {
+		return checkResult{Check: checkOperators, Result: resultFail, Message: strings.Join(problems, "; ")}
+	}
+	return checkResult{Check: checkOperators, Result: resultPass, Message: fmt.Sprintf("All %d cluster operators are available and not degraded", len(operators))}
+}
+
+// upgradeableResult checks the Upgradeable conditions, which only block updates to another
+// minor version.
+func upgradeableResult(cv *configv1.ClusterVersion, operators []configv1.ClusterOperator, current, to semver.Version) checkResult {
+	var blockers []string
+	if c := findClusterOperatorStatusCondition(cv.Status.Conditions, configv1.OperatorUpgradeable); c != nil && c.Status == configv1.ConditionFalse {
+		blockers = append(blockers, fmt.Sprintf("cluster version: %s", c.Message))
+	}
+	var operatorBlockers []string
+	for _, co := range operators {
+		if c := findClusterOperatorStatusCondition(co.Status.Conditions, configv1.OperatorUpgradeable); c != nil && c.Status == configv1.ConditionFalse {
+			operatorBlockers = append(operatorBlockers, co.Name)
+		}
+	}
+	if len(operatorBlockers) > 0 {
+		blockers = append(blockers, fmt.Sprintf("operators: %s", joinNames(operatorBlockers)))
+	}
+	if len(blockers) == 0 {
+		return checkResult{Check: checkUpgradeable, Result: resultPass, Message: "No minor update is blocked"}
+	}
+	message := fmt.Sprintf("Upgradeable=False for %s", strings.Join(blockers, "; "))
+	if current.Major != to.Major || current.Minor != to.Minor {
+		return checkResult{Check: checkUpgradeable, Result: resultFail, Message: message}
+	}
+	return checkResult{Check: checkUpgradeable, Result: resultWarn, Message: message + ", which does not block patch updates"}
+}
+
+func certificateSigningRequestsResult(csrs []certificatesv1.CertificateSigningRequest) checkResult {
+	var pending []string
+	for _, csr := range csrs {
+		decided := false
+		for _, c := range csr.Status.Conditions {
+			if c.Type == certificatesv1.CertificateApproved || c.Type == certificatesv1.CertificateDenied || c.Type == certificatesv1.CertificateFailed {
+				decided = true
+				break
+			}
+		}
+		if !decided {
+			pending = append(pending, csr.Name)
+		}
+	}
+	if len(pending) > 0 {
+		return checkResult{Check: checkCertificateSigningRequests, Result: resultWarn, Message: fmt.Sprintf("%d pending: %s", len(pending), joinNames(pending))}
+	}
+	return checkResult{Check: checkCertificateSigningRequests, Result: resultPass, Message: "No request is pending"}
+}
+
+// machineConfigPoolsResult checks that the nodes of every pool will be updated. The update
+// cannot complete while the control plane pool is paused, while other paused pools only
+// delay the update of their nodes.
+func machineConfigPoolsResult(pools []machineconfigv1.MachineConfigPool) checkResult {
+	var paused, pausedControlPlane, degraded []string
+	for _, pool := range pools {
+		if pool.Spec.Paused {
+			if pool.Name == "master" {
+				pausedControlPlane = append(pausedControlPlane, pool.Name)
+			} else {
+				paused = append(paused, pool.Name)
+			}
+		}
+		for _, c := range pool.Status.Conditions {
+			if c.Type == machineconfigv1.MachineConfigPoolDegraded && c.Status == corev1.ConditionTrue {
+				degraded = append(degraded, pool.Name)
+			}
+		}
+	}
+	var problems []string
+	if len(pausedControlPlane) > 0 || len(paused) > 0 {
+		problems = append(problems, fmt.Sprintf("Paused: %s", joinNames(append(pausedControlPlane, paused...))))
+	}
+	if len(degraded) > 0 {
+		problems = append(problems, fmt.Sprintf("Degraded: %s", joinNames(degraded)))
+	}
+	switch {
+	case len(pausedControlPlane) > 0 || len(degraded) > 0:
+		return checkResult{Check: checkMachineConfigPools, Result: resultFail, Message: strings.Join(problems, "; ")}
+	case len(paused) > 0:
+		return checkResult{Check: checkMachineConfigPools, Result: resultWarn, Message: strings.Join(problems, "; ") + ", their nodes will not be updated until they are unpaused"}
+	}
+	return checkResult{Check: checkMachineConfigPools, Result: resultPass, Message: fmt.Sprintf("All %d pools are unpaused and not degraded", len(pools))}
+}
+
+func apiRequestCounts(list *unstructured.UnstructuredList) ([]apiserverv1.APIRequestCount, error) {
+	counts := make([]apiserverv1.APIRequestCount, 0, len(list.Items))
+	for _, item := range list.Items {
+		var count apiserverv1.APIRequestCount
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+	return counts, nil
+}
+
+// deprecatedAPIsResult reports the APIs requested in the last 24 hours that the Kubernetes
+// version of the target release no longer serves. OpenShift 4.y is based on Kubernetes 1.(y+13).
+func deprecatedAPIsResult(counts []apiserverv1.APIRequestCount, to semver.Version) checkResult {
+	if to.Major != 4 {
+		return checkResult{Check: checkDeprecatedAPIs, Result: resultWarn, Message: fmt.Sprintf("Unable to check the APIs removed in %s", to)}
+	}
+	kubeMinor := to.Minor + 13
+	var removed []string
+	for _, count := range counts {
+		if len(count.Status.RemovedInRelease) == 0 || count.Status.RequestCount == 0 {
+			continue
+		}
+		release, err := semver.ParseTolerant(count.Status.RemovedInRelease)
+		if err != nil || release.Major != 1 || release.Minor > kubeMinor {
+			continue
+		}
+		removed = append(removed, fmt.Sprintf("%s (%d requests)", count.Name, count.Status.RequestCount))
+	}
+	if len(removed) > 0 {
+		sort.Strings(removed)
+		return checkResult{Check: checkDeprecatedAPIs, Result: resultWarn, Message: fmt.Sprintf("Requested in the last 24h and removed in Kubernetes 1.%d or earlier: %s", kubeMinor, joinNames(removed))}
+	}
+	return checkResult{Check: checkDeprecatedAPIs, Result: resultPass, Message: fmt.Sprintf("No API removed in Kubernetes 1.%d or earlier was requested in the last 24h", kubeMinor)}
+}
+
+// hasUnsupportedConfigOverrides returns true if the operator configuration sets overrides.
+func hasUnsupportedConfigOverrides(config *unstructured.Unstructured) bool {
+	overrides, found, err := unstructured.NestedFieldNoCopy(config.Object, "spec", "unsupportedConfigOverrides")
+	if err != nil || !found || overrides == nil {
+		return false
+	}
+	if m, ok := overrides.(map[string]interface{}); ok {
+		return len(m) > 0
+	}
+	return true
+}
+
+// unsupportedConfigurationsResult reports the components the cluster version operator does
+// not manage and the operators configured with unsupported overrides, keyed by resource.
+func unsupportedConfigurationsResult(cv *configv1.ClusterVersion, overrides map[string]bool) checkResult {
+	var unmanaged, overridden []string
+	for _, override := range cv.Spec.Overrides {
+		if !override.Unmanaged {
+			continue
+		}
+		name := override.Name
+		if len(override.Namespace) > 0 {
+			name = override.Namespace + "/" + name
+		}
+		unmanaged = append(unmanaged, fmt.Sprintf("%s %s", override.Kind, name))
+	}
+	for resource, set := range overrides {
+		if set {
+			overridden = append(overridden, resource)
+		}
+	}
+	sort.Strings(overridden)
+	var problems []string
+	if len(unmanaged) > 0 {
+		problems = append(problems, fmt.Sprintf("Unmanaged: %s", joinNames(unmanaged)))
+	}
+	if len(overridden) > 0 {
+		problems = append(problems, fmt.Sprintf("Unsupported config overrides: %s", joinNames(overridden)))
+	}
+	if len(problems) > 0 {
+		return checkResult{Check: checkUnsupportedConfigurations, Result: resultWarn, Message: strings.Join(problems, "; ")}
+	}
+	return checkResult{Check: checkUnsupportedConfigurations, Result: resultPass, Message: "No component is unmanaged or overridden"}
+}
+
+// capacityResult checks that the resources requested by the pods of the compute nodes fit
+// on the others while the largest node is drained. Clusters without dedicated compute nodes
+// are checked across all their schedulable nodes.
+func capacityResult(nodes []corev1.Node, pods []corev1.Pod) checkResult {
+	var compute, schedulable []corev1.Node
+	for _, node := range nodes {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		schedulable = append(schedulable, node)
+		_, master := node.Labels["node-role.kubernetes.io/master"]
+		_, controlPlane := node.Labels["node-role.kubernetes.io/control-plane"]
+		if !master && !controlPlane {
+			compute = append(compute, node)
+		}
+	}
+	if len(compute) == 0 {
+		compute = schedulable
+	}
+	if len(compute) < 2 {
+		return checkResult{Check: checkCapacity, Result: resultWarn, Message: fmt.Sprintf("%d schedulable node, its workloads will be disrupted while it is rebooted", len(compute))}
+	}
+
+	names := make(map[string]bool, len(compute))
+	allocatable, largest := corev1.ResourceList{}, corev1.ResourceList{}
+	for _, node := range compute {
+		names[node.Name] = true
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			quantity := node.Status.Allocatable[name]
+			addQuantity(allocatable, name, quantity)
+			if current := largest[name]; quantity.Cmp(current) > 0 {
+				largest[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	requested := corev1.ResourceList{}
+	for _, pod := range pods {
+		if !names[pod.Spec.NodeName] || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+				if quantity, ok := container.Resources.Requests[name]; ok {
+					addQuantity(requested, name, quantity)
+				}
+			}
+		}
+	}
+
+	var short []string
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		remaining := allocatable[name].DeepCopy()
+		remaining.Sub(largest[name])
+		if req := requested[name]; req.Cmp(remaining) > 0 {
+			short = append(short, fmt.Sprintf("%s requests of %s exceed the %s left", name, req.String(), remaining.String()))
+		}
+	}
+	if len(short) > 0 {
+		return checkResult{Check: checkCapacity, Result: resultWarn, Message: fmt.Sprintf("While the largest of %d nodes is drained, %s", len(compute), strings.Join(short, " and "))}
+	}
+	return checkResult{Check: checkCapacity, Result: resultPass, Message: fmt.Sprintf("The workloads of %d nodes fit while the largest is drained", len(compute))}
+}
+
+func addQuantity(list corev1.ResourceList, name corev1.ResourceName, quantity resource.Quantity) {
+	total := list[name]
+	total.Add(quantity)
+	list[name] = total
+}
+
+func findCondition(conditions []metav1.Condition, name string) *metav1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == name {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+func findClusterOperatorStatusCondition(conditions []configv1.ClusterOperatorStatusCondition, name configv1.ClusterStatusConditionType) *configv1.ClusterOperatorStatusCondition {
+	for i := range conditions {
+		if conditions[i].Type == name {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package precheck
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blang/semver"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	apiserverv1 "github.com/openshift/api/apiserver/v1"
+	configv1 "github.com/openshift/api/config/v1"
+	machineconfigv1 "github.com/openshift/api/machineconfiguration/v1"
+)
+
+func expectResult(t *testing.T, r checkResult, expected result, message string) {
+	t.Helper()
+	if r.Result != expected || !strings.Contains(r.Message, message) {
+		t.Errorf("expected %s result with message containing %q, got %s: %s", expected, message, r.Result, r.Message)
+	}
+}
+
+func TestUpdateTargetResult(t *testing.T) {
+	cv := &configv1.ClusterVersion{
+		Spec: configv1.ClusterVersionSpec{Channel: "stable-4.16"},
+		Status: configv1.ClusterVersionStatus{
+			AvailableUpdates: []configv1.Release{{Version: "4.16.5"}},
+			ConditionalUpdates: []configv1.ConditionalUpdate{{
+				Release:    configv1.Release{Version: "4.16.6"},
+				Conditions: []metav1.Condition{{Type: "Recommended", Status: metav1.ConditionFalse, Reason: "Bug", Message: "Breaks things"}},
+			}},
+		},
+	}
+	current := semver.MustParse("4.16.2")
+	expectResult(t, updateTargetResult(cv, current, semver.MustParse("4.16.5")), resultPass, "recommended update")
+	expectResult(t, updateTargetResult(cv, current, semver.MustParse("4.16.6")), resultWarn, "Bug: Breaks things")
+	expectResult(t, updateTargetResult(cv, current, semver.MustParse("4.16.7")), resultWarn, `channel "stable-4.16"`)
+	expectResult(t, updateTargetResult(cv, current, semver.MustParse("4.16.1")), resultFail, "not newer")
+}
+
+func TestOperatorsResults(t *testing.T) {
+	operator := func(name string, available, degraded, upgradeable configv1.ConditionStatus) configv1.ClusterOperator {
+		return configv1.ClusterOperator{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: configv1.ClusterOperatorStatus{Conditions: []configv1.ClusterOperatorStatusCondition{
+				{Type: configv1.OperatorAvailable, Status: available},
+				{Type: configv1.OperatorDegraded, Status: degraded},
+				{Type: configv1.OperatorUpgradeable, Status: upgradeable},
+			}},
+		}
+	}
+	healthy := []configv1.ClusterOperator{operator("dns", "True", "False", "True"), operator("etcd", "True", "False", "True")}
+	expectResult(t, operatorsResult(healthy), resultPass, "All 2 cluster operators")
+	expectResult(t, operatorsResult(append(healthy, operator("ingress", "False", "True", "True"))), resultFail, "Unavailable: ingress; Degraded: ingress")
+
+	cv := &configv1.ClusterVersion{}
+	current := semver.MustParse("4.15.10")
+	blocked := append(healthy, operator("storage", "True", "False", "False"))
+	expectResult(t, upgradeableResult(cv, healthy, current, semver.MustParse("4.16.0")), resultPass, "No minor update")
+	expectResult(t, upgradeableResult(cv, blocked, current, semver.MustParse("4.16.0")), resultFail, "operators: storage")
+	expectResult(t, upgradeableResult(cv, blocked, current, semver.MustParse("4.15.11")), resultWarn, "does not block patch updates")
+}
+
+func TestCertificateSigningRequestsResult(t *testing.T) {
+	csrs := []certificatesv1.CertificateSigningRequest{
+		{ObjectMeta: metav1.ObjectMeta{Name: "csr-approved"}, Status: certificatesv1.CertificateSigningRequestStatus{Conditions: []certificatesv1.CertificateSigningRequestCondition{{Type: certificatesv1.CertificateApproved}}}},
+	}
+	expectResult(t, certificateSigningRequestsResult(csrs), resultPass, "No request")
+	csrs = append(csrs, certificatesv1.CertificateSigningRequest{ObjectMeta: metav1.ObjectMeta{Name: "csr-pending"}})
+	expectResult(t, certificateSigningRequestsResult(csrs), resultWarn, "1 pending: csr-pending")
+}
+
+func TestMachineConfigPoolsResult(t *testing.T) {
+	pool := func(name string, paused bool) machineconfigv1.MachineConfigPool {
+		return machineconfigv1.MachineConfigPool{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: machineconfigv1.MachineConfigPoolSpec{Paused: paused}}
+	}
+	expectResult(t, machineConfigPoolsResult([]machineconfigv1.MachineConfigPool{pool("master", false), pool("worker", false)}), resultPass, "All 2 pools")
+	expectResult(t, machineConfigPoolsResult([]machineconfigv1.MachineConfigPool{pool("master", false), pool("worker", true)}), resultWarn, "Paused: worker")
+	expectResult(t, machineConfigPoolsResult([]machineconfigv1.MachineConfigPool{pool("master", true), pool("worker", false)}), resultFail, "Paused: master")
+
+	degraded := pool("infra", false)
+	degraded.Status.Conditions = []machineconfigv1.MachineConfigPoolCondition{{Type: machineconfigv1.MachineConfigPoolDegraded, Status: corev1.ConditionTrue}}
+	expectResult(t, machineConfigPoolsResult([]machineconfigv1.MachineConfigPool{degraded}), resultFail, "Degraded: infra")
+}
+
+func TestDeprecatedAPIsResult(t *testing.T) {
+	count := func(name, removedIn string, requests int64) apiserverv1.APIRequestCount {
+		return apiserverv1.APIRequestCount{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: apiserverv1.APIRequestCountStatus{RemovedInRelease: removedIn, RequestCount: requests}}
+	}
+	counts := []apiserverv1.APIRequestCount{
+		count("flowschemas.v1beta3.flowcontrol.apiserver.k8s.io", "1.32", 12),
+		count("deployments.v1.apps", "", 1000),
+		count("unused.v1beta1.example.com", "1.29", 0),
+	}
+	expectResult(t, deprecatedAPIsResult(counts, semver.MustParse("4.18.0")), resultPass, "Kubernetes 1.31")
+	expectResult(t, deprecatedAPIsResult(counts, semver.MustParse("4.19.0")), resultWarn, "flowschemas.v1beta3.flowcontrol.apiserver.k8s.io (12 requests)")
+}
+
+func TestUnsupportedConfigurationsResult(t *testing.T) {
+	withOverrides := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{"unsupportedConfigOverrides": map[string]interface{}{"foo": "bar"}}}}
+	withoutOverrides := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{"unsupportedConfigOverrides": nil}}}
+	if !hasUnsupportedConfigOverrides(withOverrides) || hasUnsupportedConfigOverrides(withoutOverrides) {
+		t.Errorf("unexpected unsupported config overrides detection")
+	}
+
+	cv := &configv1.ClusterVersion{}
+	expectResult(t, unsupportedConfigurationsResult(cv, map[string]bool{"etcds.operator.openshift.io": false}), resultPass, "No component")
+	cv.Spec.Overrides = []configv1.ComponentOverride{{Kind: "Deployment", Namespace: "openshift-monitoring", Name: "cluster-monitoring-operator", Unmanaged: true}}
+	expectResult(t, unsupportedConfigurationsResult(cv, map[string]bool{"etcds.operator.openshift.io": true}), resultWarn, "Unmanaged: Deployment openshift-monitoring/cluster-monitoring-operator; Unsupported config overrides: etcds.operator.openshift.io")
+}
+
+func TestCapacityResult(t *testing.T) {
+	node := func(name, cpu, memory string, labels map[string]string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			}},
+		}
+	}
+	pod := func(nodeName, cpu, memory string) corev1.Pod {
+		return corev1.Pod{Spec: corev1.PodSpec{NodeName: nodeName, Containers: []corev1.Container{{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		}}}}}}
+	}
+	nodes := []corev1.Node{
+		node("master-0", "4", "16Gi", map[string]string{"node-role.kubernetes.io/master": ""}),
+		node("worker-0", "4", "16Gi", nil),
+		node("worker-1", "4", "16Gi", nil),
+		node("worker-2", "8", "32Gi", nil),
+	}
+	expectResult(t, capacityResult(nodes, []corev1.Pod{pod("worker-0", "2", "8Gi"), pod("worker-2", "4", "8Gi"), pod("master-0", "4", "16Gi")}), resultPass, "3 nodes")
+	expectResult(t, capacityResult(nodes, []corev1.Pod{pod("worker-0", "3", "8Gi"), pod("worker-2", "6", "8Gi")}), resultWarn, "cpu requests of 9 exceed the 8 left")
+	expectResult(t, capacityResult(nodes[:2], nil), resultWarn, "1 schedulable node")
+}
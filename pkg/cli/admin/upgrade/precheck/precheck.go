@@ -0,0 +1,263 @@
+// Package precheck validates that a cluster is ready to be updated to a given version.
+package precheck
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/blang/semver"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+	machineconfigv1client "github.com/openshift/client-go/machineconfiguration/clientset/versioned"
+)
+
+func newOptions(streams genericiooptions.IOStreams) *options {
+	return &options{
+		IOStreams: streams,
+	}
+}
+
+func New(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := newOptions(streams)
+	cmd := &cobra.Command{
+		Use:   "pre-check --to=VERSION",
+		Short: "Check whether the cluster is ready to be updated to a version",
+		Long: templates.LongDesc(`
+			Check whether the cluster is ready to be updated to a version.
+
+			Runs a set of read-only validations against the connected cluster before an update is
+			requested, and reports each of them as pass, warn or fail:
+
+			* Update target: the version is newer than the current one and a recommended update.
+			* Cluster operators: no operator is degraded or unavailable.
+			* Upgradeable: neither the cluster version nor an operator blocks minor updates.
+			* Certificate signing requests: no request is waiting to be approved.
+			* Machine config pools: no pool is paused or degraded, so that every node is updated.
+			* Deprecated APIs: no client used an API in the last 24 hours that the target
+			  version no longer serves.
+			* Unsupported configurations: no component is unmanaged or has unsupported
+			  configuration overrides.
+			* Capacity headroom: the workloads of the compute nodes still fit when the largest
+			  of them is drained to be rebooted.
+
+			The command exits with an error if any check fails. Warnings do not prevent an update,
+			but should be reviewed before requesting it.
+		`),
+		Example: templates.Examples(`
+			# Check whether the cluster is ready to be updated to 4.16.5
+			oc adm upgrade pre-check --to=4.16.5
+		`),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run(cmd.Context()))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.To, "to", o.To, "The version the cluster would be updated to.")
+
+	return cmd
+}
+
+type options struct {
+	genericiooptions.IOStreams
+
+	// To is the version the cluster would be updated to.
+	To string
+
+	ConfigClient        configv1client.Interface
+	KubeClient          kubernetes.Interface
+	MachineConfigClient machineconfigv1client.Interface
+	DynamicClient       dynamic.Interface
+}
+
+func (o *options) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	kcmdutil.RequireNoArguments(cmd, args)
+	if len(o.To) == 0 {
+		return kcmdutil.UsageErrorf(cmd, "--to is required")
+	}
+	if _, err := semver.Parse(o.To); err != nil {
+		return fmt.Errorf("--to must be a semantic version: %v", err)
+	}
+
+	cfg, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.ConfigClient, err = configv1client.NewForConfig(cfg); err != nil {
+		return err
+	}
+	if o.KubeClient, err = kubernetes.NewForConfig(cfg); err != nil {
+		return err
+	}
+	if o.MachineConfigClient, err = machineconfigv1client.NewForConfig(cfg); err != nil {
+		return err
+	}
+	if o.DynamicClient, err = dynamic.NewForConfig(cfg); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (o *options) Run(ctx context.Context) error {
+	cv, err := o.ConfigClient.ConfigV1().ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("no cluster version information available - you must be connected to an OpenShift version 4 server to fetch the current version")
+		}
+		return err
+	}
+	current, err := semver.Parse(cv.Status.Desired.Version)
+	if err != nil {
+		return fmt.Errorf("invalid ClusterVersion status.desired.version: %w", err)
+	}
+	to, err := semver.Parse(o.To)
+	if err != nil {
+		return err
+	}
+
+	operatorsCheck, upgradeableCheck := o.checkOperators(ctx, cv, current, to)
+	results := []checkResult{
+		updateTargetResult(cv, current, to),
+		operatorsCheck,
+		upgradeableCheck,
+		o.checkCertificateSigningRequests(ctx),
+		o.checkMachineConfigPools(ctx),
+		o.checkDeprecatedAPIs(ctx, to),
+		o.checkUnsupportedConfigurations(ctx, cv),
+		o.checkCapacity(ctx),
+	}
+
+	fmt.Fprintf(o.Out, "Pre-update checks for %s to %s:\n\n", current, to)
+	w := tabwriter.NewWriter(o.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "CHECK\tRESULT\tMESSAGE\n")
+	counts := make(map[result]int)
+	for _, r := range results {
+		counts[r.Result]++
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.Check, r.Result, r.Message)
+	}
+	w.Flush()
+	fmt.Fprintf(o.Out, "\n%d passed, %d warnings, %d failed\n", counts[resultPass], counts[resultWarn], counts[resultFail])
+
+	if counts[resultFail] > 0 {
+		return fmt.Errorf("the cluster is not ready to be updated to %s", to)
+	}
+	return nil
+}
+
+type result string
+
+const (
+	resultPass result = "pass"
+	resultWarn result = "warn"
+	resultFail result = "fail"
+)
+
+// checkResult is the outcome of a check of the cluster.
+type checkResult struct {
+	Check   string
+	Result  result
+	Message string
+}
+
+// unknownResult is reported when the resources a check needs cannot be read.
+func unknownResult(check string, err error) checkResult {
+	return checkResult{Check: check, Result: resultWarn, Message: fmt.Sprintf("Unable to check: %v", err)}
+}
+
+// checkOperators returns the results of the health and Upgradeable checks of the operators.
+func (o *options) checkOperators(ctx context.Context, cv *configv1.ClusterVersion, current, to semver.Version) (checkResult, checkResult) {
+	operators, err := o.ConfigClient.ConfigV1().ClusterOperators().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return unknownResult(checkOperators, err), unknownResult(checkUpgradeable, err)
+	}
+	return operatorsResult(operators.Items), upgradeableResult(cv, operators.Items, current, to)
+}
+
+func (o *options) checkCertificateSigningRequests(ctx context.Context) checkResult {
+	csrs, err := o.KubeClient.CertificatesV1().CertificateSigningRequests().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return unknownResult(checkCertificateSigningRequests, err)
+	}
+	return certificateSigningRequestsResult(csrs.Items)
+}
+
+func (o *options) checkMachineConfigPools(ctx context.Context) checkResult {
+	pools, err := o.MachineConfigClient.MachineconfigurationV1().MachineConfigPools().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return unknownResult(checkMachineConfigPools, err)
+	}
+	return machineConfigPoolsResult(pools.Items)
+}
+
+var apiRequestCountsResource = schema.GroupVersionResource{Group: "apiserver.openshift.io", Version: "v1", Resource: "apirequestcounts"}
+
+func (o *options) checkDeprecatedAPIs(ctx context.Context, to semver.Version) checkResult {
+	list, err := o.DynamicClient.Resource(apiRequestCountsResource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return unknownResult(checkDeprecatedAPIs, err)
+	}
+	counts, err := apiRequestCounts(list)
+	if err != nil {
+		return unknownResult(checkDeprecatedAPIs, err)
+	}
+	return deprecatedAPIsResult(counts, to)
+}
+
+// operatorConfigResources are the operator configurations that accept unsupported overrides.
+var operatorConfigResources = []schema.GroupVersionResource{
+	{Group: "operator.openshift.io", Version: "v1", Resource: "authentications"},
+	{Group: "operator.openshift.io", Version: "v1", Resource: "etcds"},
+	{Group: "operator.openshift.io", Version: "v1", Resource: "kubeapiservers"},
+	{Group: "operator.openshift.io", Version: "v1", Resource: "kubecontrollermanagers"},
+	{Group: "operator.openshift.io", Version: "v1", Resource: "kubeschedulers"},
+	{Group: "operator.openshift.io", Version: "v1", Resource: "openshiftapiservers"},
+	{Group: "operator.openshift.io", Version: "v1", Resource: "openshiftcontrollermanagers"},
+}
+
+func (o *options) checkUnsupportedConfigurations(ctx context.Context, cv *configv1.ClusterVersion) checkResult {
+	overrides := make(map[string]bool)
+	for _, resource := range operatorConfigResources {
+		config, err := o.DynamicClient.Resource(resource).Get(ctx, "cluster", metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return unknownResult(checkUnsupportedConfigurations, err)
+		}
+		overrides[resource.GroupResource().String()] = hasUnsupportedConfigOverrides(config)
+	}
+	return unsupportedConfigurationsResult(cv, overrides)
+}
+
+func (o *options) checkCapacity(ctx context.Context) checkResult {
+	nodes, err := o.KubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return unknownResult(checkCapacity, err)
+	}
+	pods, err := o.KubeClient.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{FieldSelector: "status.phase!=Succeeded,status.phase!=Failed"})
+	if err != nil {
+		return unknownResult(checkCapacity, err)
+	}
+	return capacityResult(nodes.Items, pods.Items)
+}
+
+// joinNames lists the first names, so that messages remain readable on large clusters.
+func joinNames(names []string) string {
+	const max = 5
+	if len(names) <= max {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:max], ", "), len(names)-max)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"sort"
 	"strings"
 	"text/tabwriter"
@@ -51,6 +52,10 @@ func New(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command
 			'--version VERSION' to display context for a particular target release.  Use
 			'--show-outdated-releases' to display all known targets, including older
 			releases.
+
+			When '--version' selects a release which is not recommended, the conditional
+			update risks are listed along with the rules the cluster evaluates, such as
+			PromQL queries, to decide whether it is exposed to each risk.
 		`),
 		Run: func(cmd *cobra.Command, args []string) {
 			kcmdutil.CheckErr(o.Complete(f, cmd, args))
@@ -262,6 +267,7 @@ func (o *options) Run(ctx context.Context) error {
 						fmt.Fprintf(o.Out, "Update to %s has no known issues relevant to this cluster.\nImage: %s\nRelease URL: %s\n", update.Release.Version, update.Release.Image, update.Release.URL)
 					} else {
 						fmt.Fprintf(o.Out, "Update to %s %s=%s:\nImage: %s\nRelease URL: %s\nReason: %s\nMessage: %s\n", update.Release.Version, c.Type, c.Status, update.Release.Image, update.Release.URL, c.Reason, strings.ReplaceAll(c.Message, "\n", "\n  "))
+						writeRisks(o.Out, update.Risks)
 					}
 					return nil
 				}
@@ -364,6 +370,44 @@ func notRecommendedCondition(update configv1.ConditionalUpdate) *metav1.Conditio
 	}
 }
 
+// writeRisks describes each conditional update risk and the rules the
+// cluster-version operator uses to decide whether this cluster is exposed.
+func writeRisks(out io.Writer, risks []configv1.ConditionalUpdateRisk) {
+	if len(risks) == 0 {
+		return
+	}
+	sorted := make([]configv1.ConditionalUpdateRisk, len(risks))
+	copy(sorted, risks)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	fmt.Fprintf(out, "\nRisks:\n")
+	for _, risk := range sorted {
+		fmt.Fprintf(out, "  %s:\n", risk.Name)
+		if risk.URL != "" {
+			fmt.Fprintf(out, "    URL: %s\n", risk.URL)
+		}
+		if message := strings.TrimSpace(risk.Message); message != "" {
+			fmt.Fprintf(out, "    Message: %s\n", strings.ReplaceAll(message, "\n", "\n      "))
+		}
+		for _, rule := range risk.MatchingRules {
+			switch {
+			case rule.Type == "PromQL" && rule.PromQL != nil:
+				fmt.Fprintf(out, "    Matches when PromQL returns 1:\n      %s\n", strings.ReplaceAll(strings.TrimSpace(rule.PromQL.PromQL), "\n", "\n      "))
+			case rule.Type == "Always":
+				fmt.Fprintf(out, "    Matches all clusters.\n")
+			default:
+				fmt.Fprintf(out, "    Matching rule of unrecognized type %q.\n", rule.Type)
+			}
+			// the cluster-version operator only evaluates the first rule it understands
+			if rule.Type == "PromQL" || rule.Type == "Always" {
+				break
+			}
+		}
+	}
+}
+
 // sortConditionalUpdatesBySemanticVersions sorts the input slice in decreasing order.
 func sortConditionalUpdatesBySemanticVersions(updates []configv1.ConditionalUpdate) {
 	sort.Slice(updates, func(i, j int) bool {
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/blang/semver"
 	configv1 "github.com/openshift/api/config/v1"
@@ -39,6 +40,9 @@ func New(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command
 			not accepted.  Rolling back re-exposes the cluster to all the bugs which had been fixed from
 			4.y.older to 4.y.newer.  In most cases, you probably want to understand what is having trouble and
 			roll forward with fixes.
+
+			By default, this command only reports the rollback it would request.  Pass --confirm
+			to acknowledge the risks and request the rollback.
 		`),
 
 		Run: func(cmd *cobra.Command, args []string) {
@@ -47,6 +51,8 @@ func New(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command
 		},
 	}
 
+	cmd.Flags().BoolVar(&o.Confirm, "confirm", o.Confirm, "Acknowledge the risks of rolling back and request the rollback. Without this flag, the rollback is only reported.")
+
 	return cmd
 }
 
@@ -60,6 +66,9 @@ type clusterVersionInterface interface {
 type options struct {
 	genericiooptions.IOStreams
 
+	// Confirm acknowledges the rollback risks.  When false, Run only reports the rollback it would request.
+	Confirm bool
+
 	Client clusterVersionInterface
 }
 
@@ -95,6 +104,7 @@ func (o *options) Run(ctx context.Context) error {
 
 	var previousVersion *semver.Version
 	var previousImage string
+	var rolledBack []configv1.UpdateHistory
 	for _, entry := range cv.Status.History {
 		if entry.Version != targetVersion.String() || entry.Image != cv.Status.Desired.Image {
 			version, err := semver.Parse(entry.Version)
@@ -106,6 +116,7 @@ func (o *options) Run(ctx context.Context) error {
 			}
 			break
 		}
+		rolledBack = append(rolledBack, entry)
 	}
 
 	if previousVersion == nil {
@@ -131,6 +142,17 @@ func (o *options) Run(ctx context.Context) error {
 		return fmt.Errorf("unable to rollback while an update is %s=%s: %s: %s.", c.Type, c.Status, c.Reason, c.Message)
 	}
 
+	fmt.Fprintf(o.Out, "Rollback from %s (%s) to %s (%s) is permitted.\n", targetVersion, cv.Status.Desired.Image, previousVersion, previousImage)
+	for _, entry := range rolledBack {
+		fmt.Fprintf(o.Out, "  Will roll back %s update to %s (%s), started %s\n", entry.State, entry.Version, entry.Image, entry.StartedTime.UTC().Format(time.RFC3339))
+	}
+	fmt.Fprintf(o.Out, "Rolling back re-exposes the cluster to all bugs fixed between %s and %s.\n", previousVersion, targetVersion)
+
+	if !o.Confirm {
+		fmt.Fprintf(o.Out, "Rollback not requested.  Pass --confirm to acknowledge the risks and request the rollback.\n")
+		return nil
+	}
+
 	if err := patchDesiredUpdate(ctx, update, o.Client, cv.Name); err != nil {
 		return err
 	}
@@ -51,6 +51,7 @@ func TestRollback(t *testing.T) {
 	for _, testCase := range []struct {
 		name           string
 		clusterVersion *configv1.ClusterVersion
+		confirm        bool
 		expectedPatch  string
 		expectedError  string
 		expectedOut    string
@@ -172,8 +173,37 @@ func TestRollback(t *testing.T) {
 					},
 				},
 			},
+			confirm:       true,
 			expectedPatch: `{"spec":{"desiredUpdate": {"architecture":"","version":"1.2.3","image":"example.com/a","force":false}}}`,
-			expectedOut:   "Requested rollback from 1.2.4 to 1.2.3\n",
+			expectedOut: `Rollback from 1.2.4 (example.com/b) to 1.2.3 (example.com/a) is permitted.
+  Will roll back Completed update to 1.2.4 (example.com/b), started 0001-01-01T00:00:00Z
+Rolling back re-exposes the cluster to all bugs fixed between 1.2.3 and 1.2.4.
+Requested rollback from 1.2.4 to 1.2.3
+`,
+		}, {
+			name: "after patch update without confirmation",
+			clusterVersion: &configv1.ClusterVersion{
+				Status: configv1.ClusterVersionStatus{
+					Desired: configv1.Release{Version: "1.2.4", Image: "example.com/b"},
+					Conditions: []configv1.ClusterOperatorStatusCondition{
+						{
+							Type:    configv1.OperatorProgressing,
+							Status:  configv1.ConditionFalse,
+							Reason:  "AsExpected",
+							Message: "Happy on 1.2.4",
+						},
+					},
+					History: []configv1.UpdateHistory{
+						{State: configv1.CompletedUpdate, Version: "1.2.4", Image: "example.com/b"},
+						{State: configv1.CompletedUpdate, Version: "1.2.3", Image: "example.com/a"},
+					},
+				},
+			},
+			expectedOut: `Rollback from 1.2.4 (example.com/b) to 1.2.3 (example.com/a) is permitted.
+  Will roll back Completed update to 1.2.4 (example.com/b), started 0001-01-01T00:00:00Z
+Rolling back re-exposes the cluster to all bugs fixed between 1.2.3 and 1.2.4.
+Rollback not requested.  Pass --confirm to acknowledge the risks and request the rollback.
+`,
 		}, {
 			name: "after re-targeted partial update",
 			clusterVersion: &configv1.ClusterVersion{
@@ -194,8 +224,13 @@ func TestRollback(t *testing.T) {
 					},
 				},
 			},
+			confirm:       true,
 			expectedPatch: `{"spec":{"desiredUpdate": {"architecture":"","version":"1.2.4","image":"example.com/b","force":false}}}`,
-			expectedOut:   "Requested rollback from 1.2.5 to 1.2.4\n",
+			expectedOut: `Rollback from 1.2.5 (example.com/c) to 1.2.4 (example.com/b) is permitted.
+  Will roll back Completed update to 1.2.5 (example.com/c), started 0001-01-01T00:00:00Z
+Rolling back re-exposes the cluster to all bugs fixed between 1.2.4 and 1.2.5.
+Requested rollback from 1.2.5 to 1.2.4
+`,
 		},
 	} {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -207,6 +242,7 @@ func TestRollback(t *testing.T) {
 			client := &mockClusterVersionInterface{clusterVersion: clusterVersion}
 			o := &options{
 				IOStreams: streams,
+				Confirm:   testCase.confirm,
 				Client:    client,
 			}
 			err := o.Run(ctx)
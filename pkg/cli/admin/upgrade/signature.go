@@ -0,0 +1,187 @@
+package upgrade
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	configv1 "github.com/openshift/api/config/v1"
+	imagereference "github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/library-go/pkg/manifest"
+	"github.com/openshift/library-go/pkg/verify"
+	"github.com/openshift/library-go/pkg/verify/store"
+	"github.com/openshift/library-go/pkg/verify/store/configmap"
+	"github.com/openshift/library-go/pkg/verify/store/sigstore"
+	"github.com/openshift/library-go/pkg/verify/util"
+)
+
+// maxSignatureSearch bounds the number of signature-N files read from a local signature directory.
+const maxSignatureSearch = 10
+
+// signatureDirStore reads signatures from a local directory using the same
+// <ALGO>=<DIGEST>/signature-<NUMBER> layout as the signature stores, e.g. one
+// produced by 'oc adm release mirror --release-image-signature-to-dir'.
+type signatureDirStore struct {
+	directory string
+}
+
+func (s *signatureDirStore) Signatures(ctx context.Context, name string, digest string, fn store.Callback) error {
+	digestPathSegment, err := util.DigestToKeyPrefix(digest, "=")
+	if err != nil {
+		return err
+	}
+
+	base := filepath.Join(s.directory, digestPathSegment, "signature-")
+	for i := 1; i < maxSignatureSearch; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		data, err := os.ReadFile(base + strconv.Itoa(i))
+		if os.IsNotExist(err) {
+			break
+		}
+		done, err := fn(ctx, data, err)
+		if done || err != nil {
+			return err
+		}
+	}
+
+	// the directory may also hold signatures in the config map format written by 'oc adm release mirror --to-dir'
+	prefix, err := util.DigestToKeyPrefix(digest, "-")
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(filepath.Join(s.directory, prefix+".yaml"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		_, err = fn(ctx, nil, err)
+		return err
+	}
+	manifests, err := manifest.ParseManifests(bytes.NewReader(data))
+	if err != nil {
+		_, err = fn(ctx, nil, err)
+		return err
+	}
+	for _, m := range manifests {
+		cm, err := util.ReadConfigMap(m.Raw)
+		if err != nil || cm == nil {
+			continue
+		}
+		keys := make([]string, 0, len(cm.BinaryData))
+		for k := range cm.BinaryData {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			done, err := fn(ctx, cm.BinaryData[k], nil)
+			if done || err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *signatureDirStore) String() string {
+	return fmt.Sprintf("the local directory %s", s.directory)
+}
+
+// clusterVerifier builds a release verifier from the verification config map the
+// cluster-version operator applied from the current release payload, adding the
+// cluster's configured signature stores, the signatures stored in config maps on
+// the cluster, and the optional local signature directory.
+func clusterVerifier(ctx context.Context, kubeClient kubernetes.Interface, cv *configv1.ClusterVersion, signatureDir string) (verify.Interface, error) {
+	configMaps, err := kubeClient.CoreV1().ConfigMaps(configmap.NamespaceLabelConfigMap).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list config maps in %s: %w", configmap.NamespaceLabelConfigMap, err)
+	}
+
+	sort.Slice(configMaps.Items, func(i, j int) bool {
+		return configMaps.Items[i].Name < configMaps.Items[j].Name
+	})
+	var manifests []manifest.Manifest
+	for i := range configMaps.Items {
+		cm := configMaps.Items[i]
+		if _, ok := cm.Annotations[verify.ReleaseAnnotationConfigMapVerifier]; !ok {
+			continue
+		}
+		cm.TypeMeta = metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "ConfigMap"}
+		raw, err := json.Marshal(&cm)
+		if err != nil {
+			return nil, err
+		}
+		ms, err := manifest.ParseManifests(bytes.NewReader(raw))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse the config map %s/%s: %w", cm.Namespace, cm.Name, err)
+		}
+		manifests = append(manifests, ms...)
+	}
+
+	httpClient := sigstore.NewCachedHTTPClientConstructor(sigstore.DefaultClient, nil).HTTPClient
+	verifier, err := verify.NewFromManifests(manifests, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	if verifier == nil {
+		return nil, fmt.Errorf("no config map in %s has the %s annotation, so the cluster has no release verification configured", configmap.NamespaceLabelConfigMap, verify.ReleaseAnnotationConfigMapVerifier)
+	}
+
+	for _, signatureStore := range cv.Spec.SignatureStores {
+		u, err := url.Parse(signatureStore.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ClusterVersion spec.signatureStores URL %q: %w", signatureStore.URL, err)
+		}
+		verifier.AddStore(&sigstore.Store{URI: u, HTTPClient: httpClient})
+	}
+	verifier.AddStore(configmap.NewStore(kubeClient.CoreV1(), nil))
+	if len(signatureDir) > 0 {
+		verifier.AddStore(&signatureDirStore{directory: signatureDir})
+	}
+	return verifier, nil
+}
+
+// verifyReleaseSignature checks the signature of the by-digest release image
+// before it is requested.  A failed verification is an error unless --force is set.
+func (o *Options) verifyReleaseSignature(ctx context.Context, cv *configv1.ClusterVersion, image string) error {
+	ref, err := imagereference.Parse(image)
+	if err != nil {
+		return err
+	}
+	if len(ref.ID) == 0 {
+		return fmt.Errorf("unable to verify the signature of %s: release images that are not accessed via digest cannot be verified", image)
+	}
+
+	verifier := o.Verifier
+	if verifier == nil {
+		if verifier, err = clusterVerifier(ctx, o.KubeClient, cv, o.SignatureDir); err != nil {
+			if o.Force {
+				fmt.Fprintf(o.ErrOut, "warning: --force is bypassing signature verification of %s: %v\n", image, err)
+				return nil
+			}
+			return fmt.Errorf("unable to verify the signature of %s: %v\n\nIf you want to upgrade anyway, use --force.", image, err)
+		}
+	}
+
+	if err := verifier.Verify(ctx, ref.ID); err != nil {
+		if o.Force {
+			fmt.Fprintf(o.ErrOut, "warning: --force is bypassing failed signature verification of %s: %v\n", image, err)
+			return nil
+		}
+		return fmt.Errorf("the release image %s failed signature verification: %v\n\nIf you want to upgrade anyway, use --force.", image, err)
+	}
+	fmt.Fprintf(o.Out, "info: The release image %s is signed by a key the cluster trusts\n", image)
+	return nil
+}
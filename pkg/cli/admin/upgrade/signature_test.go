@@ -0,0 +1,121 @@
+package upgrade
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/crypto/openpgp"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/verify/store"
+)
+
+const testDigest = "sha256:0e71cb61694473b40e8d95f530eaf250a62616debb98199f31b4034808687dae"
+
+type fakeVerifier struct {
+	err      error
+	verified string
+}
+
+func (v *fakeVerifier) Verify(ctx context.Context, releaseDigest string) error {
+	v.verified = releaseDigest
+	return v.err
+}
+
+func (v *fakeVerifier) Signatures() map[string][][]byte          { return nil }
+func (v *fakeVerifier) Verifiers() map[string]openpgp.EntityList { return nil }
+func (v *fakeVerifier) AddStore(additionalStore store.Store)     {}
+
+func TestSignatureDirStore(t *testing.T) {
+	dir := t.TempDir()
+	digestDir := filepath.Join(dir, "sha256="+testDigest[len("sha256:"):])
+	if err := os.MkdirAll(digestDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"signature-1": "first", "signature-2": "second"} {
+		if err := os.WriteFile(filepath.Join(digestDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var signatures []string
+	s := &signatureDirStore{directory: dir}
+	err := s.Signatures(context.Background(), "", testDigest, func(ctx context.Context, signature []byte, errIn error) (bool, error) {
+		if errIn != nil {
+			return false, errIn
+		}
+		signatures = append(signatures, string(signature))
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"first", "second"}; !reflect.DeepEqual(signatures, expected) {
+		t.Errorf("expected signatures %v, got %v", expected, signatures)
+	}
+}
+
+func TestVerifyReleaseSignature(t *testing.T) {
+	for _, testCase := range []struct {
+		name          string
+		image         string
+		verifyErr     error
+		force         bool
+		expectedError string
+		expectedOut   string
+		expectedErr   string
+	}{
+		{
+			name:          "by-tag",
+			image:         "example.com/ocp/release:4.16.1",
+			expectedError: "unable to verify the signature of example.com/ocp/release:4.16.1: release images that are not accessed via digest cannot be verified",
+		},
+		{
+			name:        "verified",
+			image:       "example.com/ocp/release@" + testDigest,
+			expectedOut: "info: The release image example.com/ocp/release@" + testDigest + " is signed by a key the cluster trusts\n",
+		},
+		{
+			name:          "failed",
+			image:         "example.com/ocp/release@" + testDigest,
+			verifyErr:     errors.New("no signatures"),
+			expectedError: "the release image example.com/ocp/release@" + testDigest + " failed signature verification: no signatures\n\nIf you want to upgrade anyway, use --force.",
+		},
+		{
+			name:        "failed with force",
+			image:       "example.com/ocp/release@" + testDigest,
+			verifyErr:   errors.New("no signatures"),
+			force:       true,
+			expectedErr: "warning: --force is bypassing failed signature verification of example.com/ocp/release@" + testDigest + ": no signatures\n",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+			verifier := &fakeVerifier{err: testCase.verifyErr}
+			o := NewOptions(genericiooptions.IOStreams{Out: out, ErrOut: errOut})
+			o.Force = testCase.force
+			o.Verifier = verifier
+
+			err := o.verifyReleaseSignature(context.Background(), &configv1.ClusterVersion{}, testCase.image)
+			var actualError string
+			if err != nil {
+				actualError = err.Error()
+			}
+			if actualError != testCase.expectedError {
+				t.Errorf("expected error %q, got %q", testCase.expectedError, actualError)
+			}
+			if out.String() != testCase.expectedOut {
+				t.Errorf("expected output %q, got %q", testCase.expectedOut, out.String())
+			}
+			if errOut.String() != testCase.expectedErr {
+				t.Errorf("expected error output %q, got %q", testCase.expectedErr, errOut.String())
+			}
+		})
+	}
+}
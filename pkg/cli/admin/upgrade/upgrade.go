@@ -18,17 +18,21 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/templates"
 
 	configv1 "github.com/openshift/api/config/v1"
 	configv1client "github.com/openshift/client-go/config/clientset/versioned"
 	imagereference "github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/library-go/pkg/verify"
 
 	"github.com/openshift/oc/pkg/cli/admin/upgrade/channel"
+	"github.com/openshift/oc/pkg/cli/admin/upgrade/precheck"
 	"github.com/openshift/oc/pkg/cli/admin/upgrade/recommend"
 	"github.com/openshift/oc/pkg/cli/admin/upgrade/rollback"
 	"github.com/openshift/oc/pkg/cli/admin/upgrade/status"
+	ocompletion "github.com/openshift/oc/pkg/helpers/completion"
 )
 
 const (
@@ -44,6 +48,9 @@ var upgradeExample = templates.Examples(`
 
 	# Update to the latest version
 	oc adm upgrade --to-latest=true
+
+	# Update to a mirrored release image, checking its signature against a local signature directory first
+	oc adm upgrade --to-image=mirror.example.com/ocp/release@sha256:... --allow-explicit-upgrade --signature-dir=./signatures
 `)
 
 func NewOptions(streams genericiooptions.IOStreams) *Options {
@@ -96,6 +103,13 @@ func New(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command
 			Again, it is usually best to give these conditions time to resolve, or to actively work to
 			resolve them.  But if you decide to trigger the update regardless of these concerns,
 			use --force, which is passed through to ClusterVersion's spec.desiredUpdate.force.
+
+			Passing --verify-signature checks the signature of the by-digest target release image
+			before requesting the update, using the verification keys and signature stores the
+			cluster is configured with.  For disconnected clusters, --signature-dir may point at a
+			local directory of signatures, such as one written by 'oc adm release mirror'.  This
+			surfaces verification problems before the cluster rejects the update, instead of
+			reaching for --force.
 		`),
 		Run: func(cmd *cobra.Command, args []string) {
 			kcmdutil.CheckErr(o.Complete(f, cmd, args))
@@ -113,8 +127,17 @@ func New(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command
 	flags.BoolVar(&o.AllowUpgradeWithWarnings, "allow-upgrade-with-warnings", o.AllowUpgradeWithWarnings, "Upgrade regardless of client-side guard failures, such as upgrades in progress or failing clusters.")
 	flags.BoolVar(&o.IncludeNotRecommended, "include-not-recommended", o.IncludeNotRecommended, "Display additional updates which are not recommended based on your cluster configuration.")
 	flags.BoolVar(&o.AllowNotRecommended, "allow-not-recommended", o.AllowNotRecommended, "Allows upgrade to a version when it is supported but not recommended for updates.")
+	flags.BoolVar(&o.VerifySignature, "verify-signature", o.VerifySignature, "Verify the signature of the target release image against the cluster's configured signature stores before requesting the update.")
+	flags.StringVar(&o.SignatureDir, "signature-dir", o.SignatureDir, "A local directory of release image signatures to consult during signature verification. Implies --verify-signature.")
+	kcmdutil.CheckErr(cmd.RegisterFlagCompletionFunc("to", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return ocompletion.UpdateCompletions(ocompletion.ClusterVersion(f), toComplete, false), cobra.ShellCompDirectiveNoFileComp
+	}))
+	kcmdutil.CheckErr(cmd.RegisterFlagCompletionFunc("to-image", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return ocompletion.UpdateCompletions(ocompletion.ClusterVersion(f), toComplete, true), cobra.ShellCompDirectiveNoFileComp
+	}))
 
 	cmd.AddCommand(channel.New(f, streams))
+	cmd.AddCommand(precheck.New(f, streams))
 
 	if kcmdutil.FeatureGate("OC_ENABLE_CMD_UPGRADE_STATUS").IsEnabled() {
 		cmd.AddCommand(status.New(f, streams))
@@ -144,7 +167,13 @@ type Options struct {
 	IncludeNotRecommended    bool
 	AllowNotRecommended      bool
 
-	Client configv1client.Interface
+	VerifySignature bool
+	SignatureDir    string
+
+	Client     configv1client.Interface
+	KubeClient kubernetes.Interface
+	// Verifier overrides the verifier built from the cluster configuration, for testing.
+	Verifier verify.Interface
 }
 
 func (o *Options) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
@@ -159,6 +188,12 @@ func (o *Options) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string
 	if len(o.To) > 0 && len(o.ToImage) > 0 {
 		return fmt.Errorf("only one of --to or --to-image may be provided")
 	}
+	if len(o.SignatureDir) > 0 {
+		o.VerifySignature = true
+	}
+	if o.VerifySignature && len(o.To) == 0 && len(o.ToImage) == 0 && !o.ToLatestAvailable {
+		return fmt.Errorf("--verify-signature and --signature-dir require one of --to, --to-image, or --to-latest")
+	}
 	if len(o.To) > 0 {
 		if _, err := semver.Parse(o.To); err != nil {
 			return fmt.Errorf("--to must be a semantic version (e.g. 4.0.1 or 4.1.0-nightly-20181104): %v", err)
@@ -195,6 +230,11 @@ func (o *Options) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string
 		return err
 	}
 	o.Client = client
+	if o.VerifySignature {
+		if o.KubeClient, err = kubernetes.NewForConfig(cfg); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -389,6 +429,12 @@ func (o *Options) Run() error {
 			fmt.Fprintf(o.ErrOut, "warning: --allow-upgrade-with-warnings is bypassing: %s\n", err)
 		}
 
+		if o.VerifySignature {
+			if err := o.verifyReleaseSignature(ctx, cv, update.Image); err != nil {
+				return err
+			}
+		}
+
 		if err := patchDesiredUpdate(ctx, update, o.Client, cv.Name); err != nil {
 			return err
 		}
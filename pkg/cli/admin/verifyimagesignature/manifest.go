@@ -2,21 +2,26 @@ package verifyimagesignature
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/ocischema"
+	"github.com/distribution/distribution/v3/manifest/schema2"
 	godigest "github.com/opencontainers/go-digest"
 
 	"k8s.io/client-go/rest"
 
 	"github.com/openshift/library-go/pkg/image/registryclient"
+	imagemanifest "github.com/openshift/oc/pkg/cli/image/manifest"
 )
 
-// getImageManifestByIDFromRegistry retrieves the image manifest from the registry using the basic
-// authentication using the image ID.
-func getImageManifestByIDFromRegistry(registry *url.URL, repositoryName, imageID, username, password string, insecure bool) ([]byte, error) {
-	ctx := context.Background()
-
+// getRepository returns the repository of the registry, authenticating with basic
+// authentication.
+func getRepository(ctx context.Context, registry *url.URL, repositoryName, username, password string, insecure bool) (distribution.Repository, error) {
 	credentials := registryclient.NewBasicCredentials()
 	credentials.Add(registry, username, password)
 
@@ -25,9 +30,17 @@ func getImageManifestByIDFromRegistry(registry *url.URL, repositoryName, imageID
 		return nil, err
 	}
 
-	repo, err := registryclient.NewContext(http.DefaultTransport, insecureRT).
+	return registryclient.NewContext(http.DefaultTransport, insecureRT).
 		WithCredentials(credentials).
 		Repository(ctx, registry, repositoryName, insecure)
+}
+
+// getImageManifestByIDFromRegistry retrieves the image manifest from the registry using the basic
+// authentication using the image ID.
+func getImageManifestByIDFromRegistry(registry *url.URL, repositoryName, imageID, username, password string, insecure bool) ([]byte, error) {
+	ctx := context.Background()
+
+	repo, err := getRepository(ctx, registry, repositoryName, username, password, insecure)
 	if err != nil {
 		return nil, err
 	}
@@ -48,3 +61,59 @@ func getImageManifestByIDFromRegistry(registry *url.URL, repositoryName, imageID
 
 	return manifestPayload, nil
 }
+
+// getSigstoreSignaturesFromRegistry retrieves the sigstore signatures of the image from the
+// registry. Cosign pushes them as the layers of the sha256-<digest>.sig tag of the repository
+// of the image. No signatures are returned if the tag does not exist.
+func getSigstoreSignaturesFromRegistry(registry *url.URL, repositoryName, imageID, username, password string, insecure bool) ([]sigstoreSignature, error) {
+	ctx := context.Background()
+
+	repo, err := getRepository(ctx, registry, repositoryName, username, password, insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	tag := strings.Replace(imageID, ":", "-", 1) + ".sig"
+	desc, err := repo.Tags(ctx).Get(ctx, tag)
+	if err != nil {
+		if errors.As(err, &distribution.ErrTagUnknown{}) || imagemanifest.IsImageNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to get the signatures tag %s: %v", tag, err)
+	}
+
+	manifests, err := repo.Manifests(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := manifests.Get(ctx, desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+	var layers []distribution.Descriptor
+	switch t := manifest.(type) {
+	case *ocischema.DeserializedManifest:
+		layers = t.Layers
+	case *schema2.DeserializedManifest:
+		layers = t.Layers
+	default:
+		return nil, fmt.Errorf("the signatures tag %s has an unsupported manifest type %T", tag, manifest)
+	}
+
+	var signatures []sigstoreSignature
+	for _, layer := range layers {
+		if layer.MediaType != sigstoreSignatureMIMEType {
+			continue
+		}
+		payload, err := repo.Blobs(ctx).Get(ctx, layer.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the signature %s: %v", layer.Digest, err)
+		}
+		signatures = append(signatures, sigstoreSignature{
+			digest:      layer.Digest.String(),
+			payload:     payload,
+			annotations: layer.Annotations,
+		})
+	}
+	return signatures, nil
+}
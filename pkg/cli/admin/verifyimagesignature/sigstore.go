@@ -0,0 +1,305 @@
+package verifyimagesignature
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/containers/image/v5/directory"
+	"github.com/containers/image/v5/image"
+	"github.com/containers/image/v5/signature"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+const (
+	// sigstoreSignatureMIMEType is the media type of the layers of cosign signatures.
+	sigstoreSignatureMIMEType = "application/vnd.dev.cosign.simplesigning.v1+json"
+	// sigstoreSignatureAnnotation holds the base64 encoded signature of the payload of a layer.
+	sigstoreSignatureAnnotation = "dev.cosignproject.cosign/signature"
+	// sigstoreBundleAnnotation holds the Rekor signed entry timestamp of a signature.
+	sigstoreBundleAnnotation = "dev.sigstore.cosign/bundle"
+	// sigstoreCertificateAnnotation holds the Fulcio certificate of a keyless signature.
+	sigstoreCertificateAnnotation = "dev.sigstore.cosign/certificate"
+
+	// The annotations the result of the verification of the sigstore signatures is saved to
+	// with --save.
+	sigstoreVerifiedAnnotation   = "sigstore.signature.openshift.io/verified"
+	sigstoreVerifiedByAnnotation = "sigstore.signature.openshift.io/verified-by"
+	sigstoreVerifiedAtAnnotation = "sigstore.signature.openshift.io/verified-at"
+	sigstoreIdentityAnnotation   = "sigstore.signature.openshift.io/identity"
+	sigstoreSignerAnnotation     = "sigstore.signature.openshift.io/signer"
+	sigstoreRekorAnnotation      = "sigstore.signature.openshift.io/rekor"
+)
+
+// sigstoreSignature is a cosign signature of an image, the payload and annotations of a
+// layer of its signatures tag.
+type sigstoreSignature struct {
+	digest      string
+	payload     []byte
+	annotations map[string]string
+}
+
+// blob returns the signature in the format containers/image reads sigstore signatures from:
+// a zero byte, the name of the format and a newline followed by the JSON of the signature.
+func (s sigstoreSignature) blob() ([]byte, error) {
+	data, err := json.Marshal(struct {
+		MIMEType    string            `json:"mimeType"`
+		Payload     []byte            `json:"payload"`
+		Annotations map[string]string `json:"annotations"`
+	}{sigstoreSignatureMIMEType, s.payload, s.annotations})
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("\x00sigstore-json\n"), data...), nil
+}
+
+// cosignBundle is the offline bundle written by cosign sign --bundle.
+type cosignBundle struct {
+	Base64Signature string          `json:"base64Signature"`
+	Cert            string          `json:"cert"`
+	RekorBundle     json.RawMessage `json:"rekorBundle"`
+}
+
+// readBundle reads the --bundle file.
+func readBundle(filename string) (*cosignBundle, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	bundle := &cosignBundle{}
+	if err := json.Unmarshal(data, bundle); err != nil {
+		return nil, fmt.Errorf("%s is not a cosign bundle: %v", filename, err)
+	}
+	if len(bundle.Base64Signature) == 0 || len(bundle.RekorBundle) == 0 {
+		return nil, fmt.Errorf("%s is not a cosign bundle: base64Signature and rekorBundle are required", filename)
+	}
+	return bundle, nil
+}
+
+// applyBundle adds the Rekor entry and certificate of the bundle to the signature it was
+// created for, if the signature does not carry its own.
+func applyBundle(signatures []sigstoreSignature, bundle *cosignBundle) bool {
+	applied := false
+	for i, s := range signatures {
+		if s.annotations[sigstoreSignatureAnnotation] != bundle.Base64Signature {
+			continue
+		}
+		annotations := map[string]string{}
+		for k, v := range s.annotations {
+			annotations[k] = v
+		}
+		if _, ok := annotations[sigstoreBundleAnnotation]; !ok {
+			annotations[sigstoreBundleAnnotation] = string(bundle.RekorBundle)
+		}
+		if _, ok := annotations[sigstoreCertificateAnnotation]; !ok && len(bundle.Cert) > 0 {
+			annotations[sigstoreCertificateAnnotation] = bundle.Cert
+		}
+		signatures[i].annotations = annotations
+		applied = true
+	}
+	return applied
+}
+
+// isSigstore returns true if the image is verified against its sigstore signatures instead
+// of its atomic signatures.
+func (o *VerifyImageSignatureOptions) isSigstore() bool {
+	return len(o.SigstorePublicKeyFilename) > 0 || len(o.FulcioCAFilename) > 0
+}
+
+// sigstoreSigner returns the identity of the signer trusted by --sigstore-public-key or
+// --fulcio-ca.
+func (o *VerifyImageSignatureOptions) sigstoreSigner() (string, error) {
+	if len(o.SigstorePublicKey) == 0 {
+		return o.SubjectEmail, nil
+	}
+	block, _ := pem.Decode(o.SigstorePublicKey)
+	if block == nil {
+		return "", fmt.Errorf("%s is not a PEM encoded public key", o.SigstorePublicKeyFilename)
+	}
+	sum := sha256.Sum256(block.Bytes)
+	return "SHA256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// sigstorePolicyContext returns the policy accepting the sigstore signatures signed by the
+// key, or the Fulcio certificates, of the options.
+func (o *VerifyImageSignatureOptions) sigstorePolicyContext() (*signature.PolicyContext, error) {
+	options := []signature.PRSigstoreSignedOption{
+		signature.PRSigstoreSignedWithSignedIdentity(signature.NewPRMMatchRepoDigestOrExact()),
+	}
+	if len(o.SigstorePublicKey) > 0 {
+		options = append(options, signature.PRSigstoreSignedWithKeyData(o.SigstorePublicKey))
+	} else {
+		fulcio, err := signature.NewPRSigstoreSignedFulcio(
+			signature.PRSigstoreSignedFulcioWithCAData(o.FulcioCA),
+			signature.PRSigstoreSignedFulcioWithOIDCIssuer(o.OIDCIssuer),
+			signature.PRSigstoreSignedFulcioWithSubjectEmail(o.SubjectEmail),
+		)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, signature.PRSigstoreSignedWithFulcio(fulcio))
+	}
+	if len(o.RekorPublicKey) > 0 {
+		options = append(options, signature.PRSigstoreSignedWithRekorPublicKeyData(o.RekorPublicKey))
+	}
+	pr, err := signature.NewPRSigstoreSigned(options...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to prepare verification policy requirements: %v", err)
+	}
+	policy := signature.Policy{Default: []signature.PolicyRequirement{pr}}
+	pc, err := signature.NewPolicyContext(&policy)
+	if err != nil {
+		return nil, fmt.Errorf("unable to setup policy: %v", err)
+	}
+	return pc, nil
+}
+
+// runSigstore verifies the cosign signatures of the image, stored next to it in the
+// registry, and records the result in the annotations of the image.
+func (o VerifyImageSignatureOptions) runSigstore(img *imagev1.Image) error {
+	signatures, err := o.getSigstoreSignatures(img)
+	if err != nil {
+		return err
+	}
+	if len(signatures) == 0 {
+		return fmt.Errorf("%s does not have any sigstore signature", img.Name)
+	}
+	if o.bundle != nil && !applyBundle(signatures, o.bundle) {
+		return fmt.Errorf("the signature of the bundle %s is not a signature of image %s", o.BundleFilename, img.Name)
+	}
+	signer, err := o.sigstoreSigner()
+	if err != nil {
+		return err
+	}
+	manifest, err := o.getImageManifest(img)
+	if err != nil {
+		return fmt.Errorf("failed to get image %q manifest: %v", img.Name, err)
+	}
+
+	pc, err := o.sigstorePolicyContext()
+	if err != nil {
+		return err
+	}
+	defer pc.Destroy()
+
+	verified := false
+	for _, s := range signatures {
+		if err := o.verifySigstoreSignature(pc, manifest, s); err != nil {
+			fmt.Fprintf(o.ErrOut, "error verifying sigstore signature %s for image %s: %v\n", s.digest, o.InputImage, err)
+			continue
+		}
+		verified = true
+	}
+
+	if verified {
+		if len(o.RekorPublicKey) > 0 {
+			fmt.Fprintf(o.Out, "image %q identity is now confirmed (signed by %q, recorded in the Rekor transparency log)\n", o.InputImage, signer)
+		} else {
+			fmt.Fprintf(o.Out, "image %q identity is now confirmed (signed by %q)\n", o.InputImage, signer)
+		}
+		setSigstoreVerification(img, o.CurrentUser, o.ExpectedIdentity, signer, len(o.RekorPublicKey) > 0, time.Now())
+	} else {
+		fmt.Fprintf(o.ErrOut, "no sigstore signature of image %s could be verified (verification status will be removed)\n", o.InputImage)
+		clearSigstoreVerification(img)
+	}
+
+	if o.Save {
+		_, err := o.ImageClient.Images().Update(context.TODO(), img, metav1.UpdateOptions{})
+		return err
+	}
+	fmt.Fprintf(o.Out, "--save was not passed, image %q not updated to %v\n", o.InputImage, img.Annotations)
+	return nil
+}
+
+// verifySigstoreSignature verifies a sigstore signature of the image against the policy.
+// containers/image only reads sigstore signatures from image sources, so the manifest and
+// the signature are laid out in a temporary directory the dir: transport reads them from.
+func (o *VerifyImageSignatureOptions) verifySigstoreSignature(pc *signature.PolicyContext, manifest []byte, s sigstoreSignature) error {
+	ctx := context.TODO()
+	blob, err := s.blob()
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "verify-image-signature")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	ref, err := directory.NewReference(dir)
+	if err != nil {
+		return err
+	}
+	dest, err := ref.NewImageDestination(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+	if err := dest.PutManifest(ctx, manifest, nil); err != nil {
+		return err
+	}
+	if err := dest.PutSignatures(ctx, [][]byte{blob}, nil); err != nil {
+		return err
+	}
+
+	src, err := ref.NewImageSource(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	// We check the error in Validate()
+	expectedRef, _ := parseDockerReference("//" + o.ExpectedIdentity)
+	allowed, err := pc.IsRunningImageAllowed(ctx, image.UnparsedInstanceWithReference(image.UnparsedInstance(src, nil), expectedRef))
+	if !allowed && err == nil {
+		return fmt.Errorf("signature rejected but no error set")
+	}
+	if err != nil {
+		return fmt.Errorf("signature rejected: %v", err)
+	}
+	return nil
+}
+
+// getSigstoreSignatures fetches the sigstore signatures of the image from the integrated registry.
+func (o *VerifyImageSignatureOptions) getSigstoreSignatures(img *imagev1.Image) ([]sigstoreSignature, error) {
+	registryURL, repositoryName, err := o.registryRepository(img)
+	if err != nil {
+		return nil, err
+	}
+	return getSigstoreSignaturesFromRegistry(registryURL, repositoryName, img.Name, "user", o.CurrentUserToken, o.Insecure)
+}
+
+// setSigstoreVerification records in the annotations of the image that its sigstore
+// signatures were verified.
+func setSigstoreVerification(img *imagev1.Image, user, identity, signer string, rekor bool, now time.Time) {
+	if img.Annotations == nil {
+		img.Annotations = map[string]string{}
+	}
+	img.Annotations[sigstoreVerifiedAnnotation] = "true"
+	img.Annotations[sigstoreVerifiedByAnnotation] = user
+	img.Annotations[sigstoreVerifiedAtAnnotation] = now.UTC().Format(time.RFC3339)
+	img.Annotations[sigstoreIdentityAnnotation] = identity
+	img.Annotations[sigstoreSignerAnnotation] = signer
+	img.Annotations[sigstoreRekorAnnotation] = fmt.Sprintf("%t", rekor)
+}
+
+// clearSigstoreVerification removes the result of a previous verification of the sigstore
+// signatures of the image.
+func clearSigstoreVerification(img *imagev1.Image) {
+	for _, key := range []string{
+		sigstoreVerifiedAnnotation,
+		sigstoreVerifiedByAnnotation,
+		sigstoreVerifiedAtAnnotation,
+		sigstoreIdentityAnnotation,
+		sigstoreSignerAnnotation,
+		sigstoreRekorAnnotation,
+	} {
+		delete(img.Annotations, key)
+	}
+}
@@ -0,0 +1,311 @@
+package verifyimagesignature
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	digest "github.com/opencontainers/go-digest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+func TestValidateSigstore(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       VerifyImageSignatureOptions
+		expectedError string
+	}{
+		{
+			name:    "public key",
+			options: VerifyImageSignatureOptions{ExpectedIdentity: "registry.io/foo/bar:latest", SigstorePublicKeyFilename: "cosign.pub"},
+		},
+		{
+			name:    "public key with bundle",
+			options: VerifyImageSignatureOptions{ExpectedIdentity: "registry.io/foo/bar:latest", SigstorePublicKeyFilename: "cosign.pub", RekorPublicKeyFilename: "rekor.pub", BundleFilename: "bundle.json"},
+		},
+		{
+			name:    "fulcio",
+			options: VerifyImageSignatureOptions{ExpectedIdentity: "registry.io/foo/bar:latest", FulcioCAFilename: "fulcio.pem", OIDCIssuer: "https://oauth2.sigstore.dev/auth", SubjectEmail: "user@example.com", RekorPublicKeyFilename: "rekor.pub"},
+		},
+		{
+			name:          "public key and fulcio",
+			options:       VerifyImageSignatureOptions{ExpectedIdentity: "registry.io/foo/bar:latest", SigstorePublicKeyFilename: "cosign.pub", FulcioCAFilename: "fulcio.pem"},
+			expectedError: "cannot be used together",
+		},
+		{
+			name:          "fulcio without subject",
+			options:       VerifyImageSignatureOptions{ExpectedIdentity: "registry.io/foo/bar:latest", FulcioCAFilename: "fulcio.pem", OIDCIssuer: "https://oauth2.sigstore.dev/auth", RekorPublicKeyFilename: "rekor.pub"},
+			expectedError: "are required with --fulcio-ca",
+		},
+		{
+			name:          "fulcio without rekor",
+			options:       VerifyImageSignatureOptions{ExpectedIdentity: "registry.io/foo/bar:latest", FulcioCAFilename: "fulcio.pem", OIDCIssuer: "https://oauth2.sigstore.dev/auth", SubjectEmail: "user@example.com"},
+			expectedError: "the --rekor-public-key is required with --fulcio-ca",
+		},
+		{
+			name:          "subject without fulcio",
+			options:       VerifyImageSignatureOptions{ExpectedIdentity: "registry.io/foo/bar:latest", SigstorePublicKeyFilename: "cosign.pub", SubjectEmail: "user@example.com"},
+			expectedError: "can only be used with --fulcio-ca",
+		},
+		{
+			name:          "bundle without sigstore",
+			options:       VerifyImageSignatureOptions{ExpectedIdentity: "registry.io/foo/bar:latest", PublicKeyFilename: "key.gpg", BundleFilename: "bundle.json"},
+			expectedError: "can only be used with --sigstore-public-key or --fulcio-ca",
+		},
+		{
+			name:          "bundle without rekor",
+			options:       VerifyImageSignatureOptions{ExpectedIdentity: "registry.io/foo/bar:latest", SigstorePublicKeyFilename: "cosign.pub", BundleFilename: "bundle.json"},
+			expectedError: "the --rekor-public-key is required with --bundle",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.options.Validate()
+			if len(test.expectedError) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+				t.Fatalf("expected error %q, got %v", test.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestReadBundle(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name          string
+		content       string
+		expectedError string
+	}{
+		{
+			name:    "valid",
+			content: `{"base64Signature":"c2lnbmF0dXJl","cert":"","rekorBundle":{"SignedEntryTimestamp":"c2V0"}}`,
+		},
+		{
+			name:          "not json",
+			content:       `signature`,
+			expectedError: "is not a cosign bundle",
+		},
+		{
+			name:          "missing rekor bundle",
+			content:       `{"base64Signature":"c2lnbmF0dXJl"}`,
+			expectedError: "base64Signature and rekorBundle are required",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filename := filepath.Join(dir, strings.ReplaceAll(test.name, " ", "-"))
+			if err := os.WriteFile(filename, []byte(test.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := readBundle(filename)
+			if len(test.expectedError) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+				t.Fatalf("expected error %q, got %v", test.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestApplyBundle(t *testing.T) {
+	bundle := &cosignBundle{
+		Base64Signature: "c2lnbmF0dXJl",
+		Cert:            "certificate",
+		RekorBundle:     json.RawMessage(`{"SignedEntryTimestamp":"c2V0"}`),
+	}
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		applied     bool
+		expected    map[string]string
+	}{
+		{
+			name:        "matching signature",
+			annotations: map[string]string{sigstoreSignatureAnnotation: "c2lnbmF0dXJl"},
+			applied:     true,
+			expected: map[string]string{
+				sigstoreSignatureAnnotation:   "c2lnbmF0dXJl",
+				sigstoreBundleAnnotation:      `{"SignedEntryTimestamp":"c2V0"}`,
+				sigstoreCertificateAnnotation: "certificate",
+			},
+		},
+		{
+			name:        "signature with its own rekor entry",
+			annotations: map[string]string{sigstoreSignatureAnnotation: "c2lnbmF0dXJl", sigstoreBundleAnnotation: "own"},
+			applied:     true,
+			expected: map[string]string{
+				sigstoreSignatureAnnotation:   "c2lnbmF0dXJl",
+				sigstoreBundleAnnotation:      "own",
+				sigstoreCertificateAnnotation: "certificate",
+			},
+		},
+		{
+			name:        "tampered signature",
+			annotations: map[string]string{sigstoreSignatureAnnotation: "c2lnbmF0dXJm"},
+			expected:    map[string]string{sigstoreSignatureAnnotation: "c2lnbmF0dXJm"},
+		},
+		{
+			name:        "unsigned layer",
+			annotations: map[string]string{},
+			expected:    map[string]string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			original := map[string]string{}
+			for k, v := range test.annotations {
+				original[k] = v
+			}
+			signatures := []sigstoreSignature{{digest: "sha256:1", annotations: test.annotations}}
+			if applied := applyBundle(signatures, bundle); applied != test.applied {
+				t.Fatalf("expected applied %t, got %t", test.applied, applied)
+			}
+			if !reflect.DeepEqual(signatures[0].annotations, test.expected) {
+				t.Errorf("unexpected annotations: %v", signatures[0].annotations)
+			}
+			if !reflect.DeepEqual(test.annotations, original) {
+				t.Errorf("the annotations of the layer were modified: %v", test.annotations)
+			}
+		})
+	}
+}
+
+func TestSigstoreSignatureBlob(t *testing.T) {
+	s := sigstoreSignature{
+		digest:      "sha256:1",
+		payload:     []byte(`{"critical":{}}`),
+		annotations: map[string]string{sigstoreSignatureAnnotation: "c2lnbmF0dXJl"},
+	}
+	blob, err := s.blob()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix := []byte("\x00sigstore-json\n")
+	if !bytes.HasPrefix(blob, prefix) {
+		t.Fatalf("unexpected blob format: %q", blob)
+	}
+	var decoded struct {
+		MIMEType    string            `json:"mimeType"`
+		Payload     []byte            `json:"payload"`
+		Annotations map[string]string `json:"annotations"`
+	}
+	if err := json.Unmarshal(bytes.TrimPrefix(blob, prefix), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.MIMEType != sigstoreSignatureMIMEType || !bytes.Equal(decoded.Payload, s.payload) || !reflect.DeepEqual(decoded.Annotations, s.annotations) {
+		t.Errorf("unexpected signature: %#v", decoded)
+	}
+}
+
+func TestSigstoreVerification(t *testing.T) {
+	img := &imagev1.Image{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"other": "value"}}}
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+	setSigstoreVerification(img, "alice", "registry.io/foo/bar:latest", "SHA256:abc", true, now)
+	expected := map[string]string{
+		"other":                      "value",
+		sigstoreVerifiedAnnotation:   "true",
+		sigstoreVerifiedByAnnotation: "alice",
+		sigstoreVerifiedAtAnnotation: "2024-01-02T02:04:05Z",
+		sigstoreIdentityAnnotation:   "registry.io/foo/bar:latest",
+		sigstoreSignerAnnotation:     "SHA256:abc",
+		sigstoreRekorAnnotation:      "true",
+	}
+	if !reflect.DeepEqual(img.Annotations, expected) {
+		t.Errorf("unexpected annotations: %v", img.Annotations)
+	}
+
+	clearSigstoreVerification(img)
+	if !reflect.DeepEqual(img.Annotations, map[string]string{"other": "value"}) {
+		t.Errorf("unexpected annotations: %v", img.Annotations)
+	}
+}
+
+func TestSigstoreSigner(t *testing.T) {
+	o := &VerifyImageSignatureOptions{SigstorePublicKeyFilename: "cosign.pub", SigstorePublicKey: []byte("not a key")}
+	if _, err := o.sigstoreSigner(); err == nil || !strings.Contains(err.Error(), "is not a PEM encoded public key") {
+		t.Errorf("expected an invalid key error, got %v", err)
+	}
+
+	o = &VerifyImageSignatureOptions{FulcioCAFilename: "fulcio.pem", SubjectEmail: "user@example.com"}
+	if signer, err := o.sigstoreSigner(); err != nil || signer != "user@example.com" {
+		t.Errorf("expected the subject to be the signer, got %q, %v", signer, err)
+	}
+}
+
+func TestVerifySigstoreSignature(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"mediaType":"application/vnd.oci.image.config.v1+json","digest":"sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a","size":2},"layers":[]}`)
+	sign := func(identity string) sigstoreSignature {
+		payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":%q},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, identity, digest.FromBytes(manifest)))
+		sum := sha256.Sum256(payload)
+		sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sigstoreSignature{
+			digest:      "sha256:1",
+			payload:     payload,
+			annotations: map[string]string{sigstoreSignatureAnnotation: base64.StdEncoding.EncodeToString(sig)},
+		}
+	}
+
+	o := &VerifyImageSignatureOptions{
+		ExpectedIdentity:          "registry.io/foo/bar:latest",
+		SigstorePublicKeyFilename: "cosign.pub",
+		SigstorePublicKey:         publicKey,
+	}
+	pc, err := o.sigstorePolicyContext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Destroy()
+
+	valid := sign(o.ExpectedIdentity)
+	if err := o.verifySigstoreSignature(pc, manifest, valid); err != nil {
+		t.Errorf("expected the signature to be accepted, got %v", err)
+	}
+
+	tampered := sign(o.ExpectedIdentity)
+	tampered.payload = bytes.Replace(tampered.payload, []byte("foo/bar"), []byte("foo/baz"), 1)
+	if err := o.verifySigstoreSignature(pc, manifest, tampered); err == nil || !strings.Contains(err.Error(), "signature rejected") {
+		t.Errorf("expected the tampered signature to be rejected, got %v", err)
+	}
+
+	other := sign("registry.io/foo/other:latest")
+	if err := o.verifySigstoreSignature(pc, manifest, other); err == nil || !strings.Contains(err.Error(), "signature rejected") {
+		t.Errorf("expected the signature of another identity to be rejected, got %v", err)
+	}
+}
@@ -50,6 +50,16 @@ var (
 	with the public URL of image registry.
 
 	To remove all verifications, users can use the "--remove-all" flag.
+
+	To verify the sigstore signatures pushed next to the image by cosign instead of the
+	atomic signatures of the image, pass the cosign public key with "--sigstore-public-key",
+	or for keyless signatures the Fulcio CA certificates with "--fulcio-ca" together with the
+	"--oidc-issuer" and "--subject-email" the signing certificate must be issued for.
+	Passing the Rekor public key with "--rekor-public-key" additionally requires the signatures
+	to have been recorded in the Rekor transparency log, which is required for keyless signatures.
+	The inclusion is verified offline from the signed entry timestamp cosign attaches to the
+	signature, or from the bundle written by "cosign sign --bundle" passed with "--bundle".
+	With "--save", the result of the verification is saved to the annotations of the image.
 	`)
 
 	verifyImageSignatureExample = templates.Examples(`
@@ -66,6 +76,16 @@ var (
 			--expected-identity=registry.local:5000/foo/bar:v1 \
 			--registry-url=docker-registry.foo.com
 
+	# Verify the cosign signature and identity using a cosign public key and save the status
+	oc adm verify-image-signature sha256:c841e9b64e4579bd56c794bdd7c36e1c257110fd2404bebbb8b613e4935228c4 \
+			--expected-identity=registry.local:5000/foo/bar:v1 --sigstore-public-key=cosign.pub --save
+
+	# Verify a keyless cosign signature and its inclusion in the Rekor transparency log
+	oc adm verify-image-signature sha256:c841e9b64e4579bd56c794bdd7c36e1c257110fd2404bebbb8b613e4935228c4 \
+			--expected-identity=registry.local:5000/foo/bar:v1 \
+			--fulcio-ca=fulcio.pem --oidc-issuer=https://oauth2.sigstore.dev/auth --subject-email=dev@example.com \
+			--rekor-public-key=rekor.pub
+
 	# Remove all signature verifications from the image
 	oc adm verify-image-signature sha256:c841e9b64e4579bd56c794bdd7c36e1c257110fd2404bebbb8b613e4935228c4 --remove-all
 	`)
@@ -83,6 +103,20 @@ type VerifyImageSignatureOptions struct {
 	RegistryURL       string
 	Insecure          bool
 
+	// The trust roots of the sigstore signatures, which are verified instead of the
+	// atomic signatures if either a public key or a Fulcio CA is set.
+	SigstorePublicKeyFilename string
+	SigstorePublicKey         []byte
+	FulcioCAFilename          string
+	FulcioCA                  []byte
+	OIDCIssuer                string
+	SubjectEmail              string
+	RekorPublicKeyFilename    string
+	RekorPublicKey            []byte
+	BundleFilename            string
+
+	bundle *cosignBundle
+
 	ImageClient     imagev1typedclient.ImageV1Interface
 	DiscoveryClient discovery.DiscoveryInterface
 
@@ -119,6 +153,12 @@ func NewCmdVerifyImageSignature(f kcmdutil.Factory, streams genericiooptions.IOS
 	cmd.Flags().StringVar(&o.PublicKeyFilename, "public-key", o.PublicKeyFilename, fmt.Sprintf("A path to a public GPG key to be used for verification. (defaults to %q)", o.PublicKeyFilename))
 	cmd.Flags().StringVar(&o.RegistryURL, "registry-url", o.RegistryURL, "The address to use when contacting the registry, instead of using the internal cluster address. This is useful if you can't resolve or reach the internal registry address.")
 	cmd.Flags().BoolVar(&o.Insecure, "insecure", o.Insecure, "If set, use the insecure protocol for registry communication.")
+	cmd.Flags().StringVar(&o.SigstorePublicKeyFilename, "sigstore-public-key", o.SigstorePublicKeyFilename, "A path to a cosign public key to verify the sigstore signatures of the image with, instead of its atomic signatures.")
+	cmd.Flags().StringVar(&o.FulcioCAFilename, "fulcio-ca", o.FulcioCAFilename, "A path to the Fulcio CA certificates to verify keyless sigstore signatures with, instead of the atomic signatures of the image. Requires --oidc-issuer, --subject-email and --rekor-public-key.")
+	cmd.Flags().StringVar(&o.OIDCIssuer, "oidc-issuer", o.OIDCIssuer, "The OIDC issuer the Fulcio certificate of keyless signatures must have been issued by.")
+	cmd.Flags().StringVar(&o.SubjectEmail, "subject-email", o.SubjectEmail, "The email the Fulcio certificate of keyless signatures must have been issued to.")
+	cmd.Flags().StringVar(&o.RekorPublicKeyFilename, "rekor-public-key", o.RekorPublicKeyFilename, "A path to the Rekor public key. If set, sigstore signatures must have been recorded in the Rekor transparency log.")
+	cmd.Flags().StringVar(&o.BundleFilename, "bundle", o.BundleFilename, "A path to a bundle written by 'cosign sign --bundle' to verify the inclusion of the signature in the Rekor transparency log offline, if the signature does not carry it.")
 	return cmd
 }
 
@@ -134,6 +174,25 @@ func (o *VerifyImageSignatureOptions) Validate() error {
 	if o.RemoveAll && len(o.ExpectedIdentity) > 0 {
 		return errors.New("the --expected-identity cannot be used when removing all verifications")
 	}
+	if len(o.SigstorePublicKeyFilename) > 0 && len(o.FulcioCAFilename) > 0 {
+		return errors.New("the --sigstore-public-key and --fulcio-ca cannot be used together")
+	}
+	if len(o.FulcioCAFilename) > 0 {
+		if len(o.OIDCIssuer) == 0 || len(o.SubjectEmail) == 0 {
+			return errors.New("the --oidc-issuer and --subject-email are required with --fulcio-ca")
+		}
+		if len(o.RekorPublicKeyFilename) == 0 {
+			return errors.New("the --rekor-public-key is required with --fulcio-ca")
+		}
+	} else if len(o.OIDCIssuer) > 0 || len(o.SubjectEmail) > 0 {
+		return errors.New("the --oidc-issuer and --subject-email can only be used with --fulcio-ca")
+	}
+	if !o.isSigstore() && (len(o.RekorPublicKeyFilename) > 0 || len(o.BundleFilename) > 0) {
+		return errors.New("the --rekor-public-key and --bundle can only be used with --sigstore-public-key or --fulcio-ca")
+	}
+	if len(o.BundleFilename) > 0 && len(o.RekorPublicKeyFilename) == 0 {
+		return errors.New("the --rekor-public-key is required with --bundle")
+	}
 	return nil
 }
 func (o *VerifyImageSignatureOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
@@ -143,7 +202,11 @@ func (o *VerifyImageSignatureOptions) Complete(f kcmdutil.Factory, cmd *cobra.Co
 	o.InputImage = args[0]
 	var err error
 
-	if len(o.PublicKeyFilename) > 0 {
+	if o.isSigstore() {
+		if err := o.completeSigstore(); err != nil {
+			return err
+		}
+	} else if len(o.PublicKeyFilename) > 0 {
 		if o.PublicKey, err = os.ReadFile(o.PublicKeyFilename); err != nil {
 			return fmt.Errorf("unable to read --public-key: %v", err)
 		}
@@ -186,11 +249,40 @@ func (o *VerifyImageSignatureOptions) Complete(f kcmdutil.Factory, cmd *cobra.Co
 	return nil
 }
 
+// completeSigstore reads the trust roots of the sigstore signatures.
+func (o *VerifyImageSignatureOptions) completeSigstore() error {
+	var err error
+	for _, f := range []struct {
+		flag, filename string
+		data           *[]byte
+	}{
+		{"--sigstore-public-key", o.SigstorePublicKeyFilename, &o.SigstorePublicKey},
+		{"--fulcio-ca", o.FulcioCAFilename, &o.FulcioCA},
+		{"--rekor-public-key", o.RekorPublicKeyFilename, &o.RekorPublicKey},
+	} {
+		if len(f.filename) == 0 {
+			continue
+		}
+		if *f.data, err = os.ReadFile(f.filename); err != nil {
+			return fmt.Errorf("unable to read %s: %v", f.flag, err)
+		}
+	}
+	if len(o.BundleFilename) > 0 {
+		if o.bundle, err = readBundle(o.BundleFilename); err != nil {
+			return fmt.Errorf("unable to read --bundle: %v", err)
+		}
+	}
+	return nil
+}
+
 func (o VerifyImageSignatureOptions) Run() error {
 	img, err := o.ImageClient.Images().Get(context.TODO(), o.InputImage, metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
+	if o.isSigstore() && !o.RemoveAll {
+		return o.runSigstore(img)
+	}
 	if len(img.Signatures) == 0 {
 		return fmt.Errorf("%s does not have any signature", img.Name)
 	}
@@ -208,6 +300,7 @@ func (o VerifyImageSignatureOptions) Run() error {
 
 	if o.RemoveAll {
 		img.Signatures = []imagev1.ImageSignature{}
+		clearSigstoreVerification(img)
 	}
 
 	for i, s := range img.Signatures {
@@ -255,10 +348,22 @@ func (o VerifyImageSignatureOptions) Run() error {
 
 // getImageManifest fetches the manifest for provided image from the integrated registry.
 func (o *VerifyImageSignatureOptions) getImageManifest(img *imagev1.Image) ([]byte, error) {
-	parsed, err := imageref.Parse(img.DockerImageReference)
+	registryURL, repositoryName, err := o.registryRepository(img)
 	if err != nil {
 		return nil, err
 	}
+	// when using in-cluster auth, the username is just user + token, compare
+	// https://github.com/openshift/oc/blob/9f54c1d4f68c8530ac9466c655a4e55eb04a1459/pkg/cli/registry/login/login.go#L208
+	return getImageManifestByIDFromRegistry(registryURL, repositoryName, img.Name, "user", o.CurrentUserToken, o.Insecure)
+}
+
+// registryRepository returns the address of the registry and the repository to fetch the
+// image from.
+func (o *VerifyImageSignatureOptions) registryRepository(img *imagev1.Image) (*url.URL, string, error) {
+	parsed, err := imageref.Parse(img.DockerImageReference)
+	if err != nil {
+		return nil, "", err
+	}
 	// TODO(juanvallejo): Add missing methods to DockerImageReference object in library-go helper
 	registryURL := parsed.RegistryURL()
 	if len(o.RegistryURL) > 0 {
@@ -267,9 +372,7 @@ func (o *VerifyImageSignatureOptions) getImageManifest(img *imagev1.Image) ([]by
 			registryURL.Scheme = ""
 		}
 	}
-	// when using in-cluster auth, the username is just user + token, compare
-	// https://github.com/openshift/oc/blob/9f54c1d4f68c8530ac9466c655a4e55eb04a1459/pkg/cli/registry/login/login.go#L208
-	return getImageManifestByIDFromRegistry(registryURL, parsed.RepositoryName(), img.Name, "user", o.CurrentUserToken, o.Insecure)
+	return registryURL, parsed.RepositoryName(), nil
 }
 
 // verifySignature takes policy, image and the image signature blob and verifies that the
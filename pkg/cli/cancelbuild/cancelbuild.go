@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"sync"
 	"time"
@@ -40,6 +41,12 @@ var (
 
 		This command requests a graceful shutdown of the build. There may be a delay between requesting
 		the build and the time the build is terminated.
+
+		Builds may also be selected by label with --selector, for instance to clean up the builds
+		queued while a registry was unavailable. Builds have the labels of the build config they are
+		created from, so a selector matching build configs selects all of their builds. Only the
+		builds in one of the states of --state are cancelled, and a summary of the cancelled builds is
+		printed when several builds are selected.
 	`)
 
 	cancelBuildExample = templates.Examples(`
@@ -57,6 +64,9 @@ var (
 
 		# Cancel all builds created from the 'ruby-build' build config that are in the 'new' state
 		oc cancel-build bc/ruby-build --state=new
+
+		# Cancel all the new and pending builds of the build configs labeled 'app=ruby'
+		oc cancel-build -l app=ruby --state=new,pending
 	`)
 )
 
@@ -65,6 +75,7 @@ type CancelBuildOptions struct {
 	DumpLogs   bool
 	Restart    bool
 	States     []string
+	Selector   string
 	Namespace  string
 	BuildNames []string
 
@@ -97,7 +108,7 @@ func NewCmdCancelBuild(f kcmdutil.Factory, streams genericiooptions.IOStreams) *
 	validArgs := []string{"build", "buildconfig"}
 
 	cmd := &cobra.Command{
-		Use:               "cancel-build (BUILD | BUILDCONFIG)",
+		Use:               "cancel-build (BUILD | BUILDCONFIG | -l SELECTOR)",
 		Short:             "Cancel running, pending, or new builds",
 		Long:              cancelBuildLong,
 		Example:           cancelBuildExample,
@@ -111,6 +122,7 @@ func NewCmdCancelBuild(f kcmdutil.Factory, streams genericiooptions.IOStreams) *
 	}
 
 	cmd.Flags().StringSliceVar(&o.States, "state", o.States, "Only cancel builds in this state")
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) to filter builds on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
 	cmd.Flags().BoolVar(&o.DumpLogs, "dump-logs", o.DumpLogs, "Specify if the build logs for the cancelled build should be shown.")
 	cmd.Flags().BoolVar(&o.Restart, "restart", o.Restart, "Specify if a new build should be created after the current build is cancelled.")
 
@@ -119,8 +131,11 @@ func NewCmdCancelBuild(f kcmdutil.Factory, streams genericiooptions.IOStreams) *
 
 // Complete completes all the required options.
 func (o *CancelBuildOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("build or a buildconfig name is required")
+	switch {
+	case len(args) == 0 && len(o.Selector) == 0:
+		return fmt.Errorf("build or a buildconfig name, or a selector, is required")
+	case len(args) > 0 && len(o.Selector) > 0:
+		return fmt.Errorf("a selector may not be specified along with build or buildconfig names")
 	}
 
 	o.ReportError = func(err error) {
@@ -170,6 +185,19 @@ func (o *CancelBuildOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, ar
 		return err
 	}
 
+	if len(o.Selector) > 0 {
+		if _, err := labels.Parse(o.Selector); err != nil {
+			return fmt.Errorf("invalid selector %q: %v", o.Selector, err)
+		}
+		list, err := o.BuildClient.List(context.TODO(), metav1.ListOptions{LabelSelector: o.Selector})
+		if err != nil {
+			return err
+		}
+		for _, b := range list.Items {
+			o.BuildNames = append(o.BuildNames, b.Name)
+		}
+	}
+
 	for _, item := range args {
 		resource, name, err := cmdutil.ResolveResource(build.Resource("builds"), item, o.Mapper)
 		if err != nil {
@@ -288,11 +316,13 @@ func (o *CancelBuildOptions) RunCancelBuild() error {
 		}
 	}
 
+	summary := &cancelSummary{cancelled: map[buildv1.BuildPhase]int{}}
 	var wg sync.WaitGroup
 	for _, b := range builds {
 		wg.Add(1)
 		go func(build *buildv1.Build) {
 			defer wg.Done()
+			phase := build.Status.Phase
 			err := wait.PollUntilContextTimeout(context.TODO(), 500*time.Millisecond, o.timeout, false, func(ctx context.Context) (bool, error) {
 				build.Status.Cancelled = true
 				_, err := o.BuildClient.Update(context.TODO(), build, metav1.UpdateOptions{})
@@ -306,6 +336,7 @@ func (o *CancelBuildOptions) RunCancelBuild() error {
 				return true, err
 			})
 			if err != nil {
+				summary.fail()
 				o.ReportError(fmt.Errorf("build %s/%s failed to update: %v", build.Namespace, build.Name, err))
 				return
 			}
@@ -329,9 +360,11 @@ func (o *CancelBuildOptions) RunCancelBuild() error {
 				return updatedBuild.Status.Phase == buildv1.BuildPhaseCancelled, nil
 			})
 			if err != nil {
+				summary.fail()
 				o.ReportError(fmt.Errorf("build %s/%s failed to cancel: %v", build.Namespace, build.Name, err))
 				return
 			}
+			summary.cancel(phase)
 
 			if err := o.PrinterCancel.PrintObj(build, o.Out); err != nil {
 				o.ReportError(fmt.Errorf("build %s/%s failed to print: %v", build.Namespace, build.Name, err))
@@ -356,6 +389,10 @@ func (o *CancelBuildOptions) RunCancelBuild() error {
 		}
 	}
 
+	if len(o.Selector) > 0 || len(o.BuildNames) > 1 {
+		summary.print(o.Out, o.States)
+	}
+
 	if o.HasError {
 		return errors.New("failure during the build cancellation")
 	}
@@ -363,6 +400,52 @@ func (o *CancelBuildOptions) RunCancelBuild() error {
 	return nil
 }
 
+// cancelSummary counts the builds cancelled by the state they were in, and the builds that failed
+// to be cancelled.
+type cancelSummary struct {
+	lock      sync.Mutex
+	cancelled map[buildv1.BuildPhase]int
+	failed    int
+}
+
+func (s *cancelSummary) cancel(phase buildv1.BuildPhase) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.cancelled[phase]++
+}
+
+func (s *cancelSummary) fail() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.failed++
+}
+
+func (s *cancelSummary) print(out io.Writer, states []string) {
+	total := 0
+	counts := []string{}
+	for _, phase := range []buildv1.BuildPhase{buildv1.BuildPhaseNew, buildv1.BuildPhasePending, buildv1.BuildPhaseRunning} {
+		if count := s.cancelled[phase]; count > 0 {
+			total += count
+			counts = append(counts, fmt.Sprintf("%d %s", count, strings.ToLower(string(phase))))
+		}
+	}
+	switch {
+	case total == 0 && s.failed == 0:
+		fmt.Fprintf(out, "No builds in the %s state were found\n", strings.Join(states, ", "))
+		return
+	case total == 0:
+		fmt.Fprintf(out, "No builds were cancelled")
+	case total == 1:
+		fmt.Fprintf(out, "1 build cancelled (%s)", strings.Join(counts, ", "))
+	default:
+		fmt.Fprintf(out, "%d builds cancelled (%s)", total, strings.Join(counts, ", "))
+	}
+	if s.failed > 0 {
+		fmt.Fprintf(out, ", %d failed to be cancelled", s.failed)
+	}
+	fmt.Fprintln(out)
+}
+
 // isStateCancellable validates the state provided by the '--state' flag.
 func isStateCancellable(state string) bool {
 	cancellablePhases := []string{
@@ -225,3 +225,43 @@ func genBuild(phase buildv1.BuildPhase) *buildv1.Build {
 type testAction struct {
 	verb, resource string
 }
+
+func TestCancelSummary(t *testing.T) {
+	states := []string{"new", "pending", "running"}
+	tests := map[string]struct {
+		cancelled []buildv1.BuildPhase
+		failed    int
+		expected  string
+	}{
+		"none": {
+			expected: "No builds in the new, pending, running state were found\n",
+		},
+		"cancelled": {
+			cancelled: []buildv1.BuildPhase{buildv1.BuildPhaseRunning, buildv1.BuildPhaseNew, buildv1.BuildPhaseNew},
+			expected:  "3 builds cancelled (2 new, 1 running)\n",
+		},
+		"failed": {
+			cancelled: []buildv1.BuildPhase{buildv1.BuildPhasePending},
+			failed:    2,
+			expected:  "1 build cancelled (1 pending), 2 failed to be cancelled\n",
+		},
+		"all failed": {
+			failed:   1,
+			expected: "No builds were cancelled, 1 failed to be cancelled\n",
+		},
+	}
+	for name, test := range tests {
+		summary := &cancelSummary{cancelled: map[buildv1.BuildPhase]int{}}
+		for _, phase := range test.cancelled {
+			summary.cancel(phase)
+		}
+		for i := 0; i < test.failed; i++ {
+			summary.fail()
+		}
+		out := &strings.Builder{}
+		summary.print(out, states)
+		if out.String() != test.expected {
+			t.Errorf("%s: expected %q, got %q", name, test.expected, out.String())
+		}
+	}
+}
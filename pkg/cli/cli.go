@@ -334,6 +334,16 @@ func NewOcCommand(o kubecmd.KubectlOptions) *cobra.Command {
 
 	registerCompletionFuncForGlobalFlags(cmds, f)
 
+	// the defaults of the user apply to all the commands, so they are set once all are added
+	userDefaultsFile := userDefaultsPath()
+	userDefaults, err := loadUserDefaults(userDefaultsFile)
+	if err != nil {
+		fmt.Fprintf(o.IOStreams.ErrOut, "warning: ignoring %s: %v\n", userDefaultsFile, err)
+	}
+	for _, err := range applyUserDefaults(cmds, userDefaults) {
+		fmt.Fprintf(o.IOStreams.ErrOut, "warning: %s: %v\n", userDefaultsFile, err)
+	}
+
 	return cmds
 }
 
@@ -0,0 +1,204 @@
+package profiles
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/tools/clientcmd"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/oc/pkg/helpers/profiles"
+)
+
+var (
+	profilesLong = templates.LongDesc(i18n.T(`
+		Manage the login profiles.
+
+		A profile is a named bundle of the server, user and namespace to log in to, with the
+		certificate authority and proxy to connect to the server with. Profiles are stored in
+		~/.config/oc/profiles.yaml, separately from the kubeconfig, and do not hold credentials.
+		Log in with a profile with 'oc login --profile=NAME', which also switches to the cluster
+		of the profile when already logged in.`))
+
+	profilesExample = templates.Examples(`
+		# Add a profile for a cluster reached through a proxy
+		oc config profiles set prod --server=https://api.prod.example.com:6443 --username=sre \
+		  --namespace=openshift-monitoring --certificate-authority=prod-ca.crt --proxy-url=http://proxy.example.com:3128
+
+		# List the profiles
+		oc config profiles list
+
+		# Log in with a profile
+		oc login --profile=prod
+
+		# Delete a profile
+		oc config profiles delete prod`)
+)
+
+// NewCmdProfiles implements the profiles command and its subcommands.
+func NewCmdProfiles(streams genericiooptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "profiles",
+		Short:   i18n.T("Manage the profiles used by 'oc login --profile'"),
+		Long:    profilesLong,
+		Example: profilesExample,
+		Run:     kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+	cmd.AddCommand(newCmdListProfiles(streams))
+	cmd.AddCommand(newCmdSetProfile(streams))
+	cmd.AddCommand(newCmdDeleteProfile(streams))
+	return cmd
+}
+
+func newCmdListProfiles(streams genericiooptions.IOStreams) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: i18n.T("List the profiles"),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(listProfiles(profiles.DefaultPath(), streams))
+		},
+	}
+}
+
+func listProfiles(path string, streams genericiooptions.IOStreams) error {
+	p, err := profiles.Load(path)
+	if err != nil {
+		return err
+	}
+	if len(p.Profiles) == 0 {
+		fmt.Fprintf(streams.ErrOut, "No profiles found in %s.\n", path)
+		return nil
+	}
+	w := printers.GetNewTabWriter(streams.Out)
+	fmt.Fprintln(w, "NAME\tSERVER\tUSERNAME\tNAMESPACE\tPROXY")
+	for _, profile := range p.Profiles {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", profile.Name, profile.Server, valueOrNone(profile.Username), valueOrNone(profile.Namespace), valueOrNone(profile.ProxyURL))
+	}
+	return w.Flush()
+}
+
+// SetProfileOptions adds or updates a profile.
+type SetProfileOptions struct {
+	Path    string
+	Profile profiles.Profile
+
+	genericiooptions.IOStreams
+}
+
+func newCmdSetProfile(streams genericiooptions.IOStreams) *cobra.Command {
+	o := &SetProfileOptions{IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:                   "set NAME [--server=server] [--username=user] [--namespace=namespace] [--certificate-authority=path/to/certificate/authority] [--proxy-url=url]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Add a profile, or update the settings of an existing profile"),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(cmd, args))
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	cmd.Flags().StringVar(&o.Profile.Server, clientcmd.FlagAPIServer, "", "The URL of the API server of the profile.")
+	cmd.Flags().StringVar(&o.Profile.Username, "username", "", "The user to log in as.")
+	cmd.Flags().StringVarP(&o.Profile.Namespace, clientcmd.FlagNamespace, "n", "", "The project to use after logging in.")
+	cmd.Flags().StringVar(&o.Profile.CertificateAuthority, clientcmd.FlagCAFile, "", "Path to the certificate authority bundle trusted to connect to the server.")
+	cmd.Flags().BoolVar(&o.Profile.InsecureSkipTLSVerify, clientcmd.FlagInsecure, false, "Connect to the server without verifying its certificate.")
+	cmd.Flags().StringVar(&o.Profile.ProxyURL, clientcmd.FlagProxyURL, "", "The URL of the proxy to connect to the server through.")
+	return cmd
+}
+
+func (o *SetProfileOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return kcmdutil.UsageErrorf(cmd, "a profile name is required")
+	}
+	o.Path = profiles.DefaultPath()
+
+	p, err := profiles.Load(o.Path)
+	if err != nil {
+		return err
+	}
+	profile := profiles.Profile{Name: args[0]}
+	if existing, err := p.Get(args[0]); err == nil {
+		profile = *existing
+	}
+	// only the flags that are set change the existing profile
+	flags := cmd.Flags()
+	if flags.Changed(clientcmd.FlagAPIServer) {
+		profile.Server = o.Profile.Server
+	}
+	if flags.Changed("username") {
+		profile.Username = o.Profile.Username
+	}
+	if flags.Changed(clientcmd.FlagNamespace) {
+		profile.Namespace = o.Profile.Namespace
+	}
+	if flags.Changed(clientcmd.FlagCAFile) {
+		profile.CertificateAuthority = o.Profile.CertificateAuthority
+		if len(profile.CertificateAuthority) > 0 {
+			// the profile is used from any directory
+			if profile.CertificateAuthority, err = filepath.Abs(profile.CertificateAuthority); err != nil {
+				return err
+			}
+		}
+	}
+	if flags.Changed(clientcmd.FlagInsecure) {
+		profile.InsecureSkipTLSVerify = o.Profile.InsecureSkipTLSVerify
+	}
+	if flags.Changed(clientcmd.FlagProxyURL) {
+		profile.ProxyURL = o.Profile.ProxyURL
+	}
+	o.Profile = profile
+	return o.Profile.Validate()
+}
+
+func (o *SetProfileOptions) Run() error {
+	p, err := profiles.Load(o.Path)
+	if err != nil {
+		return err
+	}
+	p.Set(o.Profile)
+	if err := p.Save(o.Path); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "Profile %q set.\n", o.Profile.Name)
+	return nil
+}
+
+func newCmdDeleteProfile(streams genericiooptions.IOStreams) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete NAME",
+		Short: i18n.T("Delete a profile"),
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				kcmdutil.CheckErr(kcmdutil.UsageErrorf(cmd, "a profile name is required"))
+			}
+			kcmdutil.CheckErr(deleteProfile(profiles.DefaultPath(), args[0], streams))
+		},
+	}
+}
+
+func deleteProfile(path, name string, streams genericiooptions.IOStreams) error {
+	p, err := profiles.Load(path)
+	if err != nil {
+		return err
+	}
+	if !p.Delete(name) {
+		return fmt.Errorf("the profile %q does not exist", name)
+	}
+	if err := p.Save(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(streams.Out, "Profile %q deleted.\n", name)
+	return nil
+}
+
+func valueOrNone(s string) string {
+	if len(s) == 0 {
+		return "<none>"
+	}
+	return s
+}
@@ -25,7 +25,9 @@ var (
 		Create a route that uses edge TLS termination.
 
 		Specify the service (either just its name or using type/name syntax) that the
-		generated route should expose via the --service flag.
+		generated route should expose via the --service flag. The certificate served
+		by the router may be given with --cert and --key, or read from a TLS secret
+		with --cert-secret so that updating the secret rotates the certificate.
 	`)
 
 	edgeRouteExample = templates.Examples(`
@@ -35,6 +37,9 @@ var (
 		# Create an edge route that exposes the frontend service and specify a path
 		# If the route name is omitted, the service name will be used
 		oc create route edge --service=frontend --path /assets
+
+		# Create an edge route serving the certificate of the TLS secret "frontend-tls", redirecting HTTP and enabling HSTS
+		oc create route edge --service=frontend --cert-secret=frontend-tls --insecure-policy=Redirect --hsts="max-age=31536000;includeSubDomains"
 	`)
 )
 
@@ -50,6 +55,8 @@ type CreateEdgeRouteOptions struct {
 	Key            string
 	CACert         string
 	WildcardPolicy string
+	HSTS           string
+	CertSecret     string
 }
 
 // NewCmdCreateEdgeRoute is a macro command to create an edge route.
@@ -64,13 +71,14 @@ func NewCmdCreateEdgeRoute(f kcmdutil.Factory, streams genericiooptions.IOStream
 		Example: edgeRouteExample,
 		Run: func(cmd *cobra.Command, args []string) {
 			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
 			kcmdutil.CheckErr(o.Run())
 		},
 	}
 
 	cmd.Flags().StringVar(&o.Hostname, "hostname", o.Hostname, "Set a hostname for the new route")
 	cmd.Flags().StringVar(&o.Port, "port", o.Port, "Name of the service port or number of the container port the route will route traffic to")
-	cmd.Flags().StringVar(&o.InsecurePolicy, "insecure-policy", o.InsecurePolicy, "Set an insecure policy for the new route")
+	cmd.Flags().StringVar(&o.InsecurePolicy, "insecure-policy", o.InsecurePolicy, "Set an insecure policy for the new route. Valid values are \"None\", \"Allow\" and \"Redirect\"")
 	cmd.Flags().StringVar(&o.Service, "service", o.Service, "Name of the service that the new route is exposing")
 	cmd.MarkFlagRequired("service")
 	cmd.Flags().StringVar(&o.Path, "path", o.Path, "Path that the router watches to route traffic to the service.")
@@ -82,6 +90,9 @@ func NewCmdCreateEdgeRoute(f kcmdutil.Factory, streams genericiooptions.IOStream
 	cmd.MarkFlagFilename("ca-cert")
 	cmd.Flags().StringVar(&o.WildcardPolicy, "wildcard-policy", o.WildcardPolicy, "Sets the WilcardPolicy for the hostname, the default is \"None\". valid values are \"None\" and \"Subdomain\"")
 
+	cmd.Flags().StringVar(&o.HSTS, "hsts", o.HSTS, "Strict-Transport-Security header the router sends for the new route, e.g. \"max-age=31536000;includeSubDomains;preload\"")
+	cmd.Flags().StringVar(&o.CertSecret, "cert-secret", o.CertSecret, "Name of a TLS secret in the namespace of the route to serve the certificate of, instead of --cert and --key. Updating the secret rotates the certificate. The router must be allowed to read the secret.")
+
 	kcmdutil.AddValidateFlags(cmd)
 	o.CreateRouteSubcommandOptions.AddFlags(cmd)
 	kcmdutil.AddDryRunFlag(cmd)
@@ -93,6 +104,16 @@ func (o *CreateEdgeRouteOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command
 	return o.CreateRouteSubcommandOptions.Complete(f, cmd, args)
 }
 
+func (o *CreateEdgeRouteOptions) Validate() error {
+	if len(o.CertSecret) > 0 && (len(o.Cert) > 0 || len(o.Key) > 0) {
+		return fmt.Errorf("--cert-secret cannot be used with --cert or --key")
+	}
+	if err := validateHSTS(o.HSTS); err != nil {
+		return err
+	}
+	return validateInsecurePolicy(o.InsecurePolicy)
+}
+
 func (o *CreateEdgeRouteOptions) Run() error {
 	serviceName, err := resolveServiceName(o.CreateRouteSubcommandOptions.Mapper, o.Service)
 	if err != nil {
@@ -127,10 +148,12 @@ func (o *CreateEdgeRouteOptions) Run() error {
 		return err
 	}
 	route.Spec.TLS.CACertificate = string(caCert)
+	setExternalCertificate(route.Spec.TLS, o.CertSecret)
 
 	if len(o.InsecurePolicy) > 0 {
 		route.Spec.TLS.InsecureEdgeTerminationPolicy = routev1.InsecureEdgeTerminationPolicyType(o.InsecurePolicy)
 	}
+	setHSTS(route, o.HSTS)
 
 	if err := util.CreateOrUpdateAnnotation(o.CreateRouteSubcommandOptions.CreateAnnotation, route, createCmdJSONEncoder()); err != nil {
 		return err
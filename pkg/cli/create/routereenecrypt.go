@@ -2,6 +2,7 @@ package create
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/spf13/cobra"
 
@@ -24,7 +25,8 @@ var (
 		generated route should expose using the --service flag. You may also specify
 		a destination CA certificate using the --dest-ca-cert flag. If --dest-ca-cert
 		is omitted, the route will use the service CA, meaning the service must use
-		a serving certificate from the serving cert signer.
+		a serving certificate from the serving cert signer. The destination CA
+		certificates may also be read from a config map with --dest-ca-configmap.
 	`)
 
 	reencryptRouteExample = templates.Examples(`
@@ -35,22 +37,29 @@ var (
 		# route name default to the service name and the destination CA certificate
 		# default to the service CA
 		oc create route reencrypt --service=frontend
+
+		# Create a reencrypt route trusting the CA certificates of the "frontend-ca" config map
+		# for the connection to the service and serving the certificate of the TLS secret "frontend-tls"
+		oc create route reencrypt --service=frontend --dest-ca-configmap=frontend-ca:ca-bundle.crt --cert-secret=frontend-tls
 	`)
 )
 
 type CreateReencryptRouteOptions struct {
 	CreateRouteSubcommandOptions *CreateRouteSubcommandOptions
 
-	Hostname       string
-	Port           string
-	InsecurePolicy string
-	Service        string
-	Path           string
-	Cert           string
-	Key            string
-	CACert         string
-	DestCACert     string
-	WildcardPolicy string
+	Hostname        string
+	Port            string
+	InsecurePolicy  string
+	Service         string
+	Path            string
+	Cert            string
+	Key             string
+	CACert          string
+	DestCACert      string
+	WildcardPolicy  string
+	HSTS            string
+	CertSecret      string
+	DestCAConfigMap string
 }
 
 // NewCmdCreateReencryptRoute is a macro command to create a reencrypt route.
@@ -65,13 +74,14 @@ func NewCmdCreateReencryptRoute(f kcmdutil.Factory, streams genericiooptions.IOS
 		Example: reencryptRouteExample,
 		Run: func(cmd *cobra.Command, args []string) {
 			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
 			kcmdutil.CheckErr(o.Run())
 		},
 	}
 
 	cmd.Flags().StringVar(&o.Hostname, "hostname", o.Hostname, "Set a hostname for the new route")
 	cmd.Flags().StringVar(&o.Port, "port", o.Port, "Name of the service port or number of the container port the route will route traffic to")
-	cmd.Flags().StringVar(&o.InsecurePolicy, "insecure-policy", o.InsecurePolicy, "Set an insecure policy for the new route")
+	cmd.Flags().StringVar(&o.InsecurePolicy, "insecure-policy", o.InsecurePolicy, "Set an insecure policy for the new route. Valid values are \"None\", \"Allow\" and \"Redirect\"")
 	cmd.Flags().StringVar(&o.Service, "service", o.Service, "Name of the service that the new route is exposing")
 	cmd.MarkFlagRequired("service")
 	cmd.Flags().StringVar(&o.Path, "path", o.Path, "Path that the router watches to route traffic to the service.")
@@ -85,6 +95,10 @@ func NewCmdCreateReencryptRoute(f kcmdutil.Factory, streams genericiooptions.IOS
 	cmd.MarkFlagFilename("dest-ca-cert")
 	cmd.Flags().StringVar(&o.WildcardPolicy, "wildcard-policy", o.WildcardPolicy, "Sets the WilcardPolicy for the hostname, the default is \"None\". valid values are \"None\" and \"Subdomain\"")
 
+	cmd.Flags().StringVar(&o.HSTS, "hsts", o.HSTS, "Strict-Transport-Security header the router sends for the new route, e.g. \"max-age=31536000;includeSubDomains;preload\"")
+	cmd.Flags().StringVar(&o.CertSecret, "cert-secret", o.CertSecret, "Name of a TLS secret in the namespace of the route to serve the certificate of, instead of --cert and --key. Updating the secret rotates the certificate. The router must be allowed to read the secret.")
+	cmd.Flags().StringVar(&o.DestCAConfigMap, "dest-ca-configmap", o.DestCAConfigMap, "Config map, as NAME or NAME:KEY, holding the CA certificates used for securing the connection from the router to the destination, instead of --dest-ca-cert.")
+
 	kcmdutil.AddValidateFlags(cmd)
 	o.CreateRouteSubcommandOptions.AddFlags(cmd)
 	kcmdutil.AddDryRunFlag(cmd)
@@ -96,6 +110,19 @@ func (o *CreateReencryptRouteOptions) Complete(f kcmdutil.Factory, cmd *cobra.Co
 	return o.CreateRouteSubcommandOptions.Complete(f, cmd, args)
 }
 
+func (o *CreateReencryptRouteOptions) Validate() error {
+	if len(o.CertSecret) > 0 && (len(o.Cert) > 0 || len(o.Key) > 0) {
+		return fmt.Errorf("--cert-secret cannot be used with --cert or --key")
+	}
+	if len(o.DestCAConfigMap) > 0 && len(o.DestCACert) > 0 {
+		return fmt.Errorf("--dest-ca-configmap cannot be used with --dest-ca-cert")
+	}
+	if err := validateHSTS(o.HSTS); err != nil {
+		return err
+	}
+	return validateInsecurePolicy(o.InsecurePolicy)
+}
+
 func (o *CreateReencryptRouteOptions) Run() error {
 	serviceName, err := resolveServiceName(o.CreateRouteSubcommandOptions.Mapper, o.Service)
 	if err != nil {
@@ -137,15 +164,23 @@ func (o *CreateReencryptRouteOptions) Run() error {
 		return err
 	}
 	route.Spec.TLS.CACertificate = string(caCert)
+	setExternalCertificate(route.Spec.TLS, o.CertSecret)
 	destCACert, err := fileutil.LoadData(o.DestCACert)
 	if err != nil {
 		return err
 	}
 	route.Spec.TLS.DestinationCACertificate = string(destCACert)
+	if len(o.DestCAConfigMap) > 0 {
+		route.Spec.TLS.DestinationCACertificate, err = loadConfigMapCA(o.CreateRouteSubcommandOptions.CoreClient, o.CreateRouteSubcommandOptions.Namespace, o.DestCAConfigMap)
+		if err != nil {
+			return err
+		}
+	}
 
 	if len(o.InsecurePolicy) > 0 {
 		route.Spec.TLS.InsecureEdgeTerminationPolicy = routev1.InsecureEdgeTerminationPolicyType(o.InsecurePolicy)
 	}
+	setHSTS(route, o.HSTS)
 
 	if err := util.CreateOrUpdateAnnotation(o.CreateRouteSubcommandOptions.CreateAnnotation, route, createCmdJSONEncoder()); err != nil {
 		return err
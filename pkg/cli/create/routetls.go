@@ -0,0 +1,111 @@
+package create
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+// hstsAnnotation is the annotation the router reads the Strict-Transport-Security
+// header of the responses of a route from.
+const hstsAnnotation = "haproxy.router.openshift.io/hsts_header"
+
+// validateInsecurePolicy ensures the policy for insecure traffic of an edge or
+// reencrypt route is valid.
+func validateInsecurePolicy(policy string) error {
+	switch routev1.InsecureEdgeTerminationPolicyType(policy) {
+	case "", routev1.InsecureEdgeTerminationPolicyNone, routev1.InsecureEdgeTerminationPolicyRedirect, routev1.InsecureEdgeTerminationPolicyAllow:
+		return nil
+	default:
+		return fmt.Errorf("invalid --insecure-policy %q, valid values are None, Allow and Redirect", policy)
+	}
+}
+
+// validateHSTS ensures the value of --hsts is a Strict-Transport-Security header
+// the router accepts, e.g. max-age=31536000;includeSubDomains;preload.
+func validateHSTS(header string) error {
+	if len(header) == 0 {
+		return nil
+	}
+	hasMaxAge := false
+	for _, directive := range strings.Split(header, ";") {
+		directive = strings.TrimSpace(directive)
+		name, value, _ := strings.Cut(directive, "=")
+		switch strings.ToLower(name) {
+		case "max-age":
+			if _, err := strconv.ParseUint(strings.Trim(value, `"`), 10, 64); err != nil {
+				return fmt.Errorf("invalid --hsts max-age %q, it must be a number of seconds", value)
+			}
+			hasMaxAge = true
+		case "includesubdomains", "preload":
+		case "":
+			// tolerate a trailing separator
+		default:
+			return fmt.Errorf("invalid --hsts directive %q, valid directives are max-age, includeSubDomains and preload", directive)
+		}
+	}
+	if !hasMaxAge {
+		return fmt.Errorf("--hsts requires a max-age directive, e.g. max-age=31536000")
+	}
+	return nil
+}
+
+// setHSTS has the router send the Strict-Transport-Security header of the route.
+func setHSTS(route *routev1.Route, header string) {
+	if len(header) == 0 {
+		return
+	}
+	if route.Annotations == nil {
+		route.Annotations = map[string]string{}
+	}
+	route.Annotations[hstsAnnotation] = header
+}
+
+// setExternalCertificate has the router serve the certificate of a TLS secret
+// in the namespace of the route, so that updating the secret rotates the
+// certificate without changing the route.
+func setExternalCertificate(tls *routev1.TLSConfig, secret string) {
+	if len(secret) == 0 {
+		return
+	}
+	tls.ExternalCertificate = &routev1.LocalObjectReference{Name: secret}
+}
+
+// loadConfigMapCA returns the CA certificates of the NAME[:KEY] config map. KEY
+// may be omitted if the config map has a single key, like the config maps the
+// service CA bundle is injected into.
+func loadConfigMapCA(kc corev1client.ConfigMapsGetter, namespace, ref string) (string, error) {
+	name, key, _ := strings.Cut(ref, ":")
+	if len(name) == 0 {
+		return "", fmt.Errorf("invalid --dest-ca-configmap %q, it must be NAME or NAME:KEY", ref)
+	}
+	cm, err := kc.ConfigMaps(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	if len(key) == 0 {
+		if len(cm.Data) != 1 {
+			keys := make([]string, 0, len(cm.Data))
+			for k := range cm.Data {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			return "", fmt.Errorf("config map %s has keys %s, select the CA certificates with --dest-ca-configmap=%s:KEY", name, strings.Join(keys, ", "), name)
+		}
+		for k := range cm.Data {
+			key = k
+		}
+	}
+	ca, ok := cm.Data[key]
+	if !ok || len(strings.TrimSpace(ca)) == 0 {
+		return "", fmt.Errorf("config map %s has no CA certificates in key %q", name, key)
+	}
+	return ca, nil
+}
@@ -0,0 +1,63 @@
+package create
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestValidateHSTS(t *testing.T) {
+	for header, valid := range map[string]bool{
+		"":                                      true,
+		"max-age=31536000":                      true,
+		"max-age=31536000;includeSubDomains;":   true,
+		"max-age=0; preload; includeSubDomains": true,
+		"includeSubDomains":                     false,
+		"max-age=forever":                       false,
+		"max-age=1;always":                      false,
+	} {
+		if err := validateHSTS(header); (err == nil) != valid {
+			t.Errorf("validateHSTS(%q) = %v, want valid %t", header, err, valid)
+		}
+	}
+}
+
+func TestValidateInsecurePolicy(t *testing.T) {
+	if err := validateInsecurePolicy("Allow"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := validateInsecurePolicy("redirect"); err == nil {
+		t.Errorf("expected an error for an invalid policy")
+	}
+}
+
+func TestLoadConfigMapCA(t *testing.T) {
+	kc := fake.NewSimpleClientset(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "service-ca", Namespace: "app"}, Data: map[string]string{"service-ca.crt": "SERVICE CA"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "bundle", Namespace: "app"}, Data: map[string]string{"ca-bundle.crt": "BUNDLE", "other": "x"}},
+	)
+	tests := []struct {
+		ref, ca, err string
+	}{
+		{ref: "service-ca", ca: "SERVICE CA"},
+		{ref: "bundle:ca-bundle.crt", ca: "BUNDLE"},
+		{ref: "bundle", err: "has keys ca-bundle.crt, other"},
+		{ref: "bundle:missing", err: `no CA certificates in key "missing"`},
+		{ref: "unknown", err: "not found"},
+	}
+	for _, tt := range tests {
+		ca, err := loadConfigMapCA(kc.CoreV1(), "app", tt.ref)
+		if len(tt.err) > 0 {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: expected error %q, got %v", tt.ref, tt.err, err)
+			}
+			continue
+		}
+		if err != nil || ca != tt.ca {
+			t.Errorf("%s: got %q, %v, want %q", tt.ref, ca, err, tt.ca)
+		}
+	}
+}
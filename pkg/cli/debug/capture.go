@@ -0,0 +1,310 @@
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/cmd/exec"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/term"
+)
+
+const (
+	captureTranscriptFile = "transcript.txt"
+	captureSessionFile    = "session.json"
+	captureFilesDir       = "files"
+	captureSaveHelper     = "oc-debug-save"
+)
+
+// captureSaveScript is the oc-debug-save helper installed in the debug container,
+// which appends the absolute path of each argument to the list of files collected
+// when the session ends.
+const captureSaveScript = `#!/bin/sh
+if [ $# -eq 0 ]; then
+  echo "usage: oc-debug-save PATH..." >&2
+  exit 1
+fi
+for p in "$@"; do
+  if [ ! -e "$p" ]; then
+    echo "oc-debug-save: $p does not exist" >&2
+    exit 1
+  fi
+  d=$(cd "$(dirname "$p")" && pwd -P) || exit 1
+  echo "$d/$(basename "$p")" >> %s/saved
+  echo "$p will be saved in the capture bundle"
+done
+`
+
+// captureSession is the summary of the debug session stored in the capture bundle.
+type captureSession struct {
+	Pod            string    `json:"pod"`
+	Namespace      string    `json:"namespace"`
+	Node           string    `json:"node,omitempty"`
+	Container      string    `json:"container"`
+	SourceResource string    `json:"sourceResource,omitempty"`
+	Command        []string  `json:"command"`
+	RequestedBy    string    `json:"requestedBy,omitempty"`
+	StartTime      time.Time `json:"startTime"`
+	EndTime        time.Time `json:"endTime"`
+	Error          string    `json:"error,omitempty"`
+	SavedFiles     []string  `json:"savedFiles,omitempty"`
+	Warnings       []string  `json:"warnings,omitempty"`
+}
+
+// validateCapture rejects a --capture directory holding a previous bundle.
+func (o *DebugOptions) validateCapture() error {
+	if len(o.Capture) == 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(o.Capture)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("invalid --capture directory: %v", err)
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("the --capture directory %s is not empty", o.Capture)
+	}
+	return nil
+}
+
+// captureSessionDir is the directory, as seen by the debug session, holding the
+// oc-debug-save helper and the list of the files it marked.
+func (o *DebugOptions) captureSessionDir() string {
+	return path.Join("/tmp", "oc-debug-"+o.Attach.Pod.Name)
+}
+
+// captureRoot is where the filesystem of the debug session is found in the debug
+// container, which differs when the session runs inside 'chroot /host'.
+func (o *DebugOptions) captureRoot() string {
+	if o.Chroot {
+		return hostMountPath
+	}
+	return "/"
+}
+
+// captureKeepAliveCommand installs the oc-debug-save helper before idling like
+// keepAliveCommand.
+func (o *DebugOptions) captureKeepAliveCommand() []string {
+	dir := path.Join(o.captureRoot(), o.captureSessionDir())
+	script := fmt.Sprintf(
+		"mkdir -p %[1]s/bin && : > %[1]s/saved && cat > %[1]s/bin/%[2]s <<'EOF'\n%[3]sEOF\nchmod +x %[1]s/bin/%[2]s && exec %[4]s",
+		dir, captureSaveHelper, fmt.Sprintf(captureSaveScript, o.captureSessionDir()), strings.Join(keepAliveCommand(), " "))
+	return []string{commandLinuxShell, "-c", script}
+}
+
+// captureSessionCommand runs the session command with the oc-debug-save helper in
+// its PATH. With a TTY, the size of the local terminal is set explicitly, since the
+// session output is no longer written to the terminal directly.
+func (o *DebugOptions) captureSessionCommand(command []string, size *captureTerminalSize) []string {
+	script := fmt.Sprintf(`PATH=%s/bin:$PATH; export PATH; exec "$@"`, o.captureSessionDir())
+	if size != nil {
+		script = fmt.Sprintf("stty cols %d rows %d 2>/dev/null; %s", size.width, size.height, script)
+	}
+	return append([]string{commandLinuxShell, "-c", script, captureSaveHelper}, command...)
+}
+
+type captureTerminalSize struct {
+	width, height uint16
+}
+
+// capture records the transcript of a debug session and collects the bundle when
+// the session ends.
+type capture struct {
+	dir        string
+	transcript *os.File
+	session    captureSession
+}
+
+func (o *DebugOptions) startCapture(pod *corev1.Pod) (*capture, error) {
+	if err := os.MkdirAll(o.Capture, 0700); err != nil {
+		return nil, fmt.Errorf("unable to create the --capture directory: %v", err)
+	}
+	transcript, err := os.OpenFile(filepath.Join(o.Capture, captureTranscriptFile), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the session transcript: %v", err)
+	}
+	return &capture{
+		dir:        o.Capture,
+		transcript: transcript,
+		session: captureSession{
+			Pod:            pod.Name,
+			Namespace:      pod.Namespace,
+			Container:      o.ContainerName,
+			SourceResource: o.Annotations[debugPodAnnotationSourceResource],
+			Command:        o.sessionCommand,
+			RequestedBy:    o.requestedBy(),
+			StartTime:      time.Now().UTC(),
+		},
+	}, nil
+}
+
+// streams returns the streams of the session, whose output is also written to the
+// transcript, like its input when it is not echoed by a terminal.
+func (c *capture) streams(streams genericiooptions.IOStreams, tty bool) genericiooptions.IOStreams {
+	recorded := genericiooptions.IOStreams{
+		In:     streams.In,
+		Out:    io.MultiWriter(streams.Out, c.transcript),
+		ErrOut: io.MultiWriter(streams.ErrOut, c.transcript),
+	}
+	if !tty && streams.In != nil {
+		recorded.In = io.TeeReader(streams.In, c.transcript)
+	}
+	return recorded
+}
+
+// terminalSize returns the size of the local terminal for a TTY session.
+func terminalSize(streams genericiooptions.IOStreams, tty bool) *captureTerminalSize {
+	if !tty {
+		return nil
+	}
+	size := term.TTY{Out: streams.Out}.GetSize()
+	if size == nil {
+		return nil
+	}
+	return &captureTerminalSize{width: size.Width, height: size.Height}
+}
+
+// finish collects the files marked with oc-debug-save and the pod and node
+// metadata, and writes the summary of the session. Failures to collect part of
+// the bundle are reported as warnings.
+func (c *capture) finish(o *DebugOptions, pod *corev1.Pod, sessionErr error) error {
+	c.session.EndTime = time.Now().UTC()
+	if sessionErr != nil {
+		c.session.Error = sessionErr.Error()
+	}
+	if err := c.transcript.Close(); err != nil {
+		c.warn("unable to write the session transcript: %v", err)
+	}
+
+	c.collectSavedFiles(o, pod)
+	c.collectMetadata(o, pod)
+	if o.Chroot {
+		o.removeCaptureHelper(pod)
+	}
+
+	data, err := json.MarshalIndent(c.session, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(c.dir, captureSessionFile), append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("unable to write the capture bundle: %v", err)
+	}
+	for _, warning := range c.session.Warnings {
+		fmt.Fprintf(o.ErrOut, "warning: %s\n", warning)
+	}
+	if !o.Quiet {
+		fmt.Fprintf(o.ErrOut, "Capture bundle written to %s\n", c.dir)
+	}
+	return nil
+}
+
+func (c *capture) warn(format string, args ...interface{}) {
+	c.session.Warnings = append(c.session.Warnings, fmt.Sprintf(format, args...))
+}
+
+func (c *capture) collectSavedFiles(o *DebugOptions, pod *corev1.Pod) {
+	list := filepath.Join(c.dir, "saved")
+	remote := fmt.Sprintf("%s/%s:%s", pod.Namespace, pod.Name, path.Join(o.captureRoot(), o.captureSessionDir(), "saved"))
+	if err := o.Copy(remote, list); err != nil {
+		c.warn("unable to read the files marked with %s: %v", captureSaveHelper, err)
+		return
+	}
+	data, err := os.ReadFile(list)
+	os.Remove(list)
+	if err != nil {
+		c.warn("unable to read the files marked with %s: %v", captureSaveHelper, err)
+		return
+	}
+
+	saved := sets.NewString()
+	for _, line := range strings.Split(string(data), "\n") {
+		p := path.Clean(strings.TrimSpace(line))
+		if !path.IsAbs(p) || p == "/" || saved.Has(p) {
+			continue
+		}
+		saved.Insert(p)
+		local := filepath.Join(c.dir, captureFilesDir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(local), 0700); err != nil {
+			c.warn("unable to save %s: %v", p, err)
+			continue
+		}
+		if !o.Quiet {
+			fmt.Fprintf(o.ErrOut, "Saving %s ...\n", p)
+		}
+		if err := o.Copy(fmt.Sprintf("%s/%s:%s", pod.Namespace, pod.Name, path.Join(o.captureRoot(), p)), local); err != nil {
+			c.warn("unable to save %s: %v", p, err)
+			continue
+		}
+		c.session.SavedFiles = append(c.session.SavedFiles, p)
+	}
+}
+
+func (c *capture) collectMetadata(o *DebugOptions, pod *corev1.Pod) {
+	current, err := o.CoreClient.Pods(pod.Namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+	if err != nil {
+		c.warn("unable to get pod/%s: %v", pod.Name, err)
+		return
+	}
+	c.writeObject("pod.yaml", current)
+
+	c.session.Node = current.Spec.NodeName
+	if len(current.Spec.NodeName) == 0 {
+		return
+	}
+	node, err := o.CoreClient.Nodes().Get(context.TODO(), current.Spec.NodeName, metav1.GetOptions{})
+	if err != nil {
+		c.warn("unable to get node/%s: %v", current.Spec.NodeName, err)
+		return
+	}
+	c.writeObject("node.yaml", node)
+}
+
+func (c *capture) writeObject(name string, obj runtime.Object) {
+	if accessor, ok := obj.(metav1.Object); ok {
+		accessor.SetManagedFields(nil)
+	}
+	printer := printers.NewTypeSetter(scheme.Scheme).ToPrinter(&printers.YAMLPrinter{})
+	f, err := os.OpenFile(filepath.Join(c.dir, name), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		c.warn("unable to save %s: %v", name, err)
+		return
+	}
+	defer f.Close()
+	if err := printer.PrintObj(obj, f); err != nil {
+		c.warn("unable to save %s: %v", name, err)
+	}
+}
+
+// removeCaptureHelper removes the oc-debug-save helper from the host, where it
+// outlives the debug pod when the session runs inside 'chroot /host'.
+func (o *DebugOptions) removeCaptureHelper(pod *corev1.Pod) {
+	execOptions := &exec.ExecOptions{
+		StreamOptions: exec.StreamOptions{
+			Namespace:     pod.Namespace,
+			PodName:       pod.Name,
+			ContainerName: o.ContainerName,
+			Quiet:         true,
+			IOStreams:     genericiooptions.IOStreams{Out: io.Discard, ErrOut: io.Discard},
+		},
+		Command:   []string{"rm", "-rf", path.Join(o.captureRoot(), o.captureSessionDir())},
+		Executor:  &exec.DefaultRemoteExecutor{},
+		PodClient: o.CoreClient,
+		Config:    o.Attach.Config,
+	}
+	if err := execOptions.Run(); err != nil {
+		klog.V(2).Infof("Unable to remove %s from the host: %v", o.captureSessionDir(), err)
+	}
+}
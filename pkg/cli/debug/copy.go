@@ -0,0 +1,136 @@
+package debug
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/cmd/exec"
+)
+
+// keepAliveSeconds bounds how long a debug container started for a copy session
+// waits for the interactive session to finish before exiting on its own.
+const keepAliveSeconds = 4 * 60 * 60
+
+// copySpec describes a file or directory transferred between the local machine
+// and the debug pod.
+type copySpec struct {
+	local  string
+	remote string
+}
+
+// parseCopySpecs parses --copy-to (LOCAL:REMOTE) or --copy-from (REMOTE:LOCAL) values.
+// For --copy-to the last colon separates the paths, so that local Windows drive letters
+// are preserved, and for --copy-from the first colon does.
+func parseCopySpecs(flag string, values []string, localFirst bool) ([]copySpec, error) {
+	var specs []copySpec
+	for _, value := range values {
+		var i int
+		if localFirst {
+			i = strings.LastIndex(value, ":")
+		} else {
+			i = strings.Index(value, ":")
+		}
+		if i <= 0 || i == len(value)-1 {
+			if localFirst {
+				return nil, fmt.Errorf("--%s must be of the form LOCAL:REMOTE, got %q", flag, value)
+			}
+			return nil, fmt.Errorf("--%s must be of the form REMOTE:LOCAL, got %q", flag, value)
+		}
+		first, second := value[:i], value[i+1:]
+		if localFirst {
+			specs = append(specs, copySpec{local: first, remote: second})
+		} else {
+			specs = append(specs, copySpec{local: second, remote: first})
+		}
+	}
+	return specs, nil
+}
+
+// keepAlive returns true when the debug container should idle while the session
+// runs through exec, so files can be copied in before and out after the session.
+func (o *DebugOptions) keepAlive() bool {
+	return len(o.copyTo) > 0 || len(o.copyFrom) > 0 || len(o.Capture) > 0
+}
+
+// keepAliveCommand is the command run by the debug container when keepAlive is true.
+func keepAliveCommand() []string {
+	return []string{"sleep", strconv.Itoa(keepAliveSeconds)}
+}
+
+// runCopySession copies files into the debug pod, runs the debug command
+// through exec, and copies files back out and collects the capture bundle
+// before the pod is removed.
+func (o *DebugOptions) runCopySession(pod *corev1.Pod) error {
+	for _, spec := range o.copyTo {
+		remote := fmt.Sprintf("%s/%s:%s", pod.Namespace, pod.Name, spec.remote)
+		if !o.Quiet {
+			fmt.Fprintf(o.ErrOut, "Copying %s to %s ...\n", spec.local, spec.remote)
+		}
+		if err := o.Copy(spec.local, remote); err != nil {
+			return fmt.Errorf("unable to copy %s to the debug pod: %v", spec.local, err)
+		}
+	}
+
+	streams, command := o.IOStreams, o.sessionCommand
+	var sessionCapture *capture
+	if len(o.Capture) > 0 {
+		var err error
+		if sessionCapture, err = o.startCapture(pod); err != nil {
+			return err
+		}
+		tty := o.Attach.Stdin && o.Attach.TTY
+		command = o.captureSessionCommand(command, terminalSize(streams, tty))
+		streams = sessionCapture.streams(streams, tty)
+	}
+
+	execOptions := &exec.ExecOptions{
+		StreamOptions: exec.StreamOptions{
+			Namespace:       pod.Namespace,
+			PodName:         pod.Name,
+			ContainerName:   o.ContainerName,
+			Stdin:           o.Attach.Stdin,
+			TTY:             o.Attach.TTY,
+			Quiet:           o.Quiet,
+			InterruptParent: o.Attach.InterruptParent,
+			IOStreams:       streams,
+		},
+		Command:   command,
+		Executor:  &exec.DefaultRemoteExecutor{},
+		PodClient: o.CoreClient,
+		Config:    o.Attach.Config,
+	}
+	sessionErr := execOptions.Run()
+	if sessionErr != nil {
+		klog.V(4).Infof("Debug session ended with error: %v", sessionErr)
+	}
+
+	var copyErrs []string
+	for _, spec := range o.copyFrom {
+		remote := fmt.Sprintf("%s/%s:%s", pod.Namespace, pod.Name, spec.remote)
+		if !o.Quiet {
+			fmt.Fprintf(o.ErrOut, "Copying %s to %s ...\n", spec.remote, spec.local)
+		}
+		if err := o.Copy(remote, spec.local); err != nil {
+			copyErrs = append(copyErrs, fmt.Sprintf("unable to copy %s from the debug pod: %v", spec.remote, err))
+		}
+	}
+	if sessionCapture != nil {
+		if err := sessionCapture.finish(o, pod, sessionErr); err != nil {
+			copyErrs = append(copyErrs, err.Error())
+		}
+	}
+
+	if sessionErr != nil {
+		for _, msg := range copyErrs {
+			fmt.Fprintf(o.ErrOut, "error: %s\n", msg)
+		}
+		return sessionErr
+	}
+	if len(copyErrs) > 0 {
+		return fmt.Errorf("%s", strings.Join(copyErrs, "\n"))
+	}
+	return nil
+}
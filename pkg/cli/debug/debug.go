@@ -14,6 +14,7 @@ import (
 	kappsv1 "k8s.io/api/apps/v1"
 	kappsv1beta1 "k8s.io/api/apps/v1beta1"
 	kappsv1beta2 "k8s.io/api/apps/v1beta2"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
@@ -30,15 +31,19 @@ import (
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/cli-runtime/pkg/resource"
+	authenticationv1client "k8s.io/client-go/kubernetes/typed/authentication/v1"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
 	watchtools "k8s.io/client-go/tools/watch"
 	"k8s.io/kubectl/pkg/cmd/attach"
+	"k8s.io/kubectl/pkg/cmd/cp"
 	"k8s.io/kubectl/pkg/cmd/logs"
 	krun "k8s.io/kubectl/pkg/cmd/run"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	kgenerate "k8s.io/kubectl/pkg/generate/versioned"
 	"k8s.io/kubectl/pkg/polymorphichelpers"
 	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/completion"
 	"k8s.io/kubectl/pkg/util/interrupt"
 	"k8s.io/kubectl/pkg/util/templates"
 	"k8s.io/pod-security-admission/api"
@@ -54,6 +59,7 @@ import (
 	"github.com/openshift/library-go/pkg/image/reference"
 
 	ocmdhelpers "github.com/openshift/oc/pkg/helpers/cmd"
+	ocompletion "github.com/openshift/oc/pkg/helpers/completion"
 	"github.com/openshift/oc/pkg/helpers/conditions"
 	utilenv "github.com/openshift/oc/pkg/helpers/env"
 	generateapp "github.com/openshift/oc/pkg/helpers/newapp/app"
@@ -62,12 +68,18 @@ import (
 const (
 	debugPodAnnotationSourceContainer = "debug.openshift.io/source-container"
 	debugPodAnnotationSourceResource  = "debug.openshift.io/source-resource"
+	// debugPodAnnotationRequestedBy records the user who started a node debug session, for auditing
+	debugPodAnnotationRequestedBy = "debug.openshift.io/requested-by"
 	// containerResourcesAnnotationPrefix contains resource annotation prefix that will be used by CRI-O to set cpu shares
 	containerResourcesAnnotationPrefix = "resources.workload.openshift.io/"
 	// podWorkloadTargetAnnotationPrefix contains the prefix for the pod workload target annotation
 	podWorkloadTargetAnnotationPrefix = "target.workload.openshift.io/"
 	commandLinuxShell                 = "/bin/sh"
 	commandWindowsShell               = "cmd.exe"
+	// hostMountPath is where the node's root filesystem is mounted in node debug pods
+	hostMountPath = "/host"
+	// defaultNodeImage is used for node debugging when no --image is given and the tools image stream cannot be resolved
+	defaultNodeImage = "registry.redhat.io/rhel9/support-tools"
 )
 
 var (
@@ -95,6 +107,28 @@ var (
 		resources that can be used to create pods (such as image stream tags), or simply pass
 		'--image=IMAGE' to start a simple shell session in an image with a shell program
 
+		When debugging a node, the pod runs privileged on that node with the host's root
+		filesystem mounted at /host, using the support tools image unless --image is given.
+		Pass --chroot to run the command, or a login shell, inside 'chroot /host' directly.
+		The user who started the session is recorded in the debug.openshift.io/requested-by
+		annotation on the pod.
+
+		Some problems only occur in the running pod and do not reproduce in a copy.  Pass
+		--ephemeral to add an ephemeral debug container to the running pod instead.  It shares
+		the process namespace of the target container (see -c), but ephemeral containers cannot
+		be removed once added and remain in the pod spec until the pod is deleted.
+
+		Files can be copied into the debug pod before the command starts with --copy-to, and
+		out of it after the command exits with --copy-from.  When either is set, the debug
+		container idles and the command is run with 'exec', so the image must provide 'sleep'
+		and 'tar'.
+
+		To attach the findings of a session to an incident ticket, pass --capture=DIR.  When
+		the session ends, its transcript, the files marked during the session with the
+		'oc-debug-save PATH' helper, and the pod and node metadata are collected in DIR, with
+		a session.json summary.  As with --copy-to, the command is run with 'exec', and the
+		image must also provide '/bin/sh'.
+
 		The debug pod is deleted when the remote command completes or the user interrupts
 		the shell.
 	`)
@@ -109,6 +143,9 @@ var (
 		# Debug a node as an administrator
 		oc debug node/master-1
 
+		# Debug a node with a custom support tools image, starting a login shell in the host's root filesystem
+		oc debug node/master-1 --image=quay.io/example/support-tools:latest --chroot
+
 		# Debug a Windows node
 		# Note: the chosen image must match the Windows Server version (2019, 2022) of the node
 		oc debug node/win-worker-1 --image=mcr.microsoft.com/powershell:lts-nanoserver-ltsc2022
@@ -125,6 +162,19 @@ var (
 		# See the pod that would be created to debug
 		oc debug mypod-9xbc -o yaml
 
+		# Copy a script into a debug pod, run it, and copy its results back out
+		oc debug mypod-9xbc --copy-to=./collect.sh:/tmp/collect.sh --copy-from=/tmp/results:./results -- /bin/sh /tmp/collect.sh
+
+		# Debug a node and collect a bundle with the transcript of the session and the files
+		# marked with 'oc-debug-save /var/log/messages' during the session
+		oc debug node/master-1 --chroot --capture=./incident-1234
+
+		# Debug a running pod in place with an ephemeral container that can see the processes of its 'app' container
+		oc debug mypod-9xbc --ephemeral -c app --image=registry.access.redhat.com/ubi9/ubi
+
+		# Debug a copy of a large pod on a constrained cluster, without its resource requests or tolerations
+		oc debug deploy/test --strip-resources --strip-tolerations --node-selector=""
+
 		# Debug a resource but launch the debug pod in another namespace
 		# Note: Not all resources can be debugged using --to-namespace without modification. For example,
 		# volumes and service accounts are namespace-dependent. Add '-o yaml' to output the debug pod definition
@@ -139,6 +189,7 @@ type DebugOptions struct {
 	Attach attach.AttachOptions
 
 	CoreClient  corev1client.CoreV1Interface
+	AuthClient  authenticationv1client.AuthenticationV1Interface
 	AppsClient  appsv1client.AppsV1Interface
 	ImageClient imagev1client.ImageV1Interface
 
@@ -179,6 +230,28 @@ type DebugOptions struct {
 	Image              string
 	ImageStream        string
 	ToNamespace        string
+	Chroot             bool
+	RequestedBy        string
+	Ephemeral          bool
+	StripResources     bool
+	Requests           string
+	Limits             string
+	StripTolerations   bool
+	NodeSelector       string
+	NodeSelectorSet    bool
+	HostNetwork        bool
+	HostNetworkSet     bool
+	CopyTo             []string
+	CopyFrom           []string
+	Capture            string
+
+	// Copy transfers files between the local machine and the debug pod, using the
+	// source and destination conventions of 'oc cp'.
+	Copy func(src, dest string) error
+
+	copyTo         []copySpec
+	copyFrom       []copySpec
+	sessionCommand []string
 
 	// IsNode is set after we see the object we're debugging.  We use it to be able to print pertinent advice.
 	IsNode bool
@@ -206,10 +279,11 @@ func NewDebugOptions(streams genericiooptions.IOStreams) *DebugOptions {
 func NewCmdDebug(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
 	o := NewDebugOptions(streams)
 	cmd := &cobra.Command{
-		Use:     "debug RESOURCE/NAME [ENV1=VAL1 ...] [-c CONTAINER] [flags] [-- COMMAND]",
-		Short:   "Launch a new instance of a pod for debugging",
-		Long:    debugLong,
-		Example: debugExample,
+		Use:               "debug RESOURCE/NAME [ENV1=VAL1 ...] [-c CONTAINER] [flags] [-- COMMAND]",
+		Short:             "Launch a new instance of a pod for debugging",
+		Long:              debugLong,
+		Example:           debugExample,
+		ValidArgsFunction: debugTargetCompletionFunc(f),
 		Run: func(cmd *cobra.Command, args []string) {
 			kcmdutil.CheckErr(o.Complete(cmd, f, args))
 			kcmdutil.CheckErr(o.Validate())
@@ -225,10 +299,45 @@ func NewCmdDebug(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.
 	}
 
 	addDebugFlags(cmd, o)
+	kcmdutil.CheckErr(cmd.RegisterFlagCompletionFunc("node-name", ocompletion.NodeCompletionFunc(f)))
+	kcmdutil.CheckErr(cmd.RegisterFlagCompletionFunc("image-stream", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return ocompletion.CompGetImageStreamTags(f, toComplete, true)
+	}))
 
 	return cmd
 }
 
+// debugTargetTypes are the resource types completed for the RESOURCE/NAME argument.
+var debugTargetTypes = []string{"node", "pod", "deployment", "deploymentconfig", "daemonset", "statefulset", "replicaset", "replicationcontroller", "job", "cronjob"}
+
+// debugTargetCompletionFunc completes the pod names and the debuggable resource types
+// followed by a slash, then the names of the resources of that type, like node/NAME.
+func debugTargetCompletionFunc(f kcmdutil.Factory) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		if resourceType, name, ok := strings.Cut(toComplete, "/"); ok {
+			var comps []string
+			for _, c := range completion.CompGetResource(f, resourceType, name) {
+				comps = append(comps, resourceType+"/"+c)
+			}
+			return comps, cobra.ShellCompDirectiveNoFileComp
+		}
+		var types []string
+		for _, t := range debugTargetTypes {
+			if strings.HasPrefix(t, toComplete) {
+				types = append(types, t+"/")
+			}
+		}
+		pods := completion.CompGetResource(f, "pod", toComplete)
+		if len(pods) == 0 {
+			return types, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+		}
+		return append(pods, types...), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
 func addDebugFlags(cmd *cobra.Command, o *DebugOptions) {
 	usage := "to read a template"
 	kcmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, usage)
@@ -261,6 +370,17 @@ func addDebugFlags(cmd *cobra.Command, o *DebugOptions) {
 	cmd.Flags().StringVar(&o.ImageStream, "image-stream", o.ImageStream, "Specify an image stream (namespace/name:tag) containing a debug image to run.")
 	cmd.Flags().StringVar(&o.ToNamespace, "to-namespace", o.ToNamespace, "Override the namespace to create the pod into (instead of using --namespace).")
 	cmd.Flags().BoolVar(&o.PreservePod, "preserve-pod", o.PreservePod, "If true, the pod will not be deleted after the debug command exits.")
+	cmd.Flags().BoolVar(&o.StripResources, "strip-resources", o.StripResources, "If true, remove the resource requests and limits of all containers in the debug pod, so it can be scheduled on constrained clusters.")
+	cmd.Flags().StringVar(&o.Requests, "requests", o.Requests, "Resource requests for the debug container, for example 'cpu=100m,memory=256Mi'. Replaces the requests of the original container.")
+	cmd.Flags().StringVar(&o.Limits, "limits", o.Limits, "Resource limits for the debug container, for example 'cpu=200m,memory=512Mi'. Replaces the limits of the original container.")
+	cmd.Flags().BoolVar(&o.StripTolerations, "strip-tolerations", o.StripTolerations, "If true, remove the tolerations of the original pod from the debug pod.")
+	cmd.Flags().StringVar(&o.NodeSelector, "node-selector", o.NodeSelector, "Replace the node selector and node affinity of the debug pod with this label selector, for example 'node-role.kubernetes.io/worker='. An empty value clears them.")
+	cmd.Flags().BoolVar(&o.HostNetwork, "host-network", o.HostNetwork, "If set, override whether the debug pod uses the host network namespace.")
+	cmd.Flags().BoolVar(&o.Ephemeral, "ephemeral", o.Ephemeral, "If true, instead of creating a copy of the pod, add an ephemeral debug container to the running pod that shares the process namespace of the target container.")
+	cmd.Flags().StringArrayVar(&o.CopyTo, "copy-to", o.CopyTo, "Copy a local file or directory into the debug pod before the command starts, as LOCAL:REMOTE. May be repeated.")
+	cmd.Flags().StringArrayVar(&o.CopyFrom, "copy-from", o.CopyFrom, "Copy a file or directory out of the debug pod after the command exits, as REMOTE:LOCAL. May be repeated.")
+	cmd.Flags().StringVar(&o.Capture, "capture", o.Capture, "When the session ends, collect its transcript, the files marked with 'oc-debug-save PATH' and the pod and node metadata in this directory.")
+	cmd.Flags().BoolVar(&o.Chroot, "chroot", o.Chroot, "If true, when debugging a node, run the command inside 'chroot /host', starting a login shell if no command was given.")
 
 	o.PrintFlags.AddFlags(cmd)
 	kcmdutil.AddDryRunFlag(cmd)
@@ -312,6 +432,8 @@ func (o *DebugOptions) Complete(cmd *cobra.Command, f kcmdutil.Factory, args []s
 	o.Attach.Quiet = o.Quiet
 
 	o.NodeNameSet = cmd.Flags().Changed("node-name")
+	o.NodeSelectorSet = cmd.Flags().Changed("node-selector")
+	o.HostNetworkSet = cmd.Flags().Changed("host-network")
 
 	if o.Annotations == nil {
 		o.Annotations = make(map[string]string)
@@ -333,6 +455,21 @@ func (o *DebugOptions) Complete(cmd *cobra.Command, f kcmdutil.Factory, args []s
 		return err
 	}
 
+	if o.copyTo, err = parseCopySpecs("copy-to", o.CopyTo, true); err != nil {
+		return err
+	}
+	if o.copyFrom, err = parseCopySpecs("copy-from", o.CopyFrom, false); err != nil {
+		return err
+	}
+	o.Copy = func(src, dest string) error {
+		copyOptions := cp.NewCopyOptions(o.IOStreams)
+		if err := copyOptions.Complete(f, cmd, []string{src, dest}); err != nil {
+			return err
+		}
+		copyOptions.Container = o.ContainerName
+		return copyOptions.Run()
+	}
+
 	cmdParent := cmd.Parent()
 	if cmdParent != nil && len(cmdParent.CommandPath()) > 0 && kcmdutil.IsSiblingCommandExists(cmd, "describe") {
 		o.FullCmdName = cmdParent.CommandPath()
@@ -368,6 +505,11 @@ func (o *DebugOptions) Complete(cmd *cobra.Command, f kcmdutil.Factory, args []s
 		return err
 	}
 
+	o.AuthClient, err = authenticationv1client.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -375,9 +517,89 @@ func (o DebugOptions) Validate() error {
 	if (o.AsRoot || o.AsNonRoot) && o.AsUser > 0 {
 		return fmt.Errorf("you may not specify --as-root and --as-user=%d at the same time", o.AsUser)
 	}
+	if o.StripResources && (len(o.Requests) > 0 || len(o.Limits) > 0) {
+		return fmt.Errorf("you may not specify --strip-resources with --requests or --limits")
+	}
+	if _, err := o.resourceRequirements(); err != nil {
+		return err
+	}
+	if _, err := o.nodeSelector(); err != nil {
+		return err
+	}
+	if err := o.validateCapture(); err != nil {
+		return err
+	}
+	if o.Ephemeral && (o.StripResources || len(o.Requests) > 0 || len(o.Limits) > 0 || o.StripTolerations || o.NodeSelectorSet || o.HostNetworkSet) {
+		return fmt.Errorf("resource, toleration, node selector and host network flags may not be used with --ephemeral")
+	}
+	if o.Ephemeral {
+		return o.validateEphemeral()
+	}
 	return nil
 }
 
+// resourceRequirements parses --requests and --limits.
+func (o *DebugOptions) resourceRequirements() (*corev1.ResourceRequirements, error) {
+	if len(o.Requests) == 0 && len(o.Limits) == 0 {
+		return nil, nil
+	}
+	requirements, err := kgenerate.HandleResourceRequirementsV1(map[string]string{
+		"requests": o.Requests,
+		"limits":   o.Limits,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid --requests or --limits: %v", err)
+	}
+	return &requirements, nil
+}
+
+// nodeSelector parses --node-selector.
+func (o *DebugOptions) nodeSelector() (map[string]string, error) {
+	if len(o.NodeSelector) == 0 {
+		return nil, nil
+	}
+	selector, err := labels.ConvertSelectorToLabelsMap(o.NodeSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --node-selector %q: %v", o.NodeSelector, err)
+	}
+	return selector, nil
+}
+
+// applySchedulingOverrides applies the resource, toleration, node selection and
+// host network overrides to the debug pod.
+func (o *DebugOptions) applySchedulingOverrides(pod *corev1.Pod, container *corev1.Container) {
+	if o.StripResources {
+		for i := range pod.Spec.InitContainers {
+			pod.Spec.InitContainers[i].Resources = corev1.ResourceRequirements{}
+		}
+		for i := range pod.Spec.Containers {
+			pod.Spec.Containers[i].Resources = corev1.ResourceRequirements{}
+		}
+		pod.Spec.Overhead = nil
+	}
+	// errors are reported by Validate
+	if requirements, _ := o.resourceRequirements(); requirements != nil {
+		if len(o.Requests) > 0 {
+			container.Resources.Requests = requirements.Requests
+		}
+		if len(o.Limits) > 0 {
+			container.Resources.Limits = requirements.Limits
+		}
+	}
+	if o.StripTolerations {
+		pod.Spec.Tolerations = nil
+	}
+	if o.NodeSelectorSet {
+		pod.Spec.NodeSelector, _ = o.nodeSelector()
+		if pod.Spec.Affinity != nil {
+			pod.Spec.Affinity.NodeAffinity = nil
+		}
+	}
+	if o.HostNetworkSet {
+		pod.Spec.HostNetwork = o.HostNetwork
+	}
+}
+
 // Debug creates and runs a debugging pod.
 func (o *DebugOptions) RunDebug() error {
 	var infos []*resource.Info
@@ -438,6 +660,14 @@ func (o *DebugOptions) RunDebug() error {
 		return fmt.Errorf("you must identify a single resource with a pod template to debug")
 	}
 
+	if o.Ephemeral {
+		pod, ok := infos[0].Object.(*corev1.Pod)
+		if !ok {
+			return fmt.Errorf("--ephemeral may only be used when debugging a running pod")
+		}
+		return o.runEphemeral(pod)
+	}
+
 	template, err := o.approximatePodTemplateForObject(infos[0].Object)
 	if err != nil && template == nil {
 		return fmt.Errorf("cannot debug %s: %v", infos[0].Name, err)
@@ -486,8 +716,21 @@ func (o *DebugOptions) RunDebug() error {
 	o.Annotations[debugPodAnnotationSourceResource] = fmt.Sprintf("%s/%s", infos[0].Mapping.Resource, infos[0].Name)
 	o.Annotations[debugPodAnnotationSourceContainer] = o.ContainerName
 
+	if o.Chroot && !o.IsNode {
+		return fmt.Errorf("--chroot may only be used when debugging a node")
+	}
+	if o.Chroot && pod.Spec.OS != nil && pod.Spec.OS.Name == corev1.Windows {
+		return fmt.Errorf("--chroot is not supported when debugging Windows nodes")
+	}
+	if len(o.Capture) > 0 && pod.Spec.OS != nil && pod.Spec.OS.Name == corev1.Windows {
+		return fmt.Errorf("--capture is not supported when debugging Windows pods")
+	}
+
 	if infos[0].Mapping.GroupVersionKind.Kind == "Node" {
 		o.Annotations[securityv1.RequiredSCCAnnotation] = "privileged"
+		if requestedBy := o.requestedBy(); len(requestedBy) > 0 {
+			o.Annotations[debugPodAnnotationRequestedBy] = requestedBy
+		}
 	}
 
 	pod, originalCommand := o.transformPodForDebug(o.Annotations)
@@ -546,7 +789,7 @@ func (o *DebugOptions) RunDebug() error {
 			} else {
 				fmt.Fprintf(o.ErrOut, "Starting pod/%s ...\n", pod.Name)
 			}
-			if o.IsNode {
+			if o.IsNode && !o.Chroot {
 				if !(template.Spec.OS != nil && template.Spec.OS.Name == corev1.Windows) {
 					fmt.Fprintf(o.ErrOut, "To use host binaries, run `chroot /host`. Instead, if you need to access host namespaces, run `nsenter -a -t 1`.\n")
 				}
@@ -636,6 +879,8 @@ func (o *DebugOptions) RunDebug() error {
 			return conditions.ErrNonZeroExitCode
 		case err != nil:
 			return err
+		case o.keepAlive():
+			return o.runCopySession(pod)
 		case !o.Attach.Stdin:
 			if err = o.getLogs(pod); err != nil {
 				return err
@@ -813,6 +1058,17 @@ func (o *DebugOptions) transformPodForDebug(annotations map[string]string) (*cor
 	container.TTY = o.Attach.Stdin && o.Attach.TTY
 	container.Stdin = o.Attach.Stdin
 	container.StdinOnce = o.Attach.Stdin
+	if o.keepAlive() {
+		// the session is run through exec once files have been copied in
+		o.sessionCommand = command
+		container.Command = keepAliveCommand()
+		if len(o.Capture) > 0 {
+			container.Command = o.captureKeepAliveCommand()
+		}
+		container.TTY = false
+		container.Stdin = false
+		container.StdinOnce = false
+	}
 
 	if !o.KeepReadiness {
 		container.ReadinessProbe = nil
@@ -882,6 +1138,8 @@ func (o *DebugOptions) transformPodForDebug(annotations map[string]string) (*cor
 		pod.Spec.InitContainers = nil
 	}
 
+	o.applySchedulingOverrides(pod, containerForName(pod, o.ContainerName))
+
 	clearHostPorts(pod)
 
 	// keep workload annotations
@@ -1013,11 +1271,37 @@ func (o *DebugOptions) getContainerCommand() []string {
 		if pod.Spec.OS != nil && pod.Spec.OS.Name == corev1.Windows {
 			return []string{commandWindowsShell}
 		}
+
+		if o.Chroot {
+			return []string{"chroot", hostMountPath, "/bin/bash", "-l"}
+		}
+	}
+
+	if o.Chroot {
+		return append([]string{"chroot", hostMountPath}, o.Command...)
 	}
 
 	return o.Command
 }
 
+// requestedBy returns the name of the user starting the debug session, or
+// an empty string if it cannot be determined.
+func (o *DebugOptions) requestedBy() string {
+	if len(o.RequestedBy) > 0 {
+		return o.RequestedBy
+	}
+	if o.AuthClient == nil {
+		return ""
+	}
+	res, err := o.AuthClient.SelfSubjectReviews().Create(context.TODO(), &authenticationv1.SelfSubjectReview{}, metav1.CreateOptions{})
+	if err != nil {
+		klog.V(2).Infof("Unable to determine the current user for the %s annotation: %v", debugPodAnnotationRequestedBy, err)
+		return ""
+	}
+	o.RequestedBy = res.Status.UserInfo.Username
+	return o.RequestedBy
+}
+
 func (o *DebugOptions) approximatePodTemplateForObject(object runtime.Object) (*corev1.PodTemplateSpec, error) {
 	switch t := object.(type) {
 	case *corev1.Node:
@@ -1045,8 +1329,8 @@ func (o *DebugOptions) approximatePodTemplateForObject(object runtime.Object) (*
 			}
 		}
 		if len(image) == 0 {
-			klog.V(2).Infof("Falling to 'registry.redhat.io/rhel9/support-tools' image")
-			image = "registry.redhat.io/rhel9/support-tools"
+			klog.V(2).Infof("Falling to '%s' image", defaultNodeImage)
+			image = defaultNodeImage
 		}
 		zero := int64(0)
 		isTrue := true
@@ -1081,7 +1365,7 @@ func (o *DebugOptions) approximatePodTemplateForObject(object runtime.Object) (*
 						VolumeMounts: []corev1.VolumeMount{
 							{
 								Name:      "host",
-								MountPath: "/host",
+								MountPath: hostMountPath,
 							},
 						},
 						Env: []corev1.EnvVar{
@@ -1093,7 +1377,7 @@ func (o *DebugOptions) approximatePodTemplateForObject(object runtime.Object) (*
 							{
 								//  to collect more sos report requires this env var is set
 								Name:  "HOST",
-								Value: "/host",
+								Value: hostMountPath,
 							},
 						},
 					},
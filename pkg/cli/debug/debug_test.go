@@ -1,9 +1,16 @@
 package debug
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
@@ -201,3 +208,369 @@ func TestGetNamespace(t *testing.T) {
 		}
 	}
 }
+
+func TestGetContainerCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  []string
+		chroot   bool
+		os       corev1.OSName
+		expected []string
+	}{
+		{
+			name:     "default shell",
+			command:  []string{commandLinuxShell},
+			expected: []string{commandLinuxShell},
+		},
+		{
+			name:     "default shell on windows",
+			command:  []string{commandLinuxShell},
+			os:       corev1.Windows,
+			expected: []string{commandWindowsShell},
+		},
+		{
+			name:     "default shell with chroot",
+			command:  []string{commandLinuxShell},
+			chroot:   true,
+			expected: []string{"chroot", "/host", "/bin/bash", "-l"},
+		},
+		{
+			name:     "explicit command with chroot",
+			command:  []string{"journalctl", "-u", "kubelet"},
+			chroot:   true,
+			expected: []string{"chroot", "/host", "journalctl", "-u", "kubelet"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pod := &corev1.Pod{}
+			if len(test.os) > 0 {
+				pod.Spec.OS = &corev1.PodOS{Name: test.os}
+			}
+			o := &DebugOptions{
+				Command: test.command,
+				Chroot:  test.chroot,
+				Attach:  attach.AttachOptions{Pod: pod},
+			}
+			if actual := o.getContainerCommand(); !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected command %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseCopySpecs(t *testing.T) {
+	tests := []struct {
+		name          string
+		flag          string
+		values        []string
+		localFirst    bool
+		expected      []copySpec
+		expectedError string
+	}{
+		{
+			name:       "copy to",
+			flag:       "copy-to",
+			values:     []string{"./script.sh:/tmp/script.sh", `C:\scripts\run.ps1:/tmp/run.ps1`},
+			localFirst: true,
+			expected: []copySpec{
+				{local: "./script.sh", remote: "/tmp/script.sh"},
+				{local: `C:\scripts\run.ps1`, remote: "/tmp/run.ps1"},
+			},
+		},
+		{
+			name:     "copy from",
+			flag:     "copy-from",
+			values:   []string{"/tmp/results:./results"},
+			expected: []copySpec{{local: "./results", remote: "/tmp/results"}},
+		},
+		{
+			name:          "missing remote",
+			flag:          "copy-to",
+			values:        []string{"./script.sh"},
+			localFirst:    true,
+			expectedError: `--copy-to must be of the form LOCAL:REMOTE, got "./script.sh"`,
+		},
+		{
+			name:          "empty local",
+			flag:          "copy-from",
+			values:        []string{"/tmp/results:"},
+			expectedError: `--copy-from must be of the form REMOTE:LOCAL, got "/tmp/results:"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := parseCopySpecs(test.flag, test.values, test.localFirst)
+			var actualError string
+			if err != nil {
+				actualError = err.Error()
+			}
+			if actualError != test.expectedError {
+				t.Fatalf("expected error %q, got %q", test.expectedError, actualError)
+			}
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestEphemeralContainerForDebug(t *testing.T) {
+	o := &DebugOptions{
+		Command:       []string{commandLinuxShell},
+		ContainerName: "app",
+		AsUser:        -1,
+		AsRoot:        true,
+		Attach:        attach.AttachOptions{Pod: &corev1.Pod{}},
+	}
+	o.Attach.Stdin = true
+	o.Attach.TTY = true
+
+	container := o.ephemeralContainerForDebug("example.com/tools:latest")
+	if container.TargetContainerName != "app" {
+		t.Errorf("expected target container app, got %q", container.TargetContainerName)
+	}
+	if container.Image != "example.com/tools:latest" {
+		t.Errorf("expected image example.com/tools:latest, got %q", container.Image)
+	}
+	if !reflect.DeepEqual(container.Command, []string{commandLinuxShell}) {
+		t.Errorf("expected command %v, got %v", []string{commandLinuxShell}, container.Command)
+	}
+	if !container.Stdin || !container.TTY {
+		t.Errorf("expected stdin and tty to be set, got stdin=%t tty=%t", container.Stdin, container.TTY)
+	}
+	if container.SecurityContext == nil || container.SecurityContext.RunAsUser == nil || *container.SecurityContext.RunAsUser != 0 {
+		t.Errorf("expected the container to run as root, got %#v", container.SecurityContext)
+	}
+}
+
+func TestValidateEphemeral(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       DebugOptions
+		expectedError string
+	}{
+		{
+			name: "no conflicting options",
+		},
+		{
+			name:          "chroot",
+			options:       DebugOptions{Chroot: true},
+			expectedError: "--chroot may not be used with --ephemeral",
+		},
+		{
+			name:          "to namespace",
+			options:       DebugOptions{ToNamespace: "debug"},
+			expectedError: "--to-namespace may not be used with --ephemeral",
+		},
+		{
+			name:          "node name",
+			options:       DebugOptions{NodeNameSet: true},
+			expectedError: "--node-name may not be used with --ephemeral",
+		},
+		{
+			name:          "one container",
+			options:       DebugOptions{OneContainer: true},
+			expectedError: "--one-container may not be used with --ephemeral",
+		},
+		{
+			name:          "capture",
+			options:       DebugOptions{Capture: "./incident"},
+			expectedError: "--copy-to, --copy-from and --capture may not be used with --ephemeral",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.options.Ephemeral = true
+			err := test.options.Validate()
+			if len(test.expectedError) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+				t.Errorf("expected error %q, got %v", test.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestApplySchedulingOverrides(t *testing.T) {
+	newPod := func() *corev1.Pod {
+		return &corev1.Pod{
+			Spec: corev1.PodSpec{
+				NodeSelector: map[string]string{"disktype": "ssd"},
+				Affinity:     &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{}},
+				Tolerations:  []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}},
+				Containers: []corev1.Container{
+					{
+						Name: "app",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("8")},
+						},
+					},
+					{
+						Name: "sidecar",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("16Gi")},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("strip", func(t *testing.T) {
+		pod := newPod()
+		o := &DebugOptions{StripResources: true, StripTolerations: true, NodeSelectorSet: true, HostNetwork: true, HostNetworkSet: true}
+		o.applySchedulingOverrides(pod, &pod.Spec.Containers[0])
+		for _, c := range pod.Spec.Containers {
+			if len(c.Resources.Requests) > 0 || len(c.Resources.Limits) > 0 {
+				t.Errorf("expected the resources of %s to be removed, got %#v", c.Name, c.Resources)
+			}
+		}
+		if pod.Spec.Tolerations != nil {
+			t.Errorf("expected tolerations to be removed, got %#v", pod.Spec.Tolerations)
+		}
+		if pod.Spec.NodeSelector != nil || pod.Spec.Affinity.NodeAffinity != nil {
+			t.Errorf("expected node selection to be cleared, got %#v %#v", pod.Spec.NodeSelector, pod.Spec.Affinity.NodeAffinity)
+		}
+		if !pod.Spec.HostNetwork {
+			t.Errorf("expected host network to be set")
+		}
+	})
+
+	t.Run("override", func(t *testing.T) {
+		pod := newPod()
+		o := &DebugOptions{Requests: "cpu=100m", NodeSelector: "node-role.kubernetes.io/worker=", NodeSelectorSet: true}
+		o.applySchedulingOverrides(pod, &pod.Spec.Containers[0])
+		if expected := (corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}); !reflect.DeepEqual(pod.Spec.Containers[0].Resources.Requests, expected) {
+			t.Errorf("expected requests %v, got %v", expected, pod.Spec.Containers[0].Resources.Requests)
+		}
+		if _, ok := pod.Spec.Containers[1].Resources.Requests[corev1.ResourceMemory]; !ok {
+			t.Errorf("expected the sidecar requests to be kept")
+		}
+		if expected := map[string]string{"node-role.kubernetes.io/worker": ""}; !reflect.DeepEqual(pod.Spec.NodeSelector, expected) {
+			t.Errorf("expected node selector %v, got %v", expected, pod.Spec.NodeSelector)
+		}
+		if len(pod.Spec.Tolerations) != 1 {
+			t.Errorf("expected tolerations to be kept, got %#v", pod.Spec.Tolerations)
+		}
+	})
+}
+
+func TestCaptureCommands(t *testing.T) {
+	o := &DebugOptions{Chroot: true}
+	o.Attach.Pod = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "master-1-debug-abcde"}}
+
+	keepAlive := o.captureKeepAliveCommand()
+	if len(keepAlive) != 3 || keepAlive[0] != commandLinuxShell || keepAlive[1] != "-c" {
+		t.Fatalf("unexpected keep alive command %v", keepAlive)
+	}
+	for _, expected := range []string{
+		"mkdir -p /host/tmp/oc-debug-master-1-debug-abcde/bin",
+		">> /tmp/oc-debug-master-1-debug-abcde/saved",
+		"exec sleep 14400",
+	} {
+		if !strings.Contains(keepAlive[2], expected) {
+			t.Errorf("expected the keep alive command to contain %q, got %s", expected, keepAlive[2])
+		}
+	}
+
+	session := o.captureSessionCommand([]string{"chroot", "/host", "/bin/bash", "-l"}, &captureTerminalSize{width: 120, height: 40})
+	expected := []string{
+		commandLinuxShell, "-c",
+		`stty cols 120 rows 40 2>/dev/null; PATH=/tmp/oc-debug-master-1-debug-abcde/bin:$PATH; export PATH; exec "$@"`,
+		captureSaveHelper, "chroot", "/host", "/bin/bash", "-l",
+	}
+	if !reflect.DeepEqual(session, expected) {
+		t.Errorf("expected session command\n%q\ngot\n%q", expected, session)
+	}
+}
+
+func TestCaptureBundle(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "bundle")
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-debug-abcde", Namespace: "test", ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "oc"}}},
+		Spec:       corev1.PodSpec{NodeName: "worker-0"},
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0"}}
+
+	o := &DebugOptions{
+		Capture:        dir,
+		ContainerName:  "app",
+		CoreClient:     fakekubeclient.NewSimpleClientset(pod, node).CoreV1(),
+		IOStreams:      genericiooptions.NewTestIOStreamsDiscard(),
+		Annotations:    map[string]string{debugPodAnnotationSourceResource: "pods/app"},
+		sessionCommand: []string{"/bin/sh"},
+	}
+	o.Attach.Pod = pod
+	var copied []string
+	o.Copy = func(src, dest string) error {
+		copied = append(copied, src)
+		switch src {
+		case "test/app-debug-abcde:/tmp/oc-debug-app-debug-abcde/saved":
+			return os.WriteFile(dest, []byte("/etc/hosts\n//etc/hosts\n/var/log/missing\n"), 0600)
+		case "test/app-debug-abcde:/etc/hosts":
+			return os.WriteFile(dest, []byte("127.0.0.1 localhost\n"), 0600)
+		}
+		return fmt.Errorf("no such file")
+	}
+
+	if err := o.validateCapture(); err != nil {
+		t.Fatal(err)
+	}
+	c, err := o.startCapture(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprint(c.streams(o.IOStreams, true).Out, "sh-5.1$ cat /etc/hosts\n")
+	if err := c.finish(o, pod, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(copied) != 3 {
+		t.Errorf("expected the list of saved files and 2 files to be copied, got %v", copied)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, captureTranscriptFile)); err != nil || string(data) != "sh-5.1$ cat /etc/hosts\n" {
+		t.Errorf("unexpected transcript %q: %v", data, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, captureFilesDir, "etc", "hosts")); err != nil || string(data) != "127.0.0.1 localhost\n" {
+		t.Errorf("unexpected saved file %q: %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "saved")); !os.IsNotExist(err) {
+		t.Errorf("expected the list of saved files to be removed from the bundle: %v", err)
+	}
+	podData, err := os.ReadFile(filepath.Join(dir, "pod.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(podData), "kind: Pod") || strings.Contains(string(podData), "managedFields") {
+		t.Errorf("unexpected pod metadata:\n%s", podData)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "node.yaml")); err != nil {
+		t.Errorf("expected the node metadata: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, captureSessionFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var session captureSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		t.Fatal(err)
+	}
+	if session.Node != "worker-0" || session.SourceResource != "pods/app" || !reflect.DeepEqual(session.SavedFiles, []string{"/etc/hosts"}) {
+		t.Errorf("unexpected session summary %#v", session)
+	}
+	if len(session.Warnings) != 1 || !strings.Contains(session.Warnings[0], "unable to save /var/log/missing") {
+		t.Errorf("expected a warning for the missing file, got %v", session.Warnings)
+	}
+
+	if err := o.validateCapture(); err == nil || !strings.Contains(err.Error(), "is not empty") {
+		t.Errorf("expected the bundle directory to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package debug
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc/pkg/helpers/conditions"
+)
+
+// validateEphemeral rejects options that only make sense when debugging a copy of a pod or a
+// node.
+func (o *DebugOptions) validateEphemeral() error {
+	switch {
+	case o.Chroot:
+		return fmt.Errorf("--chroot may not be used with --ephemeral, it may only be used when debugging a node")
+	case len(o.ToNamespace) > 0:
+		return fmt.Errorf("--to-namespace may not be used with --ephemeral")
+	case o.NodeNameSet:
+		return fmt.Errorf("--node-name may not be used with --ephemeral")
+	case o.OneContainer:
+		return fmt.Errorf("--one-container may not be used with --ephemeral")
+	case o.keepAlive():
+		return fmt.Errorf("--copy-to, --copy-from and --capture may not be used with --ephemeral")
+	}
+	return nil
+}
+
+// ephemeralContainerForDebug returns an ephemeral debug container targeting
+// the selected container of the pod, so it shares that container's process
+// namespace.
+func (o *DebugOptions) ephemeralContainerForDebug(image string) corev1.EphemeralContainer {
+	container := corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:                     fmt.Sprintf("debugger-%s", utilrand.String(5)),
+			Image:                    image,
+			Command:                  o.getContainerCommand(),
+			Env:                      o.AddEnv,
+			Stdin:                    o.Attach.Stdin,
+			TTY:                      o.Attach.Stdin && o.Attach.TTY,
+			TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		},
+		TargetContainerName: o.ContainerName,
+	}
+
+	switch {
+	case o.AsNonRoot:
+		b := true
+		container.SecurityContext = &corev1.SecurityContext{RunAsNonRoot: &b}
+	case o.AsRoot:
+		zero := int64(0)
+		container.SecurityContext = &corev1.SecurityContext{RunAsUser: &zero}
+	case o.AsUser != -1:
+		container.SecurityContext = &corev1.SecurityContext{RunAsUser: &o.AsUser}
+	}
+	return container
+}
+
+// runEphemeral adds an ephemeral debug container to the running pod and attaches to it.
+func (o *DebugOptions) runEphemeral(source *corev1.Pod) error {
+	pod := source.DeepCopy()
+	o.Attach.Pod = pod
+
+	if len(o.ContainerName) == 0 && len(pod.Spec.Containers) > 0 {
+		o.ContainerName = pod.Spec.Containers[0].Name
+	}
+	if len(o.ContainerName) == 0 {
+		return fmt.Errorf("you must provide a container name to debug")
+	}
+	found := false
+	for _, c := range pod.Spec.Containers {
+		if c.Name == o.ContainerName {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("the container %q is not a valid container name to target with --ephemeral; must be one of %v", o.ContainerName, containerNames(pod))
+	}
+
+	image := o.Image
+	if len(image) == 0 {
+		imageStream := o.ImageStream
+		if len(imageStream) == 0 {
+			imageStream = "openshift/tools:latest"
+		}
+		imageFromStream, err := o.resolveImageStreamTagString(imageStream)
+		if err != nil {
+			return fmt.Errorf("unable to resolve a debug image from image stream %s, use --image to set one: %v", imageStream, err)
+		}
+		image = imageFromStream
+	}
+
+	debugContainer := o.ephemeralContainerForDebug(image)
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, debugContainer)
+
+	if o.Printer != nil {
+		return o.Printer.PrintObj(pod, o.Out)
+	}
+	if o.DryRun {
+		return nil
+	}
+
+	klog.V(5).Infof("Adding ephemeral container: %#v", debugContainer)
+	pod, err := o.CoreClient.Pods(pod.Namespace).UpdateEphemeralContainers(context.TODO(), pod.Name, pod, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to add an ephemeral debug container to pod/%s: %v", source.Name, err)
+	}
+	o.Attach.Pod = pod
+
+	if !o.Quiet {
+		fmt.Fprintf(o.ErrOut, "Starting ephemeral container %s in pod/%s, targeting container %s ...\n", debugContainer.Name, pod.Name, o.ContainerName)
+		fmt.Fprintf(o.ErrOut, "Ephemeral containers cannot be removed, %s will remain in the pod spec until the pod is deleted.\n", debugContainer.Name)
+	}
+
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", pod.Name).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return o.CoreClient.Pods(pod.Namespace).List(context.TODO(), options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return o.CoreClient.Pods(pod.Namespace).Watch(context.TODO(), options)
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.Timeout)
+	defer cancel()
+	_, err = watchtools.UntilWithSync(ctx, lw, &corev1.Pod{}, nil, conditions.PodEphemeralContainerRunning(debugContainer.Name))
+
+	o.ContainerName = debugContainer.Name
+	switch {
+	case err == conditions.ErrContainerTerminated:
+		return o.getLogs(pod)
+	case err == conditions.ErrNonZeroExitCode:
+		if err = o.getLogs(pod); err != nil {
+			return err
+		}
+		return conditions.ErrNonZeroExitCode
+	case err != nil:
+		return err
+	case !o.Attach.Stdin:
+		return o.getLogs(pod)
+	default:
+		o.Attach.ContainerName = debugContainer.Name
+		return o.Attach.Run()
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -13,9 +14,11 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/scheme"
@@ -35,6 +38,11 @@ var (
 
 		You can limit which keys are extracted with the --keys=NAME flag, or set the directory to extract to
 		with --to=DIRECTORY.
+
+		With --from-dir=DIRECTORY, the command works the other way around and creates or updates the secret or
+		config map from the files in the directory, each file becoming the key with the same name. Other keys
+		are kept unless --prune is passed. Creating the object, or changing or removing existing keys, requires
+		--confirm, and --dry-run prints the resulting object instead.
 	`)
 
 	extractExample = templates.Examples(`
@@ -49,6 +57,15 @@ var (
 
 		# Extract only the key "nginx.conf" from config map "nginx" to the /tmp directory
 		oc extract configmap/nginx --to=/tmp --keys=nginx.conf
+
+		# Update the key "nginx.conf" of config map "nginx" from the file edited in the /tmp directory
+		oc extract configmap/nginx --from-dir=/tmp --keys=nginx.conf --confirm
+
+		# Make the secret "test" contain exactly the files of the ./test directory, creating it if needed
+		oc extract secret/test --from-dir=./test --prune --confirm
+
+		# Print the secret "test" that would be created or updated from the ./test directory
+		oc extract secret/test --from-dir=./test --dry-run
 	`)
 )
 
@@ -58,6 +75,15 @@ type ExtractOptions struct {
 	TargetDirectory string
 	Overwrite       bool
 
+	// FromDirectory is the directory of files to create or update the secret or
+	// config map from, instead of extracting them
+	FromDirectory string
+	Prune         bool
+	DryRun        bool
+	KubeClient    kubernetes.Interface
+	fromResource  schema.GroupVersionResource
+	fromName      string
+
 	Namespace         string
 	ExplicitNamespace bool
 	Resources         []string
@@ -79,7 +105,7 @@ func NewCmdExtract(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobr
 	o := NewExtractOptions(".", streams)
 
 	cmd := &cobra.Command{
-		Use:     "extract RESOURCE/NAME [--to=DIRECTORY] [--keys=KEY ...]",
+		Use:     "extract RESOURCE/NAME [--to=DIRECTORY | --from-dir=DIRECTORY] [--keys=KEY ...]",
 		Short:   "Extract secrets or config maps to disk",
 		Long:    extractLong,
 		Example: extractExample,
@@ -94,6 +120,9 @@ func NewCmdExtract(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobr
 	cmd.Flags().StringSliceVarP(&o.Filenames, "filename", "f", o.Filenames, "Filename, directory, or URL to file to identify to extract the resource.")
 	cmd.MarkFlagFilename("filename")
 	cmd.Flags().StringSliceVar(&o.OnlyKeys, "keys", o.OnlyKeys, "An optional list of keys to extract (default is all keys).")
+	cmd.Flags().StringVar(&o.FromDirectory, "from-dir", o.FromDirectory, "Directory of files to create or update the secret or config map from, instead of extracting it.")
+	cmd.Flags().BoolVar(&o.Prune, "prune", o.Prune, "If true, remove the keys that have no file in the --from-dir directory.")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun, "If true, print the secret or config map that --from-dir would create or update without sending it.")
 	return cmd
 }
 
@@ -106,6 +135,27 @@ func (o *ExtractOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args [
 		return err
 	}
 
+	if len(o.FromDirectory) > 0 {
+		if cmd.Flags().Changed("to") || len(o.Filenames) > 0 {
+			return fmt.Errorf("--from-dir cannot be used with --to or --filename")
+		}
+		if len(args) != 1 || !strings.Contains(args[0], "/") {
+			return fmt.Errorf("--from-dir requires a single secret or config map as RESOURCE/NAME")
+		}
+		resourceType, resourceName, _ := strings.Cut(args[0], "/")
+		mapper, err := f.ToRESTMapper()
+		if err != nil {
+			return err
+		}
+		o.fromResource, err = mapper.ResourceFor(schema.GroupVersionResource{Resource: resourceType})
+		if err != nil {
+			return err
+		}
+		o.fromName = resourceName
+		o.KubeClient, err = f.KubernetesClientSet()
+		return err
+	}
+
 	if o.TargetDirectory != "-" {
 		if _, err := os.Stat(o.TargetDirectory); err != nil {
 			if !os.IsNotExist(err) {
@@ -129,6 +179,18 @@ func (o *ExtractOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args [
 }
 
 func (o *ExtractOptions) Validate() error {
+	if len(o.FromDirectory) == 0 {
+		if o.Prune || o.DryRun {
+			return fmt.Errorf("--prune and --dry-run may only be used with --from-dir")
+		}
+		return nil
+	}
+	if o.fromResource.Group != "" || (o.fromResource.Resource != "secrets" && o.fromResource.Resource != "configmaps") {
+		return fmt.Errorf("--from-dir only supports secrets and config maps, not %s", o.fromResource.Resource)
+	}
+	if len(o.fromName) == 0 {
+		return fmt.Errorf("a name is required for --from-dir")
+	}
 	return nil
 }
 
@@ -137,6 +199,10 @@ func name(info *resource.Info) string {
 }
 
 func (o *ExtractOptions) Run() error {
+	if len(o.FromDirectory) > 0 {
+		return o.RunFromDirectory()
+	}
+
 	r := o.Builder().
 		WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...).
 		NamespaceParam(o.Namespace).DefaultNamespace().
@@ -0,0 +1,247 @@
+package extract
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/klog/v2"
+)
+
+// readDirectory returns the contents of the regular files in dir by name, which
+// are the keys of the secret or config map.
+func readDirectory(dir string, onlyKeys sets.String) (map[string][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	contents := make(map[string][]byte)
+	for _, entry := range entries {
+		key := entry.Name()
+		if onlyKeys.Len() > 0 && !onlyKeys.Has(key) {
+			continue
+		}
+		path := filepath.Join(dir, key)
+		// follow symlinks, like the files of a mounted secret or config map
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.Mode().IsRegular() {
+			klog.V(4).Infof("Ignoring %s, it is not a regular file", path)
+			continue
+		}
+		if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+			return nil, fmt.Errorf("%s is not a valid key name: %s", key, strings.Join(errs, ","))
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		contents[key] = data
+	}
+	return contents, nil
+}
+
+// keyChanges are the keys added, changed and removed by updating an object from
+// a directory.
+type keyChanges struct {
+	added, changed, removed []string
+}
+
+func (c keyChanges) empty() bool {
+	return len(c.added) == 0 && len(c.changed) == 0 && len(c.removed) == 0
+}
+
+func (c keyChanges) String() string {
+	var parts []string
+	for _, change := range []struct {
+		name string
+		keys []string
+	}{{"added", c.added}, {"changed", c.changed}, {"removed", c.removed}} {
+		if len(change.keys) > 0 {
+			parts = append(parts, fmt.Sprintf("%s %s", change.name, strings.Join(change.keys, ", ")))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// diffKeys compares the existing keys with the contents of the directory. Keys
+// missing from the directory are only removed when pruning, and only among
+// onlyKeys if it is set.
+func diffKeys(existing, contents map[string][]byte, prune bool, onlyKeys sets.String) keyChanges {
+	changes := keyChanges{}
+	for key, data := range contents {
+		current, ok := existing[key]
+		switch {
+		case !ok:
+			changes.added = append(changes.added, key)
+		case !bytes.Equal(current, data):
+			changes.changed = append(changes.changed, key)
+		}
+	}
+	if prune {
+		for key := range existing {
+			if _, ok := contents[key]; !ok && (onlyKeys.Len() == 0 || onlyKeys.Has(key)) {
+				changes.removed = append(changes.removed, key)
+			}
+		}
+	}
+	sort.Strings(changes.added)
+	sort.Strings(changes.changed)
+	sort.Strings(changes.removed)
+	return changes
+}
+
+// setConfigMapKey stores text in data and anything else in binaryData, like
+// 'oc create configmap --from-file' does.
+func setConfigMapKey(cm *corev1.ConfigMap, key string, data []byte) {
+	if utf8.Valid(data) {
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[key] = string(data)
+		delete(cm.BinaryData, key)
+		return
+	}
+	if cm.BinaryData == nil {
+		cm.BinaryData = map[string][]byte{}
+	}
+	cm.BinaryData[key] = data
+	delete(cm.Data, key)
+}
+
+// RunFromDirectory creates or updates the secret or config map from the files in
+// FromDirectory. Keys that would be changed or removed, and an object that does
+// not exist yet, require --confirm, mirroring the files that extracting would
+// overwrite.
+func (o *ExtractOptions) RunFromDirectory() error {
+	onlyKeys := sets.NewString(o.OnlyKeys...)
+	contents, err := readDirectory(o.FromDirectory, onlyKeys)
+	if err != nil {
+		return err
+	}
+	if len(contents) == 0 {
+		return fmt.Errorf("no files to read from %s", o.FromDirectory)
+	}
+
+	var obj runtime.Object
+	var existing map[string][]byte
+	found := true
+	switch o.fromResource.Resource {
+	case "secrets":
+		secret, err := o.KubeClient.CoreV1().Secrets(o.Namespace).Get(context.TODO(), o.fromName, metav1.GetOptions{})
+		switch {
+		case kapierrors.IsNotFound(err):
+			found = false
+			secret = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: o.fromName, Namespace: o.Namespace}, Type: corev1.SecretTypeOpaque}
+		case err != nil:
+			return err
+		}
+		existing = secret.Data
+		obj = secret
+	case "configmaps":
+		cm, err := o.KubeClient.CoreV1().ConfigMaps(o.Namespace).Get(context.TODO(), o.fromName, metav1.GetOptions{})
+		switch {
+		case kapierrors.IsNotFound(err):
+			found = false
+			cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: o.fromName, Namespace: o.Namespace}}
+		case err != nil:
+			return err
+		}
+		existing, _, _ = extractFileContents(cm)
+		obj = cm
+	default:
+		return fmt.Errorf("--from-dir only supports secrets and config maps, not %s", o.fromResource.Resource)
+	}
+
+	changes := diffKeys(existing, contents, o.Prune, onlyKeys)
+	resourceName := fmt.Sprintf("%s/%s", o.fromResource.Resource, o.fromName)
+	if found && changes.empty() && !o.DryRun {
+		fmt.Fprintf(o.Out, "%s unchanged\n", resourceName)
+		return nil
+	}
+	if !o.Overwrite && !o.DryRun {
+		if !found {
+			return fmt.Errorf("%s not found, pass --confirm to create it", resourceName)
+		}
+		if len(changes.changed) > 0 || len(changes.removed) > 0 {
+			return fmt.Errorf("%s: keys would be overwritten or removed (%s), pass --confirm to update them", resourceName, changes)
+		}
+	}
+
+	switch t := obj.(type) {
+	case *corev1.Secret:
+		if t.Data == nil {
+			t.Data = map[string][]byte{}
+		}
+		for key, data := range contents {
+			t.Data[key] = data
+		}
+		for _, key := range changes.removed {
+			delete(t.Data, key)
+		}
+	case *corev1.ConfigMap:
+		for key, data := range contents {
+			setConfigMapKey(t, key, data)
+		}
+		for _, key := range changes.removed {
+			delete(t.Data, key)
+			delete(t.BinaryData, key)
+		}
+	}
+
+	if o.DryRun {
+		return o.printObject(obj)
+	}
+
+	switch t := obj.(type) {
+	case *corev1.Secret:
+		if found {
+			_, err = o.KubeClient.CoreV1().Secrets(o.Namespace).Update(context.TODO(), t, metav1.UpdateOptions{})
+		} else {
+			_, err = o.KubeClient.CoreV1().Secrets(o.Namespace).Create(context.TODO(), t, metav1.CreateOptions{})
+		}
+	case *corev1.ConfigMap:
+		if found {
+			_, err = o.KubeClient.CoreV1().ConfigMaps(o.Namespace).Update(context.TODO(), t, metav1.UpdateOptions{})
+		} else {
+			_, err = o.KubeClient.CoreV1().ConfigMaps(o.Namespace).Create(context.TODO(), t, metav1.CreateOptions{})
+		}
+	}
+	if err != nil {
+		return err
+	}
+	operation := "updated"
+	if !found {
+		operation = "created"
+	}
+	if changes.empty() {
+		fmt.Fprintf(o.Out, "%s %s\n", resourceName, operation)
+	} else {
+		fmt.Fprintf(o.Out, "%s %s (%s)\n", resourceName, operation, changes)
+	}
+	return nil
+}
+
+// printObject writes the object that would be sent to the server as YAML.
+func (o *ExtractOptions) printObject(obj runtime.Object) error {
+	kind := "Secret"
+	if o.fromResource.Resource == "configmaps" {
+		kind = "ConfigMap"
+	}
+	obj.GetObjectKind().SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind(kind))
+	return (&printers.YAMLPrinter{}).PrintObj(obj, o.Out)
+}
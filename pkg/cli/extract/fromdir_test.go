@@ -0,0 +1,273 @@
+package extract
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestDiffKeys(t *testing.T) {
+	existing := map[string][]byte{"a": []byte("1"), "b": []byte("2"), "c": []byte("3")}
+	contents := map[string][]byte{"a": []byte("1"), "b": []byte("changed"), "d": []byte("4")}
+	tests := []struct {
+		name     string
+		prune    bool
+		onlyKeys sets.String
+		expected keyChanges
+	}{
+		{
+			name:     "without prune",
+			expected: keyChanges{added: []string{"d"}, changed: []string{"b"}},
+		},
+		{
+			name:     "prune",
+			prune:    true,
+			expected: keyChanges{added: []string{"d"}, changed: []string{"b"}, removed: []string{"c"}},
+		},
+		{
+			name:     "prune only keys",
+			prune:    true,
+			onlyKeys: sets.NewString("a", "b", "d"),
+			expected: keyChanges{added: []string{"d"}, changed: []string{"b"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changes := diffKeys(existing, contents, test.prune, test.onlyKeys)
+			if !reflect.DeepEqual(changes, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, changes)
+			}
+		})
+	}
+
+	if changes := diffKeys(existing, existing, true, nil); !changes.empty() {
+		t.Errorf("expected no changes, got %s", changes)
+	}
+}
+
+func TestReadDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"a": "1", "b.conf": "2"})
+	if err := os.Mkdir(filepath.Join(dir, "subdir"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	contents, err := readDirectory(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(contents, map[string][]byte{"a": []byte("1"), "b.conf": []byte("2")}) {
+		t.Errorf("unexpected contents: %v", contents)
+	}
+
+	contents, err = readDirectory(dir, sets.NewString("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(contents, map[string][]byte{"a": []byte("1")}) {
+		t.Errorf("unexpected contents: %v", contents)
+	}
+
+	writeFiles(t, dir, map[string]string{"invalid key": "3"})
+	if _, err := readDirectory(dir, nil); err == nil || !strings.Contains(err.Error(), "invalid key is not a valid key name") {
+		t.Errorf("expected the invalid key to be rejected, got %v", err)
+	}
+}
+
+func TestRunFromDirectory(t *testing.T) {
+	secret := func(data map[string]string) *corev1.Secret {
+		s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "ns"}, Type: corev1.SecretTypeOpaque, Data: map[string][]byte{}}
+		for k, v := range data {
+			s.Data[k] = []byte(v)
+		}
+		return s
+	}
+	tests := []struct {
+		name          string
+		resource      string
+		existing      []runtime.Object
+		files         map[string]string
+		onlyKeys      []string
+		confirm       bool
+		prune         bool
+		dryRun        bool
+		expectedError string
+		expectedVerb  string
+		expected      runtime.Object
+		expectedOut   string
+	}{
+		{
+			name:          "create requires confirm",
+			resource:      "secrets",
+			files:         map[string]string{"a": "1"},
+			expectedError: "secrets/test not found, pass --confirm to create it",
+		},
+		{
+			name:         "create",
+			resource:     "secrets",
+			files:        map[string]string{"a": "1"},
+			confirm:      true,
+			expectedVerb: "create",
+			expected:     secret(map[string]string{"a": "1"}),
+			expectedOut:  "secrets/test created (added a)\n",
+		},
+		{
+			name:         "add keys without confirm",
+			resource:     "secrets",
+			existing:     []runtime.Object{secret(map[string]string{"a": "1"})},
+			files:        map[string]string{"a": "1", "b": "2"},
+			expectedVerb: "update",
+			expected:     secret(map[string]string{"a": "1", "b": "2"}),
+			expectedOut:  "secrets/test updated (added b)\n",
+		},
+		{
+			name:          "change requires confirm",
+			resource:      "secrets",
+			existing:      []runtime.Object{secret(map[string]string{"a": "1"})},
+			files:         map[string]string{"a": "2"},
+			expectedError: "keys would be overwritten or removed (changed a), pass --confirm to update them",
+		},
+		{
+			name:          "prune requires confirm",
+			resource:      "secrets",
+			existing:      []runtime.Object{secret(map[string]string{"a": "1", "b": "2"})},
+			files:         map[string]string{"a": "1"},
+			prune:         true,
+			expectedError: "keys would be overwritten or removed (removed b), pass --confirm to update them",
+		},
+		{
+			name:         "prune",
+			resource:     "secrets",
+			existing:     []runtime.Object{secret(map[string]string{"a": "1", "b": "2", "c": "3"})},
+			files:        map[string]string{"a": "changed"},
+			confirm:      true,
+			prune:        true,
+			expectedVerb: "update",
+			expected:     secret(map[string]string{"a": "changed"}),
+			expectedOut:  "secrets/test updated (changed a; removed b, c)\n",
+		},
+		{
+			name:         "prune only keys",
+			resource:     "secrets",
+			existing:     []runtime.Object{secret(map[string]string{"a": "1", "b": "2", "c": "3"})},
+			files:        map[string]string{"a": "1", "c": "3"},
+			onlyKeys:     []string{"a", "b"},
+			confirm:      true,
+			prune:        true,
+			expectedVerb: "update",
+			expected:     secret(map[string]string{"a": "1", "c": "3"}),
+			expectedOut:  "secrets/test updated (removed b)\n",
+		},
+		{
+			name:        "unchanged",
+			resource:    "secrets",
+			existing:    []runtime.Object{secret(map[string]string{"a": "1"})},
+			files:       map[string]string{"a": "1"},
+			prune:       true,
+			expectedOut: "secrets/test unchanged\n",
+		},
+		{
+			name:        "dry run",
+			resource:    "secrets",
+			existing:    []runtime.Object{secret(map[string]string{"a": "1"})},
+			files:       map[string]string{"a": "2"},
+			dryRun:      true,
+			expectedOut: "a: Mg==",
+		},
+		{
+			name:     "config map",
+			resource: "configmaps",
+			existing: []runtime.Object{&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "ns"},
+				Data:       map[string]string{"a": "1", "b": "2"},
+				BinaryData: map[string][]byte{"c": {0xff}},
+			}},
+			files:        map[string]string{"a": "\xff", "c": "3"},
+			confirm:      true,
+			prune:        true,
+			expectedVerb: "update",
+			expected: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "ns"},
+				Data:       map[string]string{"c": "3"},
+				BinaryData: map[string][]byte{"a": {0xff}},
+			},
+			expectedOut: "configmaps/test updated (changed a, c; removed b)\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, test.files)
+			client := fake.NewSimpleClientset(test.existing...)
+			out := &bytes.Buffer{}
+			o := &ExtractOptions{
+				FromDirectory: dir,
+				OnlyKeys:      test.onlyKeys,
+				Overwrite:     test.confirm,
+				Prune:         test.prune,
+				DryRun:        test.dryRun,
+				KubeClient:    client,
+				Namespace:     "ns",
+				fromResource:  schema.GroupVersionResource{Version: "v1", Resource: test.resource},
+				fromName:      "test",
+				IOStreams:     genericiooptions.IOStreams{Out: out, ErrOut: out},
+			}
+			err := o.RunFromDirectory()
+			if len(test.expectedError) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+					t.Fatalf("expected error %q, got %v", test.expectedError, err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out.String(), test.expectedOut) {
+				t.Errorf("expected output %q, got %q", test.expectedOut, out.String())
+			}
+
+			var writes []clienttesting.Action
+			for _, action := range client.Actions() {
+				if action.GetVerb() != "get" {
+					writes = append(writes, action)
+				}
+			}
+			if len(test.expectedVerb) == 0 {
+				if len(writes) > 0 {
+					t.Fatalf("expected no writes, got %v", writes)
+				}
+				return
+			}
+			if len(writes) != 1 || writes[0].GetVerb() != test.expectedVerb {
+				t.Fatalf("expected a single %s, got %v", test.expectedVerb, writes)
+			}
+			var obj runtime.Object
+			switch action := writes[0].(type) {
+			case clienttesting.CreateAction:
+				obj = action.GetObject()
+			case clienttesting.UpdateAction:
+				obj = action.GetObject()
+			}
+			if !reflect.DeepEqual(obj, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, obj)
+			}
+		})
+	}
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -24,7 +24,8 @@ func NewWriter(iostreams genericiooptions.IOStreams) *Writer {
 	}
 }
 
-// Write writes the ExecCredential to standard output for oc.
+// Write writes the ExecCredential to standard output for oc. A zero
+// expiry is omitted, so that the token is used until it is rejected.
 func (w *Writer) Write(token string, expiry time.Time) error {
 	ec := &v1.ExecCredential{
 		TypeMeta: metav1.TypeMeta{
@@ -32,10 +33,12 @@ func (w *Writer) Write(token string, expiry time.Time) error {
 			Kind:       "ExecCredential",
 		},
 		Status: &v1.ExecCredentialStatus{
-			Token:               token,
-			ExpirationTimestamp: &metav1.Time{Time: expiry},
+			Token: token,
 		},
 	}
+	if !expiry.IsZero() {
+		ec.Status.ExpirationTimestamp = &metav1.Time{Time: expiry}
+	}
 	e := json.NewEncoder(w.out)
 	if err := e.Encode(ec); err != nil {
 		return fmt.Errorf("could not write the ExecCredential: %w", err)
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	gooidc "github.com/coreos/go-oidc/v3/oidc"
@@ -23,6 +24,7 @@ import (
 	"github.com/openshift/oc/pkg/cli/gettoken/credwriter"
 	"github.com/openshift/oc/pkg/cli/gettoken/oidc"
 	"github.com/openshift/oc/pkg/cli/gettoken/tokencache"
+	"github.com/openshift/oc/pkg/helpers/keyring"
 )
 
 var (
@@ -35,6 +37,9 @@ var (
 	successfully completed and once ID token expires, command tries to get the
 	new token by using the refresh token flow. Although it is optional, command
 	also supports getting client secret to behave as an confidential client.
+
+	With --token-store=keyring, get-token instead returns the bearer token that
+	'oc login --token-store=keyring' stored in the keychain of the operating system.
 `)
 	getTokenExample = templates.Examples(`
 	# Starts an auth code flow to the issuer URL with the client ID and the given extra scopes
@@ -56,8 +61,11 @@ type GetTokenOptions struct {
 	CACertFilename  string
 	InsecureTLS     bool
 	AutoOpenBrowser bool
+	TokenStore      string
+	KeyringKey      string
 
 	authenticator         oidc.Authenticator
+	keyring               *keyring.Store
 	tokenCache            *tokencache.Repository
 	credWriter            *credwriter.Writer
 	tokenCacheDir         string
@@ -70,6 +78,7 @@ func NewGetTokenOptions(streams genericiooptions.IOStreams) *GetTokenOptions {
 	return &GetTokenOptions{
 		IOStreams:             streams,
 		CallbackAdress:        defaultCallbackAddress,
+		TokenStore:            keyring.TokenStoreKubeconfig,
 		authenticationTimeout: 5 * time.Minute,
 	}
 }
@@ -95,6 +104,8 @@ func NewCmdGetToken(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cob
 	cmd.Flags().StringSliceVar(&o.ExtraScopes, "extra-scopes", o.ExtraScopes, "Extra scopes for the auth request to the external OIDC provider. Optional.")
 	cmd.Flags().StringVar(&o.CallbackAdress, "callback-address", o.CallbackAdress, "Callback address where external OIDC issuer redirects to after flow is completed. Defaults to 127.0.0.1:0 to pick a random port.")
 	cmd.Flags().BoolVar(&o.AutoOpenBrowser, "auto-open-browser", o.AutoOpenBrowser, "Specify browser is automatically opened or not.")
+	cmd.Flags().StringVar(&o.TokenStore, "token-store", o.TokenStore, "Where the token is stored: "+strings.Join(keyring.TokenStores, ", ")+". With 'keyring', the token stored under --keyring-key in the keychain of the operating system is returned.")
+	cmd.Flags().StringVar(&o.KeyringKey, "keyring-key", o.KeyringKey, "The key of the token stored in the keychain of the operating system with --token-store=keyring.")
 
 	return cmd
 }
@@ -102,6 +113,12 @@ func NewCmdGetToken(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cob
 func (o *GetTokenOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
 	o.CACertFilename = kcmdutil.GetFlagString(cmd, "certificate-authority")
 	o.InsecureTLS = kcmdutil.GetFlagBool(cmd, "insecure-skip-tls-verify")
+	o.credWriter = credwriter.NewWriter(o.IOStreams)
+
+	if o.TokenStore == keyring.TokenStoreKeyring {
+		o.keyring = keyring.NewStore(keyring.DefaultHelper())
+		return nil
+	}
 
 	provider := &oidc.Provider{
 		IssuerURL:    o.IssuerURL,
@@ -118,7 +135,6 @@ func (o *GetTokenOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args
 
 	o.authenticator = authenticator
 	o.tokenCache = &tokencache.Repository{}
-	o.credWriter = credwriter.NewWriter(o.IOStreams)
 
 	o.tokenCacheDir = filepath.Join(homedir.HomeDir(), ".kube", "cache", "oc")
 	if kcd := os.Getenv("KUBECACHEDIR"); kcd != "" {
@@ -129,6 +145,20 @@ func (o *GetTokenOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args
 }
 
 func (o *GetTokenOptions) Validate() error {
+	switch o.TokenStore {
+	case keyring.TokenStoreKubeconfig:
+		if len(o.KeyringKey) > 0 {
+			return fmt.Errorf("--keyring-key may only be used with --token-store=%s", keyring.TokenStoreKeyring)
+		}
+	case keyring.TokenStoreKeyring:
+		if len(o.KeyringKey) == 0 {
+			return fmt.Errorf("--keyring-key is required with --token-store=%s", keyring.TokenStoreKeyring)
+		}
+		return nil
+	default:
+		return fmt.Errorf("--token-store must be one of %s", strings.Join(keyring.TokenStores, ", "))
+	}
+
 	if o.IssuerURL == "" {
 		return fmt.Errorf("--issuer-url is required")
 	}
@@ -143,6 +173,18 @@ func (o *GetTokenOptions) Validate() error {
 // If refresh token is found, it tries to use it to get a valid id token from
 // external OIDC issuer. If not, it forces user to log in.
 func (o *GetTokenOptions) Run() error {
+	if o.keyring != nil {
+		token, err := o.keyring.Get(o.KeyringKey)
+		if err != nil {
+			return err
+		}
+		// the token is used until the server rejects it
+		if err := o.credWriter.Write(token, time.Time{}); err != nil {
+			return fmt.Errorf("failed to write the token to client-go: %w", err)
+		}
+		return nil
+	}
+
 	tokenCacheKey := tokencache.Key{
 		IssuerURL: o.IssuerURL,
 		ClientID:  o.ClientID,
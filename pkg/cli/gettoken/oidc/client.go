@@ -16,10 +16,11 @@ import (
 	"golang.org/x/oauth2"
 )
 
-// Authenticator defines the basic functionality to support Auth Code Flow
-// and Token Refresh Grant Flow.
+// Authenticator defines the basic functionality to support Auth Code Flow,
+// Device Authorization Grant Flow and Token Refresh Grant Flow.
 type Authenticator interface {
 	GetTokenByAuthCode(ctx context.Context, callbackAddress string, localServerReadyChan chan<- string) (string, string, time.Time, error)
+	GetTokenByDeviceCode(ctx context.Context, prompt func(verificationURI, userCode string)) (string, string, time.Time, error)
 	Refresh(ctx context.Context, refreshToken string) (string, string, time.Time, error)
 	VerifyToken(ctx context.Context, token *oauth2.Token, nonce string) (string, time.Time, error)
 }
@@ -71,6 +72,35 @@ func (c *client) GetTokenByAuthCode(ctx context.Context, callbackAddress string,
 	return idToken, token.RefreshToken, expiry, err
 }
 
+// GetTokenByDeviceCode does the device authorization grant flow. The user is asked
+// through prompt to enter the user code at the verification URI, on any device,
+// while the token endpoint is polled until the user has logged in.
+func (c *client) GetTokenByDeviceCode(ctx context.Context, prompt func(verificationURI, userCode string)) (string, string, time.Time, error) {
+	if len(c.oauth2Config.Endpoint.DeviceAuthURL) == 0 {
+		return "", "", time.Time{}, fmt.Errorf("the issuer does not support the device authorization grant")
+	}
+	if c.httpClient != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, c.httpClient)
+	}
+
+	response, err := c.oauth2Config.DeviceAuth(ctx)
+	if err != nil {
+		return "", "", time.Time{}, fmt.Errorf("device authorization error: %w", err)
+	}
+	verificationURI := response.VerificationURIComplete
+	if len(verificationURI) == 0 {
+		verificationURI = response.VerificationURI
+	}
+	prompt(verificationURI, response.UserCode)
+
+	token, err := c.oauth2Config.DeviceAccessToken(ctx, response)
+	if err != nil {
+		return "", "", time.Time{}, fmt.Errorf("oauth2 error: %w", err)
+	}
+	idToken, expiry, err := c.VerifyToken(ctx, token, "")
+	return idToken, token.RefreshToken, expiry, err
+}
+
 func RandomString(length int) (string, error) {
 	bytes := make([]byte, length)
 	_, err := rand.Read(bytes)
@@ -86,6 +86,22 @@ func (r *Repository) Save(dir string, key Key, tokenSet Set) error {
 	return nil
 }
 
+// Remove removes the tokens of the key from the given directory, if any,
+// and returns whether there were tokens to remove
+func (r *Repository) Remove(dir string, key Key) (bool, error) {
+	filename, err := computeFilename(key)
+	if err != nil {
+		return false, fmt.Errorf("could not compute the key: %w", err)
+	}
+	if err := os.Remove(filepath.Join(dir, filename)); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
 func computeFilename(key Key) (string, error) {
 	s := sha256.New()
 	e := gob.NewEncoder(s)
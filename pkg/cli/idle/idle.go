@@ -50,12 +50,22 @@ var (
 		are scaled down to zero replicas.
 
 		Upon receiving network traffic, the services (and any associated routes) will "wake up" the
-		associated resources by scaling them back up to their previous scale.
+		associated resources by scaling them back up to their previous scale. Pass --wake to scale
+		the resources of idled services back up right away, without sending them traffic.
+
+		Pass --dry-run to print the services that would be marked as idled and the resources that
+		would be scaled down, and --output=json to print the result as JSON for automation.
 	`)
 
 	idleExample = templates.Examples(`
 		# Idle the scalable controllers associated with the services listed in to-idle.txt
 		$ oc idle --resource-names-file to-idle.txt
+
+		# Print the resources that idling the frontend service would scale down, as JSON
+		$ oc idle frontend --dry-run -o json
+
+		# Scale the resources of the idled frontend service back up without sending it traffic
+		$ oc idle --wake frontend
 	`)
 )
 
@@ -66,6 +76,8 @@ type IdleOptions struct {
 	selector      string
 	allNamespaces bool
 	resources     []string
+	wake          bool
+	output        string
 
 	ClientForMappingFn func(*meta.RESTMapping) (resource.RESTClient, error)
 	ClientConfig       *rest.Config
@@ -95,13 +107,18 @@ func NewCmdIdle(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.C
 	validArgs := []string{"deploymentconfig", "replicationcontroller"}
 
 	cmd := &cobra.Command{
-		Use:               "idle (SERVICE_ENDPOINTS... | -l label | --all | --resource-names-file FILENAME)",
+		Use:               "idle [--wake] (SERVICE_ENDPOINTS... | -l label | --all | --resource-names-file FILENAME)",
 		Short:             "Idle scalable resources",
 		Long:              idleLong,
 		Example:           idleExample,
 		ValidArgsFunction: completion.SpecifiedResourceTypeAndNameCompletionFunc(f, validArgs),
 		Run: func(cmd *cobra.Command, args []string) {
 			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			if o.wake {
+				kcmdutil.CheckErr(o.RunWake())
+				return
+			}
 			kcmdutil.CheckErr(o.RunIdle())
 		},
 	}
@@ -111,10 +128,10 @@ func NewCmdIdle(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.C
 	cmd.Flags().StringVarP(&o.selector, "selector", "l", o.selector, "Selector (label query) to use to select services")
 	cmd.Flags().BoolVar(&o.all, "all", o.all, "if true, select all services in the namespace")
 	cmd.Flags().BoolVarP(&o.allNamespaces, "all-namespaces", "A", o.allNamespaces, "if true, select services across all namespaces")
+	cmd.Flags().BoolVar(&o.wake, "wake", o.wake, "If true, scale the resources of the idled services back up to their previous scale and mark the services as no longer idled")
+	cmd.Flags().StringVarP(&o.output, "output", "o", o.output, "Output format. The only supported value is 'json', printing the services and the resources idled or woken up.")
 	cmd.MarkFlagFilename("resource-names-file")
 
-	// TODO: take the `-o name` argument, and only print out names instead of the summary
-
 	return cmd
 }
 
@@ -170,6 +187,13 @@ func (o *IdleOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []st
 	return nil
 }
 
+func (o *IdleOptions) Validate() error {
+	if o.output != "" && o.output != "json" {
+		return fmt.Errorf("unsupported output format %q, the only supported value is 'json'", o.output)
+	}
+	return nil
+}
+
 // scaleClient gives you back scale getter
 func scaleClient(restClientGetter genericclioptions.RESTClientGetter) (scale.ScalesGetter, error) {
 	discoveryClient, err := restClientGetter.ToDiscoveryClient()
@@ -568,19 +592,9 @@ type scaleInfo struct {
 	obj       runtime.Object
 }
 
-// RunIdle runs the idling command logic, taking a list of resources or services in a file, scaling the associated
-// scalable resources to zero, and annotating the associated endpoints objects with the scalable resources to unidle
-// when they receive traffic.
-func (o *IdleOptions) RunIdle() error {
-	clusterNetwork, err := o.OperatorClient.OperatorV1().Networks().Get(context.TODO(), "cluster", metav1.GetOptions{})
-	if err == nil {
-		sdnType := clusterNetwork.Spec.DefaultNetwork.Type
-
-		if sdnType == operatorv1.NetworkTypeOpenShiftSDN {
-			fmt.Fprintln(o.ErrOut, "WARNING: idling when network policies are in place may cause connections to bypass network policy entirely")
-		}
-	}
-
+// builder returns a builder visiting the objects of the given resource type,
+// endpoints or services, of the services selected by the arguments and flags.
+func (o *IdleOptions) builder(resourceType string) (*resource.Builder, error) {
 	b := o.Builder().
 		WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...).
 		ContinueOnError().
@@ -591,22 +605,81 @@ func (o *IdleOptions) RunIdle() error {
 	if len(o.filename) > 0 {
 		targetServiceNames, err := scanLinesFromFile(o.filename)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		b.ResourceNames("endpoints", targetServiceNames...)
+		b.ResourceNames(resourceType, targetServiceNames...)
 	} else {
 		// NB: this is a bit weird because the resource builder will complain if we use ResourceTypes and ResourceNames when len(args) > 0
 		if o.selector != "" {
-			b.LabelSelectorParam(o.selector).ResourceTypes("endpoints")
+			b.LabelSelectorParam(o.selector).ResourceTypes(resourceType)
 		}
 
-		b.ResourceNames("endpoints", o.resources...)
+		b.ResourceNames(resourceType, o.resources...)
 
 		if o.all {
-			b.ResourceTypes("endpoints").SelectAllParam(o.all)
+			b.ResourceTypes(resourceType).SelectAllParam(o.all)
+		}
+	}
+	return b, nil
+}
+
+// patchAnnotations patches obj with the changes mutate makes to its annotations.
+func (o *IdleOptions) patchAnnotations(obj runtime.Object, mutate func(annotations map[string]string) error) error {
+	metadata, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+
+	gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+	if err != nil {
+		return err
+	}
+
+	// we need a versioned obj to properly marshal to JSON, so that we can compute the patch
+	mapping, err := o.Mapper.RESTMapping(gvks[0].GroupKind(), gvks[0].Version)
+	if err != nil {
+		return err
+	}
+
+	oldData, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	clientForMapping, err := o.ClientForMappingFn(mapping)
+	if err != nil {
+		return err
+	}
+	annotations := metadata.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	if err := mutate(annotations); err != nil {
+		return err
+	}
+	metadata.SetAnnotations(annotations)
+	_, err = patchObj(obj, metadata, oldData, mapping, clientForMapping)
+	return err
+}
+
+// RunIdle runs the idling command logic, taking a list of resources or services in a file, scaling the associated
+// scalable resources to zero, and annotating the associated endpoints objects with the scalable resources to unidle
+// when they receive traffic.
+func (o *IdleOptions) RunIdle() error {
+	clusterNetwork, err := o.OperatorClient.OperatorV1().Networks().Get(context.TODO(), "cluster", metav1.GetOptions{})
+	if err == nil {
+		sdnType := clusterNetwork.Spec.DefaultNetwork.Type
+
+		if sdnType == operatorv1.NetworkTypeOpenShiftSDN {
+			fmt.Fprintln(o.ErrOut, "WARNING: idling when network policies are in place may cause connections to bypass network policy entirely")
 		}
 	}
 
+	b, err := o.builder("endpoints")
+	if err != nil {
+		return err
+	}
+
 	hadError := false
 	nowTime := time.Now().UTC()
 
@@ -652,47 +725,15 @@ func (o *IdleOptions) RunIdle() error {
 		toScale[scaleRef] = scaleInfo{scale: scale, obj: obj, namespace: svcName.Namespace}
 	}
 
-	patchInfo := func(obj runtime.Object) (metav1.Object, []byte, *meta.RESTMapping, error) {
-		metadata, err := meta.Accessor(obj)
-		if err != nil {
-			return nil, nil, nil, err
-		}
-
-		gvks, _, err := scheme.Scheme.ObjectKinds(obj)
-		if err != nil {
-			return nil, nil, nil, err
-		}
-
-		// we need a versioned obj to properly marshal to JSON, so that we can compute the patch
-		mapping, err := o.Mapper.RESTMapping(gvks[0].GroupKind(), gvks[0].Version)
-		if err != nil {
-			return nil, nil, nil, err
-		}
-
-		oldData, err := json.Marshal(obj)
-		if err != nil {
-			return nil, nil, nil, err
-		}
-
-		return metadata, oldData, mapping, nil
-	}
-
 	patchObjWithIdleAnnotations := func(obj runtime.Object, refsWithScale []unidlingapi.RecordedScaleReference, time time.Time) error {
-		metadata, oldData, mapping, err := patchInfo(obj)
-		if err != nil {
-			return err
-		}
-		clientForMapping, err := o.ClientForMappingFn(mapping)
-		if err != nil {
-			return err
-		}
-		if err = setIdleAnnotations(metadata.GetAnnotations(), refsWithScale, time); err != nil {
-			return err
-		}
-		_, err = patchObj(obj, metadata, oldData, mapping, clientForMapping)
-		return err
+		return o.patchAnnotations(obj, func(annotations map[string]string) error {
+			return setIdleAnnotations(annotations, refsWithScale, time)
+		})
 	}
 
+	result := &idleResult{DryRun: o.dryRun}
+	services := make(map[types.NamespacedName]*serviceResult, len(byService))
+
 	// annotate the endpoints objects to indicate which scalable resources need to be unidled on traffic
 	for serviceName, info := range byService {
 		if info.service.Annotations == nil {
@@ -721,6 +762,11 @@ func (o *IdleOptions) RunIdle() error {
 			}
 		}
 
+		services[serviceName] = result.addService(serviceName, refsWithScale)
+		if len(o.output) > 0 {
+			continue
+		}
+
 		fmt.Fprintf(o.Out, "The service %q has been marked as idled %s\n", serviceName.String(), dryRunText)
 
 		for _, scaleRef := range refsWithScale {
@@ -736,14 +782,23 @@ func (o *IdleOptions) RunIdle() error {
 			scaleUpdater := unidlingclient.NewScaleUpdater(scheme.DefaultJSONEncoder(), info.namespace, o.AppClient.AppsV1(), o.ClientSet.CoreV1())
 			if err := scaleAnnotater.UpdateObjectScale(scaleUpdater, info.namespace, scaleRef.CrossGroupObjectReference, info.obj, info.scale); err != nil {
 				fmt.Fprintf(o.ErrOut, "error: unable to scale %s %s/%s to 0, but still listed as target for unidling: %v\n", scaleRef.Kind, info.namespace, scaleRef.Name, err)
+				services[byScalable[scaleRef]].setTargetError(scaleRef.CrossGroupObjectReference, err)
 				hadError = true
 				continue
 			}
 		}
+		services[byScalable[scaleRef]].setTargetScaled(scaleRef.CrossGroupObjectReference)
 
-		fmt.Fprintf(o.Out, "%s \"%s/%s\" has been idled %s\n", scaleRef.Kind, info.namespace, scaleRef.Name, dryRunText)
+		if len(o.output) == 0 {
+			fmt.Fprintf(o.Out, "%s \"%s/%s\" has been idled %s\n", scaleRef.Kind, info.namespace, scaleRef.Name, dryRunText)
+		}
 	}
 
+	if len(o.output) > 0 {
+		if err := result.print(o.Out); err != nil {
+			return err
+		}
+	}
 	if hadError {
 		return kcmdutil.ErrExit
 	}
@@ -0,0 +1,97 @@
+package idle
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	unidlingapi "github.com/openshift/api/unidling/v1alpha1"
+)
+
+// idleResult is printed with --output=json, listing the services idled or woken
+// up and their scalable resources.
+type idleResult struct {
+	DryRun   bool             `json:"dryRun"`
+	Services []*serviceResult `json:"services"`
+}
+
+type serviceResult struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Targets are the scalable resources of the service, with the replicas they
+	// are scaled back up to when the service is woken up.
+	Targets []*targetResult `json:"targets"`
+}
+
+type targetResult struct {
+	Kind     string `json:"kind"`
+	Group    string `json:"group,omitempty"`
+	Name     string `json:"name"`
+	Replicas int32  `json:"replicas"`
+	// Scaled is true if the resource is, or with --dry-run would be, scaled down
+	// to zero when idling or back up to its replicas when waking up.
+	Scaled bool   `json:"scaled"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (r *idleResult) addService(name types.NamespacedName, refs []unidlingapi.RecordedScaleReference) *serviceResult {
+	svc := &serviceResult{Namespace: name.Namespace, Name: name.Name, Targets: []*targetResult{}}
+	for _, ref := range refs {
+		svc.Targets = append(svc.Targets, &targetResult{Kind: ref.Kind, Group: ref.Group, Name: ref.Name, Replicas: ref.Replicas})
+	}
+	r.Services = append(r.Services, svc)
+	return svc
+}
+
+func (s *serviceResult) target(ref unidlingapi.CrossGroupObjectReference) *targetResult {
+	if s == nil {
+		return nil
+	}
+	for _, target := range s.Targets {
+		if target.Kind == ref.Kind && target.Group == ref.Group && target.Name == ref.Name {
+			return target
+		}
+	}
+	return nil
+}
+
+func (s *serviceResult) setTargetScaled(ref unidlingapi.CrossGroupObjectReference) {
+	if target := s.target(ref); target != nil {
+		target.Scaled = true
+	}
+}
+
+func (s *serviceResult) setTargetError(ref unidlingapi.CrossGroupObjectReference, err error) {
+	if target := s.target(ref); target != nil {
+		target.Error = err.Error()
+	}
+}
+
+func (r *idleResult) print(out io.Writer) error {
+	if r.Services == nil {
+		r.Services = []*serviceResult{}
+	}
+	sort.Slice(r.Services, func(i, j int) bool {
+		if r.Services[i].Namespace != r.Services[j].Namespace {
+			return r.Services[i].Namespace < r.Services[j].Namespace
+		}
+		return r.Services[i].Name < r.Services[j].Name
+	})
+	for _, svc := range r.Services {
+		sort.Slice(svc.Targets, func(i, j int) bool {
+			if svc.Targets[i].Kind != svc.Targets[j].Kind {
+				return svc.Targets[i].Kind < svc.Targets[j].Kind
+			}
+			return svc.Targets[i].Name < svc.Targets[j].Name
+		})
+	}
+	data, err := json.MarshalIndent(r, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
@@ -0,0 +1,67 @@
+package idle
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	unidlingapi "github.com/openshift/api/unidling/v1alpha1"
+)
+
+func TestIdleResult(t *testing.T) {
+	dc := unidlingapi.CrossGroupObjectReference{Kind: "DeploymentConfig", Group: "apps.openshift.io", Name: "frontend"}
+	rc := unidlingapi.CrossGroupObjectReference{Kind: "ReplicationController", Name: "worker-1"}
+
+	result := &idleResult{DryRun: true}
+	svc := result.addService(types.NamespacedName{Namespace: "ns", Name: "web"}, []unidlingapi.RecordedScaleReference{
+		{CrossGroupObjectReference: rc, Replicas: 1},
+		{CrossGroupObjectReference: dc, Replicas: 3},
+	})
+	result.addService(types.NamespacedName{Namespace: "ns", Name: "api"}, nil)
+	svc.setTargetScaled(dc)
+	svc.setTargetError(rc, fmt.Errorf("forbidden"))
+	// the results of services that could not be marked as idled are ignored
+	var missing *serviceResult
+	missing.setTargetScaled(dc)
+
+	out := &bytes.Buffer{}
+	if err := result.print(out); err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+    "dryRun": true,
+    "services": [
+        {
+            "namespace": "ns",
+            "name": "api",
+            "targets": []
+        },
+        {
+            "namespace": "ns",
+            "name": "web",
+            "targets": [
+                {
+                    "kind": "DeploymentConfig",
+                    "group": "apps.openshift.io",
+                    "name": "frontend",
+                    "replicas": 3,
+                    "scaled": true
+                },
+                {
+                    "kind": "ReplicationController",
+                    "name": "worker-1",
+                    "replicas": 1,
+                    "scaled": false,
+                    "error": "forbidden"
+                }
+            ]
+        }
+    ]
+}
+`
+	if out.String() != want {
+		t.Errorf("unexpected result:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,126 @@
+package idle
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/resource"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+
+	unidlingapi "github.com/openshift/api/unidling/v1alpha1"
+	"github.com/openshift/library-go/pkg/unidling/unidlingclient"
+)
+
+// RunWake scales the scalable resources recorded on idled services back up to
+// their previous scale, as the unidling controller does when an idled service
+// receives traffic, and then marks the services as no longer idled.
+func (o *IdleOptions) RunWake() error {
+	b, err := o.builder("services")
+	if err != nil {
+		return err
+	}
+
+	dryRunText := ""
+	if o.dryRun {
+		dryRunText = "(dry run)"
+	}
+
+	scaleAnnotater := unidlingclient.NewScaleAnnotater(o.ScaleClient, o.Mapper, o.AppClient.AppsV1(), o.ClientSet.CoreV1(), func(currentReplicas int32, annotations map[string]string) {
+		delete(annotations, unidlingapi.IdledAtAnnotation)
+		delete(annotations, unidlingapi.PreviousScaleAnnotation)
+	})
+
+	result := &idleResult{DryRun: o.dryRun}
+	hadError := false
+	err = b.Do().Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		svc, ok := info.Object.(*corev1.Service)
+		if !ok {
+			return fmt.Errorf("you must specify services, not %v", info.Mapping.Resource)
+		}
+		serviceName := types.NamespacedName{Namespace: svc.Namespace, Name: svc.Name}
+
+		rawTargets, ok := svc.Annotations[unidlingapi.UnidleTargetAnnotation]
+		if !ok {
+			fmt.Fprintf(o.ErrOut, "error: the service %q is not idled\n", serviceName.String())
+			hadError = true
+			return nil
+		}
+		var targets []unidlingapi.RecordedScaleReference
+		if err := json.Unmarshal([]byte(rawTargets), &targets); err != nil {
+			fmt.Fprintf(o.ErrOut, "error: unable to read the scalable resources of service %q: %v\n", serviceName.String(), err)
+			hadError = true
+			return nil
+		}
+		svcResult := result.addService(serviceName, targets)
+
+		woken := true
+		for _, target := range targets {
+			obj, scale, err := scaleAnnotater.GetObjectWithScale(svc.Namespace, target.CrossGroupObjectReference)
+			if err != nil {
+				fmt.Fprintf(o.ErrOut, "error: unable to get scale for %s %s/%s: %v\n", target.Kind, svc.Namespace, target.Name, err)
+				svcResult.setTargetError(target.CrossGroupObjectReference, err)
+				woken, hadError = false, true
+				continue
+			}
+			// see RunIdle, the scale is written unconditionally
+			scale.ResourceVersion = ""
+			if scale.Spec.Replicas == 0 {
+				scale.Spec.Replicas = target.Replicas
+			}
+			if !o.dryRun {
+				scaleUpdater := unidlingclient.NewScaleUpdater(scheme.DefaultJSONEncoder(), svc.Namespace, o.AppClient.AppsV1(), o.ClientSet.CoreV1())
+				if err := scaleAnnotater.UpdateObjectScale(scaleUpdater, svc.Namespace, target.CrossGroupObjectReference, obj, scale); err != nil {
+					fmt.Fprintf(o.ErrOut, "error: unable to scale %s %s/%s to %d: %v\n", target.Kind, svc.Namespace, target.Name, scale.Spec.Replicas, err)
+					svcResult.setTargetError(target.CrossGroupObjectReference, err)
+					woken, hadError = false, true
+					continue
+				}
+			}
+			svcResult.setTargetScaled(target.CrossGroupObjectReference)
+			if len(o.output) == 0 {
+				fmt.Fprintf(o.Out, "%s \"%s/%s\" has been scaled to %v replicas %s\n", target.Kind, svc.Namespace, target.Name, scale.Spec.Replicas, dryRunText)
+			}
+		}
+
+		// keep the service idled if a resource could not be scaled, so that it
+		// is still woken up by traffic
+		if !woken {
+			return nil
+		}
+		if !o.dryRun {
+			err := o.patchAnnotations(svc, func(annotations map[string]string) error {
+				delete(annotations, unidlingapi.UnidleTargetAnnotation)
+				delete(annotations, unidlingapi.IdledAtAnnotation)
+				return nil
+			})
+			if err != nil {
+				fmt.Fprintf(o.ErrOut, "error: unable to mark service %q as no longer idled: %v\n", serviceName.String(), err)
+				hadError = true
+				return nil
+			}
+		}
+		if len(o.output) == 0 {
+			fmt.Fprintf(o.Out, "The service %q is no longer idled %s\n", serviceName.String(), dryRunText)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(o.output) > 0 {
+		if err := result.print(o.Out); err != nil {
+			return err
+		}
+	}
+	if hadError {
+		return kcmdutil.ErrExit
+	}
+	return nil
+}
@@ -62,6 +62,12 @@ var (
 		not specified, automatically select an image that matches the current operating system and architecture
 		unless --filter-by-os is used to select a different image.
 		These flags have no effect on regular images.
+
+		The --squash flag merges the appended layers into a single layer, and --squash-base=N also
+		merges the top N layers of the base image into it, which keeps images that are frequently
+		rebuilt on top of a base image small. Files removed or replaced by a layer are left out of
+		the squashed layer. Layers that already exist in the destination are not uploaded again
+		unless --force is set.
 	`)
 
 	example = templates.Examples(`
@@ -79,6 +85,12 @@ var (
 		# This will result in $(pwd)/mysql-local/v2/mysql/blobs,manifests
 		oc image append --from mysql:latest --to file://mysql:local --dir mysql-local layer.tar.gz
 
+		# Add a new layer to an image of the local Podman engine
+		oc image append --from podman:localhost/mysql:latest --to myregistry.com/myimage:latest layer.tar.gz
+
+		# Replace the top layer of an image and the new layer with a single layer
+		oc image append --from mysql:latest --to myregistry.com/myimage:latest --squash --squash-base=1 layer.tar.gz
+
 		# Add a new layer to an image that is stored on disk (~/mysql-local/v2/image exists)
 		oc image append --from-dir ~/mysql-local --to myregistry.com/myimage:latest layer.tar.gz
 
@@ -121,6 +133,11 @@ type AppendImageOptions struct {
 	FilterOptions   imagemanifest.FilterOptions
 	ParallelOptions imagemanifest.ParallelOptions
 
+	// Squash merges the appended layers, and the top SquashBase layers of the base image, into a
+	// single layer.
+	Squash     bool
+	SquashBase int
+
 	DryRun bool
 	Force  bool
 
@@ -169,6 +186,8 @@ func NewCmdAppendImage(streams genericiooptions.IOStreams) *cobra.Command {
 	flag.StringVar(&o.CreatedAt, "created-at", o.CreatedAt, "The creation date for this image, in RFC3339 format or milliseconds from the Unix epoch.")
 
 	flag.BoolVar(&o.Force, "force", o.Force, "If set, the command will attempt to upload all layers instead of skipping those that are already uploaded.")
+	flag.BoolVar(&o.Squash, "squash", o.Squash, "Merge the appended layers into a single layer.")
+	flag.IntVar(&o.SquashBase, "squash-base", o.SquashBase, "The number of top layers of the base image to merge with the appended layers. Requires --squash.")
 
 	flag.StringVar(&o.FileDir, "dir", o.FileDir, "The directory on disk that file:// images will be copied under.")
 	flag.StringVar(&o.FromFileDir, "from-dir", o.FromFileDir, "The directory on disk that file:// images will be read from. Overrides --dir")
@@ -210,6 +229,12 @@ func (o *AppendImageOptions) Complete(cmd *cobra.Command, args []string) error {
 }
 
 func (o *AppendImageOptions) Validate() error {
+	if o.SquashBase < 0 {
+		return fmt.Errorf("--squash-base must not be negative")
+	}
+	if o.SquashBase > 0 && !o.Squash {
+		return fmt.Errorf("--squash-base requires --squash")
+	}
 	return o.FilterOptions.Validate()
 }
 
@@ -442,23 +467,31 @@ func (o *AppendImageOptions) append(ctx context.Context, createdAt *time.Time,
 		klog.Infof("output config:\n%s", configJSON)
 	}
 
-	numLayers := len(layers)
 	toBlobs := toRepo.Blobs(ctx)
 
-	for _, arg := range o.LayerFiles {
-		layers, err = appendFileAsLayer(ctx, arg, layers, base, o.DryRun, o.Out, toBlobs)
+	var numLayers int
+	if o.Squash {
+		layers, numLayers, err = o.appendSquashedLayer(ctx, layers, base, fromRepo.Blobs(ctx), toBlobs)
 		if err != nil {
 			return err
 		}
-	}
-	if o.LayerStream != nil {
-		layers, err = appendLayer(ctx, o.LayerStream, layers, base, o.DryRun, o.Out, toBlobs)
-		if err != nil {
-			return err
+	} else {
+		numLayers = len(layers)
+		for _, arg := range o.LayerFiles {
+			layers, err = appendFileAsLayer(ctx, arg, layers, base, o.DryRun, o.Out, toBlobs, !o.Force)
+			if err != nil {
+				return err
+			}
+		}
+		if o.LayerStream != nil {
+			layers, err = appendLayer(ctx, o.LayerStream, layers, base, o.DryRun, o.Out, toBlobs, !o.Force)
+			if err != nil {
+				return err
+			}
 		}
 	}
 	if len(layers) == 0 {
-		layers, err = appendLayer(ctx, bytes.NewBuffer(dockerlayer.GzippedEmptyLayer), layers, base, o.DryRun, o.Out, toBlobs)
+		layers, err = appendLayer(ctx, bytes.NewBuffer(dockerlayer.GzippedEmptyLayer), layers, base, o.DryRun, o.Out, toBlobs, false)
 		if err != nil {
 			return err
 		}
@@ -665,17 +698,24 @@ func WithDescriptor(desc distribution.Descriptor) distribution.BlobCreateOption
 }
 
 func appendFileAsLayer(ctx context.Context, name string, layers []distribution.Descriptor, config *dockerv1client.DockerImageConfig, dryRun bool, out io.Writer,
-	blobs distribution.BlobService) ([]distribution.Descriptor, error) {
+	blobs distribution.BlobService, skipExisting bool) ([]distribution.Descriptor, error) {
 	f, err := os.Open(name)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
-	return appendLayer(ctx, f, layers, config, dryRun, out, blobs)
+	return appendLayer(ctx, f, layers, config, dryRun, out, blobs, skipExisting)
 }
 
-func appendLayer(ctx context.Context, r io.Reader, layers []distribution.Descriptor, config *dockerv1client.DockerImageConfig, dryRun bool, out io.Writer, blobs distribution.BlobService) ([]distribution.Descriptor,
+// appendLayer uploads the gzipped tar archive as a new layer of the image. When skipExisting is set,
+// the digest of the layer is calculated first and its upload is skipped if the destination already
+// has it, which requires reading the archive twice: it is read again from the start if it can be,
+// and is otherwise copied to a temporary file.
+func appendLayer(ctx context.Context, r io.Reader, layers []distribution.Descriptor, config *dockerv1client.DockerImageConfig, dryRun bool, out io.Writer, blobs distribution.BlobService, skipExisting bool) ([]distribution.Descriptor,
 	error) {
+	if skipExisting && !dryRun {
+		return appendLayerIfMissing(ctx, r, layers, config, out, blobs)
+	}
 	var readerFrom io.ReaderFrom = io.Discard.(io.ReaderFrom)
 	var done = func(distribution.Descriptor) error { return nil }
 	if !dryRun {
@@ -715,6 +755,63 @@ func appendLayer(ctx context.Context, r io.Reader, layers []distribution.Descrip
 	return layers, done(desc)
 }
 
+func appendLayerIfMissing(ctx context.Context, r io.Reader, layers []distribution.Descriptor, config *dockerv1client.DockerImageConfig, out io.Writer, blobs distribution.BlobService) ([]distribution.Descriptor, error) {
+	rs, ok := r.(io.ReadSeeker)
+	var readerFrom io.ReaderFrom = io.Discard.(io.ReaderFrom)
+	if !ok {
+		f, err := os.CreateTemp("", "layer-")
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(f.Name())
+		defer f.Close()
+		rs, readerFrom = f, f
+	}
+	layerDigest, blobDigest, modTime, n, err := add.DigestCopy(readerFrom, r)
+	if err != nil {
+		return nil, err
+	}
+	desc := distribution.Descriptor{
+		Digest:    blobDigest,
+		Size:      n,
+		MediaType: schema2.MediaTypeLayer,
+	}
+
+	// due to a bug in the registry, the empty layer is always returned as existing, see copying
+	// the layers of the base image
+	if _, err := blobs.Stat(ctx, blobDigest); err == nil && blobDigest != dockerlayer.GzippedEmptyLayerDigest {
+		fmt.Fprintf(out, "Skipped uploading %s, the layer already exists\n", units.HumanSize(float64(n)))
+	} else {
+		if _, err := rs.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		fmt.Fprint(out, "Uploading ... ")
+		start := time.Now()
+		bw, err := blobs.Create(ctx)
+		if err != nil {
+			fmt.Fprintln(out, "failed")
+			return nil, err
+		}
+		defer bw.Close()
+		if _, err := bw.ReadFrom(rs); err != nil {
+			fmt.Fprintln(out, "failed")
+			return nil, err
+		}
+		if _, err := bw.Commit(ctx, desc); err != nil {
+			fmt.Fprintln(out, "failed")
+			return nil, err
+		}
+		fmt.Fprintf(out, "%s/s\n", units.HumanSize(float64(desc.Size)/float64(time.Now().Sub(start))*float64(time.Second)))
+	}
+
+	layers = append(layers, desc)
+	add.AddLayerToConfig(config, desc, layerDigest.String())
+	if modTime != nil && !modTime.IsZero() {
+		config.Created = *modTime
+	}
+	return layers, nil
+}
+
 func calculateLayerDigest(blobs distribution.BlobService, dgst digest.Digest, readerFrom io.ReaderFrom, r io.Reader) (digest.Digest, error) {
 	if readerFrom == nil {
 		readerFrom = io.Discard.(io.ReaderFrom)
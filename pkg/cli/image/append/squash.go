@@ -0,0 +1,460 @@
+package append
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/klauspost/compress/zstd"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/library-go/pkg/image/dockerv1client"
+)
+
+const (
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = whiteoutPrefix + whiteoutPrefix + ".opq"
+)
+
+// appendSquashedLayer replaces the top SquashBase layers of the base image and the layers to append
+// with a single layer, and returns the layers of the image and the number of layers kept from the
+// base image.
+func (o *AppendImageOptions) appendSquashedLayer(ctx context.Context, layers []distribution.Descriptor, config *dockerv1client.DockerImageConfig, fromBlobs, toBlobs distribution.BlobService) ([]distribution.Descriptor, int, error) {
+	if o.SquashBase > len(layers) {
+		return nil, 0, fmt.Errorf("--squash-base=%d is larger than the %d layers of the base image", o.SquashBase, len(layers))
+	}
+	squashed := layers[len(layers)-o.SquashBase:]
+	layers = layers[:len(layers)-o.SquashBase]
+	if config.RootFS != nil && len(config.RootFS.DiffIDs) > len(layers) {
+		config.RootFS.DiffIDs = config.RootFS.DiffIDs[:len(layers)]
+	}
+	for _, layer := range squashed {
+		config.Size -= layer.Size
+	}
+	// the history of the squashed layers is replaced by the history of the squashed layer, the
+	// entries that do not create a layer still apply
+	removed := 0
+	for i := len(config.History) - 1; i >= 0 && removed < len(squashed); i-- {
+		if config.History[i].EmptyLayer {
+			continue
+		}
+		config.History = append(config.History[:i], config.History[i+1:]...)
+		removed++
+	}
+
+	var openers []layerOpener
+	for _, layer := range squashed {
+		dgst := layer.Digest
+		openers = append(openers, func() (io.ReadCloser, error) {
+			r, err := fromBlobs.Open(ctx, dgst)
+			if err != nil {
+				return nil, fmt.Errorf("unable to access the layer %s of the base image: %v", dgst, err)
+			}
+			return r, nil
+		})
+	}
+	for _, name := range o.LayerFiles {
+		name := name
+		openers = append(openers, func() (io.ReadCloser, error) { return os.Open(name) })
+	}
+	if o.LayerStream != nil {
+		// the stream is read more than once
+		f, err := os.CreateTemp("", "layer-")
+		if err != nil {
+			return nil, 0, err
+		}
+		defer os.Remove(f.Name())
+		_, err = io.Copy(f, o.LayerStream)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		openers = append(openers, func() (io.ReadCloser, error) { return os.Open(f.Name()) })
+	}
+	if len(openers) == 0 {
+		return layers, len(layers), nil
+	}
+
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	go func() {
+		pw.CloseWithError(squashLayers(pw, openers, len(layers) == 0))
+	}()
+	numLayers := len(layers)
+	layers, err := appendLayer(ctx, pr, layers, config, o.DryRun, o.Out, toBlobs, !o.Force)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to squash the layers: %v", err)
+	}
+	config.History = append(config.History, dockerv1client.DockerConfigHistory{
+		Created: config.Created,
+		Comment: fmt.Sprintf("Squashed %d layers", len(openers)),
+	})
+	return layers, numLayers, nil
+}
+
+// layerOpener opens the tar archive of a layer. Squashing reads each layer twice.
+type layerOpener func() (io.ReadCloser, error)
+
+// squashLayers writes a gzipped tar archive of the filesystem the layers create when they are applied
+// in order, keeping of each path the entry of the top-most layer that has it. The whiteouts are kept
+// so that they hide the files of the layers below the squashed ones, unless dropWhiteouts is set
+// because there are no such layers.
+func squashLayers(w io.Writer, layers []layerOpener, dropWhiteouts bool) error {
+	// find the entries that are not replaced or hidden by those of the layers above them, starting
+	// from the top-most layer
+	kept := make([]sets.Int, len(layers))
+	entries := make([]layerEntries, len(layers))
+	seen, removed, opaque, files := sets.NewString(), sets.NewString(), sets.NewString(), sets.NewString()
+	for i := len(layers) - 1; i >= 0; i-- {
+		kept[i] = sets.NewInt()
+		entries[i] = layerEntries{names: map[string][]int{}, links: map[int]string{}, removed: sets.NewString(), opaque: sets.NewString()}
+		// entries of a layer are only hidden by the layers above it, and a later entry of a layer
+		// replaces an earlier one
+		last := map[string]*tar.Header{}
+		lastIndex := map[string]int{}
+		err := readLayer(layers[i], func(index int, hdr *tar.Header, _ io.Reader) error {
+			name := cleanLayerPath(hdr.Name)
+			entries[i].add(index, name, hdr)
+			if hiddenPath(name, seen, removed, opaque, files) {
+				return nil
+			}
+			last[name], lastIndex[name] = hdr, index
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for name, hdr := range last {
+			kept[i].Insert(lastIndex[name])
+			seen.Insert(name)
+			// a path that is not a directory hides the entries below it in the lower layers
+			if hdr.Typeflag != tar.TypeDir {
+				files.Insert(name)
+			}
+		}
+		removed = removed.Union(entries[i].removed)
+		opaque = opaque.Union(entries[i].opaque)
+	}
+
+	// the kept hard links whose target is not kept are written as a copy of the entry they link to
+	links, err := resolveLinks(entries, kept)
+	if err != nil {
+		return err
+	}
+	copies, err := newLinkCopies(links)
+	if err != nil {
+		return err
+	}
+	defer copies.Close()
+
+	// write the kept entries in the order of the layers, from the bottom-most one
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	for i := range layers {
+		err := readLayer(layers[i], func(index int, hdr *tar.Header, r io.Reader) error {
+			if copies.needed(i, index) {
+				if err := copies.save(i, index, hdr, r); err != nil {
+					return err
+				}
+			}
+			if !kept[i].Has(index) {
+				return nil
+			}
+			if dropWhiteouts && strings.HasPrefix(path.Base(cleanLayerPath(hdr.Name)), whiteoutPrefix) {
+				return nil
+			}
+			if link, ok := links[layerEntry{i, index}]; ok {
+				if link.copy {
+					return copies.write(tw, hdr.Name, link.source)
+				}
+				hdr.Linkname = link.target
+			}
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			_, err := io.Copy(tw, r)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// layerEntry is the index of an entry in the archive of a squashed layer.
+type layerEntry struct {
+	layer, index int
+}
+
+// layerEntries are the paths, hard links and whiteouts of a squashed layer, hidden or not.
+type layerEntries struct {
+	names           map[string][]int
+	links           map[int]string
+	removed, opaque sets.String
+}
+
+func (e layerEntries) add(index int, name string, hdr *tar.Header) {
+	e.names[name] = append(e.names[name], index)
+	if hdr.Typeflag == tar.TypeLink {
+		e.links[index] = cleanLayerPath(hdr.Linkname)
+	}
+	dir, base := path.Split(name)
+	switch {
+	case base == whiteoutOpaque:
+		e.opaque.Insert(path.Clean(dir))
+	case strings.HasPrefix(base, whiteoutPrefix):
+		e.removed.Insert(path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)))
+	}
+}
+
+// hides returns whether the whiteouts of the layer hide the path in the layers below it.
+func (e layerEntries) hides(name string) bool {
+	if e.removed.Has(name) {
+		return true
+	}
+	for dir := path.Dir(name); ; dir = path.Dir(dir) {
+		if e.removed.Has(dir) || e.opaque.Has(dir) {
+			return true
+		}
+		if dir == "." {
+			return false
+		}
+	}
+}
+
+// squashedLink is how a kept hard link is written: linking to target, or as a copy of the entry of
+// source when the entry it links to is replaced or removed by the layers above it.
+type squashedLink struct {
+	target string
+	copy   bool
+	source layerEntry
+}
+
+// maxLinkDepth bounds the hard links followed to find the entry a hard link links to.
+const maxLinkDepth = 64
+
+// resolveLinks finds the entry each kept hard link links to, when the layers are applied in order.
+// A hard link whose target is below the squashed layers is kept as is.
+func resolveLinks(entries []layerEntries, kept []sets.Int) (map[layerEntry]squashedLink, error) {
+	links := map[layerEntry]squashedLink{}
+	for i := range entries {
+		for index, target := range entries[i].links {
+			if !kept[i].Has(index) {
+				continue
+			}
+			source, found := layerEntry{i, index}, false
+			for depth := 0; ; depth++ {
+				if depth == maxLinkDepth {
+					return nil, fmt.Errorf("too many hard links to follow from %s", target)
+				}
+				var err error
+				source, found, err = findEntry(entries, source, target)
+				if err != nil {
+					return nil, err
+				}
+				next, isLink := entries[source.layer].links[source.index]
+				if !found || !isLink {
+					break
+				}
+				target = next
+			}
+			switch {
+			case !found, kept[source.layer].Has(source.index):
+				links[layerEntry{i, index}] = squashedLink{target: target}
+			default:
+				links[layerEntry{i, index}] = squashedLink{copy: true, source: source}
+			}
+		}
+	}
+	return links, nil
+}
+
+// findEntry returns the entry of the path that the hard link at from links to, the top-most entry
+// of the path before it, or false if the path is only found below the squashed layers.
+func findEntry(entries []layerEntries, from layerEntry, name string) (layerEntry, bool, error) {
+	for i := from.layer; i >= 0; i-- {
+		if i < from.layer && entries[i+1].hides(name) {
+			return layerEntry{}, false, fmt.Errorf("the hard link target %s is removed before it is linked to", name)
+		}
+		indexes := entries[i].names[name]
+		for j := len(indexes) - 1; j >= 0; j-- {
+			if i < from.layer || indexes[j] < from.index {
+				return layerEntry{i, indexes[j]}, true, nil
+			}
+		}
+	}
+	return layerEntry{}, false, nil
+}
+
+// linkCopies saves the entries that hard links are written as a copy of, as the squashed layers
+// are read in order.
+type linkCopies struct {
+	dir     string
+	sources map[layerEntry]bool
+	headers map[layerEntry]*tar.Header
+}
+
+func newLinkCopies(links map[layerEntry]squashedLink) (*linkCopies, error) {
+	c := &linkCopies{sources: map[layerEntry]bool{}, headers: map[layerEntry]*tar.Header{}}
+	for _, link := range links {
+		if link.copy {
+			c.sources[link.source] = true
+		}
+	}
+	if len(c.sources) == 0 {
+		return c, nil
+	}
+	dir, err := os.MkdirTemp("", "squash-")
+	if err != nil {
+		return nil, err
+	}
+	c.dir = dir
+	return c, nil
+}
+
+func (c *linkCopies) needed(layer, index int) bool {
+	return c.sources[layerEntry{layer, index}]
+}
+
+func (c *linkCopies) path(source layerEntry) string {
+	return filepath.Join(c.dir, fmt.Sprintf("%d-%d", source.layer, source.index))
+}
+
+func (c *linkCopies) save(layer, index int, hdr *tar.Header, r io.Reader) error {
+	source := layerEntry{layer, index}
+	copied := *hdr
+	c.headers[source] = &copied
+	if hdr.Typeflag != tar.TypeReg {
+		return nil
+	}
+	f, err := os.Create(c.path(source))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// write writes the entry named name as a copy of the saved entry of source.
+func (c *linkCopies) write(tw *tar.Writer, name string, source layerEntry) error {
+	hdr := *c.headers[source]
+	hdr.Name = name
+	if err := tw.WriteHeader(&hdr); err != nil {
+		return err
+	}
+	if hdr.Typeflag != tar.TypeReg {
+		return nil
+	}
+	f, err := os.Open(c.path(source))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+func (c *linkCopies) Close() error {
+	if len(c.dir) == 0 {
+		return nil
+	}
+	return os.RemoveAll(c.dir)
+}
+
+// readLayer calls fn with each entry of the archive of the layer and its index. The archive may be
+// compressed with gzip or zstd, or not compressed.
+func readLayer(open layerOpener, fn func(index int, hdr *tar.Header, r io.Reader) error) error {
+	rc, err := open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	r, err := decompressLayer(rc)
+	if err != nil {
+		return fmt.Errorf("unable to read the layer: %v", err)
+	}
+	defer r.Close()
+	tr := tar.NewReader(r)
+	for index := 0; ; index++ {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read the layer: %v", err)
+		}
+		if err := fn(index, hdr, tr); err != nil {
+			return err
+		}
+	}
+}
+
+var (
+	gzipMagic = []byte{0x1F, 0x8B}
+	zstdMagic = []byte{0x28, 0xB5, 0x2F, 0xFD}
+)
+
+// decompressLayer returns the tar archive of a layer, detecting its compression from its first bytes.
+func decompressLayer(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(magic, zstdMagic):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	default:
+		return io.NopCloser(br), nil
+	}
+}
+
+// hiddenPath returns whether the entry of a layer is replaced or hidden by the layers above it: the
+// path was seen, or it or one of its parents was removed by a whiteout, or one of its parents is an
+// opaque directory or not a directory at all.
+func hiddenPath(name string, seen, removed, opaque, files sets.String) bool {
+	if seen.Has(name) || removed.Has(name) {
+		return true
+	}
+	for dir := path.Dir(name); ; dir = path.Dir(dir) {
+		if removed.Has(dir) || opaque.Has(dir) || files.Has(dir) {
+			return true
+		}
+		if dir == "." {
+			return false
+		}
+	}
+}
+
+func cleanLayerPath(name string) string {
+	name = path.Clean("/" + name)
+	if name == "/" {
+		return "."
+	}
+	return strings.TrimPrefix(name, "/")
+}
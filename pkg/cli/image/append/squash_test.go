@@ -0,0 +1,265 @@
+package append
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// entry is a tar entry of a test layer: a directory if name ends with /, a hard link if link is set,
+// and a regular file otherwise.
+type entry struct {
+	name, content, link string
+}
+
+type compression string
+
+const (
+	compressGzip compression = "gzip"
+	compressZstd compression = "zstd"
+	compressNone compression = "uncompressed"
+)
+
+func testLayer(t *testing.T, c compression, entries ...entry) layerOpener {
+	buf := &bytes.Buffer{}
+	var w io.WriteCloser
+	switch c {
+	case compressGzip:
+		w = gzip.NewWriter(buf)
+	case compressZstd:
+		zw, err := zstd.NewWriter(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w = zw
+	default:
+		w = nopWriteCloser{buf}
+	}
+	tw := tar.NewWriter(w)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(e.content))}
+		switch {
+		case strings.HasSuffix(e.name, "/"):
+			hdr.Typeflag, hdr.Mode = tar.TypeDir, 0755
+		case len(e.link) > 0:
+			hdr.Typeflag, hdr.Linkname, hdr.Size = tar.TypeLink, e.link, 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	return func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(data)), nil }
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+func readSquashed(t *testing.T, data []byte) []string {
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []string
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return entries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			entries = append(entries, hdr.Name)
+		case tar.TypeLink:
+			entries = append(entries, fmt.Sprintf("%s => %s", hdr.Name, hdr.Linkname))
+		default:
+			entries = append(entries, fmt.Sprintf("%s=%s", hdr.Name, content))
+		}
+	}
+}
+
+func TestSquashLayers(t *testing.T) {
+	tests := []struct {
+		name          string
+		layers        [][]entry
+		dropWhiteouts bool
+		expected      []string
+		expectedError string
+	}{
+		{
+			name: "replaced file",
+			layers: [][]entry{
+				{{name: "etc/"}, {name: "etc/a", content: "1"}, {name: "etc/b", content: "1"}},
+				{{name: "etc/a", content: "2"}},
+			},
+			expected: []string{"etc/", "etc/b=1", "etc/a=2"},
+		},
+		{
+			name: "whiteout",
+			layers: [][]entry{
+				{{name: "etc/"}, {name: "etc/a", content: "1"}, {name: "etc/b", content: "1"}},
+				{{name: "etc/.wh.a"}},
+			},
+			expected: []string{"etc/", "etc/b=1", "etc/.wh.a="},
+		},
+		{
+			name: "dropped whiteout",
+			layers: [][]entry{
+				{{name: "etc/"}, {name: "etc/a", content: "1"}, {name: "etc/b", content: "1"}},
+				{{name: "etc/.wh.a"}},
+			},
+			dropWhiteouts: true,
+			expected:      []string{"etc/", "etc/b=1"},
+		},
+		{
+			name: "removed directory",
+			layers: [][]entry{
+				{{name: "etc/"}, {name: "etc/a", content: "1"}, {name: "var/"}},
+				{{name: ".wh.etc"}},
+			},
+			dropWhiteouts: true,
+			expected:      []string{"var/"},
+		},
+		{
+			name: "opaque directory",
+			layers: [][]entry{
+				{{name: "etc/"}, {name: "etc/a", content: "1"}, {name: "etc/b", content: "1"}},
+				{{name: "etc/"}, {name: "etc/.wh..wh..opq"}, {name: "etc/c", content: "2"}},
+			},
+			expected: []string{"etc/", "etc/.wh..wh..opq=", "etc/c=2"},
+		},
+		{
+			name: "directory replaced by a file",
+			layers: [][]entry{
+				{{name: "etc/"}, {name: "etc/a", content: "1"}},
+				{{name: "etc", content: "2"}},
+			},
+			expected: []string{"etc=2"},
+		},
+		{
+			name: "hard link to a lower layer",
+			layers: [][]entry{
+				{{name: "bin/"}, {name: "bin/a", content: "1"}},
+				{{name: "bin/b", link: "bin/a"}},
+			},
+			expected: []string{"bin/", "bin/a=1", "bin/b => bin/a"},
+		},
+		{
+			name: "hard link to a file whited out later",
+			layers: [][]entry{
+				{{name: "bin/"}, {name: "bin/a", content: "1"}},
+				{{name: "bin/b", link: "bin/a"}},
+				{{name: "bin/.wh.a"}},
+			},
+			dropWhiteouts: true,
+			expected:      []string{"bin/", "bin/b=1"},
+		},
+		{
+			name: "hard link to a file replaced later",
+			layers: [][]entry{
+				{{name: "bin/"}, {name: "bin/a", content: "1"}},
+				{{name: "bin/b", link: "bin/a"}},
+				{{name: "bin/a", content: "2"}},
+			},
+			expected: []string{"bin/", "bin/b=1", "bin/a=2"},
+		},
+		{
+			name: "hard link to a hard link whited out later",
+			layers: [][]entry{
+				{{name: "bin/"}, {name: "bin/a", content: "1"}, {name: "bin/b", link: "bin/a"}},
+				{{name: "bin/c", link: "/bin/b"}},
+				{{name: "bin/.wh.b"}},
+			},
+			dropWhiteouts: true,
+			expected:      []string{"bin/", "bin/a=1", "bin/c => bin/a"},
+		},
+		{
+			name: "hard link below the squashed layers",
+			layers: [][]entry{
+				{{name: "bin/b", link: "bin/a"}},
+				{{name: "bin/.wh.a"}},
+			},
+			expected: []string{"bin/b => bin/a", "bin/.wh.a="},
+		},
+		{
+			name: "hard link to a removed file",
+			layers: [][]entry{
+				{{name: "bin/"}, {name: "bin/a", content: "1"}},
+				{{name: "bin/.wh.a"}},
+				{{name: "bin/b", link: "bin/a"}},
+			},
+			expectedError: "the hard link target bin/a is removed before it is linked to",
+		},
+	}
+	for _, test := range tests {
+		for _, c := range []compression{compressGzip, compressZstd, compressNone} {
+			t.Run(fmt.Sprintf("%s/%s", test.name, c), func(t *testing.T) {
+				var layers []layerOpener
+				for _, entries := range test.layers {
+					layers = append(layers, testLayer(t, c, entries...))
+				}
+				buf := &bytes.Buffer{}
+				err := squashLayers(buf, layers, test.dropWhiteouts)
+				if len(test.expectedError) > 0 {
+					if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+						t.Fatalf("expected error %q, got %v", test.expectedError, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if entries := readSquashed(t, buf.Bytes()); !reflect.DeepEqual(entries, test.expected) {
+					t.Errorf("expected %v, got %v", test.expected, entries)
+				}
+			})
+		}
+	}
+}
+
+func TestHiddenPath(t *testing.T) {
+	seen, removed, opaque, files := sets.NewString("etc/a"), sets.NewString("var/log"), sets.NewString("opt"), sets.NewString("usr")
+	for name, expected := range map[string]bool{
+		"etc/a":         true,
+		"etc/b":         false,
+		"var/log":       true,
+		"var/log/audit": true,
+		"var/lib":       false,
+		"opt/app":       true,
+		"opt":           false,
+		"usr/bin":       true,
+		"usr":           false,
+	} {
+		if hidden := hiddenPath(name, seen, removed, opaque, files); hidden != expected {
+			t.Errorf("%s: expected hidden %t, got %t", name, expected, hidden)
+		}
+	}
+}
@@ -52,6 +52,9 @@ type (
 		Chown bool
 
 		AlterHeaders AlterHeader
+
+		// ADDED: if set, the digest of each regular file unpacked is recorded
+		Checksums *Checksums
 	}
 )
 
@@ -232,6 +235,7 @@ func unpackLayer(dest string, layer io.Reader, options *TarOptions) (size int64,
 					}
 					if _, exists := unpackedPaths[path]; !exists {
 						err := os.RemoveAll(path)
+						options.Checksums.remove(path)
 						return err
 					}
 					return nil
@@ -245,6 +249,7 @@ func unpackLayer(dest string, layer io.Reader, options *TarOptions) (size int64,
 				if err := os.RemoveAll(originalPath); err != nil {
 					return 0, err
 				}
+				options.Checksums.remove(originalPath)
 			}
 		} else {
 			// If path exits we almost always just want to remove and replace it.
@@ -256,6 +261,7 @@ func unpackLayer(dest string, layer io.Reader, options *TarOptions) (size int64,
 					if err := os.RemoveAll(path); err != nil {
 						return 0, err
 					}
+					options.Checksums.remove(path)
 				}
 			}
 
@@ -283,10 +289,22 @@ func unpackLayer(dest string, layer io.Reader, options *TarOptions) (size int64,
 			// 	return 0, err
 			// }
 
+			var written func()
+			if srcHdr.Typeflag == tar.TypeReg || srcHdr.Typeflag == tar.TypeRegA {
+				srcData, written = options.Checksums.reader(path, srcData)
+			}
+
 			if err := createTarFile(path, dest, srcHdr, srcData, options.Chown, options.ChownOpts, options.InUserNS, currentUser); err != nil {
 				return 0, err
 			}
 
+			switch {
+			case written != nil:
+				written()
+			case hdr.Typeflag == tar.TypeLink:
+				options.Checksums.link(path, filepath.Join(dest, hdr.Linkname))
+			}
+
 			// Directory mtimes must be handled at the end to avoid further
 			// file creation in them to modify the directory mtime
 			if hdr.Typeflag == tar.TypeDir {
@@ -0,0 +1,82 @@
+package archive
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	digest "github.com/opencontainers/go-digest"
+)
+
+// Checksums records the digest of the content of each regular file written when unpacking layers,
+// by path. Files removed or replaced by a later layer are forgotten, so that once all the layers
+// are unpacked the checksums describe what is on disk.
+type Checksums struct {
+	lock  sync.Mutex
+	files map[string]digest.Digest
+}
+
+func NewChecksums() *Checksums {
+	return &Checksums{files: make(map[string]digest.Digest)}
+}
+
+// Files returns the digests of the files unpacked, by path.
+func (c *Checksums) Files() map[string]digest.Digest {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	files := make(map[string]digest.Digest, len(c.files))
+	for path, dgst := range c.files {
+		files[path] = dgst
+	}
+	return files
+}
+
+func (c *Checksums) record(path string, dgst digest.Digest) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.files[path] = dgst
+}
+
+// link records the hard link at path with the digest of its target, if the target was unpacked.
+func (c *Checksums) link(path, target string) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if dgst, ok := c.files[target]; ok {
+		c.files[path] = dgst
+	}
+}
+
+// remove forgets the file at path, or the files below it if path is a directory.
+func (c *Checksums) remove(path string) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	prefix := path + string(os.PathSeparator)
+	for file := range c.files {
+		if file == path || strings.HasPrefix(file, prefix) {
+			delete(c.files, file)
+		}
+	}
+}
+
+// reader wraps the content of the regular file at path, and returns a function recording its
+// digest once the file has been written.
+func (c *Checksums) reader(path string, r io.Reader) (io.Reader, func()) {
+	if c == nil {
+		return r, func() {}
+	}
+	digester := digest.Canonical.Digester()
+	return io.TeeReader(r, digester.Hash()), func() {
+		c.record(filepath.Clean(path), digester.Digest())
+	}
+}
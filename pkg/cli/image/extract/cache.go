@@ -0,0 +1,153 @@
+package extract
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/distribution/distribution/v3"
+	digest "github.com/opencontainers/go-digest"
+	"k8s.io/klog/v2"
+)
+
+// layerCache keeps the layers extracted by more than one mapping on disk, so that a layer shared
+// by several images or paths is only downloaded once. Layers used by a single mapping are streamed
+// from the registry and a cached layer is removed once its last user has opened it.
+type layerCache struct {
+	lock   sync.Mutex
+	dir    string
+	uses   map[digest.Digest]int
+	layers map[digest.Digest]*cachedLayer
+	// throttle, if set, slows down the reads of the layers from the registries
+	throttle *throttle
+}
+
+type cachedLayer struct {
+	once sync.Once
+	path string
+	err  error
+	// users is the number of mappings that open the layer, opened how many of them did
+	users  int
+	opened int
+}
+
+func newLayerCache(throttle *throttle) *layerCache {
+	return &layerCache{
+		uses:     make(map[digest.Digest]int),
+		layers:   make(map[digest.Digest]*cachedLayer),
+		throttle: throttle,
+	}
+}
+
+// Use records that a mapping will open the layer.
+func (c *layerCache) Use(dgst digest.Digest) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.uses[dgst]++
+}
+
+// Open returns the contents of the layer, downloading it to the cache the first time a layer with
+// several users is opened.
+func (c *layerCache) Open(ctx context.Context, blobs distribution.BlobStore, dgst digest.Digest) (io.ReadCloser, error) {
+	c.lock.Lock()
+	layer, ok := c.layers[dgst]
+	if !ok {
+		uses := c.uses[dgst]
+		delete(c.uses, dgst)
+		if uses < 2 {
+			c.lock.Unlock()
+			r, err := blobs.Open(ctx, dgst)
+			if err != nil {
+				return nil, err
+			}
+			return c.throttle.Reader(ctx, r), nil
+		}
+		layer = &cachedLayer{users: uses}
+		c.layers[dgst] = layer
+	}
+	c.lock.Unlock()
+
+	layer.once.Do(func() {
+		layer.path, layer.err = c.download(ctx, blobs, dgst)
+	})
+	var f *os.File
+	err := layer.err
+	if err == nil {
+		f, err = os.Open(layer.path)
+	}
+	c.opened(dgst, layer)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// opened records that a user opened the cached layer, or failed to, and once all of them did
+// removes it from the cache. The file remains readable by the users until they close it.
+func (c *layerCache) opened(dgst digest.Digest, layer *cachedLayer) {
+	c.lock.Lock()
+	layer.opened++
+	last := layer.opened >= layer.users
+	if last {
+		delete(c.layers, dgst)
+	}
+	c.lock.Unlock()
+	if !last || layer.err != nil {
+		return
+	}
+	if err := os.Remove(layer.path); err != nil {
+		klog.V(4).Infof("Unable to remove cached layer %s: %v", dgst, err)
+	}
+}
+
+func (c *layerCache) download(ctx context.Context, blobs distribution.BlobStore, dgst digest.Digest) (string, error) {
+	c.lock.Lock()
+	if len(c.dir) == 0 {
+		dir, err := os.MkdirTemp("", "image-extract-")
+		if err != nil {
+			c.lock.Unlock()
+			return "", fmt.Errorf("unable to create a directory to cache the layers: %v", err)
+		}
+		c.dir = dir
+	}
+	dir := c.dir
+	c.lock.Unlock()
+
+	blob, err := blobs.Open(ctx, dgst)
+	if err != nil {
+		return "", err
+	}
+	r := c.throttle.Reader(ctx, blob)
+	defer r.Close()
+	path := filepath.Join(dir, dgst.Encoded())
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	klog.V(5).Infof("Caching layer %s shared by several mappings in %s", dgst, path)
+	verifier := dgst.Verifier()
+	if _, err := io.Copy(io.MultiWriter(f, verifier), r); err != nil {
+		f.Close()
+		return "", fmt.Errorf("unable to cache the layer %s: %v", dgst, err)
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	if !verifier.Verified() {
+		return "", fmt.Errorf("the content of the layer %s does not match its digest", dgst)
+	}
+	return path, nil
+}
+
+// Close removes the layers left in the cache.
+func (c *layerCache) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.dir) == 0 {
+		return nil
+	}
+	return os.RemoveAll(c.dir)
+}
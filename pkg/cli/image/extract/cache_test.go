@@ -0,0 +1,124 @@
+package extract
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/distribution/distribution/v3"
+	digest "github.com/opencontainers/go-digest"
+)
+
+type fakeBlobStore struct {
+	distribution.BlobStore
+
+	lock  sync.Mutex
+	blobs map[digest.Digest][]byte
+	opens map[digest.Digest]int
+}
+
+type readSeekNopCloser struct {
+	*bytes.Reader
+}
+
+func (readSeekNopCloser) Close() error { return nil }
+
+func (s *fakeBlobStore) Open(ctx context.Context, dgst digest.Digest) (distribution.ReadSeekCloser, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	data, ok := s.blobs[dgst]
+	if !ok {
+		return nil, distribution.ErrBlobUnknown
+	}
+	s.opens[dgst]++
+	return readSeekNopCloser{bytes.NewReader(data)}, nil
+}
+
+func TestLayerCacheConcurrentUsers(t *testing.T) {
+	blobs := &fakeBlobStore{blobs: map[digest.Digest][]byte{}, opens: map[digest.Digest]int{}}
+	users := map[digest.Digest]int{}
+	for i, n := range []int{1, 2, 5, 16} {
+		data := []byte(fmt.Sprintf("layer %d", i))
+		dgst := digest.FromBytes(data)
+		blobs.blobs[dgst] = data
+		users[dgst] = n
+	}
+
+	c := newLayerCache(nil)
+	defer c.Close()
+	for dgst, n := range users {
+		for i := 0; i < n; i++ {
+			c.Use(dgst)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 32)
+	for dgst, n := range users {
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(dgst digest.Digest) {
+				defer wg.Done()
+				r, err := c.Open(context.Background(), blobs, dgst)
+				if err != nil {
+					errs <- fmt.Errorf("%s: %v", dgst, err)
+					return
+				}
+				defer r.Close()
+				data, err := io.ReadAll(r)
+				if err != nil {
+					errs <- fmt.Errorf("%s: %v", dgst, err)
+					return
+				}
+				if !bytes.Equal(data, blobs.blobs[dgst]) {
+					errs <- fmt.Errorf("%s: unexpected content %q", dgst, data)
+				}
+			}(dgst)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	for dgst := range users {
+		if blobs.opens[dgst] != 1 {
+			t.Errorf("%s: expected the layer to be downloaded once, got %d", dgst, blobs.opens[dgst])
+		}
+	}
+	if len(c.layers) != 0 || len(c.uses) != 0 {
+		t.Errorf("expected the cache to be empty, got %d layers and %d uses", len(c.layers), len(c.uses))
+	}
+	if len(c.dir) > 0 {
+		entries, err := os.ReadDir(c.dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 0 {
+			t.Errorf("expected the cached layers to be removed, found %d", len(entries))
+		}
+	}
+}
+
+func TestLayerCacheDownloadError(t *testing.T) {
+	blobs := &fakeBlobStore{blobs: map[digest.Digest][]byte{}, opens: map[digest.Digest]int{}}
+	dgst := digest.FromString("missing")
+
+	c := newLayerCache(nil)
+	defer c.Close()
+	c.Use(dgst)
+	c.Use(dgst)
+	for i := 0; i < 2; i++ {
+		if _, err := c.Open(context.Background(), blobs, dgst); err != distribution.ErrBlobUnknown {
+			t.Errorf("expected %v, got %v", distribution.ErrBlobUnknown, err)
+		}
+	}
+	if len(c.layers) != 0 {
+		t.Errorf("expected the cache to be empty, got %d layers", len(c.layers))
+	}
+}
@@ -2,6 +2,7 @@ package extract
 
 import (
 	"archive/tar"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -11,13 +12,16 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 
 	"github.com/distribution/distribution/v3"
 	dockerarchive "github.com/docker/docker/pkg/archive"
+	"github.com/docker/go-units"
 	digest "github.com/opencontainers/go-digest"
 
 	"k8s.io/cli-runtime/pkg/genericiooptions"
@@ -61,6 +65,24 @@ var (
 
 		Negative indices are counted from the end of the list, e.g. [-1] selects the last
 		layer.
+
+		Several images may be passed as arguments, each of them is extracted to the directories
+		of the --path flags. The images are retrieved in parallel, up to --max-per-registry at
+		a time, and a layer shared by several images or paths is only downloaded once. Paths
+		extracted to the same directory are applied in the order of the arguments, so that the
+		files of the last image win. When more than one image or path is extracted, the status
+		of each of them is summarized once all are processed, and the command exits with an
+		error if any of them failed.
+
+		Pass --write-checksums to record the sha256 checksum of every file extracted in a file
+		that can be checked with 'sha256sum -c'. The files on disk are verified against the
+		content of the image before the checksums are written.
+
+		To extract large images without starving the other traffic of the host, pass
+		--max-bandwidth to limit the rate at which the layers are downloaded, shared by all the
+		layers downloaded at the same time. Pass --idle-priority to retrieve one image at a time
+		unless --max-per-registry is given, to run with the lowest CPU and IO priority, and to
+		pause the downloads while the host is busy.
 		`)
 
 	example = templates.Examples(`
@@ -95,6 +117,12 @@ var (
 		# Extract an image stored on disk in a directory other than $(pwd)/v2 into a designated directory (must exist)
 		oc image extract file://busybox:local --dir busybox-mirror-dir --path /:/tmp/busybox
 
+		# Extract an image of the local Docker engine into the current directory
+		oc image extract docker-daemon:busybox:latest
+
+		# Extract the image tagged v1 of an OCI archive into the current directory
+		oc image extract oci-archive:images.tar:v1
+
 		# Extract the last layer in the image
 		oc image extract docker.io/library/centos:7[-1]
 
@@ -103,6 +131,15 @@ var (
 
 		# Extract the last three layers of the image
 		oc image extract docker.io/library/centos:7[-3:]
+
+		# Extract the /usr/bin directory of an image and record the checksums of the extracted files
+		oc image extract docker.io/library/centos:7 --path /usr/bin/:/tmp/bin --write-checksums=/tmp/bin.sha256
+
+		# List the files of the /etc directory of several images, retrieved in parallel
+		oc image extract docker.io/library/centos:7 docker.io/library/fedora:latest --path /etc/:. --dry-run
+
+		# Extract an image on a shared host, downloading at most 20 MiB per second and pausing while the host is busy
+		oc image extract docker.io/library/centos:7 --path /:/tmp/centos --max-bandwidth=20MiB --idle-priority
 	`)
 )
 
@@ -139,6 +176,17 @@ type ExtractOptions struct {
 	ICSPFile string
 	IDMSFile string
 
+	// ChecksumFile, if set, is written with the sha256 digest of every file extracted, once the
+	// files on disk have been verified against the content of the layers.
+	ChecksumFile string
+
+	// MaxBandwidth, if set, limits the bytes per second read from the layers, such as 500KiB
+	// or 20MiB.
+	MaxBandwidth string
+	// IdlePriority lowers the priority of the process and pauses the reads of the layers
+	// while the host is busy.
+	IdlePriority bool
+
 	genericiooptions.IOStreams
 
 	// ImageMetadataCallback is invoked once per image retrieved, and may be called in parallel if
@@ -151,6 +199,12 @@ type ExtractOptions struct {
 	// AllLayers ensures the TarEntryCallback is invoked for all files, and will cause the callback
 	// order to start at the lowest layer and work outwards.
 	AllLayers bool
+	// PrintSummary reports the status of each mapping to ErrOut once all of them are processed,
+	// when more than one mapping is extracted.
+	PrintSummary bool
+
+	checksums    *archive.Checksums
+	maxBandwidth int64
 }
 
 func NewExtractOptions(streams genericiooptions.IOStreams) *ExtractOptions {
@@ -165,6 +219,8 @@ func NewExtractOptions(streams genericiooptions.IOStreams) *ExtractOptions {
 // New creates a new command
 func NewExtract(streams genericiooptions.IOStreams) *cobra.Command {
 	o := NewExtractOptions(streams)
+	o.ParallelOptions.MaxPerRegistry = 4
+	o.PrintSummary = true
 
 	cmd := &cobra.Command{
 		Use:     "extract",
@@ -181,6 +237,7 @@ func NewExtract(streams genericiooptions.IOStreams) *cobra.Command {
 	flag := cmd.Flags()
 	o.SecurityOptions.Bind(flag)
 	o.FilterOptions.Bind(flag)
+	o.ParallelOptions.Bind(flag)
 
 	flag.BoolVar(&o.Confirm, "confirm", o.Confirm, "Pass to allow extracting to non-empty directories.")
 	flag.BoolVar(&o.DryRun, "dry-run", o.DryRun, "Print the actions that would be taken and exit without writing any contents.")
@@ -195,6 +252,9 @@ func NewExtract(streams genericiooptions.IOStreams) *cobra.Command {
 	flag.BoolVar(&o.OnlyFiles, "only-files", o.OnlyFiles, "Only extract regular files and directories from the image.")
 	flag.BoolVar(&o.AllLayers, "all-layers", o.AllLayers, "For dry-run mode, process from lowest to highest layer and don't omit duplicate files.")
 	flag.StringVar(&o.FileDir, "dir", o.FileDir, "The directory on disk that file:// images will be extracted from.")
+	flag.StringVar(&o.ChecksumFile, "write-checksums", o.ChecksumFile, "Write the sha256 checksums of the extracted files to this file, in the format of sha256sum, once they are verified against the content of the image. The paths are relative to the destination directory, or to the closest directory holding all the destinations.")
+	flag.StringVar(&o.MaxBandwidth, "max-bandwidth", o.MaxBandwidth, "Maximum bytes per second to download the layers at, such as 500KiB or 20MiB, shared by all the layers downloaded at the same time.")
+	flag.BoolVar(&o.IdlePriority, "idle-priority", o.IdlePriority, "Run with the lowest CPU and IO priority, retrieve one image at a time unless --max-per-registry is given, and pause the downloads while the host is busy.")
 
 	return cmd
 }
@@ -331,6 +391,13 @@ func (o *ExtractOptions) Complete(cmd *cobra.Command, args []string) error {
 		o.Paths = append(o.Paths, "/:.")
 	}
 
+	if o.IdlePriority && !cmd.Flags().Changed("max-per-registry") {
+		o.ParallelOptions.MaxPerRegistry = 1
+		if !cmd.Flags().Changed("max-per-registry-limit") {
+			o.ParallelOptions.MaxPerRegistryLimit = 1
+		}
+	}
+
 	var err error
 	o.Mappings, err = parseMappings(args, o.Paths, o.Files, !o.Confirm && !o.DryRun)
 	if err != nil {
@@ -346,11 +413,46 @@ func (o *ExtractOptions) Validate() error {
 	if len(o.ICSPFile) > 0 && len(o.IDMSFile) > 0 {
 		return fmt.Errorf("icsp-file and idms-file are mutually exclusive")
 	}
+	if len(o.ChecksumFile) > 0 && (o.DryRun || o.TarEntryCallback != nil) {
+		return fmt.Errorf("--write-checksums may not be used when no file is extracted")
+	}
+	if len(o.MaxBandwidth) > 0 {
+		maxBandwidth, err := units.RAMInBytes(o.MaxBandwidth)
+		if err != nil || maxBandwidth <= 0 {
+			return fmt.Errorf("--max-bandwidth must be a positive number of bytes per second, such as 500KiB or 20MiB: %q", o.MaxBandwidth)
+		}
+		o.maxBandwidth = maxBandwidth
+	}
 	return o.FilterOptions.Validate()
 }
 
+// resolvedImage is an image retrieved once for all the mappings extracting from it.
+type resolvedImage struct {
+	repo          distribution.Repository
+	location      imagemanifest.ManifestLocation
+	contentDigest digest.Digest
+	imageConfig   *dockerv1client.DockerImageConfig
+	layers        []distribution.Descriptor
+	err           error
+}
+
+// mappingStatus is the outcome of extracting a mapping, reported in the summary.
+type mappingStatus struct {
+	image   *resolvedImage
+	layers  []distribution.Descriptor
+	skipped bool
+	err     error
+	// out holds the listing of the mapping in dry-run mode, written once all mappings are processed
+	out bytes.Buffer
+}
+
 func (o *ExtractOptions) Run() error {
 	ctx := context.Background()
+	if o.IdlePriority {
+		if err := lowerPriority(); err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: %v\n", err)
+		}
+	}
 	fromContext, err := o.SecurityOptions.Context()
 	if err != nil {
 		return err
@@ -367,195 +469,395 @@ func (o *ExtractOptions) Run() error {
 		RegistryContext: fromContext,
 	}
 
+	// the mappings of an image share its manifest and configuration, which are retrieved once
+	images := make(map[string]*resolvedImage)
+	var refs []imagesource.TypedImageReference
+	alternateSourceWarned := false
+	for _, mapping := range o.Mappings {
+		from := mapping.ImageRef
+		if !alternateSourceWarned && (len(o.ICSPFile) > 0 || len(o.IDMSFile) > 0) && len(from.Ref.Tag) > 0 {
+			fmt.Fprintf(o.ErrOut, "warning: --idms-file(and --icsp-file) only applies to images referenced by digest and will be ignored for tags\n")
+			alternateSourceWarned = true
+		}
+		if _, ok := images[from.String()]; !ok {
+			images[from.String()] = &resolvedImage{}
+			refs = append(refs, from)
+		}
+	}
+
 	stopCh := make(chan struct{})
 	defer close(stopCh)
 	q := workqueue.New(o.ParallelOptions.MaxPerRegistry, stopCh)
-	return q.Try(func(q workqueue.Try) {
-		alternateSourceWarned := false
-		for i := range o.Mappings {
-			mapping := o.Mappings[i]
-			from := mapping.ImageRef
-			if !alternateSourceWarned && (len(o.ICSPFile) > 0 || len(o.IDMSFile) > 0) && len(from.Ref.Tag) > 0 {
-				fmt.Fprintf(o.ErrOut, "warning: --idms-file(and --icsp-file) only applies to images referenced by digest and will be ignored for tags\n")
-				alternateSourceWarned = true
-			}
-			q.Try(func() error {
-				repo, err := fromOptions.Repository(ctx, from)
-				if err != nil {
-					return fmt.Errorf("unable to connect to image repository %s: %v", from.String(), err)
-				}
+	q.Batch(func(w workqueue.Work) {
+		for _, from := range refs {
+			from, image := from, images[from.String()]
+			w.Parallel(func() {
+				*image = o.resolveImage(ctx, fromOptions, from)
+			})
+		}
+	})
 
-				srcManifest, location, err := retrieveSourceManifest(ctx, from, repo, o)
-				if err != nil {
-					if err != imagemanifest.AllImageFilteredErr {
-						return err
-					}
-
-					// Translate the current runtime OS to linux when looking through a manifest listed image since a manifest for the particular 'runtime OS'/'archType' may not exist.
-					// In cases where the runtime OS is windows or darwin (i.e. macOS), there is no expectation of a manifest image with those particular OS's.
-					if o.FilterOptions.DefaultOSFilter {
-						runtimeOS := runtime.GOOS
-						arch := runtime.GOARCH
-
-						klog.V(2).Infof("Warning: a manifest image could not be found for this platform: %s/%s. Converting runtime OS, %s, to 'linux' to pull the linux/%s equivalent image.\n", runtimeOS, arch, runtimeOS, arch)
-						o.FilterOptions.OSFilter, err = regexp.Compile("linux/" + arch)
-						if err != nil {
-							return fmt.Errorf("failed to compile OSFilter for linux/%s: %v", arch, err)
-						}
-
-						srcManifest, location, err = retrieveSourceManifest(ctx, from, repo, o)
-						if err != nil {
-							return err
-						}
-					} else {
-						return fmt.Errorf("failed to retrieve manifests from image %s: %v", from, err)
-					}
-				}
+	if len(o.ChecksumFile) > 0 {
+		o.checksums = archive.NewChecksums()
+	}
 
-				contentDigest, err := registryclient.ContentDigestForManifest(srcManifest, location.Manifest.Algorithm())
-				if err != nil {
-					return err
-				}
+	statuses := make([]mappingStatus, len(o.Mappings))
+	cache := newLayerCache(newThrottle(o.maxBandwidth, o.IdlePriority, o.ErrOut))
+	defer cache.Close()
+	for i := range o.Mappings {
+		status := &statuses[i]
+		status.image = images[o.Mappings[i].ImageRef.String()]
+		if status.err = status.image.err; status.err != nil {
+			continue
+		}
+		status.layers, status.skipped, status.err = o.selectLayers(&o.Mappings[i], status.image)
+		if status.err != nil || status.skipped {
+			continue
+		}
+		for _, layer := range status.layers {
+			cache.Use(layer.Digest)
+		}
+	}
 
-				imageConfig, layers, err := imagemanifest.ManifestToImageConfig(ctx, srcManifest, repo.Blobs(ctx), location)
-				if err != nil {
-					return fmt.Errorf("unable to parse image %s: %v", from, err)
+	// mappings extracting to the same destination are applied in order, so that the contents of
+	// the last image win as when extracting them one at a time
+	var destinations []string
+	byDestination := make(map[string][]int)
+	for i := range o.Mappings {
+		if statuses[i].err != nil || statuses[i].skipped {
+			continue
+		}
+		to := o.Mappings[i].To
+		if _, ok := byDestination[to]; !ok {
+			destinations = append(destinations, to)
+		}
+		byDestination[to] = append(byDestination[to], i)
+	}
+	q.Batch(func(w workqueue.Work) {
+		for _, to := range destinations {
+			indices := byDestination[to]
+			w.Parallel(func() {
+				for _, i := range indices {
+					status := &statuses[i]
+					status.err = o.extractMapping(ctx, cache, &o.Mappings[i], status.image, status.layers, &status.out)
 				}
+			})
+		}
+	})
+	for i := range statuses {
+		statuses[i].out.WriteTo(o.Out)
+	}
 
-				if mapping.ConditionFn != nil {
-					ok, err := mapping.ConditionFn(&mapping, location.Manifest, imageConfig)
-					if err != nil {
-						return fmt.Errorf("unable to check whether to include image %s: %v", from, err)
-					}
-					if !ok {
-						klog.V(2).Infof("Filtered out image %s with digest %s from being extracted", from, location.Manifest)
-						return nil
-					}
-				}
+	if o.PrintSummary && len(o.Mappings) > 1 {
+		o.printSummary(statuses)
+	}
 
-				var alter alterations
-				if o.OnlyFiles {
-					alter = append(alter, filesOnly{})
-				}
-				if len(mapping.From) > 0 {
-					switch {
-					case strings.HasSuffix(mapping.From, "/"):
-						alter = append(alter, newCopyFromDirectory(mapping.From))
-					default:
-						name, parent := path.Base(mapping.From), path.Dir(mapping.From)
-						if name != "." && parent == "." {
-							alter = append(alter, newCopyFromPattern(parent, name, true))
-						} else if name == "." || parent == "." {
-							return fmt.Errorf("unexpected directory from mapping %s", mapping.From)
-						} else {
-							alter = append(alter, newCopyFromPattern(parent, name, false))
-						}
-					}
-				}
+	// an image that cannot be retrieved is reported once for all its mappings
+	var errs []error
+	for i, status := range statuses {
+		if status.err == nil || (status.err == status.image.err && i > 0 && statuses[i-1].image == status.image) {
+			continue
+		}
+		errs = append(errs, status.err)
+	}
+	if len(errs) == 0 && o.checksums != nil {
+		if err := writeChecksums(o.ChecksumFile, checksumRoot(destinations), o.checksums.Files()); err != nil {
+			return err
+		}
+	}
 
-				filteredLayers := layers
-				if mapping.LayerFilter != nil {
-					filteredLayers, err = mapping.LayerFilter.Filter(filteredLayers)
-					if err != nil {
-						return fmt.Errorf("unable to filter layers for %s: %v", from, err)
-					}
-				}
-				if !o.PreservePermissions {
-					alter = append(alter, removePermissions{})
-				}
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		for _, err := range errs {
+			fmt.Fprintf(o.ErrOut, "error: %v\n", err)
+		}
+		return fmt.Errorf("one or more errors occurred while extracting images")
+	}
+}
 
-				var byEntry TarEntryFunc = o.TarEntryCallback
-				if o.DryRun {
-					path := mapping.To
-					out := o.Out
-					byEntry = func(hdr *tar.Header, layerInfo LayerInfo, r io.Reader) (bool, error) {
-						if len(hdr.Name) == 0 {
-							return true, nil
-						}
-						mode := hdr.FileInfo().Mode().String()
-						switch hdr.Typeflag {
-						case tar.TypeDir:
-							fmt.Fprintf(out, "%2d %s %12d %s\n", layerInfo.Index, mode, hdr.Size, filepath.Join(path, hdr.Name))
-						case tar.TypeReg, tar.TypeRegA:
-							fmt.Fprintf(out, "%2d %s %12d %s\n", layerInfo.Index, mode, hdr.Size, filepath.Join(path, hdr.Name))
-						case tar.TypeLink:
-							fmt.Fprintf(out, "%2d %s %12d %s -> %s\n", layerInfo.Index, mode, hdr.Size, hdr.Name, filepath.Join(path, hdr.Linkname))
-						case tar.TypeSymlink:
-							fmt.Fprintf(out, "%2d %s %12d %s -> %s\n", layerInfo.Index, mode, hdr.Size, hdr.Name, filepath.Join(path, hdr.Linkname))
-						default:
-							fmt.Fprintf(out, "%2d %s %12d %s %x\n", layerInfo.Index, mode, hdr.Size, filepath.Join(path, hdr.Name), hdr.Typeflag)
-						}
-						return true, nil
-					}
-				}
+// resolveImage retrieves the manifest and configuration of the image matching the filter.
+func (o *ExtractOptions) resolveImage(ctx context.Context, fromOptions *imagesource.Options, from imagesource.TypedImageReference) resolvedImage {
+	repo, err := fromOptions.Repository(ctx, from)
+	if err != nil {
+		return resolvedImage{err: fmt.Errorf("unable to connect to image repository %s: %v", from.String(), err)}
+	}
 
-				// walk the layers in reverse order, only showing a given path once
-				alreadySeen := make(map[string]struct{})
-				var layerInfos []LayerInfo
-				if byEntry != nil && !o.AllLayers {
-					for i := len(filteredLayers) - 1; i >= 0; i-- {
-						layerInfos = append(layerInfos, LayerInfo{Index: i, Descriptor: filteredLayers[i], Mapping: &mapping})
-					}
-				} else {
-					for i := range filteredLayers {
-						layerInfos = append(layerInfos, LayerInfo{Index: i, Descriptor: filteredLayers[i], Mapping: &mapping})
-					}
-				}
+	filter := o.FilterOptions
+	srcManifest, location, err := retrieveSourceManifest(ctx, from, repo, &filter)
+	if err != nil {
+		if err != imagemanifest.AllImageFilteredErr {
+			return resolvedImage{err: err}
+		}
 
-				for _, info := range layerInfos {
-					layer := info.Descriptor
-
-					cont, err := func() (bool, error) {
-						fromBlobs := repo.Blobs(ctx)
-
-						klog.V(5).Infof("Extracting from layer: %#v", layer)
-
-						// source
-						r, err := fromBlobs.Open(ctx, layer.Digest)
-						if err != nil {
-							return false, fmt.Errorf("unable to access the source layer %s: %v", layer.Digest, err)
-						}
-						defer r.Close()
-
-						options := &archive.TarOptions{
-							AlterHeaders: alter,
-							Chown:        o.PreservePermissions,
-						}
-
-						if byEntry != nil {
-							cont, err := layerByEntry(r, options, info, byEntry, o.AllLayers, alreadySeen)
-							if err != nil {
-								err = fmt.Errorf("unable to iterate over layer %s from %s: %v", layer.Digest, from, err)
-							}
-							return cont, err
-						}
-
-						klog.V(4).Infof("Extracting layer %s with options %#v", layer.Digest, options)
-						if _, err := archive.ApplyLayer(mapping.To, r, options); err != nil {
-							return false, fmt.Errorf("unable to extract layer %s from %s: %v", layer.Digest, from, err)
-						}
-						return true, nil
-					}()
-					if err != nil {
-						return err
-					}
-					if !cont {
-						break
-					}
-				}
+		// Translate the current runtime OS to linux when looking through a manifest listed image since a manifest for the particular 'runtime OS'/'archType' may not exist.
+		// In cases where the runtime OS is windows or darwin (i.e. macOS), there is no expectation of a manifest image with those particular OS's.
+		if !filter.DefaultOSFilter {
+			return resolvedImage{err: fmt.Errorf("failed to retrieve manifests from image %s: %v", from, err)}
+		}
+		runtimeOS := runtime.GOOS
+		arch := runtime.GOARCH
 
-				if o.ImageMetadataCallback != nil {
-					o.ImageMetadataCallback(&mapping, location.Manifest, contentDigest, imageConfig, location.ManifestListDigest())
+		klog.V(2).Infof("Warning: a manifest image could not be found for this platform: %s/%s. Converting runtime OS, %s, to 'linux' to pull the linux/%s equivalent image.\n", runtimeOS, arch, runtimeOS, arch)
+		filter.OSFilter, err = regexp.Compile("linux/" + arch)
+		if err != nil {
+			return resolvedImage{err: fmt.Errorf("failed to compile OSFilter for linux/%s: %v", arch, err)}
+		}
+
+		srcManifest, location, err = retrieveSourceManifest(ctx, from, repo, &filter)
+		if err != nil {
+			return resolvedImage{err: err}
+		}
+	}
+
+	contentDigest, err := registryclient.ContentDigestForManifest(srcManifest, location.Manifest.Algorithm())
+	if err != nil {
+		return resolvedImage{err: err}
+	}
+
+	imageConfig, layers, err := imagemanifest.ManifestToImageConfig(ctx, srcManifest, repo.Blobs(ctx), location)
+	if err != nil {
+		return resolvedImage{err: fmt.Errorf("unable to parse image %s: %v", from, err)}
+	}
+	return resolvedImage{
+		repo:          repo,
+		location:      location,
+		contentDigest: contentDigest,
+		imageConfig:   imageConfig,
+		layers:        layers,
+	}
+}
+
+// selectLayers returns the layers of the image the mapping extracts, or skipped if the
+// condition of the mapping excludes the image.
+func (o *ExtractOptions) selectLayers(mapping *Mapping, image *resolvedImage) (layers []distribution.Descriptor, skipped bool, err error) {
+	from := mapping.ImageRef
+	if mapping.ConditionFn != nil {
+		ok, err := mapping.ConditionFn(mapping, image.location.Manifest, image.imageConfig)
+		if err != nil {
+			return nil, false, fmt.Errorf("unable to check whether to include image %s: %v", from, err)
+		}
+		if !ok {
+			klog.V(2).Infof("Filtered out image %s with digest %s from being extracted", from, image.location.Manifest)
+			return nil, true, nil
+		}
+	}
+
+	layers = image.layers
+	if mapping.LayerFilter != nil {
+		layers, err = mapping.LayerFilter.Filter(layers)
+		if err != nil {
+			return nil, false, fmt.Errorf("unable to filter layers for %s: %v", from, err)
+		}
+	}
+	return layers, false, nil
+}
+
+// extractMapping extracts the layers of the image selected by the mapping.
+func (o *ExtractOptions) extractMapping(ctx context.Context, cache *layerCache, mapping *Mapping, image *resolvedImage, filteredLayers []distribution.Descriptor, out io.Writer) error {
+	from := mapping.ImageRef
+
+	var alter alterations
+	if o.OnlyFiles {
+		alter = append(alter, filesOnly{})
+	}
+	if len(mapping.From) > 0 {
+		switch {
+		case strings.HasSuffix(mapping.From, "/"):
+			alter = append(alter, newCopyFromDirectory(mapping.From))
+		default:
+			name, parent := path.Base(mapping.From), path.Dir(mapping.From)
+			if name != "." && parent == "." {
+				alter = append(alter, newCopyFromPattern(parent, name, true))
+			} else if name == "." || parent == "." {
+				return fmt.Errorf("unexpected directory from mapping %s", mapping.From)
+			} else {
+				alter = append(alter, newCopyFromPattern(parent, name, false))
+			}
+		}
+	}
+	if !o.PreservePermissions {
+		alter = append(alter, removePermissions{})
+	}
+
+	var byEntry TarEntryFunc = o.TarEntryCallback
+	if o.DryRun {
+		path := mapping.To
+		byEntry = func(hdr *tar.Header, layerInfo LayerInfo, r io.Reader) (bool, error) {
+			if len(hdr.Name) == 0 {
+				return true, nil
+			}
+			mode := hdr.FileInfo().Mode().String()
+			switch hdr.Typeflag {
+			case tar.TypeDir:
+				fmt.Fprintf(out, "%2d %s %12d %s\n", layerInfo.Index, mode, hdr.Size, filepath.Join(path, hdr.Name))
+			case tar.TypeReg, tar.TypeRegA:
+				fmt.Fprintf(out, "%2d %s %12d %s\n", layerInfo.Index, mode, hdr.Size, filepath.Join(path, hdr.Name))
+			case tar.TypeLink:
+				fmt.Fprintf(out, "%2d %s %12d %s -> %s\n", layerInfo.Index, mode, hdr.Size, hdr.Name, filepath.Join(path, hdr.Linkname))
+			case tar.TypeSymlink:
+				fmt.Fprintf(out, "%2d %s %12d %s -> %s\n", layerInfo.Index, mode, hdr.Size, hdr.Name, filepath.Join(path, hdr.Linkname))
+			default:
+				fmt.Fprintf(out, "%2d %s %12d %s %x\n", layerInfo.Index, mode, hdr.Size, filepath.Join(path, hdr.Name), hdr.Typeflag)
+			}
+			return true, nil
+		}
+	}
+
+	// walk the layers in reverse order, only showing a given path once
+	alreadySeen := make(map[string]struct{})
+	var layerInfos []LayerInfo
+	if byEntry != nil && !o.AllLayers {
+		for i := len(filteredLayers) - 1; i >= 0; i-- {
+			layerInfos = append(layerInfos, LayerInfo{Index: i, Descriptor: filteredLayers[i], Mapping: mapping})
+		}
+	} else {
+		for i := range filteredLayers {
+			layerInfos = append(layerInfos, LayerInfo{Index: i, Descriptor: filteredLayers[i], Mapping: mapping})
+		}
+	}
+
+	for _, info := range layerInfos {
+		layer := info.Descriptor
+
+		cont, err := func() (bool, error) {
+			fromBlobs := image.repo.Blobs(ctx)
+
+			klog.V(5).Infof("Extracting from layer: %#v", layer)
+
+			// source
+			r, err := cache.Open(ctx, fromBlobs, layer.Digest)
+			if err != nil {
+				return false, fmt.Errorf("unable to access the source layer %s: %v", layer.Digest, err)
+			}
+			defer r.Close()
+
+			options := &archive.TarOptions{
+				AlterHeaders: alter,
+				Chown:        o.PreservePermissions,
+				Checksums:    o.checksums,
+			}
+
+			if byEntry != nil {
+				cont, err := layerByEntry(r, options, info, byEntry, o.AllLayers, alreadySeen)
+				if err != nil {
+					err = fmt.Errorf("unable to iterate over layer %s from %s: %v", layer.Digest, from, err)
 				}
-				return nil
-			})
+				return cont, err
+			}
+
+			klog.V(4).Infof("Extracting layer %s with options %#v", layer.Digest, options)
+			if _, err := archive.ApplyLayer(mapping.To, r, options); err != nil {
+				return false, fmt.Errorf("unable to extract layer %s from %s: %v", layer.Digest, from, err)
+			}
+			return true, nil
+		}()
+		if err != nil {
+			return err
 		}
-	})
+		if !cont {
+			break
+		}
+	}
+
+	if o.ImageMetadataCallback != nil {
+		o.ImageMetadataCallback(mapping, image.location.Manifest, image.contentDigest, image.imageConfig, image.location.ManifestListDigest())
+	}
+	return nil
+}
+
+// printSummary reports the outcome of each mapping once all of them are processed.
+func (o *ExtractOptions) printSummary(statuses []mappingStatus) {
+	w := tabwriter.NewWriter(o.ErrOut, 0, 4, 1, ' ', 0)
+	fmt.Fprintf(w, "\nIMAGE\tDIGEST\tPATH\tSTATUS\n")
+	for i, status := range statuses {
+		mapping := o.Mappings[i]
+		dgst := "-"
+		if status.image.err == nil {
+			dgst = status.image.location.Manifest.String()
+		}
+		result := "Extracted"
+		switch {
+		case status.err != nil:
+			result = fmt.Sprintf("Failed: %v", status.err)
+		case status.skipped:
+			result = "Skipped"
+		}
+		fmt.Fprintf(w, "%s\t%s\t/%s:%s\t%s\n", mapping.Image, dgst, mapping.From, mapping.To, result)
+	}
+	w.Flush()
+}
+
+// checksumRoot returns the directory the checksums of the files extracted to the destinations are
+// relative to: the destination, or the closest directory holding all of them.
+func checksumRoot(destinations []string) string {
+	var root string
+	for i, to := range destinations {
+		to = filepath.Clean(to)
+		if i == 0 {
+			root = to
+			continue
+		}
+		for root != filepath.Dir(root) {
+			rel, err := filepath.Rel(root, to)
+			if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+				break
+			}
+			root = filepath.Dir(root)
+		}
+	}
+	return root
+}
+
+// writeChecksums verifies that the extracted files on disk match the digest of their content in the
+// layers, and writes their checksums to file in the format of sha256sum, relative to root so that
+// they can be checked from there.
+func writeChecksums(file, root string, files map[string]digest.Digest) error {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	buf := &bytes.Buffer{}
+	for _, path := range paths {
+		dgst := files[path]
+		verified, err := func() (bool, error) {
+			f, err := os.Open(path)
+			if err != nil {
+				return false, err
+			}
+			defer f.Close()
+			verifier := dgst.Verifier()
+			if _, err := io.Copy(verifier, f); err != nil {
+				return false, err
+			}
+			return verifier.Verified(), nil
+		}()
+		if err != nil {
+			return fmt.Errorf("unable to verify the extracted file %s: %v", path, err)
+		}
+		if !verified {
+			return fmt.Errorf("the extracted file %s does not match the content of the image, expected %s", path, dgst)
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return fmt.Errorf("unable to write the checksum of the extracted file %s: %v", path, err)
+		}
+		fmt.Fprintf(buf, "%s  %s\n", dgst.Encoded(), rel)
+	}
+	if err := os.WriteFile(file, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("unable to write the checksums of the extracted files: %v", err)
+	}
+	return nil
 }
 
 // retrieveSourceManifest retrieves the first manifest at the request location that matches the filter function and handles any errors resulting from retrieving the manifest
-func retrieveSourceManifest(ctx context.Context, from imagesource.TypedImageReference, repo distribution.Repository, o *ExtractOptions) (distribution.Manifest, imagemanifest.ManifestLocation, error) {
-	srcManifest, location, err := imagemanifest.FirstManifest(ctx, from.Ref, repo, o.FilterOptions.Include)
+func retrieveSourceManifest(ctx context.Context, from imagesource.TypedImageReference, repo distribution.Repository, filter *imagemanifest.FilterOptions) (distribution.Manifest, imagemanifest.ManifestLocation, error) {
+	srcManifest, location, err := imagemanifest.FirstManifest(ctx, from.Ref, repo, filter.Include)
 	if err != nil {
 		emptyManifestLocation := imagemanifest.ManifestLocation{}
 		if imagemanifest.IsImageForbidden(err) {
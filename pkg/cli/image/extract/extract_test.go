@@ -0,0 +1,145 @@
+package extract
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+
+	"github.com/openshift/oc/pkg/cli/image/archive"
+)
+
+// testLayer returns an uncompressed layer holding the files, the hard links and the
+// whiteouts, each an entry of name to content, link target or nothing.
+func testLayer(t *testing.T, files, links map[string]string, whiteouts []string) *bytes.Buffer {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	w := tar.NewWriter(buf)
+	for name, content := range files {
+		if err := w.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, target := range links {
+		if err := w.WriteHeader(&tar.Header{Typeflag: tar.TypeLink, Name: name, Linkname: target, Mode: 0644}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range whiteouts {
+		whiteout := filepath.Join(filepath.Dir(name), ".wh."+filepath.Base(name))
+		if err := w.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: whiteout, Mode: 0644}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func TestWriteChecksums(t *testing.T) {
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "dest")
+	if err := os.Mkdir(dest, 0755); err != nil {
+		t.Fatal(err)
+	}
+	checksums := archive.NewChecksums()
+	layers := []*bytes.Buffer{
+		testLayer(t, map[string]string{"etc/config": "one", "usr/bin/tool": "tool", "tmp/removed": "removed"}, nil, nil),
+		testLayer(t, map[string]string{"etc/config": "two"}, map[string]string{"usr/bin/link": "usr/bin/tool"}, []string{"tmp/removed"}),
+	}
+	for _, layer := range layers {
+		if _, err := archive.ApplyLayer(dest, layer, &archive.TarOptions{Checksums: checksums}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// run from another directory, the paths are relative to the destination
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(cwd)
+
+	file := filepath.Join(dir, "sha256sum.txt")
+	if err := writeChecksums(file, checksumRoot([]string{dest}), checksums.Files()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Join([]string{
+		digest.FromString("two").Encoded() + "  etc/config",
+		digest.FromString("tool").Encoded() + "  usr/bin/link",
+		digest.FromString("tool").Encoded() + "  usr/bin/tool",
+	}, "\n") + "\n"
+	if string(data) != expected {
+		t.Errorf("expected the checksums\n%s\ngot\n%s", expected, data)
+	}
+
+	// a file changed on disk is not written
+	if err := os.WriteFile(filepath.Join(dest, "etc", "config"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = writeChecksums(file, dest, checksums.Files())
+	if err == nil || !strings.Contains(err.Error(), "does not match the content of the image") {
+		t.Errorf("expected the changed file to be reported, got %v", err)
+	}
+}
+
+func TestChecksumRoot(t *testing.T) {
+	tests := []struct {
+		name         string
+		destinations []string
+		expected     string
+	}{
+		{
+			name:         "one destination",
+			destinations: []string{"/data/images/app/"},
+			expected:     "/data/images/app",
+		},
+		{
+			name:         "same destination",
+			destinations: []string{"/data/app", "/data/app"},
+			expected:     "/data/app",
+		},
+		{
+			name:         "sibling destinations",
+			destinations: []string{"/data/images/app", "/data/images/db", "/data/images/cache"},
+			expected:     "/data/images",
+		},
+		{
+			name:         "nested destinations",
+			destinations: []string{"/data/images/app/bin", "/data/images"},
+			expected:     "/data/images",
+		},
+		{
+			name:         "prefix of a name",
+			destinations: []string{"/data/app", "/data/app2"},
+			expected:     "/data",
+		},
+		{
+			name:         "unrelated destinations",
+			destinations: []string{"/data/app", "/srv/db"},
+			expected:     "/",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if root := checksumRoot(test.destinations); root != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, root)
+			}
+		})
+	}
+}
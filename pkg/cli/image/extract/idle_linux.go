@@ -0,0 +1,119 @@
+package extract
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// busyPressure is the share of the last 10 seconds, in percent, in which tasks of the host
+	// stalled on the CPU or on IO above which the host is busy.
+	busyPressure = 20.0
+
+	ioprioWhoProcess = 1
+	ioprioClassIdle  = 3
+	ioprioClassShift = 13
+)
+
+// lowerPriority runs the process with the lowest CPU priority and in the idle IO scheduling
+// class, so that it only uses the CPU and the disks when the other processes do not. Linux sets
+// both priorities per thread, so they are set for every thread of the process, until no new
+// thread is found. The threads started later inherit them from the thread creating them.
+func lowerPriority() error {
+	lowered := map[int]bool{}
+	for {
+		tids, err := threads()
+		if err != nil {
+			return fmt.Errorf("unable to lower the priority: %v", err)
+		}
+		found := false
+		for _, tid := range tids {
+			if lowered[tid] {
+				continue
+			}
+			found = true
+			lowered[tid] = true
+			// a thread may exit before its priority is set
+			if err := unix.Setpriority(unix.PRIO_PROCESS, tid, 19); err != nil && err != unix.ESRCH {
+				return fmt.Errorf("unable to lower the CPU priority: %v", err)
+			}
+			if _, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), ioprioClassIdle<<ioprioClassShift); errno != 0 && errno != unix.ESRCH {
+				return fmt.Errorf("unable to lower the IO priority: %v", errno)
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+}
+
+// threads returns the IDs of the threads of the process.
+func threads() ([]int, error) {
+	entries, err := os.ReadDir("/proc/self/task")
+	if err != nil {
+		return nil, err
+	}
+	var tids []int
+	for _, entry := range entries {
+		if tid, err := strconv.Atoi(entry.Name()); err == nil {
+			tids = append(tids, tid)
+		}
+	}
+	return tids, nil
+}
+
+// hostBusy returns whether tasks of the host are stalling on the CPU or on IO, from the
+// pressure stall information of the kernel, or, without it, whether the load of the last
+// minute exceeds the number of CPUs.
+func hostBusy() bool {
+	found := false
+	for _, resource := range []string{"cpu", "io"} {
+		pressure, ok := someAvg10("/proc/pressure/" + resource)
+		if !ok {
+			continue
+		}
+		found = true
+		if pressure > busyPressure {
+			return true
+		}
+	}
+	if found {
+		return false
+	}
+	data, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return false
+	}
+	load, err := strconv.ParseFloat(fields[0], 64)
+	return err == nil && load > float64(runtime.NumCPU())
+}
+
+// someAvg10 reads the avg10 value of the "some" line of a pressure file, such as
+// "some avg10=1.53 avg60=0.87 avg300=0.32 total=1234".
+func someAvg10(path string) (float64, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "some" || !strings.HasPrefix(fields[1], "avg10=") {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimPrefix(fields[1], "avg10="), 64)
+		return value, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,58 @@
+package extract
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestLowerPriority(t *testing.T) {
+	// the priority of a process cannot be raised again, so it is lowered in a child process
+	if os.Getenv("TEST_LOWER_PRIORITY") != "1" {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestLowerPriority$")
+		cmd.Env = append(os.Environ(), "TEST_LOWER_PRIORITY=1")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v: %s", err, out)
+		}
+		return
+	}
+
+	if err := lowerPriority(); err != nil {
+		t.Fatal(err)
+	}
+	// start threads after the priority was lowered
+	var wg sync.WaitGroup
+	release := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			runtime.LockOSThread()
+			wg.Done()
+			<-release
+		}()
+	}
+	wg.Wait()
+	defer close(release)
+
+	tids, err := threads()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tids) < 5 {
+		t.Fatalf("expected at least 5 threads, found %v", tids)
+	}
+	for _, tid := range tids {
+		// the kernel returns 20 - nice
+		prio, err := unix.Getpriority(unix.PRIO_PROCESS, tid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prio != 1 {
+			t.Errorf("thread %d has nice value %d, expected 19", tid, 20-prio)
+		}
+	}
+}
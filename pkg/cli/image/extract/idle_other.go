@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package extract
+
+// lowerPriority only lowers the priority of the process on Linux.
+func lowerPriority() error {
+	return nil
+}
+
+// hostBusy only detects whether the host is busy on Linux.
+func hostBusy() bool {
+	return false
+}
@@ -0,0 +1,143 @@
+package extract
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+)
+
+const (
+	// throttleChunkSize bounds how much of a layer is read between checks of the bandwidth
+	// limit and of whether the host is busy, so that slow limits are enforced smoothly.
+	throttleChunkSize = 32 * 1024
+	// busyCheckInterval is how often whether the host is busy is checked, and how long the
+	// reads are paused before checking again.
+	busyCheckInterval = time.Second
+)
+
+// throttle slows down the reads of the layers downloaded from the registries, shared by all
+// the layers read at the same time: to at most a number of bytes per second and, in idle
+// priority, pausing while the host is busy.
+type throttle struct {
+	// limiter limits the bandwidth, if set
+	limiter *rate.Limiter
+	// busy returns whether the host is busy, if the reads are paused while it is
+	busy   func() bool
+	errOut io.Writer
+	clock  clock.Clock
+
+	lock        sync.Mutex
+	checked     time.Time
+	wasBusy     bool
+	pausedSince time.Time
+}
+
+// newThrottle returns a throttle limiting the reads to maxBandwidth bytes per second, unless
+// it is zero, and pausing them while the host is busy in idle priority. It returns nil if the
+// reads are not throttled.
+func newThrottle(maxBandwidth int64, idlePriority bool, errOut io.Writer) *throttle {
+	if maxBandwidth <= 0 && !idlePriority {
+		return nil
+	}
+	t := &throttle{errOut: errOut, clock: clock.RealClock{}}
+	if maxBandwidth > 0 {
+		t.limiter = rate.NewLimiter(rate.Limit(maxBandwidth), throttleChunkSize)
+	}
+	if idlePriority {
+		t.busy = hostBusy
+	}
+	return t
+}
+
+// Reader returns a reader whose reads from r are throttled, or r if t is nil.
+func (t *throttle) Reader(ctx context.Context, r io.ReadCloser) io.ReadCloser {
+	if t == nil {
+		return r
+	}
+	return &throttledReader{ctx: ctx, throttle: t, r: r}
+}
+
+// wait waits until the host is no longer busy, then until n more bytes may have been read.
+func (t *throttle) wait(ctx context.Context, n int) error {
+	if t.busy != nil {
+		for t.isBusy() {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-t.clock.After(busyCheckInterval):
+			}
+		}
+	}
+	if t.limiter == nil {
+		return nil
+	}
+	now := t.clock.Now()
+	reservation := t.limiter.ReserveN(now, n)
+	if !reservation.OK() {
+		return fmt.Errorf("unable to read %d bytes at once, more than the limit of %d", n, t.limiter.Burst())
+	}
+	delay := reservation.DelayFrom(now)
+	if delay == 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		reservation.CancelAt(t.clock.Now())
+		return ctx.Err()
+	case <-t.clock.After(delay):
+		return nil
+	}
+}
+
+// isBusy returns whether the host is busy, checking it at most once per busyCheckInterval, and
+// reports when the extraction pauses and resumes.
+func (t *throttle) isBusy() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	now := t.clock.Now()
+	if now.Sub(t.checked) < busyCheckInterval {
+		return t.wasBusy
+	}
+	t.checked = now
+
+	busy := t.busy()
+	switch {
+	case busy && !t.wasBusy:
+		t.pausedSince = now
+		fmt.Fprintf(t.errOut, "info: The host is busy, pausing the extraction\n")
+	case !busy && t.wasBusy:
+		klog.V(2).Infof("The host is no longer busy, resuming the extraction after %s", now.Sub(t.pausedSince).Round(time.Second))
+	}
+	t.wasBusy = busy
+	return busy
+}
+
+type throttledReader struct {
+	ctx      context.Context
+	throttle *throttle
+	r        io.ReadCloser
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > throttleChunkSize {
+		p = p[:throttleChunkSize]
+	}
+	// wait after the read, for the bytes actually read
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if err := r.throttle.wait(r.ctx, n); err != nil {
+			return n, err
+		}
+	}
+	return n, err
+}
+
+func (r *throttledReader) Close() error {
+	return r.r.Close()
+}
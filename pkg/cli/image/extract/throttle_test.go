@@ -0,0 +1,176 @@
+package extract
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// readThrottled reads all of data through the throttle, stepping the fake clock by a millisecond
+// whenever the reads wait on it, and returns how long the reads took in the time of the clock.
+func readThrottled(t *testing.T, ctx context.Context, th *throttle, clock *clocktesting.FakeClock, data []byte) (time.Duration, error) {
+	start := clock.Now()
+	done := make(chan error, 1)
+	go func() {
+		read, err := readChunks(th.Reader(ctx, io.NopCloser(bytes.NewReader(data))))
+		if err == nil && !bytes.Equal(read, data) {
+			err = errors.New("unexpected content")
+		}
+		done <- err
+	}()
+	timeout := time.Now().Add(30 * time.Second)
+	for {
+		select {
+		case err := <-done:
+			return clock.Since(start), err
+		default:
+		}
+		if time.Now().After(timeout) {
+			t.Fatal("timed out reading through the throttle")
+		}
+		if clock.HasWaiters() {
+			clock.Step(time.Millisecond)
+			continue
+		}
+		time.Sleep(50 * time.Microsecond)
+	}
+}
+
+// readChunks reads r in chunks of throttleChunkSize bytes.
+func readChunks(r io.Reader) ([]byte, error) {
+	var read []byte
+	buf := make([]byte, throttleChunkSize)
+	for {
+		n, err := r.Read(buf)
+		read = append(read, buf[:n]...)
+		if err == io.EOF {
+			return read, nil
+		}
+		if err != nil {
+			return read, err
+		}
+	}
+}
+
+func TestThrottleBandwidth(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxBandwidth int64
+		size         int
+		min, max     time.Duration
+	}{
+		{
+			name:         "within burst",
+			maxBandwidth: 64 * 1024,
+			size:         throttleChunkSize,
+			max:          0,
+		},
+		{
+			name:         "64KiB per second",
+			maxBandwidth: 64 * 1024,
+			size:         256 * 1024,
+			// the first chunk is read from the burst
+			min: 3500 * time.Millisecond,
+			max: 3600 * time.Millisecond,
+		},
+		{
+			name:         "1MiB per second",
+			maxBandwidth: 1024 * 1024,
+			size:         4 * 1024 * 1024,
+			min:          3968 * time.Millisecond,
+			max:          4100 * time.Millisecond,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clock := clocktesting.NewFakeClock(time.Now())
+			th := newThrottle(test.maxBandwidth, false, io.Discard)
+			th.clock = clock
+			elapsed, err := readThrottled(t, context.Background(), th, clock, make([]byte, test.size))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if elapsed < test.min || elapsed > test.max {
+				t.Errorf("expected the reads to take between %s and %s, took %s", test.min, test.max, elapsed)
+			}
+		})
+	}
+}
+
+func TestThrottleBusy(t *testing.T) {
+	clock := clocktesting.NewFakeClock(time.Now())
+	busyUntil := clock.Now().Add(5 * time.Second)
+	errOut := &bytes.Buffer{}
+	th := newThrottle(0, true, errOut)
+	th.clock = clock
+	var lock sync.Mutex
+	checks := 0
+	th.busy = func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		checks++
+		return clock.Now().Before(busyUntil)
+	}
+
+	elapsed, err := readThrottled(t, context.Background(), th, clock, make([]byte, 4*throttleChunkSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed < 5*time.Second || elapsed > 6*time.Second {
+		t.Errorf("expected the reads to pause for 5s, took %s", elapsed)
+	}
+	// the host is checked at most once per busyCheckInterval
+	if checks < 5 || checks > 7 {
+		t.Errorf("expected the host to be checked once per second, checked %d times", checks)
+	}
+	if !strings.Contains(errOut.String(), "The host is busy, pausing the extraction") {
+		t.Errorf("expected the pause to be reported, got %q", errOut.String())
+	}
+}
+
+func TestThrottleCanceled(t *testing.T) {
+	clock := clocktesting.NewFakeClock(time.Now())
+	th := newThrottle(1024, false, io.Discard)
+	th.clock = clock
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := readChunks(th.Reader(ctx, io.NopCloser(bytes.NewReader(make([]byte, 2*throttleChunkSize)))))
+		done <- err
+	}()
+	for !clock.HasWaiters() {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("expected the reads to be canceled, got %v", err)
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("the canceled reads did not return")
+	}
+	// the canceled reservation is given back to the limiter
+	if tokens := th.limiter.TokensAt(clock.Now()); tokens < 0 {
+		t.Errorf("expected the canceled reservation to be restored, the limiter has %v tokens", tokens)
+	}
+}
+
+func TestNewThrottle(t *testing.T) {
+	if th := newThrottle(0, false, io.Discard); th != nil {
+		t.Errorf("expected no throttle, got %#v", th)
+	}
+	r := io.NopCloser(strings.NewReader("data"))
+	var th *throttle
+	if th.Reader(context.Background(), r) != r {
+		t.Errorf("expected the reads not to be throttled")
+	}
+}
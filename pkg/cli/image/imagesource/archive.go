@@ -0,0 +1,569 @@
+package imagesource
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sync"
+
+	"k8s.io/klog/v2"
+
+	man "github.com/containers/image/v5/manifest"
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/ocischema"
+	"github.com/distribution/distribution/v3/reference"
+	godigest "github.com/opencontainers/go-digest"
+	imagespecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	imagereference "github.com/openshift/library-go/pkg/image/reference"
+)
+
+// archiveSource provides the images of an archive repository.
+type archiveSource interface {
+	// Image adds the image of the tag to the repository and returns the descriptor of its
+	// manifest.
+	Image(ctx context.Context, r *archiveRepository, tag string) (distribution.Descriptor, error)
+	// Tags returns the tags of the images of the repository.
+	Tags(ctx context.Context) ([]string, error)
+}
+
+// archiveRepository is a read-only repository of the images of a tar archive, like the
+// single-file exports of 'docker save' or 'podman save', whose files are read in place.
+type archiveRepository struct {
+	named  reference.Named
+	source archiveSource
+	// noun describes the images in the errors
+	noun string
+
+	lock      sync.Mutex
+	tags      map[string]distribution.Descriptor
+	manifests map[godigest.Digest]distribution.Manifest
+	blobs     map[godigest.Digest]archiveBlob
+}
+
+func newArchiveRepository(repoName, noun string, source archiveSource) (*archiveRepository, error) {
+	named, err := reference.ParseNormalizedNamed(repoName)
+	if err != nil {
+		return nil, err
+	}
+	return &archiveRepository{
+		named:     named,
+		source:    source,
+		noun:      noun,
+		tags:      make(map[string]distribution.Descriptor),
+		manifests: make(map[godigest.Digest]distribution.Manifest),
+		blobs:     make(map[godigest.Digest]archiveBlob),
+	}, nil
+}
+
+// add registers an image of the archive.
+func (r *archiveRepository) add(tag string, desc distribution.Descriptor, manifest distribution.Manifest, blobs map[godigest.Digest]archiveBlob) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for dgst, blob := range blobs {
+		r.blobs[dgst] = blob
+	}
+	if manifest != nil {
+		r.manifests[desc.Digest] = manifest
+	}
+	r.tags[tag] = desc
+}
+
+// Named returns the name of the repository.
+func (r *archiveRepository) Named() reference.Named {
+	return r.named
+}
+
+// Manifests returns a reference to this repository's manifest service.
+func (r *archiveRepository) Manifests(ctx context.Context, options ...distribution.ManifestServiceOption) (distribution.ManifestService, error) {
+	return &archiveManifestService{r: r}, nil
+}
+
+// Blobs returns a reference to this repository's blob service.
+func (r *archiveRepository) Blobs(ctx context.Context) distribution.BlobStore {
+	return &archiveBlobStore{r: r}
+}
+
+// Tags returns a reference to this repositories tag service
+func (r *archiveRepository) Tags(ctx context.Context) distribution.TagService {
+	return &archiveTagStore{r: r}
+}
+
+// archiveBlob is a file of an archive, at offset of the archive.
+type archiveBlob struct {
+	archive *os.File
+	offset  int64
+	size    int64
+}
+
+func (b archiveBlob) reader() *io.SectionReader {
+	return io.NewSectionReader(b.archive, b.offset, b.size)
+}
+
+// archiveFiles are the regular files and the symbolic links of an archive.
+type archiveFiles struct {
+	files map[string]archiveBlob
+	links map[string]string
+}
+
+// indexArchive reads the tar archive of r, whose content is that of f, and returns where
+// its files are in f.
+func indexArchive(r io.Reader, f *os.File) (*archiveFiles, error) {
+	index := &archiveFiles{files: make(map[string]archiveBlob), links: make(map[string]string)}
+	counter := &countingReader{r: r}
+	tr := tar.NewReader(counter)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return index, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+			// archive/tar reads no further than the header, the content of the file follows
+			offset := counter.n
+			if _, err := io.Copy(io.Discard, tr); err != nil {
+				return nil, err
+			}
+			index.files[path.Clean(hdr.Name)] = archiveBlob{archive: f, offset: offset, size: hdr.Size}
+		case tar.TypeSymlink:
+			index.links[path.Clean(hdr.Name)] = path.Join(path.Dir(hdr.Name), hdr.Linkname)
+		}
+	}
+}
+
+// file returns the file of the archive at name, following the symbolic links.
+func (a *archiveFiles) file(name string) (archiveBlob, error) {
+	name = path.Clean(name)
+	for i := 0; i < 10; i++ {
+		target, ok := a.links[name]
+		if !ok {
+			break
+		}
+		name = target
+	}
+	blob, ok := a.files[name]
+	if !ok {
+		return archiveBlob{}, fmt.Errorf("the archive has no file %s", name)
+	}
+	return blob, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// dockerArchiveImage is an image of the manifest.json of a 'docker save' archive.
+type dockerArchiveImage struct {
+	Config   string
+	RepoTags []string
+	Layers   []string
+}
+
+// dockerArchiveImages returns the images of a 'docker save' archive.
+func dockerArchiveImages(files *archiveFiles) ([]dockerArchiveImage, error) {
+	manifestFile, err := files.file("manifest.json")
+	if err != nil {
+		return nil, err
+	}
+	var images []dockerArchiveImage
+	if err := json.NewDecoder(manifestFile.reader()).Decode(&images); err != nil {
+		return nil, fmt.Errorf("unable to read manifest.json: %v", err)
+	}
+	return images, nil
+}
+
+// selectDockerArchiveImage returns the image of the archive tagged name, or the only image
+// of the archive.
+func selectDockerArchiveImage(images []dockerArchiveImage, name string) (dockerArchiveImage, error) {
+	if want, err := imagereference.Parse(name); err == nil {
+		for _, image := range images {
+			for _, repoTag := range image.RepoTags {
+				if ref, err := imagereference.Parse(repoTag); err == nil && ref.DockerClientDefaults() == want.DockerClientDefaults() {
+					return image, nil
+				}
+			}
+		}
+	}
+	if len(images) == 1 {
+		return images[0], nil
+	}
+	return dockerArchiveImage{}, fmt.Errorf("expected the archive to contain %s or a single image, got %d images", name, len(images))
+}
+
+// addDockerArchiveImage adds an image of a 'docker save' archive to the repository as an
+// OCI image, since the archive has the files of the image but not its manifest.
+func addDockerArchiveImage(r *archiveRepository, files *archiveFiles, image dockerArchiveImage, tag string) (distribution.Descriptor, error) {
+	blobs := make(map[godigest.Digest]archiveBlob)
+	descriptor := func(name, mediaType string) (distribution.Descriptor, error) {
+		blob, err := files.file(name)
+		if err != nil {
+			return distribution.Descriptor{}, err
+		}
+		h := sha256.New()
+		if _, err := io.Copy(h, blob.reader()); err != nil {
+			return distribution.Descriptor{}, err
+		}
+		dgst := godigest.NewDigest(godigest.SHA256, h)
+		blobs[dgst] = blob
+		return distribution.Descriptor{MediaType: mediaType, Digest: dgst, Size: blob.size}, nil
+	}
+
+	var err error
+	m := ocischema.Manifest{Versioned: ocischema.SchemaVersion}
+	if m.Config, err = descriptor(image.Config, imagespecv1.MediaTypeImageConfig); err != nil {
+		return distribution.Descriptor{}, err
+	}
+	for _, layer := range image.Layers {
+		desc, err := descriptor(layer, imagespecv1.MediaTypeImageLayer)
+		if err != nil {
+			return distribution.Descriptor{}, err
+		}
+		magic := make([]byte, 2)
+		if _, err := blobs[desc.Digest].reader().ReadAt(magic, 0); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+			desc.MediaType = imagespecv1.MediaTypeImageLayerGzip
+		}
+		m.Layers = append(m.Layers, desc)
+	}
+	manifest, err := ocischema.FromStruct(m)
+	if err != nil {
+		return distribution.Descriptor{}, err
+	}
+	_, payload, err := manifest.Payload()
+	if err != nil {
+		return distribution.Descriptor{}, err
+	}
+	desc := distribution.Descriptor{MediaType: imagespecv1.MediaTypeImageManifest, Digest: godigest.FromBytes(payload), Size: int64(len(payload))}
+	r.add(tag, desc, manifest, blobs)
+	return desc, nil
+}
+
+// dockerArchiveSource provides the images of a docker-archive: reference.
+type dockerArchiveSource struct {
+	path     string
+	repoName string
+
+	once  sync.Once
+	files *archiveFiles
+	err   error
+}
+
+func (s *dockerArchiveSource) index() (*archiveFiles, error) {
+	s.once.Do(func() {
+		s.files, s.err = openArchive(s.path)
+	})
+	return s.files, s.err
+}
+
+func (s *dockerArchiveSource) Image(ctx context.Context, r *archiveRepository, tag string) (distribution.Descriptor, error) {
+	files, err := s.index()
+	if err != nil {
+		return distribution.Descriptor{}, err
+	}
+	images, err := dockerArchiveImages(files)
+	if err != nil {
+		return distribution.Descriptor{}, fmt.Errorf("%s: %v", s.path, err)
+	}
+	image, err := selectDockerArchiveImage(images, s.repoName+":"+tag)
+	if err != nil {
+		return distribution.Descriptor{}, fmt.Errorf("%s: %v", s.path, err)
+	}
+	return addDockerArchiveImage(r, files, image, tag)
+}
+
+func (s *dockerArchiveSource) Tags(ctx context.Context) ([]string, error) {
+	files, err := s.index()
+	if err != nil {
+		return nil, err
+	}
+	images, err := dockerArchiveImages(files)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", s.path, err)
+	}
+	var repoTags []string
+	for _, image := range images {
+		repoTags = append(repoTags, image.RepoTags...)
+	}
+	return tagsOfRepository(s.repoName, repoTags), nil
+}
+
+// ociArchiveSource provides the images of an oci-archive: reference, the images of the
+// index.json of the archive being tagged with their org.opencontainers.image.ref.name.
+type ociArchiveSource struct {
+	path string
+
+	once  sync.Once
+	files *archiveFiles
+	index []imagespecv1.Descriptor
+	err   error
+}
+
+func (s *ociArchiveSource) load() error {
+	s.once.Do(func() {
+		if s.files, s.err = openArchive(s.path); s.err != nil {
+			return
+		}
+		indexFile, err := s.files.file("index.json")
+		if err != nil {
+			s.err = fmt.Errorf("%s is not an OCI archive: %v", s.path, err)
+			return
+		}
+		var index imagespecv1.Index
+		if err := json.NewDecoder(indexFile.reader()).Decode(&index); err != nil {
+			s.err = fmt.Errorf("unable to read the index.json of %s: %v", s.path, err)
+			return
+		}
+		s.index = index.Manifests
+	})
+	return s.err
+}
+
+func (s *ociArchiveSource) Image(ctx context.Context, r *archiveRepository, tag string) (distribution.Descriptor, error) {
+	if err := s.load(); err != nil {
+		return distribution.Descriptor{}, err
+	}
+	var found *imagespecv1.Descriptor
+	for i, desc := range s.index {
+		if desc.Annotations[imagespecv1.AnnotationRefName] == tag {
+			found = &s.index[i]
+			break
+		}
+	}
+	if found == nil && len(s.index) == 1 {
+		found = &s.index[0]
+	}
+	if found == nil {
+		return distribution.Descriptor{}, fmt.Errorf("expected %s to contain an image named %s or a single image, got %d images", s.path, tag, len(s.index))
+	}
+
+	// the blobs of OCI archives are stored by digest, including the manifests
+	blobs := make(map[godigest.Digest]archiveBlob)
+	for name, blob := range s.files.files {
+		if dir, encoded := path.Split(path.Clean(name)); path.Dir(path.Clean(dir)) == "blobs" {
+			dgst := godigest.NewDigestFromEncoded(godigest.Algorithm(path.Base(dir)), encoded)
+			if dgst.Validate() == nil {
+				blobs[dgst] = blob
+			}
+		}
+	}
+	desc := distribution.Descriptor{MediaType: found.MediaType, Digest: found.Digest, Size: found.Size}
+	r.add(tag, desc, nil, blobs)
+	return desc, nil
+}
+
+func (s *ociArchiveSource) Tags(ctx context.Context) ([]string, error) {
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	var tags []string
+	for _, desc := range s.index {
+		if name := desc.Annotations[imagespecv1.AnnotationRefName]; len(name) > 0 {
+			tags = append(tags, name)
+		}
+	}
+	return tags, nil
+}
+
+var (
+	openArchivesLock sync.Mutex
+	openArchives     = make(map[string]*archiveFiles)
+)
+
+// openArchive opens and indexes the archive at path, which stays open until oc exits so
+// that the repositories of the same archive share it.
+func openArchive(path string) (*archiveFiles, error) {
+	openArchivesLock.Lock()
+	defer openArchivesLock.Unlock()
+	if files, ok := openArchives[path]; ok {
+		return files, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	files, err := indexArchive(f, f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("unable to read the archive %s: %v", path, err)
+	}
+	klog.V(5).Infof("Indexed %d files of %s", len(files.files), path)
+	openArchives[path] = files
+	return files, nil
+}
+
+// tagsOfRepository returns the tags of the references which are in the repository.
+func tagsOfRepository(repoName string, refs []string) []string {
+	repo, err := imagereference.Parse(repoName)
+	if err != nil {
+		return nil
+	}
+	var tags []string
+	for _, s := range refs {
+		ref, err := imagereference.Parse(s)
+		if err != nil || len(ref.Tag) == 0 {
+			continue
+		}
+		if ref.DockerClientDefaults().AsRepository() == repo.DockerClientDefaults().AsRepository() {
+			tags = append(tags, ref.Tag)
+		}
+	}
+	return tags
+}
+
+type archiveTagStore struct {
+	r *archiveRepository
+}
+
+// Get returns the descriptor of the manifest of the image of the tag.
+func (s *archiveTagStore) Get(ctx context.Context, tag string) (distribution.Descriptor, error) {
+	s.r.lock.Lock()
+	desc, ok := s.r.tags[tag]
+	s.r.lock.Unlock()
+	if ok {
+		return desc, nil
+	}
+	return s.r.source.Image(ctx, s.r, tag)
+}
+
+// Tag associates the tag with the provided descriptor, updating the
+// current association, if needed.
+func (s *archiveTagStore) Tag(ctx context.Context, tag string, desc distribution.Descriptor) error {
+	return fmt.Errorf("tagging %s is not supported", s.r.noun)
+}
+
+// Untag removes the given tag association
+func (s *archiveTagStore) Untag(ctx context.Context, tag string) error {
+	return fmt.Errorf("removing tags from %s is not supported", s.r.noun)
+}
+
+// All returns the set of tags managed by this tag service
+func (s *archiveTagStore) All(ctx context.Context) ([]string, error) {
+	return s.r.source.Tags(ctx)
+}
+
+// Lookup returns the set of tags referencing the given digest.
+func (s *archiveTagStore) Lookup(ctx context.Context, digest distribution.Descriptor) ([]string, error) {
+	return nil, fmt.Errorf("retrieving tags for a digest of %s is not supported", s.r.noun)
+}
+
+type archiveManifestService struct {
+	r *archiveRepository
+}
+
+// Exists returns true if the manifest exists.
+func (s *archiveManifestService) Exists(ctx context.Context, dgst godigest.Digest) (bool, error) {
+	s.r.lock.Lock()
+	defer s.r.lock.Unlock()
+	_, isManifest := s.r.manifests[dgst]
+	_, isBlob := s.r.blobs[dgst]
+	return isManifest || isBlob, nil
+}
+
+// Get returns the manifest of an image, which must have been looked up by tag first.
+func (s *archiveManifestService) Get(ctx context.Context, dgst godigest.Digest, options ...distribution.ManifestServiceOption) (distribution.Manifest, error) {
+	s.r.lock.Lock()
+	manifest, ok := s.r.manifests[dgst]
+	blob, isBlob := s.r.blobs[dgst]
+	s.r.lock.Unlock()
+	if ok {
+		return manifest, nil
+	}
+	if !isBlob {
+		return nil, fmt.Errorf("%s must be referenced by tag, %s has not been found", s.r.noun, dgst)
+	}
+	data, err := io.ReadAll(blob.reader())
+	if err != nil {
+		return nil, err
+	}
+	manifest, _, err = distribution.UnmarshalManifest(man.GuessMIMEType(data), data)
+	return manifest, err
+}
+
+func (s *archiveManifestService) Put(ctx context.Context, manifest distribution.Manifest, options ...distribution.ManifestServiceOption) (godigest.Digest, error) {
+	return "", fmt.Errorf("%s can only be used as sources", s.r.noun)
+}
+
+func (s *archiveManifestService) Delete(ctx context.Context, dgst godigest.Digest) error {
+	return fmt.Errorf("%s can only be used as sources", s.r.noun)
+}
+
+type archiveBlobStore struct {
+	r *archiveRepository
+}
+
+func (s *archiveBlobStore) blob(dgst godigest.Digest) (archiveBlob, error) {
+	s.r.lock.Lock()
+	defer s.r.lock.Unlock()
+	blob, ok := s.r.blobs[dgst]
+	if !ok {
+		return archiveBlob{}, distribution.ErrBlobUnknown
+	}
+	return blob, nil
+}
+
+func (s *archiveBlobStore) Stat(ctx context.Context, dgst godigest.Digest) (distribution.Descriptor, error) {
+	blob, err := s.blob(dgst)
+	if err != nil {
+		return distribution.Descriptor{}, err
+	}
+	return distribution.Descriptor{Digest: dgst, Size: blob.size}, nil
+}
+
+func (s *archiveBlobStore) Delete(ctx context.Context, dgst godigest.Digest) error {
+	return fmt.Errorf("%s can only be used as sources", s.r.noun)
+}
+
+func (s *archiveBlobStore) Get(ctx context.Context, dgst godigest.Digest) ([]byte, error) {
+	blob, err := s.blob(dgst)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(blob.reader())
+}
+
+func (s *archiveBlobStore) Open(ctx context.Context, dgst godigest.Digest) (io.ReadSeekCloser, error) {
+	blob, err := s.blob(dgst)
+	if err != nil {
+		return nil, err
+	}
+	return nopReadSeekCloser{blob.reader()}, nil
+}
+
+func (s *archiveBlobStore) ServeBlob(ctx context.Context, w http.ResponseWriter, r *http.Request, dgst godigest.Digest) error {
+	return fmt.Errorf("unimplemented")
+}
+
+func (s *archiveBlobStore) Put(ctx context.Context, mediaType string, payload []byte) (distribution.Descriptor, error) {
+	return distribution.Descriptor{}, fmt.Errorf("%s can only be used as sources", s.r.noun)
+}
+
+func (s *archiveBlobStore) Create(ctx context.Context, options ...distribution.BlobCreateOption) (distribution.BlobWriter, error) {
+	return nil, fmt.Errorf("%s can only be used as sources", s.r.noun)
+}
+
+func (s *archiveBlobStore) Resume(ctx context.Context, id string) (distribution.BlobWriter, error) {
+	return nil, fmt.Errorf("%s can only be used as sources", s.r.noun)
+}
+
+// nopReadSeekCloser leaves the archive open when a blob is closed, since the other blobs
+// of the image are read from it.
+type nopReadSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopReadSeekCloser) Close() error { return nil }
@@ -0,0 +1,187 @@
+package imagesource
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/distribution/distribution/v3/manifest/ocischema"
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	imagespecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type testArchiveFile struct {
+	name, link string
+	data       []byte
+}
+
+func testArchive(t *testing.T, files []testArchiveFile) []byte {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for _, file := range files {
+		hdr := &tar.Header{Name: file.name, Mode: 0644, Size: int64(len(file.data)), Typeflag: tar.TypeReg}
+		if len(file.link) > 0 {
+			hdr = &tar.Header{Name: file.name, Linkname: file.link, Typeflag: tar.TypeSymlink}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(file.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// testDockerArchive returns the config, the layer and the content of a 'docker save'
+// archive of quay.io/test/app:latest whose second layer links to the first.
+func testDockerArchive(t *testing.T) ([]byte, []byte, []byte) {
+	config := []byte(`{"architecture":"amd64","os":"linux","rootfs":{"type":"layers"}}`)
+	layer := []byte("layer content")
+	return config, layer, testArchive(t, []testArchiveFile{
+		{name: "0123.json", data: config},
+		{name: "aaaa/layer.tar", data: layer},
+		{name: "bbbb/layer.tar", link: "../aaaa/layer.tar"},
+		{name: "manifest.json", data: []byte(`[{"Config":"0123.json","RepoTags":["quay.io/test/app:latest"],"Layers":["aaaa/layer.tar","bbbb/layer.tar"]}]`)},
+	})
+}
+
+func TestDockerArchive(t *testing.T) {
+	config, layer, data := testDockerArchive(t)
+	path := filepath.Join(t.TempDir(), "app.tar")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ref, err := ParseReference("docker-archive:" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Type != DestinationDockerArchive || ref.Path != path || ref.Ref.Exact() != "app:latest" {
+		t.Fatalf("unexpected reference %#v", ref)
+	}
+	if named, err := ParseReference("docker-archive:" + path + ":quay.io/test/app"); err != nil || named.Ref.Exact() != "quay.io/test/app:latest" {
+		t.Fatalf("unexpected reference %#v: %v", named, err)
+	}
+	if _, err := ParseDestinationReference("docker-archive:" + path); err == nil {
+		t.Errorf("expected archives to be rejected as destinations")
+	}
+	// registries named like a transport are still registries
+	for _, value := range []string{"docker-archive:5000/app:v1", "oci-archive:5000/app:v1"} {
+		if ref, err := ParseReference(value); err != nil || ref.Type != DestinationRegistry || ref.Ref.Exact() != value {
+			t.Errorf("expected %s to be an image of a registry, got %#v: %v", value, ref, err)
+		}
+	}
+
+	ctx := context.TODO()
+	repo, err := (&Options{}).Repository(ctx, ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc, err := repo.Tags(ctx).Get(ctx, ref.Ref.Tag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifests, err := repo.Manifests(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := manifests.Get(ctx, desc.Digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := m.(*ocischema.DeserializedManifest)
+	if manifest.Config.Digest != godigest.FromBytes(config) || len(manifest.Layers) != 2 || manifest.Layers[1].Digest != godigest.FromBytes(layer) {
+		t.Errorf("unexpected manifest %#v", manifest.Manifest)
+	}
+	if data, err := repo.Blobs(ctx).Get(ctx, godigest.FromBytes(layer)); err != nil || !bytes.Equal(data, layer) {
+		t.Errorf("unexpected layer content %q: %v", data, err)
+	}
+	if tags, err := repo.Tags(ctx).All(ctx); err != nil || len(tags) != 0 {
+		t.Errorf("expected no tags in the app repository: %v %v", tags, err)
+	}
+}
+
+func TestOCIArchive(t *testing.T) {
+	config := []byte(`{"architecture":"amd64","os":"linux","rootfs":{"type":"layers"}}`)
+	layer := []byte("layer content")
+	manifest, err := json.Marshal(imagespecv1.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: imagespecv1.MediaTypeImageManifest,
+		Config:    imagespecv1.Descriptor{MediaType: imagespecv1.MediaTypeImageConfig, Digest: godigest.FromBytes(config), Size: int64(len(config))},
+		Layers:    []imagespecv1.Descriptor{{MediaType: imagespecv1.MediaTypeImageLayer, Digest: godigest.FromBytes(layer), Size: int64(len(layer))}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := json.Marshal(imagespecv1.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		Manifests: []imagespecv1.Descriptor{
+			{MediaType: imagespecv1.MediaTypeImageManifest, Digest: godigest.FromBytes(manifest), Size: int64(len(manifest)), Annotations: map[string]string{imagespecv1.AnnotationRefName: "v1"}},
+			{MediaType: imagespecv1.MediaTypeImageManifest, Digest: godigest.FromBytes(manifest), Size: int64(len(manifest)), Annotations: map[string]string{imagespecv1.AnnotationRefName: "v2"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := func(data []byte) string { return "blobs/sha256/" + godigest.FromBytes(data).Encoded() }
+	path := filepath.Join(t.TempDir(), "app.tar")
+	if err := os.WriteFile(path, testArchive(t, []testArchiveFile{
+		{name: "oci-layout", data: []byte(`{"imageLayoutVersion":"1.0.0"}`)},
+		{name: "index.json", data: index},
+		{name: blob(manifest), data: manifest},
+		{name: blob(config), data: config},
+		{name: blob(layer), data: layer},
+	}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ref, err := ParseReference("oci-archive:" + path + ":v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Type != DestinationOCIArchive || ref.Ref.Tag != "v2" || ref.String() != "oci-archive:"+path+":v2" {
+		t.Fatalf("unexpected reference %#v", ref)
+	}
+
+	ctx := context.TODO()
+	repo, err := (&Options{}).Repository(ctx, ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc, err := repo.Tags(ctx).Get(ctx, "v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.Digest != godigest.FromBytes(manifest) {
+		t.Errorf("unexpected descriptor %#v", desc)
+	}
+	if _, err := repo.Tags(ctx).Get(ctx, "v3"); err == nil {
+		t.Errorf("expected an error for a missing image of an archive with several images")
+	}
+	manifests, err := repo.Manifests(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := manifests.Get(ctx, desc.Digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layers := m.(*ocischema.DeserializedManifest).Layers; len(layers) != 1 || layers[0].Digest != godigest.FromBytes(layer) {
+		t.Errorf("unexpected layers %#v", layers)
+	}
+	if data, err := repo.Blobs(ctx).Get(ctx, godigest.FromBytes(config)); err != nil || !bytes.Equal(data, config) {
+		t.Errorf("unexpected config content %q: %v", data, err)
+	}
+	if tags, err := repo.Tags(ctx).All(ctx); err != nil || len(tags) != 2 {
+		t.Errorf("unexpected tags %v: %v", tags, err)
+	}
+}
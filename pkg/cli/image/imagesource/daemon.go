@@ -0,0 +1,160 @@
+package imagesource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/distribution/distribution/v3"
+)
+
+// daemonSocket returns the path of the socket of the container engine serving the images
+// of the reference type, $DOCKER_HOST or $CONTAINER_HOST if they are set.
+func daemonSocket(t DestinationType) (string, error) {
+	env := "DOCKER_HOST"
+	if t == DestinationPodman {
+		env = "CONTAINER_HOST"
+	}
+	if host := os.Getenv(env); len(host) > 0 {
+		if socket, ok := strings.CutPrefix(host, "unix://"); ok {
+			return socket, nil
+		}
+		return "", fmt.Errorf("%s=%s is not supported, only unix:// sockets can be used", env, host)
+	}
+	if t == DestinationDockerDaemon {
+		return "/var/run/docker.sock", nil
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); len(dir) > 0 {
+		socket := filepath.Join(dir, "podman", "podman.sock")
+		if _, err := os.Stat(socket); err == nil {
+			return socket, nil
+		}
+	}
+	return "/run/podman/podman.sock", nil
+}
+
+// daemonDriver reads the images of a container engine through the Docker API of its
+// socket, which both Docker and Podman serve. The images are exported like with
+// 'docker save' and can only be used as sources.
+type daemonDriver struct {
+	Socket string
+}
+
+func (d *daemonDriver) Repository(ctx context.Context, repoName string) (distribution.Repository, error) {
+	klog.V(3).Infof("Repository %s from the container engine at %s", repoName, d.Socket)
+
+	socket := d.Socket
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+	return newArchiveRepository(repoName, "images of a container engine", &daemonSource{client: client, repoName: repoName})
+}
+
+// daemonSource provides the images of a container engine.
+type daemonSource struct {
+	client   *http.Client
+	repoName string
+}
+
+func (s *daemonSource) get(ctx context.Context, apiPath string, query url.Values) (*http.Response, error) {
+	u := &url.URL{Scheme: "http", Host: "localhost", Path: apiPath, RawQuery: query.Encode()}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to the container engine: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var status struct {
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if err := json.Unmarshal(data, &status); err != nil || len(status.Message) == 0 {
+			status.Message = strings.TrimSpace(string(data))
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, distribution.ErrBlobUnknown
+		}
+		return nil, fmt.Errorf("the container engine returned %s: %s", resp.Status, status.Message)
+	}
+	return resp, nil
+}
+
+// Image exports the image of the tag from the container engine to an unlinked temporary
+// file, from which the files of the image are read.
+func (s *daemonSource) Image(ctx context.Context, r *archiveRepository, tag string) (distribution.Descriptor, error) {
+	name := s.repoName + ":" + tag
+	resp, err := s.get(ctx, "/images/"+name+"/get", nil)
+	if err != nil {
+		return distribution.Descriptor{}, err
+	}
+	defer resp.Body.Close()
+
+	f, err := os.CreateTemp("", "oc-image-export-")
+	if err != nil {
+		return distribution.Descriptor{}, err
+	}
+	// the export is removed once oc exits and closes the file
+	if err := os.Remove(f.Name()); err != nil {
+		klog.V(4).Infof("Unable to remove the temporary export of %s: %v", name, err)
+	}
+	files, err := indexArchive(io.TeeReader(resp.Body, f), f)
+	if err != nil {
+		return distribution.Descriptor{}, fmt.Errorf("unable to read the export of %s: %v", name, err)
+	}
+	images, err := dockerArchiveImages(files)
+	if err != nil {
+		return distribution.Descriptor{}, fmt.Errorf("the export of %s is invalid: %v", name, err)
+	}
+	image, err := selectDockerArchiveImage(images, name)
+	if err != nil {
+		return distribution.Descriptor{}, fmt.Errorf("the export of %s is invalid: %v", name, err)
+	}
+	desc, err := addDockerArchiveImage(r, files, image, tag)
+	if err != nil {
+		return distribution.Descriptor{}, err
+	}
+	klog.V(5).Infof("Exported %s from the container engine as %s", name, desc.Digest)
+	return desc, nil
+}
+
+// Tags returns the tags of the images of the container engine in the repository.
+func (s *daemonSource) Tags(ctx context.Context) ([]string, error) {
+	filters, err := json.Marshal(map[string][]string{"reference": {s.repoName}})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.get(ctx, "/images/json", url.Values{"filters": {string(filters)}})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var images []struct {
+		RepoTags []string
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&images); err != nil {
+		return nil, fmt.Errorf("unable to list the images of the container engine: %v", err)
+	}
+	var repoTags []string
+	for _, image := range images {
+		repoTags = append(repoTags, image.RepoTags...)
+	}
+	return tagsOfRepository(s.repoName, repoTags), nil
+}
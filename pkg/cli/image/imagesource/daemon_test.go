@@ -0,0 +1,126 @@
+package imagesource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/distribution/distribution/v3/manifest/ocischema"
+	godigest "github.com/opencontainers/go-digest"
+	imagespecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestDaemonRepository(t *testing.T) {
+	config, layer, archive := testDockerArchive(t)
+	export := bytes.NewBuffer(archive)
+
+	socket := filepath.Join(t.TempDir(), "engine.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var exports int
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/images/quay.io/test/app:latest/get":
+			exports++
+			w.Write(export.Bytes())
+		case "/images/json":
+			json.NewEncoder(w).Encode([]map[string][]string{{"RepoTags": {"quay.io/test/app:latest", "quay.io/test/app:v1", "quay.io/test/other:latest"}}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"No such image"}`))
+		}
+	})}
+	go server.Serve(l)
+	defer server.Close()
+
+	ctx := context.TODO()
+	driver := &daemonDriver{Socket: socket}
+	repo, err := driver.Repository(ctx, "quay.io/test/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	desc, err := repo.Tags(ctx).Get(ctx, "latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Tags(ctx).Get(ctx, "latest"); err != nil || exports != 1 {
+		t.Errorf("expected the image to be exported once, got %d: %v", exports, err)
+	}
+	if _, err := repo.Tags(ctx).Get(ctx, "missing"); err == nil {
+		t.Errorf("expected an error for a missing image")
+	}
+
+	manifests, err := repo.Manifests(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := manifests.Get(ctx, desc.Digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, ok := m.(*ocischema.DeserializedManifest)
+	if !ok {
+		t.Fatalf("unexpected manifest %T", m)
+	}
+	if manifest.Config.Digest != godigest.FromBytes(config) || manifest.Config.MediaType != imagespecv1.MediaTypeImageConfig {
+		t.Errorf("unexpected config %#v", manifest.Config)
+	}
+	if len(manifest.Layers) != 2 || manifest.Layers[0].Digest != godigest.FromBytes(layer) || manifest.Layers[1].Digest != manifest.Layers[0].Digest || manifest.Layers[0].MediaType != imagespecv1.MediaTypeImageLayer {
+		t.Errorf("unexpected layers %#v", manifest.Layers)
+	}
+
+	rc, err := repo.Blobs(ctx).Open(ctx, godigest.FromBytes(layer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(rc)
+	if !bytes.Equal(data, layer) {
+		t.Errorf("unexpected layer content %q", data)
+	}
+	if data, err := repo.Blobs(ctx).Get(ctx, manifest.Config.Digest); err != nil || !bytes.Equal(data, config) {
+		t.Errorf("unexpected config content %q: %v", data, err)
+	}
+
+	tags, err := repo.Tags(ctx).All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 || tags[0] != "latest" || tags[1] != "v1" {
+		t.Errorf("unexpected tags %v", tags)
+	}
+}
+
+func TestParseDaemonReference(t *testing.T) {
+	ref, err := ParseReference("podman:quay.io/test/app:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Type != DestinationPodman || ref.Ref.Exact() != "quay.io/test/app:latest" || ref.String() != "podman:quay.io/test/app:latest" {
+		t.Errorf("unexpected reference %#v", ref)
+	}
+	if _, err := ParseDestinationReference("docker-daemon:busybox:latest"); err == nil {
+		t.Errorf("expected container engines to be rejected as destinations")
+	}
+	if _, err := ParseReference("docker-daemon:busybox@sha256:4c5f3db4f8a54eb1e017c385f683a2de6e06f75be442dc32698c9bbe6c861edd"); err == nil {
+		t.Errorf("expected digests to be rejected for container engines")
+	}
+
+	// registries named like a transport are still registries
+	for _, value := range []string{"podman:5000/ns/app:latest", "docker-daemon:5000/app:latest", "podman/app:latest"} {
+		ref, err := ParseReference(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ref.Type != DestinationRegistry || ref.Ref.Exact() != value {
+			t.Errorf("expected %s to be an image of a registry, got %#v", value, ref)
+		}
+	}
+}
@@ -21,7 +21,11 @@ type Options struct {
 func (o *Options) Repository(ctx context.Context, ref TypedImageReference) (distribution.Repository, error) {
 	switch ref.Type {
 	case DestinationRegistry:
-		return o.RegistryContext.Repository(ctx, ref.Ref.DockerClientDefaults().RegistryURL(), ref.Ref.RepositoryName(), o.Insecure)
+		named, err := applyRegistriesConf(o.RegistryContext, ref.Ref)
+		if err != nil {
+			return nil, err
+		}
+		return registryContextFor(o.RegistryContext, named).Repository(ctx, named.DockerClientDefaults().RegistryURL(), named.RepositoryName(), o.Insecure)
 	case DestinationFile:
 		driver := &fileDriver{
 			BaseDir: o.FileDir,
@@ -38,6 +42,20 @@ func (o *Options) Repository(ctx context.Context, ref TypedImageReference) (dist
 		}
 		url := ref.Ref.DockerClientDefaults().RegistryURL()
 		return driver.Repository(ctx, url, ref.Ref.RepositoryName(), o.Insecure)
+	case DestinationDockerDaemon, DestinationPodman:
+		socket, err := daemonSocket(ref.Type)
+		if err != nil {
+			return nil, err
+		}
+		driver := &daemonDriver{
+			Socket: socket,
+		}
+		return driver.Repository(ctx, ref.Ref.AsRepository().Exact())
+	case DestinationDockerArchive:
+		repoName := ref.Ref.AsRepository().Exact()
+		return newArchiveRepository(repoName, "images of an archive", &dockerArchiveSource{path: ref.Path, repoName: repoName})
+	case DestinationOCIArchive:
+		return newArchiveRepository(ref.Ref.AsRepository().Exact(), "images of an archive", &ociArchiveSource{path: ref.Path})
 	default:
 		return nil, fmt.Errorf("unrecognized image reference type %s", ref.Type)
 	}
@@ -2,6 +2,7 @@ package imagesource
 
 import (
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -17,6 +18,14 @@ var (
 	DestinationRegistry DestinationType = "docker"
 	DestinationS3       DestinationType = "s3"
 	DestinationFile     DestinationType = "file"
+	// DestinationDockerDaemon and DestinationPodman are the images of a local container
+	// engine, which can only be used as sources
+	DestinationDockerDaemon DestinationType = "docker-daemon"
+	DestinationPodman       DestinationType = "podman"
+	// DestinationDockerArchive and DestinationOCIArchive are the images of a single-file
+	// export, which can only be used as sources
+	DestinationDockerArchive DestinationType = "docker-archive"
+	DestinationOCIArchive    DestinationType = "oci-archive"
 )
 
 func (t DestinationType) Prefix() string {
@@ -25,6 +34,14 @@ func (t DestinationType) Prefix() string {
 		return "file://"
 	case DestinationS3:
 		return "s3://"
+	case DestinationDockerDaemon:
+		return "docker-daemon:"
+	case DestinationPodman:
+		return "podman:"
+	case DestinationDockerArchive:
+		return "docker-archive:"
+	case DestinationOCIArchive:
+		return "oci-archive:"
 	default:
 		return ""
 	}
@@ -33,14 +50,26 @@ func (t DestinationType) Prefix() string {
 type TypedImageReference struct {
 	Type DestinationType
 	Ref  reference.DockerImageReference
+	// Path is the archive of the docker-archive: and oci-archive: references
+	Path string
 }
 
 func (t TypedImageReference) EqualRegistry(other TypedImageReference) bool {
-	return t.Type == other.Type && t.Ref.Registry == other.Ref.Registry
+	return t.Type == other.Type && t.Path == other.Path && t.Ref.Registry == other.Ref.Registry
 }
 
 func (t TypedImageReference) Equal(other TypedImageReference) bool {
-	return t.Type == other.Type && t.Ref.Equal(other.Ref)
+	return t.Type == other.Type && t.Path == other.Path && t.Ref.Equal(other.Ref)
+}
+
+// isSourceOnly returns true for the references which can only be read from.
+func (t DestinationType) isSourceOnly() bool {
+	switch t {
+	case DestinationDockerDaemon, DestinationPodman, DestinationDockerArchive, DestinationOCIArchive:
+		return true
+	default:
+		return false
+	}
 }
 
 func (t TypedImageReference) String() string {
@@ -49,6 +78,12 @@ func (t TypedImageReference) String() string {
 		return fmt.Sprintf("file://%s", t.Ref.Exact())
 	case DestinationS3:
 		return fmt.Sprintf("s3://%s", t.Ref.Exact())
+	case DestinationDockerDaemon, DestinationPodman:
+		return t.Type.Prefix() + t.Ref.Exact()
+	case DestinationDockerArchive:
+		return t.Type.Prefix() + t.Path + ":" + t.Ref.Exact()
+	case DestinationOCIArchive:
+		return t.Type.Prefix() + t.Path + ":" + t.Ref.Tag
 	default:
 		return t.Ref.Exact()
 	}
@@ -90,12 +125,19 @@ func ParseDestinationReference(ref string) (TypedImageReference, error) {
 	if len(dst.Ref.ID) != 0 {
 		return dst, fmt.Errorf("you must specify a tag for DST or leave it blank to only push by digest")
 	}
+	if dst.Type.isSourceOnly() {
+		return dst, fmt.Errorf("%s references can only be used as sources", dst.Type.Prefix())
+	}
 	return dst, err
 }
 
 func ParseReference(ref string) (TypedImageReference, error) {
 	dstType := DestinationRegistry
 	switch {
+	case hasTransport(ref, "docker-archive:"):
+		return parseArchiveReference(DestinationDockerArchive, strings.TrimPrefix(ref, "docker-archive:"))
+	case hasTransport(ref, "oci-archive:"):
+		return parseArchiveReference(DestinationOCIArchive, strings.TrimPrefix(ref, "oci-archive:"))
 	case strings.HasPrefix(ref, "s3://"):
 		dstType = DestinationS3
 		ref = strings.TrimPrefix(ref, "s3://")
@@ -105,14 +147,68 @@ func ParseReference(ref string) (TypedImageReference, error) {
 		if strings.HasPrefix(ref, "/") {
 			ref = ref[1:]
 		}
+	case hasTransport(ref, "docker-daemon:"):
+		dstType = DestinationDockerDaemon
+		ref = strings.TrimPrefix(ref, "docker-daemon:")
+	case hasTransport(ref, "podman:"):
+		dstType = DestinationPodman
+		ref = strings.TrimPrefix(ref, "podman:")
 	}
 	dst, err := reference.Parse(ref)
 	if err != nil {
 		return TypedImageReference{Ref: dst, Type: dstType}, fmt.Errorf("%q is not a valid image reference: %v", ref, err)
 	}
+	if len(dst.ID) > 0 && dstType.isSourceOnly() {
+		return TypedImageReference{Ref: dst, Type: dstType}, fmt.Errorf("images of a container engine must be referenced by tag: %s", ref)
+	}
 	return TypedImageReference{Ref: dst, Type: dstType}, nil
 }
 
+var rePort = regexp.MustCompile(`^[0-9]+/`)
+
+// hasTransport returns true if ref starts with the transport prefix, and not with a registry
+// host of the same name and a port, such as podman:5000/ns/image.
+func hasTransport(ref, prefix string) bool {
+	return strings.HasPrefix(ref, prefix) && !rePort.MatchString(strings.TrimPrefix(ref, prefix))
+}
+
+var reInvalidRepositoryName = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// parseArchiveReference parses PATH[:REFERENCE], REFERENCE being the repository and tag of
+// an image of a docker-archive:, and the org.opencontainers.image.ref.name of an image of
+// an oci-archive:. The images are named after the archive if the reference is omitted, and
+// an archive holding a single image provides it regardless of the reference.
+func parseArchiveReference(t DestinationType, value string) (TypedImageReference, error) {
+	path, name, _ := strings.Cut(value, ":")
+	if len(path) == 0 {
+		return TypedImageReference{Type: t}, fmt.Errorf("%s references require the path of the archive", t.Prefix())
+	}
+	repoName := strings.Trim(reInvalidRepositoryName.ReplaceAllString(strings.ToLower(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))), "-"), "-._")
+	if len(repoName) == 0 {
+		repoName = "image"
+	}
+	ref := TypedImageReference{Type: t, Path: path, Ref: reference.DockerImageReference{Name: repoName, Tag: "latest"}}
+
+	switch {
+	case len(name) == 0:
+	case t == DestinationDockerArchive:
+		parsed, err := reference.Parse(name)
+		if err != nil {
+			return ref, fmt.Errorf("%q is not a valid image reference: %v", name, err)
+		}
+		if len(parsed.ID) > 0 {
+			return ref, fmt.Errorf("images of an archive must be referenced by tag: %s", name)
+		}
+		if len(parsed.Tag) == 0 {
+			parsed.Tag = "latest"
+		}
+		ref.Ref = parsed
+	default:
+		ref.Ref.Tag = name
+	}
+	return ref, nil
+}
+
 // buildTagSearchRegexp creates a regexp from the provided tag value
 // that can be used to filter tags. It supports standard '*' glob
 // rules.
@@ -0,0 +1,141 @@
+package imagesource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/distribution/distribution/v3/registry/client/auth"
+	"github.com/distribution/distribution/v3/registry/client/transport"
+	godigest "github.com/opencontainers/go-digest"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// ErrReferrersAPIUnsupported is returned when a registry does not serve the OCI referrers API.
+var ErrReferrersAPIUnsupported = errors.New("the registry does not support the referrers API")
+
+// maxReferrersPages bounds the pages of referrers followed through the Link header.
+const maxReferrersPages = 100
+
+// ReferrersAPI lists the artifacts attached to the images of a registry repository through the
+// OCI referrers API, GET /v2/<name>/referrers/<digest>.
+type ReferrersAPI struct {
+	client *http.Client
+	url    *url.URL
+	name   string
+}
+
+// ReferrersAPI returns the referrers API of the repository of ref, or nil if ref is not in a
+// registry. It reuses the transport, credentials and registries.conf of the registry context.
+func (o *Options) ReferrersAPI(ctx context.Context, ref TypedImageReference) (*ReferrersAPI, error) {
+	if ref.Type != DestinationRegistry {
+		return nil, nil
+	}
+	named, err := applyRegistriesConf(o.RegistryContext, ref.Ref)
+	if err != nil {
+		return nil, err
+	}
+	c := o.RegistryContext
+	rt, registryURL, err := c.Ping(ctx, named.DockerClientDefaults().RegistryURL(), o.Insecure)
+	if err != nil {
+		return nil, err
+	}
+	name := named.RepositoryName()
+	creds := c.Credentials
+	if c.CredentialsFactory != nil {
+		creds = c.CredentialsFactory.CredentialStoreFor(named.AsRepository().String())
+	}
+	modifiers := []transport.RequestModifier{
+		auth.NewAuthorizer(
+			c.Challenges,
+			auth.NewTokenHandlerWithOptions(auth.TokenHandlerOptions{
+				Transport:   rt,
+				Credentials: creds,
+				Scopes:      []auth.Scope{auth.RepositoryScope{Repository: name, Actions: []string{"pull"}}},
+			}),
+			auth.NewBasicHandler(creds),
+		),
+	}
+	modifiers = append(modifiers, c.RequestModifiers...)
+	return &ReferrersAPI{
+		client: &http.Client{Transport: transport.NewTransport(rt, modifiers...), Timeout: time.Minute},
+		url:    registryURL,
+		name:   name,
+	}, nil
+}
+
+// Referrers returns the descriptors of the manifests whose subject is the image with digest dgst,
+// following the pages of the response. It returns ErrReferrersAPIUnsupported if the registry
+// responds 404, which registries serving the API only do for invalid requests.
+func (a *ReferrersAPI) Referrers(ctx context.Context, dgst godigest.Digest) ([]ocispecv1.Descriptor, error) {
+	u := *a.url
+	u.Path = path.Join(u.Path, "v2", a.name, "referrers", dgst.String())
+	next := u.String()
+	var descriptors []ocispecv1.Descriptor
+	for page := 0; len(next) > 0; page++ {
+		if page == maxReferrersPages {
+			return nil, fmt.Errorf("too many pages of referrers for %s", dgst)
+		}
+		index, link, err := a.get(ctx, next)
+		if err != nil {
+			return nil, err
+		}
+		descriptors = append(descriptors, index.Manifests...)
+		next = ""
+		if len(link) > 0 {
+			linkURL, err := u.Parse(link)
+			if err != nil {
+				return nil, fmt.Errorf("invalid Link header %q: %v", link, err)
+			}
+			next = linkURL.String()
+		}
+	}
+	return descriptors, nil
+}
+
+// get returns the index of referrers of a page and the URL of the next page, if any.
+func (a *ReferrersAPI) get(ctx context.Context, u string) (*ocispecv1.Index, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", ocispecv1.MediaTypeImageIndex)
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, "", ErrReferrersAPIUnsupported
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, "", fmt.Errorf("unable to list the referrers: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	index := &ocispecv1.Index{}
+	if err := json.NewDecoder(resp.Body).Decode(index); err != nil {
+		return nil, "", fmt.Errorf("unable to decode the referrers: %v", err)
+	}
+	return index, nextLink(resp.Header.Get("Link")), nil
+}
+
+// nextLink returns the URL of a Link header with rel="next", such as
+// </v2/name/referrers/sha256:...?n=10&last=b>; rel="next".
+func nextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+		if !ok || !strings.Contains(strings.ReplaceAll(params, " ", ""), `rel="next"`) {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(target), "<>")
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+package imagesource
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	godigest "github.com/opencontainers/go-digest"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/library-go/pkg/image/registryclient"
+)
+
+func TestReferrersAPI(t *testing.T) {
+	subject := godigest.FromString("image")
+	first := ocispecv1.Descriptor{MediaType: ocispecv1.MediaTypeImageManifest, Digest: godigest.FromString("signature"), Size: 10, ArtifactType: "application/vnd.dev.cosign.artifact.sig.v1+json"}
+	second := ocispecv1.Descriptor{MediaType: ocispecv1.MediaTypeImageManifest, Digest: godigest.FromString("sbom"), Size: 20, ArtifactType: "application/spdx+json"}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+		case "/v2/app/referrers/" + subject.String():
+			if r.Header.Get("Accept") != ocispecv1.MediaTypeImageIndex {
+				http.Error(w, "unexpected Accept header", http.StatusBadRequest)
+				return
+			}
+			index := ocispecv1.Index{MediaType: ocispecv1.MediaTypeImageIndex, Manifests: []ocispecv1.Descriptor{first}}
+			if r.URL.Query().Get("last") == "1" {
+				index.Manifests = []ocispecv1.Descriptor{second}
+			} else {
+				w.Header().Set("Link", `</v2/app/referrers/`+subject.String()+`?n=1&last=1>; rel="next"`)
+			}
+			w.Header().Set("Content-Type", ocispecv1.MediaTypeImageIndex)
+			json.NewEncoder(w).Encode(index)
+		case "/v2/legacy/referrers/" + subject.String():
+			http.NotFound(w, r)
+		default:
+			http.Error(w, "unexpected request", http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	o := &Options{Insecure: true, RegistryContext: registryclient.NewContext(http.DefaultTransport, http.DefaultTransport)}
+	host := strings.TrimPrefix(server.URL, "http://")
+	for name, test := range map[string]struct {
+		repository    string
+		expected      []ocispecv1.Descriptor
+		expectedError error
+	}{
+		"paged":       {repository: "app", expected: []ocispecv1.Descriptor{first, second}},
+		"unsupported": {repository: "legacy", expectedError: ErrReferrersAPIUnsupported},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ref, err := reference.Parse(host + "/" + test.repository + ":latest")
+			if err != nil {
+				t.Fatal(err)
+			}
+			api, err := o.ReferrersAPI(context.Background(), TypedImageReference{Type: DestinationRegistry, Ref: ref})
+			if err != nil {
+				t.Fatal(err)
+			}
+			descriptors, err := api.Referrers(context.Background(), subject)
+			if err != test.expectedError {
+				t.Fatalf("expected error %v, got %v", test.expectedError, err)
+			}
+			if !reflect.DeepEqual(descriptors, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, descriptors)
+			}
+		})
+	}
+
+	if api, err := o.ReferrersAPI(context.Background(), TypedImageReference{Type: DestinationFile}); api != nil || err != nil {
+		t.Errorf("expected no referrers API for a file source, got %v, %v", api, err)
+	}
+}
+
+func TestNextLink(t *testing.T) {
+	for header, expected := range map[string]string{
+		"": "",
+		`</v2/app/referrers/sha256:abc?n=10&last=b>; rel="next"`:                         "/v2/app/referrers/sha256:abc?n=10&last=b",
+		`<https://example.com/first>; rel="prev", <https://example.com/next>;rel="next"`: "https://example.com/next",
+		`<https://example.com/first>; rel="prev"`:                                        "",
+	} {
+		if link := nextLink(header); link != expected {
+			t.Errorf("%q: expected %q, got %q", header, expected, link)
+		}
+	}
+}
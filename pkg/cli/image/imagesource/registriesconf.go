@@ -0,0 +1,248 @@
+package imagesource
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	dockerreference "github.com/containers/image/v5/docker/reference"
+	"github.com/containers/image/v5/pkg/sysregistriesv2"
+	"github.com/containers/image/v5/types"
+	"github.com/opencontainers/go-digest"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/library-go/pkg/image/registryclient"
+)
+
+// anyDigest and anyTag stand for the digest or tag of the image the mirrors of a
+// repository are looked up for: registries.conf only selects mirrors by whether the
+// image is pulled by digest or by tag.
+var (
+	anyDigest = digest.FromString("")
+	anyTag    = "latest"
+)
+
+// RegistriesConf applies the mirrors, unqualified-search registries and blocked
+// registries of the containers registries.conf file podman and cri-o use, by default
+// /etc/containers/registries.conf and its drop-in directory, or the file set by the
+// CONTAINERS_REGISTRIES_CONF environment variable.
+type RegistriesConf struct {
+	sys *types.SystemContext
+
+	lock       sync.Mutex
+	alternates map[pullSourcesKey][]reference.DockerImageReference
+	// tagContexts are the registry contexts used to pull images by tag, by context
+	tagContexts map[*registryclient.Context]*registryclient.Context
+}
+
+type pullSourcesKey struct {
+	locator  reference.DockerImageReference
+	byDigest bool
+}
+
+var _ registryclient.AlternateBlobSourceStrategy = &RegistriesConf{}
+
+// NewRegistriesConf loads the registries.conf file at path, or the default one if path
+// is empty.
+func NewRegistriesConf(path string) (*RegistriesConf, error) {
+	c := &RegistriesConf{
+		sys:         &types.SystemContext{SystemRegistriesConfPath: path},
+		alternates:  make(map[pullSourcesKey][]reference.DockerImageReference),
+		tagContexts: make(map[*registryclient.Context]*registryclient.Context),
+	}
+	if _, err := sysregistriesv2.TryUpdatingCache(c.sys); err != nil {
+		return nil, fmt.Errorf("unable to load %s: %v", sysregistriesv2.ConfigurationSourceDescription(c.sys), err)
+	}
+	return c, nil
+}
+
+// Qualify sets the registry of a reference without one to the first unqualified-search
+// registry, or leaves it to default to docker.io if there are none.
+func (c *RegistriesConf) Qualify(ref reference.DockerImageReference) (reference.DockerImageReference, error) {
+	if len(ref.Registry) > 0 {
+		return ref, nil
+	}
+	registries, err := sysregistriesv2.UnqualifiedSearchRegistries(c.sys)
+	if err != nil {
+		return ref, err
+	}
+	if len(registries) > 0 {
+		klog.V(5).Infof("Resolving %s with the unqualified-search registry %s", ref.Exact(), registries[0])
+		ref.Registry = registries[0]
+	}
+	return ref, nil
+}
+
+// Blocked returns an error if the repository of the reference is blocked.
+func (c *RegistriesConf) Blocked(ref reference.DockerImageReference) error {
+	registry, err := c.findRegistry(ref)
+	if err != nil {
+		return err
+	}
+	if registry != nil && registry.Blocked {
+		return fmt.Errorf("the registry of %s is blocked in %s", ref.Exact(), sysregistriesv2.ConfigurationSourceDescription(c.sys))
+	}
+	return nil
+}
+
+// FirstRequest returns the mirrors of the repository images are pulled by digest from,
+// followed by the repository itself, unless it is blocked.
+func (c *RegistriesConf) FirstRequest(ctx context.Context, locator reference.DockerImageReference) ([]reference.DockerImageReference, error) {
+	return c.pullSources(locator, true)
+}
+
+// OnFailure returns no other sources, since FirstRequest already returned all of them.
+func (c *RegistriesConf) OnFailure(ctx context.Context, locator reference.DockerImageReference) ([]reference.DockerImageReference, error) {
+	return nil, nil
+}
+
+// TagPulls returns the strategy applying the mirrors of the repositories images are pulled
+// by tag from, which excludes the mirrors set to mirror-by-digest-only or to pull-from-mirror
+// "digest-only".
+func (c *RegistriesConf) TagPulls() registryclient.AlternateBlobSourceStrategy {
+	return tagPulls{c}
+}
+
+type tagPulls struct {
+	conf *RegistriesConf
+}
+
+func (t tagPulls) FirstRequest(ctx context.Context, locator reference.DockerImageReference) ([]reference.DockerImageReference, error) {
+	return t.conf.pullSources(locator, false)
+}
+
+func (t tagPulls) OnFailure(ctx context.Context, locator reference.DockerImageReference) ([]reference.DockerImageReference, error) {
+	return nil, nil
+}
+
+func (c *RegistriesConf) pullSources(locator reference.DockerImageReference, byDigest bool) ([]reference.DockerImageReference, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	key := pullSourcesKey{locator: locator, byDigest: byDigest}
+	if alternates, ok := c.alternates[key]; ok {
+		return alternates, nil
+	}
+	alternates, err := c.resolve(locator, byDigest)
+	if err != nil {
+		return nil, err
+	}
+	c.alternates[key] = alternates
+	return alternates, nil
+}
+
+// tagContext returns a copy of the registry context that applies the mirrors of the images
+// pulled by tag.
+func (c *RegistriesConf) tagContext(parent *registryclient.Context) *registryclient.Context {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if tagContext, ok := c.tagContexts[parent]; ok {
+		return tagContext
+	}
+	tagContext := parent.Copy().WithRequestModifiers(parent.RequestModifiers...).WithAlternateBlobSourceStrategy(tagPulls{c})
+	c.tagContexts[parent] = tagContext
+	return tagContext
+}
+
+// resolve returns the pull sources of the repository in the order of registries.conf, for
+// an image pulled by digest or by tag.
+func (c *RegistriesConf) resolve(locator reference.DockerImageReference, byDigest bool) ([]reference.DockerImageReference, error) {
+	source := locator.AsRepository().AsV2()
+	registry, err := c.findRegistry(locator)
+	if err != nil {
+		return nil, err
+	}
+	if registry == nil {
+		return []reference.DockerImageReference{source}, nil
+	}
+	if registry.Blocked {
+		return nil, fmt.Errorf("the registry of %s is blocked in %s", locator.Exact(), sysregistriesv2.ConfigurationSourceDescription(c.sys))
+	}
+
+	named, err := c.named(locator)
+	if err != nil {
+		return nil, err
+	}
+	var image dockerreference.Named
+	if byDigest {
+		image, err = dockerreference.WithDigest(named, anyDigest)
+	} else {
+		image, err = dockerreference.WithTag(named, anyTag)
+	}
+	if err != nil {
+		return nil, err
+	}
+	pullSources, err := registry.PullSourcesFromReference(image)
+	if err != nil {
+		return nil, err
+	}
+	var sources []reference.DockerImageReference
+	for _, pullSource := range pullSources {
+		ref, err := reference.Parse(dockerreference.TrimNamed(pullSource.Reference).String())
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, ref.AsRepository().AsV2())
+	}
+	klog.V(5).Infof("Sources of %s in %s: %v", locator.Exact(), sysregistriesv2.ConfigurationSourceDescription(c.sys), sources)
+	return sources, nil
+}
+
+func (c *RegistriesConf) findRegistry(ref reference.DockerImageReference) (*sysregistriesv2.Registry, error) {
+	named, err := c.named(ref)
+	if err != nil {
+		return nil, err
+	}
+	return sysregistriesv2.FindRegistry(c.sys, named.Name())
+}
+
+// named returns the repository of the reference as registries.conf names it, with
+// docker.io rather than the registry-1.docker.io endpoint.
+func (c *RegistriesConf) named(ref reference.DockerImageReference) (dockerreference.Named, error) {
+	repository := ref.DockerClientDefaults().AsRepository()
+	if repository.Registry == reference.DockerDefaultV2Registry {
+		repository.Registry = reference.DockerDefaultRegistry
+	}
+	named, err := dockerreference.ParseNormalizedNamed(repository.Exact())
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository %s: %v", repository.Exact(), err)
+	}
+	return named, nil
+}
+
+// registriesConfFor returns the registries.conf the context applies, if any.
+func registriesConfFor(c *registryclient.Context) *RegistriesConf {
+	if c == nil {
+		return nil
+	}
+	conf, _ := c.Alternates.(*RegistriesConf)
+	return conf
+}
+
+// registryContextFor returns the registry context to pull ref with: with registries.conf,
+// whose mirrors depend on whether ref is pulled by digest or by tag, the context applying
+// the mirrors of the images pulled by tag unless ref has a digest.
+func registryContextFor(c *registryclient.Context, ref reference.DockerImageReference) *registryclient.Context {
+	conf := registriesConfFor(c)
+	if conf == nil || len(ref.ID) > 0 {
+		return c
+	}
+	return conf.tagContext(c)
+}
+
+// applyRegistriesConf qualifies the reference with the unqualified-search registries and
+// rejects it if its registry is blocked.
+func applyRegistriesConf(c *registryclient.Context, ref reference.DockerImageReference) (reference.DockerImageReference, error) {
+	conf := registriesConfFor(c)
+	if conf == nil {
+		return ref, nil
+	}
+	ref, err := conf.Qualify(ref)
+	if err != nil {
+		return ref, err
+	}
+	if err := conf.Blocked(ref); err != nil {
+		return ref, err
+	}
+	return ref, nil
+}
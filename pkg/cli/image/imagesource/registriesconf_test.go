@@ -0,0 +1,147 @@
+package imagesource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/library-go/pkg/image/registryclient"
+)
+
+const testRegistriesConf = `
+unqualified-search-registries = ["registry.example.com"]
+
+[[registry]]
+prefix = "quay.io/openshift-release-dev"
+location = "quay.io/openshift-release-dev"
+[[registry.mirror]]
+location = "mirror.example.com/ocp"
+[[registry.mirror]]
+location = "tags.example.com/ocp"
+pull-from-mirror = "tag-only"
+
+[[registry]]
+location = "registry.redhat.io"
+[[registry.mirror]]
+location = "digests.example.com/redhat"
+pull-from-mirror = "digest-only"
+
+[[registry]]
+location = "legacy.example.com"
+mirror-by-digest-only = true
+[[registry.mirror]]
+location = "legacy-mirror.example.com"
+
+[[registry]]
+location = "docker.io"
+blocked = true
+`
+
+func TestRegistriesConf(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registries.conf")
+	if err := os.WriteFile(path, []byte(testRegistriesConf), 0600); err != nil {
+		t.Fatal(err)
+	}
+	conf, err := NewRegistriesConf(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parse := func(s string) reference.DockerImageReference {
+		ref, err := reference.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ref
+	}
+
+	sources, err := conf.FirstRequest(context.TODO(), parse("quay.io/openshift-release-dev/ocp-release:4.16.0-x86_64"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []reference.DockerImageReference{parse("mirror.example.com/ocp/ocp-release"), parse("quay.io/openshift-release-dev/ocp-release")}
+	if !reflect.DeepEqual(sources, want) {
+		t.Errorf("unexpected sources %v, want %v", sources, want)
+	}
+
+	sources, err = conf.FirstRequest(context.TODO(), parse("quay.io/other/image:latest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []reference.DockerImageReference{parse("quay.io/other/image")}; !reflect.DeepEqual(sources, want) {
+		t.Errorf("unexpected sources %v, want %v", sources, want)
+	}
+
+	if _, err := conf.FirstRequest(context.TODO(), parse("docker.io/library/busybox:latest")); err == nil || !strings.Contains(err.Error(), "blocked") {
+		t.Errorf("expected docker.io to be blocked, got %v", err)
+	}
+
+	for _, test := range []struct {
+		ref             string
+		byDigest, byTag []reference.DockerImageReference
+	}{
+		{
+			ref:      "quay.io/openshift-release-dev/ocp-release",
+			byDigest: []reference.DockerImageReference{parse("mirror.example.com/ocp/ocp-release"), parse("quay.io/openshift-release-dev/ocp-release")},
+			byTag:    []reference.DockerImageReference{parse("mirror.example.com/ocp/ocp-release"), parse("tags.example.com/ocp/ocp-release"), parse("quay.io/openshift-release-dev/ocp-release")},
+		},
+		{
+			ref:      "registry.redhat.io/ubi9/ubi",
+			byDigest: []reference.DockerImageReference{parse("digests.example.com/redhat/ubi9/ubi"), parse("registry.redhat.io/ubi9/ubi")},
+			byTag:    []reference.DockerImageReference{parse("registry.redhat.io/ubi9/ubi")},
+		},
+		{
+			ref:      "legacy.example.com/app",
+			byDigest: []reference.DockerImageReference{parse("legacy-mirror.example.com/app"), parse("legacy.example.com/app")},
+			byTag:    []reference.DockerImageReference{parse("legacy.example.com/app")},
+		},
+	} {
+		sources, err := conf.FirstRequest(context.TODO(), parse(test.ref))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(sources, test.byDigest) {
+			t.Errorf("%s: unexpected sources by digest %v, want %v", test.ref, sources, test.byDigest)
+		}
+		sources, err = conf.TagPulls().FirstRequest(context.TODO(), parse(test.ref))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(sources, test.byTag) {
+			t.Errorf("%s: unexpected sources by tag %v, want %v", test.ref, sources, test.byTag)
+		}
+	}
+
+	regContext := registryclient.NewContext(nil, nil).WithAlternateBlobSourceStrategy(conf)
+	if c := registryContextFor(regContext, parse("registry.redhat.io/ubi9/ubi@"+anyDigest.String())); c != regContext {
+		t.Errorf("expected the images pulled by digest to use the mirrors of registries.conf")
+	}
+	tagContext := registryContextFor(regContext, parse("registry.redhat.io/ubi9/ubi:latest"))
+	if _, ok := tagContext.Alternates.(tagPulls); !ok {
+		t.Errorf("expected the images pulled by tag to skip the digest-only mirrors, got %#v", tagContext.Alternates)
+	}
+	if registryContextFor(regContext, parse("registry.redhat.io/ubi9/ubi:9.4")) != tagContext {
+		t.Errorf("expected the context of the images pulled by tag to be reused")
+	}
+	if c := registryclient.NewContext(nil, nil); registryContextFor(c, parse("registry.redhat.io/ubi9/ubi:latest")) != c {
+		t.Errorf("registries.conf should only apply with --use-registries-conf")
+	}
+
+	qualified, err := applyRegistriesConf(regContext, parse("tools/busybox:latest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if qualified.Registry != "registry.example.com" {
+		t.Errorf("expected the reference to be qualified with the unqualified-search registry, got %s", qualified.Exact())
+	}
+	if _, err := applyRegistriesConf(regContext, parse("docker.io/library/busybox:latest")); err == nil {
+		t.Errorf("expected docker.io to be blocked")
+	}
+	if _, err := applyRegistriesConf(registryclient.NewContext(nil, nil), parse("docker.io/library/busybox:latest")); err != nil {
+		t.Errorf("registries.conf should only apply with --use-registries-conf: %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"regexp"
 	"runtime"
@@ -30,16 +31,44 @@ import (
 	"github.com/openshift/library-go/pkg/image/dockerv1client"
 	imagereference "github.com/openshift/library-go/pkg/image/reference"
 	"github.com/openshift/library-go/pkg/image/registryclient"
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
 	"github.com/openshift/oc/pkg/cli/image/manifest/dockercredentials"
+	"github.com/openshift/oc/pkg/cli/image/workqueue"
 	"github.com/openshift/oc/pkg/helpers/image/dockerlayer/add"
 )
 
 type ParallelOptions struct {
 	MaxPerRegistry int
+	// MaxPerRegistryLimit is how far the concurrency of a registry ramps up from
+	// MaxPerRegistry while it responds fast, 4 times MaxPerRegistry if unset
+	MaxPerRegistryLimit int
+
+	limiters *workqueue.Limiters
 }
 
 func (o *ParallelOptions) Bind(flags *pflag.FlagSet) {
-	flags.IntVar(&o.MaxPerRegistry, "max-per-registry", o.MaxPerRegistry, "Number of concurrent requests allowed per registry.")
+	flags.IntVar(&o.MaxPerRegistry, "max-per-registry", o.MaxPerRegistry, "Number of concurrent requests allowed per registry. The concurrency is halved when a registry throttles the requests and ramps up while it responds fast.")
+	flags.IntVar(&o.MaxPerRegistryLimit, "max-per-registry-limit", o.MaxPerRegistryLimit, "Maximum number of concurrent requests per registry the concurrency ramps up to. Defaults to 4 times --max-per-registry; set it to --max-per-registry to disable the ramp up.")
+}
+
+// Limiters returns the limiters adapting the concurrency of each registry, which
+// SecurityOptions.Limiters must be set to for the responses of the registries to be
+// reported to them.
+func (o *ParallelOptions) Limiters() *workqueue.Limiters {
+	if o.limiters == nil {
+		limit := o.MaxPerRegistryLimit
+		if limit <= 0 {
+			limit = 4 * o.MaxPerRegistry
+		}
+		o.limiters = workqueue.NewLimiters(o.MaxPerRegistry, limit)
+	}
+	return o.limiters
+}
+
+// RegistryQueue returns a work queue for the registry, running as many work units
+// concurrently as the registry allows.
+func (o *ParallelOptions) RegistryQueue(registry string, stopCh <-chan struct{}) workqueue.Interface {
+	return workqueue.NewAdaptive(o.Limiters().For(registry), stopCh)
 }
 
 type SecurityOptions struct {
@@ -47,6 +76,28 @@ type SecurityOptions struct {
 	Insecure         bool
 	SkipVerification bool
 	CAData           string
+	// TransportConfig is the path to a RegistryTransportConfig file
+	TransportConfig string
+	// TLSMinVersion is the minimum TLS version of the connections to the registries
+	TLSMinVersion string
+	// Pins are REGISTRY=HASH pairs of the public keys the certificates of registries must have
+	Pins []string
+	// TokenFiles are REGISTRY=PATH pairs of the files holding the bearer tokens of registries
+	TokenFiles []string
+	// UseRegistriesConf applies the mirrors and blocked registries of the containers registries.conf
+	UseRegistriesConf bool
+	// LogRequests and TraceRequests are the files the registry API calls are logged and traced to, - for the standard error
+	LogRequests   string
+	TraceRequests string
+	// Limiters, if set, are reported the responses of the registries and delay the requests
+	// to the registries which asked to back off
+	Limiters *workqueue.Limiters
+	// Proxy, if set, returns the proxy of the requests to the registries instead of the
+	// HTTPS_PROXY and NO_PROXY environment variables.
+	Proxy func(*http.Request) (*url.URL, error)
+	// AdditionalCAData is a CA bundle trusted in addition to --certificate-authority or
+	// the system roots, such as the trust bundle of a cluster.
+	AdditionalCAData []byte
 
 	CachedContext *registryclient.Context
 }
@@ -57,6 +108,14 @@ func (o *SecurityOptions) Bind(flags *pflag.FlagSet) {
 	flags.BoolVar(&o.Insecure, "insecure", o.Insecure, "Allow push and pull operations to registries to be made over HTTP")
 	flags.BoolVar(&o.SkipVerification, "skip-verification", o.SkipVerification, "Skip verifying the integrity of the retrieved content. This is not recommended, but may be necessary when importing images from older image registries. Only bypass verification if the registry is known to be trustworthy.")
 	flags.StringVar(&o.CAData, "certificate-authority", o.CAData, "The path to a certificate authority bundle to use when communicating with the managed container image registries. If --insecure is used, this flag will be ignored. ")
+	flags.BoolVar(&o.UseRegistriesConf, "use-registries-conf", o.UseRegistriesConf, "If true, apply the mirrors, unqualified-search registries and blocked registries of /etc/containers/registries.conf, or of the file set by the CONTAINERS_REGISTRIES_CONF environment variable, like podman does. --icsp-file and --idms-file take precedence over the mirrors.")
+	flags.StringVar(&o.LogRequests, "log-requests", o.LogRequests, "Log the method, URL, status, duration, size and retry count of every registry API call as JSON lines to this file, or to the standard error if no file is given.")
+	flags.Lookup("log-requests").NoOptDefVal = "-"
+	flags.StringVar(&o.TraceRequests, "trace-requests", o.TraceRequests, "Write a span for every registry API call to this file in the OpenTelemetry protocol JSON encoding, for the otlpjsonfile receiver of the OpenTelemetry collector, and send the trace context to the registries.")
+	flags.StringVar(&o.TransportConfig, "registry-transport-config", o.TransportConfig, "The path to a file setting the proxy, certificate authority bundle, client certificate, minimum TLS version and bearer token or credential plugin of each registry, for registries that must not use the HTTPS_PROXY environment variable, the --certificate-authority bundle or the --registry-config credentials alone.")
+	flags.StringVar(&o.TLSMinVersion, "tls-min-version", o.TLSMinVersion, "The minimum TLS version of the connections to the registries, VersionTLS10 to VersionTLS13. The registries of --registry-transport-config setting minTLSVersion keep theirs.")
+	flags.StringArrayVar(&o.Pins, "registry-pin-sha256", o.Pins, "A REGISTRY=HASH pair of a registry host, or *.domain, and the base64 encoded SHA-256 hash of a public key that one of the certificates of the registry must have. May be repeated to pin several keys.")
+	flags.StringArrayVar(&o.TokenFiles, "registry-token-file", o.TokenFiles, "A REGISTRY=PATH pair of a registry host, or *.domain, and a file holding the bearer token sent to it instead of the --registry-config credentials. The file is read again when it changes. May be repeated.")
 }
 
 // ReferentialHTTPClient returns an http.Client that is appropriate for accessing
@@ -120,28 +179,63 @@ func (o *SecurityOptions) Context() (*registryclient.Context, error) {
 func (o *SecurityOptions) NewContext() (*registryclient.Context, error) {
 	userAgent := rest.DefaultKubernetesUserAgent()
 	var rt http.RoundTripper
+	var cadata []byte
 	var err error
 	if len(o.CAData) > 0 {
-		cadata, err := os.ReadFile(o.CAData)
+		cadata, err = os.ReadFile(o.CAData)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read registry ca bundle: %v", err)
 		}
+	}
+	cadata = append(cadata, o.AdditionalCAData...)
 
-		rt, err = rest.TransportFor(&rest.Config{UserAgent: userAgent, TLSClientConfig: rest.TLSClientConfig{CAData: cadata}})
-		if err != nil {
+	if _, ok := tlsVersions[o.TLSMinVersion]; len(o.TLSMinVersion) > 0 && !ok {
+		return nil, fmt.Errorf("--tls-min-version must be one of VersionTLS10, VersionTLS11, VersionTLS12 or VersionTLS13")
+	}
+
+	switch {
+	case len(o.CAData) > 0:
+		rt, err = defaultRoundTripper(&rest.Config{UserAgent: userAgent, TLSClientConfig: rest.TLSClientConfig{CAData: cadata}, Proxy: o.Proxy}, o.TLSMinVersion)
+	case len(cadata) > 0:
+		// a rest.Config CA bundle would replace the system roots instead of being added to them
+		rt, err = (&RegistryTransport{MinTLSVersion: o.TLSMinVersion}).roundTripper(cadata, false, o.Proxy, userAgent)
+	default:
+		rt, err = defaultRoundTripper(&rest.Config{UserAgent: userAgent, Proxy: o.Proxy}, o.TLSMinVersion)
+	}
+	if err != nil {
+		return nil, err
+	}
+	insecureRT, err := defaultRoundTripper(&rest.Config{TLSClientConfig: rest.TLSClientConfig{Insecure: true}, UserAgent: userAgent, Proxy: o.Proxy}, o.TLSMinVersion)
+	if err != nil {
+		return nil, err
+	}
+	if len(o.TransportConfig) > 0 || len(o.TokenFiles) > 0 || len(o.Pins) > 0 {
+		config := &RegistryTransportConfig{}
+		if len(o.TransportConfig) > 0 {
+			if config, err = LoadRegistryTransportConfig(o.TransportConfig); err != nil {
+				return nil, fmt.Errorf("unable to load --registry-transport-config: %v", err)
+			}
+		}
+		if err := config.AddTokenFiles(o.TokenFiles); err != nil {
+			return nil, fmt.Errorf("invalid --registry-token-file: %v", err)
+		}
+		if err := config.AddPins(o.Pins); err != nil {
+			return nil, fmt.Errorf("invalid --registry-pin-sha256: %v", err)
+		}
+		config.SetDefaultMinTLSVersion(o.TLSMinVersion)
+		if rt, err = newRegistryRoundTripper(config, rt, cadata, false, o.Proxy, userAgent); err != nil {
 			return nil, err
 		}
-
-	} else {
-		rt, err = rest.TransportFor(&rest.Config{UserAgent: userAgent})
-		if err != nil {
+		if insecureRT, err = newRegistryRoundTripper(config, insecureRT, nil, true, o.Proxy, userAgent); err != nil {
 			return nil, err
 		}
 	}
-	insecureRT, err := rest.TransportFor(&rest.Config{TLSClientConfig: rest.TLSClientConfig{Insecure: true}, UserAgent: userAgent})
+	logger, err := newRequestLogger(o.LogRequests, o.TraceRequests)
 	if err != nil {
 		return nil, err
 	}
+	rt, insecureRT = logger.wrap(rt), logger.wrap(insecureRT)
+	rt, insecureRT = o.Limiters.RoundTripper(rt), o.Limiters.RoundTripper(insecureRT)
 	credStoreFactory, err := dockercredentials.NewCredentialStoreFactory(o.RegistryConfig)
 	if err != nil {
 		if len(o.RegistryConfig) > 0 {
@@ -151,6 +245,13 @@ func (o *SecurityOptions) NewContext() (*registryclient.Context, error) {
 	}
 	ctx := registryclient.NewContext(rt, insecureRT).WithCredentialsFactory(credStoreFactory)
 	ctx.DisableDigestVerification = o.SkipVerification
+	if o.UseRegistriesConf {
+		conf, err := imagesource.NewRegistriesConf("")
+		if err != nil {
+			return nil, err
+		}
+		ctx.WithAlternateBlobSourceStrategy(conf)
+	}
 	return ctx, nil
 }
 
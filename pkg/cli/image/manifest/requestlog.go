@@ -0,0 +1,302 @@
+package manifest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// requestLog is a registry API call logged with --log-requests.
+type requestLog struct {
+	Time     time.Time `json:"time"`
+	Method   string    `json:"method"`
+	URL      string    `json:"url"`
+	Status   int       `json:"status,omitempty"`
+	Duration float64   `json:"durationMs"`
+	Bytes    int64     `json:"bytes"`
+	Retry    int       `json:"retry"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// failureExpiry is how long a failed request is remembered to count its retries.
+const failureExpiry = 5 * time.Minute
+
+// requestLogger logs the registry API calls as JSON lines and, with --trace-requests,
+// writes a span for each of them in the OpenTelemetry protocol JSON encoding, which the
+// otlpjsonfile receiver of the OpenTelemetry collector reads.
+type requestLogger struct {
+	lock     sync.Mutex
+	log      io.Writer
+	traces   io.Writer
+	traceID  string
+	failures map[string]failedRequest
+	now      func() time.Time
+}
+
+// failedRequest counts the consecutive failures of a request, which are retried.
+type failedRequest struct {
+	count int
+	last  time.Time
+}
+
+// requestLogFiles are the files the requests are logged and traced to, by path. The registry
+// contexts of a command share them until CloseRequestLogs is called.
+var requestLogFiles = struct {
+	lock  sync.Mutex
+	files map[string]*os.File
+}{files: map[string]*os.File{}}
+
+// openRequestLogFile returns the file at path, opening it unless a registry context of the
+// command already did, or the standard error for "-".
+func openRequestLogFile(path string) (io.Writer, error) {
+	switch path {
+	case "":
+		return nil, nil
+	case "-":
+		return os.Stderr, nil
+	}
+	requestLogFiles.lock.Lock()
+	defer requestLogFiles.lock.Unlock()
+	if f, ok := requestLogFiles.files[path]; ok {
+		return f, nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	requestLogFiles.files[path] = f
+	return f, nil
+}
+
+// CloseRequestLogs closes the files of --log-requests and --trace-requests once the command
+// is done sending requests to the registries.
+func CloseRequestLogs() error {
+	requestLogFiles.lock.Lock()
+	defer requestLogFiles.lock.Unlock()
+	var errs []error
+	for path, f := range requestLogFiles.files {
+		if err := f.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(requestLogFiles.files, path)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// newRequestLogger opens the files the requests are logged and traced to, "-" being
+// the standard error. It returns nil if neither is set.
+func newRequestLogger(logPath, tracePath string) (*requestLogger, error) {
+	if len(logPath) == 0 && len(tracePath) == 0 {
+		return nil, nil
+	}
+	l := &requestLogger{traceID: randomHex(16), failures: map[string]failedRequest{}, now: time.Now}
+	var err error
+	if l.log, err = openRequestLogFile(logPath); err != nil {
+		return nil, fmt.Errorf("unable to open --log-requests file: %v", err)
+	}
+	if l.traces, err = openRequestLogFile(tracePath); err != nil {
+		return nil, fmt.Errorf("unable to open --trace-requests file: %v", err)
+	}
+	return l, nil
+}
+
+// wrap returns a transport logging the requests sent through rt.
+func (l *requestLogger) wrap(rt http.RoundTripper) http.RoundTripper {
+	if l == nil {
+		return rt
+	}
+	return &loggingRoundTripper{logger: l, delegate: rt}
+}
+
+// attempt returns how many times the same request failed in a row before, which is how
+// the retries of the registry client are counted. The failures are forgotten once they
+// expire, so that only the requests being retried are remembered.
+func (l *requestLogger) attempt(req *http.Request) int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	now := l.now()
+	for key, failure := range l.failures {
+		if now.Sub(failure.last) > failureExpiry {
+			delete(l.failures, key)
+		}
+	}
+	return l.failures[requestKey(req)].count
+}
+
+// finished remembers the failure of a request which may be retried, such as a
+// connection error, 429 or 5xx, and forgets the request once it completes otherwise.
+func (l *requestLogger) finished(req *http.Request, status int, err error) {
+	key := requestKey(req)
+	if err == nil && status != http.StatusTooManyRequests && status < http.StatusInternalServerError {
+		delete(l.failures, key)
+		return
+	}
+	failure := l.failures[key]
+	failure.count++
+	failure.last = l.now()
+	l.failures[key] = failure
+}
+
+func requestKey(req *http.Request) string {
+	return req.Method + " " + req.URL.String()
+}
+
+// record logs and traces a request once its response has been read.
+func (l *requestLogger) record(req *http.Request, spanID string, start time.Time, retry, status int, bytes int64, err error) {
+	end := l.now()
+	entry := requestLog{
+		Time:     start.UTC(),
+		Method:   req.Method,
+		URL:      redactURL(req.URL),
+		Status:   status,
+		Duration: float64(end.Sub(start).Microseconds()) / 1000,
+		Bytes:    bytes,
+		Retry:    retry,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.finished(req, status, err)
+	if l.log != nil {
+		data, _ := json.Marshal(entry)
+		fmt.Fprintf(l.log, "%s\n", data)
+	}
+	if l.traces != nil {
+		data, _ := json.Marshal(l.span(req, spanID, start, end, entry))
+		fmt.Fprintf(l.traces, "%s\n", data)
+	}
+}
+
+// span returns an ExportTraceServiceRequest holding the client span of the request,
+// with the attributes of the OpenTelemetry HTTP semantic conventions.
+func (l *requestLogger) span(req *http.Request, spanID string, start, end time.Time, entry requestLog) map[string]interface{} {
+	str := func(key, value string) map[string]interface{} {
+		return map[string]interface{}{"key": key, "value": map[string]interface{}{"stringValue": value}}
+	}
+	integer := func(key string, value int64) map[string]interface{} {
+		return map[string]interface{}{"key": key, "value": map[string]interface{}{"intValue": strconv.FormatInt(value, 10)}}
+	}
+	attributes := []interface{}{
+		str("http.request.method", entry.Method),
+		str("url.full", entry.URL),
+		str("server.address", req.URL.Hostname()),
+		integer("http.request.resend_count", int64(entry.Retry)),
+		integer("http.response.body.size", entry.Bytes),
+	}
+	status := map[string]interface{}{}
+	if entry.Status != 0 {
+		attributes = append(attributes, integer("http.response.status_code", int64(entry.Status)))
+	}
+	if len(entry.Error) > 0 || entry.Status >= 400 {
+		// STATUS_CODE_ERROR
+		status["code"] = 2
+		status["message"] = entry.Error
+	}
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{"attributes": []interface{}{str("service.name", "oc")}},
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": map[string]interface{}{"name": "github.com/openshift/oc/pkg/cli/image/manifest"},
+				"spans": []interface{}{map[string]interface{}{
+					"traceId": l.traceID,
+					"spanId":  spanID,
+					"name":    entry.Method,
+					// SPAN_KIND_CLIENT
+					"kind":              3,
+					"startTimeUnixNano": strconv.FormatInt(start.UnixNano(), 10),
+					"endTimeUnixNano":   strconv.FormatInt(end.UnixNano(), 10),
+					"attributes":        attributes,
+					"status":            status,
+				}},
+			}},
+		}},
+	}
+}
+
+type loggingRoundTripper struct {
+	logger   *requestLogger
+	delegate http.RoundTripper
+}
+
+func (rt *loggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	l := rt.logger
+	retry := l.attempt(req)
+	spanID := randomHex(8)
+	if l.traces != nil {
+		req = req.Clone(req.Context())
+		req.Header.Set("traceparent", fmt.Sprintf("00-%s-%s-01", l.traceID, spanID))
+	}
+
+	start := l.now()
+	resp, err := rt.delegate.RoundTrip(req)
+	if err != nil {
+		l.record(req, spanID, start, retry, 0, 0, err)
+		return resp, err
+	}
+	if resp.Body == nil || resp.Body == http.NoBody || req.Method == http.MethodHead {
+		l.record(req, spanID, start, retry, resp.StatusCode, 0, nil)
+		return resp, nil
+	}
+	resp.Body = &loggedBody{ReadCloser: resp.Body, done: func(bytes int64, err error) {
+		l.record(req, spanID, start, retry, resp.StatusCode, bytes, err)
+	}}
+	return resp, nil
+}
+
+// loggedBody counts the bytes of a response and reports them once it has been read
+// entirely or closed.
+type loggedBody struct {
+	io.ReadCloser
+	bytes int64
+	once  sync.Once
+	done  func(int64, error)
+}
+
+func (b *loggedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes += int64(n)
+	switch {
+	case err == io.EOF:
+		b.once.Do(func() { b.done(b.bytes, nil) })
+	case err != nil:
+		b.once.Do(func() { b.done(b.bytes, err) })
+	}
+	return n, err
+}
+
+func (b *loggedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b.bytes, nil) })
+	return err
+}
+
+// redactURL removes the credentials and the query of a URL, which holds the signatures
+// of the storage redirects of some registries.
+func redactURL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	redacted.RawQuery = ""
+	redacted.ForceQuery = false
+	return redacted.String()
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
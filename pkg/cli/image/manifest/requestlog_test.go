@@ -0,0 +1,190 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestLogger(t *testing.T) {
+	var traceparents []string
+	unavailable := 2
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		traceparents = append(traceparents, req.Header.Get("traceparent"))
+		switch req.URL.Path {
+		case "/v2/missing/manifests/latest":
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		case "/v2/app/manifests/latest":
+			if unavailable > 0 {
+				unavailable--
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+		}
+		w.Write([]byte("0123456789"))
+	}))
+	defer server.Close()
+
+	log, traces := &bytes.Buffer{}, &bytes.Buffer{}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	l := &requestLogger{log: log, traces: traces, traceID: randomHex(16), failures: map[string]failedRequest{}, now: func() time.Time {
+		now = now.Add(5 * time.Millisecond)
+		return now
+	}}
+	client := &http.Client{Transport: l.wrap(http.DefaultTransport)}
+	for _, path := range []string{
+		"/v2/app/blobs/sha256:abc?X-Amz-Signature=secret",
+		"/v2/app/blobs/sha256:abc?X-Amz-Signature=secret",
+		"/v2/missing/manifests/latest",
+		"/v2/app/manifests/latest",
+		"/v2/app/manifests/latest",
+		"/v2/app/manifests/latest",
+		"/v2/app/manifests/latest",
+	} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	var entries []requestLog
+	s := bufio.NewScanner(log)
+	for s.Scan() {
+		var entry requestLog
+		if err := json.Unmarshal(s.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid log line %q: %v", s.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 7 {
+		t.Fatalf("expected 7 requests to be logged, got %d:\n%s", len(entries), log.String())
+	}
+	if e := entries[0]; e.Method != "GET" || e.URL != server.URL+"/v2/app/blobs/sha256:abc" || e.Status != 200 || e.Bytes != 10 || e.Retry != 0 || e.Duration != 5 {
+		t.Errorf("unexpected first request: %#v", e)
+	}
+	if e := entries[1]; e.Retry != 0 {
+		t.Errorf("expected the same request sent again after a success not to be a retry: %#v", e)
+	}
+	if e := entries[2]; e.Status != 404 || e.Retry != 0 {
+		t.Errorf("unexpected third request: %#v", e)
+	}
+	// the manifest is retried twice after a 503, and sent again once retrieved
+	for i, expected := range []struct{ status, retry int }{{503, 0}, {503, 1}, {200, 2}, {200, 0}} {
+		if e := entries[3+i]; e.Status != expected.status || e.Retry != expected.retry {
+			t.Errorf("expected attempt %d of the manifest to have status %d and retry %d: %#v", i, expected.status, expected.retry, e)
+		}
+	}
+	if len(l.failures) != 0 {
+		t.Errorf("expected the completed requests to be forgotten: %v", l.failures)
+	}
+	if strings.Contains(log.String(), "secret") || strings.Contains(traces.String(), "secret") {
+		t.Errorf("the query of the URLs should not be logged:\n%s", log.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(traces.String()), "\n")
+	var span struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []struct {
+					TraceID string `json:"traceId"`
+					SpanID  string `json:"spanId"`
+					Status  struct {
+						Code int `json:"code"`
+					} `json:"status"`
+				} `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	if len(lines) != 7 {
+		t.Fatalf("expected 7 spans, got %d", len(lines))
+	}
+	if err := json.Unmarshal([]byte(lines[2]), &span); err != nil {
+		t.Fatal(err)
+	}
+	got := span.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	if got.TraceID != l.traceID || got.Status.Code != 2 {
+		t.Errorf("unexpected span: %s", lines[2])
+	}
+	if want := "00-" + l.traceID + "-" + got.SpanID + "-01"; traceparents[2] != want {
+		t.Errorf("unexpected traceparent %q, want %q", traceparents[2], want)
+	}
+}
+
+func TestRequestLoggerFailureExpiry(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	l := &requestLogger{failures: map[string]failedRequest{}, now: func() time.Time { return now }}
+	req := httptest.NewRequest(http.MethodGet, "https://registry.example.com/v2/app/manifests/latest", nil)
+	other := httptest.NewRequest(http.MethodHead, "https://registry.example.com/v2/app/manifests/latest", nil)
+
+	l.finished(req, 0, io.ErrUnexpectedEOF)
+	l.finished(other, http.StatusTooManyRequests, nil)
+	if retry := l.attempt(req); retry != 1 {
+		t.Errorf("expected the request to be retried once, got %d", retry)
+	}
+	now = now.Add(failureExpiry / 2)
+	l.finished(req, http.StatusBadGateway, nil)
+	if retry := l.attempt(req); retry != 2 {
+		t.Errorf("expected the request to be retried twice, got %d", retry)
+	}
+
+	// the failure of the other request expired, while the request failed again since
+	now = now.Add(failureExpiry/2 + time.Second)
+	if retry := l.attempt(other); retry != 0 {
+		t.Errorf("expected the failure of the other request to expire, got %d", retry)
+	}
+	if _, ok := l.failures[requestKey(other)]; ok || len(l.failures) != 1 {
+		t.Errorf("expected only the failure of the request to be remembered: %v", l.failures)
+	}
+	if retry := l.attempt(req); retry != 2 {
+		t.Errorf("expected the request to be retried twice, got %d", retry)
+	}
+}
+
+func TestCloseRequestLogs(t *testing.T) {
+	dir := t.TempDir()
+	logPath, tracePath := filepath.Join(dir, "requests.log"), filepath.Join(dir, "traces.json")
+	first, err := newRequestLogger(logPath, tracePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := newRequestLogger(logPath, "-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.log != second.log || second.traces != os.Stderr {
+		t.Errorf("expected the registry contexts to share the files, got %v and %v", first.log, second.log)
+	}
+
+	if err := CloseRequestLogs(); err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range []io.Writer{first.log, first.traces} {
+		if _, err := w.Write([]byte("closed\n")); err == nil {
+			t.Errorf("expected %s to be closed", w.(*os.File).Name())
+		}
+	}
+
+	// the next command opens the files again
+	third, err := newRequestLogger(logPath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer CloseRequestLogs()
+	if third.log == first.log || third.traces != nil {
+		t.Errorf("expected the log to be opened again, got %v and %v", third.log, third.traces)
+	}
+	if _, err := third.log.Write([]byte("opened\n")); err != nil {
+		t.Fatal(err)
+	}
+}
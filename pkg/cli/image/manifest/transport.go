@@ -0,0 +1,385 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/plugin/pkg/client/auth/exec"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	clientcmdv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+	"k8s.io/client-go/transport"
+	"sigs.k8s.io/yaml"
+)
+
+// RegistryTransportConfig is the content of the --registry-transport-config file, which
+// sets how each registry is connected to, for instance in environments where some
+// registries are only reachable through a proxy and others must be reached directly:
+//
+//	registries:
+//	- host: quay.io
+//	  proxy: http://proxy.example.com:3128
+//	- host: "*.registry.example.com"
+//	  proxy: direct
+//	  certificateAuthority: /etc/pki/example-ca.pem
+//	  clientCertificate: /etc/pki/oc.crt
+//	  clientKey: /etc/pki/oc.key
+//	  minTLSVersion: VersionTLS13
+//	  pinnedPublicKeys:
+//	  - 47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=
+//	- host: registry.corp.example.com
+//	  exec:
+//	    apiVersion: client.authentication.k8s.io/v1
+//	    command: corp-registry-login
+//	    args: ["--format", "exec-credential"]
+type RegistryTransportConfig struct {
+	Registries []RegistryTransport `json:"registries"`
+}
+
+// RegistryTransport is the connection configuration of the registries matching Host.
+type RegistryTransport struct {
+	// Host is the host name of the registry, with a port to only match the requests
+	// to that port, or *.domain to match all the host names in the domain.
+	Host string `json:"host"`
+	// Proxy is the URL of the proxy the requests to the registry are sent through, or
+	// "direct" to not use a proxy. Defaults to the HTTPS_PROXY and NO_PROXY environment
+	// variables.
+	Proxy string `json:"proxy,omitempty"`
+	// CertificateAuthority is the path to the CA bundle the certificate of the registry
+	// is verified with, in addition to --certificate-authority or the system roots.
+	CertificateAuthority string `json:"certificateAuthority,omitempty"`
+	// ClientCertificate and ClientKey are the paths to the certificate and key the
+	// client authenticates to the registry with.
+	ClientCertificate string `json:"clientCertificate,omitempty"`
+	ClientKey         string `json:"clientKey,omitempty"`
+	// MinTLSVersion is the minimum TLS version accepted, VersionTLS10 to VersionTLS13.
+	MinTLSVersion string `json:"minTLSVersion,omitempty"`
+	// PinnedPublicKeys are the base64 encoded SHA-256 hashes of the public keys, in the DER
+	// SubjectPublicKeyInfo form, that one of the certificates presented by the registry must
+	// have. The connection is refused otherwise, even if the certificate is trusted.
+	PinnedPublicKeys []string `json:"pinnedPublicKeys,omitempty"`
+	// BearerTokenFile is the path to a file holding the bearer token sent to the registry
+	// instead of the credentials of --registry-config. The file is read again when it
+	// changes, for short-lived tokens renewed while oc runs.
+	BearerTokenFile string `json:"bearerTokenFile,omitempty"`
+	// Exec is a credential plugin run to get the bearer token sent to the registry, like the
+	// exec user credentials of a kubeconfig. The plugin is run again when the token expires
+	// or is rejected. Only the token of the credential is used.
+	Exec *clientcmdv1.ExecConfig `json:"exec,omitempty"`
+}
+
+var tlsVersions = map[string]uint16{
+	"VersionTLS10": tls.VersionTLS10,
+	"VersionTLS11": tls.VersionTLS11,
+	"VersionTLS12": tls.VersionTLS12,
+	"VersionTLS13": tls.VersionTLS13,
+}
+
+// LoadRegistryTransportConfig reads and validates the registry transport configuration file.
+func LoadRegistryTransportConfig(path string) (*RegistryTransportConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &RegistryTransportConfig{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+	seen := map[string]bool{}
+	for i, registry := range config.Registries {
+		if err := registry.validate(); err != nil {
+			return nil, fmt.Errorf("%s: registries[%d]: %v", path, i, err)
+		}
+		if seen[registry.Host] {
+			return nil, fmt.Errorf("%s: registries[%d]: host %s is configured more than once", path, i, registry.Host)
+		}
+		seen[registry.Host] = true
+	}
+	return config, nil
+}
+
+func (r *RegistryTransport) validate() error {
+	if len(r.Host) == 0 {
+		return fmt.Errorf("host is required")
+	}
+	if strings.Contains(r.Host, "/") || strings.Contains(strings.TrimPrefix(r.Host, "*."), "*") {
+		return fmt.Errorf("host %q must be HOST[:PORT] or *.DOMAIN", r.Host)
+	}
+	if len(r.Proxy) > 0 && r.Proxy != "direct" {
+		u, err := url.Parse(r.Proxy)
+		if err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 {
+			return fmt.Errorf("proxy %q must be a URL like http://proxy.example.com:3128 or direct", r.Proxy)
+		}
+	}
+	if (len(r.ClientCertificate) == 0) != (len(r.ClientKey) == 0) {
+		return fmt.Errorf("clientCertificate and clientKey must be set together")
+	}
+	if _, ok := tlsVersions[r.MinTLSVersion]; len(r.MinTLSVersion) > 0 && !ok {
+		return fmt.Errorf("minTLSVersion %q must be one of VersionTLS10, VersionTLS11, VersionTLS12 or VersionTLS13", r.MinTLSVersion)
+	}
+	for _, pin := range r.PinnedPublicKeys {
+		if hash, err := base64.StdEncoding.DecodeString(pin); err != nil || len(hash) != sha256.Size {
+			return fmt.Errorf("pinned public key %q must be a base64 encoded SHA-256 hash", pin)
+		}
+	}
+	if r.Exec != nil {
+		switch {
+		case len(r.BearerTokenFile) > 0:
+			return fmt.Errorf("bearerTokenFile and exec may not be set together")
+		case len(r.Exec.Command) == 0:
+			return fmt.Errorf("exec.command is required")
+		case len(r.Exec.APIVersion) == 0:
+			return fmt.Errorf("exec.apiVersion is required")
+		case r.Exec.ProvideClusterInfo:
+			return fmt.Errorf("exec.provideClusterInfo is not supported for registries")
+		}
+	}
+	return nil
+}
+
+// AddTokenFiles sets the bearer token files of REGISTRY=PATH pairs, adding the registries
+// which are not configured yet.
+func (c *RegistryTransportConfig) AddTokenFiles(tokenFiles []string) error {
+	for _, s := range tokenFiles {
+		host, path, ok := strings.Cut(s, "=")
+		if !ok || len(host) == 0 || len(path) == 0 {
+			return fmt.Errorf("%q must be REGISTRY=PATH", s)
+		}
+		registry := c.registry(host)
+		if len(registry.BearerTokenFile) > 0 || registry.Exec != nil {
+			return fmt.Errorf("the credentials of %s are set more than once", host)
+		}
+		registry.BearerTokenFile = path
+		if err := registry.validate(); err != nil {
+			return fmt.Errorf("%s: %v", s, err)
+		}
+	}
+	return nil
+}
+
+// AddPins adds the public key pins of REGISTRY=HASH pairs, adding the registries which are
+// not configured yet.
+func (c *RegistryTransportConfig) AddPins(pins []string) error {
+	for _, s := range pins {
+		host, pin, ok := strings.Cut(s, "=")
+		if !ok || len(host) == 0 || len(pin) == 0 {
+			return fmt.Errorf("%q must be REGISTRY=HASH", s)
+		}
+		registry := c.registry(host)
+		registry.PinnedPublicKeys = append(registry.PinnedPublicKeys, pin)
+		if err := registry.validate(); err != nil {
+			return fmt.Errorf("%s: %v", s, err)
+		}
+	}
+	return nil
+}
+
+// SetDefaultMinTLSVersion sets the minimum TLS version of the registries that do not set one.
+func (c *RegistryTransportConfig) SetDefaultMinTLSVersion(version string) {
+	for i := range c.Registries {
+		if len(c.Registries[i].MinTLSVersion) == 0 {
+			c.Registries[i].MinTLSVersion = version
+		}
+	}
+}
+
+// registry returns the configuration of the host, adding it if it is not configured.
+func (c *RegistryTransportConfig) registry(host string) *RegistryTransport {
+	for i := range c.Registries {
+		if c.Registries[i].Host == host {
+			return &c.Registries[i]
+		}
+	}
+	c.Registries = append(c.Registries, RegistryTransport{Host: host})
+	return &c.Registries[len(c.Registries)-1]
+}
+
+// matches returns true if the registry transport applies to the host[:port] of a request.
+func (r *RegistryTransport) matches(host string) bool {
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	if domain, ok := strings.CutPrefix(r.Host, "*."); ok {
+		return strings.HasSuffix(hostname, "."+domain)
+	}
+	if strings.Contains(r.Host, ":") {
+		return r.Host == host
+	}
+	return r.Host == hostname
+}
+
+// roundTripper returns the transport of the registry, which verifies the certificate of
+// the registry with the CA bundle in addition to caData, or not at all if insecure. The
+// requests are sent through proxy, if set, when the registry does not set its own.
+func (r *RegistryTransport) roundTripper(caData []byte, insecure bool, proxy func(*http.Request) (*url.URL, error), userAgent string) (http.RoundTripper, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecure}
+	if v, ok := tlsVersions[r.MinTLSVersion]; ok {
+		tlsConfig.MinVersion = v
+	}
+	if len(r.PinnedPublicKeys) > 0 {
+		tlsConfig.VerifyConnection = verifyPinnedPublicKeys(r.Host, r.PinnedPublicKeys)
+	}
+	if !insecure && (len(caData) > 0 || len(r.CertificateAuthority) > 0) {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if len(caData) > 0 && !pool.AppendCertsFromPEM(caData) {
+			return nil, fmt.Errorf("no certificates found in the registry ca bundle")
+		}
+		if len(r.CertificateAuthority) > 0 {
+			data, err := os.ReadFile(r.CertificateAuthority)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the ca bundle of %s: %v", r.Host, err)
+			}
+			if !pool.AppendCertsFromPEM(data) {
+				return nil, fmt.Errorf("no certificates found in the ca bundle %s of %s", r.CertificateAuthority, r.Host)
+			}
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if len(r.ClientCertificate) > 0 {
+		cert, err := tls.LoadX509KeyPair(r.ClientCertificate, r.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the client certificate of %s: %v", r.Host, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = tlsConfig
+	switch r.Proxy {
+	case "":
+		if proxy != nil {
+			t.Proxy = proxy
+		}
+	case "direct":
+		t.Proxy = nil
+	default:
+		proxy, err := url.Parse(r.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		t.Proxy = http.ProxyURL(proxy)
+	}
+	return r.credentialsRoundTripper(transport.NewUserAgentRoundTripper(userAgent, t))
+}
+
+// credentialsRoundTripper returns a transport sending the bearer token of the token file or
+// of the credential plugin of the registry with the requests that are not authorized yet.
+func (r *RegistryTransport) credentialsRoundTripper(rt http.RoundTripper) (http.RoundTripper, error) {
+	switch {
+	case len(r.BearerTokenFile) > 0:
+		t, err := transport.NewBearerAuthWithRefreshRoundTripper("", r.BearerTokenFile, rt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the bearer token of %s: %v", r.Host, err)
+		}
+		return t, nil
+	case r.Exec != nil:
+		config := &clientcmdapi.ExecConfig{
+			Command:         r.Exec.Command,
+			Args:            r.Exec.Args,
+			APIVersion:      r.Exec.APIVersion,
+			InstallHint:     r.Exec.InstallHint,
+			InteractiveMode: clientcmdapi.ExecInteractiveMode(r.Exec.InteractiveMode),
+		}
+		if len(config.InteractiveMode) == 0 {
+			config.InteractiveMode = clientcmdapi.IfAvailableExecInteractiveMode
+		}
+		for _, env := range r.Exec.Env {
+			config.Env = append(config.Env, clientcmdapi.ExecEnvVar{Name: env.Name, Value: env.Value})
+		}
+		authenticator, err := exec.GetAuthenticator(config, nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid credential plugin of %s: %v", r.Host, err)
+		}
+		transportConfig := &transport.Config{}
+		if err := authenticator.UpdateTransportConfig(transportConfig); err != nil {
+			return nil, fmt.Errorf("invalid credential plugin of %s: %v", r.Host, err)
+		}
+		return transportConfig.WrapTransport(rt), nil
+	default:
+		return rt, nil
+	}
+}
+
+// verifyPinnedPublicKeys returns a TLS connection check refusing the registries that do not
+// present a certificate with one of the pinned public keys. The check applies to insecure
+// connections as well, whose certificates are not verified otherwise.
+func verifyPinnedPublicKeys(host string, pins []string) func(tls.ConnectionState) error {
+	pinned := make(map[string]bool, len(pins))
+	for _, pin := range pins {
+		pinned[pin] = true
+	}
+	return func(cs tls.ConnectionState) error {
+		for _, cert := range cs.PeerCertificates {
+			hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+			if pinned[base64.StdEncoding.EncodeToString(hash[:])] {
+				return nil
+			}
+		}
+		return fmt.Errorf("the certificates of %s do not have any of the pinned public keys", host)
+	}
+}
+
+// defaultRoundTripper returns the transport of the registries which are not configured,
+// accepting only minTLSVersion and above if it is set.
+func defaultRoundTripper(config *rest.Config, minTLSVersion string) (http.RoundTripper, error) {
+	if len(minTLSVersion) == 0 {
+		return rest.TransportFor(config)
+	}
+	tlsConfig, err := rest.TLSConfigFor(config)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	tlsConfig.MinVersion = tlsVersions[minTLSVersion]
+	t := utilnet.SetTransportDefaults(&http.Transport{TLSClientConfig: tlsConfig, Proxy: config.Proxy})
+	return transport.NewUserAgentRoundTripper(config.UserAgent, t), nil
+}
+
+// registryRoundTripper sends the requests to the transport of the registry of their host,
+// or to the default transport for the registries that are not configured.
+type registryRoundTripper struct {
+	registries []RegistryTransport
+	transports []http.RoundTripper
+	fallback   http.RoundTripper
+}
+
+// newRegistryRoundTripper returns a transport applying the configuration of each registry,
+// the first registry matching the host of a request in the order of the file being used.
+func newRegistryRoundTripper(config *RegistryTransportConfig, fallback http.RoundTripper, caData []byte, insecure bool, proxy func(*http.Request) (*url.URL, error), userAgent string) (http.RoundTripper, error) {
+	if config == nil || len(config.Registries) == 0 {
+		return fallback, nil
+	}
+	rt := &registryRoundTripper{registries: config.Registries, fallback: fallback}
+	for i := range config.Registries {
+		t, err := config.Registries[i].roundTripper(caData, insecure, proxy, userAgent)
+		if err != nil {
+			return nil, err
+		}
+		rt.transports = append(rt.transports, t)
+	}
+	return rt, nil
+}
+
+func (rt *registryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	for i := range rt.registries {
+		if rt.registries[i].matches(req.URL.Host) {
+			return rt.transports[i].RoundTrip(req)
+		}
+	}
+	return rt.fallback.RoundTrip(req)
+}
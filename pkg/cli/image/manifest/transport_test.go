@@ -0,0 +1,269 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	clientcmdv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+)
+
+func TestLoadRegistryTransportConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			config: `registries:
+- host: quay.io
+  proxy: http://proxy.example.com:3128
+- host: "*.registry.example.com"
+  proxy: direct
+  minTLSVersion: VersionTLS13
+- host: registry.corp.example.com
+  exec:
+    apiVersion: client.authentication.k8s.io/v1
+    command: corp-registry-login
+`,
+		},
+		{
+			name:    "credential plugin without api version",
+			config:  "registries:\n- host: quay.io\n  exec:\n    command: login\n",
+			wantErr: "exec.apiVersion is required",
+		},
+		{
+			name:    "token file and credential plugin",
+			config:  "registries:\n- host: quay.io\n  bearerTokenFile: /run/token\n  exec:\n    apiVersion: client.authentication.k8s.io/v1\n    command: login\n",
+			wantErr: "may not be set together",
+		},
+		{
+			name:    "missing host",
+			config:  "registries:\n- proxy: direct\n",
+			wantErr: "host is required",
+		},
+		{
+			name:    "invalid host",
+			config:  "registries:\n- host: quay.io/openshift\n",
+			wantErr: "must be HOST[:PORT] or *.DOMAIN",
+		},
+		{
+			name:    "duplicate host",
+			config:  "registries:\n- host: quay.io\n- host: quay.io\n",
+			wantErr: "configured more than once",
+		},
+		{
+			name:    "invalid proxy",
+			config:  "registries:\n- host: quay.io\n  proxy: proxy.example.com\n",
+			wantErr: "must be a URL",
+		},
+		{
+			name:    "client certificate without key",
+			config:  "registries:\n- host: quay.io\n  clientCertificate: /etc/pki/oc.crt\n",
+			wantErr: "must be set together",
+		},
+		{
+			name:    "invalid TLS version",
+			config:  "registries:\n- host: quay.io\n  minTLSVersion: TLS1.2\n",
+			wantErr: "minTLSVersion",
+		},
+		{
+			name:    "unknown field",
+			config:  "registries:\n- host: quay.io\n  caBundle: /etc/pki/ca.pem\n",
+			wantErr: "unable to parse",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(test.config), 0600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadRegistryTransportConfig(path)
+			switch {
+			case len(test.wantErr) == 0 && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case len(test.wantErr) > 0 && (err == nil || !strings.Contains(err.Error(), test.wantErr)):
+				t.Fatalf("expected error containing %q, got %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRegistryTransportMatches(t *testing.T) {
+	tests := []struct {
+		registry string
+		host     string
+		want     bool
+	}{
+		{registry: "quay.io", host: "quay.io", want: true},
+		{registry: "quay.io", host: "quay.io:443", want: true},
+		{registry: "quay.io", host: "cdn.quay.io"},
+		{registry: "registry.example.com:5000", host: "registry.example.com:5000", want: true},
+		{registry: "registry.example.com:5000", host: "registry.example.com"},
+		{registry: "*.example.com", host: "registry.example.com:5000", want: true},
+		{registry: "*.example.com", host: "example.com"},
+	}
+	for _, test := range tests {
+		r := &RegistryTransport{Host: test.registry}
+		if got := r.matches(test.host); got != test.want {
+			t.Errorf("%s matches %s: got %t, want %t", test.registry, test.host, got, test.want)
+		}
+	}
+}
+
+func TestRegistryRoundTripper(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		proxied = append(proxied, req.Host)
+		if ua := req.Header.Get("User-Agent"); ua != "oc-test" {
+			t.Errorf("unexpected user agent %q", ua)
+		}
+	}))
+	defer proxy.Close()
+	var direct []string
+	fallback := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		direct = append(direct, req.URL.Host)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	config := &RegistryTransportConfig{Registries: []RegistryTransport{{Host: "*.proxied.example.com", Proxy: proxy.URL}}}
+	rt, err := newRegistryRoundTripper(config, fallback, nil, false, nil, "oc-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, host := range []string{"registry.proxied.example.com", "quay.io"} {
+		req := &http.Request{Method: http.MethodGet, URL: &url.URL{Scheme: "http", Host: host, Path: "/v2/"}, Header: http.Header{}}
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if len(proxied) != 1 || proxied[0] != "registry.proxied.example.com" {
+		t.Errorf("unexpected proxied requests: %v", proxied)
+	}
+	if len(direct) != 1 || direct[0] != "quay.io" {
+		t.Errorf("unexpected direct requests: %v", direct)
+	}
+}
+
+func TestRegistryRoundTripperCredentials(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	plugin := filepath.Join(dir, "plugin")
+	credential := `{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential","status":{"token":"exec-token"}}`
+	if err := os.WriteFile(plugin, []byte("#!/bin/sh\necho '"+credential+"'\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &RegistryTransportConfig{Registries: []RegistryTransport{{
+		Host: "exec.example.com",
+		Exec: &clientcmdv1.ExecConfig{APIVersion: "client.authentication.k8s.io/v1", Command: plugin, InteractiveMode: clientcmdv1.NeverExecInteractiveMode},
+	}}}
+	if err := config.AddTokenFiles([]string{"token.example.com=" + tokenFile}); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.AddTokenFiles([]string{"exec.example.com=" + tokenFile}); err == nil {
+		t.Fatal("expected an error for a registry with a credential plugin and a token file")
+	}
+
+	authorization := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		authorization[req.Host] = req.Header.Get("Authorization")
+	}))
+	defer server.Close()
+	for i := range config.Registries {
+		config.Registries[i].Proxy = server.URL
+	}
+	fallback := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		authorization[req.URL.Host] = req.Header.Get("Authorization")
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	rt, err := newRegistryRoundTripper(config, fallback, nil, false, nil, "oc-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, host := range []string{"token.example.com", "exec.example.com", "quay.io"} {
+		req := &http.Request{Method: http.MethodGet, URL: &url.URL{Scheme: "http", Host: host, Path: "/v2/"}, Header: http.Header{}}
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	want := map[string]string{"token.example.com": "Bearer file-token", "exec.example.com": "Bearer exec-token", "quay.io": ""}
+	for host, value := range want {
+		if authorization[host] != value {
+			t.Errorf("%s: expected authorization %q, got %q", host, value, authorization[host])
+		}
+	}
+}
+
+func TestRegistryTransportPinnedPublicKeys(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+	hash := sha256.Sum256(server.Certificate().RawSubjectPublicKeyInfo)
+	pin := base64.StdEncoding.EncodeToString(hash[:])
+	other := base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	tests := []struct {
+		name      string
+		transport RegistryTransport
+		wantErr   string
+	}{
+		{name: "pinned", transport: RegistryTransport{PinnedPublicKeys: []string{other, pin}}},
+		{name: "not pinned", transport: RegistryTransport{PinnedPublicKeys: []string{other}}, wantErr: "do not have any of the pinned public keys"},
+		{name: "TLS version too low", transport: RegistryTransport{MinTLSVersion: "VersionTLS13"}, wantErr: "protocol version"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.transport.Host = "registry.example.com"
+			if err := test.transport.validate(); err != nil {
+				t.Fatal(err)
+			}
+			rt, err := test.transport.roundTripper(nil, true, nil, "oc-test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := (&http.Client{Transport: rt}).Get(server.URL + "/v2/")
+			if err == nil {
+				resp.Body.Close()
+			}
+			switch {
+			case len(test.wantErr) == 0 && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case len(test.wantErr) > 0 && (err == nil || !strings.Contains(err.Error(), test.wantErr)):
+				t.Fatalf("expected error containing %q, got %v", test.wantErr, err)
+			}
+		})
+	}
+
+	config := &RegistryTransportConfig{}
+	if err := config.AddPins([]string{"quay.io=" + pin, "quay.io=" + other}); err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Registries) != 1 || len(config.Registries[0].PinnedPublicKeys) != 2 {
+		t.Errorf("unexpected pins: %#v", config.Registries)
+	}
+	if err := config.AddPins([]string{"quay.io=invalid"}); err == nil {
+		t.Error("expected an error for an invalid pin")
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
@@ -0,0 +1,172 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/blang/semver"
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/manifestlist"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	imagemanifest "github.com/openshift/oc/pkg/cli/image/manifest"
+)
+
+// maxDeltaBaseTags is the number of tags of a destination repository whose blobs are looked up by
+// --delta. The tags of the latest versions are used, see sortTags.
+const maxDeltaBaseTags = 10
+
+// deltaBases caches the blobs referenced by the existing tags of the destination repositories.
+type deltaBases struct {
+	lock  sync.Mutex
+	repos map[string]*deltaBase
+}
+
+type deltaBase struct {
+	once  sync.Once
+	blobs sets.String
+}
+
+func newDeltaBases() *deltaBases {
+	return &deltaBases{repos: make(map[string]*deltaBase)}
+}
+
+// Blobs returns the blobs referenced by the images of the latest tags of the repository, which
+// exist in the repository and do not have to be copied. Errors are ignored since the blobs are
+// then copied as usual.
+func (d *deltaBases) Blobs(ctx context.Context, name string, repo distribution.Repository) sets.String {
+	d.lock.Lock()
+	base, ok := d.repos[name]
+	if !ok {
+		base = &deltaBase{}
+		d.repos[name] = base
+	}
+	d.lock.Unlock()
+
+	base.once.Do(func() {
+		blobs, err := taggedBlobs(ctx, repo, maxDeltaBaseTags)
+		if err != nil {
+			klog.V(2).Infof("Unable to find the existing layers of %s, all layers will be copied: %v", name, err)
+			blobs = sets.NewString()
+		}
+		base.blobs = blobs
+	})
+	return base.blobs
+}
+
+// taggedBlobs returns the blobs referenced by the images of the last max tags of the repository,
+// including the images of their manifest lists. The tags of the artifacts attached to images are
+// ignored.
+func taggedBlobs(ctx context.Context, repo distribution.Repository, max int) (sets.String, error) {
+	blobs := sets.NewString()
+	all, err := repo.Tags(ctx).All(ctx)
+	if err != nil {
+		if imagemanifest.IsImageNotFound(err) || errors.As(err, &distribution.ErrRepositoryUnknown{}) {
+			return blobs, nil
+		}
+		return nil, err
+	}
+	var tags []string
+	for _, tag := range all {
+		if !strings.HasPrefix(tag, "sha256-") {
+			tags = append(tags, tag)
+		}
+	}
+	sortTags(tags)
+	if len(tags) > max {
+		tags = tags[len(tags)-max:]
+	}
+
+	manifests, err := repo.Manifests(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		desc, err := repo.Tags(ctx).Get(ctx, tag)
+		if err != nil {
+			return nil, err
+		}
+		m, err := manifests.Get(ctx, desc.Digest, imagemanifest.PreferManifestList)
+		if err != nil {
+			return nil, err
+		}
+		images := []distribution.Manifest{m}
+		if _, ok := m.(*manifestlist.DeserializedManifestList); ok {
+			images = nil
+			for _, child := range m.References() {
+				childManifest, err := manifests.Get(ctx, child.Digest)
+				if err != nil {
+					return nil, err
+				}
+				images = append(images, childManifest)
+			}
+		}
+		for _, image := range images {
+			for _, blob := range image.References() {
+				blobs.Insert(blob.Digest.String())
+			}
+		}
+	}
+	return blobs, nil
+}
+
+// deltaStats counts the blobs of an image and those skipped because they exist in the destination.
+type deltaStats struct {
+	blobs, skippedBlobs int
+	size, skippedSize   int64
+}
+
+// skipExistingBlobs marks the blobs of the images that are in existing as already present in the
+// destination of the blob copy, so that they are not copied.
+func skipExistingBlobs(c *repositoryBlobCopy, existing sets.String, images ...distribution.Manifest) deltaStats {
+	var stats deltaStats
+	seen := sets.NewString()
+	for _, image := range images {
+		if _, ok := image.(*manifestlist.DeserializedManifestList); ok {
+			continue
+		}
+		for _, blob := range image.References() {
+			if seen.Has(blob.Digest.String()) {
+				continue
+			}
+			seen.Insert(blob.Digest.String())
+			stats.blobs++
+			stats.size += blob.Size
+			if existing.Has(blob.Digest.String()) {
+				c.AlreadyExists(blob)
+				stats.skippedBlobs++
+				stats.skippedSize += blob.Size
+			}
+		}
+	}
+	return stats
+}
+
+// sortTags orders the tags which are versions, such as 4.9 or v4.10.1, by version after the other
+// tags, which are ordered lexically, so that the latest versions come last.
+func sortTags(tags []string) {
+	versions := make(map[string]semver.Version, len(tags))
+	for _, tag := range tags {
+		if v, err := semver.ParseTolerant(tag); err == nil {
+			versions[tag] = v
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		a, aOK := versions[tags[i]]
+		b, bOK := versions[tags[j]]
+		switch {
+		case aOK && bOK:
+			if c := a.Compare(b); c != 0 {
+				return c < 0
+			}
+		case aOK != bOK:
+			return bOK
+		}
+		return tags[i] < tags[j]
+	})
+}
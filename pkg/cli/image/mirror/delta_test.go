@@ -0,0 +1,49 @@
+package mirror
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     []string
+		expected []string
+	}{
+		{
+			name:     "minor versions",
+			tags:     []string{"4.10", "4.9", "4.11", "4.8"},
+			expected: []string{"4.8", "4.9", "4.10", "4.11"},
+		},
+		{
+			name:     "patch versions",
+			tags:     []string{"4.10.10", "4.10.2", "4.9.59", "4.10.0-rc.1", "4.10.0"},
+			expected: []string{"4.9.59", "4.10.0-rc.1", "4.10.0", "4.10.2", "4.10.10"},
+		},
+		{
+			name:     "prefixed versions",
+			tags:     []string{"v1.10", "1.9", "v1.9.1"},
+			expected: []string{"1.9", "v1.9.1", "v1.10"},
+		},
+		{
+			name:     "equal versions",
+			tags:     []string{"v4.9", "4.9.0", "4.9"},
+			expected: []string{"4.9", "4.9.0", "v4.9"},
+		},
+		{
+			name:     "other tags first",
+			tags:     []string{"4.10", "latest", "4.9", "4.10-x86_64", "4.9-x86_64", "stable"},
+			expected: []string{"4.10-x86_64", "4.9-x86_64", "latest", "stable", "4.9", "4.10"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tags := append([]string(nil), test.tags...)
+			sortTags(tags)
+			if !reflect.DeepEqual(tags, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, tags)
+			}
+		})
+	}
+}
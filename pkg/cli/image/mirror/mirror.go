@@ -60,8 +60,39 @@ var (
 		may be stored in your docker credential file and looked up by host, or loaded via the normal
 		AWS client locations for ENV or file.
 
+		Sources prefixed with docker-daemon: or podman: are read from the local Docker or Podman
+		engine through its socket, /var/run/docker.sock or the Podman socket of the user unless
+		$DOCKER_HOST or $CONTAINER_HOST is set. These images must be referenced by tag and cannot
+		be used as destinations.
+
+		Sources prefixed with docker-archive: or oci-archive: are read from a tar archive saved by
+		'docker save', 'podman save' or 'skopeo copy', in the form PATH[:REFERENCE]. The reference
+		selects an image of an archive with several, by name for docker-archive: and by tag for
+		oci-archive:. The images of an archive cannot be used as destinations either.
+
 		Images in manifest list format will be copied as-is unless you use --filter-by-os to restrict
 		the allowed images to copy in a manifest list. This flag has no effect on regular images.
+
+		The --include-referrers flag copies the artifacts attached to the mirrored images along
+		with them. The artifacts listed by the OCI referrers API of the source registry are
+		copied by digest. The cosign signatures, attestations and SBOMs tagged
+		sha256-<digest>.sig, .att and .sbom are copied under the same tags, like the index of
+		referrers tagged sha256-<digest> by clients that push artifacts to registries without
+		the referrers API, which is looked up when the source registry does not support it.
+		Referrers are only looked up in registry and file:// sources.
+
+		The --delta flag looks up the layers referenced by the latest tags of the destination
+		repositories, up to ten tags in version order, and only copies the layers of the images
+		that are not among them, printing the size saved. This avoids checking the layers one by
+		one when mirroring a new tag of a repository whose previous tags share most of its layers.
+		It only applies to registry destinations and is ignored with --force.
+
+		The --plan flag resolves the tags of the sources to digests and the images and blobs to
+		copy without copying them, and writes the plan as JSON to the file of --output. The
+		--from-plan flag mirrors exactly the images of such a plan later, for instance once it has
+		been reviewed: the sources are referenced by the digests of the plan and the platforms of
+		manifest lists are filtered as they were, and the mirror fails without copying anything if
+		it would copy a blob or a manifest that is not part of the plan.
 	`)
 
 	mirrorExample = templates.Examples(`
@@ -83,6 +114,24 @@ var (
 		# Copy image to S3 without setting a tag (pull via @<digest>)
 		oc image mirror myregistry.com/myimage:latest s3://s3.amazonaws.com/<region>/<bucket>/image
 
+		# Copy an image of the local Podman engine to a registry
+		oc image mirror podman:localhost/myimage:latest myregistry.com/myimage:latest
+
+		# Copy an image saved with 'docker save' to a registry
+		oc image mirror docker-archive:myimage.tar myregistry.com/myimage:latest
+
+		# Copy image with its cosign signatures and other attached artifacts
+		oc image mirror myregistry.com/myimage:latest docker.io/myrepository/myimage:latest \
+			--include-referrers
+
+		# Copy a new tag of an image, only copying the layers the existing tags do not have
+		oc image mirror myregistry.com/myimage:v2 docker.io/myrepository/myimage:v2 --delta
+
+		# Write the plan of a mirror to review it, then mirror exactly the images of the plan
+		oc image mirror myregistry.com/myimage:latest docker.io/myrepository/myimage:stable \
+			--plan -o plan.json
+		oc image mirror --from-plan plan.json
+
 		# Copy image to multiple locations
 		oc image mirror myregistry.com/myimage:latest docker.io/myrepository/myimage:stable \
 			docker.io/myrepository/myimage:dev
@@ -133,6 +182,8 @@ type MirrorImageOptions struct {
 	Force              bool
 	KeepManifestList   bool
 	ContinueOnError    bool
+	IncludeReferrers   bool
+	Delta              bool
 
 	MaxRegistry     int
 	ParallelOptions imagemanifest.ParallelOptions
@@ -143,6 +194,13 @@ type MirrorImageOptions struct {
 
 	Filenames []string
 
+	// Plan resolves the images to mirror and writes the plan to PlanOutput instead of mirroring them.
+	Plan       bool
+	PlanOutput string
+	// FromPlanFile is a plan written by Plan to execute instead of the mappings of the arguments.
+	FromPlanFile string
+	fromPlan     *mirrorPlanFile
+
 	ManifestUpdateCallback func(registry string, manifests map[godigest.Digest]godigest.Digest) error
 
 	genericiooptions.IOStreams
@@ -184,16 +242,25 @@ func NewCmdMirrorImage(streams genericiooptions.IOStreams) *cobra.Command {
 	flag.BoolVar(&o.SkipMultipleScopes, "skip-multiple-scopes", o.SkipMultipleScopes, "Some registries do not support multiple scopes passed to the registry login.")
 	flag.BoolVar(&o.Force, "force", o.Force, "Attempt to write all layers and manifests even if they exist in the remote repository.")
 	flag.BoolVar(&o.KeepManifestList, "keep-manifest-list", o.KeepManifestList, "Always mirror the manifest list. The default is to mirror the architecture specific image of the platform you are performing the mirror on unless --filter-by-os is passed.")
+	flag.BoolVar(&o.IncludeReferrers, "include-referrers", o.IncludeReferrers, "Also mirror the signatures, attestations and other artifacts attached to the images in their source repository.")
+	flag.BoolVar(&o.Delta, "delta", o.Delta, "Only copy the layers of the images that the latest tags of the destination repository do not already reference.")
 	flag.IntVar(&o.MaxRegistry, "max-registry", o.MaxRegistry, "Number of concurrent registries to connect to at any one time.")
 	flag.StringSliceVar(&o.AttemptS3BucketCopy, "s3-source-bucket", o.AttemptS3BucketCopy, "A list of bucket/path locations on S3 that may contain already uploaded blobs. Add [store] to the end to use the container image registry path convention.")
 	flag.StringSliceVarP(&o.Filenames, "filename", "f", o.Filenames, "One or more files to read SRC=DST or SRC DST [DST ...] mappings from.")
 	flag.StringVar(&o.FileDir, "dir", o.FileDir, "The directory on disk that file:// images will be copied under.")
 	flag.StringVar(&o.FromFileDir, "from-dir", o.FromFileDir, "The directory on disk that file:// images will be read from. Overrides --dir")
+	flag.BoolVar(&o.Plan, "plan", o.Plan, "Resolve the images to mirror and write the plan to --output instead of mirroring them.")
+	flag.StringVarP(&o.PlanOutput, "output", "o", o.PlanOutput, "The file to write the plan of --plan to. Defaults to the standard output.")
+	flag.StringVar(&o.FromPlanFile, "from-plan", o.FromPlanFile, "Mirror the images of a plan written by --plan, instead of those of the arguments.")
 
 	return cmd
 }
 
 func (o *MirrorImageOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(o.FromPlanFile) > 0 {
+		return o.completeFromPlan(cmd, args)
+	}
+
 	if o.KeepManifestList && len(o.FilterOptions.FilterByOS) == 0 {
 		o.FilterOptions.FilterByOS = ".*"
 	}
@@ -206,6 +273,9 @@ func (o *MirrorImageOptions) Complete(cmd *cobra.Command, args []string) error {
 		o.KeepManifestList = true
 	}
 
+	if o.SecurityOptions.Limiters == nil {
+		o.SecurityOptions.Limiters = o.ParallelOptions.Limiters()
+	}
 	registryContext, err := o.SecurityOptions.Context()
 	if err != nil {
 		return err
@@ -244,11 +314,47 @@ func (o *MirrorImageOptions) Complete(cmd *cobra.Command, args []string) error {
 		if mapping.Source.Equal(mapping.Destination) {
 			return fmt.Errorf("SRC and DST may not be the same")
 		}
+		// the other sources cannot be referenced by digest when the plan is executed
+		if o.Plan && mapping.Source.Type != imagesource.DestinationRegistry && mapping.Source.Type != imagesource.DestinationFile {
+			return fmt.Errorf("--plan only supports registry and file:// sources: %s", mapping.Source)
+		}
 	}
 
 	return nil
 }
 
+// completeFromPlan loads the mappings and the options of the plan of --from-plan.
+func (o *MirrorImageOptions) completeFromPlan(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 || len(o.Filenames) > 0 {
+		return fmt.Errorf("--from-plan may not be used with mappings from arguments or --filename")
+	}
+	for _, name := range []string{"filter-by-os", "keep-manifest-list", "include-referrers"} {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s may not be used with --from-plan, the plan records it", name)
+		}
+	}
+	f, err := readMirrorPlanFile(o.FromPlanFile, o.In)
+	if err != nil {
+		return err
+	}
+	o.fromPlan = f
+	o.FilterOptions.FilterByOS = f.FilterByOS
+	o.FilterOptions.DefaultOSFilter = f.DefaultOSFilter
+	o.KeepManifestList = f.KeepManifestList
+	o.IncludeReferrers = f.IncludeReferrers
+	if o.SecurityOptions.Limiters == nil {
+		o.SecurityOptions.Limiters = o.ParallelOptions.Limiters()
+	}
+	o.Mappings, err = f.mappings()
+	if err != nil {
+		return fmt.Errorf("the plan %s is not valid: %v", o.FromPlanFile, err)
+	}
+	if len(o.Mappings) == 0 {
+		return fmt.Errorf("the plan %s has no images to mirror", o.FromPlanFile)
+	}
+	return nil
+}
+
 func (o *MirrorImageOptions) Repository(ctx context.Context, context *registryclient.Context, ref imagesource.TypedImageReference, source bool) (distribution.Repository, error) {
 	dir := o.FileDir
 	if len(o.FromFileDir) > 0 && source {
@@ -264,7 +370,22 @@ func (o *MirrorImageOptions) Repository(ctx context.Context, context *registrycl
 	return opts.Repository(ctx, ref)
 }
 
+// ReferrersAPI returns the OCI referrers API of the source repository ref.
+func (o *MirrorImageOptions) ReferrersAPI(ctx context.Context, context *registryclient.Context, ref imagesource.TypedImageReference) (*imagesource.ReferrersAPI, error) {
+	opts := &imagesource.Options{
+		Insecure:        o.SecurityOptions.Insecure,
+		RegistryContext: context,
+	}
+	return opts.ReferrersAPI(ctx, ref)
+}
+
 func (o *MirrorImageOptions) Validate() error {
+	if o.Plan && len(o.FromPlanFile) > 0 {
+		return fmt.Errorf("--plan and --from-plan may not be used together")
+	}
+	if len(o.PlanOutput) > 0 && !o.Plan {
+		return fmt.Errorf("--output may only be used with --plan")
+	}
 	return o.FilterOptions.Validate()
 }
 
@@ -288,12 +409,35 @@ func (o *MirrorImageOptions) Run() error {
 		continuedOnFailure = true
 	}
 
+	if o.fromPlan != nil {
+		if errs := o.fromPlan.verify(p); len(errs) > 0 {
+			for _, err := range errs {
+				fmt.Fprintf(o.ErrOut, "error: %v\n", err)
+			}
+			return fmt.Errorf("the images to mirror do not match the plan %s", o.FromPlanFile)
+		}
+	}
+
 	work := Greedy(p)
 	work.Print(o.ErrOut)
 	fmt.Fprintln(o.ErrOut)
 
 	fmt.Fprintf(o.ErrOut, "info: Planning completed in %s\n", time.Now().Sub(start).Round(10*time.Millisecond))
 
+	if o.Plan {
+		f, err := o.newMirrorPlanFile(p, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := writeMirrorPlanFile(f, o.PlanOutput, o.Out); err != nil {
+			return err
+		}
+		if len(o.PlanOutput) > 0 && o.PlanOutput != "-" {
+			fmt.Fprintf(o.ErrOut, "info: Wrote the plan to %s\n", o.PlanOutput)
+		}
+		return nil
+	}
+
 	if o.DryRun {
 		fmt.Fprintf(o.ErrOut, "info: Dry run complete\n")
 		return nil
@@ -310,7 +454,7 @@ func (o *MirrorImageOptions) Run() error {
 	q := workqueue.New(o.MaxRegistry, stopCh)
 	registryWorkers := make(map[string]workqueue.Interface)
 	for name := range p.RegistryNames() {
-		registryWorkers[name] = workqueue.New(o.ParallelOptions.MaxPerRegistry, stopCh)
+		registryWorkers[name] = o.ParallelOptions.RegistryQueue(name, stopCh)
 	}
 
 	next := time.Now()
@@ -453,7 +597,8 @@ func (o *MirrorImageOptions) plan() (*plan, error) {
 	if err != nil {
 		return nil, err
 	}
-	fromContext := context.Copy()
+	// only the sources are read from the mirrors of --use-registries-conf
+	fromContext := context.Copy().WithAlternateBlobSourceStrategy(context.Alternates)
 	toContext := context.Copy().WithActions("pull", "push")
 	toContexts := make(map[contextKey]*registryclient.Context)
 
@@ -473,11 +618,12 @@ func (o *MirrorImageOptions) plan() (*plan, error) {
 	registryWorkers := make(map[string]workqueue.Interface)
 	for name := range tree {
 		if _, ok := registryWorkers[name.registry]; !ok {
-			registryWorkers[name.registry] = workqueue.New(o.ParallelOptions.MaxPerRegistry, stopCh)
+			registryWorkers[name.registry] = o.ParallelOptions.RegistryQueue(name.registry, stopCh)
 		}
 	}
 
 	plan := newPlan()
+	delta := newDeltaBases()
 
 	for name := range tree {
 		src := tree[name]
@@ -492,6 +638,15 @@ func (o *MirrorImageOptions) plan() (*plan, error) {
 				plan.AddError(retrieverError{src: src.ref, err: fmt.Errorf("unable to access source image %s manifests: %v", src.ref, err)})
 				return
 			}
+			var referrersAPI referrersLister
+			if o.IncludeReferrers && src.ref.Type == imagesource.DestinationRegistry {
+				api, err := o.ReferrersAPI(ctx, fromContext, src.ref)
+				if err != nil {
+					plan.AddError(retrieverError{err: fmt.Errorf("unable to connect to %s: %v", src.ref, err), src: src.ref})
+					return
+				}
+				referrersAPI = api
+			}
 			rq := registryWorkers[name.registry]
 			rq.Batch(func(w workqueue.Work) {
 				// convert source tags to digests
@@ -557,10 +712,31 @@ func (o *MirrorImageOptions) plan() (*plan, error) {
 							location = fmt.Sprintf("manifest %s in manifest list %s", srcDigest, originalSrcDigest)
 						}
 
+						// artifacts are attached to the images through tags, which container engines and archives do not preserve
+						var referrers []referrer
+						if o.IncludeReferrers && (src.ref.Type == imagesource.DestinationRegistry || src.ref.Type == imagesource.DestinationFile) {
+							referrers, err = findReferrers(ctx, srcRepo, manifests, referrersAPI, referrerDigests(srcDigest, srcChildren)...)
+							if err != nil {
+								plan.AddError(retrieverError{src: src.ref, err: fmt.Errorf("unable to find the referrers of %s: %v", src.ref, err)})
+								return
+							}
+						}
+
 						for _, dst := range pushTargets {
+							pinned := src.ref
+							pinned.Ref.ID = originalSrcDigest.String()
+							if len(dst.tags) == 0 {
+								plan.AddMapping(pinned, dst.ref)
+							}
+							for _, tag := range dst.tags {
+								tagged := dst.ref
+								tagged.Ref.Tag = tag
+								plan.AddMapping(pinned, tagged)
+							}
+
 							var toRepo distribution.Repository
 							var err error
-							if o.DryRun {
+							if o.DryRun || o.Plan {
 								toRepo, err = imagesource.NewDryRun(dst.ref)
 							} else {
 								toRepo, err = o.Repository(ctx, toContexts[contextKeyForReference(dst.ref)], dst.ref, false)
@@ -601,36 +777,42 @@ func (o *MirrorImageOptions) plan() (*plan, error) {
 							}
 
 							toBlobs := toRepo.Blobs(ctx)
-
-							if mustCopyLayers {
-								// upload all the blobs
-								srcBlobs := srcRepo.Blobs(ctx)
-
-								addBlobsForManifest := func(srcManifest distribution.Manifest) {
-									switch srcManifest.(type) {
-									case *schema2.DeserializedManifest:
-									case *schema1.SignedManifest:
-									case *ocischema.DeserializedManifest:
-									case *manifestlist.DeserializedManifestList:
-										// we do not need to upload layers in a manifestlist
-										return
-									default:
-										repoPlan.AddError(retrieverError{src: src.ref, dst: dst.ref, err: fmt.Errorf("the manifest type %T is not supported", srcManifest)})
-										return
-									}
-									for _, blob := range srcManifest.References() {
-										if src.ref.EqualRegistry(dst.ref) {
-											registryPlan.AssociateBlob(canonicalFrom.String(), blob)
-										}
-										blobPlan.Copy(blob, srcBlobs, toBlobs)
+							srcBlobs := srcRepo.Blobs(ctx)
+
+							addBlobsForManifest := func(srcManifest distribution.Manifest) {
+								switch srcManifest.(type) {
+								case *schema2.DeserializedManifest:
+								case *schema1.SignedManifest:
+								case *ocischema.DeserializedManifest:
+								case *manifestlist.DeserializedManifestList:
+									// we do not need to upload layers in a manifestlist
+									return
+								default:
+									repoPlan.AddError(retrieverError{src: src.ref, dst: dst.ref, err: fmt.Errorf("the manifest type %T is not supported", srcManifest)})
+									return
+								}
+								for _, blob := range srcManifest.References() {
+									if src.ref.EqualRegistry(dst.ref) {
+										registryPlan.AssociateBlob(canonicalFrom.String(), blob)
 									}
+									blobPlan.Copy(blob, srcBlobs, toBlobs)
 								}
+							}
 
+							if mustCopyLayers {
 								// upload each manifest
 								addBlobsForManifest(srcManifest)
 								for _, childManifest := range srcChildren {
 									addBlobsForManifest(childManifest)
 								}
+								// only copy the layers the images do not share with the existing tags of the destination
+								if o.Delta && !o.Force && dst.ref.Type == imagesource.DestinationRegistry {
+									existing := delta.Blobs(ctx, canonicalTo.String(), toRepo)
+									stats := skipExistingBlobs(blobPlan, existing, append([]distribution.Manifest{srcManifest}, srcChildren...)...)
+									if stats.skippedBlobs > 0 {
+										fmt.Fprintf(o.ErrOut, "info: %d of the %d blobs of %s (%s of %s) already exist in %s and will not be copied\n", stats.skippedBlobs, stats.blobs, location, units.BytesSize(float64(stats.skippedSize)), units.BytesSize(float64(stats.size)), dst.ref)
+									}
+								}
 							}
 
 							prerequisites := make([]godigest.Digest, 0, len(srcChildren))
@@ -647,6 +829,23 @@ func (o *MirrorImageOptions) plan() (*plan, error) {
 							}
 
 							repoPlan.Manifests().Copy(srcDigest, srcManifest, prerequisites, dst.tags, toManifests, toBlobs)
+
+							// the artifacts are small and may have been added since the image was mirrored, so their
+							// blobs are always planned and skipped when they exist
+							for _, r := range referrers {
+								var referrerPrerequisites []godigest.Digest
+								for _, child := range r.children {
+									addBlobsForManifest(child.manifest)
+									referrerPrerequisites = append(referrerPrerequisites, child.digest)
+									repoPlan.Manifests().Copy(child.digest, child.manifest, nil, nil, toManifests, toBlobs)
+								}
+								addBlobsForManifest(r.manifest)
+								var tags []string
+								if len(r.tag) > 0 {
+									tags = []string{r.tag}
+								}
+								repoPlan.Manifests().Copy(r.digest, r.manifest, referrerPrerequisites, tags, toManifests, toBlobs)
+							}
 						}
 					})
 				}
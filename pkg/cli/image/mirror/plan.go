@@ -127,6 +127,8 @@ type plan struct {
 	errs       []error
 	blobs      map[godigest.Digest]distribution.Descriptor
 	manifests  map[godigest.Digest]distribution.Manifest
+	// mappings are the mappings of the sources resolved to digests
+	mappings []Mapping
 
 	work *workPlan
 
@@ -149,6 +151,14 @@ func (p *plan) AddError(errs ...error) {
 	p.errs = append(p.errs, errs...)
 }
 
+// AddMapping records that the image of src is mirrored to dst.
+func (p *plan) AddMapping(src, dst imagesource.TypedImageReference) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.mappings = append(p.mappings, Mapping{Source: src, Destination: dst})
+}
+
 func (p *plan) RegistryPlan(ref imagesource.TypedImageReference) *registryPlan {
 	p.lock.Lock()
 	defer p.lock.Unlock()
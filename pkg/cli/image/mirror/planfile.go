@@ -0,0 +1,244 @@
+package mirror
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	godigest "github.com/opencontainers/go-digest"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+)
+
+const (
+	mirrorPlanKind       = "ImageMirrorPlan"
+	mirrorPlanAPIVersion = "v1"
+)
+
+// mirrorPlanFile is the plan written by --plan and executed by --from-plan. The mappings pin the
+// sources to the digests their tags resolved to, and the options record how manifest lists were
+// filtered, so that executing the plan selects the same images. The repositories list what is
+// copied to each destination, for review and to check that executing the plan copies nothing else.
+type mirrorPlanFile struct {
+	Kind       string `json:"kind"`
+	APIVersion string `json:"apiVersion"`
+	Created    string `json:"created"`
+
+	FilterByOS       string `json:"filterByOS,omitempty"`
+	DefaultOSFilter  bool   `json:"defaultOSFilter,omitempty"`
+	KeepManifestList bool   `json:"keepManifestList,omitempty"`
+	IncludeReferrers bool   `json:"includeReferrers,omitempty"`
+
+	Mappings     []mirrorPlanMapping    `json:"mappings"`
+	Repositories []mirrorPlanRepository `json:"repositories"`
+}
+
+type mirrorPlanMapping struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+}
+
+type mirrorPlanRepository struct {
+	Name      string               `json:"name"`
+	Blobs     []mirrorPlanBlob     `json:"blobs,omitempty"`
+	Manifests []mirrorPlanManifest `json:"manifests,omitempty"`
+}
+
+type mirrorPlanBlob struct {
+	Source    string          `json:"source"`
+	Digest    godigest.Digest `json:"digest"`
+	MediaType string          `json:"mediaType,omitempty"`
+	Size      int64           `json:"size,omitempty"`
+}
+
+type mirrorPlanManifest struct {
+	Digest    godigest.Digest `json:"digest"`
+	MediaType string          `json:"mediaType,omitempty"`
+	Tags      []string        `json:"tags,omitempty"`
+	// RequiredBy is the manifest list or index that references the manifest, which is pushed after it
+	RequiredBy godigest.Digest `json:"requiredBy,omitempty"`
+}
+
+// newMirrorPlanFile returns the plan file of the resolved plan.
+func (o *MirrorImageOptions) newMirrorPlanFile(p *plan, now time.Time) (*mirrorPlanFile, error) {
+	f := &mirrorPlanFile{
+		Kind:             mirrorPlanKind,
+		APIVersion:       mirrorPlanAPIVersion,
+		Created:          now.UTC().Format(time.RFC3339),
+		FilterByOS:       o.FilterOptions.FilterByOS,
+		DefaultOSFilter:  o.FilterOptions.DefaultOSFilter,
+		KeepManifestList: o.KeepManifestList,
+		IncludeReferrers: o.IncludeReferrers,
+		Mappings:         []mirrorPlanMapping{},
+		Repositories:     []mirrorPlanRepository{},
+	}
+	for _, m := range p.mappings {
+		f.Mappings = append(f.Mappings, mirrorPlanMapping{Source: m.Source.String(), Destination: m.Destination.String()})
+	}
+	sort.Slice(f.Mappings, func(i, j int) bool {
+		if f.Mappings[i].Destination == f.Mappings[j].Destination {
+			return f.Mappings[i].Source < f.Mappings[j].Source
+		}
+		return f.Mappings[i].Destination < f.Mappings[j].Destination
+	})
+
+	for _, registryName := range p.RegistryNames().List() {
+		r := p.registries[registryName]
+		for _, repoName := range r.RepositoryNames().List() {
+			repo := r.repositories[repoName]
+			planned := mirrorPlanRepository{Name: planRepositoryName(r, repo)}
+			for _, blob := range repo.blobs {
+				descriptors := p.BlobDescriptors(blob.blobs)
+				sort.Slice(descriptors, func(i, j int) bool { return descriptors[i].Digest < descriptors[j].Digest })
+				for _, desc := range descriptors {
+					planned.Blobs = append(planned.Blobs, mirrorPlanBlob{Source: blob.fromRef.String(), Digest: desc.Digest, MediaType: desc.MediaType, Size: desc.Size})
+				}
+			}
+			if repo.manifests != nil {
+				digests := repo.manifests.digestCopies.Union(repo.manifests.inputDigests())
+				for _, s := range digests.List() {
+					dgst := godigest.Digest(s)
+					manifest, ok := p.GetManifest(dgst)
+					if !ok {
+						return nil, fmt.Errorf("the manifest %s of %s was not loaded", dgst, planned.Name)
+					}
+					mediaType, _, err := manifest.Payload()
+					if err != nil {
+						return nil, err
+					}
+					planned.Manifests = append(planned.Manifests, mirrorPlanManifest{
+						Digest:     dgst,
+						MediaType:  mediaType,
+						Tags:       repo.manifests.digestsToTags[dgst].List(),
+						RequiredBy: repo.manifests.prerequisites[dgst],
+					})
+				}
+			}
+			f.Repositories = append(f.Repositories, planned)
+		}
+	}
+	return f, nil
+}
+
+// writeMirrorPlanFile writes the plan file as JSON to filename, or to out if filename is empty or -.
+func writeMirrorPlanFile(f *mirrorPlanFile, filename string, out io.Writer) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if len(filename) == 0 || filename == "-" {
+		_, err := out.Write(data)
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// readMirrorPlanFile reads the plan file written by --plan.
+func readMirrorPlanFile(filename string, in io.Reader) (*mirrorPlanFile, error) {
+	var data []byte
+	var err error
+	if filename == "-" {
+		data, err = io.ReadAll(in)
+	} else {
+		data, err = os.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	f := &mirrorPlanFile{}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("the plan %s is not valid: %v", filename, err)
+	}
+	if f.Kind != mirrorPlanKind || f.APIVersion != mirrorPlanAPIVersion {
+		return nil, fmt.Errorf("the plan %s is not a %s %s written by --plan", filename, mirrorPlanKind, mirrorPlanAPIVersion)
+	}
+	return f, nil
+}
+
+// mappings returns the mappings of the plan, whose sources are pinned to digests.
+func (f *mirrorPlanFile) mappings() ([]Mapping, error) {
+	var mappings []Mapping
+	for _, m := range f.Mappings {
+		src, err := imagesource.ParseReference(m.Source)
+		if err != nil {
+			return nil, err
+		}
+		if len(src.Ref.ID) == 0 {
+			return nil, fmt.Errorf("the source %s of the plan is not referenced by digest", m.Source)
+		}
+		dst, err := imagesource.ParseDestinationReference(m.Destination)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, Mapping{Source: src, Destination: dst})
+	}
+	return mappings, nil
+}
+
+// verify returns an error for each blob or manifest that the plan p copies and the plan file does
+// not. The blobs and manifests that already exist in the destinations are not copied, so p may copy
+// less than the plan file.
+func (f *mirrorPlanFile) verify(p *plan) []error {
+	blobs := make(map[string]sets.String)
+	manifests := make(map[string]map[godigest.Digest]sets.String)
+	for _, repo := range f.Repositories {
+		blobs[repo.Name] = sets.NewString()
+		for _, blob := range repo.Blobs {
+			blobs[repo.Name].Insert(blob.Digest.String())
+		}
+		manifests[repo.Name] = make(map[godigest.Digest]sets.String)
+		for _, m := range repo.Manifests {
+			manifests[repo.Name][m.Digest] = sets.NewString(m.Tags...)
+		}
+	}
+
+	var errs []error
+	for _, registryName := range p.RegistryNames().List() {
+		r := p.registries[registryName]
+		for _, repoName := range r.RepositoryNames().List() {
+			repo := r.repositories[repoName]
+			name := planRepositoryName(r, repo)
+			planned, ok := manifests[name]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s is not a destination of the plan", name))
+				continue
+			}
+			for _, blob := range repo.blobs {
+				for _, s := range blob.blobs.List() {
+					if !blobs[name].Has(s) {
+						errs = append(errs, fmt.Errorf("the blob %s copied from %s to %s is not part of the plan", s, blob.fromRef, name))
+					}
+				}
+			}
+			if repo.manifests == nil {
+				continue
+			}
+			for _, s := range repo.manifests.digestCopies.List() {
+				if _, ok := planned[godigest.Digest(s)]; !ok {
+					errs = append(errs, fmt.Errorf("the manifest %s pushed to %s is not part of the plan", s, name))
+				}
+			}
+			for _, s := range repo.manifests.inputDigests().List() {
+				dgst := godigest.Digest(s)
+				for _, tag := range repo.manifests.digestsToTags[dgst].List() {
+					if tags, ok := planned[dgst]; !ok || !tags.Has(tag) {
+						errs = append(errs, fmt.Errorf("the manifest %s pushed to %s:%s is not part of the plan", dgst, name, tag))
+					}
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// planRepositoryName identifies a destination repository of the plan.
+func planRepositoryName(r *registryPlan, repo *repositoryPlan) string {
+	return imagesource.TypedImageReference{Type: r.t, Ref: reference.DockerImageReference{Registry: r.name, Name: repo.name}}.String()
+}
@@ -0,0 +1,208 @@
+package mirror
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/distribution/distribution/v3"
+	godigest "github.com/opencontainers/go-digest"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+)
+
+const (
+	planManifestDigest = godigest.Digest("sha256:1000000000000000000000000000000000000000000000000000000000000000")
+	planLayerDigest    = godigest.Digest("sha256:2000000000000000000000000000000000000000000000000000000000000000")
+	planConfigDigest   = godigest.Digest("sha256:3000000000000000000000000000000000000000000000000000000000000000")
+	planOtherDigest    = godigest.Digest("sha256:4000000000000000000000000000000000000000000000000000000000000000")
+)
+
+type payloadManifest struct {
+	distribution.Manifest
+}
+
+func (payloadManifest) Payload() (string, []byte, error) {
+	return ocispecv1.MediaTypeImageManifest, nil, nil
+}
+
+// testMirrorPlan returns the plan of mirroring an image to registry.example.com/app/dst,
+// copying the blobs and the manifests with their tags.
+func testMirrorPlan(t *testing.T, blobs []godigest.Digest, manifests map[godigest.Digest][]string) *plan {
+	src, err := imagesource.ParseReference("quay.io/app/src@" + planManifestDigest.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst, err := imagesource.ParseDestinationReference("registry.example.com/app/dst:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newPlan()
+	p.AddMapping(src, dst)
+	repo := p.RegistryPlan(dst).RepositoryPlan("app/dst")
+	blobPlan := repo.Blobs(src, "manifest "+planManifestDigest.String())
+	for _, blob := range blobs {
+		blobPlan.Copy(distribution.Descriptor{Digest: blob, MediaType: ocispecv1.MediaTypeImageLayerGzip, Size: 10}, nil, nil)
+	}
+	for dgst, tags := range manifests {
+		repo.Manifests().Copy(dgst, payloadManifest{}, nil, tags, nil, nil)
+	}
+	return p
+}
+
+func TestMirrorPlanFileRoundTrip(t *testing.T) {
+	p := testMirrorPlan(t, []godigest.Digest{planLayerDigest, planConfigDigest}, map[godigest.Digest][]string{planManifestDigest: {"latest"}})
+	o := &MirrorImageOptions{KeepManifestList: true}
+	o.FilterOptions.FilterByOS = "linux/amd64"
+	f, err := o.newMirrorPlanFile(p, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []mirrorPlanRepository{{
+		Name: "registry.example.com/app/dst",
+		Blobs: []mirrorPlanBlob{
+			{Source: "quay.io/app/src@" + planManifestDigest.String(), Digest: planLayerDigest, MediaType: ocispecv1.MediaTypeImageLayerGzip, Size: 10},
+			{Source: "quay.io/app/src@" + planManifestDigest.String(), Digest: planConfigDigest, MediaType: ocispecv1.MediaTypeImageLayerGzip, Size: 10},
+		},
+		Manifests: []mirrorPlanManifest{{Digest: planManifestDigest, MediaType: ocispecv1.MediaTypeImageManifest, Tags: []string{"latest"}}},
+	}}
+	if !reflect.DeepEqual(f.Repositories, expected) {
+		t.Errorf("unexpected repositories:\n%#v", f.Repositories)
+	}
+
+	filename := filepath.Join(t.TempDir(), "plan.json")
+	if err := writeMirrorPlanFile(f, filename, nil); err != nil {
+		t.Fatal(err)
+	}
+	read, err := readMirrorPlanFile(filename, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, f) {
+		t.Errorf("expected the plan read back to be\n%#v\ngot\n%#v", f, read)
+	}
+	if read.Created != "2024-01-01T12:00:00Z" || read.FilterByOS != "linux/amd64" || !read.KeepManifestList {
+		t.Errorf("unexpected options of the plan: %#v", read)
+	}
+
+	// the plan is written to and read from the standard streams with -
+	out := &bytes.Buffer{}
+	if err := writeMirrorPlanFile(f, "-", out); err != nil {
+		t.Fatal(err)
+	}
+	read, err = readMirrorPlanFile("-", out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, f) {
+		t.Errorf("expected the plan read from stdin to be\n%#v\ngot\n%#v", f, read)
+	}
+
+	mappings, err := read.mappings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mappings) != 1 || mappings[0].Source.Ref.ID != planManifestDigest.String() || mappings[0].Destination.String() != "registry.example.com/app/dst:latest" {
+		t.Errorf("unexpected mappings: %#v", mappings)
+	}
+	if errs := read.verify(p); len(errs) > 0 {
+		t.Errorf("expected the plan to match, got %v", errs)
+	}
+
+	read.Kind = "List"
+	if err := writeMirrorPlanFile(read, filename, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readMirrorPlanFile(filename, nil); err == nil || !strings.Contains(err.Error(), "is not a ImageMirrorPlan v1 written by --plan") {
+		t.Errorf("expected a plan of another kind to be rejected, got %v", err)
+	}
+}
+
+func TestMirrorPlanFileMappingsByDigest(t *testing.T) {
+	f := &mirrorPlanFile{Mappings: []mirrorPlanMapping{{Source: "quay.io/app/src:latest", Destination: "registry.example.com/app/dst:latest"}}}
+	if _, err := f.mappings(); err == nil || !strings.Contains(err.Error(), "is not referenced by digest") {
+		t.Errorf("expected a source by tag to be rejected, got %v", err)
+	}
+}
+
+func TestMirrorPlanFileVerify(t *testing.T) {
+	blobs := []godigest.Digest{planLayerDigest, planConfigDigest}
+	manifests := map[godigest.Digest][]string{planManifestDigest: {"latest"}}
+	f, err := (&MirrorImageOptions{}).newMirrorPlanFile(testMirrorPlan(t, blobs, manifests), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		blobs          []godigest.Digest
+		manifests      map[godigest.Digest][]string
+		expectedErrors []string
+	}{
+		{
+			name:      "same plan",
+			blobs:     blobs,
+			manifests: manifests,
+		},
+		{
+			name:      "blobs already in the destination",
+			blobs:     []godigest.Digest{planLayerDigest},
+			manifests: manifests,
+		},
+		{
+			name:           "extra blob",
+			blobs:          []godigest.Digest{planLayerDigest, planConfigDigest, planOtherDigest},
+			manifests:      manifests,
+			expectedErrors: []string{"the blob " + planOtherDigest.String() + " copied from quay.io/app/src@" + planManifestDigest.String() + " to registry.example.com/app/dst is not part of the plan"},
+		},
+		{
+			name:           "changed blob",
+			blobs:          []godigest.Digest{planLayerDigest, planOtherDigest},
+			manifests:      manifests,
+			expectedErrors: []string{"the blob " + planOtherDigest.String() + " copied from"},
+		},
+		{
+			name:           "extra manifest",
+			blobs:          blobs,
+			manifests:      map[godigest.Digest][]string{planManifestDigest: {"latest"}, planOtherDigest: nil},
+			expectedErrors: []string{"the manifest " + planOtherDigest.String() + " pushed to registry.example.com/app/dst is not part of the plan"},
+		},
+		{
+			name:           "changed manifest",
+			blobs:          blobs,
+			manifests:      map[godigest.Digest][]string{planOtherDigest: {"latest"}},
+			expectedErrors: []string{"the manifest " + planOtherDigest.String() + " pushed to registry.example.com/app/dst:latest is not part of the plan"},
+		},
+		{
+			name:           "extra tag",
+			blobs:          blobs,
+			manifests:      map[godigest.Digest][]string{planManifestDigest: {"latest", "stable"}},
+			expectedErrors: []string{"the manifest " + planManifestDigest.String() + " pushed to registry.example.com/app/dst:stable is not part of the plan"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errs := f.verify(testMirrorPlan(t, test.blobs, test.manifests))
+			if len(errs) != len(test.expectedErrors) {
+				t.Fatalf("expected %d errors, got %v", len(test.expectedErrors), errs)
+			}
+			for i, expected := range test.expectedErrors {
+				if !strings.Contains(errs[i].Error(), expected) {
+					t.Errorf("expected error %q, got %v", expected, errs[i])
+				}
+			}
+		})
+	}
+
+	// a destination the plan does not copy to is rejected
+	other := testMirrorPlan(t, blobs, manifests)
+	other.registries["registry.example.com"].RepositoryPlan("app/other").Manifests().Copy(planManifestDigest, payloadManifest{}, nil, []string{"latest"}, nil, nil)
+	errs := f.verify(other)
+	if len(errs) != 1 || errs[0].Error() != "registry.example.com/app/other is not a destination of the plan" {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
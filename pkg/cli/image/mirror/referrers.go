@@ -0,0 +1,142 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/manifestlist"
+	godigest "github.com/opencontainers/go-digest"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/image/registryclient"
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	imagemanifest "github.com/openshift/oc/pkg/cli/image/manifest"
+)
+
+// referrerTagSuffixes are appended to the referrers tag of an image by cosign to attach
+// signatures, attestations and software bills of materials to it.
+var referrerTagSuffixes = []string{".sig", ".att", ".sbom"}
+
+// referrersLister lists the manifests whose subject is an image through the OCI referrers API.
+type referrersLister interface {
+	Referrers(ctx context.Context, dgst godigest.Digest) ([]ocispecv1.Descriptor, error)
+}
+
+// referrer is an artifact attached to an image, through a tag derived from its digest or, if
+// tag is empty, through its subject as listed by the referrers API.
+type referrer struct {
+	tag      string
+	digest   godigest.Digest
+	manifest distribution.Manifest
+	// children are the manifests of an index, such as the referrers index of the image
+	children []referrerChild
+}
+
+type referrerChild struct {
+	digest   godigest.Digest
+	manifest distribution.Manifest
+}
+
+// referrerTags returns the tags the artifacts attached to the image with digest dgst are
+// found under: the index the OCI referrers tag schema maintains for registries without the
+// referrers API, sha256-<hex>, unless tagSchema is false, and the tags cosign pushes to,
+// such as sha256-<hex>.sig.
+func referrerTags(dgst godigest.Digest, tagSchema bool) []string {
+	tag := strings.Replace(dgst.String(), ":", "-", 1)
+	var tags []string
+	if tagSchema {
+		tags = append(tags, tag)
+	}
+	for _, suffix := range referrerTagSuffixes {
+		tags = append(tags, tag+suffix)
+	}
+	return tags
+}
+
+// findReferrers loads the artifacts of repo attached to the images with the provided digests.
+// The referrers API of the registry is queried first, if set, and the index of the referrers
+// tag schema is only looked up once the registry reports it does not support the API. Tags
+// that do not exist are ignored.
+func findReferrers(ctx context.Context, repo distribution.Repository, manifests distribution.ManifestService, api referrersLister, digests ...godigest.Digest) ([]referrer, error) {
+	var referrers []referrer
+	for _, dgst := range digests {
+		tagSchema := true
+		if api != nil {
+			descriptors, err := api.Referrers(ctx, dgst)
+			switch {
+			case err == nil:
+				tagSchema = false
+				for _, desc := range descriptors {
+					r, err := loadReferrer(ctx, manifests, "", desc.Digest)
+					if err != nil {
+						return nil, err
+					}
+					referrers = append(referrers, r)
+				}
+			case errors.Is(err, imagesource.ErrReferrersAPIUnsupported):
+				klog.V(4).Infof("The registry of %s does not support the referrers API, looking up the referrers tags", repo.Named())
+				api = nil
+			default:
+				return nil, fmt.Errorf("unable to list the referrers of %s: %v", dgst, err)
+			}
+		}
+		for _, tag := range referrerTags(dgst, tagSchema) {
+			desc, err := repo.Tags(ctx).Get(ctx, tag)
+			if err != nil {
+				if isReferrerNotFound(err) {
+					continue
+				}
+				return nil, fmt.Errorf("unable to look up the referrers tag %s: %v", tag, err)
+			}
+			r, err := loadReferrer(ctx, manifests, tag, desc.Digest)
+			if err != nil {
+				return nil, err
+			}
+			referrers = append(referrers, r)
+		}
+	}
+	return referrers, nil
+}
+
+// loadReferrer loads the manifest of a referrer and, if it is an index, of its manifests.
+func loadReferrer(ctx context.Context, manifests distribution.ManifestService, tag string, dgst godigest.Digest) (referrer, error) {
+	location := fmt.Sprintf("referrer %s", dgst)
+	if len(tag) > 0 {
+		location = fmt.Sprintf("referrers tag %s", tag)
+	}
+	srcManifest, err := manifests.Get(ctx, dgst, imagemanifest.PreferManifestList)
+	if err != nil {
+		return referrer{}, fmt.Errorf("unable to retrieve the manifest of the %s: %v", location, err)
+	}
+	r := referrer{tag: tag, digest: dgst, manifest: srcManifest}
+	if list, ok := srcManifest.(*manifestlist.DeserializedManifestList); ok {
+		for _, child := range list.Manifests {
+			childManifest, err := manifests.Get(ctx, child.Digest, imagemanifest.PreferManifestList)
+			if err != nil {
+				return referrer{}, fmt.Errorf("unable to retrieve manifest %s of the %s: %v", child.Digest, location, err)
+			}
+			r.children = append(r.children, referrerChild{digest: child.Digest, manifest: childManifest})
+		}
+	}
+	return r, nil
+}
+
+// referrerDigests returns the digest of the image and of the images of its manifest list,
+// which artifacts may be attached to.
+func referrerDigests(srcDigest godigest.Digest, srcChildren []distribution.Manifest) []godigest.Digest {
+	digests := []godigest.Digest{srcDigest}
+	for _, child := range srcChildren {
+		if dgst, err := registryclient.ContentDigestForManifest(child, srcDigest.Algorithm()); err == nil {
+			digests = append(digests, dgst)
+		}
+	}
+	return digests
+}
+
+func isReferrerNotFound(err error) bool {
+	return errors.As(err, &distribution.ErrTagUnknown{}) || errors.Is(err, distribution.ErrBlobUnknown) || imagemanifest.IsImageNotFound(err)
+}
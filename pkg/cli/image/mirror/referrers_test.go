@@ -0,0 +1,144 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/reference"
+	godigest "github.com/opencontainers/go-digest"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+)
+
+type fakeManifest struct {
+	distribution.Manifest
+}
+
+type fakeRepository struct {
+	distribution.Repository
+	tags map[string]godigest.Digest
+	// lookups are the tags looked up
+	lookups []string
+}
+
+func (r *fakeRepository) Named() reference.Named {
+	named, _ := reference.WithName("registry.example.com/app")
+	return named
+}
+
+func (r *fakeRepository) Tags(ctx context.Context) distribution.TagService {
+	return fakeTags{repo: r}
+}
+
+type fakeTags struct {
+	distribution.TagService
+	repo *fakeRepository
+}
+
+func (t fakeTags) Get(ctx context.Context, tag string) (distribution.Descriptor, error) {
+	t.repo.lookups = append(t.repo.lookups, tag)
+	dgst, ok := t.repo.tags[tag]
+	if !ok {
+		return distribution.Descriptor{}, distribution.ErrTagUnknown{Tag: tag}
+	}
+	return distribution.Descriptor{Digest: dgst}, nil
+}
+
+type fakeManifests struct {
+	distribution.ManifestService
+}
+
+func (fakeManifests) Get(ctx context.Context, dgst godigest.Digest, options ...distribution.ManifestServiceOption) (distribution.Manifest, error) {
+	return fakeManifest{}, nil
+}
+
+type fakeReferrersAPI struct {
+	referrers map[godigest.Digest][]ocispecv1.Descriptor
+	err       error
+	calls     int
+}
+
+func (a *fakeReferrersAPI) Referrers(ctx context.Context, dgst godigest.Digest) ([]ocispecv1.Descriptor, error) {
+	a.calls++
+	return a.referrers[dgst], a.err
+}
+
+func TestFindReferrers(t *testing.T) {
+	image, child := godigest.FromString("image"), godigest.FromString("child")
+	imageTag := strings.Replace(image.String(), ":", "-", 1)
+	childTag := strings.Replace(child.String(), ":", "-", 1)
+	signature, index, sbom := godigest.FromString("signature"), godigest.FromString("index"), godigest.FromString("sbom")
+	tags := map[string]godigest.Digest{imageTag: index, imageTag + ".sig": signature}
+	allTags := []string{imageTag, imageTag + ".sig", imageTag + ".att", imageTag + ".sbom", childTag, childTag + ".sig", childTag + ".att", childTag + ".sbom"}
+	cosignTags := []string{imageTag + ".sig", imageTag + ".att", imageTag + ".sbom", childTag + ".sig", childTag + ".att", childTag + ".sbom"}
+
+	tests := []struct {
+		name            string
+		api             *fakeReferrersAPI
+		expected        []string
+		expectedLookups []string
+		expectedCalls   int
+		expectedError   string
+	}{
+		{
+			name:            "tag schema",
+			expected:        []string{imageTag + "@" + index.String(), imageTag + ".sig@" + signature.String()},
+			expectedLookups: allTags,
+		},
+		{
+			name:            "referrers API",
+			api:             &fakeReferrersAPI{referrers: map[godigest.Digest][]ocispecv1.Descriptor{image: {{Digest: sbom}}}},
+			expected:        []string{"@" + sbom.String(), imageTag + ".sig@" + signature.String()},
+			expectedLookups: cosignTags,
+			expectedCalls:   2,
+		},
+		{
+			name:            "referrers API not supported",
+			api:             &fakeReferrersAPI{err: imagesource.ErrReferrersAPIUnsupported},
+			expected:        []string{imageTag + "@" + index.String(), imageTag + ".sig@" + signature.String()},
+			expectedLookups: allTags,
+			expectedCalls:   1,
+		},
+		{
+			name:          "referrers API error",
+			api:           &fakeReferrersAPI{err: errors.New("unauthorized")},
+			expectedCalls: 1,
+			expectedError: "unable to list the referrers of " + image.String() + ": unauthorized",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo := &fakeRepository{tags: tags}
+			var api referrersLister
+			if test.api != nil {
+				api = test.api
+			}
+			referrers, err := findReferrers(context.Background(), repo, fakeManifests{}, api, image, child)
+			if len(test.expectedError) > 0 {
+				if err == nil || err.Error() != test.expectedError {
+					t.Fatalf("expected error %q, got %v", test.expectedError, err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			var found []string
+			for _, r := range referrers {
+				found = append(found, r.tag+"@"+r.digest.String())
+			}
+			if !reflect.DeepEqual(found, test.expected) {
+				t.Errorf("expected referrers %v, got %v", test.expected, found)
+			}
+			if !reflect.DeepEqual(repo.lookups, test.expectedLookups) {
+				t.Errorf("expected the tags %v to be looked up, got %v", test.expectedLookups, repo.lookups)
+			}
+			if test.api != nil && test.api.calls != test.expectedCalls {
+				t.Errorf("expected %d calls to the referrers API, got %d", test.expectedCalls, test.api.calls)
+			}
+		})
+	}
+}
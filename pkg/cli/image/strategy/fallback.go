@@ -0,0 +1,77 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/library-go/pkg/image/registryclient"
+)
+
+type onErrorFallbackStrategy struct {
+	lock sync.Mutex
+
+	alternates   map[reference.DockerImageReference][]reference.DockerImageReference
+	strategies   []registryclient.AlternateBlobSourceStrategy
+	repositories []reference.DockerImageReference
+}
+
+var _ registryclient.AlternateBlobSourceStrategy = &onErrorFallbackStrategy{}
+
+// NewOnErrorFallbackStrategy returns an alternate strategy which, only after getting an error
+// from the original requested, falls back to the alternate sources of each strategy in order
+// and then to the repositories given, which may serve the content of any repository. The
+// original is tried again first unless a strategy returns alternates without it.
+func NewOnErrorFallbackStrategy(repositories []reference.DockerImageReference, strategies ...registryclient.AlternateBlobSourceStrategy) registryclient.AlternateBlobSourceStrategy {
+	return &onErrorFallbackStrategy{
+		alternates:   make(map[reference.DockerImageReference][]reference.DockerImageReference),
+		strategies:   strategies,
+		repositories: repositories,
+	}
+}
+
+func (s *onErrorFallbackStrategy) FirstRequest(ctx context.Context, locator reference.DockerImageReference) (alternateRepositories []reference.DockerImageReference, err error) {
+	return nil, nil
+}
+
+func (s *onErrorFallbackStrategy) OnFailure(ctx context.Context, locator reference.DockerImageReference) (alternateRepositories []reference.DockerImageReference, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if alternates, ok := s.alternates[locator]; ok {
+		return alternates, nil
+	}
+
+	var sources []reference.DockerImageReference
+	for _, strategy := range s.strategies {
+		alternates, err := strategy.OnFailure(ctx, locator)
+		if err != nil {
+			klog.V(2).Infof("Skipping alternative sources for image %s: %v", locator.String(), err)
+			continue
+		}
+		sources = append(sources, alternates...)
+	}
+	if len(sources) == 0 {
+		sources = append(sources, locator.AsRepository().AsV2())
+	}
+	for _, repository := range s.repositories {
+		sources = append(sources, repository.AsRepository().AsV2())
+	}
+
+	alternates := make([]reference.DockerImageReference, 0, len(sources))
+	unique := make(map[reference.DockerImageReference]bool)
+	for _, source := range sources {
+		if !unique[source] {
+			unique[source] = true
+			alternates = append(alternates, source)
+		}
+	}
+	if len(alternates) == 1 && alternates[0] == locator.AsRepository().AsV2() {
+		return nil, fmt.Errorf("no alternative image references found for image: %s", locator.String())
+	}
+	klog.V(2).Infof("Found sources: %v for image: %v", alternates, locator)
+	s.alternates[locator] = alternates
+	return s.alternates[locator], nil
+}
@@ -0,0 +1,101 @@
+package strategy
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apicfgv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/library-go/pkg/image/registryclient"
+)
+
+func TestOnErrorFallbackStrategy(t *testing.T) {
+	idms := NewIDMSListOnErrorStrategy("the cluster", []apicfgv1.ImageDigestMirrorSet{
+		{
+			Spec: apicfgv1.ImageDigestMirrorSetSpec{
+				ImageDigestMirrors: []apicfgv1.ImageDigestMirrors{
+					{
+						Source:  "quay.io/openshift-release-dev/ocp-release",
+						Mirrors: []apicfgv1.ImageMirror{"mirror.example.com/ocp/release"},
+					},
+					{
+						Source:             "quay.io/blocked/release",
+						Mirrors:            []apicfgv1.ImageMirror{"mirror.example.com/blocked/release"},
+						MirrorSourcePolicy: apicfgv1.NeverContactSource,
+					},
+				},
+			},
+		},
+	})
+	alternateFrom := []reference.DockerImageReference{
+		{Registry: "backup.example.com", Namespace: "ocp", Name: "release", Tag: "4.16"},
+		{Registry: "mirror.example.com", Namespace: "ocp", Name: "release"},
+	}
+
+	tests := []struct {
+		name         string
+		strategies   []registryclient.AlternateBlobSourceStrategy
+		repositories []reference.DockerImageReference
+		image        string
+		expected     []string
+		expectedErr  bool
+	}{
+		{
+			name:         "mirrors then alternate repositories",
+			strategies:   []registryclient.AlternateBlobSourceStrategy{idms},
+			repositories: alternateFrom,
+			image:        "quay.io/openshift-release-dev/ocp-release@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			expected:     []string{"quay.io/openshift-release-dev/ocp-release", "mirror.example.com/ocp/release", "backup.example.com/ocp/release"},
+		},
+		{
+			name:         "source never contacted",
+			strategies:   []registryclient.AlternateBlobSourceStrategy{idms},
+			repositories: alternateFrom,
+			image:        "quay.io/blocked/release@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			expected:     []string{"mirror.example.com/blocked/release", "backup.example.com/ocp/release", "mirror.example.com/ocp/release"},
+		},
+		{
+			name:         "no mirrors for the image",
+			strategies:   []registryclient.AlternateBlobSourceStrategy{idms},
+			repositories: alternateFrom,
+			image:        "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			expected:     []string{"quay.io/openshift-release-dev/ocp-v4.0-art-dev", "backup.example.com/ocp/release", "mirror.example.com/ocp/release"},
+		},
+		{
+			name:        "no alternates",
+			strategies:  []registryclient.AlternateBlobSourceStrategy{idms},
+			image:       "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			expectedErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			locator, err := reference.Parse(test.image)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := NewOnErrorFallbackStrategy(test.repositories, test.strategies...)
+			if first, err := s.FirstRequest(context.Background(), locator); first != nil || err != nil {
+				t.Fatalf("unexpected first request alternates %v: %v", first, err)
+			}
+			alternates, err := s.OnFailure(context.Background(), locator)
+			if test.expectedErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", alternates)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, alternate := range alternates {
+				got = append(got, alternate.Exact())
+			}
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
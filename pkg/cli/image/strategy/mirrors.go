@@ -0,0 +1,52 @@
+package strategy
+
+import (
+	"github.com/openshift/library-go/pkg/image/reference"
+)
+
+// Mirrors returns the mirrors configured for the repository of imageRef, in order of preference
+// and without the repository itself.
+type Mirrors func(imageRef reference.DockerImageReference) ([]reference.DockerImageReference, error)
+
+// ICSPMirrors reads the ImageContentSourcePolicy objects of file once, and returns the mirrors
+// they configure for the repository of an image.
+func ICSPMirrors(file string) (Mirrors, error) {
+	icspList, err := readICSPsFromFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return func(imageRef reference.DockerImageReference) ([]reference.DockerImageReference, error) {
+		sources, err := alternativeImageSourcesICSP(imageRef, icspList, true)
+		if err != nil {
+			return nil, err
+		}
+		return withoutSource(imageRef, sources), nil
+	}, nil
+}
+
+// IDMSMirrors reads the ImageDigestMirrorSet objects of file once, and returns the mirrors they
+// configure for the repository of an image.
+func IDMSMirrors(file string) (Mirrors, error) {
+	idmsList, err := readIDMSsFromFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return func(imageRef reference.DockerImageReference) ([]reference.DockerImageReference, error) {
+		sources, err := alternativeImageSourcesIDMS(imageRef, idmsList, true)
+		if err != nil {
+			return nil, err
+		}
+		return withoutSource(imageRef, sources), nil
+	}, nil
+}
+
+func withoutSource(imageRef reference.DockerImageReference, sources []reference.DockerImageReference) []reference.DockerImageReference {
+	source := imageRef.AsRepository().AsV2()
+	mirrors := make([]reference.DockerImageReference, 0, len(sources))
+	for _, s := range sources {
+		if s != source {
+			mirrors = append(mirrors, s)
+		}
+	}
+	return mirrors
+}
@@ -0,0 +1,59 @@
+package strategy
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+)
+
+func TestIDMSMirrors(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "idms.yaml")
+	if err := os.WriteFile(file, []byte(`apiVersion: config.openshift.io/v1
+kind: ImageDigestMirrorSet
+metadata:
+  name: release
+spec:
+  imageDigestMirrors:
+  - source: quay.io/openshift-release-dev/ocp-release
+    mirrors:
+    - mirror.example.com/ocp/release
+    - backup.example.com/ocp/release
+  - source: quay.io/openshift-release-dev
+    mirrors:
+    - mirror.example.com/ocp
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mirrorsFor, err := IDMSMirrors(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the file is read once
+	if err := os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	for image, expected := range map[string][]string{
+		"quay.io/openshift-release-dev/ocp-release@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef":      {"mirror.example.com/ocp/release", "backup.example.com/ocp/release", "mirror.example.com/ocp/ocp-release"},
+		"quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef": {"mirror.example.com/ocp/ocp-v4.0-art-dev"},
+		"registry.example.com/other/image:latest": {},
+	} {
+		ref, err := reference.Parse(image)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mirrors, err := mirrorsFor(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual := []string{}
+		for _, mirror := range mirrors {
+			actual = append(actual, mirror.Exact())
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%s: expected mirrors %v, got %v", image, expected, actual)
+		}
+	}
+}
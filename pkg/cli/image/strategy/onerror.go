@@ -42,6 +42,18 @@ func NewICSPOnErrorStrategy(file string) registryclient.AlternateBlobSourceStrat
 	}
 }
 
+// NewICSPListOnErrorStrategy returns an ICSP alternate strategy like NewICSPOnErrorStrategy
+// for the ImageContentSourcePolicy objects given, described by source, instead of a file.
+func NewICSPListOnErrorStrategy(source string, icspList []operatorv1alpha1.ImageContentSourcePolicy) registryclient.AlternateBlobSourceStrategy {
+	return &onErrorICSPStrategy{
+		icspFile:   source,
+		alternates: make(map[reference.DockerImageReference][]reference.DockerImageReference),
+		readICSPsFromFileFunc: func(string) ([]operatorv1alpha1.ImageContentSourcePolicy, error) {
+			return icspList, nil
+		},
+	}
+}
+
 func (s *onErrorICSPStrategy) FirstRequest(ctx context.Context, locator reference.DockerImageReference) (alternateRepositories []reference.DockerImageReference, err error) {
 	return nil, nil
 }
@@ -70,7 +82,7 @@ func (s *onErrorICSPStrategy) resolve(ctx context.Context, imageRef reference.Do
 	if len(s.icspFile) == 0 {
 		return nil, fmt.Errorf("no ImageContentSourceFile specified")
 	}
-	klog.V(5).Infof("Reading ICSP from file %s", s.icspFile)
+	klog.V(5).Infof("Reading ICSP from %s", s.icspFile)
 	icspList, err := s.readICSPsFromFileFunc(s.icspFile)
 	if err != nil {
 		return nil, err
@@ -179,6 +191,18 @@ func NewIDMSOnErrorStrategy(file string) registryclient.AlternateBlobSourceStrat
 	}
 }
 
+// NewIDMSListOnErrorStrategy returns an IDMS alternate strategy like NewIDMSOnErrorStrategy
+// for the ImageDigestMirrorSet objects given, described by source, instead of a file.
+func NewIDMSListOnErrorStrategy(source string, idmsList []apicfgv1.ImageDigestMirrorSet) registryclient.AlternateBlobSourceStrategy {
+	return &onErrorIDMSStrategy{
+		idmsFile:   source,
+		alternates: make(map[reference.DockerImageReference][]reference.DockerImageReference),
+		readIDMSsFromFileFunc: func(string) ([]apicfgv1.ImageDigestMirrorSet, error) {
+			return idmsList, nil
+		},
+	}
+}
+
 func (s *onErrorIDMSStrategy) FirstRequest(ctx context.Context, locator reference.DockerImageReference) (alternateRepositories []reference.DockerImageReference, err error) {
 	return nil, nil
 }
@@ -207,7 +231,7 @@ func (s *onErrorIDMSStrategy) resolve(ctx context.Context, imageRef reference.Do
 	if len(s.idmsFile) == 0 {
 		return nil, fmt.Errorf("no ImageDigestMirrorSet specified")
 	}
-	klog.V(5).Infof("Reading IDMS from file %s", s.idmsFile)
+	klog.V(5).Infof("Reading IDMS from %s", s.idmsFile)
 	idmsList, err := s.readIDMSsFromFileFunc(s.idmsFile)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,267 @@
+package workqueue
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// fastLatency is the latency below which a response is always considered fast.
+	fastLatency = 100 * time.Millisecond
+	// defaultRetryAfter is how long the workers back off when a registry throttles the
+	// requests without a Retry-After header.
+	defaultRetryAfter = time.Second
+	// maxRetryAfter bounds the back off requested by a registry.
+	maxRetryAfter = 5 * time.Minute
+)
+
+// Limiter adapts the number of concurrent work units sent to a registry to how it responds.
+// The concurrency grows by one unit per round of fast responses up to max, is halved when
+// the registry throttles the requests with 429 or 503, and no new work starts until the
+// Retry-After of the registry has passed.
+type Limiter struct {
+	lock        sync.Mutex
+	cond        *sync.Cond
+	limit       float64
+	max         int
+	active      int
+	minLatency  time.Duration
+	pausedUntil time.Time
+	now         func() time.Time
+}
+
+// NewLimiter returns a limiter starting at initial concurrent work units, which may grow
+// up to max.
+func NewLimiter(initial, max int) *Limiter {
+	if initial <= 0 {
+		initial = 1
+	}
+	if max < initial {
+		max = initial
+	}
+	l := &Limiter{limit: float64(initial), max: max, now: time.Now}
+	l.cond = sync.NewCond(&l.lock)
+	return l
+}
+
+// Limit returns the current number of concurrent work units.
+func (l *Limiter) Limit() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return int(l.limit)
+}
+
+// Observe reports a successful response which took latency to arrive. Responses that are
+// fast compared to the fastest seen so far ramp up the concurrency.
+func (l *Limiter) Observe(latency time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.minLatency == 0 || latency < l.minLatency {
+		l.minLatency = latency
+	}
+	if latency > fastLatency && latency > 2*l.minLatency {
+		return
+	}
+	if limit := l.limit + 1/l.limit; int(limit) <= l.max {
+		if int(limit) > int(l.limit) {
+			klog.V(4).Infof("Increasing the concurrency to %d", int(limit))
+		}
+		l.limit = limit
+	}
+	l.cond.Broadcast()
+}
+
+// Throttle reports that the registry asked to slow down for retryAfter. The concurrency is
+// halved and the work units waiting for a slot do not start before retryAfter has passed.
+func (l *Limiter) Throttle(retryAfter time.Duration) {
+	if retryAfter <= 0 {
+		retryAfter = defaultRetryAfter
+	}
+	if retryAfter > maxRetryAfter {
+		retryAfter = maxRetryAfter
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if limit := l.limit / 2; limit >= 1 {
+		l.limit = limit
+	} else {
+		l.limit = 1
+	}
+	if until := l.now().Add(retryAfter); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+		time.AfterFunc(retryAfter, l.broadcast)
+	}
+	klog.V(2).Infof("Registry throttled the requests, backing off for %s with a concurrency of %d", retryAfter, int(l.limit))
+}
+
+// Pause returns how long the requests must wait before being sent to the registry.
+func (l *Limiter) Pause() time.Duration {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.pausedUntil.Sub(l.now())
+}
+
+// acquire waits for a slot to run a work unit, and returns false if stopCh is closed first.
+func (l *Limiter) acquire(stopCh <-chan struct{}) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for l.active >= int(l.limit) || l.now().Before(l.pausedUntil) {
+		select {
+		case <-stopCh:
+			return false
+		default:
+		}
+		l.cond.Wait()
+	}
+	l.active++
+	return true
+}
+
+// broadcast wakes up the work units waiting for a slot, holding the lock so that the
+// wake up cannot happen between their check of the conditions and their wait.
+func (l *Limiter) broadcast() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.cond.Broadcast()
+}
+
+func (l *Limiter) release() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.active--
+	l.cond.Signal()
+}
+
+// NewAdaptive returns a work queue running as many work units concurrently as the limiter
+// allows.
+func NewAdaptive(limiter *Limiter, stopCh <-chan struct{}) Interface {
+	q := &workQueue{
+		ch: make(chan workUnit, 100),
+		wg: &sync.WaitGroup{},
+	}
+	go func() {
+		<-stopCh
+		limiter.broadcast()
+	}()
+	go q.runAdaptive(limiter, stopCh)
+	return q
+}
+
+func (q *workQueue) runAdaptive(limiter *Limiter, stopCh <-chan struct{}) {
+	for i := 0; i < limiter.max; i++ {
+		go func(i int) {
+			defer klog.V(4).Infof("worker %d stopping", i)
+			for {
+				select {
+				case work, ok := <-q.ch:
+					if !ok {
+						return
+					}
+					if !limiter.acquire(stopCh) {
+						return
+					}
+					work.fn()
+					limiter.release()
+					work.wg.Done()
+				case <-stopCh:
+					return
+				}
+			}
+		}(i)
+	}
+	<-stopCh
+	klog.V(4).Infof("work queue exiting")
+}
+
+// Limiters holds the limiters of the registries, which the responses of the registries
+// sent through RoundTripper report to.
+type Limiters struct {
+	lock     sync.Mutex
+	initial  int
+	max      int
+	limiters map[string]*Limiter
+}
+
+// NewLimiters returns the limiters of the registries, each starting at initial concurrent
+// work units and growing up to max.
+func NewLimiters(initial, max int) *Limiters {
+	return &Limiters{initial: initial, max: max, limiters: make(map[string]*Limiter)}
+}
+
+// For returns the limiter of the registry host.
+func (l *Limiters) For(host string) *Limiter {
+	if host == "docker.io" {
+		host = "registry-1.docker.io"
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	limiter, ok := l.limiters[host]
+	if !ok {
+		limiter = NewLimiter(l.initial, l.max)
+		l.limiters[host] = limiter
+	}
+	return limiter
+}
+
+// RoundTripper returns a transport reporting the responses of the registries to their
+// limiter, and delaying the requests to a registry which asked to back off.
+func (l *Limiters) RoundTripper(rt http.RoundTripper) http.RoundTripper {
+	if l == nil {
+		return rt
+	}
+	return &limitingRoundTripper{limiters: l, delegate: rt}
+}
+
+type limitingRoundTripper struct {
+	limiters *Limiters
+	delegate http.RoundTripper
+}
+
+func (rt *limitingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// report the redirects of a registry to its storage to the registry
+	origin := req
+	for origin.Response != nil && origin.Response.Request != nil {
+		origin = origin.Response.Request
+	}
+	limiter := rt.limiters.For(origin.URL.Host)
+	if d := limiter.Pause(); d > 0 {
+		t := time.NewTimer(d)
+		select {
+		case <-t.C:
+		case <-req.Context().Done():
+			t.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+
+	start := limiter.now()
+	resp, err := rt.delegate.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusServiceUnavailable:
+		limiter.Throttle(retryAfter(resp.Header.Get("Retry-After"), limiter.now()))
+	case resp.StatusCode < http.StatusInternalServerError:
+		limiter.Observe(limiter.now().Sub(start))
+	}
+	return resp, nil
+}
+
+// retryAfter parses the Retry-After header, which is either a number of seconds or a date.
+func retryAfter(value string, now time.Time) time.Duration {
+	if len(value) == 0 {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,235 @@
+package workqueue
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// fakeNow makes the limiter read its time from now, which the tests advance holding the lock
+// of the limiter.
+func fakeNow(l *Limiter, now time.Time) func(time.Duration) {
+	l.now = func() time.Time { return now }
+	return func(d time.Duration) {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		now = now.Add(d)
+	}
+}
+
+func TestLimiterObserve(t *testing.T) {
+	tests := []struct {
+		name      string
+		initial   int
+		max       int
+		latencies []time.Duration
+		expected  int
+	}{
+		{
+			name:      "ramps up one unit per round of fast responses",
+			initial:   1,
+			max:       10,
+			latencies: []time.Duration{10 * time.Millisecond, 10 * time.Millisecond, 10 * time.Millisecond},
+			// 1 -> 2 -> 2.5 -> 2.9
+			expected: 2,
+		},
+		{
+			name:    "stops at max",
+			initial: 1,
+			max:     2,
+			latencies: []time.Duration{
+				10 * time.Millisecond, 10 * time.Millisecond, 10 * time.Millisecond, 10 * time.Millisecond, 10 * time.Millisecond,
+			},
+			expected: 2,
+		},
+		{
+			name:      "slow responses do not ramp up",
+			initial:   1,
+			max:       10,
+			latencies: []time.Duration{150 * time.Millisecond, 400 * time.Millisecond, 500 * time.Millisecond},
+			// only the first response, the fastest seen, counts
+			expected: 2,
+		},
+		{
+			name:      "responses below the fast latency always ramp up",
+			initial:   1,
+			max:       10,
+			latencies: []time.Duration{time.Millisecond, 90 * time.Millisecond},
+			expected:  2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := NewLimiter(test.initial, test.max)
+			for _, latency := range test.latencies {
+				l.Observe(latency)
+			}
+			if limit := l.Limit(); limit != test.expected {
+				t.Errorf("expected a limit of %d, got %d", test.expected, limit)
+			}
+		})
+	}
+
+	l := NewLimiter(1, 10)
+	for i := 0; i < 20; i++ {
+		l.Observe(10 * time.Millisecond)
+	}
+	if limit := l.Limit(); limit != 6 {
+		t.Errorf("expected the limit to grow to 6 after 20 fast responses, got %d", limit)
+	}
+}
+
+func TestLimiterThrottle(t *testing.T) {
+	tests := []struct {
+		name          string
+		initial       int
+		retryAfter    time.Duration
+		expectedLimit int
+		expectedPause time.Duration
+	}{
+		{
+			name:          "halves the limit",
+			initial:       8,
+			retryAfter:    2 * time.Second,
+			expectedLimit: 4,
+			expectedPause: 2 * time.Second,
+		},
+		{
+			name:          "keeps at least one unit",
+			initial:       1,
+			retryAfter:    2 * time.Second,
+			expectedLimit: 1,
+			expectedPause: 2 * time.Second,
+		},
+		{
+			name:          "defaults the retry after",
+			initial:       4,
+			expectedLimit: 2,
+			expectedPause: defaultRetryAfter,
+		},
+		{
+			name:          "bounds the retry after",
+			initial:       4,
+			retryAfter:    time.Hour,
+			expectedLimit: 2,
+			expectedPause: maxRetryAfter,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := NewLimiter(test.initial, test.initial)
+			fakeNow(l, time.Now())
+			l.Throttle(test.retryAfter)
+			if limit := l.Limit(); limit != test.expectedLimit {
+				t.Errorf("expected a limit of %d, got %d", test.expectedLimit, limit)
+			}
+			if pause := l.Pause(); pause != test.expectedPause {
+				t.Errorf("expected a pause of %s, got %s", test.expectedPause, pause)
+			}
+		})
+	}
+
+	// a shorter retry after does not shorten the pause
+	l := NewLimiter(4, 4)
+	advance := fakeNow(l, time.Now())
+	l.Throttle(10 * time.Second)
+	l.Throttle(time.Second)
+	if pause := l.Pause(); pause != 10*time.Second {
+		t.Errorf("expected a pause of 10s, got %s", pause)
+	}
+	advance(10 * time.Second)
+	if pause := l.Pause(); pause > 0 {
+		t.Errorf("expected the pause to be over, got %s", pause)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{value: "", expected: 0},
+		{value: "0", expected: 0},
+		{value: "30", expected: 30 * time.Second},
+		{value: now.Add(90 * time.Second).Format(http.TimeFormat), expected: 90 * time.Second},
+		{value: now.Add(-time.Minute).Format(http.TimeFormat), expected: -time.Minute},
+		{value: "Mon Jan  1 12:02:00 2024", expected: 2 * time.Minute},
+		{value: "soon", expected: 0},
+		{value: "1.5", expected: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			if d := retryAfter(test.value, now); d != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, d)
+			}
+		})
+	}
+}
+
+// acquired calls acquire in the background and returns the result.
+func acquired(l *Limiter, stopCh <-chan struct{}) <-chan bool {
+	ch := make(chan bool, 1)
+	go func() { ch <- l.acquire(stopCh) }()
+	return ch
+}
+
+func expectBlocked(t *testing.T, ch <-chan bool) {
+	t.Helper()
+	select {
+	case ok := <-ch:
+		t.Fatalf("expected acquire to block, returned %t", ok)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func expectAcquired(t *testing.T, ch <-chan bool, expected bool) {
+	t.Helper()
+	select {
+	case ok := <-ch:
+		if ok != expected {
+			t.Fatalf("expected acquire to return %t, got %t", expected, ok)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("acquire did not return")
+	}
+}
+
+func TestLimiterAcquire(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	l := NewLimiter(2, 2)
+
+	expectAcquired(t, acquired(l, stopCh), true)
+	expectAcquired(t, acquired(l, stopCh), true)
+	// all the slots are taken until one is released
+	ch := acquired(l, stopCh)
+	expectBlocked(t, ch)
+	l.release()
+	expectAcquired(t, ch, true)
+
+	// no work starts while the registry asked to back off, even with a free slot
+	advance := fakeNow(l, time.Now())
+	l.release()
+	l.lock.Lock()
+	l.pausedUntil = l.now().Add(time.Minute)
+	l.lock.Unlock()
+	ch = acquired(l, stopCh)
+	expectBlocked(t, ch)
+	advance(time.Minute)
+	l.broadcast()
+	expectAcquired(t, ch, true)
+}
+
+func TestLimiterAcquireStopped(t *testing.T) {
+	l := NewLimiter(1, 1)
+	stopCh := make(chan struct{})
+	expectAcquired(t, acquired(l, stopCh), true)
+
+	ch := acquired(l, stopCh)
+	expectBlocked(t, ch)
+	// stopping the queue wakes up the blocked work units
+	NewAdaptive(l, stopCh)
+	close(stopCh)
+	expectAcquired(t, ch, false)
+}
@@ -0,0 +1,185 @@
+package importimage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+// importMapping is an entry of the --filename mapping file. Unset fields
+// default to the values of the corresponding flags.
+type importMapping struct {
+	// From is the image, or with All the repository, to import. It may be
+	// empty to refresh a tag that already has a source.
+	From string `json:"from,omitempty"`
+	// To is the IMAGESTREAM[:TAG] to import into.
+	To              string `json:"to"`
+	All             bool   `json:"all,omitempty"`
+	Scheduled       *bool  `json:"scheduled,omitempty"`
+	Insecure        *bool  `json:"insecure,omitempty"`
+	ReferencePolicy string `json:"referencePolicy,omitempty"`
+	ImportMode      string `json:"importMode,omitempty"`
+}
+
+// readMappings reads the list of mappings from the file, or from standard input
+// if the file is "-".
+func readMappings(filename string, in io.Reader) ([]importMapping, error) {
+	var data []byte
+	var err error
+	if filename == "-" {
+		data, err = io.ReadAll(in)
+	} else {
+		data, err = os.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var mappings []importMapping
+	if err := yaml.UnmarshalStrict(data, &mappings); err != nil {
+		return nil, fmt.Errorf("unable to read the mappings of %s: %v", filename, err)
+	}
+	if len(mappings) == 0 {
+		return nil, fmt.Errorf("%s does not contain any mappings", filename)
+	}
+	return mappings, nil
+}
+
+// forMapping returns the options importing a single entry of the mapping file.
+func (o *ImportImageOptions) forMapping(m importMapping) (*ImportImageOptions, error) {
+	if len(m.To) == 0 {
+		return nil, fmt.Errorf("the image stream to import %q into must be set with 'to'", m.From)
+	}
+	entry := *o
+	entry.Filename = ""
+	entry.mappings = nil
+	entry.From = m.From
+	entry.Target = m.To
+	entry.All = m.All
+	if m.Scheduled != nil {
+		entry.Scheduled = *m.Scheduled
+	}
+	if m.Insecure != nil {
+		entry.Insecure = *m.Insecure
+		entry.InsecureFlagProvided = true
+	}
+	if len(m.ReferencePolicy) > 0 {
+		entry.ReferencePolicy = m.ReferencePolicy
+	}
+	if len(m.ImportMode) > 0 {
+		entry.ImportMode = m.ImportMode
+	}
+	if err := entry.parseImageReference(); err != nil {
+		return nil, fmt.Errorf("%s: %v", m.To, err)
+	}
+	if err := entry.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", m.To, err)
+	}
+	return &entry, nil
+}
+
+// mappingResult is a line of the summary of the imports.
+type mappingResult struct {
+	target, from, status, message string
+}
+
+// RunMappings imports each entry of the mapping file in turn and prints a
+// summary, continuing past the entries that fail.
+func (o *ImportImageOptions) RunMappings() error {
+	var results []mappingResult
+	failed := 0
+	for _, m := range o.mappings {
+		result := mappingResult{target: m.To, from: m.From, status: "imported"}
+		if err := o.importMapping(m, &result); err != nil {
+			result.status, result.message = "failed", err.Error()
+		}
+		if result.status != "imported" {
+			failed++
+		}
+		if o.DryRun {
+			result.status += " (dry run)"
+		}
+		results = append(results, result)
+	}
+
+	w := tabwriter.NewWriter(o.Out, 0, 4, 3, ' ', 0)
+	fmt.Fprintln(w, "IMAGESTREAM\tFROM\tSTATUS\tMESSAGE")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.target, r.from, r.status, r.message)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "\n%d of %d imports succeeded\n", len(results)-failed, len(results))
+	if failed > 0 {
+		return fmt.Errorf("%d of %d imports failed", failed, len(results))
+	}
+	return nil
+}
+
+func (o *ImportImageOptions) importMapping(m importMapping, result *mappingResult) error {
+	entry, err := o.forMapping(m)
+	if err != nil {
+		return err
+	}
+	result.target = entry.Name + ":" + entry.Tag
+	if entry.All {
+		result.target = entry.Name
+	}
+	_, isi, err := entry.createImageImport()
+	if err != nil {
+		return err
+	}
+	if len(result.from) == 0 {
+		// the source of an existing tag or repository
+		if isi.Spec.Repository != nil {
+			result.from = isi.Spec.Repository.From.Name
+		} else if len(isi.Spec.Images) > 0 {
+			result.from = isi.Spec.Images[0].From.Name
+		}
+	}
+	imported, err := o.imageClient.ImageStreamImports(isi.Namespace).Create(context.TODO(), isi, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	if wasError(imported) {
+		result.status = "failed"
+		result.message = importMessages(imported)
+		return nil
+	}
+	if repo := imported.Status.Repository; repo != nil {
+		result.message = fmt.Sprintf("%d tags", len(repo.Images))
+		if len(repo.AdditionalTags) > 0 {
+			result.message += fmt.Sprintf(", %d additional tags not imported", len(repo.AdditionalTags))
+		}
+	}
+	return nil
+}
+
+// importMessages returns the messages of the images that failed to import.
+func importMessages(isi *imagev1.ImageStreamImport) string {
+	var messages []string
+	if repo := isi.Status.Repository; repo != nil {
+		if repo.Status.Status == metav1.StatusFailure {
+			messages = append(messages, repo.Status.Message)
+		}
+		for _, image := range repo.Images {
+			if image.Status.Status == metav1.StatusFailure {
+				messages = append(messages, fmt.Sprintf("%s: %s", image.Tag, image.Status.Message))
+			}
+		}
+	}
+	for _, image := range isi.Status.Images {
+		if image.Status.Status == metav1.StatusFailure {
+			messages = append(messages, image.Status.Message)
+		}
+	}
+	return strings.Join(messages, "; ")
+}
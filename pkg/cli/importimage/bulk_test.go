@@ -0,0 +1,130 @@
+package importimage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	imagefake "github.com/openshift/client-go/image/clientset/versioned/fake"
+	"github.com/openshift/oc/pkg/cli/tag"
+)
+
+func TestReadMappings(t *testing.T) {
+	in := strings.NewReader(`
+- from: registry.io/repo/image:1.0
+  to: mystream:1.0
+  scheduled: true
+- from: registry.io/repo/other
+  to: other
+  all: true
+  referencePolicy: local
+`)
+	mappings, err := readMappings("-", in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mappings) != 2 {
+		t.Fatalf("expected 2 mappings, got %d", len(mappings))
+	}
+	if m := mappings[0]; m.To != "mystream:1.0" || m.Scheduled == nil || !*m.Scheduled || m.Insecure != nil {
+		t.Errorf("unexpected mapping %#v", m)
+	}
+
+	if _, err := readMappings("-", strings.NewReader("- from: a\n  tag: b\n")); err == nil {
+		t.Errorf("expected an error for an unknown field")
+	}
+	if _, err := readMappings("-", strings.NewReader("[]")); err == nil {
+		t.Errorf("expected an error for an empty file")
+	}
+}
+
+func TestForMapping(t *testing.T) {
+	o := NewImportImageOptions(genericiooptions.NewTestIOStreamsDiscard())
+	o.ReferencePolicy = ""
+	o.Filename = "mapping.yaml"
+	scheduled := true
+
+	entry, err := o.forMapping(importMapping{From: "registry.io/repo/image", To: "other", All: true, Scheduled: &scheduled, ReferencePolicy: "local"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Name != "other" || len(entry.Tag) > 0 || !entry.Scheduled || entry.InsecureFlagProvided || entry.ReferencePolicy != tag.LocalReferencePolicy || len(entry.Filename) > 0 {
+		t.Errorf("unexpected options %#v", entry)
+	}
+	entry, err = o.forMapping(importMapping{From: "registry.io/repo/image:1.0", To: "mystream"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Name != "mystream" || entry.Tag != "latest" || entry.Scheduled || entry.ReferencePolicy != tag.SourceReferencePolicy {
+		t.Errorf("unexpected options %#v", entry)
+	}
+	if o.Scheduled || len(o.ReferencePolicy) > 0 {
+		t.Errorf("the mapping must not change the options of the command")
+	}
+
+	if _, err := o.forMapping(importMapping{From: "registry.io/repo/image:1.0"}); err == nil {
+		t.Errorf("expected an error without 'to'")
+	}
+	if _, err := o.forMapping(importMapping{From: "registry.io/repo/image", To: "mystream:1.0", All: true}); err == nil {
+		t.Errorf("expected an error for a tag with all")
+	}
+	if _, err := o.forMapping(importMapping{From: "registry.io/repo/image", To: "mystream", ImportMode: "Unknown"}); err == nil {
+		t.Errorf("expected an error for an invalid import mode")
+	}
+}
+
+func TestRunMappings(t *testing.T) {
+	fake := imagefake.NewSimpleClientset()
+	out := &bytes.Buffer{}
+	o := NewImportImageOptions(genericiooptions.IOStreams{Out: out, ErrOut: out})
+	o.Namespace = "ci"
+	o.imageClient = fake.ImageV1()
+	o.isClient = fake.ImageV1().ImageStreams("ci")
+	o.Filename = "mapping.yaml"
+	o.mappings = []importMapping{
+		{From: "registry.io/repo/image:1.0", To: "mystream:1.0"},
+		{From: "registry.io/repo/other:2.0", To: "other:2.0"},
+	}
+
+	if err := o.RunMappings(); err == nil || err.Error() != "2 of 2 imports failed" {
+		t.Errorf("expected the imports to fail without --confirm, got %v", err)
+	}
+	if !strings.Contains(out.String(), "pass --confirm to create and import") {
+		t.Errorf("expected the reason of the failures in the summary:\n%s", out.String())
+	}
+
+	out.Reset()
+	o.Confirm = true
+	if err := o.RunMappings(); err != nil {
+		t.Fatalf("%v\n%s", err, out.String())
+	}
+	want := "IMAGESTREAM    FROM                         STATUS     MESSAGE\n" +
+		"mystream:1.0   registry.io/repo/image:1.0   imported   \n" +
+		"other:2.0      registry.io/repo/other:2.0   imported   \n" +
+		"\n2 of 2 imports succeeded\n"
+	if out.String() != want {
+		t.Errorf("unexpected summary:\n%s\nwant:\n%s", out.String(), want)
+	}
+	var imports int
+	for _, action := range fake.Actions() {
+		if action.GetVerb() == "create" && action.GetResource().Resource == "imagestreamimports" {
+			imports++
+		}
+	}
+	if imports != 2 {
+		t.Errorf("expected 2 imports, got %d", imports)
+	}
+}
+
+func TestImportMessages(t *testing.T) {
+	isi := &imagev1.ImageStreamImport{}
+	isi.Status.Images = []imagev1.ImageImportStatus{{}, {}}
+	isi.Status.Images[1].Status.Status = "Failure"
+	isi.Status.Images[1].Status.Message = "manifest unknown"
+	if got := importMessages(isi); got != "manifest unknown" {
+		t.Errorf("importMessages() = %q", got)
+	}
+}
@@ -38,7 +38,14 @@ var (
 		image contents.
 
 		If you want to change the image stream tag or provide more advanced options,
-		see the 'tag' command.`)
+		see the 'tag' command.
+
+		To import many tags or repositories at once, for instance when migrating to a
+		new registry, pass a YAML list of mappings with --filename. Each mapping sets
+		the image stream tag to import into with 'to' and the image to import with
+		'from', and may set 'all', 'scheduled', 'insecure', 'referencePolicy' and
+		'importMode', which otherwise default to the values of the flags. Every mapping
+		is imported even if some fail, and a summary of the imports is printed.`)
 
 	importImageExample = templates.Examples(`
 		# Import tag latest into a new image stream
@@ -61,6 +68,11 @@ var (
 
 		# Import all tags into a new image stream using a custom timeout
 		oc --request-timeout=5m import-image mystream --from=registry.io/repo/image --all --confirm
+
+		# Import the tags listed in a mapping file, e.g. containing:
+		#   - {from: registry.io/repo/image:1.0, to: mystream:1.0, scheduled: true}
+		#   - {from: registry.io/repo/other, to: other, all: true, referencePolicy: local}
+		oc import-image -f mapping.yaml --confirm
 	`)
 )
 
@@ -81,12 +93,16 @@ type ImportImageOptions struct {
 
 	DryRun bool
 
+	// Filename is a file of mappings to import, see importMapping.
+	Filename string
+
 	// internal values
 	Namespace       string
 	Name            string
 	Tag             string
 	Target          string
 	ReferencePolicy string
+	mappings        []importMapping
 
 	// helpers
 	imageClient imagev1client.ImageV1Interface
@@ -111,7 +127,7 @@ func NewCmdImportImage(f kcmdutil.Factory, streams genericiooptions.IOStreams) *
 	validArgs := []string{"imagestream"}
 
 	cmd := &cobra.Command{
-		Use:               "import-image IMAGESTREAM[:TAG]",
+		Use:               "import-image IMAGESTREAM[:TAG] | -f FILENAME",
 		Short:             "Import images from a container image registry",
 		Long:              importImageLong,
 		Example:           importImageExample,
@@ -119,6 +135,10 @@ func NewCmdImportImage(f kcmdutil.Factory, streams genericiooptions.IOStreams) *
 		Run: func(cmd *cobra.Command, args []string) {
 			kcmdutil.CheckErr(o.Complete(f, cmd, args))
 			kcmdutil.CheckErr(o.Validate())
+			if len(o.Filename) > 0 {
+				kcmdutil.CheckErr(o.RunMappings())
+				return
+			}
 			kcmdutil.CheckErr(o.Run())
 		},
 	}
@@ -133,6 +153,7 @@ func NewCmdImportImage(f kcmdutil.Factory, streams genericiooptions.IOStreams) *
 	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun, "Fetch information about images without creating or updating an image stream.")
 	cmd.Flags().BoolVar(&o.Scheduled, "scheduled", o.Scheduled, "Set each imported container image to be periodically imported from a remote repository. Defaults to false.")
 	cmd.Flags().BoolVar(&o.Insecure, "insecure", o.Insecure, "If true, allow importing from registries that have invalid HTTPS certificates or are hosted via HTTP. This flag will take precedence over the insecure annotation.")
+	cmd.Flags().StringVarP(&o.Filename, "filename", "f", o.Filename, "A YAML file of image stream tags to import and the images to import them from, or - to read it from standard input.")
 
 	return cmd
 }
@@ -140,6 +161,9 @@ func NewCmdImportImage(f kcmdutil.Factory, streams genericiooptions.IOStreams) *
 // Complete turns a partially defined ImportImageOptions into a solvent structure
 // which can be validated and used for aa import.
 func (o *ImportImageOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(o.Filename) > 0 && len(args) > 0 {
+		return fmt.Errorf("an image stream cannot be specified with --filename")
+	}
 	if len(args) > 0 {
 		o.Target = args[0]
 	}
@@ -174,6 +198,10 @@ func (o *ImportImageOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, ar
 		return o.PrintFlags.ToPrinter()
 	}
 
+	if len(o.Filename) > 0 {
+		o.mappings, err = readMappings(o.Filename, o.In)
+		return err
+	}
 	return o.parseImageReference()
 }
 
@@ -200,6 +228,17 @@ func (o *ImportImageOptions) parseImageReference() error {
 // Validate ensures that a ImportImageOptions is valid and can be used to execute
 // an import.
 func (o *ImportImageOptions) Validate() error {
+	if len(o.Filename) > 0 {
+		if o.PrintFlags.OutputFormat != nil && len(*o.PrintFlags.OutputFormat) > 0 {
+			return fmt.Errorf("--output cannot be used with --filename")
+		}
+		for _, m := range o.mappings {
+			if _, err := o.forMapping(m); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 	if len(o.Target) == 0 {
 		return fmt.Errorf("you must specify the name of an image stream")
 	}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/openshift/oc/pkg/cli/config/adminkubeconfig"
 	"github.com/openshift/oc/pkg/cli/config/kubeletbootstrapkubeconfig"
+	"github.com/openshift/oc/pkg/cli/config/profiles"
 	"github.com/openshift/oc/pkg/cli/config/refreshcabundle"
 	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
 )
@@ -23,6 +24,7 @@ func NewCmdConfig(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra
 	configCommand.AddCommand(refreshcabundle.NewCmdConfigRefreshCABundle(f, pathOptions, streams))
 	configCommand.AddCommand(adminkubeconfig.NewCmdNewAdminKubeconfigOptions(f, streams))
 	configCommand.AddCommand(kubeletbootstrapkubeconfig.NewCmdNewKubeletBootstrapKubeconfig(f, streams))
+	configCommand.AddCommand(profiles.NewCmdProfiles(streams))
 
 	return cmdutil.ReplaceCommandName("kubectl", "oc", templates.Normalize(configCommand))
 }
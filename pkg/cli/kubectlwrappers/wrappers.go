@@ -2,11 +2,16 @@ package kubectlwrappers
 
 import (
 	"bufio"
+	"context"
+	"os"
+	"os/signal"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/kubectl/pkg/cmd/annotate"
 	"k8s.io/kubectl/pkg/cmd/apiresources"
 	"k8s.io/kubectl/pkg/cmd/apply"
@@ -40,7 +45,9 @@ import (
 	"k8s.io/kubectl/pkg/util/templates"
 
 	"github.com/openshift/oc/pkg/cli/create"
+	"github.com/openshift/oc/pkg/helpers/asciicast"
 	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
+	ocportforward "github.com/openshift/oc/pkg/helpers/portforward"
 )
 
 func adjustCmdExamples(cmd *cobra.Command, name string) {
@@ -120,14 +127,111 @@ func NewCmdCompletion(streams genericiooptions.IOStreams) *cobra.Command {
 	return cmd
 }
 
-// NewCmdExec is a wrapper for the Kubernetes cli exec command
+// NewCmdExec is a wrapper for the Kubernetes cli exec command, which can record the session
 func NewCmdExec(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
-	return cmdutil.ReplaceCommandName("kubectl", "oc", templates.Normalize(exec.NewCmdExec(f, streams)))
+	cmd := exec.NewCmdExec(f, streams)
+	cmd.Example += "\n\n" + templates.Examples(`
+		# Open a shell session in pod mypod and record it for 'asciinema play'
+		kubectl exec -it mypod --record=session.cast -- /bin/sh`)
+	record := asciicast.NewOptions()
+	record.AddFlags(cmd.Flags())
+	// the options of the exec command are not accessible, so they are read from its flags
+	// to execute the command with a recording executor
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		o := &exec.ExecOptions{
+			StreamOptions: exec.StreamOptions{
+				ContainerName: kcmdutil.GetFlagString(cmd, "container"),
+				Stdin:         kcmdutil.GetFlagBool(cmd, "stdin"),
+				TTY:           kcmdutil.GetFlagBool(cmd, "tty"),
+				Quiet:         kcmdutil.GetFlagBool(cmd, "quiet"),
+				IOStreams:     streams,
+			},
+			FilenameOptions: resource.FilenameOptions{Filenames: kcmdutil.GetFlagStringSlice(cmd, "filename")},
+			Executor:        &exec.DefaultRemoteExecutor{},
+		}
+		kcmdutil.CheckErr(o.Complete(f, cmd, args, cmd.ArgsLenAtDash()))
+		kcmdutil.CheckErr(o.Validate())
+		closeRecording, err := record.Wrap(o, "oc exec "+o.ResourceName)
+		kcmdutil.CheckErr(err)
+		defer closeRecording()
+		kcmdutil.CheckErr(o.Run())
+	}
+	return cmdutil.ReplaceCommandName("kubectl", "oc", templates.Normalize(cmd))
 }
 
 // NewCmdPortForward is a wrapper for the Kubernetes cli port-forward command
 func NewCmdPortForward(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
-	return cmdutil.ReplaceCommandName("kubectl", "oc", templates.Normalize(portforward.NewCmdPortForward(f, streams)))
+	cmd := portforward.NewCmdPortForward(f, streams)
+	cmd.Long += "\n\n" + templates.LongDesc(`
+		With --retry, the forwarding is re-established when the pod goes away, on a new pod
+		selected by the resource and on the same local ports. With --all-pods, the ports are
+		forwarded to every running pod of a service, deployment or other resource with a
+		selector, each on local ports allocated automatically. Use --output=json to print the
+		local ports of every pod on a line in JSON once they are listening.`)
+	cmd.Example += "\n\n" + templates.Examples(`
+		# Listen on port 8443 locally, forwarding to the service's port "https" in a pod, and follow the service to a new pod when the pod goes away
+		kubectl port-forward --retry service/myservice 8443:https
+
+		# Forward port 5000 of every pod of the deployment to local ports printed in JSON
+		kubectl port-forward --all-pods --output=json deployment/mydeployment 5000`)
+	var retry, allPods bool
+	var output string
+	cmd.Flags().BoolVar(&retry, "retry", retry, "If true, re-establish the forwarding on a new pod selected by the resource when the pod goes away.")
+	cmd.Flags().BoolVar(&allPods, "all-pods", allPods, "If true, forward the ports to every running pod selected by the resource, each on local ports allocated automatically.")
+	cmd.Flags().StringVarP(&output, "output", "o", output, "Output format. Only 'json' is supported, printing the local ports of every pod once they are listening.")
+	upstreamRun := cmd.Run
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		if !retry && !allPods && len(output) == 0 {
+			upstreamRun(cmd, args)
+			return
+		}
+		kcmdutil.CheckErr(validatePortForward(cmd, args, allPods, output))
+		o := portforward.NewDefaultPortForwardOptions(streams)
+		o.Address = kcmdutil.GetFlagStringSlice(cmd, "address")
+		kcmdutil.CheckErr(o.Complete(f, cmd, args))
+		kcmdutil.CheckErr(o.Validate())
+		timeout, err := kcmdutil.GetPodRunningTimeoutFlag(cmd)
+		kcmdutil.CheckErr(err)
+
+		forwarder := &ocportforward.Forwarder{
+			Namespace:     o.Namespace,
+			Addresses:     o.Address,
+			Ports:         o.Ports,
+			SelectPods:    ocportforward.PodsForResource(f, o.Namespace, args[0], allPods, timeout),
+			AllPods:       allPods,
+			Retry:         retry,
+			RetryInterval: 5 * time.Second,
+			JSON:          output == "json",
+			Config:        o.Config,
+			RESTClient:    o.RESTClient,
+			PodClient:     o.PodClient,
+			IOStreams:     streams,
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		go func() {
+			// a second interrupt exits right away
+			<-ctx.Done()
+			stop()
+		}()
+		kcmdutil.CheckErr(forwarder.Run(ctx))
+	}
+	return cmdutil.ReplaceCommandName("kubectl", "oc", templates.Normalize(cmd))
+}
+
+func validatePortForward(cmd *cobra.Command, args []string, allPods bool, output string) error {
+	if len(output) > 0 && output != "json" {
+		return kcmdutil.UsageErrorf(cmd, "--output must be 'json', got %q", output)
+	}
+	if !allPods {
+		return nil
+	}
+	for _, port := range args[min(1, len(args)):] {
+		if local, _, ok := strings.Cut(port, ":"); ok && len(local) > 0 {
+			return kcmdutil.UsageErrorf(cmd, "the local ports are allocated with --all-pods, specify %q as :REMOTE_PORT", port)
+		}
+	}
+	return nil
 }
 
 // NewCmdDescribe is a wrapper for the Kubernetes cli describe command
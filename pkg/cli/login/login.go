@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/openshift/library-go/pkg/oauth/tokenrequest"
 	"github.com/openshift/oc/pkg/helpers/flagtypes"
+	"github.com/openshift/oc/pkg/helpers/keyring"
+	"github.com/openshift/oc/pkg/helpers/profiles"
 	kapierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/printers"
@@ -49,6 +52,18 @@ var (
 
 		# Log in to the external OIDC issuer through Auth Code + PKCE by starting a local server listening on port 8080
 		oc login localhost:8443 --exec-plugin=oc-oidc --client-id=client-id --extra-scopes=email,profile --callback-port=8080
+
+		# Log in to the external OIDC issuer through Auth Code + PKCE without an exec plugin, storing the tokens in the kubeconfig
+		oc login localhost:8443 --exec-free --issuer-url=https://issuer.example.com --client-id=client-id
+
+		# Log in to the external OIDC issuer with a code entered on another device, such as from a host without a browser
+		oc login localhost:8443 --exec-free --device-code --issuer-url=https://issuer.example.com --client-id=client-id
+
+		# Log in to the given server and keep the token in the keychain of the operating system instead of the kubeconfig
+		oc login localhost:8443 --web --token-store=keyring
+
+		# Log in with the server, user, namespace, certificate authority and proxy of a profile from 'oc config profiles'
+		oc login --profile=prod
 	`)
 )
 
@@ -98,6 +113,10 @@ func NewCmdLogin(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.
 	cmds.Flags().StringSliceVar(&o.OIDCExtraScopes, "extra-scopes", o.OIDCExtraScopes, "Experimental: Extra scopes for external OIDC issuer. Optional.")
 	cmds.Flags().StringVar(&o.OIDCIssuerURL, "issuer-url", o.OIDCIssuerURL, "Experimental: Issuer url for external issuer. Required.")
 	cmds.Flags().StringVar(&o.OIDCCAFile, "oidc-certificate-authority", o.OIDCCAFile, "Experimental: The path to a certificate authority bundle to use when communicating with external OIDC issuer.")
+	cmds.Flags().BoolVar(&o.OIDCExecFree, "exec-free", o.OIDCExecFree, "Experimental: Log in to the external OIDC issuer without an exec plugin. The ID and refresh tokens are stored in the kubeconfig and the ID token is refreshed when it expires.")
+	cmds.Flags().BoolVar(&o.OIDCDeviceCode, "device-code", o.OIDCDeviceCode, "Experimental: Log in to the external OIDC issuer by entering a code in a browser on any device instead of through a local callback server. Requires --exec-free.")
+	cmds.Flags().StringVar(&o.ProfileName, "profile", o.ProfileName, "Log in with the server, username, namespace, certificate authority and proxy of the profile, see 'oc config profiles'. The flags passed on the command line take precedence.")
+	cmds.Flags().StringVar(&o.TokenStore, "token-store", o.TokenStore, "Where to store the token: "+strings.Join(keyring.TokenStores, ", ")+". With 'keyring', the token is kept in the keychain of the operating system and the kubeconfig calls 'oc get-token' to retrieve it.")
 	return cmds
 }
 
@@ -119,6 +138,20 @@ func (o *LoginOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []s
 	}
 	o.RequestTimeout = timeout
 
+	var profile *profiles.Profile
+	if len(o.ProfileName) > 0 {
+		p, err := profiles.Load(profiles.DefaultPath())
+		if err != nil {
+			return err
+		}
+		if profile, err = p.Get(o.ProfileName); err != nil {
+			return err
+		}
+		if err := profile.Validate(); err != nil {
+			return err
+		}
+	}
+
 	parsedDefaultClusterURL, err := url.Parse(defaultClusterURL)
 	if err != nil {
 		return err
@@ -137,6 +170,12 @@ func (o *LoginOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []s
 		}
 		o.Server = addr.String()
 
+	} else if profile != nil {
+		if err := addr.Set(profile.Server); err != nil {
+			return err
+		}
+		o.Server = addr.String()
+
 	} else if len(o.Server) == 0 {
 		if defaultContext, defaultContextExists := o.StartingKubeConfig.Contexts[o.StartingKubeConfig.CurrentContext]; defaultContextExists {
 			if cluster, exists := o.StartingKubeConfig.Clusters[defaultContext.Cluster]; exists {
@@ -154,6 +193,10 @@ func (o *LoginOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []s
 
 	o.DefaultNamespace, _, _ = f.ToRawKubeConfigLoader().Namespace()
 
+	if profile != nil {
+		o.applyProfile(cmd, profile)
+	}
+
 	o.PathOptions = kclientcmd.NewDefaultPathOptions()
 	// we need to set explicit path if one was specified, since NewDefaultPathOptions doesn't do it for us
 	o.PathOptions.LoadingRules.ExplicitPath = kcmdutil.GetFlagString(cmd, kclientcmd.RecommendedConfigPathFlag)
@@ -161,6 +204,22 @@ func (o *LoginOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []s
 	return nil
 }
 
+// applyProfile sets the options that are not set on the command line to the settings of the
+// profile.
+func (o *LoginOptions) applyProfile(cmd *cobra.Command, profile *profiles.Profile) {
+	if len(o.Username) == 0 && len(o.Token) == 0 {
+		o.Username = profile.Username
+	}
+	if len(o.CAFile) == 0 && !cmd.Flags().Changed("insecure-skip-tls-verify") {
+		o.CAFile = profile.CertificateAuthority
+		o.InsecureTLS = profile.InsecureSkipTLSVerify
+	}
+	if len(profile.Namespace) > 0 && !cmd.Flags().Changed("namespace") {
+		o.DefaultNamespace = profile.Namespace
+	}
+	o.ProxyURL = profile.ProxyURL
+}
+
 func (o LoginOptions) Validate(cmd *cobra.Command, serverFlag string, args []string) error {
 	if len(args) > 1 {
 		return errors.New("Only the server URL may be specified as an argument")
@@ -192,18 +251,38 @@ func (o LoginOptions) Validate(cmd *cobra.Command, serverFlag string, args []str
 
 	oidcOptionsSet := o.OIDCClientID != "" || o.OIDCClientSecret != "" || len(o.OIDCExtraScopes) > 0 || o.OIDCIssuerURL != ""
 
-	if o.OIDCExecPluginType == "" && oidcOptionsSet {
-		return errors.New("please specify --exec-plugin type. Currently only oc-oidc is supported")
+	if o.OIDCExecPluginType == "" && !o.OIDCExecFree && oidcOptionsSet {
+		return errors.New("please specify --exec-plugin type or --exec-free. Currently only oc-oidc is supported")
+	}
+
+	if o.OIDCExecPluginType != "" && o.OIDCExecFree {
+		return errors.New("--exec-plugin and --exec-free are mutually exclusive")
 	}
 
 	if o.OIDCExecPluginType != "" && (o.WebLogin || o.Username != "" || o.Password != "" || o.Token != "") {
 		return errors.New("--exec-plugin cannot be used along with --web, --username, --password or --token")
 	}
 
+	if o.OIDCExecFree && (o.WebLogin || o.Username != "" || o.Password != "" || o.Token != "") {
+		return errors.New("--exec-free cannot be used along with --web, --username, --password or --token")
+	}
+
 	if o.OIDCExecPluginType == string(OCOIDC) && (o.OIDCIssuerURL == "" || o.OIDCClientID == "") {
 		return fmt.Errorf("--issuer-url and --client-id are required fields for oc-oidc type")
 	}
 
+	if o.OIDCExecFree && (o.OIDCIssuerURL == "" || o.OIDCClientID == "") {
+		return fmt.Errorf("--issuer-url and --client-id are required fields for --exec-free")
+	}
+
+	if o.OIDCDeviceCode && !o.OIDCExecFree {
+		return errors.New("--device-code can only be specified along with --exec-free")
+	}
+
+	if o.OIDCDeviceCode && o.CallbackPort != 0 {
+		return errors.New("--device-code and --callback-port are mutually exclusive")
+	}
+
 	if o.OIDCIssuerURL != "" {
 		issuer, err := url.Parse(o.OIDCIssuerURL)
 		if err != nil {
@@ -214,8 +293,18 @@ func (o LoginOptions) Validate(cmd *cobra.Command, serverFlag string, args []str
 		}
 	}
 
-	if o.CallbackPort != 0 && !o.WebLogin && o.OIDCExecPluginType == "" {
-		return errors.New("--callback-port can only be specified along with --web or --exec-plugin")
+	if o.CallbackPort != 0 && !o.WebLogin && o.OIDCExecPluginType == "" && !o.OIDCExecFree {
+		return errors.New("--callback-port can only be specified along with --web, --exec-plugin or --exec-free")
+	}
+
+	switch o.TokenStore {
+	case "", keyring.TokenStoreKubeconfig:
+	case keyring.TokenStoreKeyring:
+		if o.OIDCExecPluginType != "" || o.OIDCExecFree {
+			return errors.New("--token-store=keyring cannot be used along with --exec-plugin or --exec-free")
+		}
+	default:
+		return fmt.Errorf("--token-store must be one of %s", strings.Join(keyring.TokenStores, ", "))
 	}
 
 	return nil
@@ -227,6 +316,12 @@ func (o LoginOptions) Run() error {
 		return err
 	}
 
+	if o.TokenStore == keyring.TokenStoreKeyring {
+		if err := o.storeTokenInKeyring(keyring.NewStore(keyring.DefaultHelper())); err != nil {
+			return err
+		}
+	}
+
 	newFileCreated, err := o.SaveConfig()
 	if err != nil {
 		return err
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -34,6 +35,7 @@ import (
 
 	occhallengers "github.com/openshift/oc/pkg/helpers/authchallengers"
 	ocerrors "github.com/openshift/oc/pkg/helpers/errors"
+	"github.com/openshift/oc/pkg/helpers/keyring"
 	cliconfig "github.com/openshift/oc/pkg/helpers/kubeconfig"
 	"github.com/openshift/oc/pkg/helpers/motd"
 	"github.com/openshift/oc/pkg/helpers/project"
@@ -85,8 +87,22 @@ type LoginOptions struct {
 	OIDCExtraScopes    []string
 	OIDCIssuerURL      string
 	OIDCCAFile         string
+	// OIDCExecFree logs in to the external OIDC issuer without an exec plugin and
+	// stores the tokens in the kubeconfig
+	OIDCExecFree bool
+	// OIDCDeviceCode uses the device authorization grant instead of the auth code flow
+	OIDCDeviceCode bool
 
 	Token string
+	// TokenStore is where the token is stored, in the kubeconfig or in the keychain
+	// of the operating system
+	TokenStore string
+
+	// ProfileName is the profile the server, user, namespace, certificate authority and
+	// proxy are read from
+	ProfileName string
+	// ProxyURL is the proxy to connect to the server through
+	ProxyURL string
 
 	PathOptions *kclientcmd.PathOptions
 
@@ -106,6 +122,7 @@ func NewLoginOptions(streams genericiooptions.IOStreams) *LoginOptions {
 	return &LoginOptions{
 		IOStreams:   streams,
 		CommandName: "oc",
+		TokenStore:  keyring.TokenStoreKubeconfig,
 	}
 }
 
@@ -157,6 +174,14 @@ func (o *LoginOptions) getClientConfig() (*restclient.Config, error) {
 	clientConfig.Host = o.Server
 	clientConfig.Insecure = o.InsecureTLS
 
+	if len(o.ProxyURL) > 0 {
+		proxyURL, err := url.Parse(o.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		clientConfig.Proxy = http.ProxyURL(proxyURL)
+	}
+
 	if !o.InsecureTLS {
 		// use specified CA or find existing CA
 		if len(o.CAFile) > 0 {
@@ -296,6 +321,14 @@ func (o *LoginOptions) gatherAuthInfo() error {
 		return nil
 	}
 
+	if o.OIDCExecFree {
+		if err := o.loginWithOIDC(clientConfig); err != nil {
+			return err
+		}
+		fmt.Fprintf(o.Out, "Logged into %q as %q from an external oidc issuer.\n\n", o.Config.Host, o.Username)
+		return nil
+	}
+
 	// if kubeconfig doesn't already have a matching user stanza...
 	clientConfig.BearerToken = ""
 	clientConfig.CertData = []byte{}
@@ -566,6 +599,21 @@ func (o *LoginOptions) SaveConfig() (bool, error) {
 	return created, nil
 }
 
+// storeTokenInKeyring moves the bearer token to the keychain, so that only the
+// exec plugin retrieving it is written to the kubeconfig.
+func (o *LoginOptions) storeTokenInKeyring(store *keyring.Store) error {
+	if len(o.Config.BearerToken) == 0 {
+		return nil
+	}
+	key := keyring.Key(o.Config.Host, o.Username)
+	if err := store.Save(key, o.Username, o.Config.BearerToken); err != nil {
+		return err
+	}
+	o.Config.BearerToken = ""
+	o.Config.ExecProvider = keyring.ExecConfig(key)
+	return nil
+}
+
 func (o *LoginOptions) usernameProvided() bool {
 	return len(o.Username) > 0
 }
@@ -7,10 +7,12 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"regexp"
 	"testing"
 
 	"github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
 
 	kapierrs "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
@@ -19,6 +21,7 @@ import (
 
 	"github.com/openshift/library-go/pkg/oauth/oauthdiscovery"
 	cliconfig "github.com/openshift/oc/pkg/helpers/kubeconfig"
+	"github.com/openshift/oc/pkg/helpers/profiles"
 )
 
 const (
@@ -374,6 +377,55 @@ func TestDialToHTTPSServer(t *testing.T) {
 	}
 }
 
+func TestApplyProfile(t *testing.T) {
+	profile := &profiles.Profile{
+		Name:                 "prod",
+		Server:               "https://api.prod.example.com:6443",
+		Username:             "sre",
+		Namespace:            "openshift-monitoring",
+		CertificateAuthority: "/etc/pki/prod-ca.crt",
+		ProxyURL:             "http://proxy.example.com:3128",
+	}
+	testCases := map[string]struct {
+		options  LoginOptions
+		args     []string
+		expected LoginOptions
+	}{
+		"profile": {
+			options:  LoginOptions{DefaultNamespace: "default"},
+			expected: LoginOptions{Username: "sre", DefaultNamespace: "openshift-monitoring", CAFile: "/etc/pki/prod-ca.crt", ProxyURL: "http://proxy.example.com:3128"},
+		},
+		"flags take precedence": {
+			options:  LoginOptions{Username: "admin", DefaultNamespace: "default", CAFile: "ca.crt"},
+			args:     []string{"--namespace=default"},
+			expected: LoginOptions{Username: "admin", DefaultNamespace: "default", CAFile: "ca.crt", ProxyURL: "http://proxy.example.com:3128"},
+		},
+		"insecure flag": {
+			options:  LoginOptions{InsecureTLS: true},
+			args:     []string{"--insecure-skip-tls-verify"},
+			expected: LoginOptions{Username: "sre", InsecureTLS: true, DefaultNamespace: "openshift-monitoring", ProxyURL: "http://proxy.example.com:3128"},
+		},
+		"token": {
+			options:  LoginOptions{Token: "sha256~token"},
+			expected: LoginOptions{Token: "sha256~token", DefaultNamespace: "openshift-monitoring", CAFile: "/etc/pki/prod-ca.crt", ProxyURL: "http://proxy.example.com:3128"},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.Flags().Bool("insecure-skip-tls-verify", false, "")
+			cmd.Flags().String("namespace", "", "")
+			if err := cmd.Flags().Parse(tc.args); err != nil {
+				t.Fatal(err)
+			}
+			tc.options.applyProfile(cmd, profile)
+			if !reflect.DeepEqual(tc.options, tc.expected) {
+				t.Errorf("expected %#v, got %#v", tc.expected, tc.options)
+			}
+		})
+	}
+}
+
 func newTLSServer(certString, keyString string) (*httptest.Server, error) {
 	invoked := make(chan struct{}, 1)
 	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
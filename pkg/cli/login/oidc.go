@@ -0,0 +1,142 @@
+package login
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/browser"
+	"golang.org/x/sync/errgroup"
+
+	restclient "k8s.io/client-go/rest"
+	kclientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/openshift/oc/pkg/cli/gettoken/oidc"
+	"github.com/openshift/oc/pkg/helpers/project"
+)
+
+// oidcAuthProvider is the name of the client-go auth provider that refreshes the ID
+// token stored in the kubeconfig with the refresh token, once the ID token expires.
+const oidcAuthProvider = "oidc"
+
+// oidcAuthenticationTimeout is how long the user has to complete the login with the
+// external OIDC issuer.
+const oidcAuthenticationTimeout = 5 * time.Minute
+
+// loginWithOIDC logs in to the external OIDC issuer without an exec plugin, through
+// the device authorization grant if --device-code is set and through the auth code
+// flow with PKCE otherwise, and stores the tokens in an auth provider config.
+func (o *LoginOptions) loginWithOIDC(clientConfig *restclient.Config) error {
+	ctx, cancel := context.WithTimeout(context.Background(), oidcAuthenticationTimeout)
+	defer cancel()
+
+	provider := &oidc.Provider{
+		IssuerURL:    o.OIDCIssuerURL,
+		ClientID:     o.OIDCClientID,
+		ClientSecret: o.OIDCClientSecret,
+		ExtraScopes:  o.OIDCExtraScopes,
+		UsePKCE:      true,
+	}
+	authenticator, err := oidc.NewAuthenticator(ctx, provider, "", o.OIDCCAFile, o.InsecureTLS)
+	if err != nil {
+		return fmt.Errorf("oidc authenticator error: %w", err)
+	}
+
+	var idToken, refreshToken string
+	if o.OIDCDeviceCode {
+		idToken, refreshToken, _, err = authenticator.GetTokenByDeviceCode(ctx, func(verificationURI, userCode string) {
+			fmt.Fprintf(o.Out, "To log in, visit %s and enter the code %s\n", verificationURI, userCode)
+		})
+	} else {
+		idToken, refreshToken, err = o.oidcAuthCode(ctx, authenticator)
+	}
+	if err != nil {
+		return fmt.Errorf("authentication error: %w", err)
+	}
+
+	authProvider, err := o.oidcAuthProviderConfig(idToken, refreshToken)
+	if err != nil {
+		return err
+	}
+
+	// the auth provider is only registered in the oc binary, so the ID token is
+	// used directly to find out who logged in
+	whoAmIConfig := restclient.CopyConfig(clientConfig)
+	whoAmIConfig.BearerToken = idToken
+	me, err := project.WhoAmI(whoAmIConfig)
+	if err != nil {
+		return err
+	}
+
+	clientConfig.AuthProvider = authProvider
+	o.Username = me.Name
+	o.Config = clientConfig
+	return nil
+}
+
+// oidcAuthCode does the auth code flow with PKCE, receiving the redirect from the
+// issuer on a local server listening on --callback-port.
+func (o *LoginOptions) oidcAuthCode(ctx context.Context, authenticator oidc.Authenticator) (string, string, error) {
+	readyChan := make(chan string, 1)
+	var idToken, refreshToken string
+	var eg errgroup.Group
+	eg.Go(func() error {
+		select {
+		case loginURL, ok := <-readyChan:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(o.Out, "Opening login URL in the default browser: %s\n", loginURL)
+			if err := browser.OpenURL(loginURL); err != nil {
+				fmt.Fprintf(o.Out, "Could not open the browser: %v\nPlease visit the login URL in your browser manually.\n", err)
+			}
+			return nil
+		case <-ctx.Done():
+			return fmt.Errorf("context cancelled while waiting for the local server: %w", ctx.Err())
+		}
+	})
+	eg.Go(func() error {
+		defer close(readyChan)
+		var err error
+		idToken, refreshToken, _, err = authenticator.GetTokenByAuthCode(ctx, fmt.Sprintf("127.0.0.1:%d", o.CallbackPort), readyChan)
+		if err != nil {
+			return fmt.Errorf("authorization code flow error: %w", err)
+		}
+		return nil
+	})
+	if err := eg.Wait(); err != nil {
+		return "", "", err
+	}
+	return idToken, refreshToken, nil
+}
+
+// oidcAuthProviderConfig returns the config of the client-go oidc auth provider, which
+// uses the ID token until it expires and then refreshes it with the refresh token,
+// updating the kubeconfig with the new tokens.
+func (o *LoginOptions) oidcAuthProviderConfig(idToken, refreshToken string) (*kclientcmdapi.AuthProviderConfig, error) {
+	config := map[string]string{
+		"idp-issuer-url": o.OIDCIssuerURL,
+		"client-id":      o.OIDCClientID,
+		"id-token":       idToken,
+	}
+	if len(refreshToken) > 0 {
+		config["refresh-token"] = refreshToken
+	}
+	if len(o.OIDCClientSecret) > 0 {
+		config["client-secret"] = o.OIDCClientSecret
+	}
+	if len(o.OIDCExtraScopes) > 0 {
+		config["extra-scopes"] = strings.Join(o.OIDCExtraScopes, ",")
+	}
+	if len(o.OIDCCAFile) > 0 {
+		// the kubeconfig may be used from any directory
+		caFile, err := filepath.Abs(o.OIDCCAFile)
+		if err != nil {
+			return nil, err
+		}
+		config["idp-certificate-authority"] = caFile
+	}
+	return &kclientcmdapi.AuthProviderConfig{Name: oidcAuthProvider, Config: config}, nil
+}
@@ -0,0 +1,118 @@
+package login
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	kclientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestValidateExecFree(t *testing.T) {
+	testCases := map[string]struct {
+		options     LoginOptions
+		expectedErr string
+	}{
+		"auth code": {
+			options: LoginOptions{OIDCExecFree: true, OIDCIssuerURL: "https://issuer.example.com", OIDCClientID: "client", CallbackPort: 8080},
+		},
+		"device code": {
+			options: LoginOptions{OIDCExecFree: true, OIDCDeviceCode: true, OIDCIssuerURL: "https://issuer.example.com", OIDCClientID: "client"},
+		},
+		"missing client id": {
+			options:     LoginOptions{OIDCExecFree: true, OIDCIssuerURL: "https://issuer.example.com"},
+			expectedErr: "--issuer-url and --client-id are required fields for --exec-free",
+		},
+		"with exec plugin": {
+			options:     LoginOptions{OIDCExecFree: true, OIDCExecPluginType: string(OCOIDC), OIDCIssuerURL: "https://issuer.example.com", OIDCClientID: "client"},
+			expectedErr: "--exec-plugin and --exec-free are mutually exclusive",
+		},
+		"with token": {
+			options:     LoginOptions{OIDCExecFree: true, Token: "token", OIDCIssuerURL: "https://issuer.example.com", OIDCClientID: "client"},
+			expectedErr: "--exec-free cannot be used along with --web, --username, --password or --token",
+		},
+		"device code without exec free": {
+			options:     LoginOptions{OIDCDeviceCode: true},
+			expectedErr: "--device-code can only be specified along with --exec-free",
+		},
+		"device code with callback port": {
+			options:     LoginOptions{OIDCExecFree: true, OIDCDeviceCode: true, CallbackPort: 8080, OIDCIssuerURL: "https://issuer.example.com", OIDCClientID: "client"},
+			expectedErr: "--device-code and --callback-port are mutually exclusive",
+		},
+		"oidc options without a flow": {
+			options:     LoginOptions{OIDCIssuerURL: "https://issuer.example.com", OIDCClientID: "client"},
+			expectedErr: "please specify --exec-plugin type or --exec-free",
+		},
+		"keyring token store": {
+			options: LoginOptions{TokenStore: "keyring", WebLogin: true},
+		},
+		"keyring token store with exec free": {
+			options:     LoginOptions{TokenStore: "keyring", OIDCExecFree: true, OIDCIssuerURL: "https://issuer.example.com", OIDCClientID: "client"},
+			expectedErr: "--token-store=keyring cannot be used along with --exec-plugin or --exec-free",
+		},
+		"unknown token store": {
+			options:     LoginOptions{TokenStore: "file"},
+			expectedErr: "--token-store must be one of kubeconfig, keyring",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tc.options.Server = "https://localhost:6443"
+			tc.options.StartingKubeConfig = kclientcmdapi.NewConfig()
+			err := tc.options.Validate(nil, "", nil)
+			if len(tc.expectedErr) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Errorf("expected error %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestOIDCAuthProviderConfig(t *testing.T) {
+	o := &LoginOptions{
+		OIDCIssuerURL:    "https://issuer.example.com",
+		OIDCClientID:     "client",
+		OIDCClientSecret: "secret",
+		OIDCExtraScopes:  []string{"email", "profile"},
+		OIDCCAFile:       "ca.crt",
+	}
+	authProvider, err := o.oidcAuthProviderConfig("id-token", "refresh-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	caFile, err := filepath.Abs("ca.crt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &kclientcmdapi.AuthProviderConfig{
+		Name: "oidc",
+		Config: map[string]string{
+			"idp-issuer-url":            "https://issuer.example.com",
+			"client-id":                 "client",
+			"client-secret":             "secret",
+			"id-token":                  "id-token",
+			"refresh-token":             "refresh-token",
+			"extra-scopes":              "email,profile",
+			"idp-certificate-authority": caFile,
+		},
+	}
+	if !reflect.DeepEqual(expected, authProvider) {
+		t.Errorf("expected %#v, got %#v", expected, authProvider)
+	}
+
+	o = &LoginOptions{OIDCIssuerURL: "https://issuer.example.com", OIDCClientID: "client"}
+	authProvider, err = o.oidcAuthProviderConfig("id-token", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := authProvider.Config["refresh-token"]; ok {
+		t.Errorf("expected no refresh token, got %#v", authProvider.Config)
+	}
+}
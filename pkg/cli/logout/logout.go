@@ -6,6 +6,8 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -16,10 +18,13 @@ import (
 	restclient "k8s.io/client-go/rest"
 	kclientcmd "k8s.io/client-go/tools/clientcmd"
 	kclientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/util/homedir"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/templates"
 
 	oauthv1client "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
+	"github.com/openshift/oc/pkg/cli/gettoken/tokencache"
+	"github.com/openshift/oc/pkg/helpers/keyring"
 	"github.com/openshift/oc/pkg/helpers/project"
 )
 
@@ -31,6 +36,22 @@ type LogoutOptions struct {
 
 	PathOptions *kclientcmd.PathOptions
 
+	// AllSessions deletes all the OAuth access tokens of the user, not only the current one
+	AllSessions bool
+	// Purge removes all the credentials for the server stored locally
+	Purge bool
+
+	// tokenCacheDir is the directory 'oc get-token' caches the OIDC tokens in
+	tokenCacheDir string
+	// tempDir is the temporary directory, whose client certificate and key files are
+	// removed by --purge
+	tempDir string
+
+	// keyringKey is the key of the token in the keychain of the operating system,
+	// if it was stored there by 'oc login --token-store=keyring'
+	keyringKey string
+	keyring    *keyring.Store
+
 	genericiooptions.IOStreams
 }
 
@@ -46,12 +67,25 @@ var (
 		are typically managed by other programs. Instead, you can delete your config file to remove
 		the local copy of that certificate or the record of your server login.
 
+		A token stored in the keychain of the operating system with 'oc login --token-store=keyring'
+		is removed from the keychain as well.
+
+		With --all-sessions, every OAuth access token of the user is deleted on the server, which
+		logs the user out of all the other clients and workstations too. With --purge, all the
+		credentials for the server stored locally are removed: the tokens of every user of the server
+		in the config file, their keychain entries, the OIDC tokens cached by 'oc get-token' and the
+		OIDC tokens stored in the config file. Client certificate and key files are only removed
+		when they are in the temporary directory of the system.
+
 		After logging out, if you want to log back into the server use 'oc login'.
 	`)
 
 	logoutExample = templates.Examples(`
 		# Log out
 		oc logout
+
+		# Log out of all the sessions of the user and remove the credentials stored on this workstation
+		oc logout --all-sessions --purge
 	`)
 )
 
@@ -76,7 +110,8 @@ func NewCmdLogout(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra
 		},
 	}
 
-	// TODO: support --all which performs the same logic on all users in your config file.
+	cmds.Flags().BoolVar(&o.AllSessions, "all-sessions", o.AllSessions, "Delete all the OAuth access tokens of the user on the server, not only the current one.")
+	cmds.Flags().BoolVar(&o.Purge, "purge", o.Purge, "Remove all the tokens for the server stored locally: in the config file, the keychain and the OIDC token cache, and the temporary client certificates.")
 
 	return cmds
 }
@@ -93,6 +128,19 @@ func (o *LogoutOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []
 		return err
 	}
 
+	if key, ok := keyring.KeyFromExecConfig(o.Config.ExecProvider); ok {
+		o.useKeyringToken(keyring.NewStore(keyring.DefaultHelper()), key)
+	}
+
+	if o.Purge && o.keyring == nil {
+		o.keyring = keyring.NewStore(keyring.DefaultHelper())
+	}
+	o.tokenCacheDir = filepath.Join(homedir.HomeDir(), ".kube", "cache", "oc")
+	if kcd := os.Getenv("KUBECACHEDIR"); kcd != "" {
+		o.tokenCacheDir = filepath.Join(kcd, "oc")
+	}
+	o.tempDir = os.TempDir()
+
 	o.PathOptions = kclientcmd.NewDefaultPathOptions()
 	// we need to set explicit path if one was specified, since NewDefaultPathOptions doesn't do it for us
 	o.PathOptions.LoadingRules.ExplicitPath = kcmdutil.GetFlagString(cmd, kclientcmd.RecommendedConfigPathFlag)
@@ -100,6 +148,19 @@ func (o *LogoutOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []
 	return nil
 }
 
+// useKeyringToken sets the token stored in the keychain under key as the token of the session.
+// A token that cannot be read is reported, and only the local references to it are removed.
+func (o *LogoutOptions) useKeyringToken(store *keyring.Store, key string) {
+	o.keyring = store
+	o.keyringKey = key
+	o.Config.ExecProvider = nil
+	token, err := store.Get(key)
+	if err != nil {
+		fmt.Fprintf(o.ErrOut, "warning: %v, the session cannot be revoked on the server\n", err)
+	}
+	o.Config.BearerToken = token
+}
+
 func (o LogoutOptions) Validate(args []string) error {
 	if len(args) > 0 {
 		return errors.New("No arguments are allowed")
@@ -109,7 +170,7 @@ func (o LogoutOptions) Validate(args []string) error {
 		return errors.New("Must have a config file already created")
 	}
 
-	if len(o.Config.BearerToken) == 0 {
+	if len(o.Config.BearerToken) == 0 && len(o.keyringKey) == 0 {
 		return errors.New("You must have a token in order to logout.")
 	}
 
@@ -119,6 +180,9 @@ func (o LogoutOptions) Validate(args []string) error {
 func (o LogoutOptions) RunLogout() error {
 	token := o.Config.BearerToken
 	tokenName := o.Config.BearerToken
+	if len(token) == 0 {
+		return o.removeLocalSession()
+	}
 
 	client, err := oauthv1client.NewForConfig(o.Config)
 	if err != nil {
@@ -134,11 +198,32 @@ func (o LogoutOptions) RunLogout() error {
 		tokenName = tokenToObjectName(tokenName)
 	}
 
+	// the current session is logged out even if the others cannot be revoked
+	var sessionsErr error
+	if o.AllSessions {
+		var revoked int
+		revoked, sessionsErr = revokeAllSessions(client, userInfo.Name, tokenName)
+		fmt.Fprintf(o.Out, "Revoked %d other sessions of %q on %q\n", revoked, userInfo.Name, o.Config.Host)
+	}
+
 	if err := client.OAuthAccessTokens().Delete(context.TODO(), tokenName, metav1.DeleteOptions{}); err != nil {
 		klog.V(1).Infof("%v", err)
 	}
 
-	configErr := deleteTokenFromConfig(*o.StartingKubeConfig, o.PathOptions, token)
+	if len(o.keyringKey) > 0 {
+		if err := o.keyring.Erase(o.keyringKey); err != nil {
+			return err
+		}
+		klog.V(1).Infof("Removed token from the keychain.")
+	}
+
+	if o.Purge {
+		if err := o.purge(*o.StartingKubeConfig, o.Config.Host); err != nil {
+			return err
+		}
+	}
+
+	configErr := deleteTokenFromConfig(*o.StartingKubeConfig, o.PathOptions, token, o.keyringKey)
 	if configErr == nil {
 		klog.V(1).Infof("Removed token from your local configuration.")
 
@@ -147,20 +232,162 @@ func (o LogoutOptions) RunLogout() error {
 		fmt.Fprintf(o.Out, "Logged %q out on %q\n", userInfo.Name, o.Config.Host)
 	}
 
-	return configErr
+	if configErr != nil {
+		return configErr
+	}
+	return sessionsErr
 }
 
-func deleteTokenFromConfig(config kclientcmdapi.Config, pathOptions *kclientcmd.PathOptions, bearerToken string) error {
+// removeLocalSession removes the keychain entry and the configuration of a session whose token
+// could not be read from the keychain, and which is left to expire on the server.
+func (o LogoutOptions) removeLocalSession() error {
+	if err := o.keyring.Erase(o.keyringKey); err != nil {
+		fmt.Fprintf(o.ErrOut, "warning: %v\n", err)
+	}
+	if o.Purge {
+		if err := o.purge(*o.StartingKubeConfig, o.Config.Host); err != nil {
+			return err
+		}
+	}
+	if err := deleteTokenFromConfig(*o.StartingKubeConfig, o.PathOptions, "", o.keyringKey); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "Removed the session on %q from your local configuration\n", o.Config.Host)
+	return nil
+}
+
+// deleteTokenFromConfig removes the token from every user stanza, along with the
+// exec plugin retrieving it from the keychain if keyringKey is set.
+func deleteTokenFromConfig(config kclientcmdapi.Config, pathOptions *kclientcmd.PathOptions, bearerToken, keyringKey string) error {
 	for key, value := range config.AuthInfos {
 		if value.Token == bearerToken {
 			value.Token = ""
 			config.AuthInfos[key] = value
 			// don't break, its possible that more than one user stanza has the same token.
 		}
+		if stored, ok := keyring.KeyFromExecConfig(value.Exec); ok && stored == keyringKey {
+			value.Exec = nil
+			config.AuthInfos[key] = value
+		}
 	}
 	return kclientcmd.ModifyConfig(pathOptions, config, true)
 }
 
+// revokeAllSessions deletes the OAuth access tokens of the user but the current one, which
+// is deleted by the logout as usual, and returns the number of tokens deleted.
+func revokeAllSessions(client oauthv1client.OauthV1Interface, userName, currentTokenName string) (int, error) {
+	tokens, err := client.UserOAuthAccessTokens().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("unable to list the sessions of %q: %v", userName, err)
+	}
+	revoked := 0
+	var errs []string
+	for _, token := range tokens.Items {
+		if token.UserName != userName || token.Name == currentTokenName {
+			continue
+		}
+		if err := client.UserOAuthAccessTokens().Delete(context.TODO(), token.Name, metav1.DeleteOptions{}); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		revoked++
+	}
+	if len(errs) > 0 {
+		return revoked, fmt.Errorf("unable to revoke %d sessions of %q: %s", len(errs), userName, strings.Join(errs, "; "))
+	}
+	return revoked, nil
+}
+
+// purge removes the credentials of every user of the server from the config, which is written
+// by deleteTokenFromConfig, along with their keychain entries, cached OIDC tokens and temporary
+// client certificates.
+func (o LogoutOptions) purge(config kclientcmdapi.Config, server string) error {
+	tokens, keychainEntries, cachedTokens, certFiles := 0, 0, 0, 0
+	cache := &tokencache.Repository{}
+	for _, c := range config.Contexts {
+		cluster, ok := config.Clusters[c.Cluster]
+		if !ok || cluster.Server != server {
+			continue
+		}
+		authInfo, ok := config.AuthInfos[c.AuthInfo]
+		if !ok {
+			continue
+		}
+		if len(authInfo.Token) > 0 {
+			authInfo.Token = ""
+			tokens++
+		}
+		if key, ok := keyring.KeyFromExecConfig(authInfo.Exec); ok {
+			authInfo.Exec = nil
+			// the current token was already removed from the keychain
+			if key != o.keyringKey {
+				if err := o.keyring.Erase(key); err != nil {
+					klog.V(1).Infof("Unable to remove %s from the keychain: %v", key, err)
+				} else {
+					keychainEntries++
+				}
+			}
+		}
+		if key, ok := oidcTokenCacheKey(authInfo.Exec); ok {
+			if removed, err := cache.Remove(o.tokenCacheDir, key); err != nil {
+				klog.V(1).Infof("Unable to remove the cached OIDC tokens of %s: %v", key.IssuerURL, err)
+			} else if removed {
+				cachedTokens++
+			}
+		}
+		for _, file := range []*string{&authInfo.ClientCertificate, &authInfo.ClientKey} {
+			if !o.isTemporary(*file) {
+				continue
+			}
+			if err := os.Remove(*file); err == nil {
+				certFiles++
+			} else if !os.IsNotExist(err) {
+				klog.V(1).Infof("Unable to remove %s: %v", *file, err)
+				continue
+			}
+			*file = ""
+		}
+		if authInfo.AuthProvider != nil && authInfo.AuthProvider.Name == "oidc" {
+			for _, name := range []string{"id-token", "refresh-token"} {
+				if _, ok := authInfo.AuthProvider.Config[name]; ok {
+					delete(authInfo.AuthProvider.Config, name)
+					tokens++
+				}
+			}
+		}
+	}
+	fmt.Fprintf(o.Out, "Removed %d tokens from your local configuration, %d from the keychain, %d cached OIDC tokens and %d temporary client certificate files of %q\n", tokens, keychainEntries, cachedTokens, certFiles, server)
+	return nil
+}
+
+// isTemporary returns whether the file is in the temporary directory, where 'oc login' may
+// have been pointed at a client certificate or key extracted for the session.
+func (o LogoutOptions) isTemporary(file string) bool {
+	if len(file) == 0 || len(o.tempDir) == 0 || !filepath.IsAbs(file) {
+		return false
+	}
+	rel, err := filepath.Rel(o.tempDir, file)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// oidcTokenCacheKey returns the key of the OIDC tokens cached by the exec plugin 'oc get-token'
+// configured by 'oc login --exec-plugin=oc-oidc'.
+func oidcTokenCacheKey(exec *kclientcmdapi.ExecConfig) (tokencache.Key, bool) {
+	if exec == nil || exec.Command != "oc" || len(exec.Args) == 0 || exec.Args[0] != "get-token" {
+		return tokencache.Key{}, false
+	}
+	key := tokencache.Key{}
+	for _, arg := range exec.Args[1:] {
+		if v, ok := strings.CutPrefix(arg, "--issuer-url="); ok {
+			key.IssuerURL = v
+		}
+		if v, ok := strings.CutPrefix(arg, "--client-id="); ok {
+			key.ClientID = v
+		}
+	}
+	return key, len(key.IssuerURL) > 0 && len(key.ClientID) > 0
+}
+
 // tokenToObjectName returns the oauthaccesstokens object name for the given raw token,
 // i.e. the sha256 hash prefixed with "sha256~".
 func tokenToObjectName(token string) string {
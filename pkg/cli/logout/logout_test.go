@@ -0,0 +1,273 @@
+package logout
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	restclient "k8s.io/client-go/rest"
+	clienttesting "k8s.io/client-go/testing"
+	kclientcmd "k8s.io/client-go/tools/clientcmd"
+	kclientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	fakeoauthclient "github.com/openshift/client-go/oauth/clientset/versioned/fake"
+	"github.com/openshift/oc/pkg/cli/gettoken/tokencache"
+	"github.com/openshift/oc/pkg/helpers/keyring"
+)
+
+func TestRevokeAllSessions(t *testing.T) {
+	token := func(name, user string) runtime.Object {
+		return &oauthv1.UserOAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: name}, UserName: user}
+	}
+	tests := []struct {
+		name            string
+		tokens          []runtime.Object
+		failDelete      string
+		expectedRevoked int
+		expectedError   string
+		expectedTokens  []string
+	}{
+		{
+			name:           "no other sessions",
+			tokens:         []runtime.Object{token("current", "alice")},
+			expectedTokens: []string{"current"},
+		},
+		{
+			name:            "other sessions of the user",
+			tokens:          []runtime.Object{token("current", "alice"), token("laptop", "alice"), token("ci", "alice"), token("other", "bob")},
+			expectedRevoked: 2,
+			expectedTokens:  []string{"current", "other"},
+		},
+		{
+			name:            "failed revocation",
+			tokens:          []runtime.Object{token("current", "alice"), token("laptop", "alice"), token("ci", "alice")},
+			failDelete:      "ci",
+			expectedRevoked: 1,
+			expectedError:   `unable to revoke 1 sessions of "alice": forbidden`,
+			expectedTokens:  []string{"ci", "current"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fakeoauthclient.NewSimpleClientset(test.tokens...)
+			client.PrependReactor("delete", "useroauthaccesstokens", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.(clienttesting.DeleteAction).GetName() == test.failDelete {
+					return true, nil, fmt.Errorf("forbidden")
+				}
+				return false, nil, nil
+			})
+
+			revoked, err := revokeAllSessions(client.OauthV1(), "alice", "current")
+			if len(test.expectedError) > 0 {
+				if err == nil || err.Error() != test.expectedError {
+					t.Errorf("expected error %q, got %v", test.expectedError, err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if revoked != test.expectedRevoked {
+				t.Errorf("expected %d revoked sessions, got %d", test.expectedRevoked, revoked)
+			}
+
+			list, err := client.OauthV1().UserOAuthAccessTokens().List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var remaining []string
+			for _, token := range list.Items {
+				remaining = append(remaining, token.Name)
+			}
+			sort.Strings(remaining)
+			if strings.Join(remaining, ",") != strings.Join(test.expectedTokens, ",") {
+				t.Errorf("expected the tokens %v to remain, got %v", test.expectedTokens, remaining)
+			}
+		})
+	}
+}
+
+func TestOIDCTokenCacheKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		exec     *kclientcmdapi.ExecConfig
+		expected tokencache.Key
+		ok       bool
+	}{
+		{
+			name: "no exec plugin",
+		},
+		{
+			name: "get-token",
+			exec: &kclientcmdapi.ExecConfig{Command: "oc", Args: []string{"get-token", "--issuer-url=https://issuer.example.com", "--client-id=oc-cli", "--callback-address=127.0.0.1:8080"}},
+			expected: tokencache.Key{
+				IssuerURL: "https://issuer.example.com",
+				ClientID:  "oc-cli",
+			},
+			ok: true,
+		},
+		{
+			name: "without client id",
+			exec: &kclientcmdapi.ExecConfig{Command: "oc", Args: []string{"get-token", "--issuer-url=https://issuer.example.com"}},
+		},
+		{
+			name: "other command",
+			exec: &kclientcmdapi.ExecConfig{Command: "kubelogin", Args: []string{"get-token", "--issuer-url=https://issuer.example.com", "--client-id=oc-cli"}},
+		},
+		{
+			name: "other subcommand",
+			exec: &kclientcmdapi.ExecConfig{Command: "oc", Args: []string{"whoami", "--issuer-url=https://issuer.example.com", "--client-id=oc-cli"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, ok := oidcTokenCacheKey(test.exec)
+			if ok != test.ok {
+				t.Fatalf("expected ok %t, got %t", test.ok, ok)
+			}
+			if ok && key != test.expected {
+				t.Errorf("expected %#v, got %#v", test.expected, key)
+			}
+		})
+	}
+}
+
+func TestPurge(t *testing.T) {
+	cacheDir, tempDir, otherDir := t.TempDir(), t.TempDir(), t.TempDir()
+	cache := &tokencache.Repository{}
+	cached := tokencache.Key{IssuerURL: "https://issuer.example.com", ClientID: "cached"}
+	if err := cache.Save(cacheDir, cached, tokencache.Set{IDToken: "id"}); err != nil {
+		t.Fatal(err)
+	}
+	getToken := func(clientID string) *kclientcmdapi.ExecConfig {
+		return &kclientcmdapi.ExecConfig{Command: "oc", Args: []string{"get-token", "--issuer-url=https://issuer.example.com", "--client-id=" + clientID}}
+	}
+	writeFile := func(dir, name string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	tempCert, tempKey, otherCert := writeFile(tempDir, "tls.crt"), writeFile(tempDir, "tls.key"), writeFile(otherDir, "tls.crt")
+
+	config := kclientcmdapi.Config{
+		Clusters: map[string]*kclientcmdapi.Cluster{
+			"cluster": {Server: "https://api.example.com:6443"},
+			"other":   {Server: "https://api.other.com:6443"},
+		},
+		AuthInfos: map[string]*kclientcmdapi.AuthInfo{
+			"token":        {Token: "sha256~a"},
+			"cached-oidc":  {Exec: getToken("cached")},
+			"missing-oidc": {Exec: getToken("missing")},
+			"temp-cert":    {ClientCertificate: tempCert, ClientKey: tempKey},
+			"other-cert":   {ClientCertificate: otherCert},
+			"other-server": {Token: "sha256~b"},
+		},
+		Contexts: map[string]*kclientcmdapi.Context{
+			"token":        {Cluster: "cluster", AuthInfo: "token"},
+			"cached-oidc":  {Cluster: "cluster", AuthInfo: "cached-oidc"},
+			"missing-oidc": {Cluster: "cluster", AuthInfo: "missing-oidc"},
+			"temp-cert":    {Cluster: "cluster", AuthInfo: "temp-cert"},
+			"other-cert":   {Cluster: "cluster", AuthInfo: "other-cert"},
+			"other-server": {Cluster: "other", AuthInfo: "other-server"},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	o := LogoutOptions{tokenCacheDir: cacheDir, tempDir: tempDir, IOStreams: genericiooptions.IOStreams{Out: out}}
+	if err := o.purge(config, "https://api.example.com:6443"); err != nil {
+		t.Fatal(err)
+	}
+	expected := `Removed 1 tokens from your local configuration, 0 from the keychain, 1 cached OIDC tokens and 2 temporary client certificate files of "https://api.example.com:6443"`
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+
+	if _, err := cache.FindByKey(cacheDir, cached); !os.IsNotExist(err) {
+		t.Errorf("expected the cached tokens to be removed, got %v", err)
+	}
+	for _, file := range []string{tempCert, tempKey} {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, got %v", file, err)
+		}
+	}
+	if _, err := os.Stat(otherCert); err != nil {
+		t.Errorf("expected %s to be kept, got %v", otherCert, err)
+	}
+	if authInfo := config.AuthInfos["temp-cert"]; len(authInfo.ClientCertificate) > 0 || len(authInfo.ClientKey) > 0 {
+		t.Errorf("expected the temporary client certificate to be removed from the config, got %#v", authInfo)
+	}
+	if authInfo := config.AuthInfos["other-cert"]; authInfo.ClientCertificate != otherCert {
+		t.Errorf("expected the client certificate to be kept in the config, got %#v", authInfo)
+	}
+	if authInfo := config.AuthInfos["other-server"]; authInfo.Token != "sha256~b" {
+		t.Errorf("expected the token of the other server to be kept, got %#v", authInfo)
+	}
+}
+
+func TestLogoutWithoutKeyringToken(t *testing.T) {
+	// the helper cannot read the token and records the key it erases
+	dir := t.TempDir()
+	erased := filepath.Join(dir, "erased")
+	helper := fmt.Sprintf("#!/bin/sh\ncase \"$1\" in\nget) echo 'the keychain is locked'; exit 1;;\nerase) cat > %s;;\nesac\n", erased)
+	if err := os.WriteFile(filepath.Join(dir, "docker-credential-test"), []byte(helper), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	key := keyring.Key("https://api.example.com:6443", "alice")
+	config := kclientcmdapi.Config{
+		Clusters: map[string]*kclientcmdapi.Cluster{"cluster": {Server: "https://api.example.com:6443"}},
+		AuthInfos: map[string]*kclientcmdapi.AuthInfo{
+			"keyring": {Exec: keyring.ExecConfig(key)},
+			"token":   {Token: "sha256~b"},
+		},
+		Contexts:       map[string]*kclientcmdapi.Context{"keyring": {Cluster: "cluster", AuthInfo: "keyring"}},
+		CurrentContext: "keyring",
+	}
+	pathOptions := kclientcmd.NewDefaultPathOptions()
+	pathOptions.LoadingRules.ExplicitPath = filepath.Join(dir, "kubeconfig")
+	if err := kclientcmd.WriteToFile(config, pathOptions.LoadingRules.ExplicitPath); err != nil {
+		t.Fatal(err)
+	}
+
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	o := LogoutOptions{
+		StartingKubeConfig: &config,
+		Config:             &restclient.Config{Host: "https://api.example.com:6443", ExecProvider: config.AuthInfos["keyring"].Exec},
+		PathOptions:        pathOptions,
+		IOStreams:          genericiooptions.IOStreams{Out: out, ErrOut: errOut},
+	}
+	o.useKeyringToken(keyring.NewStore("test"), key)
+	if !strings.Contains(errOut.String(), "the session cannot be revoked on the server") {
+		t.Errorf("expected the unreadable token to be reported, got %q", errOut.String())
+	}
+	if err := o.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.RunLogout(); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := os.ReadFile(erased); err != nil || strings.TrimSpace(string(data)) != key {
+		t.Errorf("expected %s to be erased from the keychain, got %q: %v", key, data, err)
+	}
+	written, err := kclientcmd.LoadFromFile(pathOptions.LoadingRules.ExplicitPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authInfo := written.AuthInfos["keyring"]; authInfo.Exec != nil {
+		t.Errorf("expected the keychain reference to be removed, got %#v", authInfo.Exec)
+	}
+	if authInfo := written.AuthInfos["token"]; authInfo.Token != "sha256~b" {
+		t.Errorf("expected the other token to be kept, got %#v", authInfo)
+	}
+}
@@ -0,0 +1,179 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubectl/pkg/util/interrupt"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	"github.com/openshift/oc/pkg/helpers/term"
+)
+
+// prefixColors are the colors the prefixes of the pods are cycled through when the output is a
+// terminal.
+var prefixColors = []string{
+	"\033[36m", // cyan
+	"\033[32m", // green
+	"\033[35m", // magenta
+	"\033[33m", // yellow
+	"\033[34m", // blue
+	"\033[31m", // red
+}
+
+var containerNameFromFieldPath = regexp.MustCompile(`spec\.(?:initContainers|containers|ephemeralContainers){(.+)}`)
+
+// podsForDeploymentConfig returns the current pods of the deployment config, so that the logs of
+// all of them can be read rather than those of the latest deployment.
+func (o *LogsOptions) podsForDeploymentConfig(dc *appsv1.DeploymentConfig) (*corev1.PodList, error) {
+	selector := labels.SelectorFromSet(dc.Spec.Selector)
+	if selector.Empty() {
+		return nil, fmt.Errorf("deployment config %s has no selector", dc.Name)
+	}
+	pods, err := o.KubeClient.Pods(dc.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("no pods found for deployment config %s", dc.Name)
+	}
+	return pods, nil
+}
+
+// runAllPodLogs reads the logs of all the pods of the object, each line prefixed with the pod and
+// container it comes from. When following, the logs of the pods are streamed concurrently.
+func (o *LogsOptions) runAllPodLogs() error {
+	requests, err := o.AllPodLogsForObject(o.RESTClientGetter, o.Object, o.Options, o.GetPodTimeout, o.AllContainers)
+	if err != nil {
+		return err
+	}
+	if o.Follow && len(requests) > o.MaxFollowConcurrency {
+		return fmt.Errorf(
+			"you are attempting to follow %d log streams, but maximum allowed concurrency is %d, use --max-log-requests to increase the limit",
+			len(requests), o.MaxFollowConcurrency,
+		)
+	}
+
+	refs := make([]corev1.ObjectReference, 0, len(requests))
+	for ref := range requests {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Name != refs[j].Name {
+			return refs[i].Name < refs[j].Name
+		}
+		return refs[i].FieldPath < refs[j].FieldPath
+	})
+	prefixes := o.podPrefixes(refs, term.IsTerminalWriter(o.Out))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	intr := interrupt.New(nil, cancel)
+	return intr.Run(func() error {
+		if !o.Follow || len(refs) == 1 {
+			for _, ref := range refs {
+				out := &prefixWriter{prefix: prefixes[ref], out: o.Out}
+				if err := o.consumeLogs(ctx, requests[ref], out); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		out := &lockedWriter{out: o.Out}
+		errCh := make(chan error, len(refs))
+		wg := sync.WaitGroup{}
+		for _, ref := range refs {
+			wg.Add(1)
+			go func(ref corev1.ObjectReference, request rest.ResponseWrapper) {
+				defer wg.Done()
+				if err := o.consumeLogs(ctx, request, &prefixWriter{prefix: prefixes[ref], out: out}); err != nil {
+					errCh <- err
+					// stop following the other pods, as kubectl does
+					cancel()
+				}
+			}(ref, requests[ref])
+		}
+		wg.Wait()
+		close(errCh)
+		return <-errCh
+	})
+}
+
+// consumeLogs writes the logs of a request, reporting errors inline if they are ignored.
+func (o *LogsOptions) consumeLogs(ctx context.Context, request rest.ResponseWrapper, out io.Writer) error {
+	if err := o.ConsumeRequestFn(ctx, request, out); err != nil {
+		if !o.IgnoreLogErrors {
+			return err
+		}
+		fmt.Fprintf(out, "error: %v\n", err)
+	}
+	return nil
+}
+
+// podPrefixes returns the prefix of the lines of each request. A pod keeps the same color for all
+// its containers.
+func (o *LogsOptions) podPrefixes(refs []corev1.ObjectReference, color bool) map[corev1.ObjectReference][]byte {
+	prefixes := make(map[corev1.ObjectReference][]byte, len(refs))
+	colors := make(map[string]string)
+	for _, ref := range refs {
+		if !o.Prefix || len(ref.Name) == 0 {
+			prefixes[ref] = nil
+			continue
+		}
+		var containerName string
+		if matches := containerNameFromFieldPath.FindStringSubmatch(ref.FieldPath); len(matches) == 2 {
+			containerName = matches[1]
+		}
+		prefix := fmt.Sprintf("[pod/%s/%s]", ref.Name, containerName)
+		if color {
+			c, ok := colors[ref.Name]
+			if !ok {
+				c = prefixColors[len(colors)%len(prefixColors)]
+				colors[ref.Name] = c
+			}
+			prefix = c + prefix + "\033[0m"
+		}
+		prefixes[ref] = []byte(prefix + " ")
+	}
+	return prefixes
+}
+
+// prefixWriter prefixes each write, which DefaultConsumeRequest makes once per line.
+type prefixWriter struct {
+	prefix []byte
+	out    io.Writer
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 || len(w.prefix) == 0 {
+		return w.out.Write(p)
+	}
+	// write the prefix and the line at once so lines of different pods do not interleave
+	n, err := w.out.Write(append(append([]byte{}, w.prefix...), p...))
+	n -= len(w.prefix)
+	if n < 0 {
+		n = 0
+	}
+	return n, err
+}
+
+// lockedWriter serializes the writes of the pods followed concurrently.
+type lockedWriter struct {
+	lock sync.Mutex
+	out  io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.out.Write(p)
+}
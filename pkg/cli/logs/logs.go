@@ -9,6 +9,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/kubectl/pkg/cmd/logs"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/completion"
@@ -29,6 +30,12 @@ var (
 		specified via -c. When a build config or deployment config is specified, you can view
 		the logs for a particular version of it via --version.
 
+		With --all-pods, the logs of all the current pods of a deployment config, deployment,
+		stateful set, or other workload are read instead of those of a single pod, each line
+		prefixed with the pod and container it comes from. The prefixes are colored per pod when
+		the output is a terminal. When following, the logs of the pods are streamed concurrently,
+		up to --max-log-requests streams.
+
 		If your pod is failing to start, you may need to use the --previous option to see the
 		logs of the last attempt.
 	`)
@@ -45,6 +52,9 @@ var (
 		# or due to deployment pruning or manual deletion of the deployment
 		oc logs --version=1 dc/mysql
 
+		# Follow the logs of all the current pods of the mysql deployment config
+		oc logs -f --all-pods dc/mysql
+
 		# Return a snapshot of ruby-container logs from pod backend
 		oc logs backend -c ruby-container
 
@@ -58,6 +68,8 @@ type LogsOptions struct {
 	// Client enables access to the Build object when processing
 	// build logs for Jenkins Pipeline Strategy builds
 	Client buildv1client.BuildV1Interface
+	// KubeClient lists the pods of a deployment config for --all-pods
+	KubeClient corev1client.CoreV1Interface
 
 	Version int64
 
@@ -105,12 +117,19 @@ func (o *LogsOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []st
 	if err != nil {
 		return err
 	}
+	o.KubeClient, err = corev1client.NewForConfig(config)
+	if err != nil {
+		return err
+	}
 	return o.LogsOptions.Complete(f, cmd, args)
 }
 
 // Validate runs the upstream validation for the logs command and then it
 // will validate any OpenShift-specific log options.
 func (o *LogsOptions) Validate(args []string) error {
+	if o.AllPods && o.Version != 0 {
+		return fmt.Errorf("--version cannot be used with --all-pods")
+	}
 	return o.LogsOptions.Validate()
 }
 
@@ -140,7 +159,21 @@ func (o *LogsOptions) RunLog() error {
 		o.LogsOptions.Options = o.buildLogOptions(podLogOptions)
 
 	case *appsv1.DeploymentConfig:
-		o.LogsOptions.Options = o.deployLogOptions(podLogOptions)
+		if !o.AllPods {
+			o.LogsOptions.Options = o.deployLogOptions(podLogOptions)
+			break
+		}
+		pods, err := o.podsForDeploymentConfig(t)
+		if err != nil {
+			return err
+		}
+		o.LogsOptions.Object = pods
+		return o.runAllPodLogs()
+
+	default:
+		if o.AllPods {
+			return o.runAllPodLogs()
+		}
 	}
 
 	if !isPipeline {
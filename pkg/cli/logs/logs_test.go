@@ -1,18 +1,27 @@
 package logs
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/pflag"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
 	"k8s.io/kubectl/pkg/cmd/logs"
 
+	appsv1 "github.com/openshift/api/apps/v1"
 	buildv1 "github.com/openshift/api/build/v1"
 	buildfake "github.com/openshift/client-go/build/clientset/versioned/fake"
 )
@@ -97,3 +106,79 @@ func TestRunLogForPipelineStrategy(t *testing.T) {
 	}
 
 }
+
+type fakeLogs string
+
+func (l fakeLogs) DoRaw(context.Context) ([]byte, error) { return []byte(l), nil }
+func (l fakeLogs) Stream(context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(string(l))), nil
+}
+
+func TestRunLogAllPodsForDeploymentConfig(t *testing.T) {
+	pod := func(name string, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: name, Labels: labels}}
+	}
+	fakekc := kubefake.NewSimpleClientset(
+		pod("mysql-2-b", map[string]string{"deploymentconfig": "mysql"}),
+		pod("mysql-2-a", map[string]string{"deploymentconfig": "mysql"}),
+		pod("mysql-2-deploy", map[string]string{"openshift.io/deployer-pod-for.name": "mysql-2"}),
+		pod("other", map[string]string{"deploymentconfig": "other"}),
+	)
+	dc := &appsv1.DeploymentConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "mysql"},
+		Spec:       appsv1.DeploymentConfigSpec{Selector: map[string]string{"deploymentconfig": "mysql"}},
+	}
+
+	for _, follow := range []bool{false, true} {
+		streams, _, out, _ := genericiooptions.NewTestIOStreams()
+		o := &LogsOptions{
+			LogsOptions: &logs.LogsOptions{
+				IOStreams:            streams,
+				Object:               dc,
+				Namespace:            "foo",
+				Options:              &corev1.PodLogOptions{},
+				AllPods:              true,
+				Prefix:               true,
+				Follow:               follow,
+				MaxFollowConcurrency: 5,
+				ConsumeRequestFn:     logs.DefaultConsumeRequest,
+				AllPodLogsForObject: func(_ genericclioptions.RESTClientGetter, object, _ runtime.Object, _ time.Duration, _ bool) (map[corev1.ObjectReference]rest.ResponseWrapper, error) {
+					requests := make(map[corev1.ObjectReference]rest.ResponseWrapper)
+					for _, pod := range object.(*corev1.PodList).Items {
+						ref := corev1.ObjectReference{Kind: "Pod", Name: pod.Name, FieldPath: "spec.containers{mysql}"}
+						requests[ref] = fakeLogs(fmt.Sprintf("started %s\nready %s\n", pod.Name, pod.Name))
+					}
+					return requests, nil
+				},
+			},
+			KubeClient: fakekc.CoreV1(),
+		}
+		if err := o.RunLog(); err != nil {
+			t.Fatalf("follow=%t: RunLog error %v", follow, err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		if !follow {
+			expected := []string{
+				"[pod/mysql-2-a/mysql] started mysql-2-a",
+				"[pod/mysql-2-a/mysql] ready mysql-2-a",
+				"[pod/mysql-2-b/mysql] started mysql-2-b",
+				"[pod/mysql-2-b/mysql] ready mysql-2-b",
+			}
+			if !reflect.DeepEqual(lines, expected) {
+				t.Errorf("unexpected logs:\n%s", out.String())
+			}
+			continue
+		}
+		sort.Strings(lines)
+		if len(lines) != 4 || lines[0] != "[pod/mysql-2-a/mysql] ready mysql-2-a" || lines[3] != "[pod/mysql-2-b/mysql] started mysql-2-b" {
+			t.Errorf("unexpected logs:\n%s", out.String())
+		}
+
+		o.MaxFollowConcurrency = 1
+		o.Object = dc
+		if err := o.RunLog(); err == nil || !strings.Contains(err.Error(), "--max-log-requests") {
+			t.Errorf("expected the concurrency limit to be enforced, got %v", err)
+		}
+	}
+}
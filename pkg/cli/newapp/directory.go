@@ -0,0 +1,87 @@
+package newapp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// directoryOutputPrefix selects writing the generated objects to a directory, as in -o directory=DIR.
+const directoryOutputPrefix = "directory="
+
+// kustomizationFile is the name of the kustomization written with the generated objects.
+const kustomizationFile = "kustomization.yaml"
+
+// parseDirectoryOutput returns the directory of an -o directory=DIR output format.
+func parseDirectoryOutput(output string) (string, bool, error) {
+	if !strings.HasPrefix(output, directoryOutputPrefix) {
+		return "", false, nil
+	}
+	dir := strings.TrimPrefix(output, directoryOutputPrefix)
+	if len(dir) == 0 {
+		return "", false, fmt.Errorf("-o %sDIR requires a directory", directoryOutputPrefix)
+	}
+	return dir, true, nil
+}
+
+// writeDirectory writes each object to its own <kind>-<name>.yaml file in dir,
+// along with a kustomization.yaml that lists the files as resources, so the
+// directory can be applied with 'oc apply -k' or committed to a GitOps repository.
+func writeDirectory(dir string, objects []runtime.Object, out io.Writer) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	printer := &printers.YAMLPrinter{}
+	seen := map[string]int{}
+	var files []string
+	for _, obj := range objects {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		if len(kind) == 0 {
+			return fmt.Errorf("unable to write %s without a kind", accessor.GetName())
+		}
+		base := strings.ToLower(kind)
+		if name := accessor.GetName(); len(name) > 0 {
+			base = fmt.Sprintf("%s-%s", base, name)
+		}
+		seen[base]++
+		if n := seen[base]; n > 1 {
+			base = fmt.Sprintf("%s-%d", base, n)
+		}
+		file := base + ".yaml"
+
+		buf := &bytes.Buffer{}
+		if err := printer.PrintObj(obj, buf); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, file), buf.Bytes(), 0644); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, filepath.Join(dir, file))
+		files = append(files, file)
+	}
+
+	kustomization := &bytes.Buffer{}
+	fmt.Fprintln(kustomization, "apiVersion: kustomize.config.k8s.io/v1beta1")
+	fmt.Fprintln(kustomization, "kind: Kustomization")
+	fmt.Fprintln(kustomization, "resources:")
+	for _, file := range files {
+		fmt.Fprintf(kustomization, "- %s\n", file)
+	}
+	if err := os.WriteFile(filepath.Join(dir, kustomizationFile), kustomization.Bytes(), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintln(out, filepath.Join(dir, kustomizationFile))
+	return nil
+}
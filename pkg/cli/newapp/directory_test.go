@@ -0,0 +1,76 @@
+package newapp
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestParseDirectoryOutput(t *testing.T) {
+	if dir, ok, err := parseDirectoryOutput("directory=out/app"); err != nil || !ok || dir != "out/app" {
+		t.Errorf("unexpected result: %q %t %v", dir, ok, err)
+	}
+	if _, ok, err := parseDirectoryOutput("yaml"); err != nil || ok {
+		t.Errorf("unexpected result: %t %v", ok, err)
+	}
+	if _, _, err := parseDirectoryOutput("directory="); err == nil {
+		t.Errorf("expected an error without a directory")
+	}
+}
+
+func TestWriteDirectory(t *testing.T) {
+	object := func(apiVersion, kind, name string) runtime.Object {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": name},
+		}}
+	}
+	objects := []runtime.Object{
+		object("image.openshift.io/v1", "ImageStream", "ruby"),
+		object("build.openshift.io/v1", "BuildConfig", "ruby"),
+		object("apps/v1", "Deployment", "ruby"),
+		object("v1", "Service", "ruby"),
+		object("v1", "Service", "ruby"),
+	}
+
+	dir := filepath.Join(t.TempDir(), "app")
+	out := &bytes.Buffer{}
+	if err := writeDirectory(dir, objects, out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range []string{"imagestream-ruby.yaml", "buildconfig-ruby.yaml", "deployment-ruby.yaml", "service-ruby.yaml", "service-ruby-2.yaml"} {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Errorf("expected %s to be written: %v", file, err)
+			continue
+		}
+		if !bytes.Contains(data, []byte("name: ruby")) {
+			t.Errorf("unexpected contents of %s:\n%s", file, data)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(dir, kustomizationFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- imagestream-ruby.yaml
+- buildconfig-ruby.yaml
+- deployment-ruby.yaml
+- service-ruby.yaml
+- service-ruby-2.yaml
+`
+	if string(data) != expected {
+		t.Errorf("unexpected kustomization:\n%s", data)
+	}
+	if lines := bytes.Count(out.Bytes(), []byte("\n")); lines != 6 {
+		t.Errorf("expected 6 files to be reported, got:\n%s", out.String())
+	}
+}
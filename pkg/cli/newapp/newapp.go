@@ -77,8 +77,16 @@ var (
 		You may either specify components using the various existing flags or let oc new-app autodetect
 		what kind of components you have provided.
 
+		If the source code contains a devfile.yaml, or one is passed with --devfile, the Dockerfile of
+		the first image component of the devfile is built with the docker strategy and the endpoints of
+		its container components are exposed by the service.
+
 		If you provide source code, a new build will be automatically triggered.
-		You can use 'oc status' to check the progress.`)
+		You can use 'oc status' to check the progress.
+
+		Pass -o directory=DIR to write each generated object to its own file in DIR, along with
+		a kustomization.yaml listing them, instead of creating the objects. The directory can be
+		applied later with 'oc apply -k DIR' or committed to a GitOps repository.`)
 
 	newAppExample = templates.Examples(`
 		# List all local templates and image streams that can be used to create an app
@@ -105,6 +113,12 @@ var (
 		# Create an application from a remote repository using its beta4 branch
 		oc new-app https://github.com/openshift/ruby-hello-world#beta4
 
+		# Create an application from a remote repository that builds the test stage of build/Containerfile
+		oc new-app https://github.com/youruser/yourgitrepo --containerfile=build/Containerfile --target=test
+
+		# Create an application from a remote repository using the image components of a devfile
+		oc new-app https://github.com/youruser/yourgitrepo --devfile=https://example.com/devfile.yaml
+
 		# Create an application based on a stored template, explicitly setting a parameter value
 		oc new-app --template=ruby-helloworld-sample --param=MYSQL_USER=admin
 
@@ -117,6 +131,9 @@ var (
 		# Create an application based on a template file, explicitly setting a parameter value
 		oc new-app --file=./example/myapp/template.json --param=MYSQL_USER=admin
 
+		# Write the objects for an application to the myapp directory as a kustomization instead of creating them
+		oc new-app https://github.com/openshift/ruby-hello-world -o directory=myapp
+
 		# Search all templates, image streams, and container images for the ones that match "ruby"
 		oc new-app --search ruby
 
@@ -168,6 +185,9 @@ type AppOptions struct {
 
 	RESTClientGetter genericclioptions.RESTClientGetter
 
+	// OutputDirectory is set by -o directory=DIR to write the generated objects to DIR.
+	OutputDirectory string
+
 	genericiooptions.IOStreams
 }
 
@@ -271,6 +291,12 @@ func NewCmdNewApplication(f kcmdutil.Factory, streams genericiooptions.IOStreams
 	cmd.Flags().BoolVar(&o.Config.DeploymentConfig, "as-deployment-config", o.Config.DeploymentConfig, "If true create this application as a deployment config, which allows for hooks and custom strategies.")
 	cmd.Flags().StringSliceVar(&o.Config.SourceRepositories, "code", o.Config.SourceRepositories, "Source code to use to build this application.")
 	cmd.Flags().StringVar(&o.Config.ContextDir, "context-dir", o.Config.ContextDir, "Context directory to be used for the build.")
+	cmd.Flags().StringVar(&o.Config.Containerfile, "containerfile", o.Config.Containerfile, "Path of the Containerfile or Dockerfile to build, relative to the source repository or a local file when no source is given. Implies --strategy=docker.")
+	cmd.MarkFlagFilename("containerfile")
+	cmd.Flags().StringArrayVar(&o.Config.BuildArgs, "build-arg", o.Config.BuildArgs, "Specify a key-value pair to pass to Docker during the build.")
+	cmd.Flags().StringVar(&o.Config.Target, "target", o.Config.Target, "Name of the build stage of a multi-stage Dockerfile to build. The Dockerfile up to the end of the stage is added to the build.")
+	cmd.Flags().StringVar(&o.Config.Devfile, "devfile", o.Config.Devfile, "Path or URL of a devfile to build the source code with, in place of any devfile in the repository. Implies --strategy=docker.")
+	cmd.MarkFlagFilename("devfile", "yaml", "yml")
 	cmd.Flags().StringSliceVarP(&o.Config.ImageStreams, "image-stream", "i", o.Config.ImageStreams, "Name of an existing image stream to use to deploy an app.")
 	cmd.Flags().StringSliceVar(&o.Config.DockerImages, "image", o.Config.DockerImages, "Name of a container image to include in the app.  Note:  not specifying a registry or repository means defaults in place for client image pulls are employed.")
 	cmd.Flags().StringSliceVar(&o.Config.DockerImages, "docker-image", o.Config.DockerImages, "")
@@ -314,6 +340,16 @@ func (o *AppOptions) Complete(f kcmdutil.Factory, c *cobra.Command, args []strin
 	o.RESTClientGetter = f
 
 	cmdutil.WarnAboutCommaSeparation(o.ErrOut, o.ObjectGeneratorOptions.Config.TemplateParameters, "--param")
+	if o.PrintFlags.OutputFormat != nil {
+		dir, ok, err := parseDirectoryOutput(*o.PrintFlags.OutputFormat)
+		if err != nil {
+			return kcmdutil.UsageErrorf(c, "%v", err)
+		}
+		if ok {
+			o.OutputDirectory = dir
+			*o.PrintFlags.OutputFormat = "yaml"
+		}
+	}
 	err := o.ObjectGeneratorOptions.Complete(f, c, args)
 	if err != nil {
 		return err
@@ -328,6 +364,9 @@ func (o *AppOptions) RunNewApp() error {
 	out := o.Action.Out
 
 	if config.Querying() {
+		if len(o.OutputDirectory) > 0 {
+			return fmt.Errorf("-o %sDIR cannot be used with --list or --search", directoryOutputPrefix)
+		}
 		result, err := config.RunQuery()
 		if err != nil {
 			return HandleError(err, o.CommandPath, config, TransformRunError)
@@ -396,6 +435,10 @@ func (o *AppOptions) RunNewApp() error {
 		return err
 	}
 
+	if len(o.OutputDirectory) > 0 {
+		return writeDirectory(o.OutputDirectory, result.List.Items, o.Out)
+	}
+
 	if o.Action.ShouldPrint() {
 		// TODO(juanvallejo): this needs to be fixed by updating QueryResult.List to be of type corev1.List
 		printableList := &corev1.List{
@@ -754,6 +797,10 @@ func CompleteAppConfig(config *newcmd.AppConfig, f kcmdutil.Factory, c *cobra.Co
 		return kcmdutil.UsageErrorf(c, "--source-image must be specified when --source-image-path is specified.")
 	}
 
+	if len(config.Devfile) > 0 && config.BinaryBuild {
+		return kcmdutil.UsageErrorf(c, "specifying binary builds and a devfile at the same time is not allowed.")
+	}
+
 	if config.BinaryBuild && config.Strategy == newapp.StrategyPipeline {
 		return kcmdutil.UsageErrorf(c, "specifying binary builds and the pipeline strategy at the same time is not allowed.")
 	}
@@ -761,6 +808,12 @@ func CompleteAppConfig(config *newcmd.AppConfig, f kcmdutil.Factory, c *cobra.Co
 	if len(config.BuildArgs) > 0 && config.Strategy != newapp.StrategyUnspecified && config.Strategy != newapp.StrategyDocker {
 		return kcmdutil.UsageErrorf(c, "Cannot use '--build-arg' without a Docker build")
 	}
+	if len(config.Target) > 0 && config.Strategy != newapp.StrategyUnspecified && config.Strategy != newapp.StrategyDocker {
+		return kcmdutil.UsageErrorf(c, "Cannot use '--target' without a Docker build")
+	}
+	if len(config.Containerfile) > 0 && len(config.Dockerfile) > 0 {
+		return kcmdutil.UsageErrorf(c, "--containerfile and --dockerfile are mutually exclusive.")
+	}
 	return nil
 }
 
@@ -54,6 +54,12 @@ var (
 		# Create a build config using a Dockerfile specified as an argument
 		oc new-build -D $'FROM centos:7\nRUN yum install -y httpd'
 
+		# Create a build config that builds the Containerfile in the local directory from binary input
+		oc new-build --binary --containerfile=Containerfile --name=myapp
+
+		# Create a build config from a remote repository that builds the runtime stage of its Dockerfile
+		oc new-build https://github.com/youruser/yourgitrepo --target=runtime --build-arg=VERSION=1.2
+
 		# Create a build config from a remote repository and add custom environment variables
 		oc new-build https://github.com/openshift/ruby-hello-world -e RACK_ENV=development
 
@@ -148,6 +154,9 @@ func NewCmdNewBuild(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cob
 	cmd.MarkFlagFilename("env-file")
 	cmd.Flags().Var(&o.Config.Strategy, "strategy", "Specify the build strategy to use if you don't want to detect (docker|pipeline|source). NOTICE: the pipeline strategy is deprecated; consider using Jenkinsfiles directly on Jenkins or OpenShift Pipelines.")
 	cmd.Flags().StringVarP(&o.Config.Dockerfile, "dockerfile", "D", o.Config.Dockerfile, "Specify the contents of a Dockerfile to build directly, implies --strategy=docker. Pass '-' to read from STDIN.")
+	cmd.Flags().StringVar(&o.Config.Containerfile, "containerfile", o.Config.Containerfile, "Path of the Containerfile or Dockerfile to build, relative to the source repository or a local file when no source is given. Implies --strategy=docker.")
+	cmd.MarkFlagFilename("containerfile")
+	cmd.Flags().StringVar(&o.Config.Target, "target", o.Config.Target, "Name of the build stage of a multi-stage Dockerfile to build. The Dockerfile up to the end of the stage is added to the build.")
 	cmd.Flags().StringArrayVar(&o.Config.BuildArgs, "build-arg", o.Config.BuildArgs, "Specify a key-value pair to pass to Docker during the build.")
 	cmd.Flags().BoolVar(&o.Config.BinaryBuild, "binary", o.Config.BinaryBuild, "Instead of expecting a source URL, set the build to expect binary contents. Will disable triggers.")
 	cmd.Flags().StringP("labels", "l", "", "Label to set in all generated resources.")
@@ -27,6 +27,13 @@ var (
 		},
 		[]string{"type", "exit_code"},
 	)
+	deadLetterCounts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "observe_dead_letter_counts",
+			Help: "Number of events whose command kept failing after all retries.",
+		},
+		[]string{"type"},
+	)
 	nameExecDurations = prometheus.NewSummaryVec(
 		prometheus.SummaryOpts{
 			Name: "observe_name_exec_durations_milliseconds",
@@ -78,15 +85,50 @@ func exitCodeForCommandError(err error) (int, bool) {
 	return 0, false
 }
 
-func retryCommandError(onExitStatus, times int, fn func() error) error {
-	err := fn()
-	if err != nil && onExitStatus != 0 && times > 0 {
-		if status, ok := exitCodeForCommandError(err); ok {
-			if status == onExitStatus {
-				klog.V(4).Infof("retrying command: %v", err)
-				return retryCommandError(onExitStatus, times-1, fn)
+// retryPolicy controls how failing commands are retried.
+type retryPolicy struct {
+	// onExitStatus, when non-zero, limits retries to commands exiting with that status.
+	onExitStatus int
+	// times is the maximum number of retries.
+	times int
+	// backoff is the delay before the first retry, doubled for every retry after
+	// it up to maxBackoff. When set, commands are retried on any failure unless
+	// onExitStatus is set. When zero, commands are retried immediately and only
+	// if onExitStatus is set.
+	backoff    time.Duration
+	maxBackoff time.Duration
+	// sleep waits between retries, time.Sleep if nil.
+	sleep func(time.Duration)
+}
+
+func (p retryPolicy) shouldRetry(err error) bool {
+	if p.onExitStatus != 0 {
+		status, ok := exitCodeForCommandError(err)
+		return ok && status == p.onExitStatus
+	}
+	return p.backoff > 0
+}
+
+// retryCommandError invokes fn until it succeeds or the policy stops retrying
+// it, and returns the number of attempts with the last error.
+func retryCommandError(p retryPolicy, fn func() error) (int, error) {
+	delay := p.backoff
+	sleep := p.sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	for attempts := 1; ; attempts++ {
+		err := fn()
+		if err == nil || attempts > p.times || !p.shouldRetry(err) {
+			return attempts, err
+		}
+		klog.V(4).Infof("retrying command in %s: %v", delay, err)
+		if delay > 0 {
+			sleep(delay)
+			delay *= 2
+			if p.maxBackoff > 0 && delay > p.maxBackoff {
+				delay = p.maxBackoff
 			}
 		}
 	}
-	return err
 }
@@ -1,6 +1,7 @@
 package observe
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -122,7 +123,25 @@ var (
 		script could make a call to allocate storage on your infrastructure as a
 		service, or register node names in DNS, or set complex firewalls. The more
 		complex your integration, the more important it is to record enough data in the
-		remote system that you can identify when resources on either side are deleted.\
+		remote system that you can identify when resources on either side are deleted.
+
+		For unattended use, --retry-backoff retries a failing command up to --retry-count
+		times with exponentially increasing delays, and --dead-letter records every event
+		whose command still failed as a line of JSON, so it can be inspected or replayed
+		later. Since the events in a dead letter file have not been handled, combine it with
+		--resync-period to periodically reprocess every object.
+
+		Each --jsonpath expression is passed to your command as one more argument after
+		the --template argument, so scripts can use the fields of an object without
+		parsing it. An expression is either a JSONPath template like '{.spec.clusterIP}'
+		or a jq style path like '.metadata.labels.app'. Lists and maps are passed as JSON
+		and multiple matches are separated by spaces.
+
+		With --output=json every event is written to standard output as a line of JSON
+		with its type, the namespace, name and resource version of the object, the
+		arguments and command line, and the object itself when it is available. The output
+		of your command is written to standard error, so standard output can be consumed
+		by a tool like jq.
 	`)
 
 	observeExample = templates.Examples(`
@@ -134,6 +153,15 @@ var (
 
 		# Observe changes to services filtered by a label selector
 		oc observe services -l regist-dns=true --template '{ .spec.clusterIP }' -- register_dns.sh
+
+		# Invoke a script with the clusterIP and the first port of each service as separate arguments
+		oc observe services --jsonpath .spec.clusterIP --jsonpath '.spec.ports[0].port' -- register_dns.sh
+
+		# Retry a failing script with backoff and record events that still fail, resyncing every 10 minutes
+		oc observe services --retry-backoff=5s --retry-count=5 --dead-letter=failed.jsonl --resync-period=10m -- register_dns.sh
+
+		# Write every change to nodes as a line of JSON, including the full object
+		oc observe nodes -o json
 	`)
 )
 
@@ -169,6 +197,10 @@ type ObserveOptions struct {
 	maximumErrors   int
 	retryCount      int
 	retryExitStatus int
+	retryBackoff    time.Duration
+	maxRetryBackoff time.Duration
+	deadLetterPath  string
+	deadLetterOut   io.Writer
 
 	// when to exit or reprocess the list of items
 	once               bool
@@ -178,8 +210,10 @@ type ObserveOptions struct {
 
 	// control the output of the command
 	templates       stringSliceFlag
+	jsonPaths       []string
 	printer         printerWrapper
 	strictTemplates bool
+	jsonEvents      bool
 
 	argumentStore *objectArgumentsStore
 	// knownObjects is nil if we do not need to track deletions
@@ -195,9 +229,10 @@ func NewObserveOptions(streams genericiooptions.IOStreams) *ObserveOptions {
 		}).WithDefaultOutput("jsonpath").WithTypeSetter(scheme.Scheme),
 		IOStreams: streams,
 
-		retryCount:    2,
-		maximumErrors: 20,
-		listenAddr:    ":11251",
+		retryCount:      2,
+		maximumErrors:   20,
+		maxRetryBackoff: 5 * time.Minute,
+		listenAddr:      ":11251",
 	}
 }
 
@@ -231,6 +266,7 @@ func NewCmdObserve(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobr
 	cmd.Flags().VarP(&o.templates, "argument", "a", "Template for the arguments to be passed to each command in the format defined by --output.")
 	cmd.Flags().MarkShorthandDeprecated("a", "and will be removed in a future release. Use --template instead.")
 	cmd.Flags().MarkDeprecated("argument", "and will be removed in a future release. Use --template instead.")
+	cmd.Flags().StringArrayVar(&o.jsonPaths, "jsonpath", o.jsonPaths, "A JSONPath expression, such as '{.spec.clusterIP}' or '.spec.clusterIP', whose value is passed as an additional argument to each command. May be specified multiple times.")
 	cmd.Flags().StringVar(&o.typeEnvVar, "type-env-var", o.typeEnvVar, "The name of an env var to set with the type of event received ('Sync', 'Updated', 'Deleted', 'Added') to the reaction command or --delete.")
 	cmd.Flags().StringVar(&o.objectEnvVar, "object-env-var", o.objectEnvVar, "The name of an env var to serialize the object to when calling the command, optional.")
 
@@ -238,6 +274,9 @@ func NewCmdObserve(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobr
 	cmd.Flags().IntVar(&o.maximumErrors, "maximum-errors", o.maximumErrors, "Exit after this many errors have been detected with. May be set to -1 for no maximum.")
 	cmd.Flags().IntVar(&o.retryExitStatus, "retry-on-exit-code", o.retryExitStatus, "If any command returns this exit code, retry up to --retry-count times.")
 	cmd.Flags().IntVar(&o.retryCount, "retry-count", o.retryCount, "The number of times to retry a failing command before continuing.")
+	cmd.Flags().DurationVar(&o.retryBackoff, "retry-backoff", o.retryBackoff, "If set, retry any failing command up to --retry-count times, waiting this long before the first retry and twice as long before each following one.")
+	cmd.Flags().DurationVar(&o.maxRetryBackoff, "retry-backoff-max", o.maxRetryBackoff, "The longest time to wait between retries of a failing command with --retry-backoff.")
+	cmd.Flags().StringVar(&o.deadLetterPath, "dead-letter", o.deadLetterPath, "Append a line of JSON describing each event whose command still failed after all retries to this file, optional.")
 
 	// control observe program behavior
 	cmd.Flags().BoolVar(&o.once, "once", o.once, "If true, exit with a status code 0 after all current objects have been processed.")
@@ -305,6 +344,12 @@ func (o *ObserveOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args [
 		return err
 	}
 
+	// -o json writes each event as JSON instead of selecting a template format
+	if o.PrintFlags.OutputFormat != nil && *o.PrintFlags.OutputFormat == "json" {
+		o.jsonEvents = true
+		*o.PrintFlags.OutputFormat = "jsonpath"
+	}
+
 	// TODO: Remove in the next release
 	// support backwards compatibility with misspelling of "go-template" output format
 	if o.PrintFlags.OutputFormat != nil && *o.PrintFlags.OutputFormat == "gotemplate" {
@@ -332,15 +377,24 @@ func (o *ObserveOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args [
 	o.PrintFlags.OutputFlagSpecified = func() bool { return true }
 
 	var printer printers.ResourcePrinter
-	if o.PrintFlags.TemplatePrinterFlags.TemplateArgument != nil && len(*o.PrintFlags.TemplatePrinterFlags.TemplateArgument) > 0 {
+	switch {
+	case o.PrintFlags.TemplatePrinterFlags.TemplateArgument != nil && len(*o.PrintFlags.TemplatePrinterFlags.TemplateArgument) > 0:
 		printer, err = o.PrintFlags.ToPrinter()
 		if err != nil {
 			return err
 		}
-	} else {
+	case len(o.jsonPaths) == 0:
 		printer = printers.NewDiscardingPrinter()
 	}
 	o.printer = printerWrapper{printer: printer}
+	allowMissingKeys := o.PrintFlags.TemplatePrinterFlags.AllowMissingKeys == nil || *o.PrintFlags.TemplatePrinterFlags.AllowMissingKeys
+	for _, expression := range o.jsonPaths {
+		expr, err := newArgumentExpression(expression, allowMissingKeys)
+		if err != nil {
+			return err
+		}
+		o.printer.expressions = append(o.printer.expressions, expr)
+	}
 
 	if o.quiet {
 		o.debugOut = io.Discard
@@ -353,7 +407,7 @@ func (o *ObserveOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args [
 	case len(o.nameSyncCommand) > 0:
 		o.argumentStore.keyFn = func() ([]string, error) {
 			var out []byte
-			err := retryCommandError(o.retryExitStatus, o.retryCount, func() error {
+			_, err := retryCommandError(o.retryPolicy(), func() error {
 				c := exec.Command(o.nameSyncCommand[0], o.nameSyncCommand[1:]...)
 				var err error
 				return measureCommandDuration(nameExecDurations, func() error {
@@ -398,6 +452,10 @@ func (o *ObserveOptions) Validate() error {
 		return err
 	}
 
+	if o.retryBackoff < 0 || o.maxRetryBackoff < 0 {
+		return fmt.Errorf("--retry-backoff and --retry-backoff-max may not be negative")
+	}
+
 	return nil
 }
 
@@ -416,6 +474,15 @@ func (o *ObserveOptions) Run() error {
 		lw.namespace = o.namespace
 	}
 
+	if len(o.deadLetterPath) > 0 {
+		f, err := os.OpenFile(o.deadLetterPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("unable to open the dead letter file: %v", err)
+		}
+		o.deadLetterOut = f
+		defer o.closeDeadLetter()
+	}
+
 	// ensure any child processes are reaped if we are running as PID 1
 	proc.StartReaper()
 
@@ -424,6 +491,7 @@ func (o *ObserveOptions) Run() error {
 		prometheus.MustRegister(observeCounts)
 		prometheus.MustRegister(execDurations)
 		prometheus.MustRegister(nameExecDurations)
+		prometheus.MustRegister(deadLetterCounts)
 		errWaitingForSync := fmt.Errorf("waiting for initial sync")
 		healthz.InstallHandler(http.DefaultServeMux, healthz.NamedCheck("ready", func(r *http.Request) error {
 			if !store.HasSynced() {
@@ -447,6 +515,7 @@ func (o *ObserveOptions) Run() error {
 			lock.Lock()
 			defer lock.Unlock()
 			o.dumpMetrics()
+			o.closeDeadLetter()
 			fmt.Fprintf(o.ErrOut, "Shutting down after %s ...\n", o.exitAfterPeriod)
 			os.Exit(0)
 		}()
@@ -636,10 +705,16 @@ func (o *ObserveOptions) calculateArguments(delta cache.Delta) (runtime.Object,
 }
 
 func (o *ObserveOptions) startSync() error {
+	if o.jsonEvents {
+		return writeEvent(o.Out, observeEvent{Time: time.Now().Format(time.RFC3339), Type: "SyncStarted"})
+	}
 	fmt.Fprintf(o.debugOut, "# %s Sync started\n", time.Now().Format(time.RFC3339))
 	return nil
 }
 func (o *ObserveOptions) finishSync() error {
+	if o.jsonEvents {
+		return writeEvent(o.Out, observeEvent{Time: time.Now().Format(time.RFC3339), Type: "SyncEnded"})
+	}
 	fmt.Fprintf(o.debugOut, "# %s Sync ended\n", time.Now().Format(time.RFC3339))
 	return nil
 }
@@ -674,16 +749,39 @@ func (o *ObserveOptions) next(deltaType cache.DeltaType, obj runtime.Object, out
 
 	args = append(args, arguments...)
 
-	if len(command) == 0 {
+	event := observeEvent{
+		Time:            time.Now().Format(time.RFC3339),
+		Type:            outType,
+		Namespace:       m.GetNamespace(),
+		Name:            m.GetName(),
+		ResourceVersion: resourceVersion,
+		Arguments:       arguments,
+	}
+	if len(command) > 0 {
+		event.Command = append([]string{command}, args...)
+	}
+	if len(output) > 0 {
+		event.Object = json.RawMessage(output)
+	}
+	if o.jsonEvents {
+		if err := writeEvent(o.Out, event); err != nil {
+			return err
+		}
+	} else {
 		fmt.Fprintf(o.debugOut, "# %s %s %s\t%s\n", time.Now().Format(time.RFC3339), outType, resourceVersion, printCommandLine(command, args...))
-		return nil
 	}
 
-	fmt.Fprintf(o.debugOut, "# %s %s %s\t%s\n", time.Now().Format(time.RFC3339), outType, resourceVersion, printCommandLine(command, args...))
+	if len(command) == 0 {
+		return nil
+	}
 
 	out, errOut := &newlineTrailingWriter{w: o.Out}, &newlineTrailingWriter{w: o.ErrOut}
+	if o.jsonEvents {
+		// keep standard output a stream of events
+		out.w = o.ErrOut
+	}
 
-	err = retryCommandError(o.retryExitStatus, o.retryCount, func() error {
+	attempts, err := retryCommandError(o.retryPolicy(), func() error {
 		cmd := exec.Command(command, args...)
 		cmd.Stdout = out
 		cmd.Stderr = errOut
@@ -703,11 +801,38 @@ func (o *ObserveOptions) next(deltaType cache.DeltaType, obj runtime.Object, out
 		if code, ok := exitCodeForCommandError(err); ok && code != 0 {
 			err = fmt.Errorf("command %q exited with status code %d", command, code)
 		}
+		if o.deadLetterOut != nil {
+			deadLetterCounts.WithLabelValues(outType).Inc()
+			if writeErr := writeEvent(o.deadLetterOut, deadLetter{observeEvent: event, Error: err.Error(), Attempts: attempts}); writeErr != nil {
+				return fmt.Errorf("unable to write to the dead letter file %s: %v", o.deadLetterPath, writeErr)
+			}
+		}
 		return o.handleCommandError(err)
 	}
 	return nil
 }
 
+func (o *ObserveOptions) retryPolicy() retryPolicy {
+	return retryPolicy{
+		onExitStatus: o.retryExitStatus,
+		times:        o.retryCount,
+		backoff:      o.retryBackoff,
+		maxBackoff:   o.maxRetryBackoff,
+	}
+}
+
+// closeDeadLetter closes the --dead-letter file, reporting errors flushing it to disk.
+func (o *ObserveOptions) closeDeadLetter() {
+	closer, ok := o.deadLetterOut.(io.Closer)
+	if !ok {
+		return
+	}
+	if err := closer.Close(); err != nil {
+		fmt.Fprintf(o.ErrOut, "error: unable to close the dead letter file %s: %v\n", o.deadLetterPath, err)
+	}
+	o.deadLetterOut = nil
+}
+
 func (o *ObserveOptions) handleCommandError(err error) error {
 	if err == nil {
 		return nil
@@ -0,0 +1,271 @@
+package observe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestNextJSONEvents(t *testing.T) {
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "ns", ResourceVersion: "42"}}
+	object := []byte(`{"metadata":{"name":"web"}}`)
+	tests := []struct {
+		name            string
+		eachCommand     []string
+		expected        observeEvent
+		expectedCommand string
+	}{
+		{
+			name: "without a command",
+			expected: observeEvent{
+				Type:            "Updated",
+				Namespace:       "ns",
+				Name:            "web",
+				ResourceVersion: "42",
+				Arguments:       []string{"172.30.0.10", "8080"},
+				Object:          json.RawMessage(object),
+			},
+		},
+		{
+			name:        "with a command",
+			eachCommand: []string{"echo", "observed"},
+			expected: observeEvent{
+				Type:            "Updated",
+				Namespace:       "ns",
+				Name:            "web",
+				ResourceVersion: "42",
+				Command:         []string{"echo", "observed", "ns", "web", "172.30.0.10", "8080"},
+				Arguments:       []string{"172.30.0.10", "8080"},
+				Object:          json.RawMessage(object),
+			},
+			expectedCommand: "observed ns web 172.30.0.10 8080\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if len(test.eachCommand) > 0 {
+				if _, err := exec.LookPath(test.eachCommand[0]); err != nil {
+					t.Skipf("%s is not available: %v", test.eachCommand[0], err)
+				}
+			}
+			out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+			o := NewObserveOptions(genericiooptions.IOStreams{Out: out, ErrOut: errOut})
+			o.jsonEvents = true
+			o.includeNamespace = true
+			o.eachCommand = test.eachCommand
+
+			if err := o.next(cache.Updated, service, object, []string{"172.30.0.10", "8080"}); err != nil {
+				t.Fatal(err)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+			if len(lines) != 1 {
+				t.Fatalf("expected a single line of JSON, got %q", out.String())
+			}
+			var event observeEvent
+			if err := json.Unmarshal([]byte(lines[0]), &event); err != nil {
+				t.Fatal(err)
+			}
+			if len(event.Time) == 0 {
+				t.Errorf("expected the time of the event, got %#v", event)
+			}
+			event.Time = ""
+			if !reflect.DeepEqual(event, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, event)
+			}
+			// the output of the command does not interleave with the events
+			if errOut.String() != test.expectedCommand {
+				t.Errorf("expected the output of the command %q on standard error, got %q", test.expectedCommand, errOut.String())
+			}
+		})
+	}
+}
+
+func TestSyncJSONEvents(t *testing.T) {
+	out := &bytes.Buffer{}
+	o := NewObserveOptions(genericiooptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}})
+	o.jsonEvents = true
+	if err := o.startSync(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.finishSync(); err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, line := range strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n") {
+		var event observeEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatal(err)
+		}
+		types = append(types, event.Type)
+	}
+	if !reflect.DeepEqual(types, []string{"SyncStarted", "SyncEnded"}) {
+		t.Errorf("expected the sync to be reported, got %q", out.String())
+	}
+}
+
+// exitError returns the error of a command exiting with status.
+func exitError(t *testing.T, status int) error {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skipf("sh is not available: %v", err)
+	}
+	err := exec.Command("sh", "-c", fmt.Sprintf("exit %d", status)).Run()
+	if err == nil {
+		t.Fatalf("expected the command to exit with status %d", status)
+	}
+	return err
+}
+
+func TestRetryCommandError(t *testing.T) {
+	exit1, exit2 := exitError(t, 1), exitError(t, 2)
+	tests := []struct {
+		name             string
+		policy           retryPolicy
+		errs             []error
+		expectedAttempts int
+		expectedErr      error
+		expectedDelays   []time.Duration
+	}{
+		{
+			name:             "success",
+			policy:           retryPolicy{times: 2, backoff: time.Second},
+			errs:             []error{nil},
+			expectedAttempts: 1,
+		},
+		{
+			name:             "no retry without backoff or exit status",
+			policy:           retryPolicy{times: 2},
+			errs:             []error{exit1},
+			expectedAttempts: 1,
+			expectedErr:      exit1,
+		},
+		{
+			name:             "immediate retries on the exit status",
+			policy:           retryPolicy{times: 2, onExitStatus: 1},
+			errs:             []error{exit1, exit1, exit1},
+			expectedAttempts: 3,
+			expectedErr:      exit1,
+		},
+		{
+			name:             "another exit status is not retried",
+			policy:           retryPolicy{times: 2, onExitStatus: 1},
+			errs:             []error{exit1, exit2},
+			expectedAttempts: 2,
+			expectedErr:      exit2,
+		},
+		{
+			name:             "succeeds after a retry",
+			policy:           retryPolicy{times: 2, backoff: time.Second},
+			errs:             []error{fmt.Errorf("failed"), nil},
+			expectedAttempts: 2,
+			expectedDelays:   []time.Duration{time.Second},
+		},
+		{
+			name:             "doubling backoff up to the maximum",
+			policy:           retryPolicy{times: 4, backoff: time.Second, maxBackoff: 5 * time.Second},
+			errs:             []error{exit1, exit1, exit1, exit1, exit1},
+			expectedAttempts: 5,
+			expectedErr:      exit1,
+			expectedDelays:   []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var delays []time.Duration
+			test.policy.sleep = func(d time.Duration) { delays = append(delays, d) }
+			calls := 0
+			attempts, err := retryCommandError(test.policy, func() error {
+				err := test.errs[calls]
+				calls++
+				return err
+			})
+			if attempts != test.expectedAttempts || calls != test.expectedAttempts {
+				t.Errorf("expected %d attempts, got %d with %d calls", test.expectedAttempts, attempts, calls)
+			}
+			if err != test.expectedErr {
+				t.Errorf("expected error %v, got %v", test.expectedErr, err)
+			}
+			if !reflect.DeepEqual(delays, test.expectedDelays) {
+				t.Errorf("expected the delays %v, got %v", test.expectedDelays, delays)
+			}
+		})
+	}
+}
+
+func TestNextDeadLetter(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skipf("sh is not available: %v", err)
+	}
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "ns", ResourceVersion: "42"}}
+	deadLetters, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	o := NewObserveOptions(genericiooptions.IOStreams{Out: &bytes.Buffer{}, ErrOut: errOut})
+	o.eachCommand = []string{"sh", "-c", "exit 3"}
+	o.retryExitStatus = 3
+	o.retryCount = 2
+	o.deadLetterOut = deadLetters
+	o.debugOut = io.Discard
+
+	if err := o.next(cache.Updated, service, nil, []string{"172.30.0.10"}); err != nil {
+		t.Fatal(err)
+	}
+	var letter deadLetter
+	if err := json.Unmarshal(deadLetters.Bytes(), &letter); err != nil {
+		t.Fatalf("unable to parse the dead letter %q: %v", deadLetters.String(), err)
+	}
+	letter.Time = ""
+	expected := deadLetter{
+		observeEvent: observeEvent{
+			Type:            "Updated",
+			Namespace:       "ns",
+			Name:            "web",
+			ResourceVersion: "42",
+			Command:         []string{"sh", "-c", "exit 3", "web", "172.30.0.10"},
+			Arguments:       []string{"172.30.0.10"},
+		},
+		Error:    `command "sh" exited with status code 3`,
+		Attempts: 3,
+	}
+	if !reflect.DeepEqual(letter, expected) {
+		t.Errorf("expected the dead letter\n%#v\ngot\n%#v", expected, letter)
+	}
+	if !strings.Contains(errOut.String(), `error: command "sh" exited with status code 3`) {
+		t.Errorf("expected the error to be reported, got %q", errOut.String())
+	}
+
+	// a command that succeeds leaves no dead letter
+	deadLetters.Reset()
+	o.eachCommand = []string{"sh", "-c", "exit 0"}
+	if err := o.next(cache.Updated, service, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if deadLetters.Len() > 0 {
+		t.Errorf("expected no dead letter, got %q", deadLetters.String())
+	}
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "dead-letters.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.deadLetterOut = f
+	o.closeDeadLetter()
+	if _, err := f.Write([]byte("{}\n")); err == nil {
+		t.Errorf("expected the dead letter file to be closed")
+	}
+	if o.deadLetterOut != nil {
+		t.Errorf("expected no dead letter file after closing it")
+	}
+}
@@ -2,16 +2,23 @@ package observe
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/util/jsonpath"
 	"k8s.io/kubectl/pkg/scheme"
 )
 
+// printerWrapper calculates the arguments passed to commands for an object. The
+// printer, if set, produces the first argument and each expression produces one
+// more argument.
 type printerWrapper struct {
-	printer printers.ResourcePrinter
+	printer     printers.ResourcePrinter
+	expressions []argumentExpression
 }
 
 func (p printerWrapper) PrintObj(obj runtime.Object) ([]string, []byte, error) {
@@ -20,11 +27,116 @@ func (p printerWrapper) PrintObj(obj runtime.Object) ([]string, []byte, error) {
 		return nil, nil, err
 	}
 
-	out := bytes.Buffer{}
-	if err := p.printer.PrintObj(obj, &out); err != nil {
-		return nil, nil, err
+	var args []string
+	if p.printer != nil {
+		out := bytes.Buffer{}
+		if err := p.printer.PrintObj(obj, &out); err != nil {
+			return nil, nil, err
+		}
+		args = append(args, out.String())
+	}
+	if len(p.expressions) > 0 {
+		// preserve the formatting of numbers, which would otherwise be read as floats
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		for _, expr := range p.expressions {
+			arg, err := expr.evaluate(value)
+			if err != nil {
+				return nil, nil, err
+			}
+			args = append(args, arg)
+		}
+	}
+	return args, data, nil
+}
+
+// argumentExpression is a --jsonpath expression that produces a single argument.
+type argumentExpression struct {
+	expression string
+	path       *jsonpath.JSONPath
+}
+
+// newArgumentExpression parses a JSONPath template, such as '{.spec.clusterIP}',
+// or a jq style path, such as '.metadata.labels.app', which is treated as the
+// template '{.metadata.labels.app}'.
+func newArgumentExpression(expression string, allowMissingKeys bool) (argumentExpression, error) {
+	template := expression
+	if !strings.Contains(template, "{") {
+		template = "{" + template + "}"
+	}
+	path := jsonpath.New("argument").AllowMissingKeys(allowMissingKeys)
+	if err := path.Parse(template); err != nil {
+		return argumentExpression{}, fmt.Errorf("invalid --jsonpath expression %q: %v", expression, err)
+	}
+	return argumentExpression{expression: expression, path: path}, nil
+}
+
+// evaluate returns the values matched by each part of the expression, separated by
+// spaces, with the text between the parts of a template kept as is. Strings and
+// numbers are formatted as is, while lists and maps are passed as JSON.
+func (e argumentExpression) evaluate(obj interface{}) (string, error) {
+	results, err := e.path.FindResults(obj)
+	if err != nil {
+		return "", fmt.Errorf("unable to evaluate --jsonpath expression %q: %v", e.expression, err)
+	}
+	var arg strings.Builder
+	for _, set := range results {
+		var values []string
+		for _, result := range set {
+			if !result.IsValid() {
+				continue
+			}
+			switch v := result.Interface().(type) {
+			case nil:
+			case string:
+				values = append(values, v)
+			case map[string]interface{}, []interface{}:
+				data, err := json.Marshal(v)
+				if err != nil {
+					return "", err
+				}
+				values = append(values, string(data))
+			default:
+				values = append(values, fmt.Sprint(v))
+			}
+		}
+		arg.WriteString(strings.Join(values, " "))
+	}
+	return arg.String(), nil
+}
+
+// observeEvent is written for each event with --output=json.
+type observeEvent struct {
+	Time            string          `json:"time"`
+	Type            string          `json:"type"`
+	Namespace       string          `json:"namespace,omitempty"`
+	Name            string          `json:"name,omitempty"`
+	ResourceVersion string          `json:"resourceVersion,omitempty"`
+	Command         []string        `json:"command,omitempty"`
+	Arguments       []string        `json:"arguments,omitempty"`
+	Object          json.RawMessage `json:"object,omitempty"`
+}
+
+// deadLetter is written to the --dead-letter file for each event whose command
+// kept failing after all retries.
+type deadLetter struct {
+	observeEvent
+	Error    string `json:"error"`
+	Attempts int    `json:"attempts"`
+}
+
+// writeEvent writes the event as a single line of JSON.
+func writeEvent(w io.Writer, event interface{}) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
 	}
-	return []string{out.String()}, data, nil
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
 }
 
 type newlineTrailingWriter struct {
@@ -0,0 +1,120 @@
+package observe
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+func TestPrinterWrapperExpressions(t *testing.T) {
+	service := &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "ns", Labels: map[string]string{"app": "web"}},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: "172.30.0.10",
+			Ports:     []corev1.ServicePort{{Name: "http", Port: 8080}, {Name: "https", Port: 8443}},
+		},
+	}
+	tests := []struct {
+		name             string
+		template         string
+		expressions      []string
+		allowMissingKeys bool
+		expected         []string
+		expectedError    string
+	}{
+		{
+			name:        "jq style path",
+			expressions: []string{".spec.clusterIP"},
+			expected:    []string{"172.30.0.10"},
+		},
+		{
+			name:        "JSONPath template",
+			expressions: []string{"{.metadata.namespace}/{.metadata.name}"},
+			expected:    []string{"ns/web"},
+		},
+		{
+			name:        "numbers are not formatted as floats",
+			expressions: []string{".spec.ports[0].port"},
+			expected:    []string{"8080"},
+		},
+		{
+			name:        "multiple matches",
+			expressions: []string{".spec.ports[*].name"},
+			expected:    []string{"http https"},
+		},
+		{
+			name:        "range",
+			expressions: []string{"{range .spec.ports[*]}{.name}={.port},{end}"},
+			expected:    []string{"http=8080,https=8443,"},
+		},
+		{
+			name:        "maps as JSON",
+			expressions: []string{".metadata.labels"},
+			expected:    []string{`{"app":"web"}`},
+		},
+		{
+			name:        "after the template argument",
+			template:    "{.metadata.name}",
+			expressions: []string{".spec.clusterIP", ".spec.ports[1].port"},
+			expected:    []string{"web", "172.30.0.10", "8443"},
+		},
+		{
+			name:             "missing key allowed",
+			expressions:      []string{".spec.externalName"},
+			allowMissingKeys: true,
+			expected:         []string{""},
+		},
+		{
+			name:          "missing key",
+			expressions:   []string{".spec.externalName"},
+			expectedError: `unable to evaluate --jsonpath expression ".spec.externalName": externalName is not found`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := printerWrapper{}
+			if len(test.template) > 0 {
+				printer, err := printers.NewJSONPathPrinter(test.template)
+				if err != nil {
+					t.Fatal(err)
+				}
+				p.printer = printer
+			}
+			for _, expression := range test.expressions {
+				expr, err := newArgumentExpression(expression, test.allowMissingKeys)
+				if err != nil {
+					t.Fatal(err)
+				}
+				p.expressions = append(p.expressions, expr)
+			}
+
+			args, data, err := p.PrintObj(service)
+			if len(test.expectedError) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+					t.Fatalf("expected error %q, got %v", test.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(args, test.expected) {
+				t.Errorf("expected arguments %q, got %q", test.expected, args)
+			}
+			if !strings.Contains(string(data), `"clusterIP":"172.30.0.10"`) {
+				t.Errorf("expected the object to be serialized, got %s", data)
+			}
+		})
+	}
+}
+
+func TestNewArgumentExpressionInvalid(t *testing.T) {
+	if _, err := newArgumentExpression(".spec.ports[", false); err == nil || !strings.Contains(err.Error(), `invalid --jsonpath expression ".spec.ports["`) {
+		t.Errorf("expected the expression to be rejected, got %v", err)
+	}
+}
@@ -1,10 +1,13 @@
 package process
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"time"
@@ -53,6 +56,16 @@ var (
 		Process resolves the template on the server, but you may pass --local to parameterize the template
 		locally. When running locally be aware that the version of your client tools will determine what
 		template transformations are supported, rather than the server.
+
+		Parameter files ending in .yaml, .yml, or .json are values files: the keys leading to each value
+		are split into words at dashes, dots, underscores, and camel case humps, and the words are joined
+		by underscores and upper cased to name the parameter the value is set to, so that a
+		database.memoryLimit key sets the DATABASE_MEMORY_LIMIT parameter. Other parameter files, and
+		those with these extensions that only hold KEY=VALUE lines, hold a KEY=VALUE pair per line. Pass --strict to also fail on the parameters the objects of the template
+		do not use, and on the parameters the objects reference but the template does not define.
+
+		Use -o directory=DIR to write each object to its own file in DIR instead of printing a list,
+		which suits committing the processed objects to a repository.
 	`)
 
 	processExample = templates.Examples(`
@@ -76,6 +89,12 @@ var (
 
 		# Convert template.json into a resource list
 		cat template.json | oc process -f -
+
+		# Set the parameters from a values file and fail on unknown or unused parameters
+		oc process -f template.json --param-file=values.yaml --strict
+
+		# Write each object of the template to its own file in the manifests directory
+		oc process -f template.json --local -o directory=manifests
 	`)
 )
 
@@ -92,6 +111,8 @@ type ProcessOptions struct {
 	raw                 bool
 	parameters          bool
 	ignoreUnknownParams bool
+	strict              bool
+	outputDir           string
 	templateName        string
 	paramFile           []string
 	templateParams      []string
@@ -140,7 +161,7 @@ func NewCmdProcess(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobr
 	// edit --output flag description to mention "describe" as an acceptable output format
 	// TODO: add custom PrintFlags printer that does this ^
 	if f := cmd.Flag("output"); f != nil {
-		f.Usage = "Output format. One of: (json, yaml, name, describe, go-template-file, templatefile, template, go-template, jsonpath, jsonpath-file)."
+		f.Usage = "Output format. One of: (json, yaml, name, describe, directory=DIR, go-template-file, templatefile, template, go-template, jsonpath, jsonpath-file)."
 	}
 
 	// point to the original memory address shared between the jsonpath and go-template printer's TemplateArgument field
@@ -151,9 +172,10 @@ func NewCmdProcess(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobr
 	cmd.Flags().StringVarP(&o.filename, "filename", "f", o.filename, "Filename or URL to file to read a template")
 	cmd.MarkFlagFilename("filename", "yaml", "yml", "json")
 	cmd.Flags().StringArrayVarP(&o.templateParams, "param", "p", o.templateParams, "Specify a key-value pair (eg. -p FOO=BAR) to set/override a parameter value in the template.")
-	cmd.Flags().StringArrayVar(&o.paramFile, "param-file", o.paramFile, "File containing template parameter values to set/override in the template.")
+	cmd.Flags().StringArrayVar(&o.paramFile, "param-file", o.paramFile, "File containing template parameter values to set/override in the template. Files ending in .yaml, .yml, or .json are values files with nested keys, unless they hold KEY=VALUE lines.")
 	cmd.MarkFlagFilename("param-file")
 	cmd.Flags().BoolVar(&o.ignoreUnknownParams, "ignore-unknown-parameters", o.ignoreUnknownParams, "If true, will not stop processing if a provided parameter does not exist in the template.")
+	cmd.Flags().BoolVar(&o.strict, "strict", o.strict, "If true, stop processing if a parameter is not used by the objects of the template, or if they reference a parameter that is not defined.")
 	cmd.Flags().BoolVarP(&o.local, "local", "", o.local, "If true process the template locally instead of contacting the server.")
 	cmd.Flags().BoolVarP(&o.parameters, "parameters", "", o.parameters, "If true, do not process but only print available parameters")
 	cmd.Flags().StringVarP(&o.labels, "labels", "l", o.labels, "Label to set in all resources for this template")
@@ -200,6 +222,14 @@ func (p *processPrinter) PrintObj(obj runtime.Object, out io.Writer) error {
 
 func (o *ProcessOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
 	o.outputFormat = kcmdutil.GetFlagString(cmd, "output")
+	if dir, ok := strings.CutPrefix(o.outputFormat, "directory="); ok {
+		if len(dir) == 0 {
+			return kcmdutil.UsageErrorf(cmd, "-o directory=DIR requires a directory")
+		}
+		o.outputDir = dir
+		o.outputFormat = "yaml"
+		*o.PrintFlags.OutputFormat = "yaml"
+	}
 
 	o.Printer = &processPrinter{
 		printFlags:   o.PrintFlags,
@@ -292,6 +322,10 @@ func (o *ProcessOptions) Validate(cmd *cobra.Command) error {
 		}
 	}
 
+	if o.strict && o.ignoreUnknownParams {
+		return kcmdutil.UsageErrorf(cmd, "The --strict flag fails on unknown parameters, can't be used with --ignore-unknown-parameters")
+	}
+
 	if len(o.templateName) > 0 && o.local {
 		return kcmdutil.UsageErrorf(cmd, "You may only specify a local template file via -f when running this command with --local")
 	}
@@ -302,14 +336,26 @@ func (o *ProcessOptions) Validate(cmd *cobra.Command) error {
 // RunProcess contains all the necessary functionality for the OpenShift cli process command
 func (o *ProcessOptions) RunProcess() error {
 	duplicatedKeys := sets.NewString()
-	params, paramErr := app.ParseAndCombineEnvironment(o.templateParams, o.paramFile, o.In, func(key, file string) error {
+	dupFn := func(key, file string) error {
 		if file == "" {
 			duplicatedKeys.Insert(key)
 		} else {
 			fmt.Fprintf(o.ErrOut, "warning: Template parameter %q already defined, ignoring value from file %q\n", key, file)
 		}
 		return nil
-	})
+	}
+	params, paramErr := app.ParseAndCombineEnvironment(o.templateParams, nil, o.In, dupFn)
+	// the files are read in order, so that the first file setting a parameter wins
+	for _, file := range o.paramFile {
+		if paramErr != nil {
+			break
+		}
+		var fileParams app.Environment
+		fileParams, paramErr = loadParamFile(file, o.In)
+		for _, key := range params.AddIfNotPresent(fileParams) {
+			dupFn(key, file)
+		}
+	}
 	if len(duplicatedKeys) != 0 {
 		return o.usageErrorFn(fmt.Sprintf("The following parameters were provided more than once: %s", strings.Join(duplicatedKeys.List(), ", ")))
 	}
@@ -414,6 +460,15 @@ func (o *ProcessOptions) RunProcess() error {
 	if errs := injectUserVars(params, obj, o.ignoreUnknownParams); errs != nil {
 		return kerrors.NewAggregate(errs)
 	}
+	if o.strict {
+		errs, err := strictParameterErrors(obj)
+		if err != nil {
+			return err
+		}
+		if len(errs) > 0 {
+			return kerrors.NewAggregate(errs)
+		}
+	}
 
 	resultObj := obj
 	resultObj, err := o.templateProcessor(obj)
@@ -425,21 +480,22 @@ func (o *ProcessOptions) RunProcess() error {
 		return o.Printer.PrintObj(resultObj, o.Out)
 	}
 
+	if len(o.outputDir) > 0 {
+		objs, err := decodeObjects(resultObj)
+		if err != nil {
+			return err
+		}
+		return writeObjectsToDir(o.outputDir, objs, o.Out)
+	}
+
 	// the name printer does not accept object lists, so re-use
 	// the print loop used for --raw printing instead.
 	if o.outputFormat == "name" || o.raw {
-		for _, obj := range resultObj.Objects {
-			objToPrint := obj.Object
-
-			if objToPrint == nil {
-				converted, err := runtime.Decode(unstructured.UnstructuredJSONScheme, obj.Raw)
-				if err != nil {
-					return err
-				}
-
-				objToPrint = converted
-			}
-
+		objs, err := decodeObjects(resultObj)
+		if err != nil {
+			return err
+		}
+		for _, objToPrint := range objs {
 			if err := o.Printer.PrintObj(objToPrint, o.Out); err != nil {
 				return err
 			}
@@ -454,6 +510,57 @@ func (o *ProcessOptions) RunProcess() error {
 	}, o.Out)
 }
 
+// decodeObjects returns the objects of the processed template, decoding those that are raw.
+func decodeObjects(t *templatev1.Template) ([]runtime.Object, error) {
+	objs := make([]runtime.Object, 0, len(t.Objects))
+	for _, obj := range t.Objects {
+		objToPrint := obj.Object
+
+		if objToPrint == nil {
+			converted, err := runtime.Decode(unstructured.UnstructuredJSONScheme, obj.Raw)
+			if err != nil {
+				return nil, err
+			}
+
+			objToPrint = converted
+		}
+		objs = append(objs, objToPrint)
+	}
+	return objs, nil
+}
+
+// writeObjectsToDir writes each object as YAML to a KIND-NAME.yaml file of the directory, and
+// prints the path of each file written.
+func writeObjectsToDir(dir string, objs []runtime.Object, out io.Writer) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	written := map[string]bool{}
+	for _, obj := range objs {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		name := strings.ToLower(fmt.Sprintf("%s-%s.yaml", kind, accessor.GetName()))
+		if written[name] {
+			return fmt.Errorf("more than one object of the template is written to %s", filepath.Join(dir, name))
+		}
+		written[name] = true
+
+		buf := &bytes.Buffer{}
+		if err := (&printers.YAMLPrinter{}).PrintObj(obj, buf); err != nil {
+			return err
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, path)
+	}
+	return nil
+}
+
 // injectUserVars injects user specified variables into the Template
 func injectUserVars(values app.Environment, t *templatev1.Template, ignoreUnknownParameters bool) []error {
 	var errors []error
@@ -1,10 +1,18 @@
 package process
 
 import (
+	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
 	templatev1 "github.com/openshift/api/template/v1"
+	"github.com/openshift/oc/pkg/helpers/newapp/app"
 )
 
 func TestInjectUserVars(t *testing.T) {
@@ -41,3 +49,153 @@ func TestInjectUserVars(t *testing.T) {
 			"parameter_foo_bar_2", "value_foo_bar_2", template.Parameters[1].Name, template.Parameters[1].Value)
 	}
 }
+
+func TestParseValues(t *testing.T) {
+	values, err := parseValues([]byte(`
+appName: demo
+database:
+  memoryLimit: 1Gi
+  user-name: admin
+  replicas: 3
+HTTPPort: 8080
+TLS_ENABLED: true
+empty:
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := app.Environment{
+		"APP_NAME":              "demo",
+		"DATABASE_MEMORY_LIMIT": "1Gi",
+		"DATABASE_USER_NAME":    "admin",
+		"DATABASE_REPLICAS":     "3",
+		"HTTP_PORT":             "8080",
+		"TLS_ENABLED":           "true",
+		"EMPTY":                 "",
+	}
+	if !reflect.DeepEqual(expected, values) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+
+	for _, invalid := range []string{
+		"names: [a, b]",
+		"- a",
+		"database:\n  user: a\nDATABASE_USER: b",
+	} {
+		if _, err := parseValues([]byte(invalid)); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestStrictParameterErrors(t *testing.T) {
+	template := &templatev1.Template{
+		Message: "the password is ${PASSWORD}",
+		Parameters: []templatev1.Parameter{
+			{Name: "NAME"},
+			{Name: "REPLICAS"},
+			{Name: "PASSWORD"},
+			{Name: "UNUSED"},
+		},
+		Objects: []runtime.RawExtension{
+			{Raw: []byte(`{"kind":"Deployment","metadata":{"name":"${NAME}"},"spec":{"replicas":"${{REPLICAS}}","image":"${IMAGE}"}}`)},
+		},
+	}
+	errs, err := strictParameterErrors(template)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		`parameter "UNUSED" is not used by any object of the template`,
+		`the objects of the template reference the undefined parameter "IMAGE"`,
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, errs)
+	}
+	for i := range expected {
+		if errs[i].Error() != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], errs[i])
+		}
+	}
+}
+
+func TestWriteObjectsToDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "manifests")
+	objs := []runtime.Object{
+		&unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "demo"}}},
+		&unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "Service", "metadata": map[string]interface{}{"name": "demo"}}},
+	}
+	out := &bytes.Buffer{}
+	if err := writeObjectsToDir(dir, objs, out); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "configmap-demo.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: demo\n"; string(data) != expected {
+		t.Errorf("expected %q, got %q", expected, string(data))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "service-demo.yaml")); err != nil {
+		t.Error(err)
+	}
+
+	if err := writeObjectsToDir(dir, append(objs, objs[0]), out); err == nil {
+		t.Errorf("expected an error when two objects are written to the same file")
+	}
+}
+
+func TestLoadParamFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		expected app.Environment
+	}{
+		{
+			name:     "values file",
+			filename: "values.yaml",
+			content:  "database:\n  user: admin\nREPLICAS: 3\n",
+			expected: app.Environment{"DATABASE_USER": "admin", "REPLICAS": "3"},
+		},
+		{
+			name:     "json values file",
+			filename: "values.json",
+			content:  `{"database": {"user": "admin"}}`,
+			expected: app.Environment{"DATABASE_USER": "admin"},
+		},
+		{
+			name:     "environment file named like a values file",
+			filename: "params.yaml",
+			content:  "# database\nDATABASE_USER=admin\n\nexport MESSAGE=hello: world\n",
+			expected: app.Environment{"DATABASE_USER": "admin", "MESSAGE": "hello: world"},
+		},
+		{
+			name:     "json environment file",
+			filename: "params.json",
+			content:  "DATABASE_USER=admin\n",
+			expected: app.Environment{"DATABASE_USER": "admin"},
+		},
+		{
+			name:     "environment file",
+			filename: "params.env",
+			content:  "DATABASE_USER=admin\n",
+			expected: app.Environment{"DATABASE_USER": "admin"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), test.filename)
+			if err := os.WriteFile(filename, []byte(test.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			values, err := loadParamFile(filename, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(test.expected, values) {
+				t.Errorf("expected %v, got %v", test.expected, values)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package process
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+
+	templatev1 "github.com/openshift/api/template/v1"
+	"github.com/openshift/oc/pkg/helpers/newapp/app"
+)
+
+// environmentLine matches the KEY=VALUE lines of an environment file.
+var environmentLine = regexp.MustCompile(`^\s*(export\s+)?[A-Za-z_][A-Za-z0-9_.]*\s*=`)
+
+// loadParamFile reads the parameter values of a --param-file. A file ending in .yaml, .yml, or
+// .json is a values file, the keys of which are flattened into parameter names, unless all its
+// lines are KEY=VALUE lines; other files are environment files.
+func loadParamFile(filename string, stdin io.Reader) (app.Environment, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return app.LoadEnvironmentFile(filename, stdin)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if isEnvironmentData(data) {
+		return app.LoadEnvironmentFile(filename, stdin)
+	}
+	values, err := parseValues(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the values file %q: %v", filename, err)
+	}
+	return values, nil
+}
+
+// isEnvironmentData returns whether all the lines of data, except for blank lines and comments,
+// are KEY=VALUE lines.
+func isEnvironmentData(data []byte) bool {
+	assignments := 0
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if len(trimmed) == 0 || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if !environmentLine.MatchString(line) {
+			return false
+		}
+		assignments++
+	}
+	return assignments > 0
+}
+
+func parseValues(data []byte) (app.Environment, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	var root interface{}
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	// keep numbers as written rather than as floats
+	decoder.UseNumber()
+	if err := decoder.Decode(&root); err != nil {
+		return nil, err
+	}
+	values := app.Environment{}
+	if root == nil {
+		return values, nil
+	}
+	fields, ok := root.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the values must be a mapping of keys to values")
+	}
+	if err := flattenValues(values, map[string]string{}, nil, fields); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// flattenValues flattens the nested keys of a values file into parameter names: the keys from the
+// root to a value are split into words at dashes, dots, underscores, and camel case humps, and the
+// words are joined by underscores and upper cased. For instance database.memoryLimit sets the
+// DATABASE_MEMORY_LIMIT parameter, and so does DATABASE_MEMORY_LIMIT at the root.
+func flattenValues(values app.Environment, paths map[string]string, keys []string, fields map[string]interface{}) error {
+	names := make([]string, 0, len(fields))
+	for key := range fields {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	for _, key := range names {
+		path := append(append([]string{}, keys...), key)
+		pathString := strings.Join(path, ".")
+		var value string
+		switch t := fields[key].(type) {
+		case map[string]interface{}:
+			if err := flattenValues(values, paths, path, t); err != nil {
+				return err
+			}
+			continue
+		case []interface{}:
+			return fmt.Errorf("%s: lists are not supported, set a string instead", pathString)
+		case nil:
+		case string:
+			value = t
+		case json.Number:
+			value = t.String()
+		case bool:
+			value = strconv.FormatBool(t)
+		default:
+			return fmt.Errorf("%s: unsupported value %v", pathString, t)
+		}
+		name := parameterName(path)
+		if len(name) == 0 {
+			return fmt.Errorf("%s: not a valid parameter name", pathString)
+		}
+		if other, exists := paths[name]; exists {
+			return fmt.Errorf("both %s and %s set the parameter %s", other, pathString, name)
+		}
+		paths[name] = pathString
+		values[name] = value
+	}
+	return nil
+}
+
+// parameterName returns the name of the parameter set by the keys leading to a value.
+func parameterName(keys []string) string {
+	words := []string{}
+	for _, key := range keys {
+		runes := []rune(key)
+		word := []rune{}
+		flush := func() {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = []rune{}
+			}
+		}
+		for i, r := range runes {
+			switch {
+			case r == '-' || r == '.' || r == '_':
+				flush()
+				continue
+			case unicode.IsUpper(r) && i > 0:
+				previous := runes[i-1]
+				// split fooBar and foo2Bar, and HTTPPort before the P of Port
+				if unicode.IsLower(previous) || unicode.IsDigit(previous) ||
+					(unicode.IsUpper(previous) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+					flush()
+				}
+			}
+			word = append(word, unicode.ToUpper(r))
+		}
+		flush()
+	}
+	return strings.Join(words, "_")
+}
+
+// parameterReference matches the ${NAME} and ${{NAME}} references to parameters.
+var parameterReference = regexp.MustCompile(`\$\{\{?([a-zA-Z0-9_]+)\}?\}`)
+
+// strictParameterErrors returns an error for each parameter of the template neither its objects nor
+// its message reference, and for each reference to a parameter the template does not define.
+func strictParameterErrors(t *templatev1.Template) ([]error, error) {
+	referenced := sets.NewString()
+	for _, match := range parameterReference.FindAllStringSubmatch(t.Message, -1) {
+		referenced.Insert(match[1])
+	}
+	for _, obj := range t.Objects {
+		data := obj.Raw
+		if len(data) == 0 && obj.Object != nil {
+			var err error
+			if data, err = json.Marshal(obj.Object); err != nil {
+				return nil, err
+			}
+		}
+		for _, match := range parameterReference.FindAllSubmatch(data, -1) {
+			referenced.Insert(string(match[1]))
+		}
+	}
+
+	defined := sets.NewString()
+	var errs []error
+	for _, param := range t.Parameters {
+		defined.Insert(param.Name)
+		if !referenced.Has(param.Name) {
+			errs = append(errs, fmt.Errorf("parameter %q is not used by any object of the template", param.Name))
+		}
+	}
+	for _, name := range referenced.Difference(defined).List() {
+		errs = append(errs, fmt.Errorf("the objects of the template reference the undefined parameter %q", name))
+	}
+	return errs, nil
+}
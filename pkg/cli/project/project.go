@@ -17,6 +17,7 @@ import (
 	"k8s.io/client-go/rest"
 	kclientcmd "k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/klog/v2"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/completion"
 	"k8s.io/kubectl/pkg/util/templates"
@@ -43,6 +44,9 @@ type ProjectOptions struct {
 	// SkipAccessValidation means that if a specific name is requested, don't bother checking for access to the project
 	SkipAccessValidation bool
 
+	// RecentProjectsDir is the directory the projects switched to are recorded in, for 'oc projects --recent'
+	RecentProjectsDir string
+
 	genericiooptions.IOStreams
 }
 
@@ -72,8 +76,9 @@ var (
 
 func NewProjectOptions(streams genericiooptions.IOStreams) *ProjectOptions {
 	return &ProjectOptions{
-		IOStreams:   streams,
-		PathOptions: kclientcmd.NewDefaultPathOptions(),
+		IOStreams:         streams,
+		PathOptions:       kclientcmd.NewDefaultPathOptions(),
+		RecentProjectsDir: DefaultRecentProjectsDir(),
 	}
 }
 
@@ -244,7 +249,7 @@ func (o ProjectOptions) Run() error {
 						msg = fmt.Sprintf("A project named %q does not exist on %q.", argument, clientCfg.Host)
 					}
 
-					projects, err := GetProjects(client, kubeclient)
+					projects, err := GetProjects(client, kubeclient, metav1.ListOptions{})
 					if err == nil {
 						switch len(projects) {
 						case 0:
@@ -294,6 +299,9 @@ func (o ProjectOptions) Run() error {
 	if err := kclientcmd.ModifyConfig(o.PathOptions, config, true); err != nil {
 		return err
 	}
+	if err := RecordRecentProject(o.RecentProjectsDir, clientCfg.Host, namespaceInUse); err != nil {
+		klog.V(4).Infof("Unable to record project %q as recently used: %v", namespaceInUse, err)
+	}
 
 	if o.DisplayShort {
 		fmt.Fprintln(o.Out, namespaceInUse)
@@ -351,8 +359,10 @@ func ConfirmProjectAccess(currentProject string, projectClient projectv1client.P
 	return projectErr
 }
 
-func GetProjects(projectClient projectv1client.ProjectV1Interface, kClient corev1client.CoreV1Interface) ([]projectv1.Project, error) {
-	projects, err := projectClient.Projects().List(context.TODO(), metav1.ListOptions{})
+// GetProjects lists the projects the user has access to, or the namespaces on
+// Kubernetes, matching the label and field selectors of the options.
+func GetProjects(projectClient projectv1client.ProjectV1Interface, kClient corev1client.CoreV1Interface, options metav1.ListOptions) ([]projectv1.Project, error) {
+	projects, err := projectClient.Projects().List(context.TODO(), options)
 	if err == nil {
 		return projects.Items, nil
 	}
@@ -361,7 +371,7 @@ func GetProjects(projectClient projectv1client.ProjectV1Interface, kClient corev
 		return nil, err
 	}
 
-	namespaces, err := kClient.Namespaces().List(context.TODO(), metav1.ListOptions{})
+	namespaces, err := kClient.Namespaces().List(context.TODO(), options)
 	if err != nil {
 		return nil, err
 	}
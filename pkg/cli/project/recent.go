@@ -0,0 +1,99 @@
+package project
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"k8s.io/client-go/util/homedir"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// recentProjectsFile is the name of the file the recently used projects are stored in.
+	recentProjectsFile = "recent-projects.json"
+	// maxRecentProjects is the number of recently used projects remembered per server.
+	maxRecentProjects = 20
+)
+
+// DefaultRecentProjectsDir returns the directory the recently used projects are
+// stored in, next to the other files oc caches under $KUBECACHEDIR.
+func DefaultRecentProjectsDir() string {
+	if kcd := os.Getenv("KUBECACHEDIR"); kcd != "" {
+		return filepath.Join(kcd, "oc")
+	}
+	return filepath.Join(homedir.HomeDir(), ".kube", "cache", "oc")
+}
+
+// recentProjects maps a server URL to the projects used on it, most recent first.
+type recentProjects map[string][]string
+
+func loadRecentProjects(dir string) (recentProjects, error) {
+	data, err := os.ReadFile(filepath.Join(dir, recentProjectsFile))
+	if os.IsNotExist(err) {
+		return recentProjects{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	recent := recentProjects{}
+	if err := json.Unmarshal(data, &recent); err != nil {
+		return nil, err
+	}
+	return recent, nil
+}
+
+// RecentProjects returns the projects last switched to on the server, most
+// recent first. An unreadable file is treated as if no project had been used.
+func RecentProjects(dir, server string) []string {
+	if len(dir) == 0 {
+		return nil
+	}
+	recent, err := loadRecentProjects(dir)
+	if err != nil {
+		klog.V(4).Infof("Unable to read the recently used projects: %v", err)
+		return nil
+	}
+	return recent[server]
+}
+
+// RecordRecentProject moves the project to the front of the projects recently
+// used on the server.
+func RecordRecentProject(dir, server, name string) error {
+	if len(dir) == 0 || len(name) == 0 {
+		return nil
+	}
+	recent, err := loadRecentProjects(dir)
+	if err != nil {
+		// start over rather than failing to switch project because of a corrupted file
+		recent = recentProjects{}
+	}
+	names := []string{name}
+	for _, existing := range recent[server] {
+		if existing != name && len(names) < maxRecentProjects {
+			names = append(names, existing)
+		}
+	}
+	recent[server] = names
+
+	data, err := json.Marshal(recent)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, recentProjectsFile+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, recentProjectsFile))
+}
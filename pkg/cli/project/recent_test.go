@@ -0,0 +1,121 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRecordRecentProject(t *testing.T) {
+	const server, other = "https://api.example.com:6443", "https://api.other.com:6443"
+	// projects returns the names of the projects from first to last, in that order
+	projects := func(first, last int) []string {
+		var names []string
+		for i := first; i != last; {
+			names = append(names, fmt.Sprintf("project-%d", i))
+			if i < last {
+				i++
+			} else {
+				i--
+			}
+		}
+		return append(names, fmt.Sprintf("project-%d", last))
+	}
+	tests := []struct {
+		name     string
+		existing string
+		record   []string
+		server   string
+		expected []string
+	}{
+		{
+			name:     "first project",
+			record:   []string{"a"},
+			expected: []string{"a"},
+		},
+		{
+			name:     "most recent first",
+			record:   []string{"a", "b", "c"},
+			expected: []string{"c", "b", "a"},
+		},
+		{
+			name:     "moved to the front",
+			record:   []string{"a", "b", "c", "a"},
+			expected: []string{"a", "c", "b"},
+		},
+		{
+			name:     "truncated",
+			record:   projects(1, maxRecentProjects+5),
+			expected: projects(maxRecentProjects+5, 6),
+		},
+		{
+			name:     "other server",
+			existing: `{"https://api.other.com:6443":["x"]}`,
+			record:   []string{"a"},
+			server:   other,
+			expected: []string{"x"},
+		},
+		{
+			name:     "corrupted file",
+			existing: `{"https://api.example.com:6443":`,
+			record:   []string{"a"},
+			expected: []string{"a"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "oc")
+			if len(test.existing) > 0 {
+				if err := os.MkdirAll(dir, 0700); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, recentProjectsFile), []byte(test.existing), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, name := range test.record {
+				if err := RecordRecentProject(dir, server, name); err != nil {
+					t.Fatal(err)
+				}
+			}
+			readServer := server
+			if len(test.server) > 0 {
+				readServer = test.server
+			}
+			if recent := RecentProjects(dir, readServer); !reflect.DeepEqual(recent, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, recent)
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || entries[0].Name() != recentProjectsFile {
+				t.Errorf("expected only %s to be written, got %v", recentProjectsFile, entries)
+			}
+		})
+	}
+}
+
+func TestRecentProjects(t *testing.T) {
+	dir := t.TempDir()
+	if recent := RecentProjects(dir, "https://api.example.com:6443"); recent != nil {
+		t.Errorf("expected no recent projects without a file, got %v", recent)
+	}
+	if err := os.WriteFile(filepath.Join(dir, recentProjectsFile), []byte("not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if recent := RecentProjects(dir, "https://api.example.com:6443"); recent != nil {
+		t.Errorf("expected an unreadable file to be ignored, got %v", recent)
+	}
+
+	// without a directory nothing is recorded
+	if err := RecordRecentProject("", "https://api.example.com:6443", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if recent := RecentProjects("", "https://api.example.com:6443"); recent != nil {
+		t.Errorf("expected no recent projects without a directory, got %v", recent)
+	}
+}
@@ -2,11 +2,11 @@ package projects
 
 import (
 	"fmt"
-	"sort"
 
 	"github.com/spf13/cobra"
 
 	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
@@ -34,12 +34,21 @@ type ProjectsOptions struct {
 	DisplayShort bool
 	Args         []string
 
+	// Search is matched against the name and display name of the projects
+	Search        string
+	Selector      string
+	FieldSelector string
+	// Recent lists the projects most recently switched to first
+	Recent            bool
+	RecentProjectsDir string
+
 	genericiooptions.IOStreams
 }
 
 func NewProjectsOptions(streams genericiooptions.IOStreams) *ProjectsOptions {
 	return &ProjectsOptions{
-		IOStreams: streams,
+		IOStreams:         streams,
+		RecentProjectsDir: ocproject.DefaultRecentProjectsDir(),
 	}
 }
 
@@ -60,12 +69,23 @@ var (
 	projectsLong = templates.LongDesc(`
 		Display information about the current active project and existing projects on the server.
 
+		On clusters with many projects, --selector and --field-selector narrow down the projects the
+		server returns, and --search only displays those whose name or display name contain the
+		characters of the search term in the same order, best matches first. With --recent, the
+		projects you last switched to with the 'project' command are listed first.
+
 		For advanced configuration, or to manage the contents of your config file, use the 'config'
 		command.`)
 
 	projectsExample = templates.Examples(`
 		# List all projects
 		oc projects
+
+		# List the projects whose name or display name match "payments", best matches first
+		oc projects --search=payments
+
+		# List the projects of a team, the most recently used first
+		oc projects -l team=billing --recent
 	`)
 )
 
@@ -84,6 +104,10 @@ func NewCmdProjects(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cob
 		},
 	}
 	cmd.Flags().BoolVarP(&o.DisplayShort, "short", "q", false, "If true, display only the project names")
+	cmd.Flags().StringVar(&o.Search, "search", o.Search, "Only display the projects whose name or display name fuzzy match this term, best matches first.")
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().StringVar(&o.FieldSelector, "field-selector", o.FieldSelector, "Selector (field query) to filter on, supports '=', '==', and '!='.(e.g. --field-selector metadata.name=myproject)")
+	cmd.Flags().BoolVar(&o.Recent, "recent", o.Recent, "If true, list the projects most recently switched to with the 'project' command first.")
 
 	return cmd
 }
@@ -147,12 +171,22 @@ func (o ProjectsOptions) Run() error {
 	}
 
 	var msg string
-	projects, err := ocproject.GetProjects(client, o.KubeClient)
+	projects, err := ocproject.GetProjects(client, o.KubeClient, metav1.ListOptions{LabelSelector: o.Selector, FieldSelector: o.FieldSelector})
 	if err == nil {
+		var scores map[string]int
+		if len(o.Search) > 0 {
+			projects, scores = searchProjects(projects, o.Search)
+		}
+		filtered := len(o.Search) > 0 || len(o.Selector) > 0 || len(o.FieldSelector) > 0
+
 		switch len(projects) {
 		case 0:
 			if !o.DisplayShort {
-				msg += "You are not a member of any projects. You can request a project to be created with the 'new-project' command."
+				if filtered {
+					msg += "No projects match the search."
+				} else {
+					msg += "You are not a member of any projects. You can request a project to be created with the 'new-project' command."
+				}
 			}
 		case 1:
 			if o.DisplayShort {
@@ -167,7 +201,11 @@ func (o ProjectsOptions) Run() error {
 				msg += fmt.Sprintf("You have access to the following projects and can switch between them with '%s project <projectname>':\n", o.CommandName)
 			}
 
-			sort.Sort(SortByProjectName(projects))
+			var recent []string
+			if o.Recent {
+				recent = ocproject.RecentProjects(o.RecentProjectsDir, o.RESTConfig.Host)
+			}
+			sortProjects(projects, scores, recent)
 			for _, project := range projects {
 				count = count + 1
 				displayName := project.Annotations[annotations.OpenShiftDisplayName]
@@ -0,0 +1,78 @@
+package projects
+
+import (
+	"sort"
+	"strings"
+
+	projectv1 "github.com/openshift/api/project/v1"
+	ocproject "github.com/openshift/oc/pkg/cli/project"
+)
+
+// fuzzyScore returns how well the term matches s, lower being better, and false
+// if it does not match. The term matches if its characters appear in s in the
+// same order, ignoring case; s containing the term ranks first, earlier
+// occurrences first, followed by the matches with the fewest characters
+// between those of the term.
+func fuzzyScore(term, s string) (int, bool) {
+	term, s = strings.ToLower(term), strings.ToLower(s)
+	if i := strings.Index(s, term); i != -1 {
+		return i, true
+	}
+	gaps, last := 0, -1
+	for _, r := range term {
+		i := strings.IndexRune(s[last+1:], r)
+		if i == -1 {
+			return 0, false
+		}
+		if last != -1 {
+			gaps += i
+		}
+		last += i + len(string(r))
+	}
+	return len(s) + gaps, true
+}
+
+// searchProjects returns the projects whose name or display name match the
+// term, along with their score.
+func searchProjects(projects []projectv1.Project, term string) ([]projectv1.Project, map[string]int) {
+	var matches []projectv1.Project
+	scores := map[string]int{}
+	for _, project := range projects {
+		score, ok := fuzzyScore(term, project.Name)
+		if displayName := ocproject.DisplayNameForProject(&project); displayName != project.Name {
+			if displayScore, displayOK := fuzzyScore(term, displayName); displayOK && (!ok || displayScore < score) {
+				score, ok = displayScore, true
+			}
+		}
+		if ok {
+			matches = append(matches, project)
+			scores[project.Name] = score
+		}
+	}
+	return matches, scores
+}
+
+// sortProjects orders the projects by score if they were searched, then with
+// recent the projects most recently switched to first, then by name.
+func sortProjects(projects []projectv1.Project, scores map[string]int, recent []string) {
+	rank := map[string]int{}
+	for i, name := range recent {
+		rank[name] = i + 1
+	}
+	sort.SliceStable(projects, func(i, j int) bool {
+		a, b := projects[i].Name, projects[j].Name
+		if scores[a] != scores[b] {
+			return scores[a] < scores[b]
+		}
+		if rank[a] != rank[b] {
+			switch {
+			case rank[a] == 0:
+				return false
+			case rank[b] == 0:
+				return true
+			}
+			return rank[a] < rank[b]
+		}
+		return a < b
+	})
+}
@@ -0,0 +1,104 @@
+package projects
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	projectv1 "github.com/openshift/api/project/v1"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		term, s       string
+		expectedScore int
+		expectedMatch bool
+	}{
+		{term: "dev", s: "dev", expectedScore: 0, expectedMatch: true},
+		{term: "dev", s: "my-dev", expectedScore: 3, expectedMatch: true},
+		{term: "DEV", s: "My-Dev", expectedScore: 3, expectedMatch: true},
+		{term: "dv", s: "dev", expectedScore: 4, expectedMatch: true},
+		{term: "dev", s: "d-e-v", expectedScore: 7, expectedMatch: true},
+		{term: "pd", s: "prod-db", expectedScore: 9, expectedMatch: true},
+		{term: "vd", s: "dev"},
+		{term: "xyz", s: "dev"},
+		{term: "devel", s: "dev"},
+	}
+	for _, test := range tests {
+		t.Run(test.term+"/"+test.s, func(t *testing.T) {
+			score, ok := fuzzyScore(test.term, test.s)
+			if ok != test.expectedMatch {
+				t.Fatalf("expected match %t, got %t", test.expectedMatch, ok)
+			}
+			if ok && score != test.expectedScore {
+				t.Errorf("expected score %d, got %d", test.expectedScore, score)
+			}
+		})
+	}
+}
+
+func TestSearchAndSortProjects(t *testing.T) {
+	project := func(name, displayName string) projectv1.Project {
+		p := projectv1.Project{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if len(displayName) > 0 {
+			p.Annotations = map[string]string{"openshift.io/display-name": displayName}
+		}
+		return p
+	}
+	projects := []projectv1.Project{
+		project("d-e-v", ""),
+		project("my-dev", ""),
+		project("prod", "Dev Prod"),
+		project("development", ""),
+		project("staging", ""),
+		project("dev", ""),
+	}
+	tests := []struct {
+		name     string
+		term     string
+		recent   []string
+		expected []string
+	}{
+		{
+			name:     "by name",
+			expected: []string{"d-e-v", "dev", "development", "my-dev", "prod", "staging"},
+		},
+		{
+			name:     "recent first",
+			recent:   []string{"staging", "missing", "my-dev"},
+			expected: []string{"staging", "my-dev", "d-e-v", "dev", "development", "prod"},
+		},
+		{
+			name:     "by score",
+			term:     "dev",
+			expected: []string{"dev", "development", "prod", "my-dev", "d-e-v"},
+		},
+		{
+			name:     "by score then recent",
+			term:     "dev",
+			recent:   []string{"prod", "d-e-v"},
+			expected: []string{"prod", "dev", "development", "my-dev", "d-e-v"},
+		},
+		{
+			name: "no match",
+			term: "xyz",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matches, scores := append([]projectv1.Project(nil), projects...), map[string]int{}
+			if len(test.term) > 0 {
+				matches, scores = searchProjects(matches, test.term)
+			}
+			sortProjects(matches, scores, test.recent)
+			var names []string
+			for _, p := range matches {
+				names = append(names, p.Name)
+			}
+			if !reflect.DeepEqual(names, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, names)
+			}
+		})
+	}
+}
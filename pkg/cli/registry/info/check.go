@@ -0,0 +1,314 @@
+package info
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/registry/api/errcode"
+	"github.com/distribution/distribution/v3/registry/client/transport"
+	"github.com/opencontainers/go-digest"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/openshift/library-go/pkg/image/registryclient"
+)
+
+const (
+	// registryNamespace and defaultRouteName locate the route created for the
+	// integrated registry when its default route is enabled.
+	registryNamespace = "openshift-image-registry"
+	defaultRouteName  = "default-route"
+
+	// probeRepository is the repository in the current namespace the blob probe is pushed to.
+	probeRepository = "registry-check"
+
+	// certificateExpiryWarning is how close to expiry the registry certificate is reported.
+	certificateExpiryWarning = 30 * 24 * time.Hour
+)
+
+// checkStatus is the outcome of a single registry check.
+type checkStatus string
+
+const (
+	checkOK      checkStatus = "OK"
+	checkWarning checkStatus = "WARN"
+	checkFailed  checkStatus = "FAIL"
+	checkSkipped checkStatus = "SKIP"
+)
+
+// checkResult is the outcome and explanation of a registry check.
+type checkResult struct {
+	status  checkStatus
+	message string
+	hint    string
+}
+
+func ok(format string, args ...interface{}) checkResult {
+	return checkResult{status: checkOK, message: fmt.Sprintf(format, args...)}
+}
+
+func warning(hint, format string, args ...interface{}) checkResult {
+	return checkResult{status: checkWarning, message: fmt.Sprintf(format, args...), hint: hint}
+}
+
+func failed(hint, format string, args ...interface{}) checkResult {
+	return checkResult{status: checkFailed, message: fmt.Sprintf(format, args...), hint: hint}
+}
+
+// registryCheck is a named diagnostic run against the registry. Checks that
+// contact the registry are skipped once the registry could not be reached.
+type registryCheck struct {
+	name          string
+	needsRegistry bool
+	run           func(ctx context.Context, host string) checkResult
+}
+
+// runChecks verifies the route, TLS certificate, authentication and blob
+// storage of the registry at host and reports the result of each check.
+func (o *Options) runChecks(ctx context.Context, host string) (string, error) {
+	reachable := true
+	checks := []registryCheck{
+		{name: "route", run: o.checkRoute},
+		{name: "ping", run: func(ctx context.Context, h string) checkResult {
+			result, pinged := o.checkPing(ctx, h)
+			if len(pinged) > 0 {
+				host = pinged
+			} else {
+				reachable = false
+			}
+			return result
+		}},
+		{name: "tls", needsRegistry: true, run: o.checkTLS},
+		{name: "auth", needsRegistry: true, run: o.checkAuth},
+		{name: "storage", needsRegistry: true, run: o.checkStorage},
+	}
+
+	failures := 0
+	for _, check := range checks {
+		var result checkResult
+		if check.needsRegistry && !reachable {
+			result = checkResult{status: checkSkipped, message: "the registry could not be contacted"}
+		} else {
+			result = check.run(ctx, host)
+		}
+		if result.status == checkFailed {
+			failures++
+		}
+		if o.Quiet && result.status != checkFailed {
+			continue
+		}
+		fmt.Fprintf(o.ErrOut, "%-4s  %s: %s\n", result.status, check.name, result.message)
+		if len(result.hint) > 0 {
+			fmt.Fprintf(o.ErrOut, "      %s\n", result.hint)
+		}
+	}
+	if failures > 0 {
+		return host, fmt.Errorf("%d of %d registry checks failed", failures, len(checks))
+	}
+	return host, nil
+}
+
+// checkRoute reports whether the default route of the registry exists and was admitted.
+func (o *Options) checkRoute(ctx context.Context, host string) checkResult {
+	if o.RouteClient == nil {
+		return checkResult{status: checkSkipped, message: "no route client is available"}
+	}
+	route, err := o.RouteClient.Routes(registryNamespace).Get(ctx, defaultRouteName, metav1.GetOptions{})
+	switch {
+	case kerrors.IsNotFound(err):
+		return warning("An administrator can expose the registry with 'oc patch configs.imageregistry.operator.openshift.io/cluster --type=merge -p {\"spec\":{\"defaultRoute\":true}}'.",
+			"the registry has no %s route in %s", defaultRouteName, registryNamespace)
+	case kerrors.IsForbidden(err):
+		return checkResult{status: checkSkipped, message: fmt.Sprintf("you do not have permission to view routes in %s", registryNamespace)}
+	case err != nil:
+		return failed("", "unable to get route %s/%s: %v", registryNamespace, defaultRouteName, err)
+	}
+
+	for _, ingress := range route.Status.Ingress {
+		for _, condition := range ingress.Conditions {
+			if condition.Type != routev1.RouteAdmitted || condition.Status != "True" {
+				continue
+			}
+			if route.Spec.Host != host {
+				return warning("Use --public to check the route host, or --registry with 'oc registry login' to log in to it.",
+					"route %s/%s is admitted with host %s, which is not the registry hostname %s", registryNamespace, defaultRouteName, route.Spec.Host, host)
+			}
+			return ok("route %s/%s is admitted by router %s", registryNamespace, defaultRouteName, ingress.RouterName)
+		}
+	}
+	return failed("Check the status of the ingress controller with 'oc get clusteroperator ingress'.",
+		"route %s/%s with host %s has not been admitted by a router", registryNamespace, defaultRouteName, route.Spec.Host)
+}
+
+// checkPing contacts the registry and returns the host that answered.
+func (o *Options) checkPing(ctx context.Context, host string) (checkResult, string) {
+	c := registryclient.NewContext(o.registryTransport(), http.DefaultTransport).
+		WithRequestModifiers(transport.NewHeaderRequestModifier(http.Header{http.CanonicalHeaderKey("User-Agent"): []string{rest.DefaultKubernetesUserAgent()}}))
+	_, src, err := c.Ping(ctx, &url.URL{Host: host}, false)
+	if err != nil {
+		return failed("Verify the hostname is reachable from this machine, the internal hostname is only reachable from within the cluster.",
+			"registry could not be contacted at %s: %v", host, err), ""
+	}
+	return ok("registry responded at %s://%s", src.Scheme, src.Host), src.Host
+}
+
+// checkTLS verifies the certificate chain of the registry against the system
+// trust store and the certificate authority of the current session.
+func (o *Options) checkTLS(ctx context.Context, host string) checkResult {
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil {
+		hostname, port = host, "443"
+	}
+	dialer := &tls.Dialer{NetDialer: &net.Dialer{Timeout: 10 * time.Second}, Config: &tls.Config{ServerName: hostname, InsecureSkipVerify: true}}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(hostname, port))
+	if err != nil {
+		return failed("", "unable to establish a TLS connection to %s: %v", host, err)
+	}
+	defer conn.Close()
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return failed("", "%s did not present a certificate", host)
+	}
+	leaf := certs[0]
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	if err := leaf.VerifyHostname(hostname); err != nil {
+		return failed("The registry certificate must include the hostname clients use, ask your administrator to update it.", "%v", err)
+	}
+
+	issued := fmt.Sprintf("certificate issued by %q expires %s", leaf.Issuer.CommonName, leaf.NotAfter.UTC().Format(time.RFC3339))
+	if _, err := leaf.Verify(x509.VerifyOptions{DNSName: hostname, Intermediates: intermediates}); err != nil {
+		if _, clusterErr := leaf.Verify(x509.VerifyOptions{DNSName: hostname, Intermediates: intermediates, Roots: o.clusterCAs()}); clusterErr == nil {
+			return warning(fmt.Sprintf("Container tools need the cluster CA to trust the registry, for podman add it as /etc/containers/certs.d/%s/ca.crt.", host),
+				"%s, and is trusted by your session but not by this system: %v", issued, err)
+		}
+		return failed(fmt.Sprintf("Add the CA that signed the registry certificate to /etc/containers/certs.d/%s/ca.crt or your system trust store.", host),
+			"%s, and is not trusted: %v", issued, err)
+	}
+	if remaining := time.Until(leaf.NotAfter); remaining < certificateExpiryWarning {
+		return warning("Ask your administrator to renew the registry certificate.", "%s, in %d days", issued, int(remaining.Hours()/24))
+	}
+	return ok("%s", issued)
+}
+
+// checkAuth verifies the token of the current session is accepted by the registry.
+func (o *Options) checkAuth(ctx context.Context, host string) checkResult {
+	if len(o.Token) == 0 {
+		return warning("Log in with a token, for example with 'oc login', to check registry authentication.", "no token is in use for this session")
+	}
+	blobs, err := o.probeBlobs(ctx, host)
+	if err != nil {
+		return failed("", "unable to access %s/%s: %v", o.Namespaces[0], probeRepository, err)
+	}
+	_, err = blobs.Stat(ctx, digest.FromString(probeRepository))
+	switch {
+	case err == nil, errors.Is(err, distribution.ErrBlobUnknown):
+		return ok("the token of the current session is accepted for %s/%s", o.Namespaces[0], probeRepository)
+	case isUnauthorized(err):
+		return failed("Your token may have expired, log in again with 'oc login' and retry.", "the registry rejected the token of the current session: %v", err)
+	default:
+		return failed("", "unable to authenticate to the registry: %v", err)
+	}
+}
+
+// checkStorage pushes a small blob to the registry and pulls it back to verify
+// that the registry storage is writable.
+func (o *Options) checkStorage(ctx context.Context, host string) checkResult {
+	if len(o.Token) == 0 {
+		return checkResult{status: checkSkipped, message: "no token is in use for this session"}
+	}
+	blobs, err := o.probeBlobs(ctx, host)
+	if err != nil {
+		return failed("", "unable to access %s/%s: %v", o.Namespaces[0], probeRepository, err)
+	}
+	data := []byte(fmt.Sprintf("oc registry info --check %s\n", time.Now().UTC().Format(time.RFC3339Nano)))
+	desc, err := blobs.Put(ctx, "application/octet-stream", data)
+	if err != nil {
+		if isUnauthorized(err) {
+			return failed(fmt.Sprintf("You need permission to push images to namespace %s to run the storage check.", o.Namespaces[0]),
+				"unable to push a blob to %s/%s: %v", o.Namespaces[0], probeRepository, err)
+		}
+		return failed("The registry storage may be full or misconfigured, check 'oc get clusteroperator image-registry'.",
+			"unable to push a blob to %s/%s: %v", o.Namespaces[0], probeRepository, err)
+	}
+	pulled, err := blobs.Get(ctx, desc.Digest)
+	if err != nil {
+		return failed("The registry storage may be misconfigured, check 'oc get clusteroperator image-registry'.",
+			"pushed blob %s could not be pulled back: %v", desc.Digest, err)
+	}
+	if !bytes.Equal(pulled, data) {
+		return failed("The registry storage may be corrupting data, check 'oc get clusteroperator image-registry'.",
+			"pulled blob %s does not match the pushed contents", desc.Digest)
+	}
+	return ok("pushed and pulled a %d byte blob through %s/%s", len(data), o.Namespaces[0], probeRepository)
+}
+
+// probeBlobs returns the blob store of the probe repository, authenticated with the session token.
+func (o *Options) probeBlobs(ctx context.Context, host string) (distribution.BlobStore, error) {
+	creds := registryclient.NewBasicCredentials()
+	hostURL := &url.URL{Host: host}
+	creds.Add(hostURL, "user", o.Token)
+	c := registryclient.NewContext(o.registryTransport(), http.DefaultTransport).
+		WithCredentials(creds).
+		WithActions("pull", "push").
+		WithRequestModifiers(transport.NewHeaderRequestModifier(http.Header{http.CanonicalHeaderKey("User-Agent"): []string{rest.DefaultKubernetesUserAgent()}}))
+	repo, err := c.Repository(ctx, hostURL, fmt.Sprintf("%s/%s", o.Namespaces[0], probeRepository), false)
+	if err != nil {
+		return nil, err
+	}
+	return repo.Blobs(ctx), nil
+}
+
+// registryTransport trusts the system certificate authorities and the
+// certificate authority of the current session.
+func (o *Options) registryTransport() http.RoundTripper {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	pool.AppendCertsFromPEM(o.CAData)
+	return &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		TLSHandshakeTimeout: 10 * time.Second,
+		TLSClientConfig:     &tls.Config{RootCAs: pool},
+	}
+}
+
+// clusterCAs returns the certificate authorities of the current session.
+func (o *Options) clusterCAs() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(o.CAData)
+	return pool
+}
+
+func isUnauthorized(err error) bool {
+	var errs errcode.Errors
+	if errors.As(err, &errs) {
+		for _, e := range errs {
+			if isUnauthorized(e) {
+				return true
+			}
+		}
+		return false
+	}
+	var e errcode.Error
+	if errors.As(err, &e) {
+		return e.Code == errcode.ErrorCodeUnauthorized || e.Code == errcode.ErrorCodeDenied
+	}
+	return strings.Contains(err.Error(), "unauthorized") || strings.Contains(err.Error(), "denied")
+}
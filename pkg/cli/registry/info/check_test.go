@@ -0,0 +1,49 @@
+package info
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	routev1 "github.com/openshift/api/route/v1"
+	fakerouteclient "github.com/openshift/client-go/route/clientset/versioned/fake"
+)
+
+func TestCheckRoute(t *testing.T) {
+	route := func(host string, admitted corev1.ConditionStatus) *routev1.Route {
+		return &routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{Name: defaultRouteName, Namespace: registryNamespace},
+			Spec:       routev1.RouteSpec{Host: host},
+			Status: routev1.RouteStatus{Ingress: []routev1.RouteIngress{{
+				RouterName: "default",
+				Conditions: []routev1.RouteIngressCondition{{Type: routev1.RouteAdmitted, Status: admitted}},
+			}}},
+		}
+	}
+	tests := []struct {
+		name   string
+		route  *routev1.Route
+		host   string
+		status checkStatus
+	}{
+		{name: "admitted", route: route("registry.example.com", corev1.ConditionTrue), host: "registry.example.com", status: checkOK},
+		{name: "not admitted", route: route("registry.example.com", corev1.ConditionFalse), host: "registry.example.com", status: checkFailed},
+		{name: "other host", route: route("registry.example.com", corev1.ConditionTrue), host: "image-registry.openshift-image-registry.svc:5000", status: checkWarning},
+		{name: "missing", host: "registry.example.com", status: checkWarning},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fakerouteclient.NewSimpleClientset()
+			if tt.route != nil {
+				client = fakerouteclient.NewSimpleClientset(tt.route)
+			}
+			o := &Options{RouteClient: client.RouteV1()}
+			result := o.checkRoute(context.TODO(), tt.host)
+			if result.status != tt.status {
+				t.Errorf("expected %s, got %s: %s", tt.status, result.status, result.message)
+			}
+		})
+	}
+}
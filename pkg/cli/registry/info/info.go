@@ -3,22 +3,19 @@ package info
 import (
 	"context"
 	"fmt"
-	"net/http"
-	"net/url"
+	"os"
 
-	"github.com/distribution/distribution/v3/registry/client/transport"
 	"github.com/spf13/cobra"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
-	"k8s.io/client-go/rest"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/templates"
 
 	imageclient "github.com/openshift/client-go/image/clientset/versioned"
+	routev1client "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
 	"github.com/openshift/library-go/pkg/image/reference"
-	"github.com/openshift/library-go/pkg/image/registryclient"
 )
 
 var (
@@ -30,12 +27,28 @@ var (
 		has not configured a public host name for the registry then this command may fail when
 		run outside of the server.
 
+		The --check flag runs a series of diagnostics and reports each as OK, WARN, FAIL or
+		SKIP on standard error, with a hint for how to resolve problems:
+
+		* route: the default route of the registry exists and was admitted by a router
+		* ping: the registry responds at its hostname
+		* tls: the registry certificate matches its hostname, is trusted and is not about to expire
+		* auth: the registry accepts the token of the current session
+		* storage: a small blob can be pushed to and pulled from the registry-check repository
+		  of the current namespace
+
+		The command exits with an error if any check fails. The blob pushed by the storage check
+		is not referenced by any image and is removed by 'oc adm prune images --prune-registry'.
+
 		Experimental: This command is under active development and may change without notice.
 	`)
 
 	example = templates.Examples(`
 		# Display information about the integrated registry
 		oc registry info
+
+		# Check that the registry is reachable, trusted and writable from this machine
+		oc registry info --check
 	`)
 )
 
@@ -45,8 +58,14 @@ type Options struct {
 	ShowInternal bool
 	ShowPublic   bool
 
-	Namespaces []string
-	Client     imageclient.Interface
+	Namespaces  []string
+	Client      imageclient.Interface
+	RouteClient routev1client.RoutesGetter
+
+	// Token and CAData are the credentials and certificate authorities of the
+	// current session, used by --check.
+	Token  string
+	CAData []byte
 
 	genericiooptions.IOStreams
 }
@@ -75,8 +94,8 @@ func NewRegistryInfoCmd(f kcmdutil.Factory, streams genericiooptions.IOStreams)
 	}
 
 	flag := cmd.Flags()
-	flag.BoolVar(&o.Check, "check", o.Check, "Attempt to contact the integrated registry.")
-	flag.BoolVarP(&o.Check, "quiet", "q", o.Quiet, "Suppress normal output and only print status.")
+	flag.BoolVar(&o.Check, "check", o.Check, "Verify the route, TLS certificate, authentication and storage of the integrated registry.")
+	flag.BoolVarP(&o.Quiet, "quiet", "q", o.Quiet, "Suppress normal output and only print status.")
 	flag.BoolVar(&o.ShowInternal, "internal", o.ShowInternal, "Only check the internal registry hostname.")
 	flag.BoolVar(&o.ShowPublic, "public", o.ShowPublic, "Only check the public registry hostname.")
 
@@ -94,6 +113,21 @@ func (o *Options) Complete(f kcmdutil.Factory, args []string) error {
 	}
 	o.Client = client
 
+	if o.Check {
+		routeClient, err := routev1client.NewForConfig(cfg)
+		if err != nil {
+			return err
+		}
+		o.RouteClient = routeClient
+		o.Token = cfg.BearerToken
+		o.CAData = cfg.CAData
+		if len(o.CAData) == 0 && len(cfg.CAFile) > 0 {
+			if o.CAData, err = os.ReadFile(cfg.CAFile); err != nil {
+				return err
+			}
+		}
+	}
+
 	ns, _, err := f.ToRawKubeConfigLoader().Namespace()
 	if err != nil {
 		return err
@@ -186,14 +220,11 @@ func (o *Options) Run() error {
 		if !public && !o.ShowInternal {
 			fmt.Fprintf(o.ErrOut, "info: Registry does not have a public hostname\n")
 		}
-		url := &url.URL{Host: host}
-		c := registryclient.NewContext(http.DefaultTransport, http.DefaultTransport).
-			WithRequestModifiers(transport.NewHeaderRequestModifier(http.Header{http.CanonicalHeaderKey("User-Agent"): []string{rest.DefaultKubernetesUserAgent()}}))
-		_, src, err := c.Ping(ctx, url, false)
+		checked, err := o.runChecks(ctx, host)
 		if err != nil {
-			return fmt.Errorf("registry could not be contacted at %s: %v", url.Host, err)
+			return err
 		}
-		host = src.Host
+		host = checked
 	}
 
 	if !o.Quiet {
@@ -2,6 +2,7 @@ package login
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -11,17 +12,22 @@ import (
 
 	dockerconfig "github.com/containers/image/v5/pkg/docker/config"
 	containertypes "github.com/containers/image/v5/types"
+	helperclient "github.com/docker/docker-credential-helpers/client"
+	"github.com/docker/docker-credential-helpers/credentials"
 	"github.com/spf13/cobra"
 
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/util/homedir"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util/templates"
 
 	imageclient "github.com/openshift/client-go/image/clientset/versioned"
@@ -43,7 +49,11 @@ var (
 		with USER:PASSWORD.
 
 		You may specify an alternate file to write credentials to with --to instead of
-		.docker/config.json in your home directory.
+		the default location. Pass --to=podman to write to the podman auth.json used by podman
+		and buildah, --to=docker to write to .docker/config.json in your home directory, or
+		--to=helper:NAME to store the credentials with the docker-credential-NAME credential
+		helper. An existing file with one of these names, or a path like ./podman, is still
+		written to as a file.
 
 		To detect the registry hostname the client will attempt to find an image stream in
 		the current namespace or the openshift namespace and use the status fields that
@@ -55,6 +65,11 @@ var (
 		custom DNS name, or to an external registry. Note that in absence of --auth-basic=USER:PASSWORD,
 		the authentication token from the connected kubeconfig file will be recorded as the auth entry
 		in the credentials file (defaults to Docker config.json) for the passed registry value.
+
+		Pass --to-secret=NAME to store the credentials in a kubernetes.io/dockerconfigjson secret
+		in the current namespace (or the one passed with -n) instead of a local file, so that
+		builds and deployments in that namespace can pull from the registry. The secret is
+		created, or updated if it exists, unless -o is passed to print it instead.
 	`)
 
 	example = templates.Examples(`
@@ -63,9 +78,30 @@ var (
 
 		# Log in to different registry using BASIC auth credentials
 		oc registry login --registry quay.io/myregistry --auth-basic=USER:PASS
+
+		# Log in to the integrated registry and store the credentials in the macOS keychain
+		oc registry login --to=helper:osxkeychain
+
+		# Log in to the integrated registry for podman and buildah
+		oc registry login --to=podman
+
+		# Create a pull secret for the integrated registry in the myapp namespace
+		oc registry login --to-secret=registry-pull -n myapp
+
+		# Print the pull secret for a registry instead of creating it
+		oc registry login --registry quay.io/myregistry --auth-basic=USER:PASS --to-secret=quay-pull -o yaml
 	`)
 )
 
+const (
+	// credentialHelperPrefix selects a Docker credential helper with --to=helper:NAME.
+	credentialHelperPrefix = "helper:"
+	// podmanTarget selects the podman auth.json with --to=podman.
+	podmanTarget = "podman"
+	// dockerTarget selects the Docker config.json in the home directory with --to=docker.
+	dockerTarget = "docker"
+)
+
 type Credentials struct {
 	Auth     []byte `json:"auth"`
 	Username string `json:"-"`
@@ -94,12 +130,23 @@ type LoginOptions struct {
 	AuthBasic      string
 	ServiceAccount string
 
+	ToSecret  string
+	Namespace string
+
+	// CredentialHelper is the name of the docker-credential-NAME helper storing the credentials.
+	CredentialHelper string
+
+	PrintFlags *genericclioptions.PrintFlags
+	ToPrinter  func(string) (printers.ResourcePrinter, error)
+	KubeClient clientset.Interface
+
 	genericiooptions.IOStreams
 }
 
 func NewRegistryLoginOptions(streams genericiooptions.IOStreams) *LoginOptions {
 	return &LoginOptions{
-		IOStreams: streams,
+		PrintFlags: genericclioptions.NewPrintFlags("created").WithTypeSetter(scheme.Scheme),
+		IOStreams:  streams,
 	}
 }
 
@@ -124,12 +171,15 @@ func NewRegistryLoginCmd(f kcmdutil.Factory, streams genericiooptions.IOStreams)
 	// TODO: remove REGISTRY_AUTH_PREFERENCE env variable support and support only podman in 4.15
 	flag.StringVarP(&o.ConfigFile, "registry-config", "a", o.ConfigFile, "The location of the file your credentials will be stored in. Alternatively REGISTRY_AUTH_FILE env variable can be also specified. Defaults to ${XDG_RUNTIME_DIR}/containers/auth.json or /run/containers/${UID}/auth.json. Default can be changed via the REGISTRY_AUTH_PREFERENCE env variable (deprecated) to a \"docker\" value to prioritizes Docker credentials over Podman's.")
 	// TODO: remove REGISTRY_AUTH_PREFERENCE env variable support and support only podman in 4.15
-	flag.StringVar(&o.ConfigFile, "to", o.ConfigFile, "The location of the file your credentials will be stored in. Alternatively REGISTRY_AUTH_FILE env variable can be also specified. Defaults to ${XDG_RUNTIME_DIR}/containers/auth.json or /run/containers/${UID}/auth.json. Default can be changed via the REGISTRY_AUTH_PREFERENCE env variable (deprecated) to a \"docker\" value to prioritizes Docker credentials over Podman's.")
+	flag.StringVar(&o.ConfigFile, "to", o.ConfigFile, "The location of the file your credentials will be stored in, 'podman' or 'docker' for their default files, or 'helper:NAME' to store them with the docker-credential-NAME credential helper. Alternatively REGISTRY_AUTH_FILE env variable can be also specified. Defaults to ${XDG_RUNTIME_DIR}/containers/auth.json or /run/containers/${UID}/auth.json. Default can be changed via the REGISTRY_AUTH_PREFERENCE env variable (deprecated) to a \"docker\" value to prioritizes Docker credentials over Podman's.")
 	flag.StringVarP(&o.ServiceAccount, "service-account", "z", o.ServiceAccount, "Log in as the specified service account name in the specified namespace.")
 	flag.MarkDeprecated("service-account", "and will be removed in the future version. Use oc create token instead.")
 	flag.StringVar(&o.HostPort, "registry", o.HostPort, "An alternate domain name and port to use for the registry, defaults to the cluster's configured external hostname.")
 	flag.BoolVar(&o.SkipCheck, "skip-check", o.SkipCheck, "Skip checking the credentials against the registry.")
 	flag.BoolVar(&o.Insecure, "insecure", o.Insecure, "Bypass HTTPS certificate verification when checking the registry login.")
+	flag.StringVar(&o.ToSecret, "to-secret", o.ToSecret, "Store the credentials in a pull secret with this name in the current namespace instead of a local file.")
+
+	o.PrintFlags.AddFlags(cmd)
 
 	return cmd
 }
@@ -145,6 +195,14 @@ func (o *LoginOptions) Complete(f kcmdutil.Factory, args []string) error {
 	if credentials > 1 {
 		return fmt.Errorf("You may only specify a single authentication input as --auth-basic")
 	}
+	if len(o.ToSecret) > 0 && len(o.ConfigFile) > 0 {
+		return fmt.Errorf("--to-secret may not be specified with --to or --registry-config")
+	}
+
+	defaultConfigFile, err := o.completeTarget()
+	if err != nil {
+		return err
+	}
 
 	cfg, err := f.ToRESTConfig()
 	if err != nil {
@@ -207,6 +265,22 @@ func (o *LoginOptions) Complete(f kcmdutil.Factory, args []string) error {
 		o.Credentials = newCredentials("user", cfg.BearerToken)
 	}
 
+	if len(o.ToSecret) > 0 {
+		if o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace(); err != nil {
+			return err
+		}
+		if o.KubeClient, err = clientset.NewForConfig(cfg); err != nil {
+			return err
+		}
+		if len(o.AuthBasic) == 0 && len(o.ServiceAccount) == 0 {
+			fmt.Fprintf(o.ErrOut, "warning: The secret %s will contain the token of your current session and stop working when the token expires\n", o.ToSecret)
+		}
+	}
+	o.ToPrinter = func(operation string) (printers.ResourcePrinter, error) {
+		o.PrintFlags.NamePrintFlags.Operation = operation
+		return o.PrintFlags.ToPrinter()
+	}
+
 	if len(o.HostPort) == 0 {
 		client, err := imageclient.NewForConfig(cfg)
 		if err != nil {
@@ -233,7 +307,7 @@ func (o *LoginOptions) Complete(f kcmdutil.Factory, args []string) error {
 		}
 	}
 
-	if len(o.ConfigFile) == 0 {
+	if defaultConfigFile && len(o.ToSecret) == 0 {
 		if authFile := os.Getenv("REGISTRY_AUTH_FILE"); authFile != "" {
 			o.ConfigFile = authFile
 		} else {
@@ -252,6 +326,33 @@ func (o *LoginOptions) Complete(f kcmdutil.Factory, args []string) error {
 	return nil
 }
 
+// completeTarget resolves the podman, docker and helper:NAME values of --to, and returns
+// true if the credentials should be written to the default location. A file of the
+// current directory with the name of a target, or any path like ./podman, still
+// selects that file.
+func (o *LoginOptions) completeTarget() (bool, error) {
+	if len(o.ConfigFile) == 0 {
+		return true, nil
+	}
+	if _, err := os.Stat(o.ConfigFile); err == nil {
+		return false, nil
+	}
+	switch {
+	case strings.HasPrefix(o.ConfigFile, credentialHelperPrefix):
+		o.CredentialHelper = strings.TrimPrefix(o.ConfigFile, credentialHelperPrefix)
+		if len(o.CredentialHelper) == 0 {
+			return false, fmt.Errorf("--to=%sNAME requires the name of a credential helper", credentialHelperPrefix)
+		}
+		o.ConfigFile = ""
+	case o.ConfigFile == podmanTarget:
+		// leave the location to containers/image, which writes the podman auth.json
+		o.ConfigFile = ""
+	case o.ConfigFile == dockerTarget:
+		o.ConfigFile = filepath.Join(homedir.HomeDir(), ".docker", "config.json")
+	}
+	return false, nil
+}
+
 func findPublicHostname(client *imageclient.Clientset, namespaces ...string) (name string, internal bool, err error) {
 	for _, ns := range namespaces {
 		imageStreams, err := client.ImageV1().ImageStreams(ns).List(context.TODO(), metav1.ListOptions{})
@@ -277,9 +378,17 @@ func (o *LoginOptions) Validate() error {
 	if o.Credentials.Empty() {
 		return fmt.Errorf("Unable to determine registry credentials, please log into the cluster.")
 	}
+	if len(o.ToSecret) == 0 && o.outputRequested() {
+		return fmt.Errorf("--output may only be specified with --to-secret")
+	}
 	return nil
 }
 
+// outputRequested returns true if the secret should be printed instead of applied.
+func (o *LoginOptions) outputRequested() bool {
+	return o.PrintFlags.OutputFormat != nil && len(*o.PrintFlags.OutputFormat) > 0
+}
+
 func (o *LoginOptions) Run() error {
 	if !o.SkipCheck {
 		ctx := apirequest.NewContext()
@@ -296,6 +405,24 @@ func (o *LoginOptions) Run() error {
 		}
 	}
 
+	if len(o.ToSecret) > 0 {
+		return o.saveSecret()
+	}
+
+	if len(o.CredentialHelper) > 0 {
+		helper := helperclient.NewShellProgramFunc("docker-credential-" + o.CredentialHelper)
+		creds := &credentials.Credentials{
+			ServerURL: o.HostPort,
+			Username:  o.Credentials.Username,
+			Secret:    o.Credentials.Password,
+		}
+		if err := helperclient.Store(helper, creds); err != nil {
+			return fmt.Errorf("unable to store credentials with docker-credential-%s: %v", o.CredentialHelper, err)
+		}
+		fmt.Fprintf(o.Out, "Saved credentials for %s into credential helper docker-credential-%s\n", o.HostPort, o.CredentialHelper)
+		return nil
+	}
+
 	ctx := &containertypes.SystemContext{AuthFilePath: o.ConfigFile}
 	credentialLocation, err := dockerconfig.SetCredentials(ctx, o.HostPort, o.Credentials.Username, o.Credentials.Password)
 	if err != nil {
@@ -305,3 +432,60 @@ func (o *LoginOptions) Run() error {
 	fmt.Fprintf(o.Out, "Saved credentials for %s into %s\n", o.HostPort, credentialLocation)
 	return nil
 }
+
+// pullSecret returns a kubernetes.io/dockerconfigjson secret holding the registry credentials.
+func (o *LoginOptions) pullSecret() (*corev1.Secret, error) {
+	data, err := json.Marshal(map[string]map[string]Credentials{
+		"auths": {o.HostPort: o.Credentials},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: o.ToSecret, Namespace: o.Namespace},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: data},
+	}, nil
+}
+
+// saveSecret prints the pull secret if output was requested, and otherwise
+// creates it or updates the credentials of the existing secret.
+func (o *LoginOptions) saveSecret() error {
+	secret, err := o.pullSecret()
+	if err != nil {
+		return err
+	}
+	if o.outputRequested() {
+		printer, err := o.ToPrinter("created")
+		if err != nil {
+			return err
+		}
+		return printer.PrintObj(secret, o.Out)
+	}
+
+	secrets := o.KubeClient.CoreV1().Secrets(o.Namespace)
+	operation := "created"
+	existing, err := secrets.Get(context.TODO(), o.ToSecret, metav1.GetOptions{})
+	switch {
+	case kerrors.IsNotFound(err):
+		secret, err = secrets.Create(context.TODO(), secret, metav1.CreateOptions{})
+	case err != nil:
+		return err
+	case existing.Type != corev1.SecretTypeDockerConfigJson:
+		return fmt.Errorf("the secret %s/%s exists with type %s, not %s", o.Namespace, o.ToSecret, existing.Type, corev1.SecretTypeDockerConfigJson)
+	default:
+		operation = "configured"
+		existing.Data = secret.Data
+		secret, err = secrets.Update(context.TODO(), existing, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+
+	printer, err := o.ToPrinter(operation)
+	if err != nil {
+		return err
+	}
+	return printer.PrintObj(secret, o.Out)
+}
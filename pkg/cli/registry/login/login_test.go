@@ -0,0 +1,220 @@
+package login
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSaveSecret(t *testing.T) {
+	expected := `{"auths":{"registry.example.com":{"auth":"dXNlcjpwYXNz"}}}`
+
+	tests := []struct {
+		name      string
+		existing  []*corev1.Secret
+		output    string
+		expectOut string
+		expectErr bool
+	}{
+		{
+			name:      "create",
+			expectOut: "secret/pull created\n",
+		},
+		{
+			name: "update",
+			existing: []*corev1.Secret{{
+				ObjectMeta: metav1.ObjectMeta{Name: "pull", Namespace: "myapp"},
+				Type:       corev1.SecretTypeDockerConfigJson,
+				Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte("{}")},
+			}},
+			expectOut: "secret/pull configured\n",
+		},
+		{
+			name: "wrong type",
+			existing: []*corev1.Secret{{
+				ObjectMeta: metav1.ObjectMeta{Name: "pull", Namespace: "myapp"},
+				Type:       corev1.SecretTypeOpaque,
+			}},
+			expectErr: true,
+		},
+		{
+			name:   "print",
+			output: "name",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			for _, secret := range test.existing {
+				client.Tracker().Add(secret)
+			}
+			streams, _, out, _ := genericiooptions.NewTestIOStreams()
+			o := NewRegistryLoginOptions(streams)
+			o.HostPort = "registry.example.com"
+			o.Credentials = newCredentials("user", "pass")
+			o.ToSecret = "pull"
+			o.Namespace = "myapp"
+			o.KubeClient = client
+			o.PrintFlags.OutputFormat = &test.output
+			o.ToPrinter = func(operation string) (printers.ResourcePrinter, error) {
+				o.PrintFlags.NamePrintFlags.Operation = operation
+				return o.PrintFlags.ToPrinter()
+			}
+
+			err := o.saveSecret()
+			if (err != nil) != test.expectErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if test.expectErr {
+				return
+			}
+			if len(test.expectOut) > 0 && out.String() != test.expectOut {
+				t.Errorf("unexpected output: %q", out.String())
+			}
+
+			secret, err := client.CoreV1().Secrets("myapp").Get(context.TODO(), "pull", metav1.GetOptions{})
+			if len(test.output) > 0 {
+				if err == nil {
+					t.Errorf("expected the secret to be printed and not created")
+				}
+				if !bytes.Contains(out.Bytes(), []byte("secret/pull")) {
+					t.Errorf("unexpected output: %q", out.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if secret.Type != corev1.SecretTypeDockerConfigJson || string(secret.Data[corev1.DockerConfigJsonKey]) != expected {
+				t.Errorf("unexpected secret: %#v", secret)
+			}
+		})
+	}
+}
+
+func TestRunTargets(t *testing.T) {
+	tests := []struct {
+		name string
+		to   string
+		// existing is a file of the current directory created before the login
+		existing      string
+		expectedFile  string
+		expectedError string
+	}{
+		{
+			name:         "podman",
+			to:           "podman",
+			expectedFile: "runtime/containers/auth.json",
+		},
+		{
+			name:         "docker",
+			to:           "docker",
+			expectedFile: "home/.docker/config.json",
+		},
+		{
+			name:         "credential helper",
+			to:           "helper:test",
+			expectedFile: "stored",
+		},
+		{
+			name:          "credential helper without a name",
+			to:            "helper:",
+			expectedError: "--to=helper:NAME requires the name of a credential helper",
+		},
+		{
+			name:         "relative file",
+			to:           "auth/config.json",
+			existing:     "auth/config.json",
+			expectedFile: "cwd/auth/config.json",
+		},
+		{
+			name:         "path of a target",
+			to:           "./podman",
+			expectedFile: "cwd/podman",
+		},
+		{
+			name:         "existing file named like a target",
+			to:           "docker",
+			existing:     "docker",
+			expectedFile: "cwd/docker",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, subdir := range []string{"home", "runtime", "cwd/auth", "bin"} {
+				if err := os.MkdirAll(filepath.Join(dir, subdir), 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			helper := fmt.Sprintf("#!/bin/sh\n[ \"$1\" = store ] && cat > %s\n", filepath.Join(dir, "stored"))
+			if err := os.WriteFile(filepath.Join(dir, "bin", "docker-credential-test"), []byte(helper), 0755); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("HOME", filepath.Join(dir, "home"))
+			t.Setenv("XDG_RUNTIME_DIR", filepath.Join(dir, "runtime"))
+			t.Setenv("PATH", filepath.Join(dir, "bin")+string(os.PathListSeparator)+os.Getenv("PATH"))
+			cwd, err := os.Getwd()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chdir(filepath.Join(dir, "cwd")); err != nil {
+				t.Fatal(err)
+			}
+			defer os.Chdir(cwd)
+			if len(test.existing) > 0 {
+				if err := os.WriteFile(test.existing, []byte("{}"), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			streams, _, _, _ := genericiooptions.NewTestIOStreams()
+			o := NewRegistryLoginOptions(streams)
+			o.HostPort = "registry.example.com"
+			o.Credentials = newCredentials("user", "pass")
+			o.ConfigFile = test.to
+			o.SkipCheck = true
+			defaultConfigFile, err := o.completeTarget()
+			if len(test.expectedError) > 0 {
+				if err == nil || err.Error() != test.expectedError {
+					t.Fatalf("expected error %q, got %v", test.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if defaultConfigFile {
+				t.Errorf("expected --to=%s not to use the default location", test.to)
+			}
+			if err := o.Run(); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, test.expectedFile))
+			if err != nil {
+				t.Fatalf("expected the credentials to be written to %s: %v", test.expectedFile, err)
+			}
+			// the files hold the base64 encoded credentials, the helper gets them in clear
+			secret := `"auth": "dXNlcjpwYXNz"`
+			if len(o.CredentialHelper) > 0 {
+				secret = `"Secret":"pass"`
+			}
+			for _, expected := range []string{"registry.example.com", secret} {
+				if !strings.Contains(string(data), expected) {
+					t.Errorf("expected %s to contain %q, got %s", test.expectedFile, expected, data)
+				}
+			}
+		})
+	}
+}
@@ -156,29 +156,3 @@ func NewCmdRolloutUndo(f kcmdutil.Factory, streams genericiooptions.IOStreams) *
 	cmd.ValidArgsFunction = completion.SpecifiedResourceTypeAndNameCompletionFunc(f, validArgs)
 	return cmd
 }
-
-var (
-	statusLong = templates.LongDesc(`
-		Show the status of the rollout.
-
-		By default 'rollout status' will watch the status of the latest rollout
-		until it is done. If you do not want to wait for the rollout to finish then
-		you can use --watch=false. Note that if a new rollout starts in-between, then
-		'rollout status' will continue watching the latest revision. If you want to
-		pin to a specific revision and abort if it is rolled over by another revision,
-		use --revision=N where N is the revision you need to watch for.`)
-
-	statusExample = templates.Examples(`
-		# Watch the rollout status of a deployment
-		oc rollout status deployment/nginx`)
-)
-
-// NewCmdRolloutStatus is a wrapper for the Kubernetes cli rollout status command
-func NewCmdRolloutStatus(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
-	cmd := rollout.NewCmdRolloutStatus(f, streams)
-	cmd.Long = statusLong
-	cmd.Example = statusExample
-	validArgs := []string{"deployment", "replicaset", "replicationcontroller", "statefulset", "deploymentconfig"}
-	cmd.ValidArgsFunction = completion.SpecifiedResourceTypeAndNameCompletionFunc(f, validArgs)
-	return cmd
-}
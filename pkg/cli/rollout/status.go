@@ -0,0 +1,259 @@
+package rollout
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+	"k8s.io/kubectl/pkg/cmd/rollout"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/completion"
+	"k8s.io/kubectl/pkg/util/interrupt"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	statusLong = templates.LongDesc(`
+		Show the status of the rollout.
+
+		By default 'rollout status' will watch the status of the latest rollout
+		until it is done. If you do not want to wait for the rollout to finish then
+		you can use --watch=false. Note that if a new rollout starts in-between, then
+		'rollout status' will continue watching the latest revision. If you want to
+		pin to a specific revision and abort if it is rolled over by another revision,
+		use --revision=N where N is the revision you need to watch for.
+
+		Multiple resources, like all the deployments and deployment configs of an
+		application selected with --selector, are watched at the same time, and the
+		command succeeds once all of their rollouts are done. --timeout bounds the
+		whole watch. With -o json, each status change is printed as a JSON object on
+		its own line, with the resource, the message, whether the rollout is done and
+		the error that ended the watch, if any.`)
+
+	statusExample = templates.Examples(`
+		# Watch the rollout status of a deployment
+		oc rollout status deployment/nginx
+
+		# Watch the rollouts of the deployments and deployment configs of an application for up to 10 minutes
+		oc rollout status deployment,deploymentconfig -l app=shop --timeout=10m
+
+		# Watch the rollouts of two deployments and print the progress as JSON
+		oc rollout status deployment/frontend deployment/backend -o json`)
+)
+
+// StatusOptions holds the options of 'rollout status', which watches the
+// rollouts of all the resources at once.
+type StatusOptions struct {
+	*rollout.RolloutStatusOptions
+
+	Output string
+
+	// now returns the time of the progress events
+	now func() time.Time
+}
+
+// statusEvent is a change of the status of the rollout of a resource printed with -o json.
+type statusEvent struct {
+	Time      time.Time `json:"time"`
+	Resource  string    `json:"resource"`
+	Namespace string    `json:"namespace"`
+	Message   string    `json:"message,omitempty"`
+	Done      bool      `json:"done"`
+	Error     string    `json:"error,omitempty"`
+}
+
+func NewStatusOptions(streams genericiooptions.IOStreams) *StatusOptions {
+	return &StatusOptions{
+		RolloutStatusOptions: rollout.NewRolloutStatusOptions(streams),
+		now:                  time.Now,
+	}
+}
+
+// NewCmdRolloutStatus is a replacement for the Kubernetes cli rollout status command,
+// which watches the resources one after the other.
+func NewCmdRolloutStatus(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewStatusOptions(streams)
+
+	validArgs := []string{"deployment", "replicaset", "replicationcontroller", "statefulset", "deploymentconfig"}
+	cmd := &cobra.Command{
+		Use:                   "status (TYPE NAME | TYPE/NAME)... [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Show the status of the rollout",
+		Long:                  statusLong,
+		Example:               statusExample,
+		ValidArgsFunction:     completion.SpecifiedResourceTypeAndNameCompletionFunc(f, validArgs),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	usage := "identifying the resource to get from a server."
+	kcmdutil.AddFilenameOptionFlags(cmd, o.FilenameOptions, usage)
+	cmd.Flags().BoolVarP(&o.Watch, "watch", "w", o.Watch, "Watch the status of the rollout until it's done.")
+	cmd.Flags().Int64Var(&o.Revision, "revision", o.Revision, "Pin to a specific revision for showing its status. Defaults to 0 (last revision).")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The length of time to wait for all the rollouts before ending watch, zero means never. Any other values should contain a corresponding time unit (e.g. 1s, 2m, 3h).")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json. By default, the status messages of the rollouts are printed.")
+	kcmdutil.AddLabelSelectorFlagVar(cmd, &o.LabelSelector)
+
+	return cmd
+}
+
+func (o *StatusOptions) Validate() error {
+	if err := o.RolloutStatusOptions.Validate(); err != nil {
+		return err
+	}
+	if o.Timeout < 0 {
+		return fmt.Errorf("--timeout must not be negative")
+	}
+	switch o.Output {
+	case "", "json":
+	default:
+		return fmt.Errorf("invalid output format %q, only json is supported", o.Output)
+	}
+	return nil
+}
+
+// Run watches the rollouts of all the resources until they are done, one of them
+// fails or --timeout expires.
+func (o *StatusOptions) Run() error {
+	r := o.Builder().
+		WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...).
+		NamespaceParam(o.Namespace).DefaultNamespace().
+		LabelSelectorParam(o.LabelSelector).
+		FilenameParam(o.EnforceNamespace, o.FilenameOptions).
+		ResourceTypeOrNameArgs(true, o.BuilderArgs...).
+		ContinueOnError().
+		Latest().
+		Flatten().
+		Do()
+	infos, err := r.Infos()
+	if err != nil {
+		return err
+	}
+	if len(infos) == 0 {
+		fmt.Fprintf(o.ErrOut, "No resources found in %s namespace.\n", o.Namespace)
+		return nil
+	}
+
+	ctx, cancel := watchtools.ContextWithOptionalTimeout(context.Background(), o.Timeout)
+	defer cancel()
+	intr := interrupt.New(nil, cancel)
+	return intr.Run(func() error {
+		return o.watchRollouts(ctx, infos)
+	})
+}
+
+// watchRollouts watches the resources concurrently and serializes the printing
+// of their status.
+func (o *StatusOptions) watchRollouts(ctx context.Context, infos []*resource.Info) error {
+	var lock sync.Mutex
+	report := func(info *resource.Info, message string, done bool, err error) {
+		lock.Lock()
+		defer lock.Unlock()
+		if o.Output != "json" {
+			fmt.Fprint(o.Out, message)
+			return
+		}
+		event := statusEvent{
+			Time:      o.now().UTC(),
+			Resource:  info.ObjectName(),
+			Namespace: info.Namespace,
+			Message:   strings.TrimSpace(message),
+			Done:      done,
+		}
+		if err != nil {
+			event.Error = err.Error()
+		}
+		data, _ := json.Marshal(event)
+		fmt.Fprintf(o.Out, "%s\n", data)
+	}
+
+	errs := make([]error, len(infos))
+	var wg sync.WaitGroup
+	for i, info := range infos {
+		wg.Add(1)
+		go func(i int, info *resource.Info) {
+			defer wg.Done()
+			err := o.watchRollout(ctx, info, func(status string, done bool) {
+				report(info, status, done, nil)
+			})
+			if err != nil {
+				switch {
+				case errors.Is(ctx.Err(), context.DeadlineExceeded):
+					err = fmt.Errorf("timed out waiting for the rollout of %s", info.ObjectName())
+				case ctx.Err() != nil:
+					err = fmt.Errorf("stopped watching the rollout of %s", info.ObjectName())
+				default:
+					err = fmt.Errorf("%s: %v", info.ObjectName(), err)
+				}
+				if o.Output == "json" {
+					report(info, "", false, err)
+				}
+				errs[i] = err
+			}
+		}(i, info)
+	}
+	wg.Wait()
+	return utilerrors.NewAggregate(errs)
+}
+
+// watchRollout calls report with the status of the rollout of the resource each
+// time it changes, until it is done, or once without --watch.
+func (o *StatusOptions) watchRollout(ctx context.Context, info *resource.Info, report func(status string, done bool)) error {
+	statusViewer, err := o.StatusViewerFn(info.Mapping)
+	if err != nil {
+		return err
+	}
+
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", info.Name).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return o.DynamicClient.Resource(info.Mapping.Resource).Namespace(info.Namespace).List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return o.DynamicClient.Resource(info.Mapping.Resource).Namespace(info.Namespace).Watch(ctx, options)
+		},
+	}
+
+	_, err = watchtools.UntilWithSync(ctx, lw, &unstructured.Unstructured{}, nil, func(e watch.Event) (bool, error) {
+		switch t := e.Type; t {
+		case watch.Added, watch.Modified:
+			status, done, err := statusViewer.Status(e.Object.(runtime.Unstructured), o.Revision)
+			if err != nil {
+				return false, err
+			}
+			report(status, done)
+			// quit waiting if the rollout is done, or after the first status without --watch
+			return done || !o.Watch, nil
+
+		case watch.Deleted:
+			// abort to avoid cases of recreation and not to silently watch the wrong (new) object
+			return true, fmt.Errorf("object has been deleted")
+
+		default:
+			return true, fmt.Errorf("internal error: unexpected event %#v", e)
+		}
+	})
+	return err
+}
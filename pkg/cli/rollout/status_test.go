@@ -0,0 +1,180 @@
+package rollout
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+)
+
+var deploymentsResource = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+// annotationStatusViewer reports the status annotation of the object, done when its done
+// annotation is set, and fails when its error annotation is set.
+type annotationStatusViewer struct{}
+
+func (annotationStatusViewer) Status(obj runtime.Unstructured, revision int64) (string, bool, error) {
+	annotations := obj.(*unstructured.Unstructured).GetAnnotations()
+	if err := annotations["error"]; len(err) > 0 {
+		return "", false, fmt.Errorf("%s", err)
+	}
+	return annotations["status"] + "\n", annotations["done"] == "true", nil
+}
+
+func testDeployment(namespace, status string, done bool, err string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("apps/v1")
+	obj.SetKind("Deployment")
+	obj.SetNamespace(namespace)
+	obj.SetName("web")
+	obj.SetAnnotations(map[string]string{"status": status, "done": fmt.Sprintf("%t", done), "error": err})
+	return obj
+}
+
+func testStatusOptions(out io.Writer, objects ...runtime.Object) (*StatusOptions, *dynamicfake.FakeDynamicClient, []*resource.Info) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{deploymentsResource: "DeploymentList"}, objects...)
+	o := NewStatusOptions(genericiooptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}})
+	o.DynamicClient = client
+	o.StatusViewerFn = func(*meta.RESTMapping) (polymorphichelpers.StatusViewer, error) {
+		return annotationStatusViewer{}, nil
+	}
+	o.now = func() time.Time { return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC) }
+	mapping := &meta.RESTMapping{Resource: deploymentsResource, GroupVersionKind: deploymentsResource.GroupVersion().WithKind("Deployment")}
+	var infos []*resource.Info
+	for _, obj := range objects {
+		accessor, _ := meta.Accessor(obj)
+		infos = append(infos, &resource.Info{Mapping: mapping, Namespace: accessor.GetNamespace(), Name: accessor.GetName(), Object: obj})
+	}
+	return o, client, infos
+}
+
+// notifyWriter signals each write on written.
+type notifyWriter struct {
+	bytes.Buffer
+	written chan struct{}
+}
+
+func (w *notifyWriter) Write(p []byte) (int, error) {
+	n, err := w.Buffer.Write(p)
+	select {
+	case w.written <- struct{}{}:
+	default:
+	}
+	return n, err
+}
+
+func statusEvents(t *testing.T, out string) []statusEvent {
+	t.Helper()
+	var events []statusEvent
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		var event statusEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("unable to parse the event %q: %v", line, err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestWatchRolloutsEvents(t *testing.T) {
+	out := &notifyWriter{written: make(chan struct{}, 1)}
+	o, client, infos := testStatusOptions(out, testDeployment("ns", "Waiting for rollout to finish: 0 of 1 updated replicas are available...", false, ""))
+	o.Watch = true
+	o.Output = "json"
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	updateErr := make(chan error, 1)
+	go func() {
+		// finish the rollout once its first status was reported
+		select {
+		case <-ctx.Done():
+			updateErr <- ctx.Err()
+		case <-out.written:
+			done := testDeployment("ns", `deployment "web" successfully rolled out`, true, "")
+			_, err := client.Resource(deploymentsResource).Namespace("ns").Update(ctx, done, metav1.UpdateOptions{})
+			updateErr <- err
+		}
+	}()
+	if err := o.watchRollouts(ctx, infos); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-updateErr; err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []statusEvent{
+		{Time: o.now(), Resource: "deployments/web", Namespace: "ns", Message: "Waiting for rollout to finish: 0 of 1 updated replicas are available..."},
+		{Time: o.now(), Resource: "deployments/web", Namespace: "ns", Message: `deployment "web" successfully rolled out`, Done: true},
+	}
+	events := statusEvents(t, out.String())
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %q", len(expected), out.String())
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("expected the event %#v, got %#v", expected[i], events[i])
+		}
+	}
+}
+
+func TestWatchRolloutsTimeout(t *testing.T) {
+	out := &bytes.Buffer{}
+	o, _, infos := testStatusOptions(out, testDeployment("ns", "Waiting for rollout to finish", false, ""))
+	o.Watch = true
+	o.Output = "json"
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	err := o.watchRollouts(ctx, infos)
+	if err == nil || err.Error() != "timed out waiting for the rollout of deployments/web" {
+		t.Fatalf("expected the rollout to time out, got %v", err)
+	}
+	events := statusEvents(t, out.String())
+	if last := events[len(events)-1]; last.Error != err.Error() || last.Done {
+		t.Errorf("expected the timeout to be reported as the last event, got %#v", last)
+	}
+}
+
+func TestWatchRolloutsErrors(t *testing.T) {
+	out := &bytes.Buffer{}
+	o, _, infos := testStatusOptions(out,
+		testDeployment("done", `deployment "web" successfully rolled out`, true, ""),
+		testDeployment("failed", "", false, `deployment "web" exceeded its progress deadline`),
+		testDeployment("broken", "", false, "unable to read the status"),
+	)
+	o.Watch = true
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err := o.watchRollouts(ctx, infos)
+	if err == nil {
+		t.Fatal("expected the failed rollouts to be reported")
+	}
+	for _, expected := range []string{
+		`deployments/web: deployment "web" exceeded its progress deadline`,
+		"deployments/web: unable to read the status",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected the error to contain %q, got %v", expected, err)
+		}
+	}
+	// the rollouts that are done are still reported
+	if out.String() != "deployment \"web\" successfully rolled out\n" {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+}
@@ -13,6 +13,8 @@ import (
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/completion"
 	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/oc/pkg/helpers/asciicast"
 )
 
 const (
@@ -58,6 +60,9 @@ var (
 
 		# Open a shell session on the container named 'index' inside a pod of your job
 		oc rsh -c index job/scheduled
+
+		# Open a shell session in pod 'foo' and record it, without what is typed, for 'asciinema play'
+		oc rsh --record=session.cast --record-input=false foo
 	`)
 )
 
@@ -66,6 +71,7 @@ type RshOptions struct {
 	ForceTTY   bool
 	DisableTTY bool
 	Executable string
+	Record     *asciicast.Options
 	*exec.ExecOptions
 }
 
@@ -74,6 +80,7 @@ func NewRshOptions(streams genericiooptions.IOStreams) *RshOptions {
 		ForceTTY:   false,
 		DisableTTY: false,
 		Executable: DefaultShell,
+		Record:     asciicast.NewOptions(),
 		ExecOptions: &exec.ExecOptions{
 			StreamOptions: exec.StreamOptions{
 				IOStreams: streams,
@@ -108,6 +115,7 @@ func NewCmdRsh(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Co
 	cmd.Flags().BoolVarP(&o.DisableTTY, "no-tty", "T", o.DisableTTY, "Disable pseudo-terminal allocation")
 	cmd.Flags().StringVar(&o.Executable, "shell", o.Executable, "Path to the shell command")
 	cmd.Flags().StringVarP(&o.ContainerName, "container", "c", o.ContainerName, "Container name; defaults to first container")
+	o.Record.AddFlags(cmd.Flags())
 	// For consistencty with rsh API (https://linux.die.net/man/1/rsh) we don't
 	// allow '--' and we need this flag enabled explicitly, otherwise two things
 	// will break:
@@ -188,5 +196,10 @@ func (o *RshOptions) Run() error {
 		termsh := fmt.Sprintf("TERM=%q %s", term, DefaultShell)
 		o.Command = append(o.Command, "-c", termsh)
 	}
+	closeRecording, err := o.Record.Wrap(o.ExecOptions, "oc rsh "+o.ResourceName)
+	if err != nil {
+		return err
+	}
+	defer closeRecording()
 	return o.ExecOptions.Run()
 }
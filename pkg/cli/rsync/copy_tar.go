@@ -2,12 +2,14 @@ package rsync
 
 import (
 	"bytes"
+	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -20,16 +22,26 @@ import (
 // tarStrategy implements the tar copy strategy.
 // The tar strategy consists of creating a tar of the file contents to copy
 // and then streaming them to/from the container to the destination to a tar
-// command waiting for STDIN input. If the --delete flag is specified, the
-// contents of the destination directory are first cleared before the copy.
+// command waiting for STDIN input. The --include and --exclude patterns are
+// applied to the tar stream locally, with the same semantics as rsync. If the
+// --delete flag is specified, files in the destination directory that are not
+// in the source and are not excluded are deleted before the copy.
+// If the --compress flag is specified, the tar stream is compressed with gzip
+// while it is transferred, and --bwlimit and --progress apply to the transfer
+// of the tar stream to or from the container.
 // The tar strategy requires that the remote container contain the tar command.
 type tarStrategy struct {
-	Quiet          bool
-	Delete         bool
+	Quiet    bool
+	Delete   bool
+	Compress bool
+	Progress bool
+	BWLimit  int
+	// ProgressOut receives progress reports directly, since the error output
+	// passed to Copy may be buffered until the copy completes.
+	ProgressOut    io.Writer
 	Tar            tar.Tar
 	RemoteExecutor executor
-	Includes       []string
-	Excludes       []string
+	Filter         *pathFilter
 	IgnoredFlags   []string
 	Flags          []string
 }
@@ -47,8 +59,11 @@ func NewTarStrategy(o *RsyncOptions) CopyStrategy {
 	return &tarStrategy{
 		Quiet:          o.Quiet,
 		Delete:         o.Delete,
-		Includes:       o.RsyncInclude,
-		Excludes:       o.RsyncExclude,
+		Compress:       o.Compress,
+		Progress:       o.RsyncProgress,
+		BWLimit:        o.RsyncBWLimit,
+		ProgressOut:    o.ErrOut,
+		Filter:         newPathFilter(o.RsyncInclude, o.RsyncExclude),
 		Tar:            tarHelper,
 		RemoteExecutor: remoteExec,
 		IgnoredFlags:   ignoredFlags,
@@ -56,61 +71,140 @@ func NewTarStrategy(o *RsyncOptions) CopyStrategy {
 	}
 }
 
-func deleteContents(dir string) error {
-	klog.V(4).Infof("Deleting local directory contents: %s", dir)
-	files, err := os.ReadDir(dir)
-	if err != nil {
-		klog.V(4).Infof("Could not read directory %s: %v", dir, err)
-		return err
+// deletePrefix returns the path, relative to the destination, of the directory
+// whose extraneous contents --delete removes. If the source does not end in a
+// path separator, the directory itself is copied and it is the directory of
+// the same name in the destination, mirroring rsync.
+func deletePrefix(source *PathSpec) string {
+	if source.Local() {
+		if strings.HasSuffix(source.Path, "/") || strings.HasSuffix(source.Path, string(filepath.Separator)) {
+			return ""
+		}
+		return filepath.Base(source.Path)
+	}
+	if strings.HasSuffix(source.Path, "/") {
+		return ""
 	}
-	for _, f := range files {
-		if f.IsDir() {
-			klog.V(5).Infof("Deleting directory: %s", f.Name())
-			err = os.RemoveAll(filepath.Clean(filepath.Join(dir, f.Name())))
-		} else {
-			klog.V(5).Infof("Deleting file: %s", f.Name())
-			err = os.Remove(filepath.Clean(filepath.Join(dir, f.Name())))
+	return path.Base(source.Path)
+}
+
+// extraneousFiles returns the existing paths in the destination that were not
+// copied from the source and are not protected by the filter, given as a list
+// of paths relative to the destination mapped to whether they are a directory.
+// The contents of a returned directory are not returned.
+func extraneousFiles(existing []string, isDir map[string]bool, copied map[string]bool, filter *pathFilter) []string {
+	sort.Strings(existing)
+	var extraneous []string
+	var removedDir string
+	for _, name := range existing {
+		if len(removedDir) > 0 && strings.HasPrefix(name, removedDir+"/") {
+			continue
+		}
+		dir := isDir[name]
+		// like rsync, excluded files in the destination are not deleted
+		if filter.Excluded(name, dir) {
+			continue
+		}
+		if copiedDir, ok := copied[name]; ok && copiedDir == dir {
+			continue
+		}
+		extraneous = append(extraneous, name)
+		if dir {
+			removedDir = name
+		}
+	}
+	return extraneous
+}
+
+func deleteLocal(source, dest *PathSpec, copied map[string]bool, filter *pathFilter) error {
+	root := filepath.Join(dest.Path, deletePrefix(source))
+	klog.V(4).Infof("Deleting extraneous files in local directory %s", root)
+	isDir := map[string]bool{}
+	var existing []string
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == root {
+			return nil
 		}
+		rel, err := filepath.Rel(dest.Path, p)
 		if err != nil {
-			klog.V(4).Infof("Error deleting file or directory: %s: %v", f.Name(), err)
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		existing = append(existing, name)
+		isDir[name] = info.IsDir()
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, name := range extraneousFiles(existing, isDir, copied, filter) {
+		klog.V(5).Infof("Deleting %s", name)
+		if err := os.RemoveAll(filepath.Join(dest.Path, filepath.FromSlash(name))); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func deleteLocal(source, dest *PathSpec) error {
-	deleteDir := dest.Path
-	// Determine which directory to empty based on source parameter
-	// If the source does not end in a path separator, the directory
-	// being copied over is the directory that needs to be cleaned out
-	// in the destination. This is to replicate the behavior of the
-	// rsync --delete flag
-	if !strings.HasSuffix(source.Path, "/") {
-		deleteDir = filepath.Join(deleteDir, filepath.Base(source.Path))
-	}
-	return deleteContents(deleteDir)
-}
+// listRemoteScript lists the contents of the directory passed as its first
+// argument, prefixing each path with d for a directory or f otherwise.
+const listRemoteScript = `[ -d "$1" ] || exit 0; find "$1" -mindepth 1 \( -type d -exec printf 'd %s\n' {} + \) -o -exec printf 'f %s\n' {} +`
 
-func deleteRemote(source, dest *PathSpec, ex executor) error {
-	// Determine which directory to empty based on source parameter
-	// If the source does not end in a path separator, the directory
-	// being copied over is the directory that needs to be cleaned out
-	// in the destination. This is to replicate the behavior of the
-	// rsync --delete flag
-	deleteDir := dest.Path
-	if !strings.HasSuffix(source.Path, string(filepath.Separator)) {
-		deleteDir = path.Join(deleteDir, path.Base(source.Path))
-	}
-	deleteCmd := []string{"sh", "-c", fmt.Sprintf("shopt -s dotglob && rm -rf %s", path.Join(deleteDir, "*"))}
-	return executeWithLogging(ex, deleteCmd)
+// deleteRemoteBatchSize bounds the number of paths passed to a single remote rm command.
+const deleteRemoteBatchSize = 100
+
+func deleteRemote(source, dest *PathSpec, copied map[string]bool, filter *pathFilter, ex executor) error {
+	root := path.Join(dest.Path, deletePrefix(source))
+	klog.V(4).Infof("Deleting extraneous files in remote directory %s", root)
+	listing, errBuf := &bytes.Buffer{}, &bytes.Buffer{}
+	if err := ex.Execute([]string{"sh", "-c", listRemoteScript, "sh", root}, nil, listing, errBuf); err != nil {
+		return fmt.Errorf("unable to list %s in the container: %v: %s", root, err, strings.TrimSpace(errBuf.String()))
+	}
+	base := path.Clean(dest.Path)
+	if base != "/" {
+		base += "/"
+	}
+	isDir := map[string]bool{}
+	var existing []string
+	for _, line := range strings.Split(listing.String(), "\n") {
+		if len(line) < 3 {
+			continue
+		}
+		rel := strings.TrimPrefix(path.Clean(line[2:]), base)
+		existing = append(existing, rel)
+		isDir[rel] = line[0] == 'd'
+	}
+	extraneous := extraneousFiles(existing, isDir, copied, filter)
+	for len(extraneous) > 0 {
+		n := len(extraneous)
+		if n > deleteRemoteBatchSize {
+			n = deleteRemoteBatchSize
+		}
+		cmd := []string{"rm", "-rf", "--"}
+		for _, name := range extraneous[:n] {
+			cmd = append(cmd, path.Join(dest.Path, name))
+		}
+		if err := executeWithLogging(ex, cmd); err != nil {
+			return err
+		}
+		extraneous = extraneous[n:]
+	}
+	return nil
 }
 
-func deleteFiles(source, dest *PathSpec, remoteExecutor executor) error {
+// deleteFiles implements the rsync --delete flag by removing the files in the
+// destination that were not copied from the source.
+func deleteFiles(source, dest *PathSpec, copied map[string]bool, filter *pathFilter, remoteExecutor executor) error {
 	if dest.Local() {
-		return deleteLocal(source, dest)
+		return deleteLocal(source, dest, copied, filter)
 	}
-	return deleteRemote(source, dest, remoteExecutor)
+	return deleteRemote(source, dest, copied, filter, remoteExecutor)
 }
 
 func (r *tarStrategy) Copy(source, destination *PathSpec, out, errOut io.Writer) error {
@@ -121,13 +215,6 @@ func (r *tarStrategy) Copy(source, destination *PathSpec, out, errOut io.Writer)
 		fmt.Fprintf(errOut, "Ignoring the following flags because they only apply to rsync: %s\n", strings.Join(r.IgnoredFlags, ", "))
 	}
 
-	if r.Delete {
-		// Implement the rsync --delete flag as a separate call to first delete directory contents
-		err := deleteFiles(source, destination, r.RemoteExecutor)
-		if err != nil {
-			return fmt.Errorf("unable to delete files in destination: %v", err)
-		}
-	}
 	tmp, err := os.CreateTemp("", "rsync")
 	if err != nil {
 		return fmt.Errorf("cannot create local temporary file for tar: %v", err)
@@ -145,7 +232,9 @@ func (r *tarStrategy) Copy(source, destination *PathSpec, out, errOut io.Writer)
 	} else {
 		klog.V(4).Infof("Creating local tar file %s from remote path %s", tmp.Name(), source.Path)
 		errBuf := &bytes.Buffer{}
-		err = tarRemote(r.RemoteExecutor, source.Path, r.Includes, r.Excludes, tmp, errBuf)
+		meter := r.transferMeter(0)
+		err = tarRemote(r.RemoteExecutor, source.Path, r.Filter.RemoteExcludes(), r.Compress, meter.Writer(tmp), errBuf)
+		meter.Finish()
 		if err != nil {
 			if checkTar(r.RemoteExecutor) != nil {
 				return strategySetupError("tar not available in container")
@@ -159,14 +248,41 @@ func (r *tarStrategy) Copy(source, destination *PathSpec, out, errOut io.Writer)
 		return fmt.Errorf("error resetting position in a temporary tar file %s: %v", tmp.Name(), err)
 	}
 
+	// Apply --include and --exclude with the same semantics as rsync, which tar does not share
+	filtered, err := os.CreateTemp("", "rsync")
+	if err != nil {
+		return fmt.Errorf("cannot create local temporary file for tar: %v", err)
+	}
+	defer filtered.Close()
+	defer os.Remove(filtered.Name())
+	copied, err := r.filterTar(tmp, !source.Local(), filtered, !destination.Local())
+	if err != nil {
+		return fmt.Errorf("error filtering tar of source directory: %v", err)
+	}
+	if _, err := filtered.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("error resetting position in a temporary tar file %s: %v", filtered.Name(), err)
+	}
+
+	if r.Delete {
+		if err := deleteFiles(source, destination, copied, r.Filter, r.RemoteExecutor); err != nil {
+			return fmt.Errorf("unable to delete files in destination: %v", err)
+		}
+	}
+
 	// Extract tar
 	if destination.Local() {
-		klog.V(4).Infof("Untarring temp file %s to local directory %s", tmp.Name(), destination.Path)
-		err = untarLocal(r.Tar, destination.Path, tmp, r.Quiet, out)
+		klog.V(4).Infof("Untarring temp file %s to local directory %s", filtered.Name(), destination.Path)
+		err = untarLocal(r.Tar, destination.Path, filtered, r.Quiet, out)
 	} else {
-		klog.V(4).Infof("Untarring temp file %s to remote directory %s", tmp.Name(), destination.Path)
+		klog.V(4).Infof("Untarring temp file %s to remote directory %s", filtered.Name(), destination.Path)
+		var size int64
+		if info, statErr := filtered.Stat(); statErr == nil {
+			size = info.Size()
+		}
 		errBuf := &bytes.Buffer{}
-		err = untarRemote(r.RemoteExecutor, destination.Path, r.Flags, tmp, out, errBuf)
+		meter := r.transferMeter(size)
+		err = untarRemote(r.RemoteExecutor, destination.Path, r.Flags, meter.Reader(filtered), out, errBuf)
+		meter.Finish()
 		if err != nil {
 			if checkTar(r.RemoteExecutor) != nil {
 				return strategySetupError("tar not available in container")
@@ -194,35 +310,58 @@ func (r *tarStrategy) Validate() error {
 	return nil
 }
 
+// transferMeter returns the meter for the tar stream transferred to or from
+// the container, which reports progress when requested.
+func (r *tarStrategy) transferMeter(total int64) *transferMeter {
+	var progress io.Writer
+	if r.Progress && !r.Quiet {
+		progress = r.ProgressOut
+	}
+	return newTransferMeter(r.BWLimit, progress, total)
+}
+
+// filterTar copies the tar in to out without the entries excluded by the
+// filter, decompressing or compressing the tar when it was or will be
+// transferred compressed. It returns the entries that were copied.
+func (r *tarStrategy) filterTar(in io.Reader, decompress bool, out io.Writer, compress bool) (map[string]bool, error) {
+	if r.Compress && decompress {
+		gz, err := gzip.NewReader(in)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		in = gz
+	}
+	if !r.Compress || !compress {
+		return filterTar(in, out, r.Filter)
+	}
+	gz := gzip.NewWriter(out)
+	copied, err := filterTar(in, gz, r.Filter)
+	if err != nil {
+		return nil, err
+	}
+	return copied, gz.Close()
+}
+
 func (r *tarStrategy) String() string {
 	return "tar"
 }
 
-func tarRemote(exec executor, sourceDir string, includes, excludes []string, out, errOut io.Writer) error {
+func tarRemote(exec executor, sourceDir string, excludes []string, compress bool, out, errOut io.Writer) error {
 	klog.V(4).Infof("Tarring %s remotely", sourceDir)
 
-	exclude := []string{}
-	for _, pattern := range excludes {
-		exclude = append(exclude, fmt.Sprintf("--exclude=%s", pattern))
+	create := "-c"
+	if compress {
+		create = "-cz"
 	}
 
 	var cmd []string
 	if strings.HasSuffix(sourceDir, "/") {
-		include := []string{"."}
-		include = append(include, includes...)
-
-		cmd = []string{"tar", "-C", sourceDir, "-c"}
-		cmd = append(cmd, append(include, exclude...)...)
+		cmd = []string{"tar", "-C", sourceDir, create, "."}
 	} else {
-		include := []string{}
-
-		for _, pattern := range includes {
-			include = append(include, path.Join(path.Base(sourceDir), pattern))
-		}
-
-		cmd = []string{"tar", "-C", path.Dir(sourceDir), "-c", path.Base(sourceDir)}
-		cmd = append(cmd, append(include, exclude...)...)
+		cmd = []string{"tar", "-C", path.Dir(sourceDir), create, path.Base(sourceDir)}
 	}
+	cmd = append(cmd, excludes...)
 	klog.V(4).Infof("Remote tar command: %s", strings.Join(cmd, " "))
 	return exec.Execute(cmd, nil, out, errOut)
 }
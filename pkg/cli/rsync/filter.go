@@ -0,0 +1,239 @@
+package rsync
+
+import (
+	"archive/tar"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// filterRule is a single --include or --exclude pattern, interpreted as rsync does:
+//
+//   - a leading / anchors the pattern to the root of the transfer, otherwise
+//     the pattern may match at any directory boundary
+//   - a trailing / only matches directories
+//   - a pattern without a / (other than a trailing one) or ** only matches the
+//     final component of a path
+//   - * matches anything but a /, ** matches anything, ? matches any single
+//     character but a /, [...] matches a character class and \ escapes a wildcard
+//   - a trailing /*** matches the directory itself and everything inside it
+type filterRule struct {
+	pattern string
+	include bool
+	dirOnly bool
+	// baseOnly rules only match the final component of a path.
+	baseOnly bool
+	re       *regexp.Regexp
+}
+
+func newFilterRule(pattern string, include bool) filterRule {
+	rule := filterRule{pattern: pattern, include: include}
+
+	p := pattern
+	var suffix string
+	if strings.HasSuffix(p, "/***") {
+		p = strings.TrimSuffix(p, "/***")
+		suffix = "(/.*)?"
+	} else if strings.HasSuffix(p, "/") {
+		p = strings.TrimSuffix(p, "/")
+		rule.dirOnly = true
+	}
+	anchored := strings.HasPrefix(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	rule.baseOnly = !anchored && len(suffix) == 0 && !strings.Contains(p, "/") && !strings.Contains(p, "**")
+
+	prefix := "^"
+	if !anchored && !rule.baseOnly {
+		prefix = "(^|/)"
+	}
+	rule.re = regexp.MustCompile(prefix + globToRegexp(p) + suffix + "$")
+	return rule
+}
+
+// matches returns true if the rule matches name, a slash separated path relative
+// to the root of the transfer.
+func (r filterRule) matches(name string, dir bool) bool {
+	if r.dirOnly && !dir {
+		return false
+	}
+	if r.baseOnly {
+		name = path.Base(name)
+	}
+	return r.re.MatchString(name)
+}
+
+// globToRegexp translates an rsync wildcard pattern to a regular expression.
+func globToRegexp(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				for i+1 < len(pattern) && pattern[i+1] == '*' {
+					i++
+				}
+				b.WriteString(".*")
+				continue
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				c = pattern[i]
+			}
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// hasWildcards returns true if pattern contains any rsync wildcard characters.
+func hasWildcards(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
+
+// pathFilter decides which paths of a transfer are copied. Like rsync, the
+// first rule to match a path decides whether it is included, paths that match
+// no rule are included, and the contents of an excluded directory are excluded.
+// The --include patterns are checked before the --exclude patterns.
+type pathFilter struct {
+	rules []filterRule
+	// excludedDirs caches whether each directory checked so far is excluded.
+	excludedDirs map[string]bool
+}
+
+func newPathFilter(includes, excludes []string) *pathFilter {
+	f := &pathFilter{excludedDirs: map[string]bool{}}
+	for _, pattern := range includes {
+		f.rules = append(f.rules, newFilterRule(pattern, true))
+	}
+	for _, pattern := range excludes {
+		f.rules = append(f.rules, newFilterRule(pattern, false))
+	}
+	return f
+}
+
+// excludedByRule returns true if the first rule that matches name excludes it.
+func (f *pathFilter) excludedByRule(name string, dir bool) bool {
+	for _, rule := range f.rules {
+		if rule.matches(name, dir) {
+			klog.V(5).Infof("%s matches %s pattern %q", name, map[bool]string{true: "include", false: "exclude"}[rule.include], rule.pattern)
+			return !rule.include
+		}
+	}
+	return false
+}
+
+// Excluded returns true if name, or any directory containing it, is excluded.
+func (f *pathFilter) Excluded(name string, dir bool) bool {
+	if len(f.rules) == 0 {
+		return false
+	}
+	name = strings.Trim(name, "/")
+	if len(name) == 0 || name == "." {
+		return false
+	}
+	if parent := path.Dir(name); parent != "." && f.excludedDir(parent) {
+		return true
+	}
+	if dir {
+		return f.excludedDir(name)
+	}
+	return f.excludedByRule(name, false)
+}
+
+func (f *pathFilter) excludedDir(name string) bool {
+	if excluded, ok := f.excludedDirs[name]; ok {
+		return excluded
+	}
+	excluded := false
+	if parent := path.Dir(name); parent != "." {
+		excluded = f.excludedDir(parent)
+	}
+	if !excluded {
+		excluded = f.excludedByRule(name, true)
+	}
+	f.excludedDirs[name] = excluded
+	return excluded
+}
+
+// RemoteExcludes returns --exclude arguments for a remote tar command that
+// skip excluded paths in the container, so they are not transferred at all.
+// Only literal names can be passed on safely, since tar does not share the
+// wildcard semantics of rsync, and only when no include rule could bring an
+// excluded path back in.
+func (f *pathFilter) RemoteExcludes() []string {
+	var args []string
+	for _, rule := range f.rules {
+		if rule.include {
+			return nil
+		}
+	}
+	for _, rule := range f.rules {
+		if !rule.baseOnly || rule.dirOnly || hasWildcards(rule.pattern) {
+			continue
+		}
+		args = append(args, "--exclude="+rule.pattern)
+	}
+	return args
+}
+
+// tarEntryName returns the name of a tar entry relative to the root of the transfer.
+func tarEntryName(name string) string {
+	name = strings.TrimPrefix(name, "./")
+	return strings.TrimSuffix(name, "/")
+}
+
+// filterTar copies the entries of the tar stream in that are not excluded by
+// the filter to out, and returns the names of the copied entries, relative
+// to the root of the transfer, mapped to whether they are a directory.
+func filterTar(in io.Reader, out io.Writer, f *pathFilter) (map[string]bool, error) {
+	tr := tar.NewReader(in)
+	tw := tar.NewWriter(out)
+	copied := map[string]bool{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := tarEntryName(header.Name)
+		dir := header.Typeflag == tar.TypeDir
+		if f.Excluded(name, dir) {
+			klog.V(5).Infof("Excluding %s", header.Name)
+			continue
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return nil, err
+		}
+		if len(name) > 0 && name != "." {
+			copied[name] = dir
+		}
+	}
+	return copied, tw.Close()
+}
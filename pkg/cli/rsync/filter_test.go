@@ -0,0 +1,151 @@
+package rsync
+
+import (
+	"archive/tar"
+	"bytes"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestPathFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		includes []string
+		excludes []string
+		excluded map[string]bool
+		dirs     []string
+	}{
+		{
+			name:     "base name",
+			excludes: []string{"*.log"},
+			excluded: map[string]bool{"a.log": true, "dir/b.log": true, "dir/b.txt": false, "a.log.txt": false},
+		},
+		{
+			name:     "anchored",
+			excludes: []string{"/build"},
+			dirs:     []string{"build", "src/build"},
+			excluded: map[string]bool{"build": true, "build/out.o": true, "src/build": false, "src/build/out.o": false},
+		},
+		{
+			name:     "directory only",
+			excludes: []string{"cache/"},
+			dirs:     []string{"cache", "src/cache"},
+			excluded: map[string]bool{"cache": true, "cache/x": true, "src/cache/x": true, "src/cache.go": false, "file/cache": false},
+		},
+		{
+			name:     "unanchored with slash",
+			excludes: []string{"docs/*.md"},
+			dirs:     []string{"docs", "sub/docs"},
+			excluded: map[string]bool{"docs/a.md": true, "sub/docs/a.md": true, "docs/sub/a.md": false, "mydocs/a.md": false},
+		},
+		{
+			name:     "double star",
+			excludes: []string{"vendor/**/testdata"},
+			dirs:     []string{"vendor", "vendor/a", "vendor/a/b", "vendor/a/b/testdata"},
+			excluded: map[string]bool{"vendor/a/b/testdata": true, "vendor/a/b/testdata/x": true, "vendor/a/b": false},
+		},
+		{
+			name:     "triple star",
+			excludes: []string{"/out/***"},
+			dirs:     []string{"out"},
+			excluded: map[string]bool{"out": true, "out/a/b": true, "output": false},
+		},
+		{
+			name:     "include before exclude",
+			includes: []string{"*.go", "*/"},
+			excludes: []string{"*"},
+			dirs:     []string{"pkg"},
+			excluded: map[string]bool{"main.go": false, "pkg/util.go": false, "README.md": true, "pkg/doc.txt": true},
+		},
+		{
+			name:     "excluded directory contents are not included",
+			includes: []string{"*.go"},
+			excludes: []string{"*"},
+			dirs:     []string{"pkg"},
+			excluded: map[string]bool{"main.go": false, "pkg": true, "pkg/util.go": true},
+		},
+		{
+			name:     "character class and escapes",
+			excludes: []string{"file[0-9].txt", `\*.txt`, "?.tmp"},
+			excluded: map[string]bool{"file1.txt": true, "filea.txt": false, "*.txt": true, "a.txt": false, "a.tmp": true, "ab.tmp": false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newPathFilter(tt.includes, tt.excludes)
+			dirs := map[string]bool{}
+			for _, dir := range tt.dirs {
+				dirs[dir] = true
+			}
+			for name, expected := range tt.excluded {
+				if actual := f.Excluded(name, dirs[name]); actual != expected {
+					t.Errorf("%s: expected excluded=%t, got %t", name, expected, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestRemoteExcludes(t *testing.T) {
+	if args := newPathFilter(nil, []string{"node_modules", "*.log", "/build", "cache/", ".git"}).RemoteExcludes(); !reflect.DeepEqual(args, []string{"--exclude=node_modules", "--exclude=.git"}) {
+		t.Errorf("unexpected remote excludes: %v", args)
+	}
+	if args := newPathFilter([]string{"keep"}, []string{"node_modules"}).RemoteExcludes(); len(args) != 0 {
+		t.Errorf("expected no remote excludes with include rules, got %v", args)
+	}
+}
+
+func TestFilterTar(t *testing.T) {
+	in := &bytes.Buffer{}
+	tw := tar.NewWriter(in)
+	for _, entry := range []struct {
+		name string
+		dir  bool
+	}{{"./", true}, {"./src/", true}, {"./src/main.go", false}, {"./src/main.log", false}, {"./tmp/", true}, {"./tmp/x", false}} {
+		header := &tar.Header{Name: entry.name, Typeflag: tar.TypeReg, Mode: 0644}
+		if entry.dir {
+			header.Typeflag = tar.TypeDir
+			header.Mode = 0755
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	copied, err := filterTar(in, out, newPathFilter(nil, []string{"*.log", "tmp/"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]bool{"src": true, "src/main.go": false}; !reflect.DeepEqual(copied, expected) {
+		t.Errorf("expected %v to be copied, got %v", expected, copied)
+	}
+
+	var names []string
+	tr := tar.NewReader(out)
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, header.Name)
+	}
+	if expected := []string{"./", "./src/", "./src/main.go"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected entries %v, got %v", expected, names)
+	}
+}
+
+func TestExtraneousFiles(t *testing.T) {
+	existing := []string{"src", "src/main.go", "src/old.go", "old", "old/a", "old/b", "app.log", "lib"}
+	isDir := map[string]bool{"src": true, "old": true, "lib": false}
+	copied := map[string]bool{"src": true, "src/main.go": false, "lib": true}
+	actual := extraneousFiles(existing, isDir, copied, newPathFilter(nil, []string{"*.log"}))
+	sort.Strings(actual)
+	if expected := []string{"lib", "old", "src/old.go"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v to be deleted, got %v", expected, actual)
+	}
+}
@@ -43,6 +43,20 @@ var (
 
 		The following flags are passed to rsync by default:
 		--archive --no-owner --no-group --omit-dir-times --numeric-ids
+
+		If rsync is not available locally or in the container, the tar strategy is used
+		instead. The tar strategy honors --compress by compressing the tar stream with gzip,
+		--bwlimit by throttling the stream, and --progress by reporting how much of the
+		stream has been transferred. The container must have the tar command, and gzip for
+		--compress.
+
+		The --include and --exclude patterns follow the rsync filter rules with every
+		strategy: a leading / anchors a pattern to the root of the transfer, a trailing /
+		only matches directories, * does not match a / while ** does, and the contents of
+		an excluded directory are excluded. Include patterns are checked before exclude
+		patterns and the first pattern that matches a path decides whether it is copied.
+		With the tar strategy, --delete removes files in the destination that are not in
+		the source, except for excluded files, as rsync does.
 	`)
 
 	rsyncExample = templates.Examples(`
@@ -51,6 +65,12 @@ var (
 
 		# Synchronize a pod directory with a local directory
 		oc rsync POD:/remote/dir/ ./local/dir
+
+		# Synchronize only the Go sources of a local directory with a pod directory
+		oc rsync --include='*.go' --include='*/' --exclude='*' ./src/ POD:/remote/src
+
+		# Copy a large directory to a pod without rsync, compressed and limited to 5 MiB/s
+		oc rsync --strategy=tar --compress --bwlimit=5120 --progress ./data/ POD:/remote/data
 	`)
 
 	rsyncDefaultFlags = []string{"--archive", "--no-owner", "--no-group", "--omit-dir-times", "--numeric-ids"}
@@ -97,6 +117,7 @@ type RsyncOptions struct {
 	RsyncExclude  []string
 	RsyncProgress bool
 	RsyncNoPerms  bool
+	RsyncBWLimit  int
 
 	Config *rest.Config
 	Client kubernetes.Interface
@@ -137,6 +158,7 @@ func NewCmdRsync(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.
 	cmd.Flags().BoolVar(&o.RsyncNoPerms, "no-perms", false, "If true, do not transfer permissions")
 	cmd.Flags().BoolVarP(&o.Watch, "watch", "w", false, "Watch directory for changes and resync automatically")
 	cmd.Flags().BoolVar(&o.Compress, "compress", false, "compress file data during the transfer")
+	cmd.Flags().IntVar(&o.RsyncBWLimit, "bwlimit", 0, "Limit the transfer rate to the given number of kibibytes per second, 0 for no limit")
 
 	return cmd
 }
@@ -248,6 +270,9 @@ func (o *RsyncOptions) Validate() error {
 		return errors.New("rsync is only valid between a local directory and a pod directory; " +
 			"specify a pod directory as [PODNAME]:[DIR]")
 	}
+	if o.RsyncBWLimit < 0 {
+		return errors.New("--bwlimit must be zero or a positive number of kibibytes per second")
+	}
 	if o.Destination.Local() && o.Watch {
 		return errors.New("\"--watch\" can only be used with a local source directory")
 	}
@@ -0,0 +1,141 @@
+package rsync
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	units "github.com/docker/go-units"
+)
+
+const (
+	// progressInterval is how often transfer progress is reported.
+	progressInterval = 500 * time.Millisecond
+
+	// bwlimitChunkSize bounds how much data is transferred between checks of the
+	// bandwidth limit, so slow limits are enforced smoothly.
+	bwlimitChunkSize = 32 * 1024
+)
+
+// transferMeter limits the rate of and reports progress on the tar stream
+// sent to or received from the pod. A zero bwlimit does not limit the rate and
+// a nil progress writer does not report progress.
+type transferMeter struct {
+	// bwlimit is the maximum transfer rate in bytes per second.
+	bwlimit  int64
+	progress io.Writer
+	// total is the expected size of the transfer, or zero if unknown.
+	total int64
+
+	transferred  int64
+	start        time.Time
+	lastReported time.Time
+
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+func newTransferMeter(bwlimitKBPS int, progress io.Writer, total int64) *transferMeter {
+	return &transferMeter{
+		bwlimit:  int64(bwlimitKBPS) * 1024,
+		progress: progress,
+		total:    total,
+		now:      time.Now,
+		sleep:    time.Sleep,
+	}
+}
+
+// Reader returns a reader that accounts every read from r against the meter.
+func (m *transferMeter) Reader(r io.Reader) io.Reader {
+	return &meteredReader{meter: m, r: r}
+}
+
+// Writer returns a writer that accounts every write to w against the meter.
+func (m *transferMeter) Writer(w io.Writer) io.Writer {
+	return &meteredWriter{meter: m, w: w}
+}
+
+// chunk returns the largest amount of data that may be transferred at once.
+func (m *transferMeter) chunk(n int) int {
+	if m.bwlimit > 0 && n > bwlimitChunkSize {
+		return bwlimitChunkSize
+	}
+	return n
+}
+
+// add records n transferred bytes, waits until the transfer is back under the
+// bandwidth limit, and reports progress if it is due.
+func (m *transferMeter) add(n int) {
+	now := m.now()
+	if m.start.IsZero() {
+		m.start = now
+		m.lastReported = now
+	}
+	m.transferred += int64(n)
+
+	if m.bwlimit > 0 {
+		expected := time.Duration(float64(m.transferred) / float64(m.bwlimit) * float64(time.Second))
+		if elapsed := now.Sub(m.start); elapsed < expected {
+			m.sleep(expected - elapsed)
+			now = m.now()
+		}
+	}
+
+	if m.progress != nil && now.Sub(m.lastReported) >= progressInterval {
+		m.lastReported = now
+		fmt.Fprintf(m.progress, "\r%s", m.status(now))
+	}
+}
+
+// Finish reports the final progress of the transfer.
+func (m *transferMeter) Finish() {
+	if m.progress == nil || m.start.IsZero() {
+		return
+	}
+	fmt.Fprintf(m.progress, "\r%s\n", m.status(m.now()))
+}
+
+func (m *transferMeter) status(now time.Time) string {
+	var rate string
+	if elapsed := now.Sub(m.start).Seconds(); elapsed > 0 {
+		rate = fmt.Sprintf(", %s/s", units.HumanSize(float64(m.transferred)/elapsed))
+	}
+	if m.total > 0 {
+		return fmt.Sprintf("%s of %s (%d%%)%s", units.HumanSize(float64(m.transferred)), units.HumanSize(float64(m.total)), m.transferred*100/m.total, rate)
+	}
+	return fmt.Sprintf("%s%s", units.HumanSize(float64(m.transferred)), rate)
+}
+
+type meteredReader struct {
+	meter *transferMeter
+	r     io.Reader
+}
+
+func (r *meteredReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p[:r.meter.chunk(len(p))])
+	if n > 0 {
+		r.meter.add(n)
+	}
+	return n, err
+}
+
+type meteredWriter struct {
+	meter *transferMeter
+	w     io.Writer
+}
+
+func (w *meteredWriter) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		end := written + w.meter.chunk(len(p)-written)
+		n, err := w.w.Write(p[written:end])
+		written += n
+		if n > 0 {
+			w.meter.add(n)
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,38 @@
+package rsync
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransferMeter(t *testing.T) {
+	now := time.Unix(0, 0)
+	var slept time.Duration
+	progress := &bytes.Buffer{}
+	meter := newTransferMeter(64, progress, 256*1024)
+	meter.now = func() time.Time { return now }
+	meter.sleep = func(d time.Duration) {
+		slept += d
+		now = now.Add(d)
+	}
+
+	data := bytes.Repeat([]byte("x"), 256*1024)
+	out := &bytes.Buffer{}
+	if _, err := io.Copy(out, meter.Reader(bytes.NewReader(data))); err != nil {
+		t.Fatal(err)
+	}
+	meter.Finish()
+
+	if !bytes.Equal(out.Bytes(), data) {
+		t.Fatalf("transferred data does not match")
+	}
+	if slept != 4*time.Second {
+		t.Errorf("expected the transfer to be throttled for 4s, got %s", slept)
+	}
+	if !strings.HasSuffix(progress.String(), "(100%), 65.54kB/s\n") {
+		t.Errorf("unexpected progress: %q", progress.String())
+	}
+}
@@ -73,6 +73,9 @@ func rsyncFlagsFromOptions(o *RsyncOptions) []string {
 	if o.Compress {
 		flags = append(flags, "-z")
 	}
+	if o.RsyncBWLimit > 0 {
+		flags = append(flags, fmt.Sprintf("--bwlimit=%d", o.RsyncBWLimit))
+	}
 	if len(o.RsyncInclude) > 0 {
 		for _, include := range o.RsyncInclude {
 			flags = append(flags, fmt.Sprintf("--include=%s", include))
@@ -97,35 +100,17 @@ func tarFlagsFromOptions(o *RsyncOptions) []string {
 	if !o.Quiet {
 		flags = append(flags, "-v")
 	}
-	if len(o.RsyncInclude) > 0 {
-		for _, include := range o.RsyncInclude {
-			flags = append(flags, fmt.Sprintf("**/%s", include))
-		}
-
-		// if we have explicit files or a pattern of filenames to include,
-		// maintain similar behavior to tar, and include anything else
-		// that would have otherwise been included
-		flags = append(flags, "*")
-	}
-	if len(o.RsyncExclude) > 0 {
-		for _, exclude := range o.RsyncExclude {
-			flags = append(flags, fmt.Sprintf("--exclude=%s", exclude))
-		}
+	if o.Compress {
+		flags = append(flags, "-z")
 	}
 	return flags
 }
 
 func rsyncSpecificFlags(o *RsyncOptions) []string {
 	flags := []string{}
-	if o.RsyncProgress {
-		flags = append(flags, "--progress")
-	}
 	if o.RsyncNoPerms {
 		flags = append(flags, "--no-perms")
 	}
-	if o.Compress {
-		flags = append(flags, "-z")
-	}
 	return flags
 }
 
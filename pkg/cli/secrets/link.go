@@ -3,8 +3,6 @@ package secrets
 import (
 	"context"
 	"errors"
-	"fmt"
-	"strings"
 
 	coreapiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -74,21 +72,11 @@ func (o *LinkSecretOptions) Complete(f kcmdutil.Factory, args []string) error {
 
 	if len(o.typeFlags) == 0 {
 		o.ForMount = true
-	} else {
-		for _, flag := range o.typeFlags {
-			loweredValue := strings.ToLower(flag)
-			switch loweredValue {
-			case "pull":
-				o.ForPull = true
-			case "mount":
-				o.ForMount = true
-			default:
-				return fmt.Errorf("unknown for: %v", flag)
-			}
-		}
+		return nil
 	}
-
-	return nil
+	var err error
+	o.ForMount, o.ForPull, err = parseForFlags(o.typeFlags)
+	return err
 }
 
 func (o LinkSecretOptions) Validate() error {
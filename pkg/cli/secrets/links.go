@@ -0,0 +1,191 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	linksLong = templates.LongDesc(`
+		List the secrets linked to a service account.
+
+		Each secret is listed with what it is linked for: mount for the secrets pods running as the
+		service account may mount, pull for the image pull secrets. Secrets that were deleted but are
+		still linked are shown as Missing, and the secrets the cluster created for the service account,
+		like its token and internal registry pull secrets, as Managed.
+	`)
+
+	linksExample = templates.Examples(`
+		# List the secrets linked to the 'default' service account
+		oc secrets links default
+
+		# List the secrets linked to the 'builder' service account in JSON
+		oc secrets links builder -o json
+	`)
+)
+
+type LinksOptions struct {
+	SecretOptions
+
+	Output string
+
+	genericiooptions.IOStreams
+}
+
+func NewLinksOptions(streams genericiooptions.IOStreams) *LinksOptions {
+	return &LinksOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdLinks creates a command object for listing the secrets linked to a service account
+func NewCmdLinks(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewLinksOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:     "links serviceaccount-name",
+		Short:   "List the secrets linked to a service account",
+		Long:    linksLong,
+		Example: linksExample,
+		Run: func(c *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json")
+	return cmd
+}
+
+func (o *LinksOptions) Complete(f kcmdutil.Factory, args []string) error {
+	if len(args) != 1 {
+		return errors.New("must have exactly one service account name")
+	}
+	o.TargetName = args[0]
+	return o.completeClients(f)
+}
+
+func (o LinksOptions) Validate() error {
+	if len(o.TargetName) == 0 {
+		return errors.New("service account name must be present")
+	}
+	if o.Output != "" && o.Output != "json" {
+		return fmt.Errorf("invalid output format %q, only json is supported", o.Output)
+	}
+	return nil
+}
+
+// secretLink is a secret linked to a service account.
+type secretLink struct {
+	Name string `json:"name"`
+	// For is mount, pull or both.
+	For  []string          `json:"for"`
+	Type corev1.SecretType `json:"type,omitempty"`
+	// Missing is set if the secret does not exist anymore.
+	Missing bool `json:"missing,omitempty"`
+	// Managed is set if the secret was created by the cluster for the service account.
+	Managed bool `json:"managed,omitempty"`
+}
+
+func (l secretLink) status() string {
+	switch {
+	case l.Missing:
+		return "Missing"
+	case l.Managed:
+		return "Managed"
+	default:
+		return "Linked"
+	}
+}
+
+type secretLinks struct {
+	ServiceAccount string       `json:"serviceAccount"`
+	Namespace      string       `json:"namespace"`
+	Links          []secretLink `json:"links"`
+}
+
+func (o LinksOptions) Run() error {
+	serviceaccount, err := o.GetServiceAccount()
+	if err != nil {
+		return err
+	}
+	links, err := o.getLinks(serviceaccount, true, true)
+	if err != nil {
+		return err
+	}
+
+	if o.Output == "json" {
+		data, err := json.MarshalIndent(secretLinks{ServiceAccount: serviceaccount.Name, Namespace: o.Namespace, Links: links}, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(o.Out, string(data))
+		return nil
+	}
+
+	if len(links) == 0 {
+		fmt.Fprintf(o.Out, "No secrets are linked to %s/%s service account\n", o.Namespace, serviceaccount.Name)
+		return nil
+	}
+	w := tabwriter.NewWriter(o.Out, 0, 4, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tFOR\tTYPE\tSTATUS")
+	for _, link := range links {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", link.Name, strings.Join(link.For, ","), link.Type, link.status())
+	}
+	return w.Flush()
+}
+
+// getLinks returns the mount and/or pull secrets linked to the service account, sorted by name.
+func (o SecretOptions) getLinks(serviceaccount *corev1.ServiceAccount, forMount, forPull bool) ([]secretLink, error) {
+	byName := map[string]*secretLink{}
+	add := func(name, linkedFor string) {
+		link, ok := byName[name]
+		if !ok {
+			link = &secretLink{Name: name}
+			byName[name] = link
+		}
+		link.For = append(link.For, linkedFor)
+	}
+	if forMount {
+		for _, secret := range serviceaccount.Secrets {
+			add(secret.Name, "mount")
+		}
+	}
+	if forPull {
+		for _, secret := range serviceaccount.ImagePullSecrets {
+			add(secret.Name, "pull")
+		}
+	}
+
+	links := []secretLink{}
+	for name, link := range byName {
+		secret, err := o.KubeClient.Secrets(o.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		switch {
+		case kerrors.IsNotFound(err):
+			link.Missing = true
+		case err != nil:
+			return nil, err
+		default:
+			link.Type = secret.Type
+			link.Managed = isManagedSecret(secret, serviceaccount)
+		}
+		links = append(links, *link)
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].Name < links[j].Name })
+	return links, nil
+}
@@ -41,6 +41,7 @@ func NewCmdSecrets(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobr
 
 	cmds.AddCommand(NewCmdLinkSecret(f, streams))
 	cmds.AddCommand(NewCmdUnlinkSecret(f, streams))
+	cmds.AddCommand(NewCmdLinks(f, streams))
 
 	return cmds
 }
@@ -66,6 +67,11 @@ func (o *SecretOptions) Complete(f kcmdutil.Factory, args []string) error {
 	o.TargetName = args[0]
 	o.SecretNames = args[1:]
 
+	return o.completeClients(f)
+}
+
+// completeClients sets up the clients and the namespace of the service account
+func (o *SecretOptions) completeClients(f kcmdutil.Factory) error {
 	o.BuilderFunc = f.NewBuilder
 
 	var err error
@@ -174,6 +180,33 @@ func (o SecretOptions) GetPullSecretNames(serviceaccount *corev1.ServiceAccount)
 	return names
 }
 
+// parseForFlags returns whether the --for values select the mount secrets and the pull
+// secrets of a service account.
+func parseForFlags(flags []string) (forMount, forPull bool, err error) {
+	for _, flag := range flags {
+		switch strings.ToLower(flag) {
+		case "pull":
+			forPull = true
+		case "mount":
+			forMount = true
+		default:
+			return false, false, fmt.Errorf("unknown for: %v", flag)
+		}
+	}
+	return forMount, forPull, nil
+}
+
+// internalRegistryAuthTokenServiceAccountAnnotation is set on the image pull secrets the
+// cluster creates for a service account.
+const internalRegistryAuthTokenServiceAccountAnnotation = "openshift.io/internal-registry-auth-token.service-account"
+
+// isManagedSecret returns true if the secret was created by the cluster for the service
+// account, like its token and image pull secrets, which are linked to it automatically.
+func isManagedSecret(secret *corev1.Secret, serviceaccount *corev1.ServiceAccount) bool {
+	return secret.Annotations[corev1.ServiceAccountNameKey] == serviceaccount.Name ||
+		secret.Annotations[internalRegistryAuthTokenServiceAccountAnnotation] == serviceaccount.Name
+}
+
 // GetSecrets Return a list of secret objects in the default namespace
 // If allowNonExisting is set to true, we will return the non-existing secrets as well.
 func (o SecretOptions) GetSecrets(allowNonExisting bool) ([]*corev1.Secret, bool, error) {
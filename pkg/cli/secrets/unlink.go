@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	coreapiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/printers"
@@ -22,17 +24,30 @@ var (
 		Unlink (detach) secrets from a service account.
 
 		If a secret is no longer valid for a pod, build or image pull, you may unlink it from a service account.
+
+		With --all, all the secrets linked to the service account are unlinked, for instance after the
+		credentials of a registry were rotated, except for the secrets the cluster created for the service
+		account. Use --for to only unlink the mount or the pull secrets, and 'oc secrets links' to list
+		the secrets linked to a service account beforehand.
 	`)
 
 	unlinkSecretExample = templates.Examples(`
 		# Unlink a secret currently associated with a service account
 		oc secrets unlink serviceaccount-name secret-name another-secret-name ...
+
+		# Unlink all the image pull secrets of a service account, except those the cluster created for it
+		oc secrets unlink serviceaccount-name --all --for=pull
 	`)
 )
 
 type UnlinkSecretOptions struct {
 	SecretOptions
 
+	All       bool
+	ForMount  bool
+	ForPull   bool
+	typeFlags []string
+
 	PrintFlags *genericclioptions.PrintFlags
 	Printer    printers.ResourcePrinter
 
@@ -52,7 +67,7 @@ func NewCmdUnlinkSecret(f kcmdutil.Factory, streams genericiooptions.IOStreams)
 	o := NewUnlinkSecretOptions(streams)
 
 	cmd := &cobra.Command{
-		Use:     "unlink serviceaccount-name secret-name [another-secret-name] ...",
+		Use:     "unlink serviceaccount-name (secret-name [another-secret-name] ... | --all)",
 		Short:   "Detach secrets from a service account",
 		Long:    unlinkSecretLong,
 		Example: unlinkSecretExample,
@@ -63,16 +78,29 @@ func NewCmdUnlinkSecret(f kcmdutil.Factory, streams genericiooptions.IOStreams)
 		},
 	}
 
+	cmd.Flags().BoolVar(&o.All, "all", o.All, "If true, unlink all the secrets linked to the service account, except those the cluster created for it.")
+	cmd.Flags().StringSliceVar(&o.typeFlags, "for", []string{"mount", "pull"}, "type of secret to unlink: mount or pull")
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
 }
 
 func (o *UnlinkSecretOptions) Complete(f kcmdutil.Factory, args []string) error {
-	if err := o.SecretOptions.Complete(f, args); err != nil {
+	if o.All {
+		if len(args) != 1 {
+			return errors.New("must have exactly one service account name and no secret name with --all")
+		}
+		o.TargetName = args[0]
+		if err := o.completeClients(f); err != nil {
+			return err
+		}
+	} else if err := o.SecretOptions.Complete(f, args); err != nil {
 		return err
 	}
 
 	var err error
+	if o.ForMount, o.ForPull, err = parseForFlags(o.typeFlags); err != nil {
+		return err
+	}
 	o.Printer, err = o.PrintFlags.ToPrinter()
 	if err != nil {
 		return err
@@ -81,13 +109,34 @@ func (o *UnlinkSecretOptions) Complete(f kcmdutil.Factory, args []string) error
 	return nil
 }
 
+func (o UnlinkSecretOptions) Validate() error {
+	if !o.ForPull && !o.ForMount {
+		return errors.New("for must be present")
+	}
+	if o.All {
+		if len(o.TargetName) == 0 {
+			return errors.New("service account name must be present")
+		}
+		if o.KubeClient == nil {
+			return errors.New("KubeClient must be present")
+		}
+		return nil
+	}
+	return o.SecretOptions.Validate()
+}
+
 func (o UnlinkSecretOptions) Run() error {
 	serviceaccount, err := o.GetServiceAccount()
 	if err != nil {
 		return err
 	}
 
-	if err = o.unlinkSecretsFromServiceAccount(serviceaccount); err != nil {
+	if o.All {
+		err = o.unlinkAllSecretsFromServiceAccount(serviceaccount)
+	} else {
+		err = o.unlinkSecretsFromServiceAccount(serviceaccount)
+	}
+	if err != nil {
 		return err
 	}
 
@@ -110,7 +159,7 @@ func (o UnlinkSecretOptions) unlinkSecretsFromServiceAccount(serviceaccount *cor
 
 	// Check the mount secrets
 	for _, secret := range serviceaccount.Secrets {
-		if !rmSecretNames.Has(secret.Name) {
+		if !o.ForMount || !rmSecretNames.Has(secret.Name) {
 			// Copy this back in, since it doesn't match the ones we're removing
 			newMountSecrets = append(newMountSecrets, secret)
 		} else {
@@ -120,7 +169,7 @@ func (o UnlinkSecretOptions) unlinkSecretsFromServiceAccount(serviceaccount *cor
 
 	// Check the image pull secrets
 	for _, imagePullSecret := range serviceaccount.ImagePullSecrets {
-		if !rmSecretNames.Has(imagePullSecret.Name) {
+		if !o.ForPull || !rmSecretNames.Has(imagePullSecret.Name) {
 			// Copy this back in, since it doesn't match the one we're removing
 			newPullSecrets = append(newPullSecrets, imagePullSecret)
 		} else {
@@ -144,3 +193,47 @@ func (o UnlinkSecretOptions) unlinkSecretsFromServiceAccount(serviceaccount *cor
 		return errors.New("No valid secrets found or secrets not linked to service account")
 	}
 }
+
+// unlinkAllSecretsFromServiceAccount detaches the pull and/or mount secrets from the service
+// account, except for those the cluster created for it. Secrets that do not exist anymore are
+// unlinked as well.
+func (o UnlinkSecretOptions) unlinkAllSecretsFromServiceAccount(serviceaccount *coreapiv1.ServiceAccount) error {
+	links, err := o.getLinks(serviceaccount, o.ForMount, o.ForPull)
+	if err != nil {
+		return err
+	}
+	rmSecretNames := sets.NewString()
+	for _, link := range links {
+		if link.Managed {
+			continue
+		}
+		rmSecretNames.Insert(link.Name)
+		if o.PrintFlags.OutputFormat == nil || len(*o.PrintFlags.OutputFormat) == 0 {
+			fmt.Fprintf(o.Out, "Unlinking secret %s (%s)\n", link.Name, strings.Join(link.For, ","))
+		}
+	}
+	if rmSecretNames.Len() == 0 {
+		return fmt.Errorf("No secrets to unlink from %s/%s service account", o.Namespace, serviceaccount.Name)
+	}
+
+	if o.ForMount {
+		newMountSecrets := []coreapiv1.ObjectReference{}
+		for _, secret := range serviceaccount.Secrets {
+			if !rmSecretNames.Has(secret.Name) {
+				newMountSecrets = append(newMountSecrets, secret)
+			}
+		}
+		serviceaccount.Secrets = newMountSecrets
+	}
+	if o.ForPull {
+		newPullSecrets := []coreapiv1.LocalObjectReference{}
+		for _, secret := range serviceaccount.ImagePullSecrets {
+			if !rmSecretNames.Has(secret.Name) {
+				newPullSecrets = append(newPullSecrets, secret)
+			}
+		}
+		serviceaccount.ImagePullSecrets = newPullSecrets
+	}
+	_, err = o.KubeClient.ServiceAccounts(o.Namespace).Update(context.TODO(), serviceaccount, metav1.UpdateOptions{})
+	return err
+}
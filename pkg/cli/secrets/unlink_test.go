@@ -0,0 +1,152 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestUnlinkAllSecretsFromServiceAccount(t *testing.T) {
+	secret := func(name string, annotations map[string]string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "myapp", Annotations: annotations}}
+	}
+	serviceAccount := func(name string) *corev1.ServiceAccount {
+		return &corev1.ServiceAccount{
+			ObjectMeta:       metav1.ObjectMeta{Name: name, Namespace: "myapp"},
+			Secrets:          []corev1.ObjectReference{{Name: name + "-token"}, {Name: "app-creds"}, {Name: "deleted"}, {Name: "shared"}},
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: name + "-dockercfg"}, {Name: "registry"}, {Name: "shared"}},
+		}
+	}
+	objects := func() []*corev1.Secret {
+		return []*corev1.Secret{
+			secret("builder-token", map[string]string{corev1.ServiceAccountNameKey: "builder"}),
+			secret("builder-dockercfg", map[string]string{internalRegistryAuthTokenServiceAccountAnnotation: "builder"}),
+			secret("default-token", map[string]string{corev1.ServiceAccountNameKey: "default"}),
+			secret("default-dockercfg", map[string]string{internalRegistryAuthTokenServiceAccountAnnotation: "default"}),
+			secret("app-creds", nil),
+			secret("registry", nil),
+			secret("shared", nil),
+		}
+	}
+
+	tests := []struct {
+		name            string
+		forMount        bool
+		forPull         bool
+		secrets         []string
+		pullSecrets     []string
+		expectedSecrets []string
+		expectedPull    []string
+		expectedOut     string
+		expectedError   string
+	}{
+		{
+			name:            "mount and pull secrets",
+			forMount:        true,
+			forPull:         true,
+			expectedSecrets: []string{"builder-token"},
+			expectedPull:    []string{"builder-dockercfg"},
+			expectedOut:     "Unlinking secret app-creds (mount)\nUnlinking secret deleted (mount)\nUnlinking secret registry (pull)\nUnlinking secret shared (mount,pull)\n",
+		},
+		{
+			name:            "pull secrets",
+			forPull:         true,
+			expectedSecrets: []string{"builder-token", "app-creds", "deleted", "shared"},
+			expectedPull:    []string{"builder-dockercfg"},
+			expectedOut:     "Unlinking secret registry (pull)\nUnlinking secret shared (pull)\n",
+		},
+		{
+			name:            "mount secrets",
+			forMount:        true,
+			expectedSecrets: []string{"builder-token"},
+			expectedPull:    []string{"builder-dockercfg", "registry", "shared"},
+			expectedOut:     "Unlinking secret app-creds (mount)\nUnlinking secret deleted (mount)\nUnlinking secret shared (mount)\n",
+		},
+		{
+			name:          "only managed secrets",
+			forMount:      true,
+			forPull:       true,
+			secrets:       []string{"builder-token"},
+			pullSecrets:   []string{"builder-dockercfg"},
+			expectedError: "No secrets to unlink from myapp/builder service account",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			builder, other := serviceAccount("builder"), serviceAccount("default")
+			if test.secrets != nil {
+				builder.Secrets = nil
+				for _, name := range test.secrets {
+					builder.Secrets = append(builder.Secrets, corev1.ObjectReference{Name: name})
+				}
+			}
+			if test.pullSecrets != nil {
+				builder.ImagePullSecrets = nil
+				for _, name := range test.pullSecrets {
+					builder.ImagePullSecrets = append(builder.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+				}
+			}
+			client := fake.NewSimpleClientset(builder, other)
+			for _, secret := range objects() {
+				client.Tracker().Add(secret)
+			}
+			out := &bytes.Buffer{}
+			o := UnlinkSecretOptions{
+				SecretOptions: SecretOptions{TargetName: "builder", Namespace: "myapp", KubeClient: client.CoreV1()},
+				All:           true,
+				ForMount:      test.forMount,
+				ForPull:       test.forPull,
+				PrintFlags:    genericclioptions.NewPrintFlags("updated"),
+				IOStreams:     genericiooptions.IOStreams{Out: out},
+			}
+
+			err := o.unlinkAllSecretsFromServiceAccount(builder.DeepCopy())
+			if len(test.expectedError) > 0 {
+				if err == nil || err.Error() != test.expectedError {
+					t.Fatalf("expected error %q, got %v", test.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != test.expectedOut {
+				t.Errorf("expected the output %q, got %q", test.expectedOut, out.String())
+			}
+
+			updated, err := client.CoreV1().ServiceAccounts("myapp").Get(context.TODO(), "builder", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var secrets, pullSecrets []string
+			for _, secret := range updated.Secrets {
+				secrets = append(secrets, secret.Name)
+			}
+			for _, secret := range updated.ImagePullSecrets {
+				pullSecrets = append(pullSecrets, secret.Name)
+			}
+			if strings.Join(secrets, ",") != strings.Join(test.expectedSecrets, ",") {
+				t.Errorf("expected the mount secrets %v, got %v", test.expectedSecrets, secrets)
+			}
+			if strings.Join(pullSecrets, ",") != strings.Join(test.expectedPull, ",") {
+				t.Errorf("expected the pull secrets %v, got %v", test.expectedPull, pullSecrets)
+			}
+
+			// the other service accounts linked to the same secrets are left alone
+			unchanged, err := client.CoreV1().ServiceAccounts("myapp").Get(context.TODO(), "default", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(unchanged.Secrets) != len(other.Secrets) || len(unchanged.ImagePullSecrets) != len(other.ImagePullSecrets) {
+				t.Errorf("expected the default service account to keep its secrets, got %#v", unchanged)
+			}
+		})
+	}
+}
@@ -1,14 +1,20 @@
 package set
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
 
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/yaml"
 )
 
 func selectContainers(containers []corev1.Container, spec string) ([]*corev1.Container, []*corev1.Container) {
@@ -145,5 +151,66 @@ func getObjectName(info *resource.Info) string {
 	if len(gvk.Group) == 0 {
 		return fmt.Sprintf("%s/%s", strings.ToLower(gvk.Kind), info.Name)
 	}
-	return fmt.Sprintf("%s.%s/%s\n", strings.ToLower(gvk.Kind), gvk.Group, info.Name)
+	return fmt.Sprintf("%s.%s/%s", strings.ToLower(gvk.Kind), gvk.Group, info.Name)
+}
+
+// addDiffOutput documents the diff output format in the usage of --output.
+func addDiffOutput(cmd *cobra.Command) {
+	if flag := cmd.Flags().Lookup("output"); flag != nil {
+		flag.Usage = strings.Replace(flag.Usage, "One of: (", "One of: (diff, ", 1)
+	}
+}
+
+// isDiffOutput returns whether -o diff was requested, in which case each changed object is printed
+// by printPatchDiff rather than by the printer of the print flags.
+func isDiffOutput(printFlags *genericclioptions.PrintFlags) bool {
+	return printFlags.OutputFormat != nil && *printFlags.OutputFormat == "diff"
+}
+
+// printPatchDiff prints the strategic merge patch sent for an object, followed by the unified diff
+// of the object as YAML before the patch and as returned once patched, which is the object after the
+// patch for a client dry run.
+func printPatchDiff(out io.Writer, encoder runtime.Encoder, patch *Patch, patched runtime.Object) error {
+	name := getObjectName(patch.Info)
+	after, err := runtime.Encode(encoder, patched)
+	if err != nil {
+		return err
+	}
+	beforeYAML, err := diffYAML(patch.Before)
+	if err != nil {
+		return err
+	}
+	afterYAML, err := diffYAML(after)
+	if err != nil {
+		return err
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(beforeYAML),
+		B:        difflib.SplitLines(afterYAML),
+		FromFile: name,
+		ToFile:   name,
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "# patch %s: %s\n%s", name, patch.Patch, diff)
+	return err
+}
+
+// diffYAML converts an encoded object to YAML, without the managed fields whose timestamps change
+// on every update.
+func diffYAML(data []byte) (string, error) {
+	object := map[string]interface{}{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return "", err
+	}
+	if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "managedFields")
+	}
+	out, err := yaml.Marshal(object)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
 }
@@ -52,7 +52,10 @@ var (
 				$ oc tag --source=docker myregistry:5000/test/mysql:v1 mysql:v1
 				$ oc set image-lookup deploy/mysql
 
-		Which should trigger a deployment pointing to the imported mysql:v1 tag.`)
+		Which should trigger a deployment pointing to the imported mysql:v1 tag.
+
+		To review a change before it is made, pass -o diff along with --dry-run or --local: the patch
+		sent for each object is printed along with the diff of the object.`)
 
 	imageLookupExample = templates.Examples(`
 		# Print all of the image streams and whether they resolve local names
@@ -71,7 +74,10 @@ var (
 		oc set image-lookup mysql --enabled=false
 
 		# Set local name lookup on all image streams
-		oc set image-lookup --all`)
+		oc set image-lookup --all
+
+		# Review the patches forcing local name lookup on all the deployments
+		oc set image-lookup deploy --all --dry-run=server -o diff`)
 )
 
 const alphaResolveNamesAnnotation = "alpha.image.policy.openshift.io/resolve-names"
@@ -85,6 +91,7 @@ type ImageLookupOptions struct {
 	Local      bool
 	Enabled    bool
 	PrintTable bool
+	Diff       bool
 
 	Printer           printers.ResourcePrinter
 	Builder           func() *resource.Builder
@@ -129,6 +136,7 @@ func NewCmdImageLookup(f kcmdutil.Factory, streams genericiooptions.IOStreams) *
 	cmd.Flags().BoolVar(&o.Local, "local", o.Local, "If true, operations will be performed locally.")
 
 	o.PrintFlags.AddFlags(cmd)
+	addDiffOutput(cmd)
 	kcmdutil.AddDryRunFlag(cmd)
 	kcmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl-set")
 
@@ -153,6 +161,10 @@ func (o *ImageLookupOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, ar
 
 	o.Builder = f.NewBuilder
 
+	if isDiffOutput(o.PrintFlags) {
+		o.Diff = true
+		return nil
+	}
 	kcmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
 	o.Printer, err = o.PrintFlags.ToPrinter()
 	if err != nil {
@@ -169,6 +181,14 @@ func (o *ImageLookupOptions) Validate() error {
 	if o.Local && o.DryRunStrategy == kcmdutil.DryRunServer {
 		return fmt.Errorf("cannot specify --local and --dry-run=server - did you mean --dry-run=client?")
 	}
+	if o.Diff {
+		if o.List {
+			return fmt.Errorf("-o diff cannot be used with --list")
+		}
+		if !o.Local && o.DryRunStrategy == kcmdutil.DryRunNone {
+			return fmt.Errorf("-o diff requires --dry-run or --local")
+		}
+	}
 
 	return nil
 }
@@ -267,6 +287,12 @@ func (o *ImageLookupOptions) Run() error {
 		}
 
 		if o.Local || o.DryRunStrategy == kcmdutil.DryRunClient {
+			if o.Diff {
+				if err := printPatchDiff(o.Out, setCmdJSONEncoder(), patch, info.Object); err != nil {
+					allErrs = append(allErrs, err)
+				}
+				continue
+			}
 			if err := o.Printer.PrintObj(info.Object, o.Out); err != nil {
 				allErrs = append(allErrs, err)
 			}
@@ -282,6 +308,12 @@ func (o *ImageLookupOptions) Run() error {
 			continue
 		}
 
+		if o.Diff {
+			if err := printPatchDiff(o.Out, setCmdJSONEncoder(), patch, actual); err != nil {
+				allErrs = append(allErrs, err)
+			}
+			continue
+		}
 		if err := o.Printer.PrintObj(actual, o.Out); err != nil {
 			allErrs = append(allErrs, err)
 		}
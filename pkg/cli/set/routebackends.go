@@ -1,10 +1,13 @@
 package set
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
@@ -19,6 +22,7 @@ import (
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/cli-runtime/pkg/resource"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/interrupt"
 	"k8s.io/kubectl/pkg/util/templates"
 
 	routev1 "github.com/openshift/api/route/v1"
@@ -47,6 +51,15 @@ var (
 		relative to either the primary or the first alternate (if you specify the primary).
 		If there are other backends their weights will be kept proportional to the changed.
 
+		The --auto-shift flag progressively shifts the traffic of a route from its primary backend to
+		its alternate backend, which makes a simple canary rollout: --step percent of the
+		traffic is shifted at a time, and the endpoints of the alternate service are checked during
+		--interval before the next step. If more than --max-errors checks find no ready endpoint or
+		endpoints that are not ready, the weights of the route are restored to their value before the
+		shift and the command fails. Once all of the traffic is sent to the alternate service, you may
+		make it the primary backend. Interrupting the command also restores the weights of the route
+		to their value before the shift.
+
 		Not all routers may support multiple or weighted backends.`)
 
 	backendsExample = templates.Examples(`
@@ -67,6 +80,10 @@ var (
 
 		# Set the weight to all backends to zero
 		oc set route-backends web --zero
+
+		# Shift the traffic of the route 'web' from 'prod' to 'canary' in steps of 10 percent every 2 minutes
+		oc set route-backends web prod=100 canary=0
+		oc set route-backends web --auto-shift --step=10 --interval=2m --max-errors=3
 	`)
 )
 
@@ -78,6 +95,8 @@ type BackendsOptions struct {
 	Local      bool
 	PrintTable bool
 	Transform  BackendTransform
+	AutoShift  bool
+	Shift      AutoShiftOptions
 
 	Printer           printers.ResourcePrinter
 	Builder           func() *resource.Builder
@@ -95,6 +114,10 @@ func NewBackendsOptions(streams genericiooptions.IOStreams) *BackendsOptions {
 	return &BackendsOptions{
 		PrintFlags: genericclioptions.NewPrintFlags("backends updated").WithTypeSetter(setCmdScheme),
 		IOStreams:  streams,
+		Shift: AutoShiftOptions{
+			Step:     10,
+			Interval: 2 * time.Minute,
+		},
 	}
 }
 
@@ -120,6 +143,10 @@ func NewCmdRouteBackends(f kcmdutil.Factory, streams genericiooptions.IOStreams)
 	cmd.Flags().BoolVar(&o.Transform.Adjust, "adjust", o.Transform.Adjust, "Adjust a single backend using an absolute or relative weight. If the primary backend is selected and there is more than one alternate an error will be returned.")
 	cmd.Flags().BoolVar(&o.Transform.Zero, "zero", o.Transform.Zero, "If true, set the weight of all backends to zero.")
 	cmd.Flags().BoolVar(&o.Transform.Equal, "equal", o.Transform.Equal, "If true, set the weight of all backends to 100.")
+	cmd.Flags().BoolVar(&o.AutoShift, "auto-shift", o.AutoShift, "If true, progressively shift the traffic of the route from its primary backend to its alternate backend.")
+	cmd.Flags().Int32Var(&o.Shift.Step, "step", o.Shift.Step, "The percentage of the traffic shifted at a time with --auto-shift.")
+	cmd.Flags().DurationVar(&o.Shift.Interval, "interval", o.Shift.Interval, "The time the alternate backend must stay healthy before more traffic is shifted to it with --auto-shift.")
+	cmd.Flags().IntVar(&o.Shift.MaxErrors, "max-errors", o.Shift.MaxErrors, "The number of failed health checks of the alternate backend tolerated before the traffic is shifted back with --auto-shift.")
 
 	o.PrintFlags.AddFlags(cmd)
 	kcmdutil.AddDryRunFlag(cmd)
@@ -148,7 +175,13 @@ func (o *BackendsOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args
 		o.Transform.Inputs = append(o.Transform.Inputs, *input)
 	}
 
-	o.PrintTable = o.Transform.Empty()
+	o.PrintTable = o.Transform.Empty() && !o.AutoShift
+	if o.AutoShift {
+		o.Shift.KubeClient, err = f.KubernetesClientSet()
+		if err != nil {
+			return err
+		}
+	}
 
 	o.DryRunStrategy, err = kcmdutil.GetDryRunStrategy(cmd)
 	if err != nil {
@@ -170,6 +203,17 @@ func (o *BackendsOptions) Validate() error {
 	if o.Local && o.DryRunStrategy == kcmdutil.DryRunServer {
 		return fmt.Errorf("cannot specify --local and --dry-run=server - did you mean --dry-run=client?")
 	}
+	if o.AutoShift {
+		switch {
+		case !o.Transform.Empty() || o.Transform.Adjust:
+			return fmt.Errorf("--auto-shift may not be used with backends, --adjust, --zero or --equal")
+		case o.Local || o.DryRunStrategy != kcmdutil.DryRunNone:
+			return fmt.Errorf("--auto-shift may not be used with --local or --dry-run")
+		case len(o.Resources) != 1 || o.All || len(o.Selector) > 0 || len(o.FilenameOptions.Filenames) > 0:
+			return fmt.Errorf("--auto-shift requires the name of a single route")
+		}
+		return o.Shift.Validate()
+	}
 
 	return o.Transform.Validate()
 }
@@ -203,6 +247,18 @@ func (o *BackendsOptions) Run() error {
 	if o.PrintTable {
 		return o.printBackends(infos)
 	}
+	if o.AutoShift {
+		if len(infos) != 1 {
+			return fmt.Errorf("--auto-shift requires a single route, found %d", len(infos))
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		// an interrupt stops the shift, which restores the weights before the command exits
+		intr := interrupt.New(func(os.Signal) {}, cancel)
+		return intr.Run(func() error {
+			return o.autoShift(ctx, infos[0])
+		})
+	}
 
 	patches := CalculatePatchesExternal(setCmdJSONEncoder(), infos, func(info *resource.Info) (bool, error) {
 		return UpdateBackendsForObject(info.Object, o.Transform.Apply)
@@ -0,0 +1,190 @@
+package set
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+// healthCheckInterval is the maximum time between two checks of the endpoints of the alternate
+// backend while traffic is shifted to it.
+const healthCheckInterval = 10 * time.Second
+
+// AutoShiftOptions progressively shift the traffic of a route from its primary backend to its
+// alternate backend, and shift it back at once when the alternate backend is not healthy.
+type AutoShiftOptions struct {
+	// Step is the percentage of the traffic shifted to the alternate backend at a time.
+	Step int32
+	// Interval is the time the alternate backend must stay healthy before more traffic is shifted
+	// to it.
+	Interval time.Duration
+	// MaxErrors is the number of failed health checks tolerated before the traffic is shifted back.
+	MaxErrors int
+
+	KubeClient kubernetes.Interface
+}
+
+// Validate returns an error if the options do not describe a shift of traffic.
+func (o *AutoShiftOptions) Validate() error {
+	switch {
+	case o.Step <= 0 || o.Step > 100:
+		return fmt.Errorf("--step must be between 1 and 100")
+	case o.Interval <= 0:
+		return fmt.Errorf("--interval must be positive")
+	case o.MaxErrors < 0:
+		return fmt.Errorf("--max-errors must not be negative")
+	}
+	return nil
+}
+
+// autoShift shifts the traffic of the route of the info to its alternate backend, Step percent at a
+// time, checking the endpoints of the alternate backend during Interval after each step. The route
+// is restored to its weights before the shift once more than MaxErrors checks failed, or when the
+// context is done.
+func (o *BackendsOptions) autoShift(ctx context.Context, info *resource.Info) error {
+	route, ok := info.Object.(*routev1.Route)
+	if !ok {
+		return fmt.Errorf("%s is not a route", getObjectName(info))
+	}
+	if len(route.Spec.AlternateBackends) != 1 {
+		return fmt.Errorf("--auto-shift requires a route with a primary backend and a single alternate backend, %s has %d alternate backends", getObjectName(info), len(route.Spec.AlternateBackends))
+	}
+	name := getObjectName(info)
+	primary, alternate := route.Spec.To.Name, route.Spec.AlternateBackends[0].Name
+	originalPrimary, originalAlternate := route.Spec.To.Weight, route.Spec.AlternateBackends[0].Weight
+
+	percent := shiftedPercent(originalPrimary, originalAlternate)
+	failures := 0
+	for {
+		percent = nextShiftPercent(percent, o.Shift.Step)
+		primaryWeight, alternateWeight := 100-percent, percent
+		if err := o.setBackendWeights(info, &primaryWeight, &alternateWeight); err != nil {
+			return err
+		}
+		fmt.Fprintf(o.Out, "%s: %d%% of the traffic is sent to %s, %d%% to %s\n", name, percent, alternate, 100-percent, primary)
+
+		deadline := time.Now().Add(o.Shift.Interval)
+		for remaining := time.Until(deadline); remaining > 0; remaining = time.Until(deadline) {
+			wait := healthCheckInterval
+			if remaining < wait {
+				wait = remaining
+			}
+			select {
+			case <-ctx.Done():
+				return o.restoreBackendWeights(info, originalPrimary, originalAlternate, "the shift was interrupted")
+			case <-time.After(wait):
+			}
+
+			err := o.checkBackend(ctx, route.Namespace, alternate)
+			if err == nil {
+				continue
+			}
+			failures++
+			fmt.Fprintf(o.ErrOut, "warning: %s is not healthy (%d of %d failures allowed): %v\n", alternate, failures, o.Shift.MaxErrors, err)
+			if failures <= o.Shift.MaxErrors {
+				continue
+			}
+			return o.restoreBackendWeights(info, originalPrimary, originalAlternate, fmt.Sprintf("%s was not healthy %d times", alternate, failures))
+		}
+
+		if percent == 100 {
+			fmt.Fprintf(o.Out, "%s: all of the traffic is sent to %s\n", name, alternate)
+			return nil
+		}
+	}
+}
+
+// restoreBackendWeights restores the weights of the primary and alternate backends of the route of
+// the info before the shift, and returns an error with the reason the shift stopped.
+func (o *BackendsOptions) restoreBackendWeights(info *resource.Info, primary, alternate *int32, reason string) error {
+	if err := o.setBackendWeights(info, primary, alternate); err != nil {
+		return fmt.Errorf("%s and the weights of %s could not be restored: %v", reason, getObjectName(info), err)
+	}
+	return fmt.Errorf("%s, the weights of %s were restored", reason, getObjectName(info))
+}
+
+// setBackendWeights patches the weights of the primary and alternate backends of the route of the
+// info, and refreshes the info with the patched route.
+func (o *BackendsOptions) setBackendWeights(info *resource.Info, primary, alternate *int32) error {
+	patches := CalculatePatchesExternal(setCmdJSONEncoder(), []*resource.Info{info}, func(info *resource.Info) (bool, error) {
+		return UpdateBackendsForObject(info.Object, func(b *Backends) error {
+			b.Backends[0].Weight = primary
+			b.Backends[1].Weight = alternate
+			return nil
+		})
+	})
+	patch := patches[0]
+	if patch.Err != nil {
+		return fmt.Errorf("error: %s %v", getObjectName(info), patch.Err)
+	}
+	if string(patch.Patch) == "{}" || len(patch.Patch) == 0 {
+		return nil
+	}
+	actual, err := resource.NewHelper(info.Client, info.Mapping).
+		WithFieldManager(o.FieldManager).
+		Patch(info.Namespace, info.Name, types.StrategicMergePatchType, patch.Patch, &metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to patch route backends: %v", err)
+	}
+	return info.Refresh(actual, true)
+}
+
+// checkBackend returns an error unless the endpoints of the service are healthy.
+func (o *BackendsOptions) checkBackend(ctx context.Context, namespace, service string) error {
+	endpoints, err := o.Shift.KubeClient.CoreV1().Endpoints(namespace).Get(ctx, service, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	return checkEndpoints(endpoints)
+}
+
+// checkEndpoints returns an error when the endpoints of a service have no ready address, or have
+// addresses that are not ready, such as those of pods failing their readiness probe.
+func checkEndpoints(endpoints *corev1.Endpoints) error {
+	ready, notReady := 0, 0
+	for _, subset := range endpoints.Subsets {
+		ready += len(subset.Addresses)
+		notReady += len(subset.NotReadyAddresses)
+	}
+	switch {
+	case ready == 0:
+		return fmt.Errorf("no endpoint is ready")
+	case notReady > 0:
+		return fmt.Errorf("%d of %d endpoints are not ready", notReady, ready+notReady)
+	}
+	return nil
+}
+
+// shiftedPercent returns the percentage of the traffic sent to the alternate backend, where a
+// backend without weight has the default weight of 100.
+func shiftedPercent(primary, alternate *int32) int32 {
+	primaryWeight, alternateWeight := int32(100), int32(100)
+	if primary != nil {
+		primaryWeight = *primary
+	}
+	if alternate != nil {
+		alternateWeight = *alternate
+	}
+	if primaryWeight+alternateWeight == 0 {
+		return 0
+	}
+	return alternateWeight * 100 / (primaryWeight + alternateWeight)
+}
+
+// nextShiftPercent returns the percentage of the traffic sent to the alternate backend after the
+// next step, rounded down to a multiple of the step so that the steps are even.
+func nextShiftPercent(percent, step int32) int32 {
+	next := (percent/step + 1) * step
+	if next > 100 {
+		return 100
+	}
+	return next
+}
@@ -1,12 +1,31 @@
 package set
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	restfake "k8s.io/client-go/rest/fake"
 	kcmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	routev1 "github.com/openshift/api/route/v1"
 )
 
 func TestLocalAndDryRunFlags(t *testing.T) {
@@ -28,3 +47,159 @@ func ensureLocalAndDryRunFlagsOnChildren(t *testing.T, c *cobra.Command, prefix
 		ensureLocalAndDryRunFlagsOnChildren(t, cmd, name+".")
 	}
 }
+
+func TestPrintPatchDiff(t *testing.T) {
+	dc := &appsv1.DeploymentConfig{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps.openshift.io/v1", Kind: "DeploymentConfig"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:          "app",
+			Namespace:     "test",
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "oc"}},
+		},
+		Spec: appsv1.DeploymentConfigSpec{
+			Triggers: []appsv1.DeploymentTriggerPolicy{{Type: appsv1.DeploymentTriggerOnConfigChange}},
+			Template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "app:latest"}}},
+			},
+		},
+	}
+	info := &resource.Info{
+		Name:      dc.Name,
+		Namespace: dc.Namespace,
+		Object:    dc,
+		Mapping:   &meta.RESTMapping{Resource: appsv1.Resource("deploymentconfigs").WithVersion("v1")},
+	}
+	patches := CalculatePatchesExternal(setCmdJSONEncoder(), []*resource.Info{info}, func(info *resource.Info) (bool, error) {
+		return UpdateTriggersForObject(info.Object, func(triggers *TriggerDefinition) error {
+			triggers.ConfigChange = false
+			return nil
+		})
+	})
+	if len(patches) != 1 || patches[0].Err != nil {
+		t.Fatalf("unexpected patches: %#v", patches)
+	}
+
+	out := &bytes.Buffer{}
+	if err := printPatchDiff(out, setCmdJSONEncoder(), patches[0], info.Object); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(out.String(), "\n")
+	if !strings.HasPrefix(lines[0], "# patch deploymentconfigs/app: {") {
+		t.Errorf("the patch is not printed first:\n%s", out.String())
+	}
+	for _, expected := range []string{"--- deploymentconfigs/app", "+++ deploymentconfigs/app", "-  - type: ConfigChange"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "managedFields") {
+		t.Errorf("the managed fields are diffed:\n%s", out.String())
+	}
+}
+
+func TestAutoShiftSteps(t *testing.T) {
+	weight := func(w int32) *int32 { return &w }
+	tests := []struct {
+		primary, alternate *int32
+		step               int32
+		expected           []int32
+	}{
+		{primary: weight(100), alternate: weight(0), step: 25, expected: []int32{25, 50, 75, 100}},
+		{primary: weight(100), alternate: weight(0), step: 30, expected: []int32{30, 60, 90, 100}},
+		// the steps are kept even after a manual shift
+		{primary: weight(85), alternate: weight(15), step: 10, expected: []int32{20, 30, 40, 50, 60, 70, 80, 90, 100}},
+		// backends without weight share the traffic evenly
+		{step: 50, expected: []int32{100}},
+	}
+	for _, test := range tests {
+		percent := shiftedPercent(test.primary, test.alternate)
+		var steps []int32
+		for percent < 100 {
+			percent = nextShiftPercent(percent, test.step)
+			steps = append(steps, percent)
+		}
+		if !reflect.DeepEqual(steps, test.expected) {
+			t.Errorf("expected steps %v, got %v", test.expected, steps)
+		}
+	}
+}
+
+func TestCheckEndpoints(t *testing.T) {
+	address := corev1.EndpointAddress{IP: "10.0.0.1"}
+	if err := checkEndpoints(&corev1.Endpoints{Subsets: []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{address}}}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := checkEndpoints(&corev1.Endpoints{}); err == nil {
+		t.Errorf("expected an error for endpoints without addresses")
+	}
+	notReady := &corev1.Endpoints{Subsets: []corev1.EndpointSubset{{
+		Addresses:         []corev1.EndpointAddress{address},
+		NotReadyAddresses: []corev1.EndpointAddress{address},
+	}}}
+	if err := checkEndpoints(notReady); err == nil || err.Error() != "1 of 2 endpoints are not ready" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestAutoShiftInterrupted(t *testing.T) {
+	weight := func(w int32) *int32 { return &w }
+	route := &routev1.Route{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "route.openshift.io/v1", Kind: "Route"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "web"},
+		Spec: routev1.RouteSpec{
+			To:                routev1.RouteTargetReference{Kind: "Service", Name: "prod", Weight: weight(100)},
+			AlternateBackends: []routev1.RouteTargetReference{{Kind: "Service", Name: "canary", Weight: weight(0)}},
+		},
+	}
+
+	// the server applies the patches to the route, and the command is interrupted after the first one
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var weights []string
+	client := &restfake.RESTClient{
+		NegotiatedSerializer: setCmdCodecs.WithoutConversion(),
+		GroupVersion:         routev1.GroupVersion,
+		Client: restfake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodPatch {
+				return nil, fmt.Errorf("unexpected request %s %s", req.Method, req.URL)
+			}
+			patch, err := io.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			original, err := json.Marshal(route)
+			if err != nil {
+				return nil, err
+			}
+			patched, err := strategicpatch.StrategicMergePatch(original, patch, &routev1.Route{})
+			if err != nil {
+				return nil, err
+			}
+			route = &routev1.Route{}
+			if err := json.Unmarshal(patched, route); err != nil {
+				return nil, err
+			}
+			weights = append(weights, fmt.Sprintf("%d/%d", *route.Spec.To.Weight, *route.Spec.AlternateBackends[0].Weight))
+			cancel()
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Type": []string{"application/json"}}, Body: io.NopCloser(bytes.NewReader(patched))}, nil
+		}),
+	}
+	info := &resource.Info{
+		Client:    client,
+		Mapping:   &meta.RESTMapping{Resource: routev1.GroupVersion.WithResource("routes"), Scope: meta.RESTScopeNamespace},
+		Namespace: "test",
+		Name:      "web",
+		Object:    route.DeepCopy(),
+	}
+
+	o := NewBackendsOptions(genericiooptions.NewTestIOStreamsDiscard())
+	o.Shift.Interval = time.Hour
+	o.Shift.KubeClient = kubefake.NewSimpleClientset()
+	err := o.autoShift(ctx, info)
+	if err == nil || err.Error() != "the shift was interrupted, the weights of routes/web were restored" {
+		t.Errorf("expected the shift to be interrupted, got %v", err)
+	}
+	if strings.Join(weights, ",") != "90/10,100/0" {
+		t.Errorf("expected the weights to be restored after the first step, got %v", weights)
+	}
+}
@@ -56,7 +56,12 @@ var (
 		change triggers have been submitted.
 
 		Build configs support triggering off of image changes, config changes, and webhooks. The config change
-		trigger for a build config will only trigger the first build.`)
+		trigger for a build config will only trigger the first build.
+
+		To review a change before it is made, pass -o diff along with --dry-run or --local: the patch
+		sent for each object is printed along with the diff of the object. With --dry-run=server the
+		objects are patched by the server without being persisted, so that the diff includes the
+		changes made by the server, such as the defaults it sets.`)
 
 	triggersExample = templates.Examples(`
 		# Print the triggers on the deployment config 'myapp'
@@ -83,6 +88,9 @@ var (
 
 		# Add an image trigger to a stateful set on the main container
 		oc set triggers statefulset/db --from-image=namespace1/image:latest -c main
+
+		# Review the patches setting the triggers of all the deployment configs to manual
+		oc set triggers dc --all --manual --dry-run=server -o diff
 	`)
 )
 
@@ -109,6 +117,7 @@ type TriggersOptions struct {
 	FromImageNamespace string
 
 	PrintTable        bool
+	Diff              bool
 	Printer           printers.ResourcePrinter
 	Builder           func() *resource.Builder
 	Namespace         string
@@ -161,6 +170,7 @@ func NewCmdTriggers(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cob
 	o.FromBitbucket = cmd.Flags().Bool("from-bitbucket", false, "If true, a Bitbucket webhook - a secret value will be generated automatically")
 
 	o.PrintFlags.AddFlags(cmd)
+	addDiffOutput(cmd)
 	kcmdutil.AddDryRunFlag(cmd)
 	kcmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl-set")
 
@@ -221,6 +231,10 @@ func (o *TriggersOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args
 	}
 	o.Builder = f.NewBuilder
 
+	if isDiffOutput(o.PrintFlags) {
+		o.Diff = true
+		return nil
+	}
 	kcmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
 	o.Printer, err = o.PrintFlags.ToPrinter()
 	if err != nil {
@@ -275,6 +289,14 @@ func (o *TriggersOptions) Validate() error {
 	if o.Local && o.DryRunStrategy == kcmdutil.DryRunServer {
 		return fmt.Errorf("cannot specify --local and --dry-run=server - did you mean --dry-run=client?")
 	}
+	if o.Diff {
+		if o.PrintTable {
+			return fmt.Errorf("-o diff requires a change to the triggers")
+		}
+		if !o.Local && o.DryRunStrategy == kcmdutil.DryRunNone {
+			return fmt.Errorf("-o diff requires --dry-run or --local")
+		}
+	}
 
 	return nil
 }
@@ -331,6 +353,12 @@ func (o *TriggersOptions) Run() error {
 		}
 
 		if o.Local || o.DryRunStrategy == kcmdutil.DryRunClient {
+			if o.Diff {
+				if err := printPatchDiff(o.Out, setCmdJSONEncoder(), patch, info.Object); err != nil {
+					allErrs = append(allErrs, err)
+				}
+				continue
+			}
 			if err := o.Printer.PrintObj(info.Object, o.Out); err != nil {
 				allErrs = append(allErrs, err)
 			}
@@ -346,6 +374,12 @@ func (o *TriggersOptions) Run() error {
 			continue
 		}
 
+		if o.Diff {
+			if err := printPatchDiff(o.Out, setCmdJSONEncoder(), patch, actual); err != nil {
+				allErrs = append(allErrs, err)
+			}
+			continue
+		}
 		if err := o.Printer.PrintObj(actual, o.Out); err != nil {
 			allErrs = append(allErrs, err)
 		}
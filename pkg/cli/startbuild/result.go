@@ -0,0 +1,87 @@
+package startbuild
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	buildv1 "github.com/openshift/api/build/v1"
+)
+
+// buildResult is printed with --wait and --output=json or yaml once the build
+// has finished, for CI systems to pick up what the build produced.
+type buildResult struct {
+	Name      string               `json:"name"`
+	Namespace string               `json:"namespace"`
+	Phase     buildv1.BuildPhase   `json:"phase"`
+	Reason    buildv1.StatusReason `json:"reason,omitempty"`
+	Message   string               `json:"message,omitempty"`
+
+	StartTimestamp      *metav1.Time `json:"startTimestamp,omitempty"`
+	CompletionTimestamp *metav1.Time `json:"completionTimestamp,omitempty"`
+	DurationSeconds     int64        `json:"durationSeconds,omitempty"`
+
+	// Image is the pull spec of the pushed image and ImageDigest its digest.
+	Image       string `json:"image,omitempty"`
+	ImageDigest string `json:"imageDigest,omitempty"`
+	// ImageStreamTag is the namespace/name:tag the image was pushed to, if the
+	// build outputs to an image stream tag.
+	ImageStreamTag string `json:"imageStreamTag,omitempty"`
+
+	// LogURL is the API endpoint of the build log and LogSnippet the last lines
+	// of the log of a failed build.
+	LogURL     string `json:"logURL"`
+	LogSnippet string `json:"logSnippet,omitempty"`
+}
+
+func newBuildResult(build *buildv1.Build, host string) *buildResult {
+	result := &buildResult{
+		Name:                build.Name,
+		Namespace:           build.Namespace,
+		Phase:               build.Status.Phase,
+		Reason:              build.Status.Reason,
+		Message:             build.Status.Message,
+		StartTimestamp:      build.Status.StartTimestamp,
+		CompletionTimestamp: build.Status.CompletionTimestamp,
+		DurationSeconds:     int64(build.Status.Duration.Seconds()),
+		Image:               build.Status.OutputDockerImageReference,
+		LogSnippet:          build.Status.LogSnippet,
+	}
+	if to := build.Status.Output.To; to != nil {
+		result.ImageDigest = to.ImageDigest
+	}
+	if to := build.Spec.Output.To; to != nil && to.Kind == "ImageStreamTag" {
+		namespace := to.Namespace
+		if len(namespace) == 0 {
+			namespace = build.Namespace
+		}
+		result.ImageStreamTag = namespace + "/" + to.Name
+	}
+	result.LogURL = strings.TrimSuffix(host, "/") + fmt.Sprintf("/apis/build.openshift.io/v1/namespaces/%s/builds/%s/log",
+		url.PathEscape(build.Namespace), url.PathEscape(build.Name))
+	return result
+}
+
+func (r *buildResult) print(w io.Writer, format string) error {
+	var data []byte
+	var err error
+	switch format {
+	case "json":
+		data, err = json.MarshalIndent(r, "", "    ")
+		data = append(data, '\n')
+	case "yaml":
+		data, err = yaml.Marshal(r)
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
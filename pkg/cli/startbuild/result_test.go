@@ -0,0 +1,51 @@
+package startbuild
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	buildv1 "github.com/openshift/api/build/v1"
+)
+
+func TestBuildResult(t *testing.T) {
+	start := metav1.NewTime(time.Date(2023, 10, 14, 12, 0, 0, 0, time.UTC))
+	build := &buildv1.Build{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello-world-2", Namespace: "ci"},
+		Spec: buildv1.BuildSpec{
+			CommonSpec: buildv1.CommonSpec{
+				Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "hello-world:latest"}},
+			},
+		},
+		Status: buildv1.BuildStatus{
+			Phase:                      buildv1.BuildPhaseComplete,
+			StartTimestamp:             &start,
+			Duration:                   90 * time.Second,
+			OutputDockerImageReference: "image-registry.openshift-image-registry.svc:5000/ci/hello-world:latest",
+			Output:                     buildv1.BuildStatusOutput{To: &buildv1.BuildStatusOutputTo{ImageDigest: "sha256:0123"}},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	if err := newBuildResult(build, "https://api.example.com:6443/").print(out, "json"); err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+    "name": "hello-world-2",
+    "namespace": "ci",
+    "phase": "Complete",
+    "startTimestamp": "2023-10-14T12:00:00Z",
+    "durationSeconds": 90,
+    "image": "image-registry.openshift-image-registry.svc:5000/ci/hello-world:latest",
+    "imageDigest": "sha256:0123",
+    "imageStreamTag": "ci/hello-world:latest",
+    "logURL": "https://api.example.com:6443/apis/build.openshift.io/v1/namespaces/ci/builds/hello-world-2/log"
+}
+`
+	if out.String() != want {
+		t.Errorf("unexpected result:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
@@ -3,7 +3,6 @@ package startbuild
 import (
 	"bufio"
 	"bytes"
-	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -15,7 +14,6 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
-	"time"
 
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
@@ -26,6 +24,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/third_party/forked/golang/netutil"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
@@ -65,8 +64,17 @@ var (
 		pass a http or https url to --from-file and --from-archive, however authentication is not supported
 		and in case of https the certificate must be valid and recognized by your system.
 
+		Directories passed with --from-dir or --from-repo are archived and compressed in parallel with gzip,
+		or with zstd if --compression=zstd is passed and the builder of the cluster supports it. Files matched
+		by the .gitignore files of the directory, or by its .containerignore or .dockerignore file, are not
+		uploaded unless --ignore-files=false is passed.
+
 		Note that builds triggered from binary input will not preserve the source on the server, so rebuilds
-		triggered by base image changes will use the source specified on the build config.`)
+		triggered by base image changes will use the source specified on the build config.
+
+		With --wait and --output=json or yaml, the build is printed once it has finished instead of when
+		it starts: its phase, the pushed image and its digest, the image stream tag it was pushed to and
+		the URL of its log. Build logs requested with --follow are then written to standard error.`)
 
 	startBuildExample = templates.Examples(`
 		# Starts build from build config "hello-world"
@@ -78,6 +86,9 @@ var (
 		# Use the contents of a directory as build input
 		oc start-build hello-world --from-dir=src/
 
+		# Use the contents of a directory as build input, compressed with zstd and including ignored files
+		oc start-build hello-world --from-dir=src/ --compression=zstd --ignore-files=false
+
 		# Send the contents of a Git repository to the server from tag 'v2'
 		oc start-build hello-world --from-repo=../hello-world --commit=v2
 
@@ -88,6 +99,10 @@ var (
 		# Start a new build for build config "hello-world" and wait until the build completes. It
 		# exits with a non-zero return code if the build fails
 		oc start-build hello-world --wait
+
+		# Start a new build and, once it has finished, print its phase, the digest of the pushed image,
+		# the image stream tag and the URL of the build log as JSON
+		oc start-build hello-world --wait -o json
 	`)
 )
 
@@ -110,6 +125,8 @@ type StartBuildOptions struct {
 	FromRepo      string
 	FromArchive   string
 	ExcludeRegExp string
+	Compression   string
+	IgnoreFiles   bool
 
 	Env  []string
 	Args []string
@@ -133,18 +150,22 @@ type StartBuildOptions struct {
 
 	AsBinary    bool
 	ShortOutput bool
-	EnvVar      []corev1.EnvVar
-	BuildArgs   []corev1.EnvVar
-	Name        string
-	Namespace   string
+	// WaitOutput is json or yaml to print the finished build with --wait
+	WaitOutput string
+	EnvVar     []corev1.EnvVar
+	BuildArgs  []corev1.EnvVar
+	Name       string
+	Namespace  string
 
 	genericiooptions.IOStreams
 }
 
 func NewStartBuildOptions(streams genericiooptions.IOStreams) *StartBuildOptions {
 	return &StartBuildOptions{
-		PrintFlags: genericclioptions.NewPrintFlags("started").WithTypeSetter(scheme.Scheme),
-		IOStreams:  streams,
+		PrintFlags:  genericclioptions.NewPrintFlags("started").WithTypeSetter(scheme.Scheme),
+		IOStreams:   streams,
+		Compression: compressionGzip,
+		IgnoreFiles: true,
 	}
 }
 
@@ -182,6 +203,8 @@ func NewCmdStartBuild(f kcmdutil.Factory, streams genericiooptions.IOStreams) *c
 	cmd.Flags().StringVar(&o.FromRepo, "from-repo", o.FromRepo, "The path to a local source code repository to use as the binary input for a build.")
 	cmd.Flags().StringVar(&o.Commit, "commit", o.Commit, "Specify the source code commit identifier the build should use; requires a build based on a Git repository")
 	cmd.Flags().StringVarP(&o.ExcludeRegExp, "exclude", "", tar.DefaultExclusionPattern.String(), "When using the --from-dir option: regular expression for selecting files from the source tree to exclude from the build; the default excludes the '.git' directory (see https://golang.org/pkg/regexp for syntax, but note that \"\" will be interpreted as allow all files and exclude no files)")
+	cmd.Flags().StringVar(&o.Compression, "compression", o.Compression, "When using the --from-dir or --from-repo option: how to compress the archive of the directory, one of "+strings.Join(compressions, ", ")+". zstd requires a builder that can extract zstd archives.")
+	cmd.Flags().BoolVar(&o.IgnoreFiles, "ignore-files", o.IgnoreFiles, "When using the --from-dir or --from-repo option: if true, exclude the files matched by the .gitignore files and the .containerignore or .dockerignore file of the directory.")
 
 	cmd.Flags().StringVar(&o.ListWebhooks, "list-webhooks", o.ListWebhooks, "List the webhooks for the specified build config or build; accepts 'all', 'generic', or 'github'")
 	cmd.Flags().StringVar(&o.FromWebhook, "from-webhook", o.FromWebhook, "Specify a generic webhook URL for an existing build config to trigger")
@@ -233,6 +256,10 @@ func (o *StartBuildOptions) Complete(ctx context.Context, f kcmdutil.Factory, cm
 		return fmt.Errorf("the --exclude flag is only supported with --from-dir")
 	}
 
+	if format := kcmdutil.GetFlagString(cmd, "output"); o.WaitForComplete && (format == "json" || format == "yaml") {
+		o.WaitOutput = format
+	}
+
 	o.Printer, err = o.PrintFlags.ToPrinter()
 	if err != nil {
 		return err
@@ -352,6 +379,9 @@ func (o *StartBuildOptions) Complete(ctx context.Context, f kcmdutil.Factory, cm
 
 // Validate returns validation errors regarding start-build
 func (o *StartBuildOptions) Validate() error {
+	if !sets.New(compressions...).Has(o.Compression) {
+		return fmt.Errorf("--compression must be one of %s", strings.Join(compressions, ", "))
+	}
 	if o.AsBinary {
 		if len(o.LogLevel) > 0 {
 			fmt.Fprintf(o.ErrOut, "WARNING: Specifying --build-loglevel with binary builds is not supported.\n")
@@ -457,7 +487,7 @@ func (o *StartBuildOptions) Run(ctx context.Context) error {
 		}
 
 		instantiateClient := buildclientmanual.NewBuildInstantiateBinaryClient(o.BuildClient.RESTClient(), o.Namespace)
-		if newBuild, err = streamPathToBuild(o.Git, o.In, o.ErrOut, instantiateClient, o.FromDir, o.FromFile, o.FromRepo, o.ExcludeRegExp, request); err != nil {
+		if newBuild, err = streamPathToBuild(o.Git, o.In, o.ErrOut, instantiateClient, o.FromDir, o.FromFile, o.FromRepo, o.ExcludeRegExp, archiveOptions{compression: o.Compression, ignoreFiles: o.IgnoreFiles}, request); err != nil {
 			if kerrors.IsAlreadyExists(err) {
 				return transformIsAlreadyExistsError(err, o.Name)
 			}
@@ -485,26 +515,36 @@ func (o *StartBuildOptions) Run(ctx context.Context) error {
 		}
 	}
 
-	if err := o.Printer.PrintObj(newBuild, o.Out); err != nil {
+	logOut := o.Out
+	if len(o.WaitOutput) > 0 {
+		// keep the standard output for the result
+		logOut = o.ErrOut
+	} else if err := o.Printer.PrintObj(newBuild, o.Out); err != nil {
 		fmt.Fprintf(o.ErrOut, "%v\n", err)
 	}
 
 	// Stream the logs from the build
 	if o.Follow {
-		err = o.streamBuildLogs(ctx, newBuild)
+		err = o.streamBuildLogs(ctx, newBuild, logOut)
 		if err != nil {
 			fmt.Fprintf(o.ErrOut, "Failed to stream the build logs - to view the logs, run oc logs build/%s\nError: %v\n", newBuild.Name, err)
 		}
 	}
 
 	if o.WaitForComplete {
-		return WaitForBuildComplete(ctx, o.BuildClient.Builds(o.Namespace), newBuild.Name)
+		build, err := waitForBuild(ctx, o.BuildClient.Builds(o.Namespace), newBuild.Name)
+		if len(o.WaitOutput) > 0 && build != nil {
+			if printErr := newBuildResult(build, o.ClientConfig.Host).print(o.Out, o.WaitOutput); printErr != nil {
+				return printErr
+			}
+		}
+		return err
 	}
 
 	return nil
 }
 
-func (o *StartBuildOptions) streamBuildLogs(ctx context.Context, build *buildv1.Build) error {
+func (o *StartBuildOptions) streamBuildLogs(ctx context.Context, build *buildv1.Build, out io.Writer) error {
 	opts := buildv1.BuildLogOptions{
 		Follow: true,
 		NoWait: false,
@@ -521,7 +561,7 @@ func (o *StartBuildOptions) streamBuildLogs(ctx context.Context, build *buildv1.
 			break
 		}
 		defer rd.Close()
-		if _, streamErr := io.Copy(o.Out, rd); streamErr != nil {
+		if _, streamErr := io.Copy(out, rd); streamErr != nil {
 			err = ocerrors.NewError("unable to stream the build logs").WithCause(streamErr)
 			klog.V(4).Infof("Error: %v", err)
 		}
@@ -675,7 +715,7 @@ func (o *StartBuildOptions) RunListBuildWebHooks(ctx context.Context) error {
 	return nil
 }
 
-func streamPathToBuild(repo git.Repository, in io.Reader, out io.Writer, client buildclientmanual.BuildInstantiateBinaryInterface, fromDir, fromFile, fromRepo string, excludeRegExp string, options *buildv1.BinaryBuildRequestOptions) (*buildv1.Build, error) {
+func streamPathToBuild(repo git.Repository, in io.Reader, out io.Writer, client buildclientmanual.BuildInstantiateBinaryInterface, fromDir, fromFile, fromRepo string, excludeRegExp string, archive archiveOptions, options *buildv1.BinaryBuildRequestOptions) (*buildv1.Build, error) {
 	asDir, asFile, asRepo := len(fromDir) > 0, len(fromFile) > 0, len(fromRepo) > 0
 
 	if asRepo && !git.IsGitInstalled() {
@@ -693,6 +733,8 @@ func streamPathToBuild(repo git.Repository, in io.Reader, out io.Writer, client
 	}
 
 	var r io.Reader
+	// size is the size of the upload, if it is known
+	var size int64
 	switch {
 	case fromFile == "-":
 		return nil, fmt.Errorf("--from-file=- is not supported")
@@ -713,6 +755,7 @@ func streamPathToBuild(repo git.Repository, in io.Reader, out io.Writer, client
 		}
 
 		r = resp.Body
+		size = resp.ContentLength
 
 		if asFile {
 			options.AsFile = httpFileName(resp)
@@ -814,16 +857,27 @@ func streamPathToBuild(repo git.Repository, in io.Reader, out io.Writer, client
 			if err != nil {
 				return nil, err
 			}
+			t := tar.New(s2ifs.NewFileSystem())
+			t.SetExclusionPattern(re)
+			if archive.ignoreFiles {
+				ignore, err := newIgnoreMatcher(path)
+				if err != nil {
+					return nil, err
+				}
+				t.SetExclusionFunc(ignore.exclude)
+			}
 
 			pr, pw := io.Pipe()
+			w, err := newCompressor(archive.compression, pw)
+			if err != nil {
+				return nil, err
+			}
 			go func() {
-				w := gzip.NewWriter(pw)
-				t := tar.New(s2ifs.NewFileSystem())
-				t.SetExclusionPattern(re)
 				if err := t.CreateTarStream(path, false, w); err != nil {
 					pw.CloseWithError(err)
+				} else if err := w.Close(); err != nil {
+					pw.CloseWithError(err)
 				} else {
-					w.Close()
 					pw.CloseWithError(io.EOF)
 				}
 
@@ -841,6 +895,7 @@ func streamPathToBuild(repo git.Repository, in io.Reader, out io.Writer, client
 			defer f.Close()
 
 			r = f
+			size = stat.Size()
 
 			if asFile {
 				options.AsFile = filepath.Base(path)
@@ -859,31 +914,10 @@ func streamPathToBuild(repo git.Repository, in io.Reader, out io.Writer, client
 		}
 	}
 
-	stopProgress := progress(out)
+	uploaded := &countingReader{r: r}
+	stopProgress := progress(out, uploaded, size)
 	defer stopProgress()
-	return client.InstantiateBinary(options.Name, options, r)
-}
-
-func progress(out io.Writer) func() {
-	stop := make(chan bool)
-	done := make(chan bool)
-	go func() {
-		tick := time.Tick(5 * time.Second)
-		for {
-			select {
-			case <-tick:
-				fmt.Fprintf(out, ".")
-			case <-stop:
-				fmt.Fprintf(out, "\nUploading finished\n")
-				done <- true
-				return
-			}
-		}
-	}()
-	return func() {
-		stop <- true
-		<-done
-	}
+	return client.InstantiateBinary(options.Name, options, uploaded)
 }
 
 func isArchive(r *bufio.Reader) bool {
@@ -910,6 +944,9 @@ func isArchive(r *bufio.Reader) bool {
 		{ // gzip
 			numbers: []byte{0x1F, 0x8B},
 		},
+		{ // zstd
+			numbers: []byte{0x28, 0xB5, 0x2F, 0xFD},
+		},
 	}
 	maxOffset := archivesMagicNumbers[0].offset //unified tar
 	data, err := r.Peek(maxOffset + len(archivesMagicNumbers[0].numbers))
@@ -1065,6 +1102,13 @@ func gitRefInfo(repo git.Repository, dir, ref string) (buildv1.GitRefInfo, error
 
 // WaitForBuildComplete waits for a build identified by the name to complete
 func WaitForBuildComplete(ctx context.Context, c buildv1client.BuildInterface, name string) error {
+	_, err := waitForBuild(ctx, c, name)
+	return err
+}
+
+// waitForBuild waits for a build identified by the name to finish and returns
+// it, along with an error if it did not complete.
+func waitForBuild(ctx context.Context, c buildv1client.BuildInterface, name string) (*buildv1.Build, error) {
 	isOK := func(b *buildv1.Build) bool {
 		return b.Status.Phase == buildv1.BuildPhaseComplete
 	}
@@ -1076,21 +1120,21 @@ func WaitForBuildComplete(ctx context.Context, c buildv1client.BuildInterface, n
 	for {
 		list, err := c.List(ctx, metav1.ListOptions{FieldSelector: fields.Set{"metadata.name": name}.AsSelector().String()})
 		if err != nil {
-			return err
+			return nil, err
 		}
 		for i := range list.Items {
 			if name == list.Items[i].Name && isOK(&list.Items[i]) {
-				return nil
+				return &list.Items[i], nil
 			}
 			if name != list.Items[i].Name || isFailed(&list.Items[i]) {
-				return fmt.Errorf("the build %s/%s status is %q", list.Items[i].Namespace, list.Items[i].Name, list.Items[i].Status.Phase)
+				return &list.Items[i], fmt.Errorf("the build %s/%s status is %q", list.Items[i].Namespace, list.Items[i].Name, list.Items[i].Status.Phase)
 			}
 		}
 
 		rv := list.ResourceVersion
 		w, err := c.Watch(ctx, metav1.ListOptions{FieldSelector: fields.Set{"metadata.name": name}.AsSelector().String(), ResourceVersion: rv})
 		if err != nil {
-			return err
+			return nil, err
 		}
 		defer w.Stop()
 
@@ -1102,10 +1146,10 @@ func WaitForBuildComplete(ctx context.Context, c buildv1client.BuildInterface, n
 			}
 			if e, ok := val.Object.(*buildv1.Build); ok {
 				if name == e.Name && isOK(e) {
-					return nil
+					return e, nil
 				}
 				if name != e.Name || isFailed(e) {
-					return fmt.Errorf("the build %s/%s status is %q", e.Namespace, name, e.Status.Phase)
+					return e, fmt.Errorf("the build %s/%s status is %q", e.Namespace, name, e.Status.Phase)
 				}
 			}
 		}
@@ -285,7 +285,7 @@ func TestHttpBinary(t *testing.T) {
 
 		defaultExclusionPattern := tar.DefaultExclusionPattern.String()
 
-		build, err := streamPathToBuild(nil, stdin, stdout, &FakeBuildConfigs{t: t, expectAsFile: tc.fromFile}, fromDir, fromFile, "", defaultExclusionPattern, &options)
+		build, err := streamPathToBuild(nil, stdin, stdout, &FakeBuildConfigs{t: t, expectAsFile: tc.fromFile}, fromDir, fromFile, "", defaultExclusionPattern, archiveOptions{}, &options)
 
 		if len(tc.expectedError) > 0 {
 			if err == nil {
@@ -396,7 +396,7 @@ func TestStreamBuildLogs(t *testing.T) {
 				BuildLogClient: buildclientmanual.NewBuildLogClient(fakeREST, build.Namespace, scheme),
 			}
 
-			err := o.streamBuildLogs(context.TODO(), build)
+			err := o.streamBuildLogs(context.TODO(), build, o.Out)
 			if tc.RequestErr == nil && tc.IOErr == nil {
 				if err != nil {
 					t.Errorf("received unexpected error streaming build logs: %v", err)
@@ -0,0 +1,215 @@
+package startbuild
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	units "github.com/docker/go-units"
+	"github.com/joelanford/ignore"
+	"github.com/klauspost/compress/zstd"
+	"github.com/klauspost/pgzip"
+	"github.com/moby/patternmatcher"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc/pkg/helpers/term"
+)
+
+const (
+	compressionGzip = "gzip"
+	compressionZstd = "zstd"
+	compressionNone = "none"
+)
+
+var compressions = []string{compressionGzip, compressionZstd, compressionNone}
+
+// archiveOptions control how a directory is archived for a binary build.
+type archiveOptions struct {
+	// compression is one of compressions
+	compression string
+	// ignoreFiles excludes the files matched by the .gitignore files and the
+	// .containerignore, or .dockerignore, file of the directory
+	ignoreFiles bool
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// newCompressor returns a writer compressing to w. Both gzip and zstd compress
+// blocks in parallel on all CPUs.
+func newCompressor(compression string, w io.Writer) (io.WriteCloser, error) {
+	switch compression {
+	case compressionGzip, "":
+		return pgzip.NewWriter(w), nil
+	case compressionZstd:
+		return zstd.NewWriter(w)
+	case compressionNone:
+		return nopWriteCloser{w}, nil
+	default:
+		return nil, fmt.Errorf("unknown compression %q, must be one of %s", compression, strings.Join(compressions, ", "))
+	}
+}
+
+// ignoreMatcher excludes the files of a directory that are matched by its
+// .containerignore file, which has the same syntax as a .dockerignore file
+// and falls back to it, and by the .gitignore files of the directory and its
+// subdirectories.
+type ignoreMatcher struct {
+	container *patternmatcher.PatternMatcher
+	git       ignore.Matcher
+}
+
+func newIgnoreMatcher(root string) (*ignoreMatcher, error) {
+	m := &ignoreMatcher{}
+	for _, name := range []string{".containerignore", ".dockerignore"} {
+		patterns, err := readIgnoreFile(filepath.Join(root, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if m.container, err = patternmatcher.New(patterns); err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", name, err)
+		}
+		break
+	}
+	var err error
+	if m.git, err = ignore.NewMatcher(os.DirFS(root), ".gitignore"); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// readIgnoreFile returns the patterns of a .containerignore file, cleaned like
+// container builds do.
+func readIgnoreFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var patterns []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		pattern := strings.TrimSpace(s.Text())
+		if len(pattern) == 0 || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		invert := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimSpace(strings.TrimPrefix(pattern, "!"))
+		if len(pattern) > 0 {
+			pattern = filepath.Clean(pattern)
+			pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "/")
+			if len(pattern) == 0 {
+				pattern = "."
+			}
+		}
+		if invert {
+			pattern = "!" + pattern
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, s.Err()
+}
+
+// exclude returns true if the file at the slash separated path relative to the
+// root should not be archived.
+func (m *ignoreMatcher) exclude(path string, isDir bool) bool {
+	if m.container != nil {
+		excluded, err := m.container.MatchesOrParentMatches(path)
+		if err != nil {
+			klog.V(4).Infof("Unable to match %s against the ignore file: %v", path, err)
+		}
+		if excluded && (!isDir || !m.reincluded(path)) {
+			return true
+		}
+	}
+
+	return m.git.Match(filepath.FromSlash(path), isDir)
+}
+
+// reincluded returns true if an exclusion of the .containerignore file may
+// include files of the directory again, in which case it is walked.
+func (m *ignoreMatcher) reincluded(dir string) bool {
+	for _, pattern := range m.container.Patterns() {
+		if pattern.Exclusion() && strings.HasPrefix(pattern.String()+"/", dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// countingReader counts the bytes read for the upload progress.
+type countingReader struct {
+	r     io.Reader
+	count atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.count.Add(int64(n))
+	return n, err
+}
+
+// progress reports the bytes uploaded so far until the returned function is
+// called. On a terminal, a single line is refreshed every second, with a bar if
+// the size of the upload is known, while other outputs get a dot every five
+// seconds.
+func progress(out io.Writer, uploaded *countingReader, size int64) func() {
+	interactive := term.IsTerminalWriter(out)
+	interval := 5 * time.Second
+	if interactive {
+		interval = time.Second
+	}
+	start := time.Now()
+	stop := make(chan bool)
+	done := make(chan bool)
+	go func() {
+		tick := time.NewTicker(interval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-tick.C:
+				if interactive {
+					fmt.Fprintf(out, "\r%s", progressLine(uploaded.count.Load(), size, time.Since(start)))
+				} else {
+					fmt.Fprintf(out, ".")
+				}
+			case <-stop:
+				fmt.Fprintf(out, "\nUploading finished, %s sent\n", units.HumanSize(float64(uploaded.count.Load())))
+				done <- true
+				return
+			}
+		}
+	}()
+	return func() {
+		stop <- true
+		<-done
+	}
+}
+
+func progressLine(uploaded, size int64, elapsed time.Duration) string {
+	rate := ""
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		rate = fmt.Sprintf(" (%s/s)", units.HumanSize(float64(uploaded)/seconds))
+	}
+	if size <= 0 {
+		return fmt.Sprintf("Uploaded %s%s", units.HumanSize(float64(uploaded)), rate)
+	}
+	const width = 30
+	filled := int(width * uploaded / size)
+	if filled > width {
+		filled = width
+	}
+	return fmt.Sprintf("[%s%s] %3d%% %s/%s%s", strings.Repeat("=", filled), strings.Repeat(" ", width-filled),
+		100*uploaded/size, units.HumanSize(float64(uploaded)), units.HumanSize(float64(size)), rate)
+}
@@ -0,0 +1,118 @@
+package startbuild
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/klauspost/pgzip"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		".containerignore":      "# comment\n/secrets\nlogs/*\n!logs/keep.log\n",
+		".gitignore":            "node_modules/\n*.tmp\n",
+		"src/.gitignore":        "generated/\n!important.tmp\n",
+		"src/main.go":           "",
+		"src/important.tmp":     "",
+		"src/generated/file.go": "",
+	} {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, err := newIgnoreMatcher(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		exclude bool
+	}{
+		{path: "secrets", isDir: true, exclude: true},
+		{path: "logs", isDir: true},
+		{path: "logs/app.log", exclude: true},
+		{path: "logs/keep.log"},
+		{path: "node_modules", isDir: true, exclude: true},
+		{path: "cache.tmp", exclude: true},
+		{path: "src", isDir: true},
+		{path: "src/main.go"},
+		{path: "src/important.tmp"},
+		{path: "src/generated", isDir: true, exclude: true},
+		{path: "generated", isDir: true},
+	}
+	for _, tt := range tests {
+		if got := m.exclude(tt.path, tt.isDir); got != tt.exclude {
+			t.Errorf("exclude(%q) = %t, want %t", tt.path, got, tt.exclude)
+		}
+	}
+}
+
+func TestNewCompressor(t *testing.T) {
+	data := bytes.Repeat([]byte("binary build source\n"), 1000)
+	tests := []struct {
+		compression string
+		decompress  func(io.Reader) (io.Reader, error)
+	}{
+		{compression: compressionGzip, decompress: func(r io.Reader) (io.Reader, error) { return pgzip.NewReader(r) }},
+		{compression: compressionZstd, decompress: func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) }},
+		{compression: compressionNone, decompress: func(r io.Reader) (io.Reader, error) { return r, nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.compression, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w, err := newCompressor(tt.compression, buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := w.Write(data); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if !isArchive(bufioReader(buf.Bytes())) && tt.compression != compressionNone {
+				t.Errorf("expected a compressed archive to be detected")
+			}
+			r, err := tt.decompress(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(out, data) {
+				t.Errorf("unexpected content after decompression")
+			}
+		})
+	}
+	if _, err := newCompressor("bzip2", io.Discard); err == nil {
+		t.Errorf("expected an error for an unknown compression")
+	}
+}
+
+func TestProgressLine(t *testing.T) {
+	if got, want := progressLine(2000, 0, 2*time.Second), "Uploaded 2kB (1kB/s)"; got != want {
+		t.Errorf("progressLine() = %q, want %q", got, want)
+	}
+	if got, want := progressLine(500, 1000, time.Second), "[===============               ]  50% 500B/1kB (500B/s)"; got != want {
+		t.Errorf("progressLine() = %q, want %q", got, want)
+	}
+}
+
+func bufioReader(data []byte) *bufio.Reader {
+	return bufio.NewReader(bytes.NewReader(data))
+}
@@ -2,6 +2,7 @@ package status
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -14,6 +15,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
 
 	appsv1client "github.com/openshift/client-go/apps/clientset/versioned/typed/apps/v1"
 	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
@@ -22,6 +24,7 @@ import (
 	routev1client "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
 	"github.com/openshift/oc/pkg/helpers/describe"
 	dotutil "github.com/openshift/oc/pkg/helpers/dot"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
 	loginutil "github.com/openshift/oc/pkg/helpers/project"
 )
 
@@ -35,7 +38,14 @@ var (
 		oc describe deploymentconfig, oc describe service).
 
 		You can specify an output format of "-o dot" to have this command output the generated status
-		graph in DOT format that is suitable for use by the "dot" command.`)
+		graph in DOT format that is suitable for use by the "dot" command.
+
+		Use "-o json" or "-o yaml" to print the applications, routes, and identified issues with their
+		suggestions in a schema meant for scripts. Fields are only ever added to the schema.
+
+		Use --exit-code to exit with a non-zero status when issues of the given severities (error,
+		warning, or info) are identified, for instance to gate a CI environment on the health of a
+		project. It only checks errors when no severity is given.`)
 
 	statusExample = templates.Examples(`
 		# See an overview of the current project
@@ -45,7 +55,13 @@ var (
 		oc status -o dot | dot -T svg -o project.svg
 
 		# See an overview of the current project including details for any identified issues
-		oc status --suggest`)
+		oc status --suggest
+
+		# Fail when errors or warnings are identified in the current project
+		oc status --exit-code=error,warning
+
+		# Print the overview of the current project in JSON
+		oc status -o json`)
 )
 
 // StatusOptions contains all the necessary options for the Openshift cli status command.
@@ -55,6 +71,7 @@ type StatusOptions struct {
 	outputFormat  string
 	describer     *describe.ProjectStatusDescriber
 	suggest       bool
+	exitCode      []string
 
 	logsCommandName             string
 	securityPolicyCommandFormat string
@@ -75,7 +92,7 @@ func NewStatusOptions(streams genericiooptions.IOStreams) *StatusOptions {
 func NewCmdStatus(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
 	o := NewStatusOptions(streams)
 	cmd := &cobra.Command{
-		Use:     "status [-o dot | --suggest ]",
+		Use:     "status [-o dot|json|yaml | --suggest ] [--exit-code=SEVERITY,...]",
 		Short:   "Show an overview of the current project",
 		Long:    statusLong,
 		Example: statusExample,
@@ -85,9 +102,11 @@ func NewCmdStatus(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra
 			kcmdutil.CheckErr(o.RunStatus())
 		},
 	}
-	cmd.Flags().StringVarP(&o.outputFormat, "output", "o", o.outputFormat, "Output format. One of: dot.")
+	cmd.Flags().StringVarP(&o.outputFormat, "output", "o", o.outputFormat, "Output format. One of: dot, json, yaml.")
 	cmd.Flags().BoolVar(&o.suggest, "suggest", o.suggest, "See details for resolving issues.")
 	cmd.Flags().BoolVarP(&o.allNamespaces, "all-namespaces", "A", o.allNamespaces, "If true, display status for all namespaces (must have cluster admin)")
+	cmd.Flags().StringSliceVar(&o.exitCode, "exit-code", o.exitCode, "Exit with a non-zero status when issues of these severities are identified. One or more of: error, warning, info.")
+	cmd.Flags().Lookup("exit-code").NoOptDefVal = string(osgraph.ErrorSeverity)
 
 	return cmd
 }
@@ -192,34 +211,52 @@ func (o *StatusOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []
 
 // Validate validates the options for the Openshift cli status command.
 func (o StatusOptions) Validate() error {
-	if len(o.outputFormat) != 0 && o.outputFormat != "dot" {
+	switch o.outputFormat {
+	case "", "dot", "json", "yaml":
+	default:
 		return fmt.Errorf("invalid output format provided: %s", o.outputFormat)
 	}
-	if len(o.outputFormat) > 0 && o.suggest {
+	if o.outputFormat == "dot" && o.suggest {
 		return errors.New("cannot provide suggestions when output format is dot")
 	}
+	for _, severity := range o.exitCode {
+		switch osgraph.Severity(severity) {
+		case osgraph.ErrorSeverity, osgraph.WarningSeverity, osgraph.InfoSeverity:
+		default:
+			return fmt.Errorf("--exit-code must be one or more of error, warning, or info: %s", severity)
+		}
+	}
 	return nil
 }
 
 // RunStatus contains all the necessary functionality for the OpenShift cli status command.
 func (o StatusOptions) RunStatus() error {
-	var (
-		s   string
-		err error
-	)
+	g, forbiddenResources, err := o.describer.MakeGraph(o.namespace)
+	if err != nil {
+		return err
+	}
 
+	var s string
 	switch o.outputFormat {
 	case "":
-		s, err = o.describer.Describe(o.namespace, "")
+		s, err = o.describer.DescribeGraph(g, forbiddenResources, o.namespace)
 		if err != nil {
 			return err
 		}
 	case "dot":
-		g, _, err := o.describer.MakeGraph(o.namespace)
+		data, err := dot.Marshal(g, dotutil.Quote(o.namespace), "", "  ", false)
 		if err != nil {
 			return err
 		}
-		data, err := dot.Marshal(g, dotutil.Quote(o.namespace), "", "  ", false)
+		s = string(data)
+	case "json":
+		data, err := json.MarshalIndent(o.describer.Status(g, forbiddenResources, o.namespace), "", "  ")
+		if err != nil {
+			return err
+		}
+		s = string(data) + "\n"
+	case "yaml":
+		data, err := yaml.Marshal(o.describer.Status(g, forbiddenResources, o.namespace))
 		if err != nil {
 			return err
 		}
@@ -228,6 +265,15 @@ func (o StatusOptions) RunStatus() error {
 		return fmt.Errorf("invalid output format provided: %s", o.outputFormat)
 	}
 
-	fmt.Fprintf(o.Out, s)
+	fmt.Fprint(o.Out, s)
+
+	if len(o.exitCode) > 0 {
+		markers := o.describer.Markers(g, forbiddenResources, o.namespace)
+		for _, severity := range o.exitCode {
+			if len(markers.BySeverity(osgraph.Severity(severity))) > 0 {
+				return kcmdutil.ErrExit
+			}
+		}
+	}
 	return nil
 }
@@ -0,0 +1,333 @@
+package tag
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/util/retry"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/yaml"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	"github.com/openshift/library-go/pkg/image/imageutil"
+)
+
+// destination is a tag set or deleted by the command.
+type destination struct {
+	namespace, name, tag string
+}
+
+func (d destination) stream() string { return d.namespace + "/" + d.name }
+
+func (d destination) String() string { return d.stream() + ":" + d.tag }
+
+// destinations returns the tags set or deleted by o.
+func (o *TagOptions) destinations() ([]destination, error) {
+	var dests []destination
+	for i, destNameAndTag := range o.destNameAndTag {
+		name, tag, ok := imageutil.SplitImageStreamTag(destNameAndTag)
+		if !ok {
+			return nil, fmt.Errorf("%q must be of the form <stream_name>:<tag>", destNameAndTag)
+		}
+		dests = append(dests, destination{namespace: o.destNamespace[i], name: name, tag: tag})
+	}
+	return dests, nil
+}
+
+// completeMappings reads the --filename file, each line of which has the
+// arguments of the command: a source followed by its destinations, or with
+// --delete the tags to delete. Blank lines and comments starting with # are
+// ignored. Each line is completed and validated before any tag is changed.
+func (o *TagOptions) completeMappings(f kcmdutil.Factory, cmd *cobra.Command) error {
+	in := o.In
+	if o.filename != "-" {
+		file, err := os.Open(o.filename)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+
+	s := bufio.NewScanner(in)
+	lineNumber := 0
+	for s.Scan() {
+		line := s.Text()
+		lineNumber++
+
+		// remove comments and whitespace
+		if i := strings.Index(line, "#"); i != -1 {
+			line = line[0:i]
+		}
+		args := strings.Fields(line)
+		if len(args) == 0 {
+			continue
+		}
+		if len(args) < 2 && !o.deleteTag {
+			return fmt.Errorf("file %s, line %d: a source and at least one destination are required", o.filename, lineNumber)
+		}
+
+		entry := *o
+		entry.filename = ""
+		entry.mappings = nil
+		if err := entry.completeArgs(f, cmd, args); err != nil {
+			return fmt.Errorf("file %s, line %d: %v", o.filename, lineNumber, err)
+		}
+		if err := entry.Validate(); err != nil {
+			return fmt.Errorf("file %s, line %d: %v", o.filename, lineNumber, err)
+		}
+		o.mappings = append(o.mappings, &entry)
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+	if len(o.mappings) == 0 {
+		return fmt.Errorf("%s does not contain any tags", o.filename)
+	}
+	return nil
+}
+
+// validateMappings ensures that a tag is changed by a single line of the
+// --filename file, since the destinations are restored as a whole.
+func (o *TagOptions) validateMappings() error {
+	seen := map[destination]bool{}
+	for _, entry := range o.mappings {
+		dests, err := entry.destinations()
+		if err != nil {
+			return err
+		}
+		for _, dest := range dests {
+			if seen[dest] {
+				return fmt.Errorf("the tag %s is listed more than once in %s", dest, o.filename)
+			}
+			seen[dest] = true
+		}
+	}
+	return nil
+}
+
+// runMappings sets the tags of all the lines of the --filename file. The
+// destination tags are saved beforehand and restored if setting a tag fails,
+// so an application is either promoted as a whole or left as it was.
+func (o TagOptions) runMappings() error {
+	previous, err := o.currentTags(o.mappings)
+	if err != nil {
+		return err
+	}
+	for i, entry := range o.mappings {
+		if err := entry.Run(); err != nil {
+			fmt.Fprintf(o.ErrOut, "error: %v, restoring the tags already set\n", err)
+			if restoreErr := o.restoreTags(o.mappings[:i+1], previous); restoreErr != nil {
+				return utilerrors.NewAggregate([]error{err, restoreErr})
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// currentTags returns the spec tags of the destinations of the entries, nil
+// for those that do not exist.
+func (o TagOptions) currentTags(entries []*TagOptions) (map[destination]*imagev1.TagReference, error) {
+	streams := map[string]*imagev1.ImageStream{}
+	tags := map[destination]*imagev1.TagReference{}
+	for _, entry := range entries {
+		dests, err := entry.destinations()
+		if err != nil {
+			return nil, err
+		}
+		for _, dest := range dests {
+			stream, ok := streams[dest.stream()]
+			if !ok {
+				stream, err = o.client.ImageStreams(dest.namespace).Get(context.TODO(), dest.name, metav1.GetOptions{})
+				if kerrors.IsNotFound(err) {
+					stream, err = nil, nil
+				}
+				if err != nil {
+					return nil, err
+				}
+				streams[dest.stream()] = stream
+			}
+			tags[dest] = nil
+			if stream != nil {
+				if tag, ok := imageutil.SpecHasTag(stream, dest.tag); ok {
+					tags[dest] = tag.DeepCopy()
+				}
+			}
+		}
+	}
+	return tags, nil
+}
+
+// restoreTags sets the destinations of the entries back to their previous
+// spec tag, deleting those that did not exist.
+func (o TagOptions) restoreTags(entries []*TagOptions, previous map[destination]*imagev1.TagReference) error {
+	var errs []error
+	for _, entry := range entries {
+		dests, err := entry.destinations()
+		if err != nil {
+			return err
+		}
+		for _, dest := range dests {
+			if err := o.restoreTag(dest, previous[dest]); err != nil {
+				errs = append(errs, fmt.Errorf("unable to restore the tag %s: %v", dest, err))
+				continue
+			}
+			fmt.Fprintf(o.Out, "Restored tag %s.\n", dest)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func (o TagOptions) restoreTag(dest destination, tag *imagev1.TagReference) error {
+	if tag == nil {
+		err := o.client.ImageStreamTags(dest.namespace).Delete(context.TODO(), imageutil.JoinImageStreamTag(dest.name, dest.tag), metav1.DeleteOptions{})
+		if kerrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		stream, err := o.client.ImageStreams(dest.namespace).Get(context.TODO(), dest.name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		setSpecTag(stream, *tag)
+		_, err = o.client.ImageStreams(dest.namespace).Update(context.TODO(), stream, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// setSpecTag creates or updates the spec tag of the stream.
+func setSpecTag(stream *imagev1.ImageStream, tag imagev1.TagReference) {
+	for i := range stream.Spec.Tags {
+		if stream.Spec.Tags[i].Name == tag.Name {
+			stream.Spec.Tags[i] = tag
+			return
+		}
+	}
+	stream.Spec.Tags = append(stream.Spec.Tags, tag)
+}
+
+// removeSpecTag deletes the spec tag of the stream and returns false if it
+// does not exist.
+func removeSpecTag(stream *imagev1.ImageStream, name string) bool {
+	for i := range stream.Spec.Tags {
+		if stream.Spec.Tags[i].Name == name {
+			stream.Spec.Tags = append(stream.Spec.Tags[:i], stream.Spec.Tags[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// runDryRun prints the tags that would be set or deleted, or with --output=diff
+// the changes to the spec of each image stream, without changing them.
+func (o TagOptions) runDryRun() error {
+	entries := o.mappings
+	if len(entries) == 0 {
+		entries = []*TagOptions{&o}
+	}
+
+	var order []string
+	current := map[string]*imagev1.ImageStream{}
+	updated := map[string]*imagev1.ImageStream{}
+	for _, entry := range entries {
+		dests, err := entry.destinations()
+		if err != nil {
+			return err
+		}
+		for i, dest := range dests {
+			stream, ok := updated[dest.stream()]
+			if !ok {
+				existing, err := o.client.ImageStreams(dest.namespace).Get(context.TODO(), dest.name, metav1.GetOptions{})
+				switch {
+				case kerrors.IsNotFound(err):
+					existing = nil
+					stream = &imagev1.ImageStream{ObjectMeta: metav1.ObjectMeta{Name: dest.name, Namespace: dest.namespace}}
+				case err != nil:
+					return err
+				default:
+					stream = existing.DeepCopy()
+				}
+				order = append(order, dest.stream())
+				current[dest.stream()] = existing
+				updated[dest.stream()] = stream
+			}
+
+			var msg string
+			if entry.deleteTag {
+				if !removeSpecTag(stream, dest.tag) {
+					return fmt.Errorf("destination tag %s does not exist", dest)
+				}
+				msg = fmt.Sprintf("Deleted tag %s", dest)
+			} else {
+				if err := validateTag(dest.tag); err != nil {
+					return err
+				}
+				istag, tagMsg := entry.newImageStreamTag(i, entry.destNameAndTag[i], dest.tag)
+				setSpecTag(stream, *istag.Tag)
+				msg = strings.TrimSuffix(tagMsg, ".")
+			}
+			if o.output != "diff" {
+				fmt.Fprintf(o.Out, "%s (dry run)\n", msg)
+			}
+		}
+	}
+
+	if o.output == "diff" {
+		for _, name := range order {
+			diff, err := specDiff(name, current[name], updated[name])
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(o.Out, diff)
+		}
+	}
+	return nil
+}
+
+// specDiff returns the unified diff of the spec of an image stream, which does
+// not exist yet if current is nil. The tag generations are omitted, since the
+// server sets them.
+func specDiff(name string, current, updated *imagev1.ImageStream) (string, error) {
+	toYAML := func(stream *imagev1.ImageStream) (string, error) {
+		if stream == nil {
+			return "", nil
+		}
+		spec := stream.Spec.DeepCopy()
+		for i := range spec.Tags {
+			spec.Tags[i].Generation = nil
+		}
+		data, err := yaml.Marshal(map[string]interface{}{"spec": spec})
+		return string(data), err
+	}
+	a, err := toYAML(current)
+	if err != nil {
+		return "", err
+	}
+	b, err := toYAML(updated)
+	if err != nil {
+		return "", err
+	}
+	from := name
+	if current == nil {
+		from = "/dev/null"
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(a),
+		B:        difflib.SplitLines(b),
+		FromFile: from,
+		ToFile:   name,
+		Context:  3,
+	})
+}
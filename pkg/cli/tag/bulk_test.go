@@ -0,0 +1,148 @@
+package tag
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	clientgotesting "k8s.io/client-go/testing"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	fakeimagev1client "github.com/openshift/client-go/image/clientset/versioned/fake"
+	"github.com/openshift/library-go/pkg/image/imageutil"
+)
+
+func promotionStream() *imagev1.ImageStream {
+	return &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "prod", CreationTimestamp: metav1.Now()},
+		Spec: imagev1.ImageStreamSpec{
+			Tags: []imagev1.TagReference{{
+				Name:            "latest",
+				From:            &corev1.ObjectReference{Kind: "DockerImage", Name: "registry.io/app/frontend:1.0"},
+				ReferencePolicy: imagev1.TagReferencePolicy{Type: imagev1.SourceTagReferencePolicy},
+			}},
+		},
+	}
+}
+
+func promotionEntry(streams genericiooptions.IOStreams, source, dest string) *TagOptions {
+	namespace, nameAndTag, _ := parseStreamName("dev", dest)
+	ref, _ := imageutil.ParseDockerImageReference(source)
+	return &TagOptions{
+		IOStreams:       streams,
+		namespace:       "dev",
+		sourceKind:      "DockerImage",
+		ref:             ref,
+		referencePolicy: SourceReferencePolicy,
+		importMode:      string(imagev1.ImportModeLegacy),
+		destNamespace:   []string{namespace},
+		destNameAndTag:  []string{nameAndTag},
+	}
+}
+
+func TestRunDryRunDiff(t *testing.T) {
+	client := fakeimagev1client.NewSimpleClientset(promotionStream())
+	out := &bytes.Buffer{}
+	streams := genericiooptions.IOStreams{Out: out, ErrOut: out}
+	o := TagOptions{
+		IOStreams: streams,
+		client:    client.ImageV1(),
+		filename:  "promote.txt",
+		dryRun:    true,
+		output:    "diff",
+		mappings: []*TagOptions{
+			promotionEntry(streams, "registry.io/app/frontend:2.0", "prod/frontend:latest"),
+			promotionEntry(streams, "registry.io/app/backend:2.0", "prod/backend:latest"),
+		},
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"--- prod/frontend\n+++ prod/frontend\n",
+		"-      name: registry.io/app/frontend:1.0\n+      name: registry.io/app/frontend:2.0\n",
+		"--- /dev/null\n+++ prod/backend\n",
+		"+      name: registry.io/app/backend:2.0\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in the diff:\n%s", want, out.String())
+		}
+	}
+	for _, action := range client.Actions() {
+		if action.GetVerb() != "get" {
+			t.Errorf("unexpected action %s %s during a dry run", action.GetVerb(), action.GetResource().Resource)
+		}
+	}
+}
+
+func TestRunMappingsRestore(t *testing.T) {
+	client := fakeimagev1client.NewSimpleClientset(promotionStream())
+	client.PrependReactor("*", "imagestreamtags", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		if create, ok := action.(clientgotesting.CreateAction); ok && create.GetObject().(*imagev1.ImageStreamTag).Name == "backend:latest" {
+			return true, nil, fmt.Errorf("quota exceeded")
+		}
+		return false, nil, nil
+	})
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	streams := genericiooptions.IOStreams{Out: out, ErrOut: errOut}
+	o := TagOptions{
+		IOStreams: streams,
+		client:    client.ImageV1(),
+		filename:  "promote.txt",
+		mappings: []*TagOptions{
+			promotionEntry(streams, "registry.io/app/frontend:2.0", "prod/frontend:latest"),
+			promotionEntry(streams, "registry.io/app/backend:2.0", "prod/backend:latest"),
+		},
+	}
+	for _, entry := range o.mappings {
+		entry.client = o.client
+	}
+	if err := o.Run(); err == nil || err.Error() != "quota exceeded" {
+		t.Fatalf("expected the import to fail, got %v", err)
+	}
+	if !strings.Contains(errOut.String(), "restoring the tags already set") {
+		t.Errorf("unexpected error output: %s", errOut.String())
+	}
+	want := "Tag prod/frontend:latest set to registry.io/app/frontend:2.0.\n" +
+		"Restored tag prod/frontend:latest.\n" +
+		"Restored tag prod/backend:latest.\n"
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	stream, err := client.ImageV1().ImageStreams("prod").Get(context.TODO(), "frontend", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from := stream.Spec.Tags[0].From.Name; from != "registry.io/app/frontend:1.0" {
+		t.Errorf("expected the tag to be restored, got %s", from)
+	}
+}
+
+func TestValidateMappings(t *testing.T) {
+	streams := genericiooptions.NewTestIOStreamsDiscard()
+	o := TagOptions{
+		filename: "promote.txt",
+		mappings: []*TagOptions{
+			promotionEntry(streams, "registry.io/app/frontend:2.0", "prod/frontend:latest"),
+			promotionEntry(streams, "registry.io/app/frontend:3.0", "prod/frontend:latest"),
+		},
+	}
+	if err := o.Validate(); err == nil || !strings.Contains(err.Error(), "listed more than once") {
+		t.Errorf("expected an error for a duplicated destination, got %v", err)
+	}
+	o.mappings = o.mappings[:1]
+	o.output = "diff"
+	if err := o.Validate(); err == nil || !strings.Contains(err.Error(), "requires --dry-run") {
+		t.Errorf("expected an error for -o diff without --dry-run, got %v", err)
+	}
+}
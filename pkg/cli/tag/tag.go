@@ -23,6 +23,7 @@ import (
 	imagev1 "github.com/openshift/api/image/v1"
 	imagev1typedclient "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
 	"github.com/openshift/library-go/pkg/image/imageutil"
+	ocompletion "github.com/openshift/oc/pkg/helpers/completion"
 	imagehelpers "github.com/openshift/oc/pkg/helpers/image"
 )
 
@@ -45,6 +46,14 @@ type TagOptions struct {
 	destNamespace  []string
 	destNameAndTag []string
 
+	// filename is a file of the tags to set, see completeMappings, which are
+	// read into mappings.
+	filename string
+	mappings []*TagOptions
+
+	dryRun bool
+	output string
+
 	genericiooptions.IOStreams
 }
 
@@ -61,7 +70,14 @@ var (
 		certificate, or is only served over HTTP. Pass --scheduled to have the server
 		regularly check the tag for updates and import the latest version (which can
 		then trigger builds and deployments). Note that --scheduled is only allowed for
-		container images.`)
+		container images.
+
+		To promote the images of a whole application between environments, pass a file
+		with --filename where each line has a source and one or more destinations, in
+		any namespace, or with --delete the tags to delete. All the sources are resolved
+		before any tag is changed, and if setting a tag fails the tags already set are
+		restored to their previous value. Pass --dry-run to print the tags that would be
+		set, and -o diff to print the changes to the image streams instead.`)
 
 	tagExample = templates.Examples(`
 		# Tag the current image for the image stream 'openshift/ruby' and tag '2.0' into the image stream 'yourproject/ruby with tag 'tip'
@@ -80,7 +96,13 @@ var (
 		oc tag --source=docker openshift/origin-control-plane:latest yourproject/ruby:tip --import-mode=PreserveOriginal
 
 		# Remove the specified spec tag from an image stream
-		oc tag openshift/origin-control-plane:latest -d`)
+		oc tag openshift/origin-control-plane:latest -d
+
+		# Promote the images listed in a file, with lines like 'dev/frontend:latest prod/frontend:latest'
+		oc tag -f promote.txt
+
+		# Show the changes to the image streams that promoting the images would make
+		oc tag -f promote.txt --dry-run -o diff`)
 )
 
 const (
@@ -98,10 +120,11 @@ func NewTagOptions(streams genericiooptions.IOStreams) *TagOptions {
 func NewCmdTag(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
 	o := NewTagOptions(streams)
 	cmd := &cobra.Command{
-		Use:     "tag [--source=SOURCETYPE] SOURCE DEST [DEST ...]",
-		Short:   "Tag existing images into image streams",
-		Long:    tagLong,
-		Example: tagExample,
+		Use:               "tag [--source=SOURCETYPE] (SOURCE DEST [DEST ...] | -f FILENAME)",
+		Short:             "Tag existing images into image streams",
+		Long:              tagLong,
+		Example:           tagExample,
+		ValidArgsFunction: ocompletion.ImageStreamTagCompletionFunc(f),
 		Run: func(cmd *cobra.Command, args []string) {
 			kcmdutil.CheckErr(o.Complete(f, cmd, args))
 			kcmdutil.CheckErr(o.Validate())
@@ -117,6 +140,9 @@ func NewCmdTag(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Co
 	cmd.Flags().BoolVar(&o.insecureTag, "insecure", o.insecureTag, "Set to true if importing the specified container image requires HTTP or has a self-signed certificate. Defaults to false.")
 	cmd.Flags().StringVar(&o.referencePolicy, "reference-policy", SourceReferencePolicy, "Allow to request pullthrough for external image when set to 'local'. Defaults to 'source'.")
 	cmd.Flags().StringVar(&o.importMode, "import-mode", o.importMode, "Imports the full manifest list of a tag when set to 'PreserveOriginal'. Defaults to 'Legacy'.")
+	cmd.Flags().StringVarP(&o.filename, "filename", "f", o.filename, "A file of tags to set, with a source and its destinations on each line, or - to read it from standard input.")
+	cmd.Flags().BoolVar(&o.dryRun, "dry-run", o.dryRun, "If true, print the tags that would be set without changing them.")
+	cmd.Flags().StringVarP(&o.output, "output", "o", o.output, "Output format with --dry-run. The only supported value is 'diff', printing the changes to each image stream.")
 
 	return cmd
 }
@@ -165,7 +191,11 @@ func determineSourceKind(f kcmdutil.Factory, input string) (string, error) {
 
 // Complete completes all the required options for the tag command.
 func (o *TagOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
-	if len(args) < 2 && (len(args) < 1 && !o.deleteTag) {
+	if len(o.filename) > 0 {
+		if len(args) > 0 {
+			return kcmdutil.UsageErrorf(cmd, "no arguments may be specified with --filename")
+		}
+	} else if len(args) < 2 && (len(args) < 1 && !o.deleteTag) {
 		return kcmdutil.UsageErrorf(cmd, "you must specify a source and at least one destination or one or more tags to delete")
 	}
 
@@ -184,6 +214,16 @@ func (o *TagOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []str
 		return err
 	}
 
+	if len(o.filename) > 0 {
+		return o.completeMappings(f, cmd)
+	}
+	return o.completeArgs(f, cmd, args)
+}
+
+// completeArgs populates the source and the destinations of the tags from the
+// arguments of the command or of a line of the --filename file.
+func (o *TagOptions) completeArgs(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
 	// Populate source.
 	if !o.deleteTag {
 		source := args[0]
@@ -309,6 +349,19 @@ func isCrossImageStream(namespace string, srcRef imagev1.DockerImageReference, d
 
 // Validate validates all the required options for the tag command.
 func (o *TagOptions) Validate() error {
+	switch o.output {
+	case "":
+	case "diff":
+		if !o.dryRun {
+			return errors.New("--output=diff requires --dry-run")
+		}
+	default:
+		return fmt.Errorf("unsupported output format %q, the only supported value is 'diff'", o.output)
+	}
+	if len(o.filename) > 0 {
+		return o.validateMappings()
+	}
+
 	if o.deleteTag && o.aliasTag {
 		return errors.New("--alias and --delete may not be both specified")
 	}
@@ -391,13 +444,13 @@ func validateTag(tagName string) error {
 
 // Run contains all the necessary functionality for the OpenShift cli tag command.
 func (o TagOptions) Run() error {
-	var tagReferencePolicy imagev1.TagReferencePolicyType
-	switch o.referencePolicy {
-	case SourceReferencePolicy:
-		tagReferencePolicy = imagev1.SourceTagReferencePolicy
-	case LocalReferencePolicy:
-		tagReferencePolicy = imagev1.LocalTagReferencePolicy
+	if o.dryRun {
+		return o.runDryRun()
 	}
+	if len(o.filename) > 0 {
+		return o.runMappings()
+	}
+
 	for i, destNameAndTag := range o.destNameAndTag {
 		destName, destTag, ok := imageutil.SplitImageStreamTag(destNameAndTag)
 		if !ok {
@@ -463,66 +516,7 @@ func (o TagOptions) Run() error {
 				return err
 			}
 
-			// The user wants to symlink a tag.
-			istag := &imagev1.ImageStreamTag{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      destNameAndTag,
-					Namespace: o.destNamespace[i],
-				},
-				Tag: &imagev1.TagReference{
-					Name:      destTag,
-					Reference: o.referenceTag,
-					ImportPolicy: imagev1.TagImportPolicy{
-						Insecure:   o.insecureTag,
-						Scheduled:  o.scheduleTag,
-						ImportMode: imagev1.ImportModeType(o.importMode),
-					},
-					ReferencePolicy: imagev1.TagReferencePolicy{
-						Type: tagReferencePolicy,
-					},
-					From: &corev1.ObjectReference{
-						Kind: o.sourceKind,
-					},
-				},
-			}
-			localRef := o.ref
-			switch o.sourceKind {
-			case "DockerImage":
-				istag.Tag.From.Name = imagehelpers.DockerImageReferenceExact(localRef)
-				gen := int64(0)
-				istag.Tag.Generation = &gen
-
-			default:
-				istag.Tag.From.Name = imagehelpers.DockerImageReferenceNameString(localRef)
-				istag.Tag.From.Namespace = o.ref.Namespace
-				if len(o.ref.Namespace) == 0 && o.destNamespace[i] != o.namespace {
-					istag.Tag.From.Namespace = o.namespace
-				}
-			}
-
-			msg := ""
-			sameNamespace := o.namespace == o.destNamespace[i]
-			if o.aliasTag {
-				if sameNamespace {
-					msg = fmt.Sprintf("Tag %s set up to track %s.", destNameAndTag, imagehelpers.DockerImageReferenceExact(o.ref))
-				} else {
-					msg = fmt.Sprintf("Tag %s/%s set up to track %s.", o.destNamespace[i], destNameAndTag, imagehelpers.DockerImageReferenceExact(o.ref))
-				}
-			} else {
-				if istag.Tag.ImportPolicy.Scheduled {
-					if sameNamespace {
-						msg = fmt.Sprintf("Tag %s set to import %s periodically.", destNameAndTag, imagehelpers.DockerImageReferenceExact(o.ref))
-					} else {
-						msg = fmt.Sprintf("Tag %s/%s set to %s periodically.", o.destNamespace[i], destNameAndTag, imagehelpers.DockerImageReferenceExact(o.ref))
-					}
-				} else {
-					if sameNamespace {
-						msg = fmt.Sprintf("Tag %s set to %s.", destNameAndTag, imagehelpers.DockerImageReferenceExact(o.ref))
-					} else {
-						msg = fmt.Sprintf("Tag %s/%s set to %s.", o.destNamespace[i], destNameAndTag, imagehelpers.DockerImageReferenceExact(o.ref))
-					}
-				}
-			}
+			istag, msg := o.newImageStreamTag(i, destNameAndTag, destTag)
 
 			// supported by new servers.
 			_, err := o.client.ImageStreamTags(o.destNamespace[i]).Update(context.TODO(), istag, metav1.UpdateOptions{})
@@ -610,3 +604,78 @@ func (o TagOptions) Run() error {
 
 	return nil
 }
+
+// newImageStreamTag returns the image stream tag to set for the i-th destination
+// and the message reporting it.
+func (o TagOptions) newImageStreamTag(i int, destNameAndTag, destTag string) (*imagev1.ImageStreamTag, string) {
+	var tagReferencePolicy imagev1.TagReferencePolicyType
+	switch o.referencePolicy {
+	case SourceReferencePolicy:
+		tagReferencePolicy = imagev1.SourceTagReferencePolicy
+	case LocalReferencePolicy:
+		tagReferencePolicy = imagev1.LocalTagReferencePolicy
+	}
+
+	// The user wants to symlink a tag.
+	istag := &imagev1.ImageStreamTag{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      destNameAndTag,
+			Namespace: o.destNamespace[i],
+		},
+		Tag: &imagev1.TagReference{
+			Name:      destTag,
+			Reference: o.referenceTag,
+			ImportPolicy: imagev1.TagImportPolicy{
+				Insecure:   o.insecureTag,
+				Scheduled:  o.scheduleTag,
+				ImportMode: imagev1.ImportModeType(o.importMode),
+			},
+			ReferencePolicy: imagev1.TagReferencePolicy{
+				Type: tagReferencePolicy,
+			},
+			From: &corev1.ObjectReference{
+				Kind: o.sourceKind,
+			},
+		},
+	}
+	localRef := o.ref
+	switch o.sourceKind {
+	case "DockerImage":
+		istag.Tag.From.Name = imagehelpers.DockerImageReferenceExact(localRef)
+		gen := int64(0)
+		istag.Tag.Generation = &gen
+
+	default:
+		istag.Tag.From.Name = imagehelpers.DockerImageReferenceNameString(localRef)
+		istag.Tag.From.Namespace = o.ref.Namespace
+		if len(o.ref.Namespace) == 0 && o.destNamespace[i] != o.namespace {
+			istag.Tag.From.Namespace = o.namespace
+		}
+	}
+
+	msg := ""
+	sameNamespace := o.namespace == o.destNamespace[i]
+	if o.aliasTag {
+		if sameNamespace {
+			msg = fmt.Sprintf("Tag %s set up to track %s.", destNameAndTag, imagehelpers.DockerImageReferenceExact(o.ref))
+		} else {
+			msg = fmt.Sprintf("Tag %s/%s set up to track %s.", o.destNamespace[i], destNameAndTag, imagehelpers.DockerImageReferenceExact(o.ref))
+		}
+	} else {
+		if istag.Tag.ImportPolicy.Scheduled {
+			if sameNamespace {
+				msg = fmt.Sprintf("Tag %s set to import %s periodically.", destNameAndTag, imagehelpers.DockerImageReferenceExact(o.ref))
+			} else {
+				msg = fmt.Sprintf("Tag %s/%s set to %s periodically.", o.destNamespace[i], destNameAndTag, imagehelpers.DockerImageReferenceExact(o.ref))
+			}
+		} else {
+			if sameNamespace {
+				msg = fmt.Sprintf("Tag %s set to %s.", destNameAndTag, imagehelpers.DockerImageReferenceExact(o.ref))
+			} else {
+				msg = fmt.Sprintf("Tag %s/%s set to %s.", o.destNamespace[i], destNameAndTag, imagehelpers.DockerImageReferenceExact(o.ref))
+			}
+		}
+	}
+
+	return istag, msg
+}
@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/util/homedir"
+	"sigs.k8s.io/yaml"
+)
+
+// userDefaults is the content of ~/.config/oc/config.yaml, which sets the default value
+// of flags by name:
+//
+//	# flags of every command, including the global flags like cache-dir
+//	defaults:
+//	  registry-config: ~/.docker/config.json
+//	  max-per-registry: 4
+//	# flags of a command and its subcommands, which take precedence over the defaults
+//	commands:
+//	  image mirror:
+//	    insecure: true
+//	  adm release mirror:
+//	    max-per-registry: 10
+//
+// The flags passed on the command line take precedence over both.
+type userDefaults struct {
+	Defaults map[string]interface{}            `json:"defaults"`
+	Commands map[string]map[string]interface{} `json:"commands"`
+}
+
+// userDefaultsPath returns the path to the user defaults file, $OC_CONFIG if set.
+func userDefaultsPath() string {
+	if path := os.Getenv("OC_CONFIG"); len(path) > 0 {
+		return path
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); len(dir) > 0 {
+		return filepath.Join(dir, "oc", "config.yaml")
+	}
+	return filepath.Join(homedir.HomeDir(), ".config", "oc", "config.yaml")
+}
+
+// loadUserDefaults reads the user defaults file, nil if it does not exist.
+func loadUserDefaults(path string) (*userDefaults, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defaults := &userDefaults{}
+	if err := yaml.UnmarshalStrict(data, defaults); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+	return defaults, nil
+}
+
+// applyUserDefaults sets the defaults of the flags of all the commands to the values of
+// the user defaults file, and returns the settings that do not match any flag.
+func applyUserDefaults(rootCmd *cobra.Command, defaults *userDefaults) []error {
+	if defaults == nil {
+		return nil
+	}
+	sections := make([]string, 0, len(defaults.Commands))
+	for section := range defaults.Commands {
+		sections = append(sections, section)
+	}
+	// apply the sections of the parent commands before those of their subcommands
+	sort.Slice(sections, func(i, j int) bool { return len(sections[i]) < len(sections[j]) })
+
+	var errs []error
+	used := map[string]bool{}
+	usedSections := map[string]map[string]bool{}
+	cmds := []*cobra.Command{rootCmd}
+	for i := 0; i < len(cmds); i++ {
+		currCmd := cmds[i]
+		cmds = append(cmds, currCmd.Commands()...)
+		path := strings.TrimPrefix(strings.TrimPrefix(currCmd.CommandPath(), rootCmd.Name()), " ")
+
+		visitOwnFlags(currCmd, func(flag *pflag.Flag) {
+			value, ok := defaults.Defaults[flag.Name]
+			if !ok {
+				return
+			}
+			used[flag.Name] = true
+			if err := setFlagDefault(flag, value); err != nil {
+				errs = append(errs, fmt.Errorf("defaults: %s: %v", flag.Name, err))
+			}
+		})
+		for _, section := range sections {
+			if path != section && !strings.HasPrefix(path, section+" ") {
+				continue
+			}
+			if usedSections[section] == nil {
+				usedSections[section] = map[string]bool{}
+			}
+			visitOwnFlags(currCmd, func(flag *pflag.Flag) {
+				value, ok := defaults.Commands[section][flag.Name]
+				if !ok {
+					return
+				}
+				usedSections[section][flag.Name] = true
+				if err := setFlagDefault(flag, value); err != nil {
+					errs = append(errs, fmt.Errorf("commands: %s: %s: %v", section, flag.Name, err))
+				}
+			})
+		}
+	}
+
+	for _, name := range sortedKeys(defaults.Defaults) {
+		if !used[name] {
+			errs = append(errs, fmt.Errorf("defaults: no command has a --%s flag", name))
+		}
+	}
+	for _, section := range sections {
+		if usedSections[section] == nil {
+			errs = append(errs, fmt.Errorf("commands: unknown command %q", section))
+			continue
+		}
+		for _, name := range sortedKeys(defaults.Commands[section]) {
+			if !usedSections[section][name] {
+				errs = append(errs, fmt.Errorf("commands: %s: unknown flag --%s, the global flags can only be set in defaults", section, name))
+			}
+		}
+	}
+	return errs
+}
+
+// visitOwnFlags calls fn for the flags the command defines, not those it inherits
+// from its parents.
+func visitOwnFlags(cmd *cobra.Command, fn func(*pflag.Flag)) {
+	seen := map[*pflag.Flag]bool{}
+	visit := func(flag *pflag.Flag) {
+		if !seen[flag] {
+			seen[flag] = true
+			fn(flag)
+		}
+	}
+	cmd.PersistentFlags().VisitAll(visit)
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if cmd.InheritedFlags().Lookup(flag.Name) == nil {
+			visit(flag)
+		}
+	})
+}
+
+// setFlagDefault sets the value and the default of the flag, like changeSharedFlagDefaults.
+func setFlagDefault(flag *pflag.Flag, value interface{}) error {
+	var values []string
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			values = append(values, expandHome(formatValue(item)))
+		}
+	case nil:
+		return fmt.Errorf("a value is required")
+	case map[string]interface{}:
+		return fmt.Errorf("the value must be a string, a number, a boolean or a list")
+	default:
+		values = []string{expandHome(formatValue(v))}
+	}
+
+	if slice, ok := flag.Value.(pflag.SliceValue); ok {
+		// replace rather than set the value, so that the values passed on the command line
+		// replace the default instead of being appended to it
+		if err := slice.Replace(values); err != nil {
+			return err
+		}
+	} else if err := flag.Value.Set(strings.Join(values, ",")); err != nil {
+		return err
+	}
+	flag.DefValue = flag.Value.String()
+	flag.Changed = false
+	return nil
+}
+
+// formatValue returns the value of the file as the value of a flag. The numbers of the file
+// are read as float64, and large ones are formatted without an exponent.
+func formatValue(value interface{}) string {
+	if f, ok := value.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// expandHome replaces a leading ~/ with the home directory, since the values of the
+// file are not expanded by a shell.
+func expandHome(value string) string {
+	if rest, ok := strings.CutPrefix(value, "~/"); ok {
+		return filepath.Join(homedir.HomeDir(), rest)
+	}
+	return value
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
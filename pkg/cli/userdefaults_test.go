@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+)
+
+func TestApplyUserDefaults(t *testing.T) {
+	var cacheDir, registryConfig string
+	var maxPerRegistry, infoMaxPerRegistry int
+	var insecure bool
+	var skip []string
+	newCmds := func() *cobra.Command {
+		root := &cobra.Command{Use: "oc"}
+		root.PersistentFlags().StringVar(&cacheDir, "cache-dir", "/default/cache", "")
+		image := &cobra.Command{Use: "image"}
+		mirror := &cobra.Command{Use: "mirror", Run: func(*cobra.Command, []string) {}}
+		mirror.Flags().StringVar(&registryConfig, "registry-config", "", "")
+		mirror.Flags().IntVar(&maxPerRegistry, "max-per-registry", 6, "")
+		mirror.Flags().BoolVar(&insecure, "insecure", false, "")
+		mirror.Flags().StringSliceVar(&skip, "skip", nil, "")
+		image.AddCommand(mirror)
+		info := &cobra.Command{Use: "info", Run: func(*cobra.Command, []string) {}}
+		info.Flags().IntVar(&infoMaxPerRegistry, "max-per-registry", 6, "")
+		image.AddCommand(info)
+		root.AddCommand(image)
+		return root
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	config := `defaults:
+  cache-dir: /tmp/oc-cache
+  registry-config: ~/auth.json
+  max-per-registry: 4
+  unknown: true
+commands:
+  image:
+    max-per-registry: 8
+  image mirror:
+    max-per-registry: 10
+    insecure: true
+    skip: [a, b]
+    cache-dir: /elsewhere
+  image prune:
+    all: true
+`
+	if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	defaults, err := loadUserDefaults(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := newCmds()
+	errs := applyUserDefaults(root, defaults)
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	want := []string{
+		"defaults: no command has a --unknown flag",
+		`commands: unknown command "image prune"`,
+		"commands: image mirror: unknown flag --cache-dir, the global flags can only be set in defaults",
+	}
+	if !reflect.DeepEqual(messages, want) {
+		t.Errorf("unexpected errors:\n%s\nwant:\n%s", strings.Join(messages, "\n"), strings.Join(want, "\n"))
+	}
+
+	root.SetArgs([]string{"image", "mirror"})
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if cacheDir != "/tmp/oc-cache" || maxPerRegistry != 10 || !insecure || !reflect.DeepEqual(skip, []string{"a", "b"}) {
+		t.Errorf("unexpected values: cache-dir=%s max-per-registry=%d insecure=%t skip=%v", cacheDir, maxPerRegistry, insecure, skip)
+	}
+	if !strings.HasSuffix(registryConfig, "/auth.json") || strings.HasPrefix(registryConfig, "~") {
+		t.Errorf("expected ~ to be expanded in %s", registryConfig)
+	}
+
+	root = newCmds()
+	applyUserDefaults(root, defaults)
+	root.SetArgs([]string{"image", "info"})
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if infoMaxPerRegistry != 8 {
+		t.Errorf("expected the section of the parent command to apply, got max-per-registry=%d", infoMaxPerRegistry)
+	}
+
+	root = newCmds()
+	applyUserDefaults(root, defaults)
+	root.SetArgs([]string{"image", "mirror", "--skip=c", "--max-per-registry=2"})
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if maxPerRegistry != 2 || !reflect.DeepEqual(skip, []string{"c"}) {
+		t.Errorf("expected the command line to take precedence: max-per-registry=%d skip=%v", maxPerRegistry, skip)
+	}
+}
+
+func TestSetFlagDefaultNumbers(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	size := flags.Int64("max-size", 0, "")
+	ratio := flags.Float64("ratio", 0, "")
+	ports := flags.IntSlice("ports", nil, "")
+
+	// the numbers of the file are read as float64
+	var values map[string]interface{}
+	if err := yaml.Unmarshal([]byte("max-size: 1000000\nratio: 0.25\nports: [10000000, 8080]\n"), &values); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range sortedKeys(values) {
+		if err := setFlagDefault(flags.Lookup(name), values[name]); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	if *size != 1000000 || *ratio != 0.25 || !reflect.DeepEqual(*ports, []int{10000000, 8080}) {
+		t.Errorf("unexpected values: max-size=%d ratio=%v ports=%v", *size, *ratio, *ports)
+	}
+	if def := flags.Lookup("max-size").DefValue; def != "1000000" {
+		t.Errorf("unexpected default %s", def)
+	}
+}
@@ -0,0 +1,135 @@
+package whoami
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	userv1 "github.com/openshift/api/user/v1"
+)
+
+const sha256Prefix = "sha256~"
+
+// sessionInfo is printed with --output=json.
+type sessionInfo struct {
+	User    string     `json:"user"`
+	Groups  []string   `json:"groups,omitempty"`
+	Server  string     `json:"server"`
+	Context string     `json:"context,omitempty"`
+	Token   *tokenInfo `json:"token,omitempty"`
+}
+
+// tokenInfo describes the bearer token of the session, as far as it could be
+// determined from the OAuth access token object or the claims of a JWT.
+type tokenInfo struct {
+	// Expiry is unset if the token does not expire or its expiry is unknown.
+	Expiry           *metav1.Time `json:"expiry,omitempty"`
+	ExpiresInSeconds *int64       `json:"expiresInSeconds,omitempty"`
+	Scopes           []string     `json:"scopes,omitempty"`
+	IdentityProvider string       `json:"identityProvider,omitempty"`
+	// Known is false if the token could not be introspected.
+	Known bool `json:"known"`
+}
+
+func (t *tokenInfo) setExpiry(expiry, now time.Time) {
+	t.Expiry = &metav1.Time{Time: expiry}
+	seconds := int64(expiry.Sub(now).Round(time.Second) / time.Second)
+	if seconds < 0 {
+		seconds = 0
+	}
+	t.ExpiresInSeconds = &seconds
+}
+
+// getTokenInfo introspects the bearer token of the session. OpenShift OAuth
+// access tokens are looked up on the server, any other token is decoded as a
+// JWT without verifying its signature. The user, if known, is used to find the
+// identity provider of an OAuth access token.
+func (o *WhoAmIOptions) getTokenInfo(token string, user *userv1.User, now time.Time) *tokenInfo {
+	info := &tokenInfo{}
+	if strings.HasPrefix(token, sha256Prefix) {
+		accessToken, err := o.OAuthClient.UserOAuthAccessTokens().Get(context.TODO(), tokenToObjectName(token), metav1.GetOptions{})
+		if err != nil {
+			klog.V(2).Infof("unable to get the OAuth access token: %v", err)
+			return info
+		}
+		info.Known = true
+		info.Scopes = accessToken.Scopes
+		if accessToken.ExpiresIn > 0 {
+			info.setExpiry(accessToken.CreationTimestamp.Add(time.Duration(accessToken.ExpiresIn)*time.Second), now)
+		}
+		// the token does not record the identity provider, which is only known
+		// if the user has a single identity
+		if user != nil && len(user.Identities) == 1 {
+			info.IdentityProvider, _, _ = strings.Cut(user.Identities[0], ":")
+		}
+		return info
+	}
+
+	claims, err := decodeJWTClaims(token)
+	if err != nil {
+		klog.V(2).Infof("unable to decode the token: %v", err)
+		return info
+	}
+	info.Known = true
+	info.IdentityProvider = claims.Issuer
+	info.Scopes = claims.scopes()
+	if claims.Expiry > 0 {
+		info.setExpiry(time.Unix(claims.Expiry, 0), now)
+	}
+	return info
+}
+
+type jwtClaims struct {
+	Issuer string          `json:"iss"`
+	Expiry int64           `json:"exp"`
+	Scope  json.RawMessage `json:"scope"`
+	Scp    []string        `json:"scp"`
+}
+
+// scopes returns the scopes of the token, which are either a space separated
+// string or a list.
+func (c *jwtClaims) scopes() []string {
+	if len(c.Scp) > 0 {
+		return c.Scp
+	}
+	var scope string
+	if err := json.Unmarshal(c.Scope, &scope); err == nil {
+		return strings.Fields(scope)
+	}
+	var scopes []string
+	if err := json.Unmarshal(c.Scope, &scopes); err == nil {
+		return scopes
+	}
+	return nil
+}
+
+func decodeJWTClaims(token string) (*jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("the token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT payload: %v", err)
+	}
+	claims := &jwtClaims{}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, fmt.Errorf("invalid JWT claims: %v", err)
+	}
+	return claims, nil
+}
+
+// tokenToObjectName returns the oauthaccesstokens object name for the given raw token,
+// i.e. the sha256 hash prefixed with "sha256~".
+func tokenToObjectName(token string) string {
+	name := strings.TrimPrefix(token, sha256Prefix)
+	h := sha256.Sum256([]byte(name))
+	return sha256Prefix + base64.RawURLEncoding.EncodeToString(h[0:])
+}
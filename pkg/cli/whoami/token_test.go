@@ -0,0 +1,163 @@
+package whoami
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	authfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	core "k8s.io/client-go/testing"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	userv1 "github.com/openshift/api/user/v1"
+	oauthfake "github.com/openshift/client-go/oauth/clientset/versioned/fake"
+	userv1fake "github.com/openshift/client-go/user/clientset/versioned/fake"
+	"github.com/openshift/oc/pkg/helpers/keyring"
+)
+
+func jwt(claims string) string {
+	return "e30." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".signature"
+}
+
+func TestGetTokenInfo(t *testing.T) {
+	now := time.Date(2023, 10, 14, 12, 0, 0, 0, time.UTC)
+	created := metav1.NewTime(now.Add(-time.Hour))
+	fakeOAuthClientSet := oauthfake.NewSimpleClientset(&oauthv1.UserOAuthAccessToken{
+		ObjectMeta: metav1.ObjectMeta{Name: tokenToObjectName("sha256~token"), CreationTimestamp: created},
+		ExpiresIn:  int64((2 * time.Hour) / time.Second),
+		Scopes:     []string{"user:full"},
+	})
+	o := &WhoAmIOptions{OAuthClient: fakeOAuthClientSet.OauthV1()}
+
+	expiry := metav1.NewTime(now.Add(time.Hour))
+	hour := int64(3600)
+	zero := int64(0)
+	tests := []struct {
+		name  string
+		token string
+		user  *userv1.User
+		want  *tokenInfo
+	}{
+		{
+			name:  "oauth access token",
+			token: "sha256~token",
+			user:  &userv1.User{Identities: []string{"htpasswd:jane"}},
+			want:  &tokenInfo{Known: true, Expiry: &expiry, ExpiresInSeconds: &hour, Scopes: []string{"user:full"}, IdentityProvider: "htpasswd"},
+		},
+		{
+			name:  "unknown oauth access token",
+			token: "sha256~other",
+			want:  &tokenInfo{},
+		},
+		{
+			name:  "jwt with scope string",
+			token: jwt(`{"iss":"https://issuer.example.com","exp":1697288400,"scope":"openid email"}`),
+			want:  &tokenInfo{Known: true, Expiry: &expiry, ExpiresInSeconds: &hour, Scopes: []string{"openid", "email"}, IdentityProvider: "https://issuer.example.com"},
+		},
+		{
+			name:  "expired jwt with scp list",
+			token: jwt(`{"iss":"https://issuer.example.com","exp":1697277600,"scp":["openid"]}`),
+			want: &tokenInfo{Known: true, Expiry: &metav1.Time{Time: time.Unix(1697277600, 0)}, ExpiresInSeconds: &zero, Scopes: []string{"openid"},
+				IdentityProvider: "https://issuer.example.com"},
+		},
+		{
+			name:  "opaque token",
+			token: "opaque",
+			want:  &tokenInfo{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := o.getTokenInfo(tt.token, tt.user, now)
+			if got.Expiry != nil && tt.want.Expiry != nil && got.Expiry.Equal(tt.want.Expiry) {
+				got.Expiry = tt.want.Expiry
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getTokenInfo() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintSessionInfo(t *testing.T) {
+	now := time.Date(2023, 10, 14, 12, 0, 0, 0, time.UTC)
+	fakeAuthClientSet := &authfake.Clientset{}
+	fakeAuthClientSet.AddReactor("create", "selfsubjectreviews",
+		func(action core.Action) (handled bool, ret runtime.Object, err error) {
+			return true, &v1.SelfSubjectReview{
+				Status: v1.SelfSubjectReviewStatus{
+					UserInfo: v1.UserInfo{Username: "jane.doe", Groups: []string{"system:authenticated"}},
+				},
+			}, nil
+		})
+
+	var b bytes.Buffer
+	o := &WhoAmIOptions{
+		AuthV1Client:  fakeAuthClientSet.AuthenticationV1(),
+		UserInterface: (&userv1fake.Clientset{}).UserV1(),
+		OAuthClient:   (&oauthfake.Clientset{}).OauthV1(),
+		ClientConfig:  &rest.Config{Host: "https://api.example.com:6443", BearerToken: jwt(`{"iss":"https://issuer.example.com","exp":1697288400}`)},
+		IOStreams:     genericiooptions.IOStreams{Out: &b, ErrOut: io.Discard},
+	}
+	if err := o.printSessionInfo(now); err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "user": "jane.doe",
+  "groups": [
+    "system:authenticated"
+  ],
+  "server": "https://api.example.com:6443",
+  "token": {
+    "expiry": "2023-10-14T13:00:00Z",
+    "expiresInSeconds": 3600,
+    "identityProvider": "https://issuer.example.com",
+    "known": true
+  }
+}
+`
+	if b.String() != want {
+		t.Errorf("printSessionInfo() = %s, want %s", b.String(), want)
+	}
+}
+
+func TestPrintKeyringTokenExpiry(t *testing.T) {
+	// the helper returns the token stored in the keychain
+	dir := t.TempDir()
+	token := jwt(`{"iss":"https://issuer.example.com","exp":1697288400}`)
+	helper := fmt.Sprintf("#!/bin/sh\necho '{\"ServerURL\":\"key\",\"Username\":\"alice\",\"Secret\":\"%s\"}'\n", token)
+	if err := os.WriteFile(filepath.Join(dir, "docker-credential-test"), []byte(helper), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	out := &bytes.Buffer{}
+	o := &WhoAmIOptions{
+		IOStreams:       genericiooptions.IOStreams{Out: out, ErrOut: io.Discard},
+		ClientConfig:    &rest.Config{ExecProvider: keyring.ExecConfig(keyring.Key("https://api.example.com:6443", "alice"))},
+		ShowTokenExpiry: true,
+	}
+	if err := o.useKeyringToken(keyring.NewStore("test")); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.printTokenExpiry(time.Date(2023, 10, 14, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "2023-10-14T13:00:00Z\n" {
+		t.Errorf("unexpected expiry %q", out.String())
+	}
+}
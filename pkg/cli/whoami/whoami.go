@@ -2,7 +2,10 @@ package whoami
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -19,7 +22,9 @@ import (
 	"k8s.io/kubectl/pkg/util/templates"
 
 	userv1 "github.com/openshift/api/user/v1"
+	oauthv1client "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
 	userv1typedclient "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
+	"github.com/openshift/oc/pkg/helpers/keyring"
 )
 
 const (
@@ -32,16 +37,28 @@ var whoamiLong = templates.LongDesc(`
 
 	The default options for this command will return the currently authenticated user name
 	or an empty string.  Other flags support returning the currently used token or the
-	user context.`)
+	user context.
+
+	The --show-token-expiry flag prints when the token of the session expires, so that
+	scripts can log in again before it does. With --output=json, the user, its groups,
+	the server and context, and the expiry, scopes and identity provider of the token are
+	printed as a single object.`)
 
 var whoamiExample = templates.Examples(`
 	# Display the currently authenticated user
 	oc whoami
+
+	# Display when the token of the current session expires
+	oc whoami --show-token-expiry
+
+	# Display the user, the server and the expiry, scopes and identity provider of the token as JSON
+	oc whoami -o json
 `)
 
 type WhoAmIOptions struct {
 	UserInterface userv1typedclient.UserV1Interface
 	AuthV1Client  authenticationv1client.AuthenticationV1Interface
+	OAuthClient   oauthv1client.OauthV1Interface
 
 	ClientConfig *rest.Config
 	KubeClient   kubernetes.Interface
@@ -52,6 +69,9 @@ type WhoAmIOptions struct {
 	ShowServer     bool
 	ShowConsoleUrl bool
 
+	ShowTokenExpiry bool
+	Output          string
+
 	genericiooptions.IOStreams
 }
 
@@ -80,11 +100,21 @@ func NewCmdWhoAmI(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra
 	cmd.Flags().BoolVarP(&o.ShowContext, "show-context", "c", o.ShowContext, "Print the current user context name")
 	cmd.Flags().BoolVar(&o.ShowServer, "show-server", o.ShowServer, "If true, print the current server's REST API URL")
 	cmd.Flags().BoolVar(&o.ShowConsoleUrl, "show-console", o.ShowConsoleUrl, "If true, print the current server's web console URL")
+	cmd.Flags().BoolVar(&o.ShowTokenExpiry, "show-token-expiry", o.ShowTokenExpiry, "If true, print when the token the current session is using expires, or 'never'. This will return an error if you are using a different form of authentication.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. Only 'json' is supported, which prints the user, server and context along with the expiry, scopes and identity provider of the token.")
 
 	return cmd
 }
 
 func (o WhoAmIOptions) WhoAmI() (*userv1.User, error) {
+	me, err := o.getUser()
+	if err == nil {
+		fmt.Fprintf(o.Out, "%s\n", me.Name)
+	}
+	return me, err
+}
+
+func (o WhoAmIOptions) getUser() (*userv1.User, error) {
 	res, err := o.AuthV1Client.SelfSubjectReviews().Create(context.TODO(), &v1.SelfSubjectReview{}, metav1.CreateOptions{})
 	if err == nil {
 		me := &userv1.User{
@@ -93,18 +123,12 @@ func (o WhoAmIOptions) WhoAmI() (*userv1.User, error) {
 			},
 			Groups: res.Status.UserInfo.Groups,
 		}
-		fmt.Fprintf(o.Out, "%s\n", me.Name)
 		return me, nil
 	} else {
 		klog.V(2).Infof("selfsubjectreview request error %v, falling back to user object", err)
 	}
 
-	me, err := o.UserInterface.Users().Get(context.TODO(), "~", metav1.GetOptions{})
-	if err == nil {
-		fmt.Fprintf(o.Out, "%s\n", me.Name)
-	}
-
-	return me, err
+	return o.UserInterface.Users().Get(context.TODO(), "~", metav1.GetOptions{})
 }
 
 func (o *WhoAmIOptions) Complete(f kcmdutil.Factory) error {
@@ -114,6 +138,9 @@ func (o *WhoAmIOptions) Complete(f kcmdutil.Factory) error {
 	if err != nil {
 		return err
 	}
+	if err := o.useKeyringToken(keyring.NewStore(keyring.DefaultHelper())); err != nil {
+		return err
+	}
 
 	kubeClient, err := kubernetes.NewForConfig(o.ClientConfig)
 	if err != nil {
@@ -125,6 +152,26 @@ func (o *WhoAmIOptions) Complete(f kcmdutil.Factory) error {
 	return err
 }
 
+// useKeyringToken sets the token the exec plugin of the session retrieves from the keychain
+// as the token of the session, so that it can be shown and its expiry checked.
+func (o *WhoAmIOptions) useKeyringToken(store *keyring.Store) error {
+	key, ok := keyring.KeyFromExecConfig(o.ClientConfig.ExecProvider)
+	if !ok {
+		return nil
+	}
+	token, err := store.Get(key)
+	if err != nil {
+		if o.ShowToken || o.ShowTokenExpiry {
+			return err
+		}
+		// the exec plugin reports the error when the token is used
+		return nil
+	}
+	o.ClientConfig.BearerToken = token
+	o.ClientConfig.ExecProvider = nil
+	return nil
+}
+
 func (o *WhoAmIOptions) Validate() error {
 	if o.ShowToken && len(o.ClientConfig.BearerToken) == 0 {
 		return fmt.Errorf("no token is currently in use for this session")
@@ -132,6 +179,12 @@ func (o *WhoAmIOptions) Validate() error {
 	if o.ShowContext && len(o.RawConfig.CurrentContext) == 0 {
 		return fmt.Errorf("no context has been set")
 	}
+	if o.ShowTokenExpiry && len(o.ClientConfig.BearerToken) == 0 {
+		return fmt.Errorf("no token is currently in use for this session")
+	}
+	if len(o.Output) > 0 && o.Output != "json" {
+		return fmt.Errorf("--output must be 'json'")
+	}
 
 	return nil
 }
@@ -155,6 +208,8 @@ func (o *WhoAmIOptions) getWebConsoleUrl() (string, error) {
 
 func (o *WhoAmIOptions) Run() error {
 	switch {
+	case len(o.Output) > 0, o.ShowTokenExpiry:
+		// handled below, once the clients are set up
 	case o.ShowToken:
 		fmt.Fprintf(o.Out, "%s\n", o.ClientConfig.BearerToken)
 		return nil
@@ -184,6 +239,67 @@ func (o *WhoAmIOptions) Run() error {
 		return err
 	}
 
+	o.OAuthClient, err = oauthv1client.NewForConfig(o.ClientConfig)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(o.Output) > 0:
+		return o.printSessionInfo(time.Now())
+	case o.ShowTokenExpiry:
+		return o.printTokenExpiry(time.Now())
+	}
+
 	_, err = o.WhoAmI()
 	return err
 }
+
+// identities returns the user object with the identities of the user, which
+// are needed to tell the identity provider of an OAuth access token.
+func (o *WhoAmIOptions) identities(user *userv1.User) *userv1.User {
+	if !strings.HasPrefix(o.ClientConfig.BearerToken, sha256Prefix) || (user != nil && len(user.Identities) > 0) {
+		return user
+	}
+	me, err := o.UserInterface.Users().Get(context.TODO(), "~", metav1.GetOptions{})
+	if err != nil {
+		klog.V(2).Infof("unable to get the identities of the user: %v", err)
+		return user
+	}
+	return me
+}
+
+func (o *WhoAmIOptions) printTokenExpiry(now time.Time) error {
+	info := o.getTokenInfo(o.ClientConfig.BearerToken, nil, now)
+	switch {
+	case !info.Known:
+		return fmt.Errorf("unable to determine the expiry of the token, it is neither an OAuth access token nor a JWT")
+	case info.Expiry == nil:
+		fmt.Fprintf(o.Out, "never\n")
+	default:
+		fmt.Fprintf(o.Out, "%s\n", info.Expiry.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+func (o *WhoAmIOptions) printSessionInfo(now time.Time) error {
+	user, err := o.getUser()
+	if err != nil {
+		return err
+	}
+	info := sessionInfo{
+		User:    user.Name,
+		Groups:  user.Groups,
+		Server:  o.ClientConfig.Host,
+		Context: o.RawConfig.CurrentContext,
+	}
+	if len(o.ClientConfig.BearerToken) > 0 {
+		info.Token = o.getTokenInfo(o.ClientConfig.BearerToken, o.identities(user), now)
+	}
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "%s\n", data)
+	return nil
+}
@@ -0,0 +1,243 @@
+// Package asciicast records remote command sessions in the asciicast v2 format of asciinema,
+// so that they can be replayed with 'asciinema play FILE'.
+package asciicast
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/pflag"
+
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/kubectl/pkg/cmd/exec"
+	"k8s.io/kubectl/pkg/util/term"
+)
+
+const (
+	defaultWidth  = 80
+	defaultHeight = 24
+)
+
+// Header is the first line of an asciicast v2 recording.
+type Header struct {
+	Version   int               `json:"version"`
+	Width     uint16            `json:"width"`
+	Height    uint16            `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Command   string            `json:"command,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Recorder writes the output, input and resize events of a session, timed from the start of
+// the recording. It is safe to use concurrently.
+type Recorder struct {
+	lock  sync.Mutex
+	w     io.Writer
+	start time.Time
+	now   func() time.Time
+	// pending holds the bytes of an incomplete UTF-8 character by event type, since events
+	// are JSON strings
+	pending map[string][]byte
+	err     error
+}
+
+// NewRecorder writes the header and returns a recorder of the session.
+func NewRecorder(w io.Writer, header Header, now func() time.Time) (*Recorder, error) {
+	start := now()
+	header.Version = 2
+	header.Timestamp = start.Unix()
+	data, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return nil, err
+	}
+	return &Recorder{w: w, start: start, now: now, pending: map[string][]byte{}}, nil
+}
+
+// Output records data written by the remote command.
+func (r *Recorder) Output(data []byte) {
+	r.record("o", data)
+}
+
+// Input records data sent to the remote command.
+func (r *Recorder) Input(data []byte) {
+	r.record("i", data)
+}
+
+// Resize records a change of the size of the terminal.
+func (r *Recorder) Resize(width, height uint16) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.write("r", fmt.Sprintf("%dx%d", width, height))
+}
+
+// Err returns the first error writing the recording.
+func (r *Recorder) Err() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.err
+}
+
+// Flush records the bytes of incomplete characters left at the end of the session.
+func (r *Recorder) Flush() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, eventType := range []string{"o", "i"} {
+		if pending := r.pending[eventType]; len(pending) > 0 {
+			r.write(eventType, string(pending))
+			delete(r.pending, eventType)
+		}
+	}
+	return r.err
+}
+
+func (r *Recorder) record(eventType string, data []byte) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	data = append(r.pending[eventType], data...)
+	// keep the start of a character split across writes for the next write
+	complete := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				complete = i
+			}
+			break
+		}
+	}
+	r.pending[eventType] = append([]byte(nil), data[complete:]...)
+	if complete > 0 {
+		r.write(eventType, string(data[:complete]))
+	}
+}
+
+func (r *Recorder) write(eventType, data string) {
+	if r.err != nil {
+		return
+	}
+	elapsed := r.now().Sub(r.start).Seconds()
+	event, err := json.Marshal([]interface{}{json.Number(fmt.Sprintf("%.6f", elapsed)), eventType, data})
+	if err != nil {
+		r.err = err
+		return
+	}
+	_, r.err = r.w.Write(append(event, '\n'))
+}
+
+// recorderFunc records the bytes written to it.
+type recorderFunc func([]byte)
+
+func (fn recorderFunc) Write(data []byte) (int, error) {
+	fn(data)
+	return len(data), nil
+}
+
+// Executor records the sessions of the remote commands it executes.
+type Executor struct {
+	Delegate exec.RemoteExecutor
+	Recorder *Recorder
+	// Input records the input sent to the remote command
+	Input bool
+}
+
+func (e *Executor) Execute(url *url.URL, config *restclient.Config, stdin io.Reader, stdout, stderr io.Writer, tty bool, terminalSizeQueue remotecommand.TerminalSizeQueue) error {
+	if stdin != nil && e.Input {
+		stdin = io.TeeReader(stdin, recorderFunc(e.Recorder.Input))
+	}
+	if stdout != nil {
+		stdout = io.MultiWriter(stdout, recorderFunc(e.Recorder.Output))
+	}
+	if stderr != nil {
+		stderr = io.MultiWriter(stderr, recorderFunc(e.Recorder.Output))
+	}
+	if terminalSizeQueue != nil {
+		terminalSizeQueue = &sizeQueue{delegate: terminalSizeQueue, recorder: e.Recorder}
+	}
+	err := e.Delegate.Execute(url, config, stdin, stdout, stderr, tty, terminalSizeQueue)
+	if flushErr := e.Recorder.Flush(); err == nil && flushErr != nil {
+		return fmt.Errorf("unable to record the session: %v", flushErr)
+	}
+	return err
+}
+
+// sizeQueue records the changes of the size of the terminal after the first one, which is the
+// size in the header.
+type sizeQueue struct {
+	delegate remotecommand.TerminalSizeQueue
+	recorder *Recorder
+	last     *remotecommand.TerminalSize
+}
+
+func (q *sizeQueue) Next() *remotecommand.TerminalSize {
+	size := q.delegate.Next()
+	if size != nil && q.last != nil && *size != *q.last {
+		q.recorder.Resize(size.Width, size.Height)
+	}
+	if size != nil {
+		q.last = size
+	}
+	return size
+}
+
+// Options are the flags recording the session of a remote command.
+type Options struct {
+	File  string
+	Input bool
+}
+
+func NewOptions() *Options {
+	return &Options{Input: true}
+}
+
+// AddFlags registers the recording flags.
+func (o *Options) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.File, "record", o.File, "Record the session to this file in the asciicast v2 format, which 'asciinema play' replays.")
+	flags.BoolVar(&o.Input, "record-input", o.Input, "Record the input sent to the container along with its output. Set to false to strip the input, such as typed passwords, from the recording.")
+}
+
+// Wrap records the session of the remote command of the options to the file, if any, and
+// returns a function closing the recording once the command exited.
+func (o *Options) Wrap(execOptions *exec.ExecOptions, title string) (func() error, error) {
+	if len(o.File) == 0 {
+		return func() error { return nil }, nil
+	}
+	f, err := os.OpenFile(o.File, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	header := Header{
+		Width:   defaultWidth,
+		Height:  defaultHeight,
+		Command: strings.Join(execOptions.Command, " "),
+		Title:   title,
+	}
+	if execOptions.TTY {
+		if size := (term.TTY{Out: execOptions.Out}).GetSize(); size != nil {
+			header.Width, header.Height = size.Width, size.Height
+		}
+		header.Env = map[string]string{"TERM": os.Getenv("TERM")}
+	}
+	recorder, err := NewRecorder(f, header, time.Now)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	execOptions.Executor = &Executor{
+		Delegate: execOptions.Executor,
+		Recorder: recorder,
+		Input:    o.Input,
+	}
+	return f.Close, nil
+}
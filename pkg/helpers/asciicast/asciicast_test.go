@@ -0,0 +1,104 @@
+package asciicast
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+type fakeExecutor struct{}
+
+func (fakeExecutor) Execute(url *url.URL, config *restclient.Config, stdin io.Reader, stdout, stderr io.Writer, tty bool, terminalSizeQueue remotecommand.TerminalSizeQueue) error {
+	terminalSizeQueue.Next()
+	terminalSizeQueue.Next()
+	input, err := io.ReadAll(stdin)
+	if err != nil {
+		return err
+	}
+	stdout.Write([]byte("$ "))
+	stdout.Write(input)
+	// é split across writes
+	stdout.Write([]byte{'c', 'a', 'f', 0xc3})
+	stdout.Write([]byte{0xa9, '\n'})
+	stderr.Write([]byte("warning\n"))
+	return nil
+}
+
+type fakeSizeQueue struct {
+	sizes []remotecommand.TerminalSize
+}
+
+func (q *fakeSizeQueue) Next() *remotecommand.TerminalSize {
+	if len(q.sizes) == 0 {
+		return nil
+	}
+	size := q.sizes[0]
+	q.sizes = q.sizes[1:]
+	return &size
+}
+
+func TestExecutor(t *testing.T) {
+	header := `{"version":2,"width":120,"height":40,"timestamp":1700000000,"command":"/bin/sh","title":"oc rsh pod/foo"}`
+	tests := []struct {
+		recordInput bool
+		expected    []string
+	}{
+		{
+			recordInput: true,
+			expected: []string{
+				header,
+				`[0.500000,"r","100x30"]`,
+				`[1.000000,"i","ls\n"]`,
+				`[1.500000,"o","$ "]`,
+				`[2.000000,"o","ls\n"]`,
+				`[2.500000,"o","caf"]`,
+				`[3.000000,"o","é\n"]`,
+				`[3.500000,"o","warning\n"]`,
+			},
+		},
+		{
+			recordInput: false,
+			expected: []string{
+				header,
+				`[0.500000,"r","100x30"]`,
+				`[1.000000,"o","$ "]`,
+				`[1.500000,"o","ls\n"]`,
+				`[2.000000,"o","caf"]`,
+				`[2.500000,"o","é\n"]`,
+				`[3.000000,"o","warning\n"]`,
+			},
+		},
+	}
+	for _, test := range tests {
+		start := time.Unix(1700000000, 0)
+		elapsed := time.Duration(0)
+		now := func() time.Time {
+			elapsed += 500 * time.Millisecond
+			return start.Add(elapsed - 500*time.Millisecond)
+		}
+
+		recording := &bytes.Buffer{}
+		recorder, err := NewRecorder(recording, Header{Width: 120, Height: 40, Command: "/bin/sh", Title: "oc rsh pod/foo"}, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		executor := &Executor{Delegate: fakeExecutor{}, Recorder: recorder, Input: test.recordInput}
+		sizes := &fakeSizeQueue{sizes: []remotecommand.TerminalSize{{Width: 120, Height: 40}, {Width: 100, Height: 30}}}
+		stdout := &bytes.Buffer{}
+		if err := executor.Execute(nil, nil, strings.NewReader("ls\n"), stdout, &bytes.Buffer{}, true, sizes); err != nil {
+			t.Fatal(err)
+		}
+		if stdout.String() != "$ ls\ncafé\n" {
+			t.Errorf("unexpected output %q", stdout.String())
+		}
+		if got, expected := strings.TrimSuffix(recording.String(), "\n"), strings.Join(test.expected, "\n"); got != expected {
+			t.Errorf("record input %t: expected\n%s\ngot\n%s", test.recordInput, expected, got)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package completion
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/completion"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+)
+
+// ImageStreamTagCompletionFunc completes the arguments of a command with the NAME:TAG or
+// NAMESPACE/NAME:TAG of image stream tags.
+func ImageStreamTagCompletionFunc(f kcmdutil.Factory) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return CompGetImageStreamTags(f, toComplete, false)
+	}
+}
+
+// CompGetImageStreamTags returns the image stream tags which begin with toComplete. The
+// image streams are completed first, followed by a colon and without a space, then their
+// tags. If requireNamespace is true, the namespace is completed before the image streams.
+func CompGetImageStreamTags(f kcmdutil.Factory, toComplete string, requireNamespace bool) ([]string, cobra.ShellCompDirective) {
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if requireNamespace && !strings.Contains(toComplete, "/") {
+		var comps []string
+		for _, ns := range completion.CompGetResource(f, "namespace", toComplete) {
+			comps = append(comps, ns+"/")
+		}
+		return comps, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	client, err := imagev1client.NewForConfig(config)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return imageStreamTagCompletions(client, namespace, toComplete)
+}
+
+func imageStreamTagCompletions(client imagev1client.ImageStreamsGetter, namespace, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var prefix string
+	nameAndTag := toComplete
+	if i := strings.Index(toComplete, "/"); i != -1 {
+		prefix, namespace, nameAndTag = toComplete[:i+1], toComplete[:i], toComplete[i+1:]
+	}
+
+	name, tag, ok := strings.Cut(nameAndTag, ":")
+	if !ok {
+		streams, err := client.ImageStreams(namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var comps []string
+		for _, stream := range streams.Items {
+			if strings.HasPrefix(stream.Name, name) {
+				comps = append(comps, prefix+stream.Name+":")
+			}
+		}
+		sort.Strings(comps)
+		return comps, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+
+	stream, err := client.ImageStreams(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	tags := map[string]bool{}
+	for _, t := range stream.Spec.Tags {
+		tags[t.Name] = true
+	}
+	for _, t := range stream.Status.Tags {
+		tags[t.Tag] = true
+	}
+	var comps []string
+	for t := range tags {
+		if strings.HasPrefix(t, tag) {
+			comps = append(comps, prefix+name+":"+t)
+		}
+	}
+	sort.Strings(comps)
+	return comps, cobra.ShellCompDirectiveNoFileComp
+}
+
+// ClusterVersion returns the cluster version of the cluster of the factory for the
+// completion of the release versions, channels and images, or nil if it cannot be read.
+func ClusterVersion(f kcmdutil.Factory) *configv1.ClusterVersion {
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return nil
+	}
+	client, err := configv1client.NewForConfig(config)
+	if err != nil {
+		return nil
+	}
+	cv, err := client.ConfigV1().ClusterVersions().Get(context.TODO(), "version", metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+	return cv
+}
+
+// UpdateCompletions returns the versions, or the release images if image is true, of the
+// updates the update service recommends for the cluster which begin with toComplete. The
+// conditional updates are described with the reason they may not be recommended.
+func UpdateCompletions(cv *configv1.ClusterVersion, toComplete string, image bool) []string {
+	if cv == nil {
+		return nil
+	}
+	value := func(release configv1.Release) string {
+		if image {
+			return release.Image
+		}
+		return release.Version
+	}
+	var comps []string
+	seen := map[string]bool{}
+	for _, release := range cv.Status.AvailableUpdates {
+		if v := value(release); len(v) > 0 && !seen[v] && strings.HasPrefix(v, toComplete) {
+			seen[v] = true
+			comps = append(comps, v)
+		}
+	}
+	for _, update := range cv.Status.ConditionalUpdates {
+		v := value(update.Release)
+		if len(v) == 0 || seen[v] || !strings.HasPrefix(v, toComplete) {
+			continue
+		}
+		seen[v] = true
+		description := "conditional update"
+		for _, risk := range update.Risks {
+			description += ", " + risk.Name
+		}
+		comps = append(comps, v+"\t"+description)
+	}
+	return comps
+}
+
+// ChannelCompletions returns the update channels available to the current version of the
+// cluster which begin with toComplete.
+func ChannelCompletions(cv *configv1.ClusterVersion, toComplete string) []string {
+	if cv == nil {
+		return nil
+	}
+	var comps []string
+	for _, channel := range cv.Status.Desired.Channels {
+		if strings.HasPrefix(channel, toComplete) {
+			comps = append(comps, channel)
+		}
+	}
+	return comps
+}
+
+// NodeCompletionFunc completes a flag with the names of the nodes.
+func NodeCompletionFunc(f kcmdutil.Factory) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completion.CompGetResource(f, "node", toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,90 @@
+package completion
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	imagefake "github.com/openshift/client-go/image/clientset/versioned/fake"
+)
+
+func TestImageStreamTagCompletions(t *testing.T) {
+	client := imagefake.NewSimpleClientset(
+		&imagev1.ImageStream{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app"},
+			Spec:       imagev1.ImageStreamSpec{Tags: []imagev1.TagReference{{Name: "latest"}, {Name: "v1"}}},
+			Status:     imagev1.ImageStreamStatus{Tags: []imagev1.NamedTagEventList{{Tag: "v2"}, {Tag: "latest"}}},
+		},
+		&imagev1.ImageStream{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "api"}},
+		&imagev1.ImageStream{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift", Name: "must-gather"}},
+	).ImageV1()
+
+	tests := []struct {
+		toComplete string
+		want       []string
+		directive  cobra.ShellCompDirective
+	}{
+		{
+			toComplete: "a",
+			want:       []string{"api:", "app:"},
+			directive:  cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace,
+		},
+		{
+			toComplete: "app:v",
+			want:       []string{"app:v1", "app:v2"},
+			directive:  cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			toComplete: "openshift/must",
+			want:       []string{"openshift/must-gather:"},
+			directive:  cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace,
+		},
+		{
+			toComplete: "missing:",
+			directive:  cobra.ShellCompDirectiveNoFileComp,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.toComplete, func(t *testing.T) {
+			comps, directive := imageStreamTagCompletions(client, "test", test.toComplete)
+			if !reflect.DeepEqual(comps, test.want) || directive != test.directive {
+				t.Errorf("got %v (%d), want %v (%d)", comps, directive, test.want, test.directive)
+			}
+		})
+	}
+}
+
+func TestUpdateCompletions(t *testing.T) {
+	cv := &configv1.ClusterVersion{
+		Status: configv1.ClusterVersionStatus{
+			Desired: configv1.Release{Channels: []string{"stable-4.16", "fast-4.16", "stable-4.17"}},
+			AvailableUpdates: []configv1.Release{
+				{Version: "4.16.2", Image: "quay.io/openshift-release-dev/ocp-release@sha256:2"},
+				{Version: "4.17.0", Image: "quay.io/openshift-release-dev/ocp-release@sha256:3"},
+			},
+			ConditionalUpdates: []configv1.ConditionalUpdate{
+				{
+					Release: configv1.Release{Version: "4.16.1", Image: "quay.io/openshift-release-dev/ocp-release@sha256:1"},
+					Risks:   []configv1.ConditionalUpdateRisk{{Name: "SomeRisk"}},
+				},
+				{Release: configv1.Release{Version: "4.16.2"}},
+			},
+		},
+	}
+	if got, want := UpdateCompletions(cv, "4.16", false), []string{"4.16.2", "4.16.1\tconditional update, SomeRisk"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := UpdateCompletions(cv, "quay.io/openshift-release-dev/ocp-release@sha256:3", true), []string{"quay.io/openshift-release-dev/ocp-release@sha256:3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := ChannelCompletions(cv, "stable"), []string{"stable-4.16", "stable-4.17"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := UpdateCompletions(nil, "", false); got != nil {
+		t.Errorf("expected no completions without a cluster version, got %q", got)
+	}
+}
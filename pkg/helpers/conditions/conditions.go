@@ -101,3 +101,35 @@ func PodDone(event watch.Event) (bool, error) {
 	}
 	return false, nil
 }
+
+// PodEphemeralContainerRunning returns false until the named ephemeral container has ContainerStatus running,
+// and will return an error if the pod is deleted, runs to completion, or the ephemeral container terminates.
+func PodEphemeralContainerRunning(containerName string) watchtools.ConditionFunc {
+	return func(event watch.Event) (bool, error) {
+		switch event.Type {
+		case watch.Deleted:
+			return false, errors.NewNotFound(schema.GroupResource{Resource: "pods"}, "")
+		}
+		t, ok := event.Object.(*corev1.Pod)
+		if !ok {
+			return false, nil
+		}
+		switch t.Status.Phase {
+		case corev1.PodFailed, corev1.PodSucceeded:
+			return false, krun.ErrPodCompleted
+		}
+		for _, s := range t.Status.EphemeralContainerStatuses {
+			if s.Name != containerName {
+				continue
+			}
+			if s.State.Terminated != nil {
+				if s.State.Terminated.ExitCode != 0 {
+					return false, ErrNonZeroExitCode
+				}
+				return false, ErrContainerTerminated
+			}
+			return s.State.Running != nil, nil
+		}
+		return false, nil
+	}
+}
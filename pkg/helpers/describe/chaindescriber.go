@@ -1,6 +1,7 @@
 package describe
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -8,13 +9,17 @@ import (
 	"github.com/gonum/graph"
 	"github.com/gonum/graph/encoding/dot"
 	"github.com/gonum/graph/path"
+	corev1 "k8s.io/api/core/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 
+	buildv1 "github.com/openshift/api/build/v1"
 	imagev1 "github.com/openshift/api/image/v1"
 	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	"github.com/openshift/library-go/pkg/build/buildutil"
 	dotutil "github.com/openshift/oc/pkg/helpers/dot"
 	buildedges "github.com/openshift/oc/pkg/helpers/graph/buildgraph"
 	buildanalysis "github.com/openshift/oc/pkg/helpers/graph/buildgraph/analysis"
@@ -38,10 +43,14 @@ func (e NotFoundErr) Error() string {
 // ChainDescriber generates extended information about a chain of
 // dependencies of an image stream
 type ChainDescriber struct {
-	c            buildv1client.BuildConfigsGetter
-	namespaces   sets.String
-	outputFormat string
-	namer        osgraph.Namer
+	c                buildv1client.BuildConfigsGetter
+	namespaces       sets.String
+	followNamespaces bool
+	outputFormat     string
+	namer            osgraph.Namer
+
+	// loaded are the namespaces the build configurations were loaded from
+	loaded sets.String
 }
 
 // NewChainDescriber returns a new ChainDescriber
@@ -49,27 +58,58 @@ func NewChainDescriber(c buildv1client.BuildConfigsGetter, namespaces sets.Strin
 	return &ChainDescriber{c: c, namespaces: namespaces, outputFormat: out, namer: namespacedFormatter{hideNamespace: true}}
 }
 
+// FollowNamespaces has the describer also load the build configurations of the
+// namespaces of the image stream tags the loaded build configurations refer to,
+// skipping those the user is not allowed to read.
+func (d *ChainDescriber) FollowNamespaces() *ChainDescriber {
+	d.followNamespaces = true
+	return d
+}
+
 // MakeGraph will create the graph of all build configurations and the image streams
 // they point to via image change triggers in the provided namespace(s)
 func (d *ChainDescriber) MakeGraph() (osgraph.Graph, error) {
 	g := osgraph.New()
 
-	loaders := []GraphLoader{}
-	for namespace := range d.namespaces {
-		klog.V(4).Infof("Loading build configurations from %q", namespace)
-		loaders = append(loaders, &bcLoader{namespace: namespace, lister: d.c})
-	}
-	loadingFuncs := []func() error{}
-	for _, loader := range loaders {
-		loadingFuncs = append(loadingFuncs, loader.Load)
-	}
+	d.loaded = sets.NewString()
+	pending := d.namespaces.List()
+	for len(pending) > 0 {
+		loaders := []*bcLoader{}
+		for _, namespace := range pending {
+			klog.V(4).Infof("Loading build configurations from %q", namespace)
+			loaders = append(loaders, &bcLoader{namespace: namespace, lister: d.c})
+		}
+		d.loaded.Insert(pending...)
+
+		loadingFuncs := []func() error{}
+		for _, loader := range loaders {
+			loader := loader
+			loadingFuncs = append(loadingFuncs, func() error {
+				err := loader.Load()
+				if err != nil && kapierrors.IsForbidden(err) && !d.namespaces.Has(loader.namespace) {
+					klog.V(2).Infof("Skipping the build configurations of %q: %v", loader.namespace, err)
+					return nil
+				}
+				return err
+			})
+		}
 
-	if errs := parallel.Run(loadingFuncs...); len(errs) > 0 {
-		return g, utilerrors.NewAggregate(errs)
-	}
+		if errs := parallel.Run(loadingFuncs...); len(errs) > 0 {
+			return g, utilerrors.NewAggregate(errs)
+		}
+
+		referenced := sets.NewString()
+		for _, loader := range loaders {
+			loader.AddToGraph(g)
+			for _, bc := range loader.items {
+				referenced.Insert(referencedNamespaces(&bc)...)
+			}
+		}
 
-	for _, loader := range loaders {
-		loader.AddToGraph(g)
+		if !d.followNamespaces || d.namespaces.Has(metav1.NamespaceAll) {
+			break
+		}
+		pending = referenced.Difference(d.loaded).List()
 	}
 
 	buildedges.AddAllInputOutputEdges(g)
@@ -77,6 +117,29 @@ func (d *ChainDescriber) MakeGraph() (osgraph.Graph, error) {
 	return g, nil
 }
 
+// referencedNamespaces returns the namespaces of the image streams the build
+// configuration outputs to, builds from or is triggered by.
+func referencedNamespaces(bc *buildv1.BuildConfig) []string {
+	refs := []*corev1.ObjectReference{bc.Spec.Output.To, buildutil.GetInputReference(bc.Spec.Strategy)}
+	for _, trigger := range bc.Spec.Triggers {
+		if trigger.Type == buildv1.ImageChangeBuildTriggerType && trigger.ImageChange != nil {
+			refs = append(refs, trigger.ImageChange.From)
+		}
+	}
+	namespaces := []string{}
+	for _, ref := range refs {
+		if ref == nil || ref.Kind == "DockerImage" {
+			continue
+		}
+		namespace := ref.Namespace
+		if len(namespace) == 0 {
+			namespace = bc.Namespace
+		}
+		namespaces = append(namespaces, namespace)
+	}
+	return namespaces
+}
+
 // Describe returns the output of the graph starting from the provided
 // image stream tag (name:tag) in namespace. Namespace is needed here
 // because image stream tags with the same name can be found across
@@ -117,7 +180,13 @@ func (d *ChainDescriber) Describe(ist *imagev1.ImageStreamTag, includeInputImage
 
 	switch strings.ToLower(d.outputFormat) {
 	case "dot":
-		data, err := dot.Marshal(partitioned, dotutil.Quote(ist.Name), "", "  ", false)
+		data, err := dot.Marshal(withStrategies(partitioned), dotutil.Quote(ist.Name), "", "  ", false)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	case "json":
+		data, err := json.MarshalIndent(newChainGraph(partitioned, ist), "", "    ")
 		if err != nil {
 			return "", err
 		}
@@ -201,7 +270,7 @@ func (d *ChainDescriber) humanReadableOutput(g osgraph.Graph, f osgraph.Namer, r
 	}
 
 	var singleNamespace bool
-	if len(d.namespaces) == 1 && !d.namespaces.Has(metav1.NamespaceAll) {
+	if len(d.loaded) == 1 && !d.loaded.Has(metav1.NamespaceAll) {
 		singleNamespace = true
 	}
 	depth := map[graph.Node]int{
@@ -23,12 +23,14 @@ func TestChainDescriber(t *testing.T) {
 		namespaces       sets.String
 		output           string
 		reverse          bool
+		follow           bool
 		defaultNamespace string
 		name             string
 		tag              string
 		path             string
 		humanReadable    map[string]int
 		dot              []string
+		json             string
 		expectedErr      error
 		includeInputImg  bool
 	}{
@@ -73,8 +75,8 @@ func TestChainDescriber(t *testing.T) {
 			dot: []string{
 				"digraph \"ruby-25-centos7:latest\" {",
 				"// Node definitions.",
-				"[label=\"BuildConfig|test/ruby-hello-world\"];",
-				"[label=\"BuildConfig|test/ruby-sample-build\"];",
+				"[label=\"BuildConfig|test/ruby-hello-world\\nDocker strategy\"];",
+				"[label=\"BuildConfig|test/ruby-sample-build\\nSource strategy\"];",
 				"[label=\"ImageStreamTag|test/ruby-hello-world:latest\"];",
 				"[label=\"ImageStreamTag|test/ruby-25-centos7:latest\"];",
 				"[label=\"ImageStreamTag|test/origin-ruby-sample:latest\"];",
@@ -116,8 +118,8 @@ func TestChainDescriber(t *testing.T) {
 			dot: []string{
 				"digraph \"ruby-25-centos7:latest\" {",
 				"// Node definitions.",
-				"[label=\"BuildConfig|default/ruby-hello-world\"];",
-				"[label=\"BuildConfig|test/ruby-sample-build\"];",
+				"[label=\"BuildConfig|default/ruby-hello-world\\nDocker strategy\"];",
+				"[label=\"BuildConfig|test/ruby-sample-build\\nSource strategy\"];",
 				"[label=\"ImageStreamTag|test/ruby-hello-world:latest\"];",
 				"[label=\"ImageStreamTag|master/ruby-25-centos7:latest\"];",
 				"[label=\"ImageStreamTag|another/origin-ruby-sample:latest\"];",
@@ -131,6 +133,113 @@ func TestChainDescriber(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
+		{
+			testName:         "human readable test - follow namespaces",
+			namespaces:       sets.NewString("default"),
+			follow:           true,
+			output:           "",
+			defaultNamespace: "master",
+			name:             "ruby-25-centos7",
+			tag:              "latest",
+			path:             "../../../pkg/cli/admin/buildchain/test/multiple-namespaces-bcs.yaml",
+			humanReadable: map[string]int{
+				"<master istag/ruby-25-centos7:latest>":         1,
+				"\t<default bc/ruby-hello-world>":               1,
+				"\t\t<test istag/ruby-hello-world:latest>":      1,
+				"\t<test bc/ruby-sample-build>":                 1,
+				"\t\t<another istag/origin-ruby-sample:latest>": 1,
+			},
+			expectedErr: nil,
+		},
+		{
+			testName:         "json test - multiple namespaces",
+			namespaces:       sets.NewString("test", "master", "default"),
+			output:           "json",
+			defaultNamespace: "master",
+			name:             "ruby-25-centos7",
+			tag:              "latest",
+			path:             "../../../pkg/cli/admin/buildchain/test/multiple-namespaces-bcs.yaml",
+			json: `{
+    "root": "ImageStreamTag|master/ruby-25-centos7:latest",
+    "nodes": [
+        {
+            "id": "BuildConfig|default/ruby-hello-world",
+            "kind": "BuildConfig",
+            "namespace": "default",
+            "name": "ruby-hello-world",
+            "strategy": "Docker",
+            "triggers": [
+                "GitHub",
+                "Generic",
+                "ImageChange"
+            ]
+        },
+        {
+            "id": "BuildConfig|test/ruby-sample-build",
+            "kind": "BuildConfig",
+            "namespace": "test",
+            "name": "ruby-sample-build",
+            "strategy": "Source",
+            "triggers": [
+                "GitHub",
+                "Generic",
+                "ImageChange"
+            ]
+        },
+        {
+            "id": "ImageStreamTag|another/origin-ruby-sample:latest",
+            "kind": "ImageStreamTag",
+            "namespace": "another",
+            "name": "origin-ruby-sample:latest"
+        },
+        {
+            "id": "ImageStreamTag|master/ruby-25-centos7:latest",
+            "kind": "ImageStreamTag",
+            "namespace": "master",
+            "name": "ruby-25-centos7:latest"
+        },
+        {
+            "id": "ImageStreamTag|test/ruby-hello-world:latest",
+            "kind": "ImageStreamTag",
+            "namespace": "test",
+            "name": "ruby-hello-world:latest"
+        }
+    ],
+    "edges": [
+        {
+            "from": "BuildConfig|default/ruby-hello-world",
+            "to": "ImageStreamTag|test/ruby-hello-world:latest",
+            "kinds": [
+                "BuildOutput"
+            ]
+        },
+        {
+            "from": "BuildConfig|test/ruby-sample-build",
+            "to": "ImageStreamTag|another/origin-ruby-sample:latest",
+            "kinds": [
+                "BuildOutput"
+            ]
+        },
+        {
+            "from": "ImageStreamTag|master/ruby-25-centos7:latest",
+            "to": "BuildConfig|default/ruby-hello-world",
+            "kinds": [
+                "BuildInputImage",
+                "BuildTriggerImage"
+            ]
+        },
+        {
+            "from": "ImageStreamTag|master/ruby-25-centos7:latest",
+            "to": "BuildConfig|test/ruby-sample-build",
+            "kinds": [
+                "BuildInputImage",
+                "BuildTriggerImage"
+            ]
+        }
+    ]
+}`,
+			expectedErr: nil,
+		},
 		{
 			testName:         "human readable - multiple triggers - triggeronly",
 			name:             "ruby-25-centos7",
@@ -212,7 +321,11 @@ func TestChainDescriber(t *testing.T) {
 
 			fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(filterByScheme(buildclientscheme.Scheme, objs...)...).Fake)}
 
-			desc, err := NewChainDescriber(fakeClient, test.namespaces, test.output).Describe(ist, test.includeInputImg, test.reverse)
+			describer := NewChainDescriber(fakeClient, test.namespaces, test.output)
+			if test.follow {
+				describer.FollowNamespaces()
+			}
+			desc, err := describer.Describe(ist, test.includeInputImg, test.reverse)
 			t.Logf("%s: output:\n%s\n\n", test.testName, desc)
 			if err != test.expectedErr {
 				t.Fatalf("%s: error mismatch: expected %v, got %v", test.testName, test.expectedErr, err)
@@ -230,6 +343,10 @@ func TestChainDescriber(t *testing.T) {
 						t.Errorf("%s: unexpected description:\n%s\nexpected line in it:\n%s", test.testName, desc, expected)
 					}
 				}
+			case "json":
+				if desc != test.json {
+					t.Errorf("%s: unexpected description:\n%s\nexpected:\n%s", test.testName, desc, test.json)
+				}
 			case "":
 				if lenReadable(test.humanReadable) != len(got) {
 					t.Fatalf("%s: expected %d lines, got %d:\n%s", test.testName, lenReadable(test.humanReadable), len(got), desc)
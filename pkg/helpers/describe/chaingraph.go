@@ -0,0 +1,115 @@
+package describe
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gonum/graph"
+	"github.com/gonum/graph/encoding/dot"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+)
+
+// strategyGraph labels the build configurations of the DOT output of a graph
+// with their strategy.
+type strategyGraph struct {
+	osgraph.Graph
+}
+
+func withStrategies(g osgraph.Graph) graph.Graph {
+	return strategyGraph{Graph: g}
+}
+
+func (g strategyGraph) Nodes() []graph.Node {
+	nodes := g.Graph.Nodes()
+	for i, node := range nodes {
+		if bc, ok := node.(*buildgraph.BuildConfigNode); ok {
+			nodes[i] = strategyNode{bc}
+		}
+	}
+	return nodes
+}
+
+type strategyNode struct {
+	*buildgraph.BuildConfigNode
+}
+
+// DOTAttributes implements an attribute getter for the DOT encoding
+func (n strategyNode) DOTAttributes() []dot.Attribute {
+	label := fmt.Sprintf("%s\n%s strategy", n.UniqueName(), n.BuildConfig.Spec.Strategy.Type)
+	return []dot.Attribute{{Key: "label", Value: fmt.Sprintf("%q", label)}}
+}
+
+// chainGraph is the JSON output of the ChainDescriber.
+type chainGraph struct {
+	// Root is the ID of the image stream tag the chain was described from.
+	Root  string      `json:"root"`
+	Nodes []chainNode `json:"nodes"`
+	Edges []chainEdge `json:"edges"`
+}
+
+type chainNode struct {
+	// ID is the unique name of the node, the same as its label in the DOT output.
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Strategy and Triggers are the strategy and trigger types of a build
+	// configuration.
+	Strategy string   `json:"strategy,omitempty"`
+	Triggers []string `json:"triggers,omitempty"`
+}
+
+type chainEdge struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Kinds []string `json:"kinds"`
+}
+
+func newChainGraph(g osgraph.Graph, ist *imagev1.ImageStreamTag) *chainGraph {
+	out := &chainGraph{
+		Root:  imagegraph.ImageStreamTagNodeName(ist).String(),
+		Nodes: []chainNode{},
+		Edges: []chainEdge{},
+	}
+	ids := map[int]string{}
+	for _, node := range g.Nodes() {
+		switch t := node.(type) {
+		case *imagegraph.ImageStreamTagNode:
+			n := chainNode{ID: t.UniqueName().String(), Kind: imagegraph.ImageStreamTagNodeKind, Namespace: t.Namespace, Name: t.ImageStreamTag.Name}
+			ids[node.ID()] = n.ID
+			out.Nodes = append(out.Nodes, n)
+		case *buildgraph.BuildConfigNode:
+			bc := t.BuildConfig
+			n := chainNode{ID: t.UniqueName().String(), Kind: buildgraph.BuildConfigNodeKind, Namespace: bc.Namespace, Name: bc.Name, Strategy: string(bc.Spec.Strategy.Type)}
+			for _, trigger := range bc.Spec.Triggers {
+				n.Triggers = append(n.Triggers, string(trigger.Type))
+			}
+			ids[node.ID()] = n.ID
+			out.Nodes = append(out.Nodes, n)
+		}
+	}
+	for _, edge := range g.Edges() {
+		from, ok := ids[edge.From().ID()]
+		if !ok {
+			continue
+		}
+		to, ok := ids[edge.To().ID()]
+		if !ok {
+			continue
+		}
+		out.Edges = append(out.Edges, chainEdge{From: from, To: to, Kinds: g.EdgeKinds(edge).List()})
+	}
+
+	sort.Slice(out.Nodes, func(i, j int) bool { return out.Nodes[i].ID < out.Nodes[j].ID })
+	sort.Slice(out.Edges, func(i, j int) bool {
+		if out.Edges[i].From != out.Edges[j].From {
+			return out.Edges[i].From < out.Edges[j].From
+		}
+		return out.Edges[i].To < out.Edges[j].To
+	})
+	return out
+}
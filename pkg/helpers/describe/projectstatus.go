@@ -185,12 +185,16 @@ func createSelector(values map[string]string) labels.Selector {
 
 // Describe returns the description of a project
 func (d *ProjectStatusDescriber) Describe(namespace, name string) (string, error) {
-	var f formatter = namespacedFormatter{}
-
 	g, forbiddenResources, err := d.MakeGraph(namespace)
 	if err != nil {
 		return "", err
 	}
+	return d.DescribeGraph(g, forbiddenResources, namespace)
+}
+
+// DescribeGraph describes the graph of the namespace built by MakeGraph.
+func (d *ProjectStatusDescriber) DescribeGraph(g osgraph.Graph, forbiddenResources sets.String, namespace string) (string, error) {
+	var f formatter = namespacedFormatter{}
 
 	allNamespaces := namespace == metav1.NamespaceAll
 	var project *projectv1.Project
@@ -212,53 +216,11 @@ func (d *ProjectStatusDescriber) Describe(namespace, name string) (string, error
 		f = namespacedFormatter{currentNamespace: namespace}
 	}
 
-	coveredNodes := graphview.IntSet{}
-
-	allServices, coveredByServices := graphview.AllServiceGroups(g, coveredNodes)
-	coveredNodes.Insert(coveredByServices.List()...)
-
-	// services grouped by selector
-	servicesBySelector := map[string][]graphview.ServiceGroup{}
-	services := []graphview.ServiceGroup{}
-
-	// group services with identical selectors
-	for _, svc := range allServices {
-		selector := createSelector(svc.Service.Spec.Selector)
-		if _, seen := servicesBySelector[selector.String()]; seen {
-			servicesBySelector[selector.String()] = append(servicesBySelector[selector.String()], svc)
-			continue
-		}
-
-		services = append(services, svc)
-		servicesBySelector[selector.String()] = []graphview.ServiceGroup{}
-	}
-
-	standaloneDCs, coveredByDCs := graphview.AllDeploymentConfigPipelines(g, coveredNodes)
-	coveredNodes.Insert(coveredByDCs.List()...)
-
-	standaloneDeployments, coveredByDeployments := graphview.AllDeployments(g, coveredNodes)
-	coveredNodes.Insert(coveredByDeployments.List()...)
-
-	standaloneStatefulSets, coveredByStatefulSets := graphview.AllStatefulSets(g, coveredNodes)
-	coveredNodes.Insert(coveredByStatefulSets.List()...)
-
-	standaloneRCs, coveredByRCs := graphview.AllReplicationControllers(g, coveredNodes)
-	coveredNodes.Insert(coveredByRCs.List()...)
-
-	standaloneRSs, coveredByRSs := graphview.AllReplicaSets(g, coveredNodes)
-	coveredNodes.Insert(coveredByRSs.List()...)
-
-	standaloneImages, coveredByImages := graphview.AllImagePipelinesFromBuildConfig(g, coveredNodes)
-	coveredNodes.Insert(coveredByImages.List()...)
-
-	standaloneDaemonSets, coveredByDaemonSets := graphview.AllDaemonSets(g, coveredNodes)
-	coveredNodes.Insert(coveredByDaemonSets.List()...)
-
-	standaloneJobs, coveredByJobs := graphview.AllJobs(g, coveredNodes)
-	coveredNodes.Insert(coveredByJobs.List()...)
-
-	standalonePods, coveredByPods := graphview.AllPods(g, coveredNodes)
-	coveredNodes.Insert(coveredByPods.List()...)
+	groups := groupProject(g)
+	services, servicesBySelector := groups.services, groups.servicesBySelector
+	standaloneDCs, standaloneDeployments, standaloneStatefulSets := groups.standaloneDCs, groups.standaloneDeployments, groups.standaloneStatefulSets
+	standaloneRCs, standaloneRSs, standaloneImages := groups.standaloneRCs, groups.standaloneRSs, groups.standaloneImages
+	standaloneDaemonSets, standaloneJobs, standalonePods := groups.standaloneDaemonSets, groups.standaloneJobs, groups.standalonePods
 
 	return tabbedString(func(out *tabwriter.Writer) error {
 		indent := "  "
@@ -463,20 +425,10 @@ func (d *ProjectStatusDescriber) Describe(namespace, name string) (string, error
 			printLines(out, indent, 0, describeMonopod(f, monopod.Pod)...)
 		}
 
-		allMarkers := osgraph.Markers{}
-		allMarkers = append(allMarkers, createForbiddenMarkers(forbiddenResources)...)
-		for _, scanner := range getMarkerScanners(d.LogsCommandName, d.SecurityPolicyCommandFormat, d.SetProbeCommandName, forbiddenResources) {
-			allMarkers = append(allMarkers, scanner(g, f)...)
-		}
-
-		// TODO: Provide an option to chase these hidden markers.
-		allMarkers = allMarkers.FilterByNamespace(namespace)
+		allMarkers := d.markers(g, f, forbiddenResources, namespace)
 
 		fmt.Fprintln(out)
 
-		sort.Stable(osgraph.ByKey(allMarkers))
-		sort.Stable(osgraph.ByNodeID(allMarkers))
-
 		errorMarkers := allMarkers.BySeverity(osgraph.ErrorSeverity)
 		errorSuggestions := 0
 		if len(errorMarkers) > 0 {
@@ -560,6 +512,112 @@ func (d *ProjectStatusDescriber) Describe(namespace, name string) (string, error
 	})
 }
 
+// Markers returns the errors, warnings, and infos identified in the graph of the namespace.
+func (d *ProjectStatusDescriber) Markers(g osgraph.Graph, forbiddenResources sets.String, namespace string) osgraph.Markers {
+	var f formatter = namespacedFormatter{}
+	if namespace != metav1.NamespaceAll {
+		f = namespacedFormatter{currentNamespace: namespace}
+	}
+	return d.markers(g, f, forbiddenResources, namespace)
+}
+
+func (d *ProjectStatusDescriber) markers(g osgraph.Graph, f formatter, forbiddenResources sets.String, namespace string) osgraph.Markers {
+	allMarkers := osgraph.Markers{}
+	allMarkers = append(allMarkers, createForbiddenMarkers(forbiddenResources)...)
+	for _, scanner := range getMarkerScanners(d.LogsCommandName, d.SecurityPolicyCommandFormat, d.SetProbeCommandName, forbiddenResources) {
+		allMarkers = append(allMarkers, scanner(g, f)...)
+	}
+
+	// TODO: Provide an option to chase these hidden markers.
+	allMarkers = allMarkers.FilterByNamespace(namespace)
+
+	sort.Stable(osgraph.ByKey(allMarkers))
+	sort.Stable(osgraph.ByNodeID(allMarkers))
+	return allMarkers
+}
+
+// projectGroups are the nodes of a graph grouped the way they are described: services with the
+// workloads they expose, then the workloads not exposed by any service.
+type projectGroups struct {
+	services []graphview.ServiceGroup
+	// servicesBySelector holds the services with the same selector as one of services
+	servicesBySelector map[string][]graphview.ServiceGroup
+
+	standaloneDCs          []graphview.DeploymentConfigPipeline
+	standaloneDeployments  []graphview.Deployment
+	standaloneStatefulSets []graphview.StatefulSet
+	standaloneRCs          []graphview.ReplicationController
+	standaloneRSs          []graphview.ReplicaSet
+	standaloneImages       []graphview.ImagePipeline
+	standaloneDaemonSets   []graphview.DaemonSet
+	standaloneJobs         []graphview.Job
+	standalonePods         []graphview.Pod
+}
+
+func groupProject(g osgraph.Graph) projectGroups {
+	coveredNodes := graphview.IntSet{}
+
+	allServices, coveredByServices := graphview.AllServiceGroups(g, coveredNodes)
+	coveredNodes.Insert(coveredByServices.List()...)
+
+	// services grouped by selector
+	servicesBySelector := map[string][]graphview.ServiceGroup{}
+	services := []graphview.ServiceGroup{}
+
+	// group services with identical selectors
+	for _, svc := range allServices {
+		selector := createSelector(svc.Service.Spec.Selector)
+		if _, seen := servicesBySelector[selector.String()]; seen {
+			servicesBySelector[selector.String()] = append(servicesBySelector[selector.String()], svc)
+			continue
+		}
+
+		services = append(services, svc)
+		servicesBySelector[selector.String()] = []graphview.ServiceGroup{}
+	}
+
+	standaloneDCs, coveredByDCs := graphview.AllDeploymentConfigPipelines(g, coveredNodes)
+	coveredNodes.Insert(coveredByDCs.List()...)
+
+	standaloneDeployments, coveredByDeployments := graphview.AllDeployments(g, coveredNodes)
+	coveredNodes.Insert(coveredByDeployments.List()...)
+
+	standaloneStatefulSets, coveredByStatefulSets := graphview.AllStatefulSets(g, coveredNodes)
+	coveredNodes.Insert(coveredByStatefulSets.List()...)
+
+	standaloneRCs, coveredByRCs := graphview.AllReplicationControllers(g, coveredNodes)
+	coveredNodes.Insert(coveredByRCs.List()...)
+
+	standaloneRSs, coveredByRSs := graphview.AllReplicaSets(g, coveredNodes)
+	coveredNodes.Insert(coveredByRSs.List()...)
+
+	standaloneImages, coveredByImages := graphview.AllImagePipelinesFromBuildConfig(g, coveredNodes)
+	coveredNodes.Insert(coveredByImages.List()...)
+
+	standaloneDaemonSets, coveredByDaemonSets := graphview.AllDaemonSets(g, coveredNodes)
+	coveredNodes.Insert(coveredByDaemonSets.List()...)
+
+	standaloneJobs, coveredByJobs := graphview.AllJobs(g, coveredNodes)
+	coveredNodes.Insert(coveredByJobs.List()...)
+
+	standalonePods, coveredByPods := graphview.AllPods(g, coveredNodes)
+	coveredNodes.Insert(coveredByPods.List()...)
+
+	return projectGroups{
+		services:               services,
+		servicesBySelector:     servicesBySelector,
+		standaloneDCs:          standaloneDCs,
+		standaloneDeployments:  standaloneDeployments,
+		standaloneStatefulSets: standaloneStatefulSets,
+		standaloneRCs:          standaloneRCs,
+		standaloneRSs:          standaloneRSs,
+		standaloneImages:       standaloneImages,
+		standaloneDaemonSets:   standaloneDaemonSets,
+		standaloneJobs:         standaloneJobs,
+		standalonePods:         standalonePods,
+	}
+}
+
 // printMarkerSuggestions prints a formatted list of marker suggestions
 // and returns the amount of suggestions printed
 func printMarkerSuggestions(markers []osgraph.Marker, suggest bool, out *tabwriter.Writer, indent string) int {
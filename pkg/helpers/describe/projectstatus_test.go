@@ -2,7 +2,10 @@ package describe
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 	"text/tabwriter"
@@ -728,3 +731,86 @@ func setNamespace(scheme *runtime.Scheme, obj runtime.Object, namespace string)
 
 	return nil
 }
+
+func TestProjectStatusReport(t *testing.T) {
+	objs, err := readObjectsFromPath("../graph/genericgraph/test/new-project-deployed-app.yaml", "example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	objs = append(objs, &projectv1.Project{ObjectMeta: metav1.ObjectMeta{Name: "example"}})
+
+	appsScheme := runtime.NewScheme()
+	appsv1.Install(appsScheme)
+	buildScheme := runtime.NewScheme()
+	buildv1.Install(buildScheme)
+	imageScheme := runtime.NewScheme()
+	imagev1.Install(imageScheme)
+	projectScheme := runtime.NewScheme()
+	projectv1.Install(projectScheme)
+	routeScheme := runtime.NewScheme()
+	routev1.Install(routeScheme)
+	kubeScheme := runtime.NewScheme()
+	kubernetesscheme.AddToScheme(kubeScheme)
+
+	d := ProjectStatusDescriber{
+		KubeClient:    fakekubernetes.NewSimpleClientset(filterByScheme(kubeScheme, objs...)...),
+		ProjectClient: &fakeprojectv1client.FakeProjectV1{Fake: &(fakeprojectclient.NewSimpleClientset(filterByScheme(projectScheme, objs...)...).Fake)},
+		BuildClient:   &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(filterByScheme(buildScheme, objs...)...).Fake)},
+		ImageClient:   &fakeimagev1client.FakeImageV1{Fake: &(fakeimageclient.NewSimpleClientset(filterByScheme(imageScheme, objs...)...).Fake)},
+		AppsClient:    &fakeappsv1client.FakeAppsV1{Fake: &(fakeappsclient.NewSimpleClientset(filterByScheme(appsScheme, objs...)...).Fake)},
+		RouteClient:   &fakeroutev1client.FakeRouteV1{Fake: &(fakerouteclient.NewSimpleClientset(filterByScheme(routeScheme, objs...)...).Fake)},
+		Server:        "https://example.com:8443",
+		RESTMapper:    testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme),
+	}
+	g, forbiddenResources, err := d.MakeGraph("example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := d.Status(g, forbiddenResources, "example")
+
+	var apps []string
+	for _, app := range status.Applications {
+		for _, workload := range app.Workloads {
+			apps = append(apps, fmt.Sprintf("%s %s/%s %d/%d", app.Service.Name, workload.Kind, workload.Name, workload.Replicas.Ready, workload.Replicas.Desired))
+		}
+	}
+	if expected := []string{
+		"database-external DeploymentConfig/database 0/1",
+		"database DeploymentConfig/database 0/1",
+		"frontend DeploymentConfig/frontend 0/1",
+	}; !reflect.DeepEqual(apps, expected) {
+		t.Errorf("unexpected applications: %v", apps)
+	}
+
+	if len(status.Routes) != 2 || status.Routes[0].Admitted || !status.Routes[1].Admitted || status.Routes[1].TLSTermination != "edge" {
+		t.Errorf("unexpected routes: %#v", status.Routes)
+	}
+
+	var issues []string
+	for _, issue := range status.Issues {
+		issues = append(issues, issue.Severity+" "+issue.Key)
+	}
+	if expected := []string{
+		"error MissingOutputImageStream",
+		"error MissingImageStream",
+		"error RouteNotAdmitted",
+		"info MissingReadinessProbe",
+		"info MissingLivenessProbe",
+		"info MissingReadinessProbe",
+		"info MissingLivenessProbe",
+	}; !reflect.DeepEqual(issues, expected) {
+		t.Errorf("unexpected issues: %v", issues)
+	}
+	if object := status.Issues[2].Object; object == nil || *object != (StatusObject{Kind: "Route", Namespace: "example", Name: "frontend"}) {
+		t.Errorf("unexpected object of the issue: %#v", object)
+	}
+
+	// the schema is stable, so the output must remain parseable by scripts
+	data, err := json.Marshal(status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"workloads":[{"kind":"DeploymentConfig","namespace":"example","name":"database","replicas":{"desired":1,"ready":0}}]`) {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+}
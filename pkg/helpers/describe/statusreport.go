@@ -0,0 +1,292 @@
+package describe
+
+import (
+	"sort"
+
+	"github.com/gonum/graph"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	routev1 "github.com/openshift/api/route/v1"
+	appsedges "github.com/openshift/oc/pkg/helpers/graph/appsgraph"
+	appsgraph "github.com/openshift/oc/pkg/helpers/graph/appsgraph/nodes"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+	kubeedges "github.com/openshift/oc/pkg/helpers/graph/kubegraph"
+	kubegraph "github.com/openshift/oc/pkg/helpers/graph/kubegraph/nodes"
+	routegraph "github.com/openshift/oc/pkg/helpers/graph/routegraph/nodes"
+)
+
+// ProjectStatus is the machine-readable overview of a project. Fields are only ever added to it, so
+// that scripts can rely on its schema.
+type ProjectStatus struct {
+	// Namespace is the namespace described, empty for all namespaces.
+	Namespace string `json:"namespace,omitempty"`
+	// Server is the URL of the server the namespace is on.
+	Server string `json:"server,omitempty"`
+	// Applications are the services with the workloads they expose, followed by the workloads not
+	// exposed by any service.
+	Applications []ApplicationStatus `json:"applications"`
+	// Routes are the routes of the namespace.
+	Routes []RouteStatus `json:"routes"`
+	// Issues are the errors, warnings, and infos identified, most severe first.
+	Issues []StatusIssue `json:"issues"`
+}
+
+// StatusObject identifies an object of the project.
+type StatusObject struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// ApplicationStatus is a service and the workloads it exposes.
+type ApplicationStatus struct {
+	// Service is unset for a workload not exposed by a service.
+	Service   *StatusObject    `json:"service,omitempty"`
+	Workloads []WorkloadStatus `json:"workloads"`
+}
+
+// WorkloadStatus is a deployment config, deployment, stateful set, daemon set, replication
+// controller, replica set, job, build config, or pod.
+type WorkloadStatus struct {
+	StatusObject `json:",inline"`
+	// Replicas is set for the workloads running replicas of a pod.
+	Replicas *ReplicasStatus `json:"replicas,omitempty"`
+	// Phase is set for pods.
+	Phase string `json:"phase,omitempty"`
+}
+
+// ReplicasStatus counts the desired and ready replicas of a workload.
+type ReplicasStatus struct {
+	Desired int32 `json:"desired"`
+	Ready   int32 `json:"ready"`
+}
+
+// RouteStatus is a route and whether a router admitted it.
+type RouteStatus struct {
+	StatusObject `json:",inline"`
+	Host         string `json:"host,omitempty"`
+	Path         string `json:"path,omitempty"`
+	// Service is the name of the service the route sends traffic to.
+	Service string `json:"service,omitempty"`
+	// TLSTermination is empty for an insecure route.
+	TLSTermination string `json:"tlsTermination,omitempty"`
+	Admitted       bool   `json:"admitted"`
+}
+
+// StatusIssue is an error, warning, or info identified in the project.
+type StatusIssue struct {
+	// Severity is one of error, warning, or info.
+	Severity   string        `json:"severity"`
+	Key        string        `json:"key"`
+	Message    string        `json:"message"`
+	Suggestion string        `json:"suggestion,omitempty"`
+	Object     *StatusObject `json:"object,omitempty"`
+}
+
+// Status returns the overview of the graph of the namespace built by MakeGraph.
+func (d *ProjectStatusDescriber) Status(g osgraph.Graph, forbiddenResources sets.String, namespace string) *ProjectStatus {
+	status := &ProjectStatus{
+		Namespace:    namespace,
+		Server:       d.Server,
+		Applications: []ApplicationStatus{},
+		Routes:       []RouteStatus{},
+		Issues:       []StatusIssue{},
+	}
+
+	groups := groupProject(g)
+	for _, service := range groups.services {
+		if !service.Service.Found() {
+			continue
+		}
+		// services with the same selector expose the same workloads
+		for _, svc := range append(groups.servicesBySelector[createSelector(service.Service.Spec.Selector).String()], service) {
+			if !svc.Service.Found() {
+				continue
+			}
+			app := ApplicationStatus{Service: statusObject(svc.Service), Workloads: []WorkloadStatus{}}
+			for _, node := range svc.DeploymentConfigPipelines {
+				app.Workloads = appendWorkload(app.Workloads, node.DeploymentConfig)
+			}
+			for _, node := range svc.StatefulSets {
+				app.Workloads = appendWorkload(app.Workloads, node.StatefulSet)
+			}
+			for _, node := range svc.Deployments {
+				app.Workloads = appendWorkload(app.Workloads, node.Deployment)
+			}
+			for _, node := range svc.DaemonSets {
+				app.Workloads = appendWorkload(app.Workloads, node.DaemonSet)
+			}
+		rsNode:
+			for _, rsNode := range svc.FulfillingRSs {
+				for _, coveredD := range svc.FulfillingDeployments {
+					if kubeedges.BelongsToDeployment(coveredD.Deployment, rsNode.ReplicaSet) {
+						continue rsNode
+					}
+				}
+				app.Workloads = appendWorkload(app.Workloads, rsNode)
+			}
+		rcNode:
+			for _, rcNode := range svc.FulfillingRCs {
+				for _, coveredDC := range svc.FulfillingDCs {
+					if appsedges.BelongsToDeploymentConfig(coveredDC.DeploymentConfig, rcNode.ReplicationController) {
+						continue rcNode
+					}
+				}
+				app.Workloads = appendWorkload(app.Workloads, rcNode)
+			}
+			for _, podNode := range svc.FulfillingPods {
+				// pods of a controller are described by it
+				if len(g.SuccessorNodesByEdgeKind(podNode, appsedges.ManagedByControllerEdgeKind)) > 0 {
+					continue
+				}
+				app.Workloads = appendWorkload(app.Workloads, podNode)
+			}
+			status.Applications = append(status.Applications, app)
+		}
+	}
+
+	standalone := []graph.Node{}
+	for _, node := range groups.standaloneDCs {
+		standalone = append(standalone, node.DeploymentConfig)
+	}
+	for _, node := range groups.standaloneDeployments {
+		standalone = append(standalone, node.Deployment)
+	}
+	for _, node := range groups.standaloneStatefulSets {
+		standalone = append(standalone, node.StatefulSet)
+	}
+	for _, node := range groups.standaloneImages {
+		if node.Build != nil {
+			standalone = append(standalone, node.Build)
+		}
+	}
+	for _, node := range groups.standaloneRCs {
+		standalone = append(standalone, node.RC)
+	}
+	for _, node := range groups.standaloneRSs {
+		standalone = append(standalone, node.RS)
+	}
+	for _, node := range groups.standaloneDaemonSets {
+		standalone = append(standalone, node.DaemonSet)
+	}
+	for _, node := range groups.standaloneJobs {
+		standalone = append(standalone, node.Job)
+	}
+	if monopods, err := filterBoringPods(groups.standalonePods); err == nil {
+		for _, node := range monopods {
+			standalone = append(standalone, node.Pod)
+		}
+	}
+	for _, node := range standalone {
+		if workloads := appendWorkload(nil, node); len(workloads) > 0 {
+			status.Applications = append(status.Applications, ApplicationStatus{Workloads: workloads})
+		}
+	}
+
+	for _, uncastNode := range g.NodesByKind(routegraph.RouteNodeKind) {
+		routeNode := uncastNode.(*routegraph.RouteNode)
+		status.Routes = append(status.Routes, routeStatus(routeNode.Route))
+	}
+	sort.Slice(status.Routes, func(i, j int) bool {
+		if status.Routes[i].Namespace != status.Routes[j].Namespace {
+			return status.Routes[i].Namespace < status.Routes[j].Namespace
+		}
+		return status.Routes[i].Name < status.Routes[j].Name
+	})
+
+	markers := d.Markers(g, forbiddenResources, namespace)
+	for _, severity := range []osgraph.Severity{osgraph.ErrorSeverity, osgraph.WarningSeverity, osgraph.InfoSeverity} {
+		for _, marker := range markers.BySeverity(severity) {
+			issue := StatusIssue{
+				Severity:   string(marker.Severity),
+				Key:        marker.Key,
+				Message:    marker.Message,
+				Suggestion: marker.Suggestion.String(),
+			}
+			if marker.Node != nil {
+				issue.Object = statusObject(marker.Node)
+			}
+			status.Issues = append(status.Issues, issue)
+		}
+	}
+
+	return status
+}
+
+// statusObject returns the kind, namespace, and name of the object of a node, or nil for a node
+// without object.
+func statusObject(node graph.Node) *StatusObject {
+	kinded, ok := node.(interface{ Kind() string })
+	if !ok {
+		return nil
+	}
+	objectified, ok := node.(interface{ Object() interface{} })
+	if !ok {
+		return nil
+	}
+	object, err := meta.Accessor(objectified.Object())
+	if err != nil {
+		return nil
+	}
+	return &StatusObject{Kind: kinded.Kind(), Namespace: object.GetNamespace(), Name: object.GetName()}
+}
+
+// appendWorkload appends the status of the workload of the node, unless the workload does not
+// exist.
+func appendWorkload(workloads []WorkloadStatus, node graph.Node) []WorkloadStatus {
+	if checker, ok := node.(osgraph.ExistenceChecker); ok && !checker.Found() {
+		return workloads
+	}
+	object := statusObject(node)
+	if object == nil {
+		return workloads
+	}
+	workload := WorkloadStatus{StatusObject: *object}
+	replicas := func(desired *int32, ready int32) *ReplicasStatus {
+		if desired == nil {
+			one := int32(1)
+			desired = &one
+		}
+		return &ReplicasStatus{Desired: *desired, Ready: ready}
+	}
+	switch t := node.(type) {
+	case *kubegraph.PodNode:
+		workload.Phase = string(t.Status.Phase)
+	case *kubegraph.ReplicationControllerNode:
+		workload.Replicas = replicas(t.ReplicationController.Spec.Replicas, t.ReplicationController.Status.ReadyReplicas)
+	case *kubegraph.ReplicaSetNode:
+		workload.Replicas = replicas(t.ReplicaSet.Spec.Replicas, t.ReplicaSet.Status.ReadyReplicas)
+	case *kubegraph.DeploymentNode:
+		workload.Replicas = replicas(t.Deployment.Spec.Replicas, t.Deployment.Status.ReadyReplicas)
+	case *kubegraph.StatefulSetNode:
+		workload.Replicas = replicas(t.StatefulSet.Spec.Replicas, t.StatefulSet.Status.ReadyReplicas)
+	case *kubegraph.DaemonSetNode:
+		workload.Replicas = replicas(&t.DaemonSet.Status.DesiredNumberScheduled, t.DaemonSet.Status.NumberReady)
+	case *appsgraph.DeploymentConfigNode:
+		workload.Replicas = replicas(&t.DeploymentConfig.Spec.Replicas, t.DeploymentConfig.Status.ReadyReplicas)
+	}
+	return append(workloads, workload)
+}
+
+func routeStatus(route *routev1.Route) RouteStatus {
+	status := RouteStatus{
+		StatusObject: StatusObject{Kind: routegraph.RouteNodeKind, Namespace: route.Namespace, Name: route.Name},
+		Host:         route.Spec.Host,
+		Path:         route.Spec.Path,
+		Service:      route.Spec.To.Name,
+	}
+	if route.Spec.TLS != nil {
+		status.TLSTermination = string(route.Spec.TLS.Termination)
+	}
+	for _, ingress := range route.Status.Ingress {
+		for _, condition := range ingress.Conditions {
+			if condition.Type == routev1.RouteAdmitted && condition.Status == corev1.ConditionTrue {
+				status.Admitted = true
+			}
+		}
+	}
+	return status
+}
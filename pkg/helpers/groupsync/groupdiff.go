@@ -0,0 +1,112 @@
+package syncgroups
+
+import (
+	"context"
+	"sort"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	userv1 "github.com/openshift/api/user/v1"
+	userv1client "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
+)
+
+// GroupChangeType describes what a sync or prune job does to an OpenShift group
+type GroupChangeType string
+
+const (
+	// GroupAdded is a group that does not exist yet and is created by the sync
+	GroupAdded GroupChangeType = "Added"
+	// GroupUpdated is an existing group whose members are changed by the sync
+	GroupUpdated GroupChangeType = "Updated"
+	// GroupUnchanged is an existing group whose members are already in sync
+	GroupUnchanged GroupChangeType = "Unchanged"
+	// GroupPruned is a group whose LDAP record no longer exists and is deleted by a prune
+	GroupPruned GroupChangeType = "Pruned"
+	// GroupExcluded is a group kept by a prune because it is blacklisted
+	GroupExcluded GroupChangeType = "Excluded"
+)
+
+// GroupChange describes the change to a single OpenShift group and its members
+type GroupChange struct {
+	Name         string
+	LDAPGroupUID string
+	Type         GroupChangeType
+	AddedUsers   []string
+	RemovedUsers []string
+}
+
+// DiffGroups compares the groups returned by a sync with the groups currently stored
+// in OpenShift and returns the changes the sync makes, in the same order.
+func DiffGroups(client userv1client.GroupInterface, groups []*userv1.Group) ([]GroupChange, error) {
+	var changes []GroupChange
+	for _, group := range groups {
+		change := GroupChange{
+			Name:         group.Name,
+			LDAPGroupUID: group.Annotations[LDAPUIDAnnotation],
+			Type:         GroupAdded,
+		}
+		current := sets.NewString()
+		if len(group.UID) > 0 {
+			existing, err := client.Get(context.TODO(), group.Name, metav1.GetOptions{})
+			switch {
+			case kapierrors.IsNotFound(err):
+			case err != nil:
+				return nil, err
+			default:
+				change.Type = GroupUpdated
+				current.Insert(existing.Users...)
+			}
+		}
+		desired := sets.NewString(group.Users...)
+		change.AddedUsers = desired.Difference(current).List()
+		change.RemovedUsers = current.Difference(desired).List()
+		if change.Type == GroupUpdated && len(change.AddedUsers) == 0 && len(change.RemovedUsers) == 0 {
+			change.Type = GroupUnchanged
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// Orphans returns the groups that a prune job deletes, without deleting them. Their
+// current members are returned as removed.
+func (s *LDAPGroupPruner) Orphans() ([]GroupChange, []error) {
+	var changes []GroupChange
+	errors := s.visitOrphans(func(ldapGroupUID, groupName string) error {
+		change := GroupChange{Name: groupName, LDAPGroupUID: ldapGroupUID, Type: GroupPruned}
+		group, err := s.GroupClient.Get(context.TODO(), groupName, metav1.GetOptions{})
+		switch {
+		case kapierrors.IsNotFound(err):
+		case err != nil:
+			return err
+		default:
+			change.RemovedUsers = sets.NewString(group.Users...).List()
+		}
+		changes = append(changes, change)
+		return nil
+	})
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes, errors
+}
+
+// ExcludedGroups returns the blacklisted groups that were synced from the LDAP server, which a
+// prune job keeps whether their LDAP record exists or not.
+func ExcludedGroups(client userv1client.GroupInterface, blacklist []string, ldapURL string) ([]GroupChange, error) {
+	var changes []GroupChange
+	for _, name := range sets.NewString(blacklist...).List() {
+		group, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+		switch {
+		case kapierrors.IsNotFound(err):
+			continue
+		case err != nil:
+			return nil, err
+		}
+		if matches, err := validateGroupAnnotations(ldapURL, *group); err != nil || !matches {
+			continue
+		}
+		changes = append(changes, GroupChange{Name: group.Name, LDAPGroupUID: group.Annotations[LDAPUIDAnnotation], Type: GroupExcluded})
+	}
+	return changes, nil
+}
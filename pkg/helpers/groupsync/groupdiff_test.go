@@ -0,0 +1,77 @@
+package syncgroups
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	userv1 "github.com/openshift/api/user/v1"
+	fakeuserclient "github.com/openshift/client-go/user/clientset/versioned/fake"
+	fakeuserv1client "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1/fake"
+)
+
+func TestDiffGroups(t *testing.T) {
+	host := newTestHost()
+	existing := newDefaultOpenShiftGroups(host)
+	existing[0].Users = []string{Member1UID, "removed-user"}
+	fakeClient := &fakeuserv1client.FakeUserV1{Fake: &(fakeuserclient.NewSimpleClientset(existing[0], existing[1]).Fake)}
+
+	synced := newDefaultOpenShiftGroups(host)
+	synced[0].UID = "uid-1"
+	synced[1].UID = "uid-2"
+
+	changes, err := DiffGroups(fakeClient.Groups(), synced)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []GroupChange{
+		{Name: "os" + Group1UID, LDAPGroupUID: Group1UID, Type: GroupUpdated, AddedUsers: []string{Member2UID}, RemovedUsers: []string{"removed-user"}},
+		{Name: "os" + Group2UID, LDAPGroupUID: Group2UID, Type: GroupUnchanged, AddedUsers: []string{}, RemovedUsers: []string{}},
+		{Name: "os" + Group3UID, LDAPGroupUID: Group3UID, Type: GroupAdded, AddedUsers: []string{Member3UID, Member4UID}, RemovedUsers: []string{}},
+	}
+	if !reflect.DeepEqual(expected, changes) {
+		t.Errorf("expected\n\t%#v\ngot\n\t%#v", expected, changes)
+	}
+}
+
+func TestOrphans(t *testing.T) {
+	testGroupPruner, _ := newTestPruner()
+	group := &userv1.Group{ObjectMeta: metav1.ObjectMeta{Name: "os" + Group2UID}, Users: []string{Member3UID, Member2UID}}
+	fakeClient := &fakeuserv1client.FakeUserV1{Fake: &(fakeuserclient.NewSimpleClientset(group).Fake)}
+	testGroupPruner.GroupClient = fakeClient.Groups()
+
+	changes, errs := testGroupPruner.Orphans()
+	for _, err := range errs {
+		t.Errorf("unexpected prune error: %v", err)
+	}
+	expected := []GroupChange{
+		{Name: "os" + Group2UID, LDAPGroupUID: Group2UID, Type: GroupPruned, RemovedUsers: []string{Member2UID, Member3UID}},
+	}
+	if !reflect.DeepEqual(expected, changes) {
+		t.Errorf("expected\n\t%#v\ngot\n\t%#v", expected, changes)
+	}
+	for _, action := range fakeClient.Actions() {
+		if action.GetVerb() != "get" {
+			t.Errorf("unexpected action: %v", action)
+		}
+	}
+}
+
+func TestExcludedGroups(t *testing.T) {
+	host := newTestHost()
+	existing := newDefaultOpenShiftGroups(host)
+	existing[1].Annotations[LDAPURLAnnotation] = "other.host:port"
+	fakeClient := &fakeuserv1client.FakeUserV1{Fake: &(fakeuserclient.NewSimpleClientset(existing[0], existing[1]).Fake)}
+
+	changes, err := ExcludedGroups(fakeClient.Groups(), []string{"os" + Group2UID, "missing", "os" + Group1UID}, host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []GroupChange{
+		{Name: "os" + Group1UID, LDAPGroupUID: Group1UID, Type: GroupExcluded},
+	}
+	if !reflect.DeepEqual(expected, changes) {
+		t.Errorf("expected\n\t%#v\ngot\n\t%#v", expected, changes)
+	}
+}
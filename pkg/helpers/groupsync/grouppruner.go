@@ -41,6 +41,32 @@ var _ GroupPruner = &LDAPGroupPruner{}
 
 // Prune allows the LDAPGroupPruner to be a GroupPruner
 func (s *LDAPGroupPruner) Prune() []error {
+	return s.visitOrphans(func(ldapGroupUID, groupName string) error {
+		if err := s.DeleteGroup(groupName); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(s.Out, "group/%s\n", groupName)
+		return nil
+	})
+}
+
+// DeleteGroup deletes an OpenShift group unless the pruner is a dry run. It lets a caller prune
+// exactly the groups returned by Orphans.
+func (s *LDAPGroupPruner) DeleteGroup(groupName string) error {
+	if s.DryRun {
+		return nil
+	}
+	if err := s.GroupClient.Delete(context.TODO(), groupName, metav1.DeleteOptions{}); err != nil {
+		fmt.Fprintf(s.Err, "Error pruning OpenShift group %q: %v.\n", groupName, err)
+		return err
+	}
+	return nil
+}
+
+// visitOrphans invokes fn for every group whose LDAP record no longer exists, and
+// returns the errors from determining those groups and from fn
+func (s *LDAPGroupPruner) visitOrphans(fn func(ldapGroupUID, groupName string) error) []error {
 	var errors []error
 
 	// determine what to sync
@@ -73,15 +99,9 @@ func (s *LDAPGroupPruner) Prune() []error {
 			continue
 		}
 
-		if !s.DryRun {
-			if err := s.GroupClient.Delete(context.TODO(), groupName, metav1.DeleteOptions{}); err != nil {
-				fmt.Fprintf(s.Err, "Error pruning OpenShift group %q: %v.\n", groupName, err)
-				errors = append(errors, err)
-				continue
-			}
+		if err := fn(ldapGroupUID, groupName); err != nil {
+			errors = append(errors, err)
 		}
-
-		fmt.Fprintf(s.Out, "group/%s\n", groupName)
 	}
 
 	return errors
@@ -0,0 +1,127 @@
+package keyring
+
+import (
+	"fmt"
+	"net/url"
+	"runtime"
+	"strings"
+
+	helperclient "github.com/docker/docker-credential-helpers/client"
+	"github.com/docker/docker-credential-helpers/credentials"
+
+	"k8s.io/client-go/pkg/apis/clientauthentication"
+	kclientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// TokenStoreKubeconfig stores bearer tokens in plaintext in the kubeconfig.
+	TokenStoreKubeconfig = "kubeconfig"
+	// TokenStoreKeyring stores bearer tokens in the keychain of the operating system,
+	// and the kubeconfig only refers to them.
+	TokenStoreKeyring = "keyring"
+)
+
+// TokenStores are the allowed values of --token-store.
+var TokenStores = []string{TokenStoreKubeconfig, TokenStoreKeyring}
+
+// DefaultHelper returns the name of the docker-credential-NAME helper that stores
+// credentials in the keychain of the operating system: the macOS Keychain, the
+// Windows Credential Manager or the Secret Service API implemented by libsecret.
+func DefaultHelper() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "osxkeychain"
+	case "windows":
+		return "wincred"
+	default:
+		return "secretservice"
+	}
+}
+
+// Store saves bearer tokens with a docker credential helper.
+type Store struct {
+	helper  string
+	program helperclient.ProgramFunc
+}
+
+// NewStore returns a store using the docker-credential-NAME helper.
+func NewStore(helper string) *Store {
+	return &Store{
+		helper:  helper,
+		program: helperclient.NewShellProgramFunc("docker-credential-" + helper),
+	}
+}
+
+// Key returns the key the token of the user on the server is stored under. Keys are
+// URLs because some helpers, like osxkeychain, store the credentials by URL.
+func Key(server, username string) string {
+	return strings.TrimSuffix(server, "/") + "/oc/" + url.PathEscape(username)
+}
+
+// Save stores the token of the user under the key.
+func (s *Store) Save(key, username, token string) error {
+	creds := &credentials.Credentials{
+		ServerURL: key,
+		Username:  username,
+		Secret:    token,
+	}
+	if err := helperclient.Store(s.program, creds); err != nil {
+		return fmt.Errorf("unable to store the token with docker-credential-%s: %v", s.helper, err)
+	}
+	return nil
+}
+
+// Get returns the token stored under the key.
+func (s *Store) Get(key string) (string, error) {
+	creds, err := helperclient.Get(s.program, key)
+	if err != nil {
+		if credentials.IsErrCredentialsNotFound(err) {
+			return "", fmt.Errorf("no token is stored for %s in docker-credential-%s, log in again", key, s.helper)
+		}
+		return "", fmt.Errorf("unable to retrieve the token with docker-credential-%s: %v", s.helper, err)
+	}
+	return creds.Secret, nil
+}
+
+// Erase removes the token stored under the key.
+func (s *Store) Erase(key string) error {
+	if err := helperclient.Erase(s.program, key); err != nil {
+		return fmt.Errorf("unable to erase the token with docker-credential-%s: %v", s.helper, err)
+	}
+	return nil
+}
+
+// ExecConfig returns the credentials exec plugin that retrieves the token stored
+// under the key, so that it is used transparently by every command.
+func ExecConfig(key string) *kclientcmdapi.ExecConfig {
+	return &kclientcmdapi.ExecConfig{
+		APIVersion: clientauthentication.GroupName + "/v1",
+		Command:    "oc",
+		Args: []string{
+			"get-token",
+			"--token-store=" + TokenStoreKeyring,
+			"--keyring-key=" + key,
+		},
+		InstallHint:     "Please be sure that oc is defined in $PATH to be executed as credentials exec plugin",
+		InteractiveMode: kclientcmdapi.NeverExecInteractiveMode,
+	}
+}
+
+// KeyFromExecConfig returns the key of the token retrieved by an exec plugin
+// returned by ExecConfig.
+func KeyFromExecConfig(exec *kclientcmdapi.ExecConfig) (string, bool) {
+	if exec == nil || exec.Command != "oc" || len(exec.Args) == 0 || exec.Args[0] != "get-token" {
+		return "", false
+	}
+	keyring := false
+	var key string
+	for _, arg := range exec.Args[1:] {
+		switch {
+		case arg == "--token-store="+TokenStoreKeyring:
+			keyring = true
+		case strings.HasPrefix(arg, "--keyring-key="):
+			key = strings.TrimPrefix(arg, "--keyring-key=")
+		}
+	}
+	return key, keyring && len(key) > 0
+}
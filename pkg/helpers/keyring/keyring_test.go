@@ -0,0 +1,102 @@
+package keyring
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	helperclient "github.com/docker/docker-credential-helpers/client"
+	"github.com/docker/docker-credential-helpers/credentials"
+)
+
+// fakeHelper implements the docker credential helper protocol in memory.
+type fakeHelper struct {
+	stored map[string]credentials.Credentials
+}
+
+type fakeProgram struct {
+	helper *fakeHelper
+	action string
+	input  []byte
+}
+
+func (h *fakeHelper) program(args ...string) helperclient.Program {
+	return &fakeProgram{helper: h, action: args[0]}
+}
+
+func (p *fakeProgram) Input(in io.Reader) {
+	p.input, _ = io.ReadAll(in)
+}
+
+func (p *fakeProgram) Output() ([]byte, error) {
+	switch p.action {
+	case credentials.ActionStore:
+		var creds credentials.Credentials
+		if err := json.Unmarshal(p.input, &creds); err != nil {
+			return nil, err
+		}
+		p.helper.stored[creds.ServerURL] = creds
+		return nil, nil
+	case credentials.ActionGet:
+		creds, ok := p.helper.stored[string(p.input)]
+		if !ok {
+			return []byte(credentials.NewErrCredentialsNotFound().Error()), errors.New("exit status 1")
+		}
+		return json.Marshal(creds)
+	case credentials.ActionErase:
+		if _, ok := p.helper.stored[string(p.input)]; !ok {
+			return []byte(credentials.NewErrCredentialsNotFound().Error()), errors.New("exit status 1")
+		}
+		delete(p.helper.stored, string(p.input))
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unexpected action %s", p.action)
+}
+
+func TestStore(t *testing.T) {
+	helper := &fakeHelper{stored: map[string]credentials.Credentials{}}
+	store := &Store{helper: "fake", program: helper.program}
+
+	key := Key("https://api.example.com:6443/", "kube:admin")
+	if key != "https://api.example.com:6443/oc/kube:admin" {
+		t.Errorf("unexpected key %s", key)
+	}
+	if err := store.Save(key, "kube:admin", "sha256~token"); err != nil {
+		t.Fatal(err)
+	}
+	if expected := (credentials.Credentials{ServerURL: key, Username: "kube:admin", Secret: "sha256~token"}); !reflect.DeepEqual(expected, helper.stored[key]) {
+		t.Errorf("unexpected stored credentials %#v", helper.stored[key])
+	}
+	token, err := store.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "sha256~token" {
+		t.Errorf("unexpected token %s", token)
+	}
+	if err := store.Erase(key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(key); err == nil || !bytes.Contains([]byte(err.Error()), []byte("log in again")) {
+		t.Errorf("expected a missing token error, got %v", err)
+	}
+}
+
+func TestKeyFromExecConfig(t *testing.T) {
+	key := Key("https://api.example.com:6443", "developer")
+	if actual, ok := KeyFromExecConfig(ExecConfig(key)); !ok || actual != key {
+		t.Errorf("expected %s, got %s %t", key, actual, ok)
+	}
+	oidcPlugin := ExecConfig(key)
+	oidcPlugin.Args = []string{"get-token", "--issuer-url=https://issuer.example.com", "--client-id=client"}
+	if _, ok := KeyFromExecConfig(oidcPlugin); ok {
+		t.Errorf("expected the oidc exec plugin not to use the keyring")
+	}
+	if _, ok := KeyFromExecConfig(nil); ok {
+		t.Errorf("expected no key without an exec plugin")
+	}
+}
@@ -198,8 +198,9 @@ func (r *SourceRef) BuildSource() (*buildv1.BuildSource, []buildv1.BuildTriggerP
 
 // BuildStrategyRef is a reference to a build strategy
 type BuildStrategyRef struct {
-	Strategy newapp.Strategy
-	Base     *ImageRef
+	Strategy       newapp.Strategy
+	Base           *ImageRef
+	DockerfilePath string
 }
 
 // BuildStrategy builds an OpenShift BuildStrategy from a BuildStrategyRef
@@ -216,7 +217,8 @@ func (s *BuildStrategyRef) BuildStrategy(env Environment, dockerStrategyOptions
 	case newapp.StrategyDocker:
 		var triggers []buildv1.BuildTriggerPolicy
 		strategy := &buildv1.DockerBuildStrategy{
-			Env: env.List(),
+			Env:            env.List(),
+			DockerfilePath: s.DockerfilePath,
 		}
 		if dockerStrategyOptions != nil {
 			strategy.BuildArgs = dockerStrategyOptions.BuildArgs
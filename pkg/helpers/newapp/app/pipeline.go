@@ -112,10 +112,13 @@ func (pb *pipelineBuilder) NewBuildPipeline(from string, input *ImageRef, source
 	}
 	source.Name = name
 
-	// Append any exposed ports from Dockerfile to input image
+	// Append any exposed ports from Dockerfile and devfile endpoints to input image
 	if sourceRepository.GetStrategy() == newapp.StrategyDocker && sourceRepository.Info() != nil {
 		node := sourceRepository.Info().Dockerfile.AST()
 		ports := dockerfile.LastExposedPorts(node)
+		if d := sourceRepository.Info().Devfile; d != nil {
+			ports = append(ports, d.Ports()...)
+		}
 		if len(ports) > 0 {
 			if input.Info == nil {
 				input.Info = &dockerv10.DockerImage{
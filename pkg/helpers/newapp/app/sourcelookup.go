@@ -1,9 +1,11 @@
 package app
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -21,6 +23,8 @@ import (
 	buildv1 "github.com/openshift/api/build/v1"
 	"github.com/openshift/library-go/pkg/git"
 	"github.com/openshift/oc/pkg/helpers/newapp"
+	"github.com/openshift/oc/pkg/helpers/newapp/devfile"
+	dockerfileutil "github.com/openshift/oc/pkg/helpers/newapp/docker/dockerfile"
 	"github.com/openshift/oc/pkg/helpers/newapp/source"
 )
 
@@ -114,6 +118,9 @@ type SourceRepository struct {
 	binary           bool
 
 	forceAddDockerfile bool
+	dockerfilePath     string
+
+	devfile *devfile.Devfile
 
 	requiresAuth bool
 }
@@ -231,6 +238,16 @@ func (r *SourceRepository) Detect(d Detector, dockerStrategy bool) error {
 	if err != nil {
 		return err
 	}
+	if r.devfile != nil {
+		r.info.Devfile = r.devfile
+	}
+	if len(r.dockerfilePath) > 0 {
+		dockerfile, err := NewDockerfileFromFile(filepath.Join(path, filepath.FromSlash(r.dockerfilePath)))
+		if err != nil {
+			return fmt.Errorf("unable to read the Containerfile %q of the repository %q: %v", r.dockerfilePath, r.location, err)
+		}
+		r.info.Dockerfile = dockerfile
+	}
 	if err = r.DetectAuth(); err != nil {
 		return err
 	}
@@ -396,6 +413,80 @@ func (r *SourceRepository) AddDockerfile(contents string) error {
 	return nil
 }
 
+// SetDockerfilePath sets the path of the Dockerfile to build, relative to the
+// context directory, and configures the SourceRepository to build with Docker strategy.
+func (r *SourceRepository) SetDockerfilePath(dockerfilePath string) {
+	r.dockerfilePath = dockerfilePath
+	r.SetStrategy(newapp.StrategyDocker)
+}
+
+// SetDockerfileTarget replaces the Dockerfile of the SourceRepository with its
+// contents up to the end of the build stage target, and adds those contents to
+// the build in place of the Dockerfile of the repository.
+func (r *SourceRepository) SetDockerfileTarget(target string) error {
+	if r.info == nil || r.info.Dockerfile == nil {
+		return fmt.Errorf("no Dockerfile was found in the repository %q to select the build stage %q from", r.location, target)
+	}
+	contents, err := dockerfileutil.TruncateToStage(r.info.Dockerfile.Contents(), r.info.Dockerfile.AST(), target)
+	if err != nil {
+		return err
+	}
+	r.dockerfilePath = ""
+	return r.AddDockerfile(contents)
+}
+
+// AddDevfile sets the devfile used to build the SourceRepository in place of
+// any devfile found in the repository.
+func (r *SourceRepository) AddDevfile(d *devfile.Devfile) {
+	r.devfile = d
+	if r.info != nil {
+		r.info.Devfile = d
+	}
+}
+
+// UseDevfile configures the SourceRepository to build the Dockerfile of the
+// image component of its devfile with the docker strategy. Dockerfiles that
+// are not part of the repository are added to the build by their contents.
+func (r *SourceRepository) UseDevfile() error {
+	if r.info == nil || r.info.Devfile == nil {
+		return fmt.Errorf("no devfile was found for the repository %q", r.location)
+	}
+	d := r.info.Devfile
+	component, err := d.BuildImage()
+	if err != nil {
+		return err
+	}
+	uri := component.Image.Dockerfile.URI
+	buildContext := component.Image.Dockerfile.BuildContext
+
+	if r.devfile != nil || d.IsRemote(uri) {
+		data, err := d.ReadFile(context.TODO(), uri)
+		if err != nil {
+			return err
+		}
+		if err := r.AddDockerfile(string(data)); err != nil {
+			return fmt.Errorf("the Dockerfile %q of devfile component %q is not valid: %v", uri, component.Name, err)
+		}
+	} else {
+		dockerfilePath := filepath.Join(filepath.Dir(d.Location), filepath.FromSlash(uri))
+		dockerfile, err := NewDockerfileFromFile(dockerfilePath)
+		if err != nil {
+			return fmt.Errorf("the Dockerfile %q of devfile component %q is not valid: %v", uri, component.Name, err)
+		}
+		rel, err := filepath.Rel(filepath.Join(r.info.Path, filepath.FromSlash(buildContext)), dockerfilePath)
+		if err != nil {
+			return err
+		}
+		r.info.Dockerfile = dockerfile
+		r.dockerfilePath = filepath.ToSlash(rel)
+		r.SetStrategy(newapp.StrategyDocker)
+	}
+	if len(buildContext) > 0 && !r.ignoreRepository {
+		r.contextDir = path.Clean(path.Join(r.contextDir, buildContext))
+	}
+	return nil
+}
+
 // AddBuildConfigMaps adds the defined configMaps into the build. The input format for
 // the secrets is "<secretName>:<destinationDir>". The destinationDir is
 // optional and when not specified the default is the current working directory.
@@ -498,6 +589,7 @@ type SourceRepositoryInfo struct {
 	Types       []SourceLanguageType
 	Dockerfile  Dockerfile
 	Jenkinsfile bool
+	Devfile     *devfile.Devfile
 }
 
 // Terms returns which languages the source repository was
@@ -537,6 +629,7 @@ type SourceRepositoryEnumerator struct {
 	Detectors         source.Detectors
 	DockerfileTester  newapp.Tester
 	JenkinsfileTester newapp.Tester
+	DevfileTester     newapp.Tester
 }
 
 // Detect extracts source code information about the provided source repository
@@ -567,6 +660,17 @@ func (e SourceRepositoryEnumerator) Detect(dir string, noSourceDetection bool) (
 	if _, ok, err := e.JenkinsfileTester.Has(dir); err == nil && ok {
 		info.Jenkinsfile = true
 	}
+	if e.DevfileTester != nil {
+		if path, ok, err := e.DevfileTester.Has(dir); err == nil && ok {
+			// a devfile that cannot be used, such as a version 1 devfile, leaves the
+			// repository to the Dockerfile and language detection
+			if d, err := devfile.Read(context.TODO(), path); err != nil {
+				klog.Warningf("Ignoring the devfile of the repository: %v", err)
+			} else {
+				info.Devfile = d
+			}
+		}
+	}
 
 	return info, nil
 }
@@ -577,8 +681,9 @@ func (e SourceRepositoryEnumerator) Detect(dir string, noSourceDetection bool) (
 // more info
 func StrategyAndSourceForRepository(repo *SourceRepository, image *ImageRef) (*BuildStrategyRef, *SourceRef, error) {
 	strategy := &BuildStrategyRef{
-		Base:     image,
-		Strategy: repo.strategy,
+		Base:           image,
+		Strategy:       repo.strategy,
+		DockerfilePath: repo.dockerfilePath,
 	}
 	source := &SourceRef{
 		Binary:       repo.binary,
@@ -1,9 +1,14 @@
 package app
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/openshift/oc/pkg/helpers/newapp"
+	"github.com/openshift/oc/pkg/helpers/newapp/devfile"
+	"github.com/openshift/oc/pkg/helpers/newapp/dockerfile"
+	"github.com/openshift/oc/pkg/helpers/newapp/jenkinsfile"
 )
 
 func TestAddBuildSecrets(t *testing.T) {
@@ -125,3 +130,100 @@ func TestAddBuildConfigMaps(t *testing.T) {
 		}
 	}
 }
+
+func TestUseDevfile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "app", "docker"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app", "docker", "Dockerfile"), []byte("FROM centos\nEXPOSE 8080\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	d, err := devfile.Parse([]byte(`
+schemaVersion: 2.2.0
+components:
+- name: build
+  image:
+    imageName: app:latest
+    dockerfile:
+      uri: app/docker/Dockerfile
+      buildContext: app
+`), filepath.Join(dir, "devfile.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := NewSourceRepository(dir, newapp.StrategySource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.SetInfo(&SourceRepositoryInfo{Path: dir, Devfile: d})
+	if err := repo.UseDevfile(); err != nil {
+		t.Fatal(err)
+	}
+	if repo.GetStrategy() != newapp.StrategyDocker {
+		t.Errorf("expected docker strategy, got %s", repo.GetStrategy())
+	}
+	if repo.dockerfilePath != "docker/Dockerfile" || repo.ContextDir() != "app" {
+		t.Errorf("unexpected dockerfile path %q and context dir %q", repo.dockerfilePath, repo.ContextDir())
+	}
+	if repo.Info().Dockerfile == nil || repo.forceAddDockerfile {
+		t.Errorf("expected the Dockerfile to be referenced from the repository")
+	}
+
+	// a devfile passed with --devfile adds the Dockerfile contents to the build
+	repo, err = NewSourceRepository(dir, newapp.StrategySource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.AddDevfile(d)
+	repo.SetInfo(&SourceRepositoryInfo{Path: dir, Devfile: d})
+	if err := repo.UseDevfile(); err != nil {
+		t.Fatal(err)
+	}
+	if !repo.forceAddDockerfile || len(repo.dockerfilePath) > 0 || repo.ContextDir() != "app" {
+		t.Errorf("expected the Dockerfile contents to be added to the build, got path %q and context dir %q", repo.dockerfilePath, repo.ContextDir())
+	}
+}
+
+func TestDetectUnusableDevfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		devfile string
+	}{
+		{
+			name:    "version 1 devfile",
+			devfile: "apiVersion: 1.0.0\nmetadata:\n  name: app\n",
+		},
+		{
+			name:    "malformed devfile",
+			devfile: "schemaVersion: [2.2.0\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "devfile.yaml"), []byte(test.devfile), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM centos\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			e := SourceRepositoryEnumerator{
+				DockerfileTester:  dockerfile.NewTester(),
+				JenkinsfileTester: jenkinsfile.NewTester(),
+				DevfileTester:     devfile.NewTester(),
+			}
+			info, err := e.Detect(dir, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Devfile != nil {
+				t.Errorf("expected the devfile to be ignored, got %#v", info.Devfile)
+			}
+			if info.Dockerfile == nil {
+				t.Errorf("expected the Dockerfile to be detected")
+			}
+		})
+	}
+}
@@ -43,6 +43,7 @@ import (
 	utilenv "github.com/openshift/oc/pkg/helpers/env"
 	"github.com/openshift/oc/pkg/helpers/newapp"
 	"github.com/openshift/oc/pkg/helpers/newapp/app"
+	"github.com/openshift/oc/pkg/helpers/newapp/devfile"
 	"github.com/openshift/oc/pkg/helpers/newapp/dockerfile"
 	"github.com/openshift/oc/pkg/helpers/newapp/jenkinsfile"
 	"github.com/openshift/oc/pkg/helpers/newapp/source"
@@ -81,6 +82,9 @@ type GenerationInputs struct {
 
 	OutputDocker  bool
 	Dockerfile    string
+	Containerfile string
+	Target        string
+	Devfile       string
 	ExpectToBuild bool
 	BinaryBuild   bool
 	ContextDir    string
@@ -201,6 +205,7 @@ func NewAppConfig() *AppConfig {
 				Detectors:         source.DefaultDetectors,
 				DockerfileTester:  dockerfile.NewTester(),
 				JenkinsfileTester: jenkinsfile.NewTester(),
+				DevfileTester:     devfile.NewTester(),
 			},
 		},
 		EnvironmentClassificationErrors: map[string]ArgumentClassificationError{},
@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	kutilerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -11,6 +13,7 @@ import (
 	"github.com/openshift/library-go/pkg/git"
 	"github.com/openshift/oc/pkg/helpers/newapp"
 	"github.com/openshift/oc/pkg/helpers/newapp/app"
+	"github.com/openshift/oc/pkg/helpers/newapp/devfile"
 	dockerfileutil "github.com/openshift/oc/pkg/helpers/newapp/docker/dockerfile"
 )
 
@@ -227,6 +230,28 @@ func AddSourceRepositoriesToRefBuilder(b *app.ReferenceBuilder, c *ComponentInpu
 			return nil, err
 		}
 	}
+	if len(g.Containerfile) > 0 {
+		if g.Strategy != newapp.StrategyUnspecified && g.Strategy != newapp.StrategyDocker {
+			return nil, errors.New("when referencing a Containerfile, the strategy must be 'docker'")
+		}
+		if len(g.Dockerfile) > 0 {
+			return nil, errors.New("--containerfile and --dockerfile may not be used together")
+		}
+		if err := AddContainerfileToSourceRepositories(b, g.Containerfile, g.BinaryBuild); err != nil {
+			return nil, err
+		}
+	}
+	if len(g.Devfile) > 0 {
+		if g.Strategy != newapp.StrategyUnspecified && g.Strategy != newapp.StrategyDocker {
+			return nil, errors.New("when directly referencing a devfile, the strategy must be 'docker'")
+		}
+		if len(g.Dockerfile) > 0 || len(g.Containerfile) > 0 {
+			return nil, errors.New("--devfile may not be used with --dockerfile or --containerfile")
+		}
+		if err := AddDevfileToSourceRepositories(b, g.Devfile, g.ContextDir); err != nil {
+			return nil, err
+		}
+	}
 	_, result, errs := b.Result()
 	return result, kutilerrors.NewAggregate(errs)
 }
@@ -260,6 +285,74 @@ func AddDockerfileToSourceRepositories(b *app.ReferenceBuilder, dockerfile strin
 	return nil
 }
 
+// AddContainerfileToSourceRepositories sets the path of the Containerfile built by
+// the source repository of the reference builder. When no source repository was
+// provided, the local Containerfile is built from its contents, or is expected in
+// the binary input for binary builds.
+func AddContainerfileToSourceRepositories(b *app.ReferenceBuilder, containerfile string, binary bool) error {
+	_, repos, errs := b.Result()
+	if err := kutilerrors.NewAggregate(errs); err != nil {
+		return err
+	}
+	switch len(repos) {
+	case 0:
+		dockerfile, err := app.NewDockerfileFromFile(containerfile)
+		if err != nil {
+			return fmt.Errorf("provided Containerfile is not valid: %v", err)
+		}
+		if !binary {
+			repo, err := app.NewSourceRepositoryForDockerfile(dockerfile.Contents())
+			if err != nil {
+				return fmt.Errorf("provided Containerfile is not valid: %v", err)
+			}
+			b.AddExistingSourceRepository(repo)
+			return nil
+		}
+		repo := app.NewBinarySourceRepository(newapp.StrategyDocker)
+		repo.SetInfo(&app.SourceRepositoryInfo{Dockerfile: dockerfile})
+		repo.SetDockerfilePath(filepath.ToSlash(filepath.Base(containerfile)))
+		b.AddExistingSourceRepository(repo)
+	case 1:
+		repos[0].SetDockerfilePath(filepath.ToSlash(filepath.Clean(containerfile)))
+	default:
+		return errors.New("--containerfile cannot be used with multiple source repositories")
+	}
+	return nil
+}
+
+// AddDevfileToSourceRepositories adds the devfile at the given path or URL to the
+// source repository of the reference builder. When no source repository was provided,
+// the git project of the devfile is used.
+func AddDevfileToSourceRepositories(b *app.ReferenceBuilder, location, contextDir string) error {
+	d, err := devfile.Read(context.TODO(), location)
+	if err != nil {
+		return err
+	}
+	_, repos, errs := b.Result()
+	if err := kutilerrors.NewAggregate(errs); err != nil {
+		return err
+	}
+	switch len(repos) {
+	case 0:
+		remote, ok := d.GitRemote()
+		if !ok {
+			return fmt.Errorf("devfile %q has no git project, you must specify the source repository to build", location)
+		}
+		repo, err := app.NewSourceRepository(remote, newapp.StrategyDocker)
+		if err != nil {
+			return fmt.Errorf("the git project %q of devfile %q is not valid: %v", remote, location, err)
+		}
+		repo.SetContextDir(contextDir)
+		repo.AddDevfile(d)
+		b.AddExistingSourceRepository(repo)
+	case 1:
+		repos[0].AddDevfile(d)
+	default:
+		return errors.New("--devfile cannot be used with multiple source repositories")
+	}
+	return nil
+}
+
 // DetectSource runs a code detector on the passed in repositories to obtain a SourceRepositoryInfo
 func DetectSource(repositories []*app.SourceRepository, d app.Detector, g *GenerationInputs) error {
 	errs := []error{}
@@ -269,9 +362,15 @@ func DetectSource(repositories []*app.SourceRepository, d app.Detector, g *Gener
 			errs = append(errs, err)
 			continue
 		}
+		if len(g.Target) > 0 && repo.Info().Devfile == nil {
+			if err := repo.SetDockerfileTarget(g.Target); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
 		switch g.Strategy {
 		case newapp.StrategyDocker:
-			if repo.Info().Dockerfile == nil {
+			if repo.Info().Dockerfile == nil && repo.Info().Devfile == nil {
 				errs = append(errs, errors.New("No Dockerfile was found in the repository and the requested build strategy is 'docker'"))
 			}
 		case newapp.StrategyPipeline:
@@ -279,7 +378,7 @@ func DetectSource(repositories []*app.SourceRepository, d app.Detector, g *Gener
 				errs = append(errs, errors.New("No Jenkinsfile was found in the repository and the requested build strategy is 'pipeline'"))
 			}
 		default:
-			if repo.Info().Dockerfile == nil && !repo.Info().Jenkinsfile && len(repo.Info().Types) == 0 {
+			if repo.Info().Dockerfile == nil && repo.Info().Devfile == nil && !repo.Info().Jenkinsfile && len(repo.Info().Types) == 0 {
 				errs = append(errs, errors.New("No language matched the source repository"))
 			}
 		}
@@ -555,21 +654,30 @@ func AddMissingComponentsToRefBuilder(
 			})
 			result = append(result, refs...)
 
+		case info.Devfile != nil && (g.Strategy == newapp.StrategyUnspecified || g.Strategy == newapp.StrategyDocker) && buildDevfile(info, g.Strategy):
+			if err := repo.UseDevfile(); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if len(g.Target) > 0 {
+				if err := repo.SetDockerfileTarget(g.Target); err != nil {
+					errs = append(errs, err)
+					continue
+				}
+			}
+			refs, err := addDockerfileComponent(b, repo, dockerfileResolver)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			result = append(result, refs...)
+
 		case info.Dockerfile != nil && (g.Strategy == newapp.StrategyUnspecified || g.Strategy == newapp.StrategyDocker):
-			node := info.Dockerfile.AST()
-			baseImage := dockerfileutil.LastBaseImage(node)
-			if baseImage == "" {
-				errs = append(errs, fmt.Errorf("the Dockerfile in the repository %q has no FROM instruction", info.Path))
+			refs, err := addDockerfileComponent(b, repo, dockerfileResolver)
+			if err != nil {
+				errs = append(errs, err)
 				continue
 			}
-			refs := b.AddComponents([]string{baseImage}, func(input *app.ComponentInput) app.ComponentReference {
-				input.Resolver = dockerfileResolver
-				input.Use(repo)
-				input.ExpectToBuild = true
-				repo.UsedBy(input)
-				repo.SetStrategy(newapp.StrategyDocker)
-				return input
-			})
 			result = append(result, refs...)
 
 		default:
@@ -591,3 +699,36 @@ func AddMissingComponentsToRefBuilder(
 	}
 	return result, kutilerrors.NewAggregate(errs)
 }
+
+// buildDevfile returns true when the repository should be built from its devfile,
+// that is when the devfile has an image component to build, or when there is no
+// Dockerfile or detected language to fall back to, in which case building the
+// devfile reports why it cannot be used.
+func buildDevfile(info *app.SourceRepositoryInfo, strategy newapp.Strategy) bool {
+	if _, err := info.Devfile.BuildImage(); err == nil {
+		return true
+	}
+	if info.Dockerfile != nil {
+		return false
+	}
+	return strategy == newapp.StrategyDocker || len(info.Types) == 0
+}
+
+// addDockerfileComponent adds a component for the base image of the Dockerfile of
+// the repository, built with the docker strategy.
+func addDockerfileComponent(b *app.ReferenceBuilder, repo *app.SourceRepository, dockerfileResolver app.Resolver) (app.ComponentReferences, error) {
+	info := repo.Info()
+	node := info.Dockerfile.AST()
+	baseImage := dockerfileutil.LastBaseImage(node)
+	if baseImage == "" {
+		return nil, fmt.Errorf("the Dockerfile in the repository %q has no FROM instruction", info.Path)
+	}
+	return b.AddComponents([]string{baseImage}, func(input *app.ComponentInput) app.ComponentReference {
+		input.Resolver = dockerfileResolver
+		input.Use(repo)
+		input.ExpectToBuild = true
+		repo.UsedBy(input)
+		repo.SetStrategy(newapp.StrategyDocker)
+		return input
+	}), nil
+}
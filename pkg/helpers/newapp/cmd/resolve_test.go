@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/openshift/oc/pkg/helpers/newapp"
 	"github.com/openshift/oc/pkg/helpers/newapp/app"
+	"github.com/openshift/oc/pkg/helpers/newapp/devfile"
 )
 
 // TestResolveJenkinsfileAndDockerfile ensures that if a repo has a Jenkinsfile
@@ -54,6 +58,99 @@ func TestResolveDockerfileAndSource(t *testing.T) {
 	checkResolveResult(t, componentrefs, err, newapp.StrategyDocker)
 }
 
+// TestResolveDevfileWithoutBuildImage ensures that a repository whose devfile has
+// only container components is built from its Dockerfile or detected language.
+func TestResolveDevfileWithoutBuildImage(t *testing.T) {
+	d, err := devfile.Parse([]byte("schemaVersion: 2.2.0\ncomponents:\n- name: runtime\n  container:\n    image: registry.access.redhat.com/ubi9/nodejs-18\n"), "devfile.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dockerfile, _ := app.NewDockerfile("FROM centos\n")
+
+	tests := []struct {
+		name          string
+		info          app.SourceRepositoryInfo
+		strategy      newapp.Strategy
+		expected      newapp.Strategy
+		expectedError string
+	}{
+		{
+			name:     "dockerfile",
+			info:     app.SourceRepositoryInfo{Devfile: d, Dockerfile: dockerfile},
+			expected: newapp.StrategyDocker,
+		},
+		{
+			name: "detected language",
+			info: app.SourceRepositoryInfo{Devfile: d, Types: []app.SourceLanguageType{{Platform: "nodejs"}}},
+			// the source strategy is only set once the builder image is resolved
+			expected: newapp.StrategyUnspecified,
+		},
+		{
+			name:          "docker strategy without dockerfile",
+			info:          app.SourceRepositoryInfo{Devfile: d, Types: []app.SourceLanguageType{{Platform: "nodejs"}}},
+			strategy:      newapp.StrategyDocker,
+			expectedError: "has no image component with a Dockerfile to build",
+		},
+		{
+			name:          "nothing to fall back to",
+			info:          app.SourceRepositoryInfo{Devfile: d},
+			expectedError: "has no image component with a Dockerfile to build",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo := app.SourceRepository{}
+			repo.SetInfo(&test.info)
+			resolvers := Resolvers{}
+			componentrefs, err := AddMissingComponentsToRefBuilder(&app.ReferenceBuilder{}, app.SourceRepositories{&repo}, resolvers.DockerfileResolver(), resolvers.SourceResolver(), resolvers.PipelineResolver(), &GenerationInputs{Strategy: test.strategy})
+			if len(test.expectedError) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+					t.Fatalf("expected error %q, got %v", test.expectedError, err)
+				}
+				return
+			}
+			checkResolveResult(t, componentrefs, err, test.expected)
+		})
+	}
+}
+
+// TestResolveContainerfileTarget ensures that a binary build of a local
+// Containerfile builds the selected stage of the Containerfile.
+func TestResolveContainerfileTarget(t *testing.T) {
+	containerfile := filepath.Join(t.TempDir(), "Containerfile")
+	if err := os.WriteFile(containerfile, []byte("FROM golang AS builder\nRUN make\nFROM ubi8\nCOPY --from=builder /app /app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &app.ReferenceBuilder{}
+	if err := AddContainerfileToSourceRepositories(b, containerfile, true); err != nil {
+		t.Fatal(err)
+	}
+	_, repositories, _ := b.Result()
+	if len(repositories) != 1 {
+		t.Fatalf("expected a single source repository, got %d", len(repositories))
+	}
+	g := &GenerationInputs{BinaryBuild: true, Target: "builder"}
+	if err := DetectSource(repositories, nil, g); err != nil {
+		t.Fatal(err)
+	}
+
+	resolvers := Resolvers{}
+	componentrefs, err := AddMissingComponentsToRefBuilder(b, repositories, resolvers.DockerfileResolver(), resolvers.SourceResolver(), resolvers.PipelineResolver(), g)
+	checkResolveResult(t, componentrefs, err, newapp.StrategyDocker)
+	if from := componentrefs[0].Input().From; from != "golang" {
+		t.Errorf("expected the base image of the builder stage, got %q", from)
+	}
+
+	strategy, source, err := app.StrategyAndSourceForRepository(repositories[0], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !source.Binary || len(strategy.DockerfilePath) > 0 || source.DockerfileContents != "FROM golang AS builder\nRUN make\n" {
+		t.Errorf("unexpected build source %#v and strategy %#v", source, strategy)
+	}
+}
+
 func TestBinaryContentFlagGeneratesDummySource(t *testing.T) {
 	component := app.ComponentInput{
 		Value:    "foo",
@@ -0,0 +1,260 @@
+package devfile
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc/pkg/helpers/newapp"
+)
+
+// Names are the file names a devfile is looked up under in a source repository, in order.
+var Names = []string{"devfile.yaml", ".devfile.yaml", "devfile.yml", ".devfile.yml"}
+
+// Devfile is the subset of a version 2 devfile used to generate build and
+// deployment resources.
+type Devfile struct {
+	SchemaVersion string      `json:"schemaVersion"`
+	Metadata      Metadata    `json:"metadata,omitempty"`
+	Components    []Component `json:"components,omitempty"`
+	Projects      []Project   `json:"projects,omitempty"`
+
+	// Location is the path or URL the devfile was read from. Relative URIs in
+	// the devfile are resolved against it.
+	Location string `json:"-"`
+}
+
+// Metadata describes the devfile.
+type Metadata struct {
+	Name string `json:"name,omitempty"`
+}
+
+// Component is a devfile component. Only container and image components are used.
+type Component struct {
+	Name      string     `json:"name"`
+	Container *Container `json:"container,omitempty"`
+	Image     *Image     `json:"image,omitempty"`
+}
+
+// Container is a container component, whose endpoints are exposed by the application.
+type Container struct {
+	Image     string     `json:"image"`
+	Endpoints []Endpoint `json:"endpoints,omitempty"`
+}
+
+// Endpoint is a port exposed by a container component.
+type Endpoint struct {
+	Name       string `json:"name"`
+	TargetPort int    `json:"targetPort"`
+	Protocol   string `json:"protocol,omitempty"`
+}
+
+// Image is an image component, built from a Dockerfile.
+type Image struct {
+	ImageName  string           `json:"imageName"`
+	Dockerfile *DockerfileImage `json:"dockerfile,omitempty"`
+}
+
+// DockerfileImage locates the Dockerfile of an image component.
+type DockerfileImage struct {
+	URI          string `json:"uri,omitempty"`
+	BuildContext string `json:"buildContext,omitempty"`
+}
+
+// Project is a source project checked out for the devfile.
+type Project struct {
+	Name string      `json:"name"`
+	Git  *GitProject `json:"git,omitempty"`
+}
+
+// GitProject locates the git repository of a project.
+type GitProject struct {
+	Remotes      map[string]string `json:"remotes"`
+	CheckoutFrom *CheckoutFrom     `json:"checkoutFrom,omitempty"`
+}
+
+// CheckoutFrom selects the remote and revision of a git project.
+type CheckoutFrom struct {
+	Remote   string `json:"remote,omitempty"`
+	Revision string `json:"revision,omitempty"`
+}
+
+// Parse parses the contents of a devfile read from location.
+func Parse(data []byte, location string) (*Devfile, error) {
+	d := &Devfile{}
+	if err := yaml.Unmarshal(data, d); err != nil {
+		return nil, fmt.Errorf("unable to parse devfile %q: %v", location, err)
+	}
+	if !strings.HasPrefix(d.SchemaVersion, "2.") {
+		return nil, fmt.Errorf("devfile %q has schemaVersion %q, only version 2 devfiles are supported", location, d.SchemaVersion)
+	}
+	d.Location = location
+	return d, nil
+}
+
+// Read reads and parses the devfile at the given path or http(s) URL.
+func Read(ctx context.Context, location string) (*Devfile, error) {
+	data, err := readLocation(ctx, location)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read devfile %q: %v", location, err)
+	}
+	return Parse(data, location)
+}
+
+// BuildImage returns the first image component that builds a Dockerfile.
+func (d *Devfile) BuildImage() (*Component, error) {
+	for i := range d.Components {
+		c := &d.Components[i]
+		if c.Image != nil && c.Image.Dockerfile != nil && len(c.Image.Dockerfile.URI) > 0 {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("devfile %q has no image component with a Dockerfile to build", d.Location)
+}
+
+// Ports returns the target ports of the endpoints of all container components
+// in the form used by a Dockerfile EXPOSE instruction.
+func (d *Devfile) Ports() []string {
+	var ports []string
+	seen := map[string]bool{}
+	for _, c := range d.Components {
+		if c.Container == nil {
+			continue
+		}
+		for _, e := range c.Container.Endpoints {
+			if e.TargetPort <= 0 {
+				continue
+			}
+			port := strconv.Itoa(e.TargetPort)
+			if e.Protocol == "udp" {
+				port += "/udp"
+			}
+			if !seen[port] {
+				seen[port] = true
+				ports = append(ports, port)
+			}
+		}
+	}
+	return ports
+}
+
+// GitRemote returns the git URL, including the revision as a fragment, of the
+// first git project in the devfile.
+func (d *Devfile) GitRemote() (string, bool) {
+	for _, p := range d.Projects {
+		if p.Git == nil || len(p.Git.Remotes) == 0 {
+			continue
+		}
+		var remote, revision string
+		if p.Git.CheckoutFrom != nil {
+			remote = p.Git.CheckoutFrom.Remote
+			revision = p.Git.CheckoutFrom.Revision
+		}
+		switch {
+		case len(remote) > 0:
+		case len(p.Git.Remotes) == 1:
+			for name := range p.Git.Remotes {
+				remote = name
+			}
+		default:
+			remote = "origin"
+		}
+		u, ok := p.Git.Remotes[remote]
+		if !ok {
+			continue
+		}
+		if len(revision) > 0 {
+			u = fmt.Sprintf("%s#%s", u, revision)
+		}
+		return u, true
+	}
+	return "", false
+}
+
+// IsRemote returns true if the devfile was read from, or uri refers to, an http(s) URL.
+func (d *Devfile) IsRemote(uri string) bool {
+	return isURL(uri) || isURL(d.Location)
+}
+
+// ReadFile reads a file referenced by the devfile, resolving a relative uri
+// against the location of the devfile.
+func (d *Devfile) ReadFile(ctx context.Context, uri string) ([]byte, error) {
+	location := uri
+	switch {
+	case isURL(uri):
+	case isURL(d.Location):
+		base, err := url.Parse(d.Location)
+		if err != nil {
+			return nil, err
+		}
+		ref, err := url.Parse(uri)
+		if err != nil {
+			return nil, err
+		}
+		location = base.ResolveReference(ref).String()
+	case !filepath.IsAbs(uri):
+		location = filepath.Join(filepath.Dir(d.Location), filepath.FromSlash(uri))
+	}
+	data, err := readLocation(ctx, location)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %q referenced by devfile %q: %v", uri, d.Location, err)
+	}
+	return data, nil
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// httpClient fetches the devfiles and Dockerfiles referenced by URL.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+func readLocation(ctx context.Context, location string) ([]byte, error) {
+	if !isURL(location) {
+		return os.ReadFile(location)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+type tester bool
+
+func (t tester) Has(dir string) (string, bool, error) {
+	for _, name := range Names {
+		path := filepath.Join(dir, name)
+		_, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", false, err
+		}
+		return path, true, nil
+	}
+	return "", false, nil
+}
+
+// NewTester returns a tester that finds a devfile in a directory.
+func NewTester() newapp.Tester {
+	return tester(true)
+}
@@ -0,0 +1,140 @@
+package devfile
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testDevfile = `
+schemaVersion: 2.2.0
+metadata:
+  name: nodejs
+projects:
+- name: nodejs-starter
+  git:
+    remotes:
+      upstream: https://github.com/example/nodejs-starter.git
+    checkoutFrom:
+      revision: main
+components:
+- name: runtime
+  container:
+    image: registry.access.redhat.com/ubi8/nodejs-16:latest
+    endpoints:
+    - name: http
+      targetPort: 3000
+    - name: metrics
+      targetPort: 9090
+      protocol: udp
+- name: build
+  image:
+    imageName: nodejs-image:latest
+    dockerfile:
+      uri: docker/Dockerfile
+      buildContext: .
+`
+
+func TestParse(t *testing.T) {
+	d, err := Parse([]byte(testDevfile), "devfile.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Metadata.Name != "nodejs" || d.Location != "devfile.yaml" {
+		t.Errorf("unexpected devfile: %#v", d)
+	}
+	c, err := d.BuildImage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "build" || c.Image.Dockerfile.URI != "docker/Dockerfile" {
+		t.Errorf("unexpected image component: %#v", c)
+	}
+	if ports := d.Ports(); !reflect.DeepEqual(ports, []string{"3000", "9090/udp"}) {
+		t.Errorf("unexpected ports: %v", ports)
+	}
+	if remote, ok := d.GitRemote(); !ok || remote != "https://github.com/example/nodejs-starter.git#main" {
+		t.Errorf("unexpected git remote: %s %t", remote, ok)
+	}
+
+	if _, err := Parse([]byte("schemaVersion: 1.0.0\n"), "devfile.yaml"); err == nil {
+		t.Errorf("expected an error for a version 1 devfile")
+	}
+	d, err = Parse([]byte("schemaVersion: 2.1.0\n"), "devfile.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.BuildImage(); err == nil {
+		t.Errorf("expected an error for a devfile without an image component")
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "docker"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "docker", "Dockerfile"), []byte("FROM scratch\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".devfile.yaml"), []byte(testDevfile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, ok, err := NewTester().Has(dir)
+	if err != nil || !ok {
+		t.Fatalf("expected a devfile in %s: %v", dir, err)
+	}
+	if path != filepath.Join(dir, ".devfile.yaml") {
+		t.Errorf("unexpected devfile path: %s", path)
+	}
+	d, err := Read(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.IsRemote("docker/Dockerfile") {
+		t.Errorf("local devfile reported as remote")
+	}
+	data, err := d.ReadFile(context.Background(), "docker/Dockerfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "FROM scratch\n" {
+		t.Errorf("unexpected contents: %q", string(data))
+	}
+
+	if _, ok, err := NewTester().Has(filepath.Join(dir, "docker")); ok || err != nil {
+		t.Errorf("unexpected devfile in %s: %v", filepath.Join(dir, "docker"), err)
+	}
+}
+
+func TestReadURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/devfile.yaml":
+			w.Write([]byte(testDevfile))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	d, err := Read(context.Background(), server.URL+"/devfile.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.ReadFile(context.Background(), "docker/Dockerfile"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected the missing Dockerfile to be reported, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Read(ctx, server.URL+"/devfile.yaml"); err == nil || !strings.Contains(err.Error(), "context canceled") {
+		t.Errorf("expected the request to be canceled, got %v", err)
+	}
+}
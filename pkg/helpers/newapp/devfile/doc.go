@@ -0,0 +1,2 @@
+// Package devfile provides utilities for finding and parsing devfiles
+package devfile
@@ -220,3 +220,26 @@ func evalVars(n *parser.Node, from, to int, ports []string, shlex *ShellLex) []s
 	}
 	return evaledPorts
 }
+
+// TruncateToStage takes the contents of a Dockerfile and its root node and
+// returns the contents up to the end of the build stage named target, so that
+// the last image built by the result is the image of that stage.
+func TruncateToStage(contents string, node *parser.Node, target string) (string, error) {
+	froms := FindAll(node, command.From)
+	for i, pos := range froms {
+		args := nextValues(node.Children[pos])
+		if len(args) != 3 || !strings.EqualFold(args[1], "as") || !strings.EqualFold(args[2], target) {
+			continue
+		}
+		if i == len(froms)-1 {
+			return contents, nil
+		}
+		lines := strings.SplitAfter(contents, "\n")
+		end := node.Children[froms[i+1]].StartLine - 1
+		if end < 0 || end > len(lines) {
+			return "", fmt.Errorf("unable to locate the end of build stage %q", target)
+		}
+		return strings.Join(lines[:end], ""), nil
+	}
+	return "", fmt.Errorf("the Dockerfile has no build stage named %q", target)
+}
@@ -555,3 +555,64 @@ func TestNextValuesNilNode(t *testing.T) {
 		t.Errorf("nextValues(nil) = %#v; want nil", got)
 	}
 }
+
+// TestTruncateToStage tests calling TruncateToStage with multi-stage Dockerfiles.
+func TestTruncateToStage(t *testing.T) {
+	contents := `FROM golang AS builder
+RUN make
+
+# runtime image
+FROM ubi8 as runtime
+COPY --from=builder /bin/app /bin/app
+
+FROM runtime AS test
+RUN make test
+`
+	node, err := parser.Parse(strings.NewReader(contents))
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	testCases := map[string]struct {
+		target  string
+		want    string
+		wantErr bool
+	}{
+		"first stage": {
+			target: "builder",
+			want: `FROM golang AS builder
+RUN make
+
+# runtime image
+`,
+		},
+		"middle stage with lower case as": {
+			target: "runtime",
+			want: `FROM golang AS builder
+RUN make
+
+# runtime image
+FROM ubi8 as runtime
+COPY --from=builder /bin/app /bin/app
+
+`,
+		},
+		"last stage": {
+			target: "test",
+			want:   contents,
+		},
+		"unknown stage": {
+			target:  "debug",
+			wantErr: true,
+		},
+	}
+	for name, tc := range testCases {
+		got, err := TruncateToStage(contents, node.AST, tc.target)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: TruncateToStage() = %q; want %q", name, got, tc.want)
+		}
+	}
+}
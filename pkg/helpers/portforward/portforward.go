@@ -0,0 +1,371 @@
+// Package portforward forwards local ports to the pods selected by a resource, following the
+// resource to new pods when the pods forwarded to go away.
+package portforward
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+	"k8s.io/kubectl/pkg/scheme"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+)
+
+// Target describes the local ports forwarded to a pod once they are listening.
+type Target struct {
+	Namespace string   `json:"namespace"`
+	Pod       string   `json:"pod"`
+	Addresses []string `json:"addresses"`
+	Ports     []Port   `json:"ports"`
+}
+
+// Port is a local port forwarded to a port of a pod.
+type Port struct {
+	Local  uint16 `json:"local"`
+	Remote uint16 `json:"remote"`
+}
+
+// Forwarder forwards local ports to the pods returned by SelectPods.
+type Forwarder struct {
+	Namespace string
+	Addresses []string
+	// Ports are the [LOCAL_PORT:]REMOTE_PORT forwarded, with numeric remote ports
+	Ports []string
+	// SelectPods returns the running pods the ports can be forwarded to
+	SelectPods func(ctx context.Context) ([]corev1.Pod, error)
+	// AllPods forwards the ports to every pod selected, each on automatically allocated local
+	// ports, instead of the first one
+	AllPods bool
+	// Retry selects new pods to forward the ports to when the forwarding to a pod ends, every
+	// RetryInterval
+	Retry         bool
+	RetryInterval time.Duration
+	// JSON prints every target on a line in JSON once its ports are listening
+	JSON bool
+
+	Config     *restclient.Config
+	RESTClient restclient.Interface
+	PodClient  corev1client.PodsGetter
+
+	genericiooptions.IOStreams
+
+	printLock sync.Mutex
+	// last are the ports of the last pod forwarded to
+	last []Port
+}
+
+type targetEnded struct {
+	pod string
+	err error
+}
+
+// Run forwards the ports until the context is done or, without Retry, the forwarding to a
+// pod ends.
+func (f *Forwarder) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	ports := f.Ports
+	if f.AllPods {
+		ports = AllocatedPorts(f.Ports)
+	}
+	var retry <-chan time.Time
+	if f.Retry {
+		ticker := time.NewTicker(f.RetryInterval)
+		defer ticker.Stop()
+		retry = ticker.C
+	}
+
+	active := map[string]bool{}
+	ended := make(chan targetEnded)
+	reselect := true
+	for {
+		if reselect && (f.AllPods || len(active) == 0) {
+			pods, err := f.SelectPods(ctx)
+			if err == nil && len(pods) == 0 {
+				err = fmt.Errorf("no running pods to forward the ports to")
+			}
+			if err != nil {
+				if !f.Retry {
+					return err
+				}
+				fmt.Fprintf(f.ErrOut, "error: %v, retrying in %s\n", err, f.RetryInterval)
+			}
+			for _, pod := range pods {
+				if !f.AllPods && len(active) > 0 {
+					break
+				}
+				if active[pod.Name] {
+					continue
+				}
+				active[pod.Name] = true
+				wg.Add(1)
+				go func(pod corev1.Pod, ports []string) {
+					defer wg.Done()
+					err := f.forward(ctx, pod, ports)
+					select {
+					case ended <- targetEnded{pod: pod.Name, err: err}:
+					case <-ctx.Done():
+					}
+				}(pod, ports)
+			}
+		}
+		reselect = false
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case e := <-ended:
+			delete(active, e.pod)
+			if !f.Retry {
+				return e.err
+			}
+			fmt.Fprintf(f.ErrOut, "Forwarding to pod %s ended: %v\n", e.pod, e.err)
+		case <-retry:
+			reselect = true
+		}
+		if !f.AllPods && len(f.lastPorts()) > 0 {
+			// the new pod is reached on the same local ports
+			ports = f.lastPorts()
+		}
+	}
+}
+
+// forward forwards the ports to the pod until the context is done, the connection to the
+// pod is lost or the pod stops running.
+func (f *Forwarder) forward(ctx context.Context, pod corev1.Pod, ports []string) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	go f.watchPod(ctx, pod, cancel)
+
+	req := f.RESTClient.Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("portforward")
+	dialer, err := f.dialer(req.URL())
+	if err != nil {
+		return err
+	}
+	out := f.Out
+	if f.JSON || f.AllPods {
+		// the listening ports are printed once they are known
+		out = io.Discard
+	}
+	stopCh, readyCh := make(chan struct{}), make(chan struct{})
+	forwarder, err := portforward.NewOnAddresses(dialer, f.Addresses, ports, stopCh, readyCh, out, f.ErrOut)
+	if err != nil {
+		return err
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- forwarder.ForwardPorts()
+	}()
+
+	for {
+		select {
+		case <-readyCh:
+			readyCh = nil
+			forwarded, err := forwarder.GetPorts()
+			if err != nil {
+				close(stopCh)
+				<-errCh
+				return err
+			}
+			f.ready(pod, forwarded)
+		case err := <-errCh:
+			return err
+		case <-ctx.Done():
+			close(stopCh)
+			<-errCh
+			return context.Cause(ctx)
+		}
+	}
+}
+
+func (f *Forwarder) dialer(url *url.URL) (httpstream.Dialer, error) {
+	transport, upgrader, err := spdy.RoundTripperFor(f.Config)
+	if err != nil {
+		return nil, err
+	}
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+	if !kcmdutil.PortForwardWebsockets.IsDisabled() {
+		tunnelingDialer, err := portforward.NewSPDYOverWebsocketDialer(url, f.Config)
+		if err != nil {
+			return nil, err
+		}
+		// like kubectl, first attempt tunneling over websockets, then fall back to spdy
+		dialer = portforward.NewFallbackDialer(tunnelingDialer, dialer, func(err error) bool {
+			return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
+		})
+	}
+	return dialer, nil
+}
+
+// watchPod cancels the forwarding once the pod is deleted or stops running.
+func (f *Forwarder) watchPod(ctx context.Context, pod corev1.Pod, cancel context.CancelCauseFunc) {
+	resourceVersion := pod.ResourceVersion
+	for ctx.Err() == nil {
+		w, err := f.PodClient.Pods(pod.Namespace).Watch(ctx, metav1.ListOptions{
+			FieldSelector:   fields.OneTermEqualSelector("metadata.name", pod.Name).String(),
+			ResourceVersion: resourceVersion,
+		})
+		if err != nil {
+			// the connection to the pod ends the forwarding if the pod goes away meanwhile
+			select {
+			case <-ctx.Done():
+			case <-time.After(5 * time.Second):
+			}
+			resourceVersion = ""
+			continue
+		}
+		for event := range w.ResultChan() {
+			switch event.Type {
+			case watch.Deleted:
+				cancel(fmt.Errorf("pod %s was deleted", pod.Name))
+			case watch.Added, watch.Modified:
+				current, ok := event.Object.(*corev1.Pod)
+				if !ok {
+					continue
+				}
+				resourceVersion = current.ResourceVersion
+				if current.DeletionTimestamp != nil || current.Status.Phase != corev1.PodRunning {
+					cancel(fmt.Errorf("pod %s is no longer running", pod.Name))
+				}
+			case watch.Error:
+				// watch from the current state again
+				resourceVersion = ""
+			}
+		}
+		w.Stop()
+	}
+}
+
+// lastPorts returns the ports forwarded to the last pod, with their local ports.
+func (f *Forwarder) lastPorts() []string {
+	f.printLock.Lock()
+	defer f.printLock.Unlock()
+	var ports []string
+	for _, port := range f.last {
+		ports = append(ports, fmt.Sprintf("%d:%d", port.Local, port.Remote))
+	}
+	return ports
+}
+
+func (f *Forwarder) ready(pod corev1.Pod, forwarded []portforward.ForwardedPort) {
+	target := Target{
+		Namespace: pod.Namespace,
+		Pod:       pod.Name,
+		Addresses: f.Addresses,
+	}
+	for _, port := range forwarded {
+		target.Ports = append(target.Ports, Port{Local: port.Local, Remote: port.Remote})
+	}
+
+	f.printLock.Lock()
+	defer f.printLock.Unlock()
+	if !f.AllPods {
+		f.last = target.Ports
+	}
+	if f.JSON {
+		json.NewEncoder(f.Out).Encode(target)
+		return
+	}
+	if f.AllPods {
+		for _, port := range target.Ports {
+			for _, address := range f.Addresses {
+				fmt.Fprintf(f.Out, "Forwarding from %s:%d -> %s:%d\n", address, port.Local, pod.Name, port.Remote)
+			}
+		}
+	}
+}
+
+// AllocatedPorts returns the ports with their local ports left to allocate.
+func AllocatedPorts(ports []string) []string {
+	var allocated []string
+	for _, port := range ports {
+		if i := strings.LastIndex(port, ":"); i >= 0 {
+			port = port[i+1:]
+		}
+		allocated = append(allocated, ":"+port)
+	}
+	return allocated
+}
+
+// PodsForResource returns a function selecting the pods of the resource to forward the ports
+// to: a running pod chosen like kubectl does or, with all, every running pod of the resource.
+func PodsForResource(f kcmdutil.Factory, namespace, resourceName string, all bool, timeout time.Duration) func(ctx context.Context) ([]corev1.Pod, error) {
+	return func(ctx context.Context) ([]corev1.Pod, error) {
+		obj, err := f.NewBuilder().
+			WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...).
+			NamespaceParam(namespace).DefaultNamespace().
+			ResourceNames("pods", resourceName).
+			Do().Object()
+		if err != nil {
+			return nil, err
+		}
+		if !all {
+			pod, err := polymorphichelpers.AttachablePodForObjectFn(f, obj, timeout)
+			if err != nil {
+				return nil, err
+			}
+			return runningPods([]corev1.Pod{*pod}), nil
+		}
+
+		var selector labels.Selector
+		switch t := obj.(type) {
+		case *corev1.Pod:
+			return runningPods([]corev1.Pod{*t}), nil
+		case *appsv1.DeploymentConfig:
+			selector = labels.SelectorFromSet(t.Spec.Selector)
+		default:
+			if _, selector, err = polymorphichelpers.SelectorsForObject(obj); err != nil {
+				return nil, err
+			}
+		}
+		clientset, err := f.KubernetesClientSet()
+		if err != nil {
+			return nil, err
+		}
+		pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			return nil, err
+		}
+		return runningPods(pods.Items), nil
+	}
+}
+
+// runningPods returns the running pods that are not being deleted, sorted by name.
+func runningPods(pods []corev1.Pod) []corev1.Pod {
+	var running []corev1.Pod
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil {
+			running = append(running, pod)
+		}
+	}
+	sort.Slice(running, func(i, j int) bool { return running[i].Name < running[j].Name })
+	return running
+}
@@ -0,0 +1,131 @@
+package profiles
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"k8s.io/client-go/util/homedir"
+	"sigs.k8s.io/yaml"
+)
+
+// Profile is a named bundle of the cluster, user and namespace to log in to, with the
+// settings needed to connect to the cluster. Profiles do not hold credentials, which
+// are stored by oc login as usual.
+type Profile struct {
+	Name string `json:"name"`
+	// Server is the URL of the API server.
+	Server string `json:"server"`
+	// Username is the user to log in as, if not prompted for.
+	Username string `json:"username,omitempty"`
+	// Namespace is the project used after logging in, if the user has access to it.
+	Namespace string `json:"namespace,omitempty"`
+	// CertificateAuthority is the path to the CA bundle trusted to connect to the server.
+	CertificateAuthority string `json:"certificateAuthority,omitempty"`
+	// InsecureSkipTLSVerify connects to the server without verifying its certificate.
+	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
+	// ProxyURL is the URL of the proxy to connect to the server through.
+	ProxyURL string `json:"proxyURL,omitempty"`
+}
+
+// Profiles is the content of the profiles file.
+type Profiles struct {
+	Profiles []Profile `json:"profiles"`
+}
+
+// DefaultPath returns the path to the profiles file, in the same directory as the oc
+// user defaults.
+func DefaultPath() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); len(dir) > 0 {
+		return filepath.Join(dir, "oc", "profiles.yaml")
+	}
+	return filepath.Join(homedir.HomeDir(), ".config", "oc", "profiles.yaml")
+}
+
+// Load reads the profiles file, which is empty if it does not exist.
+func Load(path string) (*Profiles, error) {
+	profiles := &Profiles{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return profiles, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.UnmarshalStrict(data, profiles); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+	return profiles, nil
+}
+
+// Save writes the profiles file, sorted by name.
+func (p *Profiles) Save(path string) error {
+	sort.Slice(p.Profiles, func(i, j int) bool { return p.Profiles[i].Name < p.Profiles[j].Name })
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// Get returns the profile with the name.
+func (p *Profiles) Get(name string) (*Profile, error) {
+	for i := range p.Profiles {
+		if p.Profiles[i].Name == name {
+			return &p.Profiles[i], nil
+		}
+	}
+	return nil, fmt.Errorf("the profile %q does not exist, see 'oc config profiles list'", name)
+}
+
+// Set adds the profile, or replaces the profile with the same name.
+func (p *Profiles) Set(profile Profile) {
+	for i := range p.Profiles {
+		if p.Profiles[i].Name == profile.Name {
+			p.Profiles[i] = profile
+			return
+		}
+	}
+	p.Profiles = append(p.Profiles, profile)
+}
+
+// Delete removes the profile with the name, and returns whether it existed.
+func (p *Profiles) Delete(name string) bool {
+	for i := range p.Profiles {
+		if p.Profiles[i].Name == name {
+			p.Profiles = append(p.Profiles[:i], p.Profiles[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns an error if the profile cannot be logged in to.
+func (p *Profile) Validate() error {
+	if len(p.Name) == 0 {
+		return fmt.Errorf("a profile name is required")
+	}
+	if len(p.Server) == 0 {
+		return fmt.Errorf("the profile %q must have a server", p.Name)
+	}
+	if p.InsecureSkipTLSVerify && len(p.CertificateAuthority) > 0 {
+		return fmt.Errorf("the profile %q cannot both have a certificate authority and skip TLS verification", p.Name)
+	}
+	if len(p.ProxyURL) > 0 {
+		u, err := url.Parse(p.ProxyURL)
+		if err != nil {
+			return fmt.Errorf("the proxy URL of the profile %q is not valid: %v", p.Name, err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("the proxy URL of the profile %q must be an http, https or socks5 URL", p.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package profiles
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "oc", "profiles.yaml")
+	profiles, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles.Profiles) != 0 {
+		t.Fatalf("expected no profiles, got %#v", profiles)
+	}
+
+	prod := Profile{Name: "prod", Server: "https://api.prod.example.com:6443", Username: "sre", Namespace: "openshift-monitoring", ProxyURL: "http://proxy.example.com:3128"}
+	dev := Profile{Name: "dev", Server: "https://api.dev.example.com:6443", InsecureSkipTLSVerify: true}
+	profiles.Set(prod)
+	profiles.Set(dev)
+	prod.CertificateAuthority = "/etc/pki/prod-ca.crt"
+	profiles.Set(prod)
+	if err := profiles.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	profiles, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []Profile{dev, prod}; !reflect.DeepEqual(expected, profiles.Profiles) {
+		t.Errorf("expected %#v, got %#v", expected, profiles.Profiles)
+	}
+	got, err := profiles.Get("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(prod, *got) {
+		t.Errorf("expected %#v, got %#v", prod, *got)
+	}
+
+	if !profiles.Delete("dev") || profiles.Delete("dev") {
+		t.Errorf("expected dev to be deleted once")
+	}
+	if _, err := profiles.Get("dev"); err == nil {
+		t.Errorf("expected an error getting a deleted profile")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		wantErr bool
+	}{
+		{name: "valid", profile: Profile{Name: "a", Server: "https://api.example.com:6443", ProxyURL: "socks5://localhost:1080"}},
+		{name: "no name", profile: Profile{Server: "https://api.example.com:6443"}, wantErr: true},
+		{name: "no server", profile: Profile{Name: "a"}, wantErr: true},
+		{name: "ca and insecure", profile: Profile{Name: "a", Server: "https://api.example.com:6443", CertificateAuthority: "ca.crt", InsecureSkipTLSVerify: true}, wantErr: true},
+		{name: "proxy scheme", profile: Profile{Name: "a", Server: "https://api.example.com:6443", ProxyURL: "ftp://proxy"}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.profile.Validate(); (err != nil) != test.wantErr {
+				t.Errorf("expected error %v, got %v", test.wantErr, err)
+			}
+		})
+	}
+}
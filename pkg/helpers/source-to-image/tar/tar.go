@@ -33,6 +33,12 @@ type Tar interface {
 	// creation
 	SetExclusionPattern(*regexp.Regexp)
 
+	// SetExclusionFunc sets a function that excludes files from tar
+	// creation in addition to the exclusion pattern. It is called with
+	// the path relative to the archived directory, and excluding a
+	// directory excludes all of its contents
+	SetExclusionFunc(func(path string, isDir bool) bool)
+
 	// CreateTarFile creates a tar file in the base directory
 	// using the contents of dir directory
 	// The name of the new tar file is returned if successful
@@ -179,6 +185,7 @@ type stiTar struct {
 	fs.FileSystem
 	timeout              time.Duration
 	exclude              *regexp.Regexp
+	excludeFunc          func(path string, isDir bool) bool
 	includeDirInPath     bool
 	disallowOverwrite    bool
 	disallowOutsidePaths bool
@@ -192,6 +199,12 @@ func (t *stiTar) SetExclusionPattern(p *regexp.Regexp) {
 	t.exclude = p
 }
 
+// SetExclusionFunc sets a function that excludes files from tar creation.
+// The path it is called with always uses UNIX-style (/) path separators.
+func (t *stiTar) SetExclusionFunc(fn func(path string, isDir bool) bool) {
+	t.excludeFunc = fn
+}
+
 // CreateTarFile creates a tar file from the given directory
 // while excluding files that match the given exclusion pattern
 // It returns the name of the created file
@@ -239,6 +252,14 @@ func (t *stiTar) CreateTarStreamToTarWriter(dir string, includeDirInPath bool, t
 		if err != nil {
 			return err
 		}
+		if t.excludeFunc != nil && dir != path {
+			if rel, err := filepath.Rel(dir, path); err == nil && t.excludeFunc(filepath.ToSlash(rel), info.IsDir()) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
 		// on Windows, directory symlinks report as a directory and as a symlink.
 		// They should be treated as symlinks.
 		if !t.shouldExclude(path) {
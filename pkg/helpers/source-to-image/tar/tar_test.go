@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -288,6 +289,39 @@ func TestCreateTarIncludeDotGit(t *testing.T) {
 	verifyTarFile(t, tarFile, testDirs, testFiles, testLinks)
 }
 
+func TestCreateTarExclusionFunc(t *testing.T) {
+	th := New(fs.NewFileSystem())
+	th.SetExclusionFunc(func(path string, isDir bool) bool {
+		return (isDir && path == "dir01/dir03") || (!isDir && strings.HasSuffix(path, ".git"))
+	})
+	tempDir, err := os.MkdirTemp("", "testtar")
+	defer os.RemoveAll(tempDir)
+	if err != nil {
+		t.Fatalf("Cannot create temp directory for test: %v", err)
+	}
+	modificationDate := time.Date(2011, time.March, 5, 23, 30, 1, 0, time.UTC)
+	testDirs := []dirDesc{
+		{"dir01", modificationDate, 0700},
+		{"dir01/dir02", modificationDate, 0755},
+		{"dir01/dir03", modificationDate, 0775},
+	}
+	testFiles := []fileDesc{
+		{"dir01/dir02/test1.txt", modificationDate, 0700, "Test1 file content", false, ""},
+		{"dir01/test2.git", modificationDate, 0660, "Test2 file content", true, ""},
+		{"dir01/dir03/test3.txt", modificationDate, 0444, "Test3 file content", true, ""},
+	}
+	if err = createTestFiles(tempDir, testDirs, testFiles, []linkDesc{}); err != nil {
+		t.Fatalf("Cannot create test files: %v", err)
+	}
+
+	tarFile, err := th.CreateTarFile("", tempDir)
+	defer os.Remove(tarFile)
+	if err != nil {
+		t.Fatalf("Unable to create new tar upload file: %v", err)
+	}
+	verifyTarFile(t, tarFile, testDirs, testFiles, []linkDesc{})
+}
+
 func TestCreateTarEmptyRegexp(t *testing.T) {
 	th := New(fs.NewFileSystem())
 	th.SetExclusionPattern(regexp.MustCompile(""))